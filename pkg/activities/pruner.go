@@ -0,0 +1,59 @@
+package activities
+
+import (
+	"time"
+
+	typev1 "github.com/jenkins-x/jx-api/pkg/client/clientset/versioned/typed/jenkins.io/v1"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/errorutil"
+	"github.com/jenkins-x/jx/v2/pkg/metrics"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Pruner deletes PipelineActivities in batches pausing between the batches so that pruning large numbers of
+// activities does not get throttled by the API server
+type Pruner struct {
+	Activities typev1.PipelineActivityInterface
+	// BatchSize the number of activities deleted before pausing. The activities are not batched if it is not positive
+	BatchSize int
+	// BatchInterval the pause between the batches
+	BatchInterval time.Duration
+	// DryRun only logs the activities which would be deleted
+	DryRun bool
+
+	sleep func(time.Duration)
+}
+
+// Delete deletes the pruned activities recording the number of deleted activities in the metrics. It continues
+// deleting the other activities if an activity fails to be deleted and returns the number of deleted activities
+func (p *Pruner) Delete(pruned []PrunedActivity) (int, error) {
+	sleep := p.sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	deleted := 0
+	errs := []error{}
+	for i, a := range pruned {
+		if i > 0 && p.BatchSize > 0 && i%p.BatchSize == 0 && p.BatchInterval > 0 && !p.DryRun {
+			sleep(p.BatchInterval)
+		}
+		activity := &a.Activity
+		if p.DryRun {
+			log.Logger().Infof("not deleting PipelineActivity %s as it exceeds the retention %s", util.ColorInfo(activity.Name), a.Reason)
+			continue
+		}
+		log.Logger().Infof("deleting PipelineActivity %s as it exceeds the retention %s", util.ColorInfo(activity.Name), a.Reason)
+		err := p.Activities.Delete(activity.Name, metav1.NewDeleteOptions(0))
+		if err != nil && !apierrors.IsNotFound(err) {
+			metrics.ActivityPruneFailures.Inc()
+			errs = append(errs, errors.Wrapf(err, "deleting PipelineActivity %s", activity.Name))
+			continue
+		}
+		deleted++
+		metrics.ActivitiesPruned.WithLabelValues(activity.RepositoryOwner(), activity.RepositoryName(), a.Reason).Inc()
+	}
+	return deleted, errorutil.CombineErrors(errs...)
+}
@@ -0,0 +1,66 @@
+// +build unit
+
+package activities
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	jxfake "github.com/jenkins-x/jx-api/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/v2/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPrunerDeletesInBatches(t *testing.T) {
+	ns := "jx"
+	jxClient := jxfake.NewSimpleClientset()
+	var pruned []PrunedActivity
+	for _, name := range []string{"a1", "a2", "a3", "a4", "a5"} {
+		activity := v1.PipelineActivity{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: ns,
+			},
+			Spec: v1.PipelineActivitySpec{
+				Pipeline: "pruner-org/pruner-app/master",
+			},
+		}
+		_, err := jxClient.JenkinsV1().PipelineActivities(ns).Create(&activity)
+		require.NoError(t, err)
+		pruned = append(pruned, PrunedActivity{Activity: activity, Reason: ReasonHistory})
+	}
+	// an activity which was already deleted is ignored
+	pruned = append(pruned, PrunedActivity{Activity: v1.PipelineActivity{ObjectMeta: metav1.ObjectMeta{Name: "deleted"}}, Reason: ReasonAge})
+
+	var pauses []time.Duration
+	pruner := &Pruner{
+		Activities:    jxClient.JenkinsV1().PipelineActivities(ns),
+		BatchSize:     2,
+		BatchInterval: time.Second,
+		DryRun:        true,
+		sleep: func(d time.Duration) {
+			pauses = append(pauses, d)
+		},
+	}
+	deleted, err := pruner.Delete(pruned)
+	require.NoError(t, err)
+	assert.Equal(t, 0, deleted)
+	assert.Empty(t, pauses)
+
+	counter := metrics.ActivitiesPruned.WithLabelValues("pruner-org", "pruner-app", ReasonHistory)
+	before := testutil.ToFloat64(counter)
+	pruner.DryRun = false
+	deleted, err = pruner.Delete(pruned)
+	require.NoError(t, err)
+	assert.Equal(t, 6, deleted)
+	assert.Equal(t, []time.Duration{time.Second, time.Second}, pauses)
+	assert.Equal(t, before+5, testutil.ToFloat64(counter))
+
+	list, err := jxClient.JenkinsV1().PipelineActivities(ns).List(metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, list.Items)
+}
@@ -0,0 +1,203 @@
+package activities
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ReasonAge the activity is pruned as it completed before the maximum age of its branch
+	ReasonAge = "age"
+	// ReasonHistory the activity is pruned as its branch has more recent activities than are kept
+	ReasonHistory = "history"
+)
+
+// RetentionPolicy the number and age of the completed PipelineActivities of each branch which are kept
+type RetentionPolicy struct {
+	// KeepReleases the number of the most recent activities of each release branch which are kept. All are kept if negative
+	KeepReleases int `json:"keepReleases"`
+	// KeepPullRequests the number of the most recent activities of each pull request which are kept. All are kept if negative
+	KeepPullRequests int `json:"keepPullRequests"`
+	// ReleaseMaxAge the maximum age of the activities of release branches such as 720h
+	ReleaseMaxAge string `json:"releaseMaxAge,omitempty"`
+	// PullRequestMaxAge the maximum age of the activities of pull requests and batch builds such as 48h
+	PullRequestMaxAge string `json:"pullRequestMaxAge,omitempty"`
+}
+
+// RetentionRule the retention policy of the activities of the repositories and branches matching the patterns
+type RetentionRule struct {
+	RetentionPolicy
+
+	// Repository the pattern of the owner/name of the repositories such as myorg/* or the name of the repository.
+	// All repositories match if empty
+	Repository string `json:"repository,omitempty"`
+	// Branch the pattern of the branches such as release-* or PR-*. All branches match if empty
+	Branch string `json:"branch,omitempty"`
+}
+
+// RetentionRules the retention rules of the PipelineActivities of a team
+type RetentionRules struct {
+	// Default the policy of the activities which match no rule
+	Default RetentionPolicy `json:"default"`
+	// Rules the rules of the repositories and branches. The first rule which matches is used
+	Rules []RetentionRule `json:"rules,omitempty"`
+}
+
+// PrunedActivity an activity pruned by the retention rules and why
+type PrunedActivity struct {
+	Activity v1.PipelineActivity
+	Reason   string
+}
+
+// LoadRetentionRules loads the retention rules of the YAML file. The settings missing from the default policy of the
+// file are taken from the given default policy and the settings missing from a rule are taken from the default policy
+func LoadRetentionRules(fileName string, defaultPolicy RetentionPolicy) (*RetentionRules, error) {
+	rules := &RetentionRules{Default: defaultPolicy}
+	if fileName == "" {
+		return rules, rules.validate()
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading the retention rules %s", fileName)
+	}
+	data, err = yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the retention rules %s", fileName)
+	}
+	file := struct {
+		Default json.RawMessage   `json:"default"`
+		Rules   []json.RawMessage `json:"rules"`
+	}{}
+	err = json.Unmarshal(data, &file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the retention rules %s", fileName)
+	}
+	if len(file.Default) > 0 {
+		err = json.Unmarshal(file.Default, &rules.Default)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing the default retention policy of %s", fileName)
+		}
+	}
+	for _, raw := range file.Rules {
+		rule := RetentionRule{RetentionPolicy: rules.Default}
+		err = json.Unmarshal(raw, &rule)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing a retention rule of %s", fileName)
+		}
+		rules.Rules = append(rules.Rules, rule)
+	}
+	err = rules.validate()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid retention rules in %s", fileName)
+	}
+	return rules, nil
+}
+
+// PolicyFor returns the retention policy of the branch of the repository which is the owner/name of the repository
+func (r *RetentionRules) PolicyFor(repository string, branch string) RetentionPolicy {
+	name := repository[strings.LastIndex(repository, "/")+1:]
+	for _, rule := range r.Rules {
+		if matchesPattern(rule.Repository, repository, name) && matchesPattern(rule.Branch, branch) {
+			return rule.RetentionPolicy
+		}
+	}
+	return r.Default
+}
+
+// Prune returns the completed activities which are not kept by the retention rules at the given time. The most
+// recently completed activities of each branch and context are kept
+func (r *RetentionRules) Prune(activities []v1.PipelineActivity, now time.Time) ([]PrunedActivity, error) {
+	var completed []v1.PipelineActivity
+	for _, activity := range activities {
+		if activity.Spec.CompletedTimestamp != nil {
+			completed = append(completed, activity)
+		}
+	}
+	sort.SliceStable(completed, func(i, j int) bool {
+		return completed[i].Spec.CompletedTimestamp.After(completed[j].Spec.CompletedTimestamp.Time)
+	})
+
+	var pruned []PrunedActivity
+	counts := map[string]int{}
+	for _, activity := range completed {
+		repository := activity.RepositoryOwner() + "/" + activity.RepositoryName()
+		branch := activity.BranchName()
+		policy := r.PolicyFor(repository, branch)
+		keep, maxAge, err := policy.limits(IsPullRequestBranch(branch))
+		if err != nil {
+			return nil, err
+		}
+		if maxAge > 0 && activity.Spec.CompletedTimestamp.Add(maxAge).Before(now) {
+			pruned = append(pruned, PrunedActivity{Activity: activity, Reason: ReasonAge})
+			continue
+		}
+		key := repository + "/" + branch + "/" + activity.Spec.Context
+		counts[key]++
+		if keep >= 0 && counts[key] > keep {
+			pruned = append(pruned, PrunedActivity{Activity: activity, Reason: ReasonHistory})
+		}
+	}
+	return pruned, nil
+}
+
+// IsPullRequestBranch returns true if the branch is a pull request or batch build rather than a release branch
+func IsPullRequestBranch(branch string) bool {
+	return strings.HasPrefix(branch, "PR-") || branch == "batch"
+}
+
+// limits returns the number of activities to keep and their maximum age for pull request or release branches
+func (p RetentionPolicy) limits(pullRequest bool) (int, time.Duration, error) {
+	keep, maxAge := p.KeepReleases, p.ReleaseMaxAge
+	if pullRequest {
+		keep, maxAge = p.KeepPullRequests, p.PullRequestMaxAge
+	}
+	if maxAge == "" {
+		return keep, 0, nil
+	}
+	duration, err := time.ParseDuration(maxAge)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "parsing the maximum age %s of a retention policy", maxAge)
+	}
+	return keep, duration, nil
+}
+
+func (r *RetentionRules) validate() error {
+	policies := []RetentionPolicy{r.Default}
+	for _, rule := range r.Rules {
+		if rule.Repository == "" && rule.Branch == "" {
+			return fmt.Errorf("a retention rule has neither a repository nor a branch")
+		}
+		policies = append(policies, rule.RetentionPolicy)
+	}
+	for _, policy := range policies {
+		for _, pullRequest := range []bool{false, true} {
+			_, _, err := policy.limits(pullRequest)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// matchesPattern returns true if the pattern is empty or matches any of the names
+func matchesPattern(pattern string, names ...string) bool {
+	if pattern == "" {
+		return true
+	}
+	for _, name := range names {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+// +build unit
+
+package activities_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/activities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var now = time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func activity(name string, repository string, branch string, completedDaysAgo int) v1.PipelineActivity {
+	answer := v1.PipelineActivity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: v1.PipelineActivitySpec{
+			Pipeline: "myorg/" + repository + "/" + branch,
+		},
+	}
+	if completedDaysAgo >= 0 {
+		answer.Spec.CompletedTimestamp = &metav1.Time{Time: now.AddDate(0, 0, -completedDaysAgo)}
+	}
+	return answer
+}
+
+func prunedNames(pruned []activities.PrunedActivity) map[string]string {
+	answer := map[string]string{}
+	for _, p := range pruned {
+		answer[p.Activity.Name] = p.Reason
+	}
+	return answer
+}
+
+func TestRetentionRulesPrune(t *testing.T) {
+	t.Parallel()
+
+	rules := &activities.RetentionRules{
+		Default: activities.RetentionPolicy{KeepReleases: 2, KeepPullRequests: 1, ReleaseMaxAge: "720h", PullRequestMaxAge: "48h"},
+		Rules: []activities.RetentionRule{
+			{
+				Repository:      "myorg/keepall",
+				RetentionPolicy: activities.RetentionPolicy{KeepReleases: -1, KeepPullRequests: -1},
+			},
+		},
+	}
+	pruned, err := rules.Prune([]v1.PipelineActivity{
+		activity("app-master-1", "app", "master", 3),
+		activity("app-master-2", "app", "master", 2),
+		activity("app-master-3", "app", "master", 1),
+		activity("app-master-4", "app", "master", 40),
+		activity("app-master-5", "app", "master", -1),
+		activity("app-pr-1", "app", "PR-1", 1),
+		activity("app-pr-2", "app", "PR-1", 0),
+		activity("app-pr-3", "app", "PR-2", 3),
+		activity("keepall-master-1", "keepall", "master", 100),
+		activity("keepall-master-2", "keepall", "master", 1),
+	}, now)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"app-master-1": activities.ReasonHistory,
+		"app-master-4": activities.ReasonAge,
+		"app-pr-1":     activities.ReasonHistory,
+		"app-pr-3":     activities.ReasonAge,
+	}, prunedNames(pruned))
+}
+
+func TestLoadRetentionRules(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-activity-retention-")
+	require.NoError(t, err)
+	fileName := filepath.Join(dir, "rules.yaml")
+	err = ioutil.WriteFile(fileName, []byte(`default:
+  keepPullRequests: 3
+rules:
+- repository: myorg/myapp
+  branch: release-*
+  keepReleases: 20
+- repository: legacy-*
+  releaseMaxAge: 24h
+`), 0600)
+	require.NoError(t, err)
+
+	defaultPolicy := activities.RetentionPolicy{KeepReleases: 5, KeepPullRequests: 2, ReleaseMaxAge: "720h"}
+	rules, err := activities.LoadRetentionRules(fileName, defaultPolicy)
+	require.NoError(t, err)
+	assert.Equal(t, activities.RetentionPolicy{KeepReleases: 5, KeepPullRequests: 3, ReleaseMaxAge: "720h"}, rules.PolicyFor("myorg/myapp", "master"))
+	assert.Equal(t, activities.RetentionPolicy{KeepReleases: 20, KeepPullRequests: 3, ReleaseMaxAge: "720h"}, rules.PolicyFor("myorg/myapp", "release-1.0"))
+	assert.Equal(t, activities.RetentionPolicy{KeepReleases: 5, KeepPullRequests: 3, ReleaseMaxAge: "24h"}, rules.PolicyFor("other/legacy-app", "master"))
+
+	rules, err = activities.LoadRetentionRules("", defaultPolicy)
+	require.NoError(t, err)
+	assert.Equal(t, defaultPolicy, rules.PolicyFor("myorg/myapp", "master"))
+
+	err = ioutil.WriteFile(fileName, []byte("rules:\n- keepReleases: 1\n"), 0600)
+	require.NoError(t, err)
+	_, err = activities.LoadRetentionRules(fileName, defaultPolicy)
+	assert.Error(t, err, "a rule without a repository or branch should be rejected")
+
+	err = ioutil.WriteFile(fileName, []byte("rules:\n- branch: master\n  releaseMaxAge: a month\n"), 0600)
+	require.NoError(t, err)
+	_, err = activities.LoadRetentionRules(fileName, defaultPolicy)
+	assert.Error(t, err, "an invalid age should be rejected")
+}
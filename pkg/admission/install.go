@@ -0,0 +1,369 @@
+package admission
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"time"
+
+	jenkinsio "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io"
+	"github.com/jenkins-x/jx/v2/pkg/version"
+	"github.com/pkg/errors"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/cert"
+)
+
+const (
+	// DefaultName the default name of the deployment, service and webhook configuration of the webhook
+	DefaultName = "jx-admission"
+	// DefaultImage the image of the webhook which is tagged with the version in the version stream or, by default, the
+	// version of the running jx
+	DefaultImage = "gcr.io/jenkinsxio/builder-jx"
+	// Port the port of the service of the webhook
+	Port = 443
+	// CertificateRenewBefore how long before it expires the certificate of the webhook is regenerated
+	CertificateRenewBefore = 30 * 24 * time.Hour
+
+	containerPort = 8443
+	certsDir      = "/etc/webhook/certs"
+	// annotationCertificateChecksum rolls the pods of the webhook when its certificate is regenerated
+	annotationCertificateChecksum = "jenkins.io/certificate-checksum"
+)
+
+// InstallOptions the options of the installation of the webhook
+type InstallOptions struct {
+	// Name the name of the deployment, service and webhook configuration
+	Name string
+	// Namespace the namespace of the deployment and service
+	Namespace string
+	// Image the image of the deployment running jx, defaults to DefaultImage tagged with the version of the running jx
+	Image string
+	// FailClosed rejects the resources when the webhook is unavailable. The webhook then runs two replicas protected
+	// by a PodDisruptionBudget so that writes are not blocked while a node is drained
+	FailClosed bool
+}
+
+// Install creates or updates the Secret of the certificate, the Deployment and Service of the webhook and the webhook
+// configuration registering it with the API server. The certificate in an existing Secret is reused until it is about
+// to expire so that the pods are only rolled when it changes
+func Install(kubeClient kubernetes.Interface, o InstallOptions) error {
+	if o.Name == "" {
+		o.Name = DefaultName
+	}
+	if o.Image == "" {
+		o.Image = DefaultImage + ":" + strings.TrimPrefix(version.GetVersion(), version.VersionPrefix)
+	}
+	labels := map[string]string{"app": o.Name}
+
+	secrets := kubeClient.CoreV1().Secrets(o.Namespace)
+	existingSecret, err := secrets.Get(tlsSecretName(o.Name), metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "getting Secret %s in namespace %s", tlsSecretName(o.Name), o.Namespace)
+	}
+	secretExists := err == nil
+	var certPEM, keyPEM []byte
+	if secretExists && ValidCertificate(existingSecret.Data[corev1.TLSCertKey], existingSecret.Data[corev1.TLSPrivateKeyKey], o.Name, o.Namespace, time.Now()) {
+		certPEM = existingSecret.Data[corev1.TLSCertKey]
+		keyPEM = existingSecret.Data[corev1.TLSPrivateKeyKey]
+	} else {
+		certPEM, keyPEM, err = GenerateCertificate(o.Name, o.Namespace)
+		if err != nil {
+			return err
+		}
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: tlsSecretName(o.Name), Namespace: o.Namespace, Labels: labels},
+		Type:       corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       certPEM,
+			corev1.TLSPrivateKeyKey: keyPEM,
+		},
+	}
+	if secretExists {
+		existingSecret.Labels = secret.Labels
+		existingSecret.Type = secret.Type
+		existingSecret.Data = secret.Data
+		_, err = secrets.Update(existingSecret)
+	} else {
+		_, err = secrets.Create(secret)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "saving Secret %s in namespace %s", secret.Name, o.Namespace)
+	}
+
+	deployment := webhookDeployment(o, labels, fmt.Sprintf("%x", sha256.Sum256(certPEM)))
+	deployments := kubeClient.AppsV1().Deployments(o.Namespace)
+	existingDeployment, err := deployments.Get(o.Name, metav1.GetOptions{})
+	if err == nil {
+		existingDeployment.Labels = deployment.Labels
+		existingDeployment.Spec = deployment.Spec
+		_, err = deployments.Update(existingDeployment)
+	} else if apierrors.IsNotFound(err) {
+		_, err = deployments.Create(deployment)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "saving Deployment %s in namespace %s", o.Name, o.Namespace)
+	}
+
+	err = savePodDisruptionBudget(kubeClient, o, labels)
+	if err != nil {
+		return err
+	}
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: o.Name, Namespace: o.Namespace, Labels: labels},
+		Spec: corev1.ServiceSpec{
+			Selector: labels,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "https",
+					Port:       Port,
+					TargetPort: intstr.FromInt(containerPort),
+				},
+			},
+		},
+	}
+	services := kubeClient.CoreV1().Services(o.Namespace)
+	existingService, err := services.Get(o.Name, metav1.GetOptions{})
+	if err == nil {
+		existingService.Labels = service.Labels
+		existingService.Spec.Selector = service.Spec.Selector
+		existingService.Spec.Ports = service.Spec.Ports
+		_, err = services.Update(existingService)
+	} else if apierrors.IsNotFound(err) {
+		_, err = services.Create(service)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "saving Service %s in namespace %s", o.Name, o.Namespace)
+	}
+
+	// the API server trusts the certificate of the webhook via the certificate authority ending the chain
+	config := WebhookConfiguration(o.Name, o.Name, o.Namespace, Port, certPEM, o.FailClosed)
+	configs := kubeClient.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations()
+	existingConfig, err := configs.Get(o.Name, metav1.GetOptions{})
+	if err == nil {
+		existingConfig.Labels = config.Labels
+		existingConfig.Webhooks = config.Webhooks
+		_, err = configs.Update(existingConfig)
+	} else if apierrors.IsNotFound(err) {
+		_, err = configs.Create(config)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "saving ValidatingWebhookConfiguration %s", o.Name)
+	}
+	return nil
+}
+
+// Uninstall deletes the webhook configuration, then the Deployment, PodDisruptionBudget, Service and Secret of the webhook
+func Uninstall(kubeClient kubernetes.Interface, name string, ns string) error {
+	if name == "" {
+		name = DefaultName
+	}
+	// the webhook configuration goes first so that the API server stops calling the webhook before it is removed
+	err := kubeClient.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations().Delete(name, &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "deleting ValidatingWebhookConfiguration %s", name)
+	}
+	err = kubeClient.AppsV1().Deployments(ns).Delete(name, &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "deleting Deployment %s in namespace %s", name, ns)
+	}
+	err = kubeClient.PolicyV1beta1().PodDisruptionBudgets(ns).Delete(name, &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "deleting PodDisruptionBudget %s in namespace %s", name, ns)
+	}
+	err = kubeClient.CoreV1().Services(ns).Delete(name, &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "deleting Service %s in namespace %s", name, ns)
+	}
+	err = kubeClient.CoreV1().Secrets(ns).Delete(tlsSecretName(name), &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "deleting Secret %s in namespace %s", tlsSecretName(name), ns)
+	}
+	return nil
+}
+
+// savePodDisruptionBudget keeps one of the replicas of a webhook which fails closed available while nodes are drained,
+// deleting the PodDisruptionBudget of a webhook which fails open
+func savePodDisruptionBudget(kubeClient kubernetes.Interface, o InstallOptions, labels map[string]string) error {
+	pdbs := kubeClient.PolicyV1beta1().PodDisruptionBudgets(o.Namespace)
+	if !o.FailClosed {
+		err := pdbs.Delete(o.Name, &metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "deleting PodDisruptionBudget %s in namespace %s", o.Name, o.Namespace)
+		}
+		return nil
+	}
+	minAvailable := intstr.FromInt(1)
+	pdb := &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: o.Name, Namespace: o.Namespace, Labels: labels},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			MinAvailable: &minAvailable,
+			Selector:     &metav1.LabelSelector{MatchLabels: labels},
+		},
+	}
+	existing, err := pdbs.Get(o.Name, metav1.GetOptions{})
+	if err == nil {
+		existing.Labels = pdb.Labels
+		existing.Spec = pdb.Spec
+		_, err = pdbs.Update(existing)
+	} else if apierrors.IsNotFound(err) {
+		_, err = pdbs.Create(pdb)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "saving PodDisruptionBudget %s in namespace %s", o.Name, o.Namespace)
+	}
+	return nil
+}
+
+func webhookDeployment(o InstallOptions, labels map[string]string, certificateChecksum string) *appsv1.Deployment {
+	replicas := int32(1)
+	if o.FailClosed {
+		replicas = 2
+	}
+	probe := &corev1.Probe{
+		Handler: corev1.Handler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path:   "/ready",
+				Port:   intstr.FromInt(containerPort),
+				Scheme: corev1.URISchemeHTTPS,
+			},
+		},
+	}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: o.Name, Namespace: o.Namespace, Labels: labels},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      labels,
+					Annotations: map[string]string{annotationCertificateChecksum: certificateChecksum},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:    "admission",
+							Image:   o.Image,
+							Command: []string{"jx"},
+							Args: []string{"controller", "admission", "--batch-mode",
+								"--port", fmt.Sprintf("%d", containerPort),
+								"--tls-cert-file", certsDir + "/" + corev1.TLSCertKey,
+								"--tls-key-file", certsDir + "/" + corev1.TLSPrivateKeyKey,
+							},
+							Ports: []corev1.ContainerPort{
+								{Name: "https", ContainerPort: containerPort},
+							},
+							ReadinessProbe: probe,
+							LivenessProbe:  probe,
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: "certs", MountPath: certsDir, ReadOnly: true},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "certs",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{SecretName: tlsSecretName(o.Name)},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func tlsSecretName(name string) string {
+	return name + "-tls"
+}
+
+// GenerateCertificate generates a self signed certificate authority and a serving certificate signed by it for the
+// DNS names of the service returning the PEM encoded certificates, whose last one is the certificate authority, and key
+func GenerateCertificate(service string, ns string) ([]byte, []byte, error) {
+	host := fmt.Sprintf("%s.%s.svc", service, ns)
+	certPEM, keyPEM, err := cert.GenerateSelfSignedCertKey(host, nil, []string{service, service + "." + ns})
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "generating the certificate of %s", host)
+	}
+	return certPEM, keyPEM, nil
+}
+
+// ValidCertificate returns true if the PEM encoded certificate and key are a valid pair whose certificate is valid for
+// the DNS name of the service and does not expire within CertificateRenewBefore
+func ValidCertificate(certPEM []byte, keyPEM []byte, service string, ns string, now time.Time) bool {
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil || len(pair.Certificate) == 0 {
+		return false
+	}
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return false
+	}
+	if now.Before(leaf.NotBefore) || now.Add(CertificateRenewBefore).After(leaf.NotAfter) {
+		return false
+	}
+	return leaf.VerifyHostname(fmt.Sprintf("%s.%s.svc", service, ns)) == nil
+}
+
+// WebhookConfiguration returns the configuration registering the webhook served by the service with the API server
+// to validate the Environment, SourceRepository and Scheduler resources on create and update. caBundle is the PEM
+// encoded certificate authority of the certificate of the service. The resources are admitted when the webhook is
+// unavailable unless failClosed is true
+func WebhookConfiguration(name string, service string, ns string, port int32, caBundle []byte, failClosed bool) *admissionregistrationv1beta1.ValidatingWebhookConfiguration {
+	path := ValidatePath
+	failurePolicy := admissionregistrationv1beta1.Ignore
+	if failClosed {
+		failurePolicy = admissionregistrationv1beta1.Fail
+	}
+	sideEffects := admissionregistrationv1beta1.SideEffectClassNone
+	timeout := int32(10)
+	return &admissionregistrationv1beta1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{"app": service},
+		},
+		Webhooks: []admissionregistrationv1beta1.ValidatingWebhook{
+			{
+				Name: service + "." + jenkinsio.GroupName,
+				ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{
+					Service: &admissionregistrationv1beta1.ServiceReference{
+						Namespace: ns,
+						Name:      service,
+						Path:      &path,
+						Port:      &port,
+					},
+					CABundle: caBundle,
+				},
+				Rules: []admissionregistrationv1beta1.RuleWithOperations{
+					{
+						Operations: []admissionregistrationv1beta1.OperationType{
+							admissionregistrationv1beta1.Create,
+							admissionregistrationv1beta1.Update,
+						},
+						Rule: admissionregistrationv1beta1.Rule{
+							APIGroups:   []string{jenkinsio.GroupName},
+							APIVersions: []string{jenkinsio.Version},
+							Resources:   Resources,
+						},
+					},
+				},
+				FailurePolicy:           &failurePolicy,
+				SideEffects:             &sideEffects,
+				TimeoutSeconds:          &timeout,
+				AdmissionReviewVersions: []string{"v1", "v1beta1"},
+			},
+		},
+	}
+}
@@ -0,0 +1,220 @@
+package admission
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+var (
+	// EnvironmentKindValues the kinds of environments the controllers support
+	EnvironmentKindValues = []string{
+		string(v1.EnvironmentKindTypePermanent),
+		string(v1.EnvironmentKindTypePreview),
+		string(v1.EnvironmentKindTypeTest),
+		string(v1.EnvironmentKindTypeEdit),
+		string(v1.EnvironmentKindTypeDevelopment),
+	}
+
+	// the names of the jobs are used as the names of the contexts of the commit statuses and of the pipelines
+	jobNameRegex = regexp.MustCompile(`^[A-Za-z0-9-._]+$`)
+)
+
+// ValidateEnvironment returns the problems of the environment which would make the controllers fail to promote to it.
+// The git repository of permanent environments is only required when they are created so that existing environments
+// without one can still be updated
+func ValidateEnvironment(env *v1.Environment, create bool) []string {
+	problems := []string{}
+	spec := &env.Spec
+	if spec.PromotionStrategy != "" && util.StringArrayIndex(v1.PromotionStrategyTypeValues, string(spec.PromotionStrategy)) < 0 {
+		problems = append(problems, notOneOf("spec.promotionStrategy", string(spec.PromotionStrategy), v1.PromotionStrategyTypeValues))
+	}
+	if spec.Kind != "" && util.StringArrayIndex(EnvironmentKindValues, string(spec.Kind)) < 0 {
+		problems = append(problems, notOneOf("spec.kind", string(spec.Kind), EnvironmentKindValues))
+	}
+	if spec.Namespace == "" {
+		if spec.Kind == v1.EnvironmentKindTypePermanent {
+			problems = append(problems, "spec.namespace is required")
+		}
+	} else {
+		for _, msg := range validation.IsDNS1123Label(spec.Namespace) {
+			problems = append(problems, fmt.Sprintf("spec.namespace %s is not a valid namespace: %s", spec.Namespace, msg))
+		}
+	}
+	// the permanent environments are promoted to via pull requests on their git repository
+	if spec.Source.URL == "" {
+		if create && spec.Kind == v1.EnvironmentKindTypePermanent && spec.PromotionStrategy != v1.PromotionStrategyTypeNever {
+			problems = append(problems, "spec.source.url is required for permanent environments which are promoted to")
+		}
+	} else if _, err := gits.ParseGitURL(spec.Source.URL); err != nil {
+		problems = append(problems, fmt.Sprintf("spec.source.url %s is not a valid git URL", spec.Source.URL))
+	}
+	return problems
+}
+
+// ValidateSourceRepository returns the problems of the source repository which would make the pipelines of the
+// repository fail to be triggered
+func ValidateSourceRepository(sr *v1.SourceRepository) []string {
+	problems := []string{}
+	spec := &sr.Spec
+	if spec.Org == "" {
+		problems = append(problems, "spec.org is required")
+	}
+	if spec.Repo == "" {
+		problems = append(problems, "spec.repo is required")
+	}
+	if spec.URL == "" && spec.HTTPCloneURL == "" && spec.Provider == "" {
+		problems = append(problems, "one of spec.url, spec.httpCloneURL or spec.provider is required")
+	}
+	if spec.Provider != "" {
+		u, err := url.Parse(spec.Provider)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("spec.provider %s is not a valid git server URL", spec.Provider))
+		}
+	}
+	for _, f := range []struct {
+		path  string
+		value string
+	}{
+		{"spec.url", spec.URL},
+		{"spec.httpCloneURL", spec.HTTPCloneURL},
+	} {
+		if f.value == "" {
+			continue
+		}
+		if _, err := gits.ParseGitURL(f.value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s %s is not a valid git URL", f.path, f.value))
+		}
+	}
+	return problems
+}
+
+// ValidateScheduler returns the problems of the jobs of the scheduler which would make the pipeline configuration
+// generated from it fail to load
+func ValidateScheduler(scheduler *v1.Scheduler) []string {
+	problems := []string{}
+	spec := &scheduler.Spec
+	if spec.Presubmits != nil {
+		names := map[string]bool{}
+		for i, job := range spec.Presubmits.Items {
+			if job == nil {
+				continue
+			}
+			path := fmt.Sprintf("spec.presubmits.entries[%d]", i)
+			problems = append(problems, validateJobBase(path, job.JobBase, names)...)
+			problems = append(problems, validateBrancher(path, job.Brancher)...)
+			problems = append(problems, validateChangeMatcher(path, job.RegexpChangeMatcher)...)
+		}
+	}
+	if spec.Postsubmits != nil {
+		names := map[string]bool{}
+		for i, job := range spec.Postsubmits.Items {
+			if job == nil {
+				continue
+			}
+			path := fmt.Sprintf("spec.postsubmits.entries[%d]", i)
+			problems = append(problems, validateJobBase(path, job.JobBase, names)...)
+			problems = append(problems, validateBrancher(path, job.Brancher)...)
+			problems = append(problems, validateChangeMatcher(path, job.RegexpChangeMatcher)...)
+		}
+	}
+	if spec.Periodics != nil {
+		names := map[string]bool{}
+		for i, job := range spec.Periodics.Items {
+			if job == nil {
+				continue
+			}
+			path := fmt.Sprintf("spec.periodics.entries[%d]", i)
+			problems = append(problems, validateJobBase(path, job.JobBase, names)...)
+			interval := stringValue(job.Interval)
+			cron := stringValue(job.Cron)
+			switch {
+			case interval == "" && cron == "":
+				problems = append(problems, path+" requires one of interval or cron")
+			case interval != "" && cron != "":
+				problems = append(problems, path+" can only have one of interval or cron")
+			case interval != "":
+				if _, err := time.ParseDuration(interval); err != nil {
+					problems = append(problems, fmt.Sprintf("%s.interval %s is not a valid duration", path, interval))
+				}
+			}
+		}
+	}
+	return problems
+}
+
+func validateJobBase(path string, job *v1.JobBase, names map[string]bool) []string {
+	problems := []string{}
+	if job == nil || stringValue(job.Name) == "" {
+		return append(problems, path+".name is required")
+	}
+	name := *job.Name
+	if !jobNameRegex.MatchString(name) {
+		problems = append(problems, fmt.Sprintf("%s.name %s must only contain letters, digits, '-', '.' and '_'", path, name))
+	}
+	if names[name] {
+		problems = append(problems, fmt.Sprintf("%s.name %s is used by another job", path, name))
+	}
+	names[name] = true
+	if ns := stringValue(job.Namespace); ns != "" {
+		for _, msg := range validation.IsDNS1123Label(ns) {
+			problems = append(problems, fmt.Sprintf("%s.namespace %s is not a valid namespace: %s", path, ns, msg))
+		}
+	}
+	return problems
+}
+
+func validateBrancher(path string, brancher *v1.Brancher) []string {
+	problems := []string{}
+	if brancher == nil {
+		return problems
+	}
+	if brancher.Branches != nil && len(brancher.Branches.Items) > 0 && brancher.SkipBranches != nil && len(brancher.SkipBranches.Items) > 0 {
+		problems = append(problems, path+" can only have one of branches or skipBranches")
+	}
+	for _, f := range []struct {
+		field    string
+		branches *v1.ReplaceableSliceOfStrings
+	}{
+		{"branches", brancher.Branches},
+		{"skipBranches", brancher.SkipBranches},
+	} {
+		if f.branches == nil {
+			continue
+		}
+		for _, b := range f.branches.Items {
+			if _, err := regexp.Compile(b); err != nil {
+				problems = append(problems, fmt.Sprintf("%s.%s %s is not a valid regular expression", path, f.field, b))
+			}
+		}
+	}
+	return problems
+}
+
+func validateChangeMatcher(path string, matcher *v1.RegexpChangeMatcher) []string {
+	if matcher == nil || stringValue(matcher.RunIfChanged) == "" {
+		return nil
+	}
+	if _, err := regexp.Compile(*matcher.RunIfChanged); err != nil {
+		return []string{fmt.Sprintf("%s.runIfChanged %s is not a valid regular expression", path, *matcher.RunIfChanged)}
+	}
+	return nil
+}
+
+func notOneOf(path string, value string, values []string) string {
+	return fmt.Sprintf("%s %s is not one of %s", path, value, strings.Join(values, ", "))
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
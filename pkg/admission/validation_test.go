@@ -0,0 +1,150 @@
+// +build unit
+
+package admission_test
+
+import (
+	"testing"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/admission"
+	"github.com/stretchr/testify/assert"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestValidateEnvironment(t *testing.T) {
+	t.Parallel()
+
+	valid := &v1.Environment{
+		Spec: v1.EnvironmentSpec{
+			Namespace:         "jx-staging",
+			PromotionStrategy: v1.PromotionStrategyTypeAutomatic,
+			Kind:              v1.EnvironmentKindTypePermanent,
+			Source:            v1.EnvironmentRepository{URL: "https://github.com/cheese/environment-staging.git"},
+		},
+	}
+	assert.Empty(t, admission.ValidateEnvironment(valid, true))
+
+	local := valid.DeepCopy()
+	local.Spec.PromotionStrategy = v1.PromotionStrategyTypeNever
+	local.Spec.Source.URL = ""
+	assert.Empty(t, admission.ValidateEnvironment(local, true), "environments which are never promoted to do not need a git repository")
+
+	invalid := valid.DeepCopy()
+	invalid.Spec.PromotionStrategy = "Sometimes"
+	invalid.Spec.Kind = "Temporary"
+	invalid.Spec.Namespace = "JX_Staging"
+	assert.Equal(t, []string{
+		"spec.promotionStrategy Sometimes is not one of Auto, Manual, Never",
+		"spec.kind Temporary is not one of Permanent, Preview, Test, Edit, Development",
+		"spec.namespace JX_Staging is not a valid namespace: a DNS-1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')",
+	}, admission.ValidateEnvironment(invalid, true))
+
+	missing := valid.DeepCopy()
+	missing.Spec.Namespace = ""
+	missing.Spec.Source.URL = ""
+	assert.Equal(t, []string{
+		"spec.namespace is required",
+		"spec.source.url is required for permanent environments which are promoted to",
+	}, admission.ValidateEnvironment(missing, true))
+
+	assert.Equal(t, []string{"spec.namespace is required"}, admission.ValidateEnvironment(missing, false),
+		"the git repository is only required when the environment is created")
+}
+
+func TestValidateSourceRepository(t *testing.T) {
+	t.Parallel()
+
+	valid := &v1.SourceRepository{
+		Spec: v1.SourceRepositorySpec{
+			Provider: "https://github.com",
+			Org:      "cheese",
+			Repo:     "edam",
+			URL:      "https://github.com/cheese/edam.git",
+		},
+	}
+	assert.Empty(t, admission.ValidateSourceRepository(valid))
+
+	assert.Equal(t, []string{
+		"spec.org is required",
+		"spec.repo is required",
+		"one of spec.url, spec.httpCloneURL or spec.provider is required",
+	}, admission.ValidateSourceRepository(&v1.SourceRepository{}))
+
+	invalid := valid.DeepCopy()
+	invalid.Spec.Provider = "github"
+	assert.Equal(t, []string{"spec.provider github is not a valid git server URL"}, admission.ValidateSourceRepository(invalid))
+}
+
+func TestValidateScheduler(t *testing.T) {
+	t.Parallel()
+
+	valid := &v1.Scheduler{
+		Spec: v1.SchedulerSpec{
+			Presubmits: &v1.Presubmits{
+				Items: []*v1.Presubmit{
+					{
+						JobBase:             &v1.JobBase{Name: strPtr("pr-build")},
+						RegexpChangeMatcher: &v1.RegexpChangeMatcher{RunIfChanged: strPtr(`^src/.*\.go$`)},
+					},
+				},
+			},
+			Postsubmits: &v1.Postsubmits{
+				Items: []*v1.Postsubmit{
+					{
+						JobBase:  &v1.JobBase{Name: strPtr("release")},
+						Brancher: &v1.Brancher{Branches: &v1.ReplaceableSliceOfStrings{Items: []string{"master"}}},
+					},
+				},
+			},
+			Periodics: &v1.Periodics{
+				Items: []*v1.Periodic{
+					{JobBase: &v1.JobBase{Name: strPtr("nightly")}, Cron: strPtr("0 2 * * *")},
+				},
+			},
+		},
+	}
+	assert.Empty(t, admission.ValidateScheduler(valid))
+
+	invalid := &v1.Scheduler{
+		Spec: v1.SchedulerSpec{
+			Presubmits: &v1.Presubmits{
+				Items: []*v1.Presubmit{
+					{JobBase: &v1.JobBase{Name: strPtr("pr build"), Namespace: strPtr("JX")}},
+					{JobBase: &v1.JobBase{}, RegexpChangeMatcher: &v1.RegexpChangeMatcher{RunIfChanged: strPtr("src/(")}},
+				},
+			},
+			Postsubmits: &v1.Postsubmits{
+				Items: []*v1.Postsubmit{
+					{
+						JobBase: &v1.JobBase{Name: strPtr("release")},
+						Brancher: &v1.Brancher{
+							Branches:     &v1.ReplaceableSliceOfStrings{Items: []string{"master"}},
+							SkipBranches: &v1.ReplaceableSliceOfStrings{Items: []string{"["}},
+						},
+					},
+					{JobBase: &v1.JobBase{Name: strPtr("release")}},
+				},
+			},
+			Periodics: &v1.Periodics{
+				Items: []*v1.Periodic{
+					{JobBase: &v1.JobBase{Name: strPtr("nightly")}},
+					{JobBase: &v1.JobBase{Name: strPtr("hourly")}, Interval: strPtr("1 hour")},
+				},
+			},
+		},
+	}
+	assert.Equal(t, []string{
+		"spec.presubmits.entries[0].name pr build must only contain letters, digits, '-', '.' and '_'",
+		"spec.presubmits.entries[0].namespace JX is not a valid namespace: a DNS-1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')",
+		"spec.presubmits.entries[1].name is required",
+		"spec.presubmits.entries[1].runIfChanged src/( is not a valid regular expression",
+		"spec.postsubmits.entries[0] can only have one of branches or skipBranches",
+		"spec.postsubmits.entries[0].skipBranches [ is not a valid regular expression",
+		"spec.postsubmits.entries[1].name release is used by another job",
+		"spec.periodics.entries[0] requires one of interval or cron",
+		"spec.periodics.entries[1].interval 1 hour is not a valid duration",
+	}, admission.ValidateScheduler(invalid))
+}
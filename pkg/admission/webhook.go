@@ -0,0 +1,130 @@
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	jenkinsio "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io"
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/pkg/errors"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ValidatePath the URL path of the HTTP endpoint validating the resources
+	ValidatePath = "/validate"
+
+	// the API server limits the size of the requests to 3MB
+	maxRequestSize = 3 * 1024 * 1024
+)
+
+// Resources the plural names of the jenkins.io resources validated by the webhook
+var Resources = []string{"environments", "sourcerepositories", "schedulers"}
+
+// Handler returns the handler of the AdmissionReview requests of the API server which validates the Environment,
+// SourceRepository and Scheduler resources when they are created or updated
+func Handler() http.Handler {
+	return http.HandlerFunc(serveValidate)
+}
+
+func serveValidate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	if contentType := r.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "application/json") {
+		http.Error(w, fmt.Sprintf("unsupported content type %s", contentType), http.StatusUnsupportedMediaType)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// the v1 and v1beta1 AdmissionReviews have the same fields so both are decoded as v1beta1, keeping the apiVersion
+	// of the request as the API server requires the response to have the same version
+	review := &admissionv1beta1.AdmissionReview{}
+	err = json.Unmarshal(body, review)
+	if err != nil || review.Request == nil {
+		http.Error(w, "the body is not an AdmissionReview request", http.StatusBadRequest)
+		return
+	}
+	if review.APIVersion == "" {
+		review.APIVersion = admissionv1beta1.SchemeGroupVersion.String()
+	}
+	review.Kind = "AdmissionReview"
+
+	review.Response = Review(review.Request)
+	review.Response.UID = review.Request.UID
+	review.Request = nil
+	data, err := json.Marshal(review)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data) //nolint:errcheck
+}
+
+// Review validates the resource of the request returning a response denying the request with the problems of the
+// resource if it is invalid. Resources which are deleted or not validated are allowed
+func Review(req *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
+	if req.Operation == admissionv1beta1.Delete || req.Kind.Group != jenkinsio.GroupName {
+		return &admissionv1beta1.AdmissionResponse{Allowed: true}
+	}
+	problems, err := validate(req.Kind.Kind, req.Object.Raw, req.Operation == admissionv1beta1.Create)
+	if err != nil {
+		return denied(metav1.StatusReasonBadRequest, http.StatusBadRequest, err.Error())
+	}
+	if len(problems) == 0 {
+		return &admissionv1beta1.AdmissionResponse{Allowed: true}
+	}
+	name := req.Name
+	if req.Namespace != "" {
+		name = req.Namespace + "/" + name
+	}
+	log.Logger().Infof("denied the %s of %s %s: %s", strings.ToLower(string(req.Operation)), req.Kind.Kind, name, strings.Join(problems, ", "))
+	return denied(metav1.StatusReasonInvalid, http.StatusUnprocessableEntity,
+		fmt.Sprintf("%s %s is invalid: %s", req.Kind.Kind, req.Name, strings.Join(problems, ", ")))
+}
+
+func validate(kind string, raw []byte, create bool) ([]string, error) {
+	switch kind {
+	case "Environment":
+		env := &v1.Environment{}
+		if err := json.Unmarshal(raw, env); err != nil {
+			return nil, errors.Wrap(err, "decoding the Environment")
+		}
+		return ValidateEnvironment(env, create), nil
+	case "SourceRepository":
+		sr := &v1.SourceRepository{}
+		if err := json.Unmarshal(raw, sr); err != nil {
+			return nil, errors.Wrap(err, "decoding the SourceRepository")
+		}
+		return ValidateSourceRepository(sr), nil
+	case "Scheduler":
+		scheduler := &v1.Scheduler{}
+		if err := json.Unmarshal(raw, scheduler); err != nil {
+			return nil, errors.Wrap(err, "decoding the Scheduler")
+		}
+		return ValidateScheduler(scheduler), nil
+	}
+	return nil, nil
+}
+
+func denied(reason metav1.StatusReason, code int32, message string) *admissionv1beta1.AdmissionResponse {
+	return &admissionv1beta1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Reason:  reason,
+			Code:    code,
+			Message: message,
+		},
+	}
+}
@@ -0,0 +1,180 @@
+// +build unit
+
+package admission_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/admission"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func reviewRequest(kind string, operation admissionv1beta1.Operation, object string) *admissionv1beta1.AdmissionReview {
+	review := &admissionv1beta1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1beta1", Kind: "AdmissionReview"},
+		Request: &admissionv1beta1.AdmissionRequest{
+			UID:       types.UID("1234"),
+			Kind:      metav1.GroupVersionKind{Group: "jenkins.io", Version: "v1", Kind: kind},
+			Name:      "staging",
+			Namespace: "jx",
+			Operation: operation,
+		},
+	}
+	if object != "" {
+		review.Request.Object = runtime.RawExtension{Raw: []byte(object)}
+	}
+	return review
+}
+
+func serve(t *testing.T, review *admissionv1beta1.AdmissionReview) *admissionv1beta1.AdmissionResponse {
+	body, err := json.Marshal(review)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, admission.ValidatePath, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	admission.Handler().ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	answer := &admissionv1beta1.AdmissionReview{}
+	err = json.Unmarshal(w.Body.Bytes(), answer)
+	require.NoError(t, err)
+	require.NotNil(t, answer.Response)
+	assert.Equal(t, review.APIVersion, answer.APIVersion, "the response should have the version of the request")
+	assert.Equal(t, review.Request.UID, answer.Response.UID)
+	return answer.Response
+}
+
+func TestHandlerValidatesResources(t *testing.T) {
+	t.Parallel()
+
+	response := serve(t, reviewRequest("Environment", admissionv1beta1.Create,
+		`{"spec":{"namespace":"jx-staging","promotionStrategy":"Auto","kind":"Permanent","source":{"url":"https://github.com/cheese/environment-staging.git"}}}`))
+	assert.True(t, response.Allowed)
+
+	review := reviewRequest("Environment", admissionv1beta1.Create,
+		`{"spec":{"namespace":"jx-staging","promotionStrategy":"Automatic","kind":"Permanent"}}`)
+	review.APIVersion = "admission.k8s.io/v1"
+	response = serve(t, review)
+	assert.False(t, response.Allowed)
+	require.NotNil(t, response.Result)
+	assert.Equal(t, int32(http.StatusUnprocessableEntity), response.Result.Code)
+	assert.Equal(t, "Environment staging is invalid: spec.promotionStrategy Automatic is not one of Auto, Manual, Never, spec.source.url is required for permanent environments which are promoted to",
+		response.Result.Message)
+
+	response = serve(t, reviewRequest("Environment", admissionv1beta1.Update,
+		`{"spec":{"namespace":"jx-staging","promotionStrategy":"Auto","kind":"Permanent"}}`))
+	assert.True(t, response.Allowed, "existing permanent environments without a git repository can be updated")
+
+	response = serve(t, reviewRequest("SourceRepository", admissionv1beta1.Create, `{"spec":{"org":"cheese"}}`))
+	assert.False(t, response.Allowed)
+
+	response = serve(t, reviewRequest("Scheduler", admissionv1beta1.Create, `{"spec":{"periodics":{"entries":[{"name":"nightly"}]}}}`))
+	assert.False(t, response.Allowed)
+
+	response = serve(t, reviewRequest("Environment", admissionv1beta1.Delete, ""))
+	assert.True(t, response.Allowed, "deletions are always allowed")
+
+	response = serve(t, reviewRequest("PipelineActivity", admissionv1beta1.Create, `{"spec":{}}`))
+	assert.True(t, response.Allowed, "resources which are not validated are allowed")
+}
+
+func TestHandlerRejectsInvalidRequests(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, admission.ValidatePath, nil)
+	w := httptest.NewRecorder()
+	admission.Handler().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	req = httptest.NewRequest(http.MethodPost, admission.ValidatePath, strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	admission.Handler().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestInstallAndUninstall(t *testing.T) {
+	t.Parallel()
+
+	kubeClient := fake.NewSimpleClientset()
+	o := admission.InstallOptions{Namespace: "jx", Image: "gcr.io/jenkinsxio/builder-jx:2.1.100"}
+	err := admission.Install(kubeClient, o)
+	require.NoError(t, err)
+
+	config, err := kubeClient.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations().Get(admission.DefaultName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, config.Webhooks, 1)
+	webhook := config.Webhooks[0]
+	require.NotNil(t, webhook.ClientConfig.Service)
+	assert.Equal(t, "jx", webhook.ClientConfig.Service.Namespace)
+	assert.Equal(t, admission.DefaultName, webhook.ClientConfig.Service.Name)
+	assert.Equal(t, admission.Resources, webhook.Rules[0].Resources)
+	assert.Equal(t, admissionregistrationv1beta1.Ignore, *webhook.FailurePolicy, "writes should not be blocked when the webhook is unavailable")
+	assert.Equal(t, []string{"v1", "v1beta1"}, webhook.AdmissionReviewVersions)
+
+	secret, err := kubeClient.CoreV1().Secrets("jx").Get(admission.DefaultName+"-tls", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, secret.Data["tls.crt"], webhook.ClientConfig.CABundle)
+
+	deployment, err := kubeClient.AppsV1().Deployments("jx").Get(admission.DefaultName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "gcr.io/jenkinsxio/builder-jx:2.1.100", deployment.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, int32(1), *deployment.Spec.Replicas)
+	checksum := deployment.Spec.Template.Annotations["jenkins.io/certificate-checksum"]
+	assert.NotEmpty(t, checksum)
+
+	// installing again reuses the valid certificate so the pods are not rolled
+	err = admission.Install(kubeClient, o)
+	require.NoError(t, err)
+	deployment, err = kubeClient.AppsV1().Deployments("jx").Get(admission.DefaultName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, checksum, deployment.Spec.Template.Annotations["jenkins.io/certificate-checksum"])
+
+	// an invalid certificate is regenerated and rolls the pods
+	secret.Data["tls.crt"] = []byte("invalid")
+	_, err = kubeClient.CoreV1().Secrets("jx").Update(secret)
+	require.NoError(t, err)
+	err = admission.Install(kubeClient, o)
+	require.NoError(t, err)
+	deployment, err = kubeClient.AppsV1().Deployments("jx").Get(admission.DefaultName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotEqual(t, checksum, deployment.Spec.Template.Annotations["jenkins.io/certificate-checksum"])
+
+	// failing closed runs two replicas which are kept available while nodes are drained
+	o.FailClosed = true
+	err = admission.Install(kubeClient, o)
+	require.NoError(t, err)
+	deployment, err = kubeClient.AppsV1().Deployments("jx").Get(admission.DefaultName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), *deployment.Spec.Replicas)
+	pdb, err := kubeClient.PolicyV1beta1().PodDisruptionBudgets("jx").Get(admission.DefaultName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, pdb.Spec.MinAvailable.IntValue())
+	config, err = kubeClient.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations().Get(admission.DefaultName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, admissionregistrationv1beta1.Fail, *config.Webhooks[0].FailurePolicy)
+
+	err = admission.Uninstall(kubeClient, "", "jx")
+	require.NoError(t, err)
+	_, err = kubeClient.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations().Get(admission.DefaultName, metav1.GetOptions{})
+	assert.Error(t, err)
+	_, err = kubeClient.CoreV1().Services("jx").Get(admission.DefaultName, metav1.GetOptions{})
+	assert.Error(t, err)
+	_, err = kubeClient.PolicyV1beta1().PodDisruptionBudgets("jx").Get(admission.DefaultName, metav1.GetOptions{})
+	assert.Error(t, err)
+
+	err = admission.Uninstall(kubeClient, "", "jx")
+	assert.NoError(t, err, "uninstalling twice should not fail")
+}
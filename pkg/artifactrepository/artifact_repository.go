@@ -0,0 +1,177 @@
+package artifactrepository
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// defaultTimeout the timeout of the requests to the artifact repository
+const defaultTimeout = time.Minute
+
+// mavenSettings the maven settings which resolve the artifacts through an artifact repository and deploy the artifacts
+// to it. The arguments are the server id, the URL resolving the artifacts, the credentials and the URLs of the release
+// and snapshot repositories
+const mavenSettings = `<settings>
+      <!-- sets the local maven repository outside of the ~/.m2 folder for easier mounting of secrets and repo -->
+      <localRepository>${user.home}/.mvnrepository</localRepository>
+      <!-- lets disable the download progress indicator that fills up logs -->
+      <interactiveMode>false</interactiveMode>
+      <mirrors>
+          <mirror>
+              <id>%[1]s</id>
+              <mirrorOf>external:*</mirrorOf>
+              <url>%[2]s</url>
+          </mirror>
+      </mirrors>
+      <servers>
+          <server>
+              <id>%[1]s</id>
+              <username>%[3]s</username>
+              <password>%[4]s</password>
+          </server>
+      </servers>
+      <profiles>
+          <profile>
+              <id>%[1]s</id>
+              <properties>
+                  <altDeploymentRepository>%[1]s::default::%[6]s</altDeploymentRepository>
+                  <altReleaseDeploymentRepository>%[1]s::default::%[5]s</altReleaseDeploymentRepository>
+                  <altSnapshotDeploymentRepository>%[1]s::default::%[6]s</altSnapshotDeploymentRepository>
+              </properties>
+          </profile>
+          <profile>
+              <id>release</id>
+              <properties>
+                  <gpg.executable>gpg</gpg.executable>
+                  <gpg.passphrase>mysecretpassphrase</gpg.passphrase>
+              </properties>
+          </profile>
+      </profiles>
+      <activeProfiles>
+          <!--make the profile active all the time -->
+          <activeProfile>%[1]s</activeProfile>
+      </activeProfiles>
+  </settings>
+`
+
+// ArtifactRepository an artifact repository which proxies the maven artifacts and npm packages used by the builds and
+// stores the released artifacts
+type ArtifactRepository interface {
+	// Kind returns the kind of the artifact repository
+	Kind() config.RepositoryType
+
+	// URL returns the base URL of the artifact repository
+	URL() string
+
+	// MavenRepositoryURL returns the URL of the maven repository which proxies the maven artifacts
+	MavenRepositoryURL() string
+
+	// MavenSettingsXML returns the maven settings which resolve the artifacts through the artifact repository and
+	// deploy the artifacts to it using the credentials
+	MavenSettingsXML(username string, password string) string
+
+	// NpmRegistryURL returns the URL of the npm registry which proxies the npm packages
+	NpmRegistryURL() string
+
+	// PromoteRelease moves the staged release of the maven artifact to the release repository
+	PromoteRelease(groupID string, artifactID string, version string) error
+}
+
+// Options the options to connect to an artifact repository
+type Options struct {
+	// URL the base URL of the artifact repository. Defaults to the in cluster service of Nexus
+	URL string
+	// Username the user of the artifact repository
+	Username string
+	// Password the password or API token of the user
+	Password string
+	// HTTPClient the client of the requests to the artifact repository
+	HTTPClient *http.Client
+}
+
+// NewArtifactRepository creates the artifact repository of the kind. An unknown kind defaults to Nexus which is
+// installed unless another repository is configured
+func NewArtifactRepository(kind config.RepositoryType, options Options) (ArtifactRepository, error) {
+	options.URL = strings.TrimSuffix(options.URL, "/")
+	if options.HTTPClient == nil {
+		options.HTTPClient = util.GetClientWithTimeout(defaultTimeout)
+	}
+	switch kind {
+	case config.RepositoryTypeNexus, config.RepositoryTypeUnknown:
+		if options.URL == "" {
+			options.URL = DefaultNexusURL
+		}
+		return &nexus{options: options}, nil
+	case config.RepositoryTypeArtifactory:
+		if options.URL == "" {
+			return nil, errors.New("no URL of the Artifactory artifact repository specified")
+		}
+		return &artifactory{options: options}, nil
+	default:
+		return nil, fmt.Errorf("the artifact repository %q does not support proxying and staging artifacts, the supported repositories are %s and %s",
+			kind, config.RepositoryTypeNexus, config.RepositoryTypeArtifactory)
+	}
+}
+
+// FromRequirements creates the artifact repository configured in the requirements. The URL of the options overrides
+// the URL of the requirements
+func FromRequirements(requirements *config.RequirementsConfig, options Options) (ArtifactRepository, error) {
+	kind := config.RepositoryTypeUnknown
+	if requirements != nil {
+		kind = requirements.Repository
+		if options.URL == "" {
+			options.URL = requirements.RepositoryURL
+		}
+	}
+	return NewArtifactRepository(kind, options)
+}
+
+// FromTeamSettings creates the artifact repository selected by the requirements stored in the team settings
+func FromTeamSettings(settings *v1.TeamSettings, options Options) (ArtifactRepository, error) {
+	requirements, err := config.GetRequirementsConfigFromTeamSettings(settings)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading the requirements of the team settings")
+	}
+	return FromRequirements(requirements, options)
+}
+
+// doRequest sends the request to the artifact repository with the credentials of the options and fails unless the
+// response is successful
+func doRequest(options Options, method string, u string) error {
+	req, err := http.NewRequest(method, u, nil)
+	if err != nil {
+		return errors.Wrapf(err, "creating the request %s %s", method, u)
+	}
+	if options.Username != "" || options.Password != "" {
+		req.SetBasicAuth(options.Username, options.Password)
+	}
+	resp, err := options.HTTPClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "sending the request %s %s", method, u)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("the request %s %s returned status %d", method, u, resp.StatusCode)
+	}
+	return nil
+}
+
+// NpmRC returns the npm configuration which installs the npm packages through the registry of the artifact repository
+// using the credentials
+func NpmRC(repository ArtifactRepository, username string, password string) string {
+	registry := repository.NpmRegistryURL()
+	npmrc := "registry=" + registry + "\n"
+	if username != "" || password != "" {
+		auth := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+		npmrc += "always-auth=true\n" + "//" + strings.TrimPrefix(strings.TrimPrefix(registry, "https://"), "http://") + ":_auth=" + auth + "\n"
+	}
+	return npmrc
+}
@@ -0,0 +1,116 @@
+// +build unit
+
+package artifactrepository_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/artifactrepository"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNexusRepository(t *testing.T) {
+	t.Parallel()
+
+	repository, err := artifactrepository.NewArtifactRepository(config.RepositoryTypeUnknown, artifactrepository.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, config.RepositoryTypeNexus, repository.Kind())
+	assert.Equal(t, "http://nexus/repository/maven-group/", repository.MavenRepositoryURL())
+	assert.Equal(t, "http://nexus/repository/npm-group/", repository.NpmRegistryURL())
+
+	settings := repository.MavenSettingsXML("admin", "secret")
+	assert.Contains(t, settings, "<url>http://nexus/repository/maven-group/</url>")
+	assert.Contains(t, settings, "<password>secret</password>")
+	assert.Contains(t, settings, "nexus::default::http://nexus/repository/maven-releases/")
+}
+
+func TestArtifactoryRepository(t *testing.T) {
+	t.Parallel()
+
+	_, err := artifactrepository.NewArtifactRepository(config.RepositoryTypeArtifactory, artifactrepository.Options{})
+	assert.Error(t, err)
+
+	repository, err := artifactrepository.NewArtifactRepository(config.RepositoryTypeArtifactory, artifactrepository.Options{URL: "https://acme.jfrog.io/artifactory/"})
+	require.NoError(t, err)
+	assert.Equal(t, config.RepositoryTypeArtifactory, repository.Kind())
+	assert.Equal(t, "https://acme.jfrog.io/artifactory/libs-release/", repository.MavenRepositoryURL())
+	assert.Equal(t, "https://acme.jfrog.io/artifactory/api/npm/npm/", repository.NpmRegistryURL())
+
+	settings := repository.MavenSettingsXML("deployer", "token")
+	assert.Contains(t, settings, "<id>artifactory</id>")
+	assert.Contains(t, settings, "artifactory::default::https://acme.jfrog.io/artifactory/libs-release-local/")
+	assert.Contains(t, settings, "artifactory::default::https://acme.jfrog.io/artifactory/libs-snapshot-local/")
+
+	assert.Equal(t, "registry=https://acme.jfrog.io/artifactory/api/npm/npm/\n", artifactrepository.NpmRC(repository, "", ""))
+	assert.Equal(t, "registry=https://acme.jfrog.io/artifactory/api/npm/npm/\nalways-auth=true\n//acme.jfrog.io/artifactory/api/npm/npm/:_auth=ZGVwbG95ZXI6dG9rZW4=\n",
+		artifactrepository.NpmRC(repository, "deployer", "token"))
+}
+
+func TestArtifactRepositoryWithoutProxy(t *testing.T) {
+	t.Parallel()
+
+	_, err := artifactrepository.NewArtifactRepository(config.RepositoryTypeBucketRepo, artifactrepository.Options{})
+	assert.Error(t, err)
+	_, err = artifactrepository.NewArtifactRepository(config.RepositoryTypeNone, artifactrepository.Options{})
+	assert.Error(t, err)
+}
+
+func TestFromTeamSettings(t *testing.T) {
+	t.Parallel()
+
+	repository, err := artifactrepository.FromTeamSettings(&v1.TeamSettings{}, artifactrepository.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, config.RepositoryTypeNexus, repository.Kind())
+
+	settings := &v1.TeamSettings{
+		BootRequirements: "repository: artifactory\nrepositoryUrl: https://acme.jfrog.io/artifactory\n",
+	}
+	repository, err = artifactrepository.FromTeamSettings(settings, artifactrepository.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, config.RepositoryTypeArtifactory, repository.Kind())
+	assert.Equal(t, "https://acme.jfrog.io/artifactory", repository.URL())
+
+	repository, err = artifactrepository.FromTeamSettings(settings, artifactrepository.Options{URL: "https://artifactory.example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://artifactory.example.com", repository.URL())
+}
+
+func TestPromoteRelease(t *testing.T) {
+	t.Parallel()
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ := r.BasicAuth()
+		assert.Equal(t, "deployer", user)
+		assert.Equal(t, "token", password)
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		if r.URL.Query().Get("maven.artifactId") == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	options := artifactrepository.Options{URL: server.URL, Username: "deployer", Password: "token"}
+
+	nexus, err := artifactrepository.NewArtifactRepository(config.RepositoryTypeNexus, options)
+	require.NoError(t, err)
+	err = nexus.PromoteRelease("io.jenkins-x", "myapp", "1.0.0")
+	require.NoError(t, err)
+	err = nexus.PromoteRelease("io.jenkins-x", "missing", "1.0.0")
+	assert.Error(t, err)
+
+	artifactory, err := artifactrepository.NewArtifactRepository(config.RepositoryTypeArtifactory, options)
+	require.NoError(t, err)
+	err = artifactory.PromoteRelease("io.jenkins-x", "myapp", "1.0.0")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"POST /service/rest/v1/staging/move/maven-releases?maven.artifactId=myapp&maven.baseVersion=1.0.0&maven.groupId=io.jenkins-x&repository=maven-staging",
+		"POST /service/rest/v1/staging/move/maven-releases?maven.artifactId=missing&maven.baseVersion=1.0.0&maven.groupId=io.jenkins-x&repository=maven-staging",
+		"POST /api/move/libs-staging-local/io/jenkins-x/myapp/1.0.0?to=/libs-release-local/io/jenkins-x/myapp/1.0.0",
+	}, requests)
+}
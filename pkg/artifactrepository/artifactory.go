@@ -0,0 +1,67 @@
+package artifactrepository
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	artifactoryServerID       = "artifactory"
+	artifactoryMavenVirtual   = "libs-release"
+	artifactoryMavenReleases  = "libs-release-local"
+	artifactoryMavenSnapshots = "libs-snapshot-local"
+	artifactoryMavenStaging   = "libs-staging-local"
+	artifactoryNpmVirtual     = "npm"
+)
+
+// artifactory the JFrog Artifactory artifact repository
+type artifactory struct {
+	options Options
+}
+
+// Kind returns the kind of the artifact repository
+func (a *artifactory) Kind() config.RepositoryType {
+	return config.RepositoryTypeArtifactory
+}
+
+// URL returns the base URL of the artifact repository
+func (a *artifactory) URL() string {
+	return a.options.URL
+}
+
+// MavenRepositoryURL returns the URL of the virtual repository which proxies the maven artifacts
+func (a *artifactory) MavenRepositoryURL() string {
+	return a.repositoryURL(artifactoryMavenVirtual)
+}
+
+// MavenSettingsXML returns the maven settings which resolve the artifacts through Artifactory
+func (a *artifactory) MavenSettingsXML(username string, password string) string {
+	return fmt.Sprintf(mavenSettings, artifactoryServerID, a.MavenRepositoryURL(), username, password,
+		a.repositoryURL(artifactoryMavenReleases), a.repositoryURL(artifactoryMavenSnapshots))
+}
+
+// NpmRegistryURL returns the URL of the npm API of the virtual repository which proxies the npm packages
+func (a *artifactory) NpmRegistryURL() string {
+	return util.UrlJoin(a.options.URL, "api", "npm", artifactoryNpmVirtual) + "/"
+}
+
+// PromoteRelease moves the version directory of the artifact from the staging repository to the release repository
+// using the move API of Artifactory
+func (a *artifactory) PromoteRelease(groupID string, artifactID string, version string) error {
+	path := strings.Join([]string{strings.Replace(groupID, ".", "/", -1), artifactID, version}, "/")
+	u := util.UrlJoin(a.options.URL, "api", "move", artifactoryMavenStaging, path) + "?to=/" + artifactoryMavenReleases + "/" + path
+	err := doRequest(a.options, http.MethodPost, u)
+	if err != nil {
+		return errors.Wrapf(err, "moving %s:%s:%s from %s to %s", groupID, artifactID, version, artifactoryMavenStaging, artifactoryMavenReleases)
+	}
+	return nil
+}
+
+func (a *artifactory) repositoryURL(repository string) string {
+	return util.UrlJoin(a.options.URL, repository) + "/"
+}
@@ -0,0 +1,76 @@
+package artifactrepository
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultNexusURL the URL of the Nexus service installed in the cluster
+	DefaultNexusURL = "http://nexus"
+
+	nexusServerID           = "nexus"
+	nexusMavenGroup         = "maven-group"
+	nexusMavenReleases      = "maven-releases"
+	nexusMavenSnapshots     = "maven-snapshots"
+	nexusMavenStaging       = "maven-staging"
+	nexusNpmGroup           = "npm-group"
+	nexusStagingMovePath    = "service/rest/v1/staging/move"
+	nexusRepositoryBasePath = "repository"
+)
+
+// nexus the Sonatype Nexus artifact repository
+type nexus struct {
+	options Options
+}
+
+// Kind returns the kind of the artifact repository
+func (n *nexus) Kind() config.RepositoryType {
+	return config.RepositoryTypeNexus
+}
+
+// URL returns the base URL of the artifact repository
+func (n *nexus) URL() string {
+	return n.options.URL
+}
+
+// MavenRepositoryURL returns the URL of the group repository which proxies the maven artifacts
+func (n *nexus) MavenRepositoryURL() string {
+	return n.repositoryURL(nexusMavenGroup)
+}
+
+// MavenSettingsXML returns the maven settings which resolve the artifacts through Nexus
+func (n *nexus) MavenSettingsXML(username string, password string) string {
+	return fmt.Sprintf(mavenSettings, nexusServerID, n.MavenRepositoryURL(), username, password,
+		n.repositoryURL(nexusMavenReleases), n.repositoryURL(nexusMavenSnapshots))
+}
+
+// NpmRegistryURL returns the URL of the group repository which proxies the npm packages
+func (n *nexus) NpmRegistryURL() string {
+	return n.repositoryURL(nexusNpmGroup)
+}
+
+// PromoteRelease moves the components of the release from the staging repository to the release repository using
+// the staging API of Nexus
+func (n *nexus) PromoteRelease(groupID string, artifactID string, version string) error {
+	query := url.Values{}
+	query.Set("repository", nexusMavenStaging)
+	query.Set("maven.groupId", groupID)
+	query.Set("maven.artifactId", artifactID)
+	query.Set("maven.baseVersion", version)
+	u := util.UrlJoin(n.options.URL, nexusStagingMovePath, nexusMavenReleases) + "?" + query.Encode()
+	err := doRequest(n.options, http.MethodPost, u)
+	if err != nil {
+		return errors.Wrapf(err, "moving %s:%s:%s from %s to %s", groupID, artifactID, version, nexusMavenStaging, nexusMavenReleases)
+	}
+	return nil
+}
+
+func (n *nexus) repositoryURL(repository string) string {
+	return util.UrlJoin(n.options.URL, nexusRepositoryBasePath, repository) + "/"
+}
@@ -0,0 +1,185 @@
+package artifacts
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/cloud/buckets"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"gocloud.dev/blob"
+)
+
+const (
+	// ManifestFileName the name of the file stored next to stashed files describing when they expire
+	ManifestFileName = "jx-artifacts.yml"
+)
+
+// Manifest describes the files stashed by a build
+type Manifest struct {
+	Classifier string     `json:"classifier"`
+	Owner      string     `json:"owner,omitempty"`
+	Repository string     `json:"repository,omitempty"`
+	Branch     string     `json:"branch,omitempty"`
+	Build      string     `json:"build,omitempty"`
+	Files      []string   `json:"files,omitempty"`
+	Created    time.Time  `json:"created"`
+	Expires    *time.Time `json:"expires,omitempty"`
+}
+
+// IsExpired returns true if the files of the manifest should no longer be kept at the given time
+func (m *Manifest) IsExpired(now time.Time) bool {
+	return m.Expires != nil && m.Expires.Before(now)
+}
+
+// NewManifest creates a manifest of the files stashed now which expire after the retention period if it is positive
+func NewManifest(classifier string, urls []string, retention time.Duration, now time.Time) *Manifest {
+	m := &Manifest{
+		Classifier: classifier,
+		Created:    now,
+	}
+	for _, name := range RelativeNames(urls) {
+		m.Files = append(m.Files, name)
+	}
+	sort.Strings(m.Files)
+	if retention > 0 {
+		expires := now.Add(retention)
+		m.Expires = &expires
+	}
+	return m
+}
+
+// RelativeNames returns the names of the URLs relative to their common directory indexed by URL
+func RelativeNames(urls []string) map[string]string {
+	answer := map[string]string{}
+	if len(urls) == 0 {
+		return answer
+	}
+	paths := map[string]string{}
+	prefix := ""
+	for i, u := range urls {
+		p := u
+		parsed, err := url.Parse(u)
+		if err == nil {
+			p = parsed.Path
+		}
+		paths[u] = p
+		dir := path.Dir(p) + "/"
+		if i == 0 {
+			prefix = dir
+			continue
+		}
+		for !strings.HasPrefix(dir, prefix) {
+			prefix = path.Dir(strings.TrimSuffix(prefix, "/")) + "/"
+		}
+	}
+	for u, p := range paths {
+		answer[u] = strings.TrimPrefix(p, prefix)
+	}
+	return answer
+}
+
+// Download downloads the file at the URL from a bucket or git provider to the given file creating its directory
+func Download(u string, fileName string, timeout time.Duration, httpFn func(urlString string) (string, func(*http.Request), error)) error {
+	reader, err := buckets.ReadURL(u, timeout, httpFn)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", u)
+	}
+	err = os.MkdirAll(filepath.Dir(fileName), util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the directory of %s", fileName)
+	}
+	err = ioutil.WriteFile(fileName, data, util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write file %s", fileName)
+	}
+	return nil
+}
+
+// DeleteExpired deletes the files stashed in the bucket under the prefix whose manifest has expired at the given
+// time, returning the directories of the deleted files
+func DeleteExpired(bucketURL string, prefix string, now time.Time, dryRun bool, timeout time.Duration) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	bucket, err := blob.Open(ctx, bucketURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open bucket %s", bucketURL)
+	}
+
+	keys, err := listKeys(ctx, bucket, prefix)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list bucket %s", bucketURL)
+	}
+	var expiredDirs []string
+	for _, key := range keys {
+		if path.Base(key) != ManifestFileName {
+			continue
+		}
+		data, err := bucket.ReadAll(ctx, key)
+		if err != nil {
+			return expiredDirs, errors.Wrapf(err, "failed to read %s in bucket %s", key, bucketURL)
+		}
+		m := &Manifest{}
+		err = yaml.Unmarshal(data, m)
+		if err != nil {
+			return expiredDirs, errors.Wrapf(err, "failed to parse %s in bucket %s", key, bucketURL)
+		}
+		if m.IsExpired(now) {
+			expiredDirs = append(expiredDirs, path.Dir(key)+"/")
+		}
+	}
+	if dryRun {
+		return expiredDirs, nil
+	}
+	for _, dir := range expiredDirs {
+		for _, key := range keys {
+			if !strings.HasPrefix(key, dir) {
+				continue
+			}
+			err = bucket.Delete(ctx, key)
+			if err != nil {
+				return expiredDirs, errors.Wrapf(err, "failed to delete %s in bucket %s", key, bucketURL)
+			}
+		}
+	}
+	return expiredDirs, nil
+}
+
+// MarshalManifest returns the YAML of the manifest
+func MarshalManifest(m *Manifest) (io.Reader, error) {
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the artifacts manifest")
+	}
+	return strings.NewReader(string(data)), nil
+}
+
+func listKeys(ctx context.Context, bucket *blob.Bucket, prefix string) ([]string, error) {
+	var keys []string
+	iter := bucket.List(&blob.ListOptions{Prefix: prefix})
+	for {
+		obj, err := iter.Next(ctx)
+		if err == io.EOF {
+			return keys, nil
+		}
+		if err != nil {
+			return keys, err
+		}
+		keys = append(keys, obj.Key)
+	}
+}
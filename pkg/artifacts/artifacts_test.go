@@ -0,0 +1,106 @@
+// +build unit
+
+package artifacts_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/artifacts"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelativeNames(t *testing.T) {
+	t.Parallel()
+	names := artifacts.RelativeNames([]string{
+		"gs://mybucket/jenkins-x/tests/myorg/myrepo/master/3/junit/a.xml",
+		"gs://mybucket/jenkins-x/tests/myorg/myrepo/master/3/junit/b/b.xml",
+		"gs://mybucket/jenkins-x/tests/myorg/myrepo/master/3/summary.txt",
+	})
+	assert.Equal(t, map[string]string{
+		"gs://mybucket/jenkins-x/tests/myorg/myrepo/master/3/junit/a.xml":   "junit/a.xml",
+		"gs://mybucket/jenkins-x/tests/myorg/myrepo/master/3/junit/b/b.xml": "junit/b/b.xml",
+		"gs://mybucket/jenkins-x/tests/myorg/myrepo/master/3/summary.txt":   "summary.txt",
+	}, names)
+
+	names = artifacts.RelativeNames([]string{"https://raw.githubusercontent.com/myorg/myrepo/gh-pages/coverage/index.html"})
+	assert.Equal(t, map[string]string{"https://raw.githubusercontent.com/myorg/myrepo/gh-pages/coverage/index.html": "index.html"}, names)
+}
+
+func TestDeleteExpired(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "test-artifacts-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	stash := func(build string, retention time.Duration, created time.Time) string {
+		buildDir := filepath.Join(dir, "jenkins-x", "tests", "myorg", "myrepo", "master", build)
+		err := os.MkdirAll(buildDir, util.DefaultWritePermissions)
+		require.NoError(t, err)
+		err = ioutil.WriteFile(filepath.Join(buildDir, "junit.xml"), []byte("<testsuite/>"), util.DefaultWritePermissions)
+		require.NoError(t, err)
+		if retention > 0 {
+			m := artifacts.NewManifest("tests", []string{"file://" + filepath.Join(buildDir, "junit.xml")}, retention, created)
+			data, err := artifacts.MarshalManifest(m)
+			require.NoError(t, err)
+			bytes, err := ioutil.ReadAll(data)
+			require.NoError(t, err)
+			err = ioutil.WriteFile(filepath.Join(buildDir, artifacts.ManifestFileName), bytes, util.DefaultWritePermissions)
+			require.NoError(t, err)
+		}
+		return buildDir
+	}
+	expired := stash("1", 24*time.Hour, now.Add(-48*time.Hour))
+	kept := stash("10", 72*time.Hour, now.Add(-48*time.Hour))
+	forever := stash("11", 0, now.Add(-48*time.Hour))
+
+	bucketURL := "file://" + dir
+	dirs, err := artifacts.DeleteExpired(bucketURL, "jenkins-x/", now, true, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"jenkins-x/tests/myorg/myrepo/master/1/"}, dirs)
+	assert.FileExists(t, filepath.Join(expired, "junit.xml"), "dry run should not delete files")
+
+	dirs, err = artifacts.DeleteExpired(bucketURL, "jenkins-x/", now, false, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"jenkins-x/tests/myorg/myrepo/master/1/"}, dirs)
+	_, err = os.Stat(filepath.Join(expired, "junit.xml"))
+	assert.True(t, os.IsNotExist(err), "the expired files should be deleted")
+	_, err = os.Stat(filepath.Join(expired, artifacts.ManifestFileName))
+	assert.True(t, os.IsNotExist(err), "the expired manifest should be deleted")
+	assert.FileExists(t, filepath.Join(kept, "junit.xml"))
+	assert.FileExists(t, filepath.Join(forever, "junit.xml"))
+}
+
+func TestDownload(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		_, err := fmt.Fprintf(w, "coverage of %s", r.URL.Path)
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "test-artifacts-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	httpFn := func(u string) (string, func(*http.Request), error) {
+		return u, func(*http.Request) {}, nil
+	}
+	fileName := filepath.Join(dir, "coverage", "html", "index.html")
+	err = artifacts.Download(server.URL+"/coverage/index.html", fileName, time.Minute, httpFn)
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(fileName)
+	require.NoError(t, err)
+	assert.Equal(t, "coverage of /coverage/index.html", string(data))
+}
@@ -237,7 +237,7 @@ func (c *AuthConfig) PickServer(message string, batchMode bool, handles util.IOF
 				Options: urls,
 			}
 			surveyOpts := survey.WithStdio(handles.In, handles.Out, handles.Err)
-			err := survey.AskOne(prompt, &url, survey.Required, surveyOpts)
+			err := util.AskOne(prompt, &url, survey.Required, surveyOpts)
 			if err != nil {
 				return nil, err
 			}
@@ -267,7 +267,7 @@ func (c *AuthConfig) PickServerUserAuth(server *AuthServer, message string, batc
 			Default: true,
 		}
 		flag := false
-		err := survey.AskOne(confirm, &flag, nil, surveyOpts)
+		err := util.AskOne(confirm, &flag, nil, surveyOpts)
 		if err != nil {
 			return auth, err
 		}
@@ -280,7 +280,7 @@ func (c *AuthConfig) PickServerUserAuth(server *AuthServer, message string, batc
 			Message: message,
 		}
 		username := ""
-		err = survey.AskOne(prompt, &username, nil, surveyOpts)
+		err = util.AskOne(prompt, &username, nil, surveyOpts)
 		if err != nil {
 			return auth, err
 		}
@@ -310,7 +310,7 @@ func (c *AuthConfig) PickServerUserAuth(server *AuthServer, message string, batc
 			Message: message,
 			Options: usernames,
 		}
-		err := survey.AskOne(prompt, &username, survey.Required, surveyOpts)
+		err := util.AskOne(prompt, &username, survey.Required, surveyOpts)
 		if err != nil {
 			return &UserAuth{}, err
 		}
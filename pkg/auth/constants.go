@@ -13,4 +13,7 @@ const (
 	GitAuthConfigFile = "gitAuth.yaml"
 	// ChartmuseumAuthConfigFile config file for chartmusuem auth credentials
 	ChartmuseumAuthConfigFile = "chartmuseumAuth.yaml"
+
+	// GitHubAppUsername the git username used with the installation tokens of a GitHub App
+	GitHubAppUsername = "x-access-token"
 )
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/jenkins-x/jx/v2/pkg/util"
@@ -36,6 +37,10 @@ const (
 	usernameKey = "username"
 	// secretDataPassword the password in a Secret/Credentials
 	passwordKey = "password"
+	// appIDKey the GitHub App ID in a Secret/Credentials
+	appIDKey = "appId"
+	// appPrivateKeyKey the GitHub App private key in a Secret/Credentials
+	appPrivateKeyKey = "privateKey"
 	// secretPrefix prefix for pipeline secrets
 	secretPrefix = "jx-pipeline"
 )
@@ -134,11 +139,14 @@ func (k *KubeAuthConfigHandler) SaveConfig(config *AuthConfig) error {
 		if user.Username == "" {
 			return errors.New("empty username")
 		}
-		if user.ApiToken == "" && user.Password == "" {
+		if user.ApiToken == "" && user.Password == "" && !user.IsGitHubApp() {
 			return errors.New("empty credentials")
 		}
 		secret.Data[usernameKey] = []byte(user.Username)
-		if user.ApiToken != "" {
+		if user.IsGitHubApp() {
+			secret.Data[appIDKey] = []byte(strconv.FormatInt(user.GithubAppID, 10))
+			secret.Data[appPrivateKeyKey] = []byte(user.GithubAppPrivateKey)
+		} else if user.ApiToken != "" {
 			secret.Data[passwordKey] = []byte(user.ApiToken)
 		} else {
 			secret.Data[passwordKey] = []byte(user.Password)
@@ -217,6 +225,21 @@ func (k *KubeAuthConfigHandler) userFromSecret(secret corev1.Secret) (UserAuth,
 	if !ok || len(username) == 0 {
 		return UserAuth{}, fmt.Errorf("no user name found in secret '%s'", secret.Name)
 	}
+	if appID, ok := data[appIDKey]; ok && len(appID) > 0 {
+		id, err := strconv.ParseInt(string(appID), 10, 64)
+		if err != nil {
+			return UserAuth{}, errors.Wrapf(err, "invalid GitHub App ID in secret '%s'", secret.Name)
+		}
+		privateKey, ok := data[appPrivateKeyKey]
+		if !ok || len(privateKey) == 0 {
+			return UserAuth{}, fmt.Errorf("no GitHub App private key found in secret '%s'", secret.Name)
+		}
+		return UserAuth{
+			Username:            string(username),
+			GithubAppID:         id,
+			GithubAppPrivateKey: string(privateKey),
+		}, nil
+	}
 	password, ok := data[passwordKey]
 	if !ok || len(password) == 0 {
 		return UserAuth{}, fmt.Errorf("no password found in secret '%s'", secret.Name)
@@ -462,6 +462,53 @@ func TestSaveConfig(t *testing.T) {
 				},
 			},
 		},
+		"save config into kubernetes secret with GitHub App credentials": {
+			namespace:  "test",
+			serverKind: "git",
+			config: &AuthConfig{
+				Servers: []*AuthServer{
+					{
+						URL: "https://github.com",
+						Users: []*UserAuth{
+							{
+								Username:            GitHubAppUsername,
+								GithubAppID:         1234,
+								GithubAppPrivateKey: "test-key",
+							},
+						},
+						Name:        "GitHub",
+						Kind:        "github",
+						CurrentUser: GitHubAppUsername,
+					},
+				},
+				CurrentServer: "https://github.com",
+			},
+			err: false,
+			want: []*corev1.Secret{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "jx-pipeline-git-github-github",
+						Namespace: "test",
+						Labels: map[string]string{
+							labelCredentialsType: valueCredentialTypeUsernamePassword,
+							labelCreatedBy:       valueCreatedByJX,
+							labelKind:            "git",
+							labelServiceKind:     "github",
+						},
+						Annotations: map[string]string{
+							annotationCredentialsDescription: fmt.Sprintf("Configuration and credentials for server https://github.com"),
+							annotationURL:                    "https://github.com",
+							annotationName:                   "GitHub",
+						},
+					},
+					Data: map[string][]byte{
+						"username":   []byte(GitHubAppUsername),
+						"appId":      []byte("1234"),
+						"privateKey": []byte("test-key"),
+					},
+				},
+			},
+		},
 		"save config into kubernetes secret with GitHub app owner": {
 			namespace:  "test",
 			serverKind: "git",
@@ -25,6 +25,11 @@ type UserAuth struct {
 	// GithubAppOwner if using GitHub Apps this represents the owner organisation/user which owns this token.
 	// we need to maintain a different token per owner
 	GithubAppOwner string `json:"appOwner,omitempty"`
+
+	// GithubAppID the ID of the GitHub App to authenticate as instead of using an API token
+	GithubAppID int64 `json:"appId,omitempty"`
+	// GithubAppPrivateKey the PEM encoded private key of the GitHub App used to request its installation tokens
+	GithubAppPrivateKey string `json:"appPrivateKey,omitempty"`
 }
 
 type AuthConfig struct {
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -9,6 +10,8 @@ const (
 	usernameSuffix    = "_USERNAME"
 	apiTokenSuffix    = "_API_TOKEN"
 	bearerTokenSuffix = "_BEARER_TOKEN"
+	appIDSuffix       = "_APP_ID"
+	appKeySuffix      = "_APP_PRIVATE_KEY"
 	DefaultUsername   = "dummy"
 )
 
@@ -30,6 +33,18 @@ func BearerTokenEnv(prefix string) string {
 	return prefix + bearerTokenSuffix
 }
 
+// AppIDEnv builds the GitHub App ID environment variable name
+func AppIDEnv(prefix string) string {
+	prefix = strings.ToUpper(prefix)
+	return prefix + appIDSuffix
+}
+
+// AppPrivateKeyEnv builds the GitHub App private key environment variable name
+func AppPrivateKeyEnv(prefix string) string {
+	prefix = strings.ToUpper(prefix)
+	return prefix + appKeySuffix
+}
+
 // CreateAuthUserFromEnvironment creates a user auth from environment variables
 func CreateAuthUserFromEnvironment(prefix string) UserAuth {
 	user := UserAuth{}
@@ -45,23 +60,42 @@ func CreateAuthUserFromEnvironment(prefix string) UserAuth {
 	if set {
 		user.BearerToken = bearerToken
 	}
+	appID, set := os.LookupEnv(AppIDEnv(prefix))
+	if set {
+		user.GithubAppID, _ = strconv.ParseInt(appID, 10, 64)
+	}
+	appKey, set := os.LookupEnv(AppPrivateKeyEnv(prefix))
+	if set {
+		user.GithubAppPrivateKey = appKey
+	}
 
 	if user.ApiToken != "" || user.Password != "" {
 		if user.Username == "" {
 			user.Username = DefaultUsername
 		}
 	}
+	if user.IsGitHubApp() && user.Username == "" {
+		user.Username = GitHubAppUsername
+	}
 
 	return user
 }
 
 // IsInvalid returns true if the user auth has a valid token
 func (a *UserAuth) IsInvalid() bool {
-	return a.BearerToken == "" && (a.ApiToken == "" || a.Username == "")
+	return a.BearerToken == "" && (a.ApiToken == "" || a.Username == "") && !a.IsGitHubApp()
+}
+
+// IsGitHubApp returns true if the user authenticates as a GitHub App with installation tokens
+func (a *UserAuth) IsGitHubApp() bool {
+	return a.GithubAppID != 0 && a.GithubAppPrivateKey != ""
 }
 
 // Valid returns true when the user authentication is valid, otherwise false
 func (a *UserAuth) IsValid() bool {
+	if a.IsGitHubApp() {
+		return true
+	}
 	if a.Username == "" {
 		return false
 	}
@@ -89,6 +89,26 @@ func TestCreateAuthUserFromEnvironment(t *testing.T) {
 				Password:    "",
 			},
 		},
+		"create GitHub App auth user from environment": {
+			prefix: prefix,
+			setup: func(t *testing.T) {
+				setEnvs(t, map[string]string{
+					auth.AppIDEnv(prefix):         "1234",
+					auth.AppPrivateKeyEnv(prefix): "test",
+				})
+			},
+			cleanup: func(t *testing.T) {
+				cleanEnvs(t, []string{
+					auth.AppIDEnv(prefix),
+					auth.AppPrivateKeyEnv(prefix),
+				})
+			},
+			want: auth.UserAuth{
+				Username:            auth.GitHubAppUsername,
+				GithubAppID:         1234,
+				GithubAppPrivateKey: "test",
+			},
+		},
 	}
 
 	for name, tc := range tests {
@@ -156,6 +176,19 @@ func TestIsInvalid(t *testing.T) {
 			},
 			want: false,
 		},
+		"valid user with GitHub App credentials": {
+			user: &auth.UserAuth{
+				GithubAppID:         1234,
+				GithubAppPrivateKey: "test",
+			},
+			want: false,
+		},
+		"invalid user with only a GitHub App ID": {
+			user: &auth.UserAuth{
+				GithubAppID: 1234,
+			},
+			want: true,
+		},
 	}
 
 	for name, tc := range tests {
@@ -0,0 +1,68 @@
+package buildpacks
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+
+	"github.com/jenkins-x/jx/v2/pkg/tekton/syntax"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// CNBPackName the name of the pack building the image with Cloud Native Buildpacks instead of a Dockerfile
+	CNBPackName = "cnb"
+
+	// CNBTinyBuilderImage the builder of statically linked applications such as Go
+	CNBTinyBuilderImage = "paketobuildpacks/builder:tiny"
+
+	// CNBFullBuilderImage the builder of applications requiring the full set of system packages such as Python or PHP
+	CNBFullBuilderImage = "paketobuildpacks/builder:full"
+
+	// CNBProjectDescriptor the project descriptor of Cloud Native Buildpacks
+	CNBProjectDescriptor = "project.toml"
+)
+
+var projectBuilderRegex = regexp.MustCompile(`(?m)^\s*builder\s*=\s*"([^"]+)"`)
+
+// cnbBuilders the builders of the files detected in the source of a project in order
+var cnbBuilders = []struct {
+	builder string
+	files   []string
+}{
+	{CNBTinyBuilderImage, []string{"go.mod"}},
+	{CNBFullBuilderImage, []string{"requirements.txt", "Pipfile", "setup.py", "composer.json"}},
+	{syntax.CNBBuilderImage, []string{"pom.xml", "build.gradle", "build.gradle.kts", "package.json", "Gemfile", "*.csproj", "Procfile"}},
+}
+
+// DetectCNBBuilder returns the builder image of the project descriptor in the given dir or the builder of the
+// language detected from its source
+func DetectCNBBuilder(dir string) (string, error) {
+	descriptor := filepath.Join(dir, CNBProjectDescriptor)
+	exists, err := util.FileExists(descriptor)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		data, err := ioutil.ReadFile(descriptor)
+		if err != nil {
+			return "", errors.Wrapf(err, "reading %s", descriptor)
+		}
+		if m := projectBuilderRegex.FindStringSubmatch(string(data)); m != nil {
+			return m[1], nil
+		}
+	}
+	for _, b := range cnbBuilders {
+		for _, file := range b.files {
+			matches, err := filepath.Glob(filepath.Join(dir, file))
+			if err != nil {
+				return "", errors.Wrapf(err, "detecting %s in %s", file, dir)
+			}
+			if len(matches) > 0 {
+				return b.builder, nil
+			}
+		}
+	}
+	return "", errors.Errorf("could not detect the Cloud Native Buildpacks builder of the source in %s, use --builder to specify it", dir)
+}
@@ -0,0 +1,58 @@
+// +build unit
+
+package buildpacks_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/buildpacks"
+	"github.com/jenkins-x/jx/v2/pkg/tekton/syntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectCNBBuilder(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name     string
+		files    map[string]string
+		expected string
+	}{
+		{"go", map[string]string{"go.mod": "module example.com/myapp"}, buildpacks.CNBTinyBuilderImage},
+		{"python", map[string]string{"requirements.txt": "flask"}, buildpacks.CNBFullBuilderImage},
+		{"dotnet", map[string]string{"myapp.csproj": "<Project/>"}, syntax.CNBBuilderImage},
+		{"descriptor", map[string]string{
+			"package.json": "{}",
+			"project.toml": "[project]\nid = \"myapp\"\n\n[build]\nbuilder = \"gcr.io/buildpacks/builder:v1\"\n",
+		}, "gcr.io/buildpacks/builder:v1"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			dir, err := ioutil.TempDir("", "cnb-"+tc.name)
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+			for name, content := range tc.files {
+				require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+			}
+
+			builder, err := buildpacks.DetectCNBBuilder(dir)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, builder)
+		})
+	}
+}
+
+func TestDetectCNBBuilderUnknownSource(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "cnb-unknown")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = buildpacks.DetectCNBBuilder(dir)
+	assert.Error(t, err)
+}
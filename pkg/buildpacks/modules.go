@@ -0,0 +1,87 @@
+package buildpacks
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// moduleFiles the files of the source of a buildable module
+var moduleFiles = []string{
+	"go.mod", "package.json", "pom.xml", "build.gradle", "build.gradle.kts", "requirements.txt", "setup.py",
+	"Pipfile", "Gemfile", "Cargo.toml", "composer.json", "*.csproj", "Dockerfile",
+}
+
+// aggregatorFiles the files of builds which aggregate the modules of their sub directories
+var aggregatorFiles = []string{"pom.xml", "build.gradle", "build.gradle.kts", "settings.gradle"}
+
+// ignoredModuleDirs the dirs never containing the source of modules
+var ignoredModuleDirs = []string{"node_modules", "vendor", "charts", "target", "build", "dist", "testdata", "test_data"}
+
+// DetectModules returns the dirs relative to the given dir of the buildable modules of a monorepo or nil if the
+// dir is a single project. The modules of builds aggregating their sub directories such as Maven are not detected
+func DetectModules(dir string) ([]string, error) {
+	aggregator, err := hasAnyFile(dir, aggregatorFiles)
+	if err != nil {
+		return nil, err
+	}
+	if aggregator {
+		return nil, nil
+	}
+	modules := []string{}
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() || path == dir {
+			return nil
+		}
+		name := info.Name()
+		if strings.HasPrefix(name, ".") {
+			return filepath.SkipDir
+		}
+		for _, ignored := range ignoredModuleDirs {
+			if name == ignored {
+				return filepath.SkipDir
+			}
+		}
+		module, err := hasAnyFile(path, moduleFiles)
+		if err != nil {
+			return err
+		}
+		if module {
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			modules = append(modules, filepath.ToSlash(rel))
+			// lets treat nested modules as part of their parent module
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "detecting the modules in %s", dir)
+	}
+	if len(modules) < 2 {
+		return nil, nil
+	}
+	sort.Strings(modules)
+	return modules, nil
+}
+
+func hasAnyFile(dir string, patterns []string) (bool, error) {
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return false, err
+		}
+		if len(matches) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,55 @@
+// +build unit
+
+package buildpacks_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/buildpacks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, dir string, files ...string) {
+	for _, file := range files {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte{}, 0600))
+	}
+}
+
+func TestDetectModules(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "modules")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	writeFiles(t, dir, "README.md",
+		"services/api/go.mod", "services/api/cmd/tool/go.mod",
+		"web/package.json", "web/node_modules/left-pad/package.json",
+		".github/actions/lint/package.json",
+		"charts/myapp/Dockerfile")
+
+	modules, err := buildpacks.DetectModules(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"services/api", "web"}, modules)
+}
+
+func TestDetectModulesSingleProject(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "modules")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	writeFiles(t, dir, "go.mod", "frontend/package.json")
+
+	modules, err := buildpacks.DetectModules(dir)
+	require.NoError(t, err)
+	assert.Empty(t, modules, "a single module should not be a monorepo")
+
+	writeFiles(t, dir, "pom.xml", "core/pom.xml", "web/pom.xml")
+	modules, err = buildpacks.DetectModules(dir)
+	require.NoError(t, err)
+	assert.Empty(t, modules, "the modules of an aggregating build should not be detected")
+}
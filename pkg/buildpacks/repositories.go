@@ -0,0 +1,144 @@
+package buildpacks
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/jenkinsfile/gitresolver"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// RepositoriesKey the key of the build pack repositories in their ConfigMap
+const RepositoriesKey = "repositories.yaml"
+
+// Repository a Git repository of build packs pinned to a ref
+type Repository struct {
+	URL string `json:"url"`
+	// Ref the branch, tag or sha of the repository to use, defaults to master
+	Ref string `json:"ref,omitempty"`
+}
+
+// GetRef returns the ref of the repository defaulting to master
+func (r Repository) GetRef() string {
+	if r.Ref == "" {
+		return "master"
+	}
+	return r.Ref
+}
+
+// SameURL returns true if the repository has the given URL ignoring any .git suffix
+func (r Repository) SameURL(url string) bool {
+	return strings.TrimSuffix(r.URL, ".git") == strings.TrimSuffix(url, ".git")
+}
+
+// LoadRepositories loads the ordered build pack repositories of the team
+func LoadRepositories(kubeClient kubernetes.Interface, ns string) ([]Repository, error) {
+	cm, err := kubeClient.CoreV1().ConfigMaps(ns).Get(kube.ConfigMapJenkinsBuildPackRepositories, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "getting ConfigMap %s in namespace %s", kube.ConfigMapJenkinsBuildPackRepositories, ns)
+	}
+	repositories := []Repository{}
+	data := cm.Data[RepositoriesKey]
+	if data == "" {
+		return repositories, nil
+	}
+	err = yaml.Unmarshal([]byte(data), &repositories)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshalling the %s key of ConfigMap %s", RepositoriesKey, kube.ConfigMapJenkinsBuildPackRepositories)
+	}
+	return repositories, nil
+}
+
+// SaveRepositories saves the ordered build pack repositories of the team
+func SaveRepositories(kubeClient kubernetes.Interface, ns string, repositories []Repository) error {
+	data, err := yaml.Marshal(repositories)
+	if err != nil {
+		return errors.Wrap(err, "marshalling the build pack repositories")
+	}
+	_, err = kube.DefaultModifyConfigMap(kubeClient, ns, kube.ConfigMapJenkinsBuildPackRepositories, func(cm *corev1.ConfigMap) error {
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[RepositoriesKey] = string(data)
+		return nil
+	}, nil)
+	return err
+}
+
+// AddRepository appends the repository to the list or pins the ref of the repository with the same URL
+func AddRepository(repositories []Repository, repository Repository) []Repository {
+	for i, r := range repositories {
+		if r.SameURL(repository.URL) {
+			repositories[i].Ref = repository.Ref
+			return repositories
+		}
+	}
+	return append(repositories, repository)
+}
+
+// RemoveRepository removes the repository of the URL returning false if there is none
+func RemoveRepository(repositories []Repository, url string) ([]Repository, bool) {
+	for i, r := range repositories {
+		if r.SameURL(url) {
+			return append(repositories[:i], repositories[i+1:]...), true
+		}
+	}
+	return repositories, false
+}
+
+// SearchOrder returns the repositories in the order packs are resolved: the repositories of the team followed by
+// the default build pack repository unless the team has pinned it in the list
+func SearchOrder(repositories []Repository, defaultURL string, defaultRef string) []Repository {
+	answer := append([]Repository{}, repositories...)
+	if defaultURL == "" {
+		return answer
+	}
+	for _, r := range answer {
+		if r.SameURL(defaultURL) {
+			return answer
+		}
+	}
+	return append(answer, Repository{URL: defaultURL, Ref: defaultRef})
+}
+
+// InitRepositories clones or updates the repositories at their refs returning their packs dirs in the same order
+func InitRepositories(gitter gits.Gitter, repositories []Repository) ([]string, error) {
+	if len(repositories) == 0 {
+		return nil, errors.New("no build pack repositories configured")
+	}
+	answer := []string{}
+	for _, r := range repositories {
+		dir, err := gitresolver.InitBuildPack(gitter, r.URL, r.GetRef())
+		if err != nil {
+			return nil, errors.Wrapf(err, "initialising build pack repository %s at %s", r.URL, r.GetRef())
+		}
+		answer = append(answer, dir)
+	}
+	return answer, nil
+}
+
+// FindPack returns the dir of the pack in the first of the packs dirs containing it or an empty string if none does
+func FindPack(packsDirs []string, pack string) (string, error) {
+	for _, packsDir := range packsDirs {
+		dir := filepath.Join(packsDir, pack)
+		exists, err := util.DirExists(dir)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return dir, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,68 @@
+// +build unit
+
+package buildpacks_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/buildpacks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const defaultPacksURL = "https://github.com/jenkins-x-buildpacks/jenkins-x-kubernetes.git"
+
+func TestEditRepositories(t *testing.T) {
+	t.Parallel()
+	repositories := buildpacks.AddRepository(nil, buildpacks.Repository{URL: "https://github.com/myorg/packs.git", Ref: "v1.0.0"})
+	repositories = buildpacks.AddRepository(repositories, buildpacks.Repository{URL: "https://github.com/otherorg/packs.git"})
+	repositories = buildpacks.AddRepository(repositories, buildpacks.Repository{URL: "https://github.com/myorg/packs", Ref: "v1.1.0"})
+	assert.Equal(t, []buildpacks.Repository{
+		{URL: "https://github.com/myorg/packs.git", Ref: "v1.1.0"},
+		{URL: "https://github.com/otherorg/packs.git"},
+	}, repositories, "adding an existing repository should only pin its ref")
+	assert.Equal(t, "master", repositories[1].GetRef())
+
+	repositories, removed := buildpacks.RemoveRepository(repositories, "https://github.com/otherorg/packs")
+	assert.True(t, removed)
+	assert.Len(t, repositories, 1)
+	_, removed = buildpacks.RemoveRepository(repositories, "https://github.com/unknown/packs.git")
+	assert.False(t, removed)
+}
+
+func TestRepositoriesSearchOrder(t *testing.T) {
+	t.Parallel()
+	custom := buildpacks.Repository{URL: "https://github.com/myorg/packs.git", Ref: "v1.0.0"}
+
+	assert.Equal(t, []buildpacks.Repository{{URL: defaultPacksURL, Ref: "master"}},
+		buildpacks.SearchOrder(nil, defaultPacksURL, "master"))
+	assert.Equal(t, []buildpacks.Repository{custom, {URL: defaultPacksURL, Ref: "master"}},
+		buildpacks.SearchOrder([]buildpacks.Repository{custom}, defaultPacksURL, "master"))
+
+	pinnedDefault := buildpacks.Repository{URL: defaultPacksURL, Ref: "v2.0.0"}
+	assert.Equal(t, []buildpacks.Repository{pinnedDefault, custom},
+		buildpacks.SearchOrder([]buildpacks.Repository{pinnedDefault, custom}, defaultPacksURL, "master"),
+		"the position and ref of the default repository in the list should be used")
+}
+
+func TestSaveAndLoadRepositories(t *testing.T) {
+	t.Parallel()
+	kubeClient := fake.NewSimpleClientset()
+	ns := "jx"
+
+	repositories, err := buildpacks.LoadRepositories(kubeClient, ns)
+	require.NoError(t, err)
+	assert.Empty(t, repositories)
+
+	expected := []buildpacks.Repository{
+		{URL: "https://github.com/myorg/packs.git", Ref: "v1.0.0"},
+		{URL: defaultPacksURL},
+	}
+	err = buildpacks.SaveRepositories(kubeClient, ns, expected)
+	require.NoError(t, err)
+
+	repositories, err = buildpacks.LoadRepositories(kubeClient, ns)
+	require.NoError(t, err)
+	assert.Equal(t, expected, repositories)
+}
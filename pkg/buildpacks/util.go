@@ -7,8 +7,8 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/jenkinsfile"
 )
 
-// CopyBuildPack copies the build pack from the source dir to the destination dir
-func CopyBuildPack(dest, src string) error {
+// CopyBuildPack copies the build pack from the source dir to the destination dir excluding the given files
+func CopyBuildPack(dest, src string, excludes ...string) error {
 	// first do some validation that we are copying from a valid pack directory
 	p, err := pack.FromDir(src)
 	if err != nil {
@@ -16,7 +16,7 @@ func CopyBuildPack(dest, src string) error {
 	}
 
 	// lets remove any files we think should be zapped
-	for _, file := range []string{jenkinsfile.PipelineConfigFileName, jenkinsfile.PipelineTemplateFileName} {
+	for _, file := range append([]string{jenkinsfile.PipelineConfigFileName, jenkinsfile.PipelineTemplateFileName}, excludes...) {
 		delete(p.Files, file)
 	}
 	return p.SaveDir(dest)
@@ -10,11 +10,9 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"k8s.io/client-go/kubernetes"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
-	"github.com/aws/aws-sdk-go/service/ecr"
 	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/registries"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 )
 
@@ -72,60 +70,27 @@ func GetRegionFromContainerRegistryHost(kubeClient kubernetes.Interface, namespa
 }
 
 // LazyCreateRegistry lazily creates the ECR registry if it does not already exist
+//
+// Deprecated: use EnsureRepository instead
 func LazyCreateRegistry(kube kubernetes.Interface, namespace string, region string, dockerRegistry string, orgName string, appName string) error {
+	return EnsureRepository(kube, namespace, region, dockerRegistry, orgName, appName)
+}
+
+// EnsureRepository creates the ECR repository of the application image via the ECR registry if it does not already
+// exist. If no region is specified it is resolved from the registry host
+func EnsureRepository(kube kubernetes.Interface, namespace string, region string, dockerRegistry string, orgName string, appName string) error {
 	// strip any tag/version from the app name
-	idx := strings.Index(appName, ":")
-	if idx > 0 {
-		appName = appName[0:idx]
-	}
-	repoName := appName
+	repoName := strings.Split(appName, ":")[0]
 	if orgName != "" {
-		repoName = orgName + "/" + appName
+		repoName = orgName + "/" + repoName
 	}
-	repoName = strings.ToLower(repoName)
 	log.Logger().Infof("Let's ensure that we have an ECR repository for the Docker image %s", util.ColorInfo(repoName))
 	if region == "" {
 		region = GetRegionFromContainerRegistryHost(kube, namespace, dockerRegistry)
 	}
-	sess, err := session.NewAwsSession("", region)
+	registry, err := registries.NewRegistry(registries.Options{Kind: registries.KindECR, Host: dockerRegistry, Region: region})
 	if err != nil {
 		return err
 	}
-	svc := ecr.New(sess)
-	repoInput := &ecr.DescribeRepositoriesInput{
-		RepositoryNames: []*string{
-			aws.String(repoName),
-		},
-	}
-	result, err := svc.DescribeRepositories(repoInput)
-	if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != ecr.ErrCodeRepositoryNotFoundException {
-		return err
-	}
-	for _, repo := range result.Repositories {
-		name := repo.String()
-		log.Logger().Infof("Found repository: %s", name)
-		if name == repoName {
-			return nil
-		}
-	}
-	createRepoInput := &ecr.CreateRepositoryInput{
-		RepositoryName: aws.String(repoName),
-	}
-	createResult, err := svc.CreateRepository(createRepoInput)
-	if err != nil {
-		return fmt.Errorf("Failed to create the ECR repository for %s due to: %s", repoName, err)
-	}
-	repo := createResult.Repository
-	if repo != nil {
-		u := repo.RepositoryUri
-		if u != nil {
-			if !strings.HasPrefix(*u, dockerRegistry) {
-				log.Logger().Warnf("Created ECR repository (%s) doesn't match registry configured for team (%s)",
-					util.ColorInfo(*u), util.ColorInfo(dockerRegistry))
-			} else {
-				log.Logger().Infof("Created ECR repository: %s", util.ColorInfo(*u))
-			}
-		}
-	}
-	return nil
+	return registry.CreateRepository(repoName)
 }
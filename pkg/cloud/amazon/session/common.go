@@ -38,7 +38,7 @@ func NewAwsSession(profileOption string, regionOption string) (*session.Session,
 		awsSession.Config.Region = aws.String(DefaultRegion)
 	}
 
-	return awsSession, nil
+	return TraceRequests(awsSession), nil
 }
 
 func NewAwsSessionWithoutOptions() (*session.Session, error) {
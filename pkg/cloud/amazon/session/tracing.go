@@ -0,0 +1,44 @@
+package session
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/jenkins-x/jx/v2/pkg/tracing"
+)
+
+// requestSpanKey the context key of the tracing span of an AWS API request
+type requestSpanKey struct{}
+
+// TraceRequests traces each AWS API request made with the session, including its retries, as a span when tracing is
+// enabled. It returns the session to allow chaining
+func TraceRequests(sess *session.Session) *session.Session {
+	sess.Handlers.Validate.PushFrontNamed(request.NamedHandler{
+		Name: "jx.tracing.StartSpan",
+		Fn: func(r *request.Request) {
+			if r.Context().Value(requestSpanKey{}) != nil {
+				return
+			}
+			name := "aws " + r.ClientInfo.ServiceName
+			if r.Operation != nil {
+				name += " " + r.Operation.Name
+			}
+			ctx, span := tracing.StartSpan(r.Context(), name,
+				tracing.String("cloud.provider", "aws"),
+				tracing.String("aws.service", r.ClientInfo.ServiceName))
+			if span != nil {
+				r.SetContext(context.WithValue(ctx, requestSpanKey{}, span))
+			}
+		},
+	})
+	sess.Handlers.Complete.PushBackNamed(request.NamedHandler{
+		Name: "jx.tracing.EndSpan",
+		Fn: func(r *request.Request) {
+			if span, ok := r.Context().Value(requestSpanKey{}).(*tracing.Span); ok {
+				span.End(r.Error)
+			}
+		},
+	})
+	return sess
+}
@@ -0,0 +1,51 @@
+// +build unit
+
+package session_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	awssession "github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/jenkins-x/jx/v2/pkg/cloud/amazon/session"
+	"github.com/jenkins-x/jx/v2/pkg/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceRequests(t *testing.T) {
+	exported := ""
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		exported = string(data)
+	}))
+	defer collector.Close()
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"clusters":["jx"]}`))
+	}))
+	defer api.Close()
+
+	tracing.Configure(tracing.Config{Endpoint: collector.URL, ServiceName: tracing.DefaultServiceName})
+	defer tracing.Configure(tracing.Config{})
+
+	sess, err := awssession.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"),
+		Endpoint:    aws.String(api.URL),
+		Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+	})
+	require.NoError(t, err)
+	result, err := eks.New(session.TraceRequests(sess)).ListClusters(&eks.ListClustersInput{})
+	require.NoError(t, err)
+	assert.Equal(t, "jx", aws.StringValue(result.Clusters[0]))
+
+	require.NoError(t, tracing.Flush())
+	assert.Equal(t, 1, strings.Count(exported, `"spanId"`), "a single span should be exported")
+	assert.Contains(t, exported, `"name":"aws eks ListClusters"`)
+}
@@ -19,6 +19,7 @@ import (
 	goformation "github.com/awslabs/goformation/cloudformation"
 	"github.com/awslabs/goformation/cloudformation/resources"
 	"github.com/jenkins-x/jx-logging/pkg/log"
+	awssession "github.com/jenkins-x/jx/v2/pkg/cloud/amazon/session"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/pkg/errors"
 )
@@ -218,9 +219,9 @@ func setProperIntrinsics(yamlTemplate string) string {
 
 func getKmsID(kmsOutputName string, stackName string) (*string, error) {
 	log.Logger().Debugf("Retrieving the vault kms id")
-	sess := session.Must(session.NewSessionWithOptions(session.Options{
+	sess := awssession.TraceRequests(session.Must(session.NewSessionWithOptions(session.Options{
 		SharedConfigState: session.SharedConfigEnable,
-	}))
+	})))
 
 	svc := cloudformation.New(sess)
 
@@ -452,9 +453,9 @@ func createIamUserPolicy(username string, depends []string) resources.AWSIAMPoli
 
 func runCloudFormationTemplate(templateBody *string, stackName *string, parameters []*cloudformation.Parameter) error {
 
-	sess := session.Must(session.NewSessionWithOptions(session.Options{
+	sess := awssession.TraceRequests(session.Must(session.NewSessionWithOptions(session.Options{
 		SharedConfigState: session.SharedConfigEnable,
-	}))
+	})))
 
 	// Create CloudFormation client in region
 	svc := cloudformation.New(sess)
@@ -488,9 +489,9 @@ func runCloudFormationTemplate(templateBody *string, stackName *string, paramete
 }
 
 func extractVaultStackOutputs(stackName string) (*StackOutputs, error) {
-	sess := session.Must(session.NewSessionWithOptions(session.Options{
+	sess := awssession.TraceRequests(session.Must(session.NewSessionWithOptions(session.Options{
 		SharedConfigState: session.SharedConfigEnable,
-	}))
+	})))
 
 	// Create CloudFormation client in region
 	svc := cloudformation.New(sess)
@@ -529,6 +530,7 @@ func createAccessKey(username string) (*string, *string, error) {
 	if err != nil {
 		return nil, nil, err
 	}
+	awssession.TraceRequests(sess)
 	svc := iam.New(sess)
 	input := &iam.CreateAccessKeyInput{
 		UserName: aws.String(username),
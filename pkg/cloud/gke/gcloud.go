@@ -382,6 +382,50 @@ func updateManagedZoneRecordTTL(projectID string, domain string, managedZone str
 	return nil
 }
 
+// DeleteDNSRecordSets deletes the A, CNAME and TXT record-sets of the host names in the managed zone of the domain.
+// The TXT record-sets are the ownership records external-dns creates along with the records of an ingress
+func DeleteDNSRecordSets(projectID string, domain string, hosts []string) error {
+	managedZone, err := getManagedZoneName(projectID, domain)
+	if err != nil {
+		return errors.Wrap(err, "unable to determine the managed zone of the domain")
+	}
+	if managedZone == "" {
+		log.Logger().Infof("Managed Zone doesn't exist for %s domain.", domain)
+		return nil
+	}
+	for _, host := range hosts {
+		for _, dnsType := range []string{"A", "CNAME", "TXT"} {
+			var record recordSet
+			record.Name = addDomainSuffix(host)
+			record.Type = dnsType
+			found, err := getManagedZoneRecordSet(projectID, managedZone, record)
+			if err != nil {
+				return errors.Wrapf(err, "when retrieving the '%s' record-set of type '%s'", host, dnsType)
+			}
+			if found.Name == "" {
+				continue
+			}
+			args := []string{"dns",
+				"record-sets",
+				fmt.Sprintf("--project=%s", projectID),
+				"delete",
+				found.Name,
+				fmt.Sprintf("--type=%s", dnsType),
+				fmt.Sprintf("--zone=%s", managedZone),
+			}
+			cmd := util.Command{
+				Name: "gcloud",
+				Args: args,
+			}
+			_, err = cmd.RunWithoutRetry()
+			if err != nil {
+				return errors.Wrapf(err, "executing gcloud dns record-sets delete command for '%s' of type '%s'", host, dnsType)
+			}
+		}
+	}
+	return nil
+}
+
 // ClusterZone retrives the zone of GKE cluster description
 func (g *GCloud) ClusterZone(cluster string) (string, error) {
 	args := []string{"container",
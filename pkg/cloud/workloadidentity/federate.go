@@ -0,0 +1,177 @@
+package workloadidentity
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cloud"
+	"github.com/jenkins-x/jx/v2/pkg/cloud/amazon"
+	"github.com/jenkins-x/jx/v2/pkg/cloud/gke"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+var (
+	// EKSPolicyARNs the policies attached to the IAM role of the pipelines so they can push images and artifacts
+	EKSPolicyARNs = []string{
+		"arn:aws:iam::aws:policy/AmazonEC2ContainerRegistryPowerUser",
+		"arn:aws:iam::aws:policy/AmazonS3FullAccess",
+	}
+
+	// AKSRegistryRole the role assigned to the managed identity of the pipelines on the container registry
+	AKSRegistryRole = "AcrPush"
+)
+
+// Federate creates the cloud identity of the pipelines if it does not exist, grants it access to the registries
+// and buckets of the cluster and trusts the tokens of the given kubernetes service account so that no key is needed.
+// The returned binding still has to be applied to the kubernetes service account
+func Federate(requirements *config.RequirementsConfig, ns string, serviceAccount string) (*Binding, error) {
+	binding := &Binding{
+		Provider:       requirements.Cluster.Provider,
+		Namespace:      ns,
+		ServiceAccount: serviceAccount,
+	}
+	identityName := IdentityName(requirements)
+	var err error
+	switch binding.Provider {
+	case cloud.GKE:
+		binding.Identity, err = federateGKE(requirements, identityName, binding)
+	case cloud.EKS:
+		binding.Identity, err = federateEKS(requirements, identityName, binding)
+	case cloud.AKS:
+		binding.Identity, err = federateAKS(requirements, identityName, binding)
+	default:
+		return nil, fmt.Errorf("workload identity is not supported for provider %s", binding.Provider)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "federating service account %s with %s", serviceAccount, identityName)
+	}
+	return binding, nil
+}
+
+// GKEMember returns the IAM member of a kubernetes service account for the workload identity pool of the project
+func GKEMember(projectID string, ns string, serviceAccount string) string {
+	return fmt.Sprintf("serviceAccount:%s.svc.id.goog[%s/%s]", projectID, ns, serviceAccount)
+}
+
+// EKSRoleARN returns the ARN of the IAM role with the given name
+func EKSRoleARN(accountID string, roleName string) string {
+	return fmt.Sprintf("arn:aws:iam::%s:role/%s", accountID, roleName)
+}
+
+func federateGKE(requirements *config.RequirementsConfig, name string, binding *Binding) (string, error) {
+	projectID := requirements.Cluster.ProjectID
+	if projectID == "" {
+		return "", errors.New("no project configured in the requirements")
+	}
+	email := fmt.Sprintf("%s@%s.iam.gserviceaccount.com", name, projectID)
+	gcloud := &gke.GCloud{}
+	if !gcloud.FindServiceAccount(name, projectID) {
+		log.Logger().Infof("Creating service account %s without keys", util.ColorInfo(name))
+		_, err := runCommand("gcloud", "iam", "service-accounts", "create", name, "--project", projectID, "--display-name", name)
+		if err != nil {
+			return "", err
+		}
+		for _, role := range gke.KanikoServiceAccountRoles {
+			log.Logger().Infof("Assigning role %s", role)
+			_, err = runCommand("gcloud", "projects", "add-iam-policy-binding", projectID, "--member", "serviceAccount:"+email, "--role", role, "--project", projectID)
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+	_, err := runCommand("gcloud", "iam", "service-accounts", "add-iam-policy-binding", email,
+		"--role", "roles/iam.workloadIdentityUser",
+		"--member", GKEMember(projectID, binding.Namespace, binding.ServiceAccount),
+		"--project", projectID)
+	if err != nil {
+		return "", err
+	}
+	return email, nil
+}
+
+func federateEKS(requirements *config.RequirementsConfig, name string, binding *Binding) (string, error) {
+	accountID, _, err := amazon.GetAccountIDAndRegion("", requirements.Cluster.Region)
+	if err != nil {
+		return "", errors.Wrap(err, "getting the AWS account ID")
+	}
+	err = amazon.EnableIRSASupportInCluster(requirements)
+	if err != nil {
+		return "", err
+	}
+	args := []string{"create", "iamserviceaccount",
+		"--cluster", requirements.Cluster.ClusterName,
+		"--region", requirements.Cluster.Region,
+		"--namespace", binding.Namespace,
+		"--name", binding.ServiceAccount,
+		"--role-name", name,
+		"--override-existing-serviceaccounts",
+		"--approve"}
+	for _, policy := range EKSPolicyARNs {
+		args = append(args, "--attach-policy-arn", policy)
+	}
+	_, err = runCommand("eksctl", args...)
+	if err != nil {
+		return "", err
+	}
+	return EKSRoleARN(accountID, name), nil
+}
+
+func federateAKS(requirements *config.RequirementsConfig, name string, binding *Binding) (string, error) {
+	resourceGroup := ""
+	if requirements.Cluster.AzureConfig != nil {
+		resourceGroup = requirements.Cluster.AzureConfig.ResourceGroup
+	}
+	if resourceGroup == "" {
+		return "", errors.New("no azure resource group configured in the requirements")
+	}
+	issuer, err := runCommand("az", "aks", "show", "--name", requirements.Cluster.ClusterName, "--resource-group", resourceGroup,
+		"--query", "oidcIssuerProfile.issuerUrl", "--output", "tsv")
+	if err != nil {
+		return "", err
+	}
+	if issuer == "" {
+		return "", fmt.Errorf("the OIDC issuer is not enabled on cluster %s, enable it with 'az aks update --enable-oidc-issuer --enable-workload-identity'", requirements.Cluster.ClusterName)
+	}
+	clientID, err := runCommand("az", "identity", "create", "--name", name, "--resource-group", resourceGroup, "--query", "clientId", "--output", "tsv")
+	if err != nil {
+		return "", err
+	}
+	_, err = runCommand("az", "identity", "federated-credential", "create",
+		"--name", fmt.Sprintf("%s-%s", binding.Namespace, binding.ServiceAccount),
+		"--identity-name", name,
+		"--resource-group", resourceGroup,
+		"--issuer", issuer,
+		"--subject", binding.Subject(),
+		"--audiences", "api://AzureADTokenExchange")
+	if err != nil {
+		return "", err
+	}
+	registry := requirements.Cluster.Registry
+	if strings.HasSuffix(registry, ".azurecr.io") {
+		registryID, err := runCommand("az", "acr", "show", "--name", strings.TrimSuffix(registry, ".azurecr.io"), "--query", "id", "--output", "tsv")
+		if err != nil {
+			return "", err
+		}
+		_, err = runCommand("az", "role", "assignment", "create", "--assignee", clientID, "--role", AKSRegistryRole, "--scope", registryID)
+		if err != nil {
+			return "", err
+		}
+	}
+	return clientID, nil
+}
+
+func runCommand(name string, args ...string) (string, error) {
+	log.Logger().Debugf("executing \"%s %s\"", util.ColorInfo(name), util.ColorInfo(strings.Join(args, " ")))
+	cmd := util.Command{
+		Name: name,
+		Args: args,
+	}
+	output, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return "", errors.Wrapf(err, "running %s %s", name, strings.Join(args, " "))
+	}
+	return strings.TrimSpace(output), nil
+}
@@ -0,0 +1,163 @@
+package workloadidentity
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cloud"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// AnnotationGKEServiceAccount the annotation binding a kubernetes service account to a GCP service account
+	AnnotationGKEServiceAccount = "iam.gke.io/gcp-service-account"
+	// AnnotationEKSRoleARN the annotation binding a kubernetes service account to an AWS IAM role
+	AnnotationEKSRoleARN = "eks.amazonaws.com/role-arn"
+	// AnnotationAzureClientID the annotation binding a kubernetes service account to an Azure managed identity
+	AnnotationAzureClientID = "azure.workload.identity/client-id"
+	// LabelAzureUse the label enabling the Azure workload identity webhook for a service account
+	LabelAzureUse = "azure.workload.identity/use"
+)
+
+// Binding federates a kubernetes service account with a cloud identity so that the pods running as the
+// service account can authenticate against the cloud registries and buckets without long-lived keys
+type Binding struct {
+	// Provider the kubernetes provider of the cluster (gke, eks or aks)
+	Provider string
+	// Namespace the namespace of the kubernetes service account
+	Namespace string
+	// ServiceAccount the name of the kubernetes service account
+	ServiceAccount string
+	// Identity the cloud identity: the GCP service account e-mail, the AWS IAM role ARN or the Azure managed identity client ID
+	Identity string
+}
+
+// Supported returns true if workload identity federation is supported for the given provider
+func Supported(provider string) bool {
+	switch provider {
+	case cloud.GKE, cloud.EKS, cloud.AKS:
+		return true
+	default:
+		return false
+	}
+}
+
+// IdentityName returns the name of the cloud identity the pipelines of the cluster are federated with
+func IdentityName(requirements *config.RequirementsConfig) string {
+	name := requirements.Cluster.KanikoSAName
+	if name == "" {
+		name = fmt.Sprintf("%s-ko", requirements.Cluster.ClusterName)
+	}
+	if requirements.Cluster.Provider == cloud.GKE {
+		return naming.ToValidGCPServiceAccount(name)
+	}
+	return naming.ToValidName(name)
+}
+
+// Subject returns the subject of the tokens kubernetes issues for the service account of the binding
+func (b *Binding) Subject() string {
+	return fmt.Sprintf("system:serviceaccount:%s:%s", b.Namespace, b.ServiceAccount)
+}
+
+// Annotations returns the annotations the kubernetes service account needs to assume the cloud identity
+func (b *Binding) Annotations() map[string]string {
+	switch b.Provider {
+	case cloud.GKE:
+		return map[string]string{AnnotationGKEServiceAccount: b.Identity}
+	case cloud.EKS:
+		return map[string]string{AnnotationEKSRoleARN: b.Identity}
+	case cloud.AKS:
+		return map[string]string{AnnotationAzureClientID: b.Identity}
+	default:
+		return nil
+	}
+}
+
+// Labels returns the labels the kubernetes service account needs to assume the cloud identity
+func (b *Binding) Labels() map[string]string {
+	if b.Provider == cloud.AKS {
+		return map[string]string{LabelAzureUse: "true"}
+	}
+	return nil
+}
+
+// Apply annotates the kubernetes service account of the binding with the cloud identity, lazily creating it if required
+func (b *Binding) Apply(kubeClient kubernetes.Interface) error {
+	if !Supported(b.Provider) {
+		return fmt.Errorf("workload identity is not supported for provider %s", b.Provider)
+	}
+	if b.Identity == "" {
+		return fmt.Errorf("no cloud identity for service account %s in namespace %s", b.ServiceAccount, b.Namespace)
+	}
+	serviceAccounts := kubeClient.CoreV1().ServiceAccounts(b.Namespace)
+	sa, err := serviceAccounts.Get(b.ServiceAccount, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "getting service account %s in namespace %s", b.ServiceAccount, b.Namespace)
+		}
+		sa = &corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        b.ServiceAccount,
+				Namespace:   b.Namespace,
+				Annotations: b.Annotations(),
+				Labels:      b.Labels(),
+			},
+		}
+		_, err = serviceAccounts.Create(sa)
+		if err != nil {
+			return errors.Wrapf(err, "creating service account %s in namespace %s", b.ServiceAccount, b.Namespace)
+		}
+		log.Logger().Infof("Created service account %s federated with %s", util.ColorInfo(b.ServiceAccount), util.ColorInfo(b.Identity))
+		return nil
+	}
+	if sa.Annotations == nil {
+		sa.Annotations = map[string]string{}
+	}
+	for k, v := range b.Annotations() {
+		sa.Annotations[k] = v
+	}
+	if sa.Labels == nil {
+		sa.Labels = map[string]string{}
+	}
+	for k, v := range b.Labels() {
+		sa.Labels[k] = v
+	}
+	_, err = serviceAccounts.Update(sa)
+	if err != nil {
+		return errors.Wrapf(err, "updating service account %s in namespace %s", b.ServiceAccount, b.Namespace)
+	}
+	log.Logger().Infof("Federated service account %s with %s", util.ColorInfo(b.ServiceAccount), util.ColorInfo(b.Identity))
+	return nil
+}
+
+// IsFederated returns true if the service account is bound to a cloud identity via workload identity federation
+func IsFederated(sa *corev1.ServiceAccount) bool {
+	if sa == nil {
+		return false
+	}
+	for _, key := range []string{AnnotationGKEServiceAccount, AnnotationEKSRoleARN, AnnotationAzureClientID} {
+		if sa.Annotations[key] != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsServiceAccountFederated returns true if the given service account exists and is bound to a cloud identity
+func IsServiceAccountFederated(kubeClient kubernetes.Interface, ns string, name string) (bool, error) {
+	sa, err := kubeClient.CoreV1().ServiceAccounts(ns).Get(name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "getting service account %s in namespace %s", name, ns)
+	}
+	return IsFederated(sa), nil
+}
@@ -0,0 +1,81 @@
+// +build unit
+
+package workloadidentity_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/cloud"
+	"github.com/jenkins-x/jx/v2/pkg/cloud/workloadidentity"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestIdentityName(t *testing.T) {
+	t.Parallel()
+
+	requirements := &config.RequirementsConfig{}
+	requirements.Cluster.Provider = cloud.GKE
+	requirements.Cluster.ClusterName = "My_Cluster"
+	assert.Equal(t, "my-cluster-ko", workloadidentity.IdentityName(requirements))
+
+	requirements.Cluster.KanikoSAName = "pipelines"
+	assert.Equal(t, "pipelines", workloadidentity.IdentityName(requirements))
+}
+
+func TestBindingAnnotations(t *testing.T) {
+	t.Parallel()
+
+	binding := &workloadidentity.Binding{Provider: cloud.GKE, Namespace: "jx", ServiceAccount: "tekton-bot", Identity: "ko@acme.iam.gserviceaccount.com"}
+	assert.Equal(t, map[string]string{workloadidentity.AnnotationGKEServiceAccount: "ko@acme.iam.gserviceaccount.com"}, binding.Annotations())
+	assert.Empty(t, binding.Labels())
+	assert.Equal(t, "system:serviceaccount:jx:tekton-bot", binding.Subject())
+
+	binding = &workloadidentity.Binding{Provider: cloud.AKS, Identity: "1234"}
+	assert.Equal(t, map[string]string{workloadidentity.AnnotationAzureClientID: "1234"}, binding.Annotations())
+	assert.Equal(t, map[string]string{workloadidentity.LabelAzureUse: "true"}, binding.Labels())
+
+	assert.Equal(t, "serviceAccount:acme.svc.id.goog[jx/tekton-bot]", workloadidentity.GKEMember("acme", "jx", "tekton-bot"))
+	assert.Equal(t, "arn:aws:iam::123456789012:role/ko", workloadidentity.EKSRoleARN("123456789012", "ko"))
+}
+
+func TestBindingApply(t *testing.T) {
+	t.Parallel()
+
+	kubeClient := fake.NewSimpleClientset(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "tekton-bot",
+			Namespace:   "jx",
+			Annotations: map[string]string{"owner": "jx"},
+		},
+	})
+
+	federated, err := workloadidentity.IsServiceAccountFederated(kubeClient, "jx", "tekton-bot")
+	require.NoError(t, err)
+	assert.False(t, federated)
+
+	binding := &workloadidentity.Binding{Provider: cloud.EKS, Namespace: "jx", ServiceAccount: "tekton-bot", Identity: "arn:aws:iam::123456789012:role/ko"}
+	require.NoError(t, binding.Apply(kubeClient))
+
+	sa, err := kubeClient.CoreV1().ServiceAccounts("jx").Get("tekton-bot", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "jx", sa.Annotations["owner"])
+	assert.Equal(t, "arn:aws:iam::123456789012:role/ko", sa.Annotations[workloadidentity.AnnotationEKSRoleARN])
+
+	federated, err = workloadidentity.IsServiceAccountFederated(kubeClient, "jx", "tekton-bot")
+	require.NoError(t, err)
+	assert.True(t, federated)
+
+	binding = &workloadidentity.Binding{Provider: cloud.GKE, Namespace: "staging", ServiceAccount: "tekton-bot", Identity: "ko@acme.iam.gserviceaccount.com"}
+	require.NoError(t, binding.Apply(kubeClient))
+	federated, err = workloadidentity.IsServiceAccountFederated(kubeClient, "staging", "tekton-bot")
+	require.NoError(t, err)
+	assert.True(t, federated)
+
+	assert.Error(t, (&workloadidentity.Binding{Provider: cloud.KUBERNETES, Identity: "x"}).Apply(kubeClient))
+	assert.Error(t, (&workloadidentity.Binding{Provider: cloud.GKE}).Apply(kubeClient))
+}
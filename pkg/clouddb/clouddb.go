@@ -0,0 +1,207 @@
+package clouddb
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/cloud"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// EnginePostgres a managed PostgreSQL database
+	EnginePostgres = "postgres"
+	// EngineMySQL a managed MySQL database
+	EngineMySQL = "mysql"
+
+	// StatusProvisioning the database is being provisioned
+	StatusProvisioning = "Provisioning"
+	// StatusReady the database is provisioned and its credentials are in its Secret
+	StatusReady = "Ready"
+	// StatusFailed the provisioning of the database failed
+	StatusFailed = "Failed"
+
+	// SecretSuffix the suffix of the name of the Secret of the credentials of a database
+	SecretSuffix = "-clouddb"
+)
+
+// Engines the engines of the databases which can be provisioned
+var Engines = []string{EnginePostgres, EngineMySQL}
+
+// Database the managed database of an environment
+type Database struct {
+	// Name the name of the database instance
+	Name string `json:"-"`
+	// Provider the kubernetes provider whose cloud provisions the database
+	Provider string `json:"provider"`
+	// Engine the engine of the database: postgres or mysql
+	Engine string `json:"engine"`
+	// Version the version of the engine, defaults to the version of the provider
+	Version string `json:"version,omitempty"`
+	// Tier the machine tier or instance class of the database, defaults to a small one
+	Tier string `json:"tier,omitempty"`
+	// Region the cloud region of the database
+	Region string `json:"region,omitempty"`
+	// Project the GCP project of a Cloud SQL database
+	Project string `json:"project,omitempty"`
+	// ResourceGroup the Azure resource group of an Azure Database
+	ResourceGroup string `json:"resourceGroup,omitempty"`
+	// Database the name of the schema created in the instance
+	Database string `json:"database"`
+	// Username the name of the user of the apps
+	Username string `json:"username"`
+	// Environment the environment whose namespace gets the Secret of the credentials
+	Environment string `json:"environment"`
+	// Namespace the namespace of the Secret of the credentials
+	Namespace string `json:"namespace"`
+	// Secret the name of the Secret of the credentials
+	Secret string `json:"secret"`
+}
+
+// Credentials the connection details of a provisioned database
+type Credentials struct {
+	Host     string
+	Port     int
+	Database string
+	Username string
+	Password string
+}
+
+// Provisioner provisions the managed databases of a cloud
+type Provisioner interface {
+	// Provision creates the database returning the credentials of its user
+	Provision(db *Database, password string) (*Credentials, error)
+
+	// Delete deletes the database
+	Delete(db *Database) error
+}
+
+// SecretName returns the name of the Secret of the credentials of the database
+func SecretName(name string) string {
+	return name + SecretSuffix
+}
+
+// NewDatabase returns a database of the cloud of the requirements
+func NewDatabase(requirements *config.RequirementsConfig, name string, engine string) *Database {
+	db := &Database{
+		Name:     name,
+		Provider: requirements.Cluster.Provider,
+		Engine:   engine,
+		Region:   requirements.Cluster.Region,
+		Project:  requirements.Cluster.ProjectID,
+		Database: strings.Replace(name, "-", "_", -1),
+		Username: "app",
+		Secret:   SecretName(name),
+	}
+	if requirements.Cluster.AzureConfig != nil {
+		db.ResourceGroup = requirements.Cluster.AzureConfig.ResourceGroup
+	}
+	return db
+}
+
+// Validate validates the database
+func (d *Database) Validate() error {
+	if d.Name == "" {
+		return util.MissingArgument("name")
+	}
+	if util.StringArrayIndex(Engines, d.Engine) < 0 {
+		return util.InvalidOption("engine", d.Engine, Engines)
+	}
+	switch d.Provider {
+	case cloud.GKE:
+		if d.Project == "" {
+			return errors.New("no GCP project configured for the Cloud SQL database")
+		}
+	case cloud.EKS, cloud.AWS:
+		if d.Region == "" {
+			return errors.New("no AWS region configured for the RDS database")
+		}
+	case cloud.AKS:
+		if d.ResourceGroup == "" {
+			return errors.New("no Azure resource group configured for the Azure database")
+		}
+	}
+	if d.Namespace == "" {
+		return errors.Errorf("no namespace for the credentials of database %s", d.Name)
+	}
+	return nil
+}
+
+// NewProvisioner returns the provisioner of the databases of the kubernetes provider
+func NewProvisioner(provider string) (Provisioner, error) {
+	switch provider {
+	case cloud.GKE:
+		return &CloudSQL{Run: runCommand}, nil
+	case cloud.EKS, cloud.AWS:
+		return &RDS{Run: runCommand}, nil
+	case cloud.AKS:
+		return &AzureDatabase{Run: runCommand}, nil
+	default:
+		return nil, fmt.Errorf("managed databases are not supported for provider %s", provider)
+	}
+}
+
+// DefaultPort returns the default port of the engine
+func DefaultPort(engine string) int {
+	if engine == EngineMySQL {
+		return 3306
+	}
+	return 5432
+}
+
+// GeneratePassword generates a random password for the user of a database
+func GeneratePassword() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", errors.Wrap(err, "generating the password")
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// URL returns the connection URL of the database
+func (c *Credentials) URL(engine string) string {
+	u := url.URL{
+		Scheme: engine,
+		User:   url.UserPassword(c.Username, c.Password),
+		Host:   c.Host + ":" + strconv.Itoa(c.Port),
+		Path:   "/" + c.Database,
+	}
+	if engine == EnginePostgres {
+		u.Scheme = "postgresql"
+	}
+	return u.String()
+}
+
+// SecretData returns the data of the Secret of the credentials
+func (c *Credentials) SecretData(engine string) map[string][]byte {
+	return map[string][]byte{
+		"host":     []byte(c.Host),
+		"port":     []byte(strconv.Itoa(c.Port)),
+		"database": []byte(c.Database),
+		"username": []byte(c.Username),
+		"password": []byte(c.Password),
+		"url":      []byte(c.URL(engine)),
+	}
+}
+
+// CommandRunner runs a CLI returning its output
+type CommandRunner func(name string, args ...string) (string, error)
+
+func runCommand(name string, args ...string) (string, error) {
+	cmd := util.Command{
+		Name: name,
+		Args: args,
+	}
+	output, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return "", errors.Wrapf(err, "running %s %s", name, strings.Join(args, " "))
+	}
+	return strings.TrimSpace(output), nil
+}
@@ -0,0 +1,139 @@
+package clouddb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+)
+
+// CloudSQL provisions Cloud SQL databases on GCP via gcloud
+type CloudSQL struct {
+	Run CommandRunner
+}
+
+// RDS provisions RDS databases on AWS via the aws CLI
+type RDS struct {
+	Run CommandRunner
+}
+
+// AzureDatabase provisions Azure Database flexible servers via az
+type AzureDatabase struct {
+	Run CommandRunner
+}
+
+// Provision creates the Cloud SQL instance, its database and its user
+func (p *CloudSQL) Provision(db *Database, password string) (*Credentials, error) {
+	version := db.Version
+	if version == "" {
+		version = map[string]string{EnginePostgres: "POSTGRES_12", EngineMySQL: "MYSQL_8_0"}[db.Engine]
+	}
+	tier := db.Tier
+	if tier == "" {
+		tier = "db-f1-micro"
+	}
+	log.Logger().Infof("Creating the Cloud SQL instance %s, this can take several minutes", util.ColorInfo(db.Name))
+	args := []string{"sql", "instances", "create", db.Name, "--project", db.Project, "--database-version", version, "--tier", tier}
+	if db.Region != "" {
+		args = append(args, "--region", db.Region)
+	}
+	_, err := p.Run("gcloud", args...)
+	if err != nil {
+		return nil, err
+	}
+	_, err = p.Run("gcloud", "sql", "databases", "create", db.Database, "--instance", db.Name, "--project", db.Project)
+	if err != nil {
+		return nil, err
+	}
+	_, err = p.Run("gcloud", "sql", "users", "create", db.Username, "--instance", db.Name, "--project", db.Project, "--password", password)
+	if err != nil {
+		return nil, err
+	}
+	host, err := p.Run("gcloud", "sql", "instances", "describe", db.Name, "--project", db.Project, "--format", "value(ipAddresses[0].ipAddress)")
+	if err != nil {
+		return nil, err
+	}
+	return newCredentials(db, host, password), nil
+}
+
+// Delete deletes the Cloud SQL instance
+func (p *CloudSQL) Delete(db *Database) error {
+	_, err := p.Run("gcloud", "sql", "instances", "delete", db.Name, "--project", db.Project, "--quiet")
+	return err
+}
+
+// Provision creates the RDS instance and waits for it to be available
+func (p *RDS) Provision(db *Database, password string) (*Credentials, error) {
+	tier := db.Tier
+	if tier == "" {
+		tier = "db.t3.micro"
+	}
+	log.Logger().Infof("Creating the RDS instance %s, this can take several minutes", util.ColorInfo(db.Name))
+	args := []string{"rds", "create-db-instance", "--db-instance-identifier", db.Name, "--engine", db.Engine,
+		"--db-instance-class", tier, "--allocated-storage", "20", "--db-name", db.Database,
+		"--master-username", db.Username, "--master-user-password", password, "--region", db.Region}
+	if db.Version != "" {
+		args = append(args, "--engine-version", db.Version)
+	}
+	_, err := p.Run("aws", args...)
+	if err != nil {
+		return nil, err
+	}
+	_, err = p.Run("aws", "rds", "wait", "db-instance-available", "--db-instance-identifier", db.Name, "--region", db.Region)
+	if err != nil {
+		return nil, err
+	}
+	host, err := p.Run("aws", "rds", "describe-db-instances", "--db-instance-identifier", db.Name, "--region", db.Region,
+		"--query", "DBInstances[0].Endpoint.Address", "--output", "text")
+	if err != nil {
+		return nil, err
+	}
+	return newCredentials(db, host, password), nil
+}
+
+// Delete deletes the RDS instance without a final snapshot
+func (p *RDS) Delete(db *Database) error {
+	_, err := p.Run("aws", "rds", "delete-db-instance", "--db-instance-identifier", db.Name, "--skip-final-snapshot", "--region", db.Region)
+	return err
+}
+
+// Provision creates the flexible server and its database
+func (p *AzureDatabase) Provision(db *Database, password string) (*Credentials, error) {
+	tier := db.Tier
+	if tier == "" {
+		tier = "Standard_B1ms"
+	}
+	log.Logger().Infof("Creating the Azure Database %s, this can take several minutes", util.ColorInfo(db.Name))
+	args := []string{db.Engine, "flexible-server", "create", "--name", db.Name, "--resource-group", db.ResourceGroup,
+		"--admin-user", db.Username, "--admin-password", password, "--database-name", db.Database,
+		"--tier", "Burstable", "--sku-name", tier, "--yes"}
+	if db.Region != "" {
+		args = append(args, "--location", db.Region)
+	}
+	if db.Version != "" {
+		args = append(args, "--version", db.Version)
+	}
+	_, err := p.Run("az", args...)
+	if err != nil {
+		return nil, err
+	}
+	host := fmt.Sprintf("%s.%s.database.azure.com", db.Name, db.Engine)
+	return newCredentials(db, host, password), nil
+}
+
+// Delete deletes the flexible server
+func (p *AzureDatabase) Delete(db *Database) error {
+	_, err := p.Run("az", db.Engine, "flexible-server", "delete", "--name", db.Name, "--resource-group", db.ResourceGroup, "--yes")
+	return err
+}
+
+func newCredentials(db *Database, host string, password string) *Credentials {
+	return &Credentials{
+		Host:     strings.TrimSpace(host),
+		Port:     DefaultPort(db.Engine),
+		Database: db.Database,
+		Username: db.Username,
+		Password: password,
+	}
+}
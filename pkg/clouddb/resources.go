@@ -0,0 +1,161 @@
+package clouddb
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// LabelCloudDatabase the label of the Secrets of the credentials of the databases
+const LabelCloudDatabase = "jenkins.io/clouddatabase"
+
+// CloudDatabaseResource the resource of the CloudDatabases tracking the provisioned databases in the dev namespace
+var CloudDatabaseResource = schema.GroupVersionResource{Group: "jenkins.io", Version: "v1", Resource: "clouddatabases"}
+
+// Status the provisioning status of a database
+type Status struct {
+	Phase   string `json:"phase"`
+	Message string `json:"message,omitempty"`
+}
+
+// Save creates or updates the CloudDatabase of the database with the status in the namespace
+func Save(client dynamic.Interface, ns string, db *Database, status *Status) error {
+	spec, err := runtime.DefaultUnstructuredConverter.ToUnstructured(db)
+	if err != nil {
+		return errors.Wrapf(err, "converting database %s", db.Name)
+	}
+	statusMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(status)
+	if err != nil {
+		return errors.Wrapf(err, "converting the status of database %s", db.Name)
+	}
+	u := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": CloudDatabaseResource.GroupVersion().String(),
+			"kind":       "CloudDatabase",
+			"metadata": map[string]interface{}{
+				"name":      db.Name,
+				"namespace": ns,
+			},
+			"spec":   spec,
+			"status": statusMap,
+		},
+	}
+	resources := client.Resource(CloudDatabaseResource).Namespace(ns)
+	existing, err := resources.Get(db.Name, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "getting CloudDatabase %s in namespace %s", db.Name, ns)
+		}
+		_, err = resources.Create(u, metav1.CreateOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "creating CloudDatabase %s in namespace %s", db.Name, ns)
+		}
+		return nil
+	}
+	u.SetResourceVersion(existing.GetResourceVersion())
+	_, err = resources.Update(u, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "updating CloudDatabase %s in namespace %s", db.Name, ns)
+	}
+	return nil
+}
+
+// Get returns the database of the CloudDatabase in the namespace with its status or nil if it does not exist
+func Get(client dynamic.Interface, ns string, name string) (*Database, *Status, error) {
+	u, err := client.Resource(CloudDatabaseResource).Namespace(ns).Get(name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, errors.Wrapf(err, "getting CloudDatabase %s in namespace %s", name, ns)
+	}
+	return fromUnstructured(u)
+}
+
+// List returns the names of the CloudDatabases in the namespace
+func List(client dynamic.Interface, ns string) ([]string, error) {
+	list, err := client.Resource(CloudDatabaseResource).Namespace(ns).List(metav1.ListOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "listing the CloudDatabases in namespace %s", ns)
+	}
+	answer := []string{}
+	for _, u := range list.Items {
+		answer = append(answer, u.GetName())
+	}
+	sort.Strings(answer)
+	return answer, nil
+}
+
+// Remove deletes the CloudDatabase in the namespace
+func Remove(client dynamic.Interface, ns string, name string) error {
+	err := client.Resource(CloudDatabaseResource).Namespace(ns).Delete(name, &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "deleting CloudDatabase %s in namespace %s", name, ns)
+	}
+	return nil
+}
+
+// ApplySecret creates or updates the Secret of the credentials of the database in its namespace
+func ApplySecret(kubeClient kubernetes.Interface, db *Database, credentials *Credentials) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      db.Secret,
+			Namespace: db.Namespace,
+			Labels: map[string]string{
+				LabelCloudDatabase: db.Name,
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: credentials.SecretData(db.Engine),
+	}
+	secrets := kubeClient.CoreV1().Secrets(db.Namespace)
+	_, err := secrets.Create(secret)
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "creating Secret %s in namespace %s", db.Secret, db.Namespace)
+	}
+	_, err = secrets.Update(secret)
+	if err != nil {
+		return errors.Wrapf(err, "updating Secret %s in namespace %s", db.Secret, db.Namespace)
+	}
+	return nil
+}
+
+// DeleteSecret deletes the Secret of the credentials of the database
+func DeleteSecret(kubeClient kubernetes.Interface, db *Database) error {
+	err := kubeClient.CoreV1().Secrets(db.Namespace).Delete(db.Secret, &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "deleting Secret %s in namespace %s", db.Secret, db.Namespace)
+	}
+	return nil
+}
+
+func fromUnstructured(u *unstructured.Unstructured) (*Database, *Status, error) {
+	db := &Database{}
+	status := &Status{}
+	spec, _, _ := unstructured.NestedMap(u.Object, "spec")
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(spec, db)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "converting CloudDatabase %s", u.GetName())
+	}
+	statusMap, _, _ := unstructured.NestedMap(u.Object, "status")
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(statusMap, status)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "converting the status of CloudDatabase %s", u.GetName())
+	}
+	db.Name = u.GetName()
+	return db, status, nil
+}
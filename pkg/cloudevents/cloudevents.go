@@ -0,0 +1,316 @@
+package cloudevents
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/google/uuid"
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// SpecVersion the version of the CloudEvents specification of the events
+	SpecVersion = "1.0"
+	// ContentType the content type of events posted in the structured mode of the HTTP binding
+	ContentType = "application/cloudevents+json"
+
+	// ConfigMapName the name of the ConfigMap in the dev namespace containing the sinks
+	ConfigMapName = "jenkins-x-event-sinks"
+	// ConfigKey the key of the sinks in the ConfigMap
+	ConfigKey = "sinks.yaml"
+	// SecretKeyToken the key of the bearer token in the Secret of a sink
+	SecretKeyToken = "token"
+
+	// TypeAppCreated an application was created or imported
+	TypeAppCreated = "io.jenkins-x.app.created"
+	// TypeEnvironmentPromoted a version of an application was promoted to an environment
+	TypeEnvironmentPromoted = "io.jenkins-x.environment.promoted"
+	// TypePreviewDeleted a preview environment was deleted
+	TypePreviewDeleted = "io.jenkins-x.preview.deleted"
+
+	postTimeout = 10 * time.Second
+
+	// maxSentEvents the number of sent promotions remembered so that each promotion is only emitted once
+	maxSentEvents = 10000
+)
+
+// Types the types of events emitted to the sinks
+var Types = []string{TypeAppCreated, TypeEnvironmentPromoted, TypePreviewDeleted}
+
+// Event a CloudEvent in the JSON format
+type Event struct {
+	SpecVersion     string      `json:"specversion"`
+	ID              string      `json:"id"`
+	Source          string      `json:"source"`
+	Type            string      `json:"type"`
+	Subject         string      `json:"subject,omitempty"`
+	Time            time.Time   `json:"time"`
+	DataContentType string      `json:"datacontenttype,omitempty"`
+	Data            interface{} `json:"data,omitempty"`
+}
+
+// AppData the data of the app created events
+type AppData struct {
+	Name        string `json:"name"`
+	Owner       string `json:"owner"`
+	ProviderURL string `json:"providerURL,omitempty"`
+}
+
+// PromotionData the data of the environment promoted events
+type PromotionData struct {
+	Environment    string `json:"environment"`
+	Owner          string `json:"owner"`
+	Repository     string `json:"repository"`
+	Version        string `json:"version,omitempty"`
+	Status         string `json:"status"`
+	ApplicationURL string `json:"applicationURL,omitempty"`
+	PullRequestURL string `json:"pullRequestURL,omitempty"`
+	Pipeline       string `json:"pipeline"`
+}
+
+// PreviewData the data of the preview deleted events
+type PreviewData struct {
+	Name           string `json:"name"`
+	Namespace      string `json:"namespace,omitempty"`
+	Application    string `json:"application,omitempty"`
+	PullRequestURL string `json:"pullRequestURL,omitempty"`
+}
+
+// Config the sinks of a team
+type Config struct {
+	Sinks []Sink `json:"sinks,omitempty"`
+}
+
+// Sink an HTTP endpoint the events are posted to
+type Sink struct {
+	// Name the name of the sink
+	Name string `json:"name"`
+	// URL the URL the events are posted to
+	URL string `json:"url"`
+	// Types the types of events posted, defaults to all events
+	Types []string `json:"types,omitempty"`
+	// Secret the name of an optional Secret containing a bearer token in the token key
+	Secret string `json:"secret,omitempty"`
+}
+
+// NewEvent creates an event of the given type with a new ID
+func NewEvent(eventType string, source string, subject string, data interface{}) *Event {
+	return &Event{
+		SpecVersion:     SpecVersion,
+		ID:              uuid.New().String(),
+		Source:          source,
+		Type:            eventType,
+		Subject:         subject,
+		Time:            time.Now().UTC(),
+		DataContentType: "application/json",
+		Data:            data,
+	}
+}
+
+// LoadConfig loads the sinks from the ConfigMap in the namespace, returning an empty configuration if there is no
+// ConfigMap
+func LoadConfig(kubeClient kubernetes.Interface, ns string) (*Config, error) {
+	config := &Config{}
+	cm, err := kubeClient.CoreV1().ConfigMaps(ns).Get(ConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return config, nil
+		}
+		return config, errors.Wrapf(err, "getting ConfigMap %s in namespace %s", ConfigMapName, ns)
+	}
+	err = yaml.Unmarshal([]byte(cm.Data[ConfigKey]), config)
+	if err != nil {
+		return config, errors.Wrapf(err, "unmarshalling the %s key of ConfigMap %s", ConfigKey, ConfigMapName)
+	}
+	return config, nil
+}
+
+// SaveConfig saves the sinks to the ConfigMap in the namespace
+func SaveConfig(kubeClient kubernetes.Interface, ns string, config *Config) error {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return errors.Wrap(err, "marshalling the sinks")
+	}
+	_, err = kube.DefaultModifyConfigMap(kubeClient, ns, ConfigMapName, func(cm *corev1.ConfigMap) error {
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[ConfigKey] = string(data)
+		return nil
+	}, nil)
+	return err
+}
+
+// SetSink adds the sink replacing any sink with the same name
+func (c *Config) SetSink(s Sink) {
+	for i := range c.Sinks {
+		if c.Sinks[i].Name == s.Name {
+			c.Sinks[i] = s
+			return
+		}
+	}
+	c.Sinks = append(c.Sinks, s)
+}
+
+// Validate returns an error if the sink is not valid
+func (s *Sink) Validate() error {
+	if s.Name == "" {
+		return util.MissingOption("name")
+	}
+	if !strings.HasPrefix(s.URL, "http://") && !strings.HasPrefix(s.URL, "https://") {
+		return util.InvalidOptionf("url", s.URL, "the URL of the sink must be an http or https URL")
+	}
+	for _, t := range s.Types {
+		if util.StringArrayIndex(Types, t) < 0 {
+			return util.InvalidOption("type", t, Types)
+		}
+	}
+	return nil
+}
+
+// Matches returns true if the event should be posted to the sink
+func (s *Sink) Matches(e *Event) bool {
+	return len(s.Types) == 0 || util.StringArrayIndex(s.Types, e.Type) >= 0
+}
+
+// Post posts the event to the URL in the structured mode of the CloudEvents HTTP binding, using the token as a
+// bearer token if it is not empty
+func Post(url string, token string, e *Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return errors.Wrap(err, "marshalling the event")
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrapf(err, "creating the request to %s", util.SanitizeURL(url))
+	}
+	req.Header.Set("Content-Type", ContentType)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	client := &http.Client{Timeout: postTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "posting to %s", util.SanitizeURL(url))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("posting to %s returned status %s", util.SanitizeURL(url), resp.Status)
+	}
+	return nil
+}
+
+// PromotionEvents returns the environment promoted events of the completed promotions of the pipeline activity
+func PromotionEvents(source string, activity *v1.PipelineActivity) []*Event {
+	spec := &activity.Spec
+	answer := []*Event{}
+	for _, step := range spec.Steps {
+		promote := step.Promote
+		if step.Kind != v1.ActivityStepKindTypePromote || promote == nil || !promote.Status.IsTerminated() {
+			continue
+		}
+		data := &PromotionData{
+			Environment:    promote.Environment,
+			Owner:          spec.GitOwner,
+			Repository:     spec.GitRepository,
+			Version:        spec.Version,
+			Status:         string(promote.Status),
+			ApplicationURL: promote.ApplicationURL,
+			Pipeline:       activity.Name,
+		}
+		if promote.PullRequest != nil {
+			data.PullRequestURL = promote.PullRequest.PullRequestURL
+		}
+		answer = append(answer, NewEvent(TypeEnvironmentPromoted, source, promote.Environment, data))
+	}
+	return answer
+}
+
+// Emitter posts events to the sinks configured in a namespace
+type Emitter struct {
+	KubeClient kubernetes.Interface
+	Namespace  string
+	// Source the source of the events
+	Source string
+	// Post posts the event to the sink URL, defaults to Post
+	Post func(url string, token string, e *Event) error
+
+	lock sync.Mutex
+	sent map[string]bool
+}
+
+// OnActivity emits the promotions of the pipeline activity which have not been emitted yet. Controllers update an
+// activity many times so each promotion is only emitted once
+func (m *Emitter) OnActivity(activity *v1.PipelineActivity) {
+	for _, e := range PromotionEvents(m.Source, activity) {
+		key := activity.Name + "/" + e.Subject
+		m.lock.Lock()
+		if m.sent == nil || len(m.sent) >= maxSentEvents {
+			m.sent = map[string]bool{}
+		}
+		sent := m.sent[key]
+		m.sent[key] = true
+		m.lock.Unlock()
+		if sent {
+			continue
+		}
+		err := m.Emit(e)
+		if err != nil {
+			log.Logger().Warnf("failed to emit the %s event of %s: %s", e.Type, activity.Name, err)
+		}
+	}
+}
+
+// Emit posts the event to the matching sinks
+func (m *Emitter) Emit(e *Event) error {
+	config, err := LoadConfig(m.KubeClient, m.Namespace)
+	if err != nil {
+		return err
+	}
+	post := m.Post
+	if post == nil {
+		post = Post
+	}
+	var failed []string
+	for i := range config.Sinks {
+		sink := &config.Sinks[i]
+		if !sink.Matches(e) {
+			continue
+		}
+		err = m.send(sink, e, post)
+		if err != nil {
+			log.Logger().Warnf("failed to post the %s event to sink %s: %s", e.Type, sink.Name, err)
+			failed = append(failed, sink.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to post to the sinks %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func (m *Emitter) send(sink *Sink, e *Event, post func(url string, token string, e *Event) error) error {
+	token := ""
+	if sink.Secret != "" {
+		secret, err := m.KubeClient.CoreV1().Secrets(m.Namespace).Get(sink.Secret, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "getting Secret %s", sink.Secret)
+		}
+		token = string(secret.Data[SecretKeyToken])
+	}
+	return post(sink.URL, token, e)
+}
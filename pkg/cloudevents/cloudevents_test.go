@@ -0,0 +1,120 @@
+// +build unit
+
+package cloudevents_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/cloudevents"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPost(t *testing.T) {
+	var received map[string]interface{}
+	var contentType, authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		authorization = r.Header.Get("Authorization")
+		err := json.NewDecoder(r.Body).Decode(&received)
+		assert.NoError(t, err)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	e := cloudevents.NewEvent(cloudevents.TypeAppCreated, "/jx/import", "myorg/myapp", &cloudevents.AppData{Name: "myapp", Owner: "myorg"})
+	err := cloudevents.Post(server.URL, "mytoken", e)
+	require.NoError(t, err)
+
+	assert.Equal(t, cloudevents.ContentType, contentType)
+	assert.Equal(t, "Bearer mytoken", authorization)
+	assert.Equal(t, "1.0", received["specversion"])
+	assert.Equal(t, e.ID, received["id"])
+	assert.Equal(t, "/jx/import", received["source"])
+	assert.Equal(t, cloudevents.TypeAppCreated, received["type"])
+	assert.Equal(t, "myorg/myapp", received["subject"])
+	assert.Equal(t, map[string]interface{}{"name": "myapp", "owner": "myorg"}, received["data"])
+}
+
+func TestSinkValidate(t *testing.T) {
+	assert.NoError(t, (&cloudevents.Sink{Name: "a", URL: "https://example.com/events"}).Validate())
+	assert.Error(t, (&cloudevents.Sink{Name: "a", URL: "example.com"}).Validate())
+	assert.Error(t, (&cloudevents.Sink{Name: "a", URL: "https://example.com", Types: []string{"app.deleted"}}).Validate())
+}
+
+func TestEmitterOnActivity(t *testing.T) {
+	ns := "jx"
+	kubeClient := kubefake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "jx-event-sink-deploy", Namespace: ns},
+		Data:       map[string][]byte{cloudevents.SecretKeyToken: []byte("mytoken")},
+	})
+	config := &cloudevents.Config{}
+	config.SetSink(cloudevents.Sink{Name: "all", URL: "https://all.example.com"})
+	config.SetSink(cloudevents.Sink{Name: "deploy", URL: "https://deploy.example.com", Types: []string{cloudevents.TypeEnvironmentPromoted}, Secret: "jx-event-sink-deploy"})
+	config.SetSink(cloudevents.Sink{Name: "apps", URL: "https://apps.example.com", Types: []string{cloudevents.TypeAppCreated}})
+	require.NoError(t, cloudevents.SaveConfig(kubeClient, ns, config))
+
+	type post struct {
+		url     string
+		token   string
+		subject string
+	}
+	posts := []post{}
+	emitter := &cloudevents.Emitter{
+		KubeClient: kubeClient,
+		Namespace:  ns,
+		Source:     "/jx/controller/build",
+		Post: func(url string, token string, e *cloudevents.Event) error {
+			assert.Equal(t, cloudevents.TypeEnvironmentPromoted, e.Type)
+			assert.Equal(t, "/jx/controller/build", e.Source)
+			posts = append(posts, post{url: url, token: token, subject: e.Subject})
+			return nil
+		},
+	}
+
+	activity := &v1.PipelineActivity{
+		ObjectMeta: metav1.ObjectMeta{Name: "myorg-myapp-master-2"},
+		Spec: v1.PipelineActivitySpec{
+			GitOwner:      "myorg",
+			GitRepository: "myapp",
+			Version:       "0.0.2",
+			Steps: []v1.PipelineActivityStep{
+				{
+					Kind: v1.ActivityStepKindTypePromote,
+					Promote: &v1.PromoteActivityStep{
+						CoreActivityStep: v1.CoreActivityStep{Status: v1.ActivityStatusTypeRunning},
+						Environment:      "staging",
+					},
+				},
+			},
+		},
+	}
+	emitter.OnActivity(activity)
+	assert.Empty(t, posts, "running promotions should not be emitted")
+
+	activity.Spec.Steps[0].Promote.Status = v1.ActivityStatusTypeSucceeded
+	emitter.OnActivity(activity)
+	emitter.OnActivity(activity)
+	assert.Equal(t, []post{
+		{url: "https://all.example.com", subject: "staging"},
+		{url: "https://deploy.example.com", token: "mytoken", subject: "staging"},
+	}, posts, "each promotion should only be emitted once to the matching sinks")
+
+	events := cloudevents.PromotionEvents("/jx", activity)
+	require.Len(t, events, 1)
+	assert.Equal(t, &cloudevents.PromotionData{
+		Environment: "staging",
+		Owner:       "myorg",
+		Repository:  "myapp",
+		Version:     "0.0.2",
+		Status:      "Succeeded",
+		Pipeline:    "myorg-myapp-master-2",
+	}, events[0].Data)
+}
@@ -0,0 +1,53 @@
+package approve
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/spf13/cobra"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+)
+
+// Approve contains the command line options
+type Approve struct {
+	*opts.CommonOptions
+}
+
+var (
+	approveLong = templates.LongDesc(`
+		Approves a process waiting for a manual approval such as a promotion.
+`)
+
+	approveExample = templates.Examples(`
+		# Approve a promotion
+		jx approve promotion myapp-1.2.3-production
+	`)
+)
+
+// NewCmdApprove creates the command object
+func NewCmdApprove(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &Approve{
+		commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "approve TYPE [flags]",
+		Short:   "Approves a process such as a promotion",
+		Long:    approveLong,
+		Example: approveExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.AddCommand(NewCmdApprovePromotion(commonOpts))
+	return cmd
+}
+
+// Run implements this command
+func (o *Approve) Run() error {
+	return o.Cmd.Help()
+}
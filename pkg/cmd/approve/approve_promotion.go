@@ -0,0 +1,93 @@
+package approve
+
+import (
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/promotion"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// PromotionOptions contains the command line options
+type PromotionOptions struct {
+	*opts.CommonOptions
+
+	Username string
+}
+
+var (
+	approvePromotionLong = templates.LongDesc(`
+		Approves a promotion to an environment with the approval gate so that its Pull Request is merged once its
+		other gates are passed.
+` + helper.SeeAlsoText("jx get promotions"))
+
+	approvePromotionExample = templates.Examples(`
+		# Approve the promotion of version 1.2.3 of myapp to production
+		jx approve promotion myapp-1.2.3-production
+	`)
+)
+
+// NewCmdApprovePromotion creates the command
+func NewCmdApprovePromotion(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &PromotionOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "promotion ID [flags]",
+		Short:   "Approves a promotion waiting for its approval gate",
+		Long:    approvePromotionLong,
+		Example: approvePromotionExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Username, "username", "", "", "The user approving the promotion, defaults to the current user")
+	return cmd
+}
+
+// Run implements this command
+func (o *PromotionOptions) Run() error {
+	if len(o.Args) == 0 {
+		return util.MissingArgument("promotion ID")
+	}
+	id := o.Args[0]
+	username, err := o.GetUsername(o.Username)
+	if err != nil {
+		return err
+	}
+	if username == "" {
+		return util.MissingOption("username")
+	}
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	p, err := promotion.GetPromotion(kubeClient, ns, id)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return errors.Errorf("no promotion %s found in namespace %s, see: jx get promotions", id, ns)
+	}
+	if p.State() == promotion.StateFailed {
+		return errors.Errorf("promotion %s has already failed its gates %s", id, p.Summary())
+	}
+	err = p.Approve(username)
+	if err != nil {
+		return err
+	}
+	err = promotion.SavePromotion(kubeClient, ns, p)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Approved promotion %s of %s version %s to %s", util.ColorInfo(id), util.ColorInfo(p.Application),
+		util.ColorInfo(p.Version), util.ColorInfo(p.Environment))
+	return nil
+}
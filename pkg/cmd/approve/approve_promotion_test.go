@@ -0,0 +1,53 @@
+// +build unit
+
+package approve_test
+
+import (
+	"testing"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/approve"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/clients/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/promotion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestApprovePromotion(t *testing.T) {
+	t.Parallel()
+
+	ns := "jx"
+	kubeClient := kubefake.NewSimpleClientset()
+	env := &v1.Environment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "production",
+			Annotations: map[string]string{promotion.AnnotationGates: promotion.GateApproval},
+		},
+	}
+	p, err := promotion.EnsurePromotion(kubeClient, ns, env, &promotion.Promotion{Application: "myapp", Version: "1.2.3"})
+	require.NoError(t, err)
+
+	commonOpts := opts.NewCommonOptionsWithFactory(fake.NewFakeFactory())
+	commonOpts.SetDevNamespace(ns)
+	commonOpts.SetKubeClient(kubeClient)
+	o := &approve.PromotionOptions{
+		CommonOptions: &commonOpts,
+		Username:      "james",
+	}
+
+	o.Args = []string{"myapp-9.9.9-production"}
+	err = o.Run()
+	assert.Error(t, err, "approving an unknown promotion should fail")
+
+	o.Args = []string{p.ID}
+	err = o.Run()
+	require.NoError(t, err)
+
+	p, err = promotion.GetPromotion(kubeClient, ns, p.ID)
+	require.NoError(t, err)
+	assert.Equal(t, promotion.StatePassed, p.State())
+	assert.Equal(t, "james", p.ApprovedBy)
+}
@@ -52,6 +52,9 @@ type BootOptions struct {
 
 	// UpgradeGit if we want to automatically upgrade this boot clone if there have been changes since the current clone
 	NoUpgradeGit bool
+
+	// Resume skips the steps of the pipeline which completed in the previous boot
+	Resume bool
 }
 
 var (
@@ -72,6 +75,9 @@ var (
 		# if we have already booted and just want to apply some environment changes without
         # re-applying ingress and so forth we can start at the environment step:
 		jx boot --start-step install-env
+
+		# resume a failed boot from the step which failed
+		jx boot --resume
 `)
 )
 
@@ -105,6 +111,7 @@ func NewCmdBoot(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.RequirementsFile, "requirements", "r", "", "WARNING: this should only be used for the initial boot of a cluster: requirements file which will overwrite the default requirements file")
 	cmd.Flags().BoolVarP(&options.AttemptRestore, "attempt-restore", "a", false, "attempt to boot from an existing dev environment repository")
 	cmd.Flags().BoolVarP(&options.NoUpgradeGit, "no-update-git", "", false, "disables any attempt to update the local git clone if its old")
+	cmd.Flags().BoolVarP(&options.Resume, "resume", "", false, "resumes a failed boot skipping the steps of the pipeline which completed")
 
 	return cmd
 }
@@ -218,6 +225,13 @@ func (o *BootOptions) Run() error {
 	so.NoReleasePrepare = true
 	so.StartStep = o.StartStep
 	so.EndStep = o.EndStep
+	so.Checkpoint, err = o.LoadCheckpoint("boot", o.Resume && o.StartStep == "")
+	if err != nil {
+		return errors.Wrap(err, "loading the checkpoint of the boot")
+	}
+	if so.Checkpoint.Failed != "" {
+		log.Logger().Infof("Resuming the boot from step %s", util.ColorInfo(so.Checkpoint.Failed))
+	}
 
 	so.AdditionalEnvVars = map[string]string{
 		"JX_NO_TILLER":                     "true",
@@ -266,6 +280,10 @@ func (o *BootOptions) Run() error {
 	if err != nil {
 		return errors.Wrapf(err, "failed to interpret pipeline file %s", pipelineFile)
 	}
+	err = so.Checkpoint.Reset()
+	if err != nil {
+		return errors.Wrap(err, "removing the checkpoint of the boot")
+	}
 
 	log.Logger().Debugf("Using additional vars: %+v", so.AdditionalEnvVars)
 
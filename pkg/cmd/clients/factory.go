@@ -12,6 +12,7 @@ import (
 	"io"
 	"net/url"
 	"os"
+	"sync"
 
 	"github.com/jenkins-x/jx/v2/pkg/kustomize"
 
@@ -67,6 +68,15 @@ type factory struct {
 	secretLocation secrets.SecretLocation
 	offline        bool
 	jxFactory      jxfactory.Factory
+	namespace      *namespaceCache
+}
+
+// namespaceCache the current namespace of the kube config file which is only loaded again by a factory when the
+// file changes
+type namespaceCache struct {
+	lock        sync.Mutex
+	fingerprint string
+	namespace   *string
 }
 
 var _ Factory = (*factory)(nil)
@@ -77,6 +87,7 @@ var _ Factory = (*factory)(nil)
 func NewFactory() Factory {
 	f := &factory{}
 	f.jxFactory = jxfactory.NewFactory()
+	f.namespace = &namespaceCache{}
 	return f
 }
 
@@ -85,6 +96,7 @@ func NewFactory() Factory {
 func NewUsingFactory(jxf jxfactory.Factory) Factory {
 	f := &factory{}
 	f.jxFactory = jxf
+	f.namespace = &namespaceCache{}
 	return f
 }
 
@@ -100,6 +112,7 @@ func (f *factory) SetOffline(offline bool) {
 func (f *factory) ImpersonateUser(user string) Factory {
 	copy := *f
 	copy.jxFactory = copy.jxFactory.ImpersonateUser(user)
+	copy.namespace = &namespaceCache{}
 	return &copy
 }
 
@@ -107,6 +120,7 @@ func (f *factory) ImpersonateUser(user string) Factory {
 func (f *factory) WithBearerToken(token string) Factory {
 	copy := *f
 	copy.jxFactory = copy.jxFactory.WithBearerToken(token)
+	copy.namespace = &namespaceCache{}
 	return &copy
 }
 
@@ -644,11 +658,10 @@ func (f *factory) CreateKnativeServeClient() (kserve.Interface, string, error) {
 	if err != nil {
 		return nil, "", err
 	}
-	kubeConfig, _, err := f.jxFactory.KubeConfig().LoadConfig()
+	ns, err := f.currentNamespace()
 	if err != nil {
 		return nil, "", err
 	}
-	ns := kube.CurrentNamespace(kubeConfig)
 	client, err := kserve.NewForConfig(config)
 	if err != nil {
 		return nil, ns, err
@@ -661,11 +674,10 @@ func (f *factory) CreateProwJobClient() (prowjobclient.Interface, string, error)
 	if err != nil {
 		return nil, "", err
 	}
-	kubeConfig, _, err := f.jxFactory.KubeConfig().LoadConfig()
+	ns, err := f.currentNamespace()
 	if err != nil {
 		return nil, "", err
 	}
-	ns := kube.CurrentNamespace(kubeConfig)
 	client, err := prowjobclient.NewForConfig(config)
 	if err != nil {
 		return nil, ns, err
@@ -682,11 +694,10 @@ func (f *factory) CreateDynamicClient() (dynamic.Interface, string, error) {
 	if err != nil {
 		return nil, "", err
 	}
-	kubeConfig, _, err := f.jxFactory.KubeConfig().LoadConfig()
+	ns, err := f.currentNamespace()
 	if err != nil {
 		return nil, "", err
 	}
-	ns := kube.CurrentNamespace(kubeConfig)
 	client, err := dynamic.NewForConfig(config)
 	if err != nil {
 		return nil, ns, err
@@ -710,29 +721,36 @@ func (f *factory) CreateMetricsClient() (metricsclient.Interface, error) {
 	return metricsclient.NewForConfig(config)
 }
 
-// CreateKubeClient creates a new Kubernetes client. It also returns the currently set namespace as per KUBECONFIG.
+// CreateKubeClient returns the Kubernetes client which is created once by the underlying factory. It also returns the
+// currently set namespace as per KUBECONFIG.
 // If no namespace is selected, 'default' is returned.
 // If an error occurs an error is returned together with a nil client and an empty string as current namespace.
 func (f *factory) CreateKubeClient() (kubernetes.Interface, string, error) {
-	cfg, err := f.CreateKubeConfig()
-	if err != nil {
-		return nil, "", err
-	}
-	client, err := kubernetes.NewForConfig(cfg)
-	if err != nil {
-		return nil, "", err
+	if f.offline {
+		panic("not supposed to be making a network connection")
 	}
-	if client == nil {
-		return nil, "", fmt.Errorf("failed to create Kubernetes Client")
+	return f.jxFactory.CreateKubeClient()
+}
+
+// currentNamespace returns the current namespace of the kube config file loading it when the file changes
+func (f *factory) currentNamespace() (string, error) {
+	if f.namespace == nil {
+		f.namespace = &namespaceCache{}
 	}
-	ns := ""
-	config, _, err := f.jxFactory.KubeConfig().LoadConfig()
-	if err != nil {
-		return client, ns, err
+	c := f.namespace
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	fingerprint := kube.KubeConfigFingerprint()
+	if c.namespace == nil || c.fingerprint != fingerprint {
+		c.fingerprint = fingerprint
+		kubeConfig, _, err := f.jxFactory.KubeConfig().LoadConfig()
+		if err != nil {
+			return "", err
+		}
+		ns := kube.CurrentNamespace(kubeConfig)
+		c.namespace = &ns
 	}
-	ns = kube.CurrentNamespace(config)
-	// TODO allow namsepace to be specified as a CLI argument!
-	return client, ns, nil
+	return *c.namespace, nil
 }
 
 func (f *factory) CreateGitProvider(gitURL string, message string, authConfigSvc auth.ConfigService, gitKind string, ghOwner string, batchMode bool, gitter gits.Gitter, handles util.IOFileHandles) (gits.GitProvider, error) {
@@ -17,6 +17,7 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -27,27 +28,37 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/deprecation"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/experimental"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/export"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/profile"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/ui"
 
 	version2 "github.com/jenkins-x/jx/v2/pkg/cmd/version"
 
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/boot"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/compliance"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/controller"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/convert"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/create"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/deletecmd"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/edit"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/gc"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/get"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/importcmd"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/initcmd"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/pipeline"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/plugin"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/preview"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/restore"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/rollback"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/rotate"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/rsh"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/start"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/stop"
@@ -55,8 +66,10 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cmd/uninstall"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/update"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/upgrade"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/verify"
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/add"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/approve"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/namespace"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/promote"
 
@@ -66,10 +79,15 @@ import (
 
 	"github.com/jenkins-x/jx-logging/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/features"
+	"github.com/jenkins-x/jx/v2/pkg/selfupdate"
+	"github.com/jenkins-x/jx/v2/pkg/telemetry"
+	"github.com/jenkins-x/jx/v2/pkg/tracing"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/clients"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/operations"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	telemetrycmd "github.com/jenkins-x/jx/v2/pkg/cmd/telemetry"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
 	"github.com/jenkins-x/jx/v2/pkg/version"
 	"github.com/spf13/cobra"
@@ -83,16 +101,26 @@ func NewJXCommand(f clients.Factory, in terminal.FileReader, out terminal.FileWr
 
 	configureViper()
 	rootCommand := &cobra.Command{
-		Use:              "jx",
-		Short:            "jx is a command line tool for working with Jenkins X",
-		PersistentPreRun: setLoggingLevel,
-		Run:              runHelp,
+		Use:               "jx",
+		Short:             "jx is a command line tool for working with Jenkins X",
+		PersistentPostRun: persistentPostRun,
+		Run:               runHelp,
 	}
 
 	features.Init()
 
 	commonOpts := opts.NewCommonOptionsWithTerm(f, in, out, err)
 	commonOpts.AddBaseFlags(rootCommand)
+	rootCommand.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		persistentPreRun(cmd, args)
+		commonOpts.SetContext(commandContext)
+	}
+	offerCertificateAuthorityRefresh := update.OfferCertificateAuthorityRefresh(commonOpts)
+	helper.BehaviorOnError(func(err error) {
+		recordTelemetry(err)
+		endTracing(err)
+		offerCertificateAuthorityRefresh(err)
+	})
 
 	addCommands := add.NewCmdAdd(commonOpts)
 	createCommands := create.NewCmdCreate(commonOpts)
@@ -108,6 +136,11 @@ func NewJXCommand(f clients.Factory, in terminal.FileReader, out terminal.FileWr
 		create.NewCmdInstall(commonOpts),
 		uninstall.NewCmdUninstall(commonOpts),
 		upgrade.NewCmdUpgrade(commonOpts),
+		verify.NewCmdVerify(commonOpts),
+		operations.NewCmdOperations(commonOpts),
+		restore.NewCmdRestore(commonOpts),
+		rotate.NewCmdRotate(commonOpts),
+		export.NewCmdExport(commonOpts),
 	}
 	installCommands = append(installCommands, findCommands("cluster", createCommands, deleteCommands)...)
 	installCommands = append(installCommands, findCommands("cluster", updateCommands)...)
@@ -116,6 +149,7 @@ func NewJXCommand(f clients.Factory, in terminal.FileReader, out terminal.FileWr
 
 	addProjectCommands := []*cobra.Command{
 		importcmd.NewCmdImport(commonOpts),
+		convert.NewCmdConvert(commonOpts),
 	}
 	addProjectCommands = append(addProjectCommands, findCommands("create spring", createCommands, deleteCommands)...)
 	addProjectCommands = append(addProjectCommands, findCommands("create quickstart", createCommands, deleteCommands)...)
@@ -132,6 +166,7 @@ func NewJXCommand(f clients.Factory, in terminal.FileReader, out terminal.FileWr
 	environmentsCommands := []*cobra.Command{
 		preview.NewCmdPreview(commonOpts),
 		promote.NewCmdPromote(commonOpts),
+		rollback.NewCmdRollback(commonOpts),
 	}
 	environmentsCommands = append(environmentsCommands, findCommands("environment", createCommands, deleteCommands, editCommands, getCommands)...)
 
@@ -158,10 +193,12 @@ func NewJXCommand(f clients.Factory, in terminal.FileReader, out terminal.FileWr
 				compliance.NewCompliance(commonOpts),
 				NewCmdCompletion(commonOpts),
 				NewCmdContext(commonOpts),
+				NewCmdLogin(commonOpts),
 				NewCmdEnvironment(commonOpts),
 				NewCmdTeam(commonOpts),
 				namespace.NewCmdNamespace(commonOpts),
 				NewCmdPrompt(commonOpts),
+				NewCmdRunInContainer(commonOpts),
 				NewCmdScan(commonOpts),
 				NewCmdShell(commonOpts),
 				NewCmdStatus(commonOpts),
@@ -191,12 +228,14 @@ func NewJXCommand(f clients.Factory, in terminal.FileReader, out terminal.FileWr
 				addCommands,
 				start.NewCmdStart(commonOpts),
 				stop.NewCmdStop(commonOpts),
+				approve.NewCmdApprove(commonOpts),
 			},
 		},
 		{
 			Message: "Jenkins X Pipeline Commands:",
 			Commands: []*cobra.Command{
 				NewCmdStep(commonOpts),
+				pipeline.NewCmdPipeline(commonOpts),
 			},
 		},
 		{
@@ -236,6 +275,8 @@ func NewJXCommand(f clients.Factory, in terminal.FileReader, out terminal.FileWr
 	rootCommand.SetVersionTemplate("{{printf .Version}}\n Deprecated will be removed on July 1, 2020. Please use version instead\n")
 	rootCommand.AddCommand(NewCmdOptions(out))
 	rootCommand.AddCommand(NewCmdDiagnose(commonOpts))
+	rootCommand.AddCommand(plugin.NewCmdPlugin(commonOpts))
+	rootCommand.AddCommand(telemetrycmd.NewCmdTelemetry(commonOpts))
 
 	// Mark the deprecated commands
 	deprecation.DeprecateCommands(rootCommand)
@@ -244,6 +285,8 @@ func NewJXCommand(f clients.Factory, in terminal.FileReader, out terminal.FileWr
 	experimental.AlphaCommands(rootCommand)
 	experimental.BetaCommands(rootCommand)
 
+	registerFlagCompletion(rootCommand, "provider", commonOpts.CompleteProviders)
+
 	managedPlugins := &managedPluginHandler{
 		CommonOptions: commonOpts,
 	}
@@ -252,7 +295,8 @@ func NewJXCommand(f clients.Factory, in terminal.FileReader, out terminal.FileWr
 	if len(args) == 0 {
 		args = os.Args
 	}
-	if len(args) > 1 {
+	// the hidden commands which shells run to complete dynamic values are added by cobra when executing
+	if len(args) > 1 && !isCompletionRequest(args[1]) {
 		cmdPathPieces := args[1:]
 
 		// only look for suitable executables if
@@ -311,12 +355,93 @@ func fullPath(command *cobra.Command) string {
 	return name
 }
 
+// registerFlagCompletion registers the function which completes the values of the given flag for every command
+// which has the flag
+func registerFlagCompletion(root *cobra.Command, flagName string, f opts.CompletionFunc) {
+	registered := map[*pflag.Flag]bool{}
+	var register func(cmd *cobra.Command)
+	register = func(cmd *cobra.Command) {
+		// commands are added to more than one parent so only register each flag once
+		if flag := cmd.Flags().Lookup(flagName); flag != nil && !registered[flag] {
+			registered[flag] = true
+			err := cmd.RegisterFlagCompletionFunc(flagName, f)
+			if err != nil {
+				log.Logger().Debugf("failed to register the completion of flag %s of %s: %s", flagName, cmd.CommandPath(), err)
+			}
+		}
+		for _, child := range cmd.Commands() {
+			register(child)
+		}
+	}
+	register(root)
+}
+
+func isCompletionRequest(arg string) bool {
+	return arg == cobra.ShellCompRequestCmd || arg == cobra.ShellCompNoDescRequestCmd
+}
+
+func persistentPreRun(cmd *cobra.Command, args []string) {
+	setLoggingFormat(cmd)
+	setLoggingLevel(cmd, args)
+	setLoggingFields(cmd)
+	setAnswers(cmd)
+	setTLSPolicy(cmd)
+	notifyNewVersion(cmd)
+	startTelemetry(cmd)
+	startTracing(cmd)
+}
+
+func persistentPostRun(cmd *cobra.Command, args []string) {
+	recordTelemetry(nil)
+	endTracing(nil)
+}
+
+var (
+	// commandSpan the span of the command being run when tracing is enabled
+	commandSpan *tracing.Span
+	// commandContext the context containing the span of the command to run its operations with
+	commandContext context.Context
+)
+
+// startTracing enables tracing if an OTLP endpoint is configured and starts the span of the command which is the root
+// span of the trace, so the parent of the spans of the operations it runs
+func startTracing(cmd *cobra.Command) {
+	endpoint := ""
+	if flag := cmd.Flag(opts.OptionOTLPEndpoint); flag != nil {
+		endpoint = flag.Value.String()
+	}
+	tracing.Configure(tracing.ConfigFromEnv(endpoint))
+	commandContext, commandSpan = tracing.StartSpan(context.Background(), cmd.CommandPath())
+}
+
+// endTracing ends the span of the command and exports the spans
+func endTracing(err error) {
+	if commandSpan == nil {
+		return
+	}
+	commandSpan.End(err)
+	commandSpan = nil
+	flushErr := tracing.Flush()
+	if flushErr != nil {
+		log.Logger().Warnf("failed to export the tracing spans: %s", flushErr)
+	}
+}
+
 func setLoggingLevel(cmd *cobra.Command, args []string) {
 	verbose, err := strconv.ParseBool(cmd.Flag(opts.OptionVerbose).Value.String())
 	if err != nil {
 		log.Logger().Errorf("Unable to check if the verbose flag is set")
 	}
 
+	if flag := cmd.Flag(opts.OptionLogLevel); flag != nil && flag.Value.String() != "" {
+		level := flag.Value.String()
+		err := log.SetLevel(level)
+		if err == nil {
+			return
+		}
+		log.Logger().Errorf("Unable to set log level to %s", level)
+	}
+
 	level := os.Getenv("JX_LOG_LEVEL")
 	if level != "" {
 		if verbose {
@@ -342,6 +467,159 @@ func setLoggingLevel(cmd *cobra.Command, args []string) {
 	}
 }
 
+func setLoggingFormat(cmd *cobra.Command) {
+	flag := cmd.Flag(opts.OptionLogFormat)
+	if flag == nil || flag.Value.String() == "" {
+		return
+	}
+	err := opts.SetLogFormat(flag.Value.String())
+	if err != nil {
+		log.Logger().Errorf("Unable to set the log format: %s", err)
+	}
+}
+
+// setAnswers loads the answers file so that prompts are answered from it rather than by the user
+func setAnswers(cmd *cobra.Command) {
+	flag := cmd.Flag(opts.OptionAnswers)
+	if flag == nil || flag.Value.String() == "" {
+		return
+	}
+	answers, err := util.LoadAnswers(flag.Value.String())
+	helper.CheckErr(err)
+	util.SetAnswers(answers)
+}
+
+// setTLSPolicy applies the minimum TLS version and cipher suites of the flags to the HTTPS connections the command makes
+// and serves
+func setTLSPolicy(cmd *cobra.Command) {
+	policy := util.TLSPolicyFromEnv()
+	if value, err := cmd.Flags().GetString(opts.OptionTLSMinVersion); err == nil {
+		policy.MinVersion = value
+	}
+	if value, err := cmd.Flags().GetStringSlice(opts.OptionTLSCipherSuites); err == nil {
+		policy.CipherSuites = value
+	}
+	helper.CheckErr(util.SetTLSPolicy(policy))
+}
+
+// setLoggingFields attaches the command and the namespace and provider it operates on to the structured log entries
+func setLoggingFields(cmd *cobra.Command) {
+	util.SetLogField(util.LogFieldCommand, cmd.CommandPath())
+	for field, flagName := range map[string]string{
+		util.LogFieldNamespace: opts.OptionNamespace,
+		util.LogFieldProvider:  "provider",
+	} {
+		value := ""
+		if flag := cmd.Flags().Lookup(flagName); flag != nil {
+			value = flag.Value.String()
+		}
+		util.SetLogField(field, value)
+	}
+}
+
+// notifyNewVersion tells interactive users when a new version of jx is available using the result of the last check
+// of the release feed. The feed is checked in the background at most once a day so commands are never slowed down
+func notifyNewVersion(cmd *cobra.Command) {
+	path := cmd.CommandPath()
+	if strings.HasPrefix(path, "jx upgrade cli") || strings.HasPrefix(path, "jx step") || isCompletionRequest(cmd.Name()) || !isTerminal(os.Stderr) {
+		return
+	}
+	if flag := cmd.Flag(opts.OptionBatchMode); flag != nil {
+		batchMode, err := strconv.ParseBool(flag.Value.String())
+		if err == nil && batchMode {
+			return
+		}
+	}
+	currentVersion, err := version.GetSemverVersion()
+	if err != nil {
+		return
+	}
+	notifier := &selfupdate.Notifier{}
+	settings, err := notifier.LoadSettings()
+	if err != nil {
+		log.Logger().Debugf("failed to load the update notifier settings: %s", err)
+		return
+	}
+	notice := notifier.Notice(settings, currentVersion)
+	if notice != "" {
+		fmt.Fprintln(os.Stderr, notice)
+	}
+	if notifier.NeedsCheck(settings) {
+		go func() {
+			err := notifier.Check(settings)
+			if err != nil {
+				log.Logger().Debugf("failed to check for a new version of jx: %s", err)
+			}
+		}()
+	}
+}
+
+// commandTelemetry the command being run whose usage metrics are recorded when it completes
+type commandTelemetry struct {
+	client   *telemetry.Client
+	settings *telemetry.Settings
+	command  string
+	provider string
+	started  time.Time
+}
+
+var currentTelemetry *commandTelemetry
+
+// startTelemetry starts timing the command if the user has opted in to telemetry. Interactive users are told about
+// telemetry the first time they run jx
+func startTelemetry(cmd *cobra.Command) {
+	currentTelemetry = nil
+	if isCompletionRequest(cmd.Name()) {
+		return
+	}
+	client := &telemetry.Client{}
+	settings, err := client.LoadSettings()
+	if err != nil {
+		log.Logger().Debugf("failed to load the telemetry settings: %s", err)
+		return
+	}
+	if !settings.NoticeShown && isTerminal(os.Stderr) && !strings.HasPrefix(cmd.CommandPath(), "jx telemetry") {
+		notice, err := client.FirstRunNotice(settings)
+		if err != nil {
+			log.Logger().Debugf("failed to save the telemetry settings: %s", err)
+		}
+		fmt.Fprintln(os.Stderr, notice)
+	}
+	if !client.Enabled(settings) {
+		return
+	}
+	provider := ""
+	if flag := cmd.Flags().Lookup("provider"); flag != nil {
+		provider = flag.Value.String()
+	}
+	currentTelemetry = &commandTelemetry{
+		client:   client,
+		settings: settings,
+		command:  cmd.CommandPath(),
+		provider: provider,
+		started:  time.Now(),
+	}
+}
+
+// recordTelemetry records the usage metrics of the command which completed with the given error, if any
+func recordTelemetry(err error) {
+	t := currentTelemetry
+	if t == nil {
+		return
+	}
+	currentTelemetry = nil
+	event := telemetry.NewEvent(t.command, time.Since(t.started), t.provider, err, version.GetVersion())
+	recordErr := t.client.Record(t.settings, event)
+	if recordErr != nil {
+		log.Logger().Debugf("failed to record the telemetry event: %s", recordErr)
+	}
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
 func runHelp(cmd *cobra.Command, args []string) {
 	cmd.Help() //nolint:errcheck
 }
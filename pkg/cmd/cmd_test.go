@@ -86,6 +86,39 @@ func TestLoggingSetup(t *testing.T) {
 	}
 }
 
+func TestLogLevelFlag(t *testing.T) {
+	origLogLevel, exists := os.LookupEnv("JX_LOG_LEVEL")
+	if exists {
+		defer func() {
+			_ = os.Setenv("JX_LOG_LEVEL", origLogLevel)
+		}()
+	}
+	err := os.Setenv("JX_LOG_LEVEL", "debug")
+	assert.NoError(t, err)
+
+	testCommandName := "logleveltest"
+	logCommand := &cobra.Command{
+		Use:   testCommandName,
+		Short: "dummy test command",
+		Run: func(cmd *cobra.Command, args []string) {
+			out := log.CaptureOutput(func() {
+				log.Logger().Debug("debug")
+				log.Logger().Info("info")
+				log.Logger().Warn("warn")
+			})
+			assert.Equal(t, "WARNING: warn\n", out, "the flag should take precedence over JX_LOG_LEVEL")
+		},
+	}
+
+	rootCmd := NewJXCommand(fake.NewFakeFactory(), os.Stdin, os.Stdout, os.Stderr, nil)
+	rootCmd.AddCommand(logCommand)
+	rootCmd.SetArgs([]string{testCommandName, "--log-level", "warn", "--verbose"})
+	_ = log.CaptureOutput(func() {
+		err := rootCmd.Execute()
+		assert.NoError(t, err)
+	})
+}
+
 func TestFindPluginBinary(t *testing.T) {
 	pluginsDir := filepath.Join("test_data", "binary_plugins_dir")
 
@@ -10,6 +10,7 @@ import (
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/spf13/cobra"
 	"gopkg.in/AlecAivazis/survey.v1"
 )
@@ -18,7 +19,7 @@ const boilerPlate = ""
 
 var (
 	completion_long = templates.LongDesc(`
-		Output shell completion code for the given shell (bash or zsh).
+		Output shell completion code for the given shell (bash, zsh or fish).
 
 		This command prints shell code which must be evaluation to provide interactive
 		completion of jx commands.
@@ -42,6 +43,13 @@ var (
 
 		    $ source <(jx completion zsh)
 
+		If you use fish, the following will load jx fish completion:
+
+		    $ jx completion fish | source
+
+		Dynamic values such as kube contexts, namespaces, Kubernetes providers and app names are completed by
+		running jx itself so they reflect the current cluster.
+
 		[1] zsh completions are only supported in versions of zsh >= 5.2`)
 )
 
@@ -49,8 +57,9 @@ var (
 	completion_shells = map[string]func(out io.Writer, cmd *cobra.Command) error{
 		"bash": runCompletionBash,
 		"zsh":  runCompletionZsh,
+		"fish": runCompletionFish,
 	}
-	// It is likely that the user has the completions for kubectl loaded, so reusing function from there if they exist
+	// bashCompletionFunctions completes the values which the commands do not complete themselves
 	bashCompletionFunctions = `
 __jx_get_env() {
 	local jx_out
@@ -69,10 +78,6 @@ __jx_custom_func() {
             __jx_get_env
             return
             ;;
-		jx_namespace )
-			declare -f __kubectl_get_resource_namespace > /dev/null && __kubectl_get_resource_namespace
-			return
-			;;
         *)
             ;;
     esac
@@ -97,7 +102,7 @@ func NewCmdCompletion(commonOpts *opts.CommonOptions) *cobra.Command {
 
 	cmd := &cobra.Command{
 		Use:   "completion SHELL",
-		Short: "Output shell completion code for the given shell (bash or zsh)",
+		Short: "Output shell completion code for the given shell (bash, zsh or fish)",
 		Long:  completion_long,
 		Run: func(cmd *cobra.Command, args []string) {
 			options.Cmd = cmd
@@ -128,7 +133,7 @@ func (o *CompletionOptions) Run() error {
 			PageSize: len(shells),
 			Help:     "The name of the shell",
 		}
-		err := survey.AskOne(prompts, &ShellName, nil, surveyOpts)
+		err := util.AskOne(prompts, &ShellName, nil, surveyOpts)
 		if err != nil {
 			return err
 		}
@@ -162,6 +167,10 @@ func runCompletionBash(out io.Writer, cmd *cobra.Command) error {
 	return cmd.GenBashCompletion(out)
 }
 
+func runCompletionFish(out io.Writer, cmd *cobra.Command) error {
+	return cmd.GenFishCompletion(out, true)
+}
+
 func runCompletionZsh(out io.Writer, cmd *cobra.Command) error {
 	zsh_head := "#compdef jx\n"
 
@@ -0,0 +1,36 @@
+// +build unit
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/clients/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompleteProviderFlag(t *testing.T) {
+	args := []string{"__complete", "install", "--provider", "ek"}
+	rootCmd := NewJXCommand(fake.NewFakeFactory(), os.Stdin, os.Stdout, os.Stderr, append([]string{"jx"}, args...))
+	out := &bytes.Buffer{}
+	rootCmd.SetOut(out)
+	rootCmd.SetErr(&bytes.Buffer{})
+	rootCmd.SetArgs(args)
+
+	err := rootCmd.Execute()
+	require.NoError(t, err)
+	assert.Equal(t, "eks\n:4\n", out.String())
+}
+
+func TestCompletionShells(t *testing.T) {
+	rootCmd := NewJXCommand(fake.NewFakeFactory(), os.Stdin, os.Stdout, os.Stderr, []string{"jx", "completion"})
+	for shell, run := range completion_shells {
+		out := &bytes.Buffer{}
+		err := run(out, rootCmd)
+		require.NoError(t, err, "generating the %s completion", shell)
+		assert.Contains(t, out.String(), "__complete", "the %s completion should complete dynamic values", shell)
+	}
+}
@@ -22,6 +22,14 @@ type ContextOptions struct {
 	*opts.CommonOptions
 
 	Filter string
+	Output string
+}
+
+// ContextInfo describes the current Kubernetes context when rendered via the output flag
+type ContextInfo struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Server    string `json:"server"`
 }
 
 var (
@@ -35,7 +43,10 @@ var (
 		jx ctx
 
 		# view the current context
-		jx ctx -b`)
+		jx ctx -b
+
+		# view the current context as JSON
+		jx ctx -o json`)
 )
 
 func NewCmdContext(commonOpts *opts.CommonOptions) *cobra.Command {
@@ -43,11 +54,12 @@ func NewCmdContext(commonOpts *opts.CommonOptions) *cobra.Command {
 		CommonOptions: commonOpts,
 	}
 	cmd := &cobra.Command{
-		Use:     "context",
-		Aliases: []string{"ctx"},
-		Short:   "View or change the current Kubernetes context (Kubernetes cluster)",
-		Long:    context_long,
-		Example: context_example,
+		Use:               "context",
+		Aliases:           []string{"ctx"},
+		Short:             "View or change the current Kubernetes context (Kubernetes cluster)",
+		Long:              context_long,
+		Example:           context_example,
+		ValidArgsFunction: options.CompleteContexts,
 		Run: func(cmd *cobra.Command, args []string) {
 			options.Cmd = cmd
 			options.Args = args
@@ -56,6 +68,7 @@ func NewCmdContext(commonOpts *opts.CommonOptions) *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVarP(&options.Filter, "filter", "f", "", "Filter the list of contexts to switch between using the given text")
+	options.AddOutputFlag(cmd, &options.Output)
 	return cmd
 }
 
@@ -88,7 +101,7 @@ func (o *ContextOptions) Run() error {
 		}
 	}
 
-	if ctxName == "" && !o.BatchMode {
+	if ctxName == "" && !o.BatchMode && o.Output == "" {
 		defaultCtxName := config.CurrentContext
 		pick, err := o.PickContext(contextNames, defaultCtxName)
 		if err != nil {
@@ -108,11 +121,25 @@ func (o *ContextOptions) Run() error {
 		if err != nil {
 			return fmt.Errorf("Failed to update the kube config %s", err)
 		}
+		if o.Output != "" {
+			return o.RenderOutput(o.Output, &ContextInfo{
+				Name:      newConfig.CurrentContext,
+				Namespace: ctx.Namespace,
+				Server:    kube.Server(config, ctx),
+			})
+		}
 		fmt.Fprintf(o.Out, "Now using namespace '%s' from context named '%s' on server '%s'.\n",
 			info(ctx.Namespace), info(newConfig.CurrentContext), info(kube.Server(config, ctx)))
 	} else {
 		ns := kube.CurrentNamespace(config)
 		server := kube.CurrentServer(config)
+		if o.Output != "" {
+			return o.RenderOutput(o.Output, &ContextInfo{
+				Name:      config.CurrentContext,
+				Namespace: ns,
+				Server:    server,
+			})
+		}
 		fmt.Fprintf(o.Out, "Using namespace '%s' from context named '%s' on server '%s'.\n",
 			info(ns), info(config.CurrentContext), info(server))
 	}
@@ -133,6 +160,6 @@ func (o *ContextOptions) PickContext(names []string, defaultValue string) (strin
 		Options: names,
 		Default: defaultValue,
 	}
-	err := survey.AskOne(prompt, &name, nil, surveyOpts)
+	err := util.AskOne(prompt, &name, nil, surveyOpts)
 	return name, err
 }
@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/admission"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// ControllerAdmissionOptions holds the command line arguments
+type ControllerAdmissionOptions struct {
+	ControllerOptions
+
+	BindAddress string
+	Port        int
+	TLSCertFile string
+	TLSKeyFile  string
+}
+
+var (
+	controllerAdmissionLong = templates.LongDesc(`
+		Runs the admission webhook which validates the Environment, SourceRepository and Scheduler resources when they
+		are created or updated so that invalid promotion strategies, missing git URLs, invalid namespaces and invalid
+		jobs are rejected by 'kubectl apply' rather than failing later in the controllers.
+
+		The API server only calls webhooks over HTTPS. The webhook is usually installed via 'jx create addon admission'.
+`)
+
+	controllerAdmissionExample = templates.Examples(`
+		# run the admission webhook
+		jx controller admission --tls-cert-file /etc/webhook/certs/tls.crt --tls-key-file /etc/webhook/certs/tls.key
+`)
+)
+
+// NewCmdControllerAdmission creates the command
+func NewCmdControllerAdmission(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &ControllerAdmissionOptions{
+		ControllerOptions: ControllerOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "admission",
+		Short:   "Runs the admission webhook which validates the Jenkins X resources",
+		Long:    controllerAdmissionLong,
+		Example: controllerAdmissionExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().IntVarP(&options.Port, optionPort, "", 8443, "The TCP port to listen on.")
+	cmd.Flags().StringVarP(&options.BindAddress, optionBind, "", "",
+		"The interface address to bind to (by default, will listen on all interfaces/addresses).")
+	cmd.Flags().StringVarP(&options.TLSCertFile, "tls-cert-file", "", "", "The certificate file to serve the webhook over HTTPS with the TLS policy of the --tls-min-version and --tls-cipher-suites flags")
+	cmd.Flags().StringVarP(&options.TLSKeyFile, "tls-key-file", "", "", "The private key file of the --tls-cert-file certificate")
+	return cmd
+}
+
+// Run implements this command
+func (o *ControllerAdmissionOptions) Run() error {
+	if o.TLSCertFile == "" {
+		return util.MissingOption("tls-cert-file")
+	}
+	if o.TLSKeyFile == "" {
+		return util.MissingOption("tls-key-file")
+	}
+	address := fmt.Sprintf("%s:%d", o.BindAddress, o.Port)
+	log.Logger().Infof("Validating the Jenkins X resources on %s", util.ColorInfo(address+admission.ValidatePath))
+	return util.ListenAndServe(&http.Server{Addr: address, Handler: o.Handler()}, o.TLSCertFile, o.TLSKeyFile)
+}
+
+// Handler returns the handler of the admission webhook and the health endpoints
+func (o *ControllerAdmissionOptions) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(admission.ValidatePath, admission.Handler())
+	mux.HandleFunc(healthPath, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc(readyPath, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return mux
+}
@@ -19,6 +19,8 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/logs"
+	"github.com/jenkins-x/jx/v2/pkg/metrics"
+	"github.com/jenkins-x/jx/v2/pkg/notify"
 	"k8s.io/apimachinery/pkg/fields"
 
 	"github.com/jenkins-x/jx/v2/pkg/collector"
@@ -34,6 +36,7 @@ import (
 	"github.com/jenkins-x/jx-api/pkg/client/clientset/versioned"
 	"github.com/jenkins-x/jx-logging/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/builds"
+	"github.com/jenkins-x/jx/v2/pkg/cloudevents"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/spf13/cobra"
 	tektonclient "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
@@ -66,11 +69,18 @@ type ControllerBuildOptions struct {
 
 	DryRun bool
 
+	opts.MetricsOptions
+
 	// private fields added for easier testing
 	gitHubProvider gits.GitProvider
 
 	// private field to record whether the lighthouse-foghorn deployment is present - if so, we skip status reporting
 	foghornPresent bool
+
+	// notifier sends the pipeline and promotion events to the notifications of the team
+	notifier *notify.Notifier
+	// emitter posts the promotions to the event sinks of the team
+	emitter *cloudevents.Emitter
 }
 
 // NewCmdControllerBuild creates a command object for the generic "get" action, which
@@ -102,6 +112,7 @@ func NewCmdControllerBuild(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.TargetURLTemplate, "target-url-template", "", "", "The Go template for generating the target URL of pipeline logs/views if git reporting is enabled. If unspecified, a default will be used based on `--job-url-base`.")
 	cmd.Flags().BoolVarP(&options.GitReporting, "git-reporting", "", false, "If enabled then lets report pipeline success/failures to the git provider. Note this is purely tactical until we can do this natively inside tekton")
 	cmd.Flags().StringVarP(&options.JobURLBase, "job-url-base", "", "", "The base URL, such as 'https://dashboard.jenkins-x.live', for generating the target URL for pipeline logs if git reporting is enabled.")
+	options.AddMetricsFlags(cmd)
 	return cmd
 }
 
@@ -151,6 +162,13 @@ func (o *ControllerBuildOptions) Run() error {
 
 	o.EnvironmentCache = kube.CreateEnvironmentCache(jxClient, ns)
 
+	err = o.StartMetrics(&o.MetricsOptions, "controllerbuild", ns)
+	if err != nil {
+		return errors.Wrap(err, "starting the metrics")
+	}
+	o.notifier = &notify.Notifier{KubeClient: kubeClient, Namespace: ns}
+	o.emitter = &cloudevents.Emitter{KubeClient: kubeClient, Namespace: ns, Source: o.EventSource()}
+
 	if o.InitGitCredentials {
 		err = o.InitGitConfigAndUser()
 		if err != nil {
@@ -230,6 +248,7 @@ func (o *ControllerBuildOptions) handleStandalonePod(pod *corev1.Pod, kubeClient
 							name = a.Name
 							return err
 						}
+						o.notify(a)
 					}
 					return nil
 				})
@@ -305,6 +324,7 @@ func (o *ControllerBuildOptions) onPipelinePod(obj interface{}, kubeClient kuber
 								name = a.Name
 								return err
 							}
+							o.notify(a)
 						}
 						return nil
 					})
@@ -319,6 +339,16 @@ func (o *ControllerBuildOptions) onPipelinePod(obj interface{}, kubeClient kuber
 	}
 }
 
+// notify sends the new pipeline and promotion events of the activity to the notifications and event sinks of the team
+func (o *ControllerBuildOptions) notify(activity *v1.PipelineActivity) {
+	if o.notifier != nil {
+		o.notifier.OnActivity(activity)
+	}
+	if o.emitter != nil {
+		o.emitter.OnActivity(activity)
+	}
+}
+
 // createPromoteStepActivityKey deduces the pipeline metadata from the build pod
 func (o *ControllerBuildOptions) createPromoteStepActivityKey(buildName string, pod *corev1.Pod) *kube.PromoteStepActivityKey {
 
@@ -554,6 +584,7 @@ func (o *ControllerBuildOptions) updatePipelineActivity(kubeClient kubernetes.In
 
 		// log that the build completed
 		logJobCompletedState(activity, nil)
+		metrics.ObservePipelineActivity(activity)
 
 		// lets ensure we overwrite any canonical jenkins build URL thats generated automatically
 		if spec.BuildLogsURL == "" || !strings.Contains(spec.BuildLogsURL, pod.Name) {
@@ -686,6 +717,7 @@ func (o *ControllerBuildOptions) updatePipelineActivityForRun(kubeClient kuberne
 
 			// log that the build completed
 			logJobCompletedState(activity, pri)
+			metrics.ObservePipelineActivity(activity)
 
 			// TODO: This will need to be reworked for per-step logs, so leaving alone as part of metapipeline work
 			// lets ensure we overwrite any canonical jenkins build URL thats generated automatically
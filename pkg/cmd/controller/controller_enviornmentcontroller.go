@@ -23,10 +23,12 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/create"
+	"github.com/jenkins-x/jx/v2/pkg/config"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/jenkinsfile"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/jenkins-x/jx/v2/pkg/kube/services"
+	"github.com/jenkins-x/jx/v2/pkg/metrics"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/sirupsen/logrus"
 	"k8s.io/test-infra/prow/github"
@@ -68,10 +70,15 @@ type ControllerEnvironmentOptions struct {
 	WebHookURL            string
 	Branch                string
 	PushRef               string
+	TriggerPaths          []string
 	Labels                map[string]string
+	TLSCertFile           string
+	TLSKeyFile            string
 
 	StepCreateTaskOptions create.StepCreateTaskOptions
 	secret                []byte
+
+	opts.MetricsOptions
 }
 
 var (
@@ -118,6 +125,10 @@ func NewCmdControllerEnvironment(commonOpts *opts.CommonOptions) *cobra.Command
 	cmd.Flags().StringVarP(&options.GitRepo, "repo", "", "", "The git repository name. If not specified defaults to $REPO")
 	cmd.Flags().StringVarP(&options.WebHookURL, "webhook-url", "w", "", "The external WebHook URL of this controller to register with the git provider. If not specified defaults to $WEBHOOK_URL")
 	cmd.Flags().StringVarP(&options.PushRef, "push-ref", "", "refs/heads/master", "The git ref passed from the WebHook which should trigger a new deploy pipeline to trigger. Defaults to only webhooks from the master branch")
+	cmd.Flags().StringVarP(&options.TLSCertFile, "tls-cert-file", "", "", "The certificate file to serve the webhooks over HTTPS with the TLS policy of the --tls-min-version and --tls-cipher-suites flags. Serves HTTP if not specified")
+	cmd.Flags().StringVarP(&options.TLSKeyFile, "tls-key-file", "", "", "The private key file of the --tls-cert-file certificate")
+	cmd.Flags().StringArrayVarP(&options.TriggerPaths, "trigger-path", "", nil, "The glob patterns of the files whose changes trigger a new deploy pipeline. Any change triggers a pipeline if not specified")
+	options.AddMetricsFlags(cmd)
 
 	so := &options.StepCreateTaskOptions
 	so.CommonOptions = commonOpts
@@ -233,6 +244,15 @@ func (o *ControllerEnvironmentOptions) Run() error {
 		}
 	}
 
+	_, ns, err := o.KubeClientAndNamespace()
+	if err != nil {
+		return err
+	}
+	err = o.StartMetrics(&o.MetricsOptions, environmentControllerService, ns)
+	if err != nil {
+		return errors.Wrap(err, "starting the metrics")
+	}
+
 	mux := http.NewServeMux()
 	mux.Handle(healthPath, http.HandlerFunc(o.health))
 	mux.Handle(readyPath, http.HandlerFunc(o.ready))
@@ -246,7 +266,7 @@ func (o *ControllerEnvironmentOptions) Run() error {
 	mux.Handle(o.Path, http.HandlerFunc(o.handleWebHookRequests))
 
 	log.Logger().Infof("Environment Controller is now listening on %s for WebHooks from the source repository %s to trigger promotions", util.ColorInfo(util.UrlJoin(o.WebHookURL, o.Path)), util.ColorInfo(o.SourceURL))
-	return http.ListenAndServe(":"+strconv.Itoa(o.Port), mux)
+	return util.ListenAndServe(&http.Server{Addr: ":" + strconv.Itoa(o.Port), Handler: mux}, o.TLSCertFile, o.TLSKeyFile)
 }
 
 // health returns either HTTP 204 if the service is healthy, otherwise nothing ('cos it's dead).
@@ -475,9 +495,15 @@ func (o *ControllerEnvironmentOptions) handleWebHookRequests(w http.ResponseWrit
 		o.getIndex(w, r)
 		return
 	}
+	start := time.Now()
+	var err error
+	defer func() {
+		metrics.ObserveWebhook(environmentControllerService, start, err)
+	}()
 	eventType, eventGUID, data, valid, _ := ValidateWebhook(w, r, o.secret, o.RequireHeaders)
 	log.Logger().Infof("webhook handler invoked event type %s UID %s valid %s method %s", eventType, eventGUID, strconv.FormatBool(valid), r.Method)
 	if !valid {
+		err = errors.Errorf("invalid webhook event type %s UID %s", eventType, eventGUID)
 		return
 	}
 	if eventType != "push" {
@@ -492,7 +518,7 @@ func (o *ControllerEnvironmentOptions) handleWebHookRequests(w http.ResponseWrit
 	// lets return 200 so we don't keep getting retries from GitHub :)
 
 	event := github.PushEvent{}
-	if err := json.Unmarshal(data, &event); err != nil {
+	if err = json.Unmarshal(data, &event); err != nil {
 		responseHTTPError(w, http.StatusBadRequest, "400 Bad Request: Could not unmarshal the PushEvent")
 		return
 	}
@@ -501,12 +527,38 @@ func (o *ControllerEnvironmentOptions) handleWebHookRequests(w http.ResponseWrit
 		return
 	}
 
+	trigger, err := pushEventTriggersPipeline(&event, o.TriggerPaths)
+	if err != nil {
+		responseHTTPError(w, http.StatusInternalServerError, "500 Internal Server Error: "+err.Error())
+		return
+	}
+	if !trigger {
+		w.Write([]byte(helloMessage + "ignoring webhook event type: " + eventType + " as no trigger paths changed")) //nolint:errcheck
+		return
+	}
+
 	log.Logger().Infof("starting pipeline from event type %s UID %s valid %s method %s", eventType, eventGUID, strconv.FormatBool(valid), r.Method)
 	w.Write([]byte("OK")) //nolint:errcheck
 
 	go o.startPipelineRun(w, r)
 }
 
+// pushEventTriggersPipeline returns true if the push changed any of the files matching the trigger paths. Pushes which
+// do not list the changed files always trigger the pipeline
+func pushEventTriggersPipeline(event *github.PushEvent, triggerPaths []string) (bool, error) {
+	files := []string{}
+	for _, commit := range event.Commits {
+		files = append(files, commit.Added...)
+		files = append(files, commit.Removed...)
+		files = append(files, commit.Modified...)
+	}
+	if len(files) == 0 {
+		return true, nil
+	}
+	trigger := &config.TriggerConfig{Paths: triggerPaths}
+	return trigger.Matches(files)
+}
+
 func (o *ControllerEnvironmentOptions) registerWebHook(webhookURL string, secret []byte) error {
 	gitURL := o.SourceURL
 	log.Logger().Infof("verifying that the webhook is registered for the git repository %s", util.ColorInfo(gitURL))
@@ -0,0 +1,40 @@
+// +build unit
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/test-infra/prow/github"
+)
+
+func TestPushEventTriggersPipeline(t *testing.T) {
+	t.Parallel()
+
+	event := &github.PushEvent{
+		Ref: "refs/heads/master",
+		Commits: []github.Commit{
+			{ID: "1", Added: []string{"README.md"}},
+			{ID: "2", Modified: []string{"env/values.yaml"}},
+		},
+	}
+
+	trigger, err := pushEventTriggersPipeline(event, nil)
+	require.NoError(t, err)
+	assert.True(t, trigger, "any change should trigger the pipeline without trigger paths")
+
+	trigger, err = pushEventTriggersPipeline(event, []string{"env"})
+	require.NoError(t, err)
+	assert.True(t, trigger)
+
+	trigger, err = pushEventTriggersPipeline(event, []string{"charts/**"})
+	require.NoError(t, err)
+	assert.False(t, trigger)
+
+	event.Commits = nil
+	trigger, err = pushEventTriggersPipeline(event, []string{"charts/**"})
+	require.NoError(t, err)
+	assert.True(t, trigger, "pushes without the changed files should trigger the pipeline")
+}
@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"time"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx-api/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/activities"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/metrics"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// ControllerGCActivitiesOptions holds the command line arguments
+type ControllerGCActivitiesOptions struct {
+	ControllerOptions
+
+	Namespace        string
+	RetentionRules   string
+	KeepReleases     int
+	KeepPullRequests int
+	ReleaseAge       time.Duration
+	PullRequestAge   time.Duration
+	BatchSize        int
+	BatchInterval    time.Duration
+	PageSize         int64
+	Interval         time.Duration
+	DryRun           bool
+	BindAddress      string
+	Port             int
+}
+
+var (
+	controllerGCActivitiesLong = templates.LongDesc(`
+		Runs a controller which periodically prunes the completed PipelineActivities according to retention rules.
+
+		The most recent activities of each release branch and pull request are kept along with the activities younger
+		than the maximum ages. The retention of repositories and branches can be overridden with a YAML file of rules
+		as described in 'jx gc activities --help'.
+
+		The activities are deleted in batches to avoid the throttling of the API server and the numbers of pruned
+		activities are available as Prometheus metrics from the /metrics endpoint.
+`)
+
+	controllerGCActivitiesExample = templates.Examples(`
+		# prune the activities every hour keeping the last 5 builds of each release branch and 2 of each pull request
+		jx controller gcactivities
+
+		# prune the activities using the retention rules of the repositories
+		jx controller gcactivities --rules /etc/jx/retention-rules.yaml
+`)
+)
+
+// NewCmdControllerGCActivities creates the command
+func NewCmdControllerGCActivities(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &ControllerGCActivitiesOptions{
+		ControllerOptions: ControllerOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "gcactivities",
+		Short:   "Runs the controller which prunes the completed PipelineActivities according to retention rules",
+		Long:    controllerGCActivitiesLong,
+		Example: controllerGCActivitiesExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "The namespace of the PipelineActivities or defaults to the dev namespace")
+	cmd.Flags().StringVarP(&options.RetentionRules, "rules", "", "", "The YAML file of the retention rules of the PipelineActivities of repositories and branches")
+	cmd.Flags().IntVarP(&options.KeepReleases, "keep-releases", "", 5, "The number of the most recent PipelineActivities kept for each release branch")
+	cmd.Flags().IntVarP(&options.KeepPullRequests, "keep-pull-requests", "", 2, "The number of the most recent PipelineActivities kept for each pull request")
+	cmd.Flags().DurationVarP(&options.ReleaseAge, "release-age", "", time.Hour*24*30, "The maximum age of the PipelineActivities of release branches")
+	cmd.Flags().DurationVarP(&options.PullRequestAge, "pull-request-age", "", time.Hour*48, "The maximum age of the PipelineActivities of pull requests")
+	cmd.Flags().IntVarP(&options.BatchSize, "batch-size", "", 50, "The number of PipelineActivities deleted before pausing")
+	cmd.Flags().DurationVarP(&options.BatchInterval, "batch-interval", "", time.Second, "The pause between the batches of deleted PipelineActivities")
+	cmd.Flags().Int64VarP(&options.PageSize, "page-size", "", 500, "The number of PipelineActivities retrieved by each list call")
+	cmd.Flags().DurationVarP(&options.Interval, "interval", "i", time.Hour, "The interval between the prunings")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "d", false, "Only logs the PipelineActivities which would be deleted")
+	cmd.Flags().IntVarP(&options.Port, optionPort, "", 8080, "The TCP port to listen on.")
+	cmd.Flags().StringVarP(&options.BindAddress, optionBind, "", "",
+		"The interface address to bind to (by default, will listen on all interfaces/addresses).")
+	return cmd
+}
+
+// Run implements this command
+func (o *ControllerGCActivitiesOptions) Run() error {
+	rules, err := activities.LoadRetentionRules(o.RetentionRules, activities.RetentionPolicy{
+		KeepReleases:      o.KeepReleases,
+		KeepPullRequests:  o.KeepPullRequests,
+		ReleaseMaxAge:     o.ReleaseAge.String(),
+		PullRequestMaxAge: o.PullRequestAge.String(),
+	})
+	if err != nil {
+		return err
+	}
+	jxClient, devNs, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return errors.Wrap(err, "creating the jx client")
+	}
+	ns := o.Namespace
+	if ns == "" {
+		ns = devNs
+	}
+	interval := o.Interval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	metrics.Serve(o.BindAddress, o.Port)
+	log.Logger().Infof("Pruning the PipelineActivities in namespace %s every %s", util.ColorInfo(ns), interval.String())
+	for {
+		_, err = o.Prune(jxClient, ns, rules, time.Now())
+		if err != nil {
+			log.Logger().Warnf("Failed to prune the PipelineActivities: %s", err)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// Prune deletes the completed PipelineActivities of the namespace which are not kept by the retention rules and
+// returns the number of deleted activities
+func (o *ControllerGCActivitiesOptions) Prune(jxClient versioned.Interface, ns string, rules *activities.RetentionRules, now time.Time) (int, error) {
+	activityInterface := jxClient.JenkinsV1().PipelineActivities(ns)
+	var completed []v1.PipelineActivity
+	err := kube.ListPipelineActivitiesInPages(activityInterface, nil, nil, o.PageSize, func(items []v1.PipelineActivity) (bool, error) {
+		for _, item := range items {
+			if item.Spec.CompletedTimestamp != nil {
+				completed = append(completed, item)
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	pruned, err := rules.Prune(completed, now)
+	if err != nil {
+		return 0, err
+	}
+	if len(pruned) == 0 {
+		log.Logger().Debugf("no PipelineActivities to prune of the %d completed activities", len(completed))
+		return 0, nil
+	}
+	pruner := &activities.Pruner{
+		Activities:    activityInterface,
+		BatchSize:     o.BatchSize,
+		BatchInterval: o.BatchInterval,
+		DryRun:        o.DryRun,
+	}
+	deleted, err := pruner.Delete(pruned)
+	log.Logger().Infof("Pruned %d of the %d completed PipelineActivities", deleted, len(completed))
+	return deleted, err
+}
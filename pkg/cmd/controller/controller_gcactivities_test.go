@@ -0,0 +1,60 @@
+// +build unit
+
+package controller_test
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	jxfake "github.com/jenkins-x/jx-api/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/v2/pkg/activities"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/controller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestControllerGCActivitiesPrune(t *testing.T) {
+	ns := "jx"
+	now := time.Now()
+	activity := func(name string, pipeline string, completed *metav1.Time) *v1.PipelineActivity {
+		return &v1.PipelineActivity{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+			Spec: v1.PipelineActivitySpec{
+				Pipeline:           pipeline,
+				CompletedTimestamp: completed,
+			},
+		}
+	}
+	hoursAgo := func(hours int) *metav1.Time {
+		return &metav1.Time{Time: now.Add(-time.Duration(hours) * time.Hour)}
+	}
+	jxClient := jxfake.NewSimpleClientset(
+		activity("master-1", "myorg/myapp/master", hoursAgo(3)),
+		activity("master-2", "myorg/myapp/master", hoursAgo(2)),
+		activity("master-3", "myorg/myapp/master", hoursAgo(1)),
+		activity("master-4", "myorg/myapp/master", nil),
+		activity("pr-1", "myorg/myapp/PR-1", hoursAgo(100)),
+		activity("pr-2", "myorg/myapp/PR-2", hoursAgo(1)),
+	)
+
+	rules, err := activities.LoadRetentionRules("", activities.RetentionPolicy{
+		KeepReleases:      2,
+		KeepPullRequests:  2,
+		PullRequestMaxAge: "48h",
+	})
+	require.NoError(t, err)
+	o := &controller.ControllerGCActivitiesOptions{}
+	deleted, err := o.Prune(jxClient, ns, rules, now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+
+	list, err := jxClient.JenkinsV1().PipelineActivities(ns).List(metav1.ListOptions{})
+	require.NoError(t, err)
+	names := []string{}
+	for _, item := range list.Items {
+		names = append(names, item.Name)
+	}
+	assert.ElementsMatch(t, []string{"master-2", "master-3", "master-4", "pr-2"}, names)
+}
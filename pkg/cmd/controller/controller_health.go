@@ -0,0 +1,328 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/metrics"
+	"github.com/jenkins-x/jx/v2/pkg/notify"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// healthzPath the URL path of the HTTP endpoint returning the health of the jx components
+	healthzPath = "/healthz"
+
+	// ComponentWebhooks the component receiving the webhooks of the git provider
+	ComponentWebhooks = "webhooks"
+	// ComponentChartMuseum the chart repository
+	ComponentChartMuseum = "chartmuseum"
+	// ComponentPipelines the pipelines operator
+	ComponentPipelines = "pipelines"
+	// ComponentIngress the ingress controller
+	ComponentIngress = "ingress"
+)
+
+// ControllerHealthOptions holds the command line arguments
+type ControllerHealthOptions struct {
+	ControllerOptions
+
+	Namespace        string
+	BindAddress      string
+	Port             int
+	Interval         time.Duration
+	IngressNamespace string
+	IngressService   string
+	SlackWebhookURL  string
+	TeamsWebhookURL  string
+
+	lock     sync.Mutex
+	statuses map[string]*ComponentHealth
+}
+
+// ComponentHealth the health of a jx component
+type ComponentHealth struct {
+	Component      string    `json:"component"`
+	Healthy        bool      `json:"healthy"`
+	Message        string    `json:"message,omitempty"`
+	LastCheck      time.Time `json:"lastCheck"`
+	LastTransition time.Time `json:"lastTransition"`
+	Failures       int64     `json:"failures"`
+}
+
+var (
+	controllerHealthLong = templates.LongDesc(`
+		Runs a controller which periodically verifies the core Jenkins X components are healthy: the webhook
+		receiver, chartmuseum, the pipelines operator and the ingress controller.
+
+		The health of the components is available as JSON from the /healthz endpoint, which returns 503 when any
+		component is degraded, and as Prometheus metrics from the /metrics endpoint. Alerts can be posted to Slack
+		and Microsoft Teams incoming webhooks when a component degrades or recovers.
+`)
+
+	controllerHealthExample = templates.Examples(`
+		# run the health controller checking the components every minute
+		jx controller health
+
+		# post alerts to a Slack incoming webhook when components degrade
+		jx controller health --slack-webhook-url https://hooks.slack.com/services/T000/B000/XXXX
+`)
+
+	// componentDeployments the deployments of each component, any of which may be installed
+	componentDeployments = map[string][]string{
+		ComponentWebhooks:    {"hook", "lighthouse-webhooks"},
+		ComponentChartMuseum: {kube.ServiceChartMuseum},
+		ComponentPipelines:   {kube.DeploymentTektonController, "pipelinerunner", "jenkins-x-controllerbuild"},
+	}
+)
+
+// NewCmdControllerHealth creates the command
+func NewCmdControllerHealth(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &ControllerHealthOptions{
+		ControllerOptions: ControllerOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "health",
+		Short:   "Runs the controller which verifies the health of the core Jenkins X components",
+		Long:    controllerHealthLong,
+		Example: controllerHealthExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "The namespace of the Jenkins X installation or defaults to the dev namespace")
+	cmd.Flags().IntVarP(&options.Port, optionPort, "", 8080, "The TCP port to listen on.")
+	cmd.Flags().StringVarP(&options.BindAddress, optionBind, "", "",
+		"The interface address to bind to (by default, will listen on all interfaces/addresses).")
+	cmd.Flags().DurationVarP(&options.Interval, "interval", "i", time.Minute, "The interval between the health checks")
+	cmd.Flags().StringVarP(&options.IngressNamespace, "ingress-namespace", "", opts.DefaultIngressNamesapce, "The namespace of the ingress controller")
+	cmd.Flags().StringVarP(&options.IngressService, "ingress-service", "", opts.DefaultIngressServiceName, "The name of the service of the ingress controller")
+	cmd.Flags().StringVarP(&options.SlackWebhookURL, "slack-webhook-url", "", "", "The Slack incoming webhook URL alerts are posted to when components degrade or recover")
+	cmd.Flags().StringVarP(&options.TeamsWebhookURL, "teams-webhook-url", "", "", "The Microsoft Teams incoming webhook URL alerts are posted to when components degrade or recover")
+	return cmd
+}
+
+// Run implements this command
+func (o *ControllerHealthOptions) Run() error {
+	kubeClient, devNs, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return errors.Wrap(err, "creating the kube client")
+	}
+	ns := o.Namespace
+	if ns == "" {
+		ns = devNs
+	}
+	interval := o.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	serverErrors := make(chan error, 1)
+	go func() {
+		address := fmt.Sprintf("%s:%d", o.BindAddress, o.Port)
+		log.Logger().Infof("Serving the health of the components in namespace %s on %s", util.ColorInfo(ns), util.ColorInfo(address))
+		serverErrors <- http.ListenAndServe(address, o.Handler())
+	}()
+
+	o.CheckHealth(kubeClient, ns)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-serverErrors:
+			return errors.Wrap(err, "serving the health endpoints")
+		case <-ticker.C:
+			o.CheckHealth(kubeClient, ns)
+		}
+	}
+}
+
+// Handler returns the handler of the /healthz and /metrics endpoints
+func (o *ControllerHealthOptions) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(healthzPath, o.healthz)
+	mux.Handle(metrics.Path, metrics.Handler())
+	mux.HandleFunc(healthPath, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc(readyPath, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return mux
+}
+
+// CheckHealth verifies the health of the components, alerting on the components which degraded or recovered since
+// the previous check, and returns their health
+func (o *ControllerHealthOptions) CheckHealth(kubeClient kubernetes.Interface, ns string) []ComponentHealth {
+	now := time.Now()
+	results := map[string]error{}
+	for component, deployments := range componentDeployments {
+		results[component] = checkDeployments(kubeClient, ns, deployments)
+	}
+	results[ComponentIngress] = checkIngressController(kubeClient, o.IngressNamespace, o.IngressService)
+
+	o.lock.Lock()
+	if o.statuses == nil {
+		o.statuses = map[string]*ComponentHealth{}
+	}
+	metrics.HealthChecks.Inc()
+	alerts := []ComponentHealth{}
+	for component, err := range results {
+		status := o.statuses[component]
+		first := status == nil
+		if first {
+			status = &ComponentHealth{Component: component, LastTransition: now}
+			o.statuses[component] = status
+		}
+		healthy := err == nil
+		status.LastCheck = now
+		status.Message = ""
+		if !healthy {
+			status.Message = err.Error()
+			status.Failures++
+			metrics.ComponentFailures.WithLabelValues(component).Inc()
+		}
+		// alert on the first check only when a component is already degraded
+		if healthy != status.Healthy || first {
+			if !first {
+				status.LastTransition = now
+			}
+			status.Healthy = healthy
+			if !first || !healthy {
+				alerts = append(alerts, *status)
+			}
+		}
+		value := 0.0
+		if healthy {
+			value = 1
+		}
+		metrics.ComponentHealthy.WithLabelValues(component).Set(value)
+		metrics.ComponentLastTransition.WithLabelValues(component).Set(float64(status.LastTransition.Unix()))
+	}
+	answer := o.snapshot()
+	o.lock.Unlock()
+
+	for _, status := range alerts {
+		o.alert(status)
+	}
+	return answer
+}
+
+// snapshot returns a copy of the health of the components sorted by name, the lock must be held
+func (o *ControllerHealthOptions) snapshot() []ComponentHealth {
+	answer := []ComponentHealth{}
+	for _, status := range o.statuses {
+		answer = append(answer, *status)
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Component < answer[j].Component
+	})
+	return answer
+}
+
+// checkDeployments verifies the installed deployments of a component have ready replicas
+func checkDeployments(kubeClient kubernetes.Interface, ns string, names []string) error {
+	found := false
+	for _, name := range names {
+		d, err := kubeClient.AppsV1().Deployments(ns).Get(name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return errors.Wrapf(err, "getting deployment %s", name)
+		}
+		found = true
+		desired := int32(1)
+		if d.Spec.Replicas != nil {
+			desired = *d.Spec.Replicas
+		}
+		if desired > 0 && d.Status.ReadyReplicas == 0 {
+			return errors.Errorf("deployment %s has no ready replicas", name)
+		}
+	}
+	if !found {
+		return errors.Errorf("none of the deployments %s exist in namespace %s", strings.Join(names, ", "), ns)
+	}
+	return nil
+}
+
+// checkIngressController verifies the service of the ingress controller exists and has a load balancer address
+func checkIngressController(kubeClient kubernetes.Interface, ns string, name string) error {
+	if ns == "" {
+		ns = opts.DefaultIngressNamesapce
+	}
+	if name == "" {
+		name = opts.DefaultIngressServiceName
+	}
+	svc, err := kubeClient.CoreV1().Services(ns).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "getting service %s in namespace %s", name, ns)
+	}
+	if svc.Spec.Type == corev1.ServiceTypeLoadBalancer && len(svc.Status.LoadBalancer.Ingress) == 0 {
+		return errors.Errorf("service %s in namespace %s has no load balancer address", name, ns)
+	}
+	return nil
+}
+
+func (o *ControllerHealthOptions) healthz(w http.ResponseWriter, r *http.Request) {
+	o.lock.Lock()
+	statuses := o.snapshot()
+	o.lock.Unlock()
+
+	code := http.StatusOK
+	for _, status := range statuses {
+		if !status.Healthy {
+			code = http.StatusServiceUnavailable
+		}
+	}
+	data, err := json.Marshal(statuses)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(data)
+}
+
+// alert posts the degraded or recovered component to the configured Slack and Teams webhooks
+func (o *ControllerHealthOptions) alert(status ComponentHealth) {
+	text := fmt.Sprintf("Jenkins X component %s recovered", status.Component)
+	if !status.Healthy {
+		text = fmt.Sprintf("Jenkins X component %s is degraded: %s", status.Component, status.Message)
+	}
+	log.Logger().Warn(text)
+	if o.SlackWebhookURL != "" {
+		err := notify.Post(o.SlackWebhookURL, notify.Payload(notify.KindSlack, text, status.Healthy))
+		if err != nil {
+			log.Logger().Warnf("Failed to post the alert to Slack: %s", err)
+		}
+	}
+	if o.TeamsWebhookURL != "" {
+		err := notify.Post(o.TeamsWebhookURL, notify.Payload(notify.KindTeams, text, status.Healthy))
+		if err != nil {
+			log.Logger().Warnf("Failed to post the alert to Teams: %s", err)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// +build unit
+
+package controller_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/controller"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestControllerHealth(t *testing.T) {
+	ns := "jx"
+	deployment := func(name string, ready int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: ready},
+		}
+	}
+	kubeClient := kubefake.NewSimpleClientset(
+		deployment("lighthouse-webhooks", 1),
+		deployment(kube.ServiceChartMuseum, 0),
+		deployment(kube.DeploymentTektonController, 1),
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: opts.DefaultIngressServiceName, Namespace: opts.DefaultIngressNamesapce},
+			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+			Status:     corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{IP: "1.2.3.4"}}}},
+		},
+	)
+
+	var lock sync.Mutex
+	alerts := []string{}
+	slack := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := map[string]string{}
+		err := json.NewDecoder(r.Body).Decode(&payload)
+		assert.NoError(t, err)
+		lock.Lock()
+		alerts = append(alerts, payload["text"])
+		lock.Unlock()
+	}))
+	defer slack.Close()
+
+	o := &controller.ControllerHealthOptions{
+		SlackWebhookURL: slack.URL,
+	}
+	statuses := o.CheckHealth(kubeClient, ns)
+	require.Len(t, statuses, 4)
+	health := map[string]bool{}
+	for _, s := range statuses {
+		health[s.Component] = s.Healthy
+	}
+	assert.Equal(t, map[string]bool{
+		controller.ComponentChartMuseum: false,
+		controller.ComponentIngress:     true,
+		controller.ComponentPipelines:   true,
+		controller.ComponentWebhooks:    true,
+	}, health)
+	assert.Equal(t, []string{"Jenkins X component chartmuseum is degraded: deployment jenkins-x-chartmuseum has no ready replicas"}, alerts,
+		"only the components degraded on the first check should be alerted")
+
+	w := httptest.NewRecorder()
+	o.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	w = httptest.NewRecorder()
+	o.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, err := ioutil.ReadAll(w.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `jx_component_healthy{component="chartmuseum"} 0`)
+	assert.Contains(t, string(body), `jx_component_healthy{component="webhooks"} 1`)
+	assert.Contains(t, string(body), `jx_component_failures_total{component="chartmuseum"}`)
+	assert.Contains(t, string(body), "jx_health_checks_total")
+
+	_, err = kubeClient.AppsV1().Deployments(ns).Update(deployment(kube.ServiceChartMuseum, 1))
+	require.NoError(t, err)
+	o.CheckHealth(kubeClient, ns)
+	assert.Equal(t, "Jenkins X component chartmuseum recovered", alerts[len(alerts)-1])
+	assert.Len(t, alerts, 2)
+
+	w = httptest.NewRecorder()
+	o.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
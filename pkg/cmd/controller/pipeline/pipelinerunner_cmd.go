@@ -33,6 +33,10 @@ type PipelineRunnerOptions struct {
 	UseMetaPipeline      bool
 	MetaPipelineImage    string
 	SemanticRelease      bool
+	TLSCertFile          string
+	TLSKeyFile           string
+
+	opts.MetricsOptions
 }
 
 var (
@@ -68,11 +72,15 @@ func NewCmdControllerPipelineRunner(commonOpts *opts.CommonOptions) *cobra.Comma
 	cmd.Flags().StringVar(&options.ServiceAccount, "service-account", tekton.DefaultPipelineSA, "The Kubernetes ServiceAccount to use to run the pipeline.")
 	cmd.Flags().BoolVar(&options.NoGitCredentialsInit, "no-git-init", false, "Disables checking we have setup git credentials on startup.")
 	cmd.Flags().BoolVar(&options.SemanticRelease, "semantic-release", false, "Enable semantic releases")
+	cmd.Flags().StringVar(&options.TLSCertFile, "tls-cert-file", "", "The certificate file to serve HTTPS with the TLS policy of the --tls-min-version and --tls-cipher-suites flags. Serves HTTP if not specified")
+	cmd.Flags().StringVar(&options.TLSKeyFile, "tls-key-file", "", "The private key file of the --tls-cert-file certificate")
 
 	// TODO - temporary flags until meta pipeline is the default
 	cmd.Flags().BoolVar(&options.UseMetaPipeline, useMetaPipelineOptionName, true, "Uses the meta pipeline to create the pipeline.")
 	cmd.Flags().StringVar(&options.MetaPipelineImage, metaPipelineImageOptionName, "", "Specify the docker image to use if there is no image specified for a step.")
 
+	options.AddMetricsFlags(cmd)
+
 	options.bindViper(cmd)
 	return cmd
 }
@@ -106,6 +114,11 @@ func (o *PipelineRunnerOptions) Run() error {
 		return err
 	}
 
+	err = o.StartMetrics(&o.MetricsOptions, "pipelinerunner", ns)
+	if err != nil {
+		return errors.Wrap(err, "starting the metrics")
+	}
+
 	metapipelineClient, err := metapipeline.NewMetaPipelineClient()
 	if err != nil {
 		return err
@@ -118,6 +131,8 @@ func (o *PipelineRunnerOptions) Run() error {
 		useMetaPipeline:    useMetaPipeline,
 		metaPipelineImage:  viper.GetString(metaPipelineImageOptionName),
 		semanticRelease:    o.SemanticRelease,
+		tlsCertFile:        o.TLSCertFile,
+		tlsKeyFile:         o.TLSKeyFile,
 		serviceAccount:     o.ServiceAccount,
 		jxClient:           jxClient,
 		ns:                 ns,
@@ -7,6 +7,7 @@ import (
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/clients"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/metrics"
 	"github.com/jenkins-x/jx/v2/pkg/tekton"
 	"github.com/jenkins-x/jx/v2/pkg/tekton/metapipeline"
 
@@ -83,6 +84,8 @@ type controller struct {
 	useMetaPipeline    bool
 	metaPipelineImage  string
 	semanticRelease    bool
+	tlsCertFile        string
+	tlsKeyFile         string
 	serviceAccount     string
 	ns                 string
 	jxClient           jxclient.Interface
@@ -117,7 +120,7 @@ func (c *controller) startWorkers(ctx context.Context, wg *sync.WaitGroup, cance
 			if c.metaPipelineImage != "" {
 				logger.Infof("using custom pipeline image: %s", c.metaPipelineImage)
 			}
-			if err := srv.ListenAndServe(); err != nil {
+			if err := util.ListenAndServe(srv, c.tlsCertFile, c.tlsKeyFile); err != nil {
 				if err == http.ErrServerClosed {
 					logger.Debugf("server closed")
 				} else {
@@ -168,35 +171,38 @@ func (c *controller) pipeline(w http.ResponseWriter, r *http.Request) {
 	case http.MethodHead:
 		logger.Info("HEAD Todo...")
 	case http.MethodPost:
-		c.handlePostRequest(r, w)
+		start := time.Now()
+		err := c.handlePostRequest(r, w)
+		metrics.ObserveWebhook("pipelinerunner", start, err)
 	default:
 		logger.Errorf("unsupported method %s for %s", r.Method, c.path)
 		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
 	}
 }
 
-func (c *controller) handlePostRequest(r *http.Request, w http.ResponseWriter) {
+func (c *controller) handlePostRequest(r *http.Request, w http.ResponseWriter) error {
 	requestParams, err := c.parseStartPipelineRequestParameters(r)
 	if err != nil {
 		c.returnStatusBadRequest(err, "could not read the JSON request body: "+err.Error(), w)
-		return
+		return err
 	}
 
 	pipelineRunResponse, err := c.startPipeline(requestParams)
 	if err != nil {
 		c.returnStatusBadRequest(err, "could not start pipeline: "+err.Error(), w)
-		return
+		return err
 	}
 
 	data, err := c.marshalPayload(pipelineRunResponse)
 	if err != nil {
 		c.returnStatusBadRequest(err, "failed to marshal payload", w)
-		return
+		return err
 	}
 	_, err = w.Write(data)
 	if err != nil {
 		logger.Errorf("error writing PipelineRunResponse: %s", err.Error())
 	}
+	return nil
 }
 
 func (c *controller) parseStartPipelineRequestParameters(r *http.Request) (PipelineRunRequest, error) {
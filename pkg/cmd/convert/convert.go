@@ -0,0 +1,52 @@
+package convert
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/spf13/cobra"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+)
+
+// ConvertOptions contains the command line options
+type ConvertOptions struct {
+	*opts.CommonOptions
+}
+
+var (
+	convertLong = templates.LongDesc(`
+		Converts the configuration of other tools to Jenkins X.
+
+		Valid resources include:
+
+		* jenkinsfile
+`)
+)
+
+// NewCmdConvert creates a command object for the "convert" command
+func NewCmdConvert(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &ConvertOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "convert",
+		Short: "Converts the configuration of other tools to Jenkins X",
+		Long:  convertLong,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.AddCommand(NewCmdConvertJenkinsfile(commonOpts))
+
+	return cmd
+}
+
+// Run implements this command
+func (o *ConvertOptions) Run() error {
+	return o.Cmd.Help()
+}
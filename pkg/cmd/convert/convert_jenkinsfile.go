@@ -0,0 +1,114 @@
+package convert
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/jenkinsfile/convert"
+	"github.com/jenkins-x/jx/v2/pkg/tekton/syntax"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	convertJenkinsfileLong = templates.LongDesc(`
+		Converts a declarative Jenkinsfile to the pipelines of a jenkins-x.yml which does not use a build pack
+
+		The stages with a 'when { branch ... }' condition only run in the release pipeline and the stages with a 'when { changeRequest() }' condition only run in the pull request pipeline. The constructs which cannot be converted such as script blocks, post conditions and credentials are reported with their line numbers so they can be ported by hand
+`)
+
+	convertJenkinsfileExample = templates.Examples(`
+		# Convert the Jenkinsfile of the current directory to a jenkins-x.yml
+		jx convert jenkinsfile
+
+		# Print the pipelines converted from a Jenkinsfile using the maven builder by default
+		jx convert jenkinsfile -f ci/Jenkinsfile --image gcr.io/jenkinsxio/builder-maven --dry-run
+	`)
+)
+
+// ConvertJenkinsfileOptions the options for the convert jenkinsfile command
+type ConvertJenkinsfileOptions struct {
+	*opts.CommonOptions
+
+	File      string
+	Output    string
+	Image     string
+	DryRun    bool
+	Overwrite bool
+}
+
+// NewCmdConvertJenkinsfile creates a command object for the "convert jenkinsfile" command
+func NewCmdConvertJenkinsfile(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &ConvertJenkinsfileOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "jenkinsfile",
+		Short:   "Converts a declarative Jenkinsfile to a jenkins-x.yml",
+		Long:    convertJenkinsfileLong,
+		Example: convertJenkinsfileExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.File, "file", "f", "Jenkinsfile", "The Jenkinsfile to convert")
+	cmd.Flags().StringVarP(&options.Output, "output", "o", "", "The file to write the pipelines to. Defaults to the jenkins-x.yml next to the Jenkinsfile")
+	cmd.Flags().StringVarP(&options.Image, "image", "i", syntax.DefaultContainerImage, "The image of the steps of the pipelines if the Jenkinsfile does not use a docker agent")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "Prints the pipelines rather than writing them")
+	cmd.Flags().BoolVarP(&options.Overwrite, "overwrite", "", false, "Overwrites the output file if it exists")
+	return cmd
+}
+
+// Run implements the command
+func (o *ConvertJenkinsfileOptions) Run() error {
+	data, err := ioutil.ReadFile(o.File)
+	if err != nil {
+		return errors.Wrapf(err, "reading %s", o.File)
+	}
+	result, err := convert.Jenkinsfile(string(data), o.Image)
+	if err != nil {
+		return errors.Wrapf(err, "converting %s", o.File)
+	}
+	for _, issue := range result.Issues {
+		log.Logger().Warnf("%s:%s", o.File, issue.String())
+	}
+	projectConfig := result.ProjectConfig()
+
+	if o.DryRun {
+		out, err := yaml.Marshal(projectConfig)
+		if err != nil {
+			return errors.Wrap(err, "marshalling the pipelines")
+		}
+		_, err = fmt.Fprint(o.Out, string(out))
+		return err
+	}
+	if o.Output == "" {
+		o.Output = filepath.Join(filepath.Dir(o.File), config.ProjectConfigFileName)
+	}
+	exists, err := util.FileExists(o.Output)
+	if err != nil {
+		return err
+	}
+	if exists && !o.Overwrite {
+		return fmt.Errorf("%s already exists, use --overwrite to replace it", o.Output)
+	}
+	err = projectConfig.SaveConfig(o.Output)
+	if err != nil {
+		return errors.Wrapf(err, "saving %s", o.Output)
+	}
+	log.Logger().Infof("Converted %s to %s with %d constructs to port by hand", util.ColorInfo(o.File), util.ColorInfo(o.Output), len(result.Issues))
+	return nil
+}
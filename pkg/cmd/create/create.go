@@ -59,6 +59,7 @@ func NewCmdCreate(commonOpts *opts.CommonOptions) *cobra.Command {
 	}
 
 	cmd.AddCommand(NewCmdCreateAddon(commonOpts))
+	cmd.AddCommand(NewCmdCreateBackup(commonOpts))
 	cmd.AddCommand(NewCmdCreateBranchPattern(commonOpts))
 	cmd.AddCommand(NewCmdCreateChat(commonOpts))
 	cmd.AddCommand(NewCmdCreateCluster(commonOpts))
@@ -68,6 +69,7 @@ func NewCmdCreate(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(NewCmdCreateDomain(commonOpts))
 	cmd.AddCommand(NewCmdCreateEnv(commonOpts))
 	cmd.AddCommand(NewCmdCreateEtcHosts(commonOpts))
+	cmd.AddCommand(NewCmdCreateEventSink(commonOpts))
 	cmd.AddCommand(NewCmdCreateGkeServiceAccount(commonOpts))
 	cmd.AddCommand(NewCmdCreateGit(commonOpts))
 	cmd.AddCommand(NewCmdCreateIssue(commonOpts))
@@ -76,10 +78,14 @@ func NewCmdCreate(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(NewCmdCreatePullRequest(commonOpts))
 	cmd.AddCommand(NewCmdCreateQuickstart(commonOpts))
 	cmd.AddCommand(NewCmdCreateQuickstartLocation(commonOpts))
+	cmd.AddCommand(NewCmdCreateQuickstartCatalog(commonOpts))
+	cmd.AddCommand(NewCmdCreateRegistry(commonOpts))
 	cmd.AddCommand(NewCmdCreateMLQuickstart(commonOpts))
+	cmd.AddCommand(NewCmdCreateNotification(commonOpts))
 	cmd.AddCommand(NewCmdCreateSpring(commonOpts))
 	cmd.AddCommand(NewCmdCreateStep(commonOpts))
 	cmd.AddCommand(NewCmdCreateTeam(commonOpts))
+	cmd.AddCommand(NewCmdCreateTerraform(commonOpts))
 	cmd.AddCommand(NewCmdCreateToken(commonOpts))
 	cmd.AddCommand(NewCmdCreateTracker(commonOpts))
 	cmd.AddCommand(NewCmdCreateUser(commonOpts))
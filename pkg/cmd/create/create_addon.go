@@ -50,8 +50,12 @@ func NewCmdCreateAddon(commonOpts *opts.CommonOptions) *cobra.Command {
 		},
 	}
 
+	cmd.AddCommand(NewCmdCreateAddonAdmission(commonOpts))
 	cmd.AddCommand(NewCmdCreateAddonAmbassador(commonOpts))
 	cmd.AddCommand(NewCmdCreateAddonAnchore(commonOpts))
+	cmd.AddCommand(NewCmdCreateAddonCloudDB(commonOpts))
+	cmd.AddCommand(NewCmdCreateAddonCosign(commonOpts))
+	cmd.AddCommand(NewCmdCreateAddonCrossplane(commonOpts))
 	cmd.AddCommand(NewCmdCreateAddonEnvironmentController(commonOpts))
 	cmd.AddCommand(NewCmdCreateAddonFlagger(commonOpts))
 	cmd.AddCommand(NewCmdCreateAddonGitea(commonOpts))
@@ -63,6 +67,7 @@ func NewCmdCreateAddon(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(NewCmdCreateAddonPipelineEvents(commonOpts))
 	cmd.AddCommand(NewCmdCreateAddonPrometheus(commonOpts))
 	cmd.AddCommand(NewCmdCreateAddonProw(commonOpts))
+	cmd.AddCommand(NewCmdCreateAddonVelero(commonOpts))
 
 	options.addFlags(cmd, kube.DefaultNamespace, "", "")
 	return cmd
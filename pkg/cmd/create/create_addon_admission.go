@@ -0,0 +1,113 @@
+package create
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/admission"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/create/options"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	createAddonAdmissionLong = templates.LongDesc(`
+		Creates the admission webhook which validates the Environment, SourceRepository and Scheduler resources when they are created or updated
+
+		Invalid promotion strategies, environment kinds and namespaces, permanent environments without a git URL, source repositories without an organisation, repository or git URL and schedulers with invalid jobs are rejected by 'kubectl apply' rather than failing later in the controllers.
+
+		The webhook runs 'jx controller admission' in the dev namespace over HTTPS with a self signed certificate which is reused until it is about to expire.
+
+		By default the resources are admitted when the webhook is unavailable so that it never blocks writes. With --fail-closed they are rejected instead and the webhook runs two replicas protected by a PodDisruptionBudget.
+`)
+
+	createAddonAdmissionExample = templates.Examples(`
+		# Create the admission webhook
+		jx create addon admission
+
+		# Create the admission webhook rejecting the resources when it is unavailable
+		jx create addon admission --fail-closed
+	`)
+)
+
+// CreateAddonAdmissionOptions the options for the create addon admission command
+type CreateAddonAdmissionOptions struct {
+	CreateAddonOptions
+
+	Name       string
+	Image      string
+	FailClosed bool
+}
+
+// NewCmdCreateAddonAdmission creates a command object for the "create addon admission" command
+func NewCmdCreateAddonAdmission(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &CreateAddonAdmissionOptions{
+		CreateAddonOptions: CreateAddonOptions{
+			CreateOptions: options.CreateOptions{
+				CommonOptions: commonOpts,
+			},
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "admission",
+		Short:   "Create the admission webhook which validates the Jenkins X resources",
+		Long:    createAddonAdmissionLong,
+		Example: createAddonAdmissionExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "The namespace to install the webhook into. Defaults to the dev namespace")
+	cmd.Flags().StringVarP(&options.Name, "name", "", admission.DefaultName, "The name of the deployment, service and webhook configuration")
+	cmd.Flags().StringVarP(&options.Image, "image", "", "", fmt.Sprintf("The image of the webhook running jx. Defaults to the version of %s in the version stream or the version of this jx", admission.DefaultImage))
+	cmd.Flags().BoolVarP(&options.FailClosed, "fail-closed", "", false, "Rejects the resources when the webhook is unavailable rather than admitting them")
+	return cmd
+}
+
+// Run implements the command
+func (o *CreateAddonAdmissionOptions) Run() error {
+	kubeClient, devNs, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	ns := o.Namespace
+	if ns == "" {
+		ns = devNs
+	}
+	image := o.Image
+	if image == "" {
+		resolver, err := o.GetVersionResolver()
+		if err != nil {
+			return errors.Wrap(err, "creating the version resolver")
+		}
+		image, err = resolver.ResolveDockerImage(admission.DefaultImage)
+		if err != nil {
+			return errors.Wrapf(err, "resolving the version of image %s", admission.DefaultImage)
+		}
+		// the webhook defaults to the version of this jx if the image is not in the version stream
+		if image == admission.DefaultImage {
+			image = ""
+		}
+	}
+	err = admission.Install(kubeClient, admission.InstallOptions{
+		Name:       o.Name,
+		Namespace:  ns,
+		Image:      image,
+		FailClosed: o.FailClosed,
+	})
+	if err != nil {
+		return errors.Wrap(err, "installing the admission webhook")
+	}
+	log.Logger().Infof("The admission webhook %s in namespace %s validates the %s", util.ColorInfo(o.Name), util.ColorInfo(ns),
+		util.ColorInfo("environments, sourcerepositories and schedulers"))
+	return nil
+}
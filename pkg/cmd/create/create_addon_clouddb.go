@@ -0,0 +1,183 @@
+package create
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/clouddb"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/create/options"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	createAddonCloudDBLong = templates.LongDesc(`
+		Provisions a managed database in the cloud of the team: Cloud SQL on GKE, RDS on EKS or an Azure Database on AKS
+
+		The credentials of the database are written to the Secret <name>-clouddb in the namespace of the environment with the keys host, port, database, username, password and url. The database is tracked by a CloudDatabase resource in the dev namespace so that 'jx delete addon clouddb' deletes it along with its Secret
+`)
+
+	createAddonCloudDBExample = templates.Examples(`
+		# Provision a PostgreSQL database for the apps of staging
+		jx create addon clouddb orders-db --env staging
+
+		# Provision a MySQL database with a bigger tier for production
+		jx create addon clouddb orders-db --env production --engine mysql --tier db-n1-standard-1
+	`)
+)
+
+// CreateAddonCloudDBOptions the options for the create addon clouddb command
+type CreateAddonCloudDBOptions struct {
+	options.CreateOptions
+
+	Environment string
+	Engine      string
+	Version     string
+	Tier        string
+	Database    string
+	Username    string
+
+	// Provisioner provisions the database, defaults to the provisioner of the cloud of the team
+	Provisioner clouddb.Provisioner
+}
+
+// NewCmdCreateAddonCloudDB creates a command object for the "create addon clouddb" command
+func NewCmdCreateAddonCloudDB(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &CreateAddonCloudDBOptions{
+		CreateOptions: options.CreateOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "clouddb <name>",
+		Short:   "Provisions a managed cloud database with its credentials in the namespace of an environment",
+		Aliases: []string{"cloud-db"},
+		Long:    createAddonCloudDBLong,
+		Example: createAddonCloudDBExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Environment, "env", "e", "", "The environment whose namespace gets the Secret of the credentials")
+	cmd.Flags().StringVarP(&options.Engine, "engine", "", clouddb.EnginePostgres, "The engine of the database: "+strings.Join(clouddb.Engines, ", "))
+	cmd.Flags().StringVarP(&options.Version, "version", "v", "", "The version of the engine. Defaults to the version of the cloud provider")
+	cmd.Flags().StringVarP(&options.Tier, "tier", "t", "", "The machine tier or instance class of the database. Defaults to a small one")
+	cmd.Flags().StringVarP(&options.Database, "database", "d", "", "The name of the database created in the instance. Defaults to the name of the instance")
+	cmd.Flags().StringVarP(&options.Username, "username", "u", "", "The name of the user of the apps. Defaults to app")
+	return cmd
+}
+
+// Run implements the command
+func (o *CreateAddonCloudDBOptions) Run() error {
+	if len(o.Args) == 0 {
+		return util.MissingArgument("name")
+	}
+	name := o.Args[0]
+	if o.Environment == "" {
+		return util.MissingOption("env")
+	}
+	jxClient, devNs, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	ns, err := kube.GetEnvironmentNamespace(jxClient, devNs, o.Environment)
+	if err != nil {
+		return err
+	}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return err
+	}
+	settings, err := o.TeamSettings()
+	if err != nil {
+		return errors.Wrap(err, "getting the team settings")
+	}
+	requirements, err := config.GetRequirementsConfigFromTeamSettings(settings)
+	if err != nil {
+		return errors.Wrap(err, "getting the requirements from the team settings")
+	}
+	if requirements == nil {
+		requirements = config.NewRequirementsConfig()
+		requirements.Cluster.Provider = settings.KubeProvider
+	}
+
+	db := clouddb.NewDatabase(requirements, name, o.Engine)
+	db.Environment = o.Environment
+	db.Namespace = ns
+	db.Version = o.Version
+	db.Tier = o.Tier
+	if o.Database != "" {
+		db.Database = o.Database
+	}
+	if o.Username != "" {
+		db.Username = o.Username
+	}
+	err = db.Validate()
+	if err != nil {
+		return err
+	}
+	if o.Provisioner == nil {
+		o.Provisioner, err = clouddb.NewProvisioner(db.Provider)
+		if err != nil {
+			return err
+		}
+	}
+
+	apisClient, err := o.ApiExtensionsClient()
+	if err != nil {
+		return err
+	}
+	err = kube.RegisterCloudDatabaseCRD(apisClient)
+	if err != nil {
+		return errors.Wrap(err, "registering the CloudDatabase CRD")
+	}
+	dynamicClient, _, err := o.GetFactory().CreateDynamicClient()
+	if err != nil {
+		return errors.Wrap(err, "creating the dynamic client")
+	}
+	existing, _, err := clouddb.Get(dynamicClient, devNs, name)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("the database %s already exists, delete it via jx delete addon clouddb %s", name, name)
+	}
+	err = clouddb.Save(dynamicClient, devNs, db, &clouddb.Status{Phase: clouddb.StatusProvisioning})
+	if err != nil {
+		return err
+	}
+
+	password, err := clouddb.GeneratePassword()
+	if err != nil {
+		return err
+	}
+	credentials, err := o.Provisioner.Provision(db, password)
+	if err == nil {
+		err = clouddb.ApplySecret(kubeClient, db, credentials)
+	}
+	if err != nil {
+		saveErr := clouddb.Save(dynamicClient, devNs, db, &clouddb.Status{Phase: clouddb.StatusFailed, Message: err.Error()})
+		if saveErr != nil {
+			log.Logger().Warnf("failed to save the status of database %s: %s", name, saveErr.Error())
+		}
+		return errors.Wrapf(err, "provisioning database %s", name)
+	}
+	err = clouddb.Save(dynamicClient, devNs, db, &clouddb.Status{Phase: clouddb.StatusReady})
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Provisioned database %s, its credentials are in Secret %s in namespace %s", util.ColorInfo(name), util.ColorInfo(db.Secret), util.ColorInfo(ns))
+	return nil
+}
@@ -0,0 +1,224 @@
+package create
+
+import (
+	"io/ioutil"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/create/options"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/signing"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	defaultCosignNamespace  = "cosign-system"
+	defaultCosignRepo       = "https://sigstore.github.io/helm-charts"
+	defaultCosignPolicyName = "jx-signed-images"
+)
+
+var (
+	createAddonCosignLong = templates.LongDesc(`
+		Creates the cosign addon which installs the sigstore policy controller so that the environments only run images signed via 'jx step sign image'
+
+		The pods of the namespaces of the environments are only admitted if their images matching '--images' are signed by the key pair of the team in the Secret jx-cosign of the dev namespace, the public key of '--public-key' or keyless by the identity of '--keyless-subject'
+`)
+
+	createAddonCosignExample = templates.Examples(`
+		# Only run the images of the team signed by its key pair in the permanent environments
+		jx create addon cosign
+
+		# Only run the images signed keyless by the pipelines in production
+		jx create addon cosign --env production --keyless-issuer https://token.actions.githubusercontent.com --keyless-subject https://github.com/myorg/myapp/.github/workflows/release.yaml@refs/heads/master
+	`)
+)
+
+// CreateAddonCosignOptions the options for the create addon cosign command
+type CreateAddonCosignOptions struct {
+	CreateAddonOptions
+
+	Chart          string
+	Environments   []string
+	Images         string
+	PolicyName     string
+	PublicKey      string
+	KeylessIssuer  string
+	KeylessSubject string
+}
+
+// NewCmdCreateAddonCosign creates a command object for the "create addon cosign" command
+func NewCmdCreateAddonCosign(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &CreateAddonCosignOptions{
+		CreateAddonOptions: CreateAddonOptions{
+			CreateOptions: options.CreateOptions{
+				CommonOptions: commonOpts,
+			},
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "cosign",
+		Short:   "Create the cosign addon so that environments only run signed images",
+		Long:    createAddonCosignLong,
+		Example: createAddonCosignExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	options.addFlags(cmd, defaultCosignNamespace, kube.DefaultCosignReleaseName, "")
+
+	cmd.Flags().StringVarP(&options.Chart, optionChart, "c", kube.ChartCosignPolicyController, "The name of the chart to use")
+	cmd.Flags().StringArrayVarP(&options.Environments, "env", "e", nil, "The environments which only run signed images. Defaults to the permanent environments")
+	cmd.Flags().StringVarP(&options.Images, "images", "", "", "The glob of the images which have to be signed. Defaults to the images of the docker registry organisation of the team such as gcr.io/myorg/**")
+	cmd.Flags().StringVarP(&options.PolicyName, "policy", "", defaultCosignPolicyName, "The name of the ClusterImagePolicy")
+	cmd.Flags().StringVarP(&options.PublicKey, "public-key", "", "", "The file of the public key of the signatures. Defaults to the public key in the Secret jx-cosign of the dev namespace")
+	cmd.Flags().StringVarP(&options.KeylessIssuer, "keyless-issuer", "", "", "The OIDC issuer of the identity of keyless signatures")
+	cmd.Flags().StringVarP(&options.KeylessSubject, "keyless-subject", "", "", "The identity of keyless signatures such as the workflow or service account of the pipelines")
+	return cmd
+}
+
+// Run implements the command
+func (o *CreateAddonCosignOptions) Run() error {
+	if o.ReleaseName == "" {
+		return util.MissingOption(optionRelease)
+	}
+	if o.Chart == "" {
+		return util.MissingOption(optionChart)
+	}
+	if (o.KeylessIssuer == "") != (o.KeylessSubject == "") {
+		return errors.New("both --keyless-issuer and --keyless-subject are required for keyless signatures")
+	}
+	kubeClient, devNs, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	policy, err := o.imagePolicy(devNs)
+	if err != nil {
+		return err
+	}
+
+	err = o.EnsureHelm()
+	if err != nil {
+		return errors.Wrap(err, "failed to ensure that Helm is present")
+	}
+	_, err = o.AddHelmBinaryRepoIfMissing(defaultCosignRepo, "sigstore", "", "")
+	if err != nil {
+		return errors.Wrap(err, "adding the sigstore chart repository")
+	}
+	helmOptions := helm.InstallChartOptions{
+		Chart:       o.Chart,
+		ReleaseName: o.ReleaseName,
+		Version:     o.Version,
+		Ns:          o.Namespace,
+		SetValues:   strings.Split(o.SetValues, ","),
+		ValueFiles:  o.ValueFiles,
+	}
+	err = o.InstallChartWithOptions(helmOptions)
+	if err != nil {
+		return errors.Wrap(err, "cosign policy controller deployment failed")
+	}
+
+	dynamicClient, _, err := o.GetFactory().CreateDynamicClient()
+	if err != nil {
+		return errors.Wrap(err, "creating the dynamic client")
+	}
+	err = signing.ApplyImagePolicy(dynamicClient, policy)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Images matching %s have to be signed", util.ColorInfo(policy.Images))
+
+	namespaces, err := o.environmentNamespaces(devNs)
+	if err != nil {
+		return err
+	}
+	patch := []byte(`{"metadata":{"labels":{"` + signing.LabelPolicyInclude + `":"true"}}}`)
+	for _, ns := range namespaces {
+		_, err = kubeClient.CoreV1().Namespaces().Patch(ns, types.MergePatchType, patch)
+		if err != nil {
+			return errors.Wrapf(err, "enabling the verification of signatures in namespace %s", ns)
+		}
+		log.Logger().Infof("Namespace %s only runs signed images", util.ColorInfo(ns))
+	}
+	return nil
+}
+
+// imagePolicy returns the policy of the images with the keyless identity, the public key file or the public key of
+// the team
+func (o *CreateAddonCosignOptions) imagePolicy(devNs string) (*signing.ImagePolicy, error) {
+	policy := &signing.ImagePolicy{
+		Name:    o.PolicyName,
+		Images:  o.Images,
+		Issuer:  o.KeylessIssuer,
+		Subject: o.KeylessSubject,
+	}
+	if policy.Images == "" {
+		registry := o.GetDockerRegistry(nil)
+		org := o.GetDockerRegistryOrg(nil, nil)
+		if registry == "" || org == "" {
+			return nil, util.MissingOption("images")
+		}
+		policy.Images = registry + "/" + org + "/**"
+	}
+	if policy.Issuer != "" {
+		return policy, nil
+	}
+	if o.PublicKey != "" {
+		data, err := ioutil.ReadFile(o.PublicKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading the public key %s", o.PublicKey)
+		}
+		policy.PublicKey = string(data)
+		return policy, nil
+	}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := kubeClient.CoreV1().Secrets(devNs).Get(signing.KeySecretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting the public key of the team in Secret %s in namespace %s, use --public-key or --keyless-subject if there is none",
+			signing.KeySecretName, devNs)
+	}
+	policy.PublicKey = string(secret.Data[signing.SecretKeyPublicKey])
+	return policy, nil
+}
+
+// environmentNamespaces returns the namespaces of the environments or of the permanent environments
+func (o *CreateAddonCosignOptions) environmentNamespaces(devNs string) ([]string, error) {
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return nil, err
+	}
+	envs, err := jxClient.JenkinsV1().Environments(devNs).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the environments in namespace %s", devNs)
+	}
+	answer := []string{}
+	for _, env := range envs.Items {
+		if len(o.Environments) > 0 {
+			if util.StringArrayIndex(o.Environments, env.Name) < 0 {
+				continue
+			}
+		} else if env.Spec.Kind != v1.EnvironmentKindTypePermanent {
+			continue
+		}
+		if env.Spec.Namespace != "" {
+			answer = append(answer, env.Spec.Namespace)
+		}
+	}
+	return answer, nil
+}
@@ -0,0 +1,153 @@
+package create
+
+import (
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cloud"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/create/options"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/crossplane"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultCrossplaneNamespace = "crossplane-system"
+	defaultCrossplaneRepo      = "https://charts.crossplane.io/stable"
+)
+
+var (
+	createAddonCrossplaneLong = templates.LongDesc(`
+		Creates the Crossplane addon so that apps can declare the cloud resources they need such as buckets and databases as claims in their charts
+
+		The Crossplane provider of the cloud of the team is installed unless '--provider-package' is specified. When 'crossplane: true' is set in the jx-requirements.yml file or the addon is enabled via 'jx edit addon crossplane --enabled=true' and the chart of an environment is applied, the claims of its namespace which do not specify a connection secret write their connection details to the Secret <claim>-connection in the namespace of the app
+
+		Use 'jx get cloudresources' to display the claims of the environments
+`)
+
+	createAddonCrossplaneExample = templates.Examples(`
+		# Create the Crossplane addon with the provider of the cloud of the team
+		jx create addon crossplane
+
+		# Create the Crossplane addon with the AWS provider
+		jx create addon crossplane --provider-package xpkg.upbound.io/crossplane-contrib/provider-aws:v0.17.0
+	`)
+
+	defaultCrossplaneProviderPackages = map[string]string{
+		cloud.GKE: "xpkg.upbound.io/crossplane-contrib/provider-gcp:v0.22.0",
+		cloud.EKS: "xpkg.upbound.io/crossplane-contrib/provider-aws:v0.17.0",
+		cloud.AKS: "xpkg.upbound.io/crossplane-contrib/provider-azure:v0.19.0",
+	}
+)
+
+// CreateAddonCrossplaneOptions the options for the create addon crossplane command
+type CreateAddonCrossplaneOptions struct {
+	CreateAddonOptions
+
+	Chart            string
+	ProviderPackages []string
+}
+
+// NewCmdCreateAddonCrossplane creates a command object for the "create addon crossplane" command
+func NewCmdCreateAddonCrossplane(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &CreateAddonCrossplaneOptions{
+		CreateAddonOptions: CreateAddonOptions{
+			CreateOptions: options.CreateOptions{
+				CommonOptions: commonOpts,
+			},
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "crossplane",
+		Short:   "Create the Crossplane addon so that apps can provision cloud resources",
+		Long:    createAddonCrossplaneLong,
+		Example: createAddonCrossplaneExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	options.addFlags(cmd, defaultCrossplaneNamespace, kube.DefaultCrossplaneReleaseName, "")
+
+	cmd.Flags().StringVarP(&options.Chart, optionChart, "c", kube.ChartCrossplane, "The name of the chart to use")
+	cmd.Flags().StringArrayVarP(&options.ProviderPackages, "provider-package", "", nil, "The packages of the Crossplane providers to install. Defaults to the provider of the cloud of the team")
+	return cmd
+}
+
+// Run implements the command
+func (o *CreateAddonCrossplaneOptions) Run() error {
+	if o.ReleaseName == "" {
+		return util.MissingOption(optionRelease)
+	}
+	if o.Chart == "" {
+		return util.MissingOption(optionChart)
+	}
+	packages, err := o.providerPackages()
+	if err != nil {
+		return err
+	}
+
+	err = o.EnsureHelm()
+	if err != nil {
+		return errors.Wrap(err, "failed to ensure that Helm is present")
+	}
+	_, err = o.AddHelmBinaryRepoIfMissing(defaultCrossplaneRepo, "crossplane-stable", "", "")
+	if err != nil {
+		return errors.Wrap(err, "adding the Crossplane chart repository")
+	}
+	helmOptions := helm.InstallChartOptions{
+		Chart:       o.Chart,
+		ReleaseName: o.ReleaseName,
+		Version:     o.Version,
+		Ns:          o.Namespace,
+		SetValues:   strings.Split(o.SetValues, ","),
+		ValueFiles:  o.ValueFiles,
+		Wait:        true,
+	}
+	err = o.InstallChartWithOptions(helmOptions)
+	if err != nil {
+		return errors.Wrap(err, "Crossplane deployment failed")
+	}
+
+	dynamicClient, _, err := o.GetFactory().CreateDynamicClient()
+	if err != nil {
+		return errors.Wrap(err, "creating the dynamic client")
+	}
+	for _, pkg := range packages {
+		name := crossplane.ProviderName(pkg)
+		err = crossplane.ApplyProvider(dynamicClient, name, pkg)
+		if err != nil {
+			return err
+		}
+		log.Logger().Infof("Installed the Crossplane provider %s of package %s", util.ColorInfo(name), util.ColorInfo(pkg))
+	}
+	log.Logger().Infof("Apps can now declare the cloud resources they need as claims in their charts, view them via %s", util.ColorInfo("jx get cloudresources"))
+	return nil
+}
+
+// providerPackages returns the packages of the providers to install defaulting to the provider of the cloud of the team
+func (o *CreateAddonCrossplaneOptions) providerPackages() ([]string, error) {
+	if len(o.ProviderPackages) > 0 {
+		return o.ProviderPackages, nil
+	}
+	settings, err := o.TeamSettings()
+	if err != nil {
+		return nil, errors.Wrap(err, "getting the team settings")
+	}
+	pkg, ok := defaultCrossplaneProviderPackages[settings.KubeProvider]
+	if !ok {
+		log.Logger().Warnf("No default Crossplane provider for kubernetes provider %s, use --provider-package to install one", settings.KubeProvider)
+		return nil, nil
+	}
+	return []string{pkg}, nil
+}
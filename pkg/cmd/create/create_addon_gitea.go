@@ -125,7 +125,7 @@ func (o *CreateAddonGiteaOptions) Run() error {
 				prompt := &survey.Input{
 					Message: "Enter the user name to create in Gitea: ",
 				}
-				err = survey.AskOne(prompt, &o.Username, nil, surveyOpts)
+				err = util.AskOne(prompt, &o.Username, nil, surveyOpts)
 				if err != nil {
 					return err
 				}
@@ -135,7 +135,7 @@ func (o *CreateAddonGiteaOptions) Run() error {
 					prompt := &survey.Password{
 						Message: "Enter the password for the new user in Gitea: ",
 					}
-					err = survey.AskOne(prompt, &o.Password, nil, surveyOpts)
+					err = util.AskOne(prompt, &o.Password, nil, surveyOpts)
 					if err != nil {
 						return err
 					}
@@ -145,7 +145,7 @@ func (o *CreateAddonGiteaOptions) Run() error {
 						prompt := &survey.Input{
 							Message: "Enter the email address of the user to create in Gitea: ",
 						}
-						err = survey.AskOne(prompt, &o.Email, nil, surveyOpts)
+						err = util.AskOne(prompt, &o.Email, nil, surveyOpts)
 						if err != nil {
 							return err
 						}
@@ -0,0 +1,200 @@
+package create
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/create/options"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/kube/velero"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	defaultVeleroNamespace    = "velero"
+	defaultVeleroRepo         = "https://vmware-tanzu.github.io/helm-charts"
+	defaultVeleroSchedule     = "0 */6 * * *"
+	defaultVeleroTTL          = "720h0m0s"
+	defaultVeleroScheduleName = "jx-backups"
+)
+
+var (
+	createAddonVeleroLong = templates.LongDesc(`
+		Creates the Velero addon which backs up the dev namespace, the namespaces of the permanent environments and the cluster resources such as the CRDs to the object storage of the cloud of the team
+
+		The backups are stored in the bucket of the backup storage of the requirements of the team unless '--bucket' is specified. The credentials of Velero are read from the Secret velero-secret of the Velero namespace if it exists, otherwise Velero uses the cloud identity of its service account
+
+		Use 'jx restore cluster' to restore a backup onto a fresh cluster
+`)
+
+	createAddonVeleroExample = templates.Examples(`
+		# Create the Velero addon backing up the jx namespaces every 6 hours
+		jx create addon velero
+
+		# Create the Velero addon backing up the jx namespaces every night to a bucket
+		jx create addon velero --bucket gs://mycluster-backups --schedule "0 2 * * *"
+	`)
+)
+
+// CreateAddonVeleroOptions the options for the create addon velero command
+type CreateAddonVeleroOptions struct {
+	CreateAddonOptions
+
+	Chart    string
+	Bucket   string
+	Schedule string
+	TTL      string
+}
+
+// NewCmdCreateAddonVelero creates a command object for the "create addon velero" command
+func NewCmdCreateAddonVelero(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &CreateAddonVeleroOptions{
+		CreateAddonOptions: CreateAddonOptions{
+			CreateOptions: options.CreateOptions{
+				CommonOptions: commonOpts,
+			},
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "velero",
+		Short:   "Create the Velero addon for scheduled backups of the jx namespaces and CRDs",
+		Long:    createAddonVeleroLong,
+		Example: createAddonVeleroExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	options.addFlags(cmd, defaultVeleroNamespace, kube.DefaultVeleroReleaseName, "")
+
+	cmd.Flags().StringVarP(&options.Chart, optionChart, "c", kube.ChartVelero, "The name of the chart to use")
+	cmd.Flags().StringVarP(&options.Bucket, "bucket", "", "", "The URL of the bucket of the backups such as gs://mybucket. Defaults to the backup storage of the requirements of the team")
+	cmd.Flags().StringVarP(&options.Schedule, "schedule", "", "", "The cron schedule of the backups. Defaults to the schedule of the requirements of the team or "+defaultVeleroSchedule)
+	cmd.Flags().StringVarP(&options.TTL, "ttl", "", "", "How long the backups are kept. Defaults to the ttl of the requirements of the team or "+defaultVeleroTTL)
+	return cmd
+}
+
+// Run implements the command
+func (o *CreateAddonVeleroOptions) Run() error {
+	if o.ReleaseName == "" {
+		return util.MissingOption(optionRelease)
+	}
+	if o.Chart == "" {
+		return util.MissingOption(optionChart)
+	}
+	kubeClient, devNs, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	settings, err := o.TeamSettings()
+	if err != nil {
+		return errors.Wrap(err, "getting the team settings")
+	}
+	requirements, err := config.GetRequirementsConfigFromTeamSettings(settings)
+	if err != nil {
+		return errors.Wrap(err, "getting the requirements from the team settings")
+	}
+	if requirements == nil {
+		requirements = config.NewRequirementsConfig()
+		requirements.Cluster.Provider = settings.KubeProvider
+	}
+	provider, err := velero.GetStorageProvider(requirements.Cluster.Provider)
+	if err != nil {
+		return err
+	}
+	if o.Bucket == "" {
+		o.Bucket = requirements.Storage.Backup.URL
+	}
+	if o.Bucket == "" {
+		return util.MissingOption("bucket")
+	}
+	bucket, err := provider.Bucket(o.Bucket)
+	if err != nil {
+		return err
+	}
+	o.Schedule = util.FirstNotEmptyString(o.Schedule, requirements.Velero.Schedule, defaultVeleroSchedule)
+	o.TTL = util.FirstNotEmptyString(o.TTL, requirements.Velero.TimeToLive, defaultVeleroTTL)
+
+	secret := ""
+	_, err = kubeClient.CoreV1().Secrets(o.Namespace).Get(kube.SecretVelero, metav1.GetOptions{})
+	if err == nil {
+		secret = kube.SecretVelero
+	} else if !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "getting Secret %s in namespace %s", kube.SecretVelero, o.Namespace)
+	}
+	resourceGroup := ""
+	if requirements.Cluster.AzureConfig != nil {
+		resourceGroup = requirements.Cluster.AzureConfig.ResourceGroup
+	}
+	values, err := provider.ChartValues(bucket, requirements.Cluster.Region, resourceGroup, secret)
+	if err != nil {
+		return err
+	}
+	dir, err := ioutil.TempDir("", "velero-values-")
+	if err != nil {
+		return errors.Wrap(err, "creating a temporary directory")
+	}
+	defer os.RemoveAll(dir) //nolint:errcheck
+	valuesFile := filepath.Join(dir, "values.yaml")
+	err = ioutil.WriteFile(valuesFile, values, util.DefaultFileWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "writing %s", valuesFile)
+	}
+
+	err = o.EnsureHelm()
+	if err != nil {
+		return errors.Wrap(err, "failed to ensure that Helm is present")
+	}
+	_, err = o.AddHelmBinaryRepoIfMissing(defaultVeleroRepo, "vmware-tanzu", "", "")
+	if err != nil {
+		return errors.Wrap(err, "adding the Velero chart repository")
+	}
+	helmOptions := helm.InstallChartOptions{
+		Chart:       o.Chart,
+		ReleaseName: o.ReleaseName,
+		Version:     o.Version,
+		Ns:          o.Namespace,
+		SetValues:   strings.Split(o.SetValues, ","),
+		ValueFiles:  append([]string{valuesFile}, o.ValueFiles...),
+		Wait:        true,
+	}
+	err = o.InstallChartWithOptions(helmOptions)
+	if err != nil {
+		return errors.Wrap(err, "Velero deployment failed")
+	}
+
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return err
+	}
+	namespaces, err := velero.BackupNamespaces(jxClient, devNs)
+	if err != nil {
+		return err
+	}
+	dynamicClient, _, err := o.GetFactory().CreateDynamicClient()
+	if err != nil {
+		return errors.Wrap(err, "creating the dynamic client")
+	}
+	err = velero.ApplySchedule(dynamicClient, o.Namespace, defaultVeleroScheduleName, o.Schedule, velero.BackupTemplate(namespaces, o.TTL))
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Namespaces %s and the CRDs are backed up to bucket %s on schedule %s", util.ColorInfo(strings.Join(namespaces, ", ")), util.ColorInfo(bucket), util.ColorInfo(o.Schedule))
+	return nil
+}
@@ -0,0 +1,112 @@
+package create
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/create/options"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/kube/velero"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	createBackupLong = templates.LongDesc(`
+		Creates a Velero backup of the dev namespace, the namespaces of the permanent environments and the cluster resources such as the CRDs
+
+		Use --schedule to create a schedule of backups rather than a single backup. Velero has to be installed first via 'jx create addon velero'
+`)
+
+	createBackupExample = templates.Examples(`
+		# Back up the jx namespaces now, for example before an upgrade
+		jx create backup
+
+		# Back up the jx namespaces every night keeping the backups a week
+		jx create backup nightly --schedule "0 2 * * *" --ttl 168h
+	`)
+)
+
+// CreateBackupOptions the options for the create backup command
+type CreateBackupOptions struct {
+	options.CreateOptions
+
+	Namespace string
+	Schedule  string
+	TTL       string
+}
+
+// NewCmdCreateBackup creates a command object for the "create backup" command
+func NewCmdCreateBackup(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &CreateBackupOptions{
+		CreateOptions: options.CreateOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "backup [name]",
+		Short:   "Creates a backup or a schedule of backups of the jx namespaces and CRDs",
+		Aliases: []string{"backups"},
+		Long:    createBackupLong,
+		Example: createBackupExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", defaultVeleroNamespace, "The namespace Velero is installed in")
+	cmd.Flags().StringVarP(&options.Schedule, "schedule", "", "", "The cron schedule of the backups to create a schedule rather than a single backup")
+	cmd.Flags().StringVarP(&options.TTL, "ttl", "", defaultVeleroTTL, "How long the backups are kept")
+	return cmd
+}
+
+// Run implements the command
+func (o *CreateBackupOptions) Run() error {
+	jxClient, devNs, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	namespaces, err := velero.BackupNamespaces(jxClient, devNs)
+	if err != nil {
+		return err
+	}
+	dynamicClient, _, err := o.GetFactory().CreateDynamicClient()
+	if err != nil {
+		return errors.Wrap(err, "creating the dynamic client")
+	}
+	template := velero.BackupTemplate(namespaces, o.TTL)
+	name := ""
+	if len(o.Args) > 0 {
+		name = o.Args[0]
+	}
+
+	if o.Schedule != "" {
+		if name == "" {
+			name = defaultVeleroScheduleName
+		}
+		err = velero.ApplySchedule(dynamicClient, o.Namespace, name, o.Schedule, template)
+		if err != nil {
+			return err
+		}
+		log.Logger().Infof("Namespaces %s and the CRDs are backed up on schedule %s", util.ColorInfo(strings.Join(namespaces, ", ")), util.ColorInfo(o.Schedule))
+		return nil
+	}
+	if name == "" {
+		name = fmt.Sprintf("jx-%s", time.Now().Format("20060102150405"))
+	}
+	err = velero.CreateBackup(dynamicClient, o.Namespace, name, template)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Created backup %s of namespaces %s and the CRDs", util.ColorInfo(name), util.ColorInfo(strings.Join(namespaces, ", ")))
+	return nil
+}
@@ -13,6 +13,8 @@ import (
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 )
 
@@ -22,12 +24,19 @@ var (
 		
 The pattern should match all the branches you wish to automate CI/CD on when creating or importing projects.
 
+The --protect flag sets the branch protection template of your team, such as the required status checks and reviews, which is applied to the default branch of the application and environment repositories created by jx.
+
 `)
 
 	createBranchPatternExample = templates.Examples(`
 		# Create a branch pattern for your team 
 		jx create branch pattern "master|develop|PR-.*"
 
+		# Protect the master branch of the application and environment repositories created by jx
+		jx create branchpattern --protect --required-check pr-build --required-reviews 1 --dismiss-stale-reviews
+
+		# Stop protecting the repositories created by jx
+		jx create branchpattern --protect=false
 	`)
 )
 
@@ -36,6 +45,8 @@ type BranchPatternOptions struct {
 	options2.CreateOptions
 
 	BranchPattern string
+	Protect       bool
+	Protection    gits.BranchProtection
 }
 
 // NewCmdCreateBranchPattern creates a command object for the "create" command
@@ -60,20 +71,44 @@ func NewCmdCreateBranchPattern(commonOpts *opts.CommonOptions) *cobra.Command {
 		},
 	}
 
+	cmd.Flags().BoolVarP(&options.Protect, "protect", "", false, "Protects the default branch of the repositories created by jx with the branch protection flags. Use --protect=false to stop protecting them")
+	cmd.Flags().StringArrayVarP(&options.Protection.RequiredChecks, "required-check", "", nil, "The status check contexts which have to pass before a Pull Request can be merged")
+	cmd.Flags().BoolVarP(&options.Protection.StrictChecks, "strict-checks", "", false, "Requires branches to be up to date with the protected branch before they can be merged")
+	cmd.Flags().IntVarP(&options.Protection.RequiredReviews, "required-reviews", "", 0, "The number of approving reviews a Pull Request needs before it can be merged")
+	cmd.Flags().BoolVarP(&options.Protection.DismissStaleReviews, "dismiss-stale-reviews", "", false, "Dismisses the approving reviews of a Pull Request when new commits are pushed")
+	cmd.Flags().BoolVarP(&options.Protection.RequireCodeOwnerReviews, "require-code-owner-reviews", "", false, "Requires the approval of the code owners of the changed files")
+	cmd.Flags().BoolVarP(&options.Protection.EnforceAdmins, "enforce-admins", "", false, "Applies the branch protection to the administrators of the repositories too")
 	return cmd
 }
 
 // Run implements the command
 func (o *BranchPatternOptions) Run() error {
-	if len(o.Args) == 0 {
+	protectChanged := o.Cmd != nil && o.Cmd.Flags().Changed("protect")
+	if len(o.Args) == 0 && !protectChanged {
 		return fmt.Errorf("missing argument for the branch pattern")
 	}
-	arg := o.Args[0]
+	if o.Protect {
+		err := o.Protection.Validate()
+		if err != nil {
+			return err
+		}
+	}
 
 	callback := func(env *v1.Environment) error {
-		env.Spec.TeamSettings.BranchPatterns = arg
-		log.Logger().Infof("Setting the team branch pattern to: %s", util.ColorInfo(arg))
-		return nil
+		if len(o.Args) > 0 {
+			arg := o.Args[0]
+			env.Spec.TeamSettings.BranchPatterns = arg
+			log.Logger().Infof("Setting the team branch pattern to: %s", util.ColorInfo(arg))
+		}
+		if !protectChanged {
+			return nil
+		}
+		if !o.Protect {
+			log.Logger().Infof("Repositories created by jx will no longer be protected")
+			return kube.SetBranchProtectionTemplate(env, nil)
+		}
+		log.Logger().Infof("Setting the team branch protection to: %s", util.ColorInfo(o.Protection.Description()))
+		return kube.SetBranchProtectionTemplate(env, &o.Protection)
 	}
 	return o.ModifyDevEnvironment(callback)
 }
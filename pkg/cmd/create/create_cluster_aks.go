@@ -16,6 +16,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
 	"github.com/jenkins-x/jx/v2/pkg/features"
+	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/spf13/cobra"
 )
 
@@ -188,7 +189,7 @@ func (o *CreateClusterAKSOptions) createClusterAKS() error {
 			PageSize: 10,
 			Help:     "location to run cluster",
 		}
-		err := survey.AskOne(prompt, &location, nil, surveyOpts)
+		err := util.AskOne(prompt, &location, nil, surveyOpts)
 		if err != nil {
 			return err
 		}
@@ -204,7 +205,7 @@ func (o *CreateClusterAKSOptions) createClusterAKS() error {
 			Default:  "Standard_D2s_v3",
 		}
 
-		err := survey.AskOne(prompts, &nodeVMSize, nil, surveyOpts)
+		err := util.AskOne(prompts, &nodeVMSize, nil, surveyOpts)
 		if err != nil {
 			return err
 		}
@@ -217,7 +218,7 @@ func (o *CreateClusterAKSOptions) createClusterAKS() error {
 			Default: "3",
 			Help:    "We recommend a minimum of 3 nodes for Jenkins X",
 		}
-		err := survey.AskOne(prompt, &nodeCount, nil, surveyOpts)
+		err := util.AskOne(prompt, &nodeCount, nil, surveyOpts)
 		if err != nil {
 			return err
 		}
@@ -257,7 +257,7 @@ func (o *CreateClusterGKEOptions) createClusterGKE() error {
 					Default: defaultClusterName,
 				}
 
-				err = survey.AskOne(prompt, &o.Flags.ClusterName, nil, surveyOpts)
+				err = util.AskOne(prompt, &o.Flags.ClusterName, nil, surveyOpts)
 				if err != nil {
 					return err
 				}
@@ -297,7 +297,7 @@ func (o *CreateClusterGKEOptions) createClusterGKE() error {
 					Default: clusterType,
 				}
 
-				err = survey.AskOne(prompts, &clusterType, nil, surveyOpts)
+				err = util.AskOne(prompts, &clusterType, nil, surveyOpts)
 				if err != nil {
 					return err
 				}
@@ -340,7 +340,7 @@ func (o *CreateClusterGKEOptions) createClusterGKE() error {
 				PageSize: 10,
 				Default:  defaultMachineType,
 			}
-			err := survey.AskOne(prompts, &machineType, nil, surveyOpts)
+			err := util.AskOne(prompts, &machineType, nil, surveyOpts)
 
 			if err != nil {
 				return err
@@ -366,7 +366,7 @@ func (o *CreateClusterGKEOptions) createClusterGKE() error {
 				Help:    "We recommend a minimum of " + defaultNodes + " for Jenkins X, the minimum number of nodes to be created in each of the cluster's zones",
 			}
 
-			err = survey.AskOne(prompt, &minNumOfNodes, nil, surveyOpts)
+			err = util.AskOne(prompt, &minNumOfNodes, nil, surveyOpts)
 			if err != nil {
 				return err
 			}
@@ -391,7 +391,7 @@ func (o *CreateClusterGKEOptions) createClusterGKE() error {
 				Help:    "We recommend at least " + defaultNodes + " for Jenkins X, the maximum number of nodes to be created in each of the cluster's zones",
 			}
 
-			err = survey.AskOne(prompt, &maxNumOfNodes, nil, surveyOpts)
+			err = util.AskOne(prompt, &maxNumOfNodes, nil, surveyOpts)
 			if err != nil {
 				return err
 			}
@@ -411,7 +411,7 @@ func (o *CreateClusterGKEOptions) createClusterGKE() error {
 					Default: false,
 					Help:    "Preemptible VMs can significantly lower the cost of a cluster",
 				}
-				err = survey.AskOne(prompt, &o.Flags.Preemptible, nil, surveyOpts)
+				err = util.AskOne(prompt, &o.Flags.Preemptible, nil, surveyOpts)
 				if err != nil {
 					return err
 				}
@@ -441,7 +441,7 @@ func (o *CreateClusterGKEOptions) createClusterGKE() error {
 					Default: o.InstallOptions.Flags.DockerRegistry == "",
 					Help:    "Enables enhanced oauth scopes to allow access to storage based services",
 				}
-				err = survey.AskOne(prompt, &o.Flags.EnhancedScopes, nil, surveyOpts)
+				err = util.AskOne(prompt, &o.Flags.EnhancedScopes, nil, surveyOpts)
 				if err != nil {
 					return err
 				}
@@ -474,7 +474,7 @@ func (o *CreateClusterGKEOptions) createClusterGKE() error {
 						Default: o.Flags.EnhancedScopes,
 						Help:    "Enables extra APIs on the GCP project",
 					}
-					err = survey.AskOne(prompt, &o.Flags.EnhancedApis, nil, surveyOpts)
+					err = util.AskOne(prompt, &o.Flags.EnhancedApis, nil, surveyOpts)
 					if err != nil {
 						return err
 					}
@@ -506,7 +506,7 @@ func (o *CreateClusterGKEOptions) createClusterGKE() error {
 						Default: o.Flags.EnhancedScopes,
 						Help:    "Use Kaniko for docker images",
 					}
-					err = survey.AskOne(prompt, &o.InstallOptions.Flags.Kaniko, nil, surveyOpts)
+					err = util.AskOne(prompt, &o.InstallOptions.Flags.Kaniko, nil, surveyOpts)
 					if err != nil {
 						return err
 					}
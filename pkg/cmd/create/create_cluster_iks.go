@@ -202,7 +202,7 @@ func (o *CreateClusterIKSOptions) createClusterIKS() error {
 			Help:     "IBM Cloud Region to authenticate with and create the cluster in:",
 		}
 		var regionstr string
-		err = survey.AskOne(prompt, &regionstr, nil)
+		err = util.AskOne(prompt, &regionstr, nil)
 		c.Region = regionstr
 		if err != nil {
 			return err
@@ -297,7 +297,7 @@ func (o *CreateClusterIKSOptions) createClusterIKS() error {
 			Default: clusterName,
 		}
 		validator := survey.ComposeValidators(survey.Required, survey.MaxLength(clusterMaxLength))
-		err := survey.AskOne(prompt, &clusterName, validator)
+		err := util.AskOne(prompt, &clusterName, validator)
 		if err != nil {
 			return err
 		}
@@ -316,7 +316,7 @@ func (o *CreateClusterIKSOptions) createClusterIKS() error {
 			Default:  "wdc07",
 		}
 		var zonestr string
-		err = survey.AskOne(prompts, &zonestr, nil)
+		err = util.AskOne(prompts, &zonestr, nil)
 		if err != nil {
 			return err
 		}
@@ -345,7 +345,7 @@ func (o *CreateClusterIKSOptions) createClusterIKS() error {
 			PageSize: 10,
 			Default:  defversion,
 		}
-		err = survey.AskOne(prompts, &kubeVersion, nil)
+		err = util.AskOne(prompts, &kubeVersion, nil)
 
 		if err != nil {
 			return err
@@ -364,7 +364,7 @@ func (o *CreateClusterIKSOptions) createClusterIKS() error {
 			Default:  "b2c.4x16",
 		}
 		var machineTypeStr string
-		err = survey.AskOne(prompts, &machineTypeStr, nil)
+		err = util.AskOne(prompts, &machineTypeStr, nil)
 		if err != nil {
 			return err
 		}
@@ -394,7 +394,7 @@ func (o *CreateClusterIKSOptions) createClusterIKS() error {
 			Default: "3",
 		}
 		workers = new(int)
-		err := survey.AskOne(prompt, workers, survey.Required)
+		err := util.AskOne(prompt, workers, survey.Required)
 		if err != nil {
 			return err
 		}
@@ -409,7 +409,7 @@ func (o *CreateClusterIKSOptions) createClusterIKS() error {
 				PageSize: 10,
 				Default:  "",
 			}
-			err = survey.AskOne(prompts, &privateVLAN, nil)
+			err = util.AskOne(prompts, &privateVLAN, nil)
 			if err != nil {
 				return err
 			}
@@ -435,7 +435,7 @@ func (o *CreateClusterIKSOptions) createClusterIKS() error {
 				PageSize: 10,
 				Default:  "",
 			}
-			err = survey.AskOne(prompts, &publicVLAN, nil)
+			err = util.AskOne(prompts, &publicVLAN, nil)
 			if err != nil {
 				return err
 			}
@@ -93,7 +93,7 @@ func (o *CreateDockerAuthOptions) Run() error {
 		prompt := &survey.Password{
 			Message: "Please provide secret for the host: " + o.Host + "  and user: " + o.User,
 		}
-		err := survey.AskOne(prompt, &secret, nil, surveyOpts)
+		err := util.AskOne(prompt, &secret, nil, surveyOpts)
 		if err != nil {
 			return err
 		}
@@ -103,7 +103,7 @@ func (o *CreateDockerAuthOptions) Run() error {
 		prompt := &survey.Input{
 			Message: "Please provide email ID for the host: " + o.Host + "  and user: " + o.User,
 		}
-		err := survey.AskOne(prompt, &email, nil, surveyOpts)
+		err := util.AskOne(prompt, &email, nil, surveyOpts)
 		if err != nil {
 			return err
 		}
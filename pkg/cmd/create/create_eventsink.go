@@ -0,0 +1,133 @@
+package create
+
+import (
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cloudevents"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/create/options"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var (
+	createEventSinkLong = templates.LongDesc(`
+		Creates or updates an HTTP sink which receives the lifecycle events of Jenkins X as CloudEvents.
+
+		The events are posted as JSON in the structured mode of the CloudEvents HTTP binding. The sinks are stored in
+		the ConfigMap ` + cloudevents.ConfigMapName + ` in the dev namespace and an optional bearer token is stored in
+		a Secret.
+
+		The event types are: ` + strings.Join(cloudevents.Types, ", ") + `
+`)
+
+	createEventSinkExample = templates.Examples(`
+		# post all the events to an HTTP endpoint
+		jx create eventsink --name automation --url https://automation.example.com/events
+
+		# post the promotions to an endpoint requiring a bearer token
+		jx create eventsink --name deployments --url https://deploy.example.com/events --token mytoken \
+			--type io.jenkins-x.environment.promoted
+	`)
+)
+
+// CreateEventSinkOptions the options for the create eventsink command
+type CreateEventSinkOptions struct {
+	options.CreateOptions
+
+	Sink  cloudevents.Sink
+	Token string
+}
+
+// NewCmdCreateEventSink creates a command object for the "create eventsink" command
+func NewCmdCreateEventSink(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &CreateEventSinkOptions{
+		CreateOptions: options.CreateOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "eventsink",
+		Short:   "Creates or updates an HTTP sink receiving the lifecycle events of Jenkins X as CloudEvents",
+		Aliases: []string{"eventsinks", "event-sink"},
+		Long:    createEventSinkLong,
+		Example: createEventSinkExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	s := &options.Sink
+	cmd.Flags().StringVarP(&s.Name, "name", "n", "", "The name of the sink")
+	cmd.Flags().StringVarP(&s.URL, "url", "u", "", "The URL the events are posted to")
+	cmd.Flags().StringArrayVarP(&s.Types, "type", "t", nil, "The types of events to post. Defaults to all events")
+	cmd.Flags().StringVarP(&options.Token, "token", "", "", "The bearer token sent with the events")
+	return cmd
+}
+
+// Run implements the command
+func (o *CreateEventSinkOptions) Run() error {
+	s := o.Sink
+	if s.Name == "" && len(o.Args) > 0 {
+		s.Name = o.Args[0]
+	}
+	if s.Name == "" {
+		return util.MissingOption("name")
+	}
+	if s.URL == "" {
+		return util.MissingOption("url")
+	}
+	s.Name = naming.ToValidName(s.Name)
+	err := s.Validate()
+	if err != nil {
+		return err
+	}
+
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	config, err := cloudevents.LoadConfig(kubeClient, ns)
+	if err != nil {
+		return err
+	}
+
+	if o.Token != "" {
+		s.Secret = "jx-event-sink-" + s.Name
+		_, err = kube.DefaultModifySecret(kubeClient, ns, s.Secret, func(secret *corev1.Secret) error {
+			if secret.Data == nil {
+				secret.Data = map[string][]byte{}
+			}
+			secret.Data[cloudevents.SecretKeyToken] = []byte(o.Token)
+			return nil
+		}, nil)
+		if err != nil {
+			return errors.Wrapf(err, "saving the token of sink %s", s.Name)
+		}
+	} else {
+		for _, existing := range config.Sinks {
+			if existing.Name == s.Name {
+				s.Secret = existing.Secret
+			}
+		}
+	}
+
+	config.SetSink(s)
+	err = cloudevents.SaveConfig(kubeClient, ns, config)
+	if err != nil {
+		return errors.Wrap(err, "saving the event sinks")
+	}
+	log.Logger().Infof("Saved the event sink %s posting to %s", util.ColorInfo(s.Name), util.ColorInfo(util.SanitizeURL(s.URL)))
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/nodes"
 	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/util/uuid"
 )
@@ -38,6 +39,10 @@ var (
  		# using browser automation to login to the Git server
 		# with the username and password to find the API Token
 		jx create git token -n local -p somePassword someUserName	
+
+		# Authenticate to GitHub as a GitHub App using its installation tokens
+		# instead of a personal API Token
+		jx create git token -n GitHub --app-id 1234 --app-private-key-file my-app.private-key.pem --app-owner myorg
 	`)
 )
 
@@ -50,6 +55,10 @@ type CreateGitTokenOptions struct {
 	Password    string
 	ApiToken    string
 	Timeout     string
+
+	AppID             int64
+	AppPrivateKeyFile string
+	AppOwner          string
 }
 
 // NewCmdCreateGitToken creates a command
@@ -77,6 +86,9 @@ func NewCmdCreateGitToken(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.ApiToken, "api-token", "t", "", "The API Token for the user")
 	cmd.Flags().StringVarP(&options.Password, "password", "p", "", "The User password to try automatically create a new API Token")
 	cmd.Flags().StringVarP(&options.Timeout, "timeout", "", "", "The timeout if using browser automation to generate the API token (by passing username and password)")
+	cmd.Flags().Int64VarP(&options.AppID, "app-id", "", 0, "The ID of the GitHub App to authenticate as instead of using an API Token")
+	cmd.Flags().StringVarP(&options.AppPrivateKeyFile, "app-private-key-file", "", "", "The file containing the PEM encoded private key of the GitHub App")
+	cmd.Flags().StringVarP(&options.AppOwner, "app-owner", "", "", "The organisation or user the GitHub App is installed on. Only required if the app has more than one installation")
 
 	return cmd
 }
@@ -105,6 +117,10 @@ func (o *CreateGitTokenOptions) Run() error {
 		return err
 	}
 
+	if o.AppID != 0 {
+		return o.saveGitHubAppAuth(authConfigSvc, server)
+	}
+
 	// TODO add the API thingy...
 	if o.Username == "" {
 		return fmt.Errorf("No Username specified")
@@ -152,6 +168,44 @@ func (o *CreateGitTokenOptions) Run() error {
 	return nil
 }
 
+// saveGitHubAppAuth validates the GitHub App credentials by creating an installation token then saves them
+func (o *CreateGitTokenOptions) saveGitHubAppAuth(authConfigSvc auth.ConfigService, server *auth.AuthServer) error {
+	if server.Kind != gits.KindGitHub {
+		return fmt.Errorf("GitHub App authentication is not supported for %s server %s", server.Kind, server.Label())
+	}
+	if o.AppPrivateKeyFile == "" {
+		return util.MissingOption("app-private-key-file")
+	}
+	privateKey, err := ioutil.ReadFile(o.AppPrivateKeyFile)
+	if err != nil {
+		return errors.Wrapf(err, "reading the GitHub App private key file %s", o.AppPrivateKeyFile)
+	}
+	if o.Username == "" {
+		o.Username = auth.GitHubAppUsername
+	}
+	config := authConfigSvc.Config()
+	userAuth := config.GetOrCreateUserAuth(server.URL, o.Username)
+	userAuth.GithubAppID = o.AppID
+	userAuth.GithubAppPrivateKey = string(privateKey)
+	userAuth.GithubAppOwner = o.AppOwner
+
+	_, err = gits.GitHubAppToken(server.URL, userAuth)
+	if err != nil {
+		return errors.Wrapf(err, "validating GitHub App %d", o.AppID)
+	}
+
+	server.CurrentUser = userAuth.Username
+	config.CurrentServer = server.URL
+	err = authConfigSvc.SaveConfig()
+	if err != nil {
+		return err
+	}
+
+	log.Logger().Infof("Created GitHub App %s authentication for Git server %s at %s",
+		util.ColorInfo(o.AppID), util.ColorInfo(server.Name), util.ColorInfo(server.URL))
+	return nil
+}
+
 // lets try use the users browser to find the API token
 func (o *CreateGitTokenOptions) tryFindAPITokenFromBrowser(tokenUrl string, userAuth *auth.UserAuth) error {
 	var ctxt context.Context
@@ -7,6 +7,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -24,6 +25,12 @@ var (
 		# Add a new Git server with a name
 		jx create git server -k bitbucketcloud -u http://bitbucket.org -n MyBitBucket 
 
+		# Add an Azure DevOps organization whose projects own the repositories
+		jx create git server -k azuredevops -u https://dev.azure.com/myorg -n MyAzureDevOps
+
+		# Add the AWS CodeCommit server of a region using the IAM credentials of the AWS profile for its API
+		jx create git server -k codecommit -u https://git-codecommit.us-east-1.amazonaws.com
+
 		For more documentation see: [https://jenkins-x.io/developing/git/](https://jenkins-x.io/developing/git/)
 
 	`)
@@ -37,11 +44,12 @@ var (
 type CreateGitServerOptions struct {
 	options.CreateOptions
 
-	Name   string
-	Kind   string
-	URL    string
-	User   string
-	Secret string
+	Name           string
+	Kind           string
+	URL            string
+	User           string
+	Secret         string
+	SkipValidation bool
 }
 
 // NewCmdCreateGitServer creates a command object for the "create" command
@@ -71,6 +79,7 @@ func NewCmdCreateGitServer(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.URL, "url", "u", "", "The git server URL")
 	cmd.Flags().StringVarP(&options.User, "apiuser", "a", "", "The git server api user")
 	cmd.Flags().StringVarP(&options.Secret, "secret", "s", "", "The git server api user secret")
+	cmd.Flags().BoolVarP(&options.SkipValidation, "skip-validation", "", false, "Skips checking the git server URL and api user secret before adding the server")
 	return cmd
 }
 
@@ -123,6 +132,13 @@ func (o *CreateGitServerOptions) Run() error {
 		ApiToken: secret,
 	}
 
+	if kind == gits.KindGitlab && !o.SkipValidation {
+		err := gits.ValidateGitlabServer(&auth.AuthServer{URL: gitUrl, Name: name, Kind: kind}, initUser)
+		if err != nil {
+			return errors.Wrap(err, "validating the GitLab server, use --skip-validation to add it anyway")
+		}
+	}
+
 	authConfigSvc, err := o.GitAuthConfigService()
 	if err != nil {
 		return errors.Wrap(err, "failed to create CreateGitAuthConfigService")
@@ -86,7 +86,7 @@ func (o *CreateGkeServiceAccountOptions) Run() error {
 			Message: "Name for the service account",
 		}
 
-		err := survey.AskOne(prompt, &o.Flags.Name, func(val interface{}) error {
+		err := util.AskOne(prompt, &o.Flags.Name, func(val interface{}) error {
 			// since we are validating an Input, the assertion will always succeed
 			if str, ok := val.(string); !ok || len(str) < 6 {
 				return errors.New("Service Account name must be longer than 5 characters")
@@ -133,7 +133,7 @@ func (o *CreateGkeServiceAccountOptions) getGoogleProjectId() (string, error) {
 			Default: true,
 		}
 		flag := true
-		err = survey.AskOne(confirm, &flag, nil, surveyOpts)
+		err = util.AskOne(confirm, &flag, nil, surveyOpts)
 		if err != nil {
 			return "", err
 		}
@@ -154,7 +154,7 @@ func (o *CreateGkeServiceAccountOptions) getGoogleProjectId() (string, error) {
 			Help:    "Select a Google Project to create the cluster in",
 		}
 
-		err := survey.AskOne(prompts, &projectId, nil, surveyOpts)
+		err := util.AskOne(prompts, &projectId, nil, surveyOpts)
 		if err != nil {
 			return "", err
 		}
@@ -0,0 +1,157 @@
+package create
+
+import (
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/create/options"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
+	"github.com/jenkins-x/jx/v2/pkg/notify"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/AlecAivazis/survey.v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var (
+	createNotificationLong = templates.LongDesc(`
+		Creates or updates a notification which sends pipeline and promotion events to a Slack, Microsoft Teams or
+		Discord webhook.
+
+		The webhook URL is stored in a Secret and the notifications are stored in the ConfigMap ` + notify.ConfigMapName + `
+		in the dev namespace. Notifications can be limited to some types of events, to the promotions to some
+		environments and to some repositories. The message is rendered from an optional Go template with the fields
+		Type, Owner, Repository, Branch, Build, Version, Status, Environment, Duration, URL and ApplicationURL.
+
+		The event types are: ` + strings.Join(notify.EventTypes, ", ") + `
+`)
+
+	createNotificationExample = templates.Examples(`
+		# send all the events to a Slack channel
+		jx create notification --name team --kind slack --url https://hooks.slack.com/services/T000/B000/XXXX
+
+		# send the failed pipelines and the production promotions to Microsoft Teams
+		jx create notification --name prod --kind teams --url https://outlook.office.com/webhook/XXXX \
+			--events pipeline-failed --events promotion-succeeded --events promotion-failed --environment production
+
+		# use a custom message for a Discord channel
+		jx create notification --name discord --kind discord --url https://discord.com/api/webhooks/XXXX \
+			--template '{{.Repository}} {{.Version}} is now in {{.Environment}}' --events promotion-succeeded
+	`)
+)
+
+// CreateNotificationOptions the options for the create notification command
+type CreateNotificationOptions struct {
+	options.CreateOptions
+
+	Notification notify.Notification
+	URL          string
+}
+
+// NewCmdCreateNotification creates a command object for the "create notification" command
+func NewCmdCreateNotification(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &CreateNotificationOptions{
+		CreateOptions: options.CreateOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "notification",
+		Short:   "Creates or updates a notification sending pipeline and promotion events to Slack, Teams or Discord",
+		Aliases: []string{"notifications", "notify"},
+		Long:    createNotificationLong,
+		Example: createNotificationExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	n := &options.Notification
+	cmd.Flags().StringVarP(&n.Name, "name", "n", "", "The name of the notification")
+	cmd.Flags().StringVarP(&n.Kind, "kind", "k", notify.KindSlack, "The kind of webhook. One of: "+strings.Join(notify.Kinds, ", "))
+	cmd.Flags().StringVarP(&options.URL, "url", "u", "", "The webhook URL the events are posted to")
+	cmd.Flags().StringArrayVarP(&n.Events, "events", "e", nil, "The types of events to send. Defaults to all events")
+	cmd.Flags().StringArrayVarP(&n.Environments, "environment", "", nil, "Only sends the promotions to these environments. Defaults to all environments")
+	cmd.Flags().StringArrayVarP(&n.Repositories, "repository", "r", nil, "Only sends the events of these owner/repository names. Defaults to all repositories")
+	cmd.Flags().StringVarP(&n.Template, "template", "t", "", "The Go template of the message. Defaults to a message for each type of event")
+	return cmd
+}
+
+// Run implements the command
+func (o *CreateNotificationOptions) Run() error {
+	n := o.Notification
+	if n.Name == "" && len(o.Args) > 0 {
+		n.Name = o.Args[0]
+	}
+	if n.Name == "" {
+		return util.MissingOption("name")
+	}
+	n.Name = naming.ToValidName(n.Name)
+	err := n.Validate()
+	if err != nil {
+		return err
+	}
+
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	config, err := notify.LoadConfig(kubeClient, ns)
+	if err != nil {
+		return err
+	}
+
+	n.Secret = "jx-notification-" + n.Name
+	url := o.URL
+	if url == "" {
+		exists := false
+		for _, existing := range config.Notifications {
+			if existing.Name == n.Name {
+				exists = true
+			}
+		}
+		if exists {
+			log.Logger().Infof("Keeping the webhook URL of notification %s", util.ColorInfo(n.Name))
+		} else {
+			if o.BatchMode {
+				return util.MissingOption("url")
+			}
+			prompt := &survey.Password{
+				Message: "The " + n.Kind + " webhook URL the events are posted to:",
+			}
+			err = util.AskOne(prompt, &url, survey.Required, survey.WithStdio(o.In, o.Out, o.Err))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if url != "" {
+		_, err = kube.DefaultModifySecret(kubeClient, ns, n.Secret, func(secret *corev1.Secret) error {
+			if secret.Data == nil {
+				secret.Data = map[string][]byte{}
+			}
+			secret.Data[notify.SecretKeyURL] = []byte(url)
+			return nil
+		}, nil)
+		if err != nil {
+			return errors.Wrapf(err, "saving the webhook URL of notification %s", n.Name)
+		}
+	}
+
+	config.SetNotification(n)
+	err = notify.SaveConfig(kubeClient, ns, config)
+	if err != nil {
+		return errors.Wrap(err, "saving the notifications")
+	}
+	log.Logger().Infof("Saved the %s notification %s", n.Kind, util.ColorInfo(n.Name))
+	return nil
+}
@@ -36,6 +36,9 @@ var (
 		This will create a new project for you from the selected template.
 		It will exclude any work-in-progress repos (containing the "WIP-" pattern)
 
+		Use --catalog to browse the quickstarts of a catalog registered by your team via 'jx create quickstartcatalog'
+		instead, such as the repositories of your own git organisation or the quickstarts listed in a catalog YAML file.
+
 		For more documentation see: [https://jenkins-x.io/developing/create-quickstart/](https://jenkins-x.io/developing/create-quickstart/)
 
 ` + helper.SeeAlsoText("jx create project"))
@@ -49,6 +52,12 @@ var (
 		jx create quickstart
 
 		jx create quickstart -f http
+
+		# Browse the quickstarts of a catalog registered by your team via 'jx create quickstartcatalog'
+		jx create quickstart --catalog mycompany
+
+		# Filter the quickstarts of a catalog by tag
+		jx create quickstart --catalog mycompany -t spring
 	`)
 )
 
@@ -90,6 +99,7 @@ func NewCmdCreateQuickstart(commonOpts *opts.CommonOptions) *cobra.Command {
 
 	cmd.Flags().StringArrayVarP(&options.GitHubOrganisations, "organisations", "g", []string{}, "The GitHub organisations to query for quickstarts")
 	cmd.Flags().StringArrayVarP(&options.Filter.Tags, "tag", "t", []string{}, "The tags on the quickstarts to filter")
+	cmd.Flags().StringArrayVarP(&options.Filter.Catalogs, "catalog", "", []string{}, "The quickstart catalogs registered by the team to browse instead of the default quickstarts")
 	cmd.Flags().StringVarP(&options.Filter.Owner, "owner", "", "", "The owner to filter on")
 	cmd.Flags().StringVarP(&options.Filter.Language, "language", "l", "", "The language to filter on")
 	cmd.Flags().StringVarP(&options.Filter.Framework, "framework", "", "", "The framework to filter on")
@@ -102,7 +112,13 @@ func NewCmdCreateQuickstart(commonOpts *opts.CommonOptions) *cobra.Command {
 
 // Run implements the generic Create command
 func (o *CreateQuickstartOptions) Run() error {
-	model, err := o.LoadQuickStartsModel(o.GitHubOrganisations, o.IgnoreTeam)
+	var model *quickstarts.QuickstartModel
+	var err error
+	if len(o.Filter.Catalogs) > 0 {
+		model, err = o.LoadQuickStartCatalogsModel(o.Filter.Catalogs)
+	} else {
+		model, err = o.LoadQuickStartsModel(o.GitHubOrganisations, o.IgnoreTeam)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to load quickstarts: %s", err)
 	}
@@ -0,0 +1,136 @@
+package create
+
+import (
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/create/options"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/quickstarts"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	createQuickstartCatalogLong = templates.LongDesc(`
+		Registers a catalog of quickstarts for your team which can be browsed via 'jx create quickstart --catalog'
+
+		The quickstarts of a catalog are either the repositories of a git organisation or are listed in the 'quickstarts'
+		of a catalog YAML file with their name, description, language, framework, tags, downloadZipURL and minJxVersion.
+
+		Quickstarts which need a newer version of jx than the one being used are not shown.
+`)
+
+	createQuickstartCatalogExample = templates.Examples(`
+		# Register the repositories of a GitHub organisation as a catalog
+		jx create quickstartcatalog mycompany --owner mycompany-quickstarts
+
+		# Register a catalog YAML file
+		jx create quickstartcatalog mycompany --url https://raw.githubusercontent.com/mycompany/quickstarts/master/catalog.yaml
+
+		# Browse the catalog
+		jx create quickstart --catalog mycompany
+	`)
+)
+
+// CreateQuickstartCatalogOptions the options for the create quickstartcatalog command
+type CreateQuickstartCatalogOptions struct {
+	options.CreateOptions
+
+	Catalog quickstarts.QuickstartCatalog
+}
+
+// NewCmdCreateQuickstartCatalog creates a command object for the "create quickstartcatalog" command
+func NewCmdCreateQuickstartCatalog(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &CreateQuickstartCatalogOptions{
+		CreateOptions: options.CreateOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     opts.QuickStartCatalogCommandName + " NAME",
+		Short:   "Registers a catalog of quickstarts for your team",
+		Aliases: opts.QuickStartCatalogCommandAliases,
+		Long:    createQuickstartCatalogLong,
+		Example: createQuickstartCatalogExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Catalog.Description, "description", "d", "", "The description of the catalog")
+	cmd.Flags().StringVarP(&options.Catalog.URL, "url", "u", "", "The URL or file name of the catalog YAML file")
+	cmd.Flags().StringVarP(&options.Catalog.GitURL, "git-url", "", "", "The URL of the Git service of the organisation. Defaults to GitHub")
+	cmd.Flags().StringVarP(&options.Catalog.GitKind, optionGitKind, "k", "", "The kind of Git service of the organisation")
+	cmd.Flags().StringVarP(&options.Catalog.Owner, optionOwner, "o", "", "The user or organisation whose repositories are the quickstarts of the catalog")
+	cmd.Flags().StringArrayVarP(&options.Catalog.Includes, "includes", "i", []string{"*"}, "The patterns to include repositories")
+	cmd.Flags().StringArrayVarP(&options.Catalog.Excludes, "excludes", "x", []string{"WIP-*"}, "The patterns to exclude repositories")
+
+	return cmd
+}
+
+// Run implements the command
+func (o *CreateQuickstartCatalogOptions) Run() error {
+	if len(o.Args) == 0 {
+		return util.MissingArgument("name")
+	}
+	if len(o.Args) > 1 {
+		return errors.Errorf("only one catalog name can be given but got %v", o.Args)
+	}
+	catalog := o.Catalog
+	catalog.Name = o.Args[0]
+	if catalog.URL != "" {
+		// the includes and excludes only apply to git organisations
+		catalog.Includes = nil
+		catalog.Excludes = nil
+		_, err := quickstarts.LoadCatalogFile(catalog.URL)
+		if err != nil {
+			return err
+		}
+	} else if catalog.Owner != "" && catalog.GitURL != "" && catalog.GitKind == "" {
+		authConfigSvc, err := o.GitAuthConfigService()
+		if err != nil {
+			return err
+		}
+		server := authConfigSvc.Config().GetServer(catalog.GitURL)
+		if server == nil {
+			return util.MissingOption(optionGitKind)
+		}
+		catalog.GitKind = server.Kind
+	}
+	err := catalog.Validate()
+	if err != nil {
+		return err
+	}
+
+	callback := func(env *v1.Environment) error {
+		catalogs, err := kube.GetQuickstartCatalogs(env)
+		if err != nil {
+			return err
+		}
+		found := false
+		for i := range catalogs {
+			if catalogs[i].Name == catalog.Name {
+				catalogs[i] = catalog
+				found = true
+			}
+		}
+		if !found {
+			catalogs = append(catalogs, catalog)
+		}
+		err = kube.SetQuickstartCatalogs(env, catalogs)
+		if err != nil {
+			return err
+		}
+		log.Logger().Infof("Registered the quickstart catalog %s from %s", util.ColorInfo(catalog.Name), util.ColorInfo(catalog.Source()))
+		return nil
+	}
+	return o.ModifyDevEnvironment(callback)
+}
@@ -0,0 +1,143 @@
+package create
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/create/options"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/registries"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	createRegistryLong = templates.LongDesc(`
+		Creates the repository of an image in the container registry unless it exists.
+
+		Amazon ECR repositories, Google Artifact Registry repositories and Harbor projects are created. Google Container
+		Registry, Azure Container Registry and Docker Hub create the repositories when the first image is pushed.
+
+		The short-lived pull and push credentials of the registry can be written into a docker config.json such as the
+		config of kaniko.
+`)
+
+	createRegistryExample = templates.Examples(`
+		# creates the repository of the image of the application in the registry of the team
+		jx create registry myorg/myapp
+
+		# creates the repository in Harbor and writes the credentials of a robot account into the kaniko config
+		jx create registry myproject/myapp --kind harbor --host harbor.example.com --docker-config /kaniko/.docker/config.json
+	`)
+)
+
+// CreateRegistryOptions the options for the create registry command
+type CreateRegistryOptions struct {
+	options.CreateOptions
+	opts.RegistryFlags
+
+	Repository   string
+	DockerConfig string
+}
+
+// NewCmdCreateRegistry creates a command object for the "create registry" command
+func NewCmdCreateRegistry(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &CreateRegistryOptions{
+		CreateOptions: options.CreateOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "registry <repository>",
+		Short:   "Creates the repository of an image in the container registry",
+		Long:    createRegistryLong,
+		Example: createRegistryExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	options.AddRegistryFlags(cmd)
+	cmd.Flags().StringVarP(&options.Repository, "repository", "r", "", "The repository of the image within the registry such as myorg/myapp")
+	cmd.Flags().StringVarP(&options.DockerConfig, "docker-config", "", "", "The docker config.json the short-lived credentials of the registry are written to")
+	return cmd
+}
+
+// Run implements the command
+func (o *CreateRegistryOptions) Run() error {
+	if o.Repository == "" && len(o.Args) > 0 {
+		o.Repository = o.Args[0]
+	}
+	if o.Repository == "" {
+		return util.MissingArgument("repository")
+	}
+	registry, err := o.NewRegistry(&o.RegistryFlags)
+	if err != nil {
+		return err
+	}
+	err = registry.CreateRepository(o.Repository)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("The repository %s is available in the %s registry %s", util.ColorInfo(o.Repository), registry.Kind(), util.ColorInfo(registry.Host()))
+	if o.DockerConfig == "" {
+		return nil
+	}
+	credentials, err := registry.Credentials(o.Repository)
+	if err != nil {
+		return err
+	}
+	err = writeDockerConfigAuth(o.DockerConfig, registry.Host(), credentials)
+	if err != nil {
+		return err
+	}
+	if credentials.Expiry != nil {
+		log.Logger().Infof("Wrote the credentials of %s which expire at %s to %s", registry.Host(), credentials.Expiry.String(), util.ColorInfo(o.DockerConfig))
+	} else {
+		log.Logger().Infof("Wrote the credentials of %s to %s", registry.Host(), util.ColorInfo(o.DockerConfig))
+	}
+	return nil
+}
+
+// writeDockerConfigAuth adds the credentials of the host to the auths of the docker config.json keeping its other
+// settings
+func writeDockerConfigAuth(fileName string, host string, credentials *registries.Credentials) error {
+	config := map[string]interface{}{}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "reading %s", fileName)
+	}
+	if err == nil && len(data) > 0 {
+		err = json.Unmarshal(data, &config)
+		if err != nil {
+			return errors.Wrapf(err, "parsing %s", fileName)
+		}
+	}
+	auths, _ := config["auths"].(map[string]interface{})
+	if auths == nil {
+		auths = map[string]interface{}{}
+	}
+	auths[host] = map[string]string{
+		"auth": base64.StdEncoding.EncodeToString([]byte(credentials.Username + ":" + credentials.Password)),
+	}
+	config["auths"] = auths
+	data, err = json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshalling the docker config")
+	}
+	err = os.MkdirAll(filepath.Dir(fileName), util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "creating the directory of %s", fileName)
+	}
+	return ioutil.WriteFile(fileName, data, 0600)
+}
@@ -0,0 +1,39 @@
+// +build unit
+
+package create
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/registries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDockerConfigAuth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-docker-config-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fileName := filepath.Join(dir, ".docker", "config.json")
+	err = os.MkdirAll(filepath.Dir(fileName), 0700)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(fileName, []byte(`{"auths":{"gcr.io":{"auth":"b2xkOnRva2Vu"}},"credsStore":"desktop"}`), 0600)
+	require.NoError(t, err)
+
+	err = writeDockerConfigAuth(fileName, "harbor.example.com", &registries.Credentials{Username: "robot$jx", Password: "s3cret"})
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(fileName)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"auths": {
+			"gcr.io": {"auth": "b2xkOnRva2Vu"},
+			"harbor.example.com": {"auth": "cm9ib3Qkang6czNjcmV0"}
+		},
+		"credsStore": "desktop"
+	}`, string(data))
+}
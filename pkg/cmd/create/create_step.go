@@ -192,7 +192,7 @@ func (o *CreateStepOptions) configureNewStepDetails(stepDetails *NewStepDetails)
 			Message: "Command for the new step: ",
 			Help:    "The shell command executed inside the container to implement this step",
 		}
-		err := survey.AskOne(prompt, &s.Step.Command, survey.Required, survey.WithStdio(o.In, o.Out, o.Err))
+		err := util.AskOne(prompt, &s.Step.Command, survey.Required, survey.WithStdio(o.In, o.Out, o.Err))
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,137 @@
+package create
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cloud"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/create/options"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/terraform"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	createTerraformLong = templates.LongDesc(`
+		Generates the Terraform module of the cluster, DNS zone, storage buckets and IAM needed by Jenkins X so that
+		the infrastructure is managed as code rather than created via gcloud, eksctl or az.
+
+		Use --apply to run 'terraform init' and 'terraform apply' on the generated module. The outputs of the applied
+		module are written to a jx-requirements.yml file which can be passed to 'jx init --config'.
+`)
+
+	createTerraformExample = templates.Examples(`
+		# Generate the Terraform module of a GKE cluster
+		jx create terraform --provider gke --cluster-name mycluster --project-id myproject --zone europe-west1-b --region europe-west1
+
+		# Generate and apply the Terraform module of an EKS cluster then initialise it
+		jx create terraform --provider eks --cluster-name mycluster --region us-east-1 --apply --dir infra
+		jx init --config infra/jx-requirements.yml
+	`)
+
+	defaultTerraformMachineTypes = map[string]string{
+		cloud.GKE: "n1-standard-2",
+		cloud.EKS: "m5.large",
+		cloud.AKS: "Standard_D2s_v3",
+	}
+)
+
+// CreateTerraformOptions the options for the create terraform command
+type CreateTerraformOptions struct {
+	options.CreateOptions
+
+	Config terraform.Config
+	Dir    string
+	Apply  bool
+}
+
+// NewCmdCreateTerraform creates a command object for the "create terraform" command
+func NewCmdCreateTerraform(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &CreateTerraformOptions{
+		CreateOptions: options.CreateOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "terraform",
+		Short:   "Generates the Terraform module of the infrastructure needed by Jenkins X",
+		Aliases: []string{"tf"},
+		Long:    createTerraformLong,
+		Example: createTerraformExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Config.Provider, "provider", "", "", "The kubernetes provider. Supported providers: "+strings.Join(terraform.SupportedProviders(), ", "))
+	cmd.Flags().StringVarP(&options.Config.ClusterName, "cluster-name", "n", "", "The name of the cluster")
+	cmd.Flags().StringVarP(&options.Config.ProjectID, "project-id", "p", "", "The GCP project of the cluster")
+	cmd.Flags().StringVarP(&options.Config.ResourceGroup, "resource-group", "", "", "The Azure resource group of the cluster")
+	cmd.Flags().StringVarP(&options.Config.Region, "region", "r", "", "The cloud region of the cluster")
+	cmd.Flags().StringVarP(&options.Config.Zone, "zone", "z", "", "The zone of the GKE cluster")
+	cmd.Flags().StringVarP(&options.Config.Domain, "domain", "", "", "The domain whose DNS zone is created")
+	cmd.Flags().StringVarP(&options.Config.Namespace, "namespace", "", "jx", "The namespace Jenkins X is installed in")
+	cmd.Flags().StringVarP(&options.Config.MachineType, "machine-type", "m", "", "The machine type of the nodes. Defaults to a type suitable for the provider")
+	cmd.Flags().IntVarP(&options.Config.MinNodes, "min-num-nodes", "", 3, "The minimum number of nodes")
+	cmd.Flags().IntVarP(&options.Config.MaxNodes, "max-num-nodes", "", 5, "The maximum number of nodes")
+	cmd.Flags().BoolVarP(&options.Config.ForceDestroy, "force-destroy", "", false, "Lets Terraform destroy the storage buckets even if they are not empty")
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", "terraform", "The directory the Terraform module is generated in")
+	cmd.Flags().BoolVarP(&options.Apply, "apply", "", false, "Runs terraform init and apply on the generated module and writes the jx-requirements.yml of the cluster")
+
+	return cmd
+}
+
+// Run implements the command
+func (o *CreateTerraformOptions) Run() error {
+	c := &o.Config
+	if c.Provider == "" {
+		if o.BatchMode {
+			return util.MissingOption("provider")
+		}
+		provider, err := util.PickName(terraform.SupportedProviders(), "Select the kubernetes provider: ", "", o.GetIOFileHandles())
+		if err != nil {
+			return err
+		}
+		c.Provider = provider
+	}
+	if c.MachineType == "" {
+		c.MachineType = defaultTerraformMachineTypes[c.Provider]
+	}
+	files, err := terraform.Generate(o.Dir, c)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Generated the Terraform module of the %s cluster %s:", c.Provider, util.ColorInfo(c.ClusterName))
+	for _, f := range files {
+		log.Logger().Infof("  %s", f)
+	}
+	if !o.Apply {
+		log.Logger().Infof("Apply it via %s then write the jx-requirements.yml of the cluster via %s", util.ColorInfo("terraform apply"), util.ColorInfo("jx create terraform --apply"))
+		return nil
+	}
+
+	err = terraform.Apply(o.Dir)
+	if err != nil {
+		return err
+	}
+	requirements, err := terraform.LoadRequirements(o.Dir)
+	if err != nil {
+		return err
+	}
+	fileName := filepath.Join(o.Dir, config.RequirementsConfigFileName)
+	err = requirements.SaveConfig(fileName)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Saved the requirements of the cluster to %s, initialise the cluster via %s", util.ColorInfo(fileName), util.ColorInfo("jx init --config "+fileName))
+	return nil
+}
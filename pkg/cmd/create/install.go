@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -62,6 +63,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cloud/aks"
 	"github.com/jenkins-x/jx/v2/pkg/cloud/amazon"
 	"github.com/jenkins-x/jx/v2/pkg/cloud/iks"
+	"github.com/jenkins-x/jx/v2/pkg/cloud/workloadidentity"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
 	"github.com/jenkins-x/jx/v2/pkg/config"
@@ -70,6 +72,8 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/helm"
 	configio "github.com/jenkins-x/jx/v2/pkg/io"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/profiles"
+	"github.com/jenkins-x/jx/v2/pkg/tekton"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	pkgvault "github.com/jenkins-x/jx/v2/pkg/vault"
 	"github.com/pkg/errors"
@@ -105,6 +109,7 @@ type InstallOptions struct {
 	modifySecretCallback    ModifySecretCallback
 
 	installValues map[string]string
+	profile       *profiles.InstallProfile
 }
 
 // InstallFlags flags for the install command
@@ -125,6 +130,7 @@ type InstallFlags struct {
 	Namespace                   string
 	CloudEnvRepository          string
 	NoDefaultEnvironments       bool
+	NoNexus                     bool
 	RemoteEnvironments          bool
 	DefaultEnvironmentPrefix    string
 	LocalCloudEnvironment       bool
@@ -133,6 +139,7 @@ type InstallFlags struct {
 	HelmTLS                     bool
 	RegisterLocalHelmRepo       bool
 	CleanupTempFiles            bool
+	Resume                      bool
 	Prow                        bool
 	DisableSetKubeContext       bool
 	Dir                         string
@@ -141,6 +148,7 @@ type InstallFlags struct {
 	Tekton                      bool
 	BuildPackName               string
 	Kaniko                      bool
+	WorkloadIdentity            bool
 	GitOpsMode                  bool
 	NoGitOpsEnvApply            bool
 	NoGitOpsEnvRepo             bool
@@ -279,6 +287,9 @@ var (
 
 		# If you know the cloud provider you can pass this as a CLI argument. E.g. for AWS
 		jx install --provider=aws
+
+		# Resume a failed install skipping the steps which completed
+		jx install --resume
 `)
 )
 
@@ -302,6 +313,7 @@ func NewCmdInstall(commonOpts *opts.CommonOptions) *cobra.Command {
 	}
 
 	options.AddInstallFlags(cmd, false)
+	options.InitOptions.AddProfileFlag(cmd)
 
 	cmd.Flags().StringVarP(&options.Flags.Provider, "provider", "", "", "Cloud service providing the Kubernetes cluster.  Supported providers: "+cloud.KubernetesProviderOptions())
 
@@ -360,6 +372,7 @@ func (options *InstallOptions) AddInstallFlags(cmd *cobra.Command, includesInit
 	flags.AddCloudEnvOptions(cmd)
 	cmd.Flags().StringVarP(&flags.LocalHelmRepoName, "local-helm-repo-name", "", kube.LocalHelmRepoName, "The name of the helm repository for the installed ChartMuseum")
 	cmd.Flags().BoolVarP(&flags.NoDefaultEnvironments, "no-default-environments", "", false, "Disables the creation of the default Staging and Production environments")
+	cmd.Flags().BoolVarP(&flags.NoNexus, "no-nexus", "", false, "Disables the installation of Nexus. Maven artifacts are resolved from Maven Central and the versions of dependency updates are resolved from the git tags and the container registry")
 	cmd.Flags().BoolVarP(&flags.RemoteEnvironments, "remote-environments", "", false, "Indicates you intend Staging and Production environments to run in remote clusters. See https://jenkins-x.io/getting-started/multi-cluster/")
 	cmd.Flags().StringVarP(&flags.DefaultEnvironmentPrefix, "default-environment-prefix", "", "", "Default environment repo prefix, your Git repos will be of the form 'environment-$prefix-$envName'")
 	cmd.Flags().StringVarP(&flags.Namespace, namespaceFlagName, "", "jx", "The namespace the Jenkins X platform should be installed into")
@@ -367,6 +380,7 @@ func (options *InstallOptions) AddInstallFlags(cmd *cobra.Command, includesInit
 	cmd.Flags().StringVarP(&flags.EnvironmentGitOwner, "environment-git-owner", "", "", "The Git provider organisation to create the environment Git repositories in")
 	cmd.Flags().BoolVarP(&flags.RegisterLocalHelmRepo, "register-local-helmrepo", "", false, "Registers the Jenkins X ChartMuseum registry with your helm client [default false]")
 	cmd.Flags().BoolVarP(&flags.CleanupTempFiles, "cleanup-temp-files", "", true, "Cleans up any temporary values.yaml used by helm install [default true]")
+	cmd.Flags().BoolVarP(&flags.Resume, "resume", "", false, "Resumes a failed install skipping the long running steps which completed, such as the install of the platform and the addons")
 	cmd.Flags().BoolVarP(&flags.HelmTLS, "helm-tls", "", false, "Whether to use TLS with helm")
 	cmd.Flags().BoolVarP(&flags.InstallOnly, "install-only", "", false, "Force the install command to fail if there is already an installation. Otherwise lets update the installation")
 	cmd.Flags().StringVarP(&flags.AzureRegistrySubscription, "azure-acr-subscription", "", "", "The Azure subscription under which the specified docker-registry is located")
@@ -387,6 +401,7 @@ func (options *InstallOptions) AddInstallFlags(cmd *cobra.Command, includesInit
 	cmd.Flags().BoolVarP(&flags.RecreateVaultBucket, "vault-bucket-recreate", "", true, "If the vault bucket already exists delete it then create it empty")
 	cmd.Flags().StringVarP(&flags.BuildPackName, "buildpack", "", "", "The name of the build pack to use for the Team")
 	cmd.Flags().BoolVarP(&flags.Kaniko, kanikoFlagName, "", false, "Use Kaniko for building docker images")
+	cmd.Flags().BoolVarP(&flags.WorkloadIdentity, "workload-identity", "", false, "Federates the pipeline service account with a cloud identity so Kaniko can push images without a long-lived key (supported only for GKE)")
 	cmd.Flags().BoolVarP(&flags.NextGeneration, "ng", "", false, "Use the Next Generation Jenkins X features like Prow, Tekton, No Tiller, Vault, Dev GitOps")
 	cmd.Flags().BoolVarP(&flags.StaticJenkins, staticJenkinsFlagName, "", false, "Install a static Jenkins master to use as the pipeline engine. Note this functionality is deprecated in favour of running serverless Tekton builds")
 	cmd.Flags().BoolVarP(&flags.LongTermStorage, longTermStorageFlagName, "", false, "Enable the Long Term Storage option to save logs and other assets into a GCS bucket (supported only for GKE)")
@@ -429,6 +444,11 @@ func (options *InstallOptions) CheckFlags() error {
 		return fmt.Errorf("option '--static-jenkins' has been removed")
 	}
 
+	err := options.applyProfile()
+	if err != nil {
+		return err
+	}
+
 	if flags.Prow {
 		flags.Tekton = true
 	}
@@ -520,6 +540,36 @@ func (options *InstallOptions) CheckFlags() error {
 	return nil
 }
 
+// applyProfile applies the settings of the installation profile to any flags which have not been explicitly set. Settings
+// which the profile omits keep the flag values
+func (options *InstallOptions) applyProfile() error {
+	profile, err := options.InitOptions.ApplyProfile()
+	if err != nil {
+		return err
+	}
+	if profile == nil {
+		return nil
+	}
+	log.Logger().Infof("Using the install profile %s", util.ColorInfo(profile.Name))
+	options.profile = profile
+	flags := &options.Flags
+	flagChanged := options.InitOptions.FlagChanged
+	if !flagChanged(gitOpsFlagName) && profile.GitOps != nil {
+		flags.GitOpsMode = *profile.GitOps
+	}
+	if !flagChanged("vault") && profile.SecretStorage != "" {
+		flags.Vault = profile.SecretStorage == profiles.SecretStorageVault
+	}
+	exposeController := options.CreateEnvOptions.HelmValuesConfig.ExposeController
+	if exposeController != nil && !flagChanged("tls-acme") && profile.TLS != nil {
+		exposeController.Config.TLSAcme = strconv.FormatBool(*profile.TLS)
+		if *profile.TLS && !flagChanged("http") {
+			exposeController.Config.HTTP = "false"
+		}
+	}
+	return nil
+}
+
 // CheckFeatures - determines if the various features have been enabled
 func (options *InstallOptions) CheckFeatures() error {
 	if options.Flags.Tekton {
@@ -558,6 +608,27 @@ func (options *InstallOptions) Run() error {
 		return errors.Wrap(err, "creating the kube client")
 	}
 
+	initOpts := &options.InitOptions
+	initOpts.Progress = options.NewProgress()
+	initOpts.Progress.Checkpoint, err = options.LoadCheckpoint("install", options.Flags.Resume)
+	if err != nil {
+		return errors.Wrap(err, "loading the checkpoint of the install")
+	}
+	initOpts.Flags.VersionsRepository = options.Flags.VersionsRepository
+	initOpts.Flags.GitOrganisation = options.Flags.EnvironmentGitOwner
+	initOpts.Flags.VersionsGitRef = options.Flags.VersionsGitRef
+	err = initOpts.LockVersionStream()
+	if err != nil {
+		return errors.Wrap(err, "locking the version stream")
+	}
+	options.Flags.VersionsRepository = initOpts.Flags.VersionsRepository
+	options.Flags.VersionsGitRef = initOpts.Flags.VersionsGitRef
+
+	err = initOpts.VerifyKubernetesVersion()
+	if err != nil {
+		return errors.Wrap(err, "verifying the Kubernetes version")
+	}
+
 	err = options.registerAllCRDs()
 	if err != nil {
 		return errors.Wrap(err, "registering all CRDs")
@@ -711,8 +782,11 @@ func (options *InstallOptions) Run() error {
 			return errors.Wrap(err, "installing the Jenkins X platform in GitOps mode")
 		}
 	} else {
-		err := options.installPlatform(providerEnvDir, platform.JenkinsXPlatformChart, platform.JenkinsXPlatformRelease,
-			ns, version, valuesFiles, secretsFiles)
+		err := initOpts.Progress.ResumableStep("Installing the Jenkins X platform", func() error {
+			initOpts.Progress.SubStep("chart %s version %s", platform.JenkinsXPlatformChart, version)
+			return options.installPlatform(providerEnvDir, platform.JenkinsXPlatformChart, platform.JenkinsXPlatformRelease,
+				ns, version, valuesFiles, secretsFiles)
+		})
 		if err != nil {
 			return errors.Wrap(err, "installing the Jenkins X platform")
 		}
@@ -740,7 +814,7 @@ func (options *InstallOptions) Run() error {
 		return errors.Wrap(err, "configuring helm3")
 	}
 
-	err = options.installAddons()
+	err = initOpts.Progress.ResumableStep("Installing the addons", options.installAddons)
 	if err != nil {
 		return errors.Wrap(err, "installing the Jenkins X Addons")
 	}
@@ -754,7 +828,9 @@ func (options *InstallOptions) Run() error {
 		}
 	}
 
-	err = options.createEnvironments(ns)
+	err = initOpts.Progress.ResumableStep("Creating the environments", func() error {
+		return options.createEnvironments(ns)
+	})
 	if err != nil {
 		if strings.Contains(err.Error(), "com.atlassian.bitbucket.project.NoSuchProjectException") {
 			log.Logger().Infof("\nProject %s cannot be found. If you are using BitBucket Server, please use "+
@@ -792,8 +868,20 @@ func (options *InstallOptions) Run() error {
 		return errors.Wrap(err, "setting up GitOps post installation")
 	}
 
+	err = initOpts.Progress.ResumableStep("Running the post install hooks", initOpts.RunPostInstallHooks)
+	if err != nil {
+		return errors.Wrap(err, "running the post install hooks")
+	}
+
+	err = initOpts.Progress.Checkpoint.Reset()
+	if err != nil {
+		return errors.Wrap(err, "removing the checkpoint of the install")
+	}
+
 	log.Logger().Infof("\nJenkins X installation completed successfully")
 
+	initOpts.Progress.LogTimings()
+
 	options.logAdminPassword()
 
 	options.logNameServers()
@@ -845,6 +933,8 @@ func (options *InstallOptions) init() error {
 	initOpts.BatchMode = options.BatchMode
 	initOpts.Flags.VersionsRepository = options.Flags.VersionsRepository
 	initOpts.Flags.Http = true
+	// the hooks run once the platform is installed rather than at the end of init
+	initOpts.SkipPostInstallHooks = true
 	exposeController := options.CreateEnvOptions.HelmValuesConfig.ExposeController
 	if exposeController != nil {
 		initOpts.Flags.Http = exposeController.Config.HTTP == "true"
@@ -1221,6 +1311,9 @@ func (options *InstallOptions) configureHelmValues(namespace string) error {
 			helmConfig.DockerRegistryEnabled = &config.EnabledConfig{false}
 		}
 	}
+	if options.Flags.NoNexus {
+		helmConfig.NexusEnabled = &config.EnabledConfig{false}
+	}
 	return nil
 }
 
@@ -1260,6 +1353,16 @@ func (options *InstallOptions) getHelmValuesFiles(configStore configio.ConfigSto
 	}
 
 	valuesFiles = append(valuesFiles, cloudEnvironmentValuesLocation)
+	if options.profile != nil {
+		profileValuesFileName, err := options.profile.WriteValuesFile()
+		if err != nil {
+			return valuesFiles, secretsFiles, temporaryFiles, errors.Wrapf(err, "writing the values of install profile %s", options.profile.Name)
+		}
+		if profileValuesFileName != "" {
+			valuesFiles = append(valuesFiles, profileValuesFileName)
+			temporaryFiles = append(temporaryFiles, profileValuesFileName)
+		}
+	}
 	valuesFiles, err = helm.AppendMyValues(valuesFiles)
 	if err != nil {
 		return valuesFiles, secretsFiles, temporaryFiles,
@@ -1362,7 +1465,7 @@ func (options *InstallOptions) configureGitAuth() error {
 			Default: true,
 		}
 		yes := false
-		err = survey.AskOne(confirm, &yes, nil, surveyOpts)
+		err = util.AskOne(confirm, &yes, nil, surveyOpts)
 		if err != nil {
 			return errors.Wrap(err, "selecting pipelines Git server")
 		}
@@ -1425,6 +1528,13 @@ func (options *InstallOptions) configureGitAuth() error {
 		return errors.Wrap(err, "saving the Git authentication configuration")
 	}
 
+	if !options.InitOptions.Flags.NoGitValidate {
+		err = options.InitOptions.ValidateGitAccess(pipelineAuthServer, pipelineUserAuth, options.Flags.EnvironmentGitOwner)
+		if err != nil {
+			return err
+		}
+	}
+
 	editTeamSettingsCallback := func(env *v1.Environment) error {
 		teamSettings := &env.Spec.TeamSettings
 		teamSettings.GitServer = pipelineAuthServerURL
@@ -1487,7 +1597,7 @@ func (options *InstallOptions) buildGitRepositoryOptionsForEnvironments() (*gits
 				Message: "Select the organization where you want to create the environment repository:",
 				Options: orgs,
 			}
-			err = survey.AskOne(prompt, &org, survey.Required, surveyOpts)
+			err = util.AskOne(prompt, &org, survey.Required, surveyOpts)
 			if err != nil {
 				return nil, errors.Wrap(err, "selecting the organization for environment repository")
 			}
@@ -1886,7 +1996,7 @@ func (options *InstallOptions) installHelmBinaries() error {
 				Default: true,
 			}
 			flag := true
-			err = survey.AskOne(confirm, &flag, nil)
+			err = util.AskOne(confirm, &flag, nil)
 			if err != nil || flag == false {
 				return errors.New("Existing tiller must be uninstalled first in order to use the jx in tiller less mode")
 			}
@@ -1904,7 +2014,7 @@ func (options *InstallOptions) installHelmBinaries() error {
 				Default: true,
 			}
 			flag := true
-			err = survey.AskOne(confirm, &flag, nil)
+			err = util.AskOne(confirm, &flag, nil)
 			if err != nil || flag == false {
 				return errors.New("Existing helm must be uninstalled first in order to use the jx in tiller less mode")
 			}
@@ -2117,6 +2227,7 @@ func (options *InstallOptions) getAdminSecrets(configStore configio.ConfigStore,
 	}
 
 	if !adminSecretsServiceInit {
+		options.AdminSecretsService.Flags.NoNexus = options.Flags.NoNexus
 		err = options.AdminSecretsService.NewAdminSecretsConfig()
 		if err != nil {
 			return "", nil, errors.Wrap(err, "failed to create the admin secret config service")
@@ -2190,6 +2301,10 @@ func (options *InstallOptions) ConfigureKaniko() error {
 			}
 		}
 
+		if options.Flags.WorkloadIdentity {
+			return options.federatePipelineServiceAccount(projectID, clusterName)
+		}
+
 		serviceAccountName := naming.ToValidGCPServiceAccount(fmt.Sprintf("%s-ko", clusterName))
 		log.Logger().Infof("Configuring Kaniko service account %s for project %s", util.ColorInfo(serviceAccountName), util.ColorInfo(projectID))
 		serviceAccountPath, err := options.GCloud().GetOrCreateServiceAccount(serviceAccountName, projectID, serviceAccountDir, gke.KanikoServiceAccountRoles)
@@ -2208,6 +2323,24 @@ func (options *InstallOptions) ConfigureKaniko() error {
 	return nil
 }
 
+// federatePipelineServiceAccount binds the pipeline service account to the Kaniko GCP service account via workload identity
+func (options *InstallOptions) federatePipelineServiceAccount(projectID string, clusterName string) error {
+	kubeClient, ns, err := options.KubeClientAndDevNamespace()
+	if err != nil {
+		return errors.Wrap(err, "creating the kube client")
+	}
+	requirements := &config.RequirementsConfig{}
+	requirements.Cluster.Provider = options.Flags.Provider
+	requirements.Cluster.ProjectID = projectID
+	requirements.Cluster.ClusterName = clusterName
+	log.Logger().Infof("Federating service account %s with a service account of project %s", util.ColorInfo(tekton.DefaultPipelineSA), util.ColorInfo(projectID))
+	binding, err := workloadidentity.Federate(requirements, ns, tekton.DefaultPipelineSA)
+	if err != nil {
+		return err
+	}
+	return binding.Apply(kubeClient)
+}
+
 func (options *InstallOptions) createSystemVault(client kubernetes.Interface, namespace string, ic *kube.IngressConfig) error {
 	if options.Flags.GitOpsMode && !options.Flags.NoGitOpsVault || options.Flags.Vault {
 		if options.Flags.Provider != cloud.GKE && options.Flags.Provider != cloud.EKS && options.Flags.Provider != cloud.AWS {
@@ -2488,7 +2621,7 @@ func (options *InstallOptions) configureLongTermStorageBucket() error {
 					" A bucket for provider %s will be created", options.Flags.Provider),
 				Default: true,
 			}
-			err := survey.AskOne(confirm, &options.Flags.LongTermStorage, nil, surveyOpts)
+			err := util.AskOne(confirm, &options.Flags.LongTermStorage, nil, surveyOpts)
 			if err != nil {
 				return errors.Wrap(err, "asking to enable Long Term Storage")
 			}
@@ -2719,14 +2852,25 @@ func (options *InstallOptions) installAddons() error {
 			return errors.Wrap(err, "failed to load the addons configuration")
 		}
 
+		addonNames := []string{}
 		for _, ac := range addonConfig.Addons {
 			if ac.Enabled {
-				err = options.installAddon(ac.Name)
-				if err != nil {
-					return fmt.Errorf("failed to install addon %s: %s", ac.Name, err)
+				addonNames = append(addonNames, ac.Name)
+			}
+		}
+		if options.profile != nil {
+			for _, name := range options.profile.Addons {
+				if util.StringArrayIndex(addonNames, name) < 0 {
+					addonNames = append(addonNames, name)
 				}
 			}
 		}
+		for _, name := range addonNames {
+			err = options.installAddon(name)
+			if err != nil {
+				return fmt.Errorf("failed to install addon %s: %s", name, err)
+			}
+		}
 	}
 	return nil
 }
@@ -3055,8 +3199,14 @@ func (options *InstallOptions) CloneJXCloudEnvironmentsRepo() (string, error) {
 		options.Flags.CloudEnvRepository = opts.DefaultCloudEnvironmentsURL
 	}
 	log.Logger().Debugf("Cloning the Jenkins X cloud environments repo to %s", wrkDir)
+	sshOptions := gits.SSHOptionsFromEnv()
+	cloneURL, auth, err := sshOptions.GoGitRemote(options.Flags.CloudEnvRepository)
+	if err != nil {
+		return wrkDir, errors.Wrapf(err, "configuring the authentication to clone %s", options.Flags.CloudEnvRepository)
+	}
 	_, err = git.PlainClone(wrkDir, false, &git.CloneOptions{
-		URL:           options.Flags.CloudEnvRepository,
+		URL:           cloneURL,
+		Auth:          auth,
 		ReferenceName: "refs/heads/master",
 		SingleBranch:  true,
 		Progress:      options.Out,
@@ -3071,7 +3221,7 @@ func (options *InstallOptions) CloneJXCloudEnvironmentsRepo() (string, error) {
 					Message: "A local Jenkins X cloud environments repository already exists, recreate with latest?",
 					Default: true,
 				}
-				err := survey.AskOne(confirm, &flag, nil, surveyOpts)
+				err := util.AskOne(confirm, &flag, nil, surveyOpts)
 				if err != nil {
 					return wrkDir, err
 				}
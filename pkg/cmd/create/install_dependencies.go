@@ -5,6 +5,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/spf13/cobra"
 	"gopkg.in/AlecAivazis/survey.v1"
 )
@@ -94,7 +95,7 @@ func (options *InstallDependenciesOptions) Run() error {
 				Options: availableDependencies,
 			}
 			surveyOpts := survey.WithStdio(options.In, options.Out, options.Err)
-			err := survey.AskOne(prompt, &install, nil, surveyOpts)
+			err := util.AskOne(prompt, &install, nil, surveyOpts)
 			if err != nil {
 				return err
 			}
@@ -20,7 +20,9 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/util"
 
 	//. "github.com/petergtz/pegomock"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestInstall(t *testing.T) {
@@ -201,3 +203,27 @@ func TestCheckFlags(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckFlagsAppliesOnlyTheProfileSettings(t *testing.T) {
+	installOptions := create.InstallOptions{
+		CommonOptions: &opts.CommonOptions{},
+		Flags: create.InstallFlags{
+			Provider: cloud.GKE,
+		},
+	}
+	installOptions.InitOptions.CommonOptions = &opts.CommonOptions{}
+	installOptions.InitOptions.Flags.Profile = "team"
+	cmd := &cobra.Command{}
+	cmd.Flags().BoolVarP(&installOptions.Flags.GitOpsMode, "gitops", "", false, "")
+	require.NoError(t, cmd.Flags().Set("gitops", "false"))
+	installOptions.InitOptions.Cmd = cmd
+
+	err := installOptions.CheckFlags()
+	require.NoError(t, err)
+
+	assert.False(t, installOptions.Flags.GitOpsMode, "the explicit gitops flag should win over the profile")
+	assert.True(t, installOptions.Flags.Vault, "the profile secret storage should be applied")
+	assert.False(t, installOptions.Flags.Prow, "the profile should not enable Prow")
+	assert.False(t, installOptions.Flags.Tekton, "the profile should not enable Tekton")
+	assert.False(t, installOptions.Flags.Kaniko, "the profile should not enable Kaniko")
+}
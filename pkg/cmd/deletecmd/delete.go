@@ -56,9 +56,11 @@ func NewCmdDelete(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(NewCmdDeleteEnv(commonOpts))
 	cmd.AddCommand(NewCmdDeleteGit(commonOpts))
 	cmd.AddCommand(NewCmdDeleteGke(commonOpts))
+	cmd.AddCommand(NewCmdDeleteInstallation(commonOpts))
 	cmd.AddCommand(NewCmdDeleteJenkins(commonOpts))
 	cmd.AddCommand(NewCmdDeleteNamespace(commonOpts))
 	cmd.AddCommand(NewCmdDeletePreview(commonOpts))
+	cmd.AddCommand(NewCmdDeleteQuickstartCatalog(commonOpts))
 	cmd.AddCommand(NewCmdDeleteQuickstartLocation(commonOpts))
 	cmd.AddCommand(NewCmdDeleteRepo(commonOpts))
 	cmd.AddCommand(NewCmdDeleteToken(commonOpts))
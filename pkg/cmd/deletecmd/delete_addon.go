@@ -40,6 +40,8 @@ func NewCmdDeleteAddon(commonOpts *opts.CommonOptions) *cobra.Command {
 		SuggestFor: []string{"remove", "rm"},
 	}
 
+	cmd.AddCommand(NewCmdDeleteAddonAdmission(commonOpts))
+	cmd.AddCommand(NewCmdDeleteAddonCloudDB(commonOpts))
 	cmd.AddCommand(NewCmdDeleteAddonEnvironmentController(commonOpts))
 	cmd.AddCommand(NewCmdDeleteAddonFlagger(commonOpts))
 	cmd.AddCommand(NewCmdDeleteAddonGitea(commonOpts))
@@ -0,0 +1,71 @@
+package deletecmd
+
+import (
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/admission"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	deleteAddonAdmissionLong = templates.LongDesc(`
+		Deletes the admission webhook created via 'jx create addon admission'
+`)
+
+	deleteAddonAdmissionExample = templates.Examples(`
+		# Deletes the admission webhook
+		jx delete addon admission
+	`)
+)
+
+// DeleteAddonAdmissionOptions the options for the delete addon admission command
+type DeleteAddonAdmissionOptions struct {
+	*opts.CommonOptions
+
+	Name      string
+	Namespace string
+}
+
+// NewCmdDeleteAddonAdmission defines the command
+func NewCmdDeleteAddonAdmission(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &DeleteAddonAdmissionOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "admission",
+		Short:   "Deletes the admission webhook which validates the Jenkins X resources",
+		Long:    deleteAddonAdmissionLong,
+		Example: deleteAddonAdmissionExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "The namespace of the webhook. Defaults to the dev namespace")
+	cmd.Flags().StringVarP(&options.Name, "name", "", admission.DefaultName, "The name of the deployment, service and webhook configuration")
+	return cmd
+}
+
+// Run implements the command
+func (o *DeleteAddonAdmissionOptions) Run() error {
+	kubeClient, devNs, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	ns := o.Namespace
+	if ns == "" {
+		ns = devNs
+	}
+	err = admission.Uninstall(kubeClient, o.Name, ns)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Deleted the admission webhook %s in namespace %s", util.ColorInfo(o.Name), util.ColorInfo(ns))
+	return nil
+}
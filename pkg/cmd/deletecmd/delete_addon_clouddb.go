@@ -0,0 +1,119 @@
+package deletecmd
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/clouddb"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	deleteAddonCloudDBLong = templates.LongDesc(`
+		Deletes a managed cloud database provisioned via 'jx create addon clouddb' along with the Secret of its credentials
+`)
+
+	deleteAddonCloudDBExample = templates.Examples(`
+		# Pick the managed cloud database to delete
+		jx delete addon clouddb
+
+		# Delete the managed cloud database orders-db
+		jx delete addon clouddb orders-db
+	`)
+)
+
+// DeleteAddonCloudDBOptions the options for the delete addon clouddb command
+type DeleteAddonCloudDBOptions struct {
+	*opts.CommonOptions
+
+	// Provisioner deletes the database, defaults to the provisioner of the cloud of the database
+	Provisioner clouddb.Provisioner
+}
+
+// NewCmdDeleteAddonCloudDB defines the command
+func NewCmdDeleteAddonCloudDB(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &DeleteAddonCloudDBOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "clouddb [name]",
+		Short:   "Deletes a managed cloud database and its credentials",
+		Aliases: []string{"cloud-db"},
+		Long:    deleteAddonCloudDBLong,
+		Example: deleteAddonCloudDBExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	return cmd
+}
+
+// Run implements the command
+func (o *DeleteAddonCloudDBOptions) Run() error {
+	kubeClient, devNs, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	dynamicClient, _, err := o.GetFactory().CreateDynamicClient()
+	if err != nil {
+		return errors.Wrap(err, "creating the dynamic client")
+	}
+	name := ""
+	if len(o.Args) > 0 {
+		name = o.Args[0]
+	}
+	if name == "" {
+		if o.BatchMode {
+			return util.MissingArgument("name")
+		}
+		names, err := clouddb.List(dynamicClient, devNs)
+		if err != nil {
+			return err
+		}
+		name, err = util.PickName(names, "Pick the managed cloud database to delete: ", "", o.GetIOFileHandles())
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			return fmt.Errorf("no database chosen")
+		}
+	}
+
+	db, _, err := clouddb.Get(dynamicClient, devNs, name)
+	if err != nil {
+		return err
+	}
+	if db == nil {
+		return fmt.Errorf("no managed cloud database found with name: %s", name)
+	}
+	if o.Provisioner == nil {
+		o.Provisioner, err = clouddb.NewProvisioner(db.Provider)
+		if err != nil {
+			return err
+		}
+	}
+	log.Logger().Infof("Deleting the managed cloud database %s", util.ColorInfo(name))
+	err = o.Provisioner.Delete(db)
+	if err != nil {
+		return errors.Wrapf(err, "deleting database %s", name)
+	}
+	err = clouddb.DeleteSecret(kubeClient, db)
+	if err != nil {
+		return err
+	}
+	err = clouddb.Remove(dynamicClient, devNs, name)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Deleted the managed cloud database %s and its Secret %s in namespace %s", util.ColorInfo(name), util.ColorInfo(db.Secret), util.ColorInfo(db.Namespace))
+	return nil
+}
@@ -47,6 +47,8 @@ type DeleteAppOptions struct {
 	Alias       string
 	AutoMerge   bool
 
+	OverrideProtected bool
+
 	// Used for testing
 	CloneDir string
 }
@@ -58,10 +60,11 @@ func NewCmdDeleteApp(commonOpts *opts.CommonOptions) *cobra.Command {
 	}
 
 	cmd := &cobra.Command{
-		Use:     "app",
-		Short:   "Deletes one or more apps from Jenkins X (an app is similar to an addon)",
-		Long:    deleteAppLong,
-		Example: deleteAppExample,
+		Use:               "app",
+		Short:             "Deletes one or more apps from Jenkins X (an app is similar to an addon)",
+		Long:              deleteAppLong,
+		Example:           deleteAppExample,
+		ValidArgsFunction: o.CompleteApps,
 		Run: func(cmd *cobra.Command, args []string) {
 			o.Cmd = cmd
 			o.Args = args
@@ -78,6 +81,7 @@ func NewCmdDeleteApp(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&o.Alias, opts.OptionAlias, "", "",
 		"An alias to use for the app (available when using GitOps for your dev environment)")
 	cmd.Flags().BoolVarP(&o.AutoMerge, "auto-merge", "", false, "Automatically merge GitOps pull requests that pass CI")
+	opts.AddProtectedNamespaceFlag(cmd, &o.OverrideProtected)
 
 	return cmd
 }
@@ -147,5 +151,15 @@ func (o *DeleteAppOptions) Run() error {
 
 	app := args[0]
 
+	// with GitOps the app is removed from the dev environment otherwise its release is deleted from the namespace
+	appNamespace := o.Namespace
+	if o.GitOps && o.DevEnv.Spec.Namespace != "" {
+		appNamespace = o.DevEnv.Spec.Namespace
+	}
+	err = o.VerifyProtectedNamespaces("delete app", o.OverrideProtected, appNamespace)
+	if err != nil {
+		return err
+	}
+
 	return installOptions.DeleteApp(app, o.Alias, o.ReleaseName, o.Purge)
 }
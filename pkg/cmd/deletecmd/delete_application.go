@@ -3,6 +3,7 @@ package deletecmd
 import (
 	"fmt"
 	"os/user"
+	"sort"
 	"strings"
 	"time"
 
@@ -30,6 +31,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/helm"
 	"github.com/jenkins-x/jx/v2/pkg/jenkins"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/kube/services"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -71,6 +73,7 @@ type DeleteApplicationOptions struct {
 	PullRequestPollTime string
 	Org                 string
 	AutoMerge           bool
+	OverrideProtected   bool
 
 	// calculated fields
 	TimeoutDuration         *time.Duration
@@ -107,6 +110,7 @@ func NewCmdDeleteApplication(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.PullRequestPollTime, optionPullRequestPollTime, "", "20s", "Poll time when waiting for a Pull Request to merge")
 	cmd.Flags().StringVarP(&options.Org, "org", "o", "", "github organisation/project name that source code resides in")
 	cmd.Flags().BoolVarP(&options.AutoMerge, "auto-merge", "", false, "Automatically merge GitOps pull requests that pass CI")
+	opts.AddProtectedNamespaceFlag(cmd, &options.OverrideProtected)
 	return cmd
 }
 
@@ -243,6 +247,11 @@ func (o *DeleteApplicationOptions) deleteProwApplication(repoService jenkinsv1.S
 		}
 	}
 
+	err = o.verifyProtectedNamespaces(envMap, ns)
+	if err != nil {
+		return deletedApplications, err
+	}
+
 	for _, repo := range o.Args {
 		path := strings.SplitN(repo, "/", 2)
 		if len(path) < 2 {
@@ -336,6 +345,18 @@ func (o *DeleteApplicationOptions) deleteJenkinsApplication() (deletedApplicatio
 			}
 		}
 	}
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return deletedApplications, err
+	}
+	envMap, _, err := kube.GetOrderedEnvironments(jxClient, ns)
+	if err != nil {
+		return deletedApplications, err
+	}
+	err = o.verifyProtectedNamespaces(envMap)
+	if err != nil {
+		return deletedApplications, err
+	}
 	deleteMessage := strings.Join(args, ", ")
 
 	if !o.BatchMode {
@@ -386,6 +407,21 @@ func (o *DeleteApplicationOptions) deleteApplication(jenkinsClient gojenkins.Jen
 	return jenkinsClient.DeleteJob(*job)
 }
 
+// verifyProtectedNamespaces returns an error if any of the namespaces the applications are deleted from is protected
+// unless the override flag is specified. The applications are removed from the namespaces of the permanent
+// environments as well as the given namespaces
+func (o *DeleteApplicationOptions) verifyProtectedNamespaces(envMap map[string]*v1.Environment, namespaces ...string) error {
+	if !o.IgnoreEnvironments {
+		for _, env := range envMap {
+			if env.Spec.Kind == v1.EnvironmentKindTypePermanent && env.Spec.Namespace != "" {
+				namespaces = append(namespaces, env.Spec.Namespace)
+			}
+		}
+	}
+	sort.Strings(namespaces)
+	return o.VerifyProtectedNamespaces("delete application", o.OverrideProtected, namespaces...)
+}
+
 func (o *DeleteApplicationOptions) applicationNameFromJenkinsJobName(name string) string {
 	path := strings.Split(name, "/")
 	return path[len(path)-1]
@@ -395,6 +431,7 @@ func (o *DeleteApplicationOptions) deleteApplicationFromEnvironment(env *v1.Envi
 	if o.IgnoreEnvironments {
 		return nil
 	}
+	o.deregisterUptimeCheck(env, applicationName)
 	if env.Spec.Source.URL == "" {
 		return nil
 	}
@@ -435,6 +472,25 @@ func (o *DeleteApplicationOptions) deleteApplicationFromEnvironment(env *v1.Envi
 	return o.waitForGitOpsPullRequest(env, info, end, duration)
 }
 
+// deregisterUptimeCheck removes any uptime check registered by 'jx promote --uptime-check' so that removing the
+// application does not raise alerts
+func (o *DeleteApplicationOptions) deregisterUptimeCheck(env *v1.Environment, applicationName string) {
+	ns := env.Spec.Namespace
+	if ns == "" {
+		return
+	}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		log.Logger().Warnf("Failed to deregister the uptime check of %s: %s", applicationName, err)
+		return
+	}
+	names := []string{naming.ToValidName(applicationName), naming.ToValidName(ns + "-" + applicationName)}
+	err = services.DeregisterUptimeCheck(kubeClient, ns, names)
+	if err != nil {
+		log.Logger().Warnf("Failed to deregister the uptime check of %s in namespace %s: %s", applicationName, ns, err)
+	}
+}
+
 func (o *DeleteApplicationOptions) waitForGitOpsPullRequest(env *v1.Environment,
 	pullRequestInfo *gits.PullRequestInfo, end time.Time,
 	duration time.Duration) error {
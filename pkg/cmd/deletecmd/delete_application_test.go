@@ -73,6 +73,52 @@ func TestDeleteApplicationInJenkins(t *testing.T) {
 	jenkinsClient.VerifyWasCalledOnce().DeleteJob(job)
 }
 
+func TestDeleteApplicationFromProtectedEnvironment(t *testing.T) {
+	pegomock.RegisterMockTestingT(t)
+	t.Parallel()
+
+	testRepoNameUUID, err := uuid.NewUUID()
+	assert.NoError(t, err)
+	testRepoName := testRepoNameUUID.String()
+
+	mockFactory := clients_test.NewMockFactory()
+	commonOpts := opts.NewCommonOptionsWithFactory(mockFactory)
+
+	production := kube.NewPermanentEnvironment("production")
+	production.Spec.PromotionStrategy = v1.PromotionStrategyTypeManual
+	testhelpers.ConfigureTestOptionsWithResources(&commonOpts,
+		[]runtime.Object{},
+		[]runtime.Object{
+			production,
+		},
+		gits.NewGitLocal(),
+		nil,
+		helm_test.NewMockHelmer(),
+		resources_test.NewMockInstaller(),
+	)
+	testhelpers.MockFactoryWithKubeClients(mockFactory, &commonOpts)
+	kubeClient, _, _ := mockFactory.CreateKubeClient()
+
+	jenkinsClient := clients_test.NewMockJenkinsClient()
+	pegomock.When(mockFactory.CreateJenkinsClient(kubeClient, "jx", commonOpts.GetIOFileHandles())).ThenReturn(pegomock.ReturnValue(jenkinsClient), pegomock.ReturnValue(nil))
+	job := gojenkins.Job{
+		Name:  testRepoName,
+		Class: "org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject",
+	}
+	pegomock.When(jenkinsClient.GetJobs()).ThenReturn(pegomock.ReturnValue([]gojenkins.Job{job}), pegomock.ReturnValue(nil))
+	pegomock.When(jenkinsClient.GetJob(pegomock.EqString(testRepoName))).ThenReturn(pegomock.ReturnValue(job), pegomock.ReturnValue(nil))
+
+	o := &DeleteApplicationOptions{
+		CommonOptions: &commonOpts,
+	}
+	o.Args = []string{testRepoName}
+
+	err = o.Run()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "jx-production")
+	jenkinsClient.VerifyWasCalled(pegomock.Never()).DeleteJob(job)
+}
+
 func TestRemoveEnvrionmentFromJobs(t *testing.T) {
 	t.Parallel()
 
@@ -215,7 +215,7 @@ func (o *DeleteBranchOptions) confirmDeletion(branches []string) bool {
 	}
 
 	surveyOpts := survey.WithStdio(o.In, o.Out, o.Err)
-	err := survey.AskOne(prompt, &flag, nil, surveyOpts)
+	err := util.AskOne(prompt, &flag, nil, surveyOpts)
 
 	if err != nil {
 		return false
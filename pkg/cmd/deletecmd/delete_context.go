@@ -117,7 +117,7 @@ func (o *DeleteContextOptions) Run() error {
 		Message: "Are you sure you want to delete these these Kubernetes Contexts?",
 		Default: false,
 	}
-	err = survey.AskOne(prompt, &flag, nil, surveyOpts)
+	err = util.AskOne(prompt, &flag, nil, surveyOpts)
 	if err != nil {
 		return err
 	}
@@ -132,7 +132,11 @@ func (o *DeleteEnvOptions) deleteEnviroment(jxClient versioned.Interface, ns str
 	}
 	kind := env.Spec.Kind
 	if o.DeleteNamespace || !kind.IsPermanent() {
-		return client.CoreV1().Namespaces().Delete(envNs, &metav1.DeleteOptions{})
+		deleted, err := kube.DeleteEnvironmentNamespaceIfOwned(client, env)
+		if deleted {
+			log.Logger().Infof("Deleted namespace %s", util.ColorInfo(envNs))
+		}
+		return err
 	}
 	log.Logger().Infof("To delete the associated namespace %s for environment %s then please run this command", name, envNs)
 	log.Logger().Infof(util.ColorInfo("  kubectl delete namespace %s"), envNs)
@@ -0,0 +1,351 @@
+package deletecmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/jx-api/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cloud"
+	"github.com/jenkins-x/jx/v2/pkg/cloud/gke"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/uninstall"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/errorutil"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// ResourceKindBucket a storage bucket
+	ResourceKindBucket = "bucket"
+	// ResourceKindServiceAccount a cloud IAM service account
+	ResourceKindServiceAccount = "service account"
+	// ResourceKindDNSRecord the DNS records of a host
+	ResourceKindDNSRecord = "DNS record"
+
+	gcpServiceAccountSecretSuffix = "-gcp-sa"
+)
+
+var (
+	deleteInstallationLong = templates.LongDesc(`
+		Deletes the Jenkins X installation from the cluster in the same way as 'jx uninstall'.
+
+		With --deep the cloud provider resources created for the installation are deleted afterwards so that they do not
+		keep incurring costs once the cluster is torn down. The resources are discovered, before the installation is
+		removed, from the install configuration and requirements stored in the cluster and the service account secrets
+		created by jx:
+
+		* the storage buckets of the logs, reports, repository, backups, long term storage and vault
+		* the service accounts of external DNS, vault, kaniko and velero
+		* the DNS records external DNS created for the ingresses of the environments
+
+		The load balancer of the ingress controller is released by the cloud provider when the ingress controller is
+		uninstalled. Only the resources of GKE installations are deleted automatically, the resources of the other
+		providers are listed so that they can be deleted manually.
+`)
+
+	deleteInstallationExample = templates.Examples(`
+		# delete the Jenkins X installation
+		jx delete installation
+
+		# delete the Jenkins X installation and the cloud resources created for it
+		jx delete installation --deep
+	`)
+)
+
+// ProviderResource a cloud provider resource created for an installation
+type ProviderResource struct {
+	Kind string
+	Name string
+}
+
+// ProviderResources the cloud provider resources of an installation
+type ProviderResources struct {
+	Provider  string
+	ProjectID string
+	Domain    string
+	Resources []ProviderResource
+}
+
+// DeleteInstallationOptions the options for the "delete installation" command
+type DeleteInstallationOptions struct {
+	*opts.CommonOptions
+
+	Namespace         string
+	Context           string
+	KeepEnvironments  bool
+	KeepNamespaces    bool
+	OverrideProtected bool
+	Deep              bool
+}
+
+// NewCmdDeleteInstallation creates a command object for the "delete installation" command
+func NewCmdDeleteInstallation(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &DeleteInstallationOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "installation",
+		Aliases: []string{"install"},
+		Short:   "Deletes the Jenkins X installation and optionally the cloud resources created for it",
+		Long:    deleteInstallationLong,
+		Example: deleteInstallationExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "The team namespace to delete. Defaults to the current namespace.")
+	cmd.Flags().StringVarP(&options.Context, "context", "", "", "The kube context to delete Jenkins X from. This will be compared with the current context to prevent accidental deletion from the wrong cluster")
+	cmd.Flags().BoolVarP(&options.KeepEnvironments, "keep-environments", "", false, "Don't delete environments. Delete Jenkins X only.")
+	cmd.Flags().BoolVarP(&options.KeepNamespaces, "keep-namespaces", "", false, "Don't delete namespaces.")
+	cmd.Flags().BoolVarP(&options.Deep, "deep", "", false, "Also delete the cloud provider resources created for the installation")
+	opts.AddProtectedNamespaceFlag(cmd, &options.OverrideProtected)
+	return cmd
+}
+
+// Run implements this command
+func (o *DeleteInstallationOptions) Run() error {
+	kubeClient, currentNs, err := o.KubeClientAndNamespace()
+	if err != nil {
+		return errors.Wrap(err, "creating the kube client")
+	}
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return errors.Wrap(err, "creating the jx client")
+	}
+	ns := o.Namespace
+	if ns == "" {
+		ns = currentNs
+	}
+
+	var resources *ProviderResources
+	if o.Deep {
+		// the resources have to be discovered before the install configuration is removed
+		resources, err = DiscoverProviderResources(kubeClient, jxClient, ns)
+		if err != nil {
+			return errors.Wrap(err, "discovering the cloud provider resources of the installation")
+		}
+		if len(resources.Resources) == 0 {
+			log.Logger().Infof("No cloud provider resources found for the installation in namespace %s", util.ColorInfo(ns))
+		} else if !o.BatchMode {
+			help := "The following resources will be deleted:"
+			for _, r := range resources.Resources {
+				help += fmt.Sprintf("\n %s %s", r.Kind, r.Name)
+			}
+			answer, err := util.Confirm(fmt.Sprintf("Delete the %d %s resources created for the installation after it is uninstalled?",
+				len(resources.Resources), resources.Provider), false, help, o.GetIOFileHandles())
+			if err != nil {
+				return err
+			}
+			if !answer {
+				return nil
+			}
+		}
+	}
+
+	uninstallOptions := &uninstall.UninstallOptions{
+		CommonOptions:     o.CommonOptions,
+		Namespace:         ns,
+		Context:           o.Context,
+		KeepEnvironments:  o.KeepEnvironments,
+		KeepNamespaces:    o.KeepNamespaces,
+		OverrideProtected: o.OverrideProtected,
+	}
+	err = uninstallOptions.Run()
+	if err != nil {
+		return err
+	}
+	if resources == nil || len(resources.Resources) == 0 {
+		return nil
+	}
+
+	// the uninstall returns without an error when it is not confirmed so lets check the environments were removed
+	envs, err := jxClient.JenkinsV1().Environments(ns).List(metav1.ListOptions{})
+	if err == nil && len(envs.Items) > 0 {
+		log.Logger().Infof("Jenkins X was not uninstalled from namespace %s so not deleting its cloud provider resources", ns)
+		return nil
+	}
+	return o.deleteProviderResources(resources)
+}
+
+// DiscoverProviderResources discovers the cloud provider resources created for the installation in the team namespace
+// from the install configuration, the requirements and the service account secrets
+func DiscoverProviderResources(kubeClient kubernetes.Interface, jxClient versioned.Interface, ns string) (*ProviderResources, error) {
+	installValues, err := kube.ReadInstallValues(kubeClient, ns)
+	if err != nil {
+		log.Logger().Debugf("failed to read the install values in namespace %s: %s", ns, err)
+	}
+	requirements := config.NewRequirementsConfig()
+	devEnv, err := kube.GetDevEnvironment(jxClient, ns)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, errors.Wrapf(err, "getting the dev environment in namespace %s", ns)
+	}
+	storageLocations := []string{}
+	if devEnv != nil {
+		loaded, err := config.GetRequirementsConfigFromTeamSettings(&devEnv.Spec.TeamSettings)
+		if err != nil {
+			return nil, errors.Wrap(err, "loading the requirements from the team settings")
+		}
+		if loaded != nil {
+			requirements = loaded
+		}
+		for _, location := range devEnv.Spec.TeamSettings.StorageLocations {
+			storageLocations = append(storageLocations, location.BucketURL)
+		}
+	}
+
+	answer := &ProviderResources{
+		Provider:  requirements.Cluster.Provider,
+		ProjectID: requirements.Cluster.ProjectID,
+		Domain:    requirements.Ingress.Domain,
+	}
+	if answer.Provider == "" {
+		answer.Provider = installValues[kube.KubeProvider]
+	}
+	if answer.ProjectID == "" {
+		answer.ProjectID = installValues[kube.ProjectID]
+	}
+
+	add := func(kind string, name string) {
+		if name == "" {
+			return
+		}
+		for _, r := range answer.Resources {
+			if r.Kind == kind && r.Name == name {
+				return
+			}
+		}
+		answer.Resources = append(answer.Resources, ProviderResource{Kind: kind, Name: name})
+	}
+
+	storage := requirements.Storage
+	bucketURLs := append([]string{storage.Logs.URL, storage.Reports.URL, storage.Repository.URL, storage.Backup.URL}, storageLocations...)
+	for _, u := range bucketURLs {
+		add(ResourceKindBucket, bucketName(u))
+	}
+	add(ResourceKindBucket, requirements.Vault.Bucket)
+
+	cluster := requirements.Cluster
+	for _, sa := range []string{cluster.ExternalDNSSAName, cluster.VaultSAName, cluster.KanikoSAName, requirements.Velero.ServiceAccount} {
+		add(ResourceKindServiceAccount, sa)
+	}
+	secrets, err := kubeClient.CoreV1().Secrets(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the secrets in namespace %s", ns)
+	}
+	for _, secret := range secrets.Items {
+		if !strings.HasSuffix(secret.Name, gcpServiceAccountSecretSuffix) {
+			continue
+		}
+		for _, data := range secret.Data {
+			add(ResourceKindServiceAccount, gcpServiceAccountName(data))
+		}
+	}
+
+	if requirements.Ingress.ExternalDNS && answer.Domain != "" {
+		namespaces := []string{ns}
+		envs, err := kube.GetPermanentEnvironments(jxClient, ns)
+		if err != nil {
+			return nil, err
+		}
+		for _, env := range envs {
+			if env.Spec.Namespace != "" && util.StringArrayIndex(namespaces, env.Spec.Namespace) < 0 {
+				namespaces = append(namespaces, env.Spec.Namespace)
+			}
+		}
+		for _, ingressNs := range namespaces {
+			ingresses, err := kubeClient.ExtensionsV1beta1().Ingresses(ingressNs).List(metav1.ListOptions{})
+			if err != nil {
+				return nil, errors.Wrapf(err, "listing the ingresses in namespace %s", ingressNs)
+			}
+			for _, ing := range ingresses.Items {
+				for _, rule := range ing.Spec.Rules {
+					if strings.HasSuffix(rule.Host, "."+answer.Domain) {
+						add(ResourceKindDNSRecord, rule.Host)
+					}
+				}
+			}
+		}
+	}
+	return answer, nil
+}
+
+func (o *DeleteInstallationOptions) deleteProviderResources(resources *ProviderResources) error {
+	if resources.Provider != cloud.GKE {
+		log.Logger().Warnf("Deleting the resources of %s installations is not supported yet, please delete them manually:", resources.Provider)
+		for _, r := range resources.Resources {
+			log.Logger().Warnf("  %s %s", r.Kind, r.Name)
+		}
+		return nil
+	}
+
+	gcloud := o.GCloud()
+	var errs []error
+	hosts := []string{}
+	for _, r := range resources.Resources {
+		var err error
+		switch r.Kind {
+		case ResourceKindBucket:
+			log.Logger().Infof("Deleting bucket %s", util.ColorInfo(r.Name))
+			err = gcloud.DeleteAllObjectsInBucket(r.Name)
+			if err == nil {
+				err = gcloud.DeleteBucket(r.Name)
+			}
+		case ResourceKindServiceAccount:
+			log.Logger().Infof("Deleting service account %s", util.ColorInfo(r.Name))
+			err = gcloud.DeleteServiceAccount(r.Name, resources.ProjectID, nil)
+		case ResourceKindDNSRecord:
+			hosts = append(hosts, r.Name)
+		}
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "deleting %s %s", r.Kind, r.Name))
+		}
+	}
+	if len(hosts) > 0 {
+		log.Logger().Infof("Deleting the DNS records of %s", util.ColorInfo(strings.Join(hosts, ", ")))
+		err := gke.DeleteDNSRecordSets(resources.ProjectID, resources.Domain, hosts)
+		if err != nil {
+			errs = append(errs, errors.Wrap(err, "deleting the DNS records"))
+		}
+	}
+	if len(errs) > 0 {
+		return errorutil.CombineErrors(errs...)
+	}
+	log.Logger().Infof("Deleted the %d cloud provider resources of the installation", len(resources.Resources))
+	return nil
+}
+
+// bucketName returns the name of the bucket of a bucket URL such as gs://name
+func bucketName(bucketURL string) string {
+	idx := strings.Index(bucketURL, "://")
+	if idx < 0 {
+		return ""
+	}
+	return strings.Split(bucketURL[idx+3:], "/")[0]
+}
+
+// gcpServiceAccountName returns the name of the service account of a GCP service account key
+func gcpServiceAccountName(data []byte) string {
+	key := struct {
+		Type        string `json:"type"`
+		ClientEmail string `json:"client_email"`
+	}{}
+	err := json.Unmarshal(data, &key)
+	if err != nil || key.Type != "service_account" {
+		return ""
+	}
+	return strings.Split(key.ClientEmail, "@")[0]
+}
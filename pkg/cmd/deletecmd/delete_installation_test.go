@@ -0,0 +1,139 @@
+// +build unit
+
+package deletecmd
+
+import (
+	"testing"
+
+	"github.com/ghodss/yaml"
+	jxfake "github.com/jenkins-x/jx-api/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cloud"
+	gke_test "github.com/jenkins-x/jx/v2/pkg/cloud/gke/mocks"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/clients/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/petergtz/pegomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDiscoverProviderResources(t *testing.T) {
+	requirements := config.NewRequirementsConfig()
+	requirements.Cluster.Provider = cloud.GKE
+	requirements.Cluster.ProjectID = "my-project"
+	requirements.Cluster.ExternalDNSSAName = "mycluster-dn"
+	requirements.Cluster.KanikoSAName = "mycluster-ko"
+	requirements.Ingress.ExternalDNS = true
+	requirements.Ingress.Domain = "example.com"
+	requirements.Storage.Logs = config.StorageEntryConfig{Enabled: true, URL: "gs://mycluster-logs"}
+	requirements.Storage.Reports = config.StorageEntryConfig{Enabled: true, URL: "gs://mycluster-logs/reports"}
+	requirements.Vault.Bucket = "mycluster-vault"
+	data, err := yaml.Marshal(requirements)
+	require.NoError(t, err)
+
+	devEnv := kube.CreateDefaultDevEnvironment("jx")
+	devEnv.Namespace = "jx"
+	devEnv.Spec.TeamSettings.BootRequirements = string(data)
+	jxClient := jxfake.NewSimpleClientset(devEnv, kube.NewPermanentEnvironment("staging"))
+
+	kubeClient := kubefake.NewSimpleClientset(
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "vault-gcp-sa", Namespace: "jx"},
+			Data: map[string][]byte{
+				"service-account.json": []byte(`{"type": "service_account", "client_email": "mycluster-vt@my-project.iam.gserviceaccount.com"}`),
+			},
+		},
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-gcp-sa", Namespace: "jx"},
+			Data:       map[string][]byte{"token": []byte("not a key")},
+		},
+		ingress("jx", "hook", "hook-jx.example.com"),
+		ingress("jx-staging", "myapp", "myapp-jx-staging.example.com"),
+		ingress("jx-staging", "other", "myapp.other.io"),
+	)
+
+	resources, err := DiscoverProviderResources(kubeClient, jxClient, "jx")
+	require.NoError(t, err)
+
+	assert.Equal(t, cloud.GKE, resources.Provider)
+	assert.Equal(t, "my-project", resources.ProjectID)
+	assert.Equal(t, "example.com", resources.Domain)
+	assert.ElementsMatch(t, []ProviderResource{
+		{Kind: ResourceKindBucket, Name: "mycluster-logs"},
+		{Kind: ResourceKindBucket, Name: "mycluster-vault"},
+		{Kind: ResourceKindServiceAccount, Name: "mycluster-dn"},
+		{Kind: ResourceKindServiceAccount, Name: "mycluster-ko"},
+		{Kind: ResourceKindServiceAccount, Name: "mycluster-vt"},
+		{Kind: ResourceKindDNSRecord, Name: "hook-jx.example.com"},
+		{Kind: ResourceKindDNSRecord, Name: "myapp-jx-staging.example.com"},
+	}, resources.Resources)
+}
+
+func TestDiscoverProviderResourcesFromInstallValues(t *testing.T) {
+	kubeClient := kubefake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: kube.ConfigMapNameJXInstallConfig, Namespace: "jx"},
+		Data: map[string]string{
+			kube.KubeProvider: cloud.EKS,
+			kube.ProjectID:    "",
+		},
+	})
+	devEnv := kube.CreateDefaultDevEnvironment("jx")
+	devEnv.Namespace = "jx"
+	jxClient := jxfake.NewSimpleClientset(devEnv)
+
+	resources, err := DiscoverProviderResources(kubeClient, jxClient, "jx")
+	require.NoError(t, err)
+	assert.Equal(t, cloud.EKS, resources.Provider)
+	assert.Empty(t, resources.Resources)
+}
+
+func TestDeleteProviderResourcesOnGKE(t *testing.T) {
+	pegomock.RegisterMockTestingT(t)
+	gcloud := gke_test.NewMockGClouder()
+	commonOpts := opts.NewCommonOptionsWithFactory(fake.NewFakeFactory())
+	commonOpts.SetGCloudClient(gcloud)
+	o := &DeleteInstallationOptions{CommonOptions: &commonOpts}
+
+	err := o.deleteProviderResources(&ProviderResources{
+		Provider:  cloud.GKE,
+		ProjectID: "my-project",
+		Resources: []ProviderResource{
+			{Kind: ResourceKindBucket, Name: "mycluster-logs"},
+			{Kind: ResourceKindServiceAccount, Name: "mycluster-dn"},
+		},
+	})
+	require.NoError(t, err)
+
+	gcloud.VerifyWasCalledOnce().DeleteAllObjectsInBucket("mycluster-logs")
+	gcloud.VerifyWasCalledOnce().DeleteBucket("mycluster-logs")
+	gcloud.VerifyWasCalledOnce().DeleteServiceAccount(pegomock.EqString("mycluster-dn"), pegomock.EqString("my-project"), pegomock.AnyStringSlice())
+}
+
+func TestDeleteProviderResourcesOnOtherProviders(t *testing.T) {
+	pegomock.RegisterMockTestingT(t)
+	gcloud := gke_test.NewMockGClouder()
+	commonOpts := opts.NewCommonOptionsWithFactory(fake.NewFakeFactory())
+	commonOpts.SetGCloudClient(gcloud)
+	o := &DeleteInstallationOptions{CommonOptions: &commonOpts}
+
+	err := o.deleteProviderResources(&ProviderResources{
+		Provider:  cloud.EKS,
+		Resources: []ProviderResource{{Kind: ResourceKindBucket, Name: "mycluster-logs"}},
+	})
+	require.NoError(t, err)
+	gcloud.VerifyWasCalled(pegomock.Never()).DeleteBucket(pegomock.AnyString())
+}
+
+func ingress(ns string, name string, host string) *v1beta1.Ingress {
+	return &v1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+		Spec: v1beta1.IngressSpec{
+			Rules: []v1beta1.IngressRule{{Host: host}},
+		},
+	}
+}
@@ -23,6 +23,8 @@ type DeleteNamespaceOptions struct {
 	SelectAll    bool
 	SelectFilter string
 	Confirm      bool
+
+	OverrideProtected bool
 }
 
 var (
@@ -63,6 +65,7 @@ func NewCmdDeleteNamespace(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().BoolVarP(&options.SelectAll, "all", "a", false, "Should we default to selecting all the matched namespaces for deletion")
 	cmd.Flags().StringVarP(&options.SelectFilter, "filter", "f", "", "Filters the list of namespaces you can pick from")
 	cmd.Flags().BoolVarP(&options.Confirm, "yes", "y", false, "Confirms we should uninstall this installation")
+	opts.AddProtectedNamespaceFlag(cmd, &options.OverrideProtected)
 	return cmd
 }
 
@@ -95,6 +98,11 @@ func (o *DeleteNamespaceOptions) Run() error {
 		}
 	}
 
+	err = o.VerifyProtectedNamespaces("delete namespace", o.OverrideProtected, names...)
+	if err != nil {
+		return err
+	}
+
 	if o.BatchMode {
 		if !o.Confirm {
 			return fmt.Errorf("In batch mode you must specify the '-y' flag to confirm")
@@ -108,7 +116,7 @@ func (o *DeleteNamespaceOptions) Run() error {
 			Message: "Are you sure you want to delete all these namespaces?",
 			Default: false,
 		}
-		err = survey.AskOne(prompt, &flag, nil, surveyOpts)
+		err = util.AskOne(prompt, &flag, nil, surveyOpts)
 		if err != nil {
 			return err
 		}
@@ -10,8 +10,10 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cmd/promote"
 
 	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cloudevents"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/previews"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/spf13/cobra"
 )
@@ -121,6 +123,16 @@ func (o *DeletePreviewOptions) DeletePreview(name string) error {
 	if err != nil {
 		return err
 	}
+	deps, err := previews.GetEnvironmentDependencies(environment)
+	if err != nil {
+		return err
+	}
+	err = previews.NewManager(o.Helm()).Deprovision(deps, environment.Spec.Namespace)
+	if err != nil {
+		// the namespace of the preview is deleted anyway so lets carry on
+		log.Logger().Warnf("%s", err)
+	}
+
 	releaseName := kube.GetPreviewEnvironmentReleaseName(environment)
 	if len(releaseName) > 0 {
 		log.Logger().Infof("Deleting helm release: %s", util.ColorInfo(releaseName))
@@ -136,5 +148,15 @@ func (o *DeletePreviewOptions) DeletePreview(name string) error {
 		DeleteNamespace: true,
 	}
 	deleteOptions.Args = []string{name}
-	return deleteOptions.Run()
+	err = deleteOptions.Run()
+	if err != nil {
+		return err
+	}
+	o.EmitEvent(cloudevents.TypePreviewDeleted, name, &cloudevents.PreviewData{
+		Name:           name,
+		Namespace:      environment.Spec.Namespace,
+		Application:    environment.Spec.PreviewGitSpec.ApplicationName,
+		PullRequestURL: environment.Spec.PreviewGitSpec.URL,
+	})
+	return nil
 }
@@ -0,0 +1,108 @@
+package deletecmd
+
+import (
+	"fmt"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	deleteQuickstartCatalogLong = templates.LongDesc(`
+		Deletes a quickstart catalog registered by your team
+
+`)
+
+	deleteQuickstartCatalogExample = templates.Examples(`
+		# Pick a quickstart catalog to delete for your team
+		jx delete quickstartcatalog
+
+		# Delete the catalog 'mycompany' for your team
+		jx delete qscatalog mycompany
+	`)
+)
+
+// DeleteQuickstartCatalogOptions the options for the delete quickstartcatalog command
+type DeleteQuickstartCatalogOptions struct {
+	*opts.CommonOptions
+}
+
+// NewCmdDeleteQuickstartCatalog defines the command
+func NewCmdDeleteQuickstartCatalog(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &DeleteQuickstartCatalogOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     opts.QuickStartCatalogCommandName + " [NAME]",
+		Short:   "Deletes a quickstart catalog registered by your team",
+		Aliases: opts.QuickStartCatalogCommandAliases,
+		Long:    deleteQuickstartCatalogLong,
+		Example: deleteQuickstartCatalogExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	return cmd
+}
+
+// Run implements the command
+func (o *DeleteQuickstartCatalogOptions) Run() error {
+	name := ""
+	if len(o.Args) > 0 {
+		name = o.Args[0]
+	}
+	if name == "" {
+		if o.BatchMode {
+			return util.MissingArgument("name")
+		}
+		jxClient, ns, err := o.JXClientAndDevNamespace()
+		if err != nil {
+			return err
+		}
+		devEnv, err := kube.GetDevEnvironment(jxClient, ns)
+		if err != nil {
+			return err
+		}
+		catalogs, err := kube.GetQuickstartCatalogs(devEnv)
+		if err != nil {
+			return err
+		}
+		names := []string{}
+		for i := range catalogs {
+			names = append(names, catalogs[i].Name)
+		}
+		name, err = util.PickName(names, "Pick the quickstart catalog to remove from the team settings: ", "", o.GetIOFileHandles())
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			return fmt.Errorf("No catalog name chosen")
+		}
+	}
+
+	callback := func(env *v1.Environment) error {
+		catalogs, err := kube.GetQuickstartCatalogs(env)
+		if err != nil {
+			return err
+		}
+		for i := range catalogs {
+			if catalogs[i].Name == name {
+				catalogs = append(catalogs[0:i], catalogs[i+1:]...)
+				log.Logger().Infof("Removing quickstart catalog %s", util.ColorInfo(name))
+				return kube.SetQuickstartCatalogs(env, catalogs)
+			}
+		}
+		return fmt.Errorf("No quickstart catalog found with name: %s", name)
+	}
+	return o.ModifyDevEnvironment(callback)
+}
@@ -147,7 +147,7 @@ func (o *DeleteRepoOptions) Run() error {
 			Message: "Are you sure you want to delete these all these repositories?",
 			Default: false,
 		}
-		err = survey.AskOne(prompt, &flag, nil, surveyOpts)
+		err = util.AskOne(prompt, &flag, nil, surveyOpts)
 		if err != nil {
 			return err
 		}
@@ -114,7 +114,7 @@ func (o *DeleteTeamOptions) Run() error {
 			Message: "Are you sure you want to delete all these teams?",
 			Default: false,
 		}
-		err = survey.AskOne(prompt, &flag, nil, surveyOpts)
+		err = util.AskOne(prompt, &flag, nil, surveyOpts)
 		if err != nil {
 			return err
 		}
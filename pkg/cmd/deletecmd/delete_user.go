@@ -109,7 +109,7 @@ func (o *DeleteUserOptions) Run() error {
 			Message: "Are you sure you want to delete these all these users?",
 			Default: false,
 		}
-		err = survey.AskOne(prompt, &flag, nil, surveyOpts)
+		err = util.AskOne(prompt, &flag, nil, surveyOpts)
 		if err != nil {
 			return err
 		}
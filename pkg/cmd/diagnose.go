@@ -22,12 +22,15 @@ import (
 )
 
 func getValidArgs() []string {
-	return []string{"", "version", "status", "pvc", "pods", "ingresses", "secrets", "configmaps", "health"}
+	return []string{"", "version", "status", "pvc", "pods", "ingresses", "secrets", "configmaps", "health", DiagnoseBundleArg}
 }
 
 var jxDiagnoseExample = templates.Examples(fmt.Sprintf(`
 	# To print diagnostic information about pods in n1 namespace
 	jx diagnose pods -n n1
+
+	# To gather a redacted troubleshooting bundle for support and summarize the problems detected
+	jx diagnose bundle
 	Supported arguments to diagnose are %v
 	
 	Deprecated usage:
@@ -46,6 +49,15 @@ type DiagnoseOptions struct {
 	//Deprecated
 	Show   []string
 	Runner util.Commander
+
+	// BundleFile the file name of the troubleshooting bundle, defaults to a timestamped file in the current directory
+	BundleFile string
+	// LogLines the number of lines of the logs of each container added to the bundle
+	LogLines int64
+	// LookupHost resolves the hosts of the ingresses, defaults to net.LookupHost
+	LookupHost func(host string) ([]string, error)
+	// PodLogs returns the last lines of the logs of a container, defaults to using the kube client
+	PodLogs func(ns string, pod string, container string, lines int64) ([]byte, error)
 }
 
 //NewCmdDiagnose creates the diagnose command
@@ -76,6 +88,8 @@ func NewCmdDiagnose(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "The namespace to display the kube resources from. If left out, defaults to the current namespace")
 	cmd.Flags().StringArrayVarP(&options.Show, "show", "", []string{"version", "status", "pvc", "pods", "ingresses", "secrets", "configmaps"}, "Determine what information to diagnose")
 	cmd.Flags().BoolVarP(&options.HelmTLS, "helm-tls", "", false, "Whether to use TLS with helm")
+	cmd.Flags().StringVarP(&options.BundleFile, "bundle-file", "", "", "The file name of the troubleshooting tarball created by 'jx diagnose bundle'. Defaults to jx-diagnose-<timestamp>.tar.gz")
+	cmd.Flags().Int64VarP(&options.LogLines, "log-lines", "", DefaultDiagnoseLogLines, "The number of lines of the logs of each container added to the troubleshooting bundle")
 	_ = cmd.Flags().MarkDeprecated("show", "use jx diagnose <object> instead. This will be removed on July 1, 2020")
 	return cmd
 }
@@ -89,6 +103,9 @@ func (o *DiagnoseOptions) Run() error {
 	}
 
 	log.Logger().Infof("Running in namespace: %s", util.ColorInfo(ns))
+	if len(o.Args) == 1 && o.showInfo(DiagnoseBundleArg) {
+		return o.createBundle(kubeClient, ns)
+	}
 	_, table := o.GetPackageVersions(ns, o.HelmTLS)
 
 	// os version
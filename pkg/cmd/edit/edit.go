@@ -56,12 +56,14 @@ func NewCmdEdit(commonOpts *opts.CommonOptions) *cobra.Command {
 
 	cmd.AddCommand(NewCmdEditAddon(commonOpts))
 	cmd.AddCommand(NewCmdEditAppJenkinsPlugins(commonOpts))
+	cmd.AddCommand(NewCmdEditBuildCache(commonOpts))
 	cmd.AddCommand(NewCmdEditBuildpack(commonOpts))
 	cmd.AddCommand(NewCmdEditConfig(commonOpts))
 	cmd.AddCommand(NewCmdEditDeployKind(commonOpts))
 	cmd.AddCommand(NewCmdEditEnv(commonOpts))
 	cmd.AddCommand(NewCmdEditHelmBin(commonOpts))
 	cmd.AddCommand(requirements.NewCmdEditRequirements(commonOpts))
+	cmd.AddCommand(NewCmdEditScanPolicy(commonOpts))
 	cmd.AddCommand(NewCmdEditStorage(commonOpts))
 	cmd.AddCommand(NewCmdEditUserRole(commonOpts))
 
@@ -0,0 +1,131 @@
+package edit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/tekton"
+	"github.com/jenkins-x/jx/v2/pkg/tekton/syntax"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	editBuildCacheLong = templates.LongDesc(`
+		Configures the shared layer cache of the image builds of the pipelines of the installation
+
+		The cached layers are stored in a repository of the docker registry, which is supported by the kaniko and buildkit build engines, or in an S3 or GCS bucket, which is only supported by the buildkit build engine.
+
+		The cache is used by the pipelines whose build engine does not configure its own cache in the team requirements, the build pack or the jenkins-x.yml of the project
+`)
+
+	editBuildCacheExample = templates.Examples(`
+		# Cache the layers in a repository of the docker registry for a day
+		jx edit buildcache --type registry --repo gcr.io/myproject/cache --ttl 24h
+
+		# Cache the layers in an S3 bucket
+		jx edit buildcache --type s3 --url s3://mybucket/cache --region us-east-1
+
+		# Disable the cache
+		jx edit buildcache --disable
+	`)
+)
+
+// EditBuildCacheOptions the options for the edit buildcache command
+type EditBuildCacheOptions struct {
+	*opts.CommonOptions
+
+	Type    string
+	Repo    string
+	URL     string
+	Region  string
+	TTL     string
+	Disable bool
+}
+
+// NewCmdEditBuildCache creates a command object for the "edit buildcache" command
+func NewCmdEditBuildCache(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &EditBuildCacheOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "buildcache",
+		Short:   "Configures the shared layer cache of the image builds of the pipelines",
+		Aliases: []string{"build-cache"},
+		Long:    editBuildCacheLong,
+		Example: editBuildCacheExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Type, "type", "t", "", fmt.Sprintf("The storage of the cached layers. Possible values: %s", strings.Join(syntax.BuildCacheTypes, ", ")))
+	cmd.Flags().StringVarP(&options.Repo, "repo", "r", "", "The repository of the cached layers for the registry cache. Defaults to <docker registry>/<project id>/cache")
+	cmd.Flags().StringVarP(&options.URL, "url", "u", "", "The bucket URL of the cached layers for the s3 and gcs caches such as s3://mybucket/cache")
+	cmd.Flags().StringVarP(&options.Region, "region", "", "", "The region of the S3 bucket")
+	cmd.Flags().StringVarP(&options.TTL, "ttl", "", "", "How long the cached layers are used such as 24h. Only used by kaniko")
+	cmd.Flags().BoolVarP(&options.Disable, "disable", "", false, "Disables the cache")
+	return cmd
+}
+
+// Run implements the command
+func (o *EditBuildCacheOptions) Run() error {
+	kubeClient, devNs, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	cache, err := tekton.LoadBuildCache(kubeClient, devNs)
+	if err != nil {
+		return err
+	}
+	if cache == nil {
+		cache = &syntax.BuildCache{}
+	}
+	flags := o.Cmd.Flags()
+	if flags.Changed("type") {
+		cache.Type = syntax.BuildCacheType(o.Type)
+	}
+	if flags.Changed("repo") {
+		cache.Repo = o.Repo
+	}
+	if flags.Changed("url") {
+		cache.URL = o.URL
+	}
+	if flags.Changed("region") {
+		cache.Region = o.Region
+	}
+	if flags.Changed("ttl") {
+		cache.TTL = o.TTL
+	}
+	if flags.Changed("disable") {
+		cache.Disabled = o.Disable
+	}
+	err = cache.Validate()
+	if err != nil {
+		return err
+	}
+	err = tekton.SaveBuildCache(kubeClient, devNs, cache)
+	if err != nil {
+		return err
+	}
+	if cache.Disabled {
+		log.Logger().Infof("Disabled the build cache")
+		return nil
+	}
+	location := cache.Repo
+	if cache.IsBucket() {
+		location = cache.URL
+	}
+	if location == "" {
+		location = "the default repository of the docker registry"
+	}
+	log.Logger().Infof("Updated the %s build cache to %s", util.ColorInfo(cache.GetType()), util.ColorInfo(location))
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/jenkins-x/jx/v2/pkg/buildpacks"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/v2/pkg/config"
 
@@ -23,6 +24,8 @@ var (
 	editBuildpackLong = templates.LongDesc(`
 		** This command does not work on boot based clusters and has been disabled **
 		Edits the build pack configuration for your team
+
+		Additional build pack repositories can be added to the ordered list of repositories of the team via '--add-repo'. Packs are searched in the repositories of the list in order followed by the default build pack repository of the team. Each repository is pinned to the Git reference of '--ref' so that its packs are only upgraded when the reference changes.
 `)
 
 	editBuildpackExample = templates.Examples(`
@@ -46,6 +49,8 @@ type EditBuildPackOptions struct {
 	BuildPackName string
 	BuildPackURL  string
 	BuildPackRef  string
+	AddRepo       string
+	RemoveRepo    string
 }
 
 // NewCmdEditBuildpack creates a command object for the "create" command
@@ -74,11 +79,16 @@ func NewCmdEditBuildpack(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.BuildPackURL, "url", "u", "", "The URL for the build pack Git repository")
 	cmd.Flags().StringVarP(&options.BuildPackRef, "ref", "r", "", "The Git reference (branch,tag,sha) in the Git repository to use")
 	cmd.Flags().StringVarP(&options.BuildPackName, "name", "n", "", "The name of the BuildPack resource to use")
+	cmd.Flags().StringVarP(&options.AddRepo, "add-repo", "", "", "The URL of a build pack Git repository to add to the repositories searched for packs, pinned to the Git reference of --ref")
+	cmd.Flags().StringVarP(&options.RemoveRepo, "remove-repo", "", "", "The URL of a build pack Git repository to remove from the repositories searched for packs")
 	return cmd
 }
 
 // Run implements the command
 func (o *EditBuildPackOptions) Run() error {
+	if o.AddRepo != "" || o.RemoveRepo != "" {
+		return o.editRepositories()
+	}
 	teamSettings, err := o.TeamSettings()
 	if err != nil {
 		return err
@@ -193,6 +203,32 @@ func (o *EditBuildPackOptions) Run() error {
 	return o.ModifyDevEnvironment(callback)
 }
 
+// editRepositories adds or removes the build pack repositories of the team
+func (o *EditBuildPackOptions) editRepositories() error {
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	repositories, err := buildpacks.LoadRepositories(kubeClient, ns)
+	if err != nil {
+		return err
+	}
+	if o.RemoveRepo != "" {
+		var removed bool
+		repositories, removed = buildpacks.RemoveRepository(repositories, o.RemoveRepo)
+		if !removed {
+			return fmt.Errorf("the build pack repository %s is not one of the repositories of the team", o.RemoveRepo)
+		}
+		log.Logger().Infof("Removed the build pack repository %s", util.ColorInfo(o.RemoveRepo))
+	}
+	if o.AddRepo != "" {
+		repository := buildpacks.Repository{URL: o.AddRepo, Ref: o.BuildPackRef}
+		repositories = buildpacks.AddRepository(repositories, repository)
+		log.Logger().Infof("Searching the build pack repository %s at ref %s", util.ColorInfo(repository.URL), util.ColorInfo(repository.GetRef()))
+	}
+	return buildpacks.SaveRepositories(kubeClient, ns, repositories)
+}
+
 func (o *EditBuildPackOptions) isBoot(teamSettings *v1.TeamSettings) (bool, error) {
 	isBoot := false
 	requirements, err := config.GetRequirementsConfigFromTeamSettings(teamSettings)
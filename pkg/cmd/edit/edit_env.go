@@ -1,6 +1,10 @@
 package edit
 
 import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 	"github.com/spf13/cobra"
 
@@ -12,7 +16,9 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/jenkinsfile"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/promotion"
 	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -33,6 +39,16 @@ var (
 
 		# Edit the prod Environment in batch mode (so not interactive)
 		jx edit env -b -n prod -l Production --no-gitops --namespace my-prod
+
+		# Require an approval and passing smoke tests before merging the promotions to production
+		jx edit env -b -n production --promotion-gates approval,smoke-tests --promotion-smoke-test "./smoke-test.sh"
+
+		# Only merge the promotions to production during office hours and not over the holidays
+		jx edit env -b -n production --promotion-windows "* 9-16 * * 1-5" --promotion-timezone Europe/Madrid \
+		  --promotion-freezes "2026-12-20T00:00:00Z/2027-01-04T00:00:00Z"
+
+		# Deploy production to a separate cluster using the kube config of a service account of that cluster
+		jx edit env -b -n production --cluster prod-cluster --cluster-kubeconfig ./prod-kubeconfig.yaml
 	`)
 )
 
@@ -49,6 +65,13 @@ type EditEnvOptions struct {
 	GitRepositoryOptions   gits.GitRepositoryOptions
 	Prefix                 string
 	BranchPattern          string
+	PromotionGates         string
+	PromotionSmokeTest     string
+	PromotionCheckURL      string
+	PromotionWindows       string
+	PromotionFreezes       string
+	PromotionTimeZone      string
+	ClusterKubeConfig      string
 }
 
 // NewCmdEditEnv creates a command object for the "create" command
@@ -79,6 +102,7 @@ func NewCmdEditEnv(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.Options.Spec.Label, "label", "l", "", "The Environment label which is a descriptive string like 'Production' or 'Staging'")
 	cmd.Flags().StringVarP(&options.Options.Spec.Namespace, kube.OptionNamespace, "s", "", "The Kubernetes namespace for the Environment")
 	cmd.Flags().StringVarP(&options.Options.Spec.Cluster, "cluster", "c", "", "The Kubernetes cluster for the Environment. If blank and a namespace is specified assumes the current cluster")
+	cmd.Flags().StringVarP(&options.ClusterKubeConfig, "cluster-kubeconfig", "", "", "The kube config file used to deploy the Environment to its cluster from the development cluster. It is stored in a Secret in the development namespace")
 	cmd.Flags().BoolVarP(&options.Options.Spec.RemoteCluster, "remote", "", false, "Indicates the Environment resides in a separate cluster to the development cluster. If this is true then we don't perform release piplines in this git repository but we use the Environment Controller inside that cluster: https://jenkins-x.io/getting-started/multi-cluster/")
 	cmd.Flags().StringVarP(&options.Options.Spec.Source.URL, "git-url", "g", "", "The Git clone URL for the source code for GitOps based Environments")
 	cmd.Flags().StringVarP(&options.Options.Spec.Source.Ref, "git-ref", "r", "", "The Git repo reference for the source code for GitOps based Environments")
@@ -91,6 +115,12 @@ func NewCmdEditEnv(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.BranchPattern, "branches", "", "", "The branch pattern for branches to trigger CI/CD pipelines on the environment Git repository")
 
 	cmd.Flags().BoolVarP(&options.NoGitOps, "no-gitops", "x", false, "Disables the use of GitOps on the environment so that promotion is implemented by directly modifying the resources via Helm instead of using a Git repository")
+	cmd.Flags().StringVarP(&options.PromotionGates, "promotion-gates", "", "", fmt.Sprintf("The comma separated gates a promotion has to pass before its Pull Request is merged: %s", strings.Join(promotion.Gates, ", ")))
+	cmd.Flags().StringVarP(&options.PromotionSmokeTest, "promotion-smoke-test", "", "", "The smoke test command of the smoke-tests promotion gate")
+	cmd.Flags().StringVarP(&options.PromotionCheckURL, "promotion-check-url", "", "", "The URL of the webhook of the check promotion gate")
+	cmd.Flags().StringVarP(&options.PromotionWindows, "promotion-windows", "", "", "The semicolon separated cron expressions of the minutes during which promotions are merged such as '* 9-16 * * 1-5'")
+	cmd.Flags().StringVarP(&options.PromotionFreezes, "promotion-freezes", "", "", "The comma separated freeze periods during which promotions are not merged, each an RFC 3339 start and end time separated by a slash")
+	cmd.Flags().StringVarP(&options.PromotionTimeZone, "promotion-timezone", "", "", "The time zone of the promotion windows such as 'Europe/Madrid', defaults to UTC")
 
 	opts.AddGitRepoOptionsArguments(cmd, &options.GitRepositoryOptions)
 	options.HelmValuesConfig.AddExposeControllerValues(cmd, false)
@@ -162,6 +192,24 @@ func (o *EditEnvOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	err = o.updatePromotionGates(env)
+	if err != nil {
+		return err
+	}
+	if o.ClusterKubeConfig != "" {
+		if env.Spec.Cluster == "" {
+			return util.MissingOption("cluster")
+		}
+		data, err := ioutil.ReadFile(o.ClusterKubeConfig)
+		if err != nil {
+			return errors.Wrapf(err, "reading the kube config file %s", o.ClusterKubeConfig)
+		}
+		err = kube.SaveClusterKubeConfig(kubeClient, ns, env.Spec.Cluster, data)
+		if err != nil {
+			return err
+		}
+		log.Logger().Infof("Saved the kube config of cluster %s", util.ColorInfo(env.Spec.Cluster))
+	}
 	_, err = jxClient.JenkinsV1().Environments(ns).PatchUpdate(env)
 	if err != nil {
 		return err
@@ -185,3 +233,38 @@ func (o *EditEnvOptions) Run() error {
 	}
 	return nil
 }
+
+// updatePromotionGates updates the promotion gate annotations of the environment with the flags which were specified
+func (o *EditEnvOptions) updatePromotionGates(env *v1.Environment) error {
+	flags := map[string]string{
+		"promotion-gates":      promotion.AnnotationGates,
+		"promotion-smoke-test": promotion.AnnotationSmokeTest,
+		"promotion-check-url":  promotion.AnnotationCheckURL,
+		"promotion-windows":    promotion.AnnotationWindows,
+		"promotion-freezes":    promotion.AnnotationFreezes,
+		"promotion-timezone":   promotion.AnnotationTimeZone,
+	}
+	values := map[string]string{
+		"promotion-gates":      o.PromotionGates,
+		"promotion-smoke-test": o.PromotionSmokeTest,
+		"promotion-check-url":  o.PromotionCheckURL,
+		"promotion-windows":    o.PromotionWindows,
+		"promotion-freezes":    o.PromotionFreezes,
+		"promotion-timezone":   o.PromotionTimeZone,
+	}
+	for flag, annotation := range flags {
+		if o.Cmd == nil || !o.Cmd.Flags().Changed(flag) {
+			continue
+		}
+		if env.Annotations == nil {
+			env.Annotations = map[string]string{}
+		}
+		if values[flag] == "" {
+			delete(env.Annotations, annotation)
+		} else {
+			env.Annotations[annotation] = values[flag]
+		}
+	}
+	_, err := promotion.EnvironmentGates(env)
+	return err
+}
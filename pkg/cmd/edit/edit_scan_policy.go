@@ -0,0 +1,109 @@
+package edit
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/scan"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	editScanPolicyLong = templates.LongDesc(`
+		Configures the scan policy of the images of your team used by 'jx step scan image'
+
+		The policy configures the scanner and the severity thresholds of the vulnerabilities failing the builds or reported as warnings
+`)
+
+	editScanPolicyExample = templates.Examples(`
+		# Fail the builds on HIGH vulnerabilities and warn on MEDIUM ones
+		jx edit scanpolicy --fail-on HIGH --warn-on MEDIUM
+
+		# Scan the images with grype ignoring the vulnerabilities without a fix
+		jx edit scanpolicy --scanner grype --ignore-unfixed
+
+		# Only warn on vulnerabilities ignoring a known one
+		jx edit scanpolicy --fail-on "" --warn-on HIGH --ignore CVE-2020-1234
+	`)
+)
+
+// EditScanPolicyOptions the options for the edit scanpolicy command
+type EditScanPolicyOptions struct {
+	*opts.CommonOptions
+
+	Scanner       string
+	FailOn        string
+	WarnOn        string
+	IgnoreUnfixed bool
+	Ignore        []string
+}
+
+// NewCmdEditScanPolicy creates a command object for the "edit scanpolicy" command
+func NewCmdEditScanPolicy(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &EditScanPolicyOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "scanpolicy",
+		Short:   "Configures the scan policy of the images of your team",
+		Aliases: []string{"scan-policy"},
+		Long:    editScanPolicyLong,
+		Example: editScanPolicyExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Scanner, "scanner", "s", "", fmt.Sprintf("The scanner of the images. Possible values: %s", util.ColorInfo(scan.Scanners)))
+	cmd.Flags().StringVarP(&options.FailOn, "fail-on", "", "", fmt.Sprintf("The lowest severity failing the builds, empty to never fail them. Possible values: %s", util.ColorInfo(scan.Severities)))
+	cmd.Flags().StringVarP(&options.WarnOn, "warn-on", "", "", "The lowest severity reported as a warning, empty to disable the warnings")
+	cmd.Flags().BoolVarP(&options.IgnoreUnfixed, "ignore-unfixed", "", false, "Ignores the vulnerabilities without a fixed version")
+	cmd.Flags().StringArrayVarP(&options.Ignore, "ignore", "", nil, "The IDs of the vulnerabilities to ignore")
+	return cmd
+}
+
+// Run implements the command
+func (o *EditScanPolicyOptions) Run() error {
+	kubeClient, devNs, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	policy, err := scan.LoadPolicy(kubeClient, devNs)
+	if err != nil {
+		return err
+	}
+	flags := o.Cmd.Flags()
+	if flags.Changed("scanner") {
+		policy.Scanner = o.Scanner
+	}
+	if flags.Changed("fail-on") {
+		policy.FailOn = o.FailOn
+	}
+	if flags.Changed("warn-on") {
+		policy.WarnOn = o.WarnOn
+	}
+	if flags.Changed("ignore-unfixed") {
+		policy.IgnoreUnfixed = o.IgnoreUnfixed
+	}
+	if flags.Changed("ignore") {
+		policy.Ignore = o.Ignore
+	}
+	err = policy.Validate()
+	if err != nil {
+		return err
+	}
+	err = scan.SavePolicy(kubeClient, devNs, policy)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Updated the scan policy: scanner %s failing on %s and warning on %s", util.ColorInfo(policy.Scanner),
+		util.ColorInfo(policy.FailOn), util.ColorInfo(policy.WarnOn))
+	return nil
+}
@@ -0,0 +1,52 @@
+package export
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/spf13/cobra"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+)
+
+// ExportOptions contains the command line options
+type ExportOptions struct {
+	*opts.CommonOptions
+}
+
+var (
+	exportLong = templates.LongDesc(`
+		Exports the state of a Jenkins X installation to files.
+
+		Valid resources include:
+
+		* install
+`)
+)
+
+// NewCmdExport creates a command object for the "export" command
+func NewCmdExport(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &ExportOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Exports the state of a Jenkins X installation to files",
+		Long:  exportLong,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.AddCommand(NewCmdExportInstall(commonOpts))
+
+	return cmd
+}
+
+// Run implements this command
+func (o *ExportOptions) Run() error {
+	return o.Cmd.Help()
+}
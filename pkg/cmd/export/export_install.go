@@ -0,0 +1,408 @@
+package export
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx-api/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// InstallManifestFile the name of the file describing the exported installation
+	InstallManifestFile = "install.yaml"
+
+	redactedValue = "*****"
+)
+
+var (
+	exportInstallLong = templates.LongDesc(`
+		Exports everything needed to recreate the Jenkins X installation of the current team to a directory, for disaster recovery or to migrate the installation to another cluster:
+
+		* install.yaml describing the installation: the version stream and the commit it resolves to, the git repositories of the environments and references to the Secrets of the team
+		* the requirements or the team settings of the installation
+		* values/ containing the resolved values of the helm releases of the dev namespace with the credentials redacted
+		* crds/ containing the CustomResourceDefinitions of Jenkins X and Tekton
+
+		The values of the Secrets are never exported, only their names and keys so they can be recreated from the secret store of the team
+`)
+
+	exportInstallExample = templates.Examples(`
+		# Export the installation to the out directory
+		jx export install --dir out
+	`)
+
+	// secretKeyPattern matches the keys of values which look like credentials
+	secretKeyPattern = regexp.MustCompile(`(?i)(password|passwd|secret|token|api[_-]?key|credentials|hmac|privatekey)`)
+
+	// exportedCRDGroups the groups of the CustomResourceDefinitions which are exported
+	exportedCRDGroups = []string{"jenkins.io", "tekton.dev"}
+)
+
+// InstallManifest describes an exported installation
+type InstallManifest struct {
+	Cluster       string                 `json:"cluster,omitempty"`
+	Provider      string                 `json:"provider,omitempty"`
+	DevNamespace  string                 `json:"devNamespace"`
+	ExportedAt    string                 `json:"exportedAt"`
+	VersionStream VersionStreamLock      `json:"versionStream"`
+	Environments  []EnvironmentReference `json:"environments,omitempty"`
+	Releases      []ReleaseReference     `json:"releases,omitempty"`
+	Secrets       []SecretReference      `json:"secrets,omitempty"`
+	CRDs          []string               `json:"crds,omitempty"`
+	Requirements  string                 `json:"requirements,omitempty"`
+}
+
+// VersionStreamLock the version stream of an installation and the commit its ref resolved to
+type VersionStreamLock struct {
+	URL    string `json:"url,omitempty"`
+	Ref    string `json:"ref,omitempty"`
+	Commit string `json:"commit,omitempty"`
+}
+
+// EnvironmentReference the git repository of an environment
+type EnvironmentReference struct {
+	Name              string `json:"name"`
+	Namespace         string `json:"namespace,omitempty"`
+	Kind              string `json:"kind,omitempty"`
+	PromotionStrategy string `json:"promotionStrategy,omitempty"`
+	GitURL            string `json:"gitUrl,omitempty"`
+	GitRef            string `json:"gitRef,omitempty"`
+}
+
+// ReleaseReference a helm release whose values are exported
+type ReleaseReference struct {
+	Name       string `json:"name"`
+	Chart      string `json:"chart,omitempty"`
+	Version    string `json:"version,omitempty"`
+	ValuesFile string `json:"valuesFile"`
+}
+
+// SecretReference references a Secret without its values
+type SecretReference struct {
+	Name      string   `json:"name"`
+	Namespace string   `json:"namespace"`
+	Type      string   `json:"type,omitempty"`
+	Keys      []string `json:"keys,omitempty"`
+}
+
+// ExportInstallOptions the options for the export install command
+type ExportInstallOptions struct {
+	*opts.CommonOptions
+
+	Dir string
+
+	// ReleaseValues returns the resolved values of a helm release, defaults to helm get values
+	ReleaseValues func(release string, ns string) (map[string]interface{}, error)
+}
+
+// NewCmdExportInstall creates a command object for the "export install" command
+func NewCmdExportInstall(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &ExportInstallOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "install",
+		Short:   "Exports everything needed to recreate the installation to a directory",
+		Long:    exportInstallLong,
+		Example: exportInstallExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", "", "The directory to export the installation to")
+	return cmd
+}
+
+// Run implements the command
+func (o *ExportInstallOptions) Run() error {
+	if o.Dir == "" {
+		return util.MissingOption("dir")
+	}
+	if o.ReleaseValues == nil {
+		o.ReleaseValues = o.helmReleaseValues
+	}
+	err := os.MkdirAll(o.Dir, util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "creating directory %s", o.Dir)
+	}
+	kubeClient, devNs, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return err
+	}
+	apiClient, err := o.ApiExtensionsClient()
+	if err != nil {
+		return errors.Wrap(err, "creating the api extensions client")
+	}
+	settings, err := o.TeamSettings()
+	if err != nil {
+		return errors.Wrap(err, "getting the team settings")
+	}
+
+	manifest := &InstallManifest{
+		DevNamespace: devNs,
+		ExportedAt:   time.Now().UTC().Format(time.RFC3339),
+		Provider:     settings.KubeProvider,
+		VersionStream: VersionStreamLock{
+			URL: settings.VersionStreamURL,
+			Ref: settings.VersionStreamRef,
+		},
+	}
+	requirements, err := config.GetRequirementsConfigFromTeamSettings(settings)
+	if err != nil {
+		return errors.Wrap(err, "getting the requirements from the team settings")
+	}
+	if requirements != nil {
+		manifest.Cluster = requirements.Cluster.ClusterName
+		manifest.Requirements = config.RequirementsConfigFileName
+		err = requirements.SaveConfig(filepath.Join(o.Dir, config.RequirementsConfigFileName))
+		if err != nil {
+			return errors.Wrapf(err, "saving the requirements")
+		}
+	} else {
+		manifest.Requirements = "team-settings.yaml"
+		err = writeYAML(filepath.Join(o.Dir, manifest.Requirements), settings)
+		if err != nil {
+			return err
+		}
+	}
+
+	manifest.VersionStream.Commit, err = o.versionStreamCommit()
+	if err != nil {
+		log.Logger().Warnf("Failed to resolve the commit of the version stream: %s", err.Error())
+	}
+	manifest.Environments, err = EnvironmentReferences(jxClient, devNs)
+	if err != nil {
+		return err
+	}
+	manifest.Secrets, err = SecretReferences(kubeClient, devNs)
+	if err != nil {
+		return err
+	}
+	manifest.Releases, err = o.exportReleaseValues(devNs)
+	if err != nil {
+		return err
+	}
+	manifest.CRDs, err = ExportCRDs(apiClient, filepath.Join(o.Dir, "crds"))
+	if err != nil {
+		return err
+	}
+	err = writeYAML(filepath.Join(o.Dir, InstallManifestFile), manifest)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Exported the installation of namespace %s to %s", util.ColorInfo(devNs), util.ColorInfo(o.Dir))
+	return nil
+}
+
+func (o *ExportInstallOptions) versionStreamCommit() (string, error) {
+	resolver, err := o.GetVersionResolver()
+	if err != nil {
+		return "", err
+	}
+	return o.Git().GetLatestCommitSha(resolver.VersionsDir)
+}
+
+func (o *ExportInstallOptions) exportReleaseValues(ns string) ([]ReleaseReference, error) {
+	releases, names, err := o.Helm().ListReleases(ns)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the helm releases in namespace %s", ns)
+	}
+	sort.Strings(names)
+	answer := []ReleaseReference{}
+	for _, name := range names {
+		values, err := o.ReleaseValues(name, ns)
+		if err != nil {
+			return nil, err
+		}
+		RedactValues(values)
+		valuesFile := filepath.Join("values", name+".yaml")
+		err = writeYAML(filepath.Join(o.Dir, valuesFile), values)
+		if err != nil {
+			return nil, err
+		}
+		release := releases[name]
+		answer = append(answer, ReleaseReference{
+			Name:       name,
+			Chart:      release.Chart,
+			Version:    release.ChartVersion,
+			ValuesFile: valuesFile,
+		})
+	}
+	return answer, nil
+}
+
+func (o *ExportInstallOptions) helmReleaseValues(release string, ns string) (map[string]interface{}, error) {
+	args := []string{"get", "values", release, "--output", "yaml"}
+	if o.Helm().HelmBinary() != "helm" {
+		args = append(args, "--namespace", ns)
+	}
+	cmd := util.Command{
+		Name: o.Helm().HelmBinary(),
+		Args: args,
+	}
+	output, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting the values of release %s", release)
+	}
+	values, err := helm.LoadValues([]byte(output))
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the values of release %s", release)
+	}
+	return values, nil
+}
+
+// EnvironmentReferences returns the git repositories of the environments of the team
+func EnvironmentReferences(jxClient versioned.Interface, ns string) ([]EnvironmentReference, error) {
+	envs, err := jxClient.JenkinsV1().Environments(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the environments in namespace %s", ns)
+	}
+	answer := []EnvironmentReference{}
+	for _, env := range envs.Items {
+		answer = append(answer, EnvironmentReference{
+			Name:              env.Name,
+			Namespace:         env.Spec.Namespace,
+			Kind:              string(env.Spec.Kind),
+			PromotionStrategy: string(env.Spec.PromotionStrategy),
+			GitURL:            env.Spec.Source.URL,
+			GitRef:            env.Spec.Source.Ref,
+		})
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Name < answer[j].Name
+	})
+	return answer, nil
+}
+
+// SecretReferences returns the names and keys of the Secrets of the namespace without their values. The tokens of
+// the service accounts and the releases of helm are recreated on install so are skipped
+func SecretReferences(kubeClient kubernetes.Interface, ns string) ([]SecretReference, error) {
+	secrets, err := kubeClient.CoreV1().Secrets(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the Secrets in namespace %s", ns)
+	}
+	answer := []SecretReference{}
+	for _, s := range secrets.Items {
+		if s.Type == corev1.SecretTypeServiceAccountToken || strings.HasPrefix(string(s.Type), "helm.sh/") {
+			continue
+		}
+		keys := []string{}
+		for k := range s.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		answer = append(answer, SecretReference{
+			Name:      s.Name,
+			Namespace: s.Namespace,
+			Type:      string(s.Type),
+			Keys:      keys,
+		})
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Name < answer[j].Name
+	})
+	return answer, nil
+}
+
+// ExportCRDs writes the CustomResourceDefinitions of Jenkins X and Tekton to the directory returning their names
+func ExportCRDs(apiClient clientset.Interface, dir string) ([]string, error) {
+	crds, err := apiClient.ApiextensionsV1beta1().CustomResourceDefinitions().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing the CustomResourceDefinitions")
+	}
+	answer := []string{}
+	for i := range crds.Items {
+		crd := crds.Items[i]
+		if !isExportedGroup(crd.Spec.Group) {
+			continue
+		}
+		crd.TypeMeta = metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1beta1", Kind: "CustomResourceDefinition"}
+		crd.ObjectMeta = metav1.ObjectMeta{
+			Name:        crd.Name,
+			Labels:      crd.Labels,
+			Annotations: crd.Annotations,
+		}
+		crd.Status = apiextensionsv1beta1.CustomResourceDefinitionStatus{}
+		err = writeYAML(filepath.Join(dir, crd.Name+".yaml"), &crd)
+		if err != nil {
+			return nil, err
+		}
+		answer = append(answer, crd.Name)
+	}
+	sort.Strings(answer)
+	return answer, nil
+}
+
+// RedactValues replaces the values of the keys which look like credentials
+func RedactValues(values map[string]interface{}) {
+	for k, v := range values {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			RedactValues(t)
+		case []interface{}:
+			for _, item := range t {
+				if m, ok := item.(map[string]interface{}); ok {
+					RedactValues(m)
+				}
+			}
+		case string:
+			if t != "" && secretKeyPattern.MatchString(k) {
+				values[k] = redactedValue
+			}
+		}
+	}
+}
+
+func isExportedGroup(group string) bool {
+	for _, g := range exportedCRDGroups {
+		if group == g || strings.HasSuffix(group, "."+g) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeYAML(fileName string, value interface{}) error {
+	err := os.MkdirAll(filepath.Dir(fileName), util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "creating directory %s", filepath.Dir(fileName))
+	}
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return errors.Wrapf(err, "marshalling %s", fileName)
+	}
+	err = ioutil.WriteFile(fileName, data, util.DefaultFileWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "writing %s", fileName)
+	}
+	return nil
+}
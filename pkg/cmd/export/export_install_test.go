@@ -0,0 +1,106 @@
+// +build unit
+
+package export_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	jxfake "github.com/jenkins-x/jx-api/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/export"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apifake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRedactValues(t *testing.T) {
+	t.Parallel()
+
+	values := map[string]interface{}{
+		"expose": map[string]interface{}{"domain": "example.com"},
+		"chartmuseum": map[string]interface{}{
+			"env": map[string]interface{}{
+				"secret": map[string]interface{}{"BASIC_AUTH_PASS": "pass", "BASIC_AUTH_USER": "admin"},
+			},
+		},
+		"pipelineSecrets": []interface{}{
+			map[string]interface{}{"name": "github", "token": "abc123"},
+		},
+		"adminPassword": "secret",
+	}
+	export.RedactValues(values)
+	assert.Equal(t, "example.com", values["expose"].(map[string]interface{})["domain"])
+	assert.Equal(t, "*****", values["adminPassword"])
+	assert.Equal(t, "*****", values["pipelineSecrets"].([]interface{})[0].(map[string]interface{})["token"])
+	assert.Equal(t, "github", values["pipelineSecrets"].([]interface{})[0].(map[string]interface{})["name"])
+}
+
+func TestSecretAndEnvironmentReferences(t *testing.T) {
+	t.Parallel()
+
+	kubeClient := fake.NewSimpleClientset(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "jx-pipeline-git-github", Namespace: "jx"},
+			Type:       corev1.SecretTypeOpaque,
+			Data:       map[string][]byte{"username": []byte("bot"), "password": []byte("token")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "default-token-abcde", Namespace: "jx"},
+			Type:       corev1.SecretTypeServiceAccountToken,
+		},
+	)
+	secrets, err := export.SecretReferences(kubeClient, "jx")
+	require.NoError(t, err)
+	assert.Equal(t, []export.SecretReference{
+		{Name: "jx-pipeline-git-github", Namespace: "jx", Type: "Opaque", Keys: []string{"password", "username"}},
+	}, secrets)
+
+	jxClient := jxfake.NewSimpleClientset(&v1.Environment{
+		ObjectMeta: metav1.ObjectMeta{Name: "staging", Namespace: "jx"},
+		Spec: v1.EnvironmentSpec{
+			Namespace:         "jx-staging",
+			Kind:              v1.EnvironmentKindTypePermanent,
+			PromotionStrategy: v1.PromotionStrategyTypeAutomatic,
+			Source:            v1.EnvironmentRepository{URL: "https://github.com/myorg/environment-staging.git", Ref: "master"},
+		},
+	})
+	envs, err := export.EnvironmentReferences(jxClient, "jx")
+	require.NoError(t, err)
+	require.Len(t, envs, 1)
+	assert.Equal(t, "https://github.com/myorg/environment-staging.git", envs[0].GitURL)
+	assert.Equal(t, "Permanent", envs[0].Kind)
+}
+
+func TestExportCRDs(t *testing.T) {
+	t.Parallel()
+
+	crd := func(name string, group string) *apiextensionsv1beta1.CustomResourceDefinition {
+		return &apiextensionsv1beta1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: name, ResourceVersion: "42"},
+			Spec:       apiextensionsv1beta1.CustomResourceDefinitionSpec{Group: group},
+		}
+	}
+	apiClient := apifake.NewSimpleClientset(
+		crd("environments.jenkins.io", "jenkins.io"),
+		crd("pipelineruns.tekton.dev", "tekton.dev"),
+		crd("certificates.cert-manager.io", "cert-manager.io"),
+	)
+	dir, err := ioutil.TempDir("", "export-crds-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) //nolint:errcheck
+
+	names, err := export.ExportCRDs(apiClient, dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"environments.jenkins.io", "pipelineruns.tekton.dev"}, names)
+	data, err := ioutil.ReadFile(filepath.Join(dir, "environments.jenkins.io.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "kind: CustomResourceDefinition")
+	assert.NotContains(t, string(data), "resourceVersion")
+}
@@ -20,7 +20,10 @@ const (
 	valid_gc_resources = `Valid resource types include:
 
     * activities
+	* artifacts
+	* gitcache
 	* helm
+	* images
 	* previews
 	* releases
     `
@@ -36,8 +39,11 @@ var (
 
 	gc_example = templates.Examples(`
 		jx gc activities
+		jx gc artifacts
+		jx gc gitcache
 		jx gc gke
 		jx gc helm
+		jx gc images
 		jx gc previews
 		jx gc releases
 
@@ -65,9 +71,12 @@ func NewCmdGC(commonOpts *opts.CommonOptions) *cobra.Command {
 	}
 
 	cmd.AddCommand(NewCmdGCActivities(commonOpts))
+	cmd.AddCommand(NewCmdGCArtifacts(commonOpts))
+	cmd.AddCommand(NewCmdGCGitCache(commonOpts))
 	cmd.AddCommand(NewCmdGCPreviews(commonOpts))
 	cmd.AddCommand(NewCmdGCGKE(commonOpts))
 	cmd.AddCommand(NewCmdGCHelm(commonOpts))
+	cmd.AddCommand(NewCmdGCImages(commonOpts))
 	cmd.AddCommand(NewCmdGCPods(commonOpts))
 	cmd.AddCommand(NewCmdGCReleases(commonOpts))
 
@@ -1,17 +1,14 @@
 package gc
 
 import (
-	"sort"
-	"strings"
 	"time"
 
 	gojenkins "github.com/jenkins-x/golang-jenkins"
-	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/activities"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 	"github.com/pkg/errors"
 	prowjobv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
 
-	jv1 "github.com/jenkins-x/jx-api/pkg/client/clientset/versioned/typed/jenkins.io/v1"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/spf13/cobra"
 	tektonv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
@@ -36,13 +33,39 @@ type GCActivitiesOptions struct {
 	PullRequestAgeLimit     time.Duration
 	PipelineRunAgeLimit     time.Duration
 	ProwJobAgeLimit         time.Duration
+	RetentionRules          string
+	BatchSize               int
+	BatchInterval           time.Duration
 	jclient                 gojenkins.JenkinsClient
 }
 
+// reasonNoJob the PipelineActivity is deleted as its Jenkins job no longer exists
+const reasonNoJob = "no-job"
+
 var (
 	GCActivitiesLong = templates.LongDesc(`
 		Garbage collect the Jenkins X PipelineActivity and PipelineRun resources
 
+		The number and age of the completed PipelineActivities kept for each release branch and pull request can be
+		overridden for repositories and branches with a YAML file of retention rules such as:
+
+		    default:
+		      keepReleases: 5
+		      keepPullRequests: 2
+		      releaseMaxAge: 720h
+		      pullRequestMaxAge: 48h
+		    rules:
+		    - repository: myorg/myapp
+		      branch: release-*
+		      keepReleases: 20
+		    - repository: myorg/*
+		      keepPullRequests: 1
+
+		The first rule whose repository and branch patterns match is used. The settings missing from a rule are taken
+		from the default policy which defaults to the flags.
+
+		The activities are deleted in batches of '--batch-size' pausing '--batch-interval' between the batches to avoid
+		the throttling of the API server.
 `)
 
 	GCActivitiesExample = templates.Examples(`
@@ -54,33 +77,6 @@ var (
 `)
 )
 
-type buildCounter struct {
-	ReleaseCount int
-	PRCount      int
-}
-
-type buildsCount struct {
-	cache map[string]*buildCounter
-}
-
-// AddBuild adds the build and returns the number of builds for this repo and branch
-func (c *buildsCount) AddBuild(repoAndBranch string, isPR bool) int {
-	if c.cache == nil {
-		c.cache = map[string]*buildCounter{}
-	}
-	bc := c.cache[repoAndBranch]
-	if bc == nil {
-		bc = &buildCounter{}
-		c.cache[repoAndBranch] = bc
-	}
-	if isPR {
-		bc.PRCount++
-		return bc.PRCount
-	}
-	bc.ReleaseCount++
-	return bc.ReleaseCount
-}
-
 // NewCmd s a command object for the "step" command
 func NewCmdGCActivities(commonOpts *opts.CommonOptions) *cobra.Command {
 	options := &GCActivitiesOptions{
@@ -107,6 +103,9 @@ func NewCmdGCActivities(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().DurationVarP(&options.ReleaseAgeLimit, "release-age", "r", time.Hour*24*30, "Maximum age to keep PipelineActivities for Releases")
 	cmd.Flags().DurationVarP(&options.PipelineRunAgeLimit, "pipelinerun-age", "", time.Hour*12, "Maximum age to keep completed PipelineRuns for all pipelines")
 	cmd.Flags().DurationVarP(&options.ProwJobAgeLimit, "prowjob-age", "", time.Hour*24*7, "Maximum age to keep completed ProwJobs for all pipelines")
+	cmd.Flags().StringVarP(&options.RetentionRules, "rules", "", "", "The YAML file of the retention rules of the PipelineActivities of repositories and branches")
+	cmd.Flags().IntVarP(&options.BatchSize, "batch-size", "", 50, "The number of PipelineActivities deleted before pausing")
+	cmd.Flags().DurationVarP(&options.BatchInterval, "batch-interval", "", time.Second, "The pause between the batches of deleted PipelineActivities")
 	return cmd
 }
 
@@ -124,11 +123,11 @@ func (o *GCActivitiesOptions) Run() error {
 
 	// cannot use field selectors like `spec.kind=Preview` on CRDs so list all environments
 	activityInterface := client.JenkinsV1().PipelineActivities(currentNs)
-	activities, err := activityInterface.List(metav1.ListOptions{})
+	activityList, err := activityInterface.List(metav1.ListOptions{})
 	if err != nil {
 		return err
 	}
-	if len(activities.Items) == 0 {
+	if len(activityList.Items) == 0 {
 		// no preview environments found so lets return gracefully
 		log.Logger().Debug("no activities found")
 		return nil
@@ -153,65 +152,38 @@ func (o *GCActivitiesOptions) Run() error {
 		}
 	}
 
-	now := time.Now()
-	counters := &buildsCount{}
-
-	var completedActivities []v1.PipelineActivity
-
-	// Filter out running activities
-	for _, a := range activities.Items {
-		if a.Spec.CompletedTimestamp != nil {
-			completedActivities = append(completedActivities, a)
-		}
+	rules, err := activities.LoadRetentionRules(o.RetentionRules, o.retentionPolicy())
+	if err != nil {
+		return err
+	}
+	pruned, err := rules.Prune(activityList.Items, time.Now())
+	if err != nil {
+		return err
 	}
 
-	// Sort with newest created activities first
-	sort.Slice(completedActivities, func(i, j int) bool {
-		return !completedActivities[i].Spec.CompletedTimestamp.Before(completedActivities[j].Spec.CompletedTimestamp)
-	})
-
-	//
-	for _, a := range completedActivities {
-		activity := a
-		branchName := a.BranchName()
-		isPR, isBatch := o.isPullRequestOrBatchBranch(branchName)
-		maxAge, revisionHistory := o.ageAndHistoryLimits(isPR, isBatch)
-		// lets remove activities that are too old
-		if activity.Spec.CompletedTimestamp != nil && activity.Spec.CompletedTimestamp.Add(maxAge).Before(now) {
-
-			err = o.deleteActivity(activityInterface, &activity)
-			if err != nil {
-				return err
-			}
-			continue
+	if !prowEnabled {
+		// if activity has no job in Jenkins delete it
+		prunedNames := map[string]bool{}
+		for _, p := range pruned {
+			prunedNames[p.Activity.Name] = true
 		}
-
-		repoBranchAndContext := activity.RepositoryOwner() + "/" + activity.RepositoryName() + "/" + activity.BranchName() + "/" + activity.Spec.Context
-		c := counters.AddBuild(repoBranchAndContext, isPR)
-		if c > revisionHistory && a.Spec.CompletedTimestamp != nil {
-			err = o.deleteActivity(activityInterface, &activity)
-			if err != nil {
-				return err
+		for _, a := range activityList.Items {
+			if a.Spec.CompletedTimestamp == nil || prunedNames[a.Name] || util.StringArrayIndex(jobNames, a.Spec.Pipeline) >= 0 {
+				continue
 			}
-			continue
+			pruned = append(pruned, activities.PrunedActivity{Activity: a, Reason: reasonNoJob})
 		}
+	}
 
-		if !prowEnabled {
-			// if activity has no job in Jenkins delete it
-			matched := false
-			for _, j := range jobNames {
-				if a.Spec.Pipeline == j {
-					matched = true
-					break
-				}
-			}
-			if !matched {
-				err = o.deleteActivity(activityInterface, &activity)
-				if err != nil {
-					return err
-				}
-			}
-		}
+	pruner := &activities.Pruner{
+		Activities:    activityInterface,
+		BatchSize:     o.BatchSize,
+		BatchInterval: o.BatchInterval,
+		DryRun:        o.DryRun,
+	}
+	_, err = pruner.Delete(pruned)
+	if err != nil {
+		return err
 	}
 
 	// Clean up completed PipelineRuns
@@ -229,18 +201,6 @@ func (o *GCActivitiesOptions) Run() error {
 	return nil
 }
 
-func (o *GCActivitiesOptions) deleteActivity(activityInterface jv1.PipelineActivityInterface, a *v1.PipelineActivity) error {
-	prefix := ""
-	if o.DryRun {
-		prefix = "not "
-	}
-	log.Logger().Infof("%sdeleting PipelineActivity %s", prefix, util.ColorInfo(a.Name))
-	if o.DryRun {
-		return nil
-	}
-	return activityInterface.Delete(a.Name, metav1.NewDeleteOptions(0))
-}
-
 func (o *GCActivitiesOptions) gcPipelineRuns(ns string) error {
 	tektonClient, _, err := o.TektonClient()
 	if err != nil {
@@ -320,16 +280,12 @@ func (o *GCActivitiesOptions) deleteProwJob(pjInterface prowjobclient.ProwJobInt
 	return pjInterface.Delete(pj.Name, metav1.NewDeleteOptions(0))
 }
 
-func (o *GCActivitiesOptions) ageAndHistoryLimits(isPR, isBatch bool) (time.Duration, int) {
-	maxAge := o.ReleaseAgeLimit
-	revisionLimit := o.ReleaseHistoryLimit
-	if isPR || isBatch {
-		maxAge = o.PullRequestAgeLimit
-		revisionLimit = o.PullRequestHistoryLimit
+// retentionPolicy returns the default retention policy of the PipelineActivities from the flags
+func (o *GCActivitiesOptions) retentionPolicy() activities.RetentionPolicy {
+	return activities.RetentionPolicy{
+		KeepReleases:      o.ReleaseHistoryLimit,
+		KeepPullRequests:  o.PullRequestHistoryLimit,
+		ReleaseMaxAge:     o.ReleaseAgeLimit.String(),
+		PullRequestMaxAge: o.PullRequestAgeLimit.String(),
 	}
-	return maxAge, revisionLimit
-}
-
-func (o *GCActivitiesOptions) isPullRequestOrBatchBranch(branchName string) (bool, bool) {
-	return strings.HasPrefix(branchName, "PR-"), branchName == "batch"
 }
@@ -0,0 +1,104 @@
+package gc
+
+import (
+	"time"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/artifacts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/errorutil"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// GCArtifactsOptions contains the CLI options
+type GCArtifactsOptions struct {
+	*opts.CommonOptions
+
+	BucketURLs []string
+	Prefix     string
+	DryRun     bool
+	Timeout    time.Duration
+}
+
+var (
+	GCArtifactsLong = templates.LongDesc(`
+		Garbage collect the files stashed in cloud storage via 'jx step stash --retention' whose retention has expired
+
+		The storage locations of the team are checked unless bucket URLs are given.
+`)
+
+	GCArtifactsExample = templates.Examples(`
+		# garbage collect the expired files in the storage locations of the team
+		jx gc artifacts
+
+		# shows the expired files in a bucket without deleting them
+		jx gc artifacts --bucket-url gs://my-gcp-bucket --dry-run
+`)
+)
+
+// NewCmdGCArtifacts creates the command object
+func NewCmdGCArtifacts(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GCArtifactsOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "artifacts",
+		Short:   "garbage collection for the expired files stashed in cloud storage",
+		Aliases: []string{"artifact"},
+		Long:    GCArtifactsLong,
+		Example: GCArtifactsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringArrayVarP(&options.BucketURLs, "bucket-url", "", nil, "The cloud storage bucket URLs to garbage collect. Defaults to the bucket URLs of the storage locations of the team")
+	cmd.Flags().StringVarP(&options.Prefix, "prefix", "", "jenkins-x/", "The path in the buckets the files are stashed in")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "d", false, "Only shows the expired files rather than deleting them")
+	cmd.Flags().DurationVarP(&options.Timeout, "timeout", "t", 5*time.Minute, "The timeout of garbage collecting each bucket")
+	return cmd
+}
+
+// Run implements this command
+func (o *GCArtifactsOptions) Run() error {
+	bucketURLs := o.BucketURLs
+	if len(bucketURLs) == 0 {
+		settings, err := o.TeamSettings()
+		if err != nil {
+			return err
+		}
+		for _, location := range settings.StorageLocations {
+			if location.BucketURL != "" && util.StringArrayIndex(bucketURLs, location.BucketURL) < 0 {
+				bucketURLs = append(bucketURLs, location.BucketURL)
+			}
+		}
+		if len(bucketURLs) == 0 {
+			log.Logger().Infof("no storage locations of the team use cloud storage")
+			return nil
+		}
+	}
+
+	now := time.Now()
+	errs := []error{}
+	for _, bucketURL := range bucketURLs {
+		dirs, err := artifacts.DeleteExpired(bucketURL, o.Prefix, now, o.DryRun, o.Timeout)
+		for _, dir := range dirs {
+			if o.DryRun {
+				log.Logger().Infof("would delete the expired files in %s of bucket %s", util.ColorInfo(dir), bucketURL)
+			} else {
+				log.Logger().Infof("deleted the expired files in %s of bucket %s", util.ColorInfo(dir), bucketURL)
+			}
+		}
+		if err != nil {
+			log.Logger().Warnf("Failed to garbage collect bucket %s: %s", bucketURL, err)
+			errs = append(errs, err)
+		}
+	}
+	return errorutil.CombineErrors(errs...)
+}
@@ -0,0 +1,94 @@
+package gc
+
+import (
+	"time"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// GCGitCacheOptions contains the CLI options
+type GCGitCacheOptions struct {
+	*opts.CommonOptions
+
+	MaxAge time.Duration
+	DryRun bool
+}
+
+var (
+	GCGitCacheLong = templates.LongDesc(`
+		Garbage collect the cached clones of the version stream, build pack and environment git repositories which have not been used recently
+
+		The cache is only used when the JX_GIT_CACHE_ENABLED environment variable is set to true. It is kept in ~/.jx/cache/git unless the JX_GIT_CACHE_DIR environment variable is set.
+`)
+
+	GCGitCacheExample = templates.Examples(`
+		# removes the cached clones which have not been used for 30 days
+		jx gc gitcache
+
+		# shows the cached clones which have not been used for a week without removing them
+		jx gc gitcache --max-age 168h --dry-run
+
+		# removes all the cached clones
+		jx gc gitcache --max-age 0
+`)
+)
+
+// NewCmdGCGitCache creates the command object
+func NewCmdGCGitCache(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GCGitCacheOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "gitcache",
+		Short:   "garbage collection for the cached clones of git repositories",
+		Aliases: []string{"git-cache"},
+		Long:    GCGitCacheLong,
+		Example: GCGitCacheExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().DurationVarP(&options.MaxAge, "max-age", "", 30*24*time.Hour, "Removes the cached clones which have not been used for longer than this duration")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "d", false, "Only shows the cached clones rather than removing them")
+	return cmd
+}
+
+// Run implements this command
+func (o *GCGitCacheOptions) Run() error {
+	dir, err := gits.CloneCacheDir()
+	if err != nil {
+		return err
+	}
+	cache := &gits.CloneCache{Dir: dir}
+	entries, err := cache.GC(o.MaxAge, o.DryRun)
+	for _, entry := range entries {
+		name := entry.URL
+		if name == "" {
+			name = entry.Dir
+		} else if entry.Ref != "" {
+			name += " ref " + entry.Ref
+		}
+		if o.DryRun {
+			log.Logger().Infof("would remove the cached clone of %s last used %s", util.ColorInfo(name), entry.LastUsed.Format(time.RFC3339))
+		} else {
+			log.Logger().Infof("removed the cached clone of %s last used %s", util.ColorInfo(name), entry.LastUsed.Format(time.RFC3339))
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		log.Logger().Infof("no cached clones in %s have expired", util.ColorInfo(dir))
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package gc
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/errorutil"
+	"github.com/jenkins-x/jx/v2/pkg/registries"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// GCImagesOptions contains the CLI options
+type GCImagesOptions struct {
+	*opts.CommonOptions
+	opts.RegistryFlags
+
+	Repositories []string
+	Prefix       string
+	Policies     string
+	KeepLast     int
+	KeepReleases bool
+	OlderThan    string
+	DryRun       bool
+
+	Results []*registries.RetentionResult
+}
+
+var (
+	GCImagesLong = templates.LongDesc(`
+		Garbage collect the stale images of the container registry according to retention policies
+
+		The most recent '--keep-last' images of each repository are kept along with the images tagged with a release
+		version if '--keep-releases' is enabled. The other images such as the snapshot images of the preview environments
+		of pull requests are deleted if they are older than '--older-than'.
+
+		The policies of the applications can be overridden with a YAML file of retention policies such as:
+
+		    default:
+		      keepLast: 10
+		      keepReleases: true
+		    repositories:
+		    - repository: myorg/myapp
+		      keepLast: 3
+		      olderThan: 168h
+		    - repository: legacy-*
+		      keepLast: -1
+
+		The first policy whose repository pattern matches the repository or the application name is used.
+
+		The repositories of the docker registry organisation of the team are checked unless repositories are given.
+		A report of the deleted images and the reclaimed storage is printed.
+`)
+
+	GCImagesExample = templates.Examples(`
+		# garbage collect the snapshot images of the applications of the team keeping the last 10 and the releases
+		jx gc images --keep-last 10 --keep-releases
+
+		# shows the images of a repository in Harbor which would be deleted without deleting them
+		jx gc images --kind harbor --host harbor.example.com --repository myproject/myapp --dry-run
+
+		# garbage collect the images using the retention policies of the applications
+		jx gc images --policies retention.yaml
+`)
+)
+
+// NewCmdGCImages creates the command object
+func NewCmdGCImages(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GCImagesOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "images",
+		Short:   "garbage collection for the stale images of the container registry",
+		Aliases: []string{"image"},
+		Long:    GCImagesLong,
+		Example: GCImagesExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	options.AddRegistryFlags(cmd)
+	cmd.Flags().StringArrayVarP(&options.Repositories, "repository", "r", nil, "The repositories to garbage collect. Defaults to the repositories starting with the prefix")
+	cmd.Flags().StringVarP(&options.Prefix, "prefix", "", "", "The prefix of the repositories to garbage collect. Defaults to the docker registry organisation of the team")
+	cmd.Flags().StringVarP(&options.Policies, "policies", "", "", "The YAML file of the retention policies of the repositories")
+	cmd.Flags().IntVarP(&options.KeepLast, "keep-last", "", 10, "The number of the most recent images of each repository which are kept. All images are kept if negative")
+	cmd.Flags().BoolVarP(&options.KeepReleases, "keep-releases", "", true, "Keeps the images tagged with a release version")
+	cmd.Flags().StringVarP(&options.OlderThan, "older-than", "", "", "Only deletes the images older than the duration such as 720h")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "d", false, "Only reports the images which would be deleted rather than deleting them")
+	return cmd
+}
+
+// Run implements this command
+func (o *GCImagesOptions) Run() error {
+	policies, err := registries.LoadRetentionPolicies(o.Policies, registries.RetentionPolicy{
+		KeepLast:     o.KeepLast,
+		KeepReleases: o.KeepReleases,
+		OlderThan:    o.OlderThan,
+	})
+	if err != nil {
+		return err
+	}
+	registry, err := o.NewRegistry(&o.RegistryFlags)
+	if err != nil {
+		return err
+	}
+	repositories := o.Repositories
+	if len(repositories) == 0 {
+		prefix := o.Prefix
+		if prefix == "" {
+			prefix = o.GetDockerRegistryOrg(nil, nil)
+		}
+		repositories, err = registry.ListRepositories(prefix)
+		if err != nil {
+			return err
+		}
+		if len(repositories) == 0 {
+			log.Logger().Infof("no repositories of %s start with %s", registry.Host(), prefix)
+			return nil
+		}
+	}
+
+	err = o.collect(registry, policies, repositories, time.Now())
+	o.printReclaimed()
+	return err
+}
+
+// collect deletes the images of the repositories which are not kept by their retention policies
+func (o *GCImagesOptions) collect(registry registries.Registry, policies *registries.RetentionPolicies, repositories []string, now time.Time) error {
+	errs := []error{}
+	for _, repository := range repositories {
+		images, err := registry.ListImages(repository)
+		if err != nil {
+			log.Logger().Warnf("Failed to list the images of %s: %s", repository, err)
+			errs = append(errs, err)
+			continue
+		}
+		result, err := policies.PolicyFor(repository).Apply(repository, images, now)
+		if err != nil {
+			return err
+		}
+		if o.DryRun {
+			for _, image := range result.Deleted {
+				log.Logger().Infof("would delete the image %s tagged %s", util.ColorInfo(repository+"@"+image.Digest), strings.Join(image.Tags, ", "))
+			}
+			o.Results = append(o.Results, result)
+			continue
+		}
+		deleted := []registries.Image{}
+		for _, image := range result.Deleted {
+			name := repository + "@" + image.Digest
+			err = registry.DeleteImage(image)
+			if err != nil {
+				log.Logger().Warnf("Failed to delete the image %s: %s", name, err)
+				errs = append(errs, err)
+				result.Kept = append(result.Kept, image)
+				continue
+			}
+			log.Logger().Infof("deleted the image %s tagged %s", util.ColorInfo(name), strings.Join(image.Tags, ", "))
+			deleted = append(deleted, image)
+		}
+		result.Deleted = deleted
+		o.Results = append(o.Results, result)
+	}
+	return errorutil.CombineErrors(errs...)
+}
+
+// printReclaimed reports the images deleted from each repository and the storage reclaimed
+func (o *GCImagesOptions) printReclaimed() {
+	if len(o.Results) == 0 {
+		return
+	}
+	deleted := 0
+	var size int64
+	table := o.CreateTable()
+	table.AddRow("REPOSITORY", "DELETED", "KEPT", "RECLAIMED")
+	for _, r := range o.Results {
+		table.AddRow(r.Repository, strconv.Itoa(len(r.Deleted)), strconv.Itoa(len(r.Kept)), registries.FormatSize(r.ReclaimedSize()))
+		deleted += len(r.Deleted)
+		size += r.ReclaimedSize()
+	}
+	table.Render()
+
+	verb := "Reclaimed"
+	if o.DryRun {
+		verb = "Would reclaim"
+	}
+	log.Logger().Infof("%s %s from %d images of %d repositories", verb, util.ColorInfo(registries.FormatSize(size)), deleted, len(o.Results))
+}
@@ -0,0 +1,71 @@
+// +build unit
+
+package gc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/registries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRegistry a registry whose images are kept in memory
+type fakeRegistry struct {
+	registries.Registry
+	images  map[string][]registries.Image
+	deleted []string
+}
+
+func (r *fakeRegistry) ListImages(repository string) ([]registries.Image, error) {
+	return r.images[repository], nil
+}
+
+func (r *fakeRegistry) DeleteImage(image registries.Image) error {
+	r.deleted = append(r.deleted, image.Repository+"@"+image.Digest)
+	return nil
+}
+
+func TestGCImagesCollect(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	image := func(repository string, digest string, days int, tags ...string) registries.Image {
+		return registries.Image{Repository: repository, Digest: digest, Tags: tags, Created: now.Add(-time.Duration(days) * 24 * time.Hour), Size: 1024}
+	}
+	registry := &fakeRegistry{
+		images: map[string][]registries.Image{
+			"myorg/myapp": {
+				image("myorg/myapp", "sha256:a", 3, "0.0.0-SNAPSHOT-PR-1-1"),
+				image("myorg/myapp", "sha256:b", 2, "1.0.0"),
+				image("myorg/myapp", "sha256:c", 1, "0.0.0-SNAPSHOT-PR-2-1"),
+			},
+			"myorg/other": {
+				image("myorg/other", "sha256:d", 3, "0.0.0-SNAPSHOT-PR-1-1"),
+				image("myorg/other", "sha256:e", 1, "0.0.0-SNAPSHOT-PR-2-1"),
+			},
+		},
+	}
+	policies := &registries.RetentionPolicies{
+		Default: registries.RetentionPolicy{KeepLast: 1, KeepReleases: true},
+		Repositories: []registries.RepositoryRetentionPolicy{
+			{Repository: "other", RetentionPolicy: registries.RetentionPolicy{KeepLast: -1}},
+		},
+	}
+
+	o := &GCImagesOptions{CommonOptions: &opts.CommonOptions{}, DryRun: true}
+	err := o.collect(registry, policies, []string{"myorg/myapp", "myorg/other"}, now)
+	require.NoError(t, err)
+	assert.Empty(t, registry.deleted)
+	require.Len(t, o.Results, 2)
+	assert.Len(t, o.Results[0].Deleted, 1)
+	assert.Empty(t, o.Results[1].Deleted)
+
+	o = &GCImagesOptions{CommonOptions: &opts.CommonOptions{}}
+	err = o.collect(registry, policies, []string{"myorg/myapp", "myorg/other"}, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"myorg/myapp@sha256:a"}, registry.deleted)
+	assert.Equal(t, int64(1024), o.Results[0].ReclaimedSize())
+}
@@ -19,9 +19,10 @@ import (
 type GCPodsOptions struct {
 	*opts.CommonOptions
 
-	Selector  string
-	Namespace string
-	Age       time.Duration
+	Selector          string
+	Namespace         string
+	Age               time.Duration
+	OverrideProtected bool
 }
 
 var (
@@ -61,6 +62,7 @@ func NewCmdGCPods(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.Selector, "selector", "s", "", "The selector to use to filter the pods")
 	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "The namespace to look for the pods. Defaults to the current namespace")
 	cmd.Flags().DurationVarP(&options.Age, "age", "a", time.Hour, "The minimum age of pods to garbage collect. Any newer pods will be kept")
+	opts.AddProtectedNamespaceFlag(cmd, &options.OverrideProtected)
 	return cmd
 }
 
@@ -75,6 +77,11 @@ func (o *GCPodsOptions) Run() error {
 		ns = o.Namespace
 	}
 
+	err = o.VerifyProtectedNamespaces("garbage collect pods", o.OverrideProtected, ns)
+	if err != nil {
+		return err
+	}
+
 	opts := metav1.ListOptions{
 		LabelSelector: o.Selector,
 	}
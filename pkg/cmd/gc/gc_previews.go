@@ -2,6 +2,7 @@ package gc
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/deletecmd"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/preview"
@@ -21,6 +22,24 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/tekton"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// GCPreviewsCronJobName the name of the CronJob garbage collecting the preview environments
+	GCPreviewsCronJobName = "jx-gc-previews"
+
+	defaultGCPreviewsSchedule = "0 */6 * * *"
+	defaultGCPreviewsImage    = "gcr.io/jenkinsxio/builder-jx"
 )
 
 // GetOptions is the start of the data required to perform the operation.  As new fields are added, add them here instead of
@@ -28,8 +47,29 @@ import (
 type GCPreviewsOptions struct {
 	*opts.CommonOptions
 
-	DisableImport bool
-	OutDir        string
+	DisableImport  bool
+	OutDir         string
+	TTL            time.Duration
+	DryRun         bool
+	InstallCronJob bool
+	Schedule       string
+	Image          string
+	ServiceAccount string
+
+	// Reclaimed the preview environments deleted by the last run
+	Reclaimed []ReclaimedPreview
+}
+
+// ReclaimedPreview a preview environment deleted by the garbage collection and the resources of its namespace
+type ReclaimedPreview struct {
+	Name                   string
+	Namespace              string
+	Reason                 string
+	Pods                   int
+	PersistentVolumeClaims int
+	CPU                    resource.Quantity
+	Memory                 resource.Quantity
+	Storage                resource.Quantity
 }
 
 var (
@@ -37,11 +77,27 @@ var (
 		Garbage collect Jenkins X preview environments.  If a pull request is merged or closed the associated preview
 		environment will be deleted.
 
+		Preview environments which have not been deployed for longer than their TTL are also deleted. The TTL of a
+		preview environment is set with 'jx preview --ttl' in the pipeline of a repository and defaults to the --ttl
+		of this command. The pods, CPU, memory and storage reclaimed from the namespaces of the deleted preview
+		environments are reported.
+
+		Use --install-cronjob to install a CronJob in the dev namespace which periodically runs this command.
+
 `)
 
 	GCPreviewsExample = templates.Examples(`
 		jx garbage collect previews
 		jx gc previews
+
+		# also delete the preview environments which have not been deployed for 3 days
+		jx gc previews --ttl 72h
+
+		# list the preview environments which would be deleted
+		jx gc previews --ttl 72h --dry-run
+
+		# install a CronJob deleting the abandoned preview environments every 6 hours
+		jx gc previews --ttl 72h --install-cronjob
 `)
 )
 
@@ -63,11 +119,20 @@ func NewCmdGCPreviews(commonOpts *opts.CommonOptions) *cobra.Command {
 			helper.CheckErr(err)
 		},
 	}
+	cmd.Flags().DurationVarP(&options.TTL, "ttl", "", 0, "The duration preview environments are kept after they were last deployed, such as 72h. Preview environments without a TTL annotation never expire if not specified")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "Only reports the preview environments which would be deleted")
+	cmd.Flags().BoolVarP(&options.InstallCronJob, "install-cronjob", "", false, "Installs a CronJob in the dev namespace which periodically garbage collects the preview environments with the given TTL")
+	cmd.Flags().StringVarP(&options.Schedule, "schedule", "", defaultGCPreviewsSchedule, "The cron schedule of the installed CronJob")
+	cmd.Flags().StringVarP(&options.Image, "image", "", defaultGCPreviewsImage, "The container image containing jx used by the installed CronJob")
+	cmd.Flags().StringVarP(&options.ServiceAccount, "service-account", "", tekton.DefaultPipelineSA, "The service account used by the installed CronJob")
 	return cmd
 }
 
 // Run implements this command
 func (o *GCPreviewsOptions) Run() error {
+	if o.InstallCronJob {
+		return o.installCronJob()
+	}
 	client, currentNs, err := o.JXClientAndDevNamespace()
 	if err != nil {
 		return err
@@ -84,63 +149,247 @@ func (o *GCPreviewsOptions) Run() error {
 		return nil
 	}
 
+	o.Reclaimed = nil
+	now := time.Now()
 	var previewFound bool
-	for _, e := range envs.Items {
-		if e.Spec.Kind == v1.EnvironmentKindTypePreview {
-			previewFound = true
-			gitInfo, err := gits.ParseGitURL(e.Spec.Source.URL)
-			if err != nil {
-				return err
+	for _, env := range envs.Items {
+		e := env
+		if e.Spec.Kind != v1.EnvironmentKindTypePreview {
+			continue
+		}
+		previewFound = true
+		reason, err := o.deleteReason(&e, now)
+		if err != nil {
+			return err
+		}
+		if reason == "" {
+			continue
+		}
+		reclaimed, err := o.previewResources(&e)
+		if err != nil {
+			return err
+		}
+		reclaimed.Reason = reason
+		if o.DryRun {
+			log.Logger().Infof("Would delete preview environment %s as %s", util.ColorInfo(e.Name), reason)
+		} else {
+			// lets delete the preview environment
+			deleteOpts := deletecmd.DeletePreviewOptions{
+				PreviewOptions: preview.PreviewOptions{
+					PromoteOptions: promote.PromoteOptions{
+						CommonOptions: o.CommonOptions,
+					},
+				},
 			}
-			// we need pull request info to include
-			authConfigSvc, err := o.GitAuthConfigService()
+			err = deleteOpts.DeletePreview(e.Name)
 			if err != nil {
-				return err
+				return fmt.Errorf("failed to delete preview environment %s: %v\n", e.Name, err)
 			}
+		}
+		o.Reclaimed = append(o.Reclaimed, *reclaimed)
+	}
+	if !previewFound {
+		log.Logger().Debug("no preview environments found")
+	}
+	o.printReclaimed()
+	return nil
+}
 
-			gitKind, err := o.GitServerKind(gitInfo)
-			if err != nil {
-				return err
-			}
+// deleteReason returns why the preview environment should be deleted or an empty string if it should be kept
+func (o *GCPreviewsOptions) deleteReason(e *v1.Environment, now time.Time) (string, error) {
+	expired, err := kube.IsPreviewExpired(e, o.TTL, now)
+	if err != nil {
+		log.Logger().Warnf("Can not check the TTL of preview environment %s: %s", e.Name, err)
+	}
+	if expired {
+		return fmt.Sprintf("it has not been deployed since %s", kube.PreviewLastDeployed(e).Format(time.RFC3339)), nil
+	}
 
-			ghOwner, err := o.GetGitHubAppOwner(gitInfo)
-			if err != nil {
-				return err
-			}
-			gitProvider, err := gitInfo.CreateProvider(o.InCluster(), authConfigSvc, gitKind, ghOwner, o.Git(), o.BatchMode, o.GetIOFileHandles())
-			if err != nil {
-				return err
-			}
-			prNum, err := strconv.Atoi(e.Spec.PreviewGitSpec.Name)
-			if err != nil {
-				log.Logger().Warn("Unable to convert PR " + e.Spec.PreviewGitSpec.Name + " to a number")
-			}
-			pullRequest, err := gitProvider.GetPullRequest(gitInfo.Organisation, gitInfo, prNum)
-			if err != nil {
-				log.Logger().Warnf("Can not get pull request %s, skipping: %s", e.Spec.PreviewGitSpec.Name, err)
-				continue
-			}
+	gitInfo, err := gits.ParseGitURL(e.Spec.Source.URL)
+	if err != nil {
+		return "", err
+	}
+	// we need pull request info to include
+	authConfigSvc, err := o.GitAuthConfigService()
+	if err != nil {
+		return "", err
+	}
+
+	gitKind, err := o.GitServerKind(gitInfo)
+	if err != nil {
+		return "", err
+	}
+
+	ghOwner, err := o.GetGitHubAppOwner(gitInfo)
+	if err != nil {
+		return "", err
+	}
+	gitProvider, err := gitInfo.CreateProvider(o.InCluster(), authConfigSvc, gitKind, ghOwner, o.Git(), o.BatchMode, o.GetIOFileHandles())
+	if err != nil {
+		return "", err
+	}
+	prNum, err := strconv.Atoi(e.Spec.PreviewGitSpec.Name)
+	if err != nil {
+		log.Logger().Warn("Unable to convert PR " + e.Spec.PreviewGitSpec.Name + " to a number")
+	}
+	pullRequest, err := gitProvider.GetPullRequest(gitInfo.Organisation, gitInfo, prNum)
+	if err != nil {
+		log.Logger().Warnf("Can not get pull request %s, skipping: %s", e.Spec.PreviewGitSpec.Name, err)
+		return "", nil
+	}
+
+	lowerState := strings.ToLower(*pullRequest.State)
+
+	if strings.HasPrefix(lowerState, "clos") || strings.HasPrefix(lowerState, "merged") || strings.HasPrefix(lowerState, "superseded") || strings.HasPrefix(lowerState, "declined") {
+		return "its pull request is " + lowerState, nil
+	}
+	return "", nil
+}
+
+// previewResources returns the resources of the namespace of the preview environment
+func (o *GCPreviewsOptions) previewResources(e *v1.Environment) (*ReclaimedPreview, error) {
+	answer := &ReclaimedPreview{Name: e.Name, Namespace: e.Spec.Namespace}
+	if answer.Namespace == "" {
+		return answer, nil
+	}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "creating the kube client")
+	}
+	err = namespaceResources(kubeClient, answer)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting the resources of preview environment %s", e.Name)
+	}
+	return answer, nil
+}
+
+func namespaceResources(kubeClient kubernetes.Interface, answer *ReclaimedPreview) error {
+	pods, err := kubeClient.CoreV1().Pods(answer.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		answer.Pods++
+		for _, c := range pod.Spec.Containers {
+			answer.CPU.Add(c.Resources.Requests[corev1.ResourceCPU])
+			answer.Memory.Add(c.Resources.Requests[corev1.ResourceMemory])
+		}
+	}
+	pvcs, err := kubeClient.CoreV1().PersistentVolumeClaims(answer.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, pvc := range pvcs.Items {
+		answer.PersistentVolumeClaims++
+		answer.Storage.Add(pvc.Spec.Resources.Requests[corev1.ResourceStorage])
+	}
+	return nil
+}
 
-			lowerState := strings.ToLower(*pullRequest.State)
+// printReclaimed reports the deleted preview environments and the resources reclaimed from their namespaces
+func (o *GCPreviewsOptions) printReclaimed() {
+	if len(o.Reclaimed) == 0 {
+		log.Logger().Info("No preview environments to delete")
+		return
+	}
+	total := ReclaimedPreview{}
+	table := o.CreateTable()
+	table.AddRow("NAME", "NAMESPACE", "PODS", "CPU", "MEMORY", "PVCS", "STORAGE", "REASON")
+	for _, r := range o.Reclaimed {
+		table.AddRow(r.Name, r.Namespace, strconv.Itoa(r.Pods), r.CPU.String(), r.Memory.String(), strconv.Itoa(r.PersistentVolumeClaims), r.Storage.String(), r.Reason)
+		total.Pods += r.Pods
+		total.PersistentVolumeClaims += r.PersistentVolumeClaims
+		total.CPU.Add(r.CPU)
+		total.Memory.Add(r.Memory)
+		total.Storage.Add(r.Storage)
+	}
+	table.Render()
 
-			if strings.HasPrefix(lowerState, "clos") || strings.HasPrefix(lowerState, "merged") || strings.HasPrefix(lowerState, "superseded") || strings.HasPrefix(lowerState, "declined") {
-				// lets delete the preview environment
-				deleteOpts := deletecmd.DeletePreviewOptions{
-					PreviewOptions: preview.PreviewOptions{
-						PromoteOptions: promote.PromoteOptions{
-							CommonOptions: o.CommonOptions,
+	verb := "Reclaimed"
+	if o.DryRun {
+		verb = "Would reclaim"
+	}
+	log.Logger().Infof("%s %s preview environments with %s pods requesting %s CPU and %s memory and %s volumes of %s storage", verb,
+		util.ColorInfo(len(o.Reclaimed)), util.ColorInfo(total.Pods), util.ColorInfo(total.CPU.String()), util.ColorInfo(total.Memory.String()),
+		util.ColorInfo(total.PersistentVolumeClaims), util.ColorInfo(total.Storage.String()))
+}
+
+// installCronJob creates or updates the CronJob in the dev namespace which periodically runs this command
+func (o *GCPreviewsOptions) installCronJob() error {
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return errors.Wrap(err, "creating the kube client")
+	}
+	args := []string{"gc", "previews", "--batch-mode"}
+	if o.TTL > 0 {
+		args = append(args, "--ttl", o.TTL.String())
+	}
+	schedule := o.Schedule
+	if schedule == "" {
+		schedule = defaultGCPreviewsSchedule
+	}
+	image := o.Image
+	if image == "" {
+		image = defaultGCPreviewsImage
+	}
+	historyLimit := int32(3)
+	cronJob := &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GCPreviewsCronJobName,
+			Namespace: ns,
+			Labels:    map[string]string{"app": GCPreviewsCronJobName},
+		},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule:                   schedule,
+			ConcurrencyPolicy:          batchv1beta1.ForbidConcurrent,
+			SuccessfulJobsHistoryLimit: &historyLimit,
+			FailedJobsHistoryLimit:     &historyLimit,
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{"app": GCPreviewsCronJobName},
+						},
+						Spec: corev1.PodSpec{
+							ServiceAccountName: o.ServiceAccount,
+							RestartPolicy:      corev1.RestartPolicyNever,
+							Containers: []corev1.Container{
+								{
+									Name:    "gc-previews",
+									Image:   image,
+									Command: []string{"jx"},
+									Args:    args,
+								},
+							},
 						},
 					},
-				}
-				err = deleteOpts.DeletePreview(e.Name)
-				if err != nil {
-					return fmt.Errorf("failed to delete preview environment %s: %v\n", e.Name, err)
-				}
-			}
+				},
+			},
+		},
+	}
+
+	cronJobs := kubeClient.BatchV1beta1().CronJobs(ns)
+	existing, err := cronJobs.Get(GCPreviewsCronJobName, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "getting CronJob %s in namespace %s", GCPreviewsCronJobName, ns)
+		}
+		_, err = cronJobs.Create(cronJob)
+		if err != nil {
+			return errors.Wrapf(err, "creating CronJob %s in namespace %s", GCPreviewsCronJobName, ns)
 		}
+		log.Logger().Infof("Created CronJob %s garbage collecting the preview environments on schedule %s", util.ColorInfo(GCPreviewsCronJobName), util.ColorInfo(schedule))
+		return nil
 	}
-	if !previewFound {
-		log.Logger().Debug("no preview environments found")
+	existing.Labels = cronJob.Labels
+	existing.Spec = cronJob.Spec
+	_, err = cronJobs.Update(existing)
+	if err != nil {
+		return errors.Wrapf(err, "updating CronJob %s in namespace %s", GCPreviewsCronJobName, ns)
 	}
+	log.Logger().Infof("Updated CronJob %s garbage collecting the preview environments on schedule %s", util.ColorInfo(GCPreviewsCronJobName), util.ColorInfo(schedule))
 	return nil
 }
@@ -0,0 +1,136 @@
+// +build unit
+
+package gc
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	jxfake "github.com/jenkins-x/jx-api/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/clients/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGCPreviewsWithTTL(t *testing.T) {
+	t.Parallel()
+
+	ns := "jx"
+	preview := func(name string, lastDeployed time.Time, ttl string) *v1.Environment {
+		env := &v1.Environment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         ns,
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-30 * 24 * time.Hour)),
+				Annotations: map[string]string{
+					kube.AnnotationPreviewLastDeployed: lastDeployed.UTC().Format(time.RFC3339),
+				},
+			},
+			Spec: v1.EnvironmentSpec{
+				Kind:      v1.EnvironmentKindTypePreview,
+				Namespace: ns + "-" + name,
+			},
+		}
+		if ttl != "" {
+			env.Annotations[kube.AnnotationPreviewTTL] = ttl
+		}
+		return env
+	}
+	abandoned := preview("myorg-myapp-pr-1", time.Now().Add(-96*time.Hour), "")
+	recent := preview("myorg-myapp-pr-2", time.Now().Add(-time.Hour), "")
+	shortTTL := preview("myorg-other-pr-3", time.Now().Add(-2*time.Hour), "1h")
+
+	commonOpts := opts.NewCommonOptionsWithFactory(fake.NewFakeFactory())
+	commonOpts.SetDevNamespace(ns)
+	commonOpts.SetJxClient(jxfake.NewSimpleClientset(abandoned, recent, shortTTL))
+	commonOpts.SetKubeClient(kubefake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: abandoned.Spec.Namespace},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: "myapp",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("250m"),
+								corev1.ResourceMemory: resource.MustParse("256Mi"),
+							},
+						},
+					},
+				},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: abandoned.Spec.Namespace},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+				},
+			},
+		},
+	))
+
+	o := &GCPreviewsOptions{
+		CommonOptions: &commonOpts,
+		TTL:           72 * time.Hour,
+		DryRun:        true,
+	}
+	// the recent preview environment is kept unless its pull request was closed
+	expired, err := kube.IsPreviewExpired(recent, o.TTL, time.Now())
+	require.NoError(t, err)
+	assert.False(t, expired)
+
+	reason, err := o.deleteReason(abandoned, time.Now())
+	require.NoError(t, err)
+	assert.Contains(t, reason, "it has not been deployed since")
+	reason, err = o.deleteReason(shortTTL, time.Now())
+	require.NoError(t, err)
+	assert.NotEmpty(t, reason, "the TTL annotation should override the default TTL")
+
+	reclaimed, err := o.previewResources(abandoned)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reclaimed.Pods)
+	assert.Equal(t, "250m", reclaimed.CPU.String())
+	assert.Equal(t, "256Mi", reclaimed.Memory.String())
+	assert.Equal(t, 1, reclaimed.PersistentVolumeClaims)
+	assert.Equal(t, "1Gi", reclaimed.Storage.String())
+}
+
+func TestGCPreviewsInstallCronJob(t *testing.T) {
+	t.Parallel()
+
+	ns := "jx"
+	commonOpts := opts.NewCommonOptionsWithFactory(fake.NewFakeFactory())
+	commonOpts.SetDevNamespace(ns)
+	kubeClient := kubefake.NewSimpleClientset()
+	commonOpts.SetKubeClient(kubeClient)
+
+	o := &GCPreviewsOptions{
+		CommonOptions:  &commonOpts,
+		TTL:            72 * time.Hour,
+		InstallCronJob: true,
+		ServiceAccount: "tekton-bot",
+	}
+	for i := 0; i < 2; i++ {
+		err := o.Run()
+		require.NoError(t, err)
+		o.Schedule = "0 * * * *"
+	}
+
+	cronJob, err := kubeClient.BatchV1beta1().CronJobs(ns).Get(GCPreviewsCronJobName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "0 * * * *", cronJob.Spec.Schedule)
+	pod := cronJob.Spec.JobTemplate.Spec.Template.Spec
+	assert.Equal(t, "tekton-bot", pod.ServiceAccountName)
+	require.Len(t, pod.Containers, 1)
+	assert.Equal(t, defaultGCPreviewsImage, pod.Containers[0].Image)
+	assert.Equal(t, []string{"gc", "previews", "--batch-mode", "--ttl", "72h0m0s"}, pod.Containers[0].Args)
+}
@@ -1,9 +1,6 @@
 package get
 
 import (
-	"encoding/json"
-	"fmt"
-
 	"github.com/jenkins-x/jx/v2/pkg/cmd/get/vault"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/get/vault/config"
 
@@ -11,7 +8,6 @@ import (
 
 	"github.com/spf13/cobra"
 
-	"github.com/ghodss/yaml"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
 	"github.com/jenkins-x/jx/v2/pkg/util"
@@ -76,12 +72,15 @@ func NewCmdGet(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(NewCmdGetAddon(commonOpts))
 	cmd.AddCommand(NewCmdGetApps(commonOpts))
 	cmd.AddCommand(NewCmdGetApplications(commonOpts))
+	cmd.AddCommand(NewCmdGetArtifacts(commonOpts))
 	cmd.AddCommand(NewCmdGetBranchPattern(commonOpts))
 	cmd.AddCommand(NewCmdGetBuild(commonOpts))
 	cmd.AddCommand(NewCmdGetBuildPack(commonOpts))
 	cmd.AddCommand(NewCmdGetChat(commonOpts))
+	cmd.AddCommand(NewCmdGetCloudResources(commonOpts))
 	cmd.AddCommand(NewCmdGetConfig(commonOpts))
 	cmd.AddCommand(NewCmdGetCRDCount(commonOpts))
+	cmd.AddCommand(NewCmdGetCRDs(commonOpts))
 	cmd.AddCommand(NewCmdGetCVE(commonOpts))
 	cmd.AddCommand(NewCmdGetDevPod(commonOpts))
 	cmd.AddCommand(NewCmdGetEnv(commonOpts))
@@ -93,9 +92,15 @@ func NewCmdGet(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(NewCmdGetLang(commonOpts))
 	cmd.AddCommand(NewCmdGetPipeline(commonOpts))
 	cmd.AddCommand(NewCmdGetPreview(commonOpts))
+	cmd.AddCommand(NewCmdGetPromotions(commonOpts))
+	cmd.AddCommand(NewCmdGetPullRequest(commonOpts))
+	cmd.AddCommand(NewCmdGetQuickstartCatalogs(commonOpts))
 	cmd.AddCommand(NewCmdGetQuickstartLocation(commonOpts))
 	cmd.AddCommand(NewCmdGetQuickstarts(commonOpts))
 	cmd.AddCommand(NewCmdGetRelease(commonOpts))
+	cmd.AddCommand(NewCmdGetReports(commonOpts))
+	cmd.AddCommand(NewCmdGetSbom(commonOpts))
+	cmd.AddCommand(NewCmdGetScans(commonOpts))
 	cmd.AddCommand(NewCmdGetStorage(commonOpts))
 	cmd.AddCommand(NewCmdGetTeam(commonOpts))
 	cmd.AddCommand(NewCmdGetTeamRole(commonOpts))
@@ -118,29 +123,7 @@ func (o *Options) Run() error {
 // AddGetFlags adds an output flag to change the format of the output
 func (o *Options) AddGetFlags(cmd *cobra.Command) {
 	o.Cmd = cmd
-	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "The output format such as 'yaml'")
-}
-
-// renderResult renders the result in a given output format
-func (o *Options) renderResult(value interface{}, format string) error {
-	switch format {
-	case "json":
-		data, err := json.Marshal(value)
-		if err != nil {
-			return err
-		}
-		_, e := o.Out.Write(data)
-		return e
-	case "yaml":
-		data, err := yaml.Marshal(value)
-		if err != nil {
-			return err
-		}
-		_, e := o.Out.Write(data)
-		return e
-	default:
-		return fmt.Errorf("Unsupported output format: %s", format)
-	}
+	o.AddOutputFlag(cmd, &o.Output)
 }
 
 func formatInt32(n int32) string {
@@ -15,9 +15,11 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	tbl "github.com/jenkins-x/jx/v2/pkg/table"
 	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/cache"
 )
 
@@ -29,15 +31,29 @@ const (
 type GetActivityOptions struct {
 	*opts.CommonOptions
 
-	Filter      string
-	BuildNumber string
-	Watch       bool
-	Sort        bool
+	Filter        string
+	BuildNumber   string
+	Selector      string
+	FieldSelector string
+	Limit         int
+	Since         time.Duration
+	PageSize      int64
+	Watch         bool
+	Sort          bool
+
+	fieldSelector fields.Selector
 }
 
 var (
 	get_activity_long = templates.LongDesc(`
 		Display the current activities for one or more projects.
+
+		The activities are listed in pages of '--page-size' and the rows of each page are output as soon as it is
+		retrieved so that large numbers of activities are neither held in memory nor delay the output. Use '--selector'
+		to filter the activities by their labels such as owner, repository, branch and build on the server.
+
+		Sorting the activities by timestamp needs all of the matching activities so the output starts once they are all
+		listed. The most recent activities are shown if '--limit' is used with '--sort'.
 `)
 
 	get_activity_example = templates.Examples(`
@@ -49,6 +65,12 @@ var (
 
 		# Watch the activities for application 'foo'
 		jx get act -f foo -w
+
+		# List the 20 most recent activities of the repository 'foo' started in the last day
+		jx get act --selector repository=foo --since 24h --limit 20 --sort
+
+		# List the failed activities
+		jx get act --field-selector spec.status=Failed
 	`)
 )
 
@@ -72,6 +94,11 @@ func NewCmdGetActivity(commonOpts *opts.CommonOptions) *cobra.Command {
 	}
 	cmd.Flags().StringVarP(&options.Filter, "filter", "f", "", "Text to filter the pipeline names")
 	cmd.Flags().StringVarP(&options.BuildNumber, "build", "", "", "The build number to filter on")
+	cmd.Flags().StringVarP(&options.Selector, "selector", "l", "", "The label selector of the activities such as 'owner=foo,repository=bar'")
+	cmd.Flags().StringVarP(&options.FieldSelector, "field-selector", "", "", "The field selector of the activities such as 'spec.status=Failed'")
+	cmd.Flags().IntVarP(&options.Limit, "limit", "", 0, "The maximum number of activities to display. All activities are displayed if zero")
+	cmd.Flags().DurationVarP(&options.Since, "since", "", 0, "Only displays the activities started within the duration such as 2h or 24h")
+	cmd.Flags().Int64VarP(&options.PageSize, "page-size", "", 500, "The number of activities retrieved by each list call")
 	cmd.Flags().BoolVarP(&options.Watch, "watch", "w", false, "Whether to watch the activities for changes")
 	cmd.Flags().BoolVarP(&options.Sort, "sort", "s", false, "Sort activities by timestamp")
 	return cmd
@@ -91,6 +118,16 @@ func (o *GetActivityOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	labelSelector, err := labels.Parse(o.Selector)
+	if err != nil {
+		return errors.Wrapf(err, "parsing the label selector %s", o.Selector)
+	}
+	if o.FieldSelector != "" {
+		o.fieldSelector, err = fields.ParseSelector(o.FieldSelector)
+		if err != nil {
+			return errors.Wrapf(err, "parsing the field selector %s", o.FieldSelector)
+		}
+	}
 	table := o.CreateTable()
 	table.SetColumnAlign(1, util.ALIGN_RIGHT)
 	table.SetColumnAlign(2, util.ALIGN_RIGHT)
@@ -100,21 +137,60 @@ func (o *GetActivityOptions) Run() error {
 		return o.WatchActivities(&table, client, ns)
 	}
 
-	list, err := client.JenkinsV1().PipelineActivities(ns).List(metav1.ListOptions{})
-	if err != nil {
-		return err
-	}
+	activities := client.JenkinsV1().PipelineActivities(ns)
 	if o.Sort {
-		kube.SortActivities(list.Items)
+		var matched []v1.PipelineActivity
+		err = kube.ListPipelineActivitiesInPages(activities, labelSelector, o.fieldSelector, o.PageSize, func(items []v1.PipelineActivity) (bool, error) {
+			for i := range items {
+				if o.matches(&items[i]) {
+					matched = append(matched, items[i])
+				}
+			}
+			// only keep the most recent activities when limited to avoid holding all activities in memory
+			if o.Limit > 0 && len(matched) > 2*o.Limit {
+				matched = mostRecentActivities(matched, o.Limit)
+			}
+			return true, nil
+		})
+		if err != nil {
+			return err
+		}
+		if o.Limit > 0 {
+			matched = mostRecentActivities(matched, o.Limit)
+		} else {
+			kube.SortActivities(matched)
+		}
+		for i := range matched {
+			o.addTableRow(&table, &matched[i])
+		}
+		table.Render()
+		return nil
 	}
 
-	for _, activity := range list.Items {
-		a := activity
-		o.addTableRow(&table, &a)
-	}
-	table.Render()
+	count := 0
+	err = kube.ListPipelineActivitiesInPages(activities, labelSelector, o.fieldSelector, o.PageSize, func(items []v1.PipelineActivity) (bool, error) {
+		for i := range items {
+			if o.Limit > 0 && count >= o.Limit {
+				break
+			}
+			if o.addTableRow(&table, &items[i]) {
+				count++
+			}
+		}
+		table.Render()
+		table.Clear()
+		return o.Limit <= 0 || count < o.Limit, nil
+	})
+	return err
+}
 
-	return nil
+// mostRecentActivities sorts the activities by timestamp and returns the last ones
+func mostRecentActivities(activities []v1.PipelineActivity, limit int) []v1.PipelineActivity {
+	kube.SortActivities(activities)
+	if len(activities) > limit {
+		activities = append([]v1.PipelineActivity{}, activities[len(activities)-limit:]...)
+	}
+	return activities
 }
 
 func (o *GetActivityOptions) addTableRow(table *tbl.Table, activity *v1.PipelineActivity) bool {
@@ -148,7 +224,9 @@ func (o *GetActivityOptions) addTableRow(table *tbl.Table, activity *v1.Pipeline
 func (o *GetActivityOptions) WatchActivities(table *tbl.Table, jxClient versioned.Interface, ns string) error {
 	yamlSpecMap := map[string]string{}
 	activity := &v1.PipelineActivity{}
-	listWatch := cache.NewListWatchFromClient(jxClient.JenkinsV1().RESTClient(), "pipelineactivities", ns, fields.Everything())
+	listWatch := cache.NewFilteredListWatchFromClient(jxClient.JenkinsV1().RESTClient(), "pipelineactivities", ns, func(options *metav1.ListOptions) {
+		options.LabelSelector = o.Selector
+	})
 	kube.SortListWatchByName(listWatch)
 	_, controller := cache.NewInformer(
 		listWatch,
@@ -179,6 +257,14 @@ func (o *GetActivityOptions) onActivity(table *tbl.Table, obj interface{}, yamlS
 		log.Logger().Infof("Object is not a PipelineActivity %#v", obj)
 		return
 	}
+	matches, err := kube.PipelineActivityMatchesFields(*activity, o.fieldSelector)
+	if err != nil {
+		log.Logger().Infof("Failed to match the fields of the activity %s: %s", activity.Name, err)
+		return
+	}
+	if !matches {
+		return
+	}
 	data, err := yaml.Marshal(&activity.Spec)
 	if err != nil {
 		log.Logger().Infof("Failed to marshal Activity.Spec to YAML: %s", err)
@@ -349,5 +435,12 @@ func (o *GetActivityOptions) matches(activity *v1.PipelineActivity) bool {
 	if answer && build != "" {
 		answer = activity.Spec.Build == build
 	}
+	if answer && o.Since > 0 {
+		started := activity.Spec.StartedTimestamp
+		if started == nil {
+			started = &activity.CreationTimestamp
+		}
+		answer = started.After(time.Now().Add(-o.Since))
+	}
 	return answer
 }
@@ -4,6 +4,7 @@ package get_test
 
 import (
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -35,6 +36,8 @@ var _ = Describe("get activity", func() {
 			originalBranchName string
 
 			sort   bool
+			limit  int
+			since  time.Duration
 			err    error
 			stdout *testhelpers.FakeOut
 		)
@@ -49,6 +52,10 @@ var _ = Describe("get activity", func() {
 			os.Setenv("REPO_NAME", "jx-testing")
 			os.Setenv("JOB_NAME", "job")
 			os.Setenv("BRANCH_NAME", "job")
+
+			sort = false
+			limit = 0
+			since = 0
 		})
 
 		AfterEach(func() {
@@ -82,6 +89,8 @@ var _ = Describe("get activity", func() {
 			options := &get.GetActivityOptions{
 				CommonOptions: commonOpts,
 				Sort:          sort,
+				Limit:         limit,
+				Since:         since,
 			}
 
 			err = options.Run()
@@ -110,5 +119,40 @@ jx-testing/jx-testing/job #1`))
 jx-testing/jx-testing/job #2`))
 			})
 		})
+
+		Context("With the limit and sort flags", func() {
+			BeforeEach(func() {
+				sort = true
+				limit = 1
+			})
+
+			It("Prints the most recent activities", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stdout.GetOutput()).To(ContainSubstring("jx-testing/jx-testing/job #1"))
+				Expect(stdout.GetOutput()).NotTo(ContainSubstring("jx-testing/jx-testing/job #2"))
+			})
+		})
+
+		Context("With the limit flag", func() {
+			BeforeEach(func() {
+				limit = 1
+			})
+
+			It("Prints a limited list of activities", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(strings.Count(stdout.GetOutput(), "jx-testing/jx-testing/job #")).To(Equal(1))
+			})
+		})
+
+		Context("With the since flag", func() {
+			BeforeEach(func() {
+				since = time.Hour
+			})
+
+			It("Prints no old activities", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stdout.GetOutput()).NotTo(ContainSubstring("jx-testing/jx-testing/job #"))
+			})
+		})
 	})
 })
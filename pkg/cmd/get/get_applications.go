@@ -31,6 +31,22 @@ type GetApplicationsOptions struct {
 	HideUrl     bool
 	HidePod     bool
 	Previews    bool
+	Output      string
+}
+
+// ApplicationSummary describes an application and its deployments when rendered via the output flag
+type ApplicationSummary struct {
+	Name        string                         `json:"name"`
+	Deployments []ApplicationDeploymentSummary `json:"deployments"`
+}
+
+// ApplicationDeploymentSummary describes a deployment of an application in an environment
+type ApplicationDeploymentSummary struct {
+	Environment string `json:"environment"`
+	Namespace   string `json:"namespace"`
+	Version     string `json:"version,omitempty"`
+	Pods        string `json:"pods,omitempty"`
+	URL         string `json:"url,omitempty"`
 }
 
 // Applications is a map indexed by the application name then the environment name
@@ -73,6 +89,9 @@ var (
 
 		# List applications just showing the versions (hiding urls and pod counts)
 		jx get applications -u -p
+
+		# List applications and their deployments as YAML
+		jx get applications -o yaml
 	`)
 )
 
@@ -99,11 +118,13 @@ func NewCmdGetApplications(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().BoolVarP(&options.Previews, "preview", "w", false, "Show preview environments only")
 	cmd.Flags().StringVarP(&options.Environment, "env", "e", "", "Filter applications in the given environment")
 	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "Filter applications in the given namespace")
+	options.AddOutputFlag(cmd, &options.Output)
 	return cmd
 }
 
 // Run implements this command
 func (o *GetApplicationsOptions) Run() error {
+	o.RedirectLogsForOutput(o.Output)
 	if o.Previews {
 		fmt.Println("The `--preview` flag has been deprecated from this command, use instead `jx get previews`")
 		return nil
@@ -113,7 +134,7 @@ func (o *GetApplicationsOptions) Run() error {
 	if err != nil {
 		return errors.Wrap(err, "fetching applications")
 	}
-	if len(list.Items) == 0 {
+	if len(list.Items) == 0 && o.Output == "" {
 		log.Logger().Infof("No applications found")
 		return nil
 	}
@@ -122,6 +143,9 @@ func (o *GetApplicationsOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	if o.Output != "" {
+		return o.RenderOutput(o.Output, o.generateSummaries(kubeClient, list))
+	}
 	table := o.generateTable(kubeClient, list)
 	table.Render()
 
@@ -177,6 +201,40 @@ func (o *GetApplicationsOptions) generateTable(kubeClient kubernetes.Interface,
 	return table
 }
 
+func (o *GetApplicationsOptions) generateSummaries(kubeClient kubernetes.Interface, list applications.List) []ApplicationSummary {
+	answer := []ApplicationSummary{}
+	for _, a := range list.Items {
+		summary := ApplicationSummary{
+			Name:        a.Name(),
+			Deployments: []ApplicationDeploymentSummary{},
+		}
+		for _, k := range o.sortedKeys(list.Environments()) {
+			ae, ok := a.Environments[k]
+			if !ok {
+				continue
+			}
+			for _, d := range ae.Deployments {
+				deployment := ApplicationDeploymentSummary{
+					Environment: k,
+					Namespace:   ae.Environment.Spec.Namespace,
+					Version:     d.Version(),
+				}
+				if !o.HidePod {
+					deployment.Pods = d.Pods()
+				}
+				if !o.HideUrl {
+					deployment.URL = d.URL(kubeClient, a)
+				}
+				summary.Deployments = append(summary.Deployments, deployment)
+			}
+		}
+		if len(summary.Deployments) > 0 {
+			answer = append(answer, summary)
+		}
+	}
+	return answer
+}
+
 func envTitleName(e v1.Environment) string {
 	if e.Spec.Kind == v1.EnvironmentKindTypeEdit {
 		return "Edit"
@@ -84,11 +84,12 @@ func NewCmdGetApps(commonOpts *opts.CommonOptions) *cobra.Command {
 		},
 	}
 	cmd := &cobra.Command{
-		Use:     "apps",
-		Short:   "Display one or more installed Apps (an app is similar to an addon)",
-		Aliases: []string{"app"},
-		Long:    getAppsLong,
-		Example: getAppsExample,
+		Use:               "apps",
+		Short:             "Display one or more installed Apps (an app is similar to an addon)",
+		Aliases:           []string{"app"},
+		Long:              getAppsLong,
+		Example:           getAppsExample,
+		ValidArgsFunction: options.CompleteApps,
 		Run: func(cmd *cobra.Command, args []string) {
 			options.Cmd = cmd
 			options.Args = args
@@ -172,7 +173,7 @@ func (o *GetAppsOptions) Run() error {
 
 	if o.Output != "" {
 		appsResult := o.generateTableFormatted(apps)
-		return o.renderResult(appsResult, o.Output)
+		return o.RenderOutput(o.Output, appsResult)
 	}
 	table := o.generateTable(apps, kubeClient)
 	table.Render()
@@ -0,0 +1,176 @@
+package get
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/artifacts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetArtifactsOptions containers the CLI options
+type GetArtifactsOptions struct {
+	Options
+
+	Classifier string
+	Dir        string
+	List       bool
+	Timeout    time.Duration
+}
+
+var (
+	getArtifactsLong = templates.LongDesc(`
+		Downloads the files stashed by a build via 'jx step stash' such as reports, test results and coverage files
+
+		The files are downloaded from the cloud storage or git repository they were stashed to so they are available
+		after the pods of the build have been removed.
+`)
+
+	getArtifactsExample = templates.Examples(`
+		# Pick a build and download its stashed files into the artifacts directory
+		jx get artifacts
+
+		# Download the test results of build 3 of the master branch of myrepo
+		jx get artifacts "myorg/myrepo/master #3" --classifier tests --dir reports
+
+		# List the stashed files of a build without downloading them
+		jx get artifacts myorg-myrepo-master-3 --list
+	`)
+)
+
+// NewCmdGetArtifacts creates the new command for: jx get artifacts
+func NewCmdGetArtifacts(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetArtifactsOptions{
+		Options: Options{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "artifacts [build]",
+		Short:   "Downloads the files stashed by a build",
+		Aliases: []string{"artifact"},
+		Long:    getArtifactsLong,
+		Example: getArtifactsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Classifier, "classifier", "c", "", "Only downloads the files stashed with the given classifier such as tests or coverage")
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", "artifacts", "The directory to download the files into. The files of each classifier are downloaded into a sub directory")
+	cmd.Flags().BoolVarP(&options.List, "list", "l", false, "Lists the URLs of the stashed files rather than downloading them")
+	cmd.Flags().DurationVarP(&options.Timeout, "timeout", "t", time.Second*30, "The timeout of downloading each file")
+	return cmd
+}
+
+// Run implements this command
+func (o *GetArtifactsOptions) Run() error {
+	activity, err := o.pickActivity()
+	if err != nil {
+		return err
+	}
+	attachments := []v1.Attachment{}
+	for _, a := range activity.Spec.Attachments {
+		if o.Classifier == "" || a.Name == o.Classifier {
+			attachments = append(attachments, a)
+		}
+	}
+	if len(attachments) == 0 {
+		return fmt.Errorf("no files were stashed by build %s", activityBuildName(activity))
+	}
+
+	if o.List {
+		table := o.CreateTable()
+		table.AddRow("CLASSIFIER", "URL")
+		for _, a := range attachments {
+			for _, u := range a.URLs {
+				table.AddRow(a.Name, u)
+			}
+		}
+		table.Render()
+		return nil
+	}
+
+	authSvc, err := o.GitAuthConfigService()
+	if err != nil {
+		return err
+	}
+	httpFn := step.CreateBucketHTTPFn(authSvc)
+	for _, a := range attachments {
+		for u, name := range artifacts.RelativeNames(a.URLs) {
+			fileName := filepath.Join(o.Dir, a.Name, filepath.FromSlash(name))
+			err = artifacts.Download(u, fileName, o.Timeout, httpFn)
+			if err != nil {
+				return errors.Wrapf(err, "failed to download %s", u)
+			}
+			log.Logger().Infof("downloaded %s", util.ColorInfo(fileName))
+		}
+	}
+	return nil
+}
+
+// pickActivity returns the PipelineActivity of the build with stashed files named by the argument or picked by the user
+func (o *GetArtifactsOptions) pickActivity() (*v1.PipelineActivity, error) {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return nil, err
+	}
+	list, err := jxClient.JenkinsV1().PipelineActivities(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the PipelineActivities in namespace %s", ns)
+	}
+	names := []string{}
+	m := map[string]*v1.PipelineActivity{}
+	for i := range list.Items {
+		activity := &list.Items[i]
+		if len(activity.Spec.Attachments) == 0 {
+			continue
+		}
+		name := activityBuildName(activity)
+		names = append(names, name)
+		m[name] = activity
+		m[activity.Name] = activity
+	}
+	sort.Strings(names)
+
+	if len(o.Args) > 0 {
+		if activity := m[o.Args[0]]; activity != nil {
+			return activity, nil
+		}
+		names = util.StringsContaining(names, o.Args[0])
+	}
+	if len(names) == 0 {
+		return nil, errors.New("no builds with stashed files were found")
+	}
+	if len(names) > 1 && o.BatchMode {
+		return nil, fmt.Errorf("more than one build with stashed files matches: %s", strings.Join(names, ", "))
+	}
+	name, err := util.PickName(names, "Which build do you want to get the stashed files of?: ", "", o.GetIOFileHandles())
+	if err != nil {
+		return nil, err
+	}
+	return m[name], nil
+}
+
+// activityBuildName returns the name of the build of the PipelineActivity such as owner/repo/branch #1
+func activityBuildName(activity *v1.PipelineActivity) string {
+	spec := activity.Spec
+	if spec.GitOwner == "" || spec.GitRepository == "" {
+		return activity.Name
+	}
+	return fmt.Sprintf("%s/%s/%s #%s", spec.GitOwner, spec.GitRepository, spec.GitBranch, spec.Build)
+}
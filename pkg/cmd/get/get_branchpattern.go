@@ -4,6 +4,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/spf13/cobra"
 )
 
@@ -14,7 +15,7 @@ type GetBranchPatternOptions struct {
 
 var (
 	getBranchPatternLong = templates.LongDesc(`
-		Display the git branch patterns for the current Team used on creating and importing projects
+		Display the git branch patterns for the current Team used on creating and importing projects and the branch protection applied to the repositories created by jx
 
 		For more documentation see: [https://jenkins-x.io/docs/using-jx/creating/import/#branch-patterns](https://jenkins-x.io/docs/using-jx/creating/import/#branch-patterns)
 `)
@@ -56,9 +57,21 @@ func (o *GetBranchPatternOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	devEnv, _, err := o.DevEnvAndTeamSettings()
+	if err != nil {
+		return err
+	}
+	protection, err := kube.BranchProtectionTemplate(devEnv)
+	if err != nil {
+		return err
+	}
+	protectionText := "none"
+	if protection != nil {
+		protectionText = protection.Description()
+	}
 	table := o.CreateTable()
-	table.AddRow("BRANCH PATTERNS")
-	table.AddRow(patterns.DefaultBranchPattern)
+	table.AddRow("BRANCH PATTERNS", "PROTECTION")
+	table.AddRow(patterns.DefaultBranchPattern, protectionText)
 	table.Render()
 	return nil
 }
@@ -1,11 +1,13 @@
 package get
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/acarl005/stripansi"
 	"github.com/jenkins-x/jx-api/pkg/client/clientset/versioned"
 	"github.com/jenkins-x/jx/v2/pkg/builds"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
@@ -34,8 +36,21 @@ type GetBuildLogsOptions struct {
 	WaitForPipelineDuration time.Duration
 	TektonLogger            *logs.TektonLogger
 	FailIfPodFails          bool
+	Stage                   string
+	Step                    string
+	Parallel                bool
 }
 
+// buildLogLine the JSON representation of a line of a build log
+type buildLogLine struct {
+	Stage string `json:"stage,omitempty"`
+	Step  string `json:"step,omitempty"`
+	Line  string `json:"line"`
+}
+
+// buildLogOutputFormats the possible values of the output flag
+var buildLogOutputFormats = []string{"json"}
+
 // CLILogWriter is an implementation of logs.LogWriter that will show logs in the standard output
 type CLILogWriter struct {
 	*opts.CommonOptions
@@ -45,6 +60,9 @@ var (
 	get_build_log_long = templates.LongDesc(`
 		Display a build log
 
+		The logs of running builds are streamed from the pods of the build. The logs of completed builds are read
+		from the long term storage of the team such as a GCS, S3 or Azure Blob bucket or the GitHub pages of the
+		environment repository.
 `)
 
 	get_build_log_example = templates.Examples(`
@@ -65,6 +83,15 @@ var (
 
 		# View the build logs for a specific tekton build pod
 		jx get build log --pod my-pod-name
+
+		# View only the logs of the steps of the build stage containing test
+		jx get build log --repo cheese --stage build --step test
+
+		# Follow the logs of the parallel stages of a build at the same time
+		jx get build log --repo cheese --parallel
+
+		# View the build log as JSON lines without colors
+		jx get build log --repo cheese --output json
 	`)
 )
 
@@ -103,6 +130,10 @@ func NewCmdGetBuildLogs(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.BuildFilter.GitURL, "giturl", "g", "", "The git URL to filter on. If you specify a link to a github repository or PR we can filter the query of build pods accordingly")
 	cmd.Flags().StringVarP(&options.BuildFilter.Context, "context", "", "", "Filters the context of the build")
 	cmd.Flags().BoolVarP(&options.CurrentFolder, "current", "c", false, "Display logs using current folder as repo name, and parent folder as owner")
+	cmd.Flags().StringVarP(&options.Stage, "stage", "", "", "Only displays the logs of the stages whose name contains the given text")
+	cmd.Flags().StringVarP(&options.Step, "step", "", "", "Only displays the logs of the steps whose name contains the given text")
+	cmd.Flags().BoolVarP(&options.Parallel, "parallel", "", false, "Follows the logs of the stages of the build at the same time, prefixing each line with its stage and step")
+	cmd.Flags().StringVarP(&options.Output, "output", "", "", fmt.Sprintf("The format of the log lines. Possible values: %s", strings.Join(buildLogOutputFormats, ", ")))
 	options.AddBaseFlags(cmd)
 
 	return cmd
@@ -114,6 +145,9 @@ func (o *GetBuildLogsOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	if o.Output != "" && util.StringArrayIndex(buildLogOutputFormats, o.Output) < 0 {
+		return util.InvalidOption("output", o.Output, buildLogOutputFormats)
+	}
 	jxClient, ns, err := o.JXClientAndDevNamespace()
 	if err != nil {
 		return err
@@ -161,6 +195,9 @@ func (o *GetBuildLogsOptions) getProwBuildLog(kubeClient kubernetes.Interface, t
 			JXClient:       jxClient,
 			Namespace:      ns,
 			FailIfPodFails: o.FailIfPodFails,
+			StageFilter:    o.Stage,
+			StepFilter:     o.Step,
+			FollowParallel: o.Parallel,
 		}
 	}
 	var waitableCondition bool
@@ -230,7 +267,10 @@ func (o *GetBuildLogsOptions) getTektonLogs(kubeClient kubernetes.Interface, tek
 			return false, err
 		}
 		for line := range o.TektonLogger.StreamPipelinePersistentLogs(pa.Spec.BuildLogsURL, authSvc) {
-			fmt.Fprintln(o.Out, line.Line)
+			err = o.writeLogLine(line)
+			if err != nil {
+				return false, err
+			}
 		}
 		return false, o.TektonLogger.Err()
 	}
@@ -238,7 +278,28 @@ func (o *GetBuildLogsOptions) getTektonLogs(kubeClient kubernetes.Interface, tek
 	log.Logger().Infof("Build logs for %s", util.ColorInfo(name))
 	name = strings.TrimSuffix(name, " ")
 	for line := range o.TektonLogger.GetRunningBuildLogs(pa, name, false) {
-		fmt.Fprintln(o.Out, line.Line)
+		err = o.writeLogLine(line)
+		if err != nil {
+			return false, err
+		}
 	}
 	return false, o.TektonLogger.Err()
 }
+
+// writeLogLine writes the line as JSON without colors if the output is json, or prefixed with its stage and step
+// if the logs of the stages are followed in parallel so that the interleaved lines can be told apart
+func (o *GetBuildLogsOptions) writeLogLine(line logs.LogLine) error {
+	if o.Output == "json" {
+		return json.NewEncoder(o.Out).Encode(&buildLogLine{
+			Stage: line.Stage,
+			Step:  line.Step,
+			Line:  strings.Trim(stripansi.Strip(line.Line), "\n"),
+		})
+	}
+	if o.Parallel && line.Stage != "" {
+		_, err := fmt.Fprintf(o.Out, "[%s/%s] %s\n", line.Stage, line.Step, strings.TrimLeft(line.Line, "\n"))
+		return err
+	}
+	_, err := fmt.Fprintln(o.Out, line.Line)
+	return err
+}
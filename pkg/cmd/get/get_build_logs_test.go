@@ -15,6 +15,7 @@ import (
 
 	"github.com/acarl005/stripansi"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/clients/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/testhelpers"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/jenkins-x/jx/v2/pkg/logs"
 	corev1 "k8s.io/api/core/v1"
@@ -79,6 +80,35 @@ func TestGetTektonLogsForRunningBuild(t *testing.T) {
 	}
 }
 
+func TestWriteBuildLogLine(t *testing.T) {
+	line := logs.LogLine{
+		Line:  "\nShowing logs for build \x1b[32mfakeowner/fakerepo/fakebranch #1\x1b[0m stage \x1b[32mbuild\x1b[0m",
+		Stage: "build",
+		Step:  "step-test",
+	}
+	commonOpts := opts.NewCommonOptionsWithFactory(fake.NewFakeFactory())
+	out := &testhelpers.FakeOut{}
+	commonOpts.Out = out
+	o := &GetBuildLogsOptions{
+		Options: Options{
+			CommonOptions: &commonOpts,
+			Output:        "json",
+		},
+	}
+
+	err := o.writeLogLine(line)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"stage":"build","step":"step-test","line":"Showing logs for build fakeowner/fakerepo/fakebranch #1 stage build"}`+"\n", out.GetOutput())
+
+	out = &testhelpers.FakeOut{}
+	o.Out = out
+	o.Output = ""
+	o.Parallel = true
+	err = o.writeLogLine(logs.LogLine{Line: "testing", Stage: "build", Step: "step-test"})
+	assert.NoError(t, err)
+	assert.Equal(t, "[build/step-test] testing\n", out.GetOutput())
+}
+
 func TestGetTektonLogsForRunningBuildWithPendingPod(t *testing.T) {
 	commonOpts := opts.NewCommonOptionsWithFactory(fake.NewFakeFactory())
 	commonOpts.BatchMode = true
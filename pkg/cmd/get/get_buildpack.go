@@ -33,7 +33,7 @@ var (
 `)
 
 	getBuildPackExample = templates.Examples(`
-		# List the build pack for the current team
+		# List the build pack repositories for the current team in the order packs are searched
 		jx get buildpack
 
 		# List all the available build packs you can pick from
@@ -99,7 +99,18 @@ func (o *GetBuildPackOptions) Run() error {
 			}
 		}
 	} else {
-		table.AddRow(settings.BuildPackName, settings.BuildPackURL, settings.BuildPackRef)
+		repositories, err := o.BuildPackRepositories(settings)
+		if err != nil {
+			return err
+		}
+		// lets list the repositories in the order packs are searched
+		for _, r := range repositories {
+			name := ""
+			if r.SameURL(settings.BuildPackURL) {
+				name = settings.BuildPackName
+			}
+			table.AddRow(name, r.URL, r.GetRef())
+		}
 	}
 	table.Render()
 	return nil
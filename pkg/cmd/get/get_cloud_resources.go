@@ -0,0 +1,108 @@
+package get
+
+import (
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/crossplane"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// GetCloudResourcesOptions containers the CLI options
+type GetCloudResourcesOptions struct {
+	Options
+
+	Env string
+}
+
+var (
+	getCloudResourcesLong = templates.LongDesc(`
+		Display the cloud resources such as buckets and databases claimed via Crossplane by the apps of the environments
+
+		The connection details of a claim are written to the Secret of the SECRET column in the namespace of the environment
+`)
+
+	getCloudResourcesExample = templates.Examples(`
+		# List the cloud resources claimed in all the environments
+		jx get cloudresources
+
+		# List the cloud resources claimed in production
+		jx get cloudresources --env production
+	`)
+)
+
+// NewCmdGetCloudResources creates the new command for: jx get cloudresources
+func NewCmdGetCloudResources(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetCloudResourcesOptions{
+		Options: Options{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "cloudresources",
+		Short:   "Display the cloud resources claimed by the apps of the environments",
+		Aliases: []string{"cloudresource", "claims"},
+		Long:    getCloudResourcesLong,
+		Example: getCloudResourcesExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Env, "env", "e", "", "The environment to display the cloud resources of. Defaults to all the environments")
+	return cmd
+}
+
+// Run implements this command
+func (o *GetCloudResourcesOptions) Run() error {
+	jxClient, devNs, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	envMap, names, err := kube.GetEnvironments(jxClient, devNs)
+	if err != nil {
+		return errors.Wrapf(err, "listing the environments in namespace %s", devNs)
+	}
+	if o.Env != "" {
+		if envMap[o.Env] == nil {
+			return util.InvalidOption("env", o.Env, names)
+		}
+		names = []string{o.Env}
+	}
+	dynamicClient, _, err := o.GetFactory().CreateDynamicClient()
+	if err != nil {
+		return errors.Wrap(err, "creating the dynamic client")
+	}
+	resources, err := crossplane.ClaimResources(dynamicClient)
+	if err != nil {
+		return err
+	}
+	if len(resources) == 0 {
+		log.Logger().Infof("No cloud resources can be claimed, install Crossplane via %s", util.ColorInfo("jx create addon crossplane"))
+		return nil
+	}
+
+	table := o.CreateTable()
+	table.AddRow("ENV", "NAMESPACE", "KIND", "NAME", "STATUS", "SECRET")
+	for _, name := range names {
+		ns := envMap[name].Spec.Namespace
+		if ns == "" {
+			continue
+		}
+		claims, err := crossplane.ListClaims(dynamicClient, resources, ns)
+		if err != nil {
+			return err
+		}
+		for _, c := range claims {
+			table.AddRow(name, ns, c.Kind, c.Name, c.Status, c.ConnectionSecret)
+		}
+	}
+	table.Render()
+	return nil
+}
@@ -0,0 +1,110 @@
+package get
+
+import (
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/kube/crds"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// GetCRDsOptions the command line options
+type GetCRDsOptions struct {
+	Options
+
+	JenkinsX bool
+}
+
+var (
+	getCRDsLong = templates.LongDesc(`
+		Displays the status of the Custom Resource Definitions in the cluster.
+
+		With --jx only the Jenkins X CRDs are displayed, including those which are not registered yet, along with
+		whether they match the CRDs of this binary. Use 'jx upgrade crd' to upgrade the CRDs which are out of date.
+`)
+
+	getCRDsExample = templates.Examples(`
+		# Display the status of all the Custom Resource Definitions
+		jx get crds
+
+		# Display the status of the Jenkins X Custom Resource Definitions
+		jx get crds --jx
+	`)
+)
+
+// NewCmdGetCRDs creates the command
+func NewCmdGetCRDs(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetCRDsOptions{
+		Options: Options{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "crds [flags]",
+		Short:   "Displays the status of the Custom Resource Definitions",
+		Long:    getCRDsLong,
+		Example: getCRDsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().BoolVarP(&options.JenkinsX, "jx", "", false, "Only displays the Jenkins X Custom Resource Definitions")
+	return cmd
+}
+
+// Run implements this command
+func (o *GetCRDsOptions) Run() error {
+	apisClient, err := o.ApiExtensionsClient()
+	if err != nil {
+		return errors.Wrap(err, "failed to create the API extensions client")
+	}
+	statuses, err := crds.GetStatuses(apisClient, o.JenkinsX)
+	if err != nil {
+		return err
+	}
+
+	table := o.CreateTable()
+	table.AddRow("NAME", "KIND", "VERSIONS", "STATUS", "SCHEMA", "UP TO DATE")
+	outOfDate := 0
+	for _, s := range statuses {
+		status := "Missing"
+		if s.Installed {
+			status = "Not Established"
+			if s.Established {
+				status = "Established"
+			}
+		}
+		schema := ""
+		if s.Installed {
+			schema = "Non Structural"
+			if s.Structural {
+				schema = "Structural"
+			}
+		}
+		upToDate := ""
+		if s.JenkinsX {
+			upToDate = "No"
+			if s.UpToDate {
+				upToDate = "Yes"
+			} else {
+				outOfDate++
+			}
+		}
+		table.AddRow(s.Name, s.Kind, strings.Join(s.Versions, ","), status, schema, upToDate)
+	}
+	table.Render()
+
+	if outOfDate > 0 {
+		log.Logger().Infof("\n%d Jenkins X CRDs are missing or out of date, run %s to upgrade them", outOfDate, util.ColorInfo("jx upgrade crd"))
+	}
+	return nil
+}
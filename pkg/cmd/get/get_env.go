@@ -35,6 +35,9 @@ var (
 
 		# List all environments using the shorter alias
 		jx get env
+
+		# List the names of the environments using a go template
+		jx get env -o go-template='{{range .items}}{{println .metadata.name}}{{end}}'
 	`)
 )
 
@@ -69,6 +72,7 @@ func NewCmdGetEnv(commonOpts *opts.CommonOptions) *cobra.Command {
 
 // Run implements this command
 func (o *GetEnvOptions) Run() error {
+	o.RedirectLogsForOutput(o.Output)
 	client, ns, err := o.JXClientAndDevNamespace()
 	if err != nil {
 		return err
@@ -90,6 +94,10 @@ func (o *GetEnvOptions) Run() error {
 			return util.InvalidArg(e, envNames)
 		}
 
+		if o.Output != "" {
+			return o.RenderOutput(o.Output, env)
+		}
+
 		// lets output one environment
 		spec := &env.Spec
 
@@ -122,7 +130,7 @@ func (o *GetEnvOptions) Run() error {
 		if err != nil {
 			return err
 		}
-		if len(envs.Items) == 0 {
+		if len(envs.Items) == 0 && o.Output == "" {
 			log.Logger().Infof("No environments found.\nTo create an environment use: jx create env")
 			return nil
 		}
@@ -132,7 +140,7 @@ func (o *GetEnvOptions) Run() error {
 
 		if o.Output != "" {
 			envs.Items = environments
-			return o.renderResult(envs, o.Output)
+			return o.RenderOutput(o.Output, envs)
 		}
 		table := o.CreateTable()
 		if o.PreviewOnly {
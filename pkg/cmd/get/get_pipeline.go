@@ -128,7 +128,7 @@ func (o *PipelineOptions) Run() error {
 	sort.Strings(names)
 
 	if o.Output != "" {
-		return o.renderResult(names, o.Output)
+		return o.RenderOutput(o.Output, names)
 	}
 
 	table := createTable(o)
@@ -0,0 +1,27 @@
+package get
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdGetPullRequest creates the command for: jx get pullrequest
+func NewCmdGetPullRequest(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &Options{
+		CommonOptions: commonOpts,
+	}
+	cmd := &cobra.Command{
+		Use:     "pullrequest",
+		Short:   "Display information about Pull Requests",
+		Aliases: []string{"pr", "pullrequests", "prs"},
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.AddCommand(NewCmdGetPullRequestStatus(commonOpts))
+	return cmd
+}
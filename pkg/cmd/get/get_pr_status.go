@@ -0,0 +1,407 @@
+package get
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	prowconfig "github.com/jenkins-x/jx/v2/pkg/prow/config"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetPullRequestStatusOptions containers the CLI options
+type GetPullRequestStatusOptions struct {
+	Options
+
+	GitURL   string
+	Watch    bool
+	Interval time.Duration
+}
+
+// PullRequestStatus the aggregated status of a Pull Request
+type PullRequestStatus struct {
+	Owner      string `json:"owner"`
+	Repository string `json:"repository"`
+	Number     int    `json:"number"`
+	Title      string `json:"title,omitempty"`
+	URL        string `json:"url,omitempty"`
+	State      string `json:"state,omitempty"`
+	// Contexts the latest status of each pipeline context of the last commit
+	Contexts   []PullRequestContext `json:"contexts,omitempty"`
+	PreviewURL string               `json:"previewURL,omitempty"`
+	// PendingApprovals the labels and reviews the Pull Request is waiting for before it can merge
+	PendingApprovals []string `json:"pendingApprovals,omitempty"`
+	// Blockers the labels which prevent the Pull Request merging
+	Blockers []string `json:"blockers,omitempty"`
+	// MergeQueuePosition the position of the Pull Request in the merge queue or 0 if it is not queued
+	MergeQueuePosition int `json:"mergeQueuePosition,omitempty"`
+	MergeQueueLength   int `json:"mergeQueueLength"`
+}
+
+// PullRequestContext the status of a pipeline context of a Pull Request
+type PullRequestContext struct {
+	Context     string `json:"context"`
+	State       string `json:"state"`
+	Description string `json:"description,omitempty"`
+	TargetURL   string `json:"targetURL,omitempty"`
+}
+
+const (
+	// pullRequestMerged the state of Pull Requests which have been merged
+	pullRequestMerged = "merged"
+)
+
+var (
+	getPullRequestStatusLong = templates.LongDesc(`
+		Display the status of a Pull Request: the state of its pipeline contexts, the URL of its preview
+		environment, the approvals it is waiting for and its position in the merge queue.
+
+		The Pull Request of the current branch is used unless a number is given.
+`)
+
+	getPullRequestStatusExample = templates.Examples(`
+		# Display the status of the Pull Request of the current branch
+		jx get pr status
+
+		# Display the status of Pull Request 12 updating it until it is merged or closed
+		jx get pr status 12 --watch
+
+		# Display the status of a Pull Request of another repository
+		jx get pr status 12 --git-url https://github.com/myorg/myrepo.git
+	`)
+)
+
+// NewCmdGetPullRequestStatus creates the new command for: jx get pr status
+func NewCmdGetPullRequestStatus(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetPullRequestStatusOptions{
+		Options: Options{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "status [number]",
+		Short:   "Display the pipelines, preview, approvals and merge queue position of a Pull Request",
+		Long:    getPullRequestStatusLong,
+		Example: getPullRequestStatusExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.GitURL, "git-url", "u", "", "The git URL of the repository of the Pull Request. Defaults to the repository in the current directory")
+	cmd.Flags().BoolVarP(&options.Watch, "watch", "w", false, "Keeps displaying the status until the Pull Request is merged or closed")
+	cmd.Flags().DurationVarP(&options.Interval, "interval", "", 30*time.Second, "The interval between updates of the status when watching")
+	options.AddGetFlags(cmd)
+	return cmd
+}
+
+// Run implements this command
+func (o *GetPullRequestStatusOptions) Run() error {
+	var gitInfo *gits.GitRepository
+	var err error
+	if o.GitURL != "" {
+		gitInfo, err = gits.ParseGitURL(o.GitURL)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse git URL %s", o.GitURL)
+		}
+	} else {
+		gitInfo, err = o.FindGitInfo("")
+		if err != nil {
+			return errors.Wrap(err, "failed to find the git repository of the current directory")
+		}
+	}
+	provider, err := o.GitProviderForURL(gitInfo.URL, "user name to query the Pull Requests")
+	if err != nil {
+		return err
+	}
+	number, err := o.pullRequestNumber(provider, gitInfo)
+	if err != nil {
+		return err
+	}
+
+	for {
+		status, err := o.PullRequestStatus(provider, gitInfo, number)
+		if err != nil {
+			return err
+		}
+		if o.Output != "" {
+			err = o.RenderOutput(o.Output, status)
+			if err != nil {
+				return err
+			}
+		} else {
+			o.renderPullRequestStatus(status)
+		}
+		if !o.Watch || status.State != gits.PullRequestOpen {
+			return nil
+		}
+		time.Sleep(o.Interval)
+		fmt.Fprintln(o.Out)
+	}
+}
+
+// PullRequestStatus aggregates the status of the Pull Request of the repository
+func (o *GetPullRequestStatusOptions) PullRequestStatus(provider gits.GitProvider, gitInfo *gits.GitRepository, number int) (*PullRequestStatus, error) {
+	pr, err := provider.GetPullRequest(gitInfo.Organisation, gitInfo, number)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting Pull Request %d of %s/%s", number, gitInfo.Organisation, gitInfo.Name)
+	}
+	status := &PullRequestStatus{
+		Owner:      gitInfo.Organisation,
+		Repository: gitInfo.Name,
+		Number:     number,
+		Title:      pr.Title,
+		URL:        pr.URL,
+		State:      util.DereferenceString(pr.State),
+	}
+	if pr.Merged != nil && *pr.Merged {
+		status.State = pullRequestMerged
+	}
+
+	if pr.LastCommitSha != "" {
+		statuses, err := provider.ListCommitStatus(gitInfo.Organisation, gitInfo.Name, pr.LastCommitSha)
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing the statuses of commit %s", pr.LastCommitSha)
+		}
+		status.Contexts = latestContexts(statuses)
+	}
+
+	status.PreviewURL, err = o.previewURL(gitInfo, pr.URL, number)
+	if err != nil {
+		return nil, err
+	}
+
+	query := prowconfig.ApplicationTideQuery()
+	status.PendingApprovals, status.Blockers = pendingApprovals(pr, query.Labels, query.MissingLabels)
+	if status.State == gits.PullRequestOpen {
+		status.MergeQueuePosition, status.MergeQueueLength, err = mergeQueuePosition(provider, gitInfo, number, query.Labels, query.MissingLabels)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// pullRequestNumber returns the number of the Pull Request given as an argument or of the current branch
+func (o *GetPullRequestStatusOptions) pullRequestNumber(provider gits.GitProvider, gitInfo *gits.GitRepository) (int, error) {
+	if len(o.Args) > 0 {
+		text := strings.TrimPrefix(strings.TrimPrefix(o.Args[0], "PR-"), "#")
+		number, err := strconv.Atoi(text)
+		if err != nil {
+			return 0, errors.Errorf("invalid Pull Request number %s", o.Args[0])
+		}
+		return number, nil
+	}
+	prs, err := provider.ListOpenPullRequests(gitInfo.Organisation, gitInfo.Name)
+	if err != nil {
+		return 0, errors.Wrapf(err, "listing the open Pull Requests of %s/%s", gitInfo.Organisation, gitInfo.Name)
+	}
+	if o.GitURL == "" {
+		branch, err := o.Git().Branch("")
+		if err == nil {
+			for _, pr := range prs {
+				if pr.Number != nil && util.DereferenceString(pr.HeadRef) == branch {
+					return *pr.Number, nil
+				}
+			}
+		}
+	}
+	if o.BatchMode {
+		return 0, errors.New("no Pull Request number was given")
+	}
+	names := []string{}
+	m := map[string]int{}
+	for _, pr := range prs {
+		if pr.Number != nil {
+			name := fmt.Sprintf("#%d %s", *pr.Number, pr.Title)
+			names = append(names, name)
+			m[name] = *pr.Number
+		}
+	}
+	if len(names) == 0 {
+		return 0, errors.Errorf("no open Pull Requests found in %s/%s", gitInfo.Organisation, gitInfo.Name)
+	}
+	name, err := util.PickName(names, "Which Pull Request do you want the status of?: ", "", o.GetIOFileHandles())
+	if err != nil {
+		return 0, err
+	}
+	return m[name], nil
+}
+
+// previewURL returns the URL of the application deployed to the preview environment of the Pull Request
+func (o *GetPullRequestStatusOptions) previewURL(gitInfo *gits.GitRepository, prURL string, number int) (string, error) {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return "", err
+	}
+	envs, err := jxClient.JenkinsV1().Environments(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return "", errors.Wrapf(err, "listing the Environments in namespace %s", ns)
+	}
+	for _, env := range envs.Items {
+		spec := env.Spec.PreviewGitSpec
+		if env.Spec.Kind != v1.EnvironmentKindTypePreview || spec.Name != strconv.Itoa(number) {
+			continue
+		}
+		if prURL != "" && spec.URL == prURL {
+			return spec.ApplicationURL, nil
+		}
+		if env.Spec.Source.URL != "" {
+			sourceInfo, err := gits.ParseGitURL(env.Spec.Source.URL)
+			if err == nil && strings.EqualFold(sourceInfo.Organisation, gitInfo.Organisation) && strings.EqualFold(sourceInfo.Name, gitInfo.Name) {
+				return spec.ApplicationURL, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+func (o *GetPullRequestStatusOptions) renderPullRequestStatus(status *PullRequestStatus) {
+	out := o.Out
+	fmt.Fprintf(out, "Pull Request %s #%d %s\n", util.ColorInfo(status.Owner+"/"+status.Repository), status.Number, status.Title)
+	if status.URL != "" {
+		fmt.Fprintf(out, "URL:          %s\n", status.URL)
+	}
+	fmt.Fprintf(out, "State:        %s\n", status.State)
+	preview := status.PreviewURL
+	if preview == "" {
+		preview = "none"
+	}
+	fmt.Fprintf(out, "Preview:      %s\n", preview)
+	approvals := "none"
+	if len(status.PendingApprovals) > 0 {
+		approvals = util.ColorWarning(strings.Join(status.PendingApprovals, ", "))
+	}
+	fmt.Fprintf(out, "Pending:      %s\n", approvals)
+	if len(status.Blockers) > 0 {
+		fmt.Fprintf(out, "Blocked by:   %s\n", util.ColorError(strings.Join(status.Blockers, ", ")))
+	}
+	queue := "not queued"
+	if status.MergeQueuePosition > 0 {
+		queue = fmt.Sprintf("%d of %d", status.MergeQueuePosition, status.MergeQueueLength)
+	}
+	if status.State == gits.PullRequestOpen {
+		fmt.Fprintf(out, "Merge queue:  %s\n", queue)
+	}
+	fmt.Fprintln(out)
+
+	if len(status.Contexts) == 0 {
+		fmt.Fprintln(out, "No pipeline contexts reported for the last commit")
+		return
+	}
+	table := o.CreateTable()
+	table.AddRow("CONTEXT", "STATE", "DESCRIPTION", "URL")
+	for _, c := range status.Contexts {
+		state := c.State
+		switch state {
+		case "success":
+			state = util.ColorInfo(state)
+		case "failure", "error":
+			state = util.ColorError(state)
+		default:
+			state = util.ColorWarning(state)
+		}
+		table.AddRow(c.Context, state, c.Description, c.TargetURL)
+	}
+	table.Render()
+}
+
+// latestContexts returns the latest status of each context sorted by context. Git providers return the
+// statuses of a commit newest first
+func latestContexts(statuses []*gits.GitRepoStatus) []PullRequestContext {
+	answer := []PullRequestContext{}
+	found := map[string]bool{}
+	for _, s := range statuses {
+		if s == nil || found[s.Context] {
+			continue
+		}
+		found[s.Context] = true
+		answer = append(answer, PullRequestContext{
+			Context:     s.Context,
+			State:       s.State,
+			Description: s.Description,
+			TargetURL:   s.TargetURL,
+		})
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Context < answer[j].Context
+	})
+	return answer
+}
+
+// pendingApprovals returns the required labels and requested reviews the Pull Request is missing along with the
+// labels which block it merging
+func pendingApprovals(pr *gits.GitPullRequest, requiredLabels []string, blockingLabels []string) ([]string, []string) {
+	labels := pullRequestLabels(pr)
+	pending := []string{}
+	for _, l := range requiredLabels {
+		if util.StringArrayIndex(labels, l) < 0 {
+			pending = append(pending, fmt.Sprintf("%s label", l))
+		}
+	}
+	for _, u := range pr.RequestedReviewers {
+		if u != nil && u.Login != "" {
+			pending = append(pending, fmt.Sprintf("review by @%s", u.Login))
+		}
+	}
+	blockers := []string{}
+	for _, l := range blockingLabels {
+		if util.StringArrayIndex(labels, l) >= 0 {
+			blockers = append(blockers, l)
+		}
+	}
+	return pending, blockers
+}
+
+// mergeQueuePosition returns the position of the Pull Request among the open Pull Requests of the repository
+// which can be merged, ordered by number as the merge queue does, and the length of the queue
+func mergeQueuePosition(provider gits.GitProvider, gitInfo *gits.GitRepository, number int, requiredLabels []string, blockingLabels []string) (int, int, error) {
+	prs, err := provider.ListOpenPullRequests(gitInfo.Organisation, gitInfo.Name)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "listing the open Pull Requests of %s/%s", gitInfo.Organisation, gitInfo.Name)
+	}
+	queue := []int{}
+	for _, p := range prs {
+		if p.Number == nil {
+			continue
+		}
+		pending, blockers := pendingApprovals(p, requiredLabels, blockingLabels)
+		if len(pending) > 0 || len(blockers) > 0 {
+			continue
+		}
+		status, err := provider.PullRequestLastCommitStatus(p)
+		if err != nil || status != "success" {
+			continue
+		}
+		queue = append(queue, *p.Number)
+	}
+	sort.Ints(queue)
+	for i, n := range queue {
+		if n == number {
+			return i + 1, len(queue), nil
+		}
+	}
+	return 0, len(queue), nil
+}
+
+func pullRequestLabels(pr *gits.GitPullRequest) []string {
+	answer := []string{}
+	for _, l := range pr.Labels {
+		if l != nil && l.Name != nil {
+			answer = append(answer, *l.Name)
+		}
+	}
+	return answer
+}
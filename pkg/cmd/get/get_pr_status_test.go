@@ -0,0 +1,110 @@
+// +build unit
+
+package get
+
+import (
+	"os"
+	"strconv"
+	"testing"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	jxfake "github.com/jenkins-x/jx-api/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/clients"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/testhelpers"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetPullRequestStatus(t *testing.T) {
+	repo, err := gits.NewFakeRepository("myorg", "myrepo", nil, nil)
+	require.NoError(t, err)
+	addPR := func(number int, status gits.CommitStatus, labels ...string) *gits.GitPullRequest {
+		sha := "sha" + strconv.Itoa(number)
+		pr := &gits.GitPullRequest{
+			URL:           "https://github.com/myorg/myrepo/pull/" + strconv.Itoa(number),
+			Owner:         "myorg",
+			Repo:          "myrepo",
+			Number:        &number,
+			State:         &gits.PullRequestOpen,
+			Title:         "change " + strconv.Itoa(number),
+			LastCommitSha: sha,
+		}
+		for i := range labels {
+			pr.Labels = append(pr.Labels, &gits.Label{Name: &labels[i]})
+		}
+		commit := &gits.FakeCommit{
+			Commit: &gits.GitCommit{SHA: sha, Message: "build " + string(status)},
+			Status: status,
+		}
+		repo.PullRequests[number] = &gits.FakePullRequest{PullRequest: pr, Commits: []*gits.FakeCommit{commit}}
+		repo.Commits = append(repo.Commits, commit)
+		return pr
+	}
+	addPR(1, gits.CommitSatusSuccess, "approved")
+	addPR(2, gits.CommitSatusSuccess, "approved", "lgtm")
+	pending := addPR(3, gits.CommitStatusPending)
+	pending.RequestedReviewers = []*gits.GitUser{{Login: "bob"}}
+	addPR(4, gits.CommitSatusSuccess, "approved", "do-not-merge/hold")
+	addPR(5, gits.CommitStatusFailure, "approved")
+
+	commonOpts := opts.NewCommonOptionsWithTerm(clients.NewFactory(), os.Stdin, &testhelpers.FakeOut{}, os.Stderr)
+	commonOpts.SetDevNamespace("jx")
+	commonOpts.SetFakeGitProvider(gits.NewFakeProvider(repo))
+	commonOpts.SetJxClient(jxfake.NewSimpleClientset(&v1.Environment{
+		ObjectMeta: metav1.ObjectMeta{Name: "myorg-myrepo-pr-2", Namespace: "jx"},
+		Spec: v1.EnvironmentSpec{
+			Kind: v1.EnvironmentKindTypePreview,
+			Source: v1.EnvironmentRepository{
+				URL: "https://github.com/myorg/myrepo.git",
+			},
+			PreviewGitSpec: v1.PreviewGitSpec{
+				Name:           "2",
+				ApplicationURL: "http://myrepo.jx-myorg-myrepo-pr-2.example.com",
+			},
+		},
+	}))
+	o := &GetPullRequestStatusOptions{
+		Options: Options{
+			CommonOptions: commonOpts,
+		},
+	}
+	gitInfo, err := gits.ParseGitURL("https://github.com/myorg/myrepo.git")
+	require.NoError(t, err)
+	provider, err := o.GitProviderForURL(gitInfo.URL, "test")
+	require.NoError(t, err)
+
+	status, err := o.PullRequestStatus(provider, gitInfo, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "open", status.State)
+	assert.Equal(t, "http://myrepo.jx-myorg-myrepo-pr-2.example.com", status.PreviewURL)
+	assert.Empty(t, status.PendingApprovals)
+	assert.Empty(t, status.Blockers)
+	assert.Equal(t, 2, status.MergeQueuePosition)
+	assert.Equal(t, 2, status.MergeQueueLength)
+	require.Len(t, status.Contexts, 1)
+	assert.Equal(t, "success", status.Contexts[0].State)
+
+	status, err = o.PullRequestStatus(provider, gitInfo, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "", status.PreviewURL)
+	assert.Equal(t, []string{"approved label", "review by @bob"}, status.PendingApprovals)
+	assert.Equal(t, 0, status.MergeQueuePosition)
+	assert.Equal(t, "pending", status.Contexts[0].State)
+
+	status, err = o.PullRequestStatus(provider, gitInfo, 4)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"do-not-merge/hold"}, status.Blockers)
+	assert.Equal(t, 0, status.MergeQueuePosition)
+
+	o.Args = []string{"PR-2"}
+	o.GitURL = "https://github.com/myorg/myrepo.git"
+	err = o.Run()
+	require.NoError(t, err)
+	output := commonOpts.Out.(*testhelpers.FakeOut).GetOutput()
+	assert.Contains(t, output, "#2 change 2")
+	assert.Contains(t, output, "http://myrepo.jx-myorg-myrepo-pr-2.example.com")
+	assert.Contains(t, output, "2 of 2")
+}
@@ -0,0 +1,125 @@
+package get
+
+import (
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/promotion"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// GetPromotionsOptions containers the CLI options
+type GetPromotionsOptions struct {
+	Options
+
+	Environment string
+	Application string
+	State       string
+}
+
+var (
+	getPromotionsLong = templates.LongDesc(`
+		Display the promotions to environments with gates and the state of their gates.
+
+		A promotion is only merged once all its gates are passed.
+` + helper.SeeAlsoText("jx approve promotion", "jx promote"))
+
+	getPromotionsExample = templates.Examples(`
+		# List all the promotions with gates
+		jx get promotions
+
+		# List the promotions waiting on their gates to production, such as the ones queued until its deployment
+		# window opens
+		jx get promotions --env production --state pending
+	`)
+)
+
+// NewCmdGetPromotions creates the new command for: jx get promotions
+func NewCmdGetPromotions(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetPromotionsOptions{
+		Options: Options{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "promotions",
+		Short:   "Display the promotions with gates and the state of their gates",
+		Aliases: []string{"promotion"},
+		Long:    getPromotionsLong,
+		Example: getPromotionsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Environment, "env", "e", "", "Filters the promotions to the environment")
+	cmd.Flags().StringVarP(&options.Application, "app", "a", "", "Filters the promotions of the application")
+	cmd.Flags().StringVarP(&options.State, "state", "s", "", "Filters the promotions by their state: pending, passed or failed")
+	options.AddGetFlags(cmd)
+	return cmd
+}
+
+// Run implements this command
+func (o *GetPromotionsOptions) Run() error {
+	states := []string{promotion.StatePending, promotion.StatePassed, promotion.StateFailed}
+	if o.State != "" && util.StringArrayIndex(states, o.State) < 0 {
+		return util.InvalidOption("state", o.State, states)
+	}
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	promotions, err := promotion.ListPromotions(kubeClient, ns)
+	if err != nil {
+		return err
+	}
+	answer := []*promotion.Promotion{}
+	for _, p := range promotions {
+		if (o.Environment == "" || p.Environment == o.Environment) &&
+			(o.Application == "" || p.Application == o.Application) &&
+			(o.State == "" || p.State() == o.State) {
+			answer = append(answer, p)
+		}
+	}
+	if o.Output != "" {
+		return o.RenderOutput(o.Output, answer)
+	}
+	if len(answer) == 0 {
+		log.Logger().Info("No promotions with gates found")
+		return nil
+	}
+
+	table := o.CreateTable()
+	table.AddRow("ID", "APPLICATION", "VERSION", "ENVIRONMENT", "STATE", "GATES", "PULL REQUEST", "DETAILS")
+	for _, p := range answer {
+		state := p.State()
+		switch state {
+		case promotion.StatePassed:
+			state = util.ColorInfo(state)
+		case promotion.StateFailed:
+			state = util.ColorError(state)
+		default:
+			state = util.ColorWarning(state)
+		}
+		table.AddRow(p.ID, p.Application, p.Version, p.Environment, state, p.Summary(), p.PullRequestURL, promotionDetails(p))
+	}
+	table.Render()
+	return nil
+}
+
+// promotionDetails describes the gates which have not passed such as when a queued promotion is merged
+func promotionDetails(p *promotion.Promotion) string {
+	details := []string{}
+	for _, g := range p.Gates {
+		if g.State != promotion.StatePassed && g.Description != "" {
+			details = append(details, g.Name+": "+g.Description)
+		}
+	}
+	return strings.Join(details, "; ")
+}
@@ -0,0 +1,78 @@
+package get
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// GetQuickstartCatalogsOptions containers the CLI options
+type GetQuickstartCatalogsOptions struct {
+	Options
+}
+
+var (
+	getQuickstartCatalogsLong = templates.LongDesc(`
+		Display the quickstart catalogs registered by the current Team.
+
+`)
+
+	getQuickstartCatalogsExample = templates.Examples(`
+		# List all the quickstart catalogs
+		jx get quickstartcatalogs
+
+		# List the quickstarts of a catalog
+		jx get quickstarts --catalog mycompany
+	`)
+)
+
+// NewCmdGetQuickstartCatalogs creates the new command for: jx get quickstartcatalogs
+func NewCmdGetQuickstartCatalogs(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetQuickstartCatalogsOptions{
+		Options: Options{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     opts.QuickStartCatalogCommandName,
+		Short:   "Display the quickstart catalogs of the team",
+		Aliases: opts.QuickStartCatalogCommandAliases,
+		Long:    getQuickstartCatalogsLong,
+		Example: getQuickstartCatalogsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	options.AddGetFlags(cmd)
+	return cmd
+}
+
+// Run implements this command
+func (o *GetQuickstartCatalogsOptions) Run() error {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	devEnv, err := kube.GetDevEnvironment(jxClient, ns)
+	if err != nil {
+		return err
+	}
+	catalogs, err := kube.GetQuickstartCatalogs(devEnv)
+	if err != nil {
+		return err
+	}
+
+	table := o.CreateTable()
+	table.AddRow("NAME", "SOURCE", "DESCRIPTION")
+	for i := range catalogs {
+		table.AddRow(catalogs[i].Name, catalogs[i].Source(), catalogs[i].Description)
+	}
+	table.Render()
+	return nil
+}
@@ -30,6 +30,9 @@ var (
 	getQuickstartsExample = templates.Examples(`
 		# List all the available quickstarts
 		jx get quickstarts
+
+		# List the quickstarts of a catalog registered by your team
+		jx get quickstarts --catalog mycompany
 	`)
 )
 
@@ -57,6 +60,7 @@ func NewCmdGetQuickstarts(commonOpts *opts.CommonOptions) *cobra.Command {
 
 	cmd.Flags().StringArrayVarP(&options.GitHubOrganisations, "organisations", "g", []string{}, "The GitHub organisations to query for quickstarts")
 	cmd.Flags().StringArrayVarP(&options.Filter.Tags, "tag", "t", []string{}, "The tags on the quickstarts to filter")
+	cmd.Flags().StringArrayVarP(&options.Filter.Catalogs, "catalog", "", []string{}, "The quickstart catalogs registered by the team to list instead of the default quickstarts")
 	cmd.Flags().StringVarP(&options.Filter.Text, "filter", "f", "", "The text filter")
 	cmd.Flags().StringVarP(&options.Filter.Owner, "owner", "", "", "The owner to filter on")
 	cmd.Flags().StringVarP(&options.Filter.Language, "language", "l", "", "The language to filter on")
@@ -70,7 +74,13 @@ func NewCmdGetQuickstarts(commonOpts *opts.CommonOptions) *cobra.Command {
 
 // Run implements this command
 func (o *GetQuickstartsOptions) Run() error {
-	model, err := o.LoadQuickStartsModel(o.GitHubOrganisations, o.IgnoreTeam)
+	var model *quickstarts.QuickstartModel
+	var err error
+	if len(o.Filter.Catalogs) > 0 {
+		model, err = o.LoadQuickStartCatalogsModel(o.Filter.Catalogs)
+	} else {
+		model, err = o.LoadQuickStartsModel(o.GitHubOrganisations, o.IgnoreTeam)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to load quickstarts: %s", err)
 	}
@@ -81,14 +91,14 @@ func (o *GetQuickstartsOptions) Run() error {
 	if o.ShortFormat {
 		table.AddRow("NAME")
 	} else {
-		table.AddRow("NAME", "OWNER", "VERSION", "LANGUAGE", "URL")
+		table.AddRow("NAME", "OWNER", "VERSION", "LANGUAGE", "CATALOG", "URL")
 	}
 
 	for _, qs := range filteredQuickstarts {
 		if o.ShortFormat {
 			table.AddRow(qs.Name)
 		} else {
-			table.AddRow(qs.Name, qs.Owner, qs.Version, qs.Language, qs.DownloadZipURL)
+			table.AddRow(qs.Name, qs.Owner, qs.Version, qs.Language, qs.Catalog, qs.DownloadZipURL)
 		}
 	}
 	table.Render()
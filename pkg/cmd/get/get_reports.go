@@ -0,0 +1,135 @@
+package get
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/testreports"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// GetReportsOptions containers the CLI options
+type GetReportsOptions struct {
+	Options
+}
+
+// TestsReport the summary of the tests of a build
+type TestsReport struct {
+	Pipeline string `json:"pipeline"`
+	Build    string `json:"build"`
+	testreports.Summary
+	Trend string `json:"trend,omitempty"`
+}
+
+var (
+	getReportsLong = templates.LongDesc(`
+		Display the summaries of the tests of the builds stored via 'jx step report tests' along with the trend
+		compared to the previous build of the same branch.
+`)
+
+	getReportsExample = templates.Examples(`
+		# List the test summaries of all the builds
+		jx get reports
+
+		# List the test summaries of the master branch of a repository
+		jx get reports myorg/myrepo/master
+
+		# View the test summaries as YAML
+		jx get reports myorg/myrepo -o yaml
+	`)
+)
+
+// NewCmdGetReports creates the new command for: jx get reports
+func NewCmdGetReports(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetReportsOptions{
+		Options: Options{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "reports [owner[/repository[/branch]]]",
+		Short:   "Display the summaries of the tests of the builds",
+		Aliases: []string{"report"},
+		Long:    getReportsLong,
+		Example: getReportsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	options.AddGetFlags(cmd)
+	return cmd
+}
+
+// Run implements this command
+func (o *GetReportsOptions) Run() error {
+	owner, repository, branch := "", "", ""
+	if len(o.Args) > 0 {
+		paths := strings.SplitN(o.Args[0], "/", 3)
+		owner = paths[0]
+		if len(paths) > 1 {
+			repository = paths[1]
+		}
+		if len(paths) > 2 {
+			branch = paths[2]
+		}
+	}
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	facts, err := testreports.ListFacts(jxClient, ns, owner, repository, branch)
+	if err != nil {
+		return err
+	}
+
+	reports := []TestsReport{}
+	var previous *TestsReport
+	for i := range facts {
+		l := facts[i].Labels
+		report := TestsReport{
+			Pipeline: fmt.Sprintf("%s/%s/%s", l[testreports.LabelOrg], l[testreports.LabelRepo], l[testreports.LabelBranch]),
+			Build:    l[testreports.LabelBuildNumber],
+			Summary:  *testreports.SummaryFromFact(&facts[i]),
+		}
+		if previous != nil && previous.Pipeline == report.Pipeline {
+			report.Trend = testreports.Trend(&report.Summary, &previous.Summary)
+		}
+		reports = append(reports, report)
+		previous = &reports[len(reports)-1]
+	}
+	if o.Output != "" {
+		return o.RenderOutput(o.Output, reports)
+	}
+	if len(reports) == 0 {
+		log.Logger().Info("No test reports found")
+		return nil
+	}
+
+	table := o.CreateTable()
+	table.AddRow("PIPELINE", "BUILD", "TESTS", "PASSED", "FAILED", "SKIPPED", "COVERAGE", "TREND")
+	for i := range reports {
+		r := &reports[i]
+		failed := strconv.Itoa(r.Failures + r.Errors)
+		if r.Succeeded() {
+			failed = util.ColorInfo(failed)
+		} else {
+			failed = util.ColorError(failed)
+		}
+		coverage := ""
+		if r.Coverage >= 0 {
+			coverage = fmt.Sprintf("%d%%", r.Coverage)
+		}
+		table.AddRow(r.Pipeline, r.Build, strconv.Itoa(r.Tests), strconv.Itoa(r.Passed()), failed, strconv.Itoa(r.Skipped), coverage, r.Trend)
+	}
+	table.Render()
+	return nil
+}
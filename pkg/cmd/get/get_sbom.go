@@ -0,0 +1,154 @@
+package get
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/sbom"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// GetSbomOptions containers the CLI options
+type GetSbomOptions struct {
+	Options
+
+	Env     string
+	Image   string
+	Version string
+	File    string
+
+	// SBOM downloads the SBOMs, defaults to the cosign binary
+	SBOM sbom.Interface
+}
+
+var (
+	getSbomLong = templates.LongDesc(`
+		Display the Software Bill of Materials (SBOM) of an application which was pushed alongside its image by 'jx step sbom'
+
+		The image of the application is the image of its deployment in the environment
+`)
+
+	getSbomExample = templates.Examples(`
+		# Display the SBOM of the application running in staging
+		jx get sbom myapp
+
+		# Save the SBOM of a version of the application running in production
+		jx get sbom myapp --env production --version 1.2.3 --file myapp.spdx.json
+
+		# Display the SBOM of an image
+		jx get sbom --image gcr.io/myorg/myapp:1.2.3
+	`)
+)
+
+// NewCmdGetSbom creates the new command for: jx get sbom
+func NewCmdGetSbom(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetSbomOptions{
+		Options: Options{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "sbom <app>",
+		Short:   "Display the Software Bill of Materials (SBOM) of an application",
+		Aliases: []string{"sboms"},
+		Long:    getSbomLong,
+		Example: getSbomExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Env, "env", "e", "staging", "The environment to find the image of the application in")
+	cmd.Flags().StringVarP(&options.Image, "image", "i", "", "The image to display the SBOM of instead of the image of the application")
+	cmd.Flags().StringVarP(&options.Version, "version", "v", "", "The version of the application if it is not the version running in the environment")
+	cmd.Flags().StringVarP(&options.File, "file", "f", "", "The file to save the SBOM to instead of displaying it")
+	return cmd
+}
+
+// Run implements this command
+func (o *GetSbomOptions) Run() error {
+	if o.SBOM == nil {
+		o.SBOM = sbom.NewCLI()
+	}
+	image := o.Image
+	if image == "" {
+		if len(o.Args) == 0 {
+			return util.MissingArgument("app")
+		}
+		var err error
+		image, err = o.applicationImage(o.Args[0])
+		if err != nil {
+			return err
+		}
+	}
+	if o.Version != "" {
+		image = imageWithTag(image, o.Version)
+	}
+
+	text, err := o.SBOM.Download(image)
+	if err != nil {
+		return err
+	}
+	if o.File == "" {
+		_, err = fmt.Fprintln(o.Out, text)
+		return err
+	}
+	err = ioutil.WriteFile(o.File, []byte(text), util.DefaultFileWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "writing %s", o.File)
+	}
+	log.Logger().Infof("Saved the SBOM of image %s to %s", util.ColorInfo(image), util.ColorInfo(o.File))
+	return nil
+}
+
+// applicationImage returns the image of the deployment of the application in the environment
+func (o *GetSbomOptions) applicationImage(app string) (string, error) {
+	jxClient, devNs, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return "", err
+	}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return "", err
+	}
+	ns, err := kube.GetEnvironmentNamespace(jxClient, devNs, o.Env)
+	if err != nil {
+		return "", err
+	}
+	deployments, err := kube.GetDeployments(kubeClient, ns)
+	if err != nil {
+		return "", errors.Wrapf(err, "listing the deployments in namespace %s", ns)
+	}
+	for name, d := range deployments {
+		if kube.GetAppName(name, ns) != app {
+			continue
+		}
+		containers := d.Spec.Template.Spec.Containers
+		if len(containers) == 0 {
+			break
+		}
+		return containers[0].Image, nil
+	}
+	return "", fmt.Errorf("no deployment found for application %s in environment %s", app, o.Env)
+}
+
+// imageWithTag replaces the tag or digest of the image
+func imageWithTag(image string, tag string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image + ":" + tag
+}
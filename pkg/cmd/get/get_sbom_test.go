@@ -0,0 +1,84 @@
+// +build unit
+
+package get_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	jxfake "github.com/jenkins-x/jx-api/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/clients/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/get"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+type fakeSBOM struct {
+	downloaded []string
+}
+
+func (f *fakeSBOM) Generate(image string, format string, file string) error {
+	return nil
+}
+
+func (f *fakeSBOM) Attach(image string, format string, file string) error {
+	return nil
+}
+
+func (f *fakeSBOM) Download(image string) (string, error) {
+	f.downloaded = append(f.downloaded, image)
+	return `{"spdxVersion": "SPDX-2.2"}`, nil
+}
+
+func TestGetSbom(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "jx-myapp", Namespace: "jx-staging"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "myapp", Image: "gcr.io/myorg/myapp:1.2.3"}},
+				},
+			},
+		},
+	}
+	commonOpts := opts.NewCommonOptionsWithFactory(fake.NewFakeFactory())
+	commonOpts.SetDevNamespace("jx")
+	commonOpts.SetKubeClient(kubefake.NewSimpleClientset(deployment))
+	commonOpts.SetJxClient(jxfake.NewSimpleClientset(&v1.Environment{
+		ObjectMeta: metav1.ObjectMeta{Name: "staging", Namespace: "jx"},
+		Spec:       v1.EnvironmentSpec{Namespace: "jx-staging", Kind: v1.EnvironmentKindTypePermanent},
+	}))
+	commonOpts.Out = os.Stdout
+
+	dir, err := ioutil.TempDir("", "test-get-sbom")
+	require.NoError(t, err)
+	generator := &fakeSBOM{}
+	o := &get.GetSbomOptions{
+		Options: get.Options{CommonOptions: &commonOpts},
+		Env:     "staging",
+		SBOM:    generator,
+	}
+	o.Args = []string{"myapp"}
+	err = o.Run()
+	require.NoError(t, err)
+
+	o.Version = "1.2.4"
+	o.File = filepath.Join(dir, "myapp.spdx.json")
+	err = o.Run()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gcr.io/myorg/myapp:1.2.3", "gcr.io/myorg/myapp:1.2.4"}, generator.downloaded)
+	data, err := ioutil.ReadFile(o.File)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "SPDX-2.2")
+
+	o.Args = []string{"unknown"}
+	assert.Error(t, o.Run(), "applications without a deployment should fail")
+}
@@ -0,0 +1,110 @@
+package get
+
+import (
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/scan"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// GetScansOptions containers the CLI options
+type GetScansOptions struct {
+	Options
+
+	Result string
+}
+
+var (
+	getScansLong = templates.LongDesc(`
+		Display the reports of the vulnerability scans of the images of the applications.
+` + helper.SeeAlsoText("jx step scan image", "jx edit scanpolicy"))
+
+	getScansExample = templates.Examples(`
+		# List the scans of all the applications
+		jx get scans
+
+		# List the failed scans of an application
+		jx get scans myapp --result failed
+
+		# View the vulnerabilities found by the scans
+		jx get scans myapp -o yaml
+	`)
+)
+
+// NewCmdGetScans creates the new command for: jx get scans
+func NewCmdGetScans(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetScansOptions{
+		Options: Options{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "scans [app]",
+		Short:   "Display the reports of the vulnerability scans of images",
+		Aliases: []string{"scan"},
+		Long:    getScansLong,
+		Example: getScansExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Result, "result", "r", "", "Filters the scans by their result: passed, warned or failed")
+	options.AddGetFlags(cmd)
+	return cmd
+}
+
+// Run implements this command
+func (o *GetScansOptions) Run() error {
+	results := []string{scan.ResultPassed, scan.ResultWarned, scan.ResultFailed}
+	if o.Result != "" && util.StringArrayIndex(results, o.Result) < 0 {
+		return util.InvalidOption("result", o.Result, results)
+	}
+	app := ""
+	if len(o.Args) > 0 {
+		app = o.Args[0]
+	}
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	reports, err := scan.ListReports(kubeClient, ns, app)
+	if err != nil {
+		return err
+	}
+	answer := []*scan.Report{}
+	for _, r := range reports {
+		if o.Result == "" || r.Result == o.Result {
+			answer = append(answer, r)
+		}
+	}
+	if o.Output != "" {
+		return o.RenderOutput(o.Output, answer)
+	}
+	if len(answer) == 0 {
+		log.Logger().Info("No scans found")
+		return nil
+	}
+
+	table := o.CreateTable()
+	table.AddRow("IMAGE", "APPLICATION", "VERSION", "SCANNER", "RESULT", "VULNERABILITIES", "CREATED")
+	for _, r := range answer {
+		result := r.Result
+		switch result {
+		case scan.ResultPassed:
+			result = util.ColorInfo(result)
+		case scan.ResultFailed:
+			result = util.ColorError(result)
+		default:
+			result = util.ColorWarning(result)
+		}
+		table.AddRow(r.Image, r.Application, r.Version, r.Scanner, result, r.Summary(), r.Created)
+	}
+	table.Render()
+	return nil
+}
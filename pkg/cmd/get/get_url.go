@@ -1,9 +1,16 @@
 package get
 
 import (
+	"fmt"
+	"time"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/jenkins-x/jx/v2/pkg/kube/services"
 	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/spf13/cobra"
 
@@ -15,20 +22,41 @@ import (
 type GetURLOptions struct {
 	Options
 
-	Namespace    string
-	Environment  string
-	OnlyViewHost bool
+	Namespace       string
+	Environment     string
+	OnlyViewHost    bool
+	AllEnvironments bool
+	Timeout         time.Duration
+	Concurrency     int
+}
+
+// EnvironmentURL a URL of a service in an environment and the result of probing it
+type EnvironmentURL struct {
+	Environment string `json:"environment,omitempty"`
+	Namespace   string `json:"namespace"`
+	Name        string `json:"name"`
+	services.URLProbe
 }
 
 var (
 	get_url_long = templates.LongDesc(`
 		Display one or more URLs from the running services.
 
+		Use --all-environments to list the URLs of the services in the namespaces of every staging, production and
+		preview environment. Each URL is then probed concurrently with an HTTP GET request to report its health and the
+		expiry of its TLS certificate.
+
 `)
 
 	get_url_example = templates.Examples(`
 		# List all URLs in this namespace
 		jx get url
+
+		# List the URLs of all the environments with their health and TLS certificate expiry
+		jx get url --all-environments
+
+		# Render the URLs of all the environments and their health as JSON
+		jx get url --all-environments -o json
 	`)
 )
 
@@ -61,10 +89,17 @@ func (o *GetURLOptions) AddGetUrlFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.Namespace, "namespace", "n", "", "Specifies the namespace name to look inside")
 	cmd.Flags().StringVarP(&o.Environment, "env", "e", "", "Specifies the Environment name to look inside")
 	cmd.Flags().BoolVarP(&o.OnlyViewHost, "host", "", false, "Only displays host names of the URLs and does not open the browser")
+	cmd.Flags().BoolVarP(&o.AllEnvironments, "all-environments", "a", false, "Lists the URLs of all the environments and probes their health")
+	cmd.Flags().DurationVarP(&o.Timeout, "timeout", "", services.DefaultProbeTimeout, "The timeout of probing each URL")
+	cmd.Flags().IntVarP(&o.Concurrency, "concurrency", "", services.DefaultProbeConcurrency, "The number of URLs probed concurrently")
+	o.AddOutputFlag(cmd, &o.Output)
 }
 
 // Run implements this command
 func (o *GetURLOptions) Run() error {
+	if o.AllEnvironments {
+		return o.runAllEnvironments()
+	}
 	client, ns, err := o.KubeClientAndNamespace()
 	if err != nil {
 		return err
@@ -81,6 +116,13 @@ func (o *GetURLOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	if o.Output != "" {
+		answer := []EnvironmentURL{}
+		for _, u := range urls {
+			answer = append(answer, EnvironmentURL{Environment: o.Environment, Namespace: ns, Name: u.Name, URLProbe: services.URLProbe{URL: u.URL}})
+		}
+		return o.RenderOutput(o.Output, answer)
+	}
 	table := o.CreateTable()
 	header := "URL"
 	if o.OnlyViewHost {
@@ -98,3 +140,99 @@ func (o *GetURLOptions) Run() error {
 	table.Render()
 	return nil
 }
+
+// runAllEnvironments lists and probes the URLs of the services of all the environments except the development
+// environment
+func (o *GetURLOptions) runAllEnvironments() error {
+	urls, err := o.FindEnvironmentURLs()
+	if err != nil {
+		return err
+	}
+	o.ProbeEnvironmentURLs(urls)
+	if o.Output != "" {
+		return o.RenderOutput(o.Output, urls)
+	}
+
+	table := o.CreateTable()
+	table.AddRow("ENVIRONMENT", "NAME", "URL", "STATUS", "LATENCY", "CERTIFICATE EXPIRY")
+	for _, u := range urls {
+		status := util.ColorInfo(fmt.Sprintf("%d", u.StatusCode))
+		if !u.Healthy {
+			status = util.ColorError(u.Error)
+		}
+		latency := ""
+		if u.Latency > 0 {
+			latency = u.Latency.String()
+		}
+		table.AddRow(u.Environment, u.Name, u.URL, status, latency, certificateExpiryString(u.CertificateExpiry))
+	}
+	table.Render()
+	return nil
+}
+
+// FindEnvironmentURLs finds the URLs of the services in the namespaces of the staging, production and preview
+// environments
+func (o *GetURLOptions) FindEnvironmentURLs() ([]EnvironmentURL, error) {
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "creating the kube client")
+	}
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return nil, errors.Wrap(err, "creating the jx client")
+	}
+	envs, err := jxClient.JenkinsV1().Environments(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the environments in namespace %s", ns)
+	}
+	environments := envs.Items
+	kube.SortEnvironments(environments)
+
+	answer := []EnvironmentURL{}
+	for _, env := range environments {
+		if env.Spec.Kind == v1.EnvironmentKindTypeDevelopment || env.Spec.Namespace == "" {
+			continue
+		}
+		urls, err := services.FindServiceURLs(kubeClient, env.Spec.Namespace)
+		if err != nil {
+			return nil, errors.Wrapf(err, "finding the URLs of environment %s", env.Name)
+		}
+		for _, u := range urls {
+			answer = append(answer, EnvironmentURL{
+				Environment: env.Name,
+				Namespace:   env.Spec.Namespace,
+				Name:        u.Name,
+				URLProbe:    services.URLProbe{URL: u.URL},
+			})
+		}
+	}
+	return answer, nil
+}
+
+// ProbeEnvironmentURLs probes the URLs concurrently recording their health
+func (o *GetURLOptions) ProbeEnvironmentURLs(urls []EnvironmentURL) {
+	list := []string{}
+	for _, u := range urls {
+		list = append(list, u.URL)
+	}
+	probes := services.ProbeURLs(list, o.Timeout, o.Concurrency)
+	for i := range urls {
+		urls[i].URLProbe = probes[i]
+	}
+}
+
+func certificateExpiryString(expiry *time.Time) string {
+	if expiry == nil {
+		return ""
+	}
+	text := expiry.Format("2006-01-02")
+	days := int(time.Until(*expiry).Hours() / 24)
+	switch {
+	case days < 0:
+		return util.ColorError(text + " (expired)")
+	case days < 14:
+		return util.ColorWarning(fmt.Sprintf("%s (%d days)", text, days))
+	default:
+		return fmt.Sprintf("%s (%d days)", text, days)
+	}
+}
@@ -0,0 +1,90 @@
+// +build unit
+
+package get_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	jxfake "github.com/jenkins-x/jx-api/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/clients/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/get"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/kube/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetURLAllEnvironments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	service := func(ns, name, url string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   ns,
+				Annotations: map[string]string{services.ExposeURLAnnotation: url},
+			},
+		}
+	}
+	environment := func(name, ns string, kind v1.EnvironmentKindType, order int32) *v1.Environment {
+		return &v1.Environment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "jx"},
+			Spec:       v1.EnvironmentSpec{Namespace: ns, Kind: kind, Order: order},
+		}
+	}
+
+	commonOpts := opts.NewCommonOptionsWithFactory(fake.NewFakeFactory())
+	commonOpts.SetDevNamespace("jx")
+	commonOpts.SetKubeClient(kubefake.NewSimpleClientset(
+		service("jx", "jenkins-x-chartmuseum", server.URL+"/dev"),
+		service("jx-staging", "myapp", server.URL+"/staging"),
+		service("jx-production", "myapp", server.URL+"/down"),
+	))
+	commonOpts.SetJxClient(jxfake.NewSimpleClientset(
+		environment("dev", "jx", v1.EnvironmentKindTypeDevelopment, 0),
+		environment("staging", "jx-staging", v1.EnvironmentKindTypePermanent, 100),
+		environment("production", "jx-production", v1.EnvironmentKindTypePermanent, 200),
+	))
+	out, err := ioutil.TempFile("", "get-url")
+	require.NoError(t, err)
+	defer os.Remove(out.Name())
+	commonOpts.Out = out
+
+	o := &get.GetURLOptions{
+		Options:         get.Options{CommonOptions: &commonOpts, Output: "json"},
+		AllEnvironments: true,
+	}
+	err = o.Run()
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(out.Name())
+	require.NoError(t, err)
+	urls := []get.EnvironmentURL{}
+	err = json.Unmarshal(data, &urls)
+	require.NoError(t, err, "output: %s", string(data))
+	require.Len(t, urls, 2, "the development environment should be skipped")
+
+	assert.Equal(t, "staging", urls[0].Environment)
+	assert.Equal(t, "jx-staging", urls[0].Namespace)
+	assert.Equal(t, server.URL+"/staging", urls[0].URL)
+	assert.True(t, urls[0].Healthy)
+	assert.Equal(t, http.StatusOK, urls[0].StatusCode)
+
+	assert.Equal(t, "production", urls[1].Environment)
+	assert.False(t, urls[1].Healthy)
+	assert.Equal(t, http.StatusBadGateway, urls[1].StatusCode)
+}
@@ -20,6 +20,7 @@ var (
 	// ErrDevEnvNotFound is an error representing when a dev environment can't be found.
 	ErrDevEnvNotFound = errors.New("the dev environment was not found")
 	fatalErrHandler   = Fatal
+	errorHook         func(error)
 )
 
 // BehaviorOnFatal allows you to override the default behavior when a fatal
@@ -34,6 +35,12 @@ func DefaultBehaviorOnFatal() {
 	fatalErrHandler = Fatal
 }
 
+// BehaviorOnError sets a function which is invoked with every error passed to CheckErr before it is reported so
+// that well known problems can be offered a fix. Passing nil removes the function
+func BehaviorOnError(f func(error)) {
+	errorHook = f
+}
+
 // Fatal prints the message (if provided) and then exits. If V(2) or greater,
 // glog.Logger().Fatal is invoked for extended information.
 func Fatal(msg string, code int) {
@@ -70,6 +77,9 @@ func checkErr(err error, handleErr func(string, int)) {
 		handleErr("", defaultErrorExitCode)
 		return
 	default:
+		if errorHook != nil {
+			errorHook(err)
+		}
 		switch err := err.(type) {
 		default: // for any other error type
 			msg, ok := StandardErrorMessage(err)
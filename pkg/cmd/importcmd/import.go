@@ -19,13 +19,16 @@ import (
 	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx-logging/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/buildpacks"
 	"github.com/jenkins-x/jx/v2/pkg/cloud/amazon"
+	"github.com/jenkins-x/jx/v2/pkg/cloudevents"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/edit"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/initcmd"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/start"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/config"
 	"github.com/jenkins-x/jx/v2/pkg/github"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/jenkins"
@@ -33,6 +36,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
 	"github.com/jenkins-x/jx/v2/pkg/prow"
+	"github.com/jenkins-x/jx/v2/pkg/registries"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -69,6 +73,8 @@ type ImportOptions struct {
 	ImportGitCommitMessage  string
 	ListDraftPacks          bool
 	DraftPack               string
+	Builder                 string
+	Modules                 []string
 	DockerRegistryOrg       string
 	GitDetails              gits.CreateRepoData
 	DeployKind              string
@@ -90,7 +96,9 @@ type ImportOptions struct {
 	UseDefaultGit         bool
 	GithubAppInstalled    bool
 	PreviewNamespace      string
+	LargeRepo             gits.LargeRepoOptions
 	reporter              ImportReporter
+	modules               []prow.Module
 }
 
 var (
@@ -116,6 +124,15 @@ var (
 		# Import a Git repository from a URL
 		jx import --url https://github.com/jenkins-x/spring-boot-web-example.git
 
+		# Import a large Git repository downloading file contents on demand and skipping Git LFS objects
+		jx import --url https://github.com/myorg/monorepo.git --clone-filter blob:none --lfs-skip-smudge
+
+		# Import the current folder building its image with Cloud Native Buildpacks instead of a Dockerfile
+		jx import --pack cnb
+
+		# Import the modules of a monorepo as separate applications
+		jx import --module services/api --module services/web
+
         # Select a number of repositories from a GitHub organisation
 		jx import --github --org myname 
 
@@ -158,6 +175,12 @@ func NewCmdImportAndOptions(commonOpts *opts.CommonOptions) (*cobra.Command, *Im
 	cmd.Flags().BoolVarP(&options.GitHub, "github", "", false, "If you wish to pick the repositories from GitHub to import")
 	cmd.Flags().BoolVarP(&options.SelectAll, "all", "", false, "If selecting projects to import from a Git provider this defaults to selecting them all")
 	cmd.Flags().StringVarP(&options.SelectFilter, "filter", "", "", "If selecting projects to import from a Git provider this filters the list of repositories")
+	largeRepo := gits.LargeRepoOptionsFromEnv()
+	cmd.Flags().BoolVarP(&options.LargeRepo.SkipLFSSmudge, "lfs-skip-smudge", "", largeRepo.SkipLFSSmudge, "Skips downloading Git LFS objects when cloning the repository")
+	cmd.Flags().StringArrayVarP(&options.LargeRepo.LFSInclude, "lfs-include", "", largeRepo.LFSInclude, "Only downloads the Git LFS objects matching these paths when cloning the repository")
+	cmd.Flags().StringVarP(&options.LargeRepo.Filter, "clone-filter", "", largeRepo.Filter, "The partial clone filter used when cloning the repository such as blob:none")
+	cmd.Flags().StringArrayVarP(&options.LargeRepo.SparseCheckout, "sparse-checkout", "", largeRepo.SparseCheckout, "Only checks out these paths when cloning the repository")
+	cmd.Flags().BoolVarP(&options.LargeRepo.Progress, "clone-progress", "", largeRepo.Progress, "Reports the progress when cloning the repository")
 	options.AddImportFlags(cmd, false)
 	options.Cmd = cmd
 	return cmd, options
@@ -180,7 +203,9 @@ func (options *ImportOptions) AddImportFlags(cmd *cobra.Command, createProject b
 	cmd.Flags().StringVarP(&options.ImportGitCommitMessage, "import-commit-message", "", "", "Specifies the initial commit message used when importing the project")
 	cmd.Flags().StringVarP(&options.BranchPattern, "branches", "", "", "The branch pattern for branches to trigger CI/CD pipelines on")
 	cmd.Flags().BoolVarP(&options.ListDraftPacks, "list-packs", "", false, "list available draft packs")
-	cmd.Flags().StringVarP(&options.DraftPack, "pack", "", "", "The name of the pack to use")
+	cmd.Flags().StringVarP(&options.DraftPack, "pack", "", "", fmt.Sprintf("The name of the pack to use. Use '%s' to build the image with Cloud Native Buildpacks instead of a Dockerfile", buildpacks.CNBPackName))
+	cmd.Flags().StringVarP(&options.Builder, "builder", "", "", fmt.Sprintf("The Cloud Native Buildpacks builder image when using '--pack %s'. Defaults to the builder of the project.toml file or of the detected language", buildpacks.CNBPackName))
+	cmd.Flags().StringArrayVarP(&options.Modules, "module", "", nil, "The directory of a module of a monorepo to import as a separate application with its own pipelines triggered by the changes in the directory. Modules are detected and picked in interactive mode if not specified")
 	cmd.Flags().StringVarP(&options.SchedulerName, "scheduler", "", "", "The name of the Scheduler configuration to use for ChatOps when using Prow")
 	cmd.Flags().StringVarP(&options.DockerRegistryOrg, "docker-registry-org", "", "", "The name of the docker registry organisation to use. If not specified then the Git provider organisation will be used")
 	cmd.Flags().StringVarP(&options.ExternalJenkinsBaseURL, "external-jenkins-url", "", "", "The jenkins url that an external git provider needs to use")
@@ -441,10 +466,16 @@ func (options *ImportOptions) Run() error {
 		}
 	}
 
-	_, err = kube.GetOrCreateSourceRepository(jxClient, ns, options.AppName, options.Organisation, gits.SourceRepositoryProviderURL(options.GitProvider))
+	providerURL := gits.SourceRepositoryProviderURL(options.GitProvider)
+	_, err = kube.GetOrCreateSourceRepository(jxClient, ns, options.AppName, options.Organisation, providerURL)
 	if err != nil {
 		return errors.Wrapf(err, "creating application resource for %s", util.ColorInfo(options.AppName))
 	}
+	options.EmitEvent(cloudevents.TypeAppCreated, options.Organisation+"/"+options.AppName, &cloudevents.AppData{
+		Name:        options.AppName,
+		Owner:       options.Organisation,
+		ProviderURL: providerURL,
+	})
 
 	if !options.GithubAppInstalled {
 		githubAppMode, err := options.IsGitHubAppMode()
@@ -528,30 +559,24 @@ func (options *ImportOptions) DraftCreate() error {
 	if !filepath.IsAbs(jenkinsfile) {
 		jenkinsfile = filepath.Join(dir, jenkinsfile)
 	}
-	args := &opts.InvokeDraftPack{
-		Dir:                     dir,
-		CustomDraftPack:         options.DraftPack,
-		Jenkinsfile:             jenkinsfile,
-		DefaultJenkinsfile:      defaultJenkinsfile,
-		WithRename:              withRename,
-		InitialisedGit:          options.InitialisedGit,
-		DisableJenkinsfileCheck: options.DisableJenkinsfileCheck,
-	}
-	options.DraftPack, err = options.InvokeDraftPack(args)
+	modules, err := options.selectModules()
 	if err != nil {
 		return err
 	}
-
-	// lets rename the chart to be the same as our app name
-	err = options.renameChartToMatchAppName()
-	if err != nil {
-		return err
-	}
-
-	err = options.modifyDeployKind()
-	err = options.modifyDeployKind()
-	if err != nil {
-		return err
+	if len(modules) > 0 {
+		settings, err := options.TeamSettings()
+		if err != nil {
+			return err
+		}
+		err = options.draftCreateModules(modules, settings)
+		if err != nil {
+			return err
+		}
+	} else {
+		err = options.draftCreate(dir, jenkinsfile, defaultJenkinsfile, withRename)
+		if err != nil {
+			return err
+		}
 	}
 
 	if options.PostDraftPackCallback != nil {
@@ -591,6 +616,10 @@ func (options *ImportOptions) DraftCreate() error {
 	}
 
 	dockerRegistryOrg := options.getDockerRegistryOrg()
+	err = options.replaceModulePlaceholders(gitServerName, dockerRegistryOrg)
+	if err != nil {
+		return err
+	}
 	err = options.ReplacePlaceholders(gitServerName, dockerRegistryOrg)
 	if err != nil {
 		return err
@@ -619,6 +648,53 @@ func (options *ImportOptions) DraftCreate() error {
 	return nil
 }
 
+// draftCreate applies the build pack to the repository as a single application
+func (options *ImportOptions) draftCreate(dir, jenkinsfile, defaultJenkinsfile string, withRename bool) error {
+	var err error
+	builder := ""
+	if options.DraftPack == buildpacks.CNBPackName {
+		builder, err = options.cnbBuilder()
+		if err != nil {
+			return err
+		}
+		// lets detect the language pack for the pipeline while the buildpacks build the image
+		options.DraftPack = ""
+	}
+	args := &opts.InvokeDraftPack{
+		Dir:                     dir,
+		CustomDraftPack:         options.DraftPack,
+		Jenkinsfile:             jenkinsfile,
+		DefaultJenkinsfile:      defaultJenkinsfile,
+		WithRename:              withRename,
+		InitialisedGit:          options.InitialisedGit,
+		DisableJenkinsfileCheck: options.DisableJenkinsfileCheck,
+		SkipDockerfile:          builder != "",
+	}
+	options.DraftPack, err = options.InvokeDraftPack(args)
+	if err != nil {
+		return err
+	}
+	if builder != "" {
+		err = options.configureCloudNativeBuildpacks(builder)
+		if err != nil {
+			return err
+		}
+	}
+
+	// lets rename the chart to be the same as our app name
+	err = options.renameChartToMatchAppName()
+	if err != nil {
+		return err
+	}
+
+	err = options.modifyDeployKind()
+	err = options.modifyDeployKind()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func (options *ImportOptions) getDockerRegistryOrg() string {
 	dockerRegistryOrg := options.DockerRegistryOrg
 	if dockerRegistryOrg == "" {
@@ -712,6 +788,15 @@ func (options *ImportOptions) CreateNewRemoteRepository() error {
 	repoURL := repo.HTMLURL
 	options.GetReporter().PushedGitRepository(repoURL)
 
+	devEnv, _, err := options.DevEnvAndTeamSettings()
+	if err != nil {
+		return errors.Wrap(err, "loading the team settings")
+	}
+	err = kube.ApplyBranchProtectionTemplate(devEnv, details.GitProvider, repo, "master")
+	if err != nil {
+		return errors.Wrapf(err, "protecting the repository %s", repoURL)
+	}
+
 	return options.AddBotAsCollaborator()
 }
 
@@ -792,7 +877,15 @@ func (options *ImportOptions) CloneRepository() error {
 	if err != nil {
 		return errors.Wrapf(err, "failed to create unique directory for '%s'", options.Dir)
 	}
-	err = options.Git().Clone(url, cloneDir)
+	gitter := options.Git()
+	if options.LargeRepo.Enabled() {
+		err = options.LargeRepo.Validate()
+		if err != nil {
+			return err
+		}
+		gitter = gits.WithLargeRepo(gitter, options.LargeRepo)
+	}
+	err = gitter.Clone(url, cloneDir)
 	if err != nil {
 		return errors.Wrapf(err, "failed to clone in directory '%s'", cloneDir)
 	}
@@ -832,7 +925,7 @@ func (options *ImportOptions) DiscoverGit() error {
 			Message: "Would you like to initialise git now?",
 			Default: true,
 		}
-		err := survey.AskOne(prompt, &flag, nil, surveyOpts)
+		err := util.AskOne(prompt, &flag, nil, surveyOpts)
 		if err != nil {
 			return err
 		}
@@ -873,7 +966,7 @@ func (options *ImportOptions) DiscoverGit() error {
 				Message: "Commit message: ",
 				Default: "Initial import",
 			}
-			err = survey.AskOne(messagePrompt, &message, nil, surveyOpts)
+			err = util.AskOne(messagePrompt, &message, nil, surveyOpts)
 			if err != nil {
 				return err
 			}
@@ -1028,7 +1121,16 @@ func (options *ImportOptions) addProwConfig(gitURL string, gitKind string) error
 		return err
 	}
 
+	triggerPaths, err := options.triggerPaths()
+	if err != nil {
+		return err
+	}
+
 	if settings.IsSchedulerMode() {
+		if len(options.modules) > 0 {
+			log.Logger().Warnf("the scheduler of the repository has to run the pipelines of the contexts of the modules %s when their directories change",
+				util.ColorInfo(moduleContexts(options.modules)))
+		}
 		jxClient, _, err := options.JXClient()
 		if err != nil {
 			return err
@@ -1046,6 +1148,14 @@ func (options *ImportOptions) addProwConfig(gitURL string, gitKind string) error
 			if sr.Spec.SSHCloneURL == "" {
 				sr.Spec.SSHCloneURL = gitInfo.SSHURL
 			}
+			if len(triggerPaths) > 0 {
+				if sr.Annotations == nil {
+					sr.Annotations = map[string]string{}
+				}
+				sr.Annotations[kube.AnnotationTriggerPaths] = strings.Join(triggerPaths, ",")
+			} else {
+				delete(sr.Annotations, kube.AnnotationTriggerPaths)
+			}
 		}
 		sr, err := kube.GetOrCreateSourceRepositoryCallback(jxClient, currentNamespace, gitInfo.Name, gitInfo.Organisation, gitInfo.HostURLWithoutUser(), callback)
 		log.Logger().Debugf("have SourceRepository: %s\n", sr.Name)
@@ -1100,6 +1210,16 @@ func (options *ImportOptions) addProwConfig(gitURL string, gitKind string) error
 		if err != nil {
 			return err
 		}
+	} else if len(options.modules) > 0 {
+		err = prow.AddApplicationModules(client, []string{repo}, currentNamespace, options.DraftPack, options.modules)
+		if err != nil {
+			return err
+		}
+	} else if len(triggerPaths) > 0 {
+		err = prow.AddApplicationWithRunIfChanged(client, []string{repo}, currentNamespace, options.DraftPack, config.TriggerPathsRegex(triggerPaths))
+		if err != nil {
+			return err
+		}
 	} else {
 		err = prow.AddApplication(client, []string{repo}, currentNamespace, options.DraftPack, settings)
 		if err != nil {
@@ -1123,6 +1243,18 @@ func (options *ImportOptions) addProwConfig(gitURL string, gitKind string) error
 	return nil
 }
 
+// triggerPaths returns the paths of the project configuration whose changes trigger the pipelines
+func (options *ImportOptions) triggerPaths() ([]string, error) {
+	projectConfig, _, err := config.LoadProjectConfig(options.Dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading the project configuration in %s", options.Dir)
+	}
+	if projectConfig.Trigger == nil {
+		return nil, nil
+	}
+	return projectConfig.Trigger.Paths, nil
+}
+
 // writeSourceRepoToYaml marshals a SourceRepository to the given directory, making sure it can be loaded by boot.
 func writeSourceRepoToYaml(dir string, sr *v1.SourceRepository) error {
 	outDir := filepath.Join(dir, "repositories", "templates")
@@ -1196,8 +1328,8 @@ func (options *ImportOptions) ensureDockerRepositoryExists() error {
 	if cm.Data != nil {
 		dockerRegistry := cm.Data["docker.registry"]
 		if dockerRegistry != "" {
-			if strings.HasSuffix(dockerRegistry, ".amazonaws.com") && strings.Index(dockerRegistry, ".ecr.") > 0 {
-				return amazon.LazyCreateRegistry(kubeClient, ns, region, dockerRegistry, options.getDockerRegistryOrg(), appName)
+			if registries.DetectKind(dockerRegistry) == registries.KindECR {
+				return amazon.EnsureRepository(kubeClient, ns, region, dockerRegistry, options.getDockerRegistryOrg(), appName)
 			}
 		}
 	}
@@ -1532,7 +1664,7 @@ func (options *ImportOptions) setPreviewNamespace() error {
 			Default: false,
 		}
 		var modifyPreviewNamespace bool
-		err := survey.AskOne(prompt, &modifyPreviewNamespace, nil, surveyOpts)
+		err := util.AskOne(prompt, &modifyPreviewNamespace, nil, surveyOpts)
 		if err != nil {
 			return err
 		}
@@ -1546,7 +1678,7 @@ func (options *ImportOptions) setPreviewNamespace() error {
 			Default: "jx-previews",
 		}
 
-		err = survey.AskOne(nsNamePrompt, &previewNamespaceName, func(ans interface{}) error {
+		err = util.AskOne(nsNamePrompt, &previewNamespaceName, func(ans interface{}) error {
 			return isValidPreviewNamespace(ans, envsList)
 		}, surveyOpts)
 		if err != nil {
@@ -1682,22 +1814,24 @@ func (options *ImportOptions) allDraftPacks() ([]string, error) {
 		CommonOptions: options.CommonOptions,
 	}
 	log.Logger().Debug("Getting latest packs ...")
-	dir, _, err := initOpts.InitBuildPacks(nil)
+	dirs, _, err := initOpts.InitBuildPacks(nil)
 	if err != nil {
 		return nil, err
 	}
 
-	files, err := ioutil.ReadDir(dir)
-	if err != nil {
-		return nil, err
-	}
 	result := make([]string, 0)
-	for _, f := range files {
-		if f.IsDir() {
-			result = append(result, f.Name())
+	for _, dir := range dirs {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			if f.IsDir() && util.StringArrayIndex(result, f.Name()) < 0 {
+				result = append(result, f.Name())
+			}
 		}
 	}
-	return result, err
+	return result, nil
 
 }
 
@@ -0,0 +1,48 @@
+package importcmd
+
+import (
+	"path/filepath"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/buildpacks"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/tekton/syntax"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// cnbBuilder returns the Cloud Native Buildpacks builder of the flag or detected from the source
+func (options *ImportOptions) cnbBuilder() (string, error) {
+	if options.Builder != "" {
+		return options.Builder, nil
+	}
+	builder, err := buildpacks.DetectCNBBuilder(options.Dir)
+	if err != nil {
+		return "", err
+	}
+	log.Logger().Infof("detected Cloud Native Buildpacks builder: %s", util.ColorInfo(builder))
+	return builder, nil
+}
+
+// configureCloudNativeBuildpacks configures the pipeline of the project to build its image with the lifecycle of the
+// given builder rather than the Dockerfile of the build pack
+func (options *ImportOptions) configureCloudNativeBuildpacks(builder string) error {
+	fileName := filepath.Join(options.Dir, config.ProjectConfigFileName)
+	projectConfig, err := config.LoadProjectConfigFile(fileName)
+	if err != nil {
+		return errors.Wrapf(err, "loading %s", fileName)
+	}
+	if projectConfig.BuildPack == "" {
+		projectConfig.BuildPack = options.DraftPack
+	}
+	projectConfig.GetOrCreatePipelineConfig().BuildEngine = &syntax.BuildEngine{
+		Kind:  syntax.BuildEngineCNB,
+		Image: builder,
+	}
+	err = projectConfig.SaveConfig(fileName)
+	if err != nil {
+		return errors.Wrapf(err, "saving %s", fileName)
+	}
+	log.Logger().Infof("the image of %s is built by Cloud Native Buildpacks with builder %s", options.DraftPack, util.ColorInfo(builder))
+	return nil
+}
@@ -0,0 +1,159 @@
+package importcmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/buildpacks"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
+	"github.com/jenkins-x/jx/v2/pkg/prow"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// selectModules returns the modules of the flags or the modules of a monorepo selected by the user
+func (options *ImportOptions) selectModules() ([]prow.Module, error) {
+	dirs := options.Modules
+	if len(dirs) == 0 {
+		if options.BatchMode {
+			return nil, nil
+		}
+		detected, err := buildpacks.DetectModules(options.Dir)
+		if err != nil {
+			return nil, err
+		}
+		if len(detected) == 0 {
+			return nil, nil
+		}
+		log.Logger().Infof("detected %d modules in %s", len(detected), util.ColorInfo(options.Dir))
+		dirs, err = util.SelectNames(detected, "Pick the modules to import as separate applications (none to import the repository as a single application):", false,
+			"Each module gets its own pipelines and chart which are only triggered by the changes in its directory", options.GetIOFileHandles())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return moduleNames(dirs), nil
+}
+
+// moduleNames returns the modules of the dirs named after their base dir unless it is not unique
+func moduleNames(dirs []string) []prow.Module {
+	counts := map[string]int{}
+	for _, dir := range dirs {
+		counts[filepath.Base(dir)]++
+	}
+	modules := []prow.Module{}
+	for _, dir := range dirs {
+		dir = strings.TrimSuffix(filepath.ToSlash(filepath.Clean(dir)), "/")
+		name := filepath.Base(dir)
+		if counts[name] > 1 {
+			name = strings.Replace(dir, "/", "-", -1)
+		}
+		modules = append(modules, prow.Module{Name: naming.ToValidName(name), Dir: dir})
+	}
+	return modules
+}
+
+// moduleContexts returns the pipeline contexts of the modules
+func moduleContexts(modules []prow.Module) string {
+	names := []string{}
+	for _, m := range modules {
+		names = append(names, m.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// draftCreateModules applies a build pack to each module of a monorepo generating its chart and the pipeline
+// configuration of its context in the root of the repository
+func (options *ImportOptions) draftCreateModules(modules []prow.Module, settings *v1.TeamSettings) error {
+	if settings.GetImportMode() != v1.ImportModeTypeYAML {
+		return errors.Errorf("importing the modules of a monorepo requires the %s import mode of the team", v1.ImportModeTypeYAML)
+	}
+	packs := []string{}
+	for _, m := range modules {
+		moduleOptions := options.moduleOptions(m)
+		exists, err := util.DirExists(moduleOptions.Dir)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return errors.Errorf("the module %s does not exist in %s", m.Dir, options.Dir)
+		}
+		args := &opts.InvokeDraftPack{
+			Dir:                     moduleOptions.Dir,
+			CustomDraftPack:         options.DraftPack,
+			InitialisedGit:          options.InitialisedGit,
+			DisableJenkinsfileCheck: true,
+		}
+		pack, err := options.InvokeDraftPack(args)
+		if err != nil {
+			return errors.Wrapf(err, "applying the build pack to module %s", m.Dir)
+		}
+		err = moduleOptions.renameChartToMatchAppName()
+		if err != nil {
+			return err
+		}
+		err = moduleOptions.modifyDeployKind()
+		if err != nil {
+			return err
+		}
+		err = options.saveModuleProjectConfig(m, pack)
+		if err != nil {
+			return err
+		}
+		log.Logger().Infof("module %s uses pack %s", util.ColorInfo(m.Dir), util.ColorInfo(pack))
+		packs = append(packs, pack)
+	}
+	options.DraftPack = strings.Join(packs, ",")
+	options.modules = modules
+	return nil
+}
+
+// moduleOptions returns the options to generate the files of the application of the module
+func (options *ImportOptions) moduleOptions(m prow.Module) *ImportOptions {
+	moduleOptions := *options
+	moduleOptions.Dir = filepath.Join(options.Dir, filepath.FromSlash(m.Dir))
+	moduleOptions.AppName = m.Name
+	return &moduleOptions
+}
+
+// saveModuleProjectConfig moves the project configuration generated in the module to the configuration of its
+// context in the root of the repository
+func (options *ImportOptions) saveModuleProjectConfig(m prow.Module, pack string) error {
+	moduleFile := filepath.Join(options.Dir, filepath.FromSlash(m.Dir), config.ProjectConfigFileName)
+	projectConfig, err := config.LoadProjectConfigFile(moduleFile)
+	if err != nil {
+		return errors.Wrapf(err, "loading %s", moduleFile)
+	}
+	if projectConfig.BuildPack == "" {
+		projectConfig.BuildPack = pack
+	}
+	projectConfig.Module = &config.ModuleConfig{Name: m.Name, Dir: m.Dir}
+	fileName := filepath.Join(options.Dir, fmt.Sprintf("jenkins-x-%s.yml", m.Name))
+	err = projectConfig.SaveConfig(fileName)
+	if err != nil {
+		return errors.Wrapf(err, "saving %s", fileName)
+	}
+	err = os.Remove(moduleFile)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "removing %s", moduleFile)
+	}
+	return nil
+}
+
+// replaceModulePlaceholders replaces the placeholders in the files of each module with the name of its application
+func (options *ImportOptions) replaceModulePlaceholders(gitServerName, dockerRegistryOrg string) error {
+	for _, m := range options.modules {
+		moduleOptions := options.moduleOptions(m)
+		err := moduleOptions.ReplacePlaceholders(gitServerName, dockerRegistryOrg)
+		if err != nil {
+			return errors.Wrapf(err, "replacing the placeholders of module %s", m.Dir)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+// +build unit
+
+package importcmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/prow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleNames(t *testing.T) {
+	t.Parallel()
+	modules := moduleNames([]string{"services/api", "services/Web/", "tools/api"})
+	assert.Equal(t, []prow.Module{
+		{Name: "services-api", Dir: "services/api"},
+		{Name: "web", Dir: "services/Web"},
+		{Name: "tools-api", Dir: "tools/api"},
+	}, modules)
+}
+
+func TestSaveModuleProjectConfig(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "import-modules")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	moduleDir := filepath.Join(dir, "services", "api")
+	require.NoError(t, os.MkdirAll(moduleDir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(moduleDir, config.ProjectConfigFileName), []byte("buildPack: go\n"), 0600))
+
+	options := &ImportOptions{Dir: dir}
+	err = options.saveModuleProjectConfig(prow.Module{Name: "api", Dir: "services/api"}, "go")
+	require.NoError(t, err)
+
+	assert.NoFileExists(t, filepath.Join(moduleDir, config.ProjectConfigFileName))
+	projectConfig, err := config.LoadProjectConfigFile(filepath.Join(dir, "jenkins-x-api.yml"))
+	require.NoError(t, err)
+	assert.Equal(t, "go", projectConfig.BuildPack)
+	assert.Equal(t, &config.ModuleConfig{Name: "api", Dir: "services/api"}, projectConfig.Module)
+}
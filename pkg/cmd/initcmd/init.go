@@ -3,6 +3,7 @@ package initcmd
 import (
 	"fmt"
 	"io/ioutil"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -16,11 +17,15 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/kube/services"
 
 	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/auth"
 	"github.com/jenkins-x/jx/v2/pkg/cloud/iks"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/helm"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/profiles"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -35,6 +40,16 @@ type InitOptions struct {
 	*opts.CommonOptions
 	Client clientset.Clientset
 	Flags  InitFlags
+	Output string
+	// Progress reports the progress of the long running steps. Created by Run if not set so that install can share
+	// its own
+	Progress *opts.Progress
+	// SkipPostInstallHooks skips running the post install hooks at the end of Run so that install can run them once
+	// Jenkins X is installed
+	SkipPostInstallHooks bool
+
+	kubernetesVersionVerified bool
+	versionsLocked            bool
 }
 
 // InitFlags the flags for running init
@@ -66,13 +81,39 @@ type InitFlags struct {
 	OnPremise                  bool
 	Http                       bool
 	NoGitValidate              bool
+	GitOrganisation            string
 	ExternalDNS                bool
+	Profile                    string
+	Config                     string
+	IgnoreK8sVersion           bool
+	RequirementsDir            string
+	LockVersions               bool
+}
+
+// InitSummary describes the initialised cluster when rendered via the output flag
+type InitSummary struct {
+	Provider         string `json:"provider"`
+	Namespace        string `json:"namespace"`
+	Domain           string `json:"domain,omitempty"`
+	ExternalIP       string `json:"externalIP,omitempty"`
+	IngressNamespace string `json:"ingressNamespace,omitempty"`
+	IngressService   string `json:"ingressService,omitempty"`
+	Helm3            bool   `json:"helm3"`
+	Tiller           bool   `json:"tiller"`
+	Profile          string `json:"profile,omitempty"`
+
+	Timings []opts.StepTiming `json:"timings,omitempty"`
 }
 
 const (
 	optionUsername        = "username"
 	optionNamespace       = "namespace"
 	optionTillerNamespace = "tiller-namespace"
+	optionProfile         = "profile"
+	optionConfig          = "config"
+
+	// OptionIgnoreK8sVersion the flag to only warn if the cluster Kubernetes version is not supported
+	OptionIgnoreK8sVersion = "ignore-k8s-version"
 
 	// JenkinsBuildPackURL URL of Draft packs for Jenkins X
 	JenkinsBuildPackURL = "https://github.com/jenkins-x/draft-packs.git"
@@ -85,6 +126,12 @@ var (
 
 	initExample = templates.Examples(`
 		jx init
+
+		# initialise the cluster and output a summary of the configuration as YAML
+		jx init -o yaml
+
+		# initialise the cluster created via 'jx create terraform --apply'
+		jx init --config terraform/jx-requirements.yml
 `)
 )
 
@@ -111,6 +158,10 @@ func NewCmdInit(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.Flags.Provider, "provider", "", "", "Cloud service providing the Kubernetes cluster.  Supported providers: "+cloud.KubernetesProviderOptions())
 	cmd.Flags().StringVarP(&options.Flags.Namespace, optionNamespace, "", "jx", "The namespace the Jenkins X platform should be installed into")
 	options.AddInitFlags(cmd)
+	cmd.Flags().StringVarP(&options.Flags.GitOrganisation, "git-organisation", "", "", "The Git provider organisation the environment repositories are created in. The pipeline git user is validated to be able to create repositories in it")
+	cmd.Flags().StringVarP(&options.Flags.Config, optionConfig, "", "", "The jx-requirements.yml file of the cluster, such as the one written by 'jx create terraform --apply', which defaults the provider, namespace and domain")
+	options.AddProfileFlag(cmd)
+	options.AddOutputFlag(cmd, &options.Output)
 	return cmd
 }
 
@@ -132,6 +183,104 @@ func (o *InitOptions) AddInitFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVarP(&o.Flags.ExternalDNS, "external-dns", "", false, "Installs external-dns into the cluster. ExternalDNS manages service DNS records for your cluster, providing you've setup your domain record")
 	cmd.Flags().BoolVarP(&o.Flags.Helm3, "helm3", "", opts.DefaultHelm3, "Use helm3 to install Jenkins X which does not use Tiller")
 	cmd.Flags().BoolVarP(&o.AdvancedMode, "advanced-mode", "", false, "Advanced install options. This will prompt for advanced install options")
+	cmd.Flags().StringVarP(&o.Flags.RequirementsDir, optionRequirementsDir, "", "", "The directory containing the jx-requirements.yml file which declares the post install hooks. Defaults to the current directory")
+	cmd.Flags().StringVarP(&o.VersionsBundle, optionVersionsBundle, "", "", "The version stream bundle created via 'jx step versionstream bundle' to use instead of cloning the version stream git repository")
+	cmd.Flags().BoolVarP(&o.Flags.LockVersions, optionLockVersions, "", false, "Records the resolved version stream versions in "+versionstream.VersionsLockFileName+" or if the file exists uses the locked versions so that installations are reproducible")
+	cmd.Flags().BoolVarP(&o.Flags.IgnoreK8sVersion, OptionIgnoreK8sVersion, "", false, "Only warn rather than fail if the Kubernetes version of the cluster is outside of the range tested by the version stream")
+}
+
+// AddProfileFlag adds the flag for the installation profile
+func (o *InitOptions) AddProfileFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.Flags.Profile, optionProfile, "", "", fmt.Sprintf("The installation profile which selects the default settings. Either one of %s, the name of a profile in ~/.jx/%s or the path of a profile YAML file. Export a profile to customize it via 'jx profile export'", strings.Join(profiles.BuiltinProfileNames(), ", "), profiles.ProfilesDir))
+}
+
+// ApplyProfile loads the installation profile if one is specified and applies its defaults to any init flags
+// which have not been explicitly set on the command line
+func (o *InitOptions) ApplyProfile() (*profiles.InstallProfile, error) {
+	if o.Flags.Profile == "" {
+		return nil, nil
+	}
+	profile, err := profiles.LoadProfile(o.Flags.Profile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading install profile %s", o.Flags.Profile)
+	}
+	if !o.FlagChanged("external-dns") && profile.ExternalDNS != nil {
+		o.Flags.ExternalDNS = *profile.ExternalDNS
+	}
+	return profile, nil
+}
+
+// ApplyConfig loads the requirements of the cluster if a config file is specified and applies them to any init flags
+// which have not been explicitly set on the command line
+func (o *InitOptions) ApplyConfig() error {
+	if o.Flags.Config == "" {
+		return nil
+	}
+	requirements, err := config.LoadRequirementsConfigFile(o.Flags.Config, config.DefaultFailOnValidationError)
+	if err != nil {
+		return errors.Wrapf(err, "loading the requirements %s", o.Flags.Config)
+	}
+	if !o.FlagChanged("provider") && requirements.Cluster.Provider != "" {
+		o.Flags.Provider = requirements.Cluster.Provider
+	}
+	if !o.FlagChanged(optionNamespace) && requirements.Cluster.Namespace != "" {
+		o.Flags.Namespace = requirements.Cluster.Namespace
+	}
+	if !o.FlagChanged("domain") && requirements.Ingress.Domain != "" {
+		o.Flags.Domain = requirements.Ingress.Domain
+	}
+	if !o.FlagChanged("external-dns") && requirements.Ingress.ExternalDNS {
+		o.Flags.ExternalDNS = true
+	}
+	if o.Flags.RequirementsDir == "" {
+		o.Flags.RequirementsDir = filepath.Dir(o.Flags.Config)
+	}
+	return nil
+}
+
+// VerifyKubernetesVersion checks the cluster is running a Kubernetes version supported by the version stream and
+// serves all the APIs the version stream charts use. Unless the ignore flag is enabled any incompatibility fails
+func (o *InitOptions) VerifyKubernetesVersion() error {
+	if o.kubernetesVersionVerified {
+		return nil
+	}
+	client, err := o.KubeClient()
+	if err != nil {
+		return errors.Wrap(err, "creating the kube client")
+	}
+	versionsDir, _, err := o.CloneJXVersionsRepo(o.Flags.VersionsRepository, o.Flags.VersionsGitRef)
+	if err != nil {
+		return errors.Wrap(err, "cloning the version stream")
+	}
+	versions, err := versionstream.GetKubernetesVersions(versionsDir)
+	if err != nil {
+		return err
+	}
+	problems, err := kube.VerifyKubernetesVersion(client.Discovery(), versions)
+	if err != nil {
+		return err
+	}
+	o.kubernetesVersionVerified = true
+	if len(problems) == 0 {
+		return nil
+	}
+	if o.Flags.IgnoreK8sVersion {
+		for _, problem := range problems {
+			log.Logger().Warnf("%s", problem)
+		}
+		log.Logger().Warnf("continuing as --%s is enabled", OptionIgnoreK8sVersion)
+		return nil
+	}
+	return fmt.Errorf("the cluster is not compatible with the version stream:\n  %s\nuse --%s to continue anyway", strings.Join(problems, "\n  "), OptionIgnoreK8sVersion)
+}
+
+// FlagChanged returns true if the given flag was explicitly set on the command line
+func (o *InitOptions) FlagChanged(name string) bool {
+	if o.Cmd == nil {
+		return false
+	}
+	flag := o.Cmd.Flags().Lookup(name)
+	return flag != nil && flag.Changed
 }
 
 func (o *InitOptions) AddIngressFlags(cmd *cobra.Command) {
@@ -185,7 +334,31 @@ func (o *InitOptions) checkOptions() error {
 
 // Run performs initialization
 func (o *InitOptions) Run() error {
-	var err error
+	o.RedirectLogsForOutput(o.Output)
+	ownProgress := o.Progress == nil
+	if ownProgress {
+		o.Progress = o.NewProgress()
+		if o.Output != "" {
+			// the spinner would end up in the rendered output
+			o.Progress.Interactive = false
+		}
+	}
+	_, err := o.ApplyProfile()
+	if err != nil {
+		return err
+	}
+	err = o.ApplyConfig()
+	if err != nil {
+		return err
+	}
+	err = o.LockVersionStream()
+	if err != nil {
+		return err
+	}
+	err = o.VerifyKubernetesVersion()
+	if err != nil {
+		return err
+	}
 	if !o.Flags.RemoteTiller || o.Flags.NoTiller {
 		o.Flags.HelmClient = true
 		o.Flags.SkipTiller = true
@@ -236,9 +409,11 @@ func (o *InitOptions) Run() error {
 		TillerRole:      o.Flags.TillerClusterRole,
 	}
 	// helm init, this has been seen to fail intermittently on public clouds, so let's retry a couple of times
-	err = o.Retry(3, 2*time.Second, func() (err error) {
-		err = o.InitHelm(cfg)
-		return
+	err = o.Progress.Step("Initialising helm", func() error {
+		return o.Retry(3, 2*time.Second, func() (err error) {
+			err = o.InitHelm(cfg)
+			return
+		})
 	})
 
 	if err != nil {
@@ -267,9 +442,40 @@ func (o *InitOptions) Run() error {
 		}
 	}
 
+	if !o.SkipPostInstallHooks {
+		err = o.RunPostInstallHooks()
+		if err != nil {
+			return err
+		}
+	}
+	if o.Output != "" {
+		return o.RenderOutput(o.Output, o.Summary())
+	}
+	if ownProgress {
+		o.Progress.LogTimings()
+	}
 	return nil
 }
 
+// Summary returns a summary of the configuration the cluster has been initialised with
+func (o *InitOptions) Summary() *InitSummary {
+	summary := &InitSummary{
+		Provider:  o.Flags.Provider,
+		Namespace: o.Flags.Namespace,
+		Domain:    o.Flags.Domain,
+		Helm3:     o.Flags.Helm3,
+		Tiller:    !o.Flags.NoTiller && !o.Flags.SkipTiller,
+		Profile:   o.Flags.Profile,
+		Timings:   o.Progress.Timings(),
+	}
+	if !o.Flags.SkipIngress {
+		summary.ExternalIP = o.Flags.ExternalIP
+		summary.IngressNamespace = o.Flags.IngressNamespace
+		summary.IngressService = o.Flags.IngressService
+	}
+	return summary
+}
+
 func (o *InitOptions) EnableClusterAdminRole() error {
 	if o.Flags.SkipClusterRole {
 		return nil
@@ -337,7 +543,7 @@ func (o *InitOptions) configureOptionsForExternalDNS() {
 			Help:    "",
 		}
 
-		survey.AskOne(prompt, &ExternalDNSDomain, nil, surveyOpts) //nolint:errcheck
+		util.AskOne(prompt, &ExternalDNSDomain, nil, surveyOpts) //nolint:errcheck
 
 		o.Flags.Domain = ExternalDNSDomain
 	}
@@ -394,7 +600,7 @@ func (o *InitOptions) configureForICP() {
 			Default: "", // Would be useful to set this as the public IP automatically
 			Help:    "",
 		}
-		survey.AskOne(prompt, &ICPExternalIP, nil, surveyOpts) //nolint:errcheck
+		util.AskOne(prompt, &ICPExternalIP, nil, surveyOpts) //nolint:errcheck
 
 		o.Flags.ExternalIP = ICPExternalIP
 
@@ -404,7 +610,7 @@ func (o *InitOptions) configureForICP() {
 			Help:    "",
 		}
 
-		survey.AskOne(prompt, &ICPDomain, nil, surveyOpts) //nolint:errcheck
+		util.AskOne(prompt, &ICPDomain, nil, surveyOpts) //nolint:errcheck
 
 		o.Flags.Domain = ICPDomain
 	}
@@ -471,7 +677,7 @@ func (o *InitOptions) InitIngress() error {
 				Default: true,
 				Help:    "An ingress controller works with an external loadbalancer so you can access Jenkins X and your applications",
 			}
-			err = survey.AskOne(prompt, &installIngressController, nil, surveyOpts)
+			err = util.AskOne(prompt, &installIngressController, nil, surveyOpts)
 			if err != nil {
 				return err
 			}
@@ -515,38 +721,44 @@ controller:
 			log.Logger().Infof("Using helm values file: %s", fileName)
 			valuesFiles = append(valuesFiles, fileName)
 		}
-		chartName := "stable/nginx-ingress"
+		chartName := opts.DefaultIngressChart
 
 		version, err := o.GetVersionNumber(versionstream.KindChart, chartName, o.Flags.VersionsRepository, o.Flags.VersionsGitRef)
 		if err != nil {
 			return errors.Wrapf(err, "failed to load version of chart %s", chartName)
 		}
 
-		i := 0
-		for {
-			log.Logger().Debugf("Installing using helm binary: %s", util.ColorInfo(o.Helm().HelmBinary()))
-			helmOptions := helm.InstallChartOptions{
-				Chart:       chartName,
-				ReleaseName: "jxing",
-				Version:     version,
-				Ns:          ingressNamespace,
-				SetValues:   values,
-				ValueFiles:  valuesFiles,
-				HelmUpdate:  true,
-			}
-			err = o.InstallChartWithOptions(helmOptions)
-			if err != nil {
-				if i >= 3 {
-					log.Logger().Errorf("Failed to install ingress chart: %s", err)
-					break
+		_ = o.Progress.Step("Installing the ingress controller chart", func() error {
+			i := 0
+			for {
+				o.Progress.SubStep("installing %s version %s", chartName, version)
+				log.Logger().Debugf("Installing using helm binary: %s", util.ColorInfo(o.Helm().HelmBinary()))
+				helmOptions := helm.InstallChartOptions{
+					Chart:       chartName,
+					ReleaseName: opts.DefaultIngressReleaseName,
+					Version:     version,
+					Ns:          ingressNamespace,
+					SetValues:   values,
+					ValueFiles:  valuesFiles,
+					HelmUpdate:  true,
+				}
+				err := o.InstallChartWithOptions(helmOptions)
+				if err != nil {
+					if i >= 3 {
+						log.Logger().Errorf("Failed to install ingress chart: %s", err)
+						return err
+					}
+					i++
+					time.Sleep(time.Second)
+				} else {
+					return nil
 				}
-				i++
-				time.Sleep(time.Second)
-			} else {
-				break
 			}
-		}
-		err = kube.WaitForDeploymentToBeReady(client, o.Flags.IngressDeployment, ingressNamespace, 10*time.Minute)
+		})
+		err = o.Progress.Step("Waiting for the ingress controller to be ready", func() error {
+			o.Progress.SubStep("deployment %s/%s", ingressNamespace, o.Flags.IngressDeployment)
+			return kube.WaitForDeploymentToBeReady(client, o.Flags.IngressDeployment, ingressNamespace, 10*time.Minute)
+		})
 		if err != nil {
 			return err
 		}
@@ -588,7 +800,10 @@ controller:
 		}
 
 		if externalIP == "" {
-			err = services.WaitForExternalIP(client, o.Flags.IngressService, ingressNamespace, 10*time.Minute)
+			err = o.Progress.Step("Waiting for the external loadbalancer", func() error {
+				o.Progress.SubStep("service %s/%s", ingressNamespace, o.Flags.IngressService)
+				return services.WaitForExternalIP(client, o.Flags.IngressService, ingressNamespace, 10*time.Minute)
+			})
 			if err != nil {
 				return err
 			}
@@ -646,6 +861,33 @@ func (o *InitOptions) ValidateGit() error {
 		}
 	}
 	log.Logger().Infof("Git configured for user: %s and email %s", util.ColorInfo(userName), util.ColorInfo(userEmail))
+
+	authConfigSvc, err := o.GitAuthConfigService()
+	if err != nil {
+		return errors.Wrap(err, "creating the git auth config service")
+	}
+	server, user := authConfigSvc.Config().GetPipelineAuth()
+	return o.ValidateGitAccess(server, user, o.Flags.GitOrganisation)
+}
+
+// ValidateGitAccess validates that the API token of the git user has the scopes and rate limit to install Jenkins X and
+// that the organisation exists and the user can create repositories in it. It is skipped if the user is not configured yet
+func (o *InitOptions) ValidateGitAccess(server *auth.AuthServer, user *auth.UserAuth, org string) error {
+	if server == nil || user == nil || user.IsInvalid() {
+		log.Logger().Debugf("skipping the validation of the git API token as the pipeline git user is not configured yet")
+		return nil
+	}
+	provider, err := gits.CreateProvider(server, user, o.Git())
+	if err != nil {
+		return errors.Wrapf(err, "creating the git provider for %s", server.URL)
+	}
+	err = gits.ValidateAccess(provider, org)
+	if err != nil {
+		return errors.Wrapf(err, "validating the API token of git user %s", user.Username)
+	}
+	if org != "" {
+		log.Logger().Infof("Git user %s can create repositories in the organisation %s on %s", util.ColorInfo(user.Username), util.ColorInfo(org), util.ColorInfo(server.URL))
+	}
 	return nil
 }
 
@@ -0,0 +1,148 @@
+package initcmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const optionRequirementsDir = "requirements-dir"
+
+// RunPostInstallHooks runs the post install hooks declared in the requirements file if there is one
+func (o *InitOptions) RunPostInstallHooks() error {
+	dir := o.Flags.RequirementsDir
+	if dir == "" {
+		dir = "."
+	}
+	fileName := filepath.Join(dir, config.RequirementsConfigFileName)
+	exists, err := util.FileExists(fileName)
+	if err != nil {
+		return errors.Wrapf(err, "checking if file %s exists", fileName)
+	}
+	if !exists {
+		if o.Flags.RequirementsDir != "" {
+			return fmt.Errorf("no %s file found in %s", config.RequirementsConfigFileName, dir)
+		}
+		return nil
+	}
+	requirements, err := config.LoadRequirementsConfigFile(fileName, config.DefaultFailOnValidationError)
+	if err != nil {
+		return errors.Wrapf(err, "loading requirements file %s", fileName)
+	}
+	if requirements.PostInstall == nil || len(requirements.PostInstall.Hooks) == 0 {
+		return nil
+	}
+	postInstall := requirements.PostInstall
+	hooks := postInstall.SortedHooks()
+	for i := range hooks {
+		err = hooks[i].Validate()
+		if err != nil {
+			return errors.Wrapf(err, "validating post install hooks in %s", fileName)
+		}
+	}
+	for i := range hooks {
+		hook := &hooks[i]
+		log.Logger().Infof("running post install hook %s", util.ColorInfo(hook.Name))
+		err = o.runPostInstallHook(postInstall, hook, dir)
+		if err != nil {
+			if hook.IgnoreFailure() {
+				log.Logger().Warnf("post install hook %s failed: %s", hook.Name, err.Error())
+				continue
+			}
+			return errors.Wrapf(err, "running post install hook %s", hook.Name)
+		}
+	}
+	return nil
+}
+
+// runPostInstallHook runs the hook. Relative manifest paths are resolved against the directory of the requirements file
+func (o *InitOptions) runPostInstallHook(postInstall *config.PostInstallConfig, hook *config.PostInstallHook, dir string) error {
+	ns := hook.Namespace
+	if ns == "" {
+		ns = o.Flags.Namespace
+	}
+	switch {
+	case hook.Chart != nil:
+		releaseName := hook.Chart.ReleaseName
+		if releaseName == "" {
+			releaseName = hook.Name
+		}
+		return o.InstallChartWithOptions(helm.InstallChartOptions{
+			ReleaseName:    releaseName,
+			Chart:          hook.Chart.Name,
+			Version:        hook.Chart.Version,
+			Repository:     hook.Chart.Repository,
+			Ns:             ns,
+			SetValues:      hook.Chart.Values,
+			HelmUpdate:     true,
+			VersionsGitURL: o.Flags.VersionsRepository,
+			VersionsGitRef: o.Flags.VersionsGitRef,
+		})
+	case hook.Script != nil:
+		return o.runPostInstallScript(postInstall, hook.Script, ns)
+	default:
+		for _, manifest := range hook.Manifests {
+			if !strings.Contains(manifest, "://") && !filepath.IsAbs(manifest) {
+				manifest = filepath.Join(dir, manifest)
+			}
+			args := append([]string{"apply"}, kube.ServerSideApplyArgs()...)
+			err := o.RunCommandVerbose("kubectl", append(args, "--namespace", ns, "-f", manifest)...)
+			if err != nil {
+				return errors.Wrapf(err, "applying manifest %s", manifest)
+			}
+		}
+		return nil
+	}
+}
+
+func (o *InitOptions) runPostInstallScript(postInstall *config.PostInstallConfig, script *config.PostInstallScript, ns string) error {
+	if !postInstall.IsTrustedURL(script.URL) {
+		return fmt.Errorf("script %s is not inside one of the trusted repositories %v", script.URL, postInstall.TrustedRepositories)
+	}
+	resp, err := util.GetClientWithTimeout(time.Minute).Get(script.URL)
+	if err != nil {
+		return errors.Wrapf(err, "downloading script %s", script.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("downloading script %s returned status %s", script.URL, resp.Status)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "reading script %s", script.URL)
+	}
+
+	tmpDir, err := ioutil.TempDir("", "jx-post-install-")
+	if err != nil {
+		return errors.Wrap(err, "creating temporary directory")
+	}
+	defer os.RemoveAll(tmpDir)
+	fileName := filepath.Join(tmpDir, filepath.Base(script.URL))
+	err = ioutil.WriteFile(fileName, data, 0700)
+	if err != nil {
+		return errors.Wrapf(err, "saving script %s", fileName)
+	}
+
+	cmd := util.Command{
+		Name: fileName,
+		Args: script.Args,
+		Out:  o.Out,
+		Err:  o.Err,
+		Env: map[string]string{
+			"JX_NAMESPACE": ns,
+		},
+	}
+	_, err = cmd.RunWithoutRetry()
+	return err
+}
@@ -0,0 +1,73 @@
+package initcmd
+
+import (
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/jenkins-x/jx/v2/pkg/versionstream"
+	"github.com/pkg/errors"
+)
+
+const (
+	optionVersionsBundle = "versions-bundle"
+	optionLockVersions   = "lock-versions"
+)
+
+// LockVersionStream when locking is enabled pins the version stream to the commit recorded in the lock file and
+// verifies it resolves the same versions. If there is no lock file yet one is created from the current version stream
+func (o *InitOptions) LockVersionStream() error {
+	if !o.Flags.LockVersions || o.versionsLocked {
+		return nil
+	}
+	fileName := versionstream.VersionsLockFileName
+	exists, err := util.FileExists(fileName)
+	if err != nil {
+		return errors.Wrapf(err, "checking if file %s exists", fileName)
+	}
+	var locked *versionstream.VersionsLock
+	if exists {
+		locked, err = versionstream.LoadVersionsLock(fileName)
+		if err != nil {
+			return err
+		}
+		if o.VersionsBundle == "" {
+			if o.Flags.VersionsRepository == "" {
+				o.Flags.VersionsRepository = locked.URL
+			}
+			if locked.Commit != "" {
+				o.Flags.VersionsGitRef = locked.Commit
+			}
+		}
+	}
+
+	versionsDir, versionsRef, err := o.CloneJXVersionsRepo(o.Flags.VersionsRepository, o.Flags.VersionsGitRef)
+	if err != nil {
+		return errors.Wrap(err, "cloning the version stream")
+	}
+	actual, err := versionstream.CreateVersionsLock(versionsDir)
+	if err != nil {
+		return err
+	}
+	metadata, err := o.VersionStreamMetadata(versionsDir, o.Flags.VersionsRepository, versionsRef)
+	if err != nil {
+		return err
+	}
+	actual.URL = metadata.URL
+	actual.Ref = metadata.Ref
+	actual.Commit = metadata.Commit
+	o.versionsLocked = true
+
+	if locked != nil {
+		err = locked.Verify(actual)
+		if err != nil {
+			return errors.Wrapf(err, "remove %s to update the locked versions", fileName)
+		}
+		log.Logger().Infof("using the version stream versions locked in %s", util.ColorInfo(fileName))
+		return nil
+	}
+	err = actual.SaveFile(fileName)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("locked the version stream versions to %s", util.ColorInfo(fileName))
+	return nil
+}
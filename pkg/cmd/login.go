@@ -0,0 +1,330 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/kube/oidc"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/browser"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	optionIssuerURL    = "issuer-url"
+	optionClientID     = "client-id"
+	optionClientSecret = "client-secret"
+	optionScopes       = "scopes"
+
+	// oidcAuthProvider the name of the kubectl auth provider whose settings are reused by jx login
+	oidcAuthProvider = "oidc"
+)
+
+// LoginOptions the options of the login command
+type LoginOptions struct {
+	*opts.CommonOptions
+
+	OIDC           bool
+	IssuerURL      string
+	ClientID       string
+	ClientSecret   string
+	Scopes         []string
+	Context        string
+	User           string
+	Device         bool
+	Port           int
+	Timeout        time.Duration
+	ExecCredential bool
+
+	openBrowser func(string) error
+	cacheDir    func() (string, error)
+}
+
+var (
+	login_long = templates.LongDesc(`
+		Logs into the Kubernetes cluster of a kube context with the OpenID Connect identity provider the cluster trusts
+		such as Dex, Keycloak or Azure AD.
+
+		The login uses the device flow if --device is specified or the identity provider only supports it otherwise it
+		opens the login page of the identity provider in the browser. The context is then configured to get its
+		credentials from jx which refreshes the ID token as it expires.
+
+		The issuer URL, client ID and client secret default to the settings of a context which already uses the oidc
+		auth provider of kubectl or was logged into before.`)
+
+	login_example = templates.Examples(`
+		# log into the current context with Dex
+		jx login --oidc --issuer-url https://dex.example.com --client-id kubernetes
+
+		# log into a context from a machine without a browser
+		jx login --oidc --device --context prod --issuer-url https://keycloak.example.com/auth/realms/jx --client-id kubernetes
+
+		# log into Azure AD requesting the groups of the user
+		jx login --oidc --issuer-url https://login.microsoftonline.com/<tenant>/v2.0 --client-id <app> --scopes openid,profile,offline_access,groups`)
+)
+
+// NewCmdLogin creates the command
+func NewCmdLogin(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &LoginOptions{
+		CommonOptions: commonOpts,
+	}
+	cmd := &cobra.Command{
+		Use:     "login",
+		Short:   "Logs into a Kubernetes cluster with its OpenID Connect identity provider",
+		Long:    login_long,
+		Example: login_example,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().BoolVarP(&options.OIDC, "oidc", "", false, "Log in with the OpenID Connect identity provider of the cluster")
+	cmd.Flags().StringVarP(&options.IssuerURL, optionIssuerURL, "", "", "The issuer URL of the identity provider")
+	cmd.Flags().StringVarP(&options.ClientID, optionClientID, "", "", "The ID of the OAuth client the API server trusts")
+	cmd.Flags().StringVarP(&options.ClientSecret, optionClientSecret, "", "", "The secret of the OAuth client if it is confidential")
+	cmd.Flags().StringSliceVarP(&options.Scopes, optionScopes, "", nil, fmt.Sprintf("The scopes to request. Defaults to %s", strings.Join(oidc.DefaultScopes, ",")))
+	cmd.Flags().StringVarP(&options.Context, "context", "c", "", "The kube context to log into. Defaults to the current context")
+	cmd.Flags().StringVarP(&options.User, "user", "u", "", "The name of the kube config user of the credentials. Defaults to <context>-oidc")
+	cmd.Flags().BoolVarP(&options.Device, "device", "", false, "Use the device flow which prints a code to confirm in a browser on any device")
+	cmd.Flags().IntVarP(&options.Port, "port", "", 8000, "The local port of the redirect URI of the browser login which must be registered with the client")
+	cmd.Flags().DurationVarP(&options.Timeout, "timeout", "t", 5*time.Minute, "The time to wait for the browser login")
+	cmd.Flags().BoolVarP(&options.ExecCredential, "exec-credential", "", false, "Write the exec credential of the kubectl exec plugin refreshing the cached ID token")
+	_ = cmd.Flags().MarkHidden("exec-credential")
+	return cmd
+}
+
+// Run implements the command
+func (o *LoginOptions) Run() error {
+	if !o.OIDC {
+		return fmt.Errorf("only the OpenID Connect login is supported, please specify --oidc")
+	}
+	if o.ExecCredential {
+		return o.writeExecCredential()
+	}
+
+	config, po, err := o.Kube().LoadConfig()
+	if err != nil {
+		return errors.Wrap(err, "loading the kube config")
+	}
+	if config == nil {
+		return fmt.Errorf("there is no kube config")
+	}
+	ctxName := o.Context
+	if ctxName == "" {
+		ctxName = config.CurrentContext
+	}
+	ctx := config.Contexts[ctxName]
+	if ctx == nil {
+		return fmt.Errorf("the kube context %s does not exist", ctxName)
+	}
+	o.defaultFromAuthInfo(config.AuthInfos[ctx.AuthInfo])
+	client, err := o.client()
+	if err != nil {
+		return err
+	}
+	provider, err := client.Discover()
+	if err != nil {
+		return err
+	}
+
+	var token *oidc.Token
+	if o.Device || provider.AuthorizationEndpoint == "" {
+		token, err = o.deviceLogin(client, provider)
+	} else {
+		token, err = client.BrowserLogin(provider, o.Port, o.open, o.Timeout)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "logging into %s", o.IssuerURL)
+	}
+	cacheFile, err := o.tokenCacheFile()
+	if err != nil {
+		return err
+	}
+	err = oidc.SaveToken(cacheFile, token)
+	if err != nil {
+		return err
+	}
+
+	user := o.User
+	if user == "" {
+		user = ctxName + "-oidc"
+	}
+	setExecCredentials(config, ctxName, user, o.execArgs())
+	err = clientcmd.ModifyConfig(po, *config, false)
+	if err != nil {
+		return errors.Wrap(err, "updating the kube config")
+	}
+	username := ""
+	claims, err := oidc.ParseClaims(token.IDToken)
+	if err == nil {
+		username = claims.Username()
+	}
+	log.Logger().Infof("Logged in as %s, the kube context %s now uses the credentials of user %s", util.ColorInfo(username), util.ColorInfo(ctxName), util.ColorInfo(user))
+	return nil
+}
+
+func (o *LoginOptions) deviceLogin(client *oidc.Client, provider *oidc.Provider) (*oidc.Token, error) {
+	auth, err := client.StartDeviceLogin(provider)
+	if err != nil {
+		return nil, err
+	}
+	log.Logger().Infof("To log in open %s and enter the code %s", util.ColorInfo(auth.URI()), util.ColorInfo(auth.UserCode))
+	return client.WaitForDeviceLogin(provider, auth, time.Sleep)
+}
+
+// writeExecCredential writes the cached ID token as the output of the kubectl exec plugin refreshing it if it expired
+func (o *LoginOptions) writeExecCredential() error {
+	client, err := o.client()
+	if err != nil {
+		return err
+	}
+	cacheFile, err := o.tokenCacheFile()
+	if err != nil {
+		return err
+	}
+	token, err := oidc.LoadToken(cacheFile)
+	if err != nil {
+		return err
+	}
+	if !token.Valid() {
+		if token == nil || token.RefreshToken == "" {
+			return fmt.Errorf("not logged into %s, please run: jx login --oidc", o.IssuerURL)
+		}
+		provider, err := client.Discover()
+		if err != nil {
+			return err
+		}
+		token, err = client.Refresh(provider, token)
+		if err != nil {
+			return errors.Wrapf(err, "refreshing the ID token, please run: jx login --oidc")
+		}
+		err = oidc.SaveToken(cacheFile, token)
+		if err != nil {
+			return err
+		}
+	}
+	data, err := oidc.ExecCredential(token)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(o.Out, string(data))
+	return err
+}
+
+// defaultFromAuthInfo defaults the settings of the identity provider from the user of the context if it uses the
+// oidc auth provider of kubectl or the credentials of a previous login
+func (o *LoginOptions) defaultFromAuthInfo(authInfo *api.AuthInfo) {
+	if authInfo == nil {
+		return
+	}
+	if authInfo.AuthProvider != nil && authInfo.AuthProvider.Name == oidcAuthProvider {
+		cfg := authInfo.AuthProvider.Config
+		o.defaultSettings(cfg["idp-issuer-url"], cfg["client-id"], cfg["client-secret"], cfg["extra-scopes"])
+	}
+	if authInfo.Exec != nil && util.StringArrayIndex(authInfo.Exec.Args, "--exec-credential") >= 0 {
+		args := map[string]string{}
+		for i := 0; i+1 < len(authInfo.Exec.Args); i++ {
+			if strings.HasPrefix(authInfo.Exec.Args[i], "--") {
+				args[strings.TrimPrefix(authInfo.Exec.Args[i], "--")] = authInfo.Exec.Args[i+1]
+			}
+		}
+		o.defaultSettings(args[optionIssuerURL], args[optionClientID], args[optionClientSecret], args[optionScopes])
+	}
+}
+
+func (o *LoginOptions) defaultSettings(issuerURL string, clientID string, clientSecret string, scopes string) {
+	if o.IssuerURL == "" {
+		o.IssuerURL = issuerURL
+	}
+	if o.ClientID == "" {
+		o.ClientID = clientID
+	}
+	if o.ClientSecret == "" {
+		o.ClientSecret = clientSecret
+	}
+	if len(o.Scopes) == 0 && scopes != "" {
+		o.Scopes = strings.Split(scopes, ",")
+	}
+}
+
+func (o *LoginOptions) client() (*oidc.Client, error) {
+	if o.IssuerURL == "" {
+		return nil, util.MissingOption(optionIssuerURL)
+	}
+	if o.ClientID == "" {
+		return nil, util.MissingOption(optionClientID)
+	}
+	return &oidc.Client{
+		IssuerURL:    o.IssuerURL,
+		ClientID:     o.ClientID,
+		ClientSecret: o.ClientSecret,
+		Scopes:       o.Scopes,
+	}, nil
+}
+
+// execArgs returns the arguments of jx as the exec plugin of the context
+func (o *LoginOptions) execArgs() []string {
+	args := []string{"login", "--oidc", "--exec-credential", "--batch-mode",
+		"--" + optionIssuerURL, o.IssuerURL,
+		"--" + optionClientID, o.ClientID,
+	}
+	if o.ClientSecret != "" {
+		args = append(args, "--"+optionClientSecret, o.ClientSecret)
+	}
+	if len(o.Scopes) > 0 {
+		args = append(args, "--"+optionScopes, strings.Join(o.Scopes, ","))
+	}
+	return args
+}
+
+func (o *LoginOptions) tokenCacheFile() (string, error) {
+	cacheDir := o.cacheDir
+	if cacheDir == nil {
+		cacheDir = util.ConfigDir
+	}
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+	return oidc.TokenCacheFile(dir, o.IssuerURL, o.ClientID), nil
+}
+
+func (o *LoginOptions) open(u string) error {
+	log.Logger().Infof("Opening the login page %s", util.ColorInfo(u))
+	openBrowser := o.openBrowser
+	if openBrowser == nil {
+		openBrowser = browser.OpenURL
+	}
+	err := openBrowser(u)
+	if err != nil {
+		log.Logger().Warnf("Failed to open the browser, please open the login page manually: %s", err)
+	}
+	return nil
+}
+
+// setExecCredentials configures the user of the context to get its credentials from the jx exec plugin
+func setExecCredentials(config *api.Config, ctxName string, user string, args []string) {
+	authInfo := config.AuthInfos[user]
+	if authInfo == nil {
+		authInfo = api.NewAuthInfo()
+		config.AuthInfos[user] = authInfo
+	}
+	authInfo.AuthProvider = nil
+	authInfo.Token = ""
+	authInfo.Exec = &api.ExecConfig{
+		APIVersion: oidc.ExecCredentialAPIVersion,
+		Command:    "jx",
+		Args:       args,
+	}
+	config.Contexts[ctxName].AuthInfo = user
+}
@@ -0,0 +1,54 @@
+// +build unit
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestLoginDefaultsFromOIDCAuthProvider(t *testing.T) {
+	o := &LoginOptions{ClientID: "override"}
+	o.defaultFromAuthInfo(&api.AuthInfo{
+		AuthProvider: &api.AuthProviderConfig{
+			Name: "oidc",
+			Config: map[string]string{
+				"idp-issuer-url": "https://dex.example.com",
+				"client-id":      "kubernetes",
+				"client-secret":  "secret",
+				"extra-scopes":   "groups",
+			},
+		},
+	})
+	assert.Equal(t, "https://dex.example.com", o.IssuerURL)
+	assert.Equal(t, "override", o.ClientID)
+	assert.Equal(t, "secret", o.ClientSecret)
+	assert.Equal(t, []string{"groups"}, o.Scopes)
+}
+
+func TestLoginSetsExecCredentials(t *testing.T) {
+	o := &LoginOptions{IssuerURL: "https://dex.example.com", ClientID: "kubernetes", Scopes: []string{"openid", "groups"}}
+	config := api.NewConfig()
+	config.Contexts["prod"] = &api.Context{Cluster: "prod", AuthInfo: "admin"}
+	config.AuthInfos["admin"] = &api.AuthInfo{Token: "static"}
+
+	setExecCredentials(config, "prod", "prod-oidc", o.execArgs())
+
+	assert.Equal(t, "prod-oidc", config.Contexts["prod"].AuthInfo)
+	assert.Equal(t, "static", config.AuthInfos["admin"].Token)
+	exec := config.AuthInfos["prod-oidc"].Exec
+	if assert.NotNil(t, exec) {
+		assert.Equal(t, "jx", exec.Command)
+		assert.Equal(t, []string{"login", "--oidc", "--exec-credential", "--batch-mode",
+			"--issuer-url", "https://dex.example.com", "--client-id", "kubernetes", "--scopes", "openid,groups"}, exec.Args)
+	}
+
+	// logging in again reuses the settings of the exec plugin
+	again := &LoginOptions{}
+	again.defaultFromAuthInfo(config.AuthInfos["prod-oidc"])
+	assert.Equal(t, o.IssuerURL, again.IssuerURL)
+	assert.Equal(t, o.ClientID, again.ClientID)
+	assert.Equal(t, o.Scopes, again.Scopes)
+}
@@ -3,26 +3,32 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/jenkins-x/jx-logging/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 type LogsOptions struct {
 	*opts.CommonOptions
 
-	Container       string
-	Namespace       string
-	Environment     string
-	Filter          string
-	Label           string
-	EditEnvironment bool
+	Container         string
+	Namespace         string
+	Environment       string
+	Filter            string
+	Label             string
+	EditEnvironment   bool
+	Access            bool
+	IngressNamespace  string
+	IngressDeployment string
 }
 
 var (
@@ -37,6 +43,9 @@ var (
 
 		# Tails the log of the container foo in the latest pod in deployment myapp
 		jx logs myapp -c foo
+
+		# Tails the ingress controller access logs of myapp in the staging environment
+		jx logs myapp --access -e staging
 `)
 )
 
@@ -63,6 +72,9 @@ func NewCmdLogs(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.Filter, "filter", "f", "", "Filters the available deployments if no deployment argument is provided")
 	cmd.Flags().StringVarP(&options.Label, "label", "l", "", "The label to filter the pods if no deployment argument is provided")
 	cmd.Flags().BoolVarP(&options.EditEnvironment, "edit", "d", false, "Use my Edit Environment to look for the Deployment pods")
+	cmd.Flags().BoolVarP(&options.Access, "access", "", false, "Tails the ingress controller access logs of the deployment rather than its pod logs. Requires the access logs to be written as JSON via 'jx upgrade ingress controller --access-logs-json'")
+	cmd.Flags().StringVarP(&options.IngressNamespace, "ingress-namespace", "", opts.DefaultIngressNamesapce, "The namespace of the ingress controller when tailing access logs")
+	cmd.Flags().StringVarP(&options.IngressDeployment, "ingress-deployment", "", opts.DefaultIngressServiceName, "The name of the ingress controller Deployment when tailing access logs")
 	return cmd
 }
 
@@ -110,7 +122,7 @@ func (o *LogsOptions) Run() error {
 	}
 	name := ""
 	if len(args) == 0 {
-		if o.Label == "" {
+		if o.Label == "" || o.Access {
 			n, err := util.PickName(names, "Pick Deployment:", "", o.GetIOFileHandles())
 			if err != nil {
 				return err
@@ -123,6 +135,9 @@ func (o *LogsOptions) Run() error {
 			return util.InvalidArg(name, names)
 		}
 	}
+	if o.Access {
+		return o.tailAccessLogs(client, ns, name)
+	}
 
 	for {
 		pod := ""
@@ -154,6 +169,25 @@ func (o *LogsOptions) Run() error {
 	}
 }
 
+// tailAccessLogs tails the ingress controller access logs of the requests routed to the deployment
+func (o *LogsOptions) tailAccessLogs(client kubernetes.Interface, ns string, name string) error {
+	deployment, err := client.AppsV1().Deployments(o.IngressNamespace).Get(o.IngressDeployment, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "getting the ingress controller deployment %s in namespace %s", o.IngressDeployment, o.IngressNamespace)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return errors.Wrapf(err, "parsing the selector of the ingress controller deployment %s", o.IngressDeployment)
+	}
+	log.Logger().Infof("Tailing the access logs of %s in namespace %s", util.ColorInfo(name), util.ColorInfo(ns))
+	out := &kube.IngressAccessLogWriter{
+		Out:       o.Out,
+		Namespace: ns,
+		Services:  []string{name, kube.GetAppName(name, ns)},
+	}
+	return kube.TailLogsForSelector(o.IngressNamespace, selector.String(), "", o.Err, out)
+}
+
 func parseSelector(selectorText string) (map[string]string, error) {
 	selector, err := metav1.ParseToLabelSelector(selectorText)
 	if err != nil {
@@ -55,11 +55,12 @@ func NewCmdNamespace(commonOpts *opts.CommonOptions) *cobra.Command {
 		CommonOptions: commonOpts,
 	}
 	cmd := &cobra.Command{
-		Use:     "namespace",
-		Aliases: []string{"ns"},
-		Short:   "View or change the current namespace context in the current Kubernetes cluster",
-		Long:    namespaceLong,
-		Example: namespaceExample,
+		Use:               "namespace",
+		Aliases:           []string{"ns"},
+		Short:             "View or change the current namespace context in the current Kubernetes cluster",
+		Long:              namespaceLong,
+		Example:           namespaceExample,
+		ValidArgsFunction: options.CompleteNamespaces,
 		Run: func(cmd *cobra.Command, args []string) {
 			options.Cmd = cmd
 			options.Args = args
@@ -171,7 +172,8 @@ func handleStatusError(err error, client kubernetes.Interface, ns string, create
 func createNamespace(client kubernetes.Interface, ns string) error {
 	namespace := core_v1.Namespace{
 		ObjectMeta: meta_v1.ObjectMeta{
-			Name: ns,
+			Name:   ns,
+			Labels: map[string]string{kube.LabelCreatedBy: kube.ValueCreatedByJX},
 		},
 	}
 	_, err := client.CoreV1().Namespaces().Create(&namespace)
@@ -223,6 +225,6 @@ func pick(o *NamespaceOptions, names []string, defaultNamespace string) (string,
 	}
 
 	surveyOpts := survey.WithStdio(o.In, o.Out, o.Err)
-	err := survey.AskOne(prompt, &name, nil, surveyOpts)
+	err := util.AskOne(prompt, &name, nil, surveyOpts)
 	return name, err
 }
@@ -2,12 +2,22 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/get"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/kube/services"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/pkg/browser"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/jenkins-x/jx-logging/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
@@ -23,6 +33,8 @@ type ConsoleOptions struct {
 
 	OnlyViewURL     bool
 	ClassicMode     bool
+	PortForward     bool
+	LocalPort       int
 	JenkinsSelector opts.JenkinsSelectorOptions
 }
 
@@ -30,6 +42,9 @@ var (
 	open_long = templates.LongDesc(`
 		Opens a named service in the browser.
 
+		If the service has no ingress, or '--port-forward' is specified, a local port is forwarded to a pod of the service
+		and the local URL is opened instead. The port is forwarded until the command is interrupted.
+
 		You can use the '--url' argument to just display the URL without opening it`)
 
 	open_example = templates.Examples(`
@@ -39,6 +54,9 @@ var (
 		# Print the Nexus console URL but do not open a browser
 		jx open jenkins-x-sonatype-nexus -u
 
+		# Open the Nexus console via a port forwarded to local port 8081
+		jx open jenkins-x-sonatype-nexus --port-forward --local-port 8081
+
 		# List all the service URLs
 		jx open`)
 )
@@ -87,17 +105,35 @@ func (o *ConsoleOptions) open(name string, label string) error {
 			return err
 		}
 	}
+	if o.PortForward {
+		return o.openPortForward(name, ns, label)
+	}
 	if ns != "" {
 		svcURL, err = o.FindServiceInNamespace(name, ns)
 	} else {
 		svcURL, err = o.FindService(name)
 	}
 	if err != nil && name != "" {
+		// lets fall back to port forwarding to services which have no ingress
+		svcNs, findErr := o.findServiceNamespace(name, ns)
+		if findErr != nil {
+			return findErr
+		}
+		if svcNs != "" {
+			log.Logger().Infof("Service %s has no ingress so forwarding a local port to it", util.ColorInfo(name))
+			return o.openPortForward(name, svcNs, label)
+		}
 		log.Logger().Infof("If the app %s is running in a different environment you could try: %s", util.ColorInfo(name), util.ColorInfo("jx get applications"))
 	}
 	if err != nil {
 		return err
 	}
+	return o.openURL(name, label, svcURL)
+}
+
+// openURL displays the URL of the service and opens it in the browser
+func (o *ConsoleOptions) openURL(name string, label string, svcURL string) error {
+	var err error
 	fullURL := svcURL
 	if name == "jenkins" {
 		fullURL = o.urlForMode(svcURL)
@@ -123,9 +159,84 @@ func (o *ConsoleOptions) open(name string, label string) error {
 	return nil
 }
 
+// openPortForward forwards a local port to the service and opens its local URL until the command is interrupted
+func (o *ConsoleOptions) openPortForward(name string, ns string, label string) error {
+	if name == "" {
+		return util.MissingArgument("service")
+	}
+	kubeClient, curNs, err := o.KubeClientAndNamespace()
+	if err != nil {
+		return err
+	}
+	if ns == "" {
+		ns = curNs
+	}
+	config, err := o.GetFactory().CreateKubeConfig()
+	if err != nil {
+		return errors.Wrap(err, "creating the kube config")
+	}
+	var out io.Writer = ioutil.Discard
+	if o.Verbose {
+		out = o.Out
+	}
+	forward, err := services.ForwardService(config, kubeClient, ns, name, o.LocalPort, out, o.Err)
+	if err != nil {
+		return err
+	}
+	defer forward.Close()
+
+	// lets stop forwarding when interrupted rather than leaving the port open
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	err = o.openURL(name, label, forward.URL)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Forwarding %s to pod %s of service %s in namespace %s, press Ctrl-C to stop", util.ColorInfo(forward.URL),
+		util.ColorInfo(forward.Pod), util.ColorInfo(name), util.ColorInfo(ns))
+	select {
+	case <-signals:
+		log.Logger().Info("\nStopping the port forwarding")
+		return nil
+	case err = <-forward.Done():
+		return errors.Wrapf(err, "forwarding to service %s", name)
+	}
+}
+
+// findServiceNamespace returns the namespace of the service which is either the given namespace or the current or dev
+// namespace, or an empty string if there is no such service
+func (o *ConsoleOptions) findServiceNamespace(name string, ns string) (string, error) {
+	kubeClient, curNs, err := o.KubeClientAndNamespace()
+	if err != nil {
+		return "", err
+	}
+	namespaces := []string{ns}
+	if ns == "" {
+		devNs, _, err := kube.GetDevNamespace(kubeClient, curNs)
+		if err != nil {
+			return "", err
+		}
+		namespaces = []string{curNs, devNs}
+	}
+	for _, n := range namespaces {
+		_, err = kubeClient.CoreV1().Services(n).Get(name, metav1.GetOptions{})
+		if err == nil {
+			return n, nil
+		}
+		if !apierrors.IsNotFound(err) {
+			return "", errors.Wrapf(err, "getting service %s in namespace %s", name, n)
+		}
+	}
+	return "", nil
+}
+
 func (o *ConsoleOptions) addConsoleFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVarP(&o.OnlyViewURL, "url", "u", false, "Only displays and the URL and does not open the browser")
 	cmd.Flags().BoolVarP(&o.ClassicMode, "classic", "", false, "Use the classic Jenkins skin instead of Blue Ocean")
+	cmd.Flags().BoolVarP(&o.PortForward, "port-forward", "", false, "Forwards a local port to the service and opens the local URL even if the service has an ingress")
+	cmd.Flags().IntVarP(&o.LocalPort, "local-port", "", 0, "The local port forwarded to the service. Defaults to a free port")
 
 	o.AddGetUrlFlags(cmd)
 	o.JenkinsSelector.AddFlags(cmd)
@@ -0,0 +1,53 @@
+package operations
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/spf13/cobra"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+)
+
+// OperationsOptions contains the command line options
+type OperationsOptions struct {
+	*opts.CommonOptions
+}
+
+var (
+	operationsLong = templates.LongDesc(`
+		Maintenance operations on a Jenkins X installation.
+
+		Valid operations include:
+
+		* rotate-secrets
+`)
+)
+
+// NewCmdOperations creates a command object for the "operations" command
+func NewCmdOperations(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &OperationsOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "operations",
+		Aliases: []string{"ops"},
+		Short:   "Maintenance operations on a Jenkins X installation",
+		Long:    operationsLong,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.AddCommand(NewCmdRotateSecrets(commonOpts))
+
+	return cmd
+}
+
+// Run implements this command
+func (o *OperationsOptions) Run() error {
+	return o.Cmd.Help()
+}
@@ -0,0 +1,515 @@
+package operations
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/update"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/kube/services"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// ComponentChartMuseum the basic auth credentials of ChartMuseum
+	ComponentChartMuseum = "chartmuseum"
+	// ComponentNexus the admin password of Nexus
+	ComponentNexus = "nexus"
+	// ComponentDashboard the basic auth credentials of the ingresses of the dashboard and other exposed services
+	ComponentDashboard = "dashboard"
+	// ComponentBotToken the git token of the pipeline bot user
+	ComponentBotToken = "bot-token"
+	// ComponentHMAC the HMAC token used to sign the webhooks
+	ComponentHMAC = "hmac"
+
+	nexusServiceName = "nexus"
+	nexusAdminUser   = "admin"
+)
+
+var (
+	// SecretComponents the components whose secrets can be rotated in the order they are rotated. The artifact
+	// repositories come first and the webhook HMAC token comes last as re-registering the webhooks uses the bot token
+	SecretComponents = []string{ComponentChartMuseum, ComponentNexus, ComponentDashboard, ComponentBotToken, ComponentHMAC}
+
+	hmacSecretNames  = []string{"hmac-token", "lighthouse-hmac-token"}
+	oauthSecretNames = []string{"oauth-token", "lighthouse-oauth-token"}
+
+	// hookDeployments the deployments which read the HMAC token
+	hookDeployments = []string{"hook", "lighthouse-webhooks"}
+	// botDeployments the deployments which read the bot token
+	botDeployments = []string{"hook", "tide", "lighthouse-webhooks", "lighthouse-keeper", "lighthouse-foghorn"}
+
+	rotateSecretsLong = templates.LongDesc(`
+		Rotates the admin passwords and tokens of the components installed by Jenkins X.
+
+		The secrets of the components are rotated in the following order:
+
+		* chartmuseum: the basic auth password of ChartMuseum
+		* nexus: the admin password of Nexus
+		* dashboard: the basic auth password of the dashboard and the other exposed services
+		* bot-token: the git token of the pipeline bot user, which has to be generated in the git provider and passed via --bot-token
+		* hmac: the HMAC token signing the webhooks, re-registering the webhooks of all repositories
+
+		Each rotation is verified, restarting and waiting for the deployments which read the secret, before the next one
+		starts. The rotation stops at the first failure so that the components which depend on it keep working.
+`)
+
+	rotateSecretsExample = templates.Examples(`
+		# rotates the secrets of all the components
+		jx operations rotate-secrets --bot-token mynewtoken
+
+		# only rotate the HMAC token and re-register the webhooks
+		jx operations rotate-secrets --component hmac
+
+		# display the rotations which would be performed
+		jx operations rotate-secrets --dry-run
+
+		# rotate a single git, registry or webhook secret updating the secrets backend of jx boot too
+		jx rotate secret git --token mynewtoken
+`)
+)
+
+// RotateSecretsOptions the options for the "operations rotate-secrets" command
+type RotateSecretsOptions struct {
+	*opts.CommonOptions
+
+	Components []string
+	BotToken   string
+	DryRun     bool
+	Timeout    time.Duration
+	// HMACToken the new HMAC token of the webhooks. A token is generated if not specified
+	HMACToken string
+
+	// updateWebhooks re-registers the webhooks of all the repositories using the HMAC token
+	updateWebhooks func(hmacToken string) error
+}
+
+// NewCmdRotateSecrets creates the command
+func NewCmdRotateSecrets(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &RotateSecretsOptions{
+		CommonOptions: commonOpts,
+	}
+	options.updateWebhooks = options.reregisterWebhooks
+
+	cmd := &cobra.Command{
+		Use:     "rotate-secrets",
+		Short:   "Rotates the admin passwords and tokens of the components installed by Jenkins X",
+		Long:    rotateSecretsLong,
+		Example: rotateSecretsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(&options.Components, "component", "c", nil, fmt.Sprintf("The components whose secrets are rotated: %s. Defaults to all of them", strings.Join(SecretComponents, ", ")))
+	cmd.Flags().StringVarP(&options.BotToken, "bot-token", "", "", "The new git token of the pipeline bot user. The bot token is not rotated if not specified")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "Only display the secrets which would be rotated")
+	cmd.Flags().DurationVarP(&options.Timeout, "timeout", "", 5*time.Minute, "The time to wait for each component to become ready after its secret is rotated")
+
+	return cmd
+}
+
+// Run implements this command
+func (o *RotateSecretsOptions) Run() error {
+	components := o.Components
+	if len(components) == 0 {
+		components = SecretComponents
+	}
+	for _, c := range components {
+		if util.StringArrayIndex(SecretComponents, c) < 0 {
+			return util.InvalidOption("component", c, SecretComponents)
+		}
+	}
+
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return errors.Wrap(err, "creating the kube client")
+	}
+
+	rotations := map[string]func(kubernetes.Interface, string) error{
+		ComponentChartMuseum: o.rotateChartMuseum,
+		ComponentNexus:       o.rotateNexus,
+		ComponentDashboard:   o.rotateDashboard,
+		ComponentBotToken:    o.rotateBotToken,
+		ComponentHMAC:        o.rotateHMAC,
+	}
+	for _, component := range SecretComponents {
+		if util.StringArrayIndex(components, component) < 0 {
+			continue
+		}
+		if o.DryRun {
+			log.Logger().Infof("Would rotate the %s secrets in namespace %s", util.ColorInfo(component), util.ColorInfo(ns))
+			continue
+		}
+		endOperation := util.StartLogOperation("rotate-" + component)
+		log.Logger().Infof("Rotating the %s secrets in namespace %s", util.ColorInfo(component), util.ColorInfo(ns))
+		err = rotations[component](kubeClient, ns)
+		endOperation()
+		if err != nil {
+			return errors.Wrapf(err, "rotating the %s secrets", component)
+		}
+	}
+	return nil
+}
+
+func (o *RotateSecretsOptions) rotateChartMuseum(kubeClient kubernetes.Interface, ns string) error {
+	secret, err := kubeClient.CoreV1().Secrets(ns).Get(kube.SecretJenkinsChartMuseum, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Logger().Infof("No %s secret found so skipping", kube.SecretJenkinsChartMuseum)
+			return nil
+		}
+		return errors.Wrapf(err, "getting secret %s", kube.SecretJenkinsChartMuseum)
+	}
+	password, err := config.GenerateAdminPassword()
+	if err != nil {
+		return err
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data["BASIC_AUTH_PASS"] = []byte(password)
+	_, err = kubeClient.CoreV1().Secrets(ns).Update(secret)
+	if err != nil {
+		return errors.Wrapf(err, "updating secret %s", kube.SecretJenkinsChartMuseum)
+	}
+	err = updateAdminSecrets(kubeClient, ns, func(secrets *config.AdminSecretsConfig) {
+		if secrets.ChartMuseum != nil {
+			secrets.ChartMuseum.ChartMuseumEnv.ChartMuseumSecret.Password = password
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return o.restartDeployments(kubeClient, ns, kube.ServiceChartMuseum)
+}
+
+func (o *RotateSecretsOptions) rotateNexus(kubeClient kubernetes.Interface, ns string) error {
+	_, err := kubeClient.CoreV1().Services(ns).Get(nexusServiceName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Logger().Infof("No %s service found so skipping", nexusServiceName)
+			return nil
+		}
+		return errors.Wrapf(err, "getting service %s", nexusServiceName)
+	}
+	u, err := services.FindServiceURL(kubeClient, ns, nexusServiceName)
+	if err != nil {
+		return err
+	}
+	if u == "" {
+		return fmt.Errorf("service %s is not exposed", nexusServiceName)
+	}
+	adminSecrets, err := loadAdminSecrets(kubeClient, ns)
+	if err != nil {
+		return err
+	}
+	if adminSecrets == nil || adminSecrets.Nexus == nil || adminSecrets.Nexus.DefaultAdminPassword == "" {
+		return fmt.Errorf("could not find the current Nexus admin password in secret %s", opts.JXInstallConfig)
+	}
+	oldPassword := adminSecrets.Nexus.DefaultAdminPassword
+	password, err := config.GenerateAdminPassword()
+	if err != nil {
+		return err
+	}
+
+	err = o.changeNexusPassword(u, oldPassword, password)
+	if err != nil {
+		return err
+	}
+	err = o.verifyNexusPassword(u, password)
+	if err != nil {
+		return err
+	}
+
+	err = updateAdminSecrets(kubeClient, ns, func(secrets *config.AdminSecretsConfig) {
+		secrets.Nexus.DefaultAdminPassword = password
+		if secrets.PipelineSecrets != nil {
+			secrets.PipelineSecrets.MavenSettingsXML = strings.Replace(secrets.PipelineSecrets.MavenSettingsXML,
+				"<password>"+oldPassword+"</password>", "<password>"+password+"</password>", -1)
+		}
+	})
+	if err != nil {
+		return errors.Wrap(err, "the Nexus admin password was changed but could not be stored")
+	}
+	return nil
+}
+
+func (o *RotateSecretsOptions) changeNexusPassword(nexusURL string, oldPassword string, password string) error {
+	u := util.UrlJoin(nexusURL, "service/rest/beta/security/users", nexusAdminUser, "change-password")
+	req, err := http.NewRequest(http.MethodPut, u, strings.NewReader(password))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	req.SetBasicAuth(nexusAdminUser, oldPassword)
+	resp, err := util.GetClientWithTimeout(o.Timeout).Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "changing the Nexus admin password via %s", u)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("changing the Nexus admin password via %s returned status %d", u, resp.StatusCode)
+	}
+	return nil
+}
+
+func (o *RotateSecretsOptions) verifyNexusPassword(nexusURL string, password string) error {
+	u := util.UrlJoin(nexusURL, "service/rest/v1/status/check")
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(nexusAdminUser, password)
+	resp, err := util.GetClientWithTimeout(o.Timeout).Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "verifying the Nexus admin password via %s", u)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("verifying the Nexus admin password via %s returned status %d", u, resp.StatusCode)
+	}
+	return nil
+}
+
+func (o *RotateSecretsOptions) rotateDashboard(kubeClient kubernetes.Interface, ns string) error {
+	secret, err := kubeClient.CoreV1().Secrets(ns).Get(kube.SecretBasicAuth, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Logger().Infof("No %s secret found so skipping", kube.SecretBasicAuth)
+			return nil
+		}
+		return errors.Wrapf(err, "getting secret %s", kube.SecretBasicAuth)
+	}
+	username := strings.Split(string(secret.Data[kube.AUTH]), ":")[0]
+	if username == "" {
+		username = "admin"
+	}
+	password, err := config.GenerateAdminPassword()
+	if err != nil {
+		return err
+	}
+	entry := config.IngressBasicAuthEntry(username, password)
+
+	// the secret is copied into the namespaces of the environments when they are exposed
+	namespaces := []string{ns}
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return errors.Wrap(err, "creating the jx client")
+	}
+	envs, err := kube.GetPermanentEnvironments(jxClient, ns)
+	if err != nil {
+		return errors.Wrap(err, "getting the environments")
+	}
+	for _, env := range envs {
+		if env.Spec.Namespace != "" && util.StringArrayIndex(namespaces, env.Spec.Namespace) < 0 {
+			namespaces = append(namespaces, env.Spec.Namespace)
+		}
+	}
+	for _, secretNS := range namespaces {
+		err = updateSecretData(kubeClient, secretNS, kube.SecretBasicAuth, kube.AUTH, entry)
+		if err != nil {
+			return err
+		}
+	}
+	err = updateAdminSecrets(kubeClient, ns, func(secrets *config.AdminSecretsConfig) {
+		secrets.IngressBasicAuth = entry
+	})
+	if err != nil {
+		return err
+	}
+	for _, secretNS := range namespaces {
+		err = verifySecretData(kubeClient, secretNS, kube.SecretBasicAuth, kube.AUTH, entry)
+		if err != nil {
+			return err
+		}
+	}
+	log.Logger().Infof("The new password of user %s is: %s", util.ColorInfo(username), util.ColorInfo(password))
+	return nil
+}
+
+func (o *RotateSecretsOptions) rotateBotToken(kubeClient kubernetes.Interface, ns string) error {
+	if o.BotToken == "" {
+		log.Logger().Warnf("No --bot-token specified so skipping. Generate a new token for the pipeline bot user in your git provider first")
+		return nil
+	}
+	secrets, err := kubeClient.CoreV1().Secrets(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "listing the secrets in namespace %s", ns)
+	}
+	prefix := strings.TrimSuffix(kube.SecretJenkinsPipelineGitCredentials, "-")
+	for _, secret := range secrets.Items {
+		switch {
+		case strings.HasPrefix(secret.Name, prefix):
+			err = updateSecretData(kubeClient, ns, secret.Name, kube.SecretDataPassword, o.BotToken)
+		case util.StringArrayIndex(oauthSecretNames, secret.Name) >= 0:
+			err = updateSecretData(kubeClient, ns, secret.Name, "oauth", o.BotToken)
+		default:
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return o.restartDeployments(kubeClient, ns, botDeployments...)
+}
+
+func (o *RotateSecretsOptions) rotateHMAC(kubeClient kubernetes.Interface, ns string) error {
+	var secret *v1.Secret
+	for _, name := range hmacSecretNames {
+		s, err := kubeClient.CoreV1().Secrets(ns).Get(name, metav1.GetOptions{})
+		if err == nil {
+			secret = s
+			break
+		}
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "getting secret %s", name)
+		}
+	}
+	if secret == nil {
+		log.Logger().Infof("No %s secret found so skipping", strings.Join(hmacSecretNames, " or "))
+		return nil
+	}
+	token := o.HMACToken
+	if token == "" {
+		var err error
+		token, err = GenerateHMACToken()
+		if err != nil {
+			return err
+		}
+	}
+	err := updateSecretData(kubeClient, ns, secret.Name, "hmac", token)
+	if err != nil {
+		return err
+	}
+	err = o.restartDeployments(kubeClient, ns, hookDeployments...)
+	if err != nil {
+		return err
+	}
+	return o.updateWebhooks(token)
+}
+
+// GenerateHMACToken generates a new HMAC token to sign the webhooks with
+func GenerateHMACToken() (string, error) {
+	token, err := util.RandStringBytesMaskImprSrc(41)
+	if err != nil {
+		return "", errors.Wrap(err, "generating the HMAC token")
+	}
+	return token, nil
+}
+
+// reregisterWebhooks updates the webhooks of all the repositories to be signed with the HMAC token
+func (o *RotateSecretsOptions) reregisterWebhooks(hmacToken string) error {
+	options := &update.UpdateWebhooksOptions{
+		CommonOptions:  o.CommonOptions,
+		ExactHookMatch: true,
+		HMAC:           hmacToken,
+	}
+	return options.Run()
+}
+
+// restartDeployments restarts the deployments which exist and waits for them to roll out to verify they work with
+// the rotated secrets
+func (o *RotateSecretsOptions) restartDeployments(kubeClient kubernetes.Interface, ns string, names ...string) error {
+	for _, name := range names {
+		err := kube.RestartDeployment(kubeClient, name, ns)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		log.Logger().Infof("Waiting for deployment %s to roll out", util.ColorInfo(name))
+		err = kube.WaitForDeploymentRollout(kubeClient, name, ns, o.Timeout)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func updateSecretData(kubeClient kubernetes.Interface, ns string, name string, key string, value string) error {
+	secret, err := kubeClient.CoreV1().Secrets(ns).Get(name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "getting secret %s in namespace %s", name, ns)
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[key] = []byte(value)
+	_, err = kubeClient.CoreV1().Secrets(ns).Update(secret)
+	if err != nil {
+		return errors.Wrapf(err, "updating secret %s in namespace %s", name, ns)
+	}
+	return nil
+}
+
+func verifySecretData(kubeClient kubernetes.Interface, ns string, name string, key string, value string) error {
+	secret, err := kubeClient.CoreV1().Secrets(ns).Get(name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "getting secret %s in namespace %s", name, ns)
+	}
+	if string(secret.Data[key]) != value {
+		return fmt.Errorf("secret %s in namespace %s was not updated", name, ns)
+	}
+	return nil
+}
+
+// loadAdminSecrets loads the admin secrets of the installation returning nil if there are none
+func loadAdminSecrets(kubeClient kubernetes.Interface, ns string) (*config.AdminSecretsConfig, error) {
+	secret, err := kubeClient.CoreV1().Secrets(ns).Get(opts.JXInstallConfig, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "getting secret %s", opts.JXInstallConfig)
+	}
+	data := secret.Data[opts.AdminSecretsFile]
+	if len(data) == 0 {
+		return nil, nil
+	}
+	answer := &config.AdminSecretsConfig{}
+	err = yaml.Unmarshal(data, answer)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshalling %s of secret %s", opts.AdminSecretsFile, opts.JXInstallConfig)
+	}
+	return answer, nil
+}
+
+// updateAdminSecrets keeps the admin secrets of the installation in sync with the rotated secrets so that upgrades
+// do not restore the previous passwords
+func updateAdminSecrets(kubeClient kubernetes.Interface, ns string, fn func(secrets *config.AdminSecretsConfig)) error {
+	adminSecrets, err := loadAdminSecrets(kubeClient, ns)
+	if err != nil || adminSecrets == nil {
+		return err
+	}
+	fn(adminSecrets)
+	data, err := yaml.Marshal(adminSecrets)
+	if err != nil {
+		return errors.Wrap(err, "marshalling the admin secrets")
+	}
+	return updateSecretData(kubeClient, ns, opts.JXInstallConfig, opts.AdminSecretsFile, string(data))
+}
@@ -0,0 +1,228 @@
+// +build unit
+
+package operations
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ghodss/yaml"
+	jxfake "github.com/jenkins-x/jx-api/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/clients/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/kube/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	testNamespace = "jx"
+	oldPassword   = "old-password"
+)
+
+func TestRotateSecrets(t *testing.T) {
+	kubeClient := kubefake.NewSimpleClientset(
+		secret(testNamespace, kube.SecretJenkinsChartMuseum, "BASIC_AUTH_USER", "admin", "BASIC_AUTH_PASS", oldPassword),
+		secret(testNamespace, kube.SecretBasicAuth, kube.AUTH, config.IngressBasicAuthEntry("admin", oldPassword)),
+		secret("jx-staging", kube.SecretBasicAuth, kube.AUTH, config.IngressBasicAuthEntry("admin", oldPassword)),
+		secret(testNamespace, "jx-pipeline-git-github-github", kube.SecretDataUsername, "bot", kube.SecretDataPassword, "old-token"),
+		secret(testNamespace, "oauth-token", "oauth", "old-token"),
+		secret(testNamespace, "hmac-token", "hmac", "old-hmac"),
+		adminSecrets(t, oldPassword),
+		rolledOutDeployment(kube.ServiceChartMuseum),
+		rolledOutDeployment("hook"),
+	)
+	o, registeredHMAC := newRotateSecretsOptions(kubeClient)
+	o.BotToken = "new-token"
+
+	err := o.Run()
+	require.NoError(t, err)
+
+	chartMuseumPassword := secretValue(t, kubeClient, testNamespace, kube.SecretJenkinsChartMuseum, "BASIC_AUTH_PASS")
+	assert.NotEqual(t, oldPassword, chartMuseumPassword)
+
+	auth := secretValue(t, kubeClient, testNamespace, kube.SecretBasicAuth, kube.AUTH)
+	assert.NotEqual(t, config.IngressBasicAuthEntry("admin", oldPassword), auth)
+	assert.True(t, strings.HasPrefix(auth, "admin:{SHA}"), "the basic auth %s should be for the admin user", auth)
+	assert.Equal(t, auth, secretValue(t, kubeClient, "jx-staging", kube.SecretBasicAuth, kube.AUTH))
+
+	loaded, err := loadAdminSecrets(kubeClient, testNamespace)
+	require.NoError(t, err)
+	assert.Equal(t, chartMuseumPassword, loaded.ChartMuseum.ChartMuseumEnv.ChartMuseumSecret.Password)
+	assert.Equal(t, auth, loaded.IngressBasicAuth)
+
+	assert.Equal(t, "new-token", secretValue(t, kubeClient, testNamespace, "jx-pipeline-git-github-github", kube.SecretDataPassword))
+	assert.Equal(t, "new-token", secretValue(t, kubeClient, testNamespace, "oauth-token", "oauth"))
+
+	hmac := secretValue(t, kubeClient, testNamespace, "hmac-token", "hmac")
+	assert.NotEqual(t, "old-hmac", hmac)
+	assert.Equal(t, []string{hmac}, *registeredHMAC, "the webhooks should be registered with the new HMAC token")
+
+	for _, name := range []string{kube.ServiceChartMuseum, "hook"} {
+		d, err := kubeClient.AppsV1().Deployments(testNamespace).Get(name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.NotEmpty(t, d.Spec.Template.Annotations[kube.AnnotationRestartedAt], "deployment %s should be restarted", name)
+	}
+}
+
+func TestRotateSecretsSelectedComponents(t *testing.T) {
+	kubeClient := kubefake.NewSimpleClientset(
+		secret(testNamespace, kube.SecretJenkinsChartMuseum, "BASIC_AUTH_PASS", oldPassword),
+		secret(testNamespace, "oauth-token", "oauth", "old-token"),
+		secret(testNamespace, "hmac-token", "hmac", "old-hmac"),
+		rolledOutDeployment("hook"),
+	)
+	o, registeredHMAC := newRotateSecretsOptions(kubeClient)
+	o.Components = []string{ComponentHMAC, ComponentBotToken}
+
+	err := o.Run()
+	require.NoError(t, err)
+
+	assert.Equal(t, oldPassword, secretValue(t, kubeClient, testNamespace, kube.SecretJenkinsChartMuseum, "BASIC_AUTH_PASS"))
+	assert.Equal(t, "old-token", secretValue(t, kubeClient, testNamespace, "oauth-token", "oauth"), "the bot token should not be rotated without --bot-token")
+	assert.NotEqual(t, "old-hmac", secretValue(t, kubeClient, testNamespace, "hmac-token", "hmac"))
+	assert.Len(t, *registeredHMAC, 1)
+}
+
+func TestRotateSecretsDryRun(t *testing.T) {
+	kubeClient := kubefake.NewSimpleClientset(
+		secret(testNamespace, kube.SecretJenkinsChartMuseum, "BASIC_AUTH_PASS", oldPassword),
+		secret(testNamespace, "hmac-token", "hmac", "old-hmac"),
+	)
+	o, registeredHMAC := newRotateSecretsOptions(kubeClient)
+	o.DryRun = true
+
+	err := o.Run()
+	require.NoError(t, err)
+
+	assert.Equal(t, oldPassword, secretValue(t, kubeClient, testNamespace, kube.SecretJenkinsChartMuseum, "BASIC_AUTH_PASS"))
+	assert.Equal(t, "old-hmac", secretValue(t, kubeClient, testNamespace, "hmac-token", "hmac"))
+	assert.Empty(t, *registeredHMAC)
+}
+
+func TestRotateSecretsInvalidComponent(t *testing.T) {
+	o, _ := newRotateSecretsOptions(kubefake.NewSimpleClientset())
+	o.Components = []string{"jenkins"}
+
+	err := o.Run()
+	assert.Error(t, err)
+}
+
+func TestRotateSecretsNexus(t *testing.T) {
+	nexusPassword := oldPassword
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "admin" || password != nexusPassword {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/service/rest/beta/security/users/admin/change-password":
+			data, _ := ioutil.ReadAll(r.Body)
+			nexusPassword = string(data)
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodGet && r.URL.Path == "/service/rest/v1/status/check":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	kubeClient := kubefake.NewSimpleClientset(
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "nexus",
+				Namespace:   testNamespace,
+				Annotations: map[string]string{services.ExposeURLAnnotation: server.URL},
+			},
+		},
+		adminSecrets(t, oldPassword),
+	)
+	o, _ := newRotateSecretsOptions(kubeClient)
+	o.Components = []string{ComponentNexus}
+
+	err := o.Run()
+	require.NoError(t, err)
+
+	assert.NotEqual(t, oldPassword, nexusPassword)
+	loaded, err := loadAdminSecrets(kubeClient, testNamespace)
+	require.NoError(t, err)
+	assert.Equal(t, nexusPassword, loaded.Nexus.DefaultAdminPassword)
+	assert.Contains(t, loaded.PipelineSecrets.MavenSettingsXML, "<password>"+nexusPassword+"</password>")
+}
+
+func newRotateSecretsOptions(kubeClient kubernetes.Interface) (*RotateSecretsOptions, *[]string) {
+	commonOpts := opts.NewCommonOptionsWithFactory(fake.NewFakeFactory())
+	commonOpts.SetDevNamespace(testNamespace)
+	commonOpts.SetKubeClient(kubeClient)
+	commonOpts.SetJxClient(jxfake.NewSimpleClientset(kube.NewPermanentEnvironment("staging")))
+
+	registered := []string{}
+	o := &RotateSecretsOptions{
+		CommonOptions: &commonOpts,
+		Timeout:       5 * time.Second,
+		updateWebhooks: func(hmacToken string) error {
+			registered = append(registered, hmacToken)
+			return nil
+		},
+	}
+	return o, &registered
+}
+
+func secret(ns string, name string, keyValues ...string) runtime.Object {
+	data := map[string][]byte{}
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		data[keyValues[i]] = []byte(keyValues[i+1])
+	}
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+		},
+		Data: data,
+	}
+}
+
+func adminSecrets(t *testing.T, password string) runtime.Object {
+	service := config.AdminSecretsService{}
+	service.Flags.DefaultAdminPassword = password
+	err := service.NewAdminSecretsConfig()
+	require.NoError(t, err)
+	data, err := yaml.Marshal(service.Secrets)
+	require.NoError(t, err)
+	return secret(testNamespace, opts.JXInstallConfig, opts.AdminSecretsFile, string(data))
+}
+
+func rolledOutDeployment(name string) runtime.Object {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: testNamespace,
+		},
+		Status: appsv1.DeploymentStatus{
+			Replicas:          1,
+			UpdatedReplicas:   1,
+			ReadyReplicas:     1,
+			AvailableReplicas: 1,
+		},
+	}
+}
+
+func secretValue(t *testing.T, kubeClient kubernetes.Interface, ns string, name string, key string) string {
+	s, err := kubeClient.CoreV1().Secrets(ns).Get(name, metav1.GetOptions{})
+	require.NoError(t, err)
+	return string(s.Data[key])
+}
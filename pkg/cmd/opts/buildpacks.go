@@ -32,29 +32,77 @@ type InvokeDraftPack struct {
 	DisableAddFiles             bool
 	UseNextGenPipeline          bool
 	CreateJenkinsxYamlIfMissing bool
-	ProjectConfig               *config.ProjectConfig
+	// SkipDockerfile does not copy the Dockerfile of the pack as the image is built without it
+	SkipDockerfile bool
+	ProjectConfig  *config.ProjectConfig
 }
 
-// InitBuildPacks initialise the build packs
-func (o *CommonOptions) InitBuildPacks(i *InvokeDraftPack) (string, *v1.TeamSettings, error) {
+// InitBuildPacks initialise the build packs returning the packs dirs of the build pack repositories in search order
+func (o *CommonOptions) InitBuildPacks(i *InvokeDraftPack) ([]string, *v1.TeamSettings, error) {
 	settings, err := o.TeamSettings()
 	if err != nil {
-		return "", settings, err
+		return nil, settings, err
 	}
-	buildPackURL := settings.BuildPackURL
 	if i != nil && i.ProjectConfig != nil && i.ProjectConfig.BuildPackGitURL != "" {
-		buildPackURL = i.ProjectConfig.BuildPackGitURL
+		ref := settings.BuildPackRef
+		if i.ProjectConfig.BuildPackGitURef != "" {
+			ref = i.ProjectConfig.BuildPackGitURef
+		}
+		dir, err := gitresolver.InitBuildPack(o.Git(), i.ProjectConfig.BuildPackGitURL, ref)
+		return []string{dir}, settings, err
+	}
+	repositories, err := o.BuildPackRepositories(settings)
+	if err != nil {
+		return nil, settings, err
+	}
+	dirs, err := buildpacks.InitRepositories(o.Git(), repositories)
+	return dirs, settings, err
+}
+
+// BuildPackRepositories returns the build pack repositories of the team in search order
+func (o *CommonOptions) BuildPackRepositories(settings *v1.TeamSettings) ([]buildpacks.Repository, error) {
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return nil, err
+	}
+	repositories, err := buildpacks.LoadRepositories(kubeClient, ns)
+	if err != nil {
+		return nil, err
+	}
+	return buildpacks.SearchOrder(repositories, settings.BuildPackURL, settings.BuildPackRef), nil
+}
+
+// InitBuildPackOfPack initialises the first of the build pack repositories containing the pack returning its packs
+// dir or the packs dir of the last repository if none contains it
+func (o *CommonOptions) InitBuildPackOfPack(repositories []buildpacks.Repository, pack string) (string, error) {
+	packsDir := ""
+	for _, r := range repositories {
+		dir, err := gitresolver.InitBuildPack(o.Git(), r.URL, r.GetRef())
+		if err != nil {
+			return "", errors.Wrapf(err, "initialising build pack repository %s at %s", r.URL, r.GetRef())
+		}
+		packsDir = dir
+		found, err := buildpacks.FindPack([]string{dir}, pack)
+		if err != nil {
+			return "", err
+		}
+		if found != "" {
+			break
+		}
+	}
+	if packsDir == "" {
+		return "", errors.New("no build pack repositories configured")
 	}
-	dir, err := gitresolver.InitBuildPack(o.Git(), buildPackURL, settings.BuildPackRef)
-	return dir, settings, err
+	return packsDir, nil
 }
 
 // InvokeDraftPack invokes a draft pack copying in a Jenkinsfile if required
 func (o *CommonOptions) InvokeDraftPack(i *InvokeDraftPack) (string, error) {
-	packsDir, settings, err := o.InitBuildPacks(i)
+	packsDirs, settings, err := o.InitBuildPacks(i)
 	if err != nil {
 		return "", err
 	}
+	packsDir := packsDirs[0]
 
 	// lets configure the draft pack mode based on the team settings
 	if settings.GetImportMode() == v1.ImportModeTypeYAML {
@@ -93,15 +141,13 @@ func (o *CommonOptions) InvokeDraftPack(i *InvokeDraftPack) (string, error) {
 
 	if len(customDraftPack) > 0 {
 		log.Logger().Infof("trying to use draft pack: %s", customDraftPack)
-		lpack = filepath.Join(packsDir, customDraftPack)
-		f, err := util.DirExists(lpack)
+		lpack, err = buildpacks.FindPack(packsDirs, customDraftPack)
 		if err != nil {
 			log.Logger().Error(err.Error())
 			return "", err
 		}
-		if f == false {
+		if lpack == "" {
 			log.Logger().Error("Could not find pack: " + customDraftPack + " going to try detect which pack to use")
-			lpack = ""
 		}
 	}
 
@@ -111,10 +157,11 @@ func (o *CommonOptions) InvokeDraftPack(i *InvokeDraftPack) (string, error) {
 			if err != nil {
 				return "", err
 			}
-			lpack = filepath.Join(packsDir, pack)
-
-			exists, _ = util.DirExists(lpack)
-			if !exists {
+			lpack, err = buildpacks.FindPack(packsDirs, pack)
+			if err != nil {
+				return "", err
+			}
+			if lpack == "" {
 				log.Logger().Warn("defaulting to maven pack")
 				lpack = filepath.Join(packsDir, "maven")
 			}
@@ -127,8 +174,13 @@ func (o *CommonOptions) InvokeDraftPack(i *InvokeDraftPack) (string, error) {
 		} else if exists, err := util.FileExists(envChart); err == nil && exists {
 			lpack = filepath.Join(packsDir, "environment")
 		} else {
-			// pack detection time
-			lpack, err = jxdraft.DoPackDetectionForBuildPack(o.Out, dir, packsDir)
+			// pack detection time searching the build pack repositories in order
+			for _, d := range packsDirs {
+				lpack, err = jxdraft.DoPackDetectionForBuildPack(o.Out, dir, d)
+				if err == nil {
+					break
+				}
+			}
 
 			if err != nil {
 				if lpack == "" {
@@ -191,6 +243,7 @@ func (o *CommonOptions) InvokeDraftPack(i *InvokeDraftPack) (string, error) {
 		}
 	}
 	log.Logger().Infof("selected pack: %s", lpack)
+	packsDir = filepath.Dir(lpack)
 	draftPack := filepath.Base(lpack)
 	i.CustomDraftPack = draftPack
 
@@ -232,7 +285,11 @@ func (o *CommonOptions) InvokeDraftPack(i *InvokeDraftPack) (string, error) {
 		generateJenkinsPath = defaultJenkinsfile
 	}
 
-	err = buildpacks.CopyBuildPack(dir, lpack)
+	var excludes []string
+	if i.SkipDockerfile {
+		excludes = append(excludes, "Dockerfile", ".dockerignore")
+	}
+	err = buildpacks.CopyBuildPack(dir, lpack, excludes...)
 	if err != nil {
 		log.Logger().Warnf("Failed to apply the build pack in %s due to %s", dir, err)
 	}
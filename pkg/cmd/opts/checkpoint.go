@@ -0,0 +1,125 @@
+package opts
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// Checkpoint records the completed steps of a long running operation such as jx install or jx boot in a state file so
+// that a failed run can be resumed from the failed step rather than starting from scratch
+type Checkpoint struct {
+	// Operation the operation whose steps are recorded such as install
+	Operation string `json:"operation"`
+	// Context the kube context the operation ran against
+	Context string `json:"context,omitempty"`
+	// Completed the names of the completed steps in the order they completed
+	Completed []string `json:"completed,omitempty"`
+	// Failed the name of the step which failed
+	Failed  string `json:"failed,omitempty"`
+	Updated string `json:"updated,omitempty"`
+
+	fileName string
+}
+
+// LoadCheckpoint loads the checkpoint of the operation from the state file in the jx config directory. If resume is
+// false or there is no state file a new checkpoint is returned. Resuming a checkpoint recorded against another kube
+// context fails
+func (o *CommonOptions) LoadCheckpoint(operation string, resume bool) (*Checkpoint, error) {
+	dir, err := util.ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	context := ""
+	config, _, err := o.Kube().LoadConfig()
+	if err == nil && config != nil {
+		context = kube.CurrentContextName(config)
+	}
+	fileName := filepath.Join(dir, "checkpoints", operation+".yaml")
+	checkpoint := &Checkpoint{Operation: operation, Context: context, fileName: fileName}
+	if !resume {
+		return checkpoint, checkpoint.Reset()
+	}
+	exists, err := util.FileExists(fileName)
+	if err != nil || !exists {
+		return checkpoint, err
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading the checkpoint %s", fileName)
+	}
+	err = yaml.Unmarshal(data, checkpoint)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the checkpoint %s", fileName)
+	}
+	if checkpoint.Context != context {
+		return nil, fmt.Errorf("cannot resume the %s of kube context %s from kube context %s", operation, checkpoint.Context, context)
+	}
+	return checkpoint, nil
+}
+
+// IsCompleted returns true if the step completed. A nil Checkpoint has no completed steps
+func (c *Checkpoint) IsCompleted(step string) bool {
+	return c != nil && util.StringArrayIndex(c.Completed, step) >= 0
+}
+
+// Complete records that the step completed. A nil Checkpoint is ignored
+func (c *Checkpoint) Complete(step string) error {
+	if c == nil {
+		return nil
+	}
+	if !c.IsCompleted(step) {
+		c.Completed = append(c.Completed, step)
+	}
+	if c.Failed == step {
+		c.Failed = ""
+	}
+	return c.save()
+}
+
+// Fail records that the step failed. A nil Checkpoint is ignored
+func (c *Checkpoint) Fail(step string) error {
+	if c == nil {
+		return nil
+	}
+	c.Failed = step
+	return c.save()
+}
+
+// Reset removes the state file so that the next run starts from scratch. A nil Checkpoint is ignored
+func (c *Checkpoint) Reset() error {
+	if c == nil {
+		return nil
+	}
+	c.Completed = nil
+	c.Failed = ""
+	err := os.Remove(c.fileName)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "removing the checkpoint %s", c.fileName)
+	}
+	return nil
+}
+
+func (c *Checkpoint) save() error {
+	c.Updated = time.Now().UTC().Format(time.RFC3339)
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return errors.Wrap(err, "marshalling the checkpoint")
+	}
+	err = os.MkdirAll(filepath.Dir(c.fileName), util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "creating the directory of the checkpoint %s", c.fileName)
+	}
+	err = ioutil.WriteFile(c.fileName, data, util.DefaultFileWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "saving the checkpoint %s", c.fileName)
+	}
+	return nil
+}
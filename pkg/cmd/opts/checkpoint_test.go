@@ -0,0 +1,68 @@
+// +build unit
+
+package opts_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResumableStepsSkipCompletedSteps(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jx-checkpoint-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) //nolint:errcheck
+	for k, v := range map[string]string{"JX_HOME": dir, "KUBECONFIG": filepath.Join(dir, "kubeconfig")} {
+		old, ok := os.LookupEnv(k)
+		require.NoError(t, os.Setenv(k, v))
+		if ok {
+			defer os.Setenv(k, old) //nolint:errcheck
+		} else {
+			defer os.Unsetenv(k) //nolint:errcheck
+		}
+	}
+
+	o := &opts.CommonOptions{}
+	runs := map[string]int{}
+	install := func(progress *opts.Progress, failAddons bool) error {
+		err := progress.ResumableStep("Installing the platform", func() error {
+			runs["platform"]++
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return progress.ResumableStep("Installing the addons", func() error {
+			runs["addons"]++
+			if failAddons {
+				return errors.New("timed out")
+			}
+			return nil
+		})
+	}
+
+	progress := opts.NewProgress(&bytes.Buffer{}, false)
+	progress.Checkpoint, err = o.LoadCheckpoint("install", false)
+	require.NoError(t, err)
+	require.Error(t, install(progress, true))
+
+	progress = opts.NewProgress(&bytes.Buffer{}, false)
+	progress.Checkpoint, err = o.LoadCheckpoint("install", true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Installing the platform"}, progress.Checkpoint.Completed)
+	assert.Equal(t, "Installing the addons", progress.Checkpoint.Failed)
+	require.NoError(t, install(progress, false))
+	assert.Equal(t, map[string]int{"platform": 1, "addons": 2}, runs, "the completed platform step should not run again")
+
+	progress.Checkpoint, err = o.LoadCheckpoint("install", false)
+	require.NoError(t, err)
+	assert.Empty(t, progress.Checkpoint.Completed, "not resuming should start from scratch")
+	assert.False(t, progress.Checkpoint.IsCompleted("Installing the platform"))
+}
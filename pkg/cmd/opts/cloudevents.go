@@ -0,0 +1,31 @@
+package opts
+
+import (
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cloudevents"
+)
+
+// EventSource returns the CloudEvents source of the events emitted by the command, such as /jx/import
+func (o *CommonOptions) EventSource() string {
+	if o.Cmd == nil {
+		return "/jx"
+	}
+	return "/" + strings.Join(strings.Fields(o.Cmd.CommandPath()), "/")
+}
+
+// EmitEvent posts a lifecycle event to the event sinks of the team. Failures are only logged so that the sinks never
+// fail the command
+func (o *CommonOptions) EmitEvent(eventType string, subject string, data interface{}) {
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		log.Logger().Warnf("failed to emit the %s event: %s", eventType, err)
+		return
+	}
+	emitter := &cloudevents.Emitter{KubeClient: kubeClient, Namespace: ns}
+	err = emitter.Emit(cloudevents.NewEvent(eventType, o.EventSource(), subject, data))
+	if err != nil {
+		log.Logger().Warnf("failed to emit the %s event: %s", eventType, err)
+	}
+}
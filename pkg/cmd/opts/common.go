@@ -1,6 +1,7 @@
 package opts
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -44,6 +45,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/prow"
 	"github.com/jenkins-x/jx/v2/pkg/secreturl"
 	"github.com/jenkins-x/jx/v2/pkg/table"
+	"github.com/jenkins-x/jx/v2/pkg/tracing"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/jenkins-x/jx/v2/pkg/vault"
 	"github.com/jenkins-x/jx/v2/pkg/versionstream"
@@ -54,15 +56,19 @@ type LogLevel string
 
 const (
 	OptionAlias            = "alias"
+	OptionAnswers          = "answers"
 	OptionApplication      = "app"
 	OptionBatchMode        = "batch-mode"
 	OptionClusterName      = "cluster-name"
 	OptionEnvironment      = "env"
 	OptionInstallDeps      = "install-dependencies"
 	OptionLabel            = "label"
+	OptionLogFormat        = "log-format"
+	OptionLogLevel         = "log-level"
 	OptionName             = "name"
 	OptionNamespace        = "namespace"
 	OptionNoBrew           = "no-brew"
+	OptionOTLPEndpoint     = "otlp-endpoint"
 	OptionRelease          = "release"
 	OptionServerName       = "name"
 	OptionOutputDir        = "output-dir"
@@ -71,8 +77,18 @@ const (
 	OptionTimeout          = "timeout"
 	OptionVerbose          = "verbose"
 
+	// OptionVersionsOverlayRepo the flag for the versions overlay git repository
+	OptionVersionsOverlayRepo = "versions-overlay-repo"
+	// OptionVersionsOverlayRef the flag for the git reference of the versions overlay repository
+	OptionVersionsOverlayRef = "versions-overlay-ref"
+	// OptionTLSMinVersion the flag for the minimum TLS version of the HTTPS connections
+	OptionTLSMinVersion = "tls-min-version"
+	// OptionTLSCipherSuites the flag for the cipher suites of the HTTPS connections
+	OptionTLSCipherSuites = "tls-cipher-suites"
+
 	BranchPatternCommandName      = "branchpattern"
 	QuickStartLocationCommandName = "quickstartlocation"
+	QuickStartCatalogCommandName  = "quickstartcatalog"
 
 	// LogInfo info level logging
 	LogInfo LogLevel = "INFO"
@@ -92,6 +108,10 @@ var (
 	QuickStartLocationCommandAliases = []string{
 		QuickStartLocationCommandName + "s", "quickstartloc", "qsloc",
 	}
+
+	QuickStartCatalogCommandAliases = []string{
+		QuickStartCatalogCommandName + "s", "qscatalog", "qscat",
+	}
 )
 
 // ModifyDevEnvironmentFn a callback to create/update the development Environment
@@ -105,6 +125,7 @@ type CommonOptions struct {
 	prow.Prow
 
 	AdvancedMode           bool
+	AnswersFile            string
 	Args                   []string
 	BatchMode              bool
 	Cmd                    *cobra.Command
@@ -114,21 +135,30 @@ type CommonOptions struct {
 	ExternalJenkinsBaseURL string
 	In                     terminal.FileReader
 	InstallDependencies    bool
+	LogFormat              string
+	LogLevel               string
 	ModifyDevEnvironmentFn ModifyDevEnvironmentFn
 	ModifyEnvironmentFn    ModifyEnvironmentFn
 	NameServers            []string
 	NoBrew                 bool
+	OTLPEndpoint           string
 	RemoteCluster          bool
 	Out                    terminal.FileWriter
 	ServiceAccount         string
 	SkipAuthSecretsMerge   bool
 	Username               string
 	Verbose                bool
+	VersionsBundle         string
+	VersionsOverlayRepo    string
+	VersionsOverlayRef     string
+	TLSMinVersion          string
+	TLSCipherSuites        []string
 	NotifyCallback         func(LogLevel, string)
 
 	apiExtensionsClient apiextensionsclientset.Interface
 	certManagerClient   certmngclient.Interface
 	complianceClient    *client.SonobuoyClient
+	ctx                 context.Context
 	currentNamespace    string
 	devNamespace        string
 	environmentsDir     string
@@ -150,6 +180,9 @@ type CommonOptions struct {
 	secretURLClient     secreturl.Client
 	vaultOperatorClient vaultoperatorclient.Interface
 	versionResolver     *versionstream.VersionResolver
+	versionsBundleDir   string
+	versionsBundleMeta  *versionstream.BundleMetadata
+	versionsOverlayDir  string
 }
 
 type ServerFlags struct {
@@ -238,6 +271,15 @@ func (o *CommonOptions) AddBaseFlags(cmd *cobra.Command) {
 	cmd.PersistentFlags().BoolVarP(&o.BatchMode, OptionBatchMode, "b", defaultBatchMode, "Runs in batch mode without prompting for user input")
 	levels := strings.Join([]string{"panic", "fatal", "error", "warn", "info", "debug", "trace"}, ", ")
 	cmd.PersistentFlags().BoolVarP(&o.Verbose, OptionVerbose, "", false, fmt.Sprintf("Enables verbose output. The environment variable JX_LOG_LEVEL has precedence over this flag and allows setting the logging level to any value of: %s", levels))
+	cmd.PersistentFlags().StringVarP(&o.LogLevel, OptionLogLevel, "", "", fmt.Sprintf("The logging level which has precedence over the verbose flag and the environment variable JX_LOG_LEVEL. One of: %s", levels))
+	cmd.PersistentFlags().StringVarP(&o.AnswersFile, OptionAnswers, "", os.Getenv(util.AnswersEnvVar), fmt.Sprintf("A YAML file mapping the keys of prompts to their answers so that prompts are answered without user input. A prompt without an answer fails. Defaults to $%s", util.AnswersEnvVar))
+	cmd.PersistentFlags().StringVarP(&o.LogFormat, OptionLogFormat, "", "", fmt.Sprintf("The format of the log output, one of %s, %s or %s. Defaults to the environment variable JX_LOG_FORMAT or coloured %s", LogFormatText, LogFormatJSON, LogFormatStackdriver, LogFormatText))
+	cmd.PersistentFlags().StringVarP(&o.OTLPEndpoint, OptionOTLPEndpoint, "", "", fmt.Sprintf("The base URL of an OpenTelemetry OTLP/HTTP endpoint the spans of operations such as chart installs, waits, git clones and cloud API calls are exported to. Defaults to $%s or $%s", tracing.EnvOTLPTracesEndpoint, tracing.EnvOTLPEndpoint))
+	cmd.PersistentFlags().StringVarP(&o.VersionsOverlayRepo, OptionVersionsOverlayRepo, "", os.Getenv("JX_VERSIONS_OVERLAY_REPO"), "A team-local git repository of versions which override the versions in the version stream. Defaults to $JX_VERSIONS_OVERLAY_REPO")
+	cmd.PersistentFlags().StringVarP(&o.VersionsOverlayRef, OptionVersionsOverlayRef, "", os.Getenv("JX_VERSIONS_OVERLAY_REF"), "The git reference of the versions overlay repository. Defaults to $JX_VERSIONS_OVERLAY_REF")
+	tlsPolicy := util.TLSPolicyFromEnv()
+	cmd.PersistentFlags().StringVarP(&o.TLSMinVersion, OptionTLSMinVersion, "", tlsPolicy.MinVersion, fmt.Sprintf("The minimum TLS version such as 1.2 or 1.3 of the HTTPS connections made by the shared HTTP clients of jx and of the HTTPS served by the controllers. Clients with their own transports, such as the Jenkins, AWS and GCP clients, and the binaries jx runs are not covered. Defaults to $%s", util.TLSMinVersionEnvVar))
+	cmd.PersistentFlags().StringSliceVarP(&o.TLSCipherSuites, OptionTLSCipherSuites, "", tlsPolicy.CipherSuites, fmt.Sprintf("The TLS 1.2 cipher suites such as TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 of the HTTPS connections covered by --tls-min-version. Defaults to $%s", util.TLSCipherSuitesEnvVar))
 
 	o.Cmd = cmd
 }
@@ -521,11 +563,28 @@ func (o *CommonOptions) JXClientDevAndAdminNamespace() (versioned.Interface, str
 // Git returns the git client
 func (o *CommonOptions) Git() gits.Gitter {
 	if o.git == nil {
-		o.git = gits.NewGitCLI()
+		o.git = gits.WithContext(gits.NewGitCLI(), o.ctx)
 	}
 	return o.git
 }
 
+// Context returns the context of the command containing its tracing span so that the spans of the operations it runs
+// are its children
+func (o *CommonOptions) Context() context.Context {
+	if o.ctx == nil {
+		return context.Background()
+	}
+	return o.ctx
+}
+
+// SetContext sets the context of the command which the git clones and other traced operations are run with
+func (o *CommonOptions) SetContext(ctx context.Context) {
+	o.ctx = ctx
+	if o.git != nil {
+		o.git = gits.WithContext(o.git, ctx)
+	}
+}
+
 // SetGit sets the git client
 func (o *CommonOptions) SetGit(git gits.Gitter) {
 	o.git = git
@@ -983,7 +1042,7 @@ func (o *CommonOptions) PickGitRemoteURL(config *gitcfg.Config) (string, error)
 			Message: "Choose a remote git URL:",
 			Options: urls,
 		}
-		err := survey.AskOne(prompt, &url, nil, surveyOpts)
+		err := util.AskOne(prompt, &url, nil, surveyOpts)
 		if err != nil {
 			return "", err
 		}
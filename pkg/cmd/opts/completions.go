@@ -0,0 +1,94 @@
+package opts
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/cloud"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CompletionFunc completes the arguments or flag values of a command in the shell
+type CompletionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// CompleteContexts completes the names of the kube contexts in the kube config
+func (o *CommonOptions) CompleteContexts(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	config, _, err := o.Kube().LoadConfig()
+	if err != nil || config == nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	names := []string{}
+	for name := range config.Contexts {
+		names = append(names, name)
+	}
+	return completions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// CompleteNamespaces completes the names of the namespaces in the current cluster
+func (o *CommonOptions) CompleteNamespaces(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	namespaces, err := kubeClient.CoreV1().Namespaces().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	names := []string{}
+	for _, ns := range namespaces.Items {
+		names = append(names, ns.Name)
+	}
+	return completions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// CompleteProviders completes the names of the Kubernetes providers
+func (o *CommonOptions) CompleteProviders(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completions(cloud.KubernetesProviders, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// CompleteApps completes the names of the apps installed in the development namespace
+func (o *CommonOptions) CompleteApps(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	apps, err := jxClient.JenkinsV1().Apps(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	names := []string{}
+	for _, app := range apps.Items {
+		name := app.Labels[helm.LabelAppName]
+		if name == "" {
+			name = app.Name
+		}
+		names = append(names, name)
+	}
+	return completions(names, toComplete, args...), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completions returns the sorted, distinct values which start with the text being completed excluding any values
+// which have already been specified
+func completions(values []string, toComplete string, exclude ...string) []string {
+	seen := map[string]bool{}
+	for _, e := range exclude {
+		seen[e] = true
+	}
+	answer := []string{}
+	for _, v := range values {
+		if !seen[v] && strings.HasPrefix(v, toComplete) {
+			seen[v] = true
+			answer = append(answer, v)
+		}
+	}
+	sort.Strings(answer)
+	return answer
+}
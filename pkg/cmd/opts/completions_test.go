@@ -0,0 +1,79 @@
+// +build unit
+
+package opts_test
+
+import (
+	"testing"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	jxfake "github.com/jenkins-x/jx-api/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	kubemocks "github.com/jenkins-x/jx/v2/pkg/kube/mocks"
+	. "github.com/petergtz/pegomock"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestCompleteContexts(t *testing.T) {
+	RegisterMockTestingT(t)
+	kuber := kubemocks.NewMockKuber()
+	config := api.NewConfig()
+	config.Contexts = map[string]*api.Context{
+		"gke_myproject_europe-west1-b_dev":     {},
+		"gke_myproject_europe-west1-b_staging": {},
+		"minikube":                             {},
+	}
+	When(kuber.LoadConfig()).ThenReturn(config, clientcmd.NewDefaultPathOptions(), nil)
+	commonOpts := &opts.CommonOptions{}
+	commonOpts.SetKube(kuber)
+
+	values, directive := commonOpts.CompleteContexts(nil, nil, "gke")
+	assert.Equal(t, []string{"gke_myproject_europe-west1-b_dev", "gke_myproject_europe-west1-b_staging"}, values)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+	values, _ = commonOpts.CompleteContexts(nil, []string{"minikube"}, "")
+	assert.Empty(t, values, "only a single context can be specified")
+}
+
+func TestCompleteNamespaces(t *testing.T) {
+	commonOpts := &opts.CommonOptions{}
+	commonOpts.SetKubeClient(fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "jx"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "jx-staging"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+	))
+
+	values, directive := commonOpts.CompleteNamespaces(nil, nil, "jx")
+	assert.Equal(t, []string{"jx", "jx-staging"}, values)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+}
+
+func TestCompleteProviders(t *testing.T) {
+	commonOpts := &opts.CommonOptions{}
+
+	values, _ := commonOpts.CompleteProviders(nil, nil, "ek")
+	assert.Equal(t, []string{"eks"}, values)
+}
+
+func TestCompleteApps(t *testing.T) {
+	commonOpts := &opts.CommonOptions{}
+	commonOpts.SetDevNamespace("jx")
+	commonOpts.SetJxClient(jxfake.NewSimpleClientset(
+		&v1.App{ObjectMeta: metav1.ObjectMeta{Name: "jx-app-prometheus", Namespace: "jx", Labels: map[string]string{helm.LabelAppName: "jx-app-prometheus"}}},
+		&v1.App{ObjectMeta: metav1.ObjectMeta{Name: "jx-app-jacoco", Namespace: "jx", Labels: map[string]string{helm.LabelAppName: "jx-app-jacoco"}}},
+		&v1.App{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "other"}},
+	))
+
+	values, directive := commonOpts.CompleteApps(nil, nil, "jx-app-")
+	assert.Equal(t, []string{"jx-app-jacoco", "jx-app-prometheus"}, values)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+	values, _ = commonOpts.CompleteApps(nil, []string{"jx-app-jacoco"}, "")
+	assert.Equal(t, []string{"jx-app-prometheus"}, values, "apps which have already been specified should not be completed")
+}
@@ -19,6 +19,10 @@ work
 	DefaultIngressNamesapce = "kube-system"
 	// DefaultIngressServiceName default name for ingress controller service and deployment
 	DefaultIngressServiceName = "jxing-nginx-ingress-controller"
+	// DefaultIngressReleaseName default helm release name of the ingress controller
+	DefaultIngressReleaseName = "jxing"
+	// DefaultIngressChart default helm chart of the ingress controller
+	DefaultIngressChart = "stable/nginx-ingress"
 
 	// DeployKindKnative for knative serve based deployments
 	DeployKindKnative = "knative"
@@ -1,13 +1,17 @@
 package opts
 
 import (
+	"fmt"
 	"os"
 	"strings"
 
 	"github.com/jenkins-x/jx-logging/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/docker"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/registries"
+	"github.com/spf13/cobra"
 )
 
 // GetDockerRegistryOrg parses the docker registry organisation from various places
@@ -58,3 +62,40 @@ func (o *CommonOptions) GetDockerRegistry(projectConfig *config.ProjectConfig) s
 	}
 	return dockerRegistry
 }
+
+// RegistryFlags the flags selecting a container registry
+type RegistryFlags struct {
+	Kind     string
+	Host     string
+	Username string
+	Password string
+	Region   string
+}
+
+// AddRegistryFlags adds the flags selecting a container registry
+func (f *RegistryFlags) AddRegistryFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&f.Kind, "kind", "k", "", fmt.Sprintf("The kind of the registry, one of: %s. Defaults to the kind detected from the host", strings.Join(registries.Kinds, ", ")))
+	cmd.Flags().StringVarP(&f.Host, "host", "", "", "The host of the registry. Defaults to the docker registry of the team")
+	cmd.Flags().StringVarP(&f.Username, "username", "", "", "The user of Harbor or Docker Hub. Defaults to the user of the registry in the docker config.json")
+	cmd.Flags().StringVarP(&f.Password, "password", "", "", "The password or access token of the user. Defaults to the password of the registry in the docker config.json")
+	cmd.Flags().StringVarP(&f.Region, "region", "", "", "The region of an ECR registry. Defaults to the region of the host")
+}
+
+// NewRegistry creates the container registry of the flags. The host defaults to the docker registry of the team and the
+// credentials default to the credentials of the host in the docker config.json
+func (o *CommonOptions) NewRegistry(flags *RegistryFlags) (registries.Registry, error) {
+	options := registries.Options{
+		Kind:     flags.Kind,
+		Host:     flags.Host,
+		Username: flags.Username,
+		Password: flags.Password,
+		Region:   flags.Region,
+	}
+	if options.Host == "" {
+		options.Host = o.GetDockerRegistry(nil)
+	}
+	if options.Username == "" {
+		options.Username, options.Password = docker.ConfigCredentials(options.Host)
+	}
+	return registries.NewRegistry(options)
+}
@@ -75,7 +75,7 @@ func (o *CommonOptions) GetDomain(client kubernetes.Interface, domain string, pr
 						Message: "Your custom DNS name: ",
 						Help:    "Enter your custom domain that we can use to setup a Route 53 ALIAS record to point at the ELB host: " + address,
 					}
-					err = survey.AskOne(prompt, &customDomain, nil, surveyOpts)
+					err = util.AskOne(prompt, &customDomain, nil, surveyOpts)
 					if err != nil {
 						return "", err
 					}
@@ -176,7 +176,7 @@ func (o *CommonOptions) GetDomain(client kubernetes.Interface, domain string, pr
 				Default: defaultDomain,
 				Help:    "Enter your custom domain that is used to generate Ingress rules, defaults to the magic DNS nip.io",
 			}
-			err := survey.AskOne(prompt, &domain,
+			err := util.AskOne(prompt, &domain,
 				survey.ComposeValidators(survey.Required, surveyutils.NoWhiteSpaceValidator()), surveyOpts)
 			if err != nil {
 				return "", err
@@ -3,8 +3,10 @@ package opts
 import (
 	jenkinsv1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/jenkins-x/jx/v2/pkg/kube/services"
+	"github.com/jenkins-x/jx/v2/pkg/util"
 )
 
 // RegisterEnvironmentCRD registers the CRD for environmnt
@@ -53,3 +55,34 @@ func (o *CommonOptions) ResolveChartMuseumURL() (string, error) {
 	}
 	return services.FindServiceURL(kubeClient, ns, kube.ServiceChartMuseum)
 }
+
+// RunInEnvironmentCluster runs the function against the remote cluster of the environment, using the kube config
+// stored for it in the dev namespace, so that the helm releases the function installs are applied there. The function
+// is run against the current cluster if the environment is in the current cluster
+func (o *CommonOptions) RunInEnvironmentCluster(env *jenkinsv1.Environment, fn func() error) error {
+	kubeClient, devNs, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	cluster, err := kube.EnvironmentCluster(kubeClient, devNs, env)
+	if err != nil {
+		return err
+	}
+	if cluster == nil {
+		return fn()
+	}
+	log.Logger().Infof("Applying to Environment %s in cluster %s", util.ColorInfo(env.Name), util.ColorInfo(cluster.Name))
+
+	if template, ok := o.Helm().(*helm.HelmTemplate); ok {
+		templateClient := template.KubeClient
+		template.KubeClient = cluster.KubeClient
+		defer func() {
+			template.KubeClient = templateClient
+		}()
+	}
+	o.kubeClient = cluster.KubeClient
+	defer func() {
+		o.kubeClient = kubeClient
+	}()
+	return cluster.Run(fn)
+}
@@ -40,7 +40,7 @@ func (o *CommonOptions) GetGoogleProjectID(defaultProject string) (string, error
 			Default: true,
 		}
 		flag := true
-		err = survey.AskOne(confirm, &flag, nil, surveyOpts)
+		err = util.AskOne(confirm, &flag, nil, surveyOpts)
 		if err != nil {
 			return "", err
 		}
@@ -67,7 +67,7 @@ func (o *CommonOptions) GetGoogleProjectID(defaultProject string) (string, error
 			prompts.Default = currentProject
 		}
 
-		err := survey.AskOne(prompts, &projectId, nil, surveyOpts)
+		err := util.AskOne(prompts, &projectId, nil, surveyOpts)
 		if err != nil {
 			return "", err
 		}
@@ -117,7 +117,7 @@ func (o *CommonOptions) GetGoogleZoneWithDefault(projectId string, defaultZone s
 	}
 	zone := ""
 	surveyOpts := survey.WithStdio(o.In, o.Out, o.Err)
-	err = survey.AskOne(prompts, &zone, nil, surveyOpts)
+	err = util.AskOne(prompts, &zone, nil, surveyOpts)
 	if err != nil {
 		return "", err
 	}
@@ -138,7 +138,7 @@ func (o *CommonOptions) GetGoogleRegionWithDefault(projectId string, defaultRegi
 	}
 	region := ""
 	surveyOpts := survey.WithStdio(o.In, o.Out, o.Err)
-	err = survey.AskOne(prompts, &region, nil, surveyOpts)
+	err = util.AskOne(prompts, &region, nil, surveyOpts)
 	if err != nil {
 		return "", err
 	}
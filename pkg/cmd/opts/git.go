@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/jenkins-x/jx/v2/pkg/config"
 	"github.com/jenkins-x/jx/v2/pkg/kube/cluster"
@@ -14,6 +16,7 @@ import (
 	"github.com/jenkins-x/jx-logging/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/auth"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/io/secrets"
 	"github.com/jenkins-x/jx/v2/pkg/issues"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/jenkins-x/jx/v2/pkg/util"
@@ -159,7 +162,7 @@ func AddGitRepoOptionsArguments(cmd *cobra.Command, repositoryOptions *gits.GitR
 func AddGitRepoOptionsArgumentsWithDefaultProviderURL(cmd *cobra.Command, repositoryOptions *gits.GitRepositoryOptions, defaultProviderURL string) {
 	cmd.Flags().StringVarP(&repositoryOptions.ServerURL, "git-provider-url", "", defaultProviderURL, "The Git server URL to create new Git repositories inside")
 	cmd.Flags().StringVarP(&repositoryOptions.ServerKind, "git-provider-kind", "", "",
-		"Kind of Git server. If not specified, kind of server will be autodetected from Git provider URL. Possible values: bitbucketcloud, bitbucketserver, gitea, gitlab, github, fakegit")
+		"Kind of Git server. If not specified, kind of server will be autodetected from Git provider URL. Possible values: azuredevops, bitbucketcloud, bitbucketserver, codecommit, gitea, gitlab, github, fakegit")
 	cmd.Flags().StringVarP(&repositoryOptions.Username, "git-username", "", "", "The Git username to use for creating new Git repositories")
 	cmd.Flags().StringVarP(&repositoryOptions.ApiToken, "git-api-token", "", "", "The Git API token to use for creating new Git repositories")
 	cmd.Flags().BoolVarP(&repositoryOptions.Public, "git-public", "", false, "Create new Git repositories as public")
@@ -449,3 +452,68 @@ func (o *CommonOptions) DisableFeatures(orgs []string, includes []string, exclud
 	}
 	return nil
 }
+
+// ConfigureGitSigning configures the git client to sign the commits it creates from the JX_GIT_SIGNING* environment
+// variables. The signing key may be a vault:path:key or local:path:key URI which is read from the secrets backend. If
+// required is true creating unsigned commits fails
+func (o *CommonOptions) ConfigureGitSigning(required bool) error {
+	signing := gits.SigningOptionsFromEnv()
+	signing.Required = signing.Required || required
+	if strings.HasPrefix(signing.KeyFile, "vault:") || strings.HasPrefix(signing.KeyFile, "local:") {
+		fileName, err := o.writeSigningKeySecret(signing.KeyFile)
+		if err != nil {
+			return errors.Wrapf(err, "reading the git signing key %s from the secrets backend", signing.KeyFile)
+		}
+		signing.KeyFile = fileName
+	}
+	err := signing.Validate()
+	if err != nil {
+		return err
+	}
+	if !gits.ConfigureSigning(o.Git(), signing) && (signing.Enabled() || signing.Required) {
+		return errors.New("the git client does not support signing commits")
+	}
+	return nil
+}
+
+// writeSigningKeySecret writes the signing key referenced by the secret URI to a file only readable by the current user
+func (o *CommonOptions) writeSigningKeySecret(uri string) (string, error) {
+	parts := strings.Split(uri, ":")
+	if len(parts) != 3 {
+		return "", errors.Errorf("cannot parse %q as scheme:path:key", uri)
+	}
+	client, err := o.GetSecretURLClient(secrets.AutoLocationKind)
+	if err != nil {
+		return "", errors.Wrap(err, "creating the secret URL client")
+	}
+	secret, err := client.Read(parts[1])
+	if err != nil {
+		return "", errors.Wrapf(err, "reading the secret %s", parts[1])
+	}
+	value, ok := secret[parts[2]]
+	if !ok {
+		return "", errors.Errorf("unable to find %q in the secret %s", parts[2], parts[1])
+	}
+	key, err := util.AsString(value)
+	if err != nil {
+		return "", errors.Wrapf(err, "converting %s to a string", parts[2])
+	}
+	configDir, err := util.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(configDir, "signing")
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return "", errors.Wrapf(err, "creating the directory %s", dir)
+	}
+	fileName := filepath.Join(dir, parts[2])
+	if !strings.HasSuffix(key, "\n") {
+		key += "\n"
+	}
+	err = ioutil.WriteFile(fileName, []byte(key), 0600)
+	if err != nil {
+		return "", errors.Wrapf(err, "writing the signing key to %s", fileName)
+	}
+	return fileName, nil
+}
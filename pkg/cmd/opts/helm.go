@@ -401,6 +401,20 @@ func (o *CommonOptions) InstallChartWithOptionsAndTimeout(options helm.InstallCh
 			return err
 		}
 	}
+	if options.Version == "" && o.VersionsOverlayRepo != "" {
+		overlays, err := o.VersionsOverlays()
+		if err != nil {
+			return err
+		}
+		resolver := &versionstream.VersionResolver{
+			VersionsDir: options.VersionsDir,
+			Overlays:    overlays,
+		}
+		options.Version, err = resolver.StableVersionNumber(versionstream.KindChart, options.Chart)
+		if err != nil {
+			return err
+		}
+	}
 	secretURLClient, err := o.GetSecretURLClient(secrets.AutoLocationKind)
 	if err != nil {
 		return errors.Wrap(err, "failed to create a Secret RL client")
@@ -556,7 +570,7 @@ func (o *CommonOptions) AddChartRepos(dir string, helmBinary string, chartRepos
 		if requirements != nil {
 			changed := false
 			// lets replace the release chart museum URL if required
-			chartRepoURL := o.ReleaseChartRepositoryURL()
+			chartRepoURL := helm.ChartRepositoryReadURL(o.ReleaseChartRepositoryURL())
 			if chartRepoURL != "" && chartRepoURL != DefaultChartRepo {
 				for i := range requirements.Dependencies {
 					if requirements.Dependencies[i].Repository == DefaultChartRepo {
@@ -567,7 +581,7 @@ func (o *CommonOptions) AddChartRepos(dir string, helmBinary string, chartRepos
 			}
 			for _, dep := range requirements.Dependencies {
 				repo := dep.Repository
-				if repo != "" && !util.StringMapHasValue(installedChartRepos, repo) && repo != DefaultChartRepo && !strings.HasPrefix(repo, "file:") && !strings.HasPrefix(repo, "alias:") && !strings.HasPrefix(repo, "@") {
+				if repo != "" && !util.StringMapHasValue(installedChartRepos, repo) && repo != DefaultChartRepo && !strings.HasPrefix(repo, "file:") && !strings.HasPrefix(repo, "oci:") && !strings.HasPrefix(repo, "alias:") && !strings.HasPrefix(repo, "@") {
 					name, err := o.AddHelmBinaryRepoIfMissing(repo, "", "", "")
 					if err != nil {
 						return errors.Wrapf(err, "failed to add Helm repository '%s'", repo)
@@ -766,26 +780,28 @@ func (o *CommonOptions) HelmInitRecursiveDependencyBuild(dir string, chartRepos
 
 // DefaultReleaseCharts returns the default release charts
 func (o *CommonOptions) DefaultReleaseCharts() []string {
-	releasesURL := o.ReleaseChartRepositoryURL()
+	releasesURL := helm.ChartRepositoryReadURL(o.ReleaseChartRepositoryURL())
 	answer := []string{
 		kube.DefaultChartMuseumURL,
 	}
-	if releasesURL != "" {
+	// charts in OCI registries are referenced directly rather than via a helm repository
+	if releasesURL != "" && helm.ChartRepositoryKind(releasesURL) != helm.ChartRepositoryKindOCI {
 		answer = append(answer, releasesURL)
 	}
 	return answer
 }
 
-// DefaultChartRepositoryURL returns the default chart repository URL
+// DefaultChartRepositoryURL returns the default chart repository URL which helm downloads the released charts from
 func (o *CommonOptions) DefaultChartRepositoryURL() string {
-	answer := o.ReleaseChartRepositoryURL()
+	answer := helm.ChartRepositoryReadURL(o.ReleaseChartRepositoryURL())
 	if answer == "" {
 		answer = DefaultChartRepo
 	}
 	return answer
 }
 
-// ReleaseChartRepositoryURL returns the chart repository URL for releases
+// ReleaseChartRepositoryURL returns the chart repository URL for releases which may be a chartmuseum URL, a gs://,
+// s3:// or azblob:// bucket URL or an oci:// registry URL
 func (o *CommonOptions) ReleaseChartRepositoryURL() string {
 	if o.RemoteCluster {
 		return ""
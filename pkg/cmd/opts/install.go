@@ -579,7 +579,7 @@ func (o *CommonOptions) GetCloudProvider(p string) (string, error) {
 			Help:    "Cloud service providing the Kubernetes cluster, Google (GKE), Oracle (OKE), Azure (AKS)",
 		}
 
-		err := survey.AskOne(prompt, &p, nil, surveyOpts)
+		err := util.AskOne(prompt, &p, nil, surveyOpts)
 		if err != nil {
 			return "", err
 		}
@@ -634,7 +634,7 @@ func (o *CommonOptions) InstallMissingDependencies(providerSpecificDeps []string
 			Options: deps,
 			Default: deps,
 		}
-		err := survey.AskOne(prompt, &install, nil, surveyOpts)
+		err := util.AskOne(prompt, &install, nil, surveyOpts)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,35 @@
+package opts
+
+import (
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/logrus-stackdriver-formatter/pkg/stackdriver"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// LogFormatText logs coloured human readable text
+	LogFormatText = "text"
+	// LogFormatJSON logs a JSON object per entry for CI systems and log aggregators. The entries include the fields
+	// describing the running command set via util.SetLogField
+	LogFormatJSON = "json"
+	// LogFormatStackdriver logs a JSON object per entry in the format of Stackdriver as supported by $JX_LOG_FORMAT
+	LogFormatStackdriver = "stackdriver"
+)
+
+// SetLogFormat sets the format of the log entries to text, json or stackdriver
+func SetLogFormat(format string) error {
+	// lets make sure the logger is initialised first as that sets the format from $JX_LOG_FORMAT
+	log.Logger()
+	switch format {
+	case LogFormatText:
+		logrus.SetFormatter(log.NewJenkinsXTextFormat())
+	case LogFormatJSON:
+		logrus.SetFormatter(&logrus.JSONFormatter{})
+	case LogFormatStackdriver:
+		logrus.SetFormatter(stackdriver.NewFormatter())
+	default:
+		return errors.Errorf("invalid log format '%s', must be one of %s, %s or %s", format, LogFormatText, LogFormatJSON, LogFormatStackdriver)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// +build unit
+
+package opts_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogFields(t *testing.T) {
+	defer func() {
+		util.SetLogField(util.LogFieldCommand, "")
+		util.SetLogField(util.LogFieldOperation, "")
+		err := opts.SetLogFormat(opts.LogFormatText)
+		assert.NoError(t, err)
+	}()
+
+	err := opts.SetLogFormat(opts.LogFormatJSON)
+	require.NoError(t, err)
+	util.SetLogField(util.LogFieldCommand, "jx verify install")
+	util.SetLogField(util.LogFieldOperation, "dns")
+
+	out := log.CaptureOutput(func() {
+		log.Logger().WithField(util.LogFieldOperation, "webhook").Info("checking")
+		endOperation := util.StartLogOperation("helm install jx")
+		log.Logger().Info("installing")
+		endOperation()
+		log.Logger().Info("verified")
+		util.SetLogField(util.LogFieldOperation, "")
+		log.Logger().Info("done")
+	})
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 4)
+
+	entry := map[string]interface{}{}
+	err = json.Unmarshal([]byte(lines[0]), &entry)
+	require.NoError(t, err)
+	assert.Equal(t, "checking", entry["msg"])
+	assert.Equal(t, "jx verify install", entry[util.LogFieldCommand])
+	assert.Equal(t, "webhook", entry[util.LogFieldOperation], "the fields of the entry should take precedence")
+
+	entry = map[string]interface{}{}
+	err = json.Unmarshal([]byte(lines[1]), &entry)
+	require.NoError(t, err)
+	assert.Equal(t, "helm install jx", entry[util.LogFieldOperation])
+
+	entry = map[string]interface{}{}
+	err = json.Unmarshal([]byte(lines[2]), &entry)
+	require.NoError(t, err)
+	assert.Equal(t, "dns", entry[util.LogFieldOperation], "ending a nested operation should restore the outer one")
+
+	entry = map[string]interface{}{}
+	err = json.Unmarshal([]byte(lines[3]), &entry)
+	require.NoError(t, err)
+	assert.Equal(t, "jx verify install", entry[util.LogFieldCommand])
+	assert.NotContains(t, entry, util.LogFieldOperation)
+
+	err = opts.SetLogFormat(opts.LogFormatText)
+	require.NoError(t, err)
+	out = log.CaptureOutput(func() {
+		log.Logger().Info("text")
+	})
+	assert.Equal(t, "text\n", out, "the text format should not include the fields")
+
+	err = opts.SetLogFormat(opts.LogFormatStackdriver)
+	require.NoError(t, err)
+	out = log.CaptureOutput(func() {
+		log.Logger().Info("stackdriver")
+	})
+	entry = map[string]interface{}{}
+	err = json.Unmarshal([]byte(strings.TrimSpace(out)), &entry)
+	require.NoError(t, err, "the stackdriver format should log JSON but got %s", out)
+	assert.Equal(t, "stackdriver", entry["message"])
+
+	assert.Error(t, opts.SetLogFormat("xml"))
+}
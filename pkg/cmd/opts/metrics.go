@@ -0,0 +1,72 @@
+package opts
+
+import (
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/metrics"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// OptionMetricsPort the port the Prometheus metrics of a controller are served on
+	OptionMetricsPort = "metrics-port"
+)
+
+// MetricsOptions the options of the Prometheus metrics exposed by the controllers jx runs in the cluster
+type MetricsOptions struct {
+	// MetricsPort the port the metrics are served on, the metrics are disabled if it is zero
+	MetricsPort int
+	// ServiceMonitor creates a ServiceMonitor so that the Prometheus operator scrapes the metrics
+	ServiceMonitor bool
+	// Selector the label selector of the pods of the controller, defaults to app=<controller name>
+	Selector string
+}
+
+// AddMetricsFlags adds the flags of the metrics to the command
+func (m *MetricsOptions) AddMetricsFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVarP(&m.MetricsPort, OptionMetricsPort, "", 0, "The TCP port to serve the Prometheus metrics on. The metrics are disabled if not specified")
+	cmd.Flags().BoolVarP(&m.ServiceMonitor, "service-monitor", "", false, "Creates a ServiceMonitor scraping the metrics if the Prometheus operator is installed")
+	cmd.Flags().StringVarP(&m.Selector, "metrics-selector", "", "", "The label selector of the pods of the controller used by the metrics service. Defaults to app=<controller name>")
+}
+
+// StartMetrics serves the metrics of the named controller if a metrics port is specified and creates its
+// ServiceMonitor when requested
+func (o *CommonOptions) StartMetrics(m *MetricsOptions, name string, ns string) error {
+	if m.MetricsPort <= 0 {
+		return nil
+	}
+	metrics.Serve("", m.MetricsPort)
+	if !m.ServiceMonitor {
+		return nil
+	}
+	selector := map[string]string{"app": name}
+	if m.Selector != "" {
+		var err error
+		selector, err = labels.ConvertSelectorToLabelsMap(m.Selector)
+		if err != nil {
+			return errors.Wrapf(err, "parsing the metrics selector %s", m.Selector)
+		}
+	}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return errors.Wrap(err, "creating the kube client")
+	}
+	apiClient, err := o.ApiExtensionsClient()
+	if err != nil {
+		return errors.Wrap(err, "creating the api extensions client")
+	}
+	dynamicClient, _, err := o.GetFactory().CreateDynamicClient()
+	if err != nil {
+		return errors.Wrap(err, "creating the dynamic client")
+	}
+	installed, err := metrics.EnsureServiceMonitor(kubeClient, apiClient, dynamicClient, name, ns, selector, m.MetricsPort)
+	if err != nil {
+		return err
+	}
+	if !installed {
+		log.Logger().Warnf("Not creating a ServiceMonitor for %s as the Prometheus operator is not installed", util.ColorInfo(name))
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package opts
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// OptionOutput the flag used to render the result of a command in a machine readable format
+	OptionOutput = "output"
+
+	// OutputJSON renders the result as JSON
+	OutputJSON = "json"
+	// OutputYAML renders the result as YAML
+	OutputYAML = "yaml"
+	// OutputGoTemplatePrefix renders the result using the go template following the prefix
+	OutputGoTemplatePrefix = "go-template="
+)
+
+// AddOutputFlag adds the flag to render the result of the command as json, yaml or via a go template rather than as
+// human readable text
+func (o *CommonOptions) AddOutputFlag(cmd *cobra.Command, output *string) {
+	cmd.Flags().StringVarP(output, OptionOutput, "o", "", "The output format: one of json, yaml or go-template=<template>. Defaults to human readable text")
+}
+
+// RedirectLogsForOutput logs to the error output if the result of the command is rendered in a machine readable format
+// so that the standard output only contains the rendered result
+func (o *CommonOptions) RedirectLogsForOutput(format string) {
+	if format == "" {
+		return
+	}
+	if o.Err != nil {
+		log.SetOutput(o.Err)
+	} else {
+		log.SetOutput(os.Stderr)
+	}
+}
+
+// RenderOutput renders the value to the standard output in the given format
+func (o *CommonOptions) RenderOutput(format string, value interface{}) error {
+	return RenderOutput(o.Out, format, value)
+}
+
+// RenderOutput renders the value to the writer in the given format. Go templates are evaluated against the JSON
+// representation of the value so that they use the same field names as the json and yaml formats
+func RenderOutput(out io.Writer, format string, value interface{}) error {
+	switch {
+	case format == OutputJSON:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return errors.Wrap(err, "marshalling the output to JSON")
+		}
+		_, err = out.Write(data)
+		return err
+	case format == OutputYAML:
+		data, err := yaml.Marshal(value)
+		if err != nil {
+			return errors.Wrap(err, "marshalling the output to YAML")
+		}
+		_, err = out.Write(data)
+		return err
+	case strings.HasPrefix(format, OutputGoTemplatePrefix):
+		text := strings.TrimPrefix(format, OutputGoTemplatePrefix)
+		if text == "" {
+			return errors.New("no template specified for the go-template output format")
+		}
+		tmpl, err := template.New("output").Parse(text)
+		if err != nil {
+			return errors.Wrap(err, "parsing the output template")
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return errors.Wrap(err, "marshalling the output to JSON")
+		}
+		var generic interface{}
+		err = json.Unmarshal(data, &generic)
+		if err != nil {
+			return errors.Wrap(err, "unmarshalling the output")
+		}
+		return tmpl.Execute(out, generic)
+	default:
+		return fmt.Errorf("Unsupported output format: %s", format)
+	}
+}
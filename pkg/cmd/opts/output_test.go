@@ -0,0 +1,43 @@
+// +build unit
+
+package opts_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type outputTestValue struct {
+	Name  string   `json:"name"`
+	Items []string `json:"items"`
+}
+
+func TestRenderOutput(t *testing.T) {
+	t.Parallel()
+
+	value := &outputTestValue{Name: "jx", Items: []string{"a", "b"}}
+	testCases := []struct {
+		format   string
+		expected string
+	}{
+		{format: "json", expected: `{"name":"jx","items":["a","b"]}`},
+		{format: "yaml", expected: "items:\n- a\n- b\nname: jx\n"},
+		{format: "go-template={{.name}}:{{range .items}} {{.}}{{end}}", expected: "jx: a b"},
+	}
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		err := opts.RenderOutput(&buf, tc.format, value)
+		require.NoError(t, err, "format %s", tc.format)
+		assert.Equal(t, tc.expected, buf.String(), "format %s", tc.format)
+	}
+
+	for _, format := range []string{"", "table", "go-template=", "go-template={{.name"} {
+		var buf bytes.Buffer
+		err := opts.RenderOutput(&buf, format, value)
+		assert.Error(t, err, "format %s", format)
+	}
+}
@@ -120,6 +120,23 @@ func (o *CommonOptions) GetPluginCommandGroups(verifier extensions.PathVerifier)
 		}
 	}
 
+	// plugins installed from the plugin index of the version stream
+	if pluginBinDir, err := extensions.LocalPluginBinDir(); err == nil {
+		installed, err := extensions.InstalledPlugins(pluginBinDir)
+		if err != nil {
+			log.Logger().Debugf("Unable to load installed plugins because %v", err)
+		}
+		for _, p := range installed {
+			pathCommands.Commands = append(pathCommands.Commands, &templates.PluginCommand{
+				PluginSpec: jenkinsv1.PluginSpec{
+					SubCommand:  p.SubCommand(),
+					Description: p.Path,
+				},
+				Errors: make([]error, 0),
+			})
+		}
+	}
+
 	pcgs := templates.PluginCommandGroups{}
 	for _, g := range groups {
 		pcgs = append(pcgs, g)
@@ -0,0 +1,310 @@
+package opts
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/tracing"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultProgressStatusInterval how often the status of the current step is logged in batch mode
+	DefaultProgressStatusInterval = 30 * time.Second
+
+	spinnerDelay = 100 * time.Millisecond
+	// clearLine moves to the start of the line and erases it
+	clearLine = "\r\033[K"
+)
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// StepTiming the time taken by a step of a long running operation
+type StepTiming struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+	Failed   bool          `json:"failed,omitempty"`
+}
+
+// Progress reports the progress of the steps of a long running operation such as waiting for a LoadBalancer or a
+// chart install. When interactive it shows a spinner with the elapsed time and the current sub-step, otherwise it
+// logs the status of the current step periodically. The timings of the steps are recorded for the final summary
+type Progress struct {
+	Out            io.Writer
+	Interactive    bool
+	StatusInterval time.Duration
+	// Checkpoint records the resumable steps which completed so that they are skipped when resuming
+	Checkpoint *Checkpoint
+
+	lock    sync.Mutex
+	ctx     context.Context
+	step    string
+	subStep string
+	started time.Time
+	frame   int
+	timings []StepTiming
+}
+
+// NewProgress creates a progress reporter writing to the standard output which only shows a spinner if not in batch
+// mode and not logging JSON
+func (o *CommonOptions) NewProgress() *Progress {
+	var out io.Writer = os.Stdout
+	if o.Out != nil {
+		out = o.Out
+	}
+	return NewProgress(out, !o.BatchMode && o.LogFormat != LogFormatJSON)
+}
+
+// NewProgress creates a progress reporter writing to the given output
+func NewProgress(out io.Writer, interactive bool) *Progress {
+	return &Progress{
+		Out:            out,
+		Interactive:    interactive,
+		StatusInterval: DefaultProgressStatusInterval,
+	}
+}
+
+// Step runs the step reporting its progress and recording how long it took. A nil Progress just runs the step and a
+// step started within another step is reported as its sub-step. Each step is traced as a span when tracing is enabled
+// which is a child of the span of the outer step, if any
+func (p *Progress) Step(name string, fn func() error) error {
+	ctx, span := tracing.StartSpan(p.Context(), name)
+	outer := p.setContext(ctx)
+	endOperation := util.StartLogOperation(name)
+	err := p.runStep(name, fn)
+	endOperation()
+	p.setContext(outer)
+	span.End(err)
+	return err
+}
+
+// Context returns the context containing the tracing span of the current step to pass to the operations it runs
+func (p *Progress) Context() context.Context {
+	if p == nil {
+		return context.Background()
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.ctx == nil {
+		return context.Background()
+	}
+	return p.ctx
+}
+
+// setContext sets the context of the current step returning the previous one
+func (p *Progress) setContext(ctx context.Context) context.Context {
+	if p == nil {
+		return nil
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	outer := p.ctx
+	p.ctx = ctx
+	return outer
+}
+
+// ResumableStep runs the step like Step unless the Checkpoint of the Progress records that it completed already.
+// Only steps whose effects persist across runs, such as installing a chart, should be resumable
+func (p *Progress) ResumableStep(name string, fn func() error) error {
+	if p == nil || p.Checkpoint == nil {
+		return p.Step(name, fn)
+	}
+	if p.Checkpoint.IsCompleted(name) {
+		log.Logger().Infof("%s %s completed by a previous run", util.ColorInfo("✓"), name)
+		return nil
+	}
+	err := p.Step(name, fn)
+	if err != nil {
+		if cerr := p.Checkpoint.Fail(name); cerr != nil {
+			log.Logger().Warnf("Failed to record the failed step: %s", cerr.Error())
+		}
+		return err
+	}
+	if cerr := p.Checkpoint.Complete(name); cerr != nil {
+		log.Logger().Warnf("Failed to record the completed step: %s", cerr.Error())
+	}
+	return nil
+}
+
+func (p *Progress) runStep(name string, fn func() error) error {
+	if p == nil {
+		return fn()
+	}
+	p.lock.Lock()
+	if p.step != "" {
+		// a step within a step is reported as a sub-step of the outer step
+		outer := p.subStep
+		p.subStep = name
+		p.lock.Unlock()
+		err := fn()
+		p.lock.Lock()
+		p.subStep = outer
+		p.lock.Unlock()
+		return err
+	}
+	p.step = name
+	p.subStep = ""
+	p.started = time.Now()
+	p.lock.Unlock()
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	if p.Interactive {
+		previous := logrus.StandardLogger().Out
+		log.SetOutput(&progressLogWriter{progress: p, out: previous})
+		defer log.SetOutput(previous)
+		go p.spin(done, stopped)
+	} else {
+		log.Logger().Infof("%s...", name)
+		go p.logStatus(done, stopped)
+	}
+
+	err := fn()
+
+	close(done)
+	<-stopped
+
+	p.lock.Lock()
+	elapsed := time.Since(p.started)
+	p.timings = append(p.timings, StepTiming{Name: name, Duration: elapsed, Failed: err != nil})
+	p.step = ""
+	p.subStep = ""
+	p.lock.Unlock()
+
+	if err != nil {
+		log.Logger().Errorf("%s failed after %s", name, formatElapsed(elapsed))
+	} else {
+		log.Logger().Infof("%s %s in %s", util.ColorInfo("✓"), name, formatElapsed(elapsed))
+	}
+	return err
+}
+
+// SubStep updates the sub-step of the current step such as the resource being waited on. A nil Progress is ignored
+func (p *Progress) SubStep(format string, args ...interface{}) {
+	if p == nil {
+		return
+	}
+	p.lock.Lock()
+	p.subStep = fmt.Sprintf(format, args...)
+	p.lock.Unlock()
+	if !p.Interactive {
+		log.Logger().Infof("  %s", p.Status())
+	}
+}
+
+// Status returns the current step, sub-step and elapsed time
+func (p *Progress) Status() string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.status()
+}
+
+func (p *Progress) status() string {
+	text := p.step
+	if p.subStep != "" {
+		text += ": " + p.subStep
+	}
+	return fmt.Sprintf("%s (%s)", text, formatElapsed(time.Since(p.started)))
+}
+
+// Timings returns the timings of the completed steps in the order they ran
+func (p *Progress) Timings() []StepTiming {
+	if p == nil {
+		return nil
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return append([]StepTiming{}, p.timings...)
+}
+
+// LogTimings logs how long each of the completed steps took
+func (p *Progress) LogTimings() {
+	timings := p.Timings()
+	if len(timings) == 0 {
+		return
+	}
+	width := 0
+	for _, t := range timings {
+		if len(t.Name) > width {
+			width = len(t.Name)
+		}
+	}
+	var total time.Duration
+	log.Logger().Info("Step timings:")
+	for _, t := range timings {
+		total += t.Duration
+		suffix := ""
+		if t.Failed {
+			suffix = " " + util.ColorError("failed")
+		}
+		log.Logger().Infof("  %s%s %8s%s", t.Name, strings.Repeat(" ", width-len(t.Name)), formatElapsed(t.Duration), suffix)
+	}
+	log.Logger().Infof("  %s%s %8s", "total", strings.Repeat(" ", width-len("total")), formatElapsed(total))
+}
+
+// spin renders the spinner and the status until done is closed
+func (p *Progress) spin(done <-chan struct{}, stopped chan<- struct{}) {
+	ticker := time.NewTicker(spinnerDelay)
+	defer ticker.Stop()
+	for {
+		p.lock.Lock()
+		fmt.Fprintf(p.Out, "%s%s %s", clearLine, util.ColorInfo(spinnerFrames[p.frame%len(spinnerFrames)]), p.status())
+		p.frame++
+		p.lock.Unlock()
+		select {
+		case <-done:
+			p.lock.Lock()
+			fmt.Fprint(p.Out, clearLine)
+			p.lock.Unlock()
+			close(stopped)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// logStatus logs the status periodically until done is closed
+func (p *Progress) logStatus(done <-chan struct{}, stopped chan<- struct{}) {
+	interval := p.StatusInterval
+	if interval <= 0 {
+		interval = DefaultProgressStatusInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			close(stopped)
+			return
+		case <-ticker.C:
+			log.Logger().Infof("  still running %s", p.Status())
+		}
+	}
+}
+
+// progressLogWriter clears the spinner line before writing a log entry so that the spinner is redrawn below it
+type progressLogWriter struct {
+	progress *Progress
+	out      io.Writer
+}
+
+func (w *progressLogWriter) Write(data []byte) (int, error) {
+	w.progress.lock.Lock()
+	defer w.progress.lock.Unlock()
+	fmt.Fprint(w.progress.Out, clearLine)
+	return w.out.Write(data)
+}
+
+func formatElapsed(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(time.Second).String()
+}
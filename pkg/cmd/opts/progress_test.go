@@ -0,0 +1,98 @@
+// +build unit
+
+package opts_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressRecordsStepTimings(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stdout)
+
+	var out bytes.Buffer
+	progress := opts.NewProgress(&out, false)
+	progress.StatusInterval = 10 * time.Millisecond
+
+	err := progress.Step("Waiting for the loadbalancer", func() error {
+		progress.SubStep("service %s", "nginx")
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+	require.NoError(t, err)
+
+	failure := errors.New("chart failed")
+	err = progress.Step("Installing the chart", func() error {
+		return progress.Step("nested", func() error {
+			assert.Contains(t, progress.Status(), "Installing the chart: nested")
+			return failure
+		})
+	})
+	assert.Equal(t, failure, err)
+
+	timings := progress.Timings()
+	require.Len(t, timings, 2, "the nested step should not be recorded")
+	assert.Equal(t, "Waiting for the loadbalancer", timings[0].Name)
+	assert.True(t, timings[0].Duration >= 50*time.Millisecond, "duration %s", timings[0].Duration)
+	assert.False(t, timings[0].Failed)
+	assert.Equal(t, "Installing the chart", timings[1].Name)
+	assert.True(t, timings[1].Failed)
+
+	text := logs.String()
+	assert.Contains(t, text, "Waiting for the loadbalancer: service nginx")
+	assert.Contains(t, text, "still running Waiting for the loadbalancer", "batch mode should log the status periodically")
+	assert.Empty(t, out.String(), "batch mode should not render a spinner")
+
+	logs.Reset()
+	progress.LogTimings()
+	assert.Contains(t, logs.String(), "Step timings:")
+	assert.Contains(t, logs.String(), "total")
+}
+
+func TestProgressInteractiveSpinner(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stdout)
+
+	var out bytes.Buffer
+	progress := opts.NewProgress(&out, true)
+
+	err := progress.Step("Installing the platform", func() error {
+		progress.SubStep("chart %s", "jenkins-x-platform")
+		time.Sleep(250 * time.Millisecond)
+		log.Logger().Info("a log line")
+		return nil
+	})
+	require.NoError(t, err)
+
+	rendered := out.String()
+	assert.Contains(t, rendered, "Installing the platform: chart jenkins-x-platform (")
+	assert.True(t, strings.HasSuffix(rendered, "\r\033[K"), "the spinner line should be cleared when the step completes")
+	assert.Contains(t, logs.String(), "a log line")
+	assert.Len(t, progress.Timings(), 1)
+}
+
+func TestNilProgress(t *testing.T) {
+	var progress *opts.Progress
+	called := false
+	err := progress.Step("step", func() error {
+		called = true
+		progress.SubStep("ignored")
+		return nil
+	})
+	require.NoError(t, err)
+	assert.True(t, called)
+	assert.Empty(t, progress.Timings())
+	progress.LogTimings()
+}
@@ -0,0 +1,86 @@
+package opts
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// OptionOverrideProtected the flag used to confirm a destructive command may touch a protected namespace
+	OptionOverrideProtected = "i-know-what-im-doing"
+
+	// AuditLogFile the file in the jx home dir which records overrides of protected namespaces
+	AuditLogFile = "audit.log"
+)
+
+// AddProtectedNamespaceFlag adds the flag to allow a destructive command to modify protected namespaces
+func AddProtectedNamespaceFlag(cmd *cobra.Command, override *bool) {
+	cmd.Flags().BoolVarP(override, OptionOverrideProtected, "", false, "Allows this command to modify protected namespaces such as kube-system or production environments. The override is recorded in the audit log")
+}
+
+// VerifyProtectedNamespaces returns an error if any of the given namespaces is protected unless the override flag is
+// specified in which case an audit entry is recorded for each protected namespace
+func (o *CommonOptions) VerifyProtectedNamespaces(action string, override bool, namespaces ...string) error {
+	kubeClient, devNs, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return errors.Wrap(err, "creating the kube client")
+	}
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return errors.Wrap(err, "creating the jx client")
+	}
+	protected, err := kube.GetProtectedNamespaces(kubeClient, jxClient, devNs)
+	if err != nil {
+		return errors.Wrap(err, "finding the protected namespaces")
+	}
+	matches := kube.FilterProtectedNamespaces(protected, namespaces...)
+	if len(matches) == 0 {
+		return nil
+	}
+	if !override {
+		return fmt.Errorf("refusing to %s as the namespaces %s are protected. Use --%s if you really want to do this",
+			action, strings.Join(matches, ", "), OptionOverrideProtected)
+	}
+	for _, ns := range matches {
+		log.Logger().Warnf("Overriding the protection of namespace %s to %s", util.ColorWarning(ns), action)
+		err = o.writeAuditEntry(action, ns)
+		if err != nil {
+			return errors.Wrapf(err, "recording the audit entry for namespace %s", ns)
+		}
+	}
+	return nil
+}
+
+func (o *CommonOptions) writeAuditEntry(action string, ns string) error {
+	dir, err := util.ConfigDir()
+	if err != nil {
+		return err
+	}
+	context := ""
+	config, _, err := o.Kube().LoadConfig()
+	if err == nil && config != nil {
+		context = kube.CurrentContextName(config)
+	}
+	user := os.Getenv("USER")
+	if user == "" {
+		user = os.Getenv("USERNAME")
+	}
+	line := fmt.Sprintf("%s user=%q context=%q namespace=%q action=%q\n", time.Now().UTC().Format(time.RFC3339), user, context, ns, action)
+
+	f, err := os.OpenFile(filepath.Join(dir, AuditLogFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, util.DefaultFileWritePermissions)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(line)
+	return err
+}
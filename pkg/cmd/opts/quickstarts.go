@@ -4,12 +4,15 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/blang/semver"
 	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx-logging/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/auth"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/jenkins-x/jx/v2/pkg/quickstarts"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/jenkins-x/jx/v2/pkg/version"
 	"github.com/jenkins-x/jx/v2/pkg/versionstream"
 	"github.com/pkg/errors"
 )
@@ -45,9 +48,105 @@ func (o *CommonOptions) LoadQuickStartsModel(gitHubOrganisations []string, ignor
 	if err != nil {
 		return nil, errors.Wrapf(err, "loading quickstarts: %v", quickstarts)
 	}
+	if !ignoreTeam {
+		catalogs, err := o.loadQuickStartCatalogs()
+		if err != nil {
+			return nil, err
+		}
+		for i := range catalogs {
+			err = o.loadQuickStartCatalog(model, &catalogs[i], config)
+			if err != nil {
+				log.Logger().Warnf("failed to load the quickstart catalog %s: %s", catalogs[i].Name, err.Error())
+			}
+		}
+	}
 	return model, nil
 }
 
+// LoadQuickStartCatalogsModel loads only the quickstarts of the given catalogs registered by the team
+func (o *CommonOptions) LoadQuickStartCatalogsModel(names []string) (*quickstarts.QuickstartModel, error) {
+	authConfigSvc, err := o.GitLocalAuthConfigService()
+	if err != nil {
+		return nil, err
+	}
+	config := authConfigSvc.Config()
+
+	catalogs, err := o.loadQuickStartCatalogs()
+	if err != nil {
+		return nil, err
+	}
+	model := quickstarts.NewQuickstartModel()
+	for _, name := range names {
+		var catalog *quickstarts.QuickstartCatalog
+		available := []string{}
+		for i := range catalogs {
+			available = append(available, catalogs[i].Name)
+			if catalogs[i].Name == name {
+				catalog = &catalogs[i]
+			}
+		}
+		if catalog == nil {
+			return nil, util.InvalidOption("catalog", name, available)
+		}
+		err = o.loadQuickStartCatalog(model, catalog, config)
+		if err != nil {
+			return nil, errors.Wrapf(err, "loading the quickstart catalog %s", name)
+		}
+	}
+	return model, nil
+}
+
+// loadQuickStartCatalogs loads the quickstart catalogs registered by the team
+func (o *CommonOptions) loadQuickStartCatalogs() ([]quickstarts.QuickstartCatalog, error) {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return nil, err
+	}
+	devEnv, err := kube.GetDevEnvironment(jxClient, ns)
+	if err != nil {
+		return nil, err
+	}
+	return kube.GetQuickstartCatalogs(devEnv)
+}
+
+// loadQuickStartCatalog adds the quickstarts of the catalog to the model
+func (o *CommonOptions) loadQuickStartCatalog(model *quickstarts.QuickstartModel, catalog *quickstarts.QuickstartCatalog, config *auth.AuthConfig) error {
+	if catalog.URL != "" {
+		file, err := quickstarts.LoadCatalogFile(catalog.URL)
+		if err != nil {
+			return err
+		}
+		var jxVersion *semver.Version
+		v, err := version.GetSemverVersion()
+		if err != nil {
+			log.Logger().Debugf("ignoring the minimum jx versions of the quickstarts: %s", err.Error())
+		} else {
+			jxVersion = &v
+		}
+		model.LoadCatalog(catalog.Name, file, jxVersion)
+		return nil
+	}
+	location := v1.QuickStartLocation{
+		GitURL:   catalog.GitURL,
+		GitKind:  catalog.GitKind,
+		Owner:    catalog.Owner,
+		Includes: catalog.Includes,
+		Excludes: catalog.Excludes,
+	}
+	if location.GitURL == "" {
+		location.GitURL = gits.GitHubURL
+	}
+	if len(location.Includes) == 0 {
+		location.Includes = []string{"*"}
+	}
+	catalogModel, err := o.LoadQuickStartsFromLocations([]v1.QuickStartLocation{location}, config)
+	if err != nil {
+		return err
+	}
+	model.AddCatalog(catalog.Name, catalogModel)
+	return nil
+}
+
 // LoadQuickStartsFromLocations Load all quickstarts from the given locatiotns
 func (o *CommonOptions) LoadQuickStartsFromLocations(locations []v1.QuickStartLocation, config *auth.AuthConfig) (*quickstarts.QuickstartModel, error) {
 	gitMap := map[string]map[string]v1.QuickStartLocation{}
@@ -389,7 +389,7 @@ func (o *UpgradeIngressOptions) getExistingIngressRules() (map[string]string, er
 			Default: true,
 		}
 		flag := true
-		err = survey.AskOne(confirm, &flag, nil, surveyOpts)
+		err = util.AskOne(confirm, &flag, nil, surveyOpts)
 		if err != nil {
 			return existingIngressNames, err
 		}
@@ -1,9 +1,12 @@
 package opts
 
 import (
+	"path/filepath"
 	"strings"
 
+	"github.com/jenkins-x/jx/v2/pkg/config"
 	"github.com/jenkins-x/jx/v2/pkg/versionstream/versionstreamrepo"
+	"github.com/pkg/errors"
 
 	"github.com/jenkins-x/jx/v2/pkg/versionstream"
 
@@ -18,8 +21,34 @@ func (o *CommonOptions) CreateVersionResolver(repo string, gitRef string) (*vers
 	if err != nil {
 		return nil, err
 	}
+	overlays, err := o.VersionsOverlays()
+	if err != nil {
+		return nil, err
+	}
 	return &versionstream.VersionResolver{
 		VersionsDir: versionsDir,
+		Overlays:    overlays,
+	}, nil
+}
+
+// VersionsOverlays returns the version stream overlays layered on top of the version stream, cloning the overlay
+// repository the first time it is used
+func (o *CommonOptions) VersionsOverlays() ([]versionstream.VersionLayer, error) {
+	if o.VersionsOverlayRepo == "" {
+		return nil, nil
+	}
+	if o.versionsOverlayDir == "" {
+		dir, err := versionstreamrepo.CloneOverlayRepo(o.VersionsOverlayRepo, o.VersionsOverlayRef, o.Git())
+		if err != nil {
+			return nil, err
+		}
+		o.versionsOverlayDir = dir
+	}
+	return []versionstream.VersionLayer{
+		{
+			Name: o.VersionsOverlayRepo,
+			Dir:  o.versionsOverlayDir,
+		},
 	}, nil
 }
 
@@ -134,14 +163,65 @@ func (o *CommonOptions) GetVersionNumber(kind versionstream.VersionKind, name, r
 	if err != nil {
 		return "", err
 	}
-	return versioner.StableVersionNumber(kind, name)
+	version, err := versioner.StableVersionNumber(kind, name)
+	if err != nil || version == "" || len(versioner.Overlays) == 0 {
+		return version, err
+	}
+	_, layer, err := versioner.StableVersionLayer(kind, name)
+	if err != nil {
+		return version, err
+	}
+	log.Logger().Debugf("using %s %s version %s from the %s version stream", string(kind), util.ColorInfo(name), util.ColorInfo(version), util.ColorInfo(layer))
+	return version, nil
 }
 
-// CloneJXVersionsRepo clones the jenkins-x versions repo to a local working dir
+// CloneJXVersionsRepo clones the jenkins-x versions repo to a local working dir. If a version stream bundle has been
+// specified it is extracted instead so that no git access is required
 func (o *CommonOptions) CloneJXVersionsRepo(versionRepository string, versionRef string) (string, string, error) {
+	if o.VersionsBundle != "" {
+		return o.extractVersionsBundle()
+	}
 	settings, err := o.TeamSettings()
 	if err != nil {
 		log.Logger().Debugf("Unable to load team settings because %v", err)
 	}
 	return versionstreamrepo.CloneJXVersionsRepo(versionRepository, versionRef, settings, o.Git(), o.BatchMode, o.AdvancedMode, o.GetIOFileHandles())
 }
+
+// extractVersionsBundle extracts the version stream bundle the first time it is used
+func (o *CommonOptions) extractVersionsBundle() (string, string, error) {
+	if o.versionsBundleDir == "" {
+		configDir, err := util.ConfigDir()
+		if err != nil {
+			return "", "", errors.Wrap(err, "determining the config dir")
+		}
+		dir := filepath.Join(configDir, "jenkins-x-versions-bundle")
+		metadata, err := versionstream.ExtractBundle(o.VersionsBundle, dir)
+		if err != nil {
+			return "", "", err
+		}
+		log.Logger().Debugf("using the version stream bundle %s extracted to %s", o.VersionsBundle, dir)
+		o.versionsBundleDir = dir
+		o.versionsBundleMeta = metadata
+	}
+	return o.versionsBundleDir, o.versionsBundleMeta.Ref, nil
+}
+
+// VersionStreamMetadata returns the URL, ref and commit of the version stream checked out in the given dir
+func (o *CommonOptions) VersionStreamMetadata(versionsDir string, versionRepository string, versionRef string) (*versionstream.BundleMetadata, error) {
+	if o.VersionsBundle != "" && o.versionsBundleMeta != nil {
+		return o.versionsBundleMeta, nil
+	}
+	commit, err := o.Git().GetLatestCommitSha(versionsDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting the latest commit of the version stream in %s", versionsDir)
+	}
+	if versionRepository == "" {
+		versionRepository = config.DefaultVersionsURL
+	}
+	return &versionstream.BundleMetadata{
+		URL:    versionRepository,
+		Ref:    versionRef,
+		Commit: commit,
+	}, nil
+}
@@ -0,0 +1,61 @@
+package pipeline
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/spf13/cobra"
+)
+
+// PipelineOptions contains the command line options
+type PipelineOptions struct {
+	*opts.CommonOptions
+}
+
+var (
+	pipelineLong = templates.LongDesc(`
+		Works with the pipeline of the current project such as linting its YAML, running its steps locally in containers
+		or restarting a failed pipeline from the step which failed
+`)
+
+	pipelineExample = templates.Examples(`
+		# lints the jenkins-x.yml in the current directory
+		jx pipeline lint
+
+		# runs the pull request pipeline of the current directory locally in containers
+		jx pipeline run --local
+
+		# restarts a failed pipeline from the step which failed
+		jx pipeline restart
+	`)
+)
+
+// NewCmdPipeline creates the command object for the "pipeline" command group
+func NewCmdPipeline(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &PipelineOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "pipeline",
+		Short:   "Works with the pipeline YAML of the current project",
+		Long:    pipelineLong,
+		Example: pipelineExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.AddCommand(NewCmdPipelineLint(commonOpts))
+	cmd.AddCommand(NewCmdPipelineRestart(commonOpts))
+	cmd.AddCommand(NewCmdPipelineRun(commonOpts))
+	return cmd
+}
+
+// Run implements this command
+func (o *PipelineOptions) Run() error {
+	return o.Cmd.Help()
+}
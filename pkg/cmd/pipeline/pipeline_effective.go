@@ -0,0 +1,151 @@
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/buildpacks"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/step/syntax"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/jenkinsfile/gitresolver"
+	"github.com/jenkins-x/jx/v2/pkg/tekton"
+	jxsyntax "github.com/jenkins-x/jx/v2/pkg/tekton/syntax"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// EffectiveOptions contains the command line options to find the pipeline YAML of a project and create its
+// effective pipeline without a cluster
+type EffectiveOptions struct {
+	*opts.CommonOptions
+
+	Dir        string
+	Context    string
+	File       string
+	CustomEnvs []string
+
+	Pack           string
+	BuildPackURL   string
+	BuildPackRef   string
+	PacksDir       string
+	DockerRegistry string
+
+	// podTemplates the pod templates of the team used for the containers of the steps
+	podTemplates map[string]*corev1.Pod
+}
+
+func (o *EffectiveOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.Dir, "dir", "d", "", "The directory of the project. Defaults to the current directory")
+	cmd.Flags().StringVarP(&o.Context, "context", "c", "", "The context of the pipeline YAML to use instead of jenkins-x.yml")
+	cmd.Flags().StringVarP(&o.File, "file", "f", "", "The pipeline YAML file to use which overrides --context")
+	cmd.Flags().StringArrayVarP(&o.CustomEnvs, "env", "e", nil, "Environment variables in the form NAME=value injected into the pipeline")
+	cmd.Flags().StringVarP(&o.Pack, "pack", "p", "", "The build pack of the effective pipeline. Defaults to the build pack of the pipeline YAML")
+	cmd.Flags().StringVarP(&o.BuildPackURL, "url", "u", "", "The URL of the build pack Git repository of the effective pipeline")
+	cmd.Flags().StringVarP(&o.BuildPackRef, "ref", "r", "", "The Git reference (branch,tag,sha) of the build pack Git repository of the effective pipeline")
+	cmd.Flags().StringVarP(&o.PacksDir, "packs-dir", "", "", "A local directory of build packs to use for the effective pipeline instead of cloning the build pack Git repository")
+	cmd.Flags().StringVarP(&o.DockerRegistry, "docker-registry", "", "docker-registry", "The Docker Registry host name of the images of the effective pipeline")
+}
+
+// EffectivePipeline returns the pipeline after the inheritance from the build pack and the overrides are applied
+// using the Git repository of the project for the placeholders rather than the cluster
+func (o *EffectiveOptions) EffectivePipeline(projectConfig *config.ProjectConfig, fileName string) (*config.ProjectConfig, error) {
+	gitInfo := o.gitInfo(filepath.Dir(fileName))
+	pack := o.Pack
+	if pack == "" {
+		pack = projectConfig.BuildPack
+	}
+	if pack == "" {
+		return nil, util.MissingOption("pack")
+	}
+	if pack == "none" && projectConfig.PipelineConfig == nil {
+		return nil, errors.Errorf("no pipeline is defined in %s without a build pack", fileName)
+	}
+
+	packsDir := o.PacksDir
+	if packsDir == "" && pack != "none" {
+		url := o.BuildPackURL
+		if url == "" {
+			url = projectConfig.BuildPackGitURL
+		}
+		if url == "" {
+			url = v1.KubernetesWorkloadBuildPackURL
+		}
+		ref := o.BuildPackRef
+		if ref == "" {
+			ref = projectConfig.BuildPackGitURef
+		}
+		packsDirs, err := buildpacks.InitRepositories(o.Git(), []buildpacks.Repository{{URL: url, Ref: ref}})
+		if err != nil {
+			return nil, err
+		}
+		packsDir = packsDirs[0]
+	}
+	resolver, err := gitresolver.CreateResolver(packsDir, o.Git())
+	if err != nil {
+		return nil, err
+	}
+
+	effectiveOptions := &syntax.StepSyntaxEffectiveOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: o.CommonOptions,
+		},
+		Pack:           pack,
+		Context:        o.Context,
+		CustomEnvs:     o.CustomEnvs,
+		DefaultImage:   jxsyntax.DefaultContainerImage,
+		KanikoImage:    jxsyntax.KanikoDockerImage,
+		UseKaniko:      true,
+		ProjectID:      "todo",
+		DockerRegistry: o.DockerRegistry,
+		SourceName:     "source",
+		GitInfo:        gitInfo,
+		PodTemplates:   o.podTemplates,
+	}
+	if effectiveOptions.ServiceAccount == "" {
+		effectiveOptions.ServiceAccount = tekton.DefaultPipelineSA
+	}
+	return effectiveOptions.CreateEffectivePipeline(packsDir, projectConfig, fileName, resolver)
+}
+
+// gitInfo returns the Git repository of the dir or a placeholder repository named after the dir if it has no remote
+func (o *EffectiveOptions) gitInfo(dir string) *gits.GitRepository {
+	gitInfo, err := o.FindGitInfo(dir)
+	if err != nil {
+		log.Logger().Debugf("failed to find the git repository of %s: %s", dir, err.Error())
+		return &gits.GitRepository{
+			Host:         gits.GitHubHost,
+			Organisation: "myorg",
+			Name:         filepath.Base(dir),
+		}
+	}
+	return gitInfo
+}
+
+func (o *EffectiveOptions) pipelineFile() (string, error) {
+	dir := o.Dir
+	if dir == "" {
+		var err error
+		dir, err = os.Getwd()
+		if err != nil {
+			return "", err
+		}
+	}
+	if o.File != "" {
+		if filepath.IsAbs(o.File) {
+			return o.File, nil
+		}
+		return filepath.Join(dir, o.File), nil
+	}
+	if o.Context != "" {
+		return filepath.Join(dir, fmt.Sprintf("jenkins-x-%s.yml", o.Context)), nil
+	}
+	return filepath.Join(dir, config.ProjectConfigFileName), nil
+}
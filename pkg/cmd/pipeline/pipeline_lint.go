@@ -0,0 +1,181 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/jenkinsfile"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+// PipelineLintOptions contains the command line options
+type PipelineLintOptions struct {
+	EffectiveOptions
+
+	Strict    bool
+	Effective bool
+}
+
+var (
+	pipelineLintLong = templates.LongDesc(`
+		Lints the pipeline YAML of the current project without needing a cluster.
+
+		The YAML is validated against the schema of the pipeline, rejecting unknown fields, then the images of the steps
+		and the references to environment variables which are not declared in the step, stage, pipeline or project
+		are checked.
+
+		With --effective the fully resolved pipeline is output after the inheritance from the build pack and the
+		overrides are applied.
+`)
+
+	pipelineLintExample = templates.Examples(`
+		# lints the jenkins-x.yml in the current directory
+		jx pipeline lint
+
+		# lints the jenkins-x-bdd.yml in the current directory failing on any warning
+		jx pipeline lint --context bdd --strict
+
+		# outputs the effective pipeline using a local checkout of the build packs
+		jx pipeline lint --effective --packs-dir ~/jenkins-x-kubernetes/packs
+	`)
+)
+
+// NewCmdPipelineLint creates the command object
+func NewCmdPipelineLint(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &PipelineLintOptions{
+		EffectiveOptions: EffectiveOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "lint",
+		Short:   "Lints the pipeline YAML of the current project",
+		Long:    pipelineLintLong,
+		Example: pipelineLintExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&options.Strict, "strict", "", false, "Fails on warnings such as references to undeclared environment variables")
+	cmd.Flags().BoolVarP(&options.Effective, "effective", "", false, "Outputs the effective pipeline after the build pack inheritance and overrides are applied")
+	options.addFlags(cmd)
+	return cmd
+}
+
+// Run implements this command
+func (o *PipelineLintOptions) Run() error {
+	fileName, err := o.pipelineFile()
+	if err != nil {
+		return err
+	}
+	projectConfig, issues, err := LintFile(fileName, customEnvNames(o.CustomEnvs)...)
+	if err != nil {
+		return err
+	}
+	errorCount := 0
+	warningCount := 0
+	for _, issue := range issues {
+		if issue.Warning {
+			warningCount++
+			log.Logger().Warnf("%s", issue.String())
+		} else {
+			errorCount++
+			log.Logger().Errorf("%s", issue.String())
+		}
+	}
+	if errorCount > 0 || (o.Strict && warningCount > 0) {
+		return errors.Errorf("%s has %d errors and %d warnings", fileName, errorCount, warningCount)
+	}
+
+	if o.Effective {
+		effectiveConfig, err := o.EffectivePipeline(projectConfig, fileName)
+		if err != nil {
+			return err
+		}
+		data, err := yaml.Marshal(effectiveConfig)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal effective pipeline")
+		}
+		_, err = fmt.Fprintf(o.Out, "%s\n", data)
+		return err
+	}
+	if warningCount > 0 {
+		log.Logger().Infof("%s is valid with %d warnings", util.ColorInfo(fileName), warningCount)
+		return nil
+	}
+	log.Logger().Infof("%s is valid", util.ColorInfo(fileName))
+	return nil
+}
+
+// LintFile validates the pipeline YAML file against its schema then lints the pipelines returning the project
+// configuration and the issues found
+func LintFile(fileName string, knownEnvVars ...string) (*config.ProjectConfig, []jenkinsfile.LintIssue, error) {
+	exists, err := util.FileExists(fileName)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "error reading pipeline file %s", fileName)
+	}
+	if !exists {
+		return nil, nil, fmt.Errorf("pipeline file %s does not exist or is not a file", fileName)
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to load file %s", fileName)
+	}
+	validationErrors, err := util.ValidateYaml(&config.ProjectConfig{}, data)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to perform schema validation of pipeline YAML file %s", fileName)
+	}
+	issues := []jenkinsfile.LintIssue{}
+	for _, e := range validationErrors {
+		issues = append(issues, jenkinsfile.LintIssue{Location: "schema", Message: e})
+	}
+	if len(issues) > 0 {
+		return nil, issues, nil
+	}
+
+	projectConfig := &config.ProjectConfig{}
+	err = yaml.Unmarshal(data, projectConfig)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "error loading pipeline YAML file %s", fileName)
+	}
+	pipelineConfig := projectConfig.PipelineConfig
+	if pipelineConfig == nil {
+		return projectConfig, issues, nil
+	}
+	for name, lifecycles := range pipelineConfig.Pipelines.AllMap() {
+		if lifecycles.Pipeline != nil {
+			validateErr := lifecycles.Pipeline.Validate(context.Background())
+			if validateErr != nil {
+				issues = append(issues, jenkinsfile.LintIssue{
+					Location: fmt.Sprintf("pipelines.%s.pipeline", name),
+					Message:  validateErr.Error(),
+				})
+			}
+		}
+	}
+	issues = append(issues, pipelineConfig.Lint(knownEnvVars...)...)
+	return projectConfig, issues, nil
+}
+
+func customEnvNames(customEnvs []string) []string {
+	answer := []string{}
+	for _, e := range customEnvs {
+		answer = append(answer, strings.SplitN(e, "=", 2)[0])
+	}
+	return answer
+}
@@ -0,0 +1,87 @@
+// +build unit
+
+package pipeline_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/pipeline"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/testhelpers"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	gits_test "github.com/jenkins-x/jx/v2/pkg/gits/mocks"
+	helm_test "github.com/jenkins-x/jx/v2/pkg/helm/mocks"
+	"github.com/jenkins-x/jx/v2/pkg/jenkinsfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintFileValid(t *testing.T) {
+	t.Parallel()
+	projectConfig, issues, err := pipeline.LintFile(filepath.Join("test_data", "valid", config.ProjectConfigFileName))
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+	assert.Equal(t, "mypack", projectConfig.BuildPack)
+}
+
+func TestLintFileUnknownField(t *testing.T) {
+	t.Parallel()
+	_, issues, err := pipeline.LintFile(filepath.Join("test_data", "unknown_field", config.ProjectConfigFileName))
+	require.NoError(t, err)
+	require.NotEmpty(t, issues)
+	for _, issue := range issues {
+		assert.Equal(t, "schema", issue.Location)
+		assert.False(t, issue.Warning)
+	}
+}
+
+func TestLintFileIssues(t *testing.T) {
+	t.Parallel()
+	_, issues, err := pipeline.LintFile(filepath.Join("test_data", "issues", config.ProjectConfigFileName))
+	require.NoError(t, err)
+	assert.Equal(t, []jenkinsfile.LintIssue{
+		{
+			Location: "pipelines.release.pipeline.agent",
+			Message:  `invalid image reference "Not A Valid/Image"`,
+		},
+		{
+			Location: "pipelines.release.pipeline.stages[release].steps[build]",
+			Message:  "environment variable SECRET_TOKEN is not declared in the step, stage, pipeline or project",
+			Warning:  true,
+		},
+	}, issues)
+
+	_, issues, err = pipeline.LintFile(filepath.Join("test_data", "issues", config.ProjectConfigFileName), "SECRET_TOKEN")
+	require.NoError(t, err)
+	assert.Len(t, issues, 1, "the custom env vars should be treated as declared")
+}
+
+func TestPipelineLintEffective(t *testing.T) {
+	t.Parallel()
+	commonOpts := opts.NewCommonOptionsWithFactory(nil)
+	testhelpers.ConfigureTestOptions(&commonOpts, gits_test.NewMockGitter(), helm_test.NewMockHelmer())
+	o := &pipeline.EffectiveOptions{
+		CommonOptions:  &commonOpts,
+		PacksDir:       filepath.Join("test_data", "packs"),
+		DockerRegistry: "my-registry",
+	}
+	fileName := filepath.Join("test_data", "valid", config.ProjectConfigFileName)
+	projectConfig, _, err := pipeline.LintFile(fileName)
+	require.NoError(t, err)
+
+	effective, err := o.EffectivePipeline(projectConfig, fileName)
+	require.NoError(t, err)
+	require.NotNil(t, effective.PipelineConfig)
+	release := effective.PipelineConfig.Pipelines.Release
+	require.NotNil(t, release)
+	require.NotNil(t, release.Pipeline)
+	require.NotEmpty(t, release.Pipeline.Stages)
+
+	names := []string{}
+	for _, s := range release.Pipeline.Stages[0].Steps {
+		names = append(names, s.Name)
+	}
+	assert.Equal(t, []string{"setup-jx-git-credentials", "build-build", "build-release", "build-publish"}, names,
+		"the override of the project should be applied to the build pack")
+}
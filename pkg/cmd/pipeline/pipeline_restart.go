@@ -0,0 +1,255 @@
+package pipeline
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/tekton"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PipelineRestartOptions contains the command line options
+type PipelineRestartOptions struct {
+	*opts.CommonOptions
+
+	FromStep string
+	DryRun   bool
+
+	// Suffix the suffix of the names of the resources of the restarted pipeline. Defaults to a random suffix
+	Suffix string
+}
+
+var (
+	pipelineRestartLong = templates.LongDesc(`
+		Restarts a failed pipeline from the stage which failed rather than running the whole pipeline again.
+
+		The stages which completed before the failed stage are not run again. If the workspace of the failed pipeline
+		is still available it is copied into the restarted stage, otherwise the source is fetched again at the same
+		revision. Only the setup steps of the stage run before the step the pipeline is restarted from.
+`)
+
+	pipelineRestartExample = templates.Examples(`
+		# picks a failed pipeline to restart from its failed step
+		jx pipeline restart
+
+		# restarts build 3 of the master branch from the step which failed
+		jx pipeline restart "myorg/myrepo/master #3"
+
+		# restarts a failed PipelineRun from the promote-jx-promote step of its failed stage
+		jx pipeline restart myorg-myrepo-master-3 --from-step promote-jx-promote
+	`)
+)
+
+// NewCmdPipelineRestart creates the command object
+func NewCmdPipelineRestart(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &PipelineRestartOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "restart [build]",
+		Short:   "Restarts a failed pipeline from the stage which failed",
+		Long:    pipelineRestartLong,
+		Example: pipelineRestartExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.FromStep, "from-step", "s", "", "The name of the step of the failed stage to restart from. Defaults to the step which failed")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "Shows the stage and step the pipeline would restart from without restarting it")
+	return cmd
+}
+
+// Run implements this command
+func (o *PipelineRestartOptions) Run() error {
+	tektonClient, ns, err := o.TektonClient()
+	if err != nil {
+		return errors.Wrap(err, "could not create tekton client")
+	}
+	pr, err := o.failedPipelineRun()
+	if err != nil {
+		return err
+	}
+	point, err := tekton.FailedRestartPoint(pr)
+	if err != nil {
+		return err
+	}
+	if o.FromStep != "" {
+		point.Step = o.FromStep
+	}
+	if pr.Spec.PipelineRef == nil {
+		return errors.Errorf("PipelineRun %s has no Pipeline", pr.Name)
+	}
+	pipeline, err := tektonClient.TektonV1alpha1().Pipelines(ns).Get(pr.Spec.PipelineRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "getting Pipeline %s of PipelineRun %s", pr.Spec.PipelineRef.Name, pr.Name)
+	}
+	taskName := ""
+	for _, pt := range pipeline.Spec.Tasks {
+		if pt.Name == point.PipelineTask && pt.TaskRef != nil {
+			taskName = pt.TaskRef.Name
+		}
+	}
+	if taskName == "" {
+		return errors.Errorf("no Task for stage %s in Pipeline %s", point.PipelineTask, pipeline.Name)
+	}
+	task, err := tektonClient.TektonV1alpha1().Tasks(ns).Get(taskName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "getting Task %s of Pipeline %s", taskName, pipeline.Name)
+	}
+	pvcName, err := o.workspacePVC(ns, pr)
+	if err != nil {
+		return err
+	}
+
+	suffix := o.Suffix
+	if suffix == "" {
+		random, err := util.RandStringBytesMaskImprSrc(5)
+		if err != nil {
+			return err
+		}
+		suffix = "restart-" + strings.ToLower(random)
+	}
+	newPipeline, newTask, newRun, err := tekton.RestartCRDs(pr, pipeline, task, *point, suffix, pvcName)
+	if err != nil {
+		return err
+	}
+	stepName := point.Step
+	if stepName == "" {
+		stepName = "its first step"
+	}
+	if pvcName == "" {
+		log.Logger().Infof("the workspace of PipelineRun %s is no longer available so the source will be fetched again", util.ColorInfo(pr.Name))
+	}
+	if o.DryRun {
+		log.Logger().Infof("would restart PipelineRun %s from stage %s at %s", util.ColorInfo(pr.Name), util.ColorInfo(point.PipelineTask), util.ColorInfo(stepName))
+		return nil
+	}
+
+	_, err = tekton.CreateOrUpdateTask(tektonClient, ns, newTask)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the Task %s in namespace %s", newTask.Name, ns)
+	}
+	_, err = tekton.CreateOrUpdatePipeline(tektonClient, ns, newPipeline)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the Pipeline %s in namespace %s", newPipeline.Name, ns)
+	}
+	_, err = tekton.ApplyPipelineRun(tektonClient, ns, newRun)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the PipelineRun %s in namespace %s", newRun.Name, ns)
+	}
+
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return errors.Wrap(err, "could not create jx client")
+	}
+	structure, err := tekton.StructureForPipelineRun(jxClient, ns, pr)
+	if err != nil {
+		log.Logger().Warnf("no PipelineStructure found for PipelineRun %s: %s", pr.Name, err.Error())
+	} else {
+		_, err = jxClient.JenkinsV1().PipelineStructures(ns).Create(tekton.RestartStructure(structure, task, newTask, newPipeline, newRun))
+		if err != nil {
+			return errors.Wrapf(err, "failed to create the PipelineStructure %s in namespace %s", newRun.Name, ns)
+		}
+	}
+	log.Logger().Infof("restarted PipelineRun %s as %s from stage %s at %s", util.ColorInfo(pr.Name), util.ColorInfo(newRun.Name),
+		util.ColorInfo(point.PipelineTask), util.ColorInfo(stepName))
+	return nil
+}
+
+// failedPipelineRun returns the failed PipelineRun named by the argument or picked by the user
+func (o *PipelineRestartOptions) failedPipelineRun() (*pipelineapi.PipelineRun, error) {
+	tektonClient, ns, err := o.TektonClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create tekton client")
+	}
+	prList, err := tektonClient.TektonV1alpha1().PipelineRuns(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list PipelineRuns in namespace %s", ns)
+	}
+
+	names := []string{}
+	m := map[string]*pipelineapi.PipelineRun{}
+	for k := range prList.Items {
+		pr := &prList.Items[k]
+		if !tekton.PipelineRunFailed(pr) {
+			continue
+		}
+		m[pr.Name] = pr
+		name := buildName(pr)
+		if name == "" {
+			continue
+		}
+		// a build which was restarted before and failed again restarts from its latest run
+		if m[name] != nil && m[name].CreationTimestamp.After(pr.CreationTimestamp.Time) {
+			continue
+		}
+		if m[name] == nil {
+			names = append(names, name)
+		}
+		m[name] = pr
+	}
+	if len(names) == 0 {
+		return nil, errors.Errorf("no failed PipelineRuns were found in namespace %s", ns)
+	}
+	sort.Strings(names)
+
+	name := ""
+	if len(o.Args) > 0 {
+		name = o.Args[0]
+	} else {
+		name, err = util.PickName(names, "Which pipeline do you want to restart: ",
+			"select a failed pipeline to restart from its failed step", o.GetIOFileHandles())
+		if err != nil {
+			return nil, err
+		}
+	}
+	pr := m[name]
+	if pr == nil {
+		return nil, errors.Errorf("no failed PipelineRun found for %s, the failed pipelines are: %s", name, strings.Join(names, ", "))
+	}
+	return pr, nil
+}
+
+// workspacePVC returns the name of the PVC of the artifacts of the PipelineRun if it still exists
+func (o *PipelineRestartOptions) workspacePVC(ns string, pr *pipelineapi.PipelineRun) (string, error) {
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return "", errors.Wrap(err, "could not create kube client")
+	}
+	pvcName := pr.Name + "-pvc"
+	_, err = kubeClient.CoreV1().PersistentVolumeClaims(ns).Get(pvcName, metav1.GetOptions{})
+	if err != nil {
+		return "", nil
+	}
+	return pvcName, nil
+}
+
+// buildName returns the name of the build of the PipelineRun such as owner/repo/branch #1
+func buildName(pr *pipelineapi.PipelineRun) string {
+	labels := pr.Labels
+	owner := labels[tekton.LabelOwner]
+	repo := labels[tekton.LabelRepo]
+	branch := labels[tekton.LabelBranch]
+	if owner == "" || repo == "" || branch == "" {
+		return ""
+	}
+	name := fmt.Sprintf("%s/%s/%s #%s", owner, repo, branch, labels[tekton.LabelBuild])
+	if context := labels[tekton.LabelContext]; context != "" {
+		name = fmt.Sprintf("%s-%s", name, context)
+	}
+	return name
+}
@@ -0,0 +1,103 @@
+// +build unit
+
+package pipeline_test
+
+import (
+	"os"
+	"testing"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	jxfake "github.com/jenkins-x/jx-api/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/clients"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/pipeline"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/testhelpers"
+	"github.com/jenkins-x/jx/v2/pkg/tekton"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	tektonfake "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"knative.dev/pkg/apis"
+)
+
+func TestPipelineRestart(t *testing.T) {
+	ns := "jx"
+	pr := &pipelineapi.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myorg-myrepo-master-3",
+			Namespace: ns,
+			Labels: map[string]string{
+				tekton.LabelOwner:  "myorg",
+				tekton.LabelRepo:   "myrepo",
+				tekton.LabelBranch: "master",
+				tekton.LabelBuild:  "3",
+			},
+		},
+		Spec: pipelineapi.PipelineRunSpec{
+			PipelineRef: &pipelineapi.PipelineRef{Name: "myorg-myrepo-master-3"},
+		},
+	}
+	pr.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse})
+	taskRunStatus := &pipelineapi.TaskRunStatus{}
+	taskRunStatus.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse})
+	taskRunStatus.Steps = []pipelineapi.StepState{
+		{Name: "git-merge", ContainerState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}}},
+		{Name: "build-test", ContainerState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 2}}},
+	}
+	pr.Status.TaskRuns = map[string]*pipelineapi.PipelineRunTaskRunStatus{
+		"myorg-myrepo-master-3-build-abcde": {PipelineTaskName: "build", Status: taskRunStatus},
+	}
+	pl := &pipelineapi.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Name: "myorg-myrepo-master-3", Namespace: ns},
+		Spec: pipelineapi.PipelineSpec{
+			Tasks: []pipelineapi.PipelineTask{
+				{Name: "build", TaskRef: &pipelineapi.TaskRef{Name: "myorg-myrepo-master-build-3"}},
+			},
+		},
+	}
+	task := &pipelineapi.Task{ObjectMeta: metav1.ObjectMeta{Name: "myorg-myrepo-master-build-3", Namespace: ns}}
+	for _, name := range []string{"git-merge", "build-compile", "build-test"} {
+		task.Spec.Steps = append(task.Spec.Steps, pipelineapi.Step{Container: corev1.Container{Name: name, Image: "golang"}})
+	}
+	taskName := task.Name
+	structure := &v1.PipelineStructure{
+		ObjectMeta: metav1.ObjectMeta{Name: pl.Name, Namespace: ns},
+		Stages:     []v1.PipelineStructureStage{{Name: "build", TaskRef: &taskName}},
+	}
+
+	commonOpts := opts.NewCommonOptionsWithTerm(clients.NewFactory(), os.Stdin, &testhelpers.FakeOut{}, os.Stderr)
+	commonOpts.BatchMode = true
+	commonOpts.SetDevNamespace(ns)
+	tektonClient := tektonfake.NewSimpleClientset(pr, pl, task)
+	jxClient := jxfake.NewSimpleClientset(structure)
+	commonOpts.SetTektonClient(tektonClient)
+	commonOpts.SetKubeClient(kubefake.NewSimpleClientset())
+	commonOpts.SetJxClient(jxClient)
+
+	o := &pipeline.PipelineRestartOptions{
+		CommonOptions: commonOpts,
+		Suffix:        "restart-1",
+	}
+	o.Args = []string{"myorg/myrepo/master #3"}
+	err := o.Run()
+	require.NoError(t, err)
+
+	newRun, err := tektonClient.TektonV1alpha1().PipelineRuns(ns).Get("myorg-myrepo-master-3-restart-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "myorg-myrepo-master-3-restart-1", newRun.Spec.PipelineRef.Name)
+
+	newTask, err := tektonClient.TektonV1alpha1().Tasks(ns).Get("myorg-myrepo-master-build-3-restart-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	names := []string{}
+	for _, s := range newTask.Spec.Steps {
+		names = append(names, s.Name)
+	}
+	assert.Equal(t, []string{"git-merge", "build-test"}, names, "the stage should restart from the failed step")
+
+	newStructure, err := jxClient.JenkinsV1().PipelineStructures(ns).Get(newRun.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, newTask.Name, *newStructure.Stages[0].TaskRef)
+}
@@ -0,0 +1,411 @@
+package pipeline
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/jenkinsfile"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/tekton/syntax"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// localHomeDir the home dir of the steps which Tekton shares between the containers of a pod
+	localHomeDir = "/tekton/home"
+)
+
+var (
+	// containerEngines the container engines which can run the steps of a pipeline locally in order of preference
+	containerEngines = []string{"docker", "podman"}
+
+	// skippedLocalSteps the steps which are not needed when the source is mounted from the local dir
+	skippedLocalSteps = []string{"git-merge", "git-checkout", "setup-builder-home"}
+)
+
+// PipelineRunOptions contains the command line options
+type PipelineRunOptions struct {
+	EffectiveOptions
+
+	Local          bool
+	Kind           string
+	Step           string
+	Engine         string
+	VersionsDir    string
+	NoPodTemplates bool
+	DryRun         bool
+
+	// localDir the dir of the dirs shared by the containers of the steps
+	localDir string
+}
+
+var (
+	pipelineRunLong = templates.LongDesc(`
+		Runs the steps of the pipeline of the current project locally in containers using docker or podman so that
+		pipeline failures can be debugged without pushing commits.
+
+		Each step runs in the container of the pod template of the team, if the current cluster can be reached, with
+		the images resolved from the version stream, the env vars of the pipeline and the source mounted from the
+		project directory. Empty dir and host path volumes are shared between the steps as they are in the pod of the
+		pipeline.
+`)
+
+	pipelineRunExample = templates.Examples(`
+		# runs the pull request pipeline of the current directory locally
+		jx pipeline run --local
+
+		# runs only the build-make-linux step of the release pipeline
+		jx pipeline run --local --kind release --step build-make-linux
+
+		# shows the container commands of the steps without running them
+		jx pipeline run --local --dry-run
+	`)
+)
+
+// NewCmdPipelineRun creates the command object
+func NewCmdPipelineRun(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &PipelineRunOptions{
+		EffectiveOptions: EffectiveOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "run",
+		Short:   "Runs the pipeline of the current project locally in containers",
+		Long:    pipelineRunLong,
+		Example: pipelineRunExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&options.Local, "local", "", false, "Runs the steps in local containers rather than in the cluster")
+	cmd.Flags().StringVarP(&options.Kind, "kind", "k", jenkinsfile.PipelineKindPullRequest, fmt.Sprintf("The kind of pipeline to run. Possible values: %s", strings.Join(jenkinsfile.PipelineKinds, ", ")))
+	cmd.Flags().StringVarP(&options.Step, "step", "s", "", "The name of the only step to run")
+	cmd.Flags().StringVarP(&options.Engine, "engine", "", "", fmt.Sprintf("The container engine running the steps. Defaults to the first of %s found on the PATH", strings.Join(containerEngines, ", ")))
+	cmd.Flags().StringVarP(&options.VersionsDir, "versions-dir", "", "", "A local checkout of the version stream resolving the images of the steps. Defaults to the version stream of the team")
+	cmd.Flags().BoolVarP(&options.NoPodTemplates, "no-pod-templates", "", false, "Uses the images of the steps rather than the pod templates of the team in the current cluster")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "Shows the container commands of the steps without running them")
+	options.addFlags(cmd)
+	return cmd
+}
+
+// Run implements this command
+func (o *PipelineRunOptions) Run() error {
+	if !o.Local {
+		return errors.New("only --local runs are supported, use jx start pipeline to trigger the pipeline in the cluster")
+	}
+	engine, err := o.containerEngine()
+	if err != nil {
+		return err
+	}
+	if !o.NoPodTemplates {
+		o.loadPodTemplates()
+	}
+	if o.VersionsDir == "" {
+		o.VersionsDir = o.versionsDir()
+	}
+
+	commands, err := o.LocalCommands(engine)
+	if o.localDir != "" && !o.DryRun {
+		defer os.RemoveAll(o.localDir)
+	}
+	if err != nil {
+		return err
+	}
+	for _, c := range commands {
+		log.Logger().Infof("\n%s\n", util.ColorInfo(c.String()))
+		if o.DryRun {
+			continue
+		}
+		c.Out = os.Stdout
+		c.Err = os.Stderr
+		c.In = os.Stdin
+		_, err = c.RunWithoutRetry()
+		if err != nil {
+			return errors.Wrapf(err, "running %s", c.String())
+		}
+	}
+	return nil
+}
+
+// LocalCommands returns the commands of the container engine running the steps of the pipeline in containers
+// sharing the source, workspace, home and volumes of the pod of the pipeline
+func (o *PipelineRunOptions) LocalCommands(engine string) ([]*util.Command, error) {
+	fileName, err := o.pipelineFile()
+	if err != nil {
+		return nil, err
+	}
+	projectConfig, err := config.LoadProjectConfigFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	effective, err := o.EffectivePipeline(projectConfig, fileName)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := effective.GetPipeline(o.Kind)
+	if err != nil {
+		return nil, err
+	}
+	if parsed == nil {
+		return nil, errors.Errorf("there is no %s pipeline in %s", o.Kind, fileName)
+	}
+	_, tasks, _, err := parsed.GenerateCRDs(syntax.CRDsFromPipelineParams{
+		PipelineIdentifier: "local",
+		BuildIdentifier:    "1",
+		PodTemplates:       o.podTemplates,
+		VersionsDir:        o.VersionsDir,
+		SourceDir:          "source",
+		InterpretMode:      true,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "generating the steps of the %s pipeline", o.Kind)
+	}
+
+	steps := []corev1.Container{}
+	volumes := map[string]corev1.Volume{}
+	for _, task := range tasks {
+		for _, s := range task.Spec.Steps {
+			if util.StringArrayIndex(skippedLocalSteps, s.Name) < 0 {
+				steps = append(steps, s.Container)
+			}
+		}
+		for _, v := range task.Spec.Volumes {
+			volumes[v.Name] = v
+		}
+	}
+	if o.Step != "" {
+		names := []string{}
+		var selected []corev1.Container
+		for _, s := range steps {
+			names = append(names, s.Name)
+			if s.Name == o.Step {
+				selected = append(selected, s)
+			}
+		}
+		if len(selected) == 0 {
+			return nil, util.InvalidOption("step", o.Step, names)
+		}
+		steps = selected
+	}
+
+	sourceDir, err := filepath.Abs(filepath.Dir(fileName))
+	if err != nil {
+		return nil, err
+	}
+	o.localDir = filepath.Join(os.TempDir(), "jx-local")
+	if !o.DryRun {
+		o.localDir, err = ioutil.TempDir("", "jx-local")
+		if err != nil {
+			return nil, errors.Wrap(err, "creating the dir shared by the steps")
+		}
+	}
+	run := &localRun{
+		sourceDir: sourceDir,
+		localDir:  o.localDir,
+		env:       o.localEnv(sourceDir),
+		dirs:      map[string]string{},
+		dryRun:    o.DryRun,
+	}
+	commands := []*util.Command{}
+	for i := range steps {
+		args, err := run.runArgs(&steps[i], volumes)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, &util.Command{
+			Name: engine,
+			Args: args,
+			Dir:  sourceDir,
+		})
+	}
+	return commands, nil
+}
+
+// localEnv returns the env vars injected into the steps of the pipeline in the cluster by the pipeline runner
+func (o *PipelineRunOptions) localEnv(dir string) map[string]string {
+	gitInfo := o.gitInfo(dir)
+	branch, err := o.Git().Branch(dir)
+	if err != nil || branch == "" {
+		branch = "master"
+	}
+	env := map[string]string{
+		"BUILD_NUMBER":  "1",
+		"BUILD_ID":      "1",
+		"PIPELINE_KIND": o.Kind,
+		"REPO_OWNER":    gitInfo.Organisation,
+		"REPO_NAME":     gitInfo.Name,
+		"APP_NAME":      gitInfo.Name,
+		"BRANCH_NAME":   branch,
+		"JOB_NAME":      fmt.Sprintf("%s/%s/%s", gitInfo.Organisation, gitInfo.Name, branch),
+		"JX_BATCH_MODE": "true",
+	}
+	if o.Context != "" {
+		env["PIPELINE_CONTEXT"] = o.Context
+	}
+	return env
+}
+
+func (o *PipelineRunOptions) containerEngine() (string, error) {
+	if o.Engine != "" {
+		if util.StringArrayIndex(containerEngines, o.Engine) < 0 {
+			return "", util.InvalidOption("engine", o.Engine, containerEngines)
+		}
+		return o.Engine, nil
+	}
+	for _, engine := range containerEngines {
+		if _, err := exec.LookPath(engine); err == nil {
+			return engine, nil
+		}
+	}
+	if o.DryRun {
+		return containerEngines[0], nil
+	}
+	return "", errors.Errorf("none of %s is installed to run the steps locally", strings.Join(containerEngines, ", "))
+}
+
+// loadPodTemplates loads the pod templates of the team from the current cluster if it can be reached
+func (o *PipelineRunOptions) loadPodTemplates() {
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err == nil {
+		o.podTemplates, err = kube.LoadPodTemplates(kubeClient, ns)
+	}
+	if err != nil {
+		log.Logger().Warnf("using the images of the steps as the pod templates could not be loaded from the cluster: %s", err.Error())
+		o.podTemplates = nil
+	}
+}
+
+// versionsDir returns the dir of the version stream of the team or an empty string if it cannot be cloned
+func (o *PipelineRunOptions) versionsDir() string {
+	resolver, err := o.GetVersionResolver()
+	if err != nil {
+		log.Logger().Warnf("the images of the steps are not resolved from the version stream: %s", err.Error())
+		return ""
+	}
+	return resolver.VersionsDir
+}
+
+// localRun the dirs shared by the containers of the steps of a local run as they are in the pod of the pipeline
+type localRun struct {
+	sourceDir string
+	localDir  string
+	env       map[string]string
+	// dirs the local dirs of the workspace, home and empty dir volumes indexed by their mount path
+	dirs   map[string]string
+	dryRun bool
+}
+
+// runArgs returns the arguments of the container engine running the step
+func (r *localRun) runArgs(c *corev1.Container, volumes map[string]corev1.Volume) ([]string, error) {
+	args := []string{"run", "--rm", "-i", "--name", fmt.Sprintf("jx-local-%s", c.Name)}
+	workspaceDir, err := r.sharedDir(syntax.WorkingDirRoot)
+	if err != nil {
+		return nil, err
+	}
+	homeDir, err := r.sharedDir(localHomeDir)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args,
+		"-v", workspaceDir+":"+syntax.WorkingDirRoot,
+		"-v", r.sourceDir+":"+filepath.Join(syntax.WorkingDirRoot, "source"),
+		"-v", homeDir+":"+localHomeDir)
+
+	for _, m := range c.VolumeMounts {
+		v, ok := volumes[m.Name]
+		switch {
+		case !ok:
+			log.Logger().Warnf("step %s mounts the unknown volume %s", c.Name, m.Name)
+		case v.HostPath != nil:
+			args = append(args, "-v", v.HostPath.Path+":"+m.MountPath)
+		case v.EmptyDir != nil:
+			dir, err := r.sharedDir(m.MountPath)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, "-v", dir+":"+m.MountPath)
+		default:
+			log.Logger().Warnf("the volume %s of step %s is not mounted as only empty dir and host path volumes are supported locally", m.Name, c.Name)
+		}
+	}
+
+	env := map[string]string{"HOME": localHomeDir}
+	for k, v := range r.env {
+		env[k] = v
+	}
+	inherited := []string{}
+	for _, e := range c.Env {
+		if e.ValueFrom == nil {
+			env[e.Name] = e.Value
+			continue
+		}
+		delete(env, e.Name)
+		if _, ok := os.LookupEnv(e.Name); ok {
+			inherited = append(inherited, e.Name)
+		} else {
+			log.Logger().Warnf("the env var %s of step %s is not set as it is not defined locally", e.Name, c.Name)
+		}
+	}
+	names := []string{}
+	for k := range env {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		args = append(args, "-e", k+"="+env[k])
+	}
+	for _, k := range inherited {
+		args = append(args, "-e", k)
+	}
+
+	if c.SecurityContext != nil && c.SecurityContext.Privileged != nil && *c.SecurityContext.Privileged {
+		args = append(args, "--privileged")
+	}
+	if c.WorkingDir != "" {
+		args = append(args, "-w", c.WorkingDir)
+	}
+	if len(c.Command) > 0 {
+		args = append(args, "--entrypoint", c.Command[0])
+	}
+	args = append(args, c.Image)
+	if len(c.Command) > 1 {
+		args = append(args, c.Command[1:]...)
+	}
+	return append(args, c.Args...), nil
+}
+
+// sharedDir returns the local dir shared by the containers mounting the path creating it if required
+func (r *localRun) sharedDir(mountPath string) (string, error) {
+	dir := r.dirs[mountPath]
+	if dir != "" {
+		return dir, nil
+	}
+	dir = filepath.Join(r.localDir, strings.Trim(strings.Replace(mountPath, "/", "-", -1), "-"))
+	if !r.dryRun {
+		err := os.MkdirAll(dir, util.DefaultWritePermissions)
+		if err != nil {
+			return "", errors.Wrapf(err, "creating the local dir of %s", mountPath)
+		}
+	}
+	r.dirs[mountPath] = dir
+	return dir, nil
+}
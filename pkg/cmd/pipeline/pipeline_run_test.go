@@ -0,0 +1,92 @@
+// +build unit
+
+package pipeline_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/pipeline"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/testhelpers"
+	gits_test "github.com/jenkins-x/jx/v2/pkg/gits/mocks"
+	helm_test "github.com/jenkins-x/jx/v2/pkg/helm/mocks"
+	"github.com/jenkins-x/jx/v2/pkg/jenkinsfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineRunLocalCommands(t *testing.T) {
+	t.Parallel()
+	commonOpts := opts.NewCommonOptionsWithFactory(nil)
+	testhelpers.ConfigureTestOptions(&commonOpts, gits_test.NewMockGitter(), helm_test.NewMockHelmer())
+	o := &pipeline.PipelineRunOptions{
+		EffectiveOptions: pipeline.EffectiveOptions{
+			CommonOptions: &commonOpts,
+			Dir:           filepath.Join("test_data", "local"),
+		},
+		Local:          true,
+		Kind:           jenkinsfile.PipelineKindRelease,
+		NoPodTemplates: true,
+		DryRun:         true,
+	}
+	commands, err := o.LocalCommands("docker")
+	require.NoError(t, err)
+	require.Len(t, commands, 2, "the git merge step should be skipped")
+
+	sourceDir, err := filepath.Abs(filepath.Join("test_data", "local"))
+	require.NoError(t, err)
+	for _, c := range commands {
+		assert.Equal(t, "docker", c.Name)
+		assert.Contains(t, c.Args, sourceDir+":/workspace/source")
+		assert.Contains(t, c.Args, "GOPROXY=https://proxy.golang.org")
+		assert.Contains(t, c.Args, "PIPELINE_KIND=release")
+		assert.Contains(t, c.Args, "HOME=/tekton/home")
+		assert.NotContains(t, c.Args, "GITHUB_TOKEN", "secret env vars not defined locally should not be set")
+	}
+	assert.Contains(t, commands[0].Args, "jx-local-test")
+	assert.Equal(t, []string{"--entrypoint", "/bin/sh", "golang:1.15", "-c", "make test"}, commands[0].Args[len(commands[0].Args)-5:])
+	assert.Equal(t, []string{"--entrypoint", "/bin/sh", "golangci/golangci-lint:v1.31", "-c", "golangci-lint run"}, commands[1].Args[len(commands[1].Args)-5:])
+	assert.Equal(t, indexOf(commands[0].Args, "-w")+1, indexOf(commands[0].Args, "/workspace/source"))
+
+	cacheMount := ""
+	for _, a := range commands[0].Args {
+		if strings.HasSuffix(a, ":/cache") {
+			cacheMount = a
+		}
+	}
+	require.NotEmpty(t, cacheMount, "the empty dir volume should be mounted")
+	assert.Contains(t, commands[1].Args, cacheMount, "the empty dir volume should be shared between the steps")
+
+	o.Step = "lint"
+	commands, err = o.LocalCommands("podman")
+	require.NoError(t, err)
+	require.Len(t, commands, 1)
+	assert.Equal(t, "podman", commands[0].Name)
+	assert.Contains(t, commands[0].Args, "jx-local-lint")
+
+	o.Step = "unknown"
+	_, err = o.LocalCommands("docker")
+	assert.Error(t, err)
+}
+
+func TestPipelineRunRequiresLocal(t *testing.T) {
+	t.Parallel()
+	commonOpts := opts.NewCommonOptionsWithFactory(nil)
+	o := &pipeline.PipelineRunOptions{
+		EffectiveOptions: pipeline.EffectiveOptions{
+			CommonOptions: &commonOpts,
+		},
+	}
+	assert.Error(t, o.Run())
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
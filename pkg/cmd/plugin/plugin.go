@@ -0,0 +1,75 @@
+package plugin
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/extensions"
+	"github.com/jenkins-x/jx/v2/pkg/versionstream"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// PluginOptions contains the command line options
+type PluginOptions struct {
+	*opts.CommonOptions
+}
+
+var (
+	pluginLong = templates.LongDesc(`
+		Manages the binary plugins which extend jx with new commands.
+
+		Any executable called jx-foo on the PATH or installed in the ~/.jx/plugins/jx/bin directory is run for the
+		command 'jx foo'. The plugins which can be installed are listed in the plugins.yml file of the version stream.
+
+`)
+)
+
+// NewCmdPlugin creates the 'jx plugin' command
+func NewCmdPlugin(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &PluginOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "plugin",
+		Aliases: []string{"plugins"},
+		Short:   "Manages the binary plugins which extend jx with new commands",
+		Long:    pluginLong,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.AddCommand(NewCmdPluginInstall(commonOpts))
+	cmd.AddCommand(NewCmdPluginList(commonOpts))
+	cmd.AddCommand(NewCmdPluginUpgrade(commonOpts))
+
+	return cmd
+}
+
+// Run implements this command
+func (o *PluginOptions) Run() error {
+	return o.Cmd.Help()
+}
+
+// pluginIndex loads the plugin index from the version stream
+func pluginIndex(o *opts.CommonOptions) (*versionstream.PluginIndex, error) {
+	resolver, err := o.GetVersionResolver()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the version resolver")
+	}
+	return versionstream.GetPluginIndex(resolver.VersionsDir)
+}
+
+// installedPlugins returns the plugins installed from the plugin index sorted by name
+func installedPlugins() ([]*extensions.LocalPlugin, error) {
+	dir, err := extensions.LocalPluginBinDir()
+	if err != nil {
+		return nil, err
+	}
+	return extensions.InstalledPlugins(dir)
+}
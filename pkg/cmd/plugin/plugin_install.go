@@ -0,0 +1,82 @@
+package plugin
+
+import (
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/extensions"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// PluginInstallOptions contains the command line options
+type PluginInstallOptions struct {
+	*opts.CommonOptions
+}
+
+var (
+	pluginInstallLong = templates.LongDesc(`
+		Installs binary plugins from the plugin index of the version stream into the ~/.jx/plugins/jx/bin directory.
+
+		Plugins can be referred to by their name (e.g. jx-foo) or their command (e.g. foo). The version of each plugin
+		is the one in the plugin index so that the plugins are upgraded along with the version stream.
+
+`)
+
+	pluginInstallExample = templates.Examples(`
+		# install the plugin for the 'jx foo' command
+		jx plugin install foo
+
+		# install several plugins
+		jx plugin install foo jx-bar
+`)
+)
+
+// NewCmdPluginInstall creates the 'jx plugin install' command
+func NewCmdPluginInstall(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &PluginInstallOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "install <plugin>...",
+		Aliases: []string{"add"},
+		Short:   "Installs binary plugins from the plugin index",
+		Long:    pluginInstallLong,
+		Example: pluginInstallExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	return cmd
+}
+
+// Run implements this command
+func (o *PluginInstallOptions) Run() error {
+	if len(o.Args) == 0 {
+		return util.MissingArgument("plugin")
+	}
+	index, err := pluginIndex(o.CommonOptions)
+	if err != nil {
+		return err
+	}
+	for _, name := range o.Args {
+		plugin := index.Find(name)
+		if plugin == nil {
+			return errors.Errorf("plugin %s is not in the plugin index of the version stream", name)
+		}
+		path, err := extensions.InstallIndexPlugin(*plugin)
+		if err != nil {
+			return errors.Wrapf(err, "failed to install plugin %s", plugin.Name)
+		}
+		log.Logger().Infof("Installed plugin %s version %s for command %s at %s", util.ColorInfo(plugin.Name),
+			util.ColorInfo(plugin.Version), util.ColorInfo("jx "+plugin.SubCommand), path)
+	}
+	return nil
+}
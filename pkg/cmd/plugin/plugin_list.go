@@ -0,0 +1,97 @@
+package plugin
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/extensions"
+	"github.com/spf13/cobra"
+)
+
+// PluginListOptions contains the command line options
+type PluginListOptions struct {
+	*opts.CommonOptions
+
+	Installed bool
+}
+
+var (
+	pluginListLong = templates.LongDesc(`
+		Lists the binary plugins in the plugin index of the version stream together with the installed plugins and
+		the jx-* plugins found on the PATH.
+
+`)
+
+	pluginListExample = templates.Examples(`
+		# list the plugins which can be installed
+		jx plugin list
+
+		# only list the plugins which are installed or on the PATH
+		jx plugin list --installed
+`)
+)
+
+// NewCmdPluginList creates the 'jx plugin list' command
+func NewCmdPluginList(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &PluginListOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "Lists the available and installed binary plugins",
+		Long:    pluginListLong,
+		Example: pluginListExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&options.Installed, "installed", "i", false, "Only lists the installed plugins and the plugins on the PATH")
+
+	return cmd
+}
+
+// Run implements this command
+func (o *PluginListOptions) Run() error {
+	index, err := pluginIndex(o.CommonOptions)
+	if err != nil {
+		return err
+	}
+	installed, err := installedPlugins()
+	if err != nil {
+		return err
+	}
+	installedVersions := map[string]string{}
+	for _, p := range installed {
+		installedVersions[p.Name] = p.Version
+	}
+
+	table := o.CreateTable()
+	table.AddRow("NAME", "COMMAND", "VERSION", "INSTALLED", "DESCRIPTION")
+	seen := map[string]bool{}
+	for _, plugin := range index.Plugins {
+		seen[plugin.Name] = true
+		installedVersion := installedVersions[plugin.Name]
+		if installedVersion == "" && o.Installed {
+			continue
+		}
+		table.AddRow(plugin.Name, "jx "+plugin.SubCommand, plugin.Version, installedVersion, plugin.Description)
+	}
+	for _, p := range installed {
+		if !seen[p.Name] {
+			seen[p.Name] = true
+			table.AddRow(p.Name, "jx "+p.SubCommand(), "", p.Version, "not in the plugin index")
+		}
+	}
+	// plugins on the PATH are run in preference to installed plugins of the same name so always list them
+	for _, p := range extensions.PathPlugins() {
+		table.AddRow(p.Name, "jx "+p.SubCommand(), "", "PATH", p.Path)
+	}
+	table.Render()
+	return nil
+}
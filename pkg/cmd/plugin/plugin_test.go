@@ -0,0 +1,92 @@
+// +build unit
+
+package plugin_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/plugin"
+	"github.com/jenkins-x/jx/v2/pkg/extensions"
+	"github.com/jenkins-x/jx/v2/pkg/tests"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/jenkins-x/jx/v2/pkg/versionstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginInstallAndUpgrade(t *testing.T) {
+	tests.SkipForWindows(t, "plugins do not work on windows")
+
+	tmpDir, err := ioutil.TempDir("", "test-plugin-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	os.Setenv("JX_HOME", filepath.Join(tmpDir, "jx-home"))
+	defer os.Unsetenv("JX_HOME")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "#!/bin/sh\necho %s\n", r.URL.Path)
+	}))
+	defer server.Close()
+
+	versionsDir := filepath.Join(tmpDir, "versions")
+	writePluginIndex(t, versionsDir, server.URL, "1.0.0")
+	commonOpts := opts.NewCommonOptionsWithFactory(nil)
+	commonOpts.SetVersionResolver(&versionstream.VersionResolver{VersionsDir: versionsDir})
+
+	installOptions := &plugin.PluginInstallOptions{CommonOptions: &commonOpts}
+	installOptions.Args = []string{"hello"}
+	err = installOptions.Run()
+	require.NoError(t, err)
+
+	installOptions.Args = []string{"doesNotExist"}
+	err = installOptions.Run()
+	assert.Error(t, err, "the plugin is not in the plugin index")
+
+	upgradeOptions := &plugin.PluginUpgradeOptions{CommonOptions: &commonOpts}
+	upgradeOptions.Args = []string{"goodbye"}
+	err = upgradeOptions.Run()
+	assert.Error(t, err, "the plugin is not installed")
+
+	writePluginIndex(t, versionsDir, server.URL, "1.1.0")
+	upgradeOptions.Args = nil
+	err = upgradeOptions.Run()
+	require.NoError(t, err)
+
+	pluginBinDir, err := extensions.LocalPluginBinDir()
+	require.NoError(t, err)
+	installed, err := extensions.InstalledPlugins(pluginBinDir)
+	require.NoError(t, err)
+	require.Len(t, installed, 1, "only the installed plugin should be upgraded")
+	assert.Equal(t, "jx-hello", installed[0].Name)
+	assert.Equal(t, "1.1.0", installed[0].Version)
+
+	cmd := util.Command{Name: installed[0].Path}
+	output, err := cmd.RunWithoutRetry()
+	require.NoError(t, err)
+	assert.Equal(t, "/1.1.0/jx-hello", output)
+}
+
+func writePluginIndex(t *testing.T, versionsDir string, serverURL string, version string) {
+	err := os.MkdirAll(versionsDir, util.DefaultWritePermissions)
+	require.NoError(t, err)
+	index := ""
+	for _, name := range []string{"hello", "goodbye"} {
+		index += fmt.Sprintf(`- subCommand: %s
+  version: %s
+  binaries:
+  - goos: %s
+    goarch: %s
+    url: %s/%s/jx-%s
+`, name, version, runtime.GOOS, runtime.GOARCH, serverURL, version, name)
+	}
+	err = ioutil.WriteFile(filepath.Join(versionsDir, "plugins.yml"), []byte("plugins:\n"+index), util.DefaultFileWritePermissions)
+	require.NoError(t, err)
+}
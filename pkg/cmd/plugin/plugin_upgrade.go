@@ -0,0 +1,110 @@
+package plugin
+
+import (
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/extensions"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// PluginUpgradeOptions contains the command line options
+type PluginUpgradeOptions struct {
+	*opts.CommonOptions
+}
+
+var (
+	pluginUpgradeLong = templates.LongDesc(`
+		Upgrades the installed binary plugins to the versions in the plugin index of the version stream.
+
+		If no plugins are specified all the installed plugins are upgraded.
+
+`)
+
+	pluginUpgradeExample = templates.Examples(`
+		# upgrade all the installed plugins
+		jx plugin upgrade
+
+		# upgrade the plugin for the 'jx foo' command
+		jx plugin upgrade foo
+`)
+)
+
+// NewCmdPluginUpgrade creates the 'jx plugin upgrade' command
+func NewCmdPluginUpgrade(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &PluginUpgradeOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "upgrade [plugin]...",
+		Aliases: []string{"update"},
+		Short:   "Upgrades the installed binary plugins to the versions in the plugin index",
+		Long:    pluginUpgradeLong,
+		Example: pluginUpgradeExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	return cmd
+}
+
+// Run implements this command
+func (o *PluginUpgradeOptions) Run() error {
+	index, err := pluginIndex(o.CommonOptions)
+	if err != nil {
+		return err
+	}
+	installed, err := installedPlugins()
+	if err != nil {
+		return err
+	}
+	installedVersions := map[string]string{}
+	for _, p := range installed {
+		installedVersions[p.Name] = p.Version
+	}
+
+	names := o.Args
+	if len(names) == 0 {
+		for _, p := range installed {
+			names = append(names, p.Name)
+		}
+	}
+	upgraded := 0
+	for _, name := range names {
+		plugin := index.Find(name)
+		if plugin == nil {
+			if len(o.Args) == 0 {
+				log.Logger().Warnf("Cannot upgrade plugin %s as it is not in the plugin index of the version stream", util.ColorWarning(name))
+				continue
+			}
+			return errors.Errorf("plugin %s is not in the plugin index of the version stream", name)
+		}
+		currentVersion := installedVersions[plugin.Name]
+		if currentVersion == "" {
+			return errors.Errorf("plugin %s is not installed, use 'jx plugin install %s' to install it", plugin.Name, plugin.SubCommand)
+		}
+		if currentVersion == plugin.Version {
+			log.Logger().Debugf("plugin %s is already at version %s", plugin.Name, plugin.Version)
+			continue
+		}
+		_, err = extensions.InstallIndexPlugin(*plugin)
+		if err != nil {
+			return errors.Wrapf(err, "failed to upgrade plugin %s", plugin.Name)
+		}
+		log.Logger().Infof("Upgraded plugin %s from version %s to %s", util.ColorInfo(plugin.Name),
+			util.ColorInfo(currentVersion), util.ColorInfo(plugin.Version))
+		upgraded++
+	}
+	if upgraded == 0 {
+		log.Logger().Info("The installed plugins are up to date")
+	}
+	return nil
+}
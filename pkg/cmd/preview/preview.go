@@ -25,11 +25,13 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/helm"
 
 	"github.com/jenkins-x/jx/v2/pkg/kserving"
+	"github.com/jenkins-x/jx/v2/pkg/previews"
 	"github.com/jenkins-x/jx/v2/pkg/users"
 
 	"github.com/jenkins-x/jx/v2/pkg/kube/services"
 
 	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	typev1 "github.com/jenkins-x/jx-api/pkg/client/clientset/versioned/typed/jenkins.io/v1"
 	"github.com/jenkins-x/jx-logging/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
@@ -49,6 +51,26 @@ var (
 	previewLong = templates.LongDesc(`
 		Creates or updates a Preview Environment for the given Pull Request or Branch.
 
+		The ResourceQuota and LimitRange specs in the resourceQuota and limitRange keys of the ConfigMap
+		` + kube.ConfigMapPreviewQuotas + ` in the dev namespace are applied to the namespace of every Preview
+		Environment so that the previews of pull requests can not starve the cluster.
+
+		Ephemeral dependencies such as databases can be declared in the preview.dependencies key of the values.yaml
+		of the preview chart. Dependencies of kind helm (the default) install the chart of the dependency into the
+		preview namespace and dependencies of kind command run the command of the dependency with the provision
+		argument. The dependencies are torn down when the Preview Environment is deleted.
+
+		    preview:
+		      dependencies:
+		      - name: postgres
+		        chart: bitnami/postgresql
+		        repository: https://charts.bitnami.com/bitnami
+		        values:
+		          postgresqlDatabase: myapp
+		      - name: queue
+		        kind: command
+		        command: ["./scripts/sqs-queue.sh"]
+
 		For more documentation on Preview Environments see: [https://jenkins-x.io/about/features/#preview-environments](https://jenkins-x.io/about/features/#preview-environments)
 
 `)
@@ -73,6 +95,7 @@ const (
 	optionPostPreviewJobTimeout  = "post-preview-job-timeout"
 	optionPostPreviewJobPollTime = "post-preview-poll-time"
 	optionPreviewHealthTimeout   = "preview-health-timeout"
+	optionTTL                    = "ttl"
 )
 
 // PreviewOptions the options for viewing running PRs
@@ -92,6 +115,7 @@ type PreviewOptions struct {
 	PostPreviewJobTimeout  string
 	PostPreviewJobPollTime string
 	PreviewHealthTimeout   string
+	TTL                    string
 
 	PullRequestName string
 	GitConfDir      string
@@ -160,6 +184,7 @@ func (o *PreviewOptions) AddPreviewOptions(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.PostPreviewJobTimeout, optionPostPreviewJobTimeout, "", "2h", "The duration before we consider the post preview Jobs failed")
 	cmd.Flags().StringVarP(&o.PostPreviewJobPollTime, optionPostPreviewJobPollTime, "", "10s", "The amount of time between polls for the post preview Job status")
 	cmd.Flags().StringVarP(&o.PreviewHealthTimeout, optionPreviewHealthTimeout, "", "5m", "The amount of time to wait for the preview application to become healthy")
+	cmd.Flags().StringVarP(&o.TTL, optionTTL, "", "", "The duration the preview environment is kept after it was last deployed before 'jx gc previews' deletes it, such as 72h. Defaults to the TTL of 'jx gc previews'")
 	cmd.Flags().BoolVarP(&o.NoComment, "no-comment", "", false, "Disables commenting on the Pull Request after preview is created.")
 	cmd.Flags().BoolVarP(&o.SkipAvailabilityCheck, "skip-availability-check", "", false, "Disables the mandatory availability check.")
 }
@@ -185,6 +210,12 @@ func (o *PreviewOptions) Run() error {
 			return fmt.Errorf("Invalid duration format %s for option --%s: %s", o.Timeout, optionPreviewHealthTimeout, err)
 		}
 	}
+	if o.TTL != "" {
+		_, err = time.ParseDuration(o.TTL)
+		if err != nil {
+			return fmt.Errorf("Invalid duration format %s for option --%s: %s", o.TTL, optionTTL, err)
+		}
+	}
 
 	log.Logger().Info("Creating a preview")
 	/*
@@ -422,6 +453,16 @@ func (o *PreviewOptions) Run() error {
 			}
 		}
 
+		if env.Annotations == nil {
+			env.Annotations = map[string]string{}
+		}
+		if o.TTL != "" {
+			env.Annotations[kube.AnnotationPreviewTTL] = o.TTL
+		}
+		// restart the TTL of the preview environment as it is deployed again
+		env.Annotations[kube.AnnotationPreviewLastDeployed] = time.Now().UTC().Format(time.RFC3339)
+		update = true
+
 		if update {
 			env, err = environmentsResource.PatchUpdate(env)
 			if err != nil {
@@ -448,7 +489,8 @@ func (o *PreviewOptions) Run() error {
 			ObjectMeta: metav1.ObjectMeta{
 				Name: o.Name,
 				Annotations: map[string]string{
-					kube.AnnotationReleaseName: o.ReleaseName,
+					kube.AnnotationReleaseName:         o.ReleaseName,
+					kube.AnnotationPreviewLastDeployed: time.Now().UTC().Format(time.RFC3339),
 				},
 			},
 			Spec: v1.EnvironmentSpec{
@@ -466,6 +508,9 @@ func (o *PreviewOptions) Run() error {
 				PreviewGitSpec: previewGitSpec,
 			},
 		}
+		if o.TTL != "" {
+			env.Annotations[kube.AnnotationPreviewTTL] = o.TTL
+		}
 		_, err = environmentsResource.Create(env)
 		if err != nil {
 			return fmt.Errorf("Failed to create environment in namespace %s due to: %s", ns, err)
@@ -477,6 +522,12 @@ func (o *PreviewOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	if env.Spec.Cluster == "" {
+		err = kube.EnsurePreviewQuotas(kubeClient, ns, env.Spec.Namespace)
+		if err != nil {
+			return err
+		}
+	}
 
 	domain, err := kube.GetCurrentDomain(kubeClient, ns)
 	if err != nil {
@@ -524,6 +575,11 @@ func (o *PreviewOptions) Run() error {
 		helmOptions.ValueFiles = append(helmOptions.ValueFiles, defaultValuesFileName)
 	}
 
+	env, err = o.provisionDependencies(environmentsResource, env, defaultValuesFileName)
+	if err != nil {
+		return err
+	}
+
 	err = o.InstallChartWithOptions(helmOptions)
 	if err != nil {
 		return err
@@ -657,6 +713,53 @@ func (o *PreviewOptions) Run() error {
 }
 
 // findPreviewURL finds the preview URL
+// provisionDependencies provisions the dependencies declared in the preview.dependencies key of the values file of
+// the preview chart, tearing down the ones provisioned by a previous deployment which are no longer declared, and
+// records them on the preview environment so that they are torn down with it
+func (o *PreviewOptions) provisionDependencies(environmentsResource typev1.EnvironmentInterface, env *v1.Environment, valuesFile string) (*v1.Environment, error) {
+	deps, err := previews.LoadDependencies(valuesFile)
+	if err != nil {
+		return env, err
+	}
+	previous, err := previews.GetEnvironmentDependencies(env)
+	if err != nil {
+		return env, err
+	}
+	if len(deps) == 0 && len(previous) == 0 {
+		return env, nil
+	}
+	manager := previews.NewManager(o.Helm())
+	declared := map[string]bool{}
+	for _, dep := range deps {
+		declared[dep.Name] = true
+	}
+	removed := []previews.Dependency{}
+	for _, dep := range previous {
+		if !declared[dep.Name] {
+			removed = append(removed, dep)
+		}
+	}
+	err = manager.Deprovision(removed, env.Spec.Namespace)
+	if err != nil {
+		log.Logger().Warnf("%s", err)
+	}
+
+	// record the dependencies before provisioning them so that a partially provisioned preview is cleaned up
+	err = previews.SetEnvironmentDependencies(env, deps)
+	if err != nil {
+		return env, err
+	}
+	env, err = environmentsResource.PatchUpdate(env)
+	if err != nil {
+		return env, errors.Wrapf(err, "recording the dependencies of Environment %s", o.Name)
+	}
+	err = manager.Provision(deps, env.Spec.Namespace)
+	if err != nil {
+		return env, err
+	}
+	return env, nil
+}
+
 func (o *PreviewOptions) findPreviewURL(kubeClient kubernetes.Interface, kserveClient kserve.Interface) (string, []string, error) {
 	app := naming.ToValidName(o.Application)
 	appNames := []string{app, o.ReleaseName, o.Namespace + "-preview", o.ReleaseName + "-" + app}
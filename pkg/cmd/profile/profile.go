@@ -53,6 +53,7 @@ func NewCmdProfile(commonOpts *opts.CommonOptions) *cobra.Command {
 			helper.CheckErr(err)
 		},
 	}
+	cmd.AddCommand(NewCmdProfileExport(commonOpts))
 	return cmd
 }
 
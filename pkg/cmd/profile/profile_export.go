@@ -0,0 +1,103 @@
+package profile
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/profiles"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// ExportOptions contains the command line options
+type ExportOptions struct {
+	*opts.CommonOptions
+
+	OutFile string
+	Custom  bool
+}
+
+var (
+	profileExportLong = templates.LongDesc(`
+		Exports one of the embedded installation profiles so that it can be customized.
+
+		The exported profile can be passed to 'jx install --profile' as a file or saved as a custom profile in ~/.jx/install-profiles
+`)
+
+	profileExportExample = templates.Examples(`
+		# Prints the production install profile
+		jx profile export production
+
+		# Saves the team install profile as a custom profile called team which can be edited
+		jx profile export team --custom
+
+		# Exports the demo profile to a file
+		jx profile export demo -f my-profile.yaml
+	`)
+)
+
+// NewCmdProfileExport creates the command object
+func NewCmdProfileExport(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &ExportOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "export <profile>",
+		Short:   "Exports an installation profile so it can be customized",
+		Long:    profileExportLong,
+		Example: profileExportExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.OutFile, "file", "f", "", "The file to write the profile to. Defaults to printing it to the terminal")
+	cmd.Flags().BoolVarP(&options.Custom, "custom", "", false, "Saves the profile in the custom profiles directory of the jx home dir")
+	return cmd
+}
+
+// Run implements this command
+func (o *ExportOptions) Run() error {
+	if len(o.Args) < 1 {
+		return fmt.Errorf("please specify a profile: %s", strings.Join(profiles.BuiltinProfileNames(), ", "))
+	}
+	name := o.Args[0]
+	text, err := profiles.BuiltinProfileYAML(name)
+	if err != nil {
+		return err
+	}
+	fileName := o.OutFile
+	if o.Custom && fileName == "" {
+		dir, err := util.ConfigDir()
+		if err != nil {
+			return err
+		}
+		profilesDir := filepath.Join(dir, profiles.ProfilesDir)
+		err = os.MkdirAll(profilesDir, util.DefaultWritePermissions)
+		if err != nil {
+			return errors.Wrapf(err, "creating directory %s", profilesDir)
+		}
+		fileName = filepath.Join(profilesDir, name+".yaml")
+	}
+	if fileName == "" {
+		_, err = fmt.Fprint(o.Out, text)
+		return err
+	}
+	err = ioutil.WriteFile(fileName, []byte(text), util.DefaultFileWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "writing file %s", fileName)
+	}
+	log.Logger().Infof("Exported the install profile %s to %s", util.ColorInfo(name), util.ColorInfo(fileName))
+	return nil
+}
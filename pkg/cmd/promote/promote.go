@@ -28,12 +28,15 @@ import (
 	"github.com/jenkins-x/jx-logging/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/flagger"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/helm"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/promotion"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 const (
@@ -70,6 +73,12 @@ type PromoteOptions struct {
 	PullRequestPollTime     string
 	Filter                  string
 	Alias                   string
+	UptimeCheck             bool
+	UptimeAlertReceiver     string
+	OverrideWindow          bool
+	RequireSigned           bool
+	ApplyQueued             bool
+	Rollout                 flagger.CanaryOptions
 
 	// calculated fields
 	TimeoutDuration         *time.Duration
@@ -95,6 +104,29 @@ var (
 	promote_long = templates.LongDesc(`
 		Promotes a version of an application to zero to many permanent environments.
 
+		The Pull Request of a promotion is only merged once it passes the gates in the ` + promotion.AnnotationGates + `
+		annotation of the Environment:
+
+		* approval: the promotion is approved with 'jx approve promotion'
+		* smoke-tests: the command in the ` + promotion.AnnotationSmokeTest + ` annotation succeeds
+		* check: the webhook in the ` + promotion.AnnotationCheckURL + ` annotation accepts the promotion
+
+		The deployment windows and freeze periods in the ` + promotion.AnnotationWindows + ` and
+		` + promotion.AnnotationFreezes + ` annotations of the Environment add a window gate. Promotions outside
+		the deployment window are queued and merged by 'jx promote --apply-queued' once the window opens unless
+		--override-window is specified for emergencies.
+
+		The state of the gates is displayed by 'jx get promotions'.
+
+		The --strategy option generates a Flagger Canary for a canary or blue-green deployment of the application
+		in the Environment repository. Once the promotion is merged the analysis of the new version is watched and
+		the promotion fails if Flagger rolls it back because its metrics failed. This requires Flagger to be
+		installed, e.g. via 'jx create addon flagger'.
+
+		Environments whose cluster has a kube config stored with 'jx edit env --cluster-kubeconfig' are deployed
+		to that cluster, so that applications built in the development cluster can be promoted to a separate
+		production cluster. The connection to the cluster is verified before its helm releases are applied.
+
 		For more documentation see: [https://jenkins-x.io/docs/getting-started/promotion/](https://jenkins-x.io/docs/getting-started/promotion/)
 
 `)
@@ -114,6 +146,19 @@ var (
 		# To promote a postgres chart using an alias
 		jx promote -f postgres --alias mydb
 
+		# Promote a version of the myapp application to production registering an uptime check of its URL which
+		# routes its alerts to the 'oncall' Alertmanager receiver
+		jx promote --app myapp --version 1.2.3 --env production --uptime-check --uptime-alert-receiver oncall
+
+		# Promote a version of myapp to production via a canary deployment shifting 20% of the traffic at a time
+		jx promote --app myapp --version 1.2.3 --env production --strategy canary --step-weight 20
+
+		# Promote a hotfix to production outside its deployment window
+		jx promote --app myapp --version 1.2.4 --env production --override-window
+
+		# Merge the queued promotions whose deployment window is now open, such as from a CronJob
+		jx promote --apply-queued
+
 		# To create or update a Preview Environment please see the 'jx preview' command if you are inside a git clone of a repo
 		jx preview
 	`)
@@ -163,6 +208,19 @@ func (o *PromoteOptions) AddPromoteOptions(cmd *cobra.Command) {
 	cmd.Flags().BoolVarP(&o.NoPoll, "no-poll", "", false, "Disables polling for Pull Request or Pipeline status")
 	cmd.Flags().BoolVarP(&o.NoWaitAfterMerge, "no-wait", "", false, "Disables waiting for completing promotion after the Pull request is merged")
 	cmd.Flags().BoolVarP(&o.IgnoreLocalFiles, "ignore-local-file", "", false, "Ignores the local file system when deducing the Git repository")
+	cmd.Flags().BoolVarP(&o.UptimeCheck, "uptime-check", "", false, "Registers an uptime check of the promoted application which is probed by the blackbox exporter of the Prometheus addon")
+	cmd.Flags().StringVarP(&o.UptimeAlertReceiver, "uptime-alert-receiver", "", "", "The Alertmanager receiver the alerts of the uptime check are routed to")
+	cmd.Flags().BoolVarP(&o.OverrideWindow, "override-window", "", false, "Promotes outside the deployment windows and freeze periods of the Environment for emergencies")
+	cmd.Flags().BoolVarP(&o.RequireSigned, "require-signed", "", false, fmt.Sprintf("Fails rather than creating unsigned commits in the Environment repository. Commits are signed with the key in the $%s environment variable. Environments with the %s annotation always require signed commits", gits.EnvGitSigningKey, promotion.AnnotationRequireSignedCommits))
+	cmd.Flags().StringVarP(&o.Rollout.Strategy, "strategy", "", "", fmt.Sprintf("The deployment strategy generated in the Environment repository which should be one of: %s. If not specified the current strategy is kept", strings.Join(flagger.Strategies, ", ")))
+	cmd.Flags().StringVarP(&o.Rollout.Interval, "analysis-interval", "", "1m", "The interval between the analysis runs of the canary or blue-green deployment")
+	cmd.Flags().IntVarP(&o.Rollout.Threshold, "max-failed-checks", "", 5, "The number of failed checks of the analysis before rolling back")
+	cmd.Flags().IntVarP(&o.Rollout.StepWeight, "step-weight", "", 10, "The percentage of traffic added to the canary on every successful analysis run")
+	cmd.Flags().IntVarP(&o.Rollout.MaxWeight, "max-weight", "", 50, "The maximum percentage of traffic routed to the canary before promoting it")
+	cmd.Flags().IntVarP(&o.Rollout.Iterations, "iterations", "", 10, "The number of analysis runs of a blue-green deployment before switching the traffic")
+	cmd.Flags().Float64VarP(&o.Rollout.SuccessRate, "min-success-rate", "", 99, "The minimum percentage of successful requests during the analysis")
+	cmd.Flags().IntVarP(&o.Rollout.MaxLatency, "max-latency", "", 500, "The maximum 99th percentile request duration in milliseconds during the analysis")
+	cmd.Flags().BoolVarP(&o.ApplyQueued, "apply-queued", "", false, "Merges the Pull Requests of the queued promotions whose gates have passed, such as the promotions queued until a deployment window opens")
 }
 
 func (o *PromoteOptions) hasApplicationFlag() bool {
@@ -212,7 +270,7 @@ func (o *PromoteOptions) setApplicationNameFromDiscoveredAppName(discoverAppName
 			Default: true,
 		}
 		surveyOpts := survey.WithStdio(o.In, o.Out, o.Err)
-		err = survey.AskOne(prompt, &continueWithAppName, nil, surveyOpts)
+		err = util.AskOne(prompt, &continueWithAppName, nil, surveyOpts)
 		if err != nil {
 			return err
 		}
@@ -250,10 +308,18 @@ func (o *PromoteOptions) EnsureApplicationNameIsDefined(sf searchForChartFn, df
 
 // Run implements this command
 func (o *PromoteOptions) Run() error {
+	if o.ApplyQueued {
+		return o.ApplyQueuedPromotions()
+	}
 	err := o.EnsureApplicationNameIsDefined(o.SearchForChart, o.DiscoverAppName)
 	if err != nil {
 		return err
 	}
+	o.Rollout.Application = o.Application
+	err = o.Rollout.Validate()
+	if err != nil {
+		return err
+	}
 
 	jxClient, ns, err := o.JXClientAndDevNamespace()
 	if err != nil {
@@ -350,6 +416,7 @@ func (o *PromoteOptions) Run() error {
 			return err
 		}
 	}
+	o.RegisterUptimeCheck(targetNS)
 	return err
 }
 
@@ -397,6 +464,7 @@ func (o *PromoteOptions) PromoteAllAutomatic() error {
 					return err
 				}
 			}
+			o.RegisterUptimeCheck(ns)
 		}
 	}
 	return nil
@@ -439,7 +507,7 @@ func (o *PromoteOptions) Promote(targetNS string, env *v1.Environment, warnIfAut
 			Default: false,
 		}
 		flag := false
-		err := survey.AskOne(confirm, &flag, nil, surveyOpts)
+		err := util.AskOne(confirm, &flag, nil, surveyOpts)
 		if err != nil {
 			return releaseInfo, err
 		}
@@ -487,6 +555,22 @@ func (o *PromoteOptions) Promote(targetNS string, env *v1.Environment, warnIfAut
 		}
 	}
 
+	if o.Rollout.Enabled() {
+		return releaseInfo, fmt.Errorf("The %s strategy requires the Environment to use GitOps", o.Rollout.Strategy)
+	}
+	if env != nil && !o.OverrideWindow {
+		// promotions which are not via Pull Requests can not be queued
+		windows, err := promotion.EnvironmentWindows(env)
+		if err != nil {
+			return releaseInfo, err
+		}
+		if windows != nil {
+			if open, _ := windows.Open(time.Now()); !open {
+				return releaseInfo, fmt.Errorf("Cannot promote to Environment %s as it is %s. Use --override-window for emergencies", env.Name, windows.Describe(time.Now()))
+			}
+		}
+	}
+
 	err = o.verifyHelmConfigured()
 	if err != nil {
 		return releaseInfo, err
@@ -529,7 +613,9 @@ func (o *PromoteOptions) Promote(targetNS string, env *v1.Environment, warnIfAut
 		Wait:        true,
 	}
 
-	err = o.InstallChartWithOptions(helmOptions)
+	err = o.RunInEnvironmentCluster(env, func() error {
+		return o.InstallChartWithOptions(helmOptions)
+	})
 	if err == nil {
 		err = o.CommentOnIssues(targetNS, env, promoteKey)
 		if err != nil {
@@ -566,8 +652,15 @@ func (o *PromoteOptions) PromoteViaPullRequest(env *v1.Environment, releaseInfo
 			}
 		}
 		requirements.SetAppVersion(app, version, o.HelmRepositoryURL, o.Alias)
+		if o.Rollout.Strategy != "" {
+			return flagger.WriteCanary(dir, &o.Rollout)
+		}
 		return nil
 	}
+	err := o.ConfigureGitSigning(o.RequireSigned || promotion.RequiresSignedCommits(env))
+	if err != nil {
+		return errors.Wrapf(err, "configuring the signing of the commits to the environment %s", env.Name)
+	}
 	gitProvider, _, err := o.CreateGitProviderForURLWithoutKind(env.Spec.Source.URL)
 	if err != nil {
 		return errors.Wrapf(err, "creating git provider for %s", env.Spec.Source.URL)
@@ -697,6 +790,37 @@ func (o *PromoteOptions) waitForGitOpsPullRequest(ns string, env *v1.Environment
 	}
 
 	if pullRequestInfo != nil {
+		gates, err := promotion.EnsurePromotion(kubeClient, o.Namespace, env, &promotion.Promotion{
+			Application:       o.Application,
+			Version:           releaseInfo.Version,
+			PullRequestURL:    pullRequestInfo.PullRequest.URL,
+			PullRequestNumber: util.DereferenceInt(pullRequestInfo.PullRequest.Number),
+			WindowOverridden:  o.OverrideWindow,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "creating the promotion gates of environment %s", env.Name)
+		}
+		if gates != nil {
+			log.Logger().Infof("Promotion %s has to pass the gates %s before it is merged, see: jx get promotions", util.ColorInfo(gates.ID), util.ColorInfo(gates.Summary()))
+			if gates.Gate(promotion.GateWindow) != nil && !o.NoMergePullRequest {
+				evaluator := &promotion.Evaluator{KubeClient: kubeClient, Namespace: o.Namespace}
+				err = evaluator.Evaluate(gates, env)
+				if err != nil {
+					return errors.Wrapf(err, "evaluating the gates of promotion %s", gates.ID)
+				}
+				window := gates.Gate(promotion.GateWindow)
+				if window.State == promotion.StatePending {
+					log.Logger().Infof("Promotion %s is queued as Environment %s is %s. It is merged by 'jx promote --apply-queued' once the window opens",
+						util.ColorInfo(gates.ID), util.ColorInfo(env.Name), window.Description)
+					return nil
+				}
+			}
+		}
+		logPendingGates := ""
+		rollout, err := o.startRolloutWatch(ns, end)
+		if err != nil {
+			return err
+		}
 		for {
 			pr := pullRequestInfo.PullRequest
 			gitProvider, _, err := o.CreateGitProviderForURLWithoutKind(env.Spec.Source.URL)
@@ -744,6 +868,10 @@ func (o *PromoteOptions) waitForGitOpsPullRequest(ns string, env *v1.Environment
 
 						if o.NoWaitForUpdatePipeline {
 							log.Logger().Info("Pull Request merged but we are not waiting for the update pipeline to complete!")
+							err = o.watchRollout(ns, rollout)
+							if err != nil {
+								return err
+							}
 							err = o.CommentOnIssues(ns, env, promoteKey)
 							if err == nil {
 								err = promoteKey.OnPromoteUpdate(kubeClient, jxClient, o.Namespace, kube.CompletePromotionUpdate)
@@ -811,6 +939,10 @@ func (o *PromoteOptions) waitForGitOpsPullRequest(ns string, env *v1.Environment
 								}
 								if succeeded {
 									log.Logger().Info("Merge status checks all passed so the promotion worked!")
+									err = o.watchRollout(ns, rollout)
+									if err != nil {
+										return err
+									}
 									err = o.CommentOnIssues(ns, env, promoteKey)
 									if err == nil {
 										err = promoteKey.OnPromoteUpdate(kubeClient, jxClient, o.Namespace, kube.CompletePromotionUpdate)
@@ -834,8 +966,15 @@ func (o *PromoteOptions) waitForGitOpsPullRequest(ns string, env *v1.Environment
 					} else if status == "in-progress" {
 						log.Logger().Info("The build for the Pull Request last commit is currently in progress.")
 					} else {
+						gatesPassed := true
+						if status == "success" && !o.NoMergePullRequest {
+							gatesPassed, err = o.promotionGatesPassed(kubeClient, gates, env, pr, &logPendingGates)
+							if err != nil {
+								return err
+							}
+						}
 						if status == "success" {
-							if !(o.NoMergePullRequest) {
+							if !(o.NoMergePullRequest) && gatesPassed {
 								tideMerge := false
 								// Now check if tide is running or not
 								commitStatues, err := gitProvider.ListCommitStatus(pr.Owner, pr.Repo, pr.LastCommitSha)
@@ -884,6 +1023,82 @@ func (o *PromoteOptions) waitForGitOpsPullRequest(ns string, env *v1.Environment
 	return nil
 }
 
+// promotionGatesPassed evaluates the gates of the promotion returning true if the Pull Request can be merged or if
+// there are no gates
+func (o *PromoteOptions) promotionGatesPassed(kubeClient kubernetes.Interface, gates *promotion.Promotion, env *v1.Environment, pr *gits.GitPullRequest, logPending *string) (bool, error) {
+	if gates == nil {
+		return true, nil
+	}
+	evaluator := &promotion.Evaluator{KubeClient: kubeClient, Namespace: o.Namespace}
+	err := evaluator.Evaluate(gates, env)
+	if err != nil {
+		return false, errors.Wrapf(err, "evaluating the gates of promotion %s", gates.ID)
+	}
+	switch gates.State() {
+	case promotion.StateFailed:
+		return false, fmt.Errorf("Promotion %s failed its gates %s", gates.ID, gates.Summary())
+	case promotion.StatePending:
+		if *logPending != gates.Summary() {
+			*logPending = gates.Summary()
+			log.Logger().Infof("Waiting for the gates %s of promotion %s before merging Pull Request %s",
+				util.ColorInfo(gates.Summary()), util.ColorInfo(gates.ID), util.ColorInfo(pr.URL))
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// startRolloutWatch records the status of the Canary of the application before the promotion is merged so that the
+// analysis of the new version can be told apart from the previous one, returning nil if there is no analysis to watch
+func (o *PromoteOptions) startRolloutWatch(ns string, end time.Time) (*flagger.RolloutWatcher, error) {
+	if !o.Rollout.Enabled() || o.NoMergePullRequest {
+		return nil, nil
+	}
+	dynamicClient, _, err := o.GetFactory().CreateDynamicClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "creating the dynamic client")
+	}
+	initial, err := flagger.GetCanaryStatus(dynamicClient, ns, o.Application)
+	if err != nil {
+		return nil, err
+	}
+	poll := 10 * time.Second
+	if o.PullRequestPollDuration != nil {
+		poll = *o.PullRequestPollDuration
+	}
+	return &flagger.RolloutWatcher{
+		Client:       dynamicClient,
+		Namespace:    ns,
+		Name:         o.Application,
+		Initial:      initial,
+		Timeout:      time.Until(end),
+		PollInterval: poll,
+	}, nil
+}
+
+// watchRollout reports the progress of the analysis of the canary or blue-green deployment of the promotion
+func (o *PromoteOptions) watchRollout(ns string, rollout *flagger.RolloutWatcher) error {
+	if rollout == nil {
+		return nil
+	}
+	log.Logger().Infof("Watching the %s analysis of %s in namespace %s", o.Rollout.Strategy, util.ColorInfo(o.Application), util.ColorInfo(ns))
+	last := ""
+	rollout.OnProgress = func(status *flagger.CanaryStatus) {
+		text := status.String()
+		if text != last {
+			last = text
+			log.Logger().Infof("Canary %s: %s", util.ColorInfo(o.Application), text)
+		}
+	}
+	status, err := rollout.Wait()
+	if err != nil {
+		log.Logger().Errorf("%s", util.ColorError(err.Error()))
+		return err
+	}
+	log.Logger().Infof("The %s deployment of %s %s", o.Rollout.Strategy, util.ColorInfo(o.Application), util.ColorInfo(strings.ToLower(status.Phase)))
+	return nil
+}
+
 func (o *PromoteOptions) findLatestVersion(app string) (string, error) {
 	charts, err := o.Helm().SearchCharts(app, true)
 	if err != nil {
@@ -1124,6 +1339,45 @@ func (o *PromoteOptions) CommentOnIssues(targetNS string, environment *v1.Enviro
 	return nil
 }
 
+// RegisterUptimeCheck registers an uptime check of the service of the promoted application if enabled. Failures are
+// only logged as the promotion itself has succeeded
+func (o *PromoteOptions) RegisterUptimeCheck(targetNS string) {
+	if !o.UptimeCheck || o.Application == "" {
+		return
+	}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		log.Logger().Warnf("Failed to register the uptime check of %s: %s", o.Application, err)
+		return
+	}
+	svc, err := services.RegisterUptimeCheck(kubeClient, targetNS, o.serviceNames(targetNS), o.UptimeAlertReceiver)
+	if err != nil {
+		log.Logger().Warnf("Failed to register the uptime check of %s: %s", o.Application, err)
+		return
+	}
+	if svc == nil {
+		log.Logger().Warnf("Could not find the service of %s in namespace %s to register its uptime check. Has the promotion been deployed yet?",
+			o.Application, targetNS)
+		return
+	}
+	log.Logger().Infof("Registered the uptime check of service %s in namespace %s", util.ColorInfo(svc.Name), util.ColorInfo(targetNS))
+}
+
+// serviceNames returns the names the service of the application may have in the namespace
+func (o *PromoteOptions) serviceNames(ns string) []string {
+	answer := []string{}
+	for _, n := range []string{o.Application, o.ReleaseName, ns + "-" + o.Application} {
+		if n == "" {
+			continue
+		}
+		name := naming.ToValidName(n)
+		if util.StringArrayIndex(answer, name) < 0 {
+			answer = append(answer, name)
+		}
+	}
+	return answer
+}
+
 func (o *PromoteOptions) SearchForChart(filter string) (string, error) {
 	answer := ""
 	charts, err := o.Helm().SearchCharts(filter, false)
@@ -0,0 +1,83 @@
+package promote
+
+import (
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/promotion"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ApplyQueuedPromotions merges the Pull Requests of the pending promotions whose gates have now passed, such as the
+// promotions queued until the deployment window of their environment opens
+func (o *PromoteOptions) ApplyQueuedPromotions() error {
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return err
+	}
+	promotions, err := promotion.ListPromotions(kubeClient, ns)
+	if err != nil {
+		return err
+	}
+	evaluator := &promotion.Evaluator{KubeClient: kubeClient, Namespace: ns}
+	merged := 0
+	for _, p := range promotions {
+		if p.State() != promotion.StatePending || p.PullRequestNumber == 0 {
+			continue
+		}
+		env, err := jxClient.JenkinsV1().Environments(ns).Get(p.Environment, metav1.GetOptions{})
+		if err != nil {
+			log.Logger().Warnf("Failed to get Environment %s of promotion %s: %s", p.Environment, p.ID, err)
+			continue
+		}
+		err = evaluator.Evaluate(p, env)
+		if err != nil {
+			log.Logger().Warnf("Failed to evaluate the gates of promotion %s: %s", p.ID, err)
+			continue
+		}
+		switch p.State() {
+		case promotion.StateFailed:
+			log.Logger().Warnf("Promotion %s failed its gates %s", p.ID, p.Summary())
+			continue
+		case promotion.StatePending:
+			log.Logger().Infof("Promotion %s is waiting for its gates %s", util.ColorInfo(p.ID), util.ColorInfo(p.Summary()))
+			continue
+		}
+		err = o.mergeQueuedPromotion(p, env)
+		if err != nil {
+			log.Logger().Warnf("Failed to merge promotion %s: %s", p.ID, err)
+			continue
+		}
+		merged++
+	}
+	log.Logger().Infof("Merged %s queued promotions", util.ColorInfo(merged))
+	return nil
+}
+
+func (o *PromoteOptions) mergeQueuedPromotion(p *promotion.Promotion, env *v1.Environment) error {
+	gitProvider, gitInfo, err := o.CreateGitProviderForURLWithoutKind(env.Spec.Source.URL)
+	if err != nil {
+		return errors.Wrapf(err, "creating git provider for %s", env.Spec.Source.URL)
+	}
+	pr, err := gitProvider.GetPullRequest(gitInfo.Organisation, gitInfo, p.PullRequestNumber)
+	if err != nil {
+		return errors.Wrapf(err, "getting Pull Request %s", p.PullRequestURL)
+	}
+	if pr.Merged != nil && *pr.Merged {
+		return nil
+	}
+	if pr.IsClosed() {
+		return errors.Errorf("Pull Request %s is closed without merging", p.PullRequestURL)
+	}
+	err = gitProvider.MergePullRequest(pr, "jx promote merged queued promotion "+p.ID)
+	if err != nil {
+		return errors.Wrapf(err, "merging Pull Request %s", p.PullRequestURL)
+	}
+	log.Logger().Infof("Merged Pull Request %s of promotion %s", util.ColorInfo(p.PullRequestURL), util.ColorInfo(p.ID))
+	return nil
+}
@@ -0,0 +1,52 @@
+package restore
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/spf13/cobra"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+)
+
+// RestoreOptions contains the command line options
+type RestoreOptions struct {
+	*opts.CommonOptions
+}
+
+var (
+	restoreLong = templates.LongDesc(`
+		Restores a Jenkins X installation from a backup.
+
+		Valid resources include:
+
+		* cluster
+`)
+)
+
+// NewCmdRestore creates a command object for the "restore" command
+func NewCmdRestore(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &RestoreOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Restores a Jenkins X installation from a backup",
+		Long:  restoreLong,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.AddCommand(NewCmdRestoreCluster(commonOpts))
+
+	return cmd
+}
+
+// Run implements this command
+func (o *RestoreOptions) Run() error {
+	return o.Cmd.Help()
+}
@@ -0,0 +1,141 @@
+package restore
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/update"
+	"github.com/jenkins-x/jx/v2/pkg/kube/velero"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	restoreClusterLong = templates.LongDesc(`
+		Restores a Velero backup of the jx namespaces and CRDs onto the current cluster, such as a fresh cluster replacing a lost one
+
+		Velero has to be installed on the cluster first via 'jx create addon velero' using the bucket of the backups. Once the backup is restored the webhooks of the environment repositories are updated to point at the restored cluster
+`)
+
+	restoreClusterExample = templates.Examples(`
+		# Pick the backup to restore
+		jx restore cluster
+
+		# Restore the latest backup
+		jx restore cluster --latest
+
+		# Restore a backup
+		jx restore cluster --backup jx-backups-20201018020000
+	`)
+)
+
+// RestoreClusterOptions the options for the restore cluster command
+type RestoreClusterOptions struct {
+	*opts.CommonOptions
+
+	Backup       string
+	Namespace    string
+	Latest       bool
+	Timeout      time.Duration
+	SkipWebhooks bool
+}
+
+// NewCmdRestoreCluster creates a command object for the "restore cluster" command
+func NewCmdRestoreCluster(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &RestoreClusterOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "cluster",
+		Short:   "Restores a backup of the jx namespaces and CRDs onto the cluster",
+		Long:    restoreClusterLong,
+		Example: restoreClusterExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Backup, "backup", "", "", "The name of the backup to restore")
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "velero", "The namespace Velero is installed in")
+	cmd.Flags().BoolVarP(&options.Latest, "latest", "", false, "Restores the latest completed backup")
+	cmd.Flags().DurationVarP(&options.Timeout, "timeout", "", 30*time.Minute, "How long to wait for the restore to complete")
+	cmd.Flags().BoolVarP(&options.SkipWebhooks, "skip-webhooks", "", false, "Does not update the webhooks of the environment repositories once the backup is restored")
+	return cmd
+}
+
+// Run implements the command
+func (o *RestoreClusterOptions) Run() error {
+	dynamicClient, _, err := o.GetFactory().CreateDynamicClient()
+	if err != nil {
+		return errors.Wrap(err, "creating the dynamic client")
+	}
+	if o.Backup == "" {
+		o.Backup, err = o.pickBackup(dynamicClient)
+		if err != nil {
+			return err
+		}
+	}
+
+	name := fmt.Sprintf("%s-restore-%s", o.Backup, time.Now().Format("20060102150405"))
+	err = velero.CreateRestore(dynamicClient, o.Namespace, name, o.Backup)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Restoring backup %s, waiting up to %s for the restore %s to complete", util.ColorInfo(o.Backup), o.Timeout.String(), util.ColorInfo(name))
+	phase, err := velero.WaitForRestore(dynamicClient, o.Namespace, name, o.Timeout)
+	if err != nil {
+		return errors.Wrapf(err, "waiting for the restore of backup %s", o.Backup)
+	}
+	switch phase {
+	case velero.PhaseFailed:
+		return fmt.Errorf("the restore %s of backup %s failed, run 'velero restore describe %s -n %s' for the details", name, o.Backup, name, o.Namespace)
+	case velero.PhasePartiallyFailed:
+		log.Logger().Warnf("The restore %s of backup %s partially failed, run 'velero restore logs %s -n %s' for the details", name, o.Backup, name, o.Namespace)
+	default:
+		log.Logger().Infof("Restored backup %s", util.ColorInfo(o.Backup))
+	}
+
+	if o.SkipWebhooks {
+		return nil
+	}
+	log.Logger().Info("Updating the webhooks of the environment repositories to point at the restored cluster")
+	webhooks := &update.UpdateWebhooksOptions{
+		CommonOptions:  o.CommonOptions,
+		ExactHookMatch: false,
+		WarnOnFail:     true,
+	}
+	return webhooks.Run()
+}
+
+func (o *RestoreClusterOptions) pickBackup(dynamicClient dynamic.Interface) (string, error) {
+	backups, err := velero.ListBackups(dynamicClient, o.Namespace)
+	if err != nil {
+		return "", err
+	}
+	names := []string{}
+	for _, b := range backups {
+		if b.Phase == velero.PhaseCompleted {
+			names = append(names, b.Name)
+		}
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("no completed backups found in namespace %s", o.Namespace)
+	}
+	if o.Latest {
+		return names[0], nil
+	}
+	if o.BatchMode {
+		return "", util.MissingOption("backup")
+	}
+	return util.PickName(names, "Pick the backup to restore: ", "", o.GetIOFileHandles())
+}
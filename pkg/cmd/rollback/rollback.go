@@ -0,0 +1,338 @@
+package rollback
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/environments"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// RollbackOptions contains the command line options
+type RollbackOptions struct {
+	*opts.CommonOptions
+
+	ToRevision   int
+	History      bool
+	Max          int
+	Dir          string
+	NoWait       bool
+	Timeout      string
+	PollInterval time.Duration
+}
+
+var (
+	rollbackLong = templates.LongDesc(`
+		Rolls back a permanent environment which uses GitOps to a previous release.
+
+		Every commit which changed the environment chart on the branch of the environment git repository is a
+		release, numbered from 1 like the revisions of helm releases. Rolling back commits the environment chart of
+		the previous release, or of the release specified with --to-revision, and pushes it so that the pipeline of
+		the environment applies it. The rollback then waits for the pipeline to succeed and for the deployments of
+		the environment to be healthy.
+
+		Use --history to list the recent releases of the environment to pick the revision from.
+` + helper.SeeAlsoText("jx promote", "jx get environments"))
+
+	rollbackExample = templates.Examples(`
+		# List the recent releases of production
+		jx rollback production --history
+
+		# Roll back production to its previous release
+		jx rollback production
+
+		# Roll back production to revision 42 without waiting for it to be applied
+		jx rollback production --to-revision 42 --no-wait
+	`)
+)
+
+// NewCmdRollback creates the command
+func NewCmdRollback(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &RollbackOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "rollback ENVIRONMENT [flags]",
+		Short:   "Rolls back an environment to a previous release of its git repository",
+		Long:    rollbackLong,
+		Example: rollbackExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().IntVarP(&options.ToRevision, "to-revision", "r", 0, "The revision to roll back to, defaults to the release before the current one")
+	cmd.Flags().BoolVarP(&options.History, "history", "", false, "Lists the recent releases of the environment instead of rolling it back")
+	cmd.Flags().IntVarP(&options.Max, "max", "m", 10, "The maximum number of releases listed")
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", "", "The directory of a clone of the environment git repository, defaults to cloning it into a temporary directory")
+	cmd.Flags().BoolVarP(&options.NoWait, "no-wait", "", false, "Do not wait for the rollback to be applied and healthy")
+	cmd.Flags().StringVarP(&options.Timeout, "timeout", "t", "20m", "The timeout to wait for the rollback to be applied and healthy")
+	return cmd
+}
+
+// Run implements this command
+func (o *RollbackOptions) Run() error {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	name := ""
+	if len(o.Args) > 0 {
+		name = o.Args[0]
+	}
+	if name == "" {
+		if o.BatchMode {
+			return util.MissingArgument("environment")
+		}
+		envNames, err := kube.GetEnvironmentNames(jxClient, ns)
+		if err != nil {
+			return err
+		}
+		name, err = kube.PickEnvironment(envNames, "", o.GetIOFileHandles())
+		if err != nil {
+			return err
+		}
+	}
+	env, err := jxClient.JenkinsV1().Environments(ns).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "getting Environment %s in namespace %s", name, ns)
+	}
+	if !env.Spec.Kind.IsPermanent() || env.Spec.Kind == v1.EnvironmentKindTypeDevelopment || env.Spec.Source.URL == "" {
+		return fmt.Errorf("Environment %s does not use GitOps so it cannot be rolled back", name)
+	}
+
+	dir := o.Dir
+	if dir == "" {
+		dir, err = o.cloneEnvironment(env)
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir) //nolint:errcheck
+	}
+	releases, err := environments.ReleaseHistory(dir)
+	if err != nil {
+		return err
+	}
+	if len(releases) == 0 {
+		return fmt.Errorf("no releases found in the git repository of Environment %s", name)
+	}
+	if o.History {
+		o.renderHistory(releases)
+		return nil
+	}
+
+	current := releases[0]
+	release, err := o.pickRelease(name, releases)
+	if err != nil {
+		return err
+	}
+	if release.Revision == current.Revision {
+		log.Logger().Infof("Environment %s is already at revision %d", util.ColorInfo(name), release.Revision)
+		return nil
+	}
+	changed, err := environments.StageRollback(dir, release)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		log.Logger().Infof("Environment %s already matches revision %d", util.ColorInfo(name), release.Revision)
+		return nil
+	}
+
+	message := fmt.Sprintf("chore: rollback %s to revision %d (%s)\n\n%s", name, release.Revision, release.ShortSHA(), release.Message)
+	err = o.Git().CommitDir(dir, message)
+	if err != nil {
+		return errors.Wrapf(err, "committing the rollback of Environment %s", name)
+	}
+	branch := env.Spec.Source.Ref
+	if branch == "" {
+		branch = "master"
+	}
+	err = o.Git().Push(dir, "origin", false, "HEAD:"+branch)
+	if err != nil {
+		return errors.Wrapf(err, "pushing the rollback of Environment %s", name)
+	}
+	sha, err := o.Git().GetLatestCommitSha(dir)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Rolling back Environment %s from revision %d to revision %d %s with commit %s", util.ColorInfo(name),
+		current.Revision, release.Revision, release.Message, util.ColorInfo(sha))
+
+	if o.NoWait {
+		return nil
+	}
+	return o.waitForRollback(env, sha)
+}
+
+// cloneEnvironment clones the branch of the git repository of the environment into a temporary directory
+func (o *RollbackOptions) cloneEnvironment(env *v1.Environment) (string, error) {
+	gitURL := env.Spec.Source.URL
+	gitInfo, err := gits.ParseGitURL(gitURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse git URL %s", gitURL)
+	}
+	_, userAuth, err := o.GetPipelineGitAuthForRepo(gitInfo)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get pipeline user auth")
+	}
+	cloneURL, err := o.Git().CreateAuthenticatedURL(gitURL, userAuth)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create the authenticated URL of %s", gitURL)
+	}
+	dir, err := ioutil.TempDir("", "jx-rollback-")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create tmp dir to clone the environment repository")
+	}
+	err = o.Git().Clone(cloneURL, dir)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to clone git URL %s to directory %s", gitURL, dir)
+	}
+	if env.Spec.Source.Ref != "" {
+		err = o.Git().Checkout(dir, env.Spec.Source.Ref)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to checkout %s", env.Spec.Source.Ref)
+		}
+	}
+	return dir, nil
+}
+
+func (o *RollbackOptions) renderHistory(releases []*environments.EnvironmentRelease) {
+	table := o.CreateTable()
+	table.AddRow("REVISION", "COMMIT", "DATE", "AUTHOR", "MESSAGE")
+	for i, r := range releases {
+		if o.Max > 0 && i >= o.Max {
+			break
+		}
+		revision := strconv.Itoa(r.Revision)
+		if i == 0 {
+			revision = util.ColorInfo(revision + " (current)")
+		}
+		table.AddRow(revision, r.ShortSHA(), r.Date.Format(time.RFC3339), r.Author, r.Message)
+	}
+	table.Render()
+}
+
+// pickRelease returns the release of the revision to roll back to, prompting for it unless in batch mode
+func (o *RollbackOptions) pickRelease(name string, releases []*environments.EnvironmentRelease) (*environments.EnvironmentRelease, error) {
+	if o.ToRevision > 0 {
+		release := environments.FindRelease(releases, o.ToRevision)
+		if release == nil {
+			return nil, fmt.Errorf("no revision %d found, the revisions are 1 to %d, see: jx rollback %s --history", o.ToRevision, releases[0].Revision, name)
+		}
+		return release, nil
+	}
+	if len(releases) < 2 {
+		return nil, fmt.Errorf("there is no release before the current revision %d to roll back to", releases[0].Revision)
+	}
+	if o.BatchMode {
+		return releases[1], nil
+	}
+	names := []string{}
+	for i, r := range releases[1:] {
+		if o.Max > 0 && i >= o.Max {
+			break
+		}
+		names = append(names, fmt.Sprintf("%d %s %s", r.Revision, r.ShortSHA(), r.Message))
+	}
+	name, err := util.PickNameWithDefault(names, "Pick the revision to roll back to:", names[0], "", o.GetIOFileHandles())
+	if err != nil {
+		return nil, err
+	}
+	return releases[util.StringArrayIndex(names, name)+1], nil
+}
+
+// waitForRollback waits for the pipeline of the environment to apply the rollback commit and for the deployments of
+// the environment to be healthy
+func (o *RollbackOptions) waitForRollback(env *v1.Environment, sha string) error {
+	timeout, err := time.ParseDuration(o.Timeout)
+	if err != nil {
+		return errors.Wrapf(err, "invalid timeout %s", o.Timeout)
+	}
+	end := time.Now().Add(timeout)
+	pollInterval := o.PollInterval
+	if pollInterval == 0 {
+		pollInterval = 5 * time.Second
+	}
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Waiting for the pipeline of Environment %s to apply the rollback", util.ColorInfo(env.Name))
+	for {
+		activities, err := jxClient.JenkinsV1().PipelineActivities(ns).List(metav1.ListOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "listing the PipelineActivities in namespace %s", ns)
+		}
+		var activity *v1.PipelineActivity
+		for i := range activities.Items {
+			if activities.Items[i].Spec.LastCommitSHA == sha {
+				activity = &activities.Items[i]
+			}
+		}
+		if activity != nil && activity.Spec.Status.IsTerminated() {
+			if activity.Spec.Status != v1.ActivityStatusTypeSucceeded {
+				return fmt.Errorf("the pipeline %s applying the rollback of Environment %s finished with status %s", activity.Name, env.Name, activity.Spec.Status.String())
+			}
+			break
+		}
+		if time.Now().After(end) {
+			return fmt.Errorf("timed out after %s waiting for the pipeline of Environment %s to apply the rollback", o.Timeout, env.Name)
+		}
+		time.Sleep(pollInterval)
+	}
+
+	kubeClient, err := o.environmentKubeClient(env)
+	if err != nil {
+		return err
+	}
+	deployments, err := kubeClient.AppsV1().Deployments(env.Spec.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "listing the Deployments in namespace %s", env.Spec.Namespace)
+	}
+	for _, d := range deployments.Items {
+		remaining := time.Until(end)
+		if remaining <= 0 {
+			return fmt.Errorf("timed out after %s waiting for the rollback of Environment %s to be healthy", o.Timeout, env.Name)
+		}
+		err = kube.WaitForDeploymentRollout(kubeClient, d.Name, env.Spec.Namespace, remaining)
+		if err != nil {
+			return errors.Wrapf(err, "the rollback of Environment %s is not healthy", env.Name)
+		}
+	}
+	log.Logger().Infof("Environment %s was rolled back and is healthy", util.ColorInfo(env.Name))
+	return nil
+}
+
+// environmentKubeClient returns the kube client of the cluster of the environment
+func (o *RollbackOptions) environmentKubeClient(env *v1.Environment) (kubernetes.Interface, error) {
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return nil, err
+	}
+	cluster, err := kube.EnvironmentCluster(kubeClient, ns, env)
+	if err != nil {
+		return nil, err
+	}
+	if cluster != nil {
+		return cluster.KubeClient, nil
+	}
+	return kubeClient, nil
+}
@@ -0,0 +1,125 @@
+// +build unit
+
+package rollback_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	jxfake "github.com/jenkins-x/jx-api/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/clients/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/rollback"
+	"github.com/jenkins-x/jx/v2/pkg/environments"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func git(t *testing.T, dir string, args ...string) string {
+	cmd := util.Command{
+		Dir:  dir,
+		Name: "git",
+		Args: args,
+	}
+	out, err := cmd.RunWithoutRetry()
+	require.NoError(t, err)
+	return out
+}
+
+func promote(t *testing.T, dir string, version string) {
+	file := filepath.Join(dir, "env", "requirements.yaml")
+	require.NoError(t, ioutil.WriteFile(file, []byte("myapp: "+version+"\n"), util.DefaultFileWritePermissions))
+	git(t, dir, "add", "-A")
+	git(t, dir, "commit", "-q", "-m", "chore: promote myapp "+version)
+}
+
+func TestRollback(t *testing.T) {
+	t.Parallel()
+
+	tmpDir, err := ioutil.TempDir("", "rollback")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	origin := filepath.Join(tmpDir, "origin")
+	dir := filepath.Join(tmpDir, "clone")
+	git(t, tmpDir, "init", "-q", "--bare", origin)
+	git(t, tmpDir, "clone", "-q", origin, dir)
+	git(t, dir, "config", "user.name", "jx")
+	git(t, dir, "config", "user.email", "jx@example.com")
+	git(t, dir, "checkout", "-q", "-b", "master")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "env"), util.DefaultWritePermissions))
+	promote(t, dir, "1.0.0")
+	promote(t, dir, "1.1.0")
+	promote(t, dir, "1.2.0")
+	git(t, dir, "push", "-q", "origin", "master")
+
+	ns := "jx"
+	env := &v1.Environment{
+		ObjectMeta: metav1.ObjectMeta{Name: "production", Namespace: ns},
+		Spec: v1.EnvironmentSpec{
+			Kind:      v1.EnvironmentKindTypePermanent,
+			Namespace: "jx-production",
+			Source:    v1.EnvironmentRepository{URL: origin},
+		},
+	}
+	jxClient := jxfake.NewSimpleClientset(env)
+	commonOpts := opts.NewCommonOptionsWithFactory(fake.NewFakeFactory())
+	commonOpts.SetDevNamespace(ns)
+	commonOpts.SetKubeClient(kubefake.NewSimpleClientset())
+	commonOpts.SetJxClient(jxClient)
+	commonOpts.BatchMode = true
+	commonOpts.Out = os.Stdout
+	o := &rollback.RollbackOptions{
+		CommonOptions: &commonOpts,
+		Dir:           dir,
+		NoWait:        true,
+		Timeout:       "1m",
+	}
+	o.Args = []string{"production"}
+
+	o.History = true
+	err = o.Run()
+	require.NoError(t, err)
+
+	o.History = false
+	o.ToRevision = 7
+	err = o.Run()
+	assert.Error(t, err, "unknown revisions should be rejected")
+
+	// by default the environment is rolled back to the previous release
+	o.ToRevision = 0
+	err = o.Run()
+	require.NoError(t, err)
+	assert.Equal(t, "myapp: 1.1.0\n", git(t, origin, "show", "master:env/requirements.yaml")+"\n")
+
+	releases, err := environments.ReleaseHistory(dir)
+	require.NoError(t, err)
+	require.Len(t, releases, 4, "the rollback should be a new release")
+	assert.Contains(t, releases[0].Message, "chore: rollback production to revision 2")
+
+	// lets fail the pipeline of the environment applying the rollback
+	jxClient.PrependReactor("list", "pipelineactivities", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		activity := v1.PipelineActivity{
+			ObjectMeta: metav1.ObjectMeta{Name: "environment-production-master-5"},
+			Spec: v1.PipelineActivitySpec{
+				Status:        v1.ActivityStatusTypeFailed,
+				LastCommitSHA: git(t, origin, "rev-parse", "master"),
+			},
+		}
+		return true, &v1.PipelineActivityList{Items: []v1.PipelineActivity{activity}}, nil
+	})
+	o.NoWait = false
+	o.PollInterval = time.Millisecond
+	o.ToRevision = 1
+	err = o.Run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Failed")
+}
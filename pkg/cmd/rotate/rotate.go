@@ -0,0 +1,52 @@
+package rotate
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/spf13/cobra"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+)
+
+// RotateOptions contains the command line options
+type RotateOptions struct {
+	*opts.CommonOptions
+}
+
+var (
+	rotateLong = templates.LongDesc(`
+		Rotates the credentials of a Jenkins X installation.
+
+		Valid resources include:
+
+		* secret
+`)
+)
+
+// NewCmdRotate creates a command object for the "rotate" command
+func NewCmdRotate(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &RotateOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "Rotates the credentials of a Jenkins X installation",
+		Long:  rotateLong,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.AddCommand(NewCmdRotateSecret(commonOpts))
+
+	return cmd
+}
+
+// Run implements this command
+func (o *RotateOptions) Run() error {
+	return o.Cmd.Help()
+}
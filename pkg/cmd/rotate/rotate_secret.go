@@ -0,0 +1,361 @@
+package rotate
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/operations"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/docker"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/io/secrets"
+	"github.com/jenkins-x/jx/v2/pkg/secreturl"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// SecretKindGit the git token of the pipeline bot user
+	SecretKindGit = "git"
+	// SecretKindRegistry the credentials of the container registry the pipelines push images to
+	SecretKindRegistry = "registry"
+	// SecretKindWebhookHMAC the HMAC token signing the webhooks
+	SecretKindWebhookHMAC = "webhook-hmac"
+
+	// DockerConfigSecret the secret of the docker config.json the pipelines push images with
+	DockerConfigSecret = "jenkins-docker-cfg"
+	dockerConfigKey    = "config.json"
+)
+
+var (
+	// SecretKinds the kinds of secret which can be rotated
+	SecretKinds = []string{SecretKindGit, SecretKindRegistry, SecretKindWebhookHMAC}
+
+	rotateSecretLong = templates.LongDesc(`
+		Rotates a secret of the installation, verifying the new credentials before they are used.
+
+		The kinds of secret are:
+
+		* git: the git token of the pipeline bot user, which has to be generated in the git provider and passed via --token
+		* registry: the password of the container registry the pipelines push images to, passed via --password
+		* webhook-hmac: the HMAC token signing the webhooks which is generated, re-registering the webhooks of all repositories
+
+		The new secret is written to the secrets backend of jx boot first, either Vault or the local secrets directory,
+		so that a rotation which fails part way is completed by re-running the rotation or jx boot. Then the Kubernetes
+		secrets are updated and the deployments which read them are restarted and waited for.
+`)
+
+	rotateSecretExample = templates.Examples(`
+		# rotate the git token of the pipeline bot user
+		jx rotate secret git --token mynewtoken
+
+		# rotate the password of the container registry
+		jx rotate secret registry --host gcr.io --username _json_key --password "$(cat key.json)"
+
+		# generate a new HMAC token and re-register the webhooks
+		jx rotate secret webhook-hmac
+`)
+)
+
+// RotateSecretOptions the options for the "rotate secret" command
+type RotateSecretOptions struct {
+	*opts.CommonOptions
+
+	Kind        string
+	Token       string
+	Host        string
+	Username    string
+	Password    string
+	SkipBackend bool
+	Timeout     time.Duration
+
+	// verifyGitToken verifies the git provider accepts the token of the pipeline bot user
+	verifyGitToken func(token string) error
+	// verifyRegistry verifies the container registry accepts the credentials
+	verifyRegistry func(host string, username string, password string) error
+	// secretsBackend returns the client of the secrets backend and the base path of the secrets of the cluster or
+	// a nil client if the installation has no secrets backend
+	secretsBackend func() (secreturl.Client, string, error)
+	// rotateSecrets updates the kubernetes secrets and restarts the deployments which read them
+	rotateSecrets func(o *operations.RotateSecretsOptions) error
+}
+
+// NewCmdRotateSecret creates the command
+func NewCmdRotateSecret(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &RotateSecretOptions{
+		CommonOptions: commonOpts,
+	}
+	options.verifyGitToken = options.verifyGitProviderToken
+	options.verifyRegistry = func(host string, username string, password string) error {
+		return docker.VerifyRegistryLogin(host, username, password, 30*time.Second)
+	}
+	options.secretsBackend = options.bootSecretsBackend
+	options.rotateSecrets = func(o *operations.RotateSecretsOptions) error {
+		return o.Run()
+	}
+
+	cmd := &cobra.Command{
+		Use:     "secret " + strings.Join(SecretKinds, "|"),
+		Aliases: []string{"secrets"},
+		Short:   "Rotates a secret of the installation",
+		Long:    rotateSecretLong,
+		Example: rotateSecretExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Token, "token", "", "", "The new git token of the pipeline bot user when rotating the git secret")
+	cmd.Flags().StringVarP(&options.Host, "host", "", "", "The host of the container registry when rotating the registry secret. Defaults to the only registry of the docker config")
+	cmd.Flags().StringVarP(&options.Username, "username", "", "", "The user of the container registry when rotating the registry secret. Defaults to the current user of the registry")
+	cmd.Flags().StringVarP(&options.Password, "password", "", "", "The new password of the container registry when rotating the registry secret")
+	cmd.Flags().BoolVarP(&options.SkipBackend, "skip-backend", "", false, "Only updates the Kubernetes secrets and not the secrets backend of jx boot")
+	cmd.Flags().DurationVarP(&options.Timeout, "timeout", "", 5*time.Minute, "The time to wait for the components to become ready after the secret is rotated")
+
+	return cmd
+}
+
+// Run implements this command
+func (o *RotateSecretOptions) Run() error {
+	if len(o.Args) > 0 {
+		o.Kind = o.Args[0]
+	}
+	if o.Kind == "" {
+		return util.MissingArgument("kind")
+	}
+	switch o.Kind {
+	case SecretKindGit:
+		return o.rotateGit()
+	case SecretKindRegistry:
+		return o.rotateRegistry()
+	case SecretKindWebhookHMAC:
+		return o.rotateWebhookHMAC()
+	default:
+		return util.InvalidArg(o.Kind, SecretKinds)
+	}
+}
+
+func (o *RotateSecretOptions) rotateGit() error {
+	token := o.Token
+	if token == "" {
+		if o.BatchMode {
+			return util.MissingOption("token")
+		}
+		var err error
+		token, err = util.PickPassword("New git token of the pipeline bot user:", "", o.GetIOFileHandles())
+		if err != nil {
+			return err
+		}
+	}
+	err := o.verifyGitToken(token)
+	if err != nil {
+		return errors.Wrap(err, "verifying the new git token")
+	}
+	err = o.updateBackend("pipelineUser", map[string]interface{}{"token": token})
+	if err != nil {
+		return err
+	}
+	return o.rotateSecrets(o.operationsOptions(operations.ComponentBotToken, func(ops *operations.RotateSecretsOptions) {
+		ops.BotToken = token
+	}))
+}
+
+func (o *RotateSecretOptions) rotateRegistry() error {
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return errors.Wrap(err, "creating the kube client")
+	}
+	secret, err := kubeClient.CoreV1().Secrets(ns).Get(DockerConfigSecret, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("no %s secret found in namespace %s", DockerConfigSecret, ns)
+		}
+		return errors.Wrapf(err, "getting secret %s", DockerConfigSecret)
+	}
+	dockerConfig := map[string]interface{}{}
+	if len(secret.Data[dockerConfigKey]) > 0 {
+		err = json.Unmarshal(secret.Data[dockerConfigKey], &dockerConfig)
+		if err != nil {
+			return errors.Wrapf(err, "parsing %s of secret %s", dockerConfigKey, DockerConfigSecret)
+		}
+	}
+	auths, _ := dockerConfig["auths"].(map[string]interface{})
+	if auths == nil {
+		auths = map[string]interface{}{}
+	}
+
+	host := o.Host
+	if host == "" {
+		if len(auths) != 1 {
+			return util.MissingOption("host")
+		}
+		for k := range auths {
+			host = k
+		}
+	}
+	entry, _ := auths[host].(map[string]interface{})
+	if entry == nil {
+		entry = map[string]interface{}{}
+	}
+	username := o.Username
+	if username == "" {
+		username, _ = docker.AuthCredentials(entry)
+		if username == "" {
+			return util.MissingOption("username")
+		}
+	}
+	password := o.Password
+	if password == "" {
+		if o.BatchMode {
+			return util.MissingOption("password")
+		}
+		password, err = util.PickPassword(fmt.Sprintf("New password of user %s of registry %s:", username, host), "", o.GetIOFileHandles())
+		if err != nil {
+			return err
+		}
+	}
+
+	err = o.verifyRegistry(host, username, password)
+	if err != nil {
+		return errors.Wrapf(err, "verifying the new credentials of registry %s", host)
+	}
+	err = o.updateBackend("docker", map[string]interface{}{"url": host, "username": username, "password": password})
+	if err != nil {
+		return err
+	}
+
+	delete(entry, "username")
+	delete(entry, "password")
+	entry["auth"] = base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	auths[host] = entry
+	dockerConfig["auths"] = auths
+	data, err := json.Marshal(dockerConfig)
+	if err != nil {
+		return errors.Wrap(err, "marshalling the docker config")
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[dockerConfigKey] = data
+	_, err = kubeClient.CoreV1().Secrets(ns).Update(secret)
+	if err != nil {
+		return errors.Wrapf(err, "updating secret %s", DockerConfigSecret)
+	}
+	log.Logger().Infof("Rotated the credentials of user %s of registry %s", util.ColorInfo(username), util.ColorInfo(host))
+	return nil
+}
+
+func (o *RotateSecretOptions) rotateWebhookHMAC() error {
+	token, err := operations.GenerateHMACToken()
+	if err != nil {
+		return err
+	}
+	err = o.updateBackend("prow", map[string]interface{}{"hmacToken": token})
+	if err != nil {
+		return err
+	}
+	return o.rotateSecrets(o.operationsOptions(operations.ComponentHMAC, func(ops *operations.RotateSecretsOptions) {
+		ops.HMACToken = token
+	}))
+}
+
+func (o *RotateSecretOptions) operationsOptions(component string, fn func(ops *operations.RotateSecretsOptions)) *operations.RotateSecretsOptions {
+	ops := &operations.RotateSecretsOptions{
+		CommonOptions: o.CommonOptions,
+		Components:    []string{component},
+		Timeout:       o.Timeout,
+	}
+	fn(ops)
+	return ops
+}
+
+// updateBackend merges the values into the secret of the secrets backend so that jx boot does not restore the
+// previous secret
+func (o *RotateSecretOptions) updateBackend(name string, values map[string]interface{}) error {
+	if o.SkipBackend {
+		return nil
+	}
+	client, basePath, err := o.secretsBackend()
+	if err != nil {
+		return errors.Wrap(err, "creating the client of the secrets backend")
+	}
+	if client == nil {
+		log.Logger().Infof("No secrets backend found so only updating the Kubernetes secrets")
+		return nil
+	}
+	path := name
+	if basePath != "" {
+		path = basePath + "/" + name
+	}
+	// the secret does not exist if it cannot be read
+	data, err := client.Read(path)
+	if err != nil || data == nil {
+		data = map[string]interface{}{}
+	}
+	for k, v := range values {
+		data[k] = v
+	}
+	_, err = client.Write(path, data)
+	if err != nil {
+		return errors.Wrapf(err, "writing secret %s to the secrets backend", path)
+	}
+	log.Logger().Infof("Updated secret %s of the secrets backend", util.ColorInfo(path))
+	return nil
+}
+
+// bootSecretsBackend returns the client of the secrets backend of the requirements of the team or nil if the
+// installation was not booted
+func (o *RotateSecretOptions) bootSecretsBackend() (secreturl.Client, string, error) {
+	settings, err := o.TeamSettings()
+	if err != nil {
+		return nil, "", errors.Wrap(err, "getting the team settings")
+	}
+	requirements, err := config.GetRequirementsConfigFromTeamSettings(settings)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "getting the requirements from the team settings")
+	}
+	if requirements == nil {
+		return nil, "", nil
+	}
+	client, err := o.GetSecretURLClient(secrets.ToSecretsLocation(string(requirements.SecretStorage)))
+	if err != nil {
+		return nil, "", err
+	}
+	return client, requirements.Cluster.ClusterName, nil
+}
+
+// verifyGitProviderToken lists the organisations of the pipeline bot user with the token
+func (o *RotateSecretOptions) verifyGitProviderToken(token string) error {
+	server, user, err := o.GetPipelineGitAuth()
+	if err != nil {
+		return err
+	}
+	if server == nil || user == nil {
+		return fmt.Errorf("no pipeline git user found")
+	}
+	newUser := *user
+	newUser.ApiToken = token
+	newUser.Password = ""
+	provider, err := gits.CreateProvider(server, &newUser, o.Git())
+	if err != nil {
+		return errors.Wrapf(err, "creating the git provider of %s", server.URL)
+	}
+	_, err = provider.ListOrganisations()
+	if err != nil {
+		return errors.Wrapf(err, "the git provider %s rejected the token of user %s", server.URL, user.Username)
+	}
+	return nil
+}
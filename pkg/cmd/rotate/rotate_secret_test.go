@@ -0,0 +1,141 @@
+// +build unit
+
+package rotate
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/clients/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/operations"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/secreturl"
+	"github.com/jenkins-x/jx/v2/pkg/secreturl/fakevault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+const testNamespace = "jx"
+
+func TestRotateGitSecret(t *testing.T) {
+	o, backend, rotated := newRotateSecretOptions(t, kubefake.NewSimpleClientset())
+	o.Args = []string{SecretKindGit}
+	o.Token = "new-token"
+	verified := ""
+	o.verifyGitToken = func(token string) error {
+		verified = token
+		return nil
+	}
+	_, err := backend.Write("mycluster/pipelineUser", map[string]interface{}{"username": "bot", "token": "old-token"})
+	require.NoError(t, err)
+
+	err = o.Run()
+	require.NoError(t, err)
+
+	assert.Equal(t, "new-token", verified)
+	data, err := backend.Read("mycluster/pipelineUser")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"username": "bot", "token": "new-token"}, data)
+	require.Len(t, *rotated, 1)
+	assert.Equal(t, []string{operations.ComponentBotToken}, (*rotated)[0].Components)
+	assert.Equal(t, "new-token", (*rotated)[0].BotToken)
+}
+
+func TestRotateWebhookHMACSecret(t *testing.T) {
+	o, backend, rotated := newRotateSecretOptions(t, kubefake.NewSimpleClientset())
+	o.Args = []string{SecretKindWebhookHMAC}
+
+	err := o.Run()
+	require.NoError(t, err)
+
+	require.Len(t, *rotated, 1)
+	assert.Equal(t, []string{operations.ComponentHMAC}, (*rotated)[0].Components)
+	token := (*rotated)[0].HMACToken
+	assert.NotEmpty(t, token)
+	data, err := backend.Read("mycluster/prow")
+	require.NoError(t, err)
+	assert.Equal(t, token, data["hmacToken"])
+}
+
+func TestRotateRegistrySecret(t *testing.T) {
+	dockerConfig := `{"auths":{"gcr.io":{"auth":"` + base64.StdEncoding.EncodeToString([]byte("_json_key:old")) + `","email":"bot@example.com"}}}`
+	kubeClient := kubefake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: DockerConfigSecret, Namespace: testNamespace},
+		Data:       map[string][]byte{dockerConfigKey: []byte(dockerConfig)},
+	})
+	o, backend, _ := newRotateSecretOptions(t, kubeClient)
+	o.Args = []string{SecretKindRegistry}
+	o.Password = "new"
+	var verified []string
+	o.verifyRegistry = func(host string, username string, password string) error {
+		verified = []string{host, username, password}
+		return nil
+	}
+
+	err := o.Run()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"gcr.io", "_json_key", "new"}, verified)
+	auth, email := dockerAuth(t, kubeClient, "gcr.io")
+	assert.Equal(t, "_json_key:new", auth)
+	assert.Equal(t, "bot@example.com", email, "the other fields of the docker config should be kept")
+	data, err := backend.Read("mycluster/docker")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"url": "gcr.io", "username": "_json_key", "password": "new"}, data)
+}
+
+func TestRotateSecretInvalidKind(t *testing.T) {
+	o, _, _ := newRotateSecretOptions(t, kubefake.NewSimpleClientset())
+	o.Args = []string{"nexus"}
+	assert.Error(t, o.Run())
+}
+
+func newRotateSecretOptions(t *testing.T, kubeClient kubernetes.Interface) (*RotateSecretOptions, secreturl.Client, *[]*operations.RotateSecretsOptions) {
+	commonOpts := opts.NewCommonOptionsWithFactory(fake.NewFakeFactory())
+	commonOpts.SetDevNamespace(testNamespace)
+	commonOpts.SetKubeClient(kubeClient)
+	commonOpts.BatchMode = true
+
+	backend := fakevault.NewFakeClient()
+	rotated := []*operations.RotateSecretsOptions{}
+	o := &RotateSecretOptions{
+		CommonOptions: &commonOpts,
+		verifyGitToken: func(token string) error {
+			t.Fatal("the git token should not be verified")
+			return nil
+		},
+		verifyRegistry: func(host string, username string, password string) error {
+			t.Fatal("the registry should not be verified")
+			return nil
+		},
+		secretsBackend: func() (secreturl.Client, string, error) {
+			return backend, "mycluster", nil
+		},
+		rotateSecrets: func(ops *operations.RotateSecretsOptions) error {
+			rotated = append(rotated, ops)
+			return nil
+		},
+	}
+	return o, backend, &rotated
+}
+
+func dockerAuth(t *testing.T, kubeClient kubernetes.Interface, host string) (string, string) {
+	secret, err := kubeClient.CoreV1().Secrets(testNamespace).Get(DockerConfigSecret, metav1.GetOptions{})
+	require.NoError(t, err)
+	config := struct {
+		Auths map[string]struct {
+			Auth  string `json:"auth"`
+			Email string `json:"email"`
+		} `json:"auths"`
+	}{}
+	err = json.Unmarshal(secret.Data[dockerConfigKey], &config)
+	require.NoError(t, err)
+	auth, err := base64.StdEncoding.DecodeString(config.Auths[host].Auth)
+	require.NoError(t, err)
+	return string(auth), config.Auths[host].Email
+}
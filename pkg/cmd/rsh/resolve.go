@@ -0,0 +1,160 @@
+package rsh
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/tekton"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// stepContainerPrefix the prefix of the names of the containers of the steps of build pods
+const stepContainerPrefix = "step-"
+
+// BuildPipeline returns the owner/repository/branch name of the pipeline of the build pod
+func BuildPipeline(pod *corev1.Pod) string {
+	return pod.Labels[v1.LabelOwner] + "/" + pod.Labels[v1.LabelRepository] + "/" + pod.Labels[v1.LabelBranch]
+}
+
+// BuildPods returns the build pods of the pipelines matching the filter owner/repository/branch, where the branch or
+// the branch and owner may be omitted, sorted so that the pods of the latest builds come first
+func BuildPods(client kubernetes.Interface, ns string, filter string) ([]*corev1.Pod, error) {
+	selector := fmt.Sprintf("%s,%s!=%s", v1.LabelBuild, tekton.LabelType, tekton.MetaPipeline.String())
+	list, err := client.CoreV1().Pods(ns).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the build pods in namespace %s", ns)
+	}
+	owner, repo, branch := "", "", ""
+	if filter != "" {
+		parts := strings.SplitN(filter, "/", 3)
+		switch len(parts) {
+		case 1:
+			repo = parts[0]
+		case 2:
+			owner, repo = parts[0], parts[1]
+		default:
+			owner, repo, branch = parts[0], parts[1], parts[2]
+		}
+	}
+	answer := []*corev1.Pod{}
+	for i := range list.Items {
+		pod := &list.Items[i]
+		if !matchesLabel(pod, v1.LabelOwner, owner) || !matchesLabel(pod, v1.LabelRepository, repo) || !matchesLabel(pod, v1.LabelBranch, branch) {
+			continue
+		}
+		answer = append(answer, pod)
+	}
+	sort.SliceStable(answer, func(i, j int) bool {
+		bi, bj := buildNumber(answer[i]), buildNumber(answer[j])
+		if bi != bj {
+			return bi > bj
+		}
+		return answer[j].CreationTimestamp.Before(&answer[i].CreationTimestamp)
+	})
+	return answer, nil
+}
+
+// LatestBuildPod returns the running pod of the latest build of the pipeline matching the filter owner/repository/branch
+func LatestBuildPod(client kubernetes.Interface, ns string, filter string) (*corev1.Pod, error) {
+	pods, err := BuildPods(client, ns, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(pods) == 0 {
+		return nil, errors.Errorf("no build pods found for pipeline %s in namespace %s", filter, ns)
+	}
+	pipeline := BuildPipeline(pods[0])
+	build := pods[0].Labels[v1.LabelBuild]
+	for _, pod := range pods {
+		if BuildPipeline(pod) != pipeline || pod.Labels[v1.LabelBuild] != build {
+			break
+		}
+		if pod.DeletionTimestamp == nil && pod.Status.Phase == corev1.PodRunning {
+			return pod, nil
+		}
+	}
+	return nil, errors.Errorf("the latest build #%s of pipeline %s is no longer running so its containers cannot be executed in", build, pipeline)
+}
+
+// AppPod returns the newest ready pod of the deployment of the app in the namespace or nil if there is no such deployment
+func AppPod(client kubernetes.Interface, ns string, app string) (*corev1.Pod, error) {
+	list, err := client.AppsV1().Deployments(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the deployments in namespace %s", ns)
+	}
+	for _, d := range list.Items {
+		if d.Name != app && kube.GetAppName(d.Name, ns) != app {
+			continue
+		}
+		pods, err := kube.GetDeploymentPods(client, d.Name, ns)
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing the pods of deployment %s in namespace %s", d.Name, ns)
+		}
+		var answer *corev1.Pod
+		for i := range pods {
+			pod := &pods[i]
+			if kube.IsPodReady(pod) && (answer == nil || answer.CreationTimestamp.Before(&pod.CreationTimestamp)) {
+				answer = pod
+			}
+		}
+		if answer == nil {
+			return nil, errors.Errorf("deployment %s of app %s in namespace %s has no ready pods", d.Name, app, ns)
+		}
+		return answer, nil
+	}
+	return nil, nil
+}
+
+// ContainerName returns the name of the container of the pod to execute in. The container of a step of a build pod
+// may be given without its step- prefix. If no container is given the running step of build pods is used otherwise
+// the first container
+func ContainerName(pod *corev1.Pod, container string) (string, error) {
+	names := []string{}
+	for _, c := range pod.Spec.Containers {
+		names = append(names, c.Name)
+	}
+	if container != "" {
+		for _, name := range []string{container, stepContainerPrefix + container} {
+			for _, n := range names {
+				if n == name {
+					return n, nil
+				}
+			}
+		}
+		return "", errors.Errorf("pod %s has no container %s, the containers are: %s", pod.Name, container, strings.Join(names, ", "))
+	}
+	if len(names) == 0 {
+		return "", errors.Errorf("pod %s has no containers", pod.Name)
+	}
+	// the containers of the steps of build pods all start at once but wait for the previous steps to terminate so the
+	// first running step is the one which is running its command
+	running := map[string]bool{}
+	for _, s := range pod.Status.ContainerStatuses {
+		running[s.Name] = s.State.Running != nil
+	}
+	for _, n := range names {
+		if strings.HasPrefix(n, stepContainerPrefix) && running[n] {
+			return n, nil
+		}
+	}
+	return names[0], nil
+}
+
+func matchesLabel(pod *corev1.Pod, label string, value string) bool {
+	return value == "" || strings.EqualFold(pod.Labels[label], value)
+}
+
+func buildNumber(pod *corev1.Pod) int {
+	n, err := strconv.Atoi(pod.Labels[v1.LabelBuild])
+	if err != nil {
+		return 0
+	}
+	return n
+}
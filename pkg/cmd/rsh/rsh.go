@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 
 	"github.com/jenkins-x/jx-logging/pkg/log"
@@ -17,6 +18,8 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 const (
@@ -36,12 +39,19 @@ type RshOptions struct {
 	DevPod      bool
 	Username    string
 	Environment string
+	Build       bool
 }
 
 var (
 	rsh_long = templates.LongDesc(`
 		Opens a terminal or runs a command in a pods container
 
+		The pod is resolved from the name of an app, whose newest ready pod is used, or from the name or part of the name
+		of a pod. With --build the argument is the pipeline whose latest build pod is used, given as
+		owner/repository/branch where the branch or the owner and branch may be omitted.
+
+		The container defaults to the running step of build pods and to the first container of other pods. The steps
+		of build pods may be given to --container without their 'step-' prefix.
 `)
 
 	rsh_example = templates.Examples(`
@@ -51,6 +61,15 @@ var (
 		# Opens a terminal in the cheese container in the latest pod in the foo deployment
 		jx rsh -c cheese foo
 
+		# Open a terminal in the newest pod of the app foo in the staging environment
+		jx rsh foo --environment staging
+
+		# Open a terminal in the running step of the latest build of the master branch of myorg/myapp
+		jx rsh --build myorg/myapp/master
+
+		# Open a terminal in the build step of the latest build of myapp
+		jx rsh --build myapp -c build
+
 		# To connect to one of your DevPods use:
 		jx rsh -d
 
@@ -64,7 +83,7 @@ func NewCmdRsh(commonOpts *opts.CommonOptions) *cobra.Command {
 		CommonOptions: commonOpts,
 	}
 	cmd := &cobra.Command{
-		Use:     "rsh [deploymentOrPodName]",
+		Use:     "rsh [app|pod|pipeline]",
 		Short:   "Opens a terminal in a pod or runs a command in the pod",
 		Long:    rsh_long,
 		Example: rsh_example,
@@ -76,14 +95,15 @@ func NewCmdRsh(commonOpts *opts.CommonOptions) *cobra.Command {
 			helper.CheckErr(err)
 		},
 	}
-	cmd.Flags().StringVarP(&options.Container, "container", "c", "", "The name of the container to log")
+	cmd.Flags().StringVarP(&options.Container, "container", "c", "", "The name of the container to open the terminal in. Defaults to the running step of build pods and the first container of other pods")
 	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "the namespace to look for the Deployment. Defaults to the current namespace")
-	cmd.Flags().StringVarP(&options.Namespace, "pod", "p", "", "the pod name to use")
+	cmd.Flags().StringVarP(&options.Pod, "pod", "p", "", "the pod name to use")
 	cmd.Flags().StringVarP(&options.Executable, "shell", "s", "", "Path to the shell command")
 	cmd.Flags().BoolVarP(&options.DevPod, "devpod", "d", false, "Connect to a DevPod")
 	cmd.Flags().StringVarP(&options.ExecCmd, "execute", "e", DefaultRshCommand, "Execute this command on the remote container")
 	cmd.Flags().StringVarP(&options.Username, "username", "", "", "The username to create the DevPod. If not specified defaults to the current operating system user or $USER'")
 	cmd.Flags().StringVarP(&options.Environment, "environment", "", "", "The environment in which to look for the Deployment. Defaults to the current environment")
+	cmd.Flags().BoolVarP(&options.Build, "build", "", false, "Opens the terminal in the latest build pod of the pipeline given as owner/repository/branch, where the branch or the owner and branch may be omitted")
 
 	return cmd
 }
@@ -106,69 +126,52 @@ func (o *RshOptions) Run() error {
 		if err != nil {
 			return err
 		}
+	} else if o.Build && o.Namespace == "" {
+		// the pipelines run in the dev namespace
+		ns, _, err = kube.GetDevNamespace(client, curNs)
+		if err != nil {
+			return err
+		}
 	}
 
 	if o.ExecCmd == "" {
 		o.ExecCmd = DefaultRshCommand
 	}
-	filter := ""
-	names := []string{}
-	podsName := "Pods"
+
+	var pod *corev1.Pod
+	if o.Build {
+		pod, err = o.pickBuildPod(client, ns, args)
+	} else if !o.DevPod && o.Pod == "" && len(args) > 0 {
+		pod, err = AppPod(client, ns, args[0])
+	}
+	if err != nil {
+		return err
+	}
+	name := ""
 	pods := map[string]*corev1.Pod{}
-	if o.DevPod {
-		podsName = "DevPods"
-		userName, err := o.GetUsername(o.Username)
-		if err != nil {
-			return err
-		}
-		names, pods, err = kube.GetDevPodNames(client, ns, userName)
-		if err != nil {
-			return err
-		}
+	if pod != nil {
+		name = pod.Name
 	} else {
-		names, err = kube.GetPodNames(client, ns, "")
+		name, pods, err = o.pickPod(client, ns, args)
 		if err != nil {
 			return err
 		}
 	}
-	if len(names) == 0 {
-		if filter == "" {
-			return fmt.Errorf("There are no %s", podsName)
-		} else {
-			return fmt.Errorf("There are no %s matching filter: %s", podsName, filter)
-		}
-	}
-	name := o.Pod
-	if len(args) == 0 {
-		if util.StringArrayIndex(names, name) < 0 {
-			n, err := util.PickName(names, "Pick Pod:", "", o.GetIOFileHandles())
+
+	container := o.Container
+	if !o.DevPod {
+		if pod == nil {
+			pod, err = client.CoreV1().Pods(ns).Get(name, metav1.GetOptions{})
 			if err != nil {
-				return err
+				return errors.Wrapf(err, "getting pod %s in namespace %s", name, ns)
 			}
-			name = n
 		}
-	} else {
-		name = args[0]
-		if util.StringArrayIndex(names, name) < 0 {
-			// lets try use the name as a filter
-			filteredNames := []string{}
-			for _, n := range names {
-				if strings.Contains(n, name) {
-					filteredNames = append(filteredNames, n)
-				}
-			}
-			n, err := util.PickName(filteredNames, "Pick Pod:", "", o.GetIOFileHandles())
-			if err != nil {
-				return err
-			}
-			name = n
+		container, err = ContainerName(pod, o.Container)
+		if err != nil {
+			return err
 		}
 	}
 
-	if name == "" {
-		return fmt.Errorf("No pod found for namespace %s with name %s", ns, name)
-	}
-
 	commandArguments := []string{}
 	if o.Executable == "" {
 		if o.DevPod {
@@ -183,7 +186,7 @@ func (o *RshOptions) Run() error {
 				commandArguments = []string{"--", "/bin/sh", "-c", o.ExecCmd}
 			}
 		} else {
-			bash, err := o.detectBash(ns, name, o.Container)
+			bash, err := o.detectBash(ns, name, container)
 			if err != nil {
 				if o.ExecCmd != "" {
 					if o.ExecCmd == "bash" {
@@ -212,9 +215,14 @@ func (o *RshOptions) Run() error {
 		commandArguments = []string{o.Executable}
 	}
 
-	a := []string{"exec", "-it", "-n", ns}
-	if o.Container != "" {
-		a = append(a, "-c", o.Container)
+	// kubectl fails to allocate a TTY when the input is not a terminal such as when piping commands into the shell
+	a := []string{"exec", "-i"}
+	if isTerminal(os.Stdin) {
+		a = append(a, "-t")
+	}
+	a = append(a, "-n", ns)
+	if container != "" {
+		a = append(a, "-c", container)
 	}
 	a = append(a, name)
 	if len(args) > 1 {
@@ -226,6 +234,108 @@ func (o *RshOptions) Run() error {
 	return o.RunCommandInteractive(true, "kubectl", a...)
 }
 
+// pickBuildPod returns the running pod of the latest build of the pipeline given as the first argument or picked from
+// the pipelines with running builds
+func (o *RshOptions) pickBuildPod(client kubernetes.Interface, ns string, args []string) (*corev1.Pod, error) {
+	filter := ""
+	if len(args) > 0 {
+		filter = args[0]
+	}
+	if filter == "" {
+		if o.BatchMode {
+			return nil, util.MissingArgument("pipeline")
+		}
+		buildPods, err := BuildPods(client, ns, "")
+		if err != nil {
+			return nil, err
+		}
+		pipelines := []string{}
+		for _, p := range buildPods {
+			pipeline := BuildPipeline(p)
+			if p.Status.Phase == corev1.PodRunning && util.StringArrayIndex(pipelines, pipeline) < 0 {
+				pipelines = append(pipelines, pipeline)
+			}
+		}
+		if len(pipelines) == 0 {
+			return nil, fmt.Errorf("There are no running builds in namespace %s", ns)
+		}
+		filter, err = util.PickName(pipelines, "Pick Pipeline:", "", o.GetIOFileHandles())
+		if err != nil {
+			return nil, err
+		}
+	}
+	pod, err := LatestBuildPod(client, ns, filter)
+	if err != nil {
+		return nil, err
+	}
+	log.Logger().Infof("Using pod %s of build #%s of pipeline %s", util.ColorInfo(pod.Name), util.ColorInfo(pod.Labels[v1.LabelBuild]), util.ColorInfo(BuildPipeline(pod)))
+	return pod, nil
+}
+
+// pickPod returns the name of the pod given by the --pod flag, the first argument or picked from the pods which
+// contain the first argument in their names
+func (o *RshOptions) pickPod(client kubernetes.Interface, ns string, args []string) (string, map[string]*corev1.Pod, error) {
+	var err error
+	filter := ""
+	names := []string{}
+	podsName := "Pods"
+	pods := map[string]*corev1.Pod{}
+	if o.DevPod {
+		podsName = "DevPods"
+		userName, err := o.GetUsername(o.Username)
+		if err != nil {
+			return "", nil, err
+		}
+		names, pods, err = kube.GetDevPodNames(client, ns, userName)
+		if err != nil {
+			return "", nil, err
+		}
+	} else {
+		names, err = kube.GetPodNames(client, ns, "")
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	if len(names) == 0 {
+		if filter == "" {
+			return "", nil, fmt.Errorf("There are no %s", podsName)
+		} else {
+			return "", nil, fmt.Errorf("There are no %s matching filter: %s", podsName, filter)
+		}
+	}
+	name := o.Pod
+	if len(args) == 0 {
+		if util.StringArrayIndex(names, name) < 0 {
+			n, err := util.PickName(names, "Pick Pod:", "", o.GetIOFileHandles())
+			if err != nil {
+				return "", nil, err
+			}
+			name = n
+		}
+	} else {
+		name = args[0]
+		if util.StringArrayIndex(names, name) < 0 {
+			// lets try use the name as a filter
+			filteredNames := []string{}
+			for _, n := range names {
+				if strings.Contains(n, name) {
+					filteredNames = append(filteredNames, n)
+				}
+			}
+			n, err := util.PickName(filteredNames, "Pick Pod:", "", o.GetIOFileHandles())
+			if err != nil {
+				return "", nil, err
+			}
+			name = n
+		}
+	}
+
+	if name == "" {
+		return "", nil, fmt.Errorf("No pod found for namespace %s with name %s", ns, name)
+	}
+	return name, pods, nil
+}
+
 func (o *RshOptions) detectBash(ns string, podName string, container string) (string, error) {
 	fileName := "/tmp/pod_" + podName + "_shells"
 	args := []string{"cp", ns + "/" + podName + ":" + ShellsFile, fileName}
@@ -251,3 +361,8 @@ func (o *RshOptions) detectBash(ns string, podName string, container string) (st
 	}
 	return "", fmt.Errorf("no bash found in POD '%s'", podName)
 }
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
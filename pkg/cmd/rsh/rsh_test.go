@@ -0,0 +1,156 @@
+// +build unit
+
+package rsh_test
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/rsh"
+	"github.com/jenkins-x/jx/v2/pkg/tekton"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func buildPod(name string, repo string, branch string, build string, phase corev1.PodPhase) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "jx",
+			Labels: map[string]string{
+				v1.LabelOwner:      "myorg",
+				v1.LabelRepository: repo,
+				v1.LabelBranch:     branch,
+				v1.LabelBuild:      build,
+				tekton.LabelType:   tekton.BuildPipeline.String(),
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "step-git-source"}, {Name: "step-build"}, {Name: "step-promote"}},
+		},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+}
+
+func appPod(name string, created time.Time, ready bool) *corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "jx-staging",
+			Labels:            map[string]string{"app": "jx-myapp"},
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "myapp"}}},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+		},
+	}
+}
+
+func TestLatestBuildPod(t *testing.T) {
+	t.Parallel()
+
+	meta := buildPod("myapp-master-3-meta", "myapp", "master", "3", corev1.PodRunning)
+	meta.Labels[tekton.LabelType] = tekton.MetaPipeline.String()
+	kubeClient := fake.NewSimpleClientset(
+		buildPod("myapp-master-1", "myapp", "master", "1", corev1.PodSucceeded),
+		buildPod("myapp-master-2", "myapp", "master", "2", corev1.PodRunning),
+		meta,
+		buildPod("myapp-pr-5-1", "myapp", "PR-5", "1", corev1.PodFailed),
+		buildPod("other-master-7", "other", "master", "7", corev1.PodRunning),
+	)
+
+	pods, err := rsh.BuildPods(kubeClient, "jx", "myorg/myapp")
+	require.NoError(t, err)
+	names := []string{}
+	for _, p := range pods {
+		names = append(names, p.Name)
+	}
+	assert.Equal(t, []string{"myapp-master-2", "myapp-master-1", "myapp-pr-5-1"}, names, "meta pipeline pods are ignored")
+
+	pod, err := rsh.LatestBuildPod(kubeClient, "jx", "myorg/MyApp/master")
+	require.NoError(t, err)
+	assert.Equal(t, "myapp-master-2", pod.Name)
+	assert.Equal(t, "myorg/myapp/master", rsh.BuildPipeline(pod))
+
+	pod, err = rsh.LatestBuildPod(kubeClient, "jx", "other")
+	require.NoError(t, err)
+	assert.Equal(t, "other-master-7", pod.Name)
+
+	_, err = rsh.LatestBuildPod(kubeClient, "jx", "myorg/myapp/pr-5")
+	assert.EqualError(t, err, "the latest build #1 of pipeline myorg/myapp/PR-5 is no longer running so its containers cannot be executed in")
+
+	_, err = rsh.LatestBuildPod(kubeClient, "jx", "missing")
+	assert.Error(t, err)
+}
+
+func TestAppPod(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	kubeClient := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "jx-myapp", Namespace: "jx-staging"},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "jx-myapp"}},
+			},
+		},
+		appPod("jx-myapp-old", now.Add(-time.Hour), true),
+		appPod("jx-myapp-new", now, true),
+		appPod("jx-myapp-starting", now.Add(time.Minute), false),
+	)
+
+	pod, err := rsh.AppPod(kubeClient, "jx-staging", "myapp")
+	require.NoError(t, err)
+	require.NotNil(t, pod)
+	assert.Equal(t, "jx-myapp-new", pod.Name)
+
+	pod, err = rsh.AppPod(kubeClient, "jx-staging", "jx-myapp")
+	require.NoError(t, err)
+	require.NotNil(t, pod)
+	assert.Equal(t, "jx-myapp-new", pod.Name)
+
+	pod, err = rsh.AppPod(kubeClient, "jx-staging", "jx-myapp-old")
+	require.NoError(t, err)
+	assert.Nil(t, pod, "names which are not apps are used to filter the pods")
+}
+
+func TestContainerName(t *testing.T) {
+	t.Parallel()
+
+	pod := buildPod("myapp-master-2", "myapp", "master", "2", corev1.PodRunning)
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+		{Name: "step-git-source", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}}},
+		{Name: "step-promote", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+		{Name: "step-build", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+	}
+
+	name, err := rsh.ContainerName(pod, "")
+	require.NoError(t, err)
+	assert.Equal(t, "step-build", name, "the first running step is used")
+
+	name, err = rsh.ContainerName(pod, "promote")
+	require.NoError(t, err)
+	assert.Equal(t, "step-promote", name)
+
+	name, err = rsh.ContainerName(pod, "step-git-source")
+	require.NoError(t, err)
+	assert.Equal(t, "step-git-source", name)
+
+	_, err = rsh.ContainerName(pod, "deploy")
+	assert.EqualError(t, err, "pod myapp-master-2 has no container deploy, the containers are: step-git-source, step-build, step-promote")
+
+	name, err = rsh.ContainerName(appPod("jx-myapp", time.Now(), true), "")
+	require.NoError(t, err)
+	assert.Equal(t, "myapp", name)
+}
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	// DefaultDevContainerImage the image containing jx and pinned versions of helm, kubectl and git
+	DefaultDevContainerImage = "gcr.io/jenkinsxio/builder-jx"
+
+	containerHome      = "/home/jenkins"
+	containerWorkspace = "/workspace"
+	dockerHostAlias    = "host.docker.internal"
+)
+
+var (
+	// devContainerCredentialDirs the directories relative to the home dir which are mounted into the container if they exist
+	devContainerCredentialDirs = []string{".jx", ".gitconfig", ".git-credentials", ".config/gcloud", ".aws", ".azure", ".ssh"}
+
+	// devContainerEnvVars the environment variables passed through to the container if they are set
+	devContainerEnvVars = []string{"JX_LOG_LEVEL", "JX_BATCH_MODE", "GIT_TOKEN", "GITHUB_TOKEN", "AWS_PROFILE", "AWS_REGION",
+		"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "GOOGLE_APPLICATION_CREDENTIALS", "CLOUDSDK_CORE_PROJECT"}
+
+	runInContainerLong = templates.LongDesc(`
+		Runs a jx command inside a container image which contains known good versions of jx, helm, kubectl and git.
+
+		The current directory, your kube config and cloud credentials are mounted into the container so that the command behaves as if it was run locally.
+		This avoids problems caused by different teams using different versions of the binaries the CLI depends on.
+
+		If the image is not specified the builder-jx image is resolved from the version stream.
+`)
+
+	runInContainerExample = templates.Examples(`
+		# run 'jx get env' inside the container
+		jx run-in-container -- get env
+
+		# run the command with a specific image
+		jx run-in-container --image gcr.io/jenkinsxio/builder-jx:2.0.1 -- boot
+	`)
+)
+
+// RunInContainerOptions the options for the run-in-container command
+type RunInContainerOptions struct {
+	*opts.CommonOptions
+
+	Image      string
+	Docker     string
+	Pull       bool
+	DryRun     bool
+	KubeConfig string
+}
+
+// NewCmdRunInContainer creates the command
+func NewCmdRunInContainer(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &RunInContainerOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "run-in-container [flags] -- [jx args]",
+		Short:   "Runs a jx command inside a container with pinned versions of jx, helm, kubectl and git",
+		Long:    runInContainerLong,
+		Example: runInContainerExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Image, "image", "i", "", "The container image to use. Defaults to the builder-jx image version in the version stream")
+	cmd.Flags().StringVarP(&options.Docker, "docker", "", "docker", "The docker compatible binary used to run the container")
+	cmd.Flags().BoolVarP(&options.Pull, "pull", "", false, "Always pull the image before running it")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "Prints the docker command rather than running it")
+	cmd.Flags().StringVarP(&options.KubeConfig, "kubeconfig", "", "", "The kube config file to mount. Defaults to $KUBECONFIG or ~/.kube/config")
+	return cmd
+}
+
+// Run implements the command
+func (o *RunInContainerOptions) Run() error {
+	image := o.Image
+	if image == "" {
+		resolver, err := o.GetVersionResolver()
+		if err != nil {
+			return errors.Wrap(err, "creating the version resolver")
+		}
+		image, err = resolver.ResolveDockerImage(DefaultDevContainerImage)
+		if err != nil {
+			return errors.Wrapf(err, "resolving the version of image %s", DefaultDevContainerImage)
+		}
+	}
+	kubeConfig := o.KubeConfig
+	if kubeConfig == "" {
+		kubeConfig = util.KubeConfigFile()
+	}
+	if strings.Contains(kubeConfig, string(os.PathListSeparator)) {
+		return fmt.Errorf("multiple kube config files are not supported: %s. Please specify one with --kubeconfig", kubeConfig)
+	}
+	exists, err := util.FileExists(kubeConfig)
+	if err != nil {
+		return err
+	}
+	if exists && runtime.GOOS != "linux" {
+		// the container runs in a VM so servers on localhost must be reached via the docker host alias
+		kubeConfig, err = rewriteKubeConfigForDockerHost(kubeConfig)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(kubeConfig) //nolint:errcheck
+	}
+	if !exists {
+		kubeConfig = ""
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	env := map[string]string{}
+	for _, name := range devContainerEnvVars {
+		if value := os.Getenv(name); value != "" {
+			env[name] = value
+		}
+	}
+	tty := false
+	if f, ok := o.In.(*os.File); ok {
+		tty = isTerminal(f)
+	}
+	args := DevContainerDockerArgs(runtime.GOOS, util.HomeDir(), dir, kubeConfig, image, util.SortedMapKeys(env), tty, o.Args)
+	if o.Pull {
+		args = append([]string{"run", "--pull", "always"}, args[1:]...)
+	}
+	if o.DryRun {
+		_, err = fmt.Fprintf(o.Out, "%s %s\n", o.Docker, strings.Join(args, " "))
+		return err
+	}
+	log.Logger().Debugf("running %s %s", o.Docker, strings.Join(args, " "))
+	e := exec.Command(o.Docker, args...) // #nosec
+	// the values of the environment variables are only passed via the environment of docker so that secrets do not
+	// show up in the arguments of the process
+	e.Env = os.Environ()
+	for _, name := range util.SortedMapKeys(env) {
+		e.Env = append(e.Env, name+"="+env[name])
+	}
+	e.Stdin = o.In
+	e.Stdout = o.Out
+	e.Stderr = o.Err
+	return e.Run()
+}
+
+// DevContainerDockerArgs returns the docker arguments to run jx inside the given image with the kube config,
+// credentials and current directory mounted. The environment variables are passed by name only so that their values,
+// which may be secrets, are taken from the environment of docker rather than appearing in its arguments. A TTY is only
+// allocated if tty is true as docker fails to allocate one when the input is not a terminal
+func DevContainerDockerArgs(goos string, home string, dir string, kubeConfig string, image string, envNames []string, tty bool, jxArgs []string) []string {
+	args := []string{"run", "--rm", "-i"}
+	if tty {
+		args = append(args, "-t")
+	}
+	if goos == "linux" {
+		// lets reuse the host network so kube API servers on localhost are reachable and run as the current user
+		// so files created in the workspace are owned by them
+		args = append(args, "--network", "host", "--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()))
+	}
+	args = append(args, "-e", "HOME="+containerHome)
+	for _, rel := range devContainerCredentialDirs {
+		path := filepath.Join(home, rel)
+		if _, err := os.Stat(path); err == nil {
+			args = append(args, "-v", path+":"+containerHome+"/"+filepath.ToSlash(rel))
+		}
+	}
+	if kubeConfig != "" {
+		args = append(args, "-v", kubeConfig+":"+containerHome+"/.kube/config", "-e", "KUBECONFIG="+containerHome+"/.kube/config")
+	}
+	for _, name := range envNames {
+		args = append(args, "-e", name)
+	}
+	args = append(args, "-v", dir+":"+containerWorkspace, "-w", containerWorkspace, "--entrypoint", "jx", image)
+	return append(args, jxArgs...)
+}
+
+// rewriteKubeConfigForDockerHost writes a copy of the kube config with any servers on localhost replaced by the
+// docker host alias returning the temporary file name
+func rewriteKubeConfigForDockerHost(fileName string) (string, error) {
+	config, err := clientcmd.LoadFromFile(fileName)
+	if err != nil {
+		return "", errors.Wrapf(err, "loading kube config %s", fileName)
+	}
+	for _, cluster := range config.Clusters {
+		u, err := url.Parse(cluster.Server)
+		if err != nil {
+			continue
+		}
+		host := u.Hostname()
+		if host == "localhost" || host == "127.0.0.1" {
+			u.Host = strings.Replace(u.Host, host, dockerHostAlias, 1)
+			cluster.Server = u.String()
+			log.Logger().Debugf("using server %s inside the container instead of %s", cluster.Server, host)
+		}
+	}
+	f, err := ioutil.TempFile("", "jx-kubeconfig-")
+	if err != nil {
+		return "", err
+	}
+	f.Close()
+	err = clientcmd.WriteToFile(*config, f.Name())
+	if err != nil {
+		return "", errors.Wrapf(err, "writing kube config %s", f.Name())
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,45 @@
+// +build unit
+
+package cmd_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDevContainerDockerArgs(t *testing.T) {
+	t.Parallel()
+
+	home, err := ioutil.TempDir("", "test-run-in-container")
+	require.NoError(t, err)
+	defer os.RemoveAll(home)
+
+	err = os.MkdirAll(filepath.Join(home, ".aws"), 0700)
+	require.NoError(t, err)
+
+	env := []string{"AWS_PROFILE", "GIT_TOKEN"}
+	args := cmd.DevContainerDockerArgs("darwin", home, "/src/myapp", "/tmp/kubeconfig", "gcr.io/jenkinsxio/builder-jx:1.2.3", env, true, []string{"get", "env"})
+
+	assert.Equal(t, []string{
+		"run", "--rm", "-i", "-t",
+		"-e", "HOME=/home/jenkins",
+		"-v", filepath.Join(home, ".aws") + ":/home/jenkins/.aws",
+		"-v", "/tmp/kubeconfig:/home/jenkins/.kube/config", "-e", "KUBECONFIG=/home/jenkins/.kube/config",
+		"-e", "AWS_PROFILE",
+		"-e", "GIT_TOKEN",
+		"-v", "/src/myapp:/workspace", "-w", "/workspace",
+		"--entrypoint", "jx", "gcr.io/jenkinsxio/builder-jx:1.2.3",
+		"get", "env",
+	}, args)
+
+	linuxArgs := cmd.DevContainerDockerArgs("linux", home, "/src/myapp", "", "image", nil, false, nil)
+	assert.Contains(t, linuxArgs, "--network")
+	assert.NotContains(t, linuxArgs, "-t", "a TTY should only be allocated when the input is a terminal")
+	assert.NotContains(t, linuxArgs, "KUBECONFIG=/home/jenkins/.kube/config")
+}
@@ -211,7 +211,7 @@ func (o *ShellOptions) PickContext(names []string, defaultValue string) (string,
 		Options: names,
 		Default: defaultValue,
 	}
-	err := survey.AskOne(prompt, &name, nil, surveyOpts)
+	err := util.AskOne(prompt, &name, nil, surveyOpts)
 	return name, err
 }
 
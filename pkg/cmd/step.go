@@ -6,6 +6,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	step2 "github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/step/artifact"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/bdd"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/boot"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/buildpack"
@@ -21,10 +22,13 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/pr"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/report"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/restore"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/step/scan"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/scheduler"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/step/sign"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/syntax"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/update"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/verify"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/step/versionstream"
 	"github.com/spf13/cobra"
 )
 
@@ -46,6 +50,7 @@ func NewCmdStep(commonOpts *opts.CommonOptions) *cobra.Command {
 		},
 	}
 
+	cmd.AddCommand(artifact.NewCmdStepArtifact(commonOpts))
 	cmd.AddCommand(boot.NewCmdStepBoot(commonOpts))
 	cmd.AddCommand(buildpack.NewCmdStepBuildPack(commonOpts))
 	cmd.AddCommand(bdd.NewCmdStepBDD(commonOpts))
@@ -69,6 +74,9 @@ func NewCmdStep(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(post.NewCmdStepPost(commonOpts))
 	cmd.AddCommand(step.NewCmdStepRelease(commonOpts))
 	cmd.AddCommand(step.NewCmdStepReplicate(commonOpts))
+	cmd.AddCommand(step.NewCmdStepSbom(commonOpts))
+	cmd.AddCommand(scan.NewCmdStepScan(commonOpts))
+	cmd.AddCommand(sign.NewCmdStepSign(commonOpts))
 	cmd.AddCommand(step.NewCmdStepSplitMonorepo(commonOpts))
 	cmd.AddCommand(syntax.NewCmdStepSyntax(commonOpts))
 	cmd.AddCommand(step.NewCmdStepTag(commonOpts))
@@ -85,6 +93,7 @@ func NewCmdStep(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(report.NewCmdStepReport(commonOpts))
 	cmd.AddCommand(step.NewCmdStepOverrideRequirements(commonOpts))
 	cmd.AddCommand(restore.NewCmdStepRestore(commonOpts))
+	cmd.AddCommand(versionstream.NewCmdStepVersionStream(commonOpts))
 
 	return cmd
 }
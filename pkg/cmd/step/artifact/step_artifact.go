@@ -0,0 +1,95 @@
+package artifact
+
+import (
+	"os"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/artifactrepository"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// envUsername the environment variable of the user of the artifact repository
+	envUsername = "ARTIFACT_REPOSITORY_CREDS_USR"
+	// envPassword the environment variable of the password of the artifact repository
+	envPassword = "ARTIFACT_REPOSITORY_CREDS_PSW"
+)
+
+// StepArtifactOptions contains the command line flags
+type StepArtifactOptions struct {
+	step.StepOptions
+}
+
+// RepositoryFlags the flags selecting the artifact repository and its credentials
+type RepositoryFlags struct {
+	Kind     string
+	URL      string
+	Username string
+	Password string
+}
+
+// NewCmdStepArtifact creates the command
+func NewCmdStepArtifact(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepArtifactOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:   "artifact",
+		Short: "artifact [command]",
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.AddCommand(NewCmdStepArtifactPromote(commonOpts))
+	cmd.AddCommand(NewCmdStepArtifactSettings(commonOpts))
+	return cmd
+}
+
+// Run implements this command
+func (o *StepArtifactOptions) Run() error {
+	return o.Cmd.Help()
+}
+
+func (f *RepositoryFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&f.Kind, "kind", "k", "", "The kind of the artifact repository: nexus or artifactory. Defaults to the repository of the requirements in the team settings")
+	cmd.Flags().StringVarP(&f.URL, "url", "u", "", "The URL of the artifact repository. Defaults to the repository URL of the requirements in the team settings or the Nexus service")
+	cmd.Flags().StringVarP(&f.Username, "username", "", os.Getenv(envUsername), "The user of the artifact repository. Defaults to $"+envUsername)
+	cmd.Flags().StringVarP(&f.Password, "password", "", os.Getenv(envPassword), "The password or API token of the user. Defaults to $"+envPassword)
+}
+
+// artifactRepository returns the artifact repository of the flags falling back to the repository selected by the
+// requirements in the team settings
+func (f *RepositoryFlags) artifactRepository(o *opts.CommonOptions) (artifactrepository.ArtifactRepository, error) {
+	options := artifactrepository.Options{
+		URL:      f.URL,
+		Username: f.Username,
+		Password: f.Password,
+	}
+	var repository artifactrepository.ArtifactRepository
+	var err error
+	if f.Kind != "" {
+		repository, err = artifactrepository.NewArtifactRepository(config.RepositoryType(f.Kind), options)
+	} else {
+		settings, settingsErr := o.TeamSettings()
+		if settingsErr != nil {
+			return nil, errors.Wrap(settingsErr, "reading the team settings")
+		}
+		repository, err = artifactrepository.FromTeamSettings(settings, options)
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.Logger().Debugf("Using the %s artifact repository %s", repository.Kind(), util.ColorInfo(repository.URL()))
+	return repository, nil
+}
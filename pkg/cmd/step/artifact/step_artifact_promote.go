@@ -0,0 +1,89 @@
+package artifact
+
+import (
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// StepArtifactPromoteOptions contains the command line flags
+type StepArtifactPromoteOptions struct {
+	step.StepOptions
+	RepositoryFlags
+
+	GroupID    string
+	ArtifactID string
+	Version    string
+}
+
+var (
+	stepArtifactPromoteLong = templates.LongDesc(`
+		Promotes a staged release of a maven artifact to the release repository of the artifact repository.
+
+		Nexus moves the components of the release from the maven-staging repository to the maven-releases repository.
+		Artifactory moves the release from the libs-staging-local repository to the libs-release-local repository.
+
+		The artifact repository defaults to the repository of the requirements in the team settings.
+`)
+
+	stepArtifactPromoteExample = templates.Examples(`
+		# promotes the staged release of the application
+		jx step artifact promote -g io.jenkins-x -a myapp -v $VERSION
+
+		# promotes the staged release in Artifactory
+		jx step artifact promote --kind artifactory --url https://acme.jfrog.io/artifactory -g io.jenkins-x -a myapp -v 1.2.3
+`)
+)
+
+// NewCmdStepArtifactPromote creates the command
+func NewCmdStepArtifactPromote(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepArtifactPromoteOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "promote",
+		Short:   "Promotes a staged release of a maven artifact to the release repository",
+		Long:    stepArtifactPromoteLong,
+		Example: stepArtifactPromoteExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	options.RepositoryFlags.addFlags(cmd)
+	cmd.Flags().StringVarP(&options.GroupID, "group", "g", "", "The group ID of the artifact")
+	cmd.Flags().StringVarP(&options.ArtifactID, "artifact", "a", "", "The artifact ID of the artifact")
+	cmd.Flags().StringVarP(&options.Version, "version", "v", "", "The version of the release")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepArtifactPromoteOptions) Run() error {
+	if o.GroupID == "" {
+		return util.MissingOption("group")
+	}
+	if o.ArtifactID == "" {
+		return util.MissingOption("artifact")
+	}
+	if o.Version == "" {
+		return util.MissingOption("version")
+	}
+	repository, err := o.artifactRepository(o.CommonOptions)
+	if err != nil {
+		return err
+	}
+	err = repository.PromoteRelease(o.GroupID, o.ArtifactID, o.Version)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Promoted %s to the release repository of %s", util.ColorInfo(o.GroupID+":"+o.ArtifactID+":"+o.Version), util.ColorInfo(repository.URL()))
+	return nil
+}
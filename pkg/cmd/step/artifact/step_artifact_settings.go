@@ -0,0 +1,109 @@
+package artifact
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/artifactrepository"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	mavenSettingsFile = "settings.xml"
+	npmrcFile         = ".npmrc"
+)
+
+// StepArtifactSettingsOptions contains the command line flags
+type StepArtifactSettingsOptions struct {
+	step.StepOptions
+	RepositoryFlags
+
+	MavenDir string
+	NpmDir   string
+}
+
+var (
+	stepArtifactSettingsLong = templates.LongDesc(`
+		Generates the maven settings.xml and the npm .npmrc which resolve the maven artifacts and the npm packages
+		through the artifact repository and deploy the maven artifacts to it.
+
+		The artifact repository defaults to the repository of the requirements in the team settings so that
+		teams using Artifactory do not need to run Nexus as well.
+`)
+
+	stepArtifactSettingsExample = templates.Examples(`
+		# generates the maven and npm settings of the artifact repository of the team
+		jx step artifact settings
+
+		# generates the maven settings of Artifactory in the given directory
+		jx step artifact settings --kind artifactory --url https://acme.jfrog.io/artifactory --maven-dir /root/.m2 --npm-dir ""
+`)
+)
+
+// NewCmdStepArtifactSettings creates the command
+func NewCmdStepArtifactSettings(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepArtifactSettingsOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "settings",
+		Short:   "Generates the maven and npm settings of the artifact repository",
+		Long:    stepArtifactSettingsLong,
+		Example: stepArtifactSettingsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	options.RepositoryFlags.addFlags(cmd)
+	cmd.Flags().StringVarP(&options.MavenDir, "maven-dir", "", filepath.Join(util.HomeDir(), ".m2"), "The directory of the generated maven settings.xml. The maven settings are not generated if it is empty")
+	cmd.Flags().StringVarP(&options.NpmDir, "npm-dir", "", util.HomeDir(), "The directory of the generated .npmrc. The npm settings are not generated if it is empty")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepArtifactSettingsOptions) Run() error {
+	repository, err := o.artifactRepository(o.CommonOptions)
+	if err != nil {
+		return err
+	}
+	if o.MavenDir != "" {
+		err = writeSettings(o.MavenDir, mavenSettingsFile, repository.MavenSettingsXML(o.Username, o.Password))
+		if err != nil {
+			return err
+		}
+	}
+	if o.NpmDir != "" {
+		err = writeSettings(o.NpmDir, npmrcFile, artifactrepository.NpmRC(repository, o.Username, o.Password))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeSettings(dir string, name string, settings string) error {
+	err := os.MkdirAll(dir, util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "creating directory %s", dir)
+	}
+	fileName := filepath.Join(dir, name)
+	err = ioutil.WriteFile(fileName, []byte(settings), 0600)
+	if err != nil {
+		return errors.Wrapf(err, "writing %s", fileName)
+	}
+	log.Logger().Infof("Generated %s", util.ColorInfo(fileName))
+	return nil
+}
@@ -0,0 +1,60 @@
+// +build unit
+
+package artifact_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/step/artifact"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepArtifactSettingsArtifactory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-artifact-settings-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	o := &artifact.StepArtifactSettingsOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: &opts.CommonOptions{},
+		},
+		RepositoryFlags: artifact.RepositoryFlags{
+			Kind:     "artifactory",
+			URL:      "https://acme.jfrog.io/artifactory",
+			Username: "deployer",
+			Password: "token",
+		},
+		MavenDir: filepath.Join(dir, ".m2"),
+		NpmDir:   dir,
+	}
+	err = o.Run()
+	require.NoError(t, err)
+
+	settings, err := ioutil.ReadFile(filepath.Join(dir, ".m2", "settings.xml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(settings), "<url>https://acme.jfrog.io/artifactory/libs-release/</url>")
+	assert.Contains(t, string(settings), "<username>deployer</username>")
+	assert.NotContains(t, string(settings), "nexus")
+
+	npmrc, err := ioutil.ReadFile(filepath.Join(dir, ".npmrc"))
+	require.NoError(t, err)
+	assert.Contains(t, string(npmrc), "registry=https://acme.jfrog.io/artifactory/api/npm/npm/\n")
+}
+
+func TestStepArtifactPromoteRequiresVersion(t *testing.T) {
+	o := &artifact.StepArtifactPromoteOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: &opts.CommonOptions{},
+		},
+		GroupID:    "io.jenkins-x",
+		ArtifactID: "myapp",
+	}
+	err := o.Run()
+	assert.Error(t, err)
+}
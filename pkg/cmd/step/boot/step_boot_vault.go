@@ -495,7 +495,8 @@ func (o *StepBootVaultOptions) verifyVaultIngress(requirements *config.Requireme
 		return true, errors.Wrapf(err, "failed to save vault ingress YAML file %s", tmpFileName)
 	}
 
-	args := []string{"apply", "--force", "-f", tmpFileName, "-n", ns}
+	args := append([]string{"apply"}, kube.ServerSideApplyArgs()...)
+	args = append(args, "-f", tmpFileName, "-n", ns)
 	err = o.RunCommand("kubectl", args...)
 	if err != nil {
 		return true, errors.Wrapf(err, "failed to apply vault ingress YAML")
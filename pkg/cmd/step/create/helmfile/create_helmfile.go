@@ -2,16 +2,16 @@ package helmfile
 
 import (
 	"fmt"
-	"io/ioutil"
 	"net/url"
 	"os"
 	"path"
+	"strings"
 
 	"github.com/jenkins-x/jx/v2/pkg/config"
 	helmfile2 "github.com/jenkins-x/jx/v2/pkg/helmfile"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	"github.com/google/uuid"
+	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 
 	"github.com/ghodss/yaml"
@@ -123,6 +123,7 @@ func (o *CreateHelmfileOptions) generateHelmFile(applications []config.Applicati
 	// contains the repo url and name to reference it by in the release spec
 	// use a map to dedupe repositories
 	repos := make(map[string]string)
+	localRepoNames := util.SortedMapKeys(localHelmRepos)
 	for _, app := range applications {
 		_, err = url.ParseRequestURI(app.Repository)
 		if err != nil {
@@ -131,20 +132,23 @@ func (o *CreateHelmfileOptions) generateHelmFile(applications []config.Applicati
 		} else {
 			matched := false
 			// check if URL matches a repo in helms local list
-			for key, value := range localHelmRepos {
-				if app.Repository == value {
+			for _, key := range localRepoNames {
+				if app.Repository == localHelmRepos[key] {
 					repos[app.Repository] = key
 					matched = true
+					break
 				}
 			}
 			if !matched {
-				repos[app.Repository] = uuid.New().String()
+				repos[app.Repository] = repositoryNameFromURL(app.Repository)
 			}
 		}
 	}
 	var repositories []helmfile2.RepositorySpec
 	var releases []helmfile2.ReleaseSpec
-	for repoURL, name := range repos {
+	// iterate in a stable order so that regenerating the helmfile does not reorder the repositories
+	for _, repoURL := range util.SortedMapKeys(repos) {
+		name := repos[repoURL]
 		_, err = url.ParseRequestURI(repoURL)
 		// skip non URLs as they're probably local directories which don't need to be in the helmfile.repository section
 		if err == nil {
@@ -208,6 +212,15 @@ func (o *CreateHelmfileOptions) generateHelmFile(applications []config.Applicati
 	return nil
 }
 
+// repositoryNameFromURL returns a stable repository name for a chart repository URL which is not known to helm
+func repositoryNameFromURL(repoURL string) string {
+	u, err := url.Parse(repoURL)
+	if err != nil || u.Host == "" {
+		return naming.ToValidName(repoURL)
+	}
+	return naming.ToValidName(strings.TrimSuffix(u.Host+u.Path, "/"))
+}
+
 func (o *CreateHelmfileOptions) writeHelmfile(err error, phase string, data []byte) error {
 	exists, err := util.DirExists(path.Join(o.outputDir, phase))
 	if err != nil || !exists {
@@ -216,7 +229,7 @@ func (o *CreateHelmfileOptions) writeHelmfile(err error, phase string, data []by
 			return errors.Wrapf(err, "cannot create phase directory %s ", path.Join(o.outputDir, phase))
 		}
 	}
-	err = ioutil.WriteFile(path.Join(o.outputDir, phase, helmfile), data, util.DefaultWritePermissions)
+	err = util.WriteCanonicalYAMLFile(path.Join(o.outputDir, phase, helmfile), data, util.DefaultWritePermissions)
 	if err != nil {
 		return errors.Wrapf(err, "failed to save file %s", helmfile)
 	}
@@ -312,6 +325,5 @@ func (o *CreateHelmfileOptions) writeGeneratedNamespaceValues(namespace, phase s
 	if err != nil {
 		return err
 	}
-	err = ioutil.WriteFile(path.Join(o.outputDir, phase, "generated", namespace, "values.yaml"), data, util.DefaultWritePermissions)
-	return nil
+	return util.WriteCanonicalYAMLFile(path.Join(o.outputDir, phase, "generated", namespace, "values.yaml"), data, util.DefaultWritePermissions)
 }
@@ -32,6 +32,9 @@ type StepCreatePrOptions struct {
 	SkipCommit    bool
 	SkipAutoMerge bool
 	Labels        []string
+	RequireSigned bool
+
+	listRemoteTags func(gitURL string) ([]string, error)
 }
 
 // NewCmdStepCreatePr Steps a command object for the "step" command
@@ -79,10 +82,11 @@ func AddStepCreatePrFlags(cmd *cobra.Command, o *StepCreatePrOptions) {
 	cmd.Flags().StringVarP(&o.Base, "base", "", "master", "The branch to create the pull request into")
 	cmd.Flags().StringVarP(&o.SrcGitURL, "src-repo", "", "", "The git repo which caused this change; if this is a dependency update this will cause commit messages to be generated which can be parsed by jx step changelog. By default this will be read from the environment variable REPO_URL")
 	cmd.Flags().StringVarP(&o.Component, "component", "", "", "The component of the git repo which caused this change; useful if you have a complex or monorepo setup and want to differentiate between different components from the same repo")
-	cmd.Flags().StringVarP(&o.Version, "version", "v", "", "The version to change. If no version is supplied the latest version is found")
+	cmd.Flags().StringVarP(&o.Version, "version", "v", "", "The version to change. If no version is supplied the latest version is found from the git tags of the source repository")
 	cmd.Flags().BoolVarP(&o.DryRun, "dry-run", "", false, "Perform a dry run, the change will be generated and committed, but not pushed or have a PR created")
 	cmd.Flags().BoolVarP(&o.SkipAutoMerge, "skip-auto-merge", "", false, "Disable auto merge of the PR if status checks pass")
 	cmd.Flags().StringArrayVarP(&o.Labels, "labels", "", []string{}, "Labels to add to the created PR")
+	cmd.Flags().BoolVarP(&o.RequireSigned, "require-signed", "", false, "Fails rather than creating unsigned commits. Commits are signed with the key in the $"+gits.EnvGitSigningKey+" environment variable")
 }
 
 // ValidateOptions validates the common options for all PR creation steps
@@ -111,7 +115,12 @@ func (o *StepCreatePrOptions) ValidateOptions(allowEmptyVersion bool) error {
 		return errors.Errorf("unable to determine source url, no argument provided, env var REPO_URL is empty and working directory is not a git repo")
 	}
 	if !allowEmptyVersion && o.Version == "" {
-		return util.MissingOption("version")
+		version, err := o.LatestGitTagVersion(o.SrcGitURL)
+		if err != nil {
+			log.Logger().Warnf("Failed to find the latest version of %s: %s", o.SrcGitURL, err)
+			return util.MissingOption("version")
+		}
+		o.Version = version
 	}
 	if len(o.GitURLs) == 0 {
 		return util.MissingOption("repo")
@@ -119,14 +128,36 @@ func (o *StepCreatePrOptions) ValidateOptions(allowEmptyVersion bool) error {
 	return nil
 }
 
+// LatestGitTagVersion returns the latest release version of the semantic version tags of the git repository which
+// resolves the versions of dependency updates without an artifact repository such as Nexus
+func (o *StepCreatePrOptions) LatestGitTagVersion(gitURL string) (string, error) {
+	listRemoteTags := o.listRemoteTags
+	if listRemoteTags == nil {
+		listRemoteTags = gits.ListRemoteTags
+	}
+	tags, err := listRemoteTags(gitURL)
+	if err != nil {
+		return "", err
+	}
+	version, err := util.LatestSemverVersion(tags)
+	if err != nil {
+		return "", errors.Wrapf(err, "finding the latest version of %s", gitURL)
+	}
+	log.Logger().Infof("Using the latest version %s of the git tags of %s", util.ColorInfo(version), util.ColorInfo(gitURL))
+	return version, nil
+}
+
 // CreatePullRequest will fork (if needed) and pull a git repo, then perform the update, and finally create or update a
 // PR for the change. Any open PR on the repo with the `updatebot` label will be updated.
 func (o *StepCreatePrOptions) CreatePullRequest(kind string, update operations.ChangeFilesFn) error {
 	if o.DryRun {
 		log.Logger().Infof("--dry-run specified. Change will be created and committed to local git repo, but not pushed. No pull request will be created or updated. A fork will still be created.")
 	}
+	err := o.ConfigureGitSigning(o.RequireSigned)
+	if err != nil {
+		return errors.Wrap(err, "configuring the signing of the commits")
+	}
 	op := o.createPullRequestOperation()
-	var err error
 	o.Results, err = op.CreatePullRequest(kind, update)
 	if err != nil {
 		return errors.Wrap(err, "unable to create pull request")
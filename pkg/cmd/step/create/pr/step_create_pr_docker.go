@@ -1,6 +1,8 @@
 package pr
 
 import (
+	"time"
+
 	"github.com/jenkins-x/jx-logging/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
 	"github.com/jenkins-x/jx/v2/pkg/docker"
@@ -18,6 +20,8 @@ import (
 var (
 	createPullRequestDockerLong = templates.LongDesc(`
 		Creates a Pull Request on a git repository updating any lines in the Dockerfile that start with FROM, ENV or ARG=
+
+		If no version is supplied the latest version is found from the tags of the image in its container registry.
 `)
 
 	createPullRequestDockerExample = templates.Examples(`
@@ -29,8 +33,13 @@ type StepCreatePullRequestDockersOptions struct {
 	StepCreatePrOptions
 
 	Names []string
+
+	listImageTags func(image string, username string, password string, timeout time.Duration) ([]string, error)
 }
 
+// registryTimeout the time to wait for the container registry to list the tags of an image
+const registryTimeout = time.Minute
+
 // NewCmdStepCreatePullRequestDocker Creates a new Command object
 func NewCmdStepCreatePullRequestDocker(commonOpts *opts.CommonOptions) *cobra.Command {
 	options := &StepCreatePullRequestDockersOptions{
@@ -63,6 +72,14 @@ func NewCmdStepCreatePullRequestDocker(commonOpts *opts.CommonOptions) *cobra.Co
 
 // ValidateDockersOptions validates the common options for docker pr steps
 func (o *StepCreatePullRequestDockersOptions) ValidateDockersOptions() error {
+	if o.Version == "" && len(o.Names) > 0 {
+		version, err := o.latestImageVersion(o.Names[0])
+		if err != nil {
+			log.Logger().Warnf("Failed to find the latest version of image %s: %s", o.Names[0], err)
+		} else {
+			o.Version = version
+		}
+	}
 	if err := o.ValidateOptions(false); err != nil {
 		return errors.WithStack(err)
 	}
@@ -98,3 +115,23 @@ func (o *StepCreatePullRequestDockersOptions) Run() error {
 	}
 	return nil
 }
+
+// latestImageVersion returns the latest release version of the semantic version tags of the image in its registry
+func (o *StepCreatePullRequestDockersOptions) latestImageVersion(image string) (string, error) {
+	listImageTags := o.listImageTags
+	if listImageTags == nil {
+		listImageTags = docker.ListTags
+	}
+	host, _ := docker.SplitImage(image)
+	username, password := docker.ConfigCredentials(host)
+	tags, err := listImageTags(image, username, password, registryTimeout)
+	if err != nil {
+		return "", err
+	}
+	version, err := util.LatestSemverVersion(tags)
+	if err != nil {
+		return "", errors.Wrapf(err, "finding the latest version of image %s", image)
+	}
+	log.Logger().Infof("Using the latest version %s of the tags of image %s", util.ColorInfo(version), util.ColorInfo(image))
+	return version, nil
+}
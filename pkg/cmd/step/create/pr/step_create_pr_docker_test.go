@@ -0,0 +1,47 @@
+// +build unit
+
+package pr
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateDockersOptionsResolvesTheLatestImageVersion(t *testing.T) {
+	o := &StepCreatePullRequestDockersOptions{
+		StepCreatePrOptions: StepCreatePrOptions{
+			GitURLs:   []string{"https://github.com/jenkins-x/jenkins-x-versions.git"},
+			SrcGitURL: "https://github.com/jenkins-x/jenkins-x-builders.git",
+		},
+		Names: []string{"gcr.io/jenkinsxio/builder-go"},
+		listImageTags: func(image string, username string, password string, timeout time.Duration) ([]string, error) {
+			assert.Equal(t, "gcr.io/jenkinsxio/builder-go", image)
+			return []string{"latest", "2.1.0", "2.1.10-rc1", "2.1.9"}, nil
+		},
+	}
+	require.NoError(t, o.ValidateDockersOptions())
+	assert.Equal(t, "2.1.9", o.Version)
+}
+
+func TestValidateOptionsResolvesTheLatestGitTagVersion(t *testing.T) {
+	o := &StepCreatePrOptions{
+		GitURLs:   []string{"https://github.com/jenkins-x/jenkins-x-versions.git"},
+		SrcGitURL: "https://github.com/jenkins-x/jx.git",
+		listRemoteTags: func(gitURL string) ([]string, error) {
+			assert.Equal(t, "https://github.com/jenkins-x/jx.git", gitURL)
+			return []string{"v2.0.1", "v2.1.0", "v2.0.10"}, nil
+		},
+	}
+	require.NoError(t, o.ValidateOptions(false))
+	assert.Equal(t, "2.1.0", o.Version)
+
+	o.Version = ""
+	o.listRemoteTags = func(gitURL string) ([]string, error) {
+		return nil, fmt.Errorf("repository not found")
+	}
+	assert.Error(t, o.ValidateOptions(false))
+}
@@ -22,6 +22,8 @@ import (
 	"github.com/ghodss/yaml"
 	jxclient "github.com/jenkins-x/jx-api/pkg/client/clientset/versioned"
 	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/buildpacks"
+	"github.com/jenkins-x/jx/v2/pkg/cloud/workloadidentity"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	syntaxstep "github.com/jenkins-x/jx/v2/pkg/cmd/step/syntax"
@@ -119,6 +121,7 @@ type StepCreateTaskOptions struct {
 	DockerRegistry      string
 	DockerRegistryOrg   string
 	KanikoFlags         string
+	BuildEngine         string
 	AdditionalEnvVars   map[string]string
 	PodTemplates        map[string]*corev1.Pod
 	UseBranchAsRevision bool
@@ -133,6 +136,12 @@ type StepCreateTaskOptions struct {
 	VersionResolver        *versionstream.VersionResolver
 	CloneDir               string
 	EffectiveProjectConfig *config.ProjectConfig
+	// Checkpoint records the steps which completed in interpret mode so that they are skipped when resuming
+	Checkpoint *opts.Checkpoint
+
+	teamBuildEngine  *syntax.BuildEngine
+	buildCache       *syntax.BuildCache
+	workloadIdentity *bool
 }
 
 // NewCmdStepCreateTask Creates a new Command object
@@ -221,6 +230,7 @@ func (o *StepCreateTaskOptions) AddCommonFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.DockerRegistry, "docker-registry", "", "", "The Docker Registry host name to use which is added as a prefix to docker images")
 	cmd.Flags().StringVarP(&o.DockerRegistryOrg, "docker-registry-org", "", "", "The Docker registry organisation. If blank the git repository owner is used")
 	cmd.Flags().StringVarP(&o.KanikoFlags, "kaniko-flags", "", "", "Optional flags to pass to kaniko builds; such as to indicate --insecure docker registry being used")
+	cmd.Flags().StringVarP(&o.BuildEngine, "build-engine", "", "", fmt.Sprintf("The engine building the images which overrides the build engine of the pipeline and team. Possible values: %s", strings.Join(syntax.BuildEngineKinds, ", ")))
 	cmd.Flags().DurationVarP(&o.Duration, "duration", "", time.Second*30, "Retry duration when trying to create a PipelineRun")
 }
 
@@ -482,6 +492,13 @@ func (o *StepCreateTaskOptions) createEffectiveProjectConfigFromOptions(tektonCl
 	if err != nil {
 		return nil, err
 	}
+	o.teamBuildEngine = syntaxstep.TeamBuildEngine(settings)
+	if !o.InterpretMode {
+		o.buildCache, err = tekton.LoadBuildCache(kubeClient, ns)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	if o.ProjectID == "" {
 		if !o.RemoteCluster {
@@ -537,6 +554,7 @@ func (o *StepCreateTaskOptions) createEffectiveProjectConfigFromOptions(tektonCl
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to load project config in dir %s", o.CloneDir)
 	}
+	pinnedRepository := o.BuildPackURL != "" || projectConfig.BuildPackGitURL != ""
 	if o.BuildPackURL == "" || o.BuildPackRef == "" {
 		if projectConfig.BuildPackGitURL != "" {
 			o.BuildPackURL = projectConfig.BuildPackGitURL
@@ -573,7 +591,14 @@ func (o *StepCreateTaskOptions) createEffectiveProjectConfigFromOptions(tektonCl
 		return nil, util.MissingOption("pack")
 	}
 
-	packsDir, err := gitresolver.InitBuildPack(o.Git(), o.BuildPackURL, o.BuildPackRef)
+	repositories := []buildpacks.Repository{{URL: o.BuildPackURL, Ref: o.BuildPackRef}}
+	if !pinnedRepository && !o.InterpretMode {
+		repositories, err = o.BuildPackRepositories(settings)
+		if err != nil {
+			return nil, err
+		}
+	}
+	packsDir, err := o.InitBuildPackOfPack(repositories, o.Pack)
 	if err != nil {
 		return nil, err
 	}
@@ -599,6 +624,9 @@ func (o *StepCreateTaskOptions) createEffectiveProjectConfig(packsDir string, pr
 		DefaultImage:      o.DefaultImage,
 		UseKaniko:         !o.NoKaniko,
 		KanikoImage:       o.KanikoImage,
+		BuildEngine:       o.BuildEngine,
+		TeamBuildEngine:   o.teamBuildEngine,
+		BuildCache:        o.buildCache,
 		ProjectID:         o.ProjectID,
 		DockerRegistry:    o.DockerRegistry,
 		DockerRegistryOrg: o.DockerRegistryOrg,
@@ -1040,7 +1068,7 @@ func (o *StepCreateTaskOptions) modifyEnvVars(container *corev1.Container, globa
 		}
 	}
 
-	if isKanikoExecutorStep(container) && !o.NoKaniko && kube.GetSliceEnvVar(envVars, "NO_GOOGLE_APPLICATION_CREDENTIALS") == nil {
+	if isKanikoExecutorStep(container) && !o.NoKaniko && !o.usesWorkloadIdentity() && kube.GetSliceEnvVar(envVars, "NO_GOOGLE_APPLICATION_CREDENTIALS") == nil {
 		if kube.GetSliceEnvVar(envVars, "GOOGLE_APPLICATION_CREDENTIALS") == nil {
 			envVars = append(envVars, corev1.EnvVar{
 				Name:  "GOOGLE_APPLICATION_CREDENTIALS",
@@ -1065,10 +1093,29 @@ func (o *StepCreateTaskOptions) modifyEnvVars(container *corev1.Container, globa
 	container.Env = envVars
 }
 
+// usesWorkloadIdentity returns true if the pipeline service account is federated with a cloud identity so that
+// kaniko authenticates without the key of the kaniko secret
+func (o *StepCreateTaskOptions) usesWorkloadIdentity() bool {
+	if o.workloadIdentity == nil {
+		federated := false
+		kubeClient, ns, err := o.KubeClientAndDevNamespace()
+		if err != nil {
+			log.Logger().Warnf("failed to find the pipeline service account: %s", err)
+		} else {
+			federated, err = workloadidentity.IsServiceAccountFederated(kubeClient, ns, o.ServiceAccount)
+			if err != nil {
+				log.Logger().Warnf("failed to check the workload identity of the pipeline service account: %s", err)
+			}
+		}
+		o.workloadIdentity = &federated
+	}
+	return *o.workloadIdentity
+}
+
 func (o *StepCreateTaskOptions) modifyVolumes(container *corev1.Container, volumes []corev1.Volume) []corev1.Volume {
 	answer := volumes
 
-	if isKanikoExecutorStep(container) && !o.NoKaniko {
+	if isKanikoExecutorStep(container) && !o.NoKaniko && !o.usesWorkloadIdentity() {
 		kubeClient, ns, err := o.KubeClientAndDevNamespace()
 		if err != nil {
 			log.Logger().Warnf("failed to find kaniko secret: %s", err)
@@ -1534,10 +1581,22 @@ func (o *StepCreateTaskOptions) interpretPipeline(ns string, projectConfig *conf
 
 	for _, step := range steps {
 		s := step
+		if o.Checkpoint.IsCompleted(s.Name) {
+			log.Logger().Infof("Skipping step %s which completed in the previous run", util.ColorInfo(s.Name))
+			continue
+		}
+		endOperation := util.StartLogOperation(s.Name)
 		err := o.interpretStep(ns, &s)
+		endOperation()
 		if err != nil {
+			if cerr := o.Checkpoint.Fail(s.Name); cerr != nil {
+				log.Logger().Warnf("Failed to record the failed step: %s", cerr.Error())
+			}
 			return err
 		}
+		if cerr := o.Checkpoint.Complete(s.Name); cerr != nil {
+			log.Logger().Warnf("Failed to record the completed step: %s", cerr.Error())
+		}
 	}
 	return nil
 }
@@ -1550,7 +1609,7 @@ func (o *StepCreateTaskOptions) interpretStep(ns string, step *corev1.Container)
 
 	// ignore some unnecessary commands
 	// TODO is there a nicer way to disable the git-merge step?
-	if step.Name == "git-merge" || step.Name == "setup-builder-home" {
+	if step.Name == "git-merge" || step.Name == "git-checkout" || step.Name == "setup-builder-home" {
 		return nil
 	}
 	commandAndArgs := append(step.Command, step.Args...)
@@ -9,6 +9,7 @@ import (
 
 	"github.com/jenkins-x/jx/v2/pkg/auth"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/gits/credentialhelper"
 	"github.com/pkg/errors"
 
@@ -257,6 +258,14 @@ func (o *StepGitCredentialsOptions) CreateGitCredentialsFromAuthService(authConf
 			}
 			username := gitAuth.Username
 			password := gitAuth.ApiToken
+			if gitAuth.IsGitHubApp() {
+				token, err := gits.GitHubAppToken(server.URL, gitAuth)
+				if err != nil {
+					return nil, errors.Wrapf(err, "creating a GitHub App installation token for git service URL %q", server.URL)
+				}
+				username = auth.GitHubAppUsername
+				password = token
+			}
 			if password == "" {
 				password = gitAuth.BearerToken
 			}
@@ -35,6 +35,7 @@ func NewCmdStepGit(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(credentials.NewCmdStepGitCredentials(commonOpts))
 	cmd.AddCommand(NewCmdStepGitEnvs(commonOpts))
 	cmd.AddCommand(NewCmdStepGitMerge(commonOpts))
+	cmd.AddCommand(NewCmdStepGitCheckout(commonOpts))
 	cmd.AddCommand(NewCmdStepGitForkAndClone(commonOpts))
 	cmd.AddCommand(NewCmdStepGitValidate(commonOpts))
 	cmd.AddCommand(NewCmdStepGitClose(commonOpts))
@@ -0,0 +1,308 @@
+package git
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/step/git/credentials"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/gits/credentialhelper"
+	"github.com/jenkins-x/jx/v2/pkg/tekton/syntax"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// StepGitCheckoutLong command long description
+	StepGitCheckoutLong = templates.LongDesc(`
+		This pipeline step checks out the git submodules of the source repository and any sibling repositories the
+		build needs.
+
+		The git credentials of every git server in the git auth config are used so that submodules and sibling
+		repositories can be hosted on different git servers. SSH URLs of those git servers are rewritten to HTTPS so
+		that the credentials are used for them too.
+
+		The step is added to the pipeline automatically if the 'checkout' section of the pipeline configuration enables
+		submodules or declares sibling repositories.
+`)
+	// StepGitCheckoutExample command example
+	StepGitCheckoutExample = templates.Examples(`
+		# Checkout the submodules of the source repository recursively
+		jx step git checkout --submodules
+
+		# Checkout a sibling repository next to the source repository
+		jx step git checkout --repository https://github.com/myorg/mylib.git
+
+		# Checkout a tag of a sibling repository into the 'libs/mylib' directory of the workspace
+		jx step git checkout --repository libs/mylib=https://github.com/myorg/mylib.git#v1.2.3
+
+		# Checkout a sibling repository without downloading its Git LFS objects
+		jx step git checkout --lfs-skip-smudge --repository https://github.com/myorg/assets.git
+`)
+)
+
+// StepGitCheckoutOptions contains the command line flags
+type StepGitCheckoutOptions struct {
+	step.StepOptions
+
+	Dir           string
+	WorkspaceDir  string
+	Submodules    bool
+	Repositories  []string
+	NoCredentials bool
+	LFSSkipSmudge bool
+}
+
+// NewCmdStepGitCheckout create the 'step git checkout' command
+func NewCmdStepGitCheckout(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := StepGitCheckoutOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "checkout",
+		Short:   "Checks out the git submodules and sibling repositories of the source repository",
+		Long:    StepGitCheckoutLong,
+		Example: StepGitCheckoutExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Dir, "dir", "", "", "The directory in which the source repository is checked out. Defaults to the current directory")
+	cmd.Flags().StringVarP(&options.WorkspaceDir, "workspace-dir", "", "", "The directory the sibling repositories are checked out into. Defaults to the parent of the source directory")
+	cmd.Flags().BoolVarP(&options.Submodules, "submodules", "", false, "Checks out the git submodules recursively")
+	cmd.Flags().StringArrayVarP(&options.Repositories, "repository", "", nil, "A sibling repository to check out in the format [dir=]url[#revision]")
+	cmd.Flags().BoolVarP(&options.NoCredentials, "no-credentials", "", false, "Uses the existing git credentials rather than the ones from the git auth config")
+	cmd.Flags().BoolVarP(&options.LFSSkipSmudge, "lfs-skip-smudge", "", gits.LargeRepoOptionsFromEnv().SkipLFSSmudge, "Skips downloading Git LFS objects to speed up the checkout")
+	return cmd
+}
+
+// Run implements the command
+func (o *StepGitCheckoutOptions) Run() error {
+	dir := o.Dir
+	if dir == "" {
+		dir = "."
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return errors.Wrapf(err, "resolving directory %s", dir)
+	}
+	workspaceDir := o.WorkspaceDir
+	if workspaceDir == "" {
+		workspaceDir = filepath.Dir(dir)
+	}
+	repositories := []*syntax.CheckoutRepository{}
+	for _, text := range o.Repositories {
+		r, err := syntax.ParseCheckoutRepository(text)
+		if err != nil {
+			return util.InvalidOptionError("repository", text, err)
+		}
+		repositories = append(repositories, r)
+	}
+
+	gitArgs := []string{}
+	if !o.NoCredentials {
+		credentialsFile, args, err := o.configureCredentials()
+		if err != nil {
+			return err
+		}
+		if credentialsFile != "" {
+			defer os.Remove(credentialsFile) //nolint:errcheck
+		}
+		gitArgs = args
+	}
+
+	if o.Submodules {
+		err = o.checkoutSubmodules(dir, gitArgs)
+		if err != nil {
+			return err
+		}
+	}
+	for _, r := range repositories {
+		err = o.checkoutRepository(workspaceDir, r, gitArgs)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configureCredentials writes the credentials of the git servers in the git auth config to a temporary credentials
+// store and returns the git config arguments which use it
+func (o *StepGitCheckoutOptions) configureCredentials() (string, []string, error) {
+	authConfigSvc, err := o.GitAuthConfigService()
+	if err != nil {
+		log.Logger().Warnf("Using the existing git credentials as the git auth config could not be loaded: %s", err)
+		return "", nil, nil
+	}
+	credentialsOptions := &credentials.StepGitCredentialsOptions{}
+	gitCredentials, err := credentialsOptions.CreateGitCredentialsFromAuthService(authConfigSvc, false)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "creating git credentials")
+	}
+	if len(gitCredentials) == 0 {
+		return "", nil, nil
+	}
+	data, err := credentialsOptions.GitCredentialsFileData(gitCredentials)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "creating git credentials")
+	}
+	f, err := ioutil.TempFile("", "jx-git-checkout-credentials-")
+	if err != nil {
+		return "", nil, errors.Wrap(err, "creating the git credentials file")
+	}
+	_, err = f.Write(data)
+	closeErr := f.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name()) //nolint:errcheck
+		return "", nil, errors.Wrapf(err, "writing the git credentials file %s", f.Name())
+	}
+	return f.Name(), CredentialConfigArgs(f.Name(), gitCredentials), nil
+}
+
+// CredentialConfigArgs returns the git arguments which use the credentials store file for each git server and rewrite
+// the SSH URLs of those git servers to HTTPS. The arguments are inherited by the git commands run for submodules
+func CredentialConfigArgs(credentialsFile string, gitCredentials []credentialhelper.GitCredential) []string {
+	args := []string{"-c", fmt.Sprintf("credential.helper=store --file=%s", credentialsFile)}
+	hosts := map[string]bool{}
+	for _, c := range gitCredentials {
+		u, err := c.URL()
+		if err != nil || u.Host == "" || hosts[u.Host] {
+			continue
+		}
+		hosts[u.Host] = true
+		base := (&url.URL{Scheme: "https", Host: u.Host, Path: "/"}).String()
+		args = append(args,
+			"-c", fmt.Sprintf("url.%s.insteadOf=git@%s:", base, u.Host),
+			"-c", fmt.Sprintf("url.%s.insteadOf=ssh://git@%s/", base, u.Host))
+	}
+	return args
+}
+
+func (o *StepGitCheckoutOptions) checkoutSubmodules(dir string, gitArgs []string) error {
+	exists, err := util.FileExists(filepath.Join(dir, ".gitmodules"))
+	if err != nil {
+		return err
+	}
+	if !exists {
+		log.Logger().Infof("No git submodules found in %s", util.ColorInfo(dir))
+		return nil
+	}
+	// lets pick up any submodule URL changes in the commit being built before updating
+	err = o.git(dir, gitArgs, "submodule", "sync", "--recursive")
+	if err != nil {
+		return errors.Wrapf(err, "syncing the git submodules in %s", dir)
+	}
+	err = o.git(dir, gitArgs, "submodule", "update", "--init", "--recursive")
+	if err != nil {
+		return errors.Wrapf(err, "checking out the git submodules in %s", dir)
+	}
+	if !o.LFSSkipSmudge && o.lfsInstalled(dir) {
+		// the submodules may use Git LFS even if the source repository does not
+		err = o.git(dir, gitArgs, "submodule", "foreach", "--recursive", "git lfs pull")
+		if err != nil {
+			return errors.Wrapf(err, "pulling the Git LFS objects of the git submodules in %s", dir)
+		}
+	}
+	log.Logger().Infof("Checked out the git submodules in %s", util.ColorInfo(dir))
+	return nil
+}
+
+func (o *StepGitCheckoutOptions) checkoutRepository(workspaceDir string, r *syntax.CheckoutRepository, gitArgs []string) error {
+	dir := filepath.Join(workspaceDir, filepath.FromSlash(r.GetDir()))
+	exists, err := util.DirExists(filepath.Join(dir, ".git"))
+	if err != nil {
+		return err
+	}
+	if !exists {
+		err = os.MkdirAll(filepath.Dir(dir), util.DefaultWritePermissions)
+		if err != nil {
+			return errors.Wrapf(err, "creating directory %s", filepath.Dir(dir))
+		}
+		err = o.git(workspaceDir, gitArgs, "clone", r.URL, dir)
+		if err != nil {
+			return errors.Wrapf(err, "cloning %s", r.URL)
+		}
+	}
+	revision := r.Revision
+	if revision == "" && exists {
+		revision = "HEAD"
+	}
+	if revision != "" {
+		err = o.git(dir, gitArgs, "fetch", "origin", revision)
+		if err != nil {
+			return errors.Wrapf(err, "fetching %s of %s", revision, r.URL)
+		}
+		err = o.git(dir, gitArgs, "checkout", "--force", "FETCH_HEAD")
+		if err != nil {
+			return errors.Wrapf(err, "checking out %s of %s", revision, r.URL)
+		}
+	}
+	err = o.pullLFS(dir, gitArgs)
+	if err != nil {
+		return errors.Wrapf(err, "pulling the Git LFS objects of %s", r.URL)
+	}
+	if o.Submodules {
+		err = o.checkoutSubmodules(dir, gitArgs)
+		if err != nil {
+			return err
+		}
+	}
+	log.Logger().Infof("Checked out %s into %s", util.ColorInfo(r.String()), util.ColorInfo(dir))
+	return nil
+}
+
+// pullLFS downloads the Git LFS objects of the repository in the directory if it uses Git LFS
+func (o *StepGitCheckoutOptions) pullLFS(dir string, gitArgs []string) error {
+	if o.LFSSkipSmudge {
+		return nil
+	}
+	lfs, err := gits.UsesLFS(dir)
+	if err != nil || !lfs {
+		return err
+	}
+	if !o.lfsInstalled(dir) {
+		log.Logger().Warnf("git lfs is not installed so Git LFS objects in %s are not downloaded", dir)
+		return nil
+	}
+	return o.git(dir, gitArgs, "lfs", "pull")
+}
+
+func (o *StepGitCheckoutOptions) lfsInstalled(dir string) bool {
+	return o.git(dir, nil, "lfs", "version") == nil
+}
+
+func (o *StepGitCheckoutOptions) git(dir string, gitArgs []string, args ...string) error {
+	env := map[string]string{
+		// fail rather than hang if a git server needs credentials we do not have
+		"GIT_TERMINAL_PROMPT": "0",
+	}
+	if o.LFSSkipSmudge {
+		env["GIT_LFS_SKIP_SMUDGE"] = "1"
+	}
+	cmd := util.Command{
+		Dir:  dir,
+		Name: "git",
+		Args: append(append([]string{}, gitArgs...), args...),
+		Env:  env,
+	}
+	log.Logger().Debug(cmd.String())
+	output, err := cmd.RunWithoutRetry()
+	return errors.Wrapf(err, "git output: %s", output)
+}
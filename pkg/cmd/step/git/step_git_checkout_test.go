@@ -0,0 +1,127 @@
+// +build unit
+
+package git_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/step/git"
+	"github.com/jenkins-x/jx/v2/pkg/gits/credentialhelper"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentialConfigArgs(t *testing.T) {
+	gitCredentials := []credentialhelper.GitCredential{
+		{Protocol: "https", Host: "github.com", Username: "user", Password: "token"},
+		{Protocol: "https", Host: "gitlab.example.com", Username: "other", Password: "secret"},
+		{Protocol: "https", Host: "github.com", Username: "bot", Password: "token"},
+	}
+	args := git.CredentialConfigArgs("/tmp/creds", gitCredentials)
+	assert.Equal(t, []string{
+		"-c", "credential.helper=store --file=/tmp/creds",
+		"-c", "url.https://github.com/.insteadOf=git@github.com:",
+		"-c", "url.https://github.com/.insteadOf=ssh://git@github.com/",
+		"-c", "url.https://gitlab.example.com/.insteadOf=git@gitlab.example.com:",
+		"-c", "url.https://gitlab.example.com/.insteadOf=ssh://git@gitlab.example.com/",
+	}, args)
+	for _, arg := range args {
+		assert.NotContains(t, arg, "token", "credentials must not be passed on the command line")
+	}
+}
+
+func TestStepGitCheckout(t *testing.T) {
+	// newer versions of git refuse to clone submodules from local paths by default
+	os.Setenv("GIT_CONFIG_COUNT", "1")
+	os.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+	os.Setenv("GIT_CONFIG_VALUE_0", "always")
+	defer func() {
+		os.Unsetenv("GIT_CONFIG_COUNT")
+		os.Unsetenv("GIT_CONFIG_KEY_0")
+		os.Unsetenv("GIT_CONFIG_VALUE_0")
+	}()
+
+	tmpDir, err := ioutil.TempDir("", "test-step-git-checkout-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	remotesDir := filepath.Join(tmpDir, "remotes")
+	nested := createRepository(t, filepath.Join(remotesDir, "nested"), "nested.txt")
+	submodule := createRepository(t, filepath.Join(remotesDir, "submodule"), "submodule.txt")
+	runGit(t, submodule, "submodule", "add", nested, "nested")
+	runGit(t, submodule, "commit", "-m", "add nested submodule")
+	lib := createRepository(t, filepath.Join(remotesDir, "lib"), "lib.txt")
+	runGit(t, lib, "tag", "v1.0.0")
+	writeAndCommit(t, lib, "lib.txt", "newer")
+
+	workspaceDir := filepath.Join(tmpDir, "workspace")
+	source := createRepository(t, filepath.Join(workspaceDir, "source"), "source.txt")
+	runGit(t, source, "submodule", "add", submodule, "modules/submodule")
+	runGit(t, source, "commit", "-m", "add submodule")
+	// simulate the pipeline checking out the source without its submodules
+	runGit(t, source, "submodule", "deinit", "--all", "--force")
+
+	options := &git.StepGitCheckoutOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: &opts.CommonOptions{},
+		},
+		Dir:           source,
+		Submodules:    true,
+		Repositories:  []string{lib + "#v1.0.0", "libs/latest=" + lib},
+		NoCredentials: true,
+	}
+	err = options.Run()
+	require.NoError(t, err)
+
+	assertFile(t, filepath.Join(source, "modules", "submodule", "submodule.txt"), "submodule.txt")
+	assertFile(t, filepath.Join(source, "modules", "submodule", "nested", "nested.txt"), "nested.txt")
+	assertFile(t, filepath.Join(workspaceDir, "lib", "lib.txt"), "lib.txt")
+	assertFile(t, filepath.Join(workspaceDir, "libs", "latest", "lib.txt"), "newer")
+
+	// running again updates the existing checkouts
+	writeAndCommit(t, lib, "lib.txt", "newest")
+	err = options.Run()
+	require.NoError(t, err)
+	assertFile(t, filepath.Join(workspaceDir, "lib", "lib.txt"), "lib.txt")
+	assertFile(t, filepath.Join(workspaceDir, "libs", "latest", "lib.txt"), "newest")
+}
+
+func createRepository(t *testing.T, dir string, fileName string) string {
+	err := os.MkdirAll(dir, util.DefaultWritePermissions)
+	require.NoError(t, err)
+	runGit(t, dir, "init")
+	runGit(t, dir, "config", "user.name", "test")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+	writeAndCommit(t, dir, fileName, fileName)
+	return dir
+}
+
+func writeAndCommit(t *testing.T, dir string, fileName string, content string) {
+	err := ioutil.WriteFile(filepath.Join(dir, fileName), []byte(content), util.DefaultFileWritePermissions)
+	require.NoError(t, err)
+	runGit(t, dir, "add", fileName)
+	runGit(t, dir, "commit", "-m", "update "+fileName)
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	cmd := util.Command{
+		Dir:  dir,
+		Name: "git",
+		Args: args,
+	}
+	output, err := cmd.RunWithoutRetry()
+	require.NoError(t, err, "git %s: %s", strings.Join(args, " "), output)
+}
+
+func assertFile(t *testing.T, fileName string, expected string) {
+	data, err := ioutil.ReadFile(fileName)
+	require.NoError(t, err, "reading %s", fileName)
+	assert.Equal(t, expected, string(data), "content of %s", fileName)
+}
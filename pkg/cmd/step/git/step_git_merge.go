@@ -41,6 +41,9 @@ master:ef08a6cd194c2687d4bc12df6bb8a86f53c348ba,2739:5b351f4eae3c4afbb90dd7787f8
 
 		# Merge a number of SHAs into the HEAD of master
 		jx step git merge --sha 123456a --sha 789012b
+
+		# Merge the SHAs without downloading the Git LFS objects
+		jx step git merge --lfs-skip-smudge
 `)
 )
 
@@ -53,6 +56,8 @@ type StepGitMergeOptions struct {
 	Dir        string
 	BaseBranch string
 	BaseSHA    string
+
+	LFSSkipSmudge bool
 }
 
 // NewCmdStepGitMerge create the 'step git envs' command
@@ -83,6 +88,7 @@ func NewCmdStepGitMerge(commonOpts *opts.CommonOptions) *cobra.Command {
 		"if not specified then the  first entry in PULL_REFS is used ")
 	cmd.Flags().StringVarP(&options.BaseSHA, "baseSHA", "", "", "The SHA to use on the base branch, "+
 		"if not specified then the first entry in PULL_REFS is used")
+	cmd.Flags().BoolVarP(&options.LFSSkipSmudge, "lfs-skip-smudge", "", gits.LargeRepoOptionsFromEnv().SkipLFSSmudge, "Skips downloading Git LFS objects to speed up the checkout")
 
 	return cmd
 }
@@ -120,12 +126,20 @@ func (o *StepGitMergeOptions) Run() error {
 			}
 		}
 	}
+	gitter := o.Git()
+	if o.LFSSkipSmudge {
+		largeRepo := gits.LargeRepoOptionsFromEnv()
+		largeRepo.SkipLFSSmudge = true
+		largeRepo.LFSInclude = nil
+		gitter = gits.WithLargeRepo(gitter, largeRepo)
+	}
+
 	if len(o.SHAs) == 0 {
 		log.Logger().Warnf("no SHAs to merge, falling back to initial cloned commit")
-		return nil
+		return gits.PullLFS(gitter, o.Dir, o.Remote)
 	}
 
-	err = gits.FetchAndMergeSHAs(o.SHAs, o.BaseBranch, o.BaseSHA, o.Remote, o.Dir, o.Git())
+	err = gits.FetchAndMergeSHAs(o.SHAs, o.BaseBranch, o.BaseSHA, o.Remote, o.Dir, gitter)
 	if err != nil {
 		return errors.Wrap(err, "error during merge")
 	}
@@ -12,12 +12,15 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/addon"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
 	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/crossplane"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/helm"
 	configio "github.com/jenkins-x/jx/v2/pkg/io"
@@ -43,6 +46,7 @@ type StepHelmApplyOptions struct {
 	Vault              bool
 	NoVault            bool
 	NoMasking          bool
+	NoPrune            bool
 	ProviderValuesDir  string
 }
 
@@ -97,6 +101,7 @@ func NewCmdStepHelmApply(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().BoolVarP(&options.Boot, "boot", "", false, "In Boot mode we load the Version Stream from the 'jx-requirements.yml' and use that to replace any missing versions in the 'requirements.yaml' file from the Version Stream")
 	cmd.Flags().BoolVarP(&options.NoVault, "no-vault", "", false, "Disables loading secrets from Vault. e.g. if bootstrapping core services like Ingress before we have a Vault")
 	cmd.Flags().BoolVarP(&options.NoMasking, "no-masking", "", false, "The effective 'values.yaml' file is output to the console with parameters masked. Enabling this flag will show the unmasked secrets in the console output")
+	cmd.Flags().BoolVarP(&options.NoPrune, "no-prune", "", false, "When using template mode, keeps the resources of a previous release which are no longer rendered by the chart rather than deleting them")
 	cmd.Flags().StringVarP(&options.ProviderValuesDir, "provider-values-dir", "", "", "The optional directory of kubernetes provider specific override values.tmpl.yaml files a kubernetes provider specific folder")
 
 	return cmd
@@ -143,17 +148,23 @@ func (o *StepHelmApplyOptions) Run() error {
 		return err
 	}
 
-	kubeClient, err := o.KubeClient()
+	kubeClient, devNs, err := o.KubeClientAndDevNamespace()
 	if err != nil {
 		return err
 	}
 
-	err = kube.EnsureNamespaceCreated(kubeClient, ns, nil, nil)
+	env, err := o.environmentForNamespace(devNs, ns)
 	if err != nil {
 		return err
 	}
 
-	_, devNs, err := o.KubeClientAndDevNamespace()
+	err = o.RunInEnvironmentCluster(env, func() error {
+		remoteClient, err := o.KubeClient()
+		if err != nil {
+			return err
+		}
+		return kube.EnsureNamespaceCreated(remoteClient, ns, nil, nil)
+	})
 	if err != nil {
 		return err
 	}
@@ -169,6 +180,7 @@ func (o *StepHelmApplyOptions) Run() error {
 			}
 		}
 	}
+	defer util.StartLogOperation("helm apply " + releaseName)()
 	info := util.ColorInfo
 
 	path, err := filepath.Abs(dir)
@@ -217,6 +229,9 @@ func (o *StepHelmApplyOptions) Run() error {
 	log.Logger().Debugf("Applying helm chart at %s as release name %s to namespace %s", info(dir), info(releaseName), info(ns))
 
 	o.Helm().SetCWD(dir)
+	if helmTemplate, ok := o.Helm().(*helm.HelmTemplate); ok {
+		helmTemplate.NoPrune = o.NoPrune
+	}
 
 	valueFiles := []string{}
 	for _, name := range defaultValueFileNames {
@@ -387,18 +402,67 @@ func (o *StepHelmApplyOptions) Run() error {
 		helmOptions.VersionsGitRef = requirements.VersionStream.Ref
 	}
 
-	if o.Wait {
-		helmOptions.Wait = true
-		err = o.InstallChartWithOptionsAndTimeout(helmOptions, "600")
-	} else {
-		err = o.InstallChartWithOptions(helmOptions)
-	}
+	err = o.RunInEnvironmentCluster(env, func() error {
+		var err error
+		if o.Wait {
+			helmOptions.Wait = true
+			err = o.InstallChartWithOptionsAndTimeout(helmOptions, "600")
+		} else {
+			err = o.InstallChartWithOptions(helmOptions)
+		}
+		if err != nil {
+			return err
+		}
+		if !requirements.Crossplane && !addon.IsAddonEnabled(kube.DefaultCrossplaneReleaseName) {
+			return nil
+		}
+		return o.wireCloudResourceSecrets(ns)
+	})
 	if err != nil {
 		return errors.Wrapf(err, "upgrading helm chart '%s'", chartName)
 	}
 	return nil
 }
 
+// wireCloudResourceSecrets makes the Crossplane claims of the apps in the namespace write their connection details
+// to a Secret in the namespace. It does nothing if Crossplane is not installed or cannot be accessed
+func (o *StepHelmApplyOptions) wireCloudResourceSecrets(ns string) error {
+	dynamicClient, _, err := o.GetFactory().CreateDynamicClient()
+	if err != nil {
+		return errors.Wrap(err, "creating the dynamic client")
+	}
+	resources, err := crossplane.ClaimResources(dynamicClient)
+	if err != nil {
+		return err
+	}
+	claims, err := crossplane.WireConnectionSecrets(dynamicClient, resources, ns)
+	if err != nil {
+		return err
+	}
+	for _, c := range claims {
+		log.Logger().Infof("the connection details of %s %s are written to Secret %s in namespace %s", c.Kind, util.ColorInfo(c.Name), util.ColorInfo(c.ConnectionSecret), ns)
+	}
+	return nil
+}
+
+// environmentForNamespace returns the environment deployed to the namespace so that the chart can be applied to its
+// cluster, returning nil for the dev namespace or if the environments cannot be loaded such as when booting
+func (o *StepHelmApplyOptions) environmentForNamespace(devNs string, ns string) (*v1.Environment, error) {
+	if ns == devNs {
+		return nil, nil
+	}
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return nil, err
+	}
+	env, err := kube.GetEnvironmentForNamespace(jxClient, devNs, ns)
+	if err != nil {
+		log.Logger().Debugf("failed to find the Environment of namespace %s: %s", ns, err.Error())
+		return nil, nil
+	}
+	return env, nil
+}
+
 // getRequirements tries to load the requirements either from the team settings or local requirements file
 func (o *StepHelmApplyOptions) getRequirements() (*config.RequirementsConfig, string, error) {
 	// Try to load first the requirements from current directory
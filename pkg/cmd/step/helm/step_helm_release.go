@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
 
@@ -23,6 +24,9 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// bucketTimeout the time to wait for the upload of a chart to a chart repository in a bucket
+const bucketTimeout = 5 * time.Minute
+
 // StepHelmReleaseOptions contains the command line flags
 type StepHelmReleaseOptions struct {
 	StepHelmOptions
@@ -30,7 +34,10 @@ type StepHelmReleaseOptions struct {
 
 var (
 	StepHelmReleaseLong = templates.LongDesc(`
-		This pipeline step releases the Helm chart in the current directory
+		This pipeline step releases the Helm chart in the current directory to the chart repository of the team.
+
+		The chart repository is either a chartmuseum URL, a gs://, s3:// or azblob:// bucket URL whose index.yaml is
+		updated with the chart or an oci:// registry URL the chart is pushed to with helm 3.
 `)
 
 	StepHelmReleaseExample = templates.Examples(`
@@ -104,7 +111,18 @@ func (o *StepHelmReleaseOptions) Run() error {
 	defer os.Remove(tarball)
 
 	chartRepo := o.ReleaseChartRepositoryURL()
+	switch helm.ChartRepositoryKind(chartRepo) {
+	case helm.ChartRepositoryKindBucket:
+		return helm.PublishChartToBucket(chartRepo, tarball, chartFile, bucketTimeout)
+	case helm.ChartRepositoryKindOCI:
+		return helm.PushChartToOCI(o.Helm().HelmBinary(), chartRepo, tarball)
+	default:
+		return o.uploadToChartMuseum(chartRepo, tarball)
+	}
+}
 
+// uploadToChartMuseum posts the chart archive to the API of chartmuseum or bucketrepo
+func (o *StepHelmReleaseOptions) uploadToChartMuseum(chartRepo string, tarball string) error {
 	userName := os.Getenv("CHARTMUSEUM_CREDS_USR")
 	password := os.Getenv("CHARTMUSEUM_CREDS_PSW")
 	if userName == "" || password == "" {
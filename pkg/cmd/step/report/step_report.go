@@ -42,6 +42,7 @@ func NewCmdStepReport(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(NewCmdStepReportChart(commonOpts))
 	cmd.AddCommand(NewCmdStepReportImageVersion(commonOpts))
 	cmd.AddCommand(NewCmdStepReportJUnit(commonOpts))
+	cmd.AddCommand(NewCmdStepReportTests(commonOpts))
 	cmd.AddCommand(NewCmdStepReportVersion(commonOpts))
 	return cmd
 }
@@ -0,0 +1,222 @@
+package report
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/builds"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/testreports"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	stepReportTestsLong = templates.LongDesc(`
+		Parses the JUnit or XUnit XML files created by the tests of a build and stores the summary of the results
+		as a Fact so the trend of the tests can be viewed via 'jx get reports'.
+
+		When run in a Pull Request pipeline the summary is added as a comment on the Pull Request comparing it to the
+		latest build of the base branch.
+`)
+	stepReportTestsExample = templates.Examples(`
+		# Summarize the test results in $REPORTS_DIR
+		jx step report tests
+
+		# Summarize the surefire reports along with the code coverage
+		jx step report tests --in-dir target/surefire-reports --pattern "TEST-*.xml" --coverage 82
+`)
+)
+
+// StepReportTestsOptions contains the command line flags and other helper objects
+type StepReportTestsOptions struct {
+	StepReportOptions
+	ReportsDir string
+	Patterns   []string
+	Coverage   int
+	Owner      string
+	Repository string
+	Branch     string
+	Build      string
+	NoComment  bool
+}
+
+// NewCmdStepReportTests Creates a new Command object
+func NewCmdStepReportTests(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepReportTestsOptions{
+		StepReportOptions: StepReportOptions{
+			StepOptions: step.StepOptions{
+				CommonOptions: commonOpts,
+			},
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "tests",
+		Short:   "Stores the summary of the JUnit test results of a build",
+		Long:    stepReportTestsLong,
+		Example: stepReportTestsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.ReportsDir, "in-dir", "f", "", "The directory to get the reports from. Defaults to $REPORTS_DIR or the current directory")
+	cmd.Flags().StringArrayVarP(&options.Patterns, "pattern", "p", []string{"*.xml"}, "The file patterns of the JUnit or XUnit reports in the directory")
+	cmd.Flags().IntVarP(&options.Coverage, "coverage", "", -1, "The percentage of the code covered by the tests if it is known")
+	cmd.Flags().StringVarP(&options.Owner, "owner", "o", "", "The git owner of the build. Defaults to $REPO_OWNER or the current git repository")
+	cmd.Flags().StringVarP(&options.Repository, "repository", "r", "", "The git repository of the build. Defaults to $REPO_NAME or the current git repository")
+	cmd.Flags().StringVarP(&options.Branch, "branch", "", "", "The branch of the build. Defaults to $BRANCH_NAME")
+	cmd.Flags().StringVarP(&options.Build, "build", "", "", "The build number. Defaults to $BUILD_NUMBER")
+	cmd.Flags().BoolVarP(&options.NoComment, "no-comment", "", false, "Disables commenting the summary on the Pull Request")
+	return cmd
+}
+
+// Run summarizes the test results
+func (o *StepReportTestsOptions) Run() error {
+	if o.ReportsDir == "" {
+		o.ReportsDir = os.Getenv("REPORTS_DIR")
+	}
+	if o.ReportsDir == "" {
+		o.ReportsDir = "."
+	}
+	fileNames := []string{}
+	for _, pattern := range o.Patterns {
+		matches, err := filepath.Glob(filepath.Join(o.ReportsDir, pattern))
+		if err != nil {
+			return errors.Wrapf(err, "invalid pattern %s", pattern)
+		}
+		for _, m := range matches {
+			if util.StringArrayIndex(fileNames, m) < 0 {
+				fileNames = append(fileNames, m)
+			}
+		}
+	}
+	if len(fileNames) == 0 {
+		log.Logger().Warnf("no test reports matching %v found in %s", o.Patterns, o.ReportsDir)
+		return nil
+	}
+
+	summary, err := testreports.ParseJUnitFiles(fileNames)
+	if err != nil {
+		return logErrorAndExitGracefully("there was a problem parsing the test reports", err)
+	}
+	if o.Coverage >= 0 {
+		summary.Coverage = o.Coverage
+	}
+	log.Logger().Infof("%d tests: %s passed, %s failed, %d skipped", summary.Tests, util.ColorInfo(strconv.Itoa(summary.Passed())),
+		util.ColorError(strconv.Itoa(summary.Failures+summary.Errors)), summary.Skipped)
+
+	err = o.defaultBuildValues()
+	if err != nil {
+		return logErrorAndExitGracefully("there was a problem finding the build of the test reports", err)
+	}
+	if o.Build == "" {
+		log.Logger().Warnf("not storing the test summary as no build number could be found")
+		return nil
+	}
+
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return logErrorAndExitGracefully("there was a problem creating the jx client", err)
+	}
+	fact, err := testreports.SaveFact(jxClient, ns, testreports.NewFact(o.Owner, o.Repository, o.Branch, o.Build, summary))
+	if err != nil {
+		return logErrorAndExitGracefully("there was a problem storing the test summary", err)
+	}
+	log.Logger().Infof("stored the test summary in Fact %s", util.ColorInfo(fact.Name))
+
+	prNumber := os.Getenv("PULL_NUMBER")
+	if o.NoComment || prNumber == "" {
+		return nil
+	}
+	baseBranch := os.Getenv("PULL_BASE_REF")
+	if baseBranch == "" {
+		baseBranch = "master"
+	}
+	previous, err := testreports.PreviousSummary(jxClient, ns, o.Owner, o.Repository, baseBranch, "")
+	if err != nil {
+		return logErrorAndExitGracefully("there was a problem finding the test summary of the base branch", err)
+	}
+	err = o.commentOnPullRequest(prNumber, testreports.Markdown(summary, previous, baseBranch))
+	if err != nil {
+		return logErrorAndExitGracefully("there was a problem commenting on the Pull Request", err)
+	}
+	return nil
+}
+
+// defaultBuildValues defaults the owner, repository, branch and build from the environment of the pipeline
+func (o *StepReportTestsOptions) defaultBuildValues() error {
+	if o.Owner == "" {
+		o.Owner = os.Getenv("REPO_OWNER")
+	}
+	if o.Repository == "" {
+		o.Repository = os.Getenv("REPO_NAME")
+	}
+	if o.Owner == "" || o.Repository == "" {
+		gitInfo, err := o.FindGitInfo("")
+		if err != nil {
+			return errors.Wrap(err, "failed to find the git repository")
+		}
+		if o.Owner == "" {
+			o.Owner = gitInfo.Organisation
+		}
+		if o.Repository == "" {
+			o.Repository = gitInfo.Name
+		}
+	}
+	if o.Branch == "" {
+		o.Branch = builds.GetBranchName()
+	}
+	if o.Branch == "" {
+		return fmt.Errorf("environment variable %s is empty", util.EnvVarBranchName)
+	}
+	if o.Build == "" {
+		o.Build = builds.GetBuildNumber()
+	}
+	return nil
+}
+
+func (o *StepReportTestsOptions) commentOnPullRequest(prNumber string, comment string) error {
+	n, err := strconv.Atoi(prNumber)
+	if err != nil {
+		return errors.Wrapf(err, "invalid Pull Request number %s", prNumber)
+	}
+	authConfigSvc, err := o.GitAuthConfigService()
+	if err != nil {
+		return err
+	}
+	gitInfo, err := o.FindGitInfo("")
+	if err != nil {
+		return err
+	}
+	gitKind, err := o.GitServerKind(gitInfo)
+	if err != nil {
+		return err
+	}
+	ghOwner, err := o.GetGitHubAppOwner(gitInfo)
+	if err != nil {
+		return err
+	}
+	provider, err := o.NewGitProvider(gitInfo.URL, "user name to submit comment as", authConfigSvc, gitKind, ghOwner, o.BatchMode, o.Git())
+	if err != nil {
+		return err
+	}
+	pr := &gits.GitPullRequest{
+		Owner:  o.Owner,
+		Repo:   o.Repository,
+		Number: &n,
+	}
+	return provider.AddPRComment(pr, comment)
+}
@@ -0,0 +1,46 @@
+// +build unit
+
+package report
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx-api/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/testreports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStepReportTests(t *testing.T) {
+	commonOpts := opts.NewCommonOptionsWithFactory(nil)
+	commonOpts.SetDevNamespace("jx")
+	jxClient := fake.NewSimpleClientset()
+	commonOpts.SetJxClient(jxClient)
+
+	o := &StepReportTestsOptions{
+		StepReportOptions: StepReportOptions{
+			StepOptions: step.StepOptions{
+				CommonOptions: &commonOpts,
+			},
+		},
+		ReportsDir: filepath.Join("test_data", "junit", "multiple_reports"),
+		Patterns:   []string{"*.junit.xml"},
+		Coverage:   81,
+		Owner:      "myorg",
+		Repository: "myrepo",
+		Branch:     "master",
+		Build:      "3",
+		NoComment:  true,
+	}
+	err := o.Run()
+	require.NoError(t, err)
+
+	fact, err := jxClient.JenkinsV1().Facts("jx").Get("jx-tests-myorg-myrepo-master-3", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, testreports.FactTypeTests, fact.Spec.FactType)
+	assert.Equal(t, testreports.Summary{Tests: 4, Seconds: 5, Coverage: 81}, *testreports.SummaryFromFact(fact))
+}
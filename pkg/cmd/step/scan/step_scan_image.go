@@ -0,0 +1,181 @@
+package scan
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/scan"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// StepScanImageOptions contains the command line flags
+type StepScanImageOptions struct {
+	step.StepOptions
+
+	Image         string
+	Scanner       string
+	FailOn        string
+	WarnOn        string
+	IgnoreUnfixed bool
+	Application   string
+	Version       string
+	NoReport      bool
+
+	// Client scans the image, defaults to the binary of the scanner of the policy
+	Client scan.Scanner
+}
+
+var (
+	stepScanImageLong = templates.LongDesc(`
+		Scans an image for vulnerabilities with trivy or grype and fails the build if the vulnerabilities reach the severity threshold of the scan policy of the team.
+
+		The scan policy of the team is configured via 'jx edit scanpolicy'. By default the build fails on CRITICAL vulnerabilities and warns on HIGH ones.
+
+		The result of the scan is stored as a scan report which can be viewed via 'jx get scans'
+`)
+
+	stepScanImageExample = templates.Examples(`
+		# scans the image of the release with the scan policy of the team
+		jx step scan image $DOCKER_REGISTRY/$ORG/$APP_NAME:$VERSION
+
+		# scans an image with grype failing on HIGH vulnerabilities
+		jx step scan image gcr.io/myorg/myapp:1.2.3 --scanner grype --fail-on HIGH
+`)
+)
+
+// NewCmdStepScanImage creates the command
+func NewCmdStepScanImage(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepScanImageOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "image <image>",
+		Short:   "Scans an image for vulnerabilities against the scan policy of the team",
+		Long:    stepScanImageLong,
+		Example: stepScanImageExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Image, "image", "i", "", "The image to scan")
+	cmd.Flags().StringVarP(&options.Scanner, "scanner", "s", "", fmt.Sprintf("The scanner overriding the scan policy of the team. Possible values: %s", util.ColorInfo(scan.Scanners)))
+	cmd.Flags().StringVarP(&options.FailOn, "fail-on", "", "", fmt.Sprintf("The lowest severity failing the build overriding the scan policy of the team. Possible values: %s", util.ColorInfo(scan.Severities)))
+	cmd.Flags().StringVarP(&options.WarnOn, "warn-on", "", "", "The lowest severity reported as a warning overriding the scan policy of the team")
+	cmd.Flags().BoolVarP(&options.IgnoreUnfixed, "ignore-unfixed", "", false, "Ignores the vulnerabilities without a fixed version")
+	cmd.Flags().StringVarP(&options.Application, "app", "a", "", "The name of the application of the image. Defaults to $APP_NAME")
+	cmd.Flags().StringVarP(&options.Version, "version", "v", "", "The version of the application. Defaults to $VERSION")
+	cmd.Flags().BoolVarP(&options.NoReport, "no-report", "", false, "Disables storing the scan report")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepScanImageOptions) Run() error {
+	image := o.Image
+	if image == "" && len(o.Args) > 0 {
+		image = o.Args[0]
+	}
+	if image == "" {
+		return util.MissingArgument("image")
+	}
+	kubeClient, devNs, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	policy, err := scan.LoadPolicy(kubeClient, devNs)
+	if err != nil {
+		return err
+	}
+	if o.Scanner != "" {
+		policy.Scanner = o.Scanner
+	}
+	if o.FailOn != "" {
+		policy.FailOn = o.FailOn
+	}
+	if o.WarnOn != "" {
+		policy.WarnOn = o.WarnOn
+	}
+	if o.IgnoreUnfixed {
+		policy.IgnoreUnfixed = true
+	}
+	err = policy.Validate()
+	if err != nil {
+		return err
+	}
+	if o.Client == nil {
+		o.Client, err = scan.NewCLI(policy.Scanner)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Logger().Infof("Scanning image %s with %s", util.ColorInfo(image), util.ColorInfo(policy.Scanner))
+	vulnerabilities, err := o.Client.Scan(image)
+	if err != nil {
+		return err
+	}
+	report := scan.NewReport(image, policy, vulnerabilities)
+	report.Application = o.Application
+	if report.Application == "" {
+		report.Application = os.Getenv("APP_NAME")
+	}
+	report.Version = o.Version
+	if report.Version == "" {
+		report.Version = os.Getenv("VERSION")
+	}
+	report.Created = time.Now().UTC().Format(time.RFC3339)
+	o.renderVulnerabilities(policy, report)
+
+	if !o.NoReport {
+		err = scan.SaveReport(kubeClient, devNs, report)
+		if err != nil {
+			return errors.Wrapf(err, "saving the scan report of image %s", image)
+		}
+	}
+
+	switch report.Result {
+	case scan.ResultFailed:
+		return errors.Errorf("image %s has vulnerabilities of severity %s or higher: %s", image, policy.FailOn, report.Summary())
+	case scan.ResultWarned:
+		log.Logger().Warnf("Image %s has vulnerabilities of severity %s or higher: %s", image, policy.WarnOn, report.Summary())
+	default:
+		log.Logger().Infof("Image %s passed the scan policy %s", util.ColorInfo(image), report.Summary())
+	}
+	return nil
+}
+
+// renderVulnerabilities renders the vulnerabilities reaching the thresholds of the policy
+func (o *StepScanImageOptions) renderVulnerabilities(policy *scan.Policy, report *scan.Report) {
+	threshold := policy.WarnOn
+	if threshold == "" || (policy.FailOn != "" && scan.SeverityIndex(policy.FailOn) < scan.SeverityIndex(threshold)) {
+		threshold = policy.FailOn
+	}
+	if threshold == "" {
+		return
+	}
+	table := o.CreateTable()
+	table.AddRow("ID", "SEVERITY", "PACKAGE", "INSTALLED", "FIXED")
+	count := 0
+	for _, v := range report.Vulnerabilities {
+		if scan.SeverityIndex(v.Severity) < scan.SeverityIndex(threshold) {
+			continue
+		}
+		table.AddRow(v.ID, v.Severity, v.Package, v.InstalledVersion, v.FixedVersion)
+		count++
+	}
+	if count > 0 {
+		table.Render()
+	}
+}
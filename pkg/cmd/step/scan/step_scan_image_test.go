@@ -0,0 +1,70 @@
+// +build unit
+
+package scan_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/clients/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	stepscan "github.com/jenkins-x/jx/v2/pkg/cmd/step/scan"
+	"github.com/jenkins-x/jx/v2/pkg/scan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+type fakeScanner struct {
+	vulnerabilities []scan.Vulnerability
+}
+
+func (f *fakeScanner) Scan(image string) ([]scan.Vulnerability, error) {
+	return f.vulnerabilities, nil
+}
+
+func TestStepScanImage(t *testing.T) {
+	kubeClient := kubefake.NewSimpleClientset()
+	commonOpts := opts.NewCommonOptionsWithFactory(fake.NewFakeFactory())
+	commonOpts.SetDevNamespace("jx")
+	commonOpts.SetKubeClient(kubeClient)
+	commonOpts.Out = os.Stdout
+
+	scanner := &fakeScanner{
+		vulnerabilities: []scan.Vulnerability{
+			{ID: "CVE-2020-1111", Package: "openssl", FixedVersion: "1.1.1i", Severity: scan.SeverityHigh},
+		},
+	}
+	o := &stepscan.StepScanImageOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: &commonOpts,
+		},
+		Application: "myapp",
+		Version:     "1.2.3",
+		Client:      scanner,
+	}
+	o.Args = []string{"gcr.io/myorg/myapp:1.2.3"}
+
+	err := o.Run()
+	require.NoError(t, err, "high vulnerabilities should only be warnings with the default policy")
+	reports, err := scan.ListReports(kubeClient, "jx", "myapp")
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, scan.ResultWarned, reports[0].Result)
+	assert.Equal(t, "1.2.3", reports[0].Version)
+
+	err = scan.SavePolicy(kubeClient, "jx", &scan.Policy{Scanner: scan.ScannerTrivy, FailOn: scan.SeverityHigh})
+	require.NoError(t, err)
+	err = o.Run()
+	assert.Error(t, err, "the team policy should fail the build on high vulnerabilities")
+
+	o.IgnoreUnfixed = true
+	scanner.vulnerabilities[0].FixedVersion = ""
+	err = o.Run()
+	require.NoError(t, err)
+	reports, err = scan.ListReports(kubeClient, "jx", "myapp")
+	require.NoError(t, err)
+	require.Len(t, reports, 1, "the report of the image should be updated")
+	assert.Equal(t, scan.ResultPassed, reports[0].Result)
+}
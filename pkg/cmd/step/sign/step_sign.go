@@ -0,0 +1,39 @@
+package sign
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/spf13/cobra"
+)
+
+// StepSignOptions contains the command line flags
+type StepSignOptions struct {
+	step.StepOptions
+}
+
+// NewCmdStepSign creates the command
+func NewCmdStepSign(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepSignOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:   "sign",
+		Short: "sign [command]",
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.AddCommand(NewCmdStepSignImage(commonOpts))
+	return cmd
+}
+
+// Run implements this command
+func (o *StepSignOptions) Run() error {
+	return o.Cmd.Help()
+}
@@ -0,0 +1,223 @@
+package sign
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/signing"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// envIdentityToken the environment variable of the OIDC identity token of keyless signing
+	envIdentityToken = "SIGSTORE_ID_TOKEN"
+
+	keySecretKey      = "key"
+	keySecretPassword = "password"
+)
+
+// StepSignImageOptions contains the command line flags
+type StepSignImageOptions struct {
+	step.StepOptions
+
+	Image         string
+	Key           string
+	KeySecret     string
+	Keyless       bool
+	IdentityToken string
+	Application   string
+	Version       string
+	NoRecord      bool
+
+	// Signer signs the image, defaults to the cosign binary
+	Signer signing.Signer
+
+	// keyFile the temporary file of the key read from the secrets backend
+	keyFile string
+}
+
+var (
+	stepSignImageLong = templates.LongDesc(`
+		Signs an image with cosign and records its signature.
+
+		The image is signed with the first of:
+
+		* an ephemeral key and a certificate issued for the OIDC identity of the pipeline via '--keyless'
+		* the cosign key reference of '--key' such as a file, k8s://namespace/secret or a KMS URI
+		* the key and password fields of the secret of '--key-secret' in the secrets backend such as Vault
+		* the key pair of the team in the Secret jx-cosign of the dev namespace generated via: cosign generate-key-pair k8s://jx/jx-cosign
+
+		The environments can be configured to only run signed images via 'jx create addon cosign'
+`)
+
+	stepSignImageExample = templates.Examples(`
+		# signs the image of the release with the key pair of the team
+		jx step sign image $DOCKER_REGISTRY/$ORG/$APP_NAME:$VERSION
+
+		# signs an image keyless with the OIDC identity of the pipeline
+		jx step sign image gcr.io/myorg/myapp:1.2.3 --keyless
+
+		# signs an image with the key in the secrets backend
+		jx step sign image gcr.io/myorg/myapp:1.2.3 --key-secret cosign
+`)
+)
+
+// NewCmdStepSignImage creates the command
+func NewCmdStepSignImage(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepSignImageOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "image <image>",
+		Short:   "Signs an image with cosign and records its signature",
+		Long:    stepSignImageLong,
+		Example: stepSignImageExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Image, "image", "i", "", "The image to sign")
+	cmd.Flags().StringVarP(&options.Key, "key", "k", "", "The cosign reference of the private key such as a file, k8s://namespace/secret or a KMS URI. The password is read from $COSIGN_PASSWORD")
+	cmd.Flags().StringVarP(&options.KeySecret, "key-secret", "", "", "The name of the secret in the secrets backend containing the private key and its password in the key and password fields")
+	cmd.Flags().BoolVarP(&options.Keyless, "keyless", "", false, "Signs keyless with the OIDC identity of the pipeline")
+	cmd.Flags().StringVarP(&options.IdentityToken, "identity-token", "", "", fmt.Sprintf("The OIDC identity token of keyless signing. Defaults to $%s or the ambient credentials", envIdentityToken))
+	cmd.Flags().StringVarP(&options.Application, "app", "a", "", "The name of the application of the image. Defaults to $APP_NAME")
+	cmd.Flags().StringVarP(&options.Version, "version", "v", "", "The version of the application. Defaults to $VERSION")
+	cmd.Flags().BoolVarP(&options.NoRecord, "no-record", "", false, "Disables recording the signature")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepSignImageOptions) Run() error {
+	image := o.Image
+	if image == "" && len(o.Args) > 0 {
+		image = o.Args[0]
+	}
+	if image == "" {
+		return util.MissingArgument("image")
+	}
+	if o.Signer == nil {
+		o.Signer = signing.NewCLI()
+	}
+	kubeClient, devNs, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+
+	signature := &signing.Signature{
+		Image:       image,
+		Application: o.Application,
+		Version:     o.Version,
+		Keyless:     o.Keyless,
+	}
+	if signature.Application == "" {
+		signature.Application = os.Getenv("APP_NAME")
+	}
+	if signature.Version == "" {
+		signature.Version = os.Getenv("VERSION")
+	}
+	signOptions, err := o.signOptions(kubeClient, devNs, signature)
+	if o.keyFile != "" {
+		defer os.Remove(o.keyFile)
+	}
+	if err != nil {
+		return err
+	}
+	if signature.Application != "" {
+		signOptions.Annotations = map[string]string{"app": signature.Application}
+		if signature.Version != "" {
+			signOptions.Annotations["version"] = signature.Version
+		}
+	}
+
+	err = o.Signer.Sign(image, signOptions)
+	if err != nil {
+		return err
+	}
+	signature.Signed = time.Now().UTC().Format(time.RFC3339)
+	signature.Ref, err = o.Signer.SignatureRef(image)
+	if err != nil {
+		log.Logger().Warnf("Failed to find the signature of image %s: %s", image, err)
+	}
+	log.Logger().Infof("Signed image %s %s", util.ColorInfo(image), signature.Ref)
+
+	if o.NoRecord {
+		return nil
+	}
+	return signing.SaveSignature(kubeClient, devNs, signature)
+}
+
+// signOptions returns the options to sign with the keyless identity or the key of the flags or of the team
+func (o *StepSignImageOptions) signOptions(kubeClient kubernetes.Interface, devNs string, signature *signing.Signature) (*signing.SignOptions, error) {
+	if o.Keyless {
+		token := o.IdentityToken
+		if token == "" {
+			token = os.Getenv(envIdentityToken)
+		}
+		return &signing.SignOptions{Keyless: true, IdentityToken: token}, nil
+	}
+	if o.Key != "" {
+		signature.Key = o.Key
+		return &signing.SignOptions{Key: o.Key, Password: os.Getenv(signing.EnvPassword)}, nil
+	}
+	if o.KeySecret != "" {
+		return o.keyFromSecretsBackend(signature)
+	}
+
+	secret, err := kubeClient.CoreV1().Secrets(devNs).Get(signing.KeySecretName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, errors.Errorf("no key to sign the image, use --keyless, --key or --key-secret or generate the key pair of the team via: cosign generate-key-pair k8s://%s/%s",
+				devNs, signing.KeySecretName)
+		}
+		return nil, errors.Wrapf(err, "getting Secret %s in namespace %s", signing.KeySecretName, devNs)
+	}
+	signature.Key = fmt.Sprintf("k8s://%s/%s", devNs, signing.KeySecretName)
+	return &signing.SignOptions{Key: signature.Key, Password: string(secret.Data[signing.SecretKeyPassword])}, nil
+}
+
+// keyFromSecretsBackend writes the private key of the secret in the secrets backend to a temporary file
+func (o *StepSignImageOptions) keyFromSecretsBackend(signature *signing.Signature) (*signing.SignOptions, error) {
+	client, err := o.GetSecretURLClient(o.GetSecretsLocation())
+	if err != nil {
+		return nil, err
+	}
+	data, err := client.Read(o.KeySecret)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading secret %s", o.KeySecret)
+	}
+	key, _ := data[keySecretKey].(string)
+	if key == "" {
+		return nil, errors.Errorf("secret %s has no %s field", o.KeySecret, keySecretKey)
+	}
+	password, _ := data[keySecretPassword].(string)
+	file, err := ioutil.TempFile("", "cosign-*.key")
+	if err != nil {
+		return nil, errors.Wrap(err, "creating the temporary file of the key")
+	}
+	defer file.Close()
+	o.keyFile = file.Name()
+	_, err = file.WriteString(key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "writing the key to %s", file.Name())
+	}
+	signature.Key = o.KeySecret
+	return &signing.SignOptions{Key: file.Name(), Password: password}, nil
+}
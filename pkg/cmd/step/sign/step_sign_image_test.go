@@ -0,0 +1,104 @@
+// +build unit
+
+package sign_test
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/clients/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/step/sign"
+	"github.com/jenkins-x/jx/v2/pkg/secreturl/fakevault"
+	"github.com/jenkins-x/jx/v2/pkg/signing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+type fakeSigner struct {
+	options *signing.SignOptions
+	key     string
+}
+
+func (f *fakeSigner) Sign(image string, options *signing.SignOptions) error {
+	f.options = options
+	if !options.Keyless {
+		data, err := ioutil.ReadFile(options.Key)
+		if err == nil {
+			f.key = string(data)
+		}
+	}
+	return nil
+}
+
+func (f *fakeSigner) SignatureRef(image string) (string, error) {
+	return "gcr.io/myorg/myapp:sha256-abc.sig", nil
+}
+
+func (f *fakeSigner) Verify(image string, key string) error {
+	return nil
+}
+
+func TestStepSignImage(t *testing.T) {
+	kubeClient := kubefake.NewSimpleClientset()
+	commonOpts := opts.NewCommonOptionsWithFactory(fake.NewFakeFactory())
+	commonOpts.SetDevNamespace("jx")
+	commonOpts.SetKubeClient(kubeClient)
+	secretURLClient := fakevault.NewFakeClient()
+	commonOpts.SetSecretURLClient(secretURLClient)
+
+	signer := &fakeSigner{}
+	o := &sign.StepSignImageOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: &commonOpts,
+		},
+		Application: "myapp",
+		Version:     "1.2.3",
+		Signer:      signer,
+	}
+	o.Args = []string{"gcr.io/myorg/myapp:1.2.3"}
+
+	err := o.Run()
+	assert.Error(t, err, "signing without a key should fail")
+
+	_, err = kubeClient.CoreV1().Secrets("jx").Create(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: signing.KeySecretName},
+		Data:       map[string][]byte{signing.SecretKeyPassword: []byte("secret")},
+	})
+	require.NoError(t, err)
+	err = o.Run()
+	require.NoError(t, err)
+	assert.Equal(t, "k8s://jx/jx-cosign", signer.options.Key, "the key pair of the team should be used by default")
+	assert.Equal(t, "secret", signer.options.Password)
+	assert.Equal(t, map[string]string{"app": "myapp", "version": "1.2.3"}, signer.options.Annotations)
+
+	signatures, err := signing.ListSignatures(kubeClient, "jx")
+	require.NoError(t, err)
+	require.Len(t, signatures, 1)
+	assert.Equal(t, "gcr.io/myorg/myapp:sha256-abc.sig", signatures[0].Ref)
+	assert.Equal(t, "1.2.3", signatures[0].Version)
+
+	_, err = secretURLClient.Write("cosign", map[string]interface{}{"key": "PRIVATE KEY", "password": "vault-secret"})
+	require.NoError(t, err)
+	o.KeySecret = "cosign"
+	err = o.Run()
+	require.NoError(t, err)
+	assert.Equal(t, "PRIVATE KEY", signer.key, "the key of the secrets backend should be used")
+	assert.Equal(t, "vault-secret", signer.options.Password)
+	assert.NoFileExists(t, signer.options.Key, "the temporary key file should be removed")
+
+	o.Keyless = true
+	o.IdentityToken = "token"
+	err = o.Run()
+	require.NoError(t, err)
+	assert.True(t, signer.options.Keyless)
+	assert.Equal(t, "token", signer.options.IdentityToken)
+	signatures, err = signing.ListSignatures(kubeClient, "jx")
+	require.NoError(t, err)
+	require.Len(t, signatures, 1)
+	assert.True(t, signatures[0].Keyless)
+}
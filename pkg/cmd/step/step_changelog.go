@@ -61,6 +61,10 @@ type StepChangelogOptions struct {
 	Footer              string
 	FooterFile          string
 	OutputMarkdownFile  string
+	Template            string
+	TemplateFile        string
+	ChangelogFile       string
+	PushChangelog       bool
 	OverwriteCRD        bool
 	GenerateCRD         bool
 	GenerateReleaseYaml bool
@@ -74,6 +78,7 @@ type StepChangelogOptions struct {
 type StepChangelogState struct {
 	GitInfo         *gits.GitRepository
 	GitProvider     gits.GitProvider
+	GitKind         string
 	Tracker         issues.IssueProvider
 	FoundIssueNames map[string]bool
 	LoggedIssueKind bool
@@ -128,6 +133,10 @@ e.g. define environment variables GIT_USERNAME and GIT_API_TOKEN
 
 		The changelog is generated by parsing the git commits. It will also detect any text like 'fixes #123' to link to issue fixes. You can also use Conventional Commits notation: https://conventionalcommits.org/ to get a nicer formatted changelog. e.g. using commits like 'fix:(my feature) this my fix' or 'feat:(cheese) something'
 
+		The release notes can be generated with a go template via '--template' or '--template-file' which is passed the changes grouped by their Conventional Commit type and scope, the breaking changes, the issues and pull requests: https://golang.org/pkg/text/template/
+
+		The release notes can also be written to a file via '--output-markdown' and prepended to a CHANGELOG.md file which is committed via '--changelog-file'.
+
 		This command also generates a Release Custom Resource Definition you can include in your helm chart to give metadata about the changelog of the application along with metadata about the release (git tag, url, commits, issues fixed etc). Including this metadata in a helm charts means we can do things like automatically comment on issues when they hit Staging or Production; or give detailed descriptions of what things have changed when using GitOps to update versions in an environment by referencing the fixed issues in the Pull Request.
 
 		You can opt out of the release YAML generation via the '--generate-yaml=false' option
@@ -146,10 +155,15 @@ e.g. define environment variables GIT_USERNAME and GIT_API_TOKEN
 		# specify the version and a header template
 		jx step changelog --header-file docs/dev/changelog-header.md --version 1.2.3
 
+		# generate the release notes with a template and commit them to the CHANGELOG.md file
+		jx step changelog --version 1.2.3 --template-file docs/release-notes.tmpl --changelog-file CHANGELOG.md --push-changelog
+
 `)
 
 	GitHubIssueRegex = regexp.MustCompile(`(\#\d+)`)
 	JIRAIssueRegex   = regexp.MustCompile(`[A-Z][A-Z]+-(\d+)`)
+
+	changelogTitle = "# Changelog\n\n"
 )
 
 func NewCmdStepChangelog(commonOpts *opts.CommonOptions) *cobra.Command {
@@ -181,7 +195,11 @@ func NewCmdStepChangelog(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.Version, "version", "v", "", "The version to release")
 	cmd.Flags().StringVarP(&options.Build, "build", "", "", "The Build number which is used to update the PipelineActivity. If not specified its defaulted from  the '$BUILD_NUMBER' environment variable")
 	cmd.Flags().StringVarP(&options.Dir, "dir", "", "", "The directory of the Git repository. Defaults to the current working directory")
-	cmd.Flags().StringVarP(&options.OutputMarkdownFile, "output-markdown", "", "", "The file to generate for the changelog output if not updating a Git provider release")
+	cmd.Flags().StringVarP(&options.OutputMarkdownFile, "output-markdown", "", "", "The file to generate for the changelog output")
+	cmd.Flags().StringVarP(&options.Template, "template", "", "", "The go template of the release notes. It is passed the changes grouped by their Conventional Commit type: https://golang.org/pkg/text/template/")
+	cmd.Flags().StringVarP(&options.TemplateFile, "template-file", "", "", "The file name of the go template of the release notes")
+	cmd.Flags().StringVarP(&options.ChangelogFile, "changelog-file", "", "", "The changelog file of the repository, such as CHANGELOG.md, to prepend the release notes to and commit")
+	cmd.Flags().BoolVarP(&options.PushChangelog, "push-changelog", "", false, "Pushes the commit of the changelog file to the current branch")
 	cmd.Flags().BoolVarP(&options.OverwriteCRD, "overwrite", "o", false, "overwrites the Release CRD YAML file if it exists")
 	cmd.Flags().BoolVarP(&options.GenerateCRD, "crd", "c", false, "Generate the CRD in the chart")
 	cmd.Flags().BoolVarP(&options.GenerateReleaseYaml, "generate-yaml", "y", true, "Generate the Release YAML in the local helm chart")
@@ -205,6 +223,10 @@ func (o *StepChangelogOptions) Run() error {
 		o.BatchMode = true
 	}
 
+	if o.ChangelogFile != "" && o.Version == "" {
+		return util.MissingOption("version")
+	}
+
 	dir := o.Dir
 	var err error
 	if dir == "" {
@@ -305,6 +327,7 @@ func (o *StepChangelogOptions) Run() error {
 	}
 
 	gitKind, err := o.GitServerKind(gitInfo)
+	o.State.GitKind = gitKind
 	foundGitProvider := true
 	ghOwner, err := o.GetGitHubAppOwner(gitInfo)
 	if err != nil {
@@ -390,7 +413,7 @@ func (o *StepChangelogOptions) Run() error {
 	release.Spec.DependencyUpdates = CollapseDependencyUpdates(release.Spec.DependencyUpdates)
 
 	// lets try to update the release
-	markdown, err := gits.GenerateMarkdown(&release.Spec, gitInfo)
+	markdown, err := o.generateMarkdown(&release.Spec, gitInfo)
 	if err != nil {
 		return err
 	}
@@ -499,15 +522,22 @@ func (o *StepChangelogOptions) Run() error {
 			log.Logger().Infof("Uploaded %s to release asset %s", dependencymatrix.DependencyUpdatesAssetName, releaseAsset.BrowserDownloadURL)
 		}
 
-	} else if o.OutputMarkdownFile != "" {
+	} else if o.OutputMarkdownFile == "" {
+		log.Logger().Infof("\nGenerated Changelog:")
+		log.Logger().Infof("%s\n", markdown)
+	}
+	if o.OutputMarkdownFile != "" {
 		err := ioutil.WriteFile(o.OutputMarkdownFile, []byte(markdown), util.DefaultWritePermissions)
 		if err != nil {
 			return err
 		}
 		log.Logger().Infof("\nGenerated Changelog: %s", util.ColorInfo(o.OutputMarkdownFile))
-	} else {
-		log.Logger().Infof("\nGenerated Changelog:")
-		log.Logger().Infof("%s\n", markdown)
+	}
+	if o.ChangelogFile != "" {
+		err = o.updateChangelogFile(dir, version, markdown)
+		if err != nil {
+			return err
+		}
 	}
 
 	o.State.Release = release
@@ -620,13 +650,15 @@ func (o *StepChangelogOptions) Run() error {
 }
 
 func (o *StepChangelogOptions) addCommit(spec *v1.ReleaseSpec, commit *object.Commit, resolver *users.GitUserResolver) {
-	// TODO
-	url := ""
 	branch := "master"
 
 	var author, committer *v1.User
 	var err error
 	sha := commit.Hash.String()
+	url := ""
+	if o.State.GitInfo != nil {
+		url = gits.CommitURL(o.State.GitInfo, o.State.GitKind, sha)
+	}
 	if commit.Author.Email != "" && commit.Author.Name != "" {
 		author, err = resolver.GitSignatureAsUser(&commit.Author)
 		if err != nil {
@@ -714,10 +746,18 @@ func (o *StepChangelogOptions) addIssuesAndPullRequests(spec *v1.ReleaseSpec, co
 				issue, err := tracker.GetIssue(result)
 				if err != nil {
 					log.Logger().Warnf("Failed to lookup issue %s in issue tracker %s due to %s", result, tracker.HomeURL(), err)
-					continue
+				} else if issue == nil {
+					log.Logger().Warnf("Failed to find issue %s for repository %s", result, tracker.HomeURL())
 				}
 				if issue == nil {
-					log.Logger().Warnf("Failed to find issue %s for repository %s", result, tracker.HomeURL())
+					if issueKind != issues.Jira && o.State.GitInfo != nil {
+						// lets still link to the issue on the git provider
+						commit.IssueIDs = append(commit.IssueIDs, result)
+						spec.Issues = append(spec.Issues, v1.IssueSummary{
+							ID:  result,
+							URL: gits.IssueURL(o.State.GitInfo, o.State.GitKind, result),
+						})
+					}
 					continue
 				}
 
@@ -816,6 +856,72 @@ func fullCommitMessageText(commit *object.Commit) string {
 
 }
 
+// generateMarkdown generates the release notes of the release with the template if one is specified
+func (o *StepChangelogOptions) generateMarkdown(releaseSpec *v1.ReleaseSpec, gitInfo *gits.GitRepository) (string, error) {
+	templateText := o.Template
+	if templateText == "" && o.TemplateFile != "" {
+		data, err := ioutil.ReadFile(o.TemplateFile)
+		if err != nil {
+			return "", errors.Wrapf(err, "reading the release notes template %s", o.TemplateFile)
+		}
+		templateText = string(data)
+	}
+	if templateText == "" {
+		return gits.GenerateMarkdown(releaseSpec, gitInfo)
+	}
+	return gits.RenderChangelog(templateText, gits.NewChangelog(releaseSpec))
+}
+
+// updateChangelogFile prepends the release notes of the version to the changelog file and commits it
+func (o *StepChangelogOptions) updateChangelogFile(dir string, version string, markdown string) error {
+	file := o.ChangelogFile
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(dir, file)
+	}
+	existing := ""
+	exists, err := util.FileExists(file)
+	if err != nil {
+		return errors.Wrapf(err, "checking if %s exists", file)
+	}
+	if exists {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return errors.Wrapf(err, "reading %s", file)
+		}
+		existing = string(data)
+	}
+	existing = strings.TrimPrefix(existing, changelogTitle)
+
+	notes := strings.TrimSpace(strings.TrimPrefix(markdown, "## Changes\n"))
+	section := fmt.Sprintf("## %s (%s)\n\n%s\n\n", version, time.Now().Format("2006-01-02"), notes)
+	err = ioutil.WriteFile(file, []byte(changelogTitle+section+existing), util.DefaultFileWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "writing %s", file)
+	}
+
+	err = o.Git().Add(dir, file)
+	if err != nil {
+		return err
+	}
+	err = o.Git().CommitIfChanges(dir, fmt.Sprintf("chore: update the changelog for %s", version))
+	if err != nil {
+		return errors.Wrapf(err, "committing %s", file)
+	}
+	log.Logger().Infof("Updated the changelog %s", util.ColorInfo(file))
+	if !o.PushChangelog {
+		return nil
+	}
+	branch, err := o.Git().Branch(dir)
+	if err != nil || branch == "" || branch == "HEAD" {
+		branch = "master"
+	}
+	err = o.Git().Push(dir, "origin", false, "HEAD:"+branch)
+	if err != nil {
+		return errors.Wrapf(err, "pushing the changelog to branch %s", branch)
+	}
+	return nil
+}
+
 func (o *StepChangelogOptions) getTemplateResult(releaseSpec *v1.ReleaseSpec, templateName string, templateText string, templateFile string) (string, error) {
 	if templateText == "" {
 		if templateFile == "" {
@@ -0,0 +1,213 @@
+package step
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/gits/releases"
+	"github.com/jenkins-x/jx/v2/pkg/sbom"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// StepSbomOptions contains the command line flags
+type StepSbomOptions struct {
+	step.StepOptions
+
+	Images         []string
+	Format         string
+	Version        string
+	Dir            string
+	OutputDir      string
+	NoPush         bool
+	NoReleaseAsset bool
+
+	// SBOM generates and publishes the SBOMs, defaults to the syft and cosign binaries
+	SBOM sbom.Interface
+}
+
+var (
+	stepSbomLong = templates.LongDesc(`
+		Generates the Software Bill of Materials (SBOM) of the images built by a release.
+
+		The SBOMs are generated in the SPDX or CycloneDX JSON format by syft, pushed alongside the images to their registry as OCI artifacts by cosign and attached to the git release of the version.
+
+		If no images are specified the image of the application is used: $DOCKER_REGISTRY/$DOCKER_REGISTRY_ORG/$APP_NAME:$VERSION
+
+		The SBOMs pushed alongside the images can be retrieved via 'jx get sbom'
+`)
+
+	stepSbomExample = templates.Examples(`
+		# generates the SBOM of the image of the release, pushes it to the registry and attaches it to the git release
+		jx step sbom --version 1.2.3
+
+		# generates the CycloneDX SBOMs of some images without pushing them
+		jx step sbom --image gcr.io/myorg/myapp:1.2.3 --image gcr.io/myorg/myapp-worker:1.2.3 --format cyclonedx-json --no-push --no-release-asset
+`)
+)
+
+// NewCmdStepSbom creates the command
+func NewCmdStepSbom(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := StepSbomOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "sbom",
+		Short:   "Generates the Software Bill of Materials (SBOM) of the images of a release",
+		Long:    stepSbomLong,
+		Example: stepSbomExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringArrayVarP(&options.Images, "image", "i", nil, "The images to generate the SBOMs of. Defaults to the image of the application")
+	cmd.Flags().StringVarP(&options.Format, "format", "f", sbom.FormatSPDX, fmt.Sprintf("The format of the SBOMs. Possible values: %s", util.ColorInfo(sbom.Formats)))
+	cmd.Flags().StringVarP(&options.Version, "version", "v", "", "The version of the release. Defaults to $VERSION")
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", "", "The directory of the git repository of the application")
+	cmd.Flags().StringVarP(&options.OutputDir, "output-dir", "o", "", "The directory to generate the SBOM files in. Defaults to the directory of the git repository")
+	cmd.Flags().BoolVarP(&options.NoPush, "no-push", "", false, "Disables pushing the SBOMs alongside the images to their registry")
+	cmd.Flags().BoolVarP(&options.NoReleaseAsset, "no-release-asset", "", false, "Disables attaching the SBOMs to the git release")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepSbomOptions) Run() error {
+	err := sbom.ValidateFormat(o.Format)
+	if err != nil {
+		return err
+	}
+	if o.SBOM == nil {
+		o.SBOM = sbom.NewCLI()
+	}
+	version := o.Version
+	if version == "" {
+		version = os.Getenv("VERSION")
+	}
+	dir := o.Dir
+	if dir == "" {
+		dir = "."
+	}
+
+	var gitInfo *gits.GitRepository
+	images := o.Images
+	if len(images) == 0 || !o.NoReleaseAsset {
+		gitInfo, err = o.FindGitInfo(dir)
+		if err != nil {
+			if len(images) == 0 {
+				return errors.Wrapf(err, "finding the git repository of the application in %s", dir)
+			}
+			log.Logger().Warnf("Cannot attach the SBOMs to the git release: %s", err)
+		}
+	}
+	if len(images) == 0 {
+		if version == "" {
+			return util.MissingOption("version")
+		}
+		image, err := o.applicationImage(dir, gitInfo, version)
+		if err != nil {
+			return err
+		}
+		images = []string{image}
+	}
+
+	outDir := o.OutputDir
+	if outDir == "" {
+		outDir = dir
+	}
+	err = os.MkdirAll(outDir, util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "creating directory %s", outDir)
+	}
+
+	files := []string{}
+	for _, image := range images {
+		file := filepath.Join(outDir, sbom.FileName(image, o.Format))
+		err = o.SBOM.Generate(image, o.Format, file)
+		if err != nil {
+			return err
+		}
+		log.Logger().Infof("Generated the SBOM of image %s to %s", util.ColorInfo(image), util.ColorInfo(file))
+		files = append(files, file)
+
+		if !o.NoPush {
+			err = o.SBOM.Attach(image, o.Format, file)
+			if err != nil {
+				return err
+			}
+			log.Logger().Infof("Pushed the SBOM alongside image %s", util.ColorInfo(image))
+		}
+	}
+
+	if o.NoReleaseAsset || gitInfo == nil {
+		return nil
+	}
+	if version == "" {
+		log.Logger().Warnf("Cannot attach the SBOMs to the git release as no version is specified")
+		return nil
+	}
+	return o.uploadReleaseAssets(gitInfo, version, files)
+}
+
+// applicationImage returns the image of the version of the application of the git repository
+func (o *StepSbomOptions) applicationImage(dir string, gitInfo *gits.GitRepository, version string) (string, error) {
+	projectConfig, _, err := config.LoadProjectConfig(dir)
+	if err != nil {
+		return "", errors.Wrapf(err, "loading the project configuration in %s", dir)
+	}
+	app := os.Getenv("APP_NAME")
+	if app == "" {
+		app = gitInfo.Name
+	}
+	image := fmt.Sprintf("%s/%s:%s", o.GetDockerRegistryOrg(projectConfig, gitInfo), app, version)
+	registry := o.GetDockerRegistry(projectConfig)
+	if registry != "" {
+		image = registry + "/" + image
+	}
+	return image, nil
+}
+
+// uploadReleaseAssets attaches the SBOM files to the git release of the version
+func (o *StepSbomOptions) uploadReleaseAssets(gitInfo *gits.GitRepository, version string, files []string) error {
+	provider, _, err := o.CreateGitProviderForURLWithoutKind(gitInfo.URL)
+	if err != nil {
+		return errors.Wrapf(err, "creating the git provider for %s", gitInfo.URL)
+	}
+	release, err := releases.GetRelease(version, gitInfo.Organisation, gitInfo.Name, provider)
+	if err != nil {
+		return err
+	}
+	if release == nil {
+		log.Logger().Warnf("Cannot attach the SBOMs as there is no release %s of %s", version, gitInfo.URL)
+		return nil
+	}
+	for _, file := range files {
+		f, err := os.Open(file)
+		// The file will be closed by the release asset uploader
+		if err != nil {
+			return errors.Wrapf(err, "opening %s", file)
+		}
+		name := filepath.Base(file)
+		asset, err := provider.UploadReleaseAsset(gitInfo.Organisation, gitInfo.Name, release.ID, name, f)
+		if err != nil {
+			return errors.Wrapf(err, "uploading %s to release %d of %s/%s", file, release.ID, gitInfo.Organisation, gitInfo.Name)
+		}
+		if asset != nil {
+			log.Logger().Infof("Uploaded %s to release asset %s", name, util.ColorInfo(asset.BrowserDownloadURL))
+		}
+	}
+	return nil
+}
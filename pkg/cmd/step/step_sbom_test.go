@@ -0,0 +1,72 @@
+// +build unit
+
+package step_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/clients/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	stepcmd "github.com/jenkins-x/jx/v2/pkg/cmd/step"
+	"github.com/jenkins-x/jx/v2/pkg/sbom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSBOM struct {
+	generated []string
+	attached  []string
+}
+
+func (f *fakeSBOM) Generate(image string, format string, file string) error {
+	f.generated = append(f.generated, image)
+	return ioutil.WriteFile(file, []byte(`{"bomFormat": "CycloneDX"}`), 0600)
+}
+
+func (f *fakeSBOM) Attach(image string, format string, file string) error {
+	f.attached = append(f.attached, image+"="+filepath.Base(file))
+	return nil
+}
+
+func (f *fakeSBOM) Download(image string) (string, error) {
+	return "", nil
+}
+
+func TestStepSbom(t *testing.T) {
+	outDir, err := ioutil.TempDir("", "test-step-sbom")
+	require.NoError(t, err)
+
+	images := []string{"gcr.io/myorg/myapp:1.2.3", "gcr.io/myorg/myapp-worker:1.2.3"}
+	commonOpts := opts.NewCommonOptionsWithFactory(fake.NewFakeFactory())
+	generator := &fakeSBOM{}
+	o := &stepcmd.StepSbomOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: &commonOpts,
+		},
+		Images:         images,
+		Format:         sbom.FormatCycloneDX,
+		OutputDir:      outDir,
+		NoReleaseAsset: true,
+		SBOM:           generator,
+	}
+	err = o.Run()
+	require.NoError(t, err)
+
+	assert.Equal(t, images, generator.generated)
+	assert.Equal(t, []string{"gcr.io/myorg/myapp:1.2.3=myapp-1.2.3.cdx.json", "gcr.io/myorg/myapp-worker:1.2.3=myapp-worker-1.2.3.cdx.json"}, generator.attached)
+	assert.FileExists(t, filepath.Join(outDir, "myapp-1.2.3.cdx.json"))
+
+	generator = &fakeSBOM{}
+	o.SBOM = generator
+	o.NoPush = true
+	err = o.Run()
+	require.NoError(t, err)
+	assert.Len(t, generator.generated, 2)
+	assert.Empty(t, generator.attached, "the SBOMs should not be pushed")
+
+	o.Format = "xml"
+	assert.Error(t, o.Run())
+}
@@ -5,7 +5,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/jenkins-x/jx/v2/pkg/artifacts"
 	"github.com/jenkins-x/jx/v2/pkg/builds"
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
@@ -38,6 +40,7 @@ type StepStashOptions struct {
 	StorageLocation jenkinsv1.StorageLocation
 	ProjectGitURL   string
 	ProjectBranch   string
+	Retention       time.Duration
 }
 
 const (
@@ -47,7 +50,8 @@ const (
 	StorageSupportDescription = `
 Currently Jenkins X supports storing files into a branch of a git repository or in cloud blob storage like S3, GCS, Azure blobs etc.
 
-When using Cloud Storage we use URLs like 's3://nameOfBucket' on AWS, 'gs://anotherBucket' on GCP or on Azure 'azblob://thatBucket'
+When using Cloud Storage we use URLs like 's3://nameOfBucket' on AWS, 'gs://anotherBucket' on GCP or on Azure 'azblob://thatBucket'.
+S3 compatible storage such as MinIO can be used with URLs like 's3://thatBucket?endpoint=minio.jx.svc:9000&disableSSL=true&s3ForcePathStyle=true'
 `
 )
 
@@ -75,6 +79,9 @@ var (
 		# lets collect some files to a specific cloud storage bucket and specify the path to store them inside
 		jx step stash -c tests -p "target/test-reports/*" --bucket-url gs://my-gcp-bucket --to-path tests/mystuff
 
+		# lets collect some files to a cloud storage bucket removed by 'jx gc artifacts' after 30 days
+		jx step stash -c coverage -p "build/coverage/*" --bucket-url gs://my-gcp-bucket --retention 720h
+
 `)
 )
 
@@ -107,6 +114,7 @@ func NewCmdStepStash(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.Basedir, "basedir", "", "", "The base directory to use to create relative output file names. e.g. if you specify '--pattern \"target/*.xml\" then you may want to supply '--basedir target' to strip the 'target/' prefix from all collected files")
 	cmd.Flags().StringVarP(&options.ProjectGitURL, "project-git-url", "", "", "The project git URL to collect for. Used to default the organisation and repository folders in the storage. If not specified its discovered from the local '.git' folder")
 	cmd.Flags().StringVarP(&options.ProjectBranch, "project-branch", "", "", "The project git branch of the project to collect for. Used to default the branch folder in the storage. If not specified its discovered from the local '.git' folder")
+	cmd.Flags().DurationVarP(&options.Retention, "retention", "", 0, "How long the files are kept in cloud storage before 'jx gc artifacts' removes them. Defaults to keeping them forever")
 	return cmd
 }
 
@@ -224,6 +232,26 @@ func (o *StepStashOptions) Run() error {
 		log.Logger().Infof("stashed: %s", util.ColorInfo(u))
 	}
 
+	if o.Retention > 0 {
+		if o.StorageLocation.BucketURL == "" {
+			log.Logger().Warnf("ignoring the retention of %s as the files are not stashed in cloud storage", o.Retention.String())
+		} else {
+			manifest := artifacts.NewManifest(classifier, urls, o.Retention, time.Now())
+			manifest.Owner = projectOrg
+			manifest.Repository = projectRepoName
+			manifest.Branch = projectBranchName
+			manifest.Build = buildNo
+			data, err := artifacts.MarshalManifest(manifest)
+			if err != nil {
+				return err
+			}
+			_, err = coll.CollectData(data, filepath.Join(storagePath, artifacts.ManifestFileName))
+			if err != nil {
+				return errors.Wrapf(err, "failed to store the retention of the files stashed to path %s", storagePath)
+			}
+		}
+	}
+
 	// TODO this pipeline name construction needs moving to a shared lib, and other things refactoring to use it
 	pipeline := fmt.Sprintf("%s-%s-%s-%s", projectOrg, projectRepoName, projectBranchName, buildNo)
 
@@ -14,7 +14,9 @@ import (
 
 	"github.com/jenkins-x/jx/v2/pkg/versionstream"
 
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/buildpacks"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
@@ -43,6 +45,7 @@ type StepSyntaxEffectiveOptions struct {
 	DefaultImage      string
 	UseKaniko         bool
 	KanikoImage       string
+	BuildEngine       string
 	ProjectID         string
 	DockerRegistry    string
 	DockerRegistryOrg string
@@ -55,8 +58,16 @@ type StepSyntaxEffectiveOptions struct {
 
 	PodTemplates map[string]*corev1.Pod
 
+	// TeamBuildEngine the default build engine of the team
+	TeamBuildEngine *syntax.BuildEngine
+	// BuildCache the default layer cache of the builds of the installation
+	BuildCache *syntax.BuildCache
+
 	GitInfo         *gits.GitRepository
 	VersionResolver *versionstream.VersionResolver
+
+	// module the module of a monorepo the pipelines build
+	module *config.ModuleConfig
 }
 
 var (
@@ -115,6 +126,7 @@ func (o *StepSyntaxEffectiveOptions) addFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVarP(&o.UseKaniko, "use-kaniko", "", true, "Enables using kaniko directly for building docker images")
 	cmd.Flags().BoolVarP(&o.ShortView, "short", "s", false, "Use short concise output")
 	cmd.Flags().StringVarP(&o.KanikoImage, "kaniko-image", "", syntax.KanikoDockerImage, "The docker image for Kaniko")
+	cmd.Flags().StringVarP(&o.BuildEngine, "build-engine", "", "", fmt.Sprintf("The engine building the images which overrides the build engine of the pipeline and team. Possible values: %s", strings.Join(syntax.BuildEngineKinds, ", ")))
 	cmd.Flags().StringVarP(&o.ProjectID, "project-id", "", "", "The cloud project ID. If not specified we default to the install project")
 	cmd.Flags().StringVarP(&o.DockerRegistry, "docker-registry", "", "", "The Docker Registry host name to use which is added as a prefix to docker images")
 	cmd.Flags().StringVarP(&o.DockerRegistryOrg, "docker-registry-org", "", "", "The Docker registry organisation. If blank the git repository owner is used")
@@ -127,11 +139,16 @@ func (o *StepSyntaxEffectiveOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	o.TeamBuildEngine = TeamBuildEngine(settings)
 
 	kubeClient, ns, err := o.KubeClientAndDevNamespace()
 	if err != nil {
 		return errors.Wrap(err, "unable to create Kube client")
 	}
+	o.BuildCache, err = tekton.LoadBuildCache(kubeClient, ns)
+	if err != nil {
+		return err
+	}
 
 	if o.ProjectID == "" {
 		if !o.RemoteCluster {
@@ -188,6 +205,7 @@ func (o *StepSyntaxEffectiveOptions) Run() error {
 	if err != nil {
 		return errors.Wrapf(err, "failed to load project config in dir %s", workingDir)
 	}
+	pinnedRepository := o.BuildPackURL != "" || projectConfig.BuildPackGitURL != ""
 	if o.BuildPackURL == "" || o.BuildPackRef == "" {
 		if projectConfig.BuildPackGitURL != "" {
 			o.BuildPackURL = projectConfig.BuildPackGitURL
@@ -226,7 +244,14 @@ func (o *StepSyntaxEffectiveOptions) Run() error {
 		return err
 	}
 
-	packsDir, err := gitresolver.InitBuildPack(o.Git(), o.BuildPackURL, o.BuildPackRef)
+	repositories := []buildpacks.Repository{{URL: o.BuildPackURL, Ref: o.BuildPackRef}}
+	if !pinnedRepository {
+		repositories, err = o.BuildPackRepositories(settings)
+		if err != nil {
+			return err
+		}
+	}
+	packsDir, err := o.InitBuildPackOfPack(repositories, o.Pack)
 	if err != nil {
 		return err
 	}
@@ -293,6 +318,7 @@ func (o *StepSyntaxEffectiveOptions) Run() error {
 func (o *StepSyntaxEffectiveOptions) CreateEffectivePipeline(packsDir string, projectConfig *config.ProjectConfig, projectConfigFile string, resolver jenkinsfile.ImportFileResolver) (*config.ProjectConfig, error) {
 	name := o.Pack
 	packDir := filepath.Join(packsDir, name)
+	o.module = projectConfig.Module
 
 	pipelineConfig := projectConfig.PipelineConfig
 	if name != "none" {
@@ -325,10 +351,20 @@ func (o *StepSyntaxEffectiveOptions) CreateEffectivePipeline(packsDir string, pr
 		return nil, fmt.Errorf("failed to find PipelineConfig in file %s", projectConfigFile)
 	}
 
-	err := o.combineEnvVars(pipelineConfig)
+	buildEngine, err := o.effectiveBuildEngine(pipelineConfig)
+	if err != nil {
+		return nil, err
+	}
+	pipelineConfig.BuildEngine = buildEngine
+
+	err = o.combineEnvVars(pipelineConfig)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to combine env vars")
 	}
+	if o.module != nil {
+		// lets name the previews and releases after the application of the module
+		pipelineConfig.Env = syntax.CombineEnv(pipelineConfig.Env, []corev1.EnvVar{{Name: "APP_NAME", Value: o.module.Name}})
+	}
 
 	pipelines := pipelineConfig.Pipelines
 	// First, handle release.
@@ -384,6 +420,41 @@ func (o *StepSyntaxEffectiveOptions) CreateEffectivePipeline(packsDir string, pr
 	return projectConfig, nil
 }
 
+// TeamBuildEngine returns the default build engine in the requirements of the team or nil if there is none
+func TeamBuildEngine(settings *v1.TeamSettings) *syntax.BuildEngine {
+	requirements, err := config.GetRequirementsConfigFromTeamSettings(settings)
+	if err != nil {
+		log.Logger().Warnf("failed to get the requirements from team settings: %s", err.Error())
+		return nil
+	}
+	if requirements == nil {
+		return nil
+	}
+	return requirements.BuildEngine
+}
+
+// effectiveBuildEngine returns the build engine of the pipeline or build pack defaulted from the build engine of the
+// team, with the kind overridden by --build-engine and the cache defaulted from the build cache of the installation
+func (o *StepSyntaxEffectiveOptions) effectiveBuildEngine(pipelineConfig *jenkinsfile.PipelineConfig) (*syntax.BuildEngine, error) {
+	engine := syntax.MergeBuildEngines(o.TeamBuildEngine, pipelineConfig.BuildEngine)
+	if o.BuildEngine != "" {
+		engine = syntax.MergeBuildEngines(engine, &syntax.BuildEngine{Kind: syntax.BuildEngineKind(o.BuildEngine)})
+	}
+	if o.BuildCache != nil && (engine == nil || engine.Cache == nil) {
+		if o.BuildCache.IsBucket() && !engine.SupportsBucketCache() {
+			log.Logger().Warnf("the %s build engine does not support the %s build cache of the installation so it is not used",
+				engine.GetKind(), o.BuildCache.GetType())
+		} else {
+			engine = syntax.MergeBuildEngines(&syntax.BuildEngine{Cache: o.BuildCache}, engine)
+		}
+	}
+	err := engine.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid build engine")
+	}
+	return engine, nil
+}
+
 func (o *StepSyntaxEffectiveOptions) createPipelineForKind(kind string, lifecycles *jenkinsfile.PipelineLifecycles, pipelines jenkinsfile.Pipelines, projectConfig *config.ProjectConfig, pipelineConfig *jenkinsfile.PipelineConfig) (*syntax.ParsedPipeline, error) {
 	var parsed *syntax.ParsedPipeline
 	var err error
@@ -410,7 +481,7 @@ func (o *StepSyntaxEffectiveOptions) createPipelineForKind(kind string, lifecycl
 			DefaultImage:      o.DefaultImage,
 			WorkspaceDir:      o.getWorkspaceDir(),
 			GitHost:           o.GitInfo.Host,
-			GitName:           o.GitInfo.Name,
+			GitName:           o.getGitName(),
 			GitOrg:            o.GitInfo.Organisation,
 			ProjectID:         o.ProjectID,
 			DockerRegistry:    o.getDockerRegistry(projectConfig),
@@ -430,7 +501,7 @@ func (o *StepSyntaxEffectiveOptions) createPipelineForKind(kind string, lifecycl
 	// Replace placeholders in directories.
 	replacePlaceholderArgs := syntax.StepPlaceholderReplacementArgs{
 		WorkspaceDir:      o.getWorkspaceDir(),
-		GitName:           o.GitInfo.Name,
+		GitName:           o.getGitName(),
 		GitOrg:            o.GitInfo.Organisation,
 		GitHost:           o.GitInfo.Host,
 		ProjectID:         o.ProjectID,
@@ -438,6 +509,7 @@ func (o *StepSyntaxEffectiveOptions) createPipelineForKind(kind string, lifecycl
 		DockerRegistryOrg: o.GetDockerRegistryOrg(projectConfig, o.GitInfo),
 		KanikoImage:       o.KanikoImage,
 		UseKaniko:         o.UseKaniko,
+		BuildEngine:       pipelineConfig.BuildEngine,
 	}
 	parsed.ReplacePlaceholdersInStepAndStageDirs(replacePlaceholderArgs)
 	parsed.AddContainerEnvVarsToPipeline(pipelineConfig.Env)
@@ -453,6 +525,15 @@ func (o *StepSyntaxEffectiveOptions) createPipelineForKind(kind string, lifecycl
 		parsed.Options.ContainerOptions = mergedContainer
 	}
 
+	if pipelineConfig.Checkout != nil {
+		if parsed.Options == nil {
+			parsed.Options = &syntax.RootOptions{}
+		}
+		if parsed.Options.Checkout == nil {
+			parsed.Options.Checkout = pipelineConfig.Checkout.DeepCopy()
+		}
+	}
+
 	for _, override := range pipelines.Overrides {
 		if override.MatchesPipeline(kind) {
 			parsed = syntax.ApplyNonStepOverridesToPipeline(parsed, override)
@@ -506,9 +587,20 @@ func (o *StepSyntaxEffectiveOptions) combineEnvVars(projectConfig *jenkinsfile.P
 }
 
 func (o *StepSyntaxEffectiveOptions) getWorkspaceDir() string {
+	if o.module != nil {
+		return filepath.Join("/workspace", o.SourceName, o.module.Dir)
+	}
 	return filepath.Join("/workspace", o.SourceName)
 }
 
+// getGitName returns the name of the application of the module of a monorepo or of the repository
+func (o *StepSyntaxEffectiveOptions) getGitName() string {
+	if o.module != nil && o.module.Name != "" {
+		return o.module.Name
+	}
+	return o.GitInfo.Name
+}
+
 func (o *StepSyntaxEffectiveOptions) getDockerRegistry(projectConfig *config.ProjectConfig) string {
 	dockerRegistry := o.DockerRegistry
 	if dockerRegistry == "" {
@@ -31,9 +31,7 @@ func (o *StepVerifyOptions) createSecret(ns string, name, key, data string) erro
 		return err
 	}
 
-	err = kube.EnsureNamespaceCreated(kubeClient, ns, map[string]string{
-		kube.LabelCreatedBy: "jx-boot",
-	}, nil)
+	err = kube.EnsureNamespaceCreated(kubeClient, ns, nil, nil)
 	if err != nil {
 		return err
 	}
@@ -21,6 +21,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cloud/buckets"
 	"github.com/jenkins-x/jx/v2/pkg/cloud/factory"
 	"github.com/jenkins-x/jx/v2/pkg/cloud/gke"
+	"github.com/jenkins-x/jx/v2/pkg/cloud/workloadidentity"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/create"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/namespace"
@@ -28,15 +29,18 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
 	"github.com/jenkins-x/jx/v2/pkg/config"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
 	"github.com/jenkins-x/jx/v2/pkg/io/secrets"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/jenkins-x/jx/v2/pkg/kube/cluster"
 	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
 	"github.com/jenkins-x/jx/v2/pkg/packages"
+	"github.com/jenkins-x/jx/v2/pkg/tekton"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
@@ -205,7 +209,15 @@ func (o *StepVerifyPreInstallOptions) Run() error {
 			return err
 		}
 	}
-	if requirements.Kaniko {
+	if requirements.Kaniko && requirements.Cluster.WorkloadIdentity {
+		log.Logger().Infof("Validating the workload identity of the pipelines in namespace %s", info(ns))
+
+		err = o.verifyPipelineWorkloadIdentity(kubeClient, requirements, ns)
+		if err != nil {
+			return err
+		}
+		log.Logger().Info("\n")
+	} else if requirements.Kaniko {
 		if requirements.Cluster.Provider == cloud.GKE {
 			log.Logger().Infof("Validating Kaniko secret in namespace %s", info(ns))
 
@@ -381,6 +393,39 @@ func (o *StepVerifyPreInstallOptions) lazyCreateKanikoSecret(requirements *confi
 	return o.createSecret(ns, kube.SecretKaniko, kube.SecretKaniko, data)
 }
 
+// verifyPipelineWorkloadIdentity verifies the pipeline service account is federated with a cloud identity, lazily
+// federating it if required, and removes the kaniko secret holding a long-lived key
+func (o *StepVerifyPreInstallOptions) verifyPipelineWorkloadIdentity(kubeClient kubernetes.Interface, requirements *config.RequirementsConfig, ns string) error {
+	if !workloadidentity.Supported(requirements.Cluster.Provider) {
+		return fmt.Errorf("workload identity is not supported for provider %s", requirements.Cluster.Provider)
+	}
+	federated, err := workloadidentity.IsServiceAccountFederated(kubeClient, ns, tekton.DefaultPipelineSA)
+	if err != nil {
+		return err
+	}
+	if !federated {
+		if !o.LazyCreate {
+			return fmt.Errorf("the service account %s in namespace %s is not federated with a cloud identity", tekton.DefaultPipelineSA, ns)
+		}
+		log.Logger().Infof("attempting to lazily federate the service account %s", util.ColorInfo(tekton.DefaultPipelineSA))
+		binding, err := workloadidentity.Federate(requirements, ns, tekton.DefaultPipelineSA)
+		if err != nil {
+			return errors.Wrapf(err, "failed to lazily federate the service account %s in: %s", tekton.DefaultPipelineSA, ns)
+		}
+		err = binding.Apply(kubeClient)
+		if err != nil {
+			return err
+		}
+	}
+	err = kubeClient.CoreV1().Secrets(ns).Delete(kube.SecretKaniko, &metav1.DeleteOptions{})
+	if err == nil {
+		log.Logger().Infof("Removed the secret %s as the pipelines no longer need a key", util.ColorInfo(kube.SecretKaniko))
+	} else if !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "deleting the secret %s in namespace %s", kube.SecretKaniko, ns)
+	}
+	return nil
+}
+
 func (o *StepVerifyPreInstallOptions) lazyCreateVeleroSecret(requirements *config.RequirementsConfig, ns string) error {
 	log.Logger().Debugf("Lazily creating the velero secret")
 	var data string
@@ -971,6 +1016,9 @@ func (o *StepVerifyPreInstallOptions) ValidateRequirements(requirements *config.
 			return fmt.Errorf("invalid requirements in file %s cannot use prow as a webhook for git kind: %s server: %s. Please try using lighthouse instead", fileName, kind, server)
 		}
 	}
+	if requirements.Repository == config.RepositoryTypeArtifactory && requirements.RepositoryURL == "" {
+		return fmt.Errorf("invalid requirements in file %s the repository URL of the existing Artifactory must be specified via repositoryUrl", fileName)
+	}
 	if requirements.Repository == config.RepositoryTypeBucketRepo && requirements.Cluster.ChartRepository == "" {
 		requirements.Cluster.ChartRepository = "http://bucketrepo/bucketrepo/charts/"
 		err := o.SaveConfig(requirements, fileName)
@@ -978,6 +1026,15 @@ func (o *StepVerifyPreInstallOptions) ValidateRequirements(requirements *config.
 			return errors.Wrapf(err, "failed to save changes to file: %s", fileName)
 		}
 	}
+	if helm.ChartRepositoryKind(requirements.Cluster.ChartRepository) != helm.ChartRepositoryKindChartMuseum && requirements.Repository == config.RepositoryTypeUnknown {
+		// the charts are stored in a bucket or registry so there is no need to run chartmuseum
+		log.Logger().Infof("Using the chart repository %s so not installing chartmuseum", util.ColorInfo(requirements.Cluster.ChartRepository))
+		requirements.Repository = config.RepositoryTypeNone
+		err := o.SaveConfig(requirements, fileName)
+		if err != nil {
+			return errors.Wrapf(err, "failed to save changes to file: %s", fileName)
+		}
+	}
 
 	// lets verify that we have a repository name defined for every environment
 	modified := false
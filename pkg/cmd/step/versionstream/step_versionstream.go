@@ -0,0 +1,40 @@
+package versionstream
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/spf13/cobra"
+)
+
+// StepVersionStreamOptions contains the command line flags
+type StepVersionStreamOptions struct {
+	step.StepOptions
+}
+
+// NewCmdStepVersionStream Steps a command object for the "step" command
+func NewCmdStepVersionStream(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepVersionStreamOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "versionstream",
+		Short: "versionstream [command]",
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.AddCommand(NewCmdStepVersionStreamBundle(commonOpts))
+	return cmd
+}
+
+// Run implements this command
+func (o *StepVersionStreamOptions) Run() error {
+	return o.Cmd.Help()
+}
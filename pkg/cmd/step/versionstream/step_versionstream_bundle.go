@@ -0,0 +1,91 @@
+package versionstream
+
+import (
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/jenkins-x/jx/v2/pkg/versionstream"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// DefaultBundleFileName the default file name of a version stream bundle
+const DefaultBundleFileName = "jx-versions-bundle.tgz"
+
+var (
+	stepVersionStreamBundleLong = templates.LongDesc(`
+		Exports the version stream to a tarball so that it can be used to install Jenkins X without any git access.
+
+		Pass the bundle to 'jx init' or 'jx install' via the --versions-bundle flag.
+`)
+
+	stepVersionStreamBundleExample = templates.Examples(`
+		# exports the default version stream
+		jx step versionstream bundle
+
+		# exports a specific version of the version stream
+		jx step versionstream bundle --versions-ref v1.0.500 -o versions.tgz
+`)
+)
+
+// StepVersionStreamBundleOptions contains the command line flags
+type StepVersionStreamBundleOptions struct {
+	step.StepOptions
+
+	OutputFile         string
+	VersionsRepository string
+	VersionsGitRef     string
+}
+
+// NewCmdStepVersionStreamBundle creates the command
+func NewCmdStepVersionStreamBundle(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepVersionStreamBundleOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "bundle",
+		Short:   "Exports the version stream to a tarball for offline installations",
+		Long:    stepVersionStreamBundleLong,
+		Example: stepVersionStreamBundleExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.OutputFile, "output", "o", DefaultBundleFileName, "The file name of the bundle to create")
+	cmd.Flags().StringVarP(&options.VersionsRepository, "versions-repo", "", "", "Jenkins X versions Git repo")
+	cmd.Flags().StringVarP(&options.VersionsGitRef, "versions-ref", "", "", "Jenkins X versions Git repository reference (tag, branch, sha etc)")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepVersionStreamBundleOptions) Run() error {
+	if o.VersionsBundle != "" {
+		return errors.New("cannot create a bundle from another version stream bundle")
+	}
+	versionsDir, versionsRef, err := o.CloneJXVersionsRepo(o.VersionsRepository, o.VersionsGitRef)
+	if err != nil {
+		return errors.Wrap(err, "cloning the version stream")
+	}
+	if versionsRef == "" {
+		versionsRef = o.VersionsGitRef
+	}
+	metadata, err := o.VersionStreamMetadata(versionsDir, o.VersionsRepository, versionsRef)
+	if err != nil {
+		return err
+	}
+	err = versionstream.CreateBundle(versionsDir, o.OutputFile, metadata)
+	if err != nil {
+		return errors.Wrapf(err, "creating the version stream bundle %s", o.OutputFile)
+	}
+	log.Logger().Infof("created the version stream bundle %s from %s commit %s", util.ColorInfo(o.OutputFile), util.ColorInfo(metadata.URL), util.ColorInfo(metadata.Commit))
+	return nil
+}
@@ -0,0 +1,178 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/telemetry"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// TelemetryOptions contains the command line options
+type TelemetryOptions struct {
+	*opts.CommonOptions
+
+	// Client the telemetry client, defaults to using the settings in ~/.jx
+	Client *telemetry.Client
+	// Endpoint the endpoint the usage metrics are sent to when opting in
+	Endpoint string
+}
+
+var (
+	telemetryLong = templates.LongDesc(`
+		Opts in or out of sending anonymized usage metrics to a telemetry endpoint.
+
+		Telemetry is off unless you opt in. There is no default endpoint so you must specify the endpoint to send the
+		usage metrics to when opting in, either with the --endpoint option or the JX_TELEMETRY_ENDPOINT environment
+		variable. When on, the name of each command you run, how long it took, the cloud
+		provider and the class of any error are recorded. No arguments, flag values, names, URLs, error messages or
+		other identifying data are recorded. Events which cannot be sent, e.g. when offline, are spooled in ~/.jx and
+		sent by a later command.
+
+		Setting the environment variable JX_TELEMETRY=false disables telemetry regardless of this setting.
+`)
+
+	telemetryExample = templates.Examples(`
+		# opt in to sending anonymized usage metrics
+		jx telemetry on --endpoint https://telemetry.example.com/v1/events
+
+		# opt out and delete any spooled events
+		jx telemetry off
+
+		# show whether telemetry is on and what is recorded
+		jx telemetry status
+`)
+)
+
+// NewCmdTelemetry creates a command object for the "telemetry" command
+func NewCmdTelemetry(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &TelemetryOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "telemetry",
+		Short:   "Opts in or out of sending anonymized usage metrics",
+		Long:    telemetryLong,
+		Example: telemetryExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Status()
+			helper.CheckErr(err)
+		},
+	}
+
+	onCmd := &cobra.Command{
+		Use:   "on",
+		Short: "Opts in to sending anonymized usage metrics",
+		Run: func(cmd *cobra.Command, args []string) {
+			err := options.SetEnabled(true)
+			helper.CheckErr(err)
+		},
+	}
+	onCmd.Flags().StringVarP(&options.Endpoint, "endpoint", "", "", fmt.Sprintf("The endpoint the usage metrics are sent to. Defaults to $%s or the endpoint previously configured", telemetry.EnvTelemetryEndpoint))
+	cmd.AddCommand(onCmd)
+	cmd.AddCommand(&cobra.Command{
+		Use:   "off",
+		Short: "Opts out of sending anonymized usage metrics and deletes any spooled events",
+		Run: func(cmd *cobra.Command, args []string) {
+			err := options.SetEnabled(false)
+			helper.CheckErr(err)
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "status",
+		Short: "Shows whether telemetry is on and what is recorded",
+		Run: func(cmd *cobra.Command, args []string) {
+			err := options.Status()
+			helper.CheckErr(err)
+		},
+	})
+	return cmd
+}
+
+// SetEnabled opts in or out of telemetry
+func (o *TelemetryOptions) SetEnabled(enabled bool) error {
+	client := o.client()
+	settings, err := client.LoadSettings()
+	if err != nil {
+		return errors.Wrap(err, "loading the telemetry settings")
+	}
+	if enabled {
+		if o.Endpoint != "" {
+			settings.Endpoint = o.Endpoint
+		}
+		if telemetry.Endpoint(settings) == "" {
+			return util.MissingOption("endpoint")
+		}
+	}
+	settings.Enabled = enabled
+	settings.NoticeShown = true
+	err = client.SaveSettings(settings)
+	if err != nil {
+		return errors.Wrap(err, "saving the telemetry settings")
+	}
+	if !enabled {
+		err = client.ClearSpool()
+		if err != nil {
+			return errors.Wrap(err, "deleting the spooled telemetry events")
+		}
+		log.Logger().Infof("Telemetry is %s", util.ColorInfo("off"))
+		return nil
+	}
+	log.Logger().Infof("Telemetry is %s and sent to %s, thank you for helping to improve Jenkins X", util.ColorInfo("on"), util.ColorInfo(telemetry.Endpoint(settings)))
+	if telemetry.Disabled() {
+		log.Logger().Warnf("Telemetry is disabled by the environment variable %s", telemetry.EnvTelemetry)
+	}
+	return nil
+}
+
+// Status shows whether telemetry is on and an example of what is recorded
+func (o *TelemetryOptions) Status() error {
+	client := o.client()
+	settings, err := client.LoadSettings()
+	if err != nil {
+		return errors.Wrap(err, "loading the telemetry settings")
+	}
+	state := "off"
+	if client.Enabled(settings) {
+		state = "on"
+	}
+	log.Logger().Infof("Telemetry is %s", util.ColorInfo(state))
+	if settings.Enabled && telemetry.Endpoint(settings) == "" {
+		log.Logger().Warnf("No telemetry endpoint is configured so nothing is sent, run 'jx telemetry on --endpoint <url>' to configure one")
+	}
+	if settings.Enabled && telemetry.Disabled() {
+		log.Logger().Infof("Telemetry is disabled by the environment variable %s", telemetry.EnvTelemetry)
+	}
+	events, err := client.SpooledEvents()
+	if err != nil {
+		return errors.Wrap(err, "loading the spooled telemetry events")
+	}
+	if len(events) > 0 {
+		log.Logger().Infof("%d events are spooled to be sent", len(events))
+	}
+
+	example := telemetry.NewEvent("jx get applications", 1500*time.Millisecond, "gke", nil, "2.1.0")
+	data, err := json.MarshalIndent(example, "", "  ")
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("An example of what is recorded for each command:\n%s", string(data))
+	return nil
+}
+
+func (o *TelemetryOptions) client() *telemetry.Client {
+	if o.Client == nil {
+		o.Client = &telemetry.Client{}
+	}
+	return o.Client
+}
@@ -0,0 +1,63 @@
+// +build unit
+
+package telemetry_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/clients/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	telemetrycmd "github.com/jenkins-x/jx/v2/pkg/cmd/telemetry"
+	"github.com/jenkins-x/jx/v2/pkg/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelemetryOnOff(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-telemetry-cmd-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	commonOpts := opts.NewCommonOptionsWithFactory(fake.NewFakeFactory())
+	client := &telemetry.Client{
+		Dir: dir,
+		Send: func(endpoint string, events []telemetry.Event) error {
+			return errors.New("offline")
+		},
+	}
+	o := &telemetrycmd.TelemetryOptions{
+		CommonOptions: &commonOpts,
+		Client:        client,
+	}
+
+	os.Unsetenv(telemetry.EnvTelemetryEndpoint)
+	err = o.SetEnabled(true)
+	assert.Error(t, err, "opting in requires an endpoint")
+
+	o.Endpoint = "http://telemetry.example.com/events"
+	err = o.SetEnabled(true)
+	require.NoError(t, err)
+	settings, err := client.LoadSettings()
+	require.NoError(t, err)
+	assert.True(t, settings.Enabled)
+	assert.Equal(t, "http://telemetry.example.com/events", settings.Endpoint)
+	assert.True(t, settings.NoticeShown, "the notice should not be shown after opting in")
+
+	err = client.Record(settings, telemetry.NewEvent("jx get env", time.Second, "", nil, "2.1.0"))
+	require.NoError(t, err)
+	err = o.Status()
+	require.NoError(t, err)
+
+	err = o.SetEnabled(false)
+	require.NoError(t, err)
+	settings, err = client.LoadSettings()
+	require.NoError(t, err)
+	assert.False(t, settings.Enabled)
+	events, err := client.SpooledEvents()
+	require.NoError(t, err)
+	assert.Empty(t, events, "opting out should delete the spooled events")
+}
@@ -210,7 +210,7 @@ func (o *UIOptions) decideLocalForwardPort() error {
 			Help:    "The local port that will be used by `kubectl port-forward` to make the UI accessible from your localhost",
 			Default: DefaultForwardPort,
 		}
-		err := survey.AskOne(prompt, &o.LocalPort, nil, surveyOpts)
+		err := util.AskOne(prompt, &o.LocalPort, nil, surveyOpts)
 		if err != nil {
 			return errors.Wrap(err, "there was a problem getting the local port from the user")
 		}
@@ -28,6 +28,8 @@ type UninstallOptions struct {
 	Force            bool // Force uninstallation - programmatic use only - do not expose to the user
 	KeepEnvironments bool
 	KeepNamespaces   bool
+
+	OverrideProtected bool
 }
 
 var (
@@ -63,6 +65,7 @@ func NewCmdUninstall(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.Context, "context", "", "", "The kube context to uninstall JX from. This will be compared with the current context to prevent accidental uninstallation from the wrong cluster")
 	cmd.Flags().BoolVarP(&options.KeepEnvironments, "keep-environments", "", false, "Don't delete environments. Uninstall Jenkins X only.")
 	cmd.Flags().BoolVarP(&options.KeepNamespaces, "keep-namespaces", "", false, "Don't delete namespaces.")
+	opts.AddProtectedNamespaceFlag(cmd, &options.OverrideProtected)
 	return cmd
 }
 
@@ -112,6 +115,17 @@ func (o *UninstallOptions) Run() error {
 		}
 	}
 
+	// the releases of the team namespace are always deleted and the environment namespaces are modified unless both
+	// the environments and namespaces are kept
+	modifiedNamespaces := []string{namespace}
+	if !o.KeepNamespaces || !o.KeepEnvironments {
+		modifiedNamespaces = o.getAllNamespaces(namespace, jxClient)
+	}
+	err = o.VerifyProtectedNamespaces("uninstall Jenkins X", o.OverrideProtected, modifiedNamespaces...)
+	if err != nil {
+		return err
+	}
+
 	log.Logger().Infof("Removing installation of Jenkins X in team namespace %s", util.ColorInfo(namespace))
 
 	err = o.setPVCFinalizerToNull(namespace)
@@ -191,7 +205,7 @@ func (o *UninstallOptions) cleanupNamespaces(namespace string, envNames []string
 		return errors.Wrap(err, "getting the kube client")
 	}
 	errs := []error{}
-	err = o.deleteNamespace(namespace)
+	err = o.deleteNamespace(namespace, envMap[kube.LabelValueDevEnvironment])
 	if err != nil {
 		errs = append(errs, fmt.Errorf("deleting namespace %s: %s", namespace, err))
 	}
@@ -212,7 +226,11 @@ func (o *UninstallOptions) cleanupNamespaces(namespace string, envNames []string
 				if err != nil {
 					continue
 				}
-				err = o.deleteNamespace(envNamespace)
+				var owner *v1.Environment
+				if envResource != nil && envResource.Spec.Namespace == envNamespace {
+					owner = envResource
+				}
+				err = o.deleteNamespace(envNamespace, owner)
 				if err != nil {
 					errs = append(errs, fmt.Errorf("deleting environment namespace %s: %s", envNamespace, err))
 				}
@@ -222,21 +240,24 @@ func (o *UninstallOptions) cleanupNamespaces(namespace string, envNames []string
 	return errorutil.CombineErrors(errs...)
 }
 
-func (o *UninstallOptions) deleteNamespace(namespace string) error {
+// deleteNamespace deletes the namespace if it was created by jx or is the namespace of the environment
+func (o *UninstallOptions) deleteNamespace(namespace string, env *v1.Environment) error {
 	client, err := o.KubeClient()
 	if err != nil {
 		return errors.Wrap(err, "getting the kube client")
 	}
-	_, err = client.CoreV1().Namespaces().Get(namespace, meta_v1.GetOptions{})
-	if err != nil {
-		// There is nothing to delete if the namespace cannot be retrieved
-		return nil
+	var deleted bool
+	if env != nil && env.Spec.Namespace == namespace {
+		deleted, err = kube.DeleteEnvironmentNamespaceIfOwned(client, env)
+	} else {
+		deleted, err = kube.DeleteNamespaceIfOwned(client, namespace)
 	}
-	log.Logger().Infof("deleting namespace %s", util.ColorInfo(namespace))
-	err = client.CoreV1().Namespaces().Delete(namespace, &meta_v1.DeleteOptions{})
 	if err != nil {
 		return errors.Wrapf(err, "deleting the namespace '%s' from Kubernetes cluster", namespace)
 	}
+	if deleted {
+		log.Logger().Infof("deleted namespace %s", util.ColorInfo(namespace))
+	}
 	return nil
 }
 
@@ -16,6 +16,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	gits_test "github.com/jenkins-x/jx/v2/pkg/gits/mocks"
 	helm_test "github.com/jenkins-x/jx/v2/pkg/helm/mocks"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
 	kuber_mocks "github.com/jenkins-x/jx/v2/pkg/kube/mocks"
 	"github.com/jenkins-x/jx/v2/pkg/tests"
 	"github.com/stretchr/testify/assert"
@@ -102,6 +103,34 @@ func TestUninstallOptions_Run_ContextSpecifiedAsOption_PassWhenForced(t *testing
 	assert.Error(t, err)
 }
 
+func TestUninstallOptions_Run_KeepsNamespacesNotCreatedByJX(t *testing.T) {
+	setup(t, "correct-context-to-delete")
+	defer tearDown(t)
+
+	o := &uninstall.UninstallOptions{
+		CommonOptions: &opts.CommonOptions{},
+		Namespace:     "ns",
+		Force:         true,
+	}
+	o.SetKube(kubeMock)
+	testhelpers.ConfigureTestOptions(o.CommonOptions, gits_test.NewMockGitter(), helm_test.NewMockHelmer())
+
+	client, err := o.KubeClient()
+	assert.NoError(t, err)
+	_, err = client.CoreV1().Namespaces().Create(&v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "ns",
+		},
+	})
+	assert.NoError(t, err)
+
+	err = o.Run()
+	assert.NoError(t, err)
+
+	_, err = client.CoreV1().Namespaces().Get("ns", metav1.GetOptions{})
+	assert.NoError(t, err, "a namespace not created by jx should not be deleted")
+}
+
 func TestUninstallOptions_Run_ContextSpecifiedViaCli_FailsWhenContextNamesDoNotMatch(t *testing.T) {
 	tests.SkipForWindows(t, "go-expect does not work on windows")
 
@@ -305,7 +334,8 @@ func createNamespace(o *uninstall.UninstallOptions, ns string) error {
 	}
 	_, err = client.CoreV1().Namespaces().Create(&v1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: ns,
+			Name:   ns,
+			Labels: map[string]string{kube.LabelCreatedBy: kube.ValueCreatedByJX},
 		},
 	})
 	return err
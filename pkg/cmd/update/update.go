@@ -20,6 +20,7 @@ var (
 	update_resources = `Valid resource types include:
 
 	* cluster
+	* kubeconfig
 	`
 
 	update_long = templates.LongDesc(`
@@ -47,6 +48,7 @@ func NewCmdUpdate(commonOpts *opts.CommonOptions) *cobra.Command {
 		},
 	}
 
+	cmd.AddCommand(NewCmdUpdateKubeConfig(commonOpts))
 	cmd.AddCommand(NewCmdUpdateWebhooks(commonOpts))
 
 	return cmd
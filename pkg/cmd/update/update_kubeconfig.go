@@ -0,0 +1,139 @@
+package update
+
+import (
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/kube/cluster"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// UpdateKubeConfigOptions the flags for refreshing the certificate authority of a kube context
+type UpdateKubeConfigOptions struct {
+	*opts.CommonOptions
+
+	Context string
+	Fetcher *cluster.CertificateAuthorityFetcher
+}
+
+var (
+	updateKubeConfigLong = templates.LongDesc(`
+		Refreshes the certificate authority of a kube context from the API of the managed Kubernetes provider.
+
+		Managed providers such as GKE, EKS and AKS rotate the certificate authority of their clusters after which
+		commands fail with 'x509: certificate signed by unknown authority'. Rather than recreating the kube context
+		this command fetches the current certificate authority using the gcloud, aws or az command line tools and
+		stores it in the kube config.
+
+`)
+
+	updateKubeConfigExample = templates.Examples(`
+		# refresh the certificate authority of the current kube context
+		jx update kubeconfig
+
+		# refresh the certificate authority of another kube context
+		jx update kubeconfig --context gke_myproject_europe-west1-b_mycluster
+
+`)
+)
+
+// NewCmdUpdateKubeConfig creates the 'jx update kubeconfig' command
+func NewCmdUpdateKubeConfig(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &UpdateKubeConfigOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "kubeconfig",
+		Aliases: []string{"kube-config"},
+		Short:   "Refreshes the certificate authority of a kube context from the managed Kubernetes provider",
+		Long:    updateKubeConfigLong,
+		Example: updateKubeConfigExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Context, "context", "", "", "The kube context to refresh. Defaults to the current context")
+
+	return cmd
+}
+
+// Run runs the command
+func (o *UpdateKubeConfigOptions) Run() error {
+	config, po, err := o.Kube().LoadConfig()
+	if err != nil {
+		return errors.Wrap(err, "loading the kube config")
+	}
+	managedCluster, err := cluster.ParseManagedCluster(config, o.Context)
+	if err != nil {
+		return err
+	}
+	fetcher := o.Fetcher
+	if fetcher == nil {
+		fetcher = cluster.NewCertificateAuthorityFetcher()
+	}
+	data, err := fetcher.Fetch(managedCluster)
+	if err != nil {
+		return errors.Wrapf(err, "fetching the certificate authority of the %s", managedCluster.String())
+	}
+	changed, err := cluster.UpdateCertificateAuthority(config, o.Context, data)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		log.Logger().Infof("The certificate authority of the %s is already up to date", util.ColorInfo(managedCluster.String()))
+		return nil
+	}
+	err = clientcmd.ModifyConfig(po, *config, false)
+	if err != nil {
+		return errors.Wrap(err, "saving the kube config")
+	}
+	log.Logger().Infof("Refreshed the certificate authority of the %s", util.ColorInfo(managedCluster.String()))
+	return nil
+}
+
+// OfferCertificateAuthorityRefresh returns a function which, when a command fails because the kube API server
+// certificate is not signed by the certificate authority in the kube config, offers to refresh the certificate
+// authority of the current kube context from the managed Kubernetes provider
+func OfferCertificateAuthorityRefresh(commonOpts *opts.CommonOptions) func(error) {
+	return func(err error) {
+		if !cluster.IsCertificateAuthorityError(err) {
+			return
+		}
+		config, _, err := commonOpts.Kube().LoadConfig()
+		if err != nil {
+			return
+		}
+		managedCluster, err := cluster.ParseManagedCluster(config, "")
+		if err != nil {
+			return
+		}
+		if commonOpts.BatchMode {
+			log.Logger().Warnf("The certificate authority of the %s may have been rotated, run %s to refresh it",
+				managedCluster.String(), util.ColorInfo("jx update kubeconfig"))
+			return
+		}
+		message := "The certificate authority of the " + managedCluster.String() + " may have been rotated. Refresh it in your kube config?"
+		refresh, err := util.Confirm(message, true, "Fetches the current certificate authority of the cluster from the provider API", commonOpts.GetIOFileHandles())
+		if err != nil || !refresh {
+			return
+		}
+		options := &UpdateKubeConfigOptions{
+			CommonOptions: commonOpts,
+		}
+		err = options.Run()
+		if err != nil {
+			log.Logger().Errorf("failed to refresh the certificate authority: %s", err)
+			return
+		}
+		log.Logger().Info("Please run the command again")
+	}
+}
@@ -74,11 +74,12 @@ func NewCmdUpgradeApps(commonOpts *opts.CommonOptions) *cobra.Command {
 	}
 
 	cmd := &cobra.Command{
-		Use:     "apps",
-		Short:   "Upgrades any Apps to the latest release (an app is similar to an addon)",
-		Aliases: []string{"app"},
-		Long:    upgradeAppsLong,
-		Example: upgradeAppsExample,
+		Use:               "apps",
+		Short:             "Upgrades any Apps to the latest release (an app is similar to an addon)",
+		Aliases:           []string{"app"},
+		Long:              upgradeAppsLong,
+		Example:           upgradeAppsExample,
+		ValidArgsFunction: o.CompleteApps,
 		Run: func(cmd *cobra.Command, args []string) {
 			o.Cmd = cmd
 			o.Args = args
@@ -1,6 +1,10 @@
 package upgrade
 
 import (
+	"fmt"
+	"os"
+	"strings"
+
 	"github.com/blang/semver"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/create/options"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
@@ -12,6 +16,7 @@ import (
 	"github.com/jenkins-x/jx-logging/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/selfupdate"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/jenkins-x/jx/v2/pkg/version"
 	"github.com/spf13/cobra"
@@ -24,19 +29,50 @@ var (
 		The exact version used for the version stream is stored in the Team Settings on the 'dev' Environment CRD.
 
 		For more information on Version Streams see: [https://jenkins-x.io/about/concepts/version-stream/](https://jenkins-x.io/about/concepts/version-stream/)
+
+		If a release channel is specified the latest version on that channel of the release feed is used instead:
+
+		* stable - full releases only
+		* beta - full releases plus beta and release candidate pre-releases
+		* nightly - all releases including nightly builds
+
+		The download is verified against the checksums published with the release and the signature of the checksums is
+		verified with the release key embedded in jx, or the key specified via --public-key or the JX_UPDATE_PUBLIC_KEY
+		environment variable. Releases with a missing or invalid signature are not installed. The binary is then replaced
+		atomically.
+
+		Commands print a notice when a new version is available on the last channel used. The notice can be disabled
+		with 'jx upgrade cli --update-notifier=off' or by setting JX_NO_UPDATE_NOTIFIER=true
 `)
 
 	upgradeCLIExample = templates.Examples(`
 		# Upgrades the Jenkins X CLI tools
 		jx upgrade cli
+
+		# Upgrades to the latest beta release
+		jx upgrade cli --channel beta
+
+		# Upgrades or downgrades to a specific version
+		jx upgrade cli --to-version 2.1.155
+
+		# Disables the new version available notice
+		jx upgrade cli --update-notifier=off
 	`)
 )
 
+const (
+	// EnvUpdatePublicKey environment variable with the base64 encoded ed25519 public key used to verify releases
+	EnvUpdatePublicKey = "JX_UPDATE_PUBLIC_KEY"
+)
+
 // UpgradeCLIOptions the options for the create spring command
 type UpgradeCLIOptions struct {
 	options.CreateOptions
 
-	Version string
+	Version        string
+	Channel        string
+	PublicKey      string
+	UpdateNotifier string
 }
 
 // NewCmdUpgradeCLI defines the command
@@ -61,15 +97,26 @@ func NewCmdUpgradeCLI(commonOpts *opts.CommonOptions) *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVarP(&options.Version, "version", "v", "", "The specific version to upgrade to (requires --no-brew on macOS)")
+	cmd.Flags().StringVarP(&options.Version, "to-version", "", "", "The specific version to upgrade or downgrade to")
+	cmd.Flags().StringVarP(&options.Channel, "channel", "", "", fmt.Sprintf("The release channel to upgrade from instead of the version stream. One of: %s", strings.Join(selfupdate.Channels, ", ")))
+	cmd.Flags().StringVarP(&options.PublicKey, "public-key", "", "", fmt.Sprintf("The base64 encoded ed25519 public key to verify the release signature with. Defaults to $%s or the release key embedded in jx", EnvUpdatePublicKey))
+	cmd.Flags().StringVarP(&options.UpdateNotifier, "update-notifier", "", "", "Enables or disables the new version available notice. One of: on, off")
 	cmd.Flags().BoolVar(&options.CommonOptions.NoBrew, opts.OptionNoBrew, false, "Disables brew package manager on MacOS when installing binary dependencies")
 	return cmd
 }
 
 // Run implements the command
 func (o *UpgradeCLIOptions) Run() error {
+	if o.UpdateNotifier != "" {
+		return o.configureUpdateNotifier()
+	}
 	// upgrading to a specific version is not yet supported in brew so lets disable it for upgrades
 	o.NoBrew = true
-	candidateInstallVersion, err := o.candidateInstallVersion()
+	channel, err := selfupdate.ParseChannel(o.Channel)
+	if err != nil {
+		return err
+	}
+	release, candidateInstallVersion, err := o.candidateInstallVersion(channel)
 	if err != nil {
 		return err
 	}
@@ -81,37 +128,139 @@ func (o *UpgradeCLIOptions) Run() error {
 
 	log.Logger().Debugf("Current version of jx: %s", util.ColorInfo(currentVersion))
 
+	if o.Channel != "" {
+		o.rememberChannel(channel)
+	}
 	if o.needsUpgrade(currentVersion, candidateInstallVersion) {
-		shouldUpgrade, err := o.ShouldUpdate(candidateInstallVersion)
-		if err != nil {
-			return errors.Wrap(err, "failed to determine if we should upgrade")
+		// an explicitly requested version may be a downgrade
+		shouldUpgrade := o.Version != ""
+		if !shouldUpgrade {
+			shouldUpgrade, err = o.ShouldUpdate(candidateInstallVersion)
+			if err != nil {
+				return errors.Wrap(err, "failed to determine if we should upgrade")
+			}
 		}
 		if shouldUpgrade {
-			return o.InstallJx(true, candidateInstallVersion.String())
+			return o.installRelease(release, candidateInstallVersion)
 		}
 	}
 
 	return o.UpgradeBinaryPlugins()
 }
 
-func (o *UpgradeCLIOptions) candidateInstallVersion() (semver.Version, error) {
-	if o.Version == "" {
-		versionResolver, err := o.GetVersionResolver()
+// candidateInstallVersion returns the version to install along with its release if it came from the release feed.
+// The version stream is used unless a version or channel is requested
+func (o *UpgradeCLIOptions) candidateInstallVersion(channel selfupdate.Channel) (*selfupdate.Release, semver.Version, error) {
+	if o.Version != "" {
+		requestedVersion, err := semver.ParseTolerant(o.Version)
+		if err != nil {
+			return nil, semver.Version{}, errors.Wrapf(err, "invalid version requested: %s", o.Version)
+		}
+		return nil, requestedVersion, nil
+	}
+	if o.Channel != "" {
+		releases, err := selfupdate.FetchReleases(selfupdate.DefaultOwner, selfupdate.DefaultRepository)
+		if err != nil {
+			return nil, semver.Version{}, err
+		}
+		release, err := selfupdate.LatestRelease(releases, channel)
+		if err != nil {
+			return nil, semver.Version{}, err
+		}
+		log.Logger().Debugf("Latest version of jx on the %s channel: %s", channel, util.ColorInfo(release.Version))
+		return release, release.Version, nil
+	}
+
+	versionResolver, err := o.GetVersionResolver()
+	if err != nil {
+		return nil, semver.Version{}, err
+	}
+	latestVersion, err := o.GetLatestJXVersion(versionResolver)
+	if err != nil {
+		return nil, semver.Version{}, errors.Wrap(err, "failed to determine version of latest jx release")
+	}
+	return nil, latestVersion, nil
+}
+
+// installRelease downloads and verifies the release then atomically replaces the current jx binary
+func (o *UpgradeCLIOptions) installRelease(release *selfupdate.Release, v semver.Version) error {
+	var err error
+	if release == nil {
+		release, err = selfupdate.FetchRelease(selfupdate.DefaultOwner, selfupdate.DefaultRepository, v.String())
+		if err != nil {
+			return err
+		}
+	}
+	updater := &selfupdate.Updater{}
+	publicKey := o.PublicKey
+	if publicKey == "" {
+		publicKey = os.Getenv(EnvUpdatePublicKey)
+	}
+	if publicKey != "" {
+		updater.PublicKey, err = selfupdate.ParsePublicKey(publicKey)
 		if err != nil {
-			return semver.Version{}, err
+			return err
 		}
-		latestVersion, err := o.GetLatestJXVersion(versionResolver)
+	}
+	// lets replace the binary which is running rather than the default location if it is installed elsewhere
+	binDir, err := util.JXBinaryLocation()
+	if err != nil {
+		binDir, err = util.JXBinLocation()
 		if err != nil {
-			return semver.Version{}, errors.Wrap(err, "failed to determine version of latest jx release")
+			return err
 		}
-		return latestVersion, nil
 	}
+	log.Logger().Infof("Upgrading jx to version %s in %s", util.ColorInfo(v.String()), util.ColorInfo(binDir))
+	err = updater.Install(release, binDir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to upgrade jx to version %s", v.String())
+	}
+	log.Logger().Infof("jx upgraded to version %s", util.ColorInfo(v.String()))
+	return nil
+}
 
-	requestedVersion, err := semver.New(o.Version)
+// configureUpdateNotifier enables or disables the new version available notice
+func (o *UpgradeCLIOptions) configureUpdateNotifier() error {
+	var disabled bool
+	switch strings.ToLower(o.UpdateNotifier) {
+	case "on", "true":
+		disabled = false
+	case "off", "false":
+		disabled = true
+	default:
+		return util.InvalidOption("update-notifier", o.UpdateNotifier, []string{"on", "off"})
+	}
+	notifier := &selfupdate.Notifier{}
+	settings, err := notifier.LoadSettings()
+	if err != nil {
+		return err
+	}
+	settings.Disabled = disabled
+	err = notifier.SaveSettings(settings)
+	if err != nil {
+		return err
+	}
+	if disabled {
+		log.Logger().Info("The new version available notice is disabled")
+	} else {
+		log.Logger().Info("The new version available notice is enabled")
+	}
+	return nil
+}
+
+// rememberChannel records the channel so that the new version available notice checks the same channel
+func (o *UpgradeCLIOptions) rememberChannel(channel selfupdate.Channel) {
+	notifier := &selfupdate.Notifier{}
+	settings, err := notifier.LoadSettings()
+	if err == nil && settings.Channel != string(channel) {
+		settings.Channel = string(channel)
+		settings.LatestVersion = ""
+		settings.LastChecked = settings.LastChecked.AddDate(-1, 0, 0)
+		err = notifier.SaveSettings(settings)
+	}
 	if err != nil {
-		return semver.Version{}, errors.Wrapf(err, "invalid version requested: %s", o.Version)
+		log.Logger().Debugf("failed to save the update notifier channel: %s", err)
 	}
-	return *requestedVersion, nil
 }
 
 func (o *UpgradeCLIOptions) needsUpgrade(currentVersion semver.Version, latestVersion semver.Version) bool {
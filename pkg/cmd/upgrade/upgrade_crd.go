@@ -1,29 +1,55 @@
 package upgrade
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
 	"github.com/jenkins-x/jx-logging/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/kube/crds"
+	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
 )
 
 var (
 	upgradeCRDsLong = templates.LongDesc(`
 		Upgrades the Jenkins X Custom Resource Definitions in the Kubernetes Cluster
+
+		Before the CRDs are upgraded they are checked to be compatible with the CRDs in the cluster and all the existing
+		custom resources are decoded using the versions required by this binary and validated against the schemas of the
+		new CRDs. The CRDs and custom resources are backed up to a local directory before the upgrade is applied.
 `)
 
 	upgradeCRDsExample = templates.Examples(`
-		# Upgrades the Custom Resource Definitions 
+		# Upgrades the Custom Resource Definitions
 		jx upgrade crd
+
+		# Checks whether the Custom Resource Definitions can be upgraded safely without applying them
+		jx upgrade crd --dry-run
 	`)
 )
 
 // UpgradeCRDsOptions the options for the upgrade CRDs command
 type UpgradeCRDsOptions struct {
 	UpgradeOptions
+
+	BackupDir  string
+	SkipBackup bool
+	DryRun     bool
+	Force      bool
 }
 
 // NewCmdUpgradeCRDs defines the command
@@ -39,6 +65,7 @@ func NewCmdUpgradeCRDs(commonOpts *opts.CommonOptions) *cobra.Command {
 		Short:   "Upgrades the Jenkins X Custom Resource Definitions in the Kubernetes Cluster",
 		Long:    upgradeCRDsLong,
 		Example: upgradeCRDsExample,
+		Aliases: []string{"crds"},
 		Run: func(cmd *cobra.Command, args []string) {
 			options.Cmd = cmd
 			options.Args = args
@@ -46,6 +73,10 @@ func NewCmdUpgradeCRDs(commonOpts *opts.CommonOptions) *cobra.Command {
 			helper.CheckErr(err)
 		},
 	}
+	cmd.Flags().StringVarP(&options.BackupDir, "backup-dir", "", "", "The directory to back up the CRDs and custom resources to. Defaults to a new directory in ~/.jx/backup/crds")
+	cmd.Flags().BoolVarP(&options.SkipBackup, "skip-backup", "", false, "Skips backing up the CRDs and custom resources")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "Only checks whether the CRDs can be upgraded safely")
+	cmd.Flags().BoolVarP(&options.Force, "force", "", false, "Upgrades the CRDs even if the safety checks fail")
 	return cmd
 }
 
@@ -55,6 +86,60 @@ func (o *UpgradeCRDsOptions) Run() error {
 	if err != nil {
 		return errors.Wrap(err, "failed to create the API extensions client")
 	}
+	dynamicClient, _, err := o.GetFactory().CreateDynamicClient()
+	if err != nil {
+		return errors.Wrap(err, "failed to create the dynamic client")
+	}
+	desiredCRDs, err := kube.DesiredCRDs()
+	if err != nil {
+		return err
+	}
+
+	backupDir := ""
+	if !o.SkipBackup {
+		backupDir, err = o.createBackupDir()
+		if err != nil {
+			return err
+		}
+	}
+
+	problems := []string{}
+	for i := range desiredCRDs {
+		desired := &desiredCRDs[i]
+		existing, err := apisClient.ApiextensionsV1beta1().CustomResourceDefinitions().Get(desired.Name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				log.Logger().Infof("CRD %s will be created", util.ColorInfo(desired.Name))
+				continue
+			}
+			return errors.Wrapf(err, "getting the CRD %s", desired.Name)
+		}
+		problems = append(problems, kube.CheckCRDUpgrade(existing, desired)...)
+
+		crdProblems, err := o.checkCustomResources(dynamicClient, existing, desired, backupDir)
+		if err != nil {
+			return err
+		}
+		problems = append(problems, crdProblems...)
+	}
+
+	if backupDir != "" {
+		log.Logger().Infof("Backed up the CRDs and custom resources to %s", util.ColorInfo(backupDir))
+	}
+	if len(problems) > 0 {
+		log.Logger().Warnf("The CRDs cannot be upgraded safely:")
+		for _, problem := range problems {
+			log.Logger().Warnf("  %s", problem)
+		}
+		if !o.Force {
+			return fmt.Errorf("%d problems found upgrading the CRDs, use --force to upgrade anyway", len(problems))
+		}
+	}
+	if o.DryRun {
+		log.Logger().Info("The safety checks have completed, not upgrading the CRDs as --dry-run was specified")
+		return nil
+	}
+
 	err = kube.RegisterAllCRDs(apisClient)
 	if err != nil {
 		return errors.Wrap(err, "failed to register all CRDs")
@@ -62,3 +147,95 @@ func (o *UpgradeCRDsOptions) Run() error {
 	log.Logger().Info("Jenkins X CRDs upgraded with success")
 	return nil
 }
+
+// checkCustomResources decodes the existing custom resources of a CRD using the version required by this binary,
+// backing them up if a backup directory is specified
+func (o *UpgradeCRDsOptions) checkCustomResources(dynamicClient dynamic.Interface, existing *v1beta1.CustomResourceDefinition,
+	desired *v1beta1.CustomResourceDefinition, backupDir string) ([]string, error) {
+	if backupDir != "" {
+		err := writeBackupFile(filepath.Join(backupDir, "crds", existing.Name+".yaml"), existing)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// lets list the resources using a version served by the existing CRD then decode them as the desired version
+	desiredVersions := kube.CRDServedVersions(desired)
+	servedVersions := kube.CRDServedVersions(existing)
+	if len(desiredVersions) == 0 || len(servedVersions) == 0 {
+		return []string{fmt.Sprintf("CRD %s does not serve any versions", existing.Name)}, nil
+	}
+	listVersion := servedVersions[0]
+	if util.StringArrayIndex(servedVersions, desiredVersions[0]) >= 0 {
+		listVersion = desiredVersions[0]
+	}
+	gvr := schema.GroupVersionResource{Group: existing.Spec.Group, Version: listVersion, Resource: existing.Spec.Names.Plural}
+	resources, err := dynamicClient.Resource(gvr).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the %s", existing.Name)
+	}
+
+	problems := []string{}
+	for i := range resources.Items {
+		resource := &resources.Items[i]
+		if backupDir != "" {
+			name := resource.GetName()
+			if resource.GetNamespace() != "" {
+				name = resource.GetNamespace() + "-" + name
+			}
+			err = writeBackupFile(filepath.Join(backupDir, "resources", existing.Name, name+".yaml"), resource)
+			if err != nil {
+				return nil, err
+			}
+		}
+		resource = resource.DeepCopy()
+		resource.SetAPIVersion(desired.Spec.Group + "/" + desiredVersions[0])
+		warnings, err := kube.DecodeCustomResource(resource)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		for _, warning := range warnings {
+			log.Logger().Warnf("%s", warning)
+		}
+		schemaProblems, err := crds.ValidateCustomResource(desired, resource)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, schemaProblems...)
+	}
+	log.Logger().Infof("Checked %d %s", len(resources.Items), util.ColorInfo(existing.Name))
+	return problems, nil
+}
+
+func (o *UpgradeCRDsOptions) createBackupDir() (string, error) {
+	dir := o.BackupDir
+	if dir == "" {
+		backupDir, err := util.BackupDir()
+		if err != nil {
+			return "", errors.Wrap(err, "finding the backup directory")
+		}
+		dir = filepath.Join(backupDir, "crds", time.Now().Format("20060102-150405"))
+	}
+	err := os.MkdirAll(dir, util.DefaultWritePermissions)
+	if err != nil {
+		return "", errors.Wrapf(err, "creating the backup directory %s", dir)
+	}
+	return dir, nil
+}
+
+func writeBackupFile(fileName string, obj interface{}) error {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return errors.Wrapf(err, "marshalling %s", fileName)
+	}
+	err = os.MkdirAll(filepath.Dir(fileName), util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "creating directory %s", filepath.Dir(fileName))
+	}
+	err = ioutil.WriteFile(fileName, data, util.DefaultFileWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "writing %s", fileName)
+	}
+	return nil
+}
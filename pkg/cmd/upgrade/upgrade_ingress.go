@@ -17,6 +17,9 @@ var (
 	upgradeIngressExample = templates.Examples(`
 		# Upgrades the Jenkins X Ingress rules
 		jx upgrade ingress
+
+		# Upgrades the ingress controller to the version in the version stream
+		jx upgrade ingress controller
 	`)
 )
 
@@ -41,6 +44,7 @@ func NewCmdUpgradeIngress(commonOpts *opts.CommonOptions) *cobra.Command {
 	}
 	addFlags(options, cmd)
 
+	cmd.AddCommand(NewCmdUpgradeIngressController(commonOpts))
 	return cmd
 }
 
@@ -0,0 +1,375 @@
+package upgrade
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/jenkins-x/jx/v2/pkg/versionstream"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// minIngressControllerReplicas the minimum number of controller replicas used during an upgrade so that there is
+	// always a ready pod behind the load balancer while the rolling update happens
+	minIngressControllerReplicas = 2
+)
+
+var (
+	upgradeIngressControllerLong = templates.LongDesc(`
+		Upgrades the ingress controller installed by 'jx init' to the version in the version stream.
+
+		The current values of the release are preserved and a diff of the chart version and values is shown before upgrading.
+		The upgrade is performed as a rolling update which keeps the current LoadBalancer IP address of the ingress
+		controller Service. If the new controller does not become ready the release is rolled back to its previous revision.
+`)
+
+	upgradeIngressControllerExample = templates.Examples(`
+		# Upgrades the ingress controller to the version in the version stream
+		jx upgrade ingress controller
+
+		# Shows what would change without upgrading
+		jx upgrade ingress controller --dry-run
+
+		# Writes the access logs of the ingress controller as JSON
+		jx upgrade ingress controller --access-logs-json
+	`)
+)
+
+// UpgradeIngressControllerOptions the options for the upgrade ingress controller command
+type UpgradeIngressControllerOptions struct {
+	UpgradeOptions
+
+	Namespace          string
+	ReleaseName        string
+	Chart              string
+	Deployment         string
+	Service            string
+	Version            string
+	VersionsRepository string
+	VersionsGitRef     string
+	Timeout            time.Duration
+	DryRun             bool
+	Force              bool
+	AccessLogsJSON     bool
+}
+
+// NewCmdUpgradeIngressController defines the command
+func NewCmdUpgradeIngressController(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &UpgradeIngressControllerOptions{
+		UpgradeOptions: UpgradeOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "controller",
+		Short:   "Upgrades the ingress controller to the version in the version stream",
+		Long:    upgradeIngressControllerLong,
+		Example: upgradeIngressControllerExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", opts.DefaultIngressNamesapce, "The namespace of the ingress controller")
+	cmd.Flags().StringVarP(&options.ReleaseName, "release", "r", opts.DefaultIngressReleaseName, "The helm release name of the ingress controller")
+	cmd.Flags().StringVarP(&options.Chart, "chart", "c", opts.DefaultIngressChart, "The helm chart of the ingress controller")
+	cmd.Flags().StringVarP(&options.Deployment, "deployment", "", opts.DefaultIngressServiceName, "The name of the ingress controller Deployment")
+	cmd.Flags().StringVarP(&options.Service, "service", "", opts.DefaultIngressServiceName, "The name of the ingress controller Service")
+	cmd.Flags().StringVarP(&options.Version, "version", "v", "", "The chart version to upgrade to. Defaults to the version in the version stream")
+	cmd.Flags().StringVarP(&options.VersionsRepository, "versions-repo", "", "", "Jenkins X versions Git repo")
+	cmd.Flags().StringVarP(&options.VersionsGitRef, "versions-ref", "", "", "Jenkins X versions Git repository reference (tag, branch, sha etc)")
+	cmd.Flags().DurationVarP(&options.Timeout, "timeout", "", 10*time.Minute, "How long to wait for the upgraded ingress controller to become ready before rolling back")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "Only show the diff without upgrading the ingress controller")
+	cmd.Flags().BoolVarP(&options.Force, "force", "", false, "Upgrade the ingress controller even if nothing has changed")
+	cmd.Flags().BoolVarP(&options.AccessLogsJSON, "access-logs-json", "", false, "Writes the access logs of the ingress controller as JSON including the namespace and service of each request")
+	return cmd
+}
+
+// Run implements the command
+func (o *UpgradeIngressControllerOptions) Run() error {
+	client, err := o.KubeClient()
+	if err != nil {
+		return errors.Wrap(err, "creating the kube client")
+	}
+	release, err := o.findRelease()
+	if err != nil {
+		return err
+	}
+
+	version := o.Version
+	if version == "" {
+		version, err = o.GetVersionNumber(versionstream.KindChart, o.Chart, o.VersionsRepository, o.VersionsGitRef)
+		if err != nil {
+			return errors.Wrapf(err, "failed to load version of chart %s", o.Chart)
+		}
+		if version == "" {
+			return fmt.Errorf("no version of chart %s found in the version stream", o.Chart)
+		}
+	}
+
+	currentValues, err := o.releaseValues()
+	if err != nil {
+		return err
+	}
+	svc, err := client.CoreV1().Services(o.Namespace).Get(o.Service, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "getting the ingress controller service %s in namespace %s", o.Service, o.Namespace)
+	}
+	deployment, err := client.AppsV1().Deployments(o.Namespace).Get(o.Deployment, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "getting the ingress controller deployment %s in namespace %s", o.Deployment, o.Namespace)
+	}
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	targetValues, err := UpgradeIngressControllerValues(currentValues, svc, replicas, o.Namespace+"/"+o.Service)
+	if err != nil {
+		return err
+	}
+	if o.AccessLogsJSON {
+		EnableIngressJSONAccessLogs(targetValues)
+	}
+
+	loadBalancerIP := ServiceLoadBalancerIP(svc)
+	if loadBalancerIP == "" {
+		log.Logger().Warnf("The ingress controller service %s has no LoadBalancer IP address so it cannot be preserved", util.ColorInfo(o.Service))
+	}
+
+	diff := helm.DiffValues(currentValues, targetValues)
+	log.Logger().Infof("Ingress controller release %s chart %s: %s -> %s", util.ColorInfo(release.ReleaseName), util.ColorInfo(release.Chart),
+		util.ColorInfo(release.ChartVersion), util.ColorInfo(version))
+	if len(diff) == 0 {
+		log.Logger().Info("No changes to the release values")
+	} else {
+		log.Logger().Info("Changes to the release values:")
+		for _, line := range diff {
+			log.Logger().Infof("  %s", line)
+		}
+	}
+	if release.ChartVersion == version && len(diff) == 0 && !o.Force {
+		log.Logger().Infof("The ingress controller is already up to date")
+		return nil
+	}
+	if o.DryRun {
+		return nil
+	}
+	if !o.BatchMode {
+		upgrade, err := util.Confirm("Upgrade the ingress controller", true, "Performs a rolling upgrade of the ingress controller", o.GetIOFileHandles())
+		if err != nil {
+			return err
+		}
+		if !upgrade {
+			return nil
+		}
+	}
+
+	valuesFile, err := writeValuesFile(targetValues)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(valuesFile) //nolint:errcheck
+
+	err = o.InstallChartWithOptions(helm.InstallChartOptions{
+		Chart:       o.Chart,
+		ReleaseName: release.ReleaseName,
+		Version:     version,
+		Ns:          o.Namespace,
+		ValueFiles:  []string{valuesFile},
+		HelmUpdate:  true,
+		UpgradeOnly: true,
+		NoForce:     true,
+	})
+	if err == nil {
+		err = o.verifyUpgrade(client, loadBalancerIP)
+	}
+	if err != nil {
+		log.Logger().Warnf("Upgrade of the ingress controller failed: %s", err)
+		rollbackErr := o.rollback(client, release)
+		if rollbackErr != nil {
+			return errors.Wrapf(rollbackErr, "failed to roll back the ingress controller after the upgrade failed with: %s", err)
+		}
+		return errors.Wrapf(err, "upgrading the ingress controller, rolled back to revision %s", release.Revision)
+	}
+	log.Logger().Infof("Ingress controller upgraded to version %s", util.ColorInfo(version))
+	return nil
+}
+
+// UpgradeIngressControllerValues returns the values to upgrade the ingress controller with. The current release values
+// are preserved and the values needed for a zero downtime upgrade which keeps the LoadBalancer IP address are added
+func UpgradeIngressControllerValues(current map[string]interface{}, svc *v1.Service, replicas int32, publishService string) (map[string]interface{}, error) {
+	// lets deep copy the current values so we can diff them afterwards
+	data, err := yaml.Marshal(current)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling the current release values")
+	}
+	answer, err := helm.LoadValues(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "copying the current release values")
+	}
+
+	util.SetMapValueViaPath(answer, "controller.extraArgs.publish-service", publishService)
+	loadBalancerIP := ServiceLoadBalancerIP(svc)
+	if loadBalancerIP != "" {
+		util.SetMapValueViaPath(answer, "controller.service.loadBalancerIP", loadBalancerIP)
+	}
+	if replicas < minIngressControllerReplicas {
+		replicas = minIngressControllerReplicas
+	}
+	if int32(util.GetMapValueAsIntViaPath(answer, "controller.replicaCount")) < replicas {
+		util.SetMapValueViaPath(answer, "controller.replicaCount", int(replicas))
+	}
+	util.SetMapValueViaPath(answer, "controller.updateStrategy.type", "RollingUpdate")
+	util.SetMapValueViaPath(answer, "controller.updateStrategy.rollingUpdate.maxUnavailable", 0)
+	return answer, nil
+}
+
+// EnableIngressJSONAccessLogs configures the ingress controller to write its access logs as JSON and annotates the
+// controller pods so that the fluent-bit based logging addons parse the logs into fields
+func EnableIngressJSONAccessLogs(values map[string]interface{}) {
+	util.SetMapValueViaPath(values, "controller.config.log-format-escape-json", "true")
+	util.SetMapValueViaPath(values, "controller.config.log-format-upstream", kube.IngressAccessLogFormat)
+
+	// the annotation key contains dots so it cannot be set via a path
+	annotations := util.GetMapValueAsMapViaPath(values, "controller.podAnnotations")
+	if annotations == nil {
+		annotations = map[string]interface{}{}
+	}
+	annotations["fluentbit.io/parser"] = "json"
+	util.SetMapValueViaPath(values, "controller.podAnnotations", annotations)
+}
+
+// ServiceLoadBalancerIP returns the IP address of the load balancer of the service or an empty string if it has none
+func ServiceLoadBalancerIP(svc *v1.Service) string {
+	if svc == nil {
+		return ""
+	}
+	if svc.Spec.LoadBalancerIP != "" {
+		return svc.Spec.LoadBalancerIP
+	}
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			return ingress.IP
+		}
+	}
+	return ""
+}
+
+func (o *UpgradeIngressControllerOptions) findRelease() (*helm.ReleaseSummary, error) {
+	releases, _, err := o.Helm().ListReleases(o.Namespace)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the helm releases in namespace %s", o.Namespace)
+	}
+	release, ok := releases[o.ReleaseName]
+	if !ok {
+		return nil, fmt.Errorf("no ingress controller release %s found in namespace %s. Was it installed by 'jx init'?", o.ReleaseName, o.Namespace)
+	}
+	return &release, nil
+}
+
+func (o *UpgradeIngressControllerOptions) releaseValues() (map[string]interface{}, error) {
+	args, err := o.addHelmNamespace([]string{"get", "values", o.ReleaseName, "--output", "yaml"})
+	if err != nil {
+		return nil, err
+	}
+	cmd := util.Command{
+		Name: o.Helm().HelmBinary(),
+		Args: args,
+	}
+	output, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting the values of release %s", o.ReleaseName)
+	}
+	values, err := helm.LoadValues([]byte(output))
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the values of release %s", o.ReleaseName)
+	}
+	return values, nil
+}
+
+func (o *UpgradeIngressControllerOptions) verifyUpgrade(client kubernetes.Interface, loadBalancerIP string) error {
+	err := kube.WaitForDeploymentRollout(client, o.Deployment, o.Namespace, o.Timeout)
+	if err != nil {
+		return err
+	}
+	if loadBalancerIP == "" {
+		return nil
+	}
+	svc, err := client.CoreV1().Services(o.Namespace).Get(o.Service, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "getting the ingress controller service %s in namespace %s", o.Service, o.Namespace)
+	}
+	actual := ServiceLoadBalancerIP(svc)
+	if actual != loadBalancerIP {
+		return fmt.Errorf("the LoadBalancer IP address of service %s changed from %s to %s", o.Service, loadBalancerIP, actual)
+	}
+	return nil
+}
+
+func (o *UpgradeIngressControllerOptions) rollback(client kubernetes.Interface, release *helm.ReleaseSummary) error {
+	log.Logger().Infof("Rolling back release %s to revision %s", util.ColorInfo(release.ReleaseName), util.ColorInfo(release.Revision))
+	args, err := o.addHelmNamespace([]string{"rollback", release.ReleaseName, release.Revision})
+	if err != nil {
+		return err
+	}
+	cmd := util.Command{
+		Name: o.Helm().HelmBinary(),
+		Args: args,
+	}
+	_, err = cmd.RunWithoutRetry()
+	if err != nil {
+		return errors.Wrapf(err, "rolling back release %s to revision %s", release.ReleaseName, release.Revision)
+	}
+	return kube.WaitForDeploymentRollout(client, o.Deployment, o.Namespace, o.Timeout)
+}
+
+// addHelmNamespace adds the namespace argument which is required by helm 3 as releases are namespaced
+func (o *UpgradeIngressControllerOptions) addHelmNamespace(args []string) ([]string, error) {
+	v, err := o.Helm().Version(false)
+	if err != nil {
+		return nil, errors.Wrap(err, "detecting the helm version")
+	}
+	helmVersion, err := semver.ParseTolerant(v)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse semantic version %s", v)
+	}
+	if helmVersion.Major >= 3 {
+		args = append(args, "--namespace", o.Namespace)
+	}
+	return args, nil
+}
+
+func writeValuesFile(values map[string]interface{}) (string, error) {
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return "", errors.Wrap(err, "marshalling the ingress controller values")
+	}
+	f, err := ioutil.TempFile("", "jxing-values-")
+	if err != nil {
+		return "", errors.Wrap(err, "creating a temporary values file")
+	}
+	defer f.Close() //nolint:errcheck
+	_, err = f.Write(data)
+	if err != nil {
+		return "", errors.Wrapf(err, "writing the values file %s", f.Name())
+	}
+	return f.Name(), nil
+}
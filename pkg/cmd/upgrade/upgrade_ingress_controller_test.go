@@ -0,0 +1,103 @@
+// +build unit
+
+package upgrade_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/upgrade"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestUpgradeIngressControllerValues(t *testing.T) {
+	t.Parallel()
+
+	current := map[string]interface{}{
+		"controller": map[string]interface{}{
+			"service": map[string]interface{}{
+				"annotations": map[string]interface{}{
+					"service.beta.kubernetes.io/aws-load-balancer-type": "nlb",
+				},
+			},
+		},
+	}
+	svc := &v1.Service{
+		Status: v1.ServiceStatus{
+			LoadBalancer: v1.LoadBalancerStatus{
+				Ingress: []v1.LoadBalancerIngress{
+					{
+						IP: "35.1.2.3",
+					},
+				},
+			},
+		},
+	}
+
+	values, err := upgrade.UpgradeIngressControllerValues(current, svc, 1, "kube-system/jxing-nginx-ingress-controller")
+	require.NoError(t, err)
+
+	annotations := util.GetMapValueAsMapViaPath(values, "controller.service.annotations")
+	assert.Equal(t, "nlb", annotations["service.beta.kubernetes.io/aws-load-balancer-type"], "existing values should be preserved")
+	assert.Equal(t, "35.1.2.3", util.GetMapValueAsStringViaPath(values, "controller.service.loadBalancerIP"))
+	assert.Equal(t, "kube-system/jxing-nginx-ingress-controller", util.GetMapValueAsStringViaPath(values, "controller.extraArgs.publish-service"))
+	assert.Equal(t, 2, util.GetMapValueAsIntViaPath(values, "controller.replicaCount"))
+	assert.Equal(t, "RollingUpdate", util.GetMapValueAsStringViaPath(values, "controller.updateStrategy.type"))
+	assert.Equal(t, 0, util.GetMapValueAsIntViaPath(values, "controller.updateStrategy.rollingUpdate.maxUnavailable"))
+
+	assert.Nil(t, util.GetMapValueViaPath(current, "controller.service.loadBalancerIP"), "the current values should not be modified")
+	assert.Nil(t, util.GetMapValueViaPath(values, "rbac"), "rbac values should only come from the release")
+
+	util.SetMapValueViaPath(current, "rbac.create", false)
+	values, err = upgrade.UpgradeIngressControllerValues(current, svc, 1, "kube-system/jxing-nginx-ingress-controller")
+	require.NoError(t, err)
+	assert.Equal(t, false, util.GetMapValueViaPath(values, "rbac.create"), "the rbac values of the release should be preserved")
+}
+
+func TestEnableIngressJSONAccessLogs(t *testing.T) {
+	t.Parallel()
+
+	values := map[string]interface{}{
+		"controller": map[string]interface{}{
+			"podAnnotations": map[string]interface{}{
+				"prometheus.io/scrape": "true",
+			},
+		},
+	}
+	upgrade.EnableIngressJSONAccessLogs(values)
+
+	assert.Equal(t, "true", util.GetMapValueAsStringViaPath(values, "controller.config.log-format-escape-json"))
+	assert.Equal(t, kube.IngressAccessLogFormat, util.GetMapValueAsStringViaPath(values, "controller.config.log-format-upstream"))
+	annotations := util.GetMapValueAsMapViaPath(values, "controller.podAnnotations")
+	assert.Equal(t, "json", annotations["fluentbit.io/parser"])
+	assert.Equal(t, "true", annotations["prometheus.io/scrape"], "existing annotations should be preserved")
+}
+
+func TestServiceLoadBalancerIP(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "", upgrade.ServiceLoadBalancerIP(nil))
+	assert.Equal(t, "", upgrade.ServiceLoadBalancerIP(&v1.Service{}))
+
+	svc := &v1.Service{
+		Spec: v1.ServiceSpec{
+			LoadBalancerIP: "10.0.0.1",
+		},
+		Status: v1.ServiceStatus{
+			LoadBalancer: v1.LoadBalancerStatus{
+				Ingress: []v1.LoadBalancerIngress{
+					{
+						IP: "35.1.2.3",
+					},
+				},
+			},
+		},
+	}
+	assert.Equal(t, "10.0.0.1", upgrade.ServiceLoadBalancerIP(svc))
+
+	svc.Spec.LoadBalancerIP = ""
+	assert.Equal(t, "35.1.2.3", upgrade.ServiceLoadBalancerIP(svc))
+}
@@ -0,0 +1,55 @@
+package verify
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/spf13/cobra"
+)
+
+// Options contains the command line options
+type Options struct {
+	*opts.CommonOptions
+}
+
+var (
+	verifyLong = templates.LongDesc(`
+		Verifies that Jenkins X resources are working correctly
+`)
+
+	verifyExample = templates.Examples(`
+		# verify the installation end to end
+		jx verify install
+
+		# verify the credentials and report when they expire
+		jx verify credentials
+	`)
+)
+
+// NewCmdVerify creates the command object
+func NewCmdVerify(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &Options{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "verify TYPE [flags]",
+		Short:   "Verifies that Jenkins X resources are working correctly",
+		Long:    verifyLong,
+		Example: verifyExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.AddCommand(NewCmdVerifyCredentials(commonOpts))
+	cmd.AddCommand(NewCmdVerifyInstall(commonOpts))
+	return cmd
+}
+
+// Run implements this command
+func (o *Options) Run() error {
+	return o.Cmd.Help()
+}
@@ -0,0 +1,736 @@
+package verify
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" // #nosec
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/docker"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/kube/services"
+	"github.com/jenkins-x/jx/v2/pkg/notify"
+	"github.com/jenkins-x/jx/v2/pkg/tekton"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// CredentialKindGit a git token
+	CredentialKindGit = "git"
+	// CredentialKindRegistry the credentials of a container registry
+	CredentialKindRegistry = "registry"
+	// CredentialKindCloud a cloud service account key
+	CredentialKindCloud = "cloud"
+	// CredentialKindWebhookHMAC the HMAC token signing the webhooks
+	CredentialKindWebhookHMAC = "webhook-hmac"
+
+	// CredentialStatusValid the credential is valid and does not expire soon
+	CredentialStatusValid = "Valid"
+	// CredentialStatusExpiring the credential expires within the warning period
+	CredentialStatusExpiring = "Expiring"
+	// CredentialStatusExpired the credential has expired
+	CredentialStatusExpired = "Expired"
+	// CredentialStatusInvalid the credential was rejected
+	CredentialStatusInvalid = "Invalid"
+	// CredentialStatusUnknown the credential could not be checked
+	CredentialStatusUnknown = "Unknown"
+
+	// VerifyCredentialsCronJobName the name of the CronJob verifying the credentials
+	VerifyCredentialsCronJobName = "jx-verify-credentials"
+
+	defaultVerifyCredentialsSchedule = "0 8 * * *"
+	defaultVerifyCredentialsImage    = "gcr.io/jenkinsxio/builder-jx"
+
+	dockerConfigSecret = "jenkins-docker-cfg"
+	dockerConfigKey    = "config.json"
+	hookServiceName    = "hook"
+)
+
+var (
+	// CredentialKinds the kinds of credentials which are verified
+	CredentialKinds = []string{CredentialKindGit, CredentialKindRegistry, CredentialKindCloud, CredentialKindWebhookHMAC}
+
+	hmacSecretNames = []string{"hmac-token", "lighthouse-hmac-token"}
+
+	verifyCredentialsLong = templates.LongDesc(`
+		Verifies the credentials used by the installation and reports the days remaining before they expire.
+
+		The credentials verified are:
+
+		* git: the tokens of the git servers, including the expiry of GitHub and GitLab tokens
+		* registry: the docker config the pipelines push images with, including the expiry of Amazon ECR tokens
+		* cloud: the Google Cloud service account keys stored in secrets, including their expiry if gcloud is available
+		* webhook-hmac: the HMAC token signing the webhooks, sending a signed ping to the webhook endpoint
+
+		Credentials which expire within the --warn-days are reported as expiring. Use --notify to send the expiring,
+		expired and invalid credentials to the notifications of the team, see 'jx create notification', and
+		--install-cronjob to install a CronJob which verifies the credentials daily.
+`)
+
+	verifyCredentialsExample = templates.Examples(`
+		# verify the credentials of the installation
+		jx verify credentials
+
+		# warn about the credentials expiring within 30 days as JSON
+		jx verify credentials --warn-days 30 -o json
+
+		# install a CronJob sending the credentials expiring within 14 days to the notifications every morning
+		jx verify credentials --install-cronjob
+	`)
+)
+
+// CredentialResult the result of the verification of a credential
+type CredentialResult struct {
+	Name          string     `json:"name"`
+	Kind          string     `json:"kind"`
+	Status        string     `json:"status"`
+	Expires       *time.Time `json:"expires,omitempty"`
+	DaysRemaining *int       `json:"daysRemaining,omitempty"`
+	Message       string     `json:"message,omitempty"`
+}
+
+// Healthy returns true if the credential is valid and does not expire within the warning period
+func (r *CredentialResult) Healthy() bool {
+	return r.Status == CredentialStatusValid || r.Status == CredentialStatusUnknown
+}
+
+// ServiceAccountKey a key of a cloud service account
+type ServiceAccountKey struct {
+	ID       string
+	Expires  *time.Time
+	Disabled bool
+}
+
+// CredentialsOptions contains the command line options
+type CredentialsOptions struct {
+	*opts.CommonOptions
+
+	Namespace      string
+	Kinds          []string
+	WarnDays       int
+	Notify         bool
+	Timeout        time.Duration
+	Output         string
+	InstallCronJob bool
+	Schedule       string
+	Image          string
+	ServiceAccount string
+	Results        []CredentialResult
+
+	now func() time.Time
+	// gitAuthConfig returns the git servers and users of the installation
+	gitAuthConfig func() (*auth.AuthConfig, error)
+	// verifyRegistry verifies the container registry accepts the credentials
+	verifyRegistry func(host string, username string, password string) error
+	// serviceAccountKeys lists the keys of the cloud service account
+	serviceAccountKeys func(email string, projectID string) ([]ServiceAccountKey, error)
+	// hookURL returns the URL the webhooks are sent to or an empty string if there is no webhook endpoint
+	hookURL func(kubeClient kubernetes.Interface, ns string) (string, error)
+	// post posts the payload of a notification to a webhook URL
+	post func(url string, payload map[string]interface{}) error
+}
+
+// NewCmdVerifyCredentials creates the command object
+func NewCmdVerifyCredentials(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &CredentialsOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "credentials",
+		Aliases: []string{"credential", "creds"},
+		Short:   "Verifies the credentials used by the installation and reports when they expire",
+		Long:    verifyCredentialsLong,
+		Example: verifyCredentialsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "The namespace Jenkins X is installed in. Defaults to the dev namespace")
+	cmd.Flags().StringArrayVarP(&options.Kinds, "kind", "k", nil, fmt.Sprintf("The kinds of credentials to verify: %s. Defaults to all of them", strings.Join(CredentialKinds, ", ")))
+	cmd.Flags().IntVarP(&options.WarnDays, "warn-days", "", 14, "The number of days before the expiry of a credential it is reported as expiring")
+	cmd.Flags().BoolVarP(&options.Notify, "notify", "", false, "Sends the expiring, expired and invalid credentials to the notifications of the team")
+	cmd.Flags().DurationVarP(&options.Timeout, "timeout", "t", 30*time.Second, "The timeout of the calls verifying each credential")
+	cmd.Flags().BoolVarP(&options.InstallCronJob, "install-cronjob", "", false, "Installs a CronJob in the dev namespace which periodically verifies the credentials and sends the warnings to the notifications of the team")
+	cmd.Flags().StringVarP(&options.Schedule, "schedule", "", defaultVerifyCredentialsSchedule, "The cron schedule of the installed CronJob")
+	cmd.Flags().StringVarP(&options.Image, "image", "i", defaultVerifyCredentialsImage, "The container image containing jx used by the installed CronJob")
+	cmd.Flags().StringVarP(&options.ServiceAccount, "service-account", "", tekton.DefaultPipelineSA, "The service account used by the installed CronJob")
+	options.AddOutputFlag(cmd, &options.Output)
+	return cmd
+}
+
+// Run implements this command
+func (o *CredentialsOptions) Run() error {
+	for _, k := range o.Kinds {
+		if util.StringArrayIndex(CredentialKinds, k) < 0 {
+			return util.InvalidOption("kind", k, CredentialKinds)
+		}
+	}
+	if o.InstallCronJob {
+		return o.installCronJob()
+	}
+	o.defaults()
+	o.RedirectLogsForOutput(o.Output)
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return errors.Wrap(err, "creating the kube client")
+	}
+	if o.Namespace != "" {
+		ns = o.Namespace
+	}
+
+	o.Results = nil
+	checks := map[string]func(kubernetes.Interface, string) error{
+		CredentialKindGit:         o.verifyGitTokens,
+		CredentialKindRegistry:    o.verifyRegistries,
+		CredentialKindCloud:       o.verifyCloudKeys,
+		CredentialKindWebhookHMAC: o.verifyWebhookHMAC,
+	}
+	for _, kind := range CredentialKinds {
+		if len(o.Kinds) > 0 && util.StringArrayIndex(o.Kinds, kind) < 0 {
+			continue
+		}
+		err = checks[kind](kubeClient, ns)
+		if err != nil {
+			return errors.Wrapf(err, "verifying the %s credentials", kind)
+		}
+	}
+
+	if o.Notify {
+		err = o.notify(kubeClient, ns)
+		if err != nil {
+			log.Logger().Warnf("%s", err.Error())
+		}
+	}
+	return o.report()
+}
+
+func (o *CredentialsOptions) defaults() {
+	if o.now == nil {
+		o.now = time.Now
+	}
+	if o.gitAuthConfig == nil {
+		o.gitAuthConfig = func() (*auth.AuthConfig, error) {
+			svc, err := o.GitAuthConfigService()
+			if err != nil {
+				return nil, err
+			}
+			return svc.Config(), nil
+		}
+	}
+	if o.verifyRegistry == nil {
+		o.verifyRegistry = func(host string, username string, password string) error {
+			return docker.VerifyRegistryLogin(host, username, password, o.Timeout)
+		}
+	}
+	if o.serviceAccountKeys == nil {
+		o.serviceAccountKeys = gcloudServiceAccountKeys
+	}
+	if o.hookURL == nil {
+		o.hookURL = func(kubeClient kubernetes.Interface, ns string) (string, error) {
+			u, err := services.FindServiceURL(kubeClient, ns, hookServiceName)
+			if err != nil || u == "" {
+				return "", err
+			}
+			return util.UrlJoin(u, "hook"), nil
+		}
+	}
+}
+
+// addResult records the result of a credential from the error of its verification and its expiry
+func (o *CredentialsOptions) addResult(kind string, name string, expires *time.Time, err error) {
+	result := CredentialResult{
+		Name:    name,
+		Kind:    kind,
+		Status:  CredentialStatusValid,
+		Expires: expires,
+	}
+	if expires != nil {
+		days := int(expires.Sub(o.now()).Hours() / 24)
+		result.DaysRemaining = &days
+		switch {
+		case !expires.After(o.now()):
+			result.Status = CredentialStatusExpired
+			result.Message = fmt.Sprintf("expired on %s", expires.Format("2006-01-02"))
+		case days < o.WarnDays:
+			result.Status = CredentialStatusExpiring
+			result.Message = fmt.Sprintf("expires in %d days on %s", days, expires.Format("2006-01-02"))
+		}
+	}
+	if err != nil {
+		result.Status = CredentialStatusInvalid
+		result.Message = err.Error()
+	}
+	o.Results = append(o.Results, result)
+}
+
+func (o *CredentialsOptions) addUnknown(kind string, name string, err error) {
+	o.Results = append(o.Results, CredentialResult{
+		Name:    name,
+		Kind:    kind,
+		Status:  CredentialStatusUnknown,
+		Message: err.Error(),
+	})
+}
+
+func (o *CredentialsOptions) verifyGitTokens(kubeClient kubernetes.Interface, ns string) error {
+	config, err := o.gitAuthConfig()
+	if err != nil {
+		return errors.Wrap(err, "loading the git auth config")
+	}
+	for _, server := range config.Servers {
+		for _, user := range server.Users {
+			if user.ApiToken == "" || user.GithubAppOwner != "" {
+				continue
+			}
+			name := fmt.Sprintf("%s@%s", user.Username, server.URL)
+			expires, err := o.gitTokenExpiry(server, user)
+			o.addResult(CredentialKindGit, name, expires, err)
+		}
+	}
+	return nil
+}
+
+// gitTokenExpiry verifies the git token returning its expiry if the git provider reports it
+func (o *CredentialsOptions) gitTokenExpiry(server *auth.AuthServer, user *auth.UserAuth) (*time.Time, error) {
+	kind := server.Kind
+	if kind == "" {
+		kind = gits.SaasGitKind(server.URL)
+	}
+	client := util.GetClientWithTimeout(o.Timeout)
+	switch kind {
+	case gits.KindGitHub:
+		apiURL := util.UrlJoin(server.URL, "api/v3", "user")
+		if u, err := url.Parse(server.URL); err == nil && u.Host == "github.com" {
+			apiURL = "https://api.github.com/user"
+		}
+		resp, err := getWithHeader(client, apiURL, "Authorization", "token "+user.ApiToken)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%s returned status %d", apiURL, resp.StatusCode)
+		}
+		return parseGitHubExpiry(resp.Header.Get("GitHub-Authentication-Token-Expiration")), nil
+
+	case gits.KindGitlab:
+		apiURL := util.UrlJoin(server.URL, "api/v4/personal_access_tokens/self")
+		resp, err := getWithHeader(client, apiURL, "PRIVATE-TOKEN", user.ApiToken)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%s returned status %d", apiURL, resp.StatusCode)
+		}
+		token := struct {
+			ExpiresAt string `json:"expires_at"`
+			Active    *bool  `json:"active"`
+		}{}
+		err = json.NewDecoder(resp.Body).Decode(&token)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing the response of %s", apiURL)
+		}
+		if token.Active != nil && !*token.Active {
+			return nil, fmt.Errorf("the token is not active")
+		}
+		if token.ExpiresAt == "" {
+			return nil, nil
+		}
+		expires, err := time.Parse("2006-01-02", token.ExpiresAt)
+		if err != nil {
+			return nil, nil
+		}
+		return &expires, nil
+
+	default:
+		provider, err := gits.CreateProvider(server, user, o.Git())
+		if err != nil {
+			return nil, errors.Wrapf(err, "creating the git provider of %s", server.URL)
+		}
+		_, err = provider.ListOrganisations()
+		return nil, err
+	}
+}
+
+func (o *CredentialsOptions) verifyRegistries(kubeClient kubernetes.Interface, ns string) error {
+	secret, err := kubeClient.CoreV1().Secrets(ns).Get(dockerConfigSecret, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Logger().Debugf("no %s secret found in namespace %s", dockerConfigSecret, ns)
+			return nil
+		}
+		return errors.Wrapf(err, "getting secret %s", dockerConfigSecret)
+	}
+	dockerConfig := struct {
+		Auths map[string]map[string]interface{} `json:"auths"`
+	}{}
+	if len(secret.Data[dockerConfigKey]) > 0 {
+		err = json.Unmarshal(secret.Data[dockerConfigKey], &dockerConfig)
+		if err != nil {
+			return errors.Wrapf(err, "parsing %s of secret %s", dockerConfigKey, dockerConfigSecret)
+		}
+	}
+	hosts := []string{}
+	for host := range dockerConfig.Auths {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		username, password := docker.AuthCredentials(dockerConfig.Auths[host])
+		if username == "" {
+			continue
+		}
+		expires := docker.TokenExpiry(username, password)
+		if expires != nil && !expires.After(o.now()) {
+			o.addResult(CredentialKindRegistry, host, expires, nil)
+			continue
+		}
+		err = o.verifyRegistry(host, username, password)
+		o.addResult(CredentialKindRegistry, host, expires, err)
+	}
+	return nil
+}
+
+func (o *CredentialsOptions) verifyCloudKeys(kubeClient kubernetes.Interface, ns string) error {
+	secrets, err := kubeClient.CoreV1().Secrets(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "listing the secrets in namespace %s", ns)
+	}
+	for _, secret := range secrets.Items {
+		keys := []string{}
+		for k := range secret.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			key := struct {
+				Type         string `json:"type"`
+				ProjectID    string `json:"project_id"`
+				PrivateKeyID string `json:"private_key_id"`
+				ClientEmail  string `json:"client_email"`
+			}{}
+			err = json.Unmarshal(secret.Data[k], &key)
+			if err != nil || key.Type != "service_account" || key.ClientEmail == "" {
+				continue
+			}
+			name := fmt.Sprintf("%s (secret %s)", key.ClientEmail, secret.Name)
+			accountKeys, err := o.serviceAccountKeys(key.ClientEmail, key.ProjectID)
+			if err != nil {
+				o.addUnknown(CredentialKindCloud, name, err)
+				continue
+			}
+			var found *ServiceAccountKey
+			for i := range accountKeys {
+				if accountKeys[i].ID == key.PrivateKeyID {
+					found = &accountKeys[i]
+					break
+				}
+			}
+			switch {
+			case found == nil:
+				o.addResult(CredentialKindCloud, name, nil, fmt.Errorf("key %s has been deleted", key.PrivateKeyID))
+			case found.Disabled:
+				o.addResult(CredentialKindCloud, name, found.Expires, fmt.Errorf("key %s is disabled", key.PrivateKeyID))
+			default:
+				o.addResult(CredentialKindCloud, name, found.Expires, nil)
+			}
+		}
+	}
+	return nil
+}
+
+func (o *CredentialsOptions) verifyWebhookHMAC(kubeClient kubernetes.Interface, ns string) error {
+	var secret *corev1.Secret
+	for _, name := range hmacSecretNames {
+		s, err := kubeClient.CoreV1().Secrets(ns).Get(name, metav1.GetOptions{})
+		if err == nil {
+			secret = s
+			break
+		}
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "getting secret %s", name)
+		}
+	}
+	if secret == nil {
+		log.Logger().Debugf("no %s secret found in namespace %s", strings.Join(hmacSecretNames, " or "), ns)
+		return nil
+	}
+	token := string(secret.Data["hmac"])
+	if token == "" {
+		o.addResult(CredentialKindWebhookHMAC, secret.Name, nil, fmt.Errorf("the secret has no hmac key"))
+		return nil
+	}
+	u, err := o.hookURL(kubeClient, ns)
+	if err != nil {
+		o.addUnknown(CredentialKindWebhookHMAC, secret.Name, errors.Wrap(err, "finding the webhook endpoint"))
+		return nil
+	}
+	if u == "" {
+		o.addUnknown(CredentialKindWebhookHMAC, secret.Name, fmt.Errorf("no webhook endpoint found"))
+		return nil
+	}
+	o.addResult(CredentialKindWebhookHMAC, secret.Name, nil, o.pingHook(u, token))
+	return nil
+}
+
+// pingHook sends a ping event signed with the HMAC token to the webhook endpoint which rejects invalid signatures
+func (o *CredentialsOptions) pingHook(hookURL string, token string) error {
+	body := []byte(`{"zen":"jx verify credentials","hook_id":0}`)
+	mac := hmac.New(sha1.New, []byte(token))
+	_, _ = mac.Write(body)
+	req, err := http.NewRequest(http.MethodPost, hookURL, strings.NewReader(string(body)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Event", "ping")
+	req.Header.Set("X-GitHub-Delivery", strconv.FormatInt(o.now().UnixNano(), 10))
+	req.Header.Set("X-Hub-Signature", "sha1="+hex.EncodeToString(mac.Sum(nil)))
+	resp, err := util.GetClientWithTimeout(o.Timeout).Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "pinging the webhook endpoint %s", hookURL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("the webhook endpoint %s rejected the signed ping with status %d", hookURL, resp.StatusCode)
+	}
+	return nil
+}
+
+// notify sends the unhealthy credentials to the notifications of the team
+func (o *CredentialsOptions) notify(kubeClient kubernetes.Interface, ns string) error {
+	notifier := &notify.Notifier{
+		KubeClient: kubeClient,
+		Namespace:  ns,
+		Post:       o.post,
+	}
+	var failed []string
+	for _, r := range o.Results {
+		if r.Healthy() {
+			continue
+		}
+		err := notifier.Notify(&notify.Event{
+			Type:       notify.EventCredentialWarning,
+			Credential: fmt.Sprintf("%s %s", r.Kind, r.Name),
+			Status:     r.Status,
+			Message:    r.Message,
+		})
+		if err != nil {
+			failed = append(failed, r.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to send the notifications of the credentials %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func (o *CredentialsOptions) report() error {
+	if o.Output != "" {
+		err := o.RenderOutput(o.Output, o.Results)
+		if err != nil {
+			return err
+		}
+	} else {
+		table := o.CreateTable()
+		table.AddRow("KIND", "NAME", "STATUS", "EXPIRES", "DAYS", "MESSAGE")
+		for _, r := range o.Results {
+			expires, days := "", ""
+			if r.Expires != nil {
+				expires = r.Expires.Format("2006-01-02")
+			}
+			if r.DaysRemaining != nil {
+				days = strconv.Itoa(*r.DaysRemaining)
+			}
+			status := r.Status
+			switch r.Status {
+			case CredentialStatusValid:
+				status = util.ColorInfo(status)
+			case CredentialStatusExpiring, CredentialStatusUnknown:
+				status = util.ColorWarning(status)
+			default:
+				status = util.ColorError(status)
+			}
+			table.AddRow(r.Kind, r.Name, status, expires, days, r.Message)
+		}
+		table.Render()
+	}
+	failed := []string{}
+	for _, r := range o.Results {
+		if r.Status == CredentialStatusExpired || r.Status == CredentialStatusInvalid {
+			failed = append(failed, r.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("the credentials %s are expired or invalid", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// installCronJob creates or updates the CronJob in the dev namespace which periodically runs this command
+func (o *CredentialsOptions) installCronJob() error {
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return errors.Wrap(err, "creating the kube client")
+	}
+	args := []string{"verify", "credentials", "--batch-mode", "--notify", "--warn-days", strconv.Itoa(o.WarnDays)}
+	for _, k := range o.Kinds {
+		args = append(args, "--kind", k)
+	}
+	schedule := o.Schedule
+	if schedule == "" {
+		schedule = defaultVerifyCredentialsSchedule
+	}
+	image := o.Image
+	if image == "" {
+		image = defaultVerifyCredentialsImage
+	}
+	historyLimit := int32(3)
+	cronJob := &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      VerifyCredentialsCronJobName,
+			Namespace: ns,
+			Labels:    map[string]string{"app": VerifyCredentialsCronJobName},
+		},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule:                   schedule,
+			ConcurrencyPolicy:          batchv1beta1.ForbidConcurrent,
+			SuccessfulJobsHistoryLimit: &historyLimit,
+			FailedJobsHistoryLimit:     &historyLimit,
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{"app": VerifyCredentialsCronJobName},
+						},
+						Spec: corev1.PodSpec{
+							ServiceAccountName: o.ServiceAccount,
+							RestartPolicy:      corev1.RestartPolicyNever,
+							Containers: []corev1.Container{
+								{
+									Name:    "verify-credentials",
+									Image:   image,
+									Command: []string{"jx"},
+									Args:    args,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	cronJobs := kubeClient.BatchV1beta1().CronJobs(ns)
+	existing, err := cronJobs.Get(VerifyCredentialsCronJobName, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "getting CronJob %s in namespace %s", VerifyCredentialsCronJobName, ns)
+		}
+		_, err = cronJobs.Create(cronJob)
+		if err != nil {
+			return errors.Wrapf(err, "creating CronJob %s in namespace %s", VerifyCredentialsCronJobName, ns)
+		}
+		log.Logger().Infof("Created CronJob %s verifying the credentials on schedule %s", util.ColorInfo(VerifyCredentialsCronJobName), util.ColorInfo(schedule))
+		return nil
+	}
+	existing.Labels = cronJob.Labels
+	existing.Spec = cronJob.Spec
+	_, err = cronJobs.Update(existing)
+	if err != nil {
+		return errors.Wrapf(err, "updating CronJob %s in namespace %s", VerifyCredentialsCronJobName, ns)
+	}
+	log.Logger().Infof("Updated CronJob %s verifying the credentials on schedule %s", util.ColorInfo(VerifyCredentialsCronJobName), util.ColorInfo(schedule))
+	return nil
+}
+
+func getWithHeader(client *http.Client, u string, name string, value string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(name, value)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "calling %s", u)
+	}
+	return resp, nil
+}
+
+// parseGitHubExpiry parses the GitHub-Authentication-Token-Expiration header of tokens which expire
+func parseGitHubExpiry(value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -0700"} {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			t = t.UTC()
+			return &t
+		}
+	}
+	return nil
+}
+
+// gcloudServiceAccountKeys lists the keys of the Google Cloud service account using gcloud
+func gcloudServiceAccountKeys(email string, projectID string) ([]ServiceAccountKey, error) {
+	args := []string{"iam", "service-accounts", "keys", "list", "--iam-account", email, "--format", "json"}
+	if projectID != "" {
+		args = append(args, "--project", projectID)
+	}
+	cmd := util.Command{
+		Name: "gcloud",
+		Args: args,
+	}
+	output, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the keys of the service account %s", email)
+	}
+	keys := []struct {
+		Name            string `json:"name"`
+		ValidBeforeTime string `json:"validBeforeTime"`
+		Disabled        bool   `json:"disabled"`
+	}{}
+	err = json.Unmarshal([]byte(output), &keys)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the keys of the service account %s", email)
+	}
+	answer := []ServiceAccountKey{}
+	for _, k := range keys {
+		key := ServiceAccountKey{
+			ID:       k.Name[strings.LastIndex(k.Name, "/")+1:],
+			Disabled: k.Disabled,
+		}
+		// keys which do not expire are valid until the year 9999
+		if t, err := time.Parse(time.RFC3339, k.ValidBeforeTime); err == nil && t.Year() < 9999 {
+			key.Expires = &t
+		}
+		answer = append(answer, key)
+	}
+	return answer, nil
+}
@@ -0,0 +1,209 @@
+// +build unit
+
+package verify
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" // #nosec
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/clients/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+const testNamespace = "jx"
+
+var testNow = time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func TestVerifyCredentials(t *testing.T) {
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/user" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.Header.Get("Authorization") {
+		case "token expiring":
+			w.Header().Set("GitHub-Authentication-Token-Expiration", "2020-06-08 12:00:00 UTC")
+		case "token forever":
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer github.Close()
+
+	hmacToken := "my-hmac"
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mac := hmac.New(sha1.New, []byte(hmacToken))
+		_, _ = mac.Write(body)
+		if r.Header.Get("X-Hub-Signature") != "sha1="+hex.EncodeToString(mac.Sum(nil)) {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer hook.Close()
+
+	ecrToken := base64.StdEncoding.EncodeToString([]byte(`{"expiration":1590969600}`))
+	dockerConfig := `{"auths":{"gcr.io":{"auth":"` + base64.StdEncoding.EncodeToString([]byte("_json_key:key")) + `"},` +
+		`"123.dkr.ecr.us-east-1.amazonaws.com":{"auth":"` + base64.StdEncoding.EncodeToString([]byte("AWS:"+ecrToken)) + `"}}}`
+	kubeClient := kubefake.NewSimpleClientset(
+		secret("jenkins-docker-cfg", dockerConfigKey, dockerConfig),
+		secret("hmac-token", "hmac", hmacToken),
+		secret("kaniko-secret", "kaniko-secret", `{"type":"service_account","project_id":"myproject","private_key_id":"key1","client_email":"kaniko@myproject.iam.gserviceaccount.com"}`),
+	)
+	o := newCredentialsOptions(kubeClient)
+	o.gitAuthConfig = func() (*auth.AuthConfig, error) {
+		return &auth.AuthConfig{Servers: []*auth.AuthServer{{
+			URL:  github.URL,
+			Kind: gits.KindGitHub,
+			Users: []*auth.UserAuth{
+				{Username: "bot", ApiToken: "expiring"},
+				{Username: "admin", ApiToken: "forever"},
+				{Username: "old", ApiToken: "revoked"},
+			},
+		}}}, nil
+	}
+	o.hookURL = func(kubernetes.Interface, string) (string, error) {
+		return hook.URL, nil
+	}
+	keyExpiry := testNow.Add(60 * 24 * time.Hour)
+	o.serviceAccountKeys = func(email string, projectID string) ([]ServiceAccountKey, error) {
+		assert.Equal(t, "kaniko@myproject.iam.gserviceaccount.com", email)
+		assert.Equal(t, "myproject", projectID)
+		return []ServiceAccountKey{{ID: "key1", Expires: &keyExpiry}}, nil
+	}
+	notifications := []string{}
+	o.post = func(url string, payload map[string]interface{}) error {
+		notifications = append(notifications, payload["text"].(string))
+		return nil
+	}
+	o.Notify = true
+	err := notify.SaveConfig(kubeClient, testNamespace, &notify.Config{Notifications: []notify.Notification{{Name: "ops", Kind: notify.KindSlack, Secret: "slack"}}})
+	require.NoError(t, err)
+	_, err = kubeClient.CoreV1().Secrets(testNamespace).Create(secret("slack", notify.SecretKeyURL, "https://hooks.slack.com/x").(*v1.Secret))
+	require.NoError(t, err)
+
+	err = o.Run()
+	require.Error(t, err, "the revoked token and the expired ECR token should fail the verification")
+
+	statuses := map[string]string{}
+	for _, r := range o.Results {
+		statuses[r.Name] = r.Status
+	}
+	assert.Equal(t, map[string]string{
+		"bot@" + github.URL:                   CredentialStatusExpiring,
+		"admin@" + github.URL:                 CredentialStatusValid,
+		"old@" + github.URL:                   CredentialStatusInvalid,
+		"123.dkr.ecr.us-east-1.amazonaws.com": CredentialStatusExpired,
+		"gcr.io":                              CredentialStatusValid,
+		"kaniko@myproject.iam.gserviceaccount.com (secret kaniko-secret)": CredentialStatusValid,
+		"hmac-token": CredentialStatusValid,
+	}, statuses)
+
+	for _, r := range o.Results {
+		if r.Name == "bot@"+github.URL {
+			require.NotNil(t, r.DaysRemaining)
+			assert.Equal(t, 7, *r.DaysRemaining)
+		}
+	}
+	assert.Len(t, notifications, 3, "the expiring, expired and invalid credentials should be notified")
+	assert.Contains(t, notifications[0], "Credential git bot@"+github.URL+" is expiring: expires in 7 days on 2020-06-08")
+}
+
+func TestVerifyWebhookHMACInvalid(t *testing.T) {
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer hook.Close()
+
+	kubeClient := kubefake.NewSimpleClientset(secret("lighthouse-hmac-token", "hmac", "stale"))
+	o := newCredentialsOptions(kubeClient)
+	o.Kinds = []string{CredentialKindWebhookHMAC}
+	o.hookURL = func(kubernetes.Interface, string) (string, error) {
+		return hook.URL, nil
+	}
+
+	err := o.Run()
+	require.Error(t, err)
+	require.Len(t, o.Results, 1)
+	assert.Equal(t, CredentialStatusInvalid, o.Results[0].Status)
+	assert.Equal(t, "lighthouse-hmac-token", o.Results[0].Name)
+}
+
+func TestVerifyCloudKeyUnknownWithoutGCloud(t *testing.T) {
+	kubeClient := kubefake.NewSimpleClientset(
+		secret("velero", "cloud", `{"type":"service_account","private_key_id":"key1","client_email":"velero@p.iam.gserviceaccount.com"}`),
+	)
+	o := newCredentialsOptions(kubeClient)
+	o.Kinds = []string{CredentialKindCloud}
+	o.serviceAccountKeys = func(string, string) ([]ServiceAccountKey, error) {
+		return nil, errors.New("gcloud not found")
+	}
+
+	err := o.Run()
+	require.NoError(t, err, "credentials which cannot be checked should not fail the verification")
+	require.Len(t, o.Results, 1)
+	assert.Equal(t, CredentialStatusUnknown, o.Results[0].Status)
+}
+
+func TestInstallVerifyCredentialsCronJob(t *testing.T) {
+	kubeClient := kubefake.NewSimpleClientset()
+	o := newCredentialsOptions(kubeClient)
+	o.InstallCronJob = true
+	o.WarnDays = 30
+
+	err := o.Run()
+	require.NoError(t, err)
+
+	cronJob, err := kubeClient.BatchV1beta1().CronJobs(testNamespace).Get(VerifyCredentialsCronJobName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"verify", "credentials", "--batch-mode", "--notify", "--warn-days", "30"}, cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Args)
+}
+
+func newCredentialsOptions(kubeClient kubernetes.Interface) *CredentialsOptions {
+	commonOpts := opts.NewCommonOptionsWithFactory(fake.NewFakeFactory())
+	commonOpts.SetDevNamespace(testNamespace)
+	commonOpts.SetKubeClient(kubeClient)
+	commonOpts.Out = os.Stdout
+	return &CredentialsOptions{
+		CommonOptions: &commonOpts,
+		WarnDays:      14,
+		Timeout:       5 * time.Second,
+		now: func() time.Time {
+			return testNow
+		},
+		gitAuthConfig: func() (*auth.AuthConfig, error) {
+			return &auth.AuthConfig{}, nil
+		},
+		verifyRegistry: func(host string, username string, password string) error {
+			return nil
+		},
+	}
+}
+
+func secret(name string, key string, value string) runtime.Object {
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: testNamespace,
+		},
+		Data: map[string][]byte{key: []byte(value)},
+	}
+}
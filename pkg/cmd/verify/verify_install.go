@@ -0,0 +1,359 @@
+package verify
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/kube/services"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// InstallOptions contains the command line options
+type InstallOptions struct {
+	*opts.CommonOptions
+
+	Namespace    string
+	Image        string
+	Timeout      time.Duration
+	SkipHelm     bool
+	SkipCanary   bool
+	SkipWebhook  bool
+	SkipDNS      bool
+	Output       string
+	checkResults []CheckResult
+}
+
+// CheckResult the result of a single verification check
+type CheckResult struct {
+	Name        string
+	Error       error
+	Remediation string
+}
+
+// Passed returns true if the check passed
+func (r *CheckResult) Passed() bool {
+	return r.Error == nil
+}
+
+// MarshalJSON marshals the result with the error message so that results can be rendered via the output flag
+func (r CheckResult) MarshalJSON() ([]byte, error) {
+	result := struct {
+		Name        string `json:"name"`
+		Passed      bool   `json:"passed"`
+		Error       string `json:"error,omitempty"`
+		Remediation string `json:"remediation,omitempty"`
+	}{
+		Name:   r.Name,
+		Passed: r.Passed(),
+	}
+	if !result.Passed {
+		result.Error = r.Error.Error()
+		result.Remediation = r.Remediation
+	}
+	return json.Marshal(result)
+}
+
+const (
+	// DefaultCanaryImage the image used for the canary build pod
+	DefaultCanaryImage = "busybox:1.31"
+
+	testReleaseName = "jx-verify-install"
+)
+
+var (
+	verifyInstallLong = templates.LongDesc(`
+		Verifies the installed Jenkins X stack end to end.
+
+		The checks performed are:
+
+		* the webhook endpoint is reachable through the ingress controller
+		* the hosts of the ingress rules resolve in DNS
+		* a test helm release can be installed and deleted
+		* a canary build pod can be scheduled and completes successfully
+
+		Any failures are reported along with a suggested remediation. This command is useful after 'jx install' or 'jx boot' and in nightly cluster health jobs.
+`)
+
+	verifyInstallExample = templates.Examples(`
+		# verify the installation in the current team
+		jx verify install
+
+		# verify the installation without the helm release check
+		jx verify install --skip-helm
+
+		# verify the installation reporting the results of the checks as JSON
+		jx verify install -o json
+	`)
+)
+
+// NewCmdVerifyInstall creates the command object
+func NewCmdVerifyInstall(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &InstallOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "install",
+		Short:   "Verifies the installation end to end after install and in health checks",
+		Long:    verifyInstallLong,
+		Example: verifyInstallExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "The namespace Jenkins X is installed in. Defaults to the dev namespace")
+	cmd.Flags().StringVarP(&options.Image, "image", "i", DefaultCanaryImage, "The container image used for the canary build pod")
+	cmd.Flags().DurationVarP(&options.Timeout, "timeout", "t", 5*time.Minute, "The timeout for each check")
+	cmd.Flags().BoolVarP(&options.SkipHelm, "skip-helm", "", false, "Skips installing the test helm release")
+	cmd.Flags().BoolVarP(&options.SkipCanary, "skip-canary", "", false, "Skips running the canary build pod")
+	cmd.Flags().BoolVarP(&options.SkipWebhook, "skip-webhook", "", false, "Skips checking the webhook endpoint")
+	cmd.Flags().BoolVarP(&options.SkipDNS, "skip-dns", "", false, "Skips checking the DNS resolution of the ingress hosts")
+	options.AddOutputFlag(cmd, &options.Output)
+	return cmd
+}
+
+// Run implements this command
+func (o *InstallOptions) Run() error {
+	o.RedirectLogsForOutput(o.Output)
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return errors.Wrap(err, "creating the kube client")
+	}
+	if o.Namespace != "" {
+		ns = o.Namespace
+	}
+	log.Logger().Infof("Verifying the Jenkins X installation in namespace %s", util.ColorInfo(ns))
+
+	ingresses, err := kubeClient.ExtensionsV1beta1().Ingresses(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "listing ingresses in namespace %s", ns)
+	}
+
+	o.checkResults = nil
+	if !o.SkipWebhook {
+		o.check("webhook", func() error { return o.verifyWebhook(ingresses.Items) },
+			"check the ingress controller is running and the hook or jenkins ingress exists: 'jx upgrade ingress' can recreate the ingress rules")
+	}
+	if !o.SkipDNS {
+		o.check("dns", func() error { return o.verifyDNS(ingresses.Items) },
+			"check the DNS records for the domain point at the ingress controller's external IP or use 'jx step verify dns' to wait for propagation")
+	}
+	if !o.SkipHelm {
+		o.check("helm release", func() error { return o.verifyHelmRelease(ns) },
+			"check helm is installed and configured for the cluster, and if using tiller that it is running")
+	}
+	if !o.SkipCanary {
+		o.check("canary build pod", func() error { return o.verifyCanaryPod(kubeClient, ns) },
+			"check the nodes have enough capacity with 'kubectl describe nodes' and that images can be pulled from the registry")
+	}
+	return o.report()
+}
+
+// CheckResults returns the results of the last run
+func (o *InstallOptions) CheckResults() []CheckResult {
+	return o.checkResults
+}
+
+func (o *InstallOptions) check(name string, verify func() error, remediation string) {
+	defer util.StartLogOperation(name)()
+	err := verify()
+	o.checkResults = append(o.checkResults, CheckResult{
+		Name:        name,
+		Error:       err,
+		Remediation: remediation,
+	})
+}
+
+func (o *InstallOptions) report() error {
+	if o.Output != "" {
+		err := o.RenderOutput(o.Output, o.checkResults)
+		if err != nil {
+			return err
+		}
+	}
+	failed := []string{}
+	for _, r := range o.checkResults {
+		if r.Passed() {
+			log.Logger().Infof("%s %s", util.ColorInfo("PASS"), r.Name)
+			continue
+		}
+		failed = append(failed, r.Name)
+		log.Logger().Errorf("%s %s: %s", util.ColorError("FAIL"), r.Name, r.Error)
+		log.Logger().Infof("  remediation: %s", r.Remediation)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("the installation failed the checks: %s", strings.Join(failed, ", "))
+	}
+	log.Logger().Infof("The installation passed all %d checks", len(o.checkResults))
+	return nil
+}
+
+func (o *InstallOptions) verifyWebhook(ingresses []v1beta1.Ingress) error {
+	var hook *v1beta1.Ingress
+	for _, name := range []string{"hook", "jenkins"} {
+		for i := range ingresses {
+			if ingresses[i].Name == name {
+				hook = &ingresses[i]
+				break
+			}
+		}
+		if hook != nil {
+			break
+		}
+	}
+	if hook == nil {
+		return fmt.Errorf("no hook or jenkins ingress found")
+	}
+	u := services.IngressURL(hook)
+	if u == "" {
+		return fmt.Errorf("ingress %s has no host", hook.Name)
+	}
+	if hook.Name == "hook" {
+		u = util.UrlJoin(u, "hook")
+	}
+	resp, err := util.GetClientWithTimeout(o.Timeout).Get(u)
+	if err != nil {
+		return errors.Wrapf(err, "calling webhook endpoint %s", u)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("webhook endpoint %s returned status %d", u, resp.StatusCode)
+	}
+	log.Logger().Debugf("webhook endpoint %s returned status %d", u, resp.StatusCode)
+	return nil
+}
+
+func (o *InstallOptions) verifyDNS(ingresses []v1beta1.Ingress) error {
+	hosts := IngressHosts(ingresses)
+	if len(hosts) == 0 {
+		return fmt.Errorf("no ingress hosts found")
+	}
+	failed := []string{}
+	for _, host := range hosts {
+		_, err := net.LookupHost(host)
+		if err != nil {
+			log.Logger().Debugf("failed to resolve %s: %s", host, err)
+			failed = append(failed, host)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to resolve hosts: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// IngressHosts returns the sorted unique hosts of the given ingresses
+func IngressHosts(ingresses []v1beta1.Ingress) []string {
+	hosts := map[string]bool{}
+	for _, ing := range ingresses {
+		for _, rule := range ing.Spec.Rules {
+			if rule.Host != "" {
+				hosts[rule.Host] = true
+			}
+		}
+	}
+	answer := []string{}
+	for h := range hosts {
+		answer = append(answer, h)
+	}
+	sort.Strings(answer)
+	return answer
+}
+
+func (o *InstallOptions) verifyHelmRelease(ns string) error {
+	dir, err := ioutil.TempDir("", "jx-verify-chart-")
+	if err != nil {
+		return errors.Wrap(err, "creating temporary chart dir")
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"Chart.yaml": "apiVersion: v1\nname: " + testReleaseName + "\nversion: 0.0.1\ndescription: test chart used by jx verify install\n",
+		filepath.Join("templates", "configmap.yaml"): "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + testReleaseName + "\ndata:\n  verified: \"true\"\n",
+	}
+	for name, text := range files {
+		fileName := filepath.Join(dir, name)
+		err = os.MkdirAll(filepath.Dir(fileName), util.DefaultWritePermissions)
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(fileName, []byte(text), util.DefaultFileWritePermissions)
+		if err != nil {
+			return errors.Wrapf(err, "writing file %s", fileName)
+		}
+	}
+
+	helmer := o.Helm()
+	timeout := int(o.Timeout.Seconds())
+	err = helmer.InstallChart(dir, testReleaseName, ns, "", timeout, nil, nil, nil, "", "", "")
+	if err != nil {
+		return errors.Wrapf(err, "installing test release %s", testReleaseName)
+	}
+	err = helmer.DeleteRelease(ns, testReleaseName, true)
+	if err != nil {
+		return errors.Wrapf(err, "deleting test release %s", testReleaseName)
+	}
+	return nil
+}
+
+func (o *InstallOptions) verifyCanaryPod(kubeClient kubernetes.Interface, ns string) error {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "jx-verify-canary-",
+			Labels: map[string]string{
+				kube.LabelCreatedBy: kube.ValueCreatedByJX,
+			},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:    "canary",
+					Image:   o.Image,
+					Command: []string{"sh", "-c", "echo jx verify install"},
+				},
+			},
+		},
+	}
+	podInterface := kubeClient.CoreV1().Pods(ns)
+	pod, err := podInterface.Create(pod)
+	if err != nil {
+		return errors.Wrapf(err, "creating canary pod in namespace %s", ns)
+	}
+	name := pod.Name
+	defer podInterface.Delete(name, &metav1.DeleteOptions{}) //nolint:errcheck
+
+	err = kube.WaitForPodNameToBeComplete(kubeClient, ns, name, o.Timeout)
+	if err != nil {
+		return errors.Wrapf(err, "waiting for canary pod %s to complete", name)
+	}
+	pod, err = podInterface.Get(name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "getting canary pod %s", name)
+	}
+	if !kube.IsPodSucceeded(pod) {
+		return fmt.Errorf("canary pod %s finished with status %s", name, kube.PodStatus(pod))
+	}
+	return nil
+}
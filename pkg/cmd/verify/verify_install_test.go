@@ -0,0 +1,109 @@
+// +build unit
+
+package verify_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/testhelpers"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/verify"
+	gits_test "github.com/jenkins-x/jx/v2/pkg/gits/mocks"
+	helm_test "github.com/jenkins-x/jx/v2/pkg/helm/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestIngressHosts(t *testing.T) {
+	t.Parallel()
+
+	ingresses := []v1beta1.Ingress{
+		createIngress("hook", "hook.jx.example.com"),
+		createIngress("chartmuseum", "chartmuseum.jx.example.com"),
+		createIngress("hook-duplicate", "hook.jx.example.com"),
+	}
+	assert.Equal(t, []string{"chartmuseum.jx.example.com", "hook.jx.example.com"}, verify.IngressHosts(ingresses))
+}
+
+func TestCheckResultJSON(t *testing.T) {
+	t.Parallel()
+
+	results := []verify.CheckResult{
+		{Name: "webhook", Remediation: "check the ingress"},
+		{Name: "dns", Error: errors.New("no such host"), Remediation: "check the DNS records"},
+	}
+	data, err := json.Marshal(results)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"name":"webhook","passed":true},{"name":"dns","passed":false,"error":"no such host","remediation":"check the DNS records"}]`, string(data))
+}
+
+func TestVerifyInstallWebhookAndHelm(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	hook := createIngress("hook", strings.TrimPrefix(server.URL, "http://"))
+	o := &verify.InstallOptions{
+		CommonOptions: &opts.CommonOptions{},
+		SkipDNS:       true,
+		SkipCanary:    true,
+	}
+	testhelpers.ConfigureTestOptionsWithResources(o.CommonOptions, []runtime.Object{&hook}, nil, gits_test.NewMockGitter(), nil, helm_test.NewMockHelmer(), nil)
+
+	err := o.Run()
+	require.NoError(t, err)
+
+	results := o.CheckResults()
+	require.Len(t, results, 2)
+	assert.Equal(t, "webhook", results[0].Name)
+	assert.True(t, results[0].Passed())
+	assert.Equal(t, "helm release", results[1].Name)
+	assert.True(t, results[1].Passed())
+}
+
+func TestVerifyInstallMissingWebhook(t *testing.T) {
+	t.Parallel()
+
+	o := &verify.InstallOptions{
+		CommonOptions: &opts.CommonOptions{},
+		SkipDNS:       true,
+		SkipCanary:    true,
+		SkipHelm:      true,
+	}
+	testhelpers.ConfigureTestOptions(o.CommonOptions, gits_test.NewMockGitter(), helm_test.NewMockHelmer())
+
+	err := o.Run()
+	require.Error(t, err)
+
+	results := o.CheckResults()
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Passed())
+	assert.NotEmpty(t, results[0].Remediation)
+}
+
+func createIngress(name string, host string) v1beta1.Ingress {
+	return v1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "jx",
+		},
+		Spec: v1beta1.IngressSpec{
+			Rules: []v1beta1.IngressRule{
+				{
+					Host: host,
+				},
+			},
+		},
+	}
+}
@@ -59,6 +59,24 @@ const defaultMavenSettings = `<settings>
   </settings>
 `
 
+// mavenCentralSettings the maven settings of installations without Nexus which resolve the artifacts from Maven Central
+const mavenCentralSettings = `<settings>
+      <!-- sets the local maven repository outside of the ~/.m2 folder for easier mounting of secrets and repo -->
+      <localRepository>${user.home}/.mvnrepository</localRepository>
+      <!-- lets disable the download progress indicator that fills up logs -->
+      <interactiveMode>false</interactiveMode>
+      <profiles>
+          <profile>
+              <id>release</id>
+              <properties>
+                  <gpg.executable>gpg</gpg.executable>
+                  <gpg.passphrase>mysecretpassphrase</gpg.passphrase>
+              </properties>
+          </profile>
+      </profiles>
+  </settings>
+`
+
 const allowedSymbols = "~!#%^_+-=?,."
 
 type ChartMuseum struct {
@@ -125,6 +143,8 @@ type AdminSecretsFlags struct {
 	DefaultAdminUsername string
 	DefaultAdminPassword string
 	KanikoSecret         string
+	// NoNexus generates maven settings which resolve the artifacts from Maven Central rather than Nexus
+	NoNexus bool
 }
 
 func (s *AdminSecretsService) AddAdminSecretsValues(cmd *cobra.Command) {
@@ -145,16 +165,11 @@ func (s *AdminSecretsService) NewAdminSecretsConfig() error {
 	if s.Flags.DefaultAdminPassword == "" {
 		log.Logger().Debugf("No default password set, generating a random one")
 
-		input := password.GeneratorInput{
-			Symbols: allowedSymbols,
-		}
-
-		generator, err := password.NewGenerator(&input)
+		generated, err := GenerateAdminPassword()
 		if err != nil {
-			return errors.Wrap(err, "unable to create password generator")
+			return err
 		}
-
-		s.Flags.DefaultAdminPassword, _ = generator.Generate(20, 4, 2, false, true)
+		s.Flags.DefaultAdminPassword = generated
 	}
 
 	err := s.setDefaultSecrets()
@@ -171,6 +186,24 @@ func (s *AdminSecretsService) NewAdminSecretsConfig() error {
 	return nil
 }
 
+// GenerateAdminPassword generates a random admin password using only the symbols which are safe in the charts
+func GenerateAdminPassword() (string, error) {
+	input := password.GeneratorInput{
+		Symbols: allowedSymbols,
+	}
+
+	generator, err := password.NewGenerator(&input)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to create password generator")
+	}
+	return generator.Generate(20, 4, 2, false, true)
+}
+
+// IngressBasicAuthEntry returns the htpasswd entry of the user and password used by the ingress basic auth
+func IngressBasicAuthEntry(username string, password string) string {
+	return fmt.Sprintf("%s:{SHA}%s", username, util.HashPassword(password))
+}
+
 func (s *AdminSecretsService) setDefaultSecrets() error {
 	s.Secrets.Jenkins.JenkinsSecret.Password = s.Flags.DefaultAdminPassword
 	s.Secrets.ChartMuseum.ChartMuseumEnv.ChartMuseumSecret.User = "admin"
@@ -183,6 +216,10 @@ func (s *AdminSecretsService) setDefaultSecrets() error {
 
 // NewMavenSettingsXML generates the maven settings
 func (s *AdminSecretsService) NewMavenSettingsXML() error {
+	if s.Flags.NoNexus {
+		s.Secrets.PipelineSecrets.MavenSettingsXML = mavenCentralSettings
+		return nil
+	}
 	s.Secrets.PipelineSecrets.MavenSettingsXML = fmt.Sprintf(defaultMavenSettings, s.Flags.DefaultAdminPassword)
 	return nil
 }
@@ -223,8 +260,7 @@ func (s *AdminSecretsService) newIngressBasicAuth() {
 		Username: "admin",
 		Password: password,
 	}
-	hash := util.HashPassword(password)
-	s.Secrets.IngressBasicAuth = fmt.Sprintf("%s:{SHA}%s", username, hash)
+	s.Secrets.IngressBasicAuth = IngressBasicAuthEntry(username, password)
 }
 
 func (s *AdminSecretsService) updateIngressBasicAuth() {
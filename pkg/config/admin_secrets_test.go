@@ -34,3 +34,18 @@ func TestAdminSecrets(t *testing.T) {
 
 	assert.Equal(t, secretsFromFile, secretsFromService, "expected admin secret values do not match")
 }
+
+func TestAdminSecretsWithoutNexus(t *testing.T) {
+	t.Parallel()
+
+	service := config.AdminSecretsService{}
+	service.Flags.DefaultAdminPassword = "admin-password"
+	service.Flags.NoNexus = true
+
+	err := service.NewAdminSecretsConfig()
+	assert.NoError(t, err)
+
+	settings := service.Secrets.PipelineSecrets.MavenSettingsXML
+	assert.NotContains(t, settings, "http://nexus")
+	assert.NotContains(t, settings, "admin-password")
+}
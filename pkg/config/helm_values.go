@@ -75,6 +75,7 @@ type HelmValuesConfig struct {
 	ControllerBuild       *EnabledConfig                     `json:"controllerbuild,omitempty"`
 	ControllerWorkflow    *EnabledConfig                     `json:"controllerworkflow,omitempty"`
 	DockerRegistryEnabled *EnabledConfig                     `json:"docker-registry,omitempty"`
+	NexusEnabled          *EnabledConfig                     `json:"nexus,omitempty"`
 	DockerRegistry        string                             `json:"dockerRegistry,omitempty"`
 }
 
@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/ghodss/yaml"
@@ -19,6 +21,7 @@ import (
 	"github.com/jenkins-x/jx-logging/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/cloud"
 	"github.com/jenkins-x/jx/v2/pkg/cloud/gke"
+	"github.com/jenkins-x/jx/v2/pkg/tekton/syntax"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 )
 
@@ -87,6 +90,8 @@ const (
 	RequirementKanikoServiceAccountName = "JX_REQUIREMENT_KANIKO_SA_NAME"
 	// RequirementKaniko if kaniko is required
 	RequirementKaniko = "JX_REQUIREMENT_KANIKO"
+	// RequirementWorkloadIdentity if the pipelines should authenticate against the cloud via workload identity federation
+	RequirementWorkloadIdentity = "JX_REQUIREMENT_WORKLOAD_IDENTITY"
 	// RequirementIngressTLSProduction use the lets encrypt production server
 	RequirementIngressTLSProduction = "JX_REQUIREMENT_INGRESS_TLS_PRODUCTION"
 	// RequirementChartRepository the helm chart repository for jx
@@ -95,6 +100,8 @@ const (
 	RequirementRegistry = "JX_REQUIREMENT_REGISTRY"
 	// RequirementRepository the artifact repository for jx
 	RequirementRepository = "JX_REQUIREMENT_REPOSITORY"
+	// RequirementRepositoryURL the URL of an artifact repository which is not installed by jx
+	RequirementRepositoryURL = "JX_REQUIREMENT_REPOSITORY_URL"
 	// RequirementWebhook the webhook handler for jx
 	RequirementWebhook = "JX_REQUIREMENT_WEBHOOK"
 	// RequirementStorageBackupEnabled if backup storage is required
@@ -312,6 +319,8 @@ type AzureConfig struct {
 	// RegistrySubscription the registry subscription for defaulting the container registry.
 	// Not used if you specify a Registry explicitly
 	RegistrySubscription string `json:"registrySubscription,omitempty"`
+	// ResourceGroup the resource group of the cluster and of the managed identities federated with it
+	ResourceGroup string `json:"resourceGroup,omitempty"`
 }
 
 // GKEConfig contains GKE specific requirements
@@ -324,7 +333,8 @@ type GKEConfig struct {
 type ClusterConfig struct {
 	// AzureConfig the azure specific configuration
 	AzureConfig *AzureConfig `json:"azure,omitempty"`
-	// ChartRepository the repository URL to deploy charts to
+	// ChartRepository the repository URL to deploy charts to. Either the URL of chartmuseum, a gs://, s3:// or azblob://
+	// bucket URL or an oci:// registry URL
 	ChartRepository string `json:"chartRepository,omitempty" envconfig:"JX_REQUIREMENT_CHART_REPOSITORY"`
 	// GKEConfig the gke specific configuration
 	GKEConfig *GKEConfig `json:"gke,omitempty"`
@@ -364,6 +374,8 @@ type ClusterConfig struct {
 	VaultSAName string `json:"vaultSAName,omitempty"`
 	// KanikoSAName the service account name for kaniko
 	KanikoSAName string `json:"kanikoSAName,omitempty" envconfig:"JX_REQUIREMENT_KANIKO_SA_NAME"`
+	// WorkloadIdentity federates the pipeline service account with a cloud identity instead of using long-lived keys
+	WorkloadIdentity bool `json:"workloadIdentity,omitempty" envconfig:"JX_REQUIREMENT_WORKLOAD_IDENTITY"`
 	// HelmMajorVersion contains the major helm version number. Assumes helm 2.x with no tiller if no value specified
 	HelmMajorVersion string `json:"helmMajorVersion,omitempty"`
 	// DevEnvApprovers contains an optional list of approvers to populate the initial OWNERS file in the dev env repo
@@ -503,6 +515,135 @@ type VeleroConfig struct {
 	TimeToLive string `json:"ttl" envconfig:"JX_REQUIREMENT_VELERO_TTL"`
 }
 
+// PostInstallHookFailurePolicy what to do if a post install hook fails
+type PostInstallHookFailurePolicy string
+
+const (
+	// PostInstallHookFailurePolicyFail fails the installation if the hook fails
+	PostInstallHookFailurePolicyFail PostInstallHookFailurePolicy = "Fail"
+	// PostInstallHookFailurePolicyIgnore logs a warning and carries on if the hook fails
+	PostInstallHookFailurePolicyIgnore PostInstallHookFailurePolicy = "Ignore"
+)
+
+// PostInstallConfig contains the hooks which are run at the end of the installation
+type PostInstallConfig struct {
+	// TrustedRepositories the URL prefixes from which hook scripts may be downloaded
+	TrustedRepositories []string `json:"trustedRepositories,omitempty"`
+	// Hooks the hooks to run
+	Hooks []PostInstallHook `json:"hooks,omitempty"`
+}
+
+// PostInstallHook a hook which is run at the end of the installation. Each hook should specify exactly one
+// of a chart, a script or a list of manifests
+type PostInstallHook struct {
+	// Name the name of the hook
+	Name string `json:"name"`
+	// Order hooks are run in ascending order. Hooks with the same order run in the order they are declared
+	Order int `json:"order,omitempty"`
+	// FailurePolicy either Fail or Ignore. Defaults to Fail
+	FailurePolicy PostInstallHookFailurePolicy `json:"failurePolicy,omitempty"`
+	// Namespace the namespace the hook installs into. Defaults to the installation namespace
+	Namespace string `json:"namespace,omitempty"`
+	// Chart a helm chart to install
+	Chart *PostInstallChart `json:"chart,omitempty"`
+	// Script a script to download from a trusted repository and run
+	Script *PostInstallScript `json:"script,omitempty"`
+	// Manifests the files or URLs of Kubernetes manifests to apply via kubectl. Relative files are resolved against the
+	// directory of the requirements file
+	Manifests []string `json:"manifests,omitempty"`
+}
+
+// PostInstallChart a helm chart installed by a post install hook
+type PostInstallChart struct {
+	// Name the name of the chart such as 'jenkins-x/my-chart'
+	Name string `json:"name"`
+	// ReleaseName the helm release name. Defaults to the hook name
+	ReleaseName string `json:"releaseName,omitempty"`
+	// Repository the chart repository URL
+	Repository string `json:"repository,omitempty"`
+	// Version the chart version. Defaults to the version in the version stream
+	Version string `json:"version,omitempty"`
+	// Values the 'name=value' pairs to set on the chart
+	Values []string `json:"values,omitempty"`
+}
+
+// PostInstallScript a script run by a post install hook
+type PostInstallScript struct {
+	// URL the URL of the script which must be inside one of the trusted repositories
+	URL string `json:"url"`
+	// Args the arguments passed to the script
+	Args []string `json:"args,omitempty"`
+}
+
+// Validate returns an error if the hook does not specify exactly one action
+func (h *PostInstallHook) Validate() error {
+	if h.Name == "" {
+		return errors.New("post install hook is missing a name")
+	}
+	count := 0
+	if h.Chart != nil {
+		count++
+	}
+	if h.Script != nil {
+		count++
+	}
+	if len(h.Manifests) > 0 {
+		count++
+	}
+	if count != 1 {
+		return fmt.Errorf("post install hook %s must specify exactly one of chart, script or manifests", h.Name)
+	}
+	switch h.FailurePolicy {
+	case "", PostInstallHookFailurePolicyFail, PostInstallHookFailurePolicyIgnore:
+	default:
+		return fmt.Errorf("post install hook %s has an unknown failure policy %s", h.Name, h.FailurePolicy)
+	}
+	return nil
+}
+
+// IgnoreFailure returns true if a failure of the hook should not fail the installation
+func (h *PostInstallHook) IgnoreFailure() bool {
+	return h.FailurePolicy == PostInstallHookFailurePolicyIgnore
+}
+
+// SortedHooks returns the hooks in the order they should be run
+func (c *PostInstallConfig) SortedHooks() []PostInstallHook {
+	answer := append([]PostInstallHook{}, c.Hooks...)
+	sort.SliceStable(answer, func(i, j int) bool {
+		return answer[i].Order < answer[j].Order
+	})
+	return answer
+}
+
+// IsTrustedURL returns true if the URL is inside one of the trusted repositories. The scheme and host must match the
+// repository exactly and the cleaned path must be the repository path or inside it, so that URLs such as
+// 'https://host/repo-evil' or 'https://host/repo/../other' are not trusted
+func (c *PostInstallConfig) IsTrustedURL(u string) bool {
+	target, err := url.Parse(u)
+	if err != nil || target.Scheme == "" || target.Host == "" {
+		return false
+	}
+	targetPath := path.Clean("/" + target.Path)
+	for _, repo := range c.TrustedRepositories {
+		if repo == "" {
+			continue
+		}
+		trusted, err := url.Parse(repo)
+		if err != nil || trusted.Scheme == "" || trusted.Host == "" {
+			log.Logger().Warnf("ignoring the trusted repository %s which is not a valid URL", repo)
+			continue
+		}
+		if target.Scheme != trusted.Scheme || !strings.EqualFold(target.Host, trusted.Host) {
+			continue
+		}
+		trustedPath := path.Clean("/" + trusted.Path)
+		if trustedPath == "/" || targetPath == trustedPath || strings.HasPrefix(targetPath, trustedPath+"/") {
+			return true
+		}
+	}
+	return false
+}
+
 // AutoUpdateConfig contains auto update config
 type AutoUpdateConfig struct {
 	// Enabled autoupdate
@@ -542,10 +683,15 @@ type RequirementsConfig struct {
 	AutoUpdate AutoUpdateConfig `json:"autoUpdate,omitempty"`
 	// BootConfigURL contains the url to which the dev environment is associated with
 	BootConfigURL string `json:"bootConfigURL,omitempty"`
+	// BuildEngine the default engine building the images of the pipelines of the team
+	BuildEngine *syntax.BuildEngine `json:"buildEngine,omitempty"`
 	// BuildPackConfig contains custom build pack settings
 	BuildPacks *BuildPackConfig `json:"buildPacks,omitempty"`
 	// Cluster contains cluster specific requirements
 	Cluster ClusterConfig `json:"cluster"`
+	// Crossplane wires the connection secrets of the Crossplane claims of the apps when the chart of an environment
+	// is applied
+	Crossplane bool `json:"crossplane,omitempty"`
 	// Environments the requirements for the environments
 	Environments []EnvironmentConfig `json:"environments,omitempty"`
 	// GithubApp contains github app config
@@ -562,8 +708,12 @@ type RequirementsConfig struct {
 	Ingress IngressConfig `json:"ingress"`
 	// PipelineUser the user name and email used for running pipelines
 	PipelineUser *UserNameEmailConfig `json:"pipelineUser,omitempty"`
+	// PostInstall the hooks to run at the end of the installation
+	PostInstall *PostInstallConfig `json:"postInstall,omitempty"`
 	// Repository specifies what kind of artifact repository you wish to use for storing artifacts (jars, tarballs, npm modules etc)
 	Repository RepositoryType `json:"repository,omitempty" envconfig:"JX_REQUIREMENT_REPOSITORY"`
+	// RepositoryURL the URL of an existing artifact repository such as Artifactory which is used rather than installing one
+	RepositoryURL string `json:"repositoryUrl,omitempty" envconfig:"JX_REQUIREMENT_REPOSITORY_URL"`
 	// SecretStorage how should we store secrets for the cluster
 	SecretStorage SecretStorageType `json:"secretStorage,omitempty" envconfig:"JX_REQUIREMENT_SECRET_STORAGE_TYPE"`
 	// Storage contains storage requirements
@@ -691,7 +841,7 @@ func (c *RequirementsConfig) SaveConfig(fileName string) error {
 	if err != nil {
 		return err
 	}
-	err = ioutil.WriteFile(fileName, data, util.DefaultWritePermissions)
+	err = util.WriteCanonicalYAMLFile(fileName, data, util.DefaultWritePermissions)
 	if err != nil {
 		return errors.Wrapf(err, "failed to save file %s", fileName)
 	}
@@ -705,7 +855,7 @@ func (c *RequirementsConfig) SaveConfig(fileName string) error {
 			return err
 		}
 
-		err = ioutil.WriteFile(path.Join(path.Dir(fileName), RequirementsValuesFileName), data, util.DefaultWritePermissions)
+		err = util.WriteCanonicalYAMLFile(path.Join(path.Dir(fileName), RequirementsValuesFileName), data, util.DefaultWritePermissions)
 		if err != nil {
 			return errors.Wrapf(err, "failed to save file %s", RequirementsValuesFileName)
 		}
@@ -104,6 +104,7 @@ func Test_OverrideRequirementsFromEnvironment_populate_requirements_from_environ
 		{config.RequirementKaniko, "false", config.RequirementsConfig{Kaniko: false}},
 		{config.RequirementKaniko, "", config.RequirementsConfig{Kaniko: false}},
 		{config.RequirementRepository, "bucketrepo", config.RequirementsConfig{Repository: "bucketrepo"}},
+		{config.RequirementRepositoryURL, "https://artifactory.example.com/artifactory", config.RequirementsConfig{RepositoryURL: "https://artifactory.example.com/artifactory"}},
 		{config.RequirementWebhook, "prow", config.RequirementsConfig{Webhook: "prow"}},
 		{config.RequirementGitAppEnabled, "true", config.RequirementsConfig{GithubApp: &config.GithubAppConfig{Enabled: true}}},
 		{config.RequirementGitAppEnabled, "false", config.RequirementsConfig{GithubApp: &config.GithubAppConfig{Enabled: false}}},
@@ -117,6 +118,7 @@ func Test_OverrideRequirementsFromEnvironment_populate_requirements_from_environ
 		{config.RequirementRegistry, "my-registry", config.RequirementsConfig{Cluster: config.ClusterConfig{Registry: "my-registry"}}},
 		{config.RequirementEnvGitOwner, "john-doe", config.RequirementsConfig{Cluster: config.ClusterConfig{EnvironmentGitOwner: "john-doe"}}},
 		{config.RequirementKanikoServiceAccountName, "kaniko-sa", config.RequirementsConfig{Cluster: config.ClusterConfig{KanikoSAName: "kaniko-sa"}}},
+		{config.RequirementWorkloadIdentity, "true", config.RequirementsConfig{Cluster: config.ClusterConfig{WorkloadIdentity: true}}},
 		{config.RequirementEnvGitPublic, "true", config.RequirementsConfig{Cluster: config.ClusterConfig{EnvironmentGitPublic: true}}},
 		{config.RequirementEnvGitPublic, "false", config.RequirementsConfig{Cluster: config.ClusterConfig{EnvironmentGitPublic: false}}},
 		{config.RequirementEnvGitPublic, "", config.RequirementsConfig{Cluster: config.ClusterConfig{EnvironmentGitPublic: false}}},
@@ -576,3 +578,45 @@ func TestLoadRequirementsConfig_load_invalid_yaml(t *testing.T) {
 	requirementsConfigPath := path.Join(absolute, config.RequirementsConfigFileName)
 	assert.EqualError(t, err, fmt.Sprintf("validation failures in YAML file %s:\nenvironments.0: Additional property namespace is not allowed", requirementsConfigPath))
 }
+
+func TestRequirementsConfigPostInstallHooks(t *testing.T) {
+	t.Parallel()
+
+	fileName := filepath.Join(testDataDir, "jx-requirements-post-install", config.RequirementsConfigFileName)
+	requirements, err := config.LoadRequirementsConfigFile(fileName, config.DefaultFailOnValidationError)
+	require.NoError(t, err, "failed to load file %s", fileName)
+	require.NotNil(t, requirements.PostInstall, "no postInstall configuration loaded from %s", fileName)
+
+	postInstall := requirements.PostInstall
+	hooks := postInstall.SortedHooks()
+	names := []string{}
+	for i := range hooks {
+		assert.NoError(t, hooks[i].Validate(), "hook %s", hooks[i].Name)
+		names = append(names, hooks[i].Name)
+	}
+	assert.Equal(t, []string{"monitoring", "policies", "register"}, names)
+	assert.True(t, hooks[0].IgnoreFailure())
+	assert.False(t, hooks[1].IgnoreFailure())
+
+	assert.True(t, postInstall.IsTrustedURL("https://raw.githubusercontent.com/acme/platform-hooks/master/register.sh"))
+	assert.False(t, postInstall.IsTrustedURL("https://raw.githubusercontent.com/acme/platform-hooks-evil/master/register.sh"))
+	assert.False(t, postInstall.IsTrustedURL("https://example.com/register.sh"))
+	assert.False(t, postInstall.IsTrustedURL("https://raw.githubusercontent.com/acme/platform-hooks/../other/register.sh"))
+	assert.False(t, postInstall.IsTrustedURL("https://raw.githubusercontent.com.evil.io/acme/platform-hooks/register.sh"))
+	assert.False(t, postInstall.IsTrustedURL("http://raw.githubusercontent.com/acme/platform-hooks/master/register.sh"))
+	assert.False(t, postInstall.IsTrustedURL("https://evil@example.com/acme/platform-hooks/master/register.sh"))
+	assert.False(t, postInstall.IsTrustedURL("acme/platform-hooks/master/register.sh"))
+
+	invalid := config.PostInstallHook{
+		Name:      "both",
+		Manifests: []string{"foo.yaml"},
+		Script:    &config.PostInstallScript{URL: "https://example.com/foo.sh"},
+	}
+	assert.Error(t, invalid.Validate())
+	invalid = config.PostInstallHook{
+		Name:          "bad-policy",
+		Manifests:     []string{"foo.yaml"},
+		FailurePolicy: "Retry",
+	}
+	assert.Error(t, invalid.Validate())
+}
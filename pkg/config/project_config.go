@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/jenkins-x/jx/v2/pkg/jenkinsfile"
@@ -41,6 +42,24 @@ type ProjectConfig struct {
 	NoReleasePrepare    bool                        `json:"noReleasePrepare,omitempty"`
 	DockerRegistryHost  string                      `json:"dockerRegistryHost,omitempty"`
 	DockerRegistryOwner string                      `json:"dockerRegistryOwner,omitempty"`
+	Module              *ModuleConfig               `json:"module,omitempty"`
+	Trigger             *TriggerConfig              `json:"trigger,omitempty"`
+}
+
+// ModuleConfig the module of a monorepo built by the pipelines of a project configuration
+type ModuleConfig struct {
+	// Name the name of the application of the module
+	Name string `json:"name"`
+	// Dir the directory of the module relative to the root of the repository
+	Dir string `json:"dir"`
+}
+
+// TriggerConfig configures which changes trigger the pipelines of a project
+type TriggerConfig struct {
+	// Paths the glob patterns of the files relative to the root of the repository whose changes trigger the pipelines.
+	// A pattern without wildcards matches the file or everything in the directory of that name. The pipelines are
+	// triggered by any change if empty
+	Paths []string `json:"paths,omitempty"`
 }
 
 type PreviewEnvironmentConfig struct {
@@ -170,3 +189,73 @@ func (c *ProjectConfig) GetPipeline(kind string) (*syntax.ParsedPipeline, error)
 	}
 	return parsed, nil
 }
+
+// RunIfChanged returns the regular expression matching the changed files which should trigger the pipelines or an
+// empty string if any change should
+func (c *TriggerConfig) RunIfChanged() string {
+	if c == nil {
+		return ""
+	}
+	return TriggerPathsRegex(c.Paths)
+}
+
+// Matches returns true if any of the changed files should trigger the pipelines
+func (c *TriggerConfig) Matches(files []string) (bool, error) {
+	expression := c.RunIfChanged()
+	if expression == "" {
+		return true, nil
+	}
+	r, err := regexp.Compile(expression)
+	if err != nil {
+		return false, errors.Wrapf(err, "parsing the trigger paths %s", strings.Join(c.Paths, ", "))
+	}
+	for _, f := range files {
+		if r.MatchString(strings.TrimPrefix(f, "/")) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// TriggerPathsRegex converts the glob patterns of trigger paths to a regular expression matching the changed files
+// in the format used by the run_if_changed of Prow and Lighthouse jobs. Within a pattern ** matches any number of
+// directories, * matches any characters apart from / and ? matches a single character apart from /
+func TriggerPathsRegex(paths []string) string {
+	expressions := []string{}
+	for _, path := range paths {
+		path = strings.TrimPrefix(strings.TrimSpace(path), "/")
+		if path == "" {
+			continue
+		}
+		if !strings.ContainsAny(path, "*?") {
+			if strings.HasSuffix(path, "/") {
+				expressions = append(expressions, regexp.QuoteMeta(path)+".*")
+			} else {
+				expressions = append(expressions, regexp.QuoteMeta(path)+"(/.*)?")
+			}
+			continue
+		}
+		buf := strings.Builder{}
+		for i := 0; i < len(path); i++ {
+			switch {
+			case strings.HasPrefix(path[i:], "**/"):
+				buf.WriteString("(.*/)?")
+				i += 2
+			case strings.HasPrefix(path[i:], "**"):
+				buf.WriteString(".*")
+				i++
+			case path[i] == '*':
+				buf.WriteString("[^/]*")
+			case path[i] == '?':
+				buf.WriteString("[^/]")
+			default:
+				buf.WriteString(regexp.QuoteMeta(path[i : i+1]))
+			}
+		}
+		expressions = append(expressions, buf.String())
+	}
+	if len(expressions) == 0 {
+		return ""
+	}
+	return "^(" + strings.Join(expressions, "|") + ")$"
+}
@@ -104,3 +104,44 @@ func TestGetPipeline(t *testing.T) {
 	assert.Equal(t, err.Error(), "no pipeline defined for kind feature")
 	assert.Nil(t, featurePipeline)
 }
+
+func TestTriggerConfig(t *testing.T) {
+	t.Parallel()
+
+	var empty *config.TriggerConfig
+	assert.Equal(t, "", empty.RunIfChanged())
+	matches, err := empty.Matches([]string{"README.md"})
+	assert.NoError(t, err)
+	assert.True(t, matches, "any change should trigger the pipelines without trigger paths")
+
+	trigger := &config.TriggerConfig{Paths: []string{"services/api", "web/", "**/*.proto", "charts/*/values.yaml", "/go.?od"}}
+	assert.Equal(t, `^(services/api(/.*)?|web/.*|(.*/)?[^/]*\.proto|charts/[^/]*/values\.yaml|go\.[^/]od)$`, trigger.RunIfChanged())
+
+	testCases := map[string]bool{
+		"services/api/main.go":         true,
+		"services/api":                 true,
+		"services/api-gateway/main.go": false,
+		"web/index.html":               true,
+		"api.proto":                    true,
+		"proto/v1/api.proto":           true,
+		"charts/api/values.yaml":       true,
+		"charts/api/sub/values.yaml":   false,
+		"go.mod":                       true,
+		"/go.mod":                      true,
+		"README.md":                    false,
+	}
+	for file, expected := range testCases {
+		matches, err := trigger.Matches([]string{file})
+		assert.NoError(t, err)
+		assert.Equal(t, expected, matches, "for file %s", file)
+	}
+
+	matches, err = trigger.Matches([]string{"README.md", "web/app.js"})
+	assert.NoError(t, err)
+	assert.True(t, matches)
+
+	projectConfig := &config.ProjectConfig{}
+	err = yaml.Unmarshal([]byte("trigger:\n  paths:\n  - services/api\n"), projectConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"services/api"}, projectConfig.Trigger.Paths)
+}
@@ -6,6 +6,7 @@ package config
 
 import (
 	jenkinsfile "github.com/jenkins-x/jx/v2/pkg/jenkinsfile"
+	syntax "github.com/jenkins-x/jx/v2/pkg/tekton/syntax"
 	v1 "k8s.io/api/core/v1"
 )
 
@@ -485,6 +486,11 @@ func (in *HelmValuesConfig) DeepCopyInto(out *HelmValuesConfig) {
 		*out = new(EnabledConfig)
 		**out = **in
 	}
+	if in.NexusEnabled != nil {
+		in, out := &in.NexusEnabled, &out.NexusEnabled
+		*out = new(EnabledConfig)
+		**out = **in
+	}
 	return
 }
 
@@ -749,6 +755,22 @@ func (in *KanikoSecret) DeepCopy() *KanikoSecret {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModuleConfig) DeepCopyInto(out *ModuleConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModuleConfig.
+func (in *ModuleConfig) DeepCopy() *ModuleConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ModuleConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Nexus) DeepCopyInto(out *Nexus) {
 	*out = *in
@@ -781,6 +803,107 @@ func (in *PipelineSecrets) DeepCopy() *PipelineSecrets {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostInstallChart) DeepCopyInto(out *PostInstallChart) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostInstallChart.
+func (in *PostInstallChart) DeepCopy() *PostInstallChart {
+	if in == nil {
+		return nil
+	}
+	out := new(PostInstallChart)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostInstallConfig) DeepCopyInto(out *PostInstallConfig) {
+	*out = *in
+	if in.TrustedRepositories != nil {
+		in, out := &in.TrustedRepositories, &out.TrustedRepositories
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]PostInstallHook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostInstallConfig.
+func (in *PostInstallConfig) DeepCopy() *PostInstallConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PostInstallConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostInstallHook) DeepCopyInto(out *PostInstallHook) {
+	*out = *in
+	if in.Chart != nil {
+		in, out := &in.Chart, &out.Chart
+		*out = new(PostInstallChart)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Script != nil {
+		in, out := &in.Script, &out.Script
+		*out = new(PostInstallScript)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Manifests != nil {
+		in, out := &in.Manifests, &out.Manifests
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostInstallHook.
+func (in *PostInstallHook) DeepCopy() *PostInstallHook {
+	if in == nil {
+		return nil
+	}
+	out := new(PostInstallHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostInstallScript) DeepCopyInto(out *PostInstallScript) {
+	*out = *in
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostInstallScript.
+func (in *PostInstallScript) DeepCopy() *PostInstallScript {
+	if in == nil {
+		return nil
+	}
+	out := new(PostInstallScript)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Preview) DeepCopyInto(out *Preview) {
 	*out = *in
@@ -890,6 +1013,16 @@ func (in *ProjectConfig) DeepCopyInto(out *ProjectConfig) {
 		*out = new(jenkinsfile.PipelineConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Module != nil {
+		in, out := &in.Module, &out.Module
+		*out = new(ModuleConfig)
+		**out = **in
+	}
+	if in.Trigger != nil {
+		in, out := &in.Trigger, &out.Trigger
+		*out = new(TriggerConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -923,6 +1056,11 @@ func (in *ProwValuesConfig) DeepCopy() *ProwValuesConfig {
 func (in *RequirementsConfig) DeepCopyInto(out *RequirementsConfig) {
 	*out = *in
 	out.AutoUpdate = in.AutoUpdate
+	if in.BuildEngine != nil {
+		in, out := &in.BuildEngine, &out.BuildEngine
+		*out = new(syntax.BuildEngine)
+		(*in).DeepCopyInto(*out)
+	}
 	out.BuildPacks = in.BuildPacks
 	in.Cluster.DeepCopyInto(&out.Cluster)
 	if in.Environments != nil {
@@ -936,6 +1074,11 @@ func (in *RequirementsConfig) DeepCopyInto(out *RequirementsConfig) {
 		**out = **in
 	}
 	out.Ingress = in.Ingress
+	if in.PostInstall != nil {
+		in, out := &in.PostInstall, &out.PostInstall
+		*out = new(PostInstallConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	out.Storage = in.Storage
 	in.Vault.DeepCopyInto(&out.Vault)
 	out.Velero = in.Velero
@@ -1026,6 +1169,27 @@ func (in *TLSConfig) DeepCopy() *TLSConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TriggerConfig) DeepCopyInto(out *TriggerConfig) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TriggerConfig.
+func (in *TriggerConfig) DeepCopy() *TriggerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TriggerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VaultAWSConfig) DeepCopyInto(out *VaultAWSConfig) {
 	*out = *in
@@ -0,0 +1,228 @@
+package crossplane
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// ConnectionSecretSuffix the suffix of the name of the connection secrets jx wires into the namespace of a claim
+	ConnectionSecretSuffix = "-connection"
+
+	// StatusReady the claim is bound to a ready composite resource
+	StatusReady = "Ready"
+	// StatusPending the composite resource of the claim is still being provisioned
+	StatusPending = "Pending"
+)
+
+// CompositeResourceDefinitionResource the resource of the Crossplane definitions of the claims apps can declare
+var CompositeResourceDefinitionResource = schema.GroupVersionResource{Group: "apiextensions.crossplane.io", Version: "v1", Resource: "compositeresourcedefinitions"}
+
+// ProviderResource the resource of the Crossplane providers of the cloud resources
+var ProviderResource = schema.GroupVersionResource{Group: "pkg.crossplane.io", Version: "v1", Resource: "providers"}
+
+// ClaimResource the resource of a kind of claim such as a PostgreSQLInstance or a Bucket
+type ClaimResource struct {
+	Kind     string
+	Resource schema.GroupVersionResource
+}
+
+// Claim a cloud resource claimed by an app
+type Claim struct {
+	Kind             string
+	Namespace        string
+	Name             string
+	Status           string
+	Message          string
+	ConnectionSecret string
+}
+
+// ConnectionSecretName returns the name of the connection secret jx wires into the namespace of the claim
+func ConnectionSecretName(claim string) string {
+	return claim + ConnectionSecretSuffix
+}
+
+// ClaimResources returns the resources of the claims offered by the composite resource definitions of the cluster
+// or nil if Crossplane is not installed or the definitions cannot be listed without cluster scoped permissions
+func ClaimResources(client dynamic.Interface) ([]ClaimResource, error) {
+	list, err := client.Resource(CompositeResourceDefinitionResource).List(metav1.ListOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		if apierrors.IsForbidden(err) {
+			log.Logger().Debugf("not allowed to list the CompositeResourceDefinitions: %s", err.Error())
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "listing the CompositeResourceDefinitions")
+	}
+	answer := []ClaimResource{}
+	for i := range list.Items {
+		xrd := &list.Items[i]
+		kind, _, _ := unstructured.NestedString(xrd.Object, "spec", "claimNames", "kind")
+		plural, _, _ := unstructured.NestedString(xrd.Object, "spec", "claimNames", "plural")
+		if kind == "" || plural == "" {
+			continue
+		}
+		group, _, _ := unstructured.NestedString(xrd.Object, "spec", "group")
+		version := referenceableVersion(xrd)
+		if version == "" {
+			continue
+		}
+		answer = append(answer, ClaimResource{
+			Kind:     kind,
+			Resource: schema.GroupVersionResource{Group: group, Version: version, Resource: plural},
+		})
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Kind < answer[j].Kind
+	})
+	return answer, nil
+}
+
+// referenceableVersion returns the version of the definition used to store its resources
+func referenceableVersion(xrd *unstructured.Unstructured) string {
+	versions, _, _ := unstructured.NestedSlice(xrd.Object, "spec", "versions")
+	answer := ""
+	for _, v := range versions {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := m["name"].(string)
+		if referenceable, _ := m["referenceable"].(bool); referenceable {
+			return name
+		}
+		if served, _ := m["served"].(bool); served && answer == "" {
+			answer = name
+		}
+	}
+	return answer
+}
+
+// ListClaims returns the claims of the resources in the namespace
+func ListClaims(client dynamic.Interface, resources []ClaimResource, ns string) ([]Claim, error) {
+	answer := []Claim{}
+	for _, r := range resources {
+		list, err := client.Resource(r.Resource).Namespace(ns).List(metav1.ListOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "listing the %s claims in namespace %s", r.Kind, ns)
+		}
+		for i := range list.Items {
+			answer = append(answer, toClaim(r.Kind, &list.Items[i]))
+		}
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		if answer[i].Kind != answer[j].Kind {
+			return answer[i].Kind < answer[j].Kind
+		}
+		return answer[i].Name < answer[j].Name
+	})
+	return answer, nil
+}
+
+func toClaim(kind string, u *unstructured.Unstructured) Claim {
+	claim := Claim{
+		Kind:      kind,
+		Namespace: u.GetNamespace(),
+		Name:      u.GetName(),
+		Status:    StatusPending,
+	}
+	claim.ConnectionSecret, _, _ = unstructured.NestedString(u.Object, "spec", "writeConnectionSecretToRef", "name")
+	conditions, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	for _, c := range conditions {
+		m, ok := c.(map[string]interface{})
+		if !ok || m["type"] != StatusReady {
+			continue
+		}
+		if m["status"] == "True" {
+			claim.Status = StatusReady
+		} else if reason, ok := m["reason"].(string); ok && reason != "" {
+			claim.Status = reason
+		}
+		claim.Message, _ = m["message"].(string)
+	}
+	return claim
+}
+
+// WireConnectionSecrets makes the claims of the namespace which do not specify where to write their connection
+// details write them to a Secret in the namespace so that the apps can mount it, returning the updated claims
+func WireConnectionSecrets(client dynamic.Interface, resources []ClaimResource, ns string) ([]Claim, error) {
+	answer := []Claim{}
+	for _, r := range resources {
+		list, err := client.Resource(r.Resource).Namespace(ns).List(metav1.ListOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "listing the %s claims in namespace %s", r.Kind, ns)
+		}
+		for i := range list.Items {
+			u := &list.Items[i]
+			name, _, _ := unstructured.NestedString(u.Object, "spec", "writeConnectionSecretToRef", "name")
+			if name != "" {
+				continue
+			}
+			err = unstructured.SetNestedField(u.Object, ConnectionSecretName(u.GetName()), "spec", "writeConnectionSecretToRef", "name")
+			if err != nil {
+				return nil, errors.Wrapf(err, "setting the connection secret of %s %s", r.Kind, u.GetName())
+			}
+			updated, err := client.Resource(r.Resource).Namespace(ns).Update(u, metav1.UpdateOptions{})
+			if err != nil {
+				return nil, errors.Wrapf(err, "updating %s %s in namespace %s", r.Kind, u.GetName(), ns)
+			}
+			answer = append(answer, toClaim(r.Kind, updated))
+		}
+	}
+	return answer, nil
+}
+
+// ProviderName returns the name of the provider of a package such as provider-gcp for
+// xpkg.upbound.io/crossplane-contrib/provider-gcp:v0.22.0
+func ProviderName(pkg string) string {
+	name := pkg[strings.LastIndex(pkg, "/")+1:]
+	if i := strings.IndexAny(name, ":@"); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// ApplyProvider creates the Crossplane provider of the package if it does not exist
+func ApplyProvider(client dynamic.Interface, name string, pkg string) error {
+	resources := client.Resource(ProviderResource)
+	_, err := resources.Get(name, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "getting Provider %s", name)
+	}
+	u := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": ProviderResource.GroupVersion().String(),
+			"kind":       "Provider",
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+			"spec": map[string]interface{}{
+				"package": pkg,
+			},
+		},
+	}
+	_, err = resources.Create(u, metav1.CreateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "creating Provider %s", name)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// +build unit
+
+package crossplane_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/crossplane"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var postgreSQLInstances = schema.GroupVersionResource{Group: "database.example.org", Version: "v1alpha1", Resource: "postgresqlinstances"}
+
+func newDefinition() *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apiextensions.crossplane.io/v1",
+			"kind":       "CompositeResourceDefinition",
+			"metadata": map[string]interface{}{
+				"name": "xpostgresqlinstances.database.example.org",
+			},
+			"spec": map[string]interface{}{
+				"group": "database.example.org",
+				"names": map[string]interface{}{
+					"kind":   "XPostgreSQLInstance",
+					"plural": "xpostgresqlinstances",
+				},
+				"claimNames": map[string]interface{}{
+					"kind":   "PostgreSQLInstance",
+					"plural": "postgresqlinstances",
+				},
+				"versions": []interface{}{
+					map[string]interface{}{"name": "v1alpha1", "served": true, "referenceable": true},
+				},
+			},
+		},
+	}
+}
+
+func newClaim(name string, secret string, ready string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "database.example.org/v1alpha1",
+			"kind":       "PostgreSQLInstance",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "jx-staging",
+			},
+			"spec": map[string]interface{}{
+				"parameters": map[string]interface{}{"storageGB": int64(20)},
+			},
+		},
+	}
+	if secret != "" {
+		_ = unstructured.SetNestedField(u.Object, secret, "spec", "writeConnectionSecretToRef", "name")
+	}
+	if ready != "" {
+		conditions := []interface{}{
+			map[string]interface{}{"type": "Ready", "status": ready, "reason": "Creating"},
+		}
+		_ = unstructured.SetNestedSlice(u.Object, conditions, "status", "conditions")
+	}
+	return u
+}
+
+func TestClaimResources(t *testing.T) {
+	t.Parallel()
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), newDefinition())
+
+	resources, err := crossplane.ClaimResources(client)
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	assert.Equal(t, "PostgreSQLInstance", resources[0].Kind)
+	assert.Equal(t, postgreSQLInstances, resources[0].Resource)
+}
+
+func TestClaimResourcesForbidden(t *testing.T) {
+	t.Parallel()
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), newDefinition())
+	client.PrependReactor("list", "compositeresourcedefinitions", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(crossplane.CompositeResourceDefinitionResource.GroupResource(), "", errors.New("cluster scoped"))
+	})
+
+	resources, err := crossplane.ClaimResources(client)
+	require.NoError(t, err)
+	assert.Empty(t, resources)
+}
+
+func TestListClaimsAndWireConnectionSecrets(t *testing.T) {
+	t.Parallel()
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(),
+		newDefinition(),
+		newClaim("orders-db", "", "False"),
+		newClaim("users-db", "users-db-credentials", "True"),
+	)
+	resources, err := crossplane.ClaimResources(client)
+	require.NoError(t, err)
+
+	claims, err := crossplane.ListClaims(client, resources, "jx-staging")
+	require.NoError(t, err)
+	require.Len(t, claims, 2)
+	assert.Equal(t, crossplane.Claim{Kind: "PostgreSQLInstance", Namespace: "jx-staging", Name: "orders-db", Status: "Creating"}, claims[0])
+	assert.Equal(t, crossplane.StatusReady, claims[1].Status)
+	assert.Equal(t, "users-db-credentials", claims[1].ConnectionSecret)
+
+	wired, err := crossplane.WireConnectionSecrets(client, resources, "jx-staging")
+	require.NoError(t, err)
+	require.Len(t, wired, 1, "the claims with a connection secret should not be modified")
+	assert.Equal(t, "orders-db-connection", wired[0].ConnectionSecret)
+
+	u, err := client.Resource(postgreSQLInstances).Namespace("jx-staging").Get("orders-db", metav1.GetOptions{})
+	require.NoError(t, err)
+	secret, _, _ := unstructured.NestedString(u.Object, "spec", "writeConnectionSecretToRef", "name")
+	assert.Equal(t, "orders-db-connection", secret)
+}
+
+func TestApplyProvider(t *testing.T) {
+	t.Parallel()
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+
+	pkg := "xpkg.upbound.io/crossplane-contrib/provider-gcp:v0.22.0"
+	name := crossplane.ProviderName(pkg)
+	assert.Equal(t, "provider-gcp", name)
+	require.NoError(t, crossplane.ApplyProvider(client, name, pkg))
+	require.NoError(t, crossplane.ApplyProvider(client, name, pkg), "applying an existing provider should succeed")
+
+	u, err := client.Resource(crossplane.ProviderResource).Get(name, metav1.GetOptions{})
+	require.NoError(t, err)
+	actual, _, _ := unstructured.NestedString(u.Object, "spec", "package")
+	assert.Equal(t, pkg, actual)
+}
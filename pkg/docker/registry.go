@@ -0,0 +1,297 @@
+package docker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+var (
+	challengeParamRegex = regexp.MustCompile(`(\w+)="([^"]*)"`)
+	linkNextRegex       = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+)
+
+// VerifyRegistryLogin logs into the registry with the credentials following the docker registry v2 token
+// authentication if the registry asks for it
+func VerifyRegistryLogin(host string, username string, password string, timeout time.Duration) error {
+	base := host
+	if !strings.Contains(base, "://") {
+		base = "https://" + base
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return errors.Wrapf(err, "parsing the registry host %s", host)
+	}
+	u.Host = registryHost(u.Host)
+	u.Path = "/v2/"
+	client := util.GetClientWithTimeout(timeout)
+
+	status, challenge, err := registryGet(client, u.String(), username, password)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusOK {
+		return nil
+	}
+	if status != http.StatusUnauthorized || !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return fmt.Errorf("the registry %s returned status %d", u.String(), status)
+	}
+	params := challengeParams(challenge)
+	if params["realm"] == "" {
+		return fmt.Errorf("the registry %s did not return the realm of its token service", u.String())
+	}
+	tokenURL, err := url.Parse(params["realm"])
+	if err != nil {
+		return errors.Wrapf(err, "parsing the realm %s", params["realm"])
+	}
+	query := tokenURL.Query()
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	tokenURL.RawQuery = query.Encode()
+	status, _, err = registryGet(client, tokenURL.String(), username, password)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("the token service %s of the registry returned status %d", params["realm"], status)
+	}
+	return nil
+}
+
+// ListTags lists the tags of the image in its registry following the docker registry v2 token authentication if the
+// registry asks for it. Anonymous access is used if no username is given
+func ListTags(image string, username string, password string, timeout time.Duration) ([]string, error) {
+	host, repository := SplitImage(image)
+	base := registryScheme(host) + "://" + registryHost(host)
+	client := util.GetClientWithTimeout(timeout)
+	token := ""
+	next := base + "/v2/" + repository + "/tags/list"
+	var tags []string
+	for next != "" {
+		req, err := http.NewRequest(http.MethodGet, next, nil)
+		if err != nil {
+			return nil, err
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		} else if username != "" {
+			req.SetBasicAuth(username, password)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, errors.Wrapf(err, "calling %s", next)
+		}
+		challenge := resp.Header.Get("WWW-Authenticate")
+		if resp.StatusCode == http.StatusUnauthorized && token == "" && strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+			resp.Body.Close()
+			token, err = registryToken(client, challenge, username, password, "repository:"+repository+":pull")
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("listing the tags of %s returned status %d", image, resp.StatusCode)
+		}
+		page := struct {
+			Tags []string `json:"tags"`
+		}{}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing the tags of %s", image)
+		}
+		tags = append(tags, page.Tags...)
+		next = ""
+		if m := linkNextRegex.FindStringSubmatch(resp.Header.Get("Link")); m != nil {
+			next = base + m[1]
+		}
+	}
+	return tags, nil
+}
+
+// SplitImage splits the image name into the host of its registry and the repository within the registry. The tag or
+// digest of the image is dropped and images without a registry are on docker hub
+func SplitImage(image string) (string, string) {
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, ":"); i >= 0 && !strings.Contains(name[i:], "/") {
+		name = name[:i]
+	}
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		return parts[0], parts[1]
+	}
+	if len(parts) == 1 {
+		return "docker.io", "library/" + name
+	}
+	return "docker.io", name
+}
+
+// registryScheme returns http for the registries on the local host which docker treats as insecure registries
+func registryScheme(host string) string {
+	hostname := strings.Split(host, ":")[0]
+	if hostname == "localhost" || strings.HasPrefix(hostname, "127.") {
+		return "http"
+	}
+	return "https"
+}
+
+func registryHost(host string) string {
+	if host == "index.docker.io" || host == "docker.io" {
+		return "registry-1.docker.io"
+	}
+	return host
+}
+
+// registryToken gets a bearer token of the scope from the token service of the challenge of a registry
+func registryToken(client *http.Client, challenge string, username string, password string, scope string) (string, error) {
+	params := challengeParams(challenge)
+	if params["realm"] == "" {
+		return "", fmt.Errorf("the registry did not return the realm of its token service")
+	}
+	tokenURL, err := url.Parse(params["realm"])
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing the realm %s", params["realm"])
+	}
+	query := tokenURL.Query()
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	query.Set("scope", scope)
+	tokenURL.RawQuery = query.Encode()
+	req, err := http.NewRequest(http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if username != "" {
+		req.SetBasicAuth(username, password)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", errors.Wrapf(err, "calling %s", params["realm"])
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("the token service %s of the registry returned status %d", params["realm"], resp.StatusCode)
+	}
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(&token)
+	if err != nil {
+		return "", errors.Wrap(err, "parsing the registry token")
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	return token.AccessToken, nil
+}
+
+func challengeParams(challenge string) map[string]string {
+	params := map[string]string{}
+	for _, m := range challengeParamRegex.FindAllStringSubmatch(challenge, -1) {
+		params[m[1]] = m[2]
+	}
+	return params
+}
+
+func registryGet(client *http.Client, u string, username string, password string) (int, string, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	req.SetBasicAuth(username, password)
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", errors.Wrapf(err, "calling %s", u)
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, resp.Header.Get("WWW-Authenticate"), nil
+}
+
+// AuthCredentials returns the user and password of an entry of the auths of a docker config.json
+func AuthCredentials(entry map[string]interface{}) (string, string) {
+	username, _ := entry["username"].(string)
+	password, _ := entry["password"].(string)
+	if username != "" {
+		return username, password
+	}
+	auth, _ := entry["auth"].(string)
+	data, err := base64.StdEncoding.DecodeString(auth)
+	if err != nil {
+		return "", ""
+	}
+	parts := strings.SplitN(string(data), ":", 2)
+	if len(parts) < 2 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+// ConfigCredentials returns the user and password of the registry host in the docker config.json of the
+// $DOCKER_CONFIG directory or ~/.docker or empty strings if there are none
+func ConfigCredentials(host string) (string, string) {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		dir = filepath.Join(util.HomeDir(), ".docker")
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return "", ""
+	}
+	config := struct {
+		Auths map[string]map[string]interface{} `json:"auths"`
+	}{}
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return "", ""
+	}
+	for server, entry := range config.Auths {
+		u, err := url.Parse(server)
+		if err == nil && u.Host != "" {
+			server = u.Host
+		}
+		if server == host || (host == "docker.io" && server == "index.docker.io") {
+			return AuthCredentials(entry)
+		}
+	}
+	return "", ""
+}
+
+// TokenExpiry returns the expiry of registry credentials which are temporary tokens such as the tokens of Amazon ECR
+// or nil if the credentials do not expire
+func TokenExpiry(username string, password string) *time.Time {
+	if username != "AWS" {
+		return nil
+	}
+	data, err := base64.StdEncoding.DecodeString(password)
+	if err != nil {
+		return nil
+	}
+	token := struct {
+		Expiration int64 `json:"expiration"`
+	}{}
+	err = json.Unmarshal(data, &token)
+	if err != nil || token.Expiration == 0 {
+		return nil
+	}
+	expiry := time.Unix(token.Expiration, 0).UTC()
+	return &expiry
+}
@@ -0,0 +1,123 @@
+// +build unit
+
+package docker_test
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/docker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyRegistryLoginWithTokenService(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ := r.BasicAuth()
+		switch r.URL.Path {
+		case "/v2/":
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="registry.example.com"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		case "/token":
+			if username == "bot" && password == "secret" && r.URL.Query().Get("service") == "registry.example.com" {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	assert.NoError(t, docker.VerifyRegistryLogin(server.URL, "bot", "secret", time.Second))
+	assert.Error(t, docker.VerifyRegistryLogin(server.URL, "bot", "wrong", time.Second))
+}
+
+func TestAuthCredentials(t *testing.T) {
+	username, password := docker.AuthCredentials(map[string]interface{}{"auth": base64.StdEncoding.EncodeToString([]byte("bot:p:w"))})
+	assert.Equal(t, "bot", username)
+	assert.Equal(t, "p:w", password)
+
+	username, password = docker.AuthCredentials(map[string]interface{}{"username": "other", "password": "secret"})
+	assert.Equal(t, "other", username)
+	assert.Equal(t, "secret", password)
+}
+
+func TestTokenExpiry(t *testing.T) {
+	token := base64.StdEncoding.EncodeToString([]byte(`{"payload":"x","expiration":1700000000}`))
+	expiry := docker.TokenExpiry("AWS", token)
+	require.NotNil(t, expiry)
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), *expiry)
+
+	assert.Nil(t, docker.TokenExpiry("_json_key", token))
+}
+
+func TestListTags(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/jenkinsxio/builder-go/tags/list":
+			if r.Header.Get("Authorization") != "Bearer abc" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="registry.example.com"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.URL.Query().Get("last") == "" {
+				w.Header().Set("Link", `</v2/jenkinsxio/builder-go/tags/list?n=2&last=0.1.0>; rel="next"`)
+				_, _ = w.Write([]byte(`{"name":"jenkinsxio/builder-go","tags":["latest","0.1.0"]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"name":"jenkinsxio/builder-go","tags":["0.2.0"]}`))
+		case "/token":
+			assert.Equal(t, "repository:jenkinsxio/builder-go:pull", r.URL.Query().Get("scope"))
+			_, _ = w.Write([]byte(`{"token":"abc"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	image := strings.TrimPrefix(server.URL, "http://") + "/jenkinsxio/builder-go:0.0.1"
+	tags, err := docker.ListTags(image, "", "", time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"latest", "0.1.0", "0.2.0"}, tags)
+}
+
+func TestSplitImage(t *testing.T) {
+	for image, expected := range map[string][]string{
+		"gcr.io/jenkinsxio/builder-go:2.1.0": {"gcr.io", "jenkinsxio/builder-go"},
+		"localhost:5000/app@sha256:abc":      {"localhost:5000", "app"},
+		"jenkinsxio/jx:1.0.0":                {"docker.io", "jenkinsxio/jx"},
+		"golang":                             {"docker.io", "library/golang"},
+	} {
+		host, repository := docker.SplitImage(image)
+		assert.Equal(t, expected, []string{host, repository}, "for image %s", image)
+	}
+}
+
+func TestConfigCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-docker-config")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	auth := base64.StdEncoding.EncodeToString([]byte("bot:secret"))
+	err = ioutil.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"auths":{"https://gcr.io":{"auth":"`+auth+`"}}}`), 0600)
+	require.NoError(t, err)
+	os.Setenv("DOCKER_CONFIG", dir)
+	defer os.Unsetenv("DOCKER_CONFIG")
+
+	username, password := docker.ConfigCredentials("gcr.io")
+	assert.Equal(t, "bot", username)
+	assert.Equal(t, "secret", password)
+
+	username, _ = docker.ConfigCredentials("quay.io")
+	assert.Empty(t, username)
+}
@@ -339,7 +339,7 @@ func CreateNestedRequirementDir(dir string, requirementName string, requirementD
 			gitRepo = release.Spec.GitHTTPURL
 			releaseNotesURL = release.Spec.ReleaseNotesURL
 			releaseYamlOutPath := filepath.Join(appDir, "release.yaml")
-			err = ioutil.WriteFile(releaseYamlOutPath, bytes, 0600)
+			err = util.WriteCanonicalYAMLFile(releaseYamlOutPath, bytes, 0600)
 			if err != nil {
 				return errors.Wrapf(err, "write file %s", releaseYamlOutPath)
 			}
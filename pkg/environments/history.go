@@ -0,0 +1,115 @@
+package environments
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// EnvironmentRelease a release of an environment which is a commit of the branch of its git repository that changed
+// the environment chart
+type EnvironmentRelease struct {
+	// Revision the number of the release, starting at 1 for the first release like the revisions of helm releases
+	Revision int
+	SHA      string
+	Date     time.Time
+	Author   string
+	Message  string
+}
+
+// ShortSHA returns the abbreviated SHA of the release commit
+func (r *EnvironmentRelease) ShortSHA() string {
+	if len(r.SHA) > 7 {
+		return r.SHA[0:7]
+	}
+	return r.SHA
+}
+
+// ReleaseHistory returns the releases of the environment repository cloned in the directory, the most recent first
+func ReleaseHistory(dir string) ([]*EnvironmentRelease, error) {
+	args := []string{"log", "--first-parent", "--format=%H%x1f%aI%x1f%an%x1f%s%x1e", "--"}
+	args = append(args, chartPaths(dir)...)
+	out, err := runGit(dir, args...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the releases of the environment repository in %s", dir)
+	}
+	answer := []*EnvironmentRelease{}
+	for _, line := range strings.Split(out, "\x1e") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, "\x1f")
+		if len(fields) < 4 {
+			return nil, errors.Errorf("unexpected git log output %s", line)
+		}
+		date, err := time.Parse(time.RFC3339, fields[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing the date of commit %s", fields[0])
+		}
+		answer = append(answer, &EnvironmentRelease{
+			SHA:     fields[0],
+			Date:    date,
+			Author:  fields[2],
+			Message: fields[3],
+		})
+	}
+	for i, r := range answer {
+		r.Revision = len(answer) - i
+	}
+	return answer, nil
+}
+
+// FindRelease returns the release with the revision or nil if there is none
+func FindRelease(releases []*EnvironmentRelease, revision int) *EnvironmentRelease {
+	for _, r := range releases {
+		if r.Revision == revision {
+			return r
+		}
+	}
+	return nil
+}
+
+// StageRollback stages the environment chart of the release in the repository cloned in the directory, removing the
+// files added since, so that committing reverts the environment to the release. It returns false if the environment
+// chart already matches the release
+func StageRollback(dir string, release *EnvironmentRelease) (bool, error) {
+	paths := chartPaths(dir)
+	args := append([]string{"rm", "-r", "-q", "--ignore-unmatch", "--"}, paths...)
+	_, err := runGit(dir, args...)
+	if err != nil {
+		return false, errors.Wrapf(err, "removing the current environment chart in %s", dir)
+	}
+	args = append([]string{"checkout", release.SHA, "--"}, paths...)
+	_, err = runGit(dir, args...)
+	if err != nil {
+		return false, errors.Wrapf(err, "checking out the environment chart of revision %d", release.Revision)
+	}
+	out, err := runGit(dir, "status", "--porcelain")
+	if err != nil {
+		return false, errors.Wrapf(err, "getting the status of %s", dir)
+	}
+	return strings.TrimSpace(out) != "", nil
+}
+
+// chartPaths returns the paths of the environment chart in the repository which is in the env directory of
+// environment repositories or the whole repository otherwise
+func chartPaths(dir string) []string {
+	exists, err := util.DirExists(filepath.Join(dir, "env"))
+	if err == nil && exists {
+		return []string{"env"}
+	}
+	return []string{"."}
+}
+
+func runGit(dir string, args ...string) (string, error) {
+	cmd := util.Command{
+		Dir:  dir,
+		Name: "git",
+		Args: args,
+	}
+	return cmd.RunWithoutRetry()
+}
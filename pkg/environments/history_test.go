@@ -0,0 +1,80 @@
+// +build unit
+
+package environments_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/environments"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func git(t *testing.T, dir string, args ...string) {
+	cmd := util.Command{
+		Dir:  dir,
+		Name: "git",
+		Args: append([]string{"-c", "user.name=jx", "-c", "user.email=jx@example.com"}, args...),
+	}
+	_, err := cmd.RunWithoutRetry()
+	require.NoError(t, err)
+}
+
+func release(t *testing.T, dir string, files map[string]string, message string) {
+	for name, text := range files {
+		file := filepath.Join(dir, "env", name)
+		if text == "" {
+			git(t, dir, "rm", "-q", file)
+			continue
+		}
+		require.NoError(t, ioutil.WriteFile(file, []byte(text), util.DefaultFileWritePermissions))
+	}
+	git(t, dir, "add", "-A")
+	git(t, dir, "commit", "-q", "-m", message)
+}
+
+func TestReleaseHistoryAndRollback(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "env-history")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "env"), util.DefaultWritePermissions))
+	git(t, dir, "init", "-q")
+
+	release(t, dir, map[string]string{"requirements.yaml": "myapp: 1.0.0\n"}, "chore: promote myapp 1.0.0")
+	// commits outside of the environment chart are not releases
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("production"), util.DefaultFileWritePermissions))
+	git(t, dir, "add", "-A")
+	git(t, dir, "commit", "-q", "-m", "docs: readme")
+	release(t, dir, map[string]string{"requirements.yaml": "myapp: 1.1.0\n", "canary.yaml": "kind: Canary\n"}, "chore: promote myapp 1.1.0")
+
+	releases, err := environments.ReleaseHistory(dir)
+	require.NoError(t, err)
+	require.Len(t, releases, 2)
+	assert.Equal(t, 2, releases[0].Revision)
+	assert.Equal(t, "chore: promote myapp 1.1.0", releases[0].Message)
+	assert.Equal(t, "jx", releases[0].Author)
+
+	first := environments.FindRelease(releases, 1)
+	require.NotNil(t, first)
+	assert.Nil(t, environments.FindRelease(releases, 3))
+
+	changed, err := environments.StageRollback(dir, first)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	data, err := ioutil.ReadFile(filepath.Join(dir, "env", "requirements.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "myapp: 1.0.0\n", string(data))
+	assert.NoFileExists(t, filepath.Join(dir, "env", "canary.yaml"), "files added since the release should be removed")
+	assert.FileExists(t, filepath.Join(dir, "README.md"))
+
+	git(t, dir, "commit", "-q", "-m", "chore: rollback")
+	changed, err = environments.StageRollback(dir, first)
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
@@ -0,0 +1,133 @@
+package extensions
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	jenkinsv1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// LocalPluginsNamespace the plugins dir namespace that binary plugins installed from the plugin index are stored in
+	LocalPluginsNamespace = "jx"
+)
+
+// installed plugin binaries are named <name>-<version> by EnsurePluginInstalled
+var installedPluginRegex = regexp.MustCompile(`^(jx-.+?)-(v?\d.*)$`)
+
+// LocalPlugin a binary plugin which is available locally
+type LocalPlugin struct {
+	// Name the name of the plugin binary e.g. jx-foo
+	Name string
+	// Version the installed version of plugins installed into the plugins dir
+	Version string
+	// Path the path of the plugin binary
+	Path string
+}
+
+// SubCommand returns the jx sub command the plugin implements
+func (p *LocalPlugin) SubCommand() string {
+	return strings.Replace(strings.TrimPrefix(p.Name, "jx-"), "-", " ", -1)
+}
+
+// LocalPluginBinDir returns the dir the binary plugins installed from the plugin index are stored in
+func LocalPluginBinDir() (string, error) {
+	return util.PluginBinDir(LocalPluginsNamespace)
+}
+
+// InstalledPlugins returns the binary plugins installed into the given plugins dir sorted by name
+func InstalledPlugins(pluginBinDir string) ([]*LocalPlugin, error) {
+	files, err := ioutil.ReadDir(pluginBinDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "reading plugins dir %s", pluginBinDir)
+	}
+	answer := []*LocalPlugin{}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		result := installedPluginRegex.FindStringSubmatch(f.Name())
+		if result == nil {
+			continue
+		}
+		answer = append(answer, &LocalPlugin{
+			Name:    result[1],
+			Version: result[2],
+			Path:    filepath.Join(pluginBinDir, f.Name()),
+		})
+	}
+	sortLocalPlugins(answer)
+	return answer, nil
+}
+
+// PathPlugins returns the jx-* executables on the PATH sorted by name. Only the first executable of each name is
+// returned as that is the one which is run
+func PathPlugins() []*LocalPlugin {
+	path := "PATH"
+	if runtime.GOOS == "windows" {
+		path = "path"
+	}
+	seen := map[string]bool{}
+	answer := []*LocalPlugin{}
+	for _, dir := range filepath.SplitList(os.Getenv(path)) {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, f := range files {
+			name := strings.TrimSuffix(f.Name(), ".exe")
+			if f.IsDir() || !strings.HasPrefix(name, "jx-") || seen[name] {
+				continue
+			}
+			fullPath := filepath.Join(dir, f.Name())
+			if isExec, err := isExecutable(fullPath); err != nil || !isExec {
+				continue
+			}
+			seen[name] = true
+			answer = append(answer, &LocalPlugin{
+				Name: name,
+				Path: fullPath,
+			})
+		}
+	}
+	sortLocalPlugins(answer)
+	return answer
+}
+
+// InstallIndexPlugin installs the given plugin from the plugin index into the local plugins dir, removing any other
+// installed versions of the plugin, so that it is run for its sub command
+func InstallIndexPlugin(plugin jenkinsv1.PluginSpec) (string, error) {
+	expectedName := "jx-" + strings.Join(strings.Fields(plugin.SubCommand), "-")
+	if plugin.Name != expectedName {
+		return "", fmt.Errorf("the binary of plugin %s must be called %s to be run for the command jx %s", plugin.Name,
+			expectedName, plugin.SubCommand)
+	}
+	if plugin.Version == "" {
+		return "", fmt.Errorf("no version specified for plugin %s", plugin.Name)
+	}
+	return EnsurePluginInstalled(jenkinsv1.Plugin{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      plugin.Name,
+			Namespace: LocalPluginsNamespace,
+		},
+		Spec: plugin,
+	})
+}
+
+func sortLocalPlugins(plugins []*LocalPlugin) {
+	sort.SliceStable(plugins, func(i, j int) bool {
+		return plugins[i].Name < plugins[j].Name
+	})
+}
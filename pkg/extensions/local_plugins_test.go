@@ -0,0 +1,82 @@
+// +build unit
+
+package extensions_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	jenkinsv1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/extensions"
+	"github.com/jenkins-x/jx/v2/pkg/tests"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallIndexPlugin(t *testing.T) {
+	tests.SkipForWindows(t, "plugins do not work on windows")
+
+	jxHome, err := ioutil.TempDir("", "test-install-index-plugin-")
+	require.NoError(t, err)
+	defer os.RemoveAll(jxHome)
+	os.Setenv("JX_HOME", jxHome)
+	defer os.Unsetenv("JX_HOME")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "#!/bin/sh\necho %s\n", r.URL.Path)
+	}))
+	defer server.Close()
+
+	pluginBinDir, err := extensions.LocalPluginBinDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(jxHome, "plugins", "jx", "bin"), pluginBinDir)
+	// a plugin for a sub command of the plugin being installed should be left alone
+	siblingPath := filepath.Join(pluginBinDir, "jx-hello-world-0.1.0")
+	err = ioutil.WriteFile(siblingPath, []byte("#!/bin/sh\n"), 0755)
+	require.NoError(t, err)
+
+	for _, version := range []string{"1.0.0", "1.1.0"} {
+		path, err := extensions.InstallIndexPlugin(indexPlugin(server.URL, version))
+		require.NoError(t, err, "installing version %s", version)
+		assert.Equal(t, filepath.Join(pluginBinDir, "jx-hello-"+version), path)
+
+		cmd := util.Command{Name: path}
+		output, err := cmd.RunWithoutRetry()
+		require.NoError(t, err)
+		assert.Equal(t, "/"+version+"/jx-hello", output)
+	}
+
+	installed, err := extensions.InstalledPlugins(pluginBinDir)
+	require.NoError(t, err)
+	require.Len(t, installed, 2, "the previous version of the plugin should be removed")
+	assert.Equal(t, &extensions.LocalPlugin{Name: "jx-hello", Version: "1.1.0", Path: filepath.Join(pluginBinDir, "jx-hello-1.1.0")}, installed[0])
+	assert.Equal(t, &extensions.LocalPlugin{Name: "jx-hello-world", Version: "0.1.0", Path: siblingPath}, installed[1])
+	assert.Equal(t, "hello world", installed[1].SubCommand())
+
+	plugin := indexPlugin(server.URL, "1.2.0")
+	plugin.Name = "jx-greeting"
+	_, err = extensions.InstallIndexPlugin(plugin)
+	assert.Error(t, err, "the plugin binary is not named after its sub command")
+}
+
+func indexPlugin(serverURL string, version string) jenkinsv1.PluginSpec {
+	return jenkinsv1.PluginSpec{
+		Name:       "jx-hello",
+		SubCommand: "hello",
+		Version:    version,
+		Binaries: []jenkinsv1.Binary{
+			{
+				Goos:   runtime.GOOS,
+				Goarch: runtime.GOARCH,
+				URL:    fmt.Sprintf("%s/%s/jx-hello", serverURL, version),
+			},
+		},
+	}
+}
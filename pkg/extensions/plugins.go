@@ -138,12 +138,14 @@ func EnsurePluginInstalled(plugin jenkinsv1.Plugin) (string, error) {
 		}
 		deleted := make([]string, 0)
 		for _, f := range files {
-			if strings.HasPrefix(f.Name(), plugin.Name) {
+			// match the exact binary name so that installing jx-foo does not remove jx-foo-bar
+			result := installedPluginRegex.FindStringSubmatch(f.Name())
+			if result != nil && result[1] == plugin.Spec.Name {
 				err = os.Remove(filepath.Join(pluginBinDir, f.Name()))
 				if err != nil {
 					log.Logger().Warnf("Unable to delete old version of plugin %s installed at %s because %v", plugin.Name, f.Name(), err)
 				} else {
-					deleted = append(deleted, strings.TrimPrefix(f.Name(), fmt.Sprintf("%s-", plugin.Name)))
+					deleted = append(deleted, result[2])
 				}
 			}
 		}
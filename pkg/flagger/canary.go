@@ -0,0 +1,274 @@
+package flagger
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// StrategyCanary shifts the traffic progressively to the new version while its metrics are analysed
+	StrategyCanary = "canary"
+	// StrategyBlueGreen runs the analysis against the new version before switching all the traffic to it
+	StrategyBlueGreen = "blue-green"
+	// StrategyRolling removes any generated Canary so the default rolling update of the Deployment is used
+	StrategyRolling = "rolling"
+
+	// PhaseInitialized Flagger has created the primary Deployment of the first version
+	PhaseInitialized = "Initialized"
+	// PhaseSucceeded the analysis passed and the new version was promoted
+	PhaseSucceeded = "Succeeded"
+	// PhaseFailed the analysis failed and Flagger rolled back to the previous version
+	PhaseFailed = "Failed"
+
+	// APIVersion the API version of the generated Canary resources
+	APIVersion = "flagger.app/v1beta1"
+)
+
+// Strategies the deployment strategies of promotions
+var Strategies = []string{StrategyCanary, StrategyBlueGreen, StrategyRolling}
+
+// CanaryResource the resource of Flagger Canaries
+var CanaryResource = schema.GroupVersionResource{Group: "flagger.app", Version: "v1beta1", Resource: "canaries"}
+
+// CanaryOptions the options of the Canary generated for a deployment strategy
+type CanaryOptions struct {
+	Strategy    string
+	Application string
+	// Interval the interval between the analysis runs
+	Interval string
+	// Threshold the number of failed checks before rolling back
+	Threshold int
+	// MaxWeight the maximum traffic percentage routed to the canary
+	MaxWeight int
+	// StepWeight the traffic percentage added to the canary on every successful analysis run
+	StepWeight int
+	// Iterations the number of analysis runs of a blue-green deployment
+	Iterations int
+	// SuccessRate the minimum percentage of successful requests
+	SuccessRate float64
+	// MaxLatency the maximum 99th percentile request duration in milliseconds
+	MaxLatency int
+}
+
+// Enabled returns true if the strategy generates a Canary
+func (o *CanaryOptions) Enabled() bool {
+	return o.Strategy == StrategyCanary || o.Strategy == StrategyBlueGreen
+}
+
+// Validate validates the options
+func (o *CanaryOptions) Validate() error {
+	if o.Strategy != "" && util.StringArrayIndex(Strategies, o.Strategy) < 0 {
+		return util.InvalidOption("strategy", o.Strategy, Strategies)
+	}
+	if !o.Enabled() {
+		return nil
+	}
+	if _, err := time.ParseDuration(o.Interval); err != nil {
+		return errors.Wrapf(err, "invalid analysis interval %s", o.Interval)
+	}
+	if o.Threshold <= 0 || o.Iterations <= 0 || o.StepWeight <= 0 || o.MaxWeight <= 0 || o.MaxWeight > 100 {
+		return errors.Errorf("the threshold, iterations and weights of the analysis should be positive and the max weight at most 100")
+	}
+	return nil
+}
+
+// FileName returns the name of the file of the Canary of the application in the templates of the environment chart
+func FileName(app string) string {
+	return app + "-canary.yaml"
+}
+
+// GenerateCanary generates the Canary of the application for the environment chart. The Canary targets the
+// Deployment of the application in the release of the environment chart
+func GenerateCanary(o *CanaryOptions) ([]byte, error) {
+	metrics := []map[string]interface{}{
+		{
+			"name":           "request-success-rate",
+			"interval":       o.Interval,
+			"thresholdRange": map[string]interface{}{"min": o.SuccessRate},
+		},
+		{
+			"name":           "request-duration",
+			"interval":       o.Interval,
+			"thresholdRange": map[string]interface{}{"max": o.MaxLatency},
+		},
+	}
+	analysis := map[string]interface{}{
+		"interval":  o.Interval,
+		"threshold": o.Threshold,
+		"metrics":   metrics,
+	}
+	spec := map[string]interface{}{
+		"targetRef": map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"name":       "{{ .Release.Name }}-" + o.Application,
+		},
+		"service": map[string]interface{}{
+			"port":       80,
+			"targetPort": 8080,
+		},
+		"analysis": analysis,
+	}
+	if o.Strategy == StrategyBlueGreen {
+		// blue-green does not need a service mesh as all the traffic is switched at once
+		spec["provider"] = "kubernetes"
+		analysis["iterations"] = o.Iterations
+	} else {
+		analysis["maxWeight"] = o.MaxWeight
+		analysis["stepWeight"] = o.StepWeight
+	}
+	canary := map[string]interface{}{
+		"apiVersion": APIVersion,
+		"kind":       "Canary",
+		"metadata": map[string]interface{}{
+			"name": o.Application,
+			"labels": map[string]interface{}{
+				"jenkins.io/strategy": o.Strategy,
+			},
+		},
+		"spec": spec,
+	}
+	data, err := yaml.Marshal(canary)
+	if err != nil {
+		return nil, errors.Wrapf(err, "marshalling the Canary of %s", o.Application)
+	}
+	return data, nil
+}
+
+// WriteCanary writes the Canary of the strategy into the templates of the environment chart in the directory or
+// removes any generated Canary if the strategy does not use one
+func WriteCanary(dir string, o *CanaryOptions) error {
+	file := filepath.Join(dir, "templates", FileName(o.Application))
+	if !o.Enabled() {
+		err := os.Remove(file)
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "removing %s", file)
+		}
+		return nil
+	}
+	data, err := GenerateCanary(o)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(file), util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "creating the templates directory of %s", dir)
+	}
+	err = ioutil.WriteFile(file, data, util.DefaultFileWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "writing %s", file)
+	}
+	return nil
+}
+
+// CanaryStatus the status of the analysis of a Canary
+type CanaryStatus struct {
+	Phase           string
+	CanaryWeight    int64
+	FailedChecks    int64
+	Iterations      int64
+	LastAppliedSpec string
+	Message         string
+}
+
+// String describes the status
+func (s *CanaryStatus) String() string {
+	answer := fmt.Sprintf("%s weight: %d%% failed checks: %d", s.Phase, s.CanaryWeight, s.FailedChecks)
+	if s.Message != "" {
+		answer += " " + s.Message
+	}
+	return answer
+}
+
+// GetCanaryStatus returns the status of the Canary or nil if it does not exist yet
+func GetCanaryStatus(client dynamic.Interface, ns string, name string) (*CanaryStatus, error) {
+	u, err := client.Resource(CanaryResource).Namespace(ns).Get(name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "getting Canary %s in namespace %s", name, ns)
+	}
+	status := &CanaryStatus{}
+	status.Phase, _, _ = unstructured.NestedString(u.Object, "status", "phase")
+	status.CanaryWeight, _, _ = unstructured.NestedInt64(u.Object, "status", "canaryWeight")
+	status.FailedChecks, _, _ = unstructured.NestedInt64(u.Object, "status", "failedChecks")
+	status.Iterations, _, _ = unstructured.NestedInt64(u.Object, "status", "iterations")
+	status.LastAppliedSpec, _, _ = unstructured.NestedString(u.Object, "status", "lastAppliedSpec")
+	conditions, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	for _, c := range conditions {
+		if m, ok := c.(map[string]interface{}); ok {
+			if message, ok := m["message"].(string); ok {
+				status.Message = message
+			}
+		}
+	}
+	return status, nil
+}
+
+// RolloutWatcher waits for Flagger to finish the analysis of a new version of a Canary
+type RolloutWatcher struct {
+	Client    dynamic.Interface
+	Namespace string
+	Name      string
+	// Initial the status of the Canary before the new version was deployed, nil if it did not exist
+	Initial      *CanaryStatus
+	Timeout      time.Duration
+	PollInterval time.Duration
+	// OnProgress is called with the status of every poll
+	OnProgress func(status *CanaryStatus)
+}
+
+// Wait waits for the analysis to succeed, returning an error if the analysis failed and Flagger rolled back or if
+// the timeout expired
+func (w *RolloutWatcher) Wait() (*CanaryStatus, error) {
+	end := time.Now().Add(w.Timeout)
+	started := false
+	for {
+		status, err := GetCanaryStatus(w.Client, w.Namespace, w.Name)
+		if err != nil {
+			return nil, err
+		}
+		if status != nil {
+			if w.OnProgress != nil {
+				w.OnProgress(status)
+			}
+			if w.Initial == nil || status.LastAppliedSpec != w.Initial.LastAppliedSpec || isAnalysing(status.Phase) {
+				started = true
+			}
+			switch {
+			case w.Initial == nil && status.Phase == PhaseInitialized:
+				// the first version is deployed without an analysis
+				return status, nil
+			case started && status.Phase == PhaseSucceeded:
+				return status, nil
+			case started && status.Phase == PhaseFailed:
+				return status, errors.Errorf("the analysis of Canary %s failed so it was rolled back: %s", w.Name, status.Message)
+			}
+		}
+		if time.Now().After(end) {
+			return status, errors.Errorf("timed out after %s waiting for the analysis of Canary %s", w.Timeout.String(), w.Name)
+		}
+		time.Sleep(w.PollInterval)
+	}
+}
+
+func isAnalysing(phase string) bool {
+	switch phase {
+	case "Progressing", "Promoting", "Finalising", "Waiting":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+// +build unit
+
+package flagger_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/flagger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func newCanaryOptions(strategy string) *flagger.CanaryOptions {
+	return &flagger.CanaryOptions{
+		Strategy:    strategy,
+		Application: "myapp",
+		Interval:    "1m",
+		Threshold:   5,
+		MaxWeight:   50,
+		StepWeight:  10,
+		Iterations:  10,
+		SuccessRate: 99,
+		MaxLatency:  500,
+	}
+}
+
+func TestGenerateCanary(t *testing.T) {
+	t.Parallel()
+
+	data, err := flagger.GenerateCanary(newCanaryOptions(flagger.StrategyCanary))
+	require.NoError(t, err)
+	canary := map[string]interface{}{}
+	require.NoError(t, yaml.Unmarshal(data, &canary))
+	assert.Equal(t, flagger.APIVersion, canary["apiVersion"])
+	name, _, _ := unstructured.NestedString(canary, "spec", "targetRef", "name")
+	assert.Equal(t, "{{ .Release.Name }}-myapp", name)
+	stepWeight, _, _ := unstructured.NestedFieldNoCopy(canary, "spec", "analysis", "stepWeight")
+	assert.EqualValues(t, 10, stepWeight)
+	_, found, _ := unstructured.NestedFieldNoCopy(canary, "spec", "provider")
+	assert.False(t, found, "canaries should use the default provider of Flagger")
+
+	data, err = flagger.GenerateCanary(newCanaryOptions(flagger.StrategyBlueGreen))
+	require.NoError(t, err)
+	canary = map[string]interface{}{}
+	require.NoError(t, yaml.Unmarshal(data, &canary))
+	provider, _, _ := unstructured.NestedString(canary, "spec", "provider")
+	assert.Equal(t, "kubernetes", provider)
+	iterations, _, _ := unstructured.NestedFieldNoCopy(canary, "spec", "analysis", "iterations")
+	assert.EqualValues(t, 10, iterations)
+	_, found, _ = unstructured.NestedFieldNoCopy(canary, "spec", "analysis", "stepWeight")
+	assert.False(t, found, "blue-green deployments should not shift the traffic progressively")
+}
+
+func TestValidateCanaryOptions(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, newCanaryOptions("").Validate())
+	assert.NoError(t, newCanaryOptions(flagger.StrategyBlueGreen).Validate())
+	assert.Error(t, newCanaryOptions("red-black").Validate())
+
+	o := newCanaryOptions(flagger.StrategyCanary)
+	o.MaxWeight = 150
+	assert.Error(t, o.Validate())
+}
+
+func TestWriteCanary(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "flagger-env")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "templates", flagger.FileName("myapp"))
+
+	err = flagger.WriteCanary(dir, newCanaryOptions(flagger.StrategyCanary))
+	require.NoError(t, err)
+	assert.FileExists(t, file)
+
+	err = flagger.WriteCanary(dir, newCanaryOptions(flagger.StrategyRolling))
+	require.NoError(t, err)
+	_, err = os.Stat(file)
+	assert.True(t, os.IsNotExist(err), "the rolling strategy should remove the Canary")
+}
+
+func newCanary(phase string, spec string, message string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": flagger.APIVersion,
+		"kind":       "Canary",
+		"metadata":   map[string]interface{}{"name": "myapp", "namespace": "jx-production"},
+		"status": map[string]interface{}{
+			"phase":           phase,
+			"lastAppliedSpec": spec,
+			"canaryWeight":    int64(20),
+			"conditions":      []interface{}{map[string]interface{}{"message": message}},
+		},
+	}}
+}
+
+func TestRolloutWatcher(t *testing.T) {
+	t.Parallel()
+
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), newCanary(flagger.PhaseSucceeded, "v1", "Canary analysis completed successfully"))
+	initial, err := flagger.GetCanaryStatus(client, "jx-production", "myapp")
+	require.NoError(t, err)
+	require.NotNil(t, initial)
+
+	// the status of the new version moves on at every poll
+	phases := []*unstructured.Unstructured{
+		newCanary("Progressing", "v2", "Advance myapp canary weight 20"),
+		newCanary(flagger.PhaseFailed, "v2", "Canary analysis failed, Deployment scaled to zero"),
+	}
+	statuses := []string{}
+	watcher := &flagger.RolloutWatcher{
+		Client:       client,
+		Namespace:    "jx-production",
+		Name:         "myapp",
+		Initial:      initial,
+		Timeout:      time.Minute,
+		PollInterval: time.Millisecond,
+		OnProgress: func(status *flagger.CanaryStatus) {
+			statuses = append(statuses, status.Phase)
+			if len(phases) > 0 {
+				_, err := client.Resource(flagger.CanaryResource).Namespace("jx-production").Update(phases[0], metav1.UpdateOptions{})
+				require.NoError(t, err)
+				phases = phases[1:]
+			}
+		},
+	}
+	status, err := watcher.Wait()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rolled back")
+	assert.Equal(t, flagger.PhaseFailed, status.Phase)
+	assert.Equal(t, []string{flagger.PhaseSucceeded, "Progressing", flagger.PhaseFailed}, statuses,
+		"the succeeded analysis of the previous version should be ignored")
+}
@@ -0,0 +1,373 @@
+package gits
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// apiCacheEnvVar disables the on-disk cache of the responses of the git provider APIs when set to 0 or off
+	apiCacheEnvVar = "JX_GIT_API_CACHE"
+	// apiCacheDirPermissions the cache holds the private responses of the users so only the owner can read it
+	apiCacheDirPermissions = 0700
+	// apiCacheFilePermissions the permissions of the cached responses
+	apiCacheFilePermissions = 0600
+
+	defaultAPIMaxRetries = 5
+	defaultAPIMaxWait    = 5 * time.Minute
+	defaultAPIBackoff    = time.Second
+
+	// maxCachedResponseSize the largest response body stored in the cache
+	maxCachedResponseSize = 5 * 1024 * 1024
+	// maxAPICacheSize the total size of the cache above which the least recently used responses are removed
+	maxAPICacheSize = 100 * 1024 * 1024
+	// maxAPICacheAge how long a response which is not used is kept in the cache
+	maxAPICacheAge = 30 * 24 * time.Hour
+	// apiCachePruneInterval the number of responses stored between the removals of the expired responses
+	apiCachePruneInterval = 100
+)
+
+var (
+	sharedAPITransport     http.RoundTripper
+	sharedAPITransportOnce sync.Once
+)
+
+// NewAPIHTTPClient returns an HTTP client for the APIs of the git providers which retries the requests rejected by a
+// rate limit and makes conditional requests for the responses cached on disk. The clients share the same transport so
+// that all providers reuse the connections and the cache
+func NewAPIHTTPClient() *http.Client {
+	sharedAPITransportOnce.Do(func() {
+		sharedAPITransport = newAPITransport(http.DefaultTransport, defaultAPICache())
+	})
+	return &http.Client{Transport: sharedAPITransport}
+}
+
+// apiTransport an http.RoundTripper which backs off when the API rate limit is exceeded and revalidates the cached
+// responses with their ETag or modification time, which does not count against the rate limit of GitHub
+type apiTransport struct {
+	base       http.RoundTripper
+	cache      *apiCache
+	maxRetries int
+	maxWait    time.Duration
+	backoff    time.Duration
+	now        func() time.Time
+	sleep      func(time.Duration)
+}
+
+func newAPITransport(base http.RoundTripper, cache *apiCache) *apiTransport {
+	return &apiTransport{
+		base:       base,
+		cache:      cache,
+		maxRetries: defaultAPIMaxRetries,
+		maxWait:    defaultAPIMaxWait,
+		backoff:    defaultAPIBackoff,
+		now:        time.Now,
+		sleep:      time.Sleep,
+	}
+}
+
+// RoundTrip sends the request retrying it while it is rate limited
+func (t *apiTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := ""
+	var cached *apiCacheEntry
+	if t.cache != nil && req.Method == http.MethodGet && req.Header.Get("Range") == "" {
+		key = apiCacheKey(req)
+		cached = t.cache.get(key)
+	}
+	for attempt := 0; ; attempt++ {
+		r, err := t.prepare(req, cached, attempt)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := t.base.RoundTrip(r)
+		if err != nil {
+			return nil, err
+		}
+		wait, limited := t.rateLimitWait(resp, attempt)
+		if limited && attempt < t.maxRetries && wait <= t.maxWait && (req.Body == nil || req.GetBody != nil) {
+			log.Logger().Warnf("The API rate limit of %s is exceeded, retrying in %s", req.URL.Host, wait.String())
+			drainAndClose(resp)
+			t.sleep(wait)
+			continue
+		}
+		if cached != nil && resp.StatusCode == http.StatusNotModified {
+			drainAndClose(resp)
+			return cached.response(req, resp.Header), nil
+		}
+		if key != "" && resp.StatusCode == http.StatusOK {
+			return t.store(key, resp)
+		}
+		return resp, nil
+	}
+}
+
+// prepare returns the request of the attempt with the conditional headers of the cached response
+func (t *apiTransport) prepare(req *http.Request, cached *apiCacheEntry, attempt int) (*http.Request, error) {
+	if cached == nil && attempt == 0 {
+		return req, nil
+	}
+	r := req.Clone(req.Context())
+	if attempt > 0 && req.Body != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, errors.Wrapf(err, "rewinding the body of %s", req.URL.String())
+		}
+		r.Body = body
+	}
+	if cached != nil {
+		if cached.ETag != "" && r.Header.Get("If-None-Match") == "" {
+			r.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" && r.Header.Get("If-Modified-Since") == "" {
+			r.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+	return r, nil
+}
+
+// rateLimitWait returns how long to wait before retrying the request if the response shows it was rate limited.
+// The Retry-After header of secondary rate limits takes precedence over the reset time of the primary rate limit,
+// otherwise the wait doubles with each attempt
+func (t *apiTransport) rateLimitWait(resp *http.Response, attempt int) (time.Duration, bool) {
+	retryAfter := resp.Header.Get("Retry-After")
+	remaining := resp.Header.Get("X-RateLimit-Remaining")
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+	case resp.StatusCode == http.StatusForbidden && (retryAfter != "" || remaining == "0"):
+	default:
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(retryAfter); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(retryAfter); err == nil {
+		return maxDuration(date.Sub(t.now()), 0), true
+	}
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil && remaining == "0" {
+		return maxDuration(time.Unix(reset, 0).Sub(t.now()), 0) + time.Second, true
+	}
+	return t.backoff * time.Duration(math.Pow(2, float64(attempt))), true
+}
+
+// store caches the response if it can be revalidated
+func (t *apiTransport) store(key string, resp *http.Response) (*http.Response, error) {
+	etag := resp.Header.Get("ETag")
+	lastModified := resp.Header.Get("Last-Modified")
+	if (etag == "" && lastModified == "") || resp.ContentLength > maxCachedResponseSize {
+		return resp, nil
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading the response of %s", resp.Request.URL.String())
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if len(body) <= maxCachedResponseSize {
+		t.cache.put(key, &apiCacheEntry{
+			ETag:         etag,
+			LastModified: lastModified,
+			StatusCode:   resp.StatusCode,
+			Header:       cacheableHeader(resp.Header),
+			Body:         body,
+		})
+	}
+	return resp, nil
+}
+
+// cacheableHeader returns the headers of the response without the cookies which must never be stored
+func cacheableHeader(header http.Header) http.Header {
+	answer := header.Clone()
+	answer.Del("Set-Cookie")
+	return answer
+}
+
+// apiCache the responses of the git provider APIs stored as a file per request in a directory. The modification time
+// of a file is updated each time it is used so that the least recently used responses are removed first.
+//
+// The bodies are stored unencrypted as they may include private repository data, so the directory and files are only
+// readable by their owner. Set JX_GIT_API_CACHE=off to never store responses on disk
+type apiCache struct {
+	dir string
+
+	lock sync.Mutex
+	puts int
+}
+
+// apiCacheEntry a cached response with the validators used to revalidate it
+type apiCacheEntry struct {
+	ETag         string      `json:"etag,omitempty"`
+	LastModified string      `json:"lastModified,omitempty"`
+	StatusCode   int         `json:"statusCode"`
+	Header       http.Header `json:"header"`
+	Body         []byte      `json:"body"`
+}
+
+// defaultAPICache returns the cache in the jx cache directory unless it is disabled or cannot be created
+func defaultAPICache() *apiCache {
+	value := os.Getenv(apiCacheEnvVar)
+	if value == "0" || value == "off" {
+		return nil
+	}
+	dir, err := util.CacheDir()
+	if err != nil {
+		log.Logger().Debugf("Not caching the git provider API responses: %s", err)
+		return nil
+	}
+	return &apiCache{dir: filepath.Join(dir, "git-api")}
+}
+
+// apiCacheKey returns the key of the request which includes its credentials so that users never share responses
+func apiCacheKey(req *http.Request) string {
+	hash := sha256.New()
+	for _, value := range []string{req.URL.String(), req.Header.Get("Authorization"), req.Header.Get("Accept"), req.Header.Get("Private-Token")} {
+		hash.Write([]byte(value))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (c *apiCache) get(key string) *apiCacheEntry {
+	fileName := filepath.Join(c.dir, key)
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil
+	}
+	entry := &apiCacheEntry{}
+	err = json.Unmarshal(data, entry)
+	if err != nil {
+		return nil
+	}
+	now := time.Now()
+	os.Chtimes(fileName, now, now) //nolint:errcheck
+	return entry
+}
+
+// put writes the entry via a temporary file so that concurrent processes never read a partial entry
+func (c *apiCache) put(key string, entry *apiCacheEntry) {
+	data, err := json.Marshal(entry)
+	if err == nil {
+		err = os.MkdirAll(c.dir, apiCacheDirPermissions)
+	}
+	if err == nil {
+		// the directory may have been created by an older jx which allowed others to read it
+		err = os.Chmod(c.dir, apiCacheDirPermissions)
+	}
+	var file *os.File
+	if err == nil {
+		file, err = ioutil.TempFile(c.dir, key+".tmp")
+	}
+	if err == nil {
+		err = file.Chmod(apiCacheFilePermissions)
+		if err == nil {
+			_, err = file.Write(data)
+		}
+		closeErr := file.Close()
+		if err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Rename(file.Name(), filepath.Join(c.dir, key))
+		}
+		if err != nil {
+			os.Remove(file.Name())
+		}
+	}
+	if err != nil {
+		log.Logger().Debugf("Failed to cache the git provider API response: %s", err)
+		return
+	}
+	c.lock.Lock()
+	prune := c.puts%apiCachePruneInterval == 0
+	c.puts++
+	c.lock.Unlock()
+	if prune {
+		err = c.prune(maxAPICacheSize, maxAPICacheAge, time.Now())
+		if err != nil {
+			log.Logger().Debugf("Failed to prune the git provider API cache: %s", err)
+		}
+	}
+}
+
+// prune removes the responses not used within the maximum age then the least recently used responses until the
+// cache is no larger than the maximum size
+func (c *apiCache) prune(maxSize int64, maxAge time.Duration, now time.Time) error {
+	files, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		return errors.Wrapf(err, "reading directory %s", c.dir)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().After(files[j].ModTime())
+	})
+	size := int64(0)
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		size += file.Size()
+		// temporary files are kept until they expire as they may be being written by another process
+		if now.Sub(file.ModTime()) <= maxAge && (size <= maxSize || strings.Contains(file.Name(), ".tmp")) {
+			continue
+		}
+		err = os.Remove(filepath.Join(c.dir, file.Name()))
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "removing %s", file.Name())
+		}
+	}
+	return nil
+}
+
+// response returns the cached response of the request updated with the headers of the not modified response such as
+// the current rate limit
+func (e *apiCacheEntry) response(req *http.Request, notModified http.Header) *http.Response {
+	header := e.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	for name, values := range notModified {
+		switch name {
+		case "Content-Length", "Content-Type", "Content-Encoding", "Transfer-Encoding":
+		default:
+			header[name] = append([]string{}, values...)
+		}
+	}
+	header.Set("X-From-Cache", "1")
+	return &http.Response{
+		Status:        strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+func drainAndClose(resp *http.Response) {
+	ioutil.ReadAll(resp.Body) //nolint:errcheck
+	resp.Body.Close()
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,197 @@
+// +build unit
+
+package gits
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAPITransport(t *testing.T, cache *apiCache) (*apiTransport, *[]time.Duration) {
+	waits := []time.Duration{}
+	transport := newAPITransport(http.DefaultTransport, cache)
+	transport.now = func() time.Time {
+		return time.Unix(1000, 0)
+	}
+	transport.sleep = func(d time.Duration) {
+		waits = append(waits, d)
+	}
+	return transport, &waits
+}
+
+func getBody(t *testing.T, client *http.Client, url string) (*http.Response, string) {
+	resp, err := client.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(body)
+}
+
+func TestAPITransportRetriesRateLimitedRequests(t *testing.T) {
+	t.Parallel()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch requests {
+		case 1:
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusForbidden)
+		case 2:
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", "1010")
+			w.WriteHeader(http.StatusForbidden)
+		case 3:
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	transport, waits := newTestAPITransport(t, nil)
+	resp, body := getBody(t, &http.Client{Transport: transport}, server.URL)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "ok", body)
+	assert.Equal(t, []time.Duration{3 * time.Second, 11 * time.Second, 4 * time.Second}, *waits)
+}
+
+func TestAPITransportGivesUpOnLongRateLimits(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.Itoa(1000+3600))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	transport, waits := newTestAPITransport(t, nil)
+	resp, _ := getBody(t, &http.Client{Transport: transport}, server.URL)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Empty(t, *waits)
+
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer plain.Close()
+	resp, _ = getBody(t, &http.Client{Transport: transport}, plain.URL)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Empty(t, *waits, "a forbidden response without rate limit headers should not be retried")
+}
+
+func TestAPITransportRevalidatesCachedResponses(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "git-api-cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	conditional := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conditional = append(conditional, r.Header.Get("If-None-Match"))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(100-len(conditional)))
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"myrepo"}`))
+	}))
+	defer server.Close()
+
+	transport, _ := newTestAPITransport(t, &apiCache{dir: dir})
+	client := &http.Client{Transport: transport}
+
+	resp, body := getBody(t, client, server.URL+"/repos/myorg/myrepo")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"name":"myrepo"}`, body)
+	assert.Empty(t, resp.Header.Get("X-From-Cache"))
+
+	resp, body = getBody(t, client, server.URL+"/repos/myorg/myrepo")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"name":"myrepo"}`, body)
+	assert.Equal(t, "1", resp.Header.Get("X-From-Cache"))
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "98", resp.Header.Get("X-RateLimit-Remaining"), "the rate limit of the not modified response should be used")
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/repos/myorg/myrepo", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "token other")
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Empty(t, resp.Header.Get("X-From-Cache"), "the responses of other users should not be shared")
+
+	assert.Equal(t, []string{"", `"v1"`, ""}, conditional)
+}
+
+func TestAPICacheIsOnlyReadableByTheOwner(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "git-api-cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cacheDir := filepath.Join(dir, "git-api")
+	require.NoError(t, os.MkdirAll(cacheDir, 0755))
+	cache := &apiCache{dir: cacheDir}
+	cache.put("mykey", &apiCacheEntry{
+		ETag:       `"v1"`,
+		StatusCode: http.StatusOK,
+		Header:     cacheableHeader(http.Header{"Set-Cookie": []string{"session=secret"}, "Content-Type": []string{"application/json"}}),
+		Body:       []byte(`{"private":true}`),
+	})
+
+	info, err := os.Stat(cacheDir)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm(), "permissions of %s", cacheDir)
+	info, err = os.Stat(filepath.Join(cacheDir, "mykey"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "permissions of the cached response")
+
+	entry := cache.get("mykey")
+	require.NotNil(t, entry)
+	assert.Empty(t, entry.Header.Get("Set-Cookie"), "cookies should not be cached")
+	assert.Equal(t, "application/json", entry.Header.Get("Content-Type"))
+}
+
+func TestAPICachePrunesExpiredAndLeastRecentlyUsedResponses(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "git-api-cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Now()
+	cache := &apiCache{dir: dir}
+	for i, age := range []time.Duration{time.Minute, time.Hour, 2 * time.Hour, 48 * time.Hour} {
+		fileName := filepath.Join(dir, "entry"+strconv.Itoa(i))
+		err = ioutil.WriteFile(fileName, make([]byte, 10), 0600)
+		require.NoError(t, err)
+		err = os.Chtimes(fileName, now.Add(-age), now.Add(-age))
+		require.NoError(t, err)
+	}
+
+	err = cache.prune(25, 24*time.Hour, now)
+	require.NoError(t, err)
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	names := []string{}
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	assert.Equal(t, []string{"entry0", "entry1"}, names)
+}
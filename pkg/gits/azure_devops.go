@@ -0,0 +1,1283 @@
+package gits
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// AzureDevOpsURL the URL of Azure DevOps Services
+	AzureDevOpsURL = "https://dev.azure.com"
+
+	azureDevOpsHost       = "dev.azure.com"
+	azureDevOpsLegacyHost = ".visualstudio.com"
+	azureDevOpsAPIVersion = "6.0"
+)
+
+// azureDevOpsWebHookEvents the service hook events which are sent to a webhook. Azure DevOps has a service hook
+// subscription per event type rather than a single webhook per repository
+var azureDevOpsWebHookEvents = []string{
+	"git.push",
+	"git.pullrequest.created",
+	"git.pullrequest.updated",
+	"git.pullrequest.merged",
+	"ms.vss-code.git-pullrequest-comment-event",
+}
+
+// azureDevOpsStates maps the commit status states of Azure DevOps to the ones of GitHub
+var azureDevOpsStates = map[string]string{
+	"pending":   "pending",
+	"succeeded": "success",
+	"failed":    "failure",
+	"error":     "error",
+}
+
+// AzureDevOpsProvider implements GitProvider interface for Azure DevOps Repos. The owner of a repository is
+// of the form organization/project as Azure DevOps groups the repositories of an organization into projects
+type AzureDevOpsProvider struct {
+	Username string
+	Client   *http.Client
+	// Organization the organization of the server URL, if any, which is used for owners which are only a project
+	Organization string
+
+	Server auth.AuthServer
+	User   auth.UserAuth
+	Git    Gitter
+
+	baseURL string
+}
+
+type azureDevOpsError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *azureDevOpsError) Error() string {
+	return fmt.Sprintf("status %d: %s", e.StatusCode, e.Message)
+}
+
+type azureDevOpsProject struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type azureDevOpsRepository struct {
+	ID            string             `json:"id"`
+	Name          string             `json:"name"`
+	URL           string             `json:"url"`
+	RemoteURL     string             `json:"remoteUrl"`
+	SSHURL        string             `json:"sshUrl"`
+	WebURL        string             `json:"webUrl"`
+	DefaultBranch string             `json:"defaultBranch"`
+	IsFork        bool               `json:"isFork"`
+	Project       azureDevOpsProject `json:"project"`
+}
+
+type azureDevOpsIdentity struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	UniqueName  string `json:"uniqueName"`
+	ImageURL    string `json:"imageUrl"`
+	Vote        int    `json:"vote,omitempty"`
+	IsRequired  bool   `json:"isRequired,omitempty"`
+}
+
+type azureDevOpsCommitRef struct {
+	CommitID string `json:"commitId"`
+}
+
+type azureDevOpsLabel struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+}
+
+type azureDevOpsPullRequest struct {
+	PullRequestID         int                    `json:"pullRequestId,omitempty"`
+	Status                string                 `json:"status,omitempty"`
+	Title                 string                 `json:"title,omitempty"`
+	Description           string                 `json:"description,omitempty"`
+	SourceRefName         string                 `json:"sourceRefName,omitempty"`
+	TargetRefName         string                 `json:"targetRefName,omitempty"`
+	MergeStatus           string                 `json:"mergeStatus,omitempty"`
+	CreatedBy             *azureDevOpsIdentity   `json:"createdBy,omitempty"`
+	CreationDate          *time.Time             `json:"creationDate,omitempty"`
+	ClosedDate            *time.Time             `json:"closedDate,omitempty"`
+	LastMergeSourceCommit *azureDevOpsCommitRef  `json:"lastMergeSourceCommit,omitempty"`
+	LastMergeCommit       *azureDevOpsCommitRef  `json:"lastMergeCommit,omitempty"`
+	Labels                []azureDevOpsLabel     `json:"labels,omitempty"`
+	Reviewers             []*azureDevOpsIdentity `json:"reviewers,omitempty"`
+	CompletionOptions     map[string]interface{} `json:"completionOptions,omitempty"`
+}
+
+type azureDevOpsStatusContext struct {
+	Name  string `json:"name"`
+	Genre string `json:"genre,omitempty"`
+}
+
+type azureDevOpsStatus struct {
+	ID          int                      `json:"id,omitempty"`
+	State       string                   `json:"state"`
+	Description string                   `json:"description"`
+	TargetURL   string                   `json:"targetUrl"`
+	URL         string                   `json:"url,omitempty"`
+	Context     azureDevOpsStatusContext `json:"context"`
+}
+
+type azureDevOpsCommit struct {
+	CommitID string `json:"commitId"`
+	Comment  string `json:"comment"`
+	URL      string `json:"url"`
+	Author   struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	} `json:"author"`
+	Committer struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	} `json:"committer"`
+}
+
+type azureDevOpsSubscription struct {
+	ID               string            `json:"id,omitempty"`
+	PublisherID      string            `json:"publisherId"`
+	EventType        string            `json:"eventType"`
+	ResourceVersion  string            `json:"resourceVersion"`
+	ConsumerID       string            `json:"consumerId"`
+	ConsumerActionID string            `json:"consumerActionId"`
+	PublisherInputs  map[string]string `json:"publisherInputs"`
+	ConsumerInputs   map[string]string `json:"consumerInputs"`
+}
+
+// NewAzureDevOpsProvider creates a git provider for Azure DevOps Services or Server. The user authenticates with
+// either a personal access token as its API token or an OAuth access token as its bearer token
+func NewAzureDevOpsProvider(server *auth.AuthServer, user *auth.UserAuth, git Gitter) (GitProvider, error) {
+	serverURL := strings.TrimSuffix(server.URL, "/")
+	if serverURL == "" {
+		serverURL = AzureDevOpsURL
+	}
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the Azure DevOps server URL %s", serverURL)
+	}
+	provider := &AzureDevOpsProvider{
+		Username: user.Username,
+		Client:   NewAPIHTTPClient(),
+		Server:   *server,
+		User:     *user,
+		Git:      git,
+		baseURL:  serverURL,
+	}
+	switch {
+	case u.Host == azureDevOpsHost:
+		// the organization is the first path element of https://dev.azure.com/myorg
+		provider.baseURL = u.Scheme + "://" + u.Host
+		provider.Organization = strings.Split(strings.Trim(u.Path, "/"), "/")[0]
+	case strings.HasSuffix(u.Host, azureDevOpsLegacyHost):
+		provider.baseURL = u.Scheme + "://" + azureDevOpsHost
+		provider.Organization = strings.TrimSuffix(u.Host, azureDevOpsLegacyHost)
+	}
+	return provider, nil
+}
+
+// IsAzureDevOpsServerURL returns true if the URL is for Azure DevOps Services
+func IsAzureDevOpsServerURL(u string) bool {
+	return strings.HasPrefix(u, AzureDevOpsURL) || strings.Contains(u, azureDevOpsLegacyHost)
+}
+
+// AzureDevOpsAccessTokenURL returns the URL to create personal access tokens
+func AzureDevOpsAccessTokenURL(url string) string {
+	return util.UrlJoin(url, "_usersSettings", "tokens")
+}
+
+// splitOwner returns the organization and project of the owner
+func (p *AzureDevOpsProvider) splitOwner(owner string) (string, string, error) {
+	parts := strings.SplitN(owner, "/", 2)
+	if len(parts) == 2 && parts[0] != "" && parts[1] != "" {
+		return parts[0], parts[1], nil
+	}
+	if p.Organization != "" && owner != "" {
+		return p.Organization, owner, nil
+	}
+	return "", "", errors.Errorf("the owner %q of an Azure DevOps repository should be of the form organization/project", owner)
+}
+
+func (p *AzureDevOpsProvider) apiURL(owner string, paths ...string) (string, error) {
+	org, project, err := p.splitOwner(owner)
+	if err != nil {
+		return "", err
+	}
+	return util.UrlJoin(append([]string{p.baseURL, url.PathEscape(org), url.PathEscape(project), "_apis"}, paths...)...), nil
+}
+
+func (p *AzureDevOpsProvider) repoURL(owner string, repo string, paths ...string) (string, error) {
+	return p.apiURL(owner, append([]string{"git", "repositories", url.PathEscape(repo)}, paths...)...)
+}
+
+func (p *AzureDevOpsProvider) pullRequestURL(owner string, repo string, number int, paths ...string) (string, error) {
+	return p.repoURL(owner, repo, append([]string{"pullrequests", strconv.Itoa(number)}, paths...)...)
+}
+
+// webURL returns the URL of the repository in the web console
+func (p *AzureDevOpsProvider) webURL(owner string, repo string) string {
+	org, project, err := p.splitOwner(owner)
+	if err != nil {
+		return ""
+	}
+	return util.UrlJoin(p.baseURL, org, project, "_git", repo)
+}
+
+// do invokes the REST API unmarshalling the response into the result if it is not nil
+func (p *AzureDevOpsProvider) do(method string, u string, body interface{}, result interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return errors.Wrap(err, "marshalling the request body")
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	if !strings.Contains(u, "api-version=") {
+		separator := "?"
+		if strings.Contains(u, "?") {
+			separator = "&"
+		}
+		u += separator + "api-version=" + azureDevOpsAPIVersion
+	}
+	req, err := http.NewRequest(method, u, reader)
+	if err != nil {
+		return errors.Wrapf(err, "creating request %s %s", method, u)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if p.User.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+p.User.BearerToken)
+	} else {
+		req.SetBasicAuth(p.User.Username, p.User.ApiToken)
+	}
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "invoking %s %s", method, u)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "reading the response of %s %s", method, u)
+	}
+	if resp.StatusCode >= 300 {
+		return &azureDevOpsError{StatusCode: resp.StatusCode, Message: string(data)}
+	}
+	if result != nil && len(data) > 0 {
+		err = json.Unmarshal(data, result)
+		if err != nil {
+			return errors.Wrapf(err, "unmarshalling the response of %s %s", method, u)
+		}
+	}
+	return nil
+}
+
+func isAzureDevOpsNotFound(err error) bool {
+	e, ok := errors.Cause(err).(*azureDevOpsError)
+	return ok && e.StatusCode == http.StatusNotFound
+}
+
+// ListOrganisations lists the projects of the organization of the server as organization/project owners
+func (p *AzureDevOpsProvider) ListOrganisations() ([]GitOrganisation, error) {
+	answer := []GitOrganisation{}
+	if p.Organization == "" {
+		log.Logger().Warnf("Cannot list the projects of the Azure DevOps server %s as its URL does not include the organization", p.Server.URL)
+		return answer, nil
+	}
+	results := struct {
+		Value []azureDevOpsProject `json:"value"`
+	}{}
+	err := p.do(http.MethodGet, util.UrlJoin(p.baseURL, url.PathEscape(p.Organization), "_apis", "projects"), nil, &results)
+	if err != nil {
+		return answer, errors.Wrapf(err, "listing the projects of %s", p.Organization)
+	}
+	for _, project := range results.Value {
+		answer = append(answer, GitOrganisation{Login: p.Organization + "/" + project.Name})
+	}
+	return answer, nil
+}
+
+// ListRepositories lists the repositories of the organization/project
+func (p *AzureDevOpsProvider) ListRepositories(org string) ([]*GitRepository, error) {
+	answer := []*GitRepository{}
+	u, err := p.apiURL(org, "git", "repositories")
+	if err != nil {
+		return answer, err
+	}
+	results := struct {
+		Value []*azureDevOpsRepository `json:"value"`
+	}{}
+	err = p.do(http.MethodGet, u, nil, &results)
+	if err != nil {
+		return answer, errors.Wrapf(err, "listing the repositories of %s", org)
+	}
+	for _, repo := range results.Value {
+		answer = append(answer, p.toGitRepository(org, repo))
+	}
+	return answer, nil
+}
+
+func (p *AzureDevOpsProvider) toGitRepository(owner string, repo *azureDevOpsRepository) *GitRepository {
+	return &GitRepository{
+		Name:             repo.Name,
+		AllowMergeCommit: true,
+		HTMLURL:          repo.WebURL,
+		CloneURL:         repo.RemoteURL,
+		SSHURL:           repo.SSHURL,
+		URL:              repo.RemoteURL,
+		Fork:             repo.IsFork,
+		Host:             azureDevOpsHost,
+		Organisation:     owner,
+		Project:          repo.Project.Name,
+		Private:          true,
+	}
+}
+
+func (p *AzureDevOpsProvider) getRepository(org string, name string) (*azureDevOpsRepository, error) {
+	u, err := p.repoURL(org, name)
+	if err != nil {
+		return nil, err
+	}
+	repo := &azureDevOpsRepository{}
+	err = p.do(http.MethodGet, u, nil, repo)
+	if err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// GetRepository gets the repository
+func (p *AzureDevOpsProvider) GetRepository(org string, name string) (*GitRepository, error) {
+	repo, err := p.getRepository(org, name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting repository %s/%s", org, name)
+	}
+	return p.toGitRepository(org, repo), nil
+}
+
+// CreateRepository creates a repository in the project. Azure DevOps repositories are always private
+func (p *AzureDevOpsProvider) CreateRepository(org string, name string, private bool) (*GitRepository, error) {
+	organization, project, err := p.splitOwner(org)
+	if err != nil {
+		return nil, err
+	}
+	proj := &azureDevOpsProject{}
+	err = p.do(http.MethodGet, util.UrlJoin(p.baseURL, url.PathEscape(organization), "_apis", "projects", url.PathEscape(project)), nil, proj)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting project %s", org)
+	}
+	u, err := p.apiURL(org, "git", "repositories")
+	if err != nil {
+		return nil, err
+	}
+	body := map[string]interface{}{
+		"name":    name,
+		"project": map[string]string{"id": proj.ID},
+	}
+	repo := &azureDevOpsRepository{}
+	err = p.do(http.MethodPost, u, body, repo)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating repository %s/%s", org, name)
+	}
+	return p.toGitRepository(org, repo), nil
+}
+
+// DeleteRepository deletes the repository
+func (p *AzureDevOpsProvider) DeleteRepository(org string, name string) error {
+	repo, err := p.getRepository(org, name)
+	if err != nil {
+		return errors.Wrapf(err, "getting repository %s/%s", org, name)
+	}
+	u, err := p.repoURL(org, repo.ID)
+	if err != nil {
+		return err
+	}
+	return p.do(http.MethodDelete, u, nil, nil)
+}
+
+// ForkRepository is not supported for Azure DevOps
+func (p *AzureDevOpsProvider) ForkRepository(originalOrg string, name string, destinationOrg string) (*GitRepository, error) {
+	return nil, errors.Errorf("forking repositories is not supported for Azure DevOps")
+}
+
+// RenameRepository renames the repository
+func (p *AzureDevOpsProvider) RenameRepository(org string, name string, newName string) (*GitRepository, error) {
+	repo, err := p.getRepository(org, name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting repository %s/%s", org, name)
+	}
+	u, err := p.repoURL(org, repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	renamed := &azureDevOpsRepository{}
+	err = p.do(http.MethodPatch, u, map[string]string{"name": newName}, renamed)
+	if err != nil {
+		return nil, errors.Wrapf(err, "renaming repository %s/%s to %s", org, name, newName)
+	}
+	return p.toGitRepository(org, renamed), nil
+}
+
+// ValidateRepositoryName returns an error if the repository already exists
+func (p *AzureDevOpsProvider) ValidateRepositoryName(org string, name string) error {
+	_, err := p.getRepository(org, name)
+	if err == nil {
+		return fmt.Errorf("repository %s/%s already exists", org, name)
+	}
+	if isAzureDevOpsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// CreatePullRequest creates a pull request
+func (p *AzureDevOpsProvider) CreatePullRequest(data *GitPullRequestArguments) (*GitPullRequest, error) {
+	owner := data.GitRepository.Organisation
+	repo := data.GitRepository.Name
+	u, err := p.repoURL(owner, repo, "pullrequests")
+	if err != nil {
+		return nil, err
+	}
+	body := &azureDevOpsPullRequest{
+		Title:         data.Title,
+		Description:   data.Body,
+		SourceRefName: azureDevOpsBranchRef(data.Head),
+		TargetRefName: azureDevOpsBranchRef(data.Base),
+	}
+	pr := &azureDevOpsPullRequest{}
+	err = p.do(http.MethodPost, u, body, pr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating pull request on %s/%s", owner, repo)
+	}
+	return p.toPullRequest(owner, repo, pr), nil
+}
+
+// UpdatePullRequest updates the title and description of the pull request
+func (p *AzureDevOpsProvider) UpdatePullRequest(data *GitPullRequestArguments, number int) (*GitPullRequest, error) {
+	owner := data.GitRepository.Organisation
+	repo := data.GitRepository.Name
+	u, err := p.pullRequestURL(owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+	body := &azureDevOpsPullRequest{
+		Title:       data.Title,
+		Description: data.Body,
+	}
+	pr := &azureDevOpsPullRequest{}
+	err = p.do(http.MethodPatch, u, body, pr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "updating pull request %s/%s #%d", owner, repo, number)
+	}
+	return p.toPullRequest(owner, repo, pr), nil
+}
+
+func azureDevOpsBranchRef(branch string) string {
+	if strings.HasPrefix(branch, "refs/") {
+		return branch
+	}
+	return "refs/heads/" + branch
+}
+
+func (p *AzureDevOpsProvider) toPullRequest(owner string, repo string, source *azureDevOpsPullRequest) *GitPullRequest {
+	number := source.PullRequestID
+	pr := &GitPullRequest{
+		URL:    util.UrlJoin(p.webURL(owner, repo), "pullrequest", strconv.Itoa(number)),
+		Owner:  owner,
+		Repo:   repo,
+		Number: &number,
+	}
+	p.updatePullRequest(pr, source)
+	return pr
+}
+
+// updatePullRequest updates the pr with the data from Azure DevOps. The active, completed and abandoned statuses
+// become open, merged and closed
+func (p *AzureDevOpsProvider) updatePullRequest(pr *GitPullRequest, source *azureDevOpsPullRequest) {
+	state := "open"
+	merged := false
+	switch source.Status {
+	case "completed":
+		state = "closed"
+		merged = true
+		pr.MergedAt = source.ClosedDate
+	case "abandoned":
+		state = "closed"
+	}
+	pr.State = &state
+	pr.Merged = &merged
+	pr.ClosedAt = source.ClosedDate
+	mergeable := source.MergeStatus == "succeeded"
+	pr.Mergeable = &mergeable
+	pr.Title = source.Title
+	pr.Body = source.Description
+	headRef := strings.TrimPrefix(source.SourceRefName, "refs/heads/")
+	pr.HeadRef = &headRef
+	if source.CreatedBy != nil {
+		pr.Author = toAzureDevOpsUser(source.CreatedBy)
+	}
+	if source.LastMergeSourceCommit != nil {
+		pr.LastCommitSha = source.LastMergeSourceCommit.CommitID
+	}
+	if source.LastMergeCommit != nil && merged {
+		pr.MergeCommitSHA = &source.LastMergeCommit.CommitID
+	}
+	pr.Labels = nil
+	for _, label := range source.Labels {
+		name := label.Name
+		pr.Labels = append(pr.Labels, &Label{Name: &name})
+	}
+	// the required reviewers who have still to approve
+	pr.RequestedReviewers = nil
+	for _, reviewer := range source.Reviewers {
+		if reviewer.IsRequired && reviewer.Vote <= 0 {
+			pr.RequestedReviewers = append(pr.RequestedReviewers, toAzureDevOpsUser(reviewer))
+		}
+	}
+}
+
+func toAzureDevOpsUser(identity *azureDevOpsIdentity) *GitUser {
+	return &GitUser{
+		Login:     identity.UniqueName,
+		Name:      identity.DisplayName,
+		Email:     identity.UniqueName,
+		AvatarURL: identity.ImageURL,
+	}
+}
+
+func (p *AzureDevOpsProvider) getPullRequest(owner string, repo string, number int) (*azureDevOpsPullRequest, error) {
+	u, err := p.pullRequestURL(owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+	pr := &azureDevOpsPullRequest{}
+	err = p.do(http.MethodGet, u, nil, pr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting pull request %s/%s #%d", owner, repo, number)
+	}
+	return pr, nil
+}
+
+// UpdatePullRequestStatus updates the pull request with its current state
+func (p *AzureDevOpsProvider) UpdatePullRequestStatus(pr *GitPullRequest) error {
+	if pr.Number == nil {
+		return fmt.Errorf("missing Number for GitPullRequest %#v", pr)
+	}
+	source, err := p.getPullRequest(pr.Owner, pr.Repo, *pr.Number)
+	if err != nil {
+		return err
+	}
+	p.updatePullRequest(pr, source)
+	return nil
+}
+
+// AddLabelsToIssue adds the labels to the pull request. Azure DevOps only supports labels, known as tags, on
+// pull requests
+func (p *AzureDevOpsProvider) AddLabelsToIssue(owner, repo string, number int, labels []string) error {
+	u, err := p.pullRequestURL(owner, repo, number, "labels")
+	if err != nil {
+		return err
+	}
+	for _, label := range labels {
+		err = p.do(http.MethodPost, u, &azureDevOpsLabel{Name: label}, nil)
+		if err != nil {
+			return errors.Wrapf(err, "adding label %s to pull request %s/%s #%d", label, owner, repo, number)
+		}
+	}
+	return nil
+}
+
+// GetPullRequest gets the pull request
+func (p *AzureDevOpsProvider) GetPullRequest(owner string, repo *GitRepository, number int) (*GitPullRequest, error) {
+	source, err := p.getPullRequest(owner, repo.Name, number)
+	if err != nil {
+		return nil, err
+	}
+	return p.toPullRequest(owner, repo.Name, source), nil
+}
+
+// ListOpenPullRequests lists the active pull requests
+func (p *AzureDevOpsProvider) ListOpenPullRequests(owner string, repo string) ([]*GitPullRequest, error) {
+	answer := []*GitPullRequest{}
+	u, err := p.repoURL(owner, repo, "pullrequests")
+	if err != nil {
+		return answer, err
+	}
+	results := struct {
+		Value []*azureDevOpsPullRequest `json:"value"`
+	}{}
+	err = p.do(http.MethodGet, u+"?searchCriteria.status=active", nil, &results)
+	if err != nil {
+		return answer, errors.Wrapf(err, "listing pull requests of %s/%s", owner, repo)
+	}
+	for _, pr := range results.Value {
+		answer = append(answer, p.toPullRequest(owner, repo, pr))
+	}
+	return answer, nil
+}
+
+// GetPullRequestCommits gets the commits of the pull request
+func (p *AzureDevOpsProvider) GetPullRequestCommits(owner string, repository *GitRepository, number int) ([]*GitCommit, error) {
+	answer := []*GitCommit{}
+	u, err := p.pullRequestURL(owner, repository.Name, number, "commits")
+	if err != nil {
+		return answer, err
+	}
+	results := struct {
+		Value []*azureDevOpsCommit `json:"value"`
+	}{}
+	err = p.do(http.MethodGet, u, nil, &results)
+	if err != nil {
+		return answer, errors.Wrapf(err, "listing the commits of pull request %s/%s #%d", owner, repository.Name, number)
+	}
+	for _, commit := range results.Value {
+		answer = append(answer, toAzureDevOpsCommit(commit))
+	}
+	return answer, nil
+}
+
+func toAzureDevOpsCommit(commit *azureDevOpsCommit) *GitCommit {
+	return &GitCommit{
+		SHA:     commit.CommitID,
+		Message: commit.Comment,
+		URL:     commit.URL,
+		Author: &GitUser{
+			Login: commit.Author.Email,
+			Name:  commit.Author.Name,
+			Email: commit.Author.Email,
+		},
+		Committer: &GitUser{
+			Login: commit.Committer.Email,
+			Name:  commit.Committer.Name,
+			Email: commit.Committer.Email,
+		},
+	}
+}
+
+// PullRequestLastCommitStatus returns the state of the latest status of the last commit of the pull request
+func (p *AzureDevOpsProvider) PullRequestLastCommitStatus(pr *GitPullRequest) (string, error) {
+	if pr.LastCommitSha == "" {
+		return "", fmt.Errorf("missing LastCommitSha for GitPullRequest %#v", pr)
+	}
+	statuses, err := p.ListCommitStatus(pr.Owner, pr.Repo, pr.LastCommitSha)
+	if err != nil {
+		return "", err
+	}
+	for _, status := range statuses {
+		if status.State != "" {
+			return status.State, nil
+		}
+	}
+	return "", fmt.Errorf("could not find a status for repository %s/%s with ref %s", pr.Owner, pr.Repo, pr.LastCommitSha)
+}
+
+// ListCommitStatus lists the statuses of the commit, most recent first
+func (p *AzureDevOpsProvider) ListCommitStatus(org string, repo string, sha string) ([]*GitRepoStatus, error) {
+	answer := []*GitRepoStatus{}
+	u, err := p.repoURL(org, repo, "commits", sha, "statuses")
+	if err != nil {
+		return answer, err
+	}
+	results := struct {
+		Value []*azureDevOpsStatus `json:"value"`
+	}{}
+	err = p.do(http.MethodGet, u, nil, &results)
+	if err != nil {
+		return answer, errors.Wrapf(err, "listing the statuses of commit %s of %s/%s", sha, org, repo)
+	}
+	for _, status := range results.Value {
+		answer = append(answer, toAzureDevOpsRepoStatus(status))
+	}
+	return answer, nil
+}
+
+func toAzureDevOpsRepoStatus(status *azureDevOpsStatus) *GitRepoStatus {
+	state := azureDevOpsStates[status.State]
+	if state == "" {
+		state = status.State
+	}
+	return &GitRepoStatus{
+		ID:          strconv.Itoa(status.ID),
+		Context:     status.Context.Name,
+		URL:         status.URL,
+		State:       state,
+		TargetURL:   status.TargetURL,
+		Description: status.Description,
+	}
+}
+
+// UpdateCommitStatus adds the status to the commit
+func (p *AzureDevOpsProvider) UpdateCommitStatus(org string, repo string, sha string, status *GitRepoStatus) (*GitRepoStatus, error) {
+	u, err := p.repoURL(org, repo, "commits", sha, "statuses")
+	if err != nil {
+		return nil, err
+	}
+	state := status.State
+	for k, v := range azureDevOpsStates {
+		if v == status.State {
+			state = k
+		}
+	}
+	body := &azureDevOpsStatus{
+		State:       state,
+		Description: status.Description,
+		TargetURL:   status.TargetURL,
+		Context: azureDevOpsStatusContext{
+			Name:  status.Context,
+			Genre: "jenkins-x",
+		},
+	}
+	result := &azureDevOpsStatus{}
+	err = p.do(http.MethodPost, u, body, result)
+	if err != nil {
+		return nil, errors.Wrapf(err, "updating the status of commit %s of %s/%s", sha, org, repo)
+	}
+	return toAzureDevOpsRepoStatus(result), nil
+}
+
+// ListCommits lists the commits of the branch
+func (p *AzureDevOpsProvider) ListCommits(owner string, repo string, opt *ListCommitsArguments) ([]*GitCommit, error) {
+	answer := []*GitCommit{}
+	u, err := p.repoURL(owner, repo, "commits")
+	if err != nil {
+		return answer, err
+	}
+	params := url.Values{}
+	if opt != nil {
+		if opt.SHA != "" {
+			params.Set("searchCriteria.itemVersion.version", opt.SHA)
+		}
+		if opt.Path != "" {
+			params.Set("searchCriteria.itemPath", opt.Path)
+		}
+		if opt.Author != "" {
+			params.Set("searchCriteria.author", opt.Author)
+		}
+		if opt.PerPage > 0 {
+			params.Set("searchCriteria.$top", strconv.Itoa(opt.PerPage))
+			if opt.Page > 1 {
+				params.Set("searchCriteria.$skip", strconv.Itoa((opt.Page-1)*opt.PerPage))
+			}
+		}
+	}
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	results := struct {
+		Value []*azureDevOpsCommit `json:"value"`
+	}{}
+	err = p.do(http.MethodGet, u, nil, &results)
+	if err != nil {
+		return answer, errors.Wrapf(err, "listing the commits of %s/%s", owner, repo)
+	}
+	for _, commit := range results.Value {
+		answer = append(answer, toAzureDevOpsCommit(commit))
+	}
+	return answer, nil
+}
+
+// MergePullRequest completes the pull request
+func (p *AzureDevOpsProvider) MergePullRequest(pr *GitPullRequest, message string) error {
+	if pr.Number == nil {
+		return fmt.Errorf("missing Number for GitPullRequest %#v", pr)
+	}
+	source, err := p.getPullRequest(pr.Owner, pr.Repo, *pr.Number)
+	if err != nil {
+		return err
+	}
+	u, err := p.pullRequestURL(pr.Owner, pr.Repo, *pr.Number)
+	if err != nil {
+		return err
+	}
+	body := &azureDevOpsPullRequest{
+		Status:                "completed",
+		LastMergeSourceCommit: source.LastMergeSourceCommit,
+		CompletionOptions: map[string]interface{}{
+			"mergeCommitMessage": message,
+		},
+	}
+	err = p.do(http.MethodPatch, u, body, nil)
+	if err != nil {
+		return errors.Wrapf(err, "completing pull request %s/%s #%d", pr.Owner, pr.Repo, *pr.Number)
+	}
+	return nil
+}
+
+// listSubscriptions lists the service hook subscriptions of the organization for the repository
+func (p *AzureDevOpsProvider) listSubscriptions(owner string, repoID string) ([]*azureDevOpsSubscription, error) {
+	org, _, err := p.splitOwner(owner)
+	if err != nil {
+		return nil, err
+	}
+	results := struct {
+		Value []*azureDevOpsSubscription `json:"value"`
+	}{}
+	err = p.do(http.MethodGet, util.UrlJoin(p.baseURL, url.PathEscape(org), "_apis", "hooks", "subscriptions"), nil, &results)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the service hooks of %s", org)
+	}
+	answer := []*azureDevOpsSubscription{}
+	for _, s := range results.Value {
+		if s.ConsumerID == "webHooks" && s.PublisherInputs["repository"] == repoID {
+			answer = append(answer, s)
+		}
+	}
+	return answer, nil
+}
+
+func (p *AzureDevOpsProvider) saveSubscription(owner string, subscription *azureDevOpsSubscription) error {
+	org, _, err := p.splitOwner(owner)
+	if err != nil {
+		return err
+	}
+	u := util.UrlJoin(p.baseURL, url.PathEscape(org), "_apis", "hooks", "subscriptions")
+	method := http.MethodPost
+	if subscription.ID != "" {
+		u = util.UrlJoin(u, subscription.ID)
+		method = http.MethodPut
+	}
+	err = p.do(method, u, subscription, nil)
+	if err != nil {
+		return errors.Wrapf(err, "saving the %s service hook for %s", subscription.EventType, subscription.ConsumerInputs["url"])
+	}
+	return nil
+}
+
+// webHookConsumerInputs returns the inputs of the web hook consumer. Service hooks cannot sign their payloads so
+// the secret is sent as the basic authentication password
+func webHookConsumerInputs(data *GitWebHookArguments) map[string]string {
+	inputs := map[string]string{
+		"url": data.URL,
+	}
+	if data.Secret != "" {
+		inputs["basicAuthUsername"] = "jenkins-x"
+		inputs["basicAuthPassword"] = data.Secret
+	}
+	if data.InsecureSSL {
+		inputs["acceptUntrustedCerts"] = "true"
+	}
+	return inputs
+}
+
+// CreateWebHook creates a service hook subscription for each of the events of the repository which is not already
+// sent to the URL
+func (p *AzureDevOpsProvider) CreateWebHook(data *GitWebHookArguments) error {
+	owner := data.Owner
+	repo, err := p.getRepository(owner, data.Repo.Name)
+	if err != nil {
+		return errors.Wrapf(err, "getting repository %s/%s", owner, data.Repo.Name)
+	}
+	existing, err := p.listSubscriptions(owner, repo.ID)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Creating Azure DevOps service hooks for %s/%s for url %s", util.ColorInfo(owner), util.ColorInfo(repo.Name), util.ColorInfo(data.URL))
+	for _, eventType := range azureDevOpsWebHookEvents {
+		subscription := &azureDevOpsSubscription{
+			PublisherID:      "tfs",
+			EventType:        eventType,
+			ResourceVersion:  "1.0",
+			ConsumerID:       "webHooks",
+			ConsumerActionID: "httpRequest",
+			PublisherInputs: map[string]string{
+				"projectId":  repo.Project.ID,
+				"repository": repo.ID,
+			},
+			ConsumerInputs: webHookConsumerInputs(data),
+		}
+		for _, s := range existing {
+			if s.EventType == eventType && s.ConsumerInputs["url"] == data.URL {
+				subscription.ID = s.ID
+				subscription.PublisherInputs = s.PublisherInputs
+			}
+		}
+		err = p.saveSubscription(owner, subscription)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListWebHooks lists the URLs the service hooks of the repository are sent to. Service hooks are identified by
+// GUIDs so the webhooks have no ID
+func (p *AzureDevOpsProvider) ListWebHooks(owner string, repo string) ([]*GitWebHookArguments, error) {
+	answer := []*GitWebHookArguments{}
+	r, err := p.getRepository(owner, repo)
+	if err != nil {
+		return answer, errors.Wrapf(err, "getting repository %s/%s", owner, repo)
+	}
+	subscriptions, err := p.listSubscriptions(owner, r.ID)
+	if err != nil {
+		return answer, err
+	}
+	urls := map[string]string{}
+	for _, s := range subscriptions {
+		urls[s.ConsumerInputs["url"]] = s.ID
+	}
+	for _, u := range util.SortedMapKeys(urls) {
+		answer = append(answer, &GitWebHookArguments{
+			Owner: owner,
+			Repo: &GitRepository{
+				Organisation: owner,
+				Name:         repo,
+			},
+			URL: u,
+		})
+	}
+	return answer, nil
+}
+
+// UpdateWebHook updates the service hooks sent to the existing URL
+func (p *AzureDevOpsProvider) UpdateWebHook(data *GitWebHookArguments) error {
+	owner := data.Owner
+	repo, err := p.getRepository(owner, data.Repo.Name)
+	if err != nil {
+		return errors.Wrapf(err, "getting repository %s/%s", owner, data.Repo.Name)
+	}
+	subscriptions, err := p.listSubscriptions(owner, repo.ID)
+	if err != nil {
+		return err
+	}
+	existingURL := data.ExistingURL
+	if existingURL == "" {
+		existingURL = data.URL
+	}
+	found := false
+	for _, s := range subscriptions {
+		if s.ConsumerInputs["url"] != existingURL {
+			continue
+		}
+		found = true
+		s.ConsumerInputs = webHookConsumerInputs(data)
+		err = p.saveSubscription(owner, s)
+		if err != nil {
+			return err
+		}
+	}
+	if !found {
+		log.Logger().Warn("No webhooks found to update")
+	}
+	return nil
+}
+
+// IsGitHub returns false
+func (p *AzureDevOpsProvider) IsGitHub() bool {
+	return false
+}
+
+// IsGitea returns false
+func (p *AzureDevOpsProvider) IsGitea() bool {
+	return false
+}
+
+// IsBitbucketCloud returns false
+func (p *AzureDevOpsProvider) IsBitbucketCloud() bool {
+	return false
+}
+
+// IsBitbucketServer returns false
+func (p *AzureDevOpsProvider) IsBitbucketServer() bool {
+	return false
+}
+
+// IsGerrit returns false
+func (p *AzureDevOpsProvider) IsGerrit() bool {
+	return false
+}
+
+// Kind returns the kind of the provider
+func (p *AzureDevOpsProvider) Kind() string {
+	return KindAzureDevOps
+}
+
+// GetIssue is not supported as Azure DevOps tracks work items in Azure Boards
+func (p *AzureDevOpsProvider) GetIssue(org string, name string, number int) (*GitIssue, error) {
+	log.Logger().Warn("Azure DevOps Repos does not support issue tracking")
+	return nil, nil
+}
+
+// IssueURL returns the URL of the pull request or of the Azure Boards work item
+func (p *AzureDevOpsProvider) IssueURL(org string, name string, number int, isPull bool) string {
+	if isPull {
+		return util.UrlJoin(p.webURL(org, name), "pullrequest", strconv.Itoa(number))
+	}
+	organization, project, err := p.splitOwner(org)
+	if err != nil {
+		return ""
+	}
+	return util.UrlJoin(p.baseURL, organization, project, "_workitems", "edit", strconv.Itoa(number))
+}
+
+// SearchIssues is not supported as Azure DevOps tracks work items in Azure Boards
+func (p *AzureDevOpsProvider) SearchIssues(org string, name string, state string) ([]*GitIssue, error) {
+	log.Logger().Warn("Azure DevOps Repos does not support issue tracking")
+	return nil, nil
+}
+
+// SearchIssuesClosedSince is not supported as Azure DevOps tracks work items in Azure Boards
+func (p *AzureDevOpsProvider) SearchIssuesClosedSince(org string, name string, t time.Time) ([]*GitIssue, error) {
+	log.Logger().Warn("Azure DevOps Repos does not support issue tracking")
+	return nil, nil
+}
+
+// CreateIssue is not supported as Azure DevOps tracks work items in Azure Boards
+func (p *AzureDevOpsProvider) CreateIssue(owner string, repo string, issue *GitIssue) (*GitIssue, error) {
+	log.Logger().Warn("Azure DevOps Repos does not support issue tracking")
+	return nil, nil
+}
+
+// HasIssues returns false as Azure DevOps tracks work items in Azure Boards
+func (p *AzureDevOpsProvider) HasIssues() bool {
+	return false
+}
+
+// AddPRComment adds the comment as a new thread of the pull request
+func (p *AzureDevOpsProvider) AddPRComment(pr *GitPullRequest, comment string) error {
+	if pr.Number == nil {
+		return fmt.Errorf("missing Number for GitPullRequest %#v", pr)
+	}
+	return p.CreateIssueComment(pr.Owner, pr.Repo, *pr.Number, comment)
+}
+
+// CreateIssueComment adds the comment as a new thread of the pull request
+func (p *AzureDevOpsProvider) CreateIssueComment(owner string, repo string, number int, comment string) error {
+	u, err := p.pullRequestURL(owner, repo, number, "threads")
+	if err != nil {
+		return err
+	}
+	body := map[string]interface{}{
+		"comments": []map[string]interface{}{
+			{
+				"parentCommentId": 0,
+				"content":         comment,
+				"commentType":     1,
+			},
+		},
+		"status": 1,
+	}
+	err = p.do(http.MethodPost, u, body, nil)
+	if err != nil {
+		return errors.Wrapf(err, "commenting on pull request %s/%s #%d", owner, repo, number)
+	}
+	return nil
+}
+
+// UpdateRelease is not supported as Azure DevOps Repos has no releases
+func (p *AzureDevOpsProvider) UpdateRelease(owner string, repo string, tag string, releaseInfo *GitRelease) error {
+	log.Logger().Debugf("Azure DevOps Repos does not support releases so not updating the release %s of %s/%s", tag, owner, repo)
+	return nil
+}
+
+// UpdateReleaseStatus is not supported as Azure DevOps Repos has no releases
+func (p *AzureDevOpsProvider) UpdateReleaseStatus(owner string, repo string, tag string, releaseInfo *GitRelease) error {
+	return nil
+}
+
+// ListReleases returns no releases as Azure DevOps Repos has no releases
+func (p *AzureDevOpsProvider) ListReleases(org string, name string) ([]*GitRelease, error) {
+	return []*GitRelease{}, nil
+}
+
+// GetRelease returns no release as Azure DevOps Repos has no releases
+func (p *AzureDevOpsProvider) GetRelease(org string, name string, tag string) (*GitRelease, error) {
+	return nil, nil
+}
+
+// UploadReleaseAsset is not supported as Azure DevOps Repos has no releases
+func (p *AzureDevOpsProvider) UploadReleaseAsset(org string, repo string, id int64, name string, asset *os.File) (*GitReleaseAsset, error) {
+	return nil, errors.Errorf("uploading release assets is not supported for Azure DevOps")
+}
+
+// GetLatestRelease returns no release as Azure DevOps Repos has no releases
+func (p *AzureDevOpsProvider) GetLatestRelease(org string, name string) (*GitRelease, error) {
+	return nil, nil
+}
+
+// GetContent returns the content of the file at the ref
+func (p *AzureDevOpsProvider) GetContent(org string, name string, path string, ref string) (*GitFileContent, error) {
+	u, err := p.repoURL(org, name, "items")
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	params.Set("path", path)
+	params.Set("includeContent", "true")
+	if ref != "" {
+		params.Set("versionDescriptor.version", ref)
+	}
+	item := struct {
+		ObjectID string `json:"objectId"`
+		Path     string `json:"path"`
+		Content  string `json:"content"`
+		URL      string `json:"url"`
+	}{}
+	err = p.do(http.MethodGet, u+"?"+params.Encode(), nil, &item)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting %s of %s/%s", path, org, name)
+	}
+	parts := strings.Split(item.Path, "/")
+	return &GitFileContent{
+		Type:    "file",
+		Size:    len(item.Content),
+		Name:    parts[len(parts)-1],
+		Path:    item.Path,
+		Content: item.Content,
+		Sha:     item.ObjectID,
+		Url:     item.URL,
+	}, nil
+}
+
+// JenkinsWebHookPath returns the path of the webhook endpoint of Jenkins
+func (p *AzureDevOpsProvider) JenkinsWebHookPath(gitURL string, secret string) string {
+	return "/generic-webhook-trigger/invoke"
+}
+
+// Label returns the label of the server
+func (p *AzureDevOpsProvider) Label() string {
+	return p.Server.Label()
+}
+
+// ServerURL returns the URL of the server
+func (p *AzureDevOpsProvider) ServerURL() string {
+	return p.Server.URL
+}
+
+// BranchArchiveURL returns the URL to download the branch as a zip file
+func (p *AzureDevOpsProvider) BranchArchiveURL(org string, name string, branch string) string {
+	u, err := p.repoURL(org, name, "items")
+	if err != nil {
+		return ""
+	}
+	params := url.Values{}
+	params.Set("path", "/")
+	params.Set("versionDescriptor.version", branch)
+	params.Set("$format", "zip")
+	params.Set("download", "true")
+	return u + "?" + params.Encode()
+}
+
+// CurrentUsername returns the username of the user
+func (p *AzureDevOpsProvider) CurrentUsername() string {
+	return p.Username
+}
+
+// UserAuth returns the authentication of the user
+func (p *AzureDevOpsProvider) UserAuth() auth.UserAuth {
+	return p.User
+}
+
+// UserInfo returns the user
+func (p *AzureDevOpsProvider) UserInfo(username string) *GitUser {
+	return &GitUser{
+		Login: username,
+	}
+}
+
+// AddCollaborator is not supported as Azure DevOps manages access with project teams
+func (p *AzureDevOpsProvider) AddCollaborator(user string, organisation string, repo string) error {
+	log.Logger().Infof("Automatically adding the pipeline user as a collaborator is not supported for Azure DevOps. Please add user: %v to the project %s.", user, organisation)
+	return nil
+}
+
+// ListInvitations is not supported for Azure DevOps
+func (p *AzureDevOpsProvider) ListInvitations() ([]*github.RepositoryInvitation, *github.Response, error) {
+	return []*github.RepositoryInvitation{}, &github.Response{}, nil
+}
+
+// AcceptInvitation is not supported for Azure DevOps
+func (p *AzureDevOpsProvider) AcceptInvitation(ID int64) (*github.Response, error) {
+	return &github.Response{}, nil
+}
+
+// ShouldForkForPullRequest returns false as pull requests are created from branches of the repository
+func (p *AzureDevOpsProvider) ShouldForkForPullRequest(originalOwner string, repoName string, username string) bool {
+	return false
+}
+
+// GetBranch returns the branch and the commit at its tip
+func (p *AzureDevOpsProvider) GetBranch(owner string, repo string, branch string) (*GitBranch, error) {
+	u, err := p.repoURL(owner, repo, "refs")
+	if err != nil {
+		return nil, err
+	}
+	results := struct {
+		Value []struct {
+			Name     string `json:"name"`
+			ObjectID string `json:"objectId"`
+		} `json:"value"`
+	}{}
+	err = p.do(http.MethodGet, u+"?filter="+url.QueryEscape("heads/"+branch), nil, &results)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting branch %s of %s/%s", branch, owner, repo)
+	}
+	for _, ref := range results.Value {
+		if ref.Name == azureDevOpsBranchRef(branch) {
+			return &GitBranch{
+				Name: branch,
+				Commit: &GitCommit{
+					SHA:    ref.ObjectID,
+					Branch: branch,
+				},
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetProjects returns no projects as Azure DevOps tracks work in Azure Boards
+func (p *AzureDevOpsProvider) GetProjects(owner string, repo string) ([]GitProject, error) {
+	return nil, nil
+}
+
+// IsWikiEnabled returns false as wikis belong to projects rather than repositories
+func (p *AzureDevOpsProvider) IsWikiEnabled(owner string, repo string) (bool, error) {
+	return false, nil
+}
+
+// ConfigureFeatures is not supported for Azure DevOps
+func (p *AzureDevOpsProvider) ConfigureFeatures(owner string, repo string, issues *bool, projects *bool, wikis *bool) (*GitRepository, error) {
+	return p.GetRepository(owner, repo)
+}
+
+// azureDevOpsOwnerAndName returns the organization/project owner and the name of the repository of an Azure DevOps
+// URL path such as /myorg/myproject/_git/myrepo or false if the path is not a repository path
+func azureDevOpsOwnerAndName(host string, arr []string) (string, string, bool) {
+	i := util.StringArrayIndex(arr, "_git")
+	if i < 1 || i+1 >= len(arr) {
+		return "", "", false
+	}
+	project := arr[i-1]
+	org := ""
+	if strings.HasSuffix(host, azureDevOpsLegacyHost) {
+		org = strings.TrimSuffix(host, azureDevOpsLegacyHost)
+	} else if i >= 2 {
+		org = arr[i-2]
+	}
+	owner := project
+	if org != "" {
+		owner = org + "/" + project
+	}
+	return owner, arr[i+1], true
+}
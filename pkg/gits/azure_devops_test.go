@@ -0,0 +1,155 @@
+// +build unit
+
+package gits_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const azureRepository = `{"id": "r1", "name": "myrepo", "remoteUrl": "https://dev.azure.com/myorg/myproject/_git/myrepo", "project": {"id": "p1", "name": "myproject"}}`
+
+func newFakeAzureDevOpsProvider(t *testing.T, responses map[string]string) (*fakeGitServer, *gits.AzureDevOpsProvider, func()) {
+	fake := &fakeGitServer{
+		t:         t,
+		responses: responses,
+		bodies:    map[string]map[string]interface{}{},
+	}
+	server := httptest.NewServer(fake)
+	provider, err := gits.NewAzureDevOpsProvider(&auth.AuthServer{URL: server.URL, Kind: gits.KindAzureDevOps}, &auth.UserAuth{Username: "myuser", ApiToken: "test"}, nil)
+	require.NoError(t, err)
+	return fake, provider.(*gits.AzureDevOpsProvider), server.Close
+}
+
+func TestAzureDevOpsCreateProvider(t *testing.T) {
+	t.Parallel()
+	provider, err := gits.CreateProvider(&auth.AuthServer{URL: "https://dev.azure.com/myorg"}, &auth.UserAuth{Username: "myuser", ApiToken: "test"}, nil)
+	require.NoError(t, err)
+	require.IsType(t, &gits.AzureDevOpsProvider{}, provider)
+	assert.Equal(t, gits.KindAzureDevOps, provider.Kind())
+	assert.Equal(t, "myorg", provider.(*gits.AzureDevOpsProvider).Organization)
+	assert.Equal(t, "https://dev.azure.com/myorg/myproject/_git/myrepo/pullrequest/2", provider.IssueURL("myproject", "myrepo", 2, true))
+	assert.Equal(t, "https://dev.azure.com/myorg/myproject/_workitems/edit/2", provider.IssueURL("myorg/myproject", "myrepo", 2, false))
+}
+
+func TestAzureDevOpsCreateRepository(t *testing.T) {
+	t.Parallel()
+	fake, provider, closer := newFakeAzureDevOpsProvider(t, map[string]string{
+		"GET /myorg/_apis/projects/myproject":                `{"id": "p1", "name": "myproject"}`,
+		"POST /myorg/myproject/_apis/git/repositories":       azureRepository,
+		"GET /myorg/myproject/_apis/git/repositories/myrepo": azureRepository,
+	})
+	defer closer()
+
+	repo, err := provider.CreateRepository("myorg/myproject", "myrepo", true)
+	require.NoError(t, err)
+	assert.Equal(t, "myrepo", repo.Name)
+	assert.Equal(t, "myorg/myproject", repo.Organisation)
+	assert.Equal(t, "https://dev.azure.com/myorg/myproject/_git/myrepo", repo.CloneURL)
+	assert.Equal(t, map[string]interface{}{"id": "p1"}, fake.bodies["POST /myorg/myproject/_apis/git/repositories"]["project"])
+
+	assert.Error(t, provider.ValidateRepositoryName("myorg/myproject", "myrepo"))
+	assert.NoError(t, provider.ValidateRepositoryName("myorg/myproject", "missing"))
+}
+
+func TestAzureDevOpsPullRequests(t *testing.T) {
+	t.Parallel()
+	fake, provider, closer := newFakeAzureDevOpsProvider(t, map[string]string{
+		"POST /myorg/myproject/_apis/git/repositories/myrepo/pullrequests": `{"pullRequestId": 3, "status": "active", "title": "my change", "sourceRefName": "refs/heads/feature"}`,
+		"GET /myorg/myproject/_apis/git/repositories/myrepo/pullrequests/3": `{"pullRequestId": 3, "status": "completed", "title": "my change", "sourceRefName": "refs/heads/feature",
+			"lastMergeSourceCommit": {"commitId": "abc123"}, "lastMergeCommit": {"commitId": "def456"}, "labels": [{"name": "approved"}],
+			"reviewers": [{"uniqueName": "bob@example.com", "vote": 0, "isRequired": true}, {"uniqueName": "alice@example.com", "vote": 10, "isRequired": true}]}`,
+		"PATCH /myorg/myproject/_apis/git/repositories/myrepo/pullrequests/3":        `{}`,
+		"POST /myorg/myproject/_apis/git/repositories/myrepo/pullrequests/3/threads": `{}`,
+	})
+	defer closer()
+
+	pr, err := provider.CreatePullRequest(&gits.GitPullRequestArguments{
+		GitRepository: &gits.GitRepository{Organisation: "myorg/myproject", Name: "myrepo"},
+		Title:         "my change",
+		Head:          "feature",
+		Base:          "master",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, *pr.Number)
+	assert.Equal(t, "open", *pr.State)
+	assert.Equal(t, "feature", *pr.HeadRef)
+	assert.Equal(t, "refs/heads/master", fake.bodies["POST /myorg/myproject/_apis/git/repositories/myrepo/pullrequests"]["targetRefName"])
+
+	err = provider.UpdatePullRequestStatus(pr)
+	require.NoError(t, err)
+	assert.True(t, *pr.Merged)
+	assert.Equal(t, "closed", *pr.State)
+	assert.Equal(t, "abc123", pr.LastCommitSha)
+	assert.Equal(t, "def456", *pr.MergeCommitSHA)
+	require.Len(t, pr.Labels, 1)
+	assert.Equal(t, "approved", *pr.Labels[0].Name)
+	require.Len(t, pr.RequestedReviewers, 1)
+	assert.Equal(t, "bob@example.com", pr.RequestedReviewers[0].Login)
+
+	err = provider.MergePullRequest(pr, "merged by jx")
+	require.NoError(t, err)
+	body := fake.bodies["PATCH /myorg/myproject/_apis/git/repositories/myrepo/pullrequests/3"]
+	assert.Equal(t, "completed", body["status"])
+	assert.Equal(t, map[string]interface{}{"commitId": "abc123"}, body["lastMergeSourceCommit"])
+
+	err = provider.AddPRComment(pr, "/lgtm")
+	require.NoError(t, err)
+	assert.Contains(t, fake.requests, "POST /myorg/myproject/_apis/git/repositories/myrepo/pullrequests/3/threads")
+}
+
+func TestAzureDevOpsCommitStatus(t *testing.T) {
+	t.Parallel()
+	fake, provider, closer := newFakeAzureDevOpsProvider(t, map[string]string{
+		"POST /myorg/myproject/_apis/git/repositories/myrepo/commits/abc123/statuses": `{"id": 2, "state": "succeeded", "context": {"name": "pr-build"}}`,
+		"GET /myorg/myproject/_apis/git/repositories/myrepo/commits/abc123/statuses":  `{"value": [{"id": 2, "state": "failed", "context": {"name": "pr-build"}}]}`,
+	})
+	defer closer()
+
+	status, err := provider.UpdateCommitStatus("myorg/myproject", "myrepo", "abc123", &gits.GitRepoStatus{
+		State:   "success",
+		Context: "pr-build",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "success", status.State)
+	assert.Equal(t, "succeeded", fake.bodies["POST /myorg/myproject/_apis/git/repositories/myrepo/commits/abc123/statuses"]["state"])
+
+	state, err := provider.PullRequestLastCommitStatus(&gits.GitPullRequest{Owner: "myorg/myproject", Repo: "myrepo", LastCommitSha: "abc123"})
+	require.NoError(t, err)
+	assert.Equal(t, "failure", state)
+}
+
+func TestAzureDevOpsWebHooks(t *testing.T) {
+	t.Parallel()
+	fake, provider, closer := newFakeAzureDevOpsProvider(t, map[string]string{
+		"GET /myorg/myproject/_apis/git/repositories/myrepo": azureRepository,
+		"GET /myorg/_apis/hooks/subscriptions": `{"value": [
+			{"id": "s1", "eventType": "git.push", "consumerId": "webHooks", "publisherInputs": {"repository": "r1"}, "consumerInputs": {"url": "http://hook.jx.example.com/hook"}},
+			{"id": "s2", "eventType": "git.push", "consumerId": "webHooks", "publisherInputs": {"repository": "other"}, "consumerInputs": {"url": "http://other.example.com/hook"}}]}`,
+		"POST /myorg/_apis/hooks/subscriptions":   `{}`,
+		"PUT /myorg/_apis/hooks/subscriptions/s1": `{}`,
+	})
+	defer closer()
+
+	hooks, err := provider.ListWebHooks("myorg/myproject", "myrepo")
+	require.NoError(t, err)
+	require.Len(t, hooks, 1)
+	assert.Equal(t, "http://hook.jx.example.com/hook", hooks[0].URL)
+
+	repo := &gits.GitRepository{Name: "myrepo"}
+	err = provider.CreateWebHook(&gits.GitWebHookArguments{Owner: "myorg/myproject", Repo: repo, URL: "http://hook.jx.example.com/hook", Secret: "s3cr3t"})
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", fake.bodies["PUT /myorg/_apis/hooks/subscriptions/s1"]["consumerInputs"].(map[string]interface{})["basicAuthPassword"])
+	body := fake.bodies["POST /myorg/_apis/hooks/subscriptions"]
+	assert.Equal(t, "ms.vss-code.git-pullrequest-comment-event", body["eventType"])
+	assert.Equal(t, map[string]interface{}{"projectId": "p1", "repository": "r1"}, body["publisherInputs"])
+
+	err = provider.UpdateWebHook(&gits.GitWebHookArguments{Owner: "myorg/myproject", Repo: repo, URL: "http://hook.jx.example.com/new", ExistingURL: "http://hook.jx.example.com/hook"})
+	require.NoError(t, err)
+	assert.Equal(t, "http://hook.jx.example.com/new", fake.bodies["PUT /myorg/_apis/hooks/subscriptions/s1"]["consumerInputs"].(map[string]interface{})["url"])
+}
@@ -55,6 +55,7 @@ func NewBitbucketCloudProvider(server *auth.AuthServer, user *auth.UserAuth, git
 	}
 
 	cfg := bitbucket.NewConfiguration()
+	cfg.HTTPClient = NewAPIHTTPClient()
 	provider.Client = bitbucket.NewAPIClient(cfg)
 
 	return &provider, nil
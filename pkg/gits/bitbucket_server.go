@@ -120,6 +120,7 @@ func NewBitbucketServerProvider(server *auth.AuthServer, user *auth.UserAuth, gi
 	}
 
 	cfg := bitbucket.NewConfiguration(server.URL + "/rest")
+	cfg.HTTPClient = NewAPIHTTPClient()
 	provider.Client = bitbucket.NewAPIClient(apiKeyAuthContext, cfg)
 
 	return &provider, nil
@@ -0,0 +1,114 @@
+package gits
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/pkg/errors"
+)
+
+// BranchProtection the protection of a branch such as the status checks and reviews required to merge a Pull Request
+type BranchProtection struct {
+	// RequiredChecks the status check contexts which have to pass before a Pull Request can be merged
+	RequiredChecks []string `json:"requiredChecks,omitempty"`
+	// StrictChecks requires branches to be up to date with the protected branch before they can be merged
+	StrictChecks bool `json:"strictChecks,omitempty"`
+	// RequiredReviews the number of approving reviews a Pull Request needs before it can be merged
+	RequiredReviews int `json:"requiredReviews,omitempty"`
+	// DismissStaleReviews dismisses the approving reviews of a Pull Request when new commits are pushed
+	DismissStaleReviews bool `json:"dismissStaleReviews,omitempty"`
+	// RequireCodeOwnerReviews requires the approval of the code owners of the changed files
+	RequireCodeOwnerReviews bool `json:"requireCodeOwnerReviews,omitempty"`
+	// EnforceAdmins applies the protection to the administrators of the repository too
+	EnforceAdmins bool `json:"enforceAdmins,omitempty"`
+}
+
+// Validate validates the branch protection
+func (p *BranchProtection) Validate() error {
+	if p.RequiredReviews < 0 || p.RequiredReviews > 6 {
+		return fmt.Errorf("the number of required reviews must be between 0 and 6 but was %d", p.RequiredReviews)
+	}
+	if p.DismissStaleReviews && p.RequiredReviews == 0 {
+		return fmt.Errorf("stale reviews can only be dismissed if reviews are required")
+	}
+	if p.RequireCodeOwnerReviews && p.RequiredReviews == 0 {
+		return fmt.Errorf("code owner reviews can only be required if reviews are required")
+	}
+	return nil
+}
+
+// Description returns a human readable description of the branch protection
+func (p *BranchProtection) Description() string {
+	parts := []string{}
+	if len(p.RequiredChecks) > 0 {
+		text := "required checks " + strings.Join(p.RequiredChecks, ", ")
+		if p.StrictChecks {
+			text += " on up to date branches"
+		}
+		parts = append(parts, text)
+	}
+	if p.RequiredReviews > 0 {
+		text := fmt.Sprintf("%d required reviews", p.RequiredReviews)
+		if p.RequireCodeOwnerReviews {
+			text += " including the code owners"
+		}
+		if p.DismissStaleReviews {
+			text += " dismissed on new commits"
+		}
+		parts = append(parts, text)
+	}
+	if p.EnforceAdmins {
+		parts = append(parts, "enforced for administrators")
+	}
+	if len(parts) == 0 {
+		return "protected"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// BranchProtector is implemented by git providers which can protect the branches of repositories
+type BranchProtector interface {
+	// ProtectBranch creates or replaces the protection of the branch of the repository
+	ProtectBranch(owner string, repo string, branch string, protection *BranchProtection) error
+}
+
+// ProtectBranch protects the branch of the repository if the git provider supports it, otherwise a warning is logged
+func ProtectBranch(provider GitProvider, owner string, repo string, branch string, protection *BranchProtection) error {
+	protector, ok := provider.(BranchProtector)
+	if !ok {
+		log.Logger().Warnf("cannot protect the branch %s of %s/%s as the %s git provider does not support branch protection", branch, owner, repo, provider.Kind())
+		return nil
+	}
+	err := protection.Validate()
+	if err != nil {
+		return err
+	}
+	return protector.ProtectBranch(owner, repo, branch, protection)
+}
+
+// ProtectBranch creates or replaces the protection of the branch of the repository
+func (p *GitHubProvider) ProtectBranch(owner string, repo string, branch string, protection *BranchProtection) error {
+	request := &github.ProtectionRequest{
+		EnforceAdmins: protection.EnforceAdmins,
+	}
+	if len(protection.RequiredChecks) > 0 || protection.StrictChecks {
+		request.RequiredStatusChecks = &github.RequiredStatusChecks{
+			Strict:   protection.StrictChecks,
+			Contexts: append([]string{}, protection.RequiredChecks...),
+		}
+	}
+	if protection.RequiredReviews > 0 {
+		request.RequiredPullRequestReviews = &github.PullRequestReviewsEnforcementRequest{
+			DismissStaleReviews:          protection.DismissStaleReviews,
+			RequireCodeOwnerReviews:      protection.RequireCodeOwnerReviews,
+			RequiredApprovingReviewCount: protection.RequiredReviews,
+		}
+	}
+	_, _, err := p.Client.Repositories.UpdateBranchProtection(p.Context, owner, repo, branch, request)
+	if err != nil {
+		return errors.Wrapf(err, "protecting the branch %s of %s/%s", branch, owner, repo)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// +build unit
+
+package gits_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBranchProtectionValidate(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, (&gits.BranchProtection{}).Validate())
+	assert.NoError(t, (&gits.BranchProtection{RequiredReviews: 2, DismissStaleReviews: true}).Validate())
+	assert.Error(t, (&gits.BranchProtection{RequiredReviews: 7}).Validate())
+	assert.Error(t, (&gits.BranchProtection{DismissStaleReviews: true}).Validate())
+	assert.Error(t, (&gits.BranchProtection{RequireCodeOwnerReviews: true}).Validate())
+
+	protection := &gits.BranchProtection{
+		RequiredChecks:      []string{"pr-build", "lint"},
+		StrictChecks:        true,
+		RequiredReviews:     1,
+		DismissStaleReviews: true,
+	}
+	assert.Equal(t, "required checks pr-build, lint on up to date branches; 1 required reviews dismissed on new commits", protection.Description())
+}
+
+func TestGitHubProtectBranch(t *testing.T) {
+	t.Parallel()
+
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/api/v3/repos/acme/app/branches/master/protection" {
+			http.NotFound(w, r)
+			return
+		}
+		data, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &body))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	provider, err := gits.NewGitHubProvider(&auth.AuthServer{URL: server.URL, Kind: gits.KindGitHub},
+		&auth.UserAuth{Username: "bot", ApiToken: "token"}, nil)
+	require.NoError(t, err)
+
+	err = gits.ProtectBranch(provider, "acme", "app", "master", &gits.BranchProtection{
+		RequiredChecks:      []string{"pr-build"},
+		RequiredReviews:     2,
+		DismissStaleReviews: true,
+		EnforceAdmins:       true,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, true, body["enforce_admins"])
+	assert.Equal(t, map[string]interface{}{"strict": false, "contexts": []interface{}{"pr-build"}}, body["required_status_checks"])
+	reviews, ok := body["required_pull_request_reviews"].(map[string]interface{})
+	require.True(t, ok, "the required reviews should be set")
+	assert.Equal(t, float64(2), reviews["required_approving_review_count"])
+	assert.Equal(t, true, reviews["dismiss_stale_reviews"])
+
+	err = gits.ProtectBranch(provider, "acme", "app", "master", &gits.BranchProtection{RequiredReviews: 10})
+	assert.Error(t, err, "invalid protections are not applied")
+}
+
+func TestFakeProviderProtectBranch(t *testing.T) {
+	t.Parallel()
+
+	provider := &gits.FakeProvider{}
+	protection := &gits.BranchProtection{RequiredChecks: []string{"pr-build"}}
+	require.NoError(t, gits.ProtectBranch(provider, "acme", "app", "master", protection))
+	assert.Equal(t, protection, provider.BranchProtections["acme/app/master"])
+}
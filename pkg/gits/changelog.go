@@ -0,0 +1,162 @@
+package gits
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"text/template"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// Changelog the changes of a release grouped by their conventional commit type which is passed to the templates of
+// release notes
+type Changelog struct {
+	Version string
+	// Groups the commits grouped by their type in the order of the conventional commit types
+	Groups []*ChangelogGroup
+	// Breaking the commits with breaking changes
+	Breaking          []*ChangelogEntry
+	Issues            []v1.IssueSummary
+	PullRequests      []v1.IssueSummary
+	DependencyUpdates []v1.DependencyUpdate
+	Release           *v1.ReleaseSpec
+}
+
+// ChangelogGroup the commits of a conventional commit type
+type ChangelogGroup struct {
+	Kind    string
+	Title   string
+	Commits []*ChangelogEntry
+	order   int
+}
+
+// ChangelogEntry a commit of a changelog
+type ChangelogEntry struct {
+	Kind     string
+	Scope    string
+	Message  string
+	Breaking bool
+	SHA      string
+	URL      string
+	Author   *v1.UserDetails
+	Issues   []*v1.IssueSummary
+}
+
+// Scopes returns the commits of the group by their scope
+func (g *ChangelogGroup) Scopes() map[string][]*ChangelogEntry {
+	answer := map[string][]*ChangelogEntry{}
+	for _, c := range g.Commits {
+		answer[c.Scope] = append(answer[c.Scope], c)
+	}
+	return answer
+}
+
+// NewChangelog groups the commits of the release by their conventional commit type and scope
+func NewChangelog(releaseSpec *v1.ReleaseSpec) *Changelog {
+	answer := &Changelog{
+		Version:           releaseSpec.Version,
+		Issues:            releaseSpec.Issues,
+		PullRequests:      releaseSpec.PullRequests,
+		DependencyUpdates: releaseSpec.DependencyUpdates,
+		Release:           releaseSpec,
+	}
+	issueMap := map[string]*v1.IssueSummary{}
+	for _, issue := range append(append([]v1.IssueSummary{}, releaseSpec.Issues...), releaseSpec.PullRequests...) {
+		copy := issue
+		issueMap[copy.ID] = &copy
+	}
+	groups := map[string]*ChangelogGroup{}
+	for _, cs := range releaseSpec.Commits {
+		if cs.Message == "" {
+			continue
+		}
+		ci := ParseCommit(cs.Message)
+		entry := &ChangelogEntry{
+			Kind:     ci.Kind,
+			Scope:    ci.Feature,
+			Message:  strings.Split(strings.TrimSpace(ci.Message), "\n")[0],
+			Breaking: ci.Breaking,
+			SHA:      cs.SHA,
+			URL:      cs.URL,
+			Author:   cs.Author,
+		}
+		if entry.Author == nil {
+			entry.Author = cs.Committer
+		}
+		for _, id := range cs.IssueIDs {
+			if issue := issueMap[id]; issue != nil {
+				entry.Issues = append(entry.Issues, issue)
+			}
+		}
+		kind := strings.ToLower(ci.Kind)
+		group := groups[kind]
+		if group == nil {
+			commitGroup := ConventionalCommitTypeToTitle(kind)
+			group = &ChangelogGroup{
+				Kind:  kind,
+				Title: commitGroup.Title,
+				order: commitGroup.Order,
+			}
+			if group.Title == "" {
+				group.Title = "Other Changes"
+			}
+			groups[kind] = group
+			answer.Groups = append(answer.Groups, group)
+		}
+		group.Commits = append(group.Commits, entry)
+		if entry.Breaking {
+			answer.Breaking = append(answer.Breaking, entry)
+		}
+	}
+	sort.SliceStable(answer.Groups, func(i, j int) bool {
+		return answer.Groups[i].order < answer.Groups[j].order
+	})
+	for _, g := range answer.Groups {
+		commits := g.Commits
+		sort.SliceStable(commits, func(i, j int) bool {
+			return commits[i].Scope < commits[j].Scope
+		})
+	}
+	return answer
+}
+
+// RenderChangelog renders the changelog with the go template of the release notes
+func RenderChangelog(templateText string, changelog *Changelog) (string, error) {
+	tmpl, err := template.New("changelog").Parse(templateText)
+	if err != nil {
+		return "", errors.Wrap(err, "parsing the changelog template")
+	}
+	var buffer bytes.Buffer
+	err = tmpl.Execute(&buffer, changelog)
+	if err != nil {
+		return "", errors.Wrap(err, "rendering the changelog template")
+	}
+	return buffer.String(), nil
+}
+
+// IssueURL returns the URL of the issue of the repository on a git provider of the kind
+func IssueURL(info *GitRepository, kind string, id string) string {
+	switch kind {
+	case KindGitlab:
+		return util.UrlJoin(info.HttpsURL(), "-", "issues", id)
+	default:
+		// GitHub, Gitea and Bitbucket Cloud
+		return util.UrlJoin(info.HttpsURL(), "issues", id)
+	}
+}
+
+// CommitURL returns the URL of the commit of the repository on a git provider of the kind
+func CommitURL(info *GitRepository, kind string, sha string) string {
+	switch kind {
+	case KindGitlab:
+		return util.UrlJoin(info.HttpsURL(), "-", "commit", sha)
+	case KindBitBucketCloud:
+		return util.UrlJoin(info.HttpsURL(), "commits", sha)
+	default:
+		// GitHub and Gitea
+		return util.UrlJoin(info.HttpsURL(), "commit", sha)
+	}
+}
@@ -0,0 +1,81 @@
+// +build unit
+
+package gits_test
+
+import (
+	"testing"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewChangelog(t *testing.T) {
+	t.Parallel()
+	spec := &v1.ReleaseSpec{
+		Version: "1.2.3",
+		Commits: []v1.CommitSummary{
+			{SHA: "1", Message: "chore: tidy up"},
+			{SHA: "2", Message: "fix(ui): broken button"},
+			{SHA: "3", Message: "feat(cli)!: new flags", IssueIDs: []string{"#12"}},
+			{SHA: "4", Message: "feat(api): new endpoint"},
+			{SHA: "5", Message: "something regular"},
+		},
+		Issues: []v1.IssueSummary{
+			{ID: "#12", URL: "https://github.com/jenkins-x/jx/issues/12"},
+		},
+	}
+
+	changelog := gits.NewChangelog(spec)
+	titles := []string{}
+	for _, g := range changelog.Groups {
+		titles = append(titles, g.Title)
+	}
+	assert.Equal(t, []string{"New Features", "Bug Fixes", "Chores", "Other Changes"}, titles)
+
+	features := changelog.Groups[0].Commits
+	require.Len(t, features, 2)
+	assert.Equal(t, "api", features[0].Scope, "the commits should be sorted by scope")
+	assert.Equal(t, "new endpoint", features[0].Message)
+	assert.Equal(t, "cli", features[1].Scope)
+	require.Len(t, features[1].Issues, 1)
+	assert.Equal(t, "#12", features[1].Issues[0].ID)
+	assert.Len(t, changelog.Groups[0].Scopes(), 2)
+
+	require.Len(t, changelog.Breaking, 1)
+	assert.Equal(t, "3", changelog.Breaking[0].SHA)
+
+	text, err := gits.RenderChangelog(`# {{ .Version }}
+{{ range .Groups }}## {{ .Title }}
+{{ range .Commits }}* {{ if .Scope }}**{{ .Scope }}:** {{ end }}{{ .Message }}
+{{ end }}{{ end }}`, changelog)
+	require.NoError(t, err)
+	assert.Equal(t, `# 1.2.3
+## New Features
+* **api:** new endpoint
+* **cli:** new flags
+## Bug Fixes
+* **ui:** broken button
+## Chores
+* tidy up
+## Other Changes
+* something regular
+`, text)
+
+	_, err = gits.RenderChangelog("{{ .Unknown }}", changelog)
+	assert.Error(t, err)
+}
+
+func TestChangelogURLs(t *testing.T) {
+	t.Parallel()
+	info, err := gits.ParseGitURL("https://gitlab.com/myorg/myrepo.git")
+	require.NoError(t, err)
+	assert.Equal(t, "https://gitlab.com/myorg/myrepo/-/issues/12", gits.IssueURL(info, gits.KindGitlab, "12"))
+	assert.Equal(t, "https://gitlab.com/myorg/myrepo/-/commit/abc", gits.CommitURL(info, gits.KindGitlab, "abc"))
+
+	info, err = gits.ParseGitURL("https://github.com/myorg/myrepo.git")
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/myorg/myrepo/issues/12", gits.IssueURL(info, gits.KindGitHub, "12"))
+	assert.Equal(t, "https://github.com/myorg/myrepo/commit/abc", gits.CommitURL(info, gits.KindGitHub, "abc"))
+}
@@ -0,0 +1,340 @@
+package gits
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// EnvGitCacheDir environment variable with the directory of the git clone cache
+	EnvGitCacheDir = "JX_GIT_CACHE_DIR"
+	// EnvGitCacheEnabled environment variable to clone the repositories jx clones internally via the git clone cache
+	EnvGitCacheEnabled = "JX_GIT_CACHE_ENABLED"
+
+	// cacheMarkerFile the file in the .git directory of a cache entry with its git URL and ref. Its modification time
+	// is the last time the entry was used
+	cacheMarkerFile = "jx-clone-cache"
+	// cacheLockSuffix the suffix of the lock file next to a cache entry which is held while the entry is used
+	cacheLockSuffix = ".lock"
+	// cacheLockTimeout how long to wait for another process using a cache entry
+	cacheLockTimeout = 5 * time.Minute
+	// cacheLockStaleAge the age after which the lock of a cache entry is assumed to be left by a killed process
+	cacheLockStaleAge = 30 * time.Minute
+	// cacheLockPollInterval how often the lock of a cache entry is checked while waiting for it
+	cacheLockPollInterval = 200 * time.Millisecond
+)
+
+var cacheEntryNameRegex = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// CloneCache caches clones of the repositories jx clones internally such as the version stream, build packs and
+// environment repositories so that repeated clones only fetch the latest changes from the git server
+type CloneCache struct {
+	// Dir the directory the cached clones are kept in
+	Dir string
+
+	git *GitCLI
+}
+
+// CloneCacheEntry a cached clone of a git repository ref
+type CloneCacheEntry struct {
+	Dir      string
+	URL      string
+	Ref      string
+	Shallow  bool
+	LastUsed time.Time
+}
+
+// CloneCacheDir returns the directory of the git clone cache
+func CloneCacheDir() (string, error) {
+	dir := strings.TrimSpace(os.Getenv(EnvGitCacheDir))
+	if dir != "" {
+		return dir, nil
+	}
+	configDir, err := util.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "cache", "git"), nil
+}
+
+// NewCloneCache creates the clone cache for the git client if it is enabled via the JX_GIT_CACHE_ENABLED environment
+// variable. It returns nil if the cache is not enabled or the git client does not support it in which case
+// repositories should be cloned as usual
+func NewCloneCache(gitter Gitter) (*CloneCache, error) {
+	cli, ok := gitter.(*GitCLI)
+	if !ok || !envBool(EnvGitCacheEnabled) {
+		return nil, nil
+	}
+	dir, err := CloneCacheDir()
+	if err != nil {
+		return nil, errors.Wrap(err, "finding the git clone cache directory")
+	}
+	return &CloneCache{Dir: dir, git: cli}, nil
+}
+
+// Clone clones the ref of the git URL into the directory via the cache. The ref may be a branch, tag or commit SHA;
+// the default branch is used if it is empty. Shallow clones only contain the latest commit of the ref so should only
+// be used if the history and tags of the repository are not needed
+func (c *CloneCache) Clone(gitURL string, ref string, dir string, shallow bool) error {
+	entryDir := filepath.Join(c.Dir, cacheEntryName(gitURL, ref, shallow))
+	unlock, err := c.lock(entryDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	entry, err := c.checkout(entryDir, gitURL, ref, shallow)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(dir), util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "creating the parent directory of %s", dir)
+	}
+	// a local clone hard links the objects of the cache entry so does not use the network
+	err = c.git.gitCmd(filepath.Dir(dir), "clone", "--quiet", entry.Dir, dir)
+	if err != nil {
+		return errors.Wrapf(err, "cloning %s from the git clone cache into %s", util.SanitizeURL(gitURL), dir)
+	}
+	err = c.git.SetRemoteURL(dir, "origin", gitURL)
+	if err != nil {
+		return errors.Wrapf(err, "setting the origin of %s", dir)
+	}
+	return c.git.pullLFS(dir, "origin")
+}
+
+// Checkout returns the cache entry for the ref of the git URL cloning it if it is not cached yet or fetching the
+// latest changes if it is. An error is returned rather than a stale entry if the latest changes cannot be fetched
+func (c *CloneCache) Checkout(gitURL string, ref string, shallow bool) (*CloneCacheEntry, error) {
+	entryDir := filepath.Join(c.Dir, cacheEntryName(gitURL, ref, shallow))
+	unlock, err := c.lock(entryDir)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return c.checkout(entryDir, gitURL, ref, shallow)
+}
+
+// checkout returns the cache entry in the directory whose lock must be held
+func (c *CloneCache) checkout(entryDir string, gitURL string, ref string, shallow bool) (*CloneCacheEntry, error) {
+	entry := &CloneCacheEntry{
+		Dir:     entryDir,
+		URL:     util.SanitizeURL(gitURL),
+		Ref:     ref,
+		Shallow: shallow,
+	}
+	exists, err := util.DirExists(filepath.Join(entry.Dir, ".git"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "checking if %s exists", entry.Dir)
+	}
+	if exists {
+		err = c.fetch(entry, gitURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "updating the cached clone of %s", entry.URL)
+		}
+	} else {
+		err = c.create(entry, gitURL)
+		if err != nil {
+			os.RemoveAll(entry.Dir) //nolint:errcheck
+			return nil, err
+		}
+	}
+	entry.LastUsed = time.Now()
+	data := fmt.Sprintf("%s\n%s\n%t\n", entry.URL, entry.Ref, entry.Shallow)
+	err = ioutil.WriteFile(filepath.Join(entry.Dir, ".git", cacheMarkerFile), []byte(data), util.DefaultFileWritePermissions)
+	if err != nil {
+		return nil, errors.Wrapf(err, "marking %s as used", entry.Dir)
+	}
+	return entry, nil
+}
+
+// create clones the git URL into a new cache entry. Commit SHAs cannot be cloned directly so are fetched
+func (c *CloneCache) create(entry *CloneCacheEntry, gitURL string) error {
+	log.Logger().Debugf("caching a clone of %s ref %s in %s", entry.URL, entry.Ref, entry.Dir)
+	err := os.MkdirAll(c.Dir, util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "creating the git clone cache directory %s", c.Dir)
+	}
+	remoteURL := c.git.SSH.SSHURL(gitURL)
+	args := []string{"clone", "--quiet"}
+	if entry.Shallow {
+		args = append(args, "--depth=1")
+	}
+	if entry.Ref != "" {
+		args = append(args, "--branch", entry.Ref)
+	}
+	err = c.git.gitCmd(c.Dir, append(args, remoteURL, entry.Dir)...)
+	if err != nil && entry.Ref != "" {
+		// lets fetch the ref in case it is a commit SHA
+		os.RemoveAll(entry.Dir) //nolint:errcheck
+		err = os.MkdirAll(entry.Dir, util.DefaultWritePermissions)
+		if err == nil {
+			err = c.git.Init(entry.Dir)
+		}
+		if err == nil {
+			err = c.git.SetRemoteURL(entry.Dir, "origin", remoteURL)
+		}
+		if err == nil {
+			err = c.fetch(entry, gitURL)
+		}
+	}
+	if err != nil {
+		return errors.Wrapf(err, "cloning %s ref %s into the git clone cache", entry.URL, entry.Ref)
+	}
+	// lets not keep any credentials in the cache
+	return c.git.SetRemoteURL(entry.Dir, "origin", entry.URL)
+}
+
+// fetch updates the cache entry to the latest commit of its ref
+func (c *CloneCache) fetch(entry *CloneCacheEntry, gitURL string) error {
+	ref := entry.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	args := []string{"fetch", "--quiet"}
+	if entry.Shallow {
+		args = append(args, "--depth=1")
+	} else {
+		args = append(args, "--tags")
+	}
+	err := c.git.gitCmd(entry.Dir, append(args, c.git.SSH.SSHURL(gitURL), ref)...)
+	if err != nil {
+		return errors.Wrapf(err, "fetching %s ref %s", entry.URL, ref)
+	}
+	err = c.git.gitCmd(entry.Dir, "reset", "--quiet", "--hard", "FETCH_HEAD")
+	if err != nil {
+		return errors.Wrapf(err, "resetting %s to ref %s", entry.Dir, ref)
+	}
+	return nil
+}
+
+// lock waits until no other process uses the cache entry in the directory then marks it as used by this process
+// returning the function to release it. The lock is a file created exclusively next to the entry so that it works
+// on all platforms
+func (c *CloneCache) lock(entryDir string) (func(), error) {
+	err := os.MkdirAll(c.Dir, util.DefaultWritePermissions)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating the git clone cache directory %s", c.Dir)
+	}
+	fileName := entryDir + cacheLockSuffix
+	deadline := time.Now().Add(cacheLockTimeout)
+	for {
+		f, err := os.OpenFile(fileName, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid()) //nolint:errcheck
+			f.Close()                           //nolint:errcheck
+			return func() {
+				os.Remove(fileName) //nolint:errcheck
+			}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, errors.Wrapf(err, "creating the lock file %s", fileName)
+		}
+		if info, err := os.Stat(fileName); err == nil && time.Since(info.ModTime()) > cacheLockStaleAge {
+			log.Logger().Debugf("removing the stale lock file %s", fileName)
+			os.Remove(fileName) //nolint:errcheck
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.Errorf("timed out waiting for another process to release the lock file %s", fileName)
+		}
+		time.Sleep(cacheLockPollInterval)
+	}
+}
+
+// Entries returns the cached clones sorted by the time they were last used
+func (c *CloneCache) Entries() ([]*CloneCacheEntry, error) {
+	answer := []*CloneCacheEntry{}
+	exists, err := util.DirExists(c.Dir)
+	if err != nil || !exists {
+		return answer, err
+	}
+	files, err := ioutil.ReadDir(c.Dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading the git clone cache directory %s", c.Dir)
+	}
+	for _, f := range files {
+		if !f.IsDir() {
+			continue
+		}
+		dir := filepath.Join(c.Dir, f.Name())
+		fileName := filepath.Join(dir, ".git", cacheMarkerFile)
+		info, err := os.Stat(fileName)
+		if err != nil {
+			// the entry was not cloned successfully
+			answer = append(answer, &CloneCacheEntry{Dir: dir, LastUsed: f.ModTime()})
+			continue
+		}
+		data, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading %s", fileName)
+		}
+		lines := strings.Split(string(data), "\n")
+		entry := &CloneCacheEntry{Dir: dir, URL: lines[0], LastUsed: info.ModTime()}
+		if len(lines) > 2 {
+			entry.Ref = lines[1]
+			entry.Shallow = lines[2] == "true"
+		}
+		answer = append(answer, entry)
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].LastUsed.Before(answer[j].LastUsed)
+	})
+	return answer, nil
+}
+
+// GC removes the cached clones which have not been used for longer than the maximum age returning the removed entries
+func (c *CloneCache) GC(maxAge time.Duration, dryRun bool) ([]*CloneCacheEntry, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return nil, err
+	}
+	answer := []*CloneCacheEntry{}
+	cutoff := time.Now().Add(-maxAge)
+	for _, entry := range entries {
+		if entry.LastUsed.After(cutoff) {
+			continue
+		}
+		if dryRun {
+			answer = append(answer, entry)
+			continue
+		}
+		lockFile := entry.Dir + cacheLockSuffix
+		if exists, _ := util.FileExists(lockFile); exists {
+			// the entry is being used by another process
+			continue
+		}
+		answer = append(answer, entry)
+		err = os.RemoveAll(entry.Dir)
+		if err != nil {
+			return answer, errors.Wrapf(err, "removing the cached clone %s", entry.Dir)
+		}
+	}
+	return answer, nil
+}
+
+// cacheEntryName returns a readable directory name for the cache entry which is unique for the git URL and ref
+func cacheEntryName(gitURL string, ref string, shallow bool) string {
+	sanitized := strings.TrimSuffix(util.SanitizeURL(gitURL), ".git")
+	name := sanitized
+	if u, err := url.Parse(sanitized); err == nil && u.Host != "" {
+		name = u.Host + u.Path
+	}
+	if ref != "" {
+		name += "@" + ref
+	}
+	name = strings.Trim(cacheEntryNameRegex.ReplaceAllString(name, "-"), "-")
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s#%s#%t", sanitized, ref, shallow)))
+	return fmt.Sprintf("%s-%x", name, hash[:4])
+}
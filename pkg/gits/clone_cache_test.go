@@ -0,0 +1,118 @@
+// +build unit
+
+package gits_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloneCache(t *testing.T) {
+	cacheDir, err := ioutil.TempDir("", "test-clone-cache-")
+	require.NoError(t, err)
+	defer os.RemoveAll(cacheDir) //nolint:errcheck
+	for k, v := range map[string]string{gits.EnvGitCacheDir: cacheDir, gits.EnvGitCacheEnabled: "true"} {
+		original, exists := os.LookupEnv(k)
+		require.NoError(t, os.Setenv(k, v))
+		if exists {
+			defer os.Setenv(k, original) //nolint:errcheck
+		} else {
+			defer os.Unsetenv(k) //nolint:errcheck
+		}
+	}
+
+	sourceDir, err := ioutil.TempDir("", "test-clone-cache-source-")
+	require.NoError(t, err)
+	defer os.RemoveAll(sourceDir) //nolint:errcheck
+
+	git := gits.NewGitCLI()
+	require.NoError(t, git.Init(sourceDir))
+	require.NoError(t, git.Config(sourceDir, "user.name", "test"))
+	require.NoError(t, git.Config(sourceDir, "user.email", "test@example.com"))
+	commit := func(version string) {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(sourceDir, "version"), []byte(version), util.DefaultFileWritePermissions))
+		require.NoError(t, git.Add(sourceDir, "."))
+		require.NoError(t, git.CommitDir(sourceDir, "version "+version))
+	}
+	commit("1")
+	require.NoError(t, git.CreateTag(sourceDir, "v1", "version 1"))
+	commit("2")
+	gitURL := "file://" + sourceDir
+
+	cache, err := gits.NewCloneCache(git)
+	require.NoError(t, err)
+	require.NotNil(t, cache)
+	assert.Equal(t, cacheDir, cache.Dir)
+
+	assertClone := func(ref string, shallow bool, expectedVersion string) string {
+		dir, err := ioutil.TempDir("", "test-clone-cache-clone-")
+		require.NoError(t, err)
+		require.NoError(t, cache.Clone(gitURL, ref, dir, shallow))
+		data, err := ioutil.ReadFile(filepath.Join(dir, "version"))
+		require.NoError(t, err)
+		assert.Equal(t, expectedVersion, string(data))
+		remoteURL, err := git.DiscoverUpstreamGitURL(filepath.Join(dir, ".git", "config"))
+		require.NoError(t, err)
+		assert.Equal(t, gitURL, remoteURL)
+		return dir
+	}
+
+	dir := assertClone("", true, "2")
+	defer os.RemoveAll(dir) //nolint:errcheck
+	assert.FileExists(t, filepath.Join(dir, ".git", "shallow"))
+
+	// the cached clone is updated with the latest changes
+	commit("3")
+	dir = assertClone("", true, "3")
+	defer os.RemoveAll(dir) //nolint:errcheck
+
+	// a stale clone is not used if the latest changes cannot be fetched
+	require.NoError(t, os.Rename(sourceDir, sourceDir+"-moved"))
+	err = cache.Clone(gitURL, "", sourceDir+"-stale-clone", true)
+	assert.Error(t, err)
+	require.NoError(t, os.Rename(sourceDir+"-moved", sourceDir))
+
+	dir = assertClone("v1", false, "1")
+	defer os.RemoveAll(dir) //nolint:errcheck
+	tags, err := git.FilterTags(dir, "v1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1"}, tags)
+
+	entries, err := cache.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	for _, entry := range entries {
+		assert.Equal(t, gitURL, entry.URL)
+		assert.True(t, strings.HasPrefix(entry.Dir, cacheDir))
+	}
+
+	removed, err := cache.GC(time.Hour, false)
+	require.NoError(t, err)
+	assert.Empty(t, removed)
+
+	removed, err = cache.GC(0, true)
+	require.NoError(t, err)
+	assert.Len(t, removed, 2)
+	assert.DirExists(t, removed[0].Dir)
+
+	removed, err = cache.GC(0, false)
+	require.NoError(t, err)
+	assert.Len(t, removed, 2)
+	entries, err = cache.Entries()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	require.NoError(t, os.Setenv(gits.EnvGitCacheEnabled, "false"))
+	cache, err = gits.NewCloneCache(git)
+	require.NoError(t, err)
+	assert.Nil(t, cache)
+}
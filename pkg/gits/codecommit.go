@@ -0,0 +1,985 @@
+package gits
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatchevents"
+	"github.com/aws/aws-sdk-go/service/cloudwatchevents/cloudwatcheventsiface"
+	"github.com/aws/aws-sdk-go/service/codecommit"
+	"github.com/aws/aws-sdk-go/service/codecommit/codecommitiface"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sns/snsiface"
+	"github.com/google/go-github/v32/github"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	codeCommitHostPrefix = "git-codecommit."
+	codeCommitHostSuffix = ".amazonaws.com"
+	codeCommitGRCPrefix  = "codecommit::"
+
+	// codeCommitMaxCommits the maximum number of commits walked when listing the commits of a pull request
+	codeCommitMaxCommits = 250
+)
+
+// codeCommitEventTypes the EventBridge detail types of the CodeCommit events which are bridged to webhooks
+var codeCommitEventTypes = []string{
+	"CodeCommit Repository State Change",
+	"CodeCommit Pull Request State Change",
+	"CodeCommit Comment on Pull Request",
+}
+
+var codeCommitInvalidNameChars = regexp.MustCompile("[^a-zA-Z0-9_-]")
+
+// CodeCommitProvider implements GitProvider interface for AWS CodeCommit.
+//
+// The API is invoked with the IAM credentials of the default AWS credential chain while git uses the HTTPS Git
+// credentials of the user. CodeCommit repositories belong to an account and region so the owner of a repository is
+// the region of the server.
+//
+// CodeCommit cannot send webhooks so the events of a repository are bridged to them by an EventBridge rule which
+// publishes them to an SNS topic the webhook URL is subscribed to
+type CodeCommitProvider struct {
+	Username string
+	Region   string
+
+	Client        codecommitiface.CodeCommitAPI
+	Events        cloudwatcheventsiface.CloudWatchEventsAPI
+	Notifications snsiface.SNSAPI
+
+	Server auth.AuthServer
+	User   auth.UserAuth
+	Git    Gitter
+}
+
+// NewCodeCommitProvider creates a git provider for the CodeCommit server of a region such as
+// https://git-codecommit.us-east-1.amazonaws.com
+func NewCodeCommitProvider(server *auth.AuthServer, user *auth.UserAuth, git Gitter) (GitProvider, error) {
+	region := CodeCommitRegion(server.URL)
+	if region == "" {
+		return nil, errors.Errorf("could not find the AWS region of the CodeCommit server URL %s", server.URL)
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+		Config: aws.Config{
+			Region: aws.String(region),
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "creating the AWS session")
+	}
+	return &CodeCommitProvider{
+		Username:      user.Username,
+		Region:        region,
+		Client:        codecommit.New(sess),
+		Events:        cloudwatchevents.New(sess),
+		Notifications: sns.New(sess),
+		Server:        *server,
+		User:          *user,
+		Git:           git,
+	}, nil
+}
+
+// CodeCommitServerURL returns the URL of the CodeCommit server of the region
+func CodeCommitServerURL(region string) string {
+	return "https://" + codeCommitHostPrefix + region + codeCommitHostSuffix
+}
+
+// CodeCommitRegion returns the region of a CodeCommit URL or "" if the URL is not a CodeCommit URL
+func CodeCommitRegion(gitURL string) string {
+	if strings.HasPrefix(gitURL, codeCommitGRCPrefix) {
+		// git-remote-codecommit URLs are of the form codecommit::<region>://<repo>
+		return strings.Split(strings.TrimPrefix(gitURL, codeCommitGRCPrefix), ":")[0]
+	}
+	host := gitURL
+	u, err := url.Parse(gitURL)
+	if err == nil && u.Host != "" {
+		host = u.Host
+	}
+	if !strings.HasPrefix(host, codeCommitHostPrefix) || !strings.HasSuffix(host, codeCommitHostSuffix) {
+		return ""
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(host, codeCommitHostPrefix), codeCommitHostSuffix)
+}
+
+// IsCodeCommitServerURL returns true if the URL is a CodeCommit URL
+func IsCodeCommitServerURL(gitURL string) bool {
+	return CodeCommitRegion(gitURL) != ""
+}
+
+// CodeCommitAccessTokenURL returns the URL of the IAM console where the HTTPS Git credentials for CodeCommit
+// are generated
+func CodeCommitAccessTokenURL(url string) string {
+	return "https://console.aws.amazon.com/iam/home#/security_credentials"
+}
+
+func isCodeCommitNotFound(err error) bool {
+	e, ok := errors.Cause(err).(awserr.Error)
+	if !ok {
+		return false
+	}
+	switch e.Code() {
+	case codecommit.ErrCodeRepositoryDoesNotExistException, codecommit.ErrCodeBranchDoesNotExistException,
+		codecommit.ErrCodePullRequestDoesNotExistException, codecommit.ErrCodeFileDoesNotExistException:
+		return true
+	}
+	return false
+}
+
+// consoleURL returns the URL of a page of the repository in the AWS console
+func (p *CodeCommitProvider) consoleURL(repo string, paths ...string) string {
+	return util.UrlJoin(append([]string{"https://" + p.Region + ".console.aws.amazon.com", "codesuite", "codecommit", "repositories", repo}, paths...)...) + "?region=" + p.Region
+}
+
+func (p *CodeCommitProvider) toGitRepository(metadata *codecommit.RepositoryMetadata) *GitRepository {
+	return &GitRepository{
+		Name:             aws.StringValue(metadata.RepositoryName),
+		AllowMergeCommit: true,
+		HTMLURL:          p.consoleURL(aws.StringValue(metadata.RepositoryName), "browse"),
+		CloneURL:         aws.StringValue(metadata.CloneUrlHttp),
+		SSHURL:           aws.StringValue(metadata.CloneUrlSsh),
+		URL:              aws.StringValue(metadata.CloneUrlHttp),
+		Host:             codeCommitHostPrefix + p.Region + codeCommitHostSuffix,
+		Organisation:     p.Region,
+		Project:          p.Region,
+		Private:          true,
+	}
+}
+
+// ListOrganisations returns the region as the only owner of repositories
+func (p *CodeCommitProvider) ListOrganisations() ([]GitOrganisation, error) {
+	return []GitOrganisation{{Login: p.Region}}, nil
+}
+
+// ListRepositories lists the repositories of the region
+func (p *CodeCommitProvider) ListRepositories(org string) ([]*GitRepository, error) {
+	answer := []*GitRepository{}
+	names := []*string{}
+	err := p.Client.ListRepositoriesPages(&codecommit.ListRepositoriesInput{}, func(page *codecommit.ListRepositoriesOutput, lastPage bool) bool {
+		for _, r := range page.Repositories {
+			names = append(names, r.RepositoryName)
+		}
+		return true
+	})
+	if err != nil {
+		return answer, errors.Wrapf(err, "listing the CodeCommit repositories of %s", p.Region)
+	}
+	// repositories are described in batches of at most 25
+	for i := 0; i < len(names); i += 25 {
+		end := i + 25
+		if end > len(names) {
+			end = len(names)
+		}
+		output, err := p.Client.BatchGetRepositories(&codecommit.BatchGetRepositoriesInput{RepositoryNames: names[i:end]})
+		if err != nil {
+			return answer, errors.Wrapf(err, "describing the CodeCommit repositories of %s", p.Region)
+		}
+		for _, metadata := range output.Repositories {
+			answer = append(answer, p.toGitRepository(metadata))
+		}
+	}
+	return answer, nil
+}
+
+func (p *CodeCommitProvider) getRepository(name string) (*codecommit.RepositoryMetadata, error) {
+	output, err := p.Client.GetRepository(&codecommit.GetRepositoryInput{RepositoryName: aws.String(name)})
+	if err != nil {
+		return nil, err
+	}
+	return output.RepositoryMetadata, nil
+}
+
+// GetRepository gets the repository
+func (p *CodeCommitProvider) GetRepository(org string, name string) (*GitRepository, error) {
+	metadata, err := p.getRepository(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting CodeCommit repository %s", name)
+	}
+	return p.toGitRepository(metadata), nil
+}
+
+// CreateRepository creates a repository. CodeCommit repositories are always private
+func (p *CodeCommitProvider) CreateRepository(org string, name string, private bool) (*GitRepository, error) {
+	output, err := p.Client.CreateRepository(&codecommit.CreateRepositoryInput{
+		RepositoryName:        aws.String(name),
+		RepositoryDescription: aws.String("created by Jenkins X"),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating CodeCommit repository %s", name)
+	}
+	return p.toGitRepository(output.RepositoryMetadata), nil
+}
+
+// DeleteRepository deletes the repository
+func (p *CodeCommitProvider) DeleteRepository(org string, name string) error {
+	_, err := p.Client.DeleteRepository(&codecommit.DeleteRepositoryInput{RepositoryName: aws.String(name)})
+	if err != nil {
+		return errors.Wrapf(err, "deleting CodeCommit repository %s", name)
+	}
+	return nil
+}
+
+// ForkRepository is not supported for CodeCommit
+func (p *CodeCommitProvider) ForkRepository(originalOrg string, name string, destinationOrg string) (*GitRepository, error) {
+	return nil, errors.Errorf("forking repositories is not supported for CodeCommit")
+}
+
+// RenameRepository renames the repository
+func (p *CodeCommitProvider) RenameRepository(org string, name string, newName string) (*GitRepository, error) {
+	_, err := p.Client.UpdateRepositoryName(&codecommit.UpdateRepositoryNameInput{
+		OldName: aws.String(name),
+		NewName: aws.String(newName),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "renaming CodeCommit repository %s to %s", name, newName)
+	}
+	return p.GetRepository(org, newName)
+}
+
+// ValidateRepositoryName returns an error if the repository already exists
+func (p *CodeCommitProvider) ValidateRepositoryName(org string, name string) error {
+	_, err := p.getRepository(name)
+	if err == nil {
+		return fmt.Errorf("repository %s already exists", name)
+	}
+	if isCodeCommitNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// CreatePullRequest creates a pull request
+func (p *CodeCommitProvider) CreatePullRequest(data *GitPullRequestArguments) (*GitPullRequest, error) {
+	repo := data.GitRepository.Name
+	output, err := p.Client.CreatePullRequest(&codecommit.CreatePullRequestInput{
+		Title:       aws.String(data.Title),
+		Description: aws.String(data.Body),
+		Targets: []*codecommit.Target{
+			{
+				RepositoryName:       aws.String(repo),
+				SourceReference:      aws.String(data.Head),
+				DestinationReference: aws.String(data.Base),
+			},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating pull request on CodeCommit repository %s", repo)
+	}
+	return p.toPullRequest(repo, output.PullRequest)
+}
+
+// UpdatePullRequest updates the title and description of the pull request
+func (p *CodeCommitProvider) UpdatePullRequest(data *GitPullRequestArguments, number int) (*GitPullRequest, error) {
+	id := aws.String(strconv.Itoa(number))
+	_, err := p.Client.UpdatePullRequestTitle(&codecommit.UpdatePullRequestTitleInput{
+		PullRequestId: id,
+		Title:         aws.String(data.Title),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "updating the title of pull request #%d", number)
+	}
+	output, err := p.Client.UpdatePullRequestDescription(&codecommit.UpdatePullRequestDescriptionInput{
+		PullRequestId: id,
+		Description:   aws.String(data.Body),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "updating the description of pull request #%d", number)
+	}
+	return p.toPullRequest(data.GitRepository.Name, output.PullRequest)
+}
+
+func (p *CodeCommitProvider) toPullRequest(repo string, source *codecommit.PullRequest) (*GitPullRequest, error) {
+	number, err := strconv.Atoi(aws.StringValue(source.PullRequestId))
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the pull request ID %s", aws.StringValue(source.PullRequestId))
+	}
+	pr := &GitPullRequest{
+		URL:    p.consoleURL(repo, "pull-requests", strconv.Itoa(number), "details"),
+		Owner:  p.Region,
+		Repo:   repo,
+		Number: &number,
+	}
+	p.updatePullRequest(pr, source)
+	return pr, nil
+}
+
+// updatePullRequest updates the pr with the data from CodeCommit. A pull request which has approval rules
+// which are all satisfied gets the approved label as CodeCommit has no labels
+func (p *CodeCommitProvider) updatePullRequest(pr *GitPullRequest, source *codecommit.PullRequest) {
+	state := strings.ToLower(aws.StringValue(source.PullRequestStatus))
+	pr.State = &state
+	pr.Title = aws.StringValue(source.Title)
+	pr.Body = aws.StringValue(source.Description)
+	pr.Author = codeCommitUser(aws.StringValue(source.AuthorArn))
+	merged := false
+	if len(source.PullRequestTargets) > 0 {
+		target := source.PullRequestTargets[0]
+		headRef := strings.TrimPrefix(aws.StringValue(target.SourceReference), "refs/heads/")
+		pr.HeadRef = &headRef
+		pr.LastCommitSha = aws.StringValue(target.SourceCommit)
+		if target.MergeMetadata != nil && aws.BoolValue(target.MergeMetadata.IsMerged) {
+			merged = true
+			pr.MergeCommitSHA = target.MergeMetadata.MergeCommitId
+			pr.MergedAt = source.LastActivityDate
+		}
+		mergeable := !merged && state == "open"
+		pr.Mergeable = &mergeable
+	}
+	pr.Merged = &merged
+	if state == "closed" {
+		pr.ClosedAt = source.LastActivityDate
+	}
+	pr.Labels = nil
+	if len(source.ApprovalRules) > 0 {
+		output, err := p.Client.EvaluatePullRequestApprovalRules(&codecommit.EvaluatePullRequestApprovalRulesInput{
+			PullRequestId: source.PullRequestId,
+			RevisionId:    source.RevisionId,
+		})
+		if err == nil && output.Evaluation != nil && aws.BoolValue(output.Evaluation.Approved) {
+			pr.Labels = append(pr.Labels, &Label{Name: aws.String(ApprovedLabel)})
+		}
+	}
+}
+
+// codeCommitUser returns the user of an IAM ARN such as arn:aws:iam::123456789012:user/bob
+func codeCommitUser(arn string) *GitUser {
+	if arn == "" {
+		return nil
+	}
+	parts := strings.Split(arn, "/")
+	return &GitUser{
+		Login: parts[len(parts)-1],
+		Name:  parts[len(parts)-1],
+	}
+}
+
+func (p *CodeCommitProvider) getPullRequest(number int) (*codecommit.PullRequest, error) {
+	output, err := p.Client.GetPullRequest(&codecommit.GetPullRequestInput{PullRequestId: aws.String(strconv.Itoa(number))})
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting pull request #%d", number)
+	}
+	return output.PullRequest, nil
+}
+
+// UpdatePullRequestStatus updates the pull request with its current state
+func (p *CodeCommitProvider) UpdatePullRequestStatus(pr *GitPullRequest) error {
+	if pr.Number == nil {
+		return fmt.Errorf("missing Number for GitPullRequest %#v", pr)
+	}
+	source, err := p.getPullRequest(*pr.Number)
+	if err != nil {
+		return err
+	}
+	p.updatePullRequest(pr, source)
+	return nil
+}
+
+// AddLabelsToIssue is not supported as CodeCommit has no labels
+func (p *CodeCommitProvider) AddLabelsToIssue(owner, repo string, number int, labels []string) error {
+	log.Logger().Warnf("CodeCommit does not support labels so not adding %s to pull request #%d", strings.Join(labels, ", "), number)
+	return nil
+}
+
+// GetPullRequest gets the pull request
+func (p *CodeCommitProvider) GetPullRequest(owner string, repo *GitRepository, number int) (*GitPullRequest, error) {
+	source, err := p.getPullRequest(number)
+	if err != nil {
+		return nil, err
+	}
+	return p.toPullRequest(repo.Name, source)
+}
+
+// ListOpenPullRequests lists the open pull requests of the repository
+func (p *CodeCommitProvider) ListOpenPullRequests(owner string, repo string) ([]*GitPullRequest, error) {
+	answer := []*GitPullRequest{}
+	ids := []*string{}
+	input := &codecommit.ListPullRequestsInput{
+		RepositoryName:    aws.String(repo),
+		PullRequestStatus: aws.String(codecommit.PullRequestStatusEnumOpen),
+	}
+	err := p.Client.ListPullRequestsPages(input, func(page *codecommit.ListPullRequestsOutput, lastPage bool) bool {
+		ids = append(ids, page.PullRequestIds...)
+		return true
+	})
+	if err != nil {
+		return answer, errors.Wrapf(err, "listing the pull requests of CodeCommit repository %s", repo)
+	}
+	for _, id := range ids {
+		output, err := p.Client.GetPullRequest(&codecommit.GetPullRequestInput{PullRequestId: id})
+		if err != nil {
+			return answer, errors.Wrapf(err, "getting pull request #%s", aws.StringValue(id))
+		}
+		pr, err := p.toPullRequest(repo, output.PullRequest)
+		if err != nil {
+			return answer, err
+		}
+		answer = append(answer, pr)
+	}
+	return answer, nil
+}
+
+// walkCommits returns the first parent history of the commit until the stop commit, skipping the first skip
+// commits and returning at most max of them
+func (p *CodeCommitProvider) walkCommits(repo string, sha string, stop string, skip int, max int) ([]*GitCommit, error) {
+	answer := []*GitCommit{}
+	for i := 0; sha != "" && sha != stop && len(answer) < max; i++ {
+		output, err := p.Client.GetCommit(&codecommit.GetCommitInput{
+			RepositoryName: aws.String(repo),
+			CommitId:       aws.String(sha),
+		})
+		if err != nil {
+			return answer, errors.Wrapf(err, "getting commit %s of CodeCommit repository %s", sha, repo)
+		}
+		if i >= skip {
+			answer = append(answer, toCodeCommitCommit(output.Commit))
+		}
+		sha = ""
+		if len(output.Commit.Parents) > 0 {
+			sha = aws.StringValue(output.Commit.Parents[0])
+		}
+	}
+	return answer, nil
+}
+
+func toCodeCommitCommit(commit *codecommit.Commit) *GitCommit {
+	answer := &GitCommit{
+		SHA:     aws.StringValue(commit.CommitId),
+		Message: aws.StringValue(commit.Message),
+	}
+	if commit.Author != nil {
+		answer.Author = &GitUser{
+			Login: aws.StringValue(commit.Author.Email),
+			Name:  aws.StringValue(commit.Author.Name),
+			Email: aws.StringValue(commit.Author.Email),
+		}
+	}
+	if commit.Committer != nil {
+		answer.Committer = &GitUser{
+			Login: aws.StringValue(commit.Committer.Email),
+			Name:  aws.StringValue(commit.Committer.Name),
+			Email: aws.StringValue(commit.Committer.Email),
+		}
+	}
+	return answer
+}
+
+// GetPullRequestCommits returns the commits of the source branch since its merge base
+func (p *CodeCommitProvider) GetPullRequestCommits(owner string, repository *GitRepository, number int) ([]*GitCommit, error) {
+	source, err := p.getPullRequest(number)
+	if err != nil {
+		return nil, err
+	}
+	if len(source.PullRequestTargets) == 0 {
+		return []*GitCommit{}, nil
+	}
+	target := source.PullRequestTargets[0]
+	return p.walkCommits(repository.Name, aws.StringValue(target.SourceCommit), aws.StringValue(target.MergeBase), 0, codeCommitMaxCommits)
+}
+
+// PullRequestLastCommitStatus returns no status as CodeCommit has no commit statuses
+func (p *CodeCommitProvider) PullRequestLastCommitStatus(pr *GitPullRequest) (string, error) {
+	return "", nil
+}
+
+// ListCommitStatus returns no statuses as CodeCommit has no commit statuses
+func (p *CodeCommitProvider) ListCommitStatus(org string, repo string, sha string) ([]*GitRepoStatus, error) {
+	return []*GitRepoStatus{}, nil
+}
+
+// UpdateCommitStatus is not supported as CodeCommit has no commit statuses
+func (p *CodeCommitProvider) UpdateCommitStatus(org string, repo string, sha string, status *GitRepoStatus) (*GitRepoStatus, error) {
+	log.Logger().Debugf("CodeCommit does not support commit statuses so not updating the %s status of %s", status.Context, sha)
+	return status, nil
+}
+
+// ListCommits lists the first parent history of the SHA or of the default branch
+func (p *CodeCommitProvider) ListCommits(owner string, repo string, opt *ListCommitsArguments) ([]*GitCommit, error) {
+	sha := ""
+	skip := 0
+	max := 30
+	if opt != nil {
+		sha = opt.SHA
+		if opt.PerPage > 0 {
+			max = opt.PerPage
+		}
+		if opt.Page > 1 {
+			skip = (opt.Page - 1) * max
+		}
+	}
+	if sha == "" {
+		metadata, err := p.getRepository(repo)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting CodeCommit repository %s", repo)
+		}
+		sha = aws.StringValue(metadata.DefaultBranch)
+	}
+	branch, err := p.GetBranch(owner, repo, sha)
+	if err == nil && branch != nil {
+		sha = branch.Commit.SHA
+	}
+	return p.walkCommits(repo, sha, "", skip, max+skip)
+}
+
+// MergePullRequest merges the pull request with a three way merge
+func (p *CodeCommitProvider) MergePullRequest(pr *GitPullRequest, message string) error {
+	if pr.Number == nil {
+		return fmt.Errorf("missing Number for GitPullRequest %#v", pr)
+	}
+	input := &codecommit.MergePullRequestByThreeWayInput{
+		PullRequestId:  aws.String(strconv.Itoa(*pr.Number)),
+		RepositoryName: aws.String(pr.Repo),
+		CommitMessage:  aws.String(message),
+	}
+	if pr.LastCommitSha != "" {
+		input.SourceCommitId = aws.String(pr.LastCommitSha)
+	}
+	_, err := p.Client.MergePullRequestByThreeWay(input)
+	if err != nil {
+		return errors.Wrapf(err, "merging pull request #%d of CodeCommit repository %s", *pr.Number, pr.Repo)
+	}
+	return nil
+}
+
+// webHookBridgeName returns the name of the EventBridge rule and SNS topic which bridge the events of the
+// repository to webhooks
+func webHookBridgeName(repo string) string {
+	return "jx-codecommit-" + codeCommitInvalidNameChars.ReplaceAllString(repo, "-")
+}
+
+// webHookTopic creates, if it does not exist, the SNS topic of the repository and the EventBridge rule which
+// publishes the events of the repository to it
+func (p *CodeCommitProvider) webHookTopic(repo string) (string, error) {
+	metadata, err := p.getRepository(repo)
+	if err != nil {
+		return "", errors.Wrapf(err, "getting CodeCommit repository %s", repo)
+	}
+	name := webHookBridgeName(repo)
+	topic, err := p.Notifications.CreateTopic(&sns.CreateTopicInput{Name: aws.String(name)})
+	if err != nil {
+		return "", errors.Wrapf(err, "creating SNS topic %s", name)
+	}
+	topicArn := aws.StringValue(topic.TopicArn)
+	policy, err := json.Marshal(map[string]interface{}{
+		"Version": "2012-10-17",
+		"Statement": []map[string]interface{}{
+			{
+				"Effect":    "Allow",
+				"Principal": map[string]string{"Service": "events.amazonaws.com"},
+				"Action":    "sns:Publish",
+				"Resource":  topicArn,
+			},
+		},
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "marshalling the SNS topic policy")
+	}
+	_, err = p.Notifications.SetTopicAttributes(&sns.SetTopicAttributesInput{
+		TopicArn:       topic.TopicArn,
+		AttributeName:  aws.String("Policy"),
+		AttributeValue: aws.String(string(policy)),
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "allowing EventBridge to publish to SNS topic %s", name)
+	}
+	pattern, err := json.Marshal(map[string][]string{
+		"source":      {"aws.codecommit"},
+		"resources":   {aws.StringValue(metadata.Arn)},
+		"detail-type": codeCommitEventTypes,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "marshalling the EventBridge event pattern")
+	}
+	_, err = p.Events.PutRule(&cloudwatchevents.PutRuleInput{
+		Name:         aws.String(name),
+		Description:  aws.String(fmt.Sprintf("Sends the events of CodeCommit repository %s to Jenkins X", repo)),
+		EventPattern: aws.String(string(pattern)),
+		State:        aws.String(cloudwatchevents.RuleStateEnabled),
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "creating EventBridge rule %s", name)
+	}
+	_, err = p.Events.PutTargets(&cloudwatchevents.PutTargetsInput{
+		Rule: aws.String(name),
+		Targets: []*cloudwatchevents.Target{
+			{
+				Id:  aws.String(name),
+				Arn: topic.TopicArn,
+			},
+		},
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "adding SNS topic %s to EventBridge rule %s", name, name)
+	}
+	return topicArn, nil
+}
+
+func (p *CodeCommitProvider) listSubscriptions(topicArn string) ([]*sns.Subscription, error) {
+	answer := []*sns.Subscription{}
+	input := &sns.ListSubscriptionsByTopicInput{TopicArn: aws.String(topicArn)}
+	err := p.Notifications.ListSubscriptionsByTopicPages(input, func(page *sns.ListSubscriptionsByTopicOutput, lastPage bool) bool {
+		answer = append(answer, page.Subscriptions...)
+		return true
+	})
+	if err != nil {
+		return answer, errors.Wrapf(err, "listing the subscriptions of SNS topic %s", topicArn)
+	}
+	return answer, nil
+}
+
+func (p *CodeCommitProvider) subscribe(topicArn string, webHookURL string) error {
+	u, err := url.Parse(webHookURL)
+	if err != nil {
+		return errors.Wrapf(err, "parsing the webhook URL %s", webHookURL)
+	}
+	_, err = p.Notifications.Subscribe(&sns.SubscribeInput{
+		TopicArn: aws.String(topicArn),
+		Protocol: aws.String(u.Scheme),
+		Endpoint: aws.String(webHookURL),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "subscribing %s to SNS topic %s", webHookURL, topicArn)
+	}
+	return nil
+}
+
+// CreateWebHook subscribes the webhook URL to the SNS topic the events of the repository are published to. The
+// webhook has to confirm the subscription and SNS signs the messages rather than using the secret
+func (p *CodeCommitProvider) CreateWebHook(data *GitWebHookArguments) error {
+	repo := data.Repo.Name
+	topicArn, err := p.webHookTopic(repo)
+	if err != nil {
+		return err
+	}
+	subscriptions, err := p.listSubscriptions(topicArn)
+	if err != nil {
+		return err
+	}
+	for _, s := range subscriptions {
+		if aws.StringValue(s.Endpoint) == data.URL {
+			log.Logger().Infof("Already has a webhook registered for %s", data.URL)
+			return nil
+		}
+	}
+	log.Logger().Infof("Creating CodeCommit webhook bridge for %s for url %s", util.ColorInfo(repo), util.ColorInfo(data.URL))
+	return p.subscribe(topicArn, data.URL)
+}
+
+// ListWebHooks lists the webhook URLs subscribed to the SNS topic of the repository
+func (p *CodeCommitProvider) ListWebHooks(owner string, repo string) ([]*GitWebHookArguments, error) {
+	answer := []*GitWebHookArguments{}
+	topicArn, err := p.webHookTopic(repo)
+	if err != nil {
+		return answer, err
+	}
+	subscriptions, err := p.listSubscriptions(topicArn)
+	if err != nil {
+		return answer, err
+	}
+	for _, s := range subscriptions {
+		answer = append(answer, &GitWebHookArguments{
+			Owner: owner,
+			Repo: &GitRepository{
+				Organisation: owner,
+				Name:         repo,
+			},
+			URL: aws.StringValue(s.Endpoint),
+		})
+	}
+	return answer, nil
+}
+
+// UpdateWebHook replaces the subscription of the existing URL with one for the new URL
+func (p *CodeCommitProvider) UpdateWebHook(data *GitWebHookArguments) error {
+	repo := data.Repo.Name
+	topicArn, err := p.webHookTopic(repo)
+	if err != nil {
+		return err
+	}
+	subscriptions, err := p.listSubscriptions(topicArn)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, s := range subscriptions {
+		if aws.StringValue(s.Endpoint) != data.ExistingURL {
+			continue
+		}
+		found = true
+		if aws.StringValue(s.SubscriptionArn) == "PendingConfirmation" {
+			log.Logger().Warnf("Cannot remove the subscription of %s to SNS topic %s as it is pending confirmation", data.ExistingURL, topicArn)
+			continue
+		}
+		_, err = p.Notifications.Unsubscribe(&sns.UnsubscribeInput{SubscriptionArn: s.SubscriptionArn})
+		if err != nil {
+			return errors.Wrapf(err, "unsubscribing %s from SNS topic %s", data.ExistingURL, topicArn)
+		}
+	}
+	if !found {
+		log.Logger().Warn("No webhooks found to update")
+		return nil
+	}
+	return p.subscribe(topicArn, data.URL)
+}
+
+// IsGitHub returns false
+func (p *CodeCommitProvider) IsGitHub() bool {
+	return false
+}
+
+// IsGitea returns false
+func (p *CodeCommitProvider) IsGitea() bool {
+	return false
+}
+
+// IsBitbucketCloud returns false
+func (p *CodeCommitProvider) IsBitbucketCloud() bool {
+	return false
+}
+
+// IsBitbucketServer returns false
+func (p *CodeCommitProvider) IsBitbucketServer() bool {
+	return false
+}
+
+// IsGerrit returns false
+func (p *CodeCommitProvider) IsGerrit() bool {
+	return false
+}
+
+// Kind returns the kind of the provider
+func (p *CodeCommitProvider) Kind() string {
+	return KindCodeCommit
+}
+
+// GetIssue is not supported as CodeCommit has no issue tracking
+func (p *CodeCommitProvider) GetIssue(org string, name string, number int) (*GitIssue, error) {
+	log.Logger().Warn("CodeCommit does not support issue tracking")
+	return nil, nil
+}
+
+// IssueURL returns the URL of the pull request in the AWS console or "" as CodeCommit has no issues
+func (p *CodeCommitProvider) IssueURL(org string, name string, number int, isPull bool) string {
+	if !isPull {
+		return ""
+	}
+	return p.consoleURL(name, "pull-requests", strconv.Itoa(number), "details")
+}
+
+// SearchIssues is not supported as CodeCommit has no issue tracking
+func (p *CodeCommitProvider) SearchIssues(org string, name string, state string) ([]*GitIssue, error) {
+	log.Logger().Warn("CodeCommit does not support issue tracking")
+	return nil, nil
+}
+
+// SearchIssuesClosedSince is not supported as CodeCommit has no issue tracking
+func (p *CodeCommitProvider) SearchIssuesClosedSince(org string, name string, t time.Time) ([]*GitIssue, error) {
+	log.Logger().Warn("CodeCommit does not support issue tracking")
+	return nil, nil
+}
+
+// CreateIssue is not supported as CodeCommit has no issue tracking
+func (p *CodeCommitProvider) CreateIssue(owner string, repo string, issue *GitIssue) (*GitIssue, error) {
+	log.Logger().Warn("CodeCommit does not support issue tracking")
+	return nil, nil
+}
+
+// HasIssues returns false as CodeCommit has no issue tracking
+func (p *CodeCommitProvider) HasIssues() bool {
+	return false
+}
+
+// AddPRComment comments on the changes of the pull request
+func (p *CodeCommitProvider) AddPRComment(pr *GitPullRequest, comment string) error {
+	if pr.Number == nil {
+		return fmt.Errorf("missing Number for GitPullRequest %#v", pr)
+	}
+	return p.CreateIssueComment(pr.Owner, pr.Repo, *pr.Number, comment)
+}
+
+// CreateIssueComment comments on the changes of the pull request
+func (p *CodeCommitProvider) CreateIssueComment(owner string, repo string, number int, comment string) error {
+	source, err := p.getPullRequest(number)
+	if err != nil {
+		return err
+	}
+	if len(source.PullRequestTargets) == 0 {
+		return errors.Errorf("pull request #%d has no targets", number)
+	}
+	target := source.PullRequestTargets[0]
+	_, err = p.Client.PostCommentForPullRequest(&codecommit.PostCommentForPullRequestInput{
+		PullRequestId:  source.PullRequestId,
+		RepositoryName: aws.String(repo),
+		BeforeCommitId: target.DestinationCommit,
+		AfterCommitId:  target.SourceCommit,
+		Content:        aws.String(comment),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "commenting on pull request #%d of CodeCommit repository %s", number, repo)
+	}
+	return nil
+}
+
+// UpdateRelease is not supported as CodeCommit has no releases
+func (p *CodeCommitProvider) UpdateRelease(owner string, repo string, tag string, releaseInfo *GitRelease) error {
+	log.Logger().Debugf("CodeCommit does not support releases so not updating the release %s of %s", tag, repo)
+	return nil
+}
+
+// UpdateReleaseStatus is not supported as CodeCommit has no releases
+func (p *CodeCommitProvider) UpdateReleaseStatus(owner string, repo string, tag string, releaseInfo *GitRelease) error {
+	return nil
+}
+
+// ListReleases returns no releases as CodeCommit has no releases
+func (p *CodeCommitProvider) ListReleases(org string, name string) ([]*GitRelease, error) {
+	return []*GitRelease{}, nil
+}
+
+// GetRelease returns no release as CodeCommit has no releases
+func (p *CodeCommitProvider) GetRelease(org string, name string, tag string) (*GitRelease, error) {
+	return nil, nil
+}
+
+// UploadReleaseAsset is not supported as CodeCommit has no releases
+func (p *CodeCommitProvider) UploadReleaseAsset(org string, repo string, id int64, name string, asset *os.File) (*GitReleaseAsset, error) {
+	return nil, errors.Errorf("uploading release assets is not supported for CodeCommit")
+}
+
+// GetLatestRelease returns no release as CodeCommit has no releases
+func (p *CodeCommitProvider) GetLatestRelease(org string, name string) (*GitRelease, error) {
+	return nil, nil
+}
+
+// GetContent returns the base64 encoded content of the file at the ref
+func (p *CodeCommitProvider) GetContent(org string, name string, path string, ref string) (*GitFileContent, error) {
+	input := &codecommit.GetFileInput{
+		RepositoryName: aws.String(name),
+		FilePath:       aws.String(path),
+	}
+	if ref != "" {
+		input.CommitSpecifier = aws.String(ref)
+	}
+	output, err := p.Client.GetFile(input)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting %s of CodeCommit repository %s", path, name)
+	}
+	filePath := aws.StringValue(output.FilePath)
+	parts := strings.Split(filePath, "/")
+	return &GitFileContent{
+		Type:     "file",
+		Encoding: "base64",
+		Size:     int(aws.Int64Value(output.FileSize)),
+		Name:     parts[len(parts)-1],
+		Path:     filePath,
+		Content:  base64.StdEncoding.EncodeToString(output.FileContent),
+		Sha:      aws.StringValue(output.BlobId),
+	}, nil
+}
+
+// JenkinsWebHookPath returns the path of the webhook endpoint of Jenkins
+func (p *CodeCommitProvider) JenkinsWebHookPath(gitURL string, secret string) string {
+	return "/generic-webhook-trigger/invoke"
+}
+
+// Label returns the label of the server
+func (p *CodeCommitProvider) Label() string {
+	return p.Server.Label()
+}
+
+// ServerURL returns the URL of the server
+func (p *CodeCommitProvider) ServerURL() string {
+	return p.Server.URL
+}
+
+// BranchArchiveURL returns "" as CodeCommit cannot download archives of branches
+func (p *CodeCommitProvider) BranchArchiveURL(org string, name string, branch string) string {
+	return ""
+}
+
+// CurrentUsername returns the username of the HTTPS Git credentials
+func (p *CodeCommitProvider) CurrentUsername() string {
+	return p.Username
+}
+
+// UserAuth returns the authentication of the user
+func (p *CodeCommitProvider) UserAuth() auth.UserAuth {
+	return p.User
+}
+
+// UserInfo returns the user
+func (p *CodeCommitProvider) UserInfo(username string) *GitUser {
+	return &GitUser{
+		Login: username,
+	}
+}
+
+// AddCollaborator is not supported as CodeCommit manages access with IAM policies
+func (p *CodeCommitProvider) AddCollaborator(user string, organisation string, repo string) error {
+	log.Logger().Infof("Automatically adding the pipeline user as a collaborator is not supported for CodeCommit. Please grant user: %v access to the repository %s with an IAM policy.", user, repo)
+	return nil
+}
+
+// ListInvitations is not supported for CodeCommit
+func (p *CodeCommitProvider) ListInvitations() ([]*github.RepositoryInvitation, *github.Response, error) {
+	return []*github.RepositoryInvitation{}, &github.Response{}, nil
+}
+
+// AcceptInvitation is not supported for CodeCommit
+func (p *CodeCommitProvider) AcceptInvitation(ID int64) (*github.Response, error) {
+	return &github.Response{}, nil
+}
+
+// ShouldForkForPullRequest returns false as CodeCommit does not support forks
+func (p *CodeCommitProvider) ShouldForkForPullRequest(originalOwner string, repoName string, username string) bool {
+	return false
+}
+
+// GetBranch returns the branch and the commit at its tip
+func (p *CodeCommitProvider) GetBranch(owner string, repo string, branch string) (*GitBranch, error) {
+	output, err := p.Client.GetBranch(&codecommit.GetBranchInput{
+		RepositoryName: aws.String(repo),
+		BranchName:     aws.String(branch),
+	})
+	if err != nil {
+		if isCodeCommitNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "getting branch %s of CodeCommit repository %s", branch, repo)
+	}
+	return &GitBranch{
+		Name: branch,
+		Commit: &GitCommit{
+			SHA:    aws.StringValue(output.Branch.CommitId),
+			Branch: branch,
+		},
+	}, nil
+}
+
+// GetProjects returns no projects as CodeCommit has no projects
+func (p *CodeCommitProvider) GetProjects(owner string, repo string) ([]GitProject, error) {
+	return nil, nil
+}
+
+// IsWikiEnabled returns false as CodeCommit has no wikis
+func (p *CodeCommitProvider) IsWikiEnabled(owner string, repo string) (bool, error) {
+	return false, nil
+}
+
+// ConfigureFeatures is not supported for CodeCommit
+func (p *CodeCommitProvider) ConfigureFeatures(owner string, repo string, issues *bool, projects *bool, wikis *bool) (*GitRepository, error) {
+	return p.GetRepository(owner, repo)
+}
@@ -0,0 +1,269 @@
+// +build unit
+
+package gits_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudwatchevents"
+	"github.com/aws/aws-sdk-go/service/cloudwatchevents/cloudwatcheventsiface"
+	"github.com/aws/aws-sdk-go/service/codecommit"
+	"github.com/aws/aws-sdk-go/service/codecommit/codecommitiface"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sns/snsiface"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockedCodeCommit struct {
+	codecommitiface.CodeCommitAPI
+	repositories map[string]*codecommit.RepositoryMetadata
+	pullRequest  *codecommit.PullRequest
+	commits      map[string]*codecommit.Commit
+	merged       *codecommit.MergePullRequestByThreeWayInput
+	comment      *codecommit.PostCommentForPullRequestInput
+}
+
+func (m *mockedCodeCommit) CreateRepository(input *codecommit.CreateRepositoryInput) (*codecommit.CreateRepositoryOutput, error) {
+	name := aws.StringValue(input.RepositoryName)
+	metadata := &codecommit.RepositoryMetadata{
+		RepositoryName: input.RepositoryName,
+		Arn:            aws.String("arn:aws:codecommit:us-east-1:123456789012:" + name),
+		CloneUrlHttp:   aws.String("https://git-codecommit.us-east-1.amazonaws.com/v1/repos/" + name),
+	}
+	m.repositories[name] = metadata
+	return &codecommit.CreateRepositoryOutput{RepositoryMetadata: metadata}, nil
+}
+
+func (m *mockedCodeCommit) GetRepository(input *codecommit.GetRepositoryInput) (*codecommit.GetRepositoryOutput, error) {
+	metadata, ok := m.repositories[aws.StringValue(input.RepositoryName)]
+	if !ok {
+		return nil, awserr.New(codecommit.ErrCodeRepositoryDoesNotExistException, "not found", nil)
+	}
+	return &codecommit.GetRepositoryOutput{RepositoryMetadata: metadata}, nil
+}
+
+func (m *mockedCodeCommit) CreatePullRequest(input *codecommit.CreatePullRequestInput) (*codecommit.CreatePullRequestOutput, error) {
+	target := input.Targets[0]
+	m.pullRequest = &codecommit.PullRequest{
+		PullRequestId:     aws.String("7"),
+		PullRequestStatus: aws.String(codecommit.PullRequestStatusEnumOpen),
+		Title:             input.Title,
+		Description:       input.Description,
+		AuthorArn:         aws.String("arn:aws:iam::123456789012:user/bob"),
+		PullRequestTargets: []*codecommit.PullRequestTarget{
+			{
+				RepositoryName:       target.RepositoryName,
+				SourceReference:      aws.String("refs/heads/" + aws.StringValue(target.SourceReference)),
+				DestinationReference: aws.String("refs/heads/" + aws.StringValue(target.DestinationReference)),
+				SourceCommit:         aws.String("c3"),
+				DestinationCommit:    aws.String("c1"),
+				MergeBase:            aws.String("c1"),
+				MergeMetadata:        &codecommit.MergeMetadata{IsMerged: aws.Bool(false)},
+			},
+		},
+		ApprovalRules: []*codecommit.ApprovalRule{{ApprovalRuleName: aws.String("reviewers")}},
+		RevisionId:    aws.String("r1"),
+	}
+	return &codecommit.CreatePullRequestOutput{PullRequest: m.pullRequest}, nil
+}
+
+func (m *mockedCodeCommit) GetPullRequest(input *codecommit.GetPullRequestInput) (*codecommit.GetPullRequestOutput, error) {
+	if m.pullRequest == nil || aws.StringValue(input.PullRequestId) != aws.StringValue(m.pullRequest.PullRequestId) {
+		return nil, awserr.New(codecommit.ErrCodePullRequestDoesNotExistException, "not found", nil)
+	}
+	return &codecommit.GetPullRequestOutput{PullRequest: m.pullRequest}, nil
+}
+
+func (m *mockedCodeCommit) EvaluatePullRequestApprovalRules(input *codecommit.EvaluatePullRequestApprovalRulesInput) (*codecommit.EvaluatePullRequestApprovalRulesOutput, error) {
+	return &codecommit.EvaluatePullRequestApprovalRulesOutput{
+		Evaluation: &codecommit.Evaluation{Approved: aws.Bool(true)},
+	}, nil
+}
+
+func (m *mockedCodeCommit) GetCommit(input *codecommit.GetCommitInput) (*codecommit.GetCommitOutput, error) {
+	return &codecommit.GetCommitOutput{Commit: m.commits[aws.StringValue(input.CommitId)]}, nil
+}
+
+func (m *mockedCodeCommit) MergePullRequestByThreeWay(input *codecommit.MergePullRequestByThreeWayInput) (*codecommit.MergePullRequestByThreeWayOutput, error) {
+	m.merged = input
+	return &codecommit.MergePullRequestByThreeWayOutput{}, nil
+}
+
+func (m *mockedCodeCommit) PostCommentForPullRequest(input *codecommit.PostCommentForPullRequestInput) (*codecommit.PostCommentForPullRequestOutput, error) {
+	m.comment = input
+	return &codecommit.PostCommentForPullRequestOutput{}, nil
+}
+
+type mockedEvents struct {
+	cloudwatcheventsiface.CloudWatchEventsAPI
+	rule    *cloudwatchevents.PutRuleInput
+	targets *cloudwatchevents.PutTargetsInput
+}
+
+func (m *mockedEvents) PutRule(input *cloudwatchevents.PutRuleInput) (*cloudwatchevents.PutRuleOutput, error) {
+	m.rule = input
+	return &cloudwatchevents.PutRuleOutput{}, nil
+}
+
+func (m *mockedEvents) PutTargets(input *cloudwatchevents.PutTargetsInput) (*cloudwatchevents.PutTargetsOutput, error) {
+	m.targets = input
+	return &cloudwatchevents.PutTargetsOutput{}, nil
+}
+
+type mockedSNS struct {
+	snsiface.SNSAPI
+	subscriptions []*sns.Subscription
+	unsubscribed  []string
+}
+
+func (m *mockedSNS) CreateTopic(input *sns.CreateTopicInput) (*sns.CreateTopicOutput, error) {
+	return &sns.CreateTopicOutput{TopicArn: aws.String("arn:aws:sns:us-east-1:123456789012:" + aws.StringValue(input.Name))}, nil
+}
+
+func (m *mockedSNS) SetTopicAttributes(input *sns.SetTopicAttributesInput) (*sns.SetTopicAttributesOutput, error) {
+	return &sns.SetTopicAttributesOutput{}, nil
+}
+
+func (m *mockedSNS) ListSubscriptionsByTopicPages(input *sns.ListSubscriptionsByTopicInput, fn func(*sns.ListSubscriptionsByTopicOutput, bool) bool) error {
+	fn(&sns.ListSubscriptionsByTopicOutput{Subscriptions: m.subscriptions}, true)
+	return nil
+}
+
+func (m *mockedSNS) Subscribe(input *sns.SubscribeInput) (*sns.SubscribeOutput, error) {
+	arn := aws.StringValue(input.TopicArn) + ":" + aws.StringValue(input.Endpoint)
+	m.subscriptions = append(m.subscriptions, &sns.Subscription{
+		Endpoint:        input.Endpoint,
+		Protocol:        input.Protocol,
+		SubscriptionArn: aws.String(arn),
+	})
+	return &sns.SubscribeOutput{SubscriptionArn: aws.String(arn)}, nil
+}
+
+func (m *mockedSNS) Unsubscribe(input *sns.UnsubscribeInput) (*sns.UnsubscribeOutput, error) {
+	m.unsubscribed = append(m.unsubscribed, aws.StringValue(input.SubscriptionArn))
+	subscriptions := []*sns.Subscription{}
+	for _, s := range m.subscriptions {
+		if aws.StringValue(s.SubscriptionArn) != aws.StringValue(input.SubscriptionArn) {
+			subscriptions = append(subscriptions, s)
+		}
+	}
+	m.subscriptions = subscriptions
+	return &sns.UnsubscribeOutput{}, nil
+}
+
+func newMockedCodeCommitProvider() (*gits.CodeCommitProvider, *mockedCodeCommit, *mockedEvents, *mockedSNS) {
+	client := &mockedCodeCommit{
+		repositories: map[string]*codecommit.RepositoryMetadata{},
+		commits: map[string]*codecommit.Commit{
+			"c3": {CommitId: aws.String("c3"), Message: aws.String("third"), Parents: []*string{aws.String("c2")}},
+			"c2": {CommitId: aws.String("c2"), Message: aws.String("second"), Parents: []*string{aws.String("c1")}},
+			"c1": {CommitId: aws.String("c1"), Message: aws.String("first")},
+		},
+	}
+	events := &mockedEvents{}
+	notifications := &mockedSNS{}
+	provider := &gits.CodeCommitProvider{
+		Region:        "us-east-1",
+		Client:        client,
+		Events:        events,
+		Notifications: notifications,
+	}
+	return provider, client, events, notifications
+}
+
+func TestCodeCommitRegion(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "us-east-1", gits.CodeCommitRegion("https://git-codecommit.us-east-1.amazonaws.com"))
+	assert.Equal(t, "eu-west-1", gits.CodeCommitRegion("codecommit::eu-west-1://myrepo"))
+	assert.Equal(t, "", gits.CodeCommitRegion("https://github.com"))
+	assert.Equal(t, "https://git-codecommit.us-east-1.amazonaws.com", gits.CodeCommitServerURL("us-east-1"))
+}
+
+func TestCodeCommitRepositories(t *testing.T) {
+	t.Parallel()
+	provider, _, _, _ := newMockedCodeCommitProvider()
+
+	assert.NoError(t, provider.ValidateRepositoryName("us-east-1", "environment-staging"))
+	repo, err := provider.CreateRepository("us-east-1", "environment-staging", true)
+	require.NoError(t, err)
+	assert.Equal(t, "environment-staging", repo.Name)
+	assert.Equal(t, "us-east-1", repo.Organisation)
+	assert.Equal(t, "https://git-codecommit.us-east-1.amazonaws.com/v1/repos/environment-staging", repo.CloneURL)
+	assert.Error(t, provider.ValidateRepositoryName("us-east-1", "environment-staging"))
+
+	orgs, err := provider.ListOrganisations()
+	require.NoError(t, err)
+	assert.Equal(t, []gits.GitOrganisation{{Login: "us-east-1"}}, orgs)
+}
+
+func TestCodeCommitPullRequests(t *testing.T) {
+	t.Parallel()
+	provider, client, _, _ := newMockedCodeCommitProvider()
+
+	pr, err := provider.CreatePullRequest(&gits.GitPullRequestArguments{
+		GitRepository: &gits.GitRepository{Organisation: "us-east-1", Name: "myrepo"},
+		Title:         "my change",
+		Head:          "feature",
+		Base:          "master",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 7, *pr.Number)
+	assert.Equal(t, "open", *pr.State)
+	assert.Equal(t, "feature", *pr.HeadRef)
+	assert.Equal(t, "c3", pr.LastCommitSha)
+	assert.Equal(t, "bob", pr.Author.Login)
+	require.Len(t, pr.Labels, 1)
+	assert.Equal(t, gits.ApprovedLabel, *pr.Labels[0].Name)
+	assert.Equal(t, "https://us-east-1.console.aws.amazon.com/codesuite/codecommit/repositories/myrepo/pull-requests/7/details?region=us-east-1", pr.URL)
+
+	commits, err := provider.GetPullRequestCommits("us-east-1", &gits.GitRepository{Name: "myrepo"}, 7)
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	assert.Equal(t, "c3", commits[0].SHA)
+	assert.Equal(t, "c2", commits[1].SHA)
+
+	err = provider.AddPRComment(pr, "/lgtm")
+	require.NoError(t, err)
+	assert.Equal(t, "c1", aws.StringValue(client.comment.BeforeCommitId))
+	assert.Equal(t, "c3", aws.StringValue(client.comment.AfterCommitId))
+
+	err = provider.MergePullRequest(pr, "merged by jx")
+	require.NoError(t, err)
+	assert.Equal(t, "7", aws.StringValue(client.merged.PullRequestId))
+	assert.Equal(t, "c3", aws.StringValue(client.merged.SourceCommitId))
+}
+
+func TestCodeCommitWebHooks(t *testing.T) {
+	t.Parallel()
+	provider, client, events, notifications := newMockedCodeCommitProvider()
+	_, err := client.CreateRepository(&codecommit.CreateRepositoryInput{RepositoryName: aws.String("myrepo")})
+	require.NoError(t, err)
+
+	repo := &gits.GitRepository{Name: "myrepo"}
+	err = provider.CreateWebHook(&gits.GitWebHookArguments{Owner: "us-east-1", Repo: repo, URL: "https://hook.jx.example.com/hook"})
+	require.NoError(t, err)
+	err = provider.CreateWebHook(&gits.GitWebHookArguments{Owner: "us-east-1", Repo: repo, URL: "https://hook.jx.example.com/hook"})
+	require.NoError(t, err)
+	require.Len(t, notifications.subscriptions, 1)
+	assert.Equal(t, "https", aws.StringValue(notifications.subscriptions[0].Protocol))
+
+	assert.Equal(t, "jx-codecommit-myrepo", aws.StringValue(events.rule.Name))
+	pattern := map[string][]string{}
+	require.NoError(t, json.Unmarshal([]byte(aws.StringValue(events.rule.EventPattern)), &pattern))
+	assert.Equal(t, []string{"arn:aws:codecommit:us-east-1:123456789012:myrepo"}, pattern["resources"])
+	assert.Equal(t, "arn:aws:sns:us-east-1:123456789012:jx-codecommit-myrepo", aws.StringValue(events.targets.Targets[0].Arn))
+
+	err = provider.UpdateWebHook(&gits.GitWebHookArguments{Owner: "us-east-1", Repo: repo, URL: "https://hook.jx.example.com/new", ExistingURL: "https://hook.jx.example.com/hook"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"arn:aws:sns:us-east-1:123456789012:jx-codecommit-myrepo:https://hook.jx.example.com/hook"}, notifications.unsubscribed)
+
+	hooks, err := provider.ListWebHooks("us-east-1", "myrepo")
+	require.NoError(t, err)
+	require.Len(t, hooks, 1)
+	assert.Equal(t, "https://hook.jx.example.com/new", hooks[0].URL)
+}
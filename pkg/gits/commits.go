@@ -3,6 +3,8 @@ package gits
 import (
 	"bytes"
 	"fmt"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -14,7 +16,9 @@ type CommitInfo struct {
 	Kind    string
 	Feature string
 	Message string
-	group   *CommitGroup
+	// Breaking is true for commits marked with a '!' after their type or scope or with a BREAKING CHANGE footer
+	Breaking bool
+	group    *CommitGroup
 }
 
 type CommitGroup struct {
@@ -41,6 +45,9 @@ var (
 	}
 
 	unknownKindOrder = len(ConventionalCommitTitles) + 1
+
+	conventionalCommitRegex = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9_-]*)(\(([^)]*)\))?(!)?:`)
+	breakingChangeRegex     = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE:`)
 )
 
 func createCommitGroup(title string) *CommitGroup {
@@ -68,20 +75,15 @@ func ParseCommit(message string) *CommitInfo {
 		Message: message,
 	}
 
-	idx := strings.Index(message, ":")
-	if idx > 0 {
-		kind := message[0:idx]
-		if strings.HasSuffix(kind, ")") {
-			idx := strings.Index(kind, "(")
-			if idx > 0 {
-				answer.Feature = strings.TrimSpace(kind[idx+1 : len(kind)-1])
-				kind = strings.TrimSpace(kind[0:idx])
-			}
-		}
-		answer.Kind = kind
-		rest := strings.TrimSpace(message[idx+1:])
-
-		answer.Message = rest
+	match := conventionalCommitRegex.FindStringSubmatch(message)
+	if match != nil {
+		answer.Kind = match[1]
+		answer.Feature = strings.TrimSpace(match[3])
+		answer.Breaking = match[4] != ""
+		answer.Message = strings.TrimSpace(message[len(match[0]):])
+	}
+	if breakingChangeRegex.MatchString(message) {
+		answer.Breaking = true
 	}
 	return answer
 }
@@ -104,6 +106,7 @@ func (c *CommitInfo) Order() int {
 type GroupAndCommitInfos struct {
 	group   *CommitGroup
 	commits []string
+	scopes  []string
 }
 
 // GenerateMarkdown generates the markdown document for the commits
@@ -137,6 +140,7 @@ func GenerateMarkdown(releaseSpec *v1.ReleaseSpec, gitInfo *GitRepository) (stri
 					groupAndCommits[group.Order] = gac
 				}
 				gac.commits = append(gac.commits, description)
+				gac.scopes = append(gac.scopes, ci.Feature)
 			}
 			commitInfos = append(commitInfos, ci)
 		}
@@ -151,6 +155,21 @@ func GenerateMarkdown(releaseSpec *v1.ReleaseSpec, gitInfo *GitRepository) (stri
 
 	buffer.WriteString("## Changes\n")
 
+	breaking := []string{}
+	for _, cs := range releaseSpec.Commits {
+		commit := cs
+		ci := ParseCommit(commit.Message)
+		if ci.Breaking {
+			breaking = append(breaking, "* "+describeCommit(gitInfo, &commit, ci, issueMap)+"\n")
+		}
+	}
+	if len(breaking) > 0 {
+		buffer.WriteString("\n### BREAKING CHANGES\n\n")
+		for _, msg := range breaking {
+			buffer.WriteString(msg)
+		}
+	}
+
 	hasTitle := false
 	for i := 0; i <= unknownKindOrder; i++ {
 		gac := groupAndCommits[i]
@@ -168,8 +187,17 @@ func GenerateMarkdown(releaseSpec *v1.ReleaseSpec, gitInfo *GitRepository) (stri
 					buffer.WriteString("### " + group.Title + "\n\n" + legend)
 				}
 			}
+			// lets keep the commits of the same scope together
+			order := make([]int, len(gac.commits))
+			for j := range order {
+				order[j] = j
+			}
+			sort.SliceStable(order, func(a, b int) bool {
+				return gac.scopes[order[a]] < gac.scopes[order[b]]
+			})
 			previous := ""
-			for _, msg := range gac.commits {
+			for _, j := range order {
+				msg := gac.commits[j]
 				if msg != previous {
 					buffer.WriteString(msg)
 					previous = msg
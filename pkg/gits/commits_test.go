@@ -23,6 +23,17 @@ func TestParseCommits(t *testing.T) {
 		Feature: "beer",
 		Message: "wine is good too",
 	})
+	assertParseCommit(t, "feat(api)!: remove the v1 endpoints", &gits.CommitInfo{
+		Kind:     "feat",
+		Feature:  "api",
+		Message:  "remove the v1 endpoints",
+		Breaking: true,
+	})
+	assertParseCommit(t, "fix: drop the old flag\n\nBREAKING CHANGE: the --old flag is gone", &gits.CommitInfo{
+		Kind:     "fix",
+		Message:  "drop the old flag\n\nBREAKING CHANGE: the --old flag is gone",
+		Breaking: true,
+	})
 }
 
 func assertParseCommit(t *testing.T, input string, expected *gits.CommitInfo) {
@@ -11,6 +11,10 @@ const (
 	KindGitlab = "gitlab"
 	// KindGitHub git kind for github
 	KindGitHub = "github"
+	// KindAzureDevOps git kind for Azure DevOps
+	KindAzureDevOps = "azuredevops"
+	// KindCodeCommit git kind for AWS CodeCommit
+	KindCodeCommit = "codecommit"
 	// KindGitFake git kind for fake git
 	KindGitFake = "fakegit"
 	// KindUnknown git kind for unknown git
@@ -24,8 +28,11 @@ const (
 
 	ProjectOpen   = "open"   // the state of an open project
 	ProjectClosed = "closed" // the stat of a closed project
+
+	// ApprovedLabel the label of a pull request which has all the approvals it requires
+	ApprovedLabel = "approved"
 )
 
 var (
-	KindGits = []string{KindBitBucketCloud, KindBitBucketServer, KindGitea, KindGitHub, KindGitlab}
+	KindGits = []string{KindAzureDevOps, KindBitBucketCloud, KindBitBucketServer, KindCodeCommit, KindGitea, KindGitHub, KindGitlab}
 )
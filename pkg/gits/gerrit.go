@@ -35,7 +35,7 @@ func NewGerritProvider(server *auth.AuthServer, user *auth.UserAuth, git Gitter)
 		Git:      git,
 	}
 
-	client, err := gerrit.NewClient(server.URL, nil)
+	client, err := gerrit.NewClient(server.URL, NewAPIHTTPClient())
 	if err != nil {
 		return nil, err
 	}
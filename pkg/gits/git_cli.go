@@ -2,6 +2,7 @@ package gits
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -18,6 +19,7 @@ import (
 
 	"github.com/jenkins-x/jx-logging/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/tracing"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 
 	gitcfg "gopkg.in/src-d/go-git.v4/config"
@@ -34,7 +36,13 @@ var (
 
 // GitCLI implements common git actions based on git CLI
 type GitCLI struct {
-	Env map[string]string
+	Env       map[string]string
+	LargeRepo LargeRepoOptions
+	SSH       SSHOptions
+	Signing   SigningOptions
+
+	// ctx the context containing the tracing span the spans of the clones and git commands are children of
+	ctx context.Context
 }
 
 // NewGitCLI creates a new GitCLI instance
@@ -46,6 +54,9 @@ func NewGitCLI() *GitCLI {
 	cli.Env["LC_ALL"] = "C"
 	// When jx is called as credential helper we want to make sure that potential debug trace is not interfering with the process
 	cli.Env["JX_LOG_LEVEL"] = "error"
+	setLargeRepo(cli, LargeRepoOptionsFromEnv())
+	ConfigureSSH(cli, SSHOptionsFromEnv())
+	ConfigureSigning(cli, SigningOptionsFromEnv())
 	return cli
 }
 
@@ -119,11 +130,21 @@ func (g *GitCLI) FindGitConfigDir(dir string) (string, string, error) {
 
 // Clone clones the given git URL into the given directory
 func (g *GitCLI) Clone(url string, dir string) error {
-	return g.clone(dir, url, "", false, false, "", "", "")
+	cli, span := g.startCloneSpan(url)
+	err := cli.clone(dir, url, "", false, false, "", "", "")
+	span.End(err)
+	return err
 }
 
 // Clone clones a single branch of the given git URL into the given directory
 func (g *GitCLI) ShallowCloneBranch(gitURL string, branch string, dir string) error {
+	cli, span := g.startCloneSpan(gitURL, tracing.String("git.branch", branch))
+	err := cli.shallowCloneBranch(gitURL, branch, dir)
+	span.End(err)
+	return err
+}
+
+func (g *GitCLI) shallowCloneBranch(gitURL string, branch string, dir string) error {
 	var err error
 	verbose := true
 	remoteName := "origin"
@@ -173,12 +194,37 @@ func (g *GitCLI) ShallowCloneBranch(gitURL string, branch string, dir string) er
 				branch, branch, dir)
 		}
 	}
-	return nil
+	return g.pullLFS(dir, remoteName)
 }
 
 // ShallowClone shallow clones the repo at url from the specified commitish or pull request to a local master branch
 func (g *GitCLI) ShallowClone(dir string, url string, commitish string, pullRequest string) error {
-	return g.clone(dir, url, "", true, false, "master", commitish, pullRequest)
+	cli, span := g.startCloneSpan(url)
+	err := cli.clone(dir, url, "", true, false, "master", commitish, pullRequest)
+	span.End(err)
+	return err
+}
+
+// WithContext returns a copy of the git client whose clones are traced as children of the span in the context, such as
+// the span of the running command. It returns the git client unchanged if it does not support tracing
+func WithContext(gitter Gitter, ctx context.Context) Gitter {
+	cli, ok := gitter.(*GitCLI)
+	if !ok || ctx == nil {
+		return gitter
+	}
+	answer := *cli
+	answer.ctx = ctx
+	return &answer
+}
+
+// startCloneSpan starts the tracing span of cloning the repository without any credentials in its URL. It returns a
+// copy of the git client which runs the git commands of the clone as children of the span
+func (g *GitCLI) startCloneSpan(gitURL string, attributes ...tracing.Attribute) (*GitCLI, *tracing.Span) {
+	attributes = append([]tracing.Attribute{tracing.String("git.url", util.SanitizeURL(gitURL))}, attributes...)
+	ctx, span := tracing.StartSpan(g.ctx, "git clone", attributes...)
+	cli := *g
+	cli.ctx = ctx
+	return &cli, span
 }
 
 // clone is a safer implementation of the `git clone` method
@@ -208,6 +254,10 @@ func (g *GitCLI) clone(dir string, gitURL string, remoteName string, shallow boo
 		localBranch = commitish
 	}
 
+	err = g.SSH.Validate()
+	if err != nil {
+		return err
+	}
 	err = g.Init(dir)
 	if err != nil {
 		return errors.Wrapf(err, "failed to init a new git repository in directory %s", dir)
@@ -222,6 +272,10 @@ func (g *GitCLI) clone(dir string, gitURL string, remoteName string, shallow boo
 	if verbose {
 		log.Logger().Infof("ran git add remote %s %s in %s", remoteName, gitURL, dir)
 	}
+	err = g.configureLargeRepo(dir, remoteName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to configure the clone of %s in directory %s", gitURL, dir)
+	}
 
 	err = g.fetchBranch(dir, remoteName, false, shallow, verbose, commitish)
 	if err != nil {
@@ -245,13 +299,17 @@ func (g *GitCLI) clone(dir string, gitURL string, remoteName string, shallow boo
 			commitish = "master"
 		}
 	}
-	err = g.Reset(dir, fmt.Sprintf("%s/%s", remoteName, commitish), true)
+	_, err = g.gitCmdWithEnvAndOutput(dir, g.largeRepoEnv(), "reset", "--hard", fmt.Sprintf("%s/%s", remoteName, commitish))
 	if err != nil {
 		return errors.Wrapf(err, "failed to reset hard to %s in directory %s", commitish, dir)
 	}
 	if verbose {
 		log.Logger().Infof("ran git reset --hard %s in directory %s", commitish, dir)
 	}
+	err = g.pullLFS(dir, remoteName)
+	if err != nil {
+		return err
+	}
 	err = g.gitCmd(dir, "branch", "--set-upstream-to", fmt.Sprintf("%s/%s", remoteName, commitish), localBranch)
 	if err != nil {
 		return errors.Wrapf(err, "failed to set tracking information to %s/%s %s in directory %s", remoteName,
@@ -266,7 +324,11 @@ func (g *GitCLI) clone(dir string, gitURL string, remoteName string, shallow boo
 
 // Pull pulls the Git repository in the given directory
 func (g *GitCLI) Pull(dir string) error {
-	return g.gitCmd(dir, "pull")
+	err := g.gitCmd(dir, "pull")
+	if err != nil {
+		return err
+	}
+	return g.pullLFS(dir, "")
 }
 
 // PullRemoteBranches pulls the remote Git tags from the given directory
@@ -313,6 +375,7 @@ func (g *GitCLI) ResetToUpstream(dir string, branch string) error {
 
 // AddRemote adds a remote repository at the given URL and with the given name
 func (g *GitCLI) AddRemote(dir string, name string, url string) error {
+	url = g.SSH.SSHURL(url)
 	err := g.gitCmd(dir, "remote", "add", name, url)
 	if err != nil {
 		err = g.gitCmd(dir, "remote", "set-url", name, url)
@@ -325,7 +388,7 @@ func (g *GitCLI) AddRemote(dir string, name string, url string) error {
 
 // UpdateRemote updates the URL of the remote repository
 func (g *GitCLI) UpdateRemote(dir, url string) error {
-	return g.gitCmd(dir, "remote", "set-url", "origin", url)
+	return g.gitCmd(dir, "remote", "set-url", "origin", g.SSH.SSHURL(url))
 }
 
 // RemoteUpdate performs a git remote update
@@ -567,12 +630,12 @@ func (g *GitCLI) GetCommitsNotOnAnyRemote(dir string, branch string) ([]GitCommi
 
 // CommitDir commits all changes from the given directory
 func (g *GitCLI) CommitDir(dir string, message string) error {
-	return g.gitCmd(dir, "commit", "-m", message)
+	return g.commitCmd(dir, "-m", message)
 }
 
 // AddCommit perform an add and commit of the changes from the repository at the given directory with the given messages
 func (g *GitCLI) AddCommit(dir string, msg string) error {
-	return g.gitCmd(dir, "commit", "-a", "-m", msg, "--allow-empty")
+	return g.commitCmd(dir, "-a", "-m", msg, "--allow-empty")
 }
 
 // AddCommitFiles perform an add and commit selected files from the repository at the given directory with the given messages
@@ -583,15 +646,25 @@ func (g *GitCLI) AddCommitFiles(dir string, msg string, files []string) error {
 			return err
 		}
 	}
-	return g.gitCmd(dir, "commit", "-m", msg)
+	return g.commitCmd(dir, "-m", msg)
+}
+
+// commitCmd runs git commit with the arguments signing the commit if signing is configured
+func (g *GitCLI) commitCmd(dir string, args ...string) error {
+	configArgs, err := g.signingConfigArgs()
+	if err != nil {
+		return errors.Wrap(err, "configuring the signing of the commit")
+	}
+	return g.gitCmd(dir, append(append(configArgs, "commit"), args...)...)
 }
 
 func (g *GitCLI) gitCmd(dir string, args ...string) error {
 	cmd := util.Command{
-		Dir:  dir,
-		Name: "git",
-		Args: args,
-		Env:  g.Env,
+		Dir:     dir,
+		Name:    "git",
+		Args:    args,
+		Env:     g.Env,
+		Context: g.ctx,
 	}
 	log.Logger().Debug(cmd.String())
 	output, err := cmd.RunWithoutRetry()
@@ -600,17 +673,25 @@ func (g *GitCLI) gitCmd(dir string, args ...string) error {
 
 func (g *GitCLI) gitCmdWithOutput(dir string, args ...string) (string, error) {
 	cmd := util.Command{
-		Dir:  dir,
-		Name: "git",
-		Args: args,
-		Env:  g.Env,
+		Dir:     dir,
+		Name:    "git",
+		Args:    args,
+		Env:     g.Env,
+		Context: g.ctx,
 	}
 	log.Logger().Debug(cmd.String())
 	return cmd.RunWithoutRetry()
 }
 
 // CreateAuthenticatedURL creates the Git repository URL with the username and password encoded for HTTPS based URLs
+// or the SSH URL if git is configured to use SSH
 func (g *GitCLI) CreateAuthenticatedURL(cloneURL string, userAuth *auth.UserAuth) (string, error) {
+	if IsSSHURL(cloneURL) {
+		return cloneURL, nil
+	}
+	if g.SSH.Enabled {
+		return g.SSH.SSHURL(cloneURL), nil
+	}
 	u, err := url.Parse(cloneURL)
 	if err != nil {
 		// already a git/ssh url?
@@ -620,6 +701,14 @@ func (g *GitCLI) CreateAuthenticatedURL(cloneURL string, userAuth *auth.UserAuth
 	if u.Scheme == "file" {
 		return cloneURL, nil
 	}
+	if userAuth.IsGitHubApp() {
+		token, err := GitHubAppToken(u.Scheme+"://"+u.Host, userAuth)
+		if err != nil {
+			return "", errors.Wrap(err, "creating a GitHub App installation token")
+		}
+		u.User = url.UserPassword(auth.GitHubAppUsername, token)
+		return u.String(), nil
+	}
 	if userAuth.Username != "" || userAuth.ApiToken != "" {
 		u.User = url.UserPassword(userAuth.Username, userAuth.ApiToken)
 		return u.String(), nil
@@ -723,7 +812,7 @@ func (g *GitCLI) FetchBranchUnshallow(dir string, repo string, refspecs ...strin
 // FetchBranch fetches the refspecs from the repo
 func (g *GitCLI) fetchBranch(dir string, repo string, unshallow bool, shallow bool,
 	verbose bool, refspecs ...string) error {
-	args := []string{"fetch", repo}
+	args := []string{"fetch", g.SSH.SSHURL(repo)}
 	if shallow && unshallow {
 		return errors.Errorf("cannot use --depth=1 and --unshallow at the same time")
 	}
@@ -733,10 +822,13 @@ func (g *GitCLI) fetchBranch(dir string, repo string, unshallow bool, shallow bo
 	if unshallow {
 		args = append(args, "--unshallow")
 	}
+	if g.LargeRepo.Progress {
+		args = append(args, "--progress")
+	}
 	for _, refspec := range refspecs {
 		args = append(args, refspec)
 	}
-	err := g.gitCmd(dir, args...)
+	_, err := g.gitCmdWithEnvAndOutput(dir, g.largeRepoEnv(), args...)
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -765,6 +857,7 @@ func (g *GitCLI) GetAuthorEmailForCommit(dir string, sha string) (string, error)
 
 // SetRemoteURL sets the remote URL of the remote with the given name
 func (g *GitCLI) SetRemoteURL(dir string, name string, gitURL string) error {
+	gitURL = g.SSH.SSHURL(gitURL)
 	err := g.gitCmd(dir, "remote", "add", name, gitURL)
 	if err != nil {
 		err = g.gitCmd(dir, "remote", "set-url", name, gitURL)
@@ -0,0 +1,69 @@
+// +build unit
+
+package gits_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloneIsTracedWithTheContextSpan(t *testing.T) {
+	var exported struct {
+		ResourceSpans []struct {
+			ScopeSpans []struct {
+				Spans []struct {
+					SpanID       string `json:"spanId"`
+					ParentSpanID string `json:"parentSpanId"`
+					Name         string `json:"name"`
+				} `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &exported)
+	}))
+	defer collector.Close()
+
+	tracing.Configure(tracing.Config{Endpoint: collector.URL, ServiceName: tracing.DefaultServiceName})
+	defer tracing.Configure(tracing.Config{})
+
+	dir, err := ioutil.TempDir("", "test-clone-tracing-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, root := tracing.StartSpan(context.Background(), "jx import")
+	ctx, step := tracing.StartSpan(context.Background(), "clone the repository")
+	err = gits.WithContext(gits.NewGitCLI(), ctx).Clone("file://"+filepath.Join(dir, "does-not-exist"), filepath.Join(dir, "clone"))
+	assert.Error(t, err)
+	step.End(nil)
+	root.End(nil)
+	require.NoError(t, tracing.Flush())
+
+	require.Len(t, exported.ResourceSpans, 1)
+	spans := map[string]string{}
+	parents := map[string]string{}
+	for _, s := range exported.ResourceSpans[0].ScopeSpans[0].Spans {
+		spans[s.Name] = s.SpanID
+		parents[s.SpanID] = s.ParentSpanID
+	}
+	require.Contains(t, spans, "git clone")
+	require.True(t, len(spans) > 3, "the git commands of the clone should be traced")
+	assert.Equal(t, spans["clone the repository"], parents[spans["git clone"]], "the clone should be a child of the span in the context")
+	for name, id := range spans {
+		if name != "jx import" && name != "clone the repository" && name != "git clone" {
+			assert.Equal(t, spans["git clone"], parents[id], "the git command %s should be a child of the clone", name)
+		}
+	}
+}
@@ -206,7 +206,7 @@ func GetRepoName(batchMode, allowExistingRepo bool, provider GitProvider, defaul
 			}
 			return provider.ValidateRepositoryName(owner, str)
 		}
-		err := survey.AskOne(prompt, &repoName, validator, surveyOpts)
+		err := util.AskOne(prompt, &repoName, validator, surveyOpts)
 		if err != nil {
 			return "", err
 		}
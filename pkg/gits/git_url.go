@@ -119,6 +119,23 @@ func ParseGitURL(text string) (*GitRepository, error) {
 	answer := GitRepository{
 		URL: text,
 	}
+	if strings.HasPrefix(text, codeCommitGRCPrefix) {
+		// git-remote-codecommit URLs are of the form codecommit::<region>://[<profile>@]<repo>
+		region := CodeCommitRegion(text)
+		arr := strings.Split(text, "://")
+		if region != "" && len(arr) == 2 {
+			name := arr[1]
+			if i := strings.LastIndex(name, "@"); i >= 0 {
+				name = name[i+1:]
+			}
+			answer.Scheme = "https"
+			answer.Host = codeCommitHostPrefix + region + codeCommitHostSuffix
+			answer.Organisation = region
+			answer.Project = region
+			answer.Name = name
+			return &answer, nil
+		}
+	}
 	u, err := url.Parse(text)
 	if err == nil && u != nil {
 		answer.Host = u.Host
@@ -143,6 +160,15 @@ func ParseGitURL(text string) (*GitRepository, error) {
 		t = strings.TrimSuffix(t, ".git")
 
 		arr := util.RegexpSplit(t, ":|/")
+		if len(arr) >= 5 && arr[1] == "v3" && strings.HasSuffix(arr[0], azureDevOpsHost) {
+			// Azure DevOps URLs are of the form git@ssh.dev.azure.com:v3/<org>/<project>/<repo>
+			answer.Scheme = "git"
+			answer.Host = azureDevOpsHost
+			answer.Organisation = arr[2] + "/" + arr[3]
+			answer.Project = arr[3]
+			answer.Name = arr[len(arr)-1]
+			return &answer, nil
+		}
 		if len(arr) >= 3 {
 			answer.Scheme = "git"
 			answer.Host = arr[0]
@@ -194,6 +220,13 @@ func ParseGitOrganizationURL(text string) (*GitRepository, error) {
 }
 
 func parsePath(path string, info *GitRepository, requireRepo bool) (*GitRepository, error) {
+	// CodeCommit paths are of the form /v1/repos/<repo> and the repositories belong to the region
+	if region := CodeCommitRegion(info.Host); region != "" && strings.HasPrefix(path, "/v1/repos/") {
+		info.Organisation = region
+		info.Project = region
+		info.Name = strings.TrimSuffix(strings.TrimPrefix(path, "/v1/repos/"), "/")
+		return info, nil
+	}
 
 	// This is necessary for Bitbucket Server in some cases.
 	trimPath := strings.TrimPrefix(path, "/scm")
@@ -218,6 +251,13 @@ func parsePath(path string, info *GitRepository, requireRepo bool) (*GitReposito
 	trimPath = strings.TrimSuffix(trimPath, ".git")
 
 	arr := strings.Split(trimPath, "/")
+	if owner, name, ok := azureDevOpsOwnerAndName(info.Host, arr); ok {
+		// Azure DevOps paths are of the form /<org>/<project>/_git/<repo>
+		info.Organisation = owner
+		info.Project = arr[util.StringArrayIndex(arr, "_git")-1]
+		info.Name = name
+		return info, nil
+	}
 	if len(arr) >= 2 {
 		// We're assuming the beginning of the path is of the form /<org>/<repo> or /<org>/<subgroup>/.../<repo>
 		info.Organisation = arr[0]
@@ -255,6 +295,12 @@ func SaasGitKind(gitServiceUrl string) string {
 		if strings.HasPrefix(gitServiceUrl, "https://github") {
 			return KindGitHub
 		}
+		if IsAzureDevOpsServerURL(gitServiceUrl) {
+			return KindAzureDevOps
+		}
+		if IsCodeCommitServerURL(gitServiceUrl) {
+			return KindCodeCommit
+		}
 		return ""
 	}
 }
@@ -269,5 +315,24 @@ func HttpCloneURL(repo *GitRepository, kind string) string {
 		return util.UrlJoin(host, "scm", repo.Organisation, repo.Name) + ".git"
 
 	}
+	if kind == KindAzureDevOps {
+		host := repo.Host
+		if !strings.Contains(host, ":/") {
+			host = "https://" + host
+		}
+		owner := repo.Organisation
+		if strings.HasSuffix(repo.Host, azureDevOpsLegacyHost) {
+			// the organization is part of the host name
+			owner = repo.Project
+		}
+		return util.UrlJoin(host, owner, "_git", repo.Name)
+	}
+	if kind == KindCodeCommit {
+		host := repo.Host
+		if !strings.Contains(host, ":/") {
+			host = "https://" + host
+		}
+		return util.UrlJoin(host, "v1", "repos", repo.Name)
+	}
 	return repo.HttpsURL() + ".git"
 }
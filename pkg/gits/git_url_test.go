@@ -133,6 +133,27 @@ func TestParseGitURL(t *testing.T) {
 		{
 			"https://bitbucketserver.com/projects/myproject/repos/foo/pull-requests/1/overview", "bitbucketserver.com", "myproject", "foo",
 		},
+		{
+			"https://dev.azure.com/myorg/myproject/_git/foo", "dev.azure.com", "myorg/myproject", "foo",
+		},
+		{
+			"https://myuser@dev.azure.com/myorg/myproject/_git/foo/pullrequest/3", "dev.azure.com", "myorg/myproject", "foo",
+		},
+		{
+			"https://myorg.visualstudio.com/myproject/_git/foo", "myorg.visualstudio.com", "myorg/myproject", "foo",
+		},
+		{
+			"git@ssh.dev.azure.com:v3/myorg/myproject/foo", "dev.azure.com", "myorg/myproject", "foo",
+		},
+		{
+			"https://git-codecommit.us-east-1.amazonaws.com/v1/repos/foo", "git-codecommit.us-east-1.amazonaws.com", "us-east-1", "foo",
+		},
+		{
+			"ssh://git-codecommit.eu-west-1.amazonaws.com/v1/repos/foo", "git-codecommit.eu-west-1.amazonaws.com", "eu-west-1", "foo",
+		},
+		{
+			"codecommit::us-east-1://myprofile@foo", "git-codecommit.us-east-1.amazonaws.com", "us-east-1", "foo",
+		},
 	}
 	for _, data := range testCases {
 		info, err := gits.ParseGitURL(data.url)
@@ -158,6 +179,14 @@ func TestSaasKind(t *testing.T) {
 			gitURL: "https://github.test.com",
 			kind:   gits.KindGitHub,
 		},
+		"Azure DevOps": {
+			gitURL: "https://dev.azure.com/myorg",
+			kind:   gits.KindAzureDevOps,
+		},
+		"CodeCommit": {
+			gitURL: "https://git-codecommit.us-east-1.amazonaws.com",
+			kind:   gits.KindCodeCommit,
+		},
 	}
 
 	for name, tc := range tests {
@@ -224,6 +253,27 @@ func TestGitInfoHttpCloneURL(t *testing.T) {
 			kind:     gits.KindBitBucketServer,
 			expected: "https://bbs.something.com/scm/some-org/some-repo.git",
 		},
+		{
+			name: "azure devops",
+			gitInfo: &gits.GitRepository{
+				Name:         "some-repo",
+				Host:         "dev.azure.com",
+				Organisation: "some-org/some-project",
+				Project:      "some-project",
+			},
+			kind:     gits.KindAzureDevOps,
+			expected: "https://dev.azure.com/some-org/some-project/_git/some-repo",
+		},
+		{
+			name: "codecommit",
+			gitInfo: &gits.GitRepository{
+				Name:         "some-repo",
+				Host:         "git-codecommit.us-east-1.amazonaws.com",
+				Organisation: "us-east-1",
+			},
+			kind:     gits.KindCodeCommit,
+			expected: "https://git-codecommit.us-east-1.amazonaws.com/v1/repos/some-repo",
+		},
 		{
 			name: "no kind",
 			gitInfo: &gits.GitRepository{
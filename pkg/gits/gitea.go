@@ -1,7 +1,6 @@
 package gits
 
 import (
-	"errors"
 	"fmt"
 	"os"
 	"strconv"
@@ -17,6 +16,9 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/util"
 )
 
+// giteaWebHookEvents the events sent to the webhooks registered on Gitea repositories
+var giteaWebHookEvents = []string{"create", "push", "pull_request", "issue_comment"}
+
 type GiteaProvider struct {
 	Username string
 	Client   *gitea.Client
@@ -28,6 +30,7 @@ type GiteaProvider struct {
 
 func NewGiteaProvider(server *auth.AuthServer, user *auth.UserAuth, git Gitter) (GitProvider, error) {
 	client := gitea.NewClient(server.URL, user.ApiToken)
+	client.SetHTTPClient(NewAPIHTTPClient())
 
 	provider := GiteaProvider{
 		Client:   client,
@@ -92,7 +95,7 @@ func (p *GiteaProvider) ListReleases(org string, name string) ([]*GitRelease, er
 		return answer, err
 	}
 	for _, repo := range repos {
-		answer = append(answer, toGiteaRelease(org, name, repo))
+		answer = append(answer, p.toGiteaRelease(owner, name, repo))
 	}
 	return answer, nil
 }
@@ -111,24 +114,27 @@ func (p *GiteaProvider) GetRelease(org string, name string, tag string) (*GitRel
 	return nil, nil
 }
 
-func toGiteaRelease(org string, name string, release *gitea.Release) *GitRelease {
+func (p *GiteaProvider) toGiteaRelease(org string, name string, release *gitea.Release) *GitRelease {
 	totalDownloadCount := 0
 	assets := make([]GitReleaseAsset, 0)
 	for _, asset := range release.Attachments {
 		totalDownloadCount = totalDownloadCount + int(asset.DownloadCount)
 		assets = append(assets, GitReleaseAsset{
+			ID:                 asset.ID,
 			Name:               asset.Name,
 			BrowserDownloadURL: asset.DownloadURL,
 		})
 	}
 	return &GitRelease{
+		ID:            release.ID,
 		Name:          release.Title,
 		TagName:       release.TagName,
 		Body:          release.Note,
 		URL:           release.URL,
-		HTMLURL:       release.URL,
+		HTMLURL:       util.UrlJoin(p.Server.URL, org, name, "releases", "tag", release.TagName),
 		DownloadCount: totalDownloadCount,
 		Assets:        &assets,
+		PreRelease:    release.IsPrerelease,
 	}
 }
 
@@ -232,21 +238,14 @@ func (p *GiteaProvider) CreateWebHook(data *GitWebHookArguments) error {
 	for _, hook := range hooks {
 		s := hook.Config["url"]
 		if s == webhookUrl {
-			log.Logger().Warnf("Already has a webhook registered for %s", webhookUrl)
-			return nil
+			log.Logger().Infof("Updating the existing Gitea webhook for %s/%s for url %s", util.ColorInfo(owner), util.ColorInfo(repo), util.ColorInfo(webhookUrl))
+			return p.editWebHook(owner, repo, hook.ID, data)
 		}
 	}
-	config := map[string]string{
-		"url":          webhookUrl,
-		"content_type": "json",
-	}
-	if data.Secret != "" {
-		config["secret"] = data.Secret
-	}
 	hook := gitea.CreateHookOption{
 		Type:   "gitea",
-		Config: config,
-		Events: []string{"create", "push", "pull_request"},
+		Config: giteaWebHookConfig(data),
+		Events: giteaWebHookEvents,
 		Active: true,
 	}
 	log.Logger().Infof("Creating Gitea webhook for %s/%s for url %s", util.ColorInfo(owner), util.ColorInfo(repo), util.ColorInfo(webhookUrl))
@@ -257,13 +256,77 @@ func (p *GiteaProvider) CreateWebHook(data *GitWebHookArguments) error {
 	return err
 }
 
+// ListWebHooks lists the webhooks of the repository
 func (p *GiteaProvider) ListWebHooks(owner string, repo string) ([]*GitWebHookArguments, error) {
 	webHooks := []*GitWebHookArguments{}
-	return webHooks, fmt.Errorf("not implemented!")
+	hooks, err := p.Client.ListRepoHooks(owner, repo, gitea.ListHooksOptions{})
+	if err != nil {
+		return webHooks, err
+	}
+	for _, hook := range hooks {
+		webHooks = append(webHooks, &GitWebHookArguments{
+			ID:    hook.ID,
+			Owner: owner,
+			Repo: &GitRepository{
+				Organisation: owner,
+				Name:         repo,
+			},
+			URL: hook.Config["url"],
+		})
+	}
+	return webHooks, nil
 }
 
+// UpdateWebHook updates the webhook with the given ID or the one registered for the existing URL
 func (p *GiteaProvider) UpdateWebHook(data *GitWebHookArguments) error {
-	return fmt.Errorf("not implemented!")
+	owner := data.Owner
+	if owner == "" {
+		owner = p.Username
+	}
+	repo := data.Repo.Name
+	id := data.ID
+	if id == 0 && data.ExistingURL != "" {
+		hooks, err := p.Client.ListRepoHooks(owner, repo, gitea.ListHooksOptions{})
+		if err != nil {
+			return err
+		}
+		for _, hook := range hooks {
+			if hook.Config["url"] == data.ExistingURL {
+				log.Logger().Warnf("Found existing webhook for url %s", data.ExistingURL)
+				id = hook.ID
+			}
+		}
+	}
+	if id == 0 {
+		log.Logger().Warn("No webhooks found to update")
+		return nil
+	}
+	log.Logger().Infof("Updating Gitea webhook for %s/%s for url %s", util.ColorInfo(owner), util.ColorInfo(repo), util.ColorInfo(data.URL))
+	return p.editWebHook(owner, repo, id, data)
+}
+
+func (p *GiteaProvider) editWebHook(owner string, repo string, id int64, data *GitWebHookArguments) error {
+	active := true
+	err := p.Client.EditRepoHook(owner, repo, id, gitea.EditHookOption{
+		Config: giteaWebHookConfig(data),
+		Events: giteaWebHookEvents,
+		Active: &active,
+	})
+	if err != nil {
+		return errors2.Wrapf(err, "failed to update webhook %d for %s/%s", id, owner, repo)
+	}
+	return nil
+}
+
+func giteaWebHookConfig(data *GitWebHookArguments) map[string]string {
+	config := map[string]string{
+		"url":          data.URL,
+		"content_type": "json",
+	}
+	if data.Secret != "" {
+		config["secret"] = data.Secret
+	}
+	return config
 }
 
 func (p *GiteaProvider) CreatePullRequest(data *GitPullRequestArguments) (*GitPullRequest, error) {
@@ -305,7 +368,27 @@ func (p *GiteaProvider) CreatePullRequest(data *GitPullRequestArguments) (*GitPu
 
 // UpdatePullRequest updates pull request with number using data
 func (p *GiteaProvider) UpdatePullRequest(data *GitPullRequestArguments, number int) (*GitPullRequest, error) {
-	return nil, errors2.Errorf("Not yet implemented for gitea")
+	owner := data.GitRepository.Organisation
+	repo := data.GitRepository.Name
+	existing, err := p.Client.GetPullRequest(owner, repo, int64(number))
+	if err != nil {
+		return nil, errors2.Wrapf(err, "getting pull request %s/%s #%d", owner, repo, number)
+	}
+	config := gitea.EditPullRequestOption{
+		Title: existing.Title,
+		Body:  existing.Body,
+	}
+	if data.Title != "" {
+		config.Title = data.Title
+	}
+	if data.Body != "" {
+		config.Body = data.Body
+	}
+	pr, err := p.Client.EditPullRequest(owner, repo, int64(number), config)
+	if err != nil {
+		return nil, errors2.Wrapf(err, "updating pull request %s/%s #%d", owner, repo, number)
+	}
+	return p.toPullRequest(owner, repo, pr), nil
 }
 
 func (p *GiteaProvider) UpdatePullRequestStatus(pr *GitPullRequest) error {
@@ -333,6 +416,11 @@ func (p *GiteaProvider) updatePullRequest(pr *GitPullRequest, source *gitea.Pull
 	pr.MergeCommitSHA = source.MergedCommitID
 	pr.Title = source.Title
 	pr.Body = source.Body
+	pr.Labels = nil
+	for _, label := range source.Labels {
+		name := label.Name
+		pr.Labels = append(pr.Labels, &Label{Name: &name})
+	}
 	stateText := string(source.State)
 	pr.State = &stateText
 	head := source.Head
@@ -581,7 +669,7 @@ func (p *GiteaProvider) ListCommitStatus(org string, repo string, sha string) ([
 	}
 	for _, result := range results {
 		status := &GitRepoStatus{
-			ID:          string(result.ID),
+			ID:          strconv.FormatInt(result.ID, 10),
 			Context:     result.Context,
 			URL:         result.URL,
 			TargetURL:   result.TargetURL,
@@ -593,8 +681,25 @@ func (p *GiteaProvider) ListCommitStatus(org string, repo string, sha string) ([
 	return answer, nil
 }
 
-func (b *GiteaProvider) UpdateCommitStatus(org string, repo string, sha string, status *GitRepoStatus) (*GitRepoStatus, error) {
-	return &GitRepoStatus{}, errors.New("TODO")
+// UpdateCommitStatus creates the status of the context on the commit
+func (p *GiteaProvider) UpdateCommitStatus(org string, repo string, sha string, status *GitRepoStatus) (*GitRepoStatus, error) {
+	result, err := p.Client.CreateStatus(org, repo, sha, gitea.CreateStatusOption{
+		State:       gitea.StatusState(status.State),
+		TargetURL:   status.TargetURL,
+		Description: status.Description,
+		Context:     status.Context,
+	})
+	if err != nil {
+		return nil, errors2.Wrapf(err, "failed to update the status of commit %s of %s/%s", sha, org, repo)
+	}
+	return &GitRepoStatus{
+		ID:          strconv.FormatInt(result.ID, 10),
+		Context:     result.Context,
+		URL:         result.URL,
+		TargetURL:   result.TargetURL,
+		State:       string(result.State),
+		Description: result.Description,
+	}, nil
 }
 
 func (p *GiteaProvider) RenameRepository(org string, name string, newName string) (*GitRepository, error) {
@@ -615,6 +720,9 @@ func (p *GiteaProvider) ValidateRepositoryName(org string, name string) error {
 func (p *GiteaProvider) UpdateRelease(owner string, repo string, tag string, releaseInfo *GitRelease) error {
 	var release *gitea.Release
 	releases, err := p.Client.ListReleases(owner, repo, gitea.ListReleasesOptions{})
+	if err != nil {
+		return errors2.Wrapf(err, "getting releases for %s/%s", owner, repo)
+	}
 	found := false
 	for _, rel := range releases {
 		if rel.TagName == tag {
@@ -627,15 +735,26 @@ func (p *GiteaProvider) UpdateRelease(owner string, repo string, tag string, rel
 
 	// lets populate the release
 	if !found {
+		tagName := releaseInfo.TagName
+		if tagName == "" {
+			tagName = tag
+		}
 		createRelease := gitea.CreateReleaseOption{
-			TagName:      releaseInfo.TagName,
+			TagName:      tagName,
 			Title:        releaseInfo.Name,
 			Note:         releaseInfo.Body,
 			IsDraft:      flag,
 			IsPrerelease: flag,
 		}
-		_, err = p.Client.CreateRelease(owner, repo, createRelease)
-		return err
+		log.Logger().Infof("No release found for %s/%s and tag %s so creating a new release", owner, repo, tag)
+		r, err := p.Client.CreateRelease(owner, repo, createRelease)
+		if err != nil {
+			return err
+		}
+		releaseInfo.ID = r.ID
+		releaseInfo.URL = r.URL
+		releaseInfo.HTMLURL = util.UrlJoin(p.Server.URL, owner, repo, "releases", "tag", r.TagName)
+		return nil
 	} else {
 		editRelease := gitea.EditReleaseOption{
 			TagName:      release.TagName,
@@ -653,15 +772,17 @@ func (p *GiteaProvider) UpdateRelease(owner string, repo string, tag string, rel
 		if editRelease.Note == "" && releaseInfo.Body != "" {
 			editRelease.Note = releaseInfo.Body
 		}
+		releaseInfo.ID = release.ID
 		r2, err := p.Client.EditRelease(owner, repo, release.ID, editRelease)
 		if err != nil {
 			return err
 		}
 		if r2 != nil {
 			releaseInfo.URL = r2.URL
+			releaseInfo.HTMLURL = util.UrlJoin(p.Server.URL, owner, repo, "releases", "tag", r2.TagName)
 		}
 	}
-	return err
+	return nil
 }
 
 // UpdateReleaseStatus updates the state (release/prerelease) of a release
@@ -802,7 +923,34 @@ func (p *GiteaProvider) ListCommits(owner, repo string, opt *ListCommitsArgument
 
 // AddLabelsToIssue adds labels to issues or pullrequests
 func (p *GiteaProvider) AddLabelsToIssue(owner, repo string, number int, labels []string) error {
-	return fmt.Errorf("Getting content not supported on gitea")
+	repoLabels, err := p.Client.ListRepoLabels(owner, repo, gitea.ListLabelsOptions{})
+	if err != nil {
+		return errors2.Wrapf(err, "listing labels of %s/%s", owner, repo)
+	}
+	ids := []int64{}
+	for _, name := range labels {
+		var id int64
+		for _, label := range repoLabels {
+			if label.Name == name {
+				id = label.ID
+				break
+			}
+		}
+		if id == 0 {
+			// Gitea only adds labels which already exist in the repository
+			label, err := p.Client.CreateLabel(owner, repo, gitea.CreateLabelOption{
+				Name:  name,
+				Color: "#ededed",
+			})
+			if err != nil {
+				return errors2.Wrapf(err, "creating label %s in %s/%s", name, owner, repo)
+			}
+			id = label.ID
+		}
+		ids = append(ids, id)
+	}
+	_, err = p.Client.AddIssueLabels(owner, repo, int64(number), gitea.IssueLabelsOption{Labels: ids})
+	return err
 }
 
 // GetLatestRelease fetches the latest release from the git provider for org and name
@@ -811,7 +959,10 @@ func (p *GiteaProvider) GetLatestRelease(org string, name string) (*GitRelease,
 	if err != nil {
 		return nil, errors2.Wrapf(err, "getting releases for %s/%s", org, name)
 	}
-	return toGiteaRelease(org, name, releases[0]), nil
+	if len(releases) == 0 {
+		return nil, nil
+	}
+	return p.toGiteaRelease(org, name, releases[0]), nil
 }
 
 // UploadReleaseAsset will upload an asset to org/repo to a release with id, giving it a name, it will return the release asset from the git provider
@@ -0,0 +1,139 @@
+// +build unit
+
+package gits_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGitServer records the requests made to a git provider API and replies with the responses for their method and path
+type fakeGitServer struct {
+	t         *testing.T
+	responses map[string]string
+	requests  []string
+	bodies    map[string]map[string]interface{}
+}
+
+func (f *fakeGitServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Method + " " + r.URL.Path
+	f.requests = append(f.requests, key)
+	data, err := ioutil.ReadAll(r.Body)
+	require.NoError(f.t, err)
+	if len(data) > 0 {
+		body := map[string]interface{}{}
+		require.NoError(f.t, json.Unmarshal(data, &body), "body of %s", key)
+		f.bodies[key] = body
+	}
+	response, ok := f.responses[key]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write([]byte(response))
+	require.NoError(f.t, err)
+}
+
+func newFakeGiteaProvider(t *testing.T, responses map[string]string) (*fakeGitServer, *gits.GiteaProvider, func()) {
+	fake := &fakeGitServer{
+		t:         t,
+		responses: responses,
+		bodies:    map[string]map[string]interface{}{},
+	}
+	server := httptest.NewServer(fake)
+	provider, err := gits.NewGiteaProvider(&auth.AuthServer{URL: server.URL}, &auth.UserAuth{Username: "myuser", ApiToken: "test"}, nil)
+	require.NoError(t, err)
+	return fake, provider.(*gits.GiteaProvider), server.Close
+}
+
+func TestGiteaWebHooks(t *testing.T) {
+	t.Parallel()
+	fake, provider, closer := newFakeGiteaProvider(t, map[string]string{
+		"GET /api/v1/repos/myorg/myrepo/hooks":     `[{"id": 3, "type": "gitea", "config": {"url": "http://hook.jx.example.com/hook"}, "active": true}]`,
+		"POST /api/v1/repos/myorg/myrepo/hooks":    `{"id": 4, "type": "gitea", "config": {"url": "http://other.example.com/hook"}, "active": true}`,
+		"PATCH /api/v1/repos/myorg/myrepo/hooks/3": `{}`,
+	})
+	defer closer()
+
+	hooks, err := provider.ListWebHooks("myorg", "myrepo")
+	require.NoError(t, err)
+	require.Len(t, hooks, 1)
+	assert.Equal(t, int64(3), hooks[0].ID)
+	assert.Equal(t, "http://hook.jx.example.com/hook", hooks[0].URL)
+
+	repo := &gits.GitRepository{Name: "myrepo"}
+	err = provider.CreateWebHook(&gits.GitWebHookArguments{Owner: "myorg", Repo: repo, URL: "http://hook.jx.example.com/hook", Secret: "s3cr3t"})
+	require.NoError(t, err)
+	err = provider.CreateWebHook(&gits.GitWebHookArguments{Owner: "myorg", Repo: repo, URL: "http://other.example.com/hook"})
+	require.NoError(t, err)
+	assert.Contains(t, fake.requests, "POST /api/v1/repos/myorg/myrepo/hooks")
+	assert.Equal(t, "s3cr3t", fake.bodies["PATCH /api/v1/repos/myorg/myrepo/hooks/3"]["config"].(map[string]interface{})["secret"])
+	assert.Contains(t, fake.bodies["POST /api/v1/repos/myorg/myrepo/hooks"]["events"], "issue_comment")
+
+	err = provider.UpdateWebHook(&gits.GitWebHookArguments{Owner: "myorg", Repo: repo, URL: "http://hook.jx.example.com/new", ExistingURL: "http://hook.jx.example.com/hook"})
+	require.NoError(t, err)
+	assert.Equal(t, "http://hook.jx.example.com/new", fake.bodies["PATCH /api/v1/repos/myorg/myrepo/hooks/3"]["config"].(map[string]interface{})["url"])
+}
+
+func TestGiteaUpdateCommitStatus(t *testing.T) {
+	t.Parallel()
+	fake, provider, closer := newFakeGiteaProvider(t, map[string]string{
+		"POST /api/v1/repos/myorg/myrepo/statuses/abc123": `{"id": 12, "status": "pending", "context": "pr-build", "target_url": "http://jx.example.com/build/1", "description": "running"}`,
+	})
+	defer closer()
+
+	status, err := provider.UpdateCommitStatus("myorg", "myrepo", "abc123", &gits.GitRepoStatus{
+		State:       "pending",
+		Context:     "pr-build",
+		TargetURL:   "http://jx.example.com/build/1",
+		Description: "running",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "12", status.ID)
+	assert.Equal(t, "pending", status.State)
+	assert.Equal(t, "pending", fake.bodies["POST /api/v1/repos/myorg/myrepo/statuses/abc123"]["state"])
+}
+
+func TestGiteaUpdateRelease(t *testing.T) {
+	t.Parallel()
+	fake, provider, closer := newFakeGiteaProvider(t, map[string]string{
+		"GET /api/v1/repos/myorg/myrepo/releases":  `[]`,
+		"POST /api/v1/repos/myorg/myrepo/releases": `{"id": 7, "tag_name": "v1.0.0", "name": "1.0.0", "body": "the changelog"}`,
+	})
+	defer closer()
+
+	release := &gits.GitRelease{Name: "1.0.0", Body: "the changelog"}
+	err := provider.UpdateRelease("myorg", "myrepo", "v1.0.0", release)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), release.ID)
+	assert.Equal(t, provider.Server.URL+"/myorg/myrepo/releases/tag/v1.0.0", release.HTMLURL)
+	assert.Equal(t, "v1.0.0", fake.bodies["POST /api/v1/repos/myorg/myrepo/releases"]["tag_name"])
+
+	latest, err := provider.GetLatestRelease("myorg", "myrepo")
+	require.NoError(t, err)
+	assert.Nil(t, latest)
+}
+
+func TestGiteaAddLabelsToIssue(t *testing.T) {
+	t.Parallel()
+	fake, provider, closer := newFakeGiteaProvider(t, map[string]string{
+		"GET /api/v1/repos/myorg/myrepo/labels":           `[{"id": 1, "name": "lgtm"}]`,
+		"POST /api/v1/repos/myorg/myrepo/labels":          `{"id": 5, "name": "updatebot"}`,
+		"POST /api/v1/repos/myorg/myrepo/issues/3/labels": `[{"id": 1, "name": "lgtm"}, {"id": 5, "name": "updatebot"}]`,
+	})
+	defer closer()
+
+	err := provider.AddLabelsToIssue("myorg", "myrepo", 3, []string{"lgtm", "updatebot"})
+	require.NoError(t, err)
+	assert.Equal(t, "updatebot", fake.bodies["POST /api/v1/repos/myorg/myrepo/labels"]["name"])
+	assert.Equal(t, []interface{}{float64(1), float64(5)}, fake.bodies["POST /api/v1/repos/myorg/myrepo/issues/3/labels"]["labels"])
+}
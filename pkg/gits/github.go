@@ -46,10 +46,23 @@ func NewGitHubProvider(server *auth.AuthServer, user *auth.UserAuth, git Gitter)
 		Git:      git,
 	}
 
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: user.ApiToken},
-	)
-	tc := oauth2.NewClient(ctx, ts)
+	var ts oauth2.TokenSource
+	if user.IsGitHubApp() {
+		var err error
+		ts, err = GitHubAppTokenSource(server.URL, user)
+		if err != nil {
+			return nil, err
+		}
+		if provider.Username == "" {
+			provider.Username = auth.GitHubAppUsername
+			provider.User.Username = auth.GitHubAppUsername
+		}
+	} else {
+		ts = oauth2.StaticTokenSource(
+			&oauth2.Token{AccessToken: user.ApiToken},
+		)
+	}
+	tc := oauth2.NewClient(context.WithValue(ctx, oauth2.HTTPClient, NewAPIHTTPClient()), ts)
 
 	traceGitHubAPI := os.Getenv("TRACE_GITHUB_API")
 	if traceGitHubAPI == "1" || traceGitHubAPI == "on" {
@@ -70,7 +83,7 @@ func NewAnonymousGitHubProvider(server *auth.AuthServer, git Gitter) (GitProvide
 		Git:     git,
 	}
 
-	return newGitHubProviderFromOauthClient(nil, provider)
+	return newGitHubProviderFromOauthClient(NewAPIHTTPClient(), provider)
 }
 
 func newGitHubProviderFromOauthClient(tc *http.Client, provider GitHubProvider) (GitProvider, error) {
@@ -1351,7 +1364,7 @@ func GitHubAccessTokenURL(url string) string {
 	if strings.Index(url, "://") < 0 {
 		url = "https://" + url
 	}
-	return util.UrlJoin(url, "/settings/tokens/new?scopes=repo,read:user,read:org,user:email,write:repo_hook,delete_repo")
+	return util.UrlJoin(url, "/settings/tokens/new?scopes=repo,read:user,read:org,user:email,admin:repo_hook,delete_repo")
 }
 
 func (p *GitHubProvider) Label() string {
@@ -1411,7 +1424,7 @@ func (p *GitHubProvider) AcceptInvitation(ID int64) (*github.Response, error) {
 // ShouldForkForPullRequest returns true if we should create a personal fork of this repository
 // before creating a pull request
 func (p *GitHubProvider) ShouldForkForPullRequest(originalOwner string, repoName string, username string) bool {
-	if strings.HasSuffix(username, "[bot]") || originalOwner == username {
+	if strings.HasSuffix(username, "[bot]") || originalOwner == username || p.User.IsGitHubApp() {
 		return false
 	}
 
@@ -0,0 +1,212 @@
+package gits
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// gitHubAppJWTExpiry how long the JWT used to authenticate as the GitHub App is valid for; GitHub allows up to 10 minutes
+	gitHubAppJWTExpiry = 9 * time.Minute
+	// gitHubAppClockSkew allows for clock drift between this machine and the GitHub server
+	gitHubAppClockSkew = 60 * time.Second
+)
+
+var (
+	gitHubAppTokenSources     = map[string]oauth2.TokenSource{}
+	gitHubAppTokenSourcesLock sync.Mutex
+)
+
+// GitHubAppTokenSource returns a token source which creates installation access tokens for the GitHub App of
+// the given user, refreshing them automatically before they expire. The token sources are shared per server,
+// app and owner so that every provider and git operation reuses the same cached token.
+func GitHubAppTokenSource(serverURL string, user *auth.UserAuth) (oauth2.TokenSource, error) {
+	if !user.IsGitHubApp() {
+		return nil, fmt.Errorf("user %q is not configured with a GitHub App ID and private key", user.Username)
+	}
+	key := fmt.Sprintf("%s/%d/%s", strings.TrimSuffix(serverURL, "/"), user.GithubAppID, user.GithubAppOwner)
+
+	gitHubAppTokenSourcesLock.Lock()
+	defer gitHubAppTokenSourcesLock.Unlock()
+
+	if ts, ok := gitHubAppTokenSources[key]; ok {
+		return ts, nil
+	}
+	privateKey, err := parseGitHubAppPrivateKey(user.GithubAppPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	ts := oauth2.ReuseTokenSource(nil, &gitHubAppInstallationTokenSource{
+		serverURL:  serverURL,
+		appID:      user.GithubAppID,
+		owner:      user.GithubAppOwner,
+		privateKey: privateKey,
+	})
+	gitHubAppTokenSources[key] = ts
+	return ts, nil
+}
+
+// GitHubAppToken returns a valid installation access token for the GitHub App of the given user
+func GitHubAppToken(serverURL string, user *auth.UserAuth) (string, error) {
+	ts, err := GitHubAppTokenSource(serverURL, user)
+	if err != nil {
+		return "", err
+	}
+	token, err := ts.Token()
+	if err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+// gitHubAppInstallationTokenSource creates a new installation access token each time it is invoked
+type gitHubAppInstallationTokenSource struct {
+	serverURL      string
+	appID          int64
+	owner          string
+	privateKey     *rsa.PrivateKey
+	installationID int64
+}
+
+// Token creates a new installation access token for the GitHub App
+func (s *gitHubAppInstallationTokenSource) Token() (*oauth2.Token, error) {
+	ctx := context.Background()
+	client, err := s.appClient()
+	if err != nil {
+		return nil, err
+	}
+	if s.installationID == 0 {
+		s.installationID, err = s.findInstallationID(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+	}
+	token, _, err := client.Apps.CreateInstallationToken(ctx, s.installationID, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating an access token for installation %d of GitHub App %d", s.installationID, s.appID)
+	}
+	answer := &oauth2.Token{
+		AccessToken: token.GetToken(),
+		TokenType:   "token",
+	}
+	if token.ExpiresAt != nil {
+		answer.Expiry = *token.ExpiresAt
+	}
+	return answer, nil
+}
+
+// findInstallationID finds the installation of the GitHub App for the owner or, if there is no owner,
+// the only installation of the app
+func (s *gitHubAppInstallationTokenSource) findInstallationID(ctx context.Context, client *github.Client) (int64, error) {
+	if s.owner != "" {
+		installation, _, err := client.Apps.FindOrganizationInstallation(ctx, s.owner)
+		if err != nil {
+			installation, _, err = client.Apps.FindUserInstallation(ctx, s.owner)
+			if err != nil {
+				return 0, errors.Wrapf(err, "finding the installation of GitHub App %d for owner %s", s.appID, s.owner)
+			}
+		}
+		return installation.GetID(), nil
+	}
+	installations, _, err := client.Apps.ListInstallations(ctx, &github.ListOptions{PerPage: pageSize})
+	if err != nil {
+		return 0, errors.Wrapf(err, "listing the installations of GitHub App %d", s.appID)
+	}
+	if len(installations) != 1 {
+		return 0, fmt.Errorf("GitHub App %d has %d installations so an owner must be specified", s.appID, len(installations))
+	}
+	return installations[0].GetID(), nil
+}
+
+// appClient creates a GitHub client which authenticates as the GitHub App itself
+func (s *gitHubAppInstallationTokenSource) appClient() (*github.Client, error) {
+	tc := &http.Client{
+		Transport: &gitHubAppTransport{source: s},
+	}
+	if IsGitHubServerURL(s.serverURL) {
+		return github.NewClient(tc), nil
+	}
+	u := GitHubEnterpriseApiEndpointURL(s.serverURL)
+	return github.NewEnterpriseClient(u, u, tc)
+}
+
+// gitHubAppTransport adds a freshly signed JWT to each request made as the GitHub App
+type gitHubAppTransport struct {
+	source *gitHubAppInstallationTokenSource
+}
+
+// RoundTrip authenticates the request with the JWT of the GitHub App
+func (t *gitHubAppTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	jwt, err := CreateGitHubAppJWT(t.source.appID, t.source.privateKey, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", "Bearer "+jwt)
+	r.Header.Set("Accept", "application/vnd.github.machine-man-preview+json")
+	return NewAPIHTTPClient().Transport.RoundTrip(r)
+}
+
+// CreateGitHubAppJWT creates the RS256 signed JSON Web Token used to authenticate as the GitHub App
+func CreateGitHubAppJWT(appID int64, privateKey *rsa.PrivateKey, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+	})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]int64{
+		"iat": now.Add(-gitHubAppClockSkew).Unix(),
+		"exp": now.Add(gitHubAppJWTExpiry).Unix(),
+		"iss": appID,
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hashed := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, hashed[:])
+	if err != nil {
+		return "", errors.Wrap(err, "signing the GitHub App JWT")
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parseGitHubAppPrivateKey parses the PEM encoded RSA private key generated for a GitHub App
+func parseGitHubAppPrivateKey(text string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(text))
+	if block == nil {
+		return nil, errors.New("the GitHub App private key is not PEM encoded")
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing the GitHub App private key")
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("the GitHub App private key is not an RSA key")
+	}
+	return key, nil
+}
@@ -0,0 +1,120 @@
+// +build unit
+
+package gits_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newGitHubAppKey(t *testing.T) (*rsa.PrivateKey, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	text := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return key, string(text)
+}
+
+func verifyGitHubAppJWT(t *testing.T, jwt string, key *rsa.PrivateKey) map[string]int64 {
+	parts := strings.Split(jwt, ".")
+	require.Len(t, parts, 3)
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	hashed := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	require.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, hashed[:], signature))
+
+	data, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	claims := map[string]int64{}
+	require.NoError(t, json.Unmarshal(data, &claims))
+	return claims
+}
+
+func TestCreateGitHubAppJWT(t *testing.T) {
+	t.Parallel()
+	key, _ := newGitHubAppKey(t)
+	now := time.Now()
+
+	jwt, err := gits.CreateGitHubAppJWT(1234, key, now)
+	require.NoError(t, err)
+
+	claims := verifyGitHubAppJWT(t, jwt, key)
+	assert.Equal(t, int64(1234), claims["iss"])
+	assert.Equal(t, now.Add(-time.Minute).Unix(), claims["iat"])
+	assert.Equal(t, now.Add(9*time.Minute).Unix(), claims["exp"])
+}
+
+func TestGitHubAppToken(t *testing.T) {
+	t.Parallel()
+	key, keyText := newGitHubAppKey(t)
+
+	var lock sync.Mutex
+	tokens := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		claims := verifyGitHubAppJWT(t, auth, key)
+		assert.Equal(t, int64(5678), claims["iss"])
+
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v3/orgs/myorg/installation":
+			fmt.Fprint(w, `{"id": 42}`)
+		case "POST /api/v3/app/installations/42/access_tokens":
+			lock.Lock()
+			tokens++
+			token := fmt.Sprintf("token-%d", tokens)
+			lock.Unlock()
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"token": %q, "expires_at": %q}`, token, time.Now().Add(time.Hour).Format(time.RFC3339))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	user := &auth.UserAuth{
+		GithubAppID:         5678,
+		GithubAppPrivateKey: keyText,
+		GithubAppOwner:      "myorg",
+	}
+	token, err := gits.GitHubAppToken(server.URL, user)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+
+	// the token is cached until it expires
+	token, err = gits.GitHubAppToken(server.URL, user)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+
+	git := gits.NewGitCLI()
+	u, err := git.CreateAuthenticatedURL(server.URL+"/myorg/myrepo.git", user)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Replace(server.URL, "://", "://"+auth.GitHubAppUsername+":token-1@", 1)+"/myorg/myrepo.git", u)
+
+	provider, err := gits.NewGitHubProvider(&auth.AuthServer{URL: server.URL, Kind: gits.KindGitHub}, user, git)
+	require.NoError(t, err)
+	assert.Equal(t, auth.GitHubAppUsername, provider.CurrentUsername())
+	assert.False(t, provider.ShouldForkForPullRequest("myorg", "myrepo", auth.GitHubAppUsername))
+}
+
+func TestGitHubAppTokenWithoutApp(t *testing.T) {
+	t.Parallel()
+	_, err := gits.GitHubAppToken("https://github.com", &auth.UserAuth{Username: "test", ApiToken: "test"})
+	assert.Error(t, err)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/jenkins-x/jx-logging/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/auth"
 	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
 	"github.com/xanzy/go-gitlab"
 )
 
@@ -30,7 +31,7 @@ type GitlabProvider struct {
 
 func NewGitlabProvider(server *auth.AuthServer, user *auth.UserAuth, git Gitter) (GitProvider, error) {
 	u := server.URL
-	c := gitlab.NewClient(nil, user.ApiToken)
+	c := gitlab.NewClient(NewAPIHTTPClient(), user.ApiToken)
 	if !IsGitLabServerURL(u) {
 		if err := c.SetBaseURL(u); err != nil {
 			return nil, err
@@ -44,6 +45,28 @@ func IsGitLabServerURL(u string) bool {
 	return u == "" || u == "https://gitlab.com" || u == "http://gitlab.com"
 }
 
+// ValidateGitlabServer checks that the server is a GitLab server (either gitlab.com or a self-managed
+// installation) and that the API token authenticates the given user
+func ValidateGitlabServer(server *auth.AuthServer, user *auth.UserAuth) error {
+	provider, err := NewGitlabProvider(server, user, nil)
+	if err != nil {
+		return errors.Wrapf(err, "creating GitLab client for %s", server.URL)
+	}
+	return provider.(*GitlabProvider).ValidateCredentials()
+}
+
+// ValidateCredentials checks the API token of the provider authenticates its user
+func (g *GitlabProvider) ValidateCredentials() error {
+	current, _, err := g.Client.Users.CurrentUser()
+	if err != nil {
+		return errors.Wrapf(err, "failed to query the current user of the GitLab server %s, is the URL a GitLab server and the API token valid?", g.ServerURL())
+	}
+	if g.Username != "" && !strings.EqualFold(current.Username, g.Username) {
+		return fmt.Errorf("the API token of the GitLab server %s belongs to user %s rather than %s", g.ServerURL(), current.Username, g.Username)
+	}
+	return nil
+}
+
 // Used by unit tests to inject a mocked client
 func WithGitlabClient(server *auth.AuthServer, user *auth.UserAuth, client *gitlab.Client, git Gitter) (GitProvider, error) {
 	provider := &GitlabProvider{
@@ -69,15 +92,65 @@ func (g *GitlabProvider) ListRepositories(org string) ([]*GitRepository, error)
 	return repos, nil
 }
 
+// ListReleases lists the releases of the project
 func (g *GitlabProvider) ListReleases(org string, name string) ([]*GitRelease, error) {
 	answer := []*GitRelease{}
-	// TODO
+	pid, err := g.projectId(org, g.Username, name)
+	if err != nil {
+		return answer, err
+	}
+	opt := &gitlab.ListReleasesOptions{
+		Page:    1,
+		PerPage: pageSize,
+	}
+	for {
+		releases, _, err := g.Client.Releases.ListReleases(pid, opt)
+		if err != nil {
+			return answer, err
+		}
+		for _, release := range releases {
+			answer = append(answer, g.fromGitlabRelease(org, name, release))
+		}
+		if len(releases) < pageSize || len(releases) == 0 {
+			break
+		}
+		opt.Page++
+	}
 	return answer, nil
 }
 
 // GetRelease returns the release info for the org, repo name and tag
 func (g *GitlabProvider) GetRelease(org string, name string, tag string) (*GitRelease, error) {
-	return nil, nil
+	pid, err := g.projectId(org, g.Username, name)
+	if err != nil {
+		return nil, err
+	}
+	release, _, err := g.Client.Releases.GetRelease(pid, tag)
+	if err != nil {
+		return nil, err
+	}
+	return g.fromGitlabRelease(org, name, release), nil
+}
+
+func (g *GitlabProvider) fromGitlabRelease(org string, name string, release *gitlab.Release) *GitRelease {
+	assets := []GitReleaseAsset{}
+	for _, link := range release.Assets.Links {
+		if link == nil {
+			continue
+		}
+		assets = append(assets, GitReleaseAsset{
+			ID:                 int64(link.ID),
+			Name:               link.Name,
+			BrowserDownloadURL: link.URL,
+		})
+	}
+	return &GitRelease{
+		Name:    release.Name,
+		TagName: release.TagName,
+		Body:    release.Description,
+		HTMLURL: util.UrlJoin(g.ServerURL(), org, name, "-", "releases", release.TagName),
+		Assets:  &assets,
+	}
 }
 
 func getRepositories(g *gitlab.Client, username string, org string, searchFilter string) ([]*gitlab.Project, *gitlab.Response, error) {
@@ -193,6 +266,12 @@ func (g *GitlabProvider) ListOrganisations() ([]GitOrganisation, error) {
 }
 
 func (g *GitlabProvider) projectId(org, username, name string) (string, error) {
+	// projects the user is a member of but does not own are only found via their path
+	project, _, err := g.Client.Projects.GetProject(owner(org, username)+"/"+name, nil)
+	if err == nil && project != nil && project.Name == name {
+		return strconv.Itoa(project.ID), nil
+	}
+
 	repos, _, err := getRepositories(g.Client, username, org, name)
 	if err != nil {
 		return "", err
@@ -368,9 +447,36 @@ func (g *GitlabProvider) UpdatePullRequestStatus(pr *GitPullRequest) error {
 	}
 
 	*pr = *fromMergeRequest(mr, owner, repo)
+	g.addApprovals(pid, pr)
 	return nil
 }
 
+// addApprovals adds the approval rules of the merge request to the pull request: the approvers who have still
+// to approve become the requested reviewers and the ApprovedLabel is added once no more approvals are required.
+// Merge request approvals are not available on every GitLab edition so any error is ignored
+func (g *GitlabProvider) addApprovals(pid string, pr *GitPullRequest) {
+	approvals, _, err := g.Client.MergeRequests.GetMergeRequestApprovals(pid, *pr.Number)
+	if err != nil || approvals == nil {
+		return
+	}
+	approved := map[string]bool{}
+	for _, approver := range approvals.ApprovedBy {
+		if approver != nil && approver.User != nil {
+			approved[approver.User.Username] = true
+		}
+	}
+	pr.RequestedReviewers = nil
+	for _, approver := range approvals.Approvers {
+		if approver != nil && approver.User != nil && !approved[approver.User.Username] {
+			pr.RequestedReviewers = append(pr.RequestedReviewers, convertUser(approver.User))
+		}
+	}
+	if approvals.ApprovalsLeft == 0 && (approvals.ApprovalsRequired > 0 || len(approved) > 0) {
+		label := ApprovedLabel
+		pr.Labels = append(pr.Labels, &Label{Name: &label})
+	}
+}
+
 // GetPullRequest gets a PR
 func (g *GitlabProvider) GetPullRequest(owner string, repo *GitRepository, number int) (*GitPullRequest, error) {
 	pr := &GitPullRequest{
@@ -549,27 +655,66 @@ func (g *GitlabProvider) MergePullRequest(pr *GitPullRequest, message string) er
 	return err
 }
 
+// CreateWebHook creates the webhook on the project, updating any existing webhook for the same URL rather than
+// registering it twice
 func (g *GitlabProvider) CreateWebHook(data *GitWebHookArguments) error {
 	pid, err := g.projectId(data.Owner, g.Username, data.Repo.Name)
 	if err != nil {
-		return nil
+		return err
+	}
+
+	webhookURL := g.webHookURL(data)
+	hooks, _, err := g.Client.Projects.ListProjectHooks(pid, &gitlab.ListProjectHooksOptions{})
+	if err != nil {
+		return err
+	}
+	for _, hook := range hooks {
+		if hook.URL == webhookURL {
+			log.Logger().Infof("Updating existing GitLab webhook for %s/%s for url %s", util.ColorInfo(owner(data.Owner, g.Username)), util.ColorInfo(data.Repo.Name), util.ColorInfo(webhookURL))
+			return g.editWebHook(pid, hook.ID, webhookURL, data)
+		}
 	}
 
 	flag := true
-	owner := owner(data.Owner, g.Username)
-	webhookURL := util.UrlJoin(data.URL, owner, data.Repo.Name)
+	sslVerification := !data.InsecureSSL
 	opt := &gitlab.AddProjectHookOptions{
-		URL:                 &webhookURL,
-		Token:               &data.Secret,
-		PushEvents:          &flag,
-		MergeRequestsEvents: &flag,
-		IssuesEvents:        &flag,
-		NoteEvents:          &flag,
+		URL:                   &webhookURL,
+		Token:                 &data.Secret,
+		PushEvents:            &flag,
+		MergeRequestsEvents:   &flag,
+		IssuesEvents:          &flag,
+		NoteEvents:            &flag,
+		EnableSSLVerification: &sslVerification,
 	}
 	_, _, err = g.Client.Projects.AddProjectHook(pid, opt)
 	return err
 }
 
+// webHookURL returns the URL of the webhook. Jenkins expects the project to be appended to its GitLab webhook
+// path whereas hook endpoints such as lighthouse and prow use the URL as it is
+func (g *GitlabProvider) webHookURL(data *GitWebHookArguments) string {
+	if strings.HasSuffix(strings.TrimSuffix(data.URL, "/"), g.JenkinsWebHookPath("", "")) {
+		return util.UrlJoin(data.URL, owner(data.Owner, g.Username), data.Repo.Name)
+	}
+	return data.URL
+}
+
+func (g *GitlabProvider) editWebHook(pid string, id int, webhookURL string, data *GitWebHookArguments) error {
+	flag := true
+	sslVerification := !data.InsecureSSL
+	opt := &gitlab.EditProjectHookOptions{
+		URL:                   &webhookURL,
+		Token:                 &data.Secret,
+		PushEvents:            &flag,
+		MergeRequestsEvents:   &flag,
+		IssuesEvents:          &flag,
+		NoteEvents:            &flag,
+		EnableSSLVerification: &sslVerification,
+	}
+	_, _, err := g.Client.Projects.EditProjectHook(pid, id, opt)
+	return err
+}
+
 // ListWebHooks lists the webhooks
 func (g *GitlabProvider) ListWebHooks(owner string, repo string) ([]*GitWebHookArguments, error) {
 	answer := []*GitWebHookArguments{}
@@ -597,24 +742,30 @@ func gitLabToGitHook(owner string, repo string, hook *gitlab.ProjectHook) *GitWe
 	}
 }
 
+// UpdateWebHook updates the webhook with the given ID or the one registered for the existing URL
 func (g *GitlabProvider) UpdateWebHook(data *GitWebHookArguments) error {
 	pid, err := g.projectId(data.Owner, g.Username, data.Repo.Name)
 	if err != nil {
+		return err
+	}
+	id := int(data.ID)
+	if id == 0 && data.ExistingURL != "" {
+		hooks, _, err := g.Client.Projects.ListProjectHooks(pid, &gitlab.ListProjectHooksOptions{})
+		if err != nil {
+			return err
+		}
+		for _, hook := range hooks {
+			if hook.URL == data.ExistingURL {
+				log.Logger().Warnf("Found existing webhook for url %s", data.ExistingURL)
+				id = hook.ID
+			}
+		}
+	}
+	if id == 0 {
+		log.Logger().Warn("No webhooks found to update")
 		return nil
 	}
-	flag := true
-	owner := owner(data.Owner, g.Username)
-	webhookURL := util.UrlJoin(data.URL, owner, data.Repo.Name)
-	opt := &gitlab.EditProjectHookOptions{
-		URL:                 &webhookURL,
-		Token:               &data.Secret,
-		PushEvents:          &flag,
-		MergeRequestsEvents: &flag,
-		IssuesEvents:        &flag,
-		NoteEvents:          &flag,
-	}
-	_, _, err = g.Client.Projects.EditProjectHook(pid, int(data.ID), opt)
-	return err
+	return g.editWebHook(pid, id, g.webHookURL(data), data)
 }
 
 func (g *GitlabProvider) SearchIssues(org, repo, query string) ([]*GitIssue, error) {
@@ -722,7 +873,7 @@ func (g *GitlabProvider) AddPRComment(pr *GitPullRequest, comment string) error
 
 	pid, err := g.projectId(owner, g.Username, repo)
 	if err != nil {
-		return nil
+		return err
 	}
 	_, _, err = g.Client.Notes.CreateMergeRequestNote(pid, *pr.Number, opt)
 	return err
@@ -823,8 +974,36 @@ func (g *GitlabProvider) UserInfo(username string) *GitUser {
 	}
 }
 
+// UpdateRelease creates the release for the tag or updates its name and description if it already exists
 func (g *GitlabProvider) UpdateRelease(owner string, repo string, tag string, releaseInfo *GitRelease) error {
-	return nil
+	pid, err := g.projectId(owner, g.Username, repo)
+	if err != nil {
+		return err
+	}
+	name := releaseInfo.Name
+	if name == "" {
+		name = tag
+	}
+	description := releaseInfo.Body
+	release, resp, err := g.Client.Releases.GetRelease(pid, tag)
+	if err != nil && (resp == nil || resp.StatusCode != 404) {
+		return err
+	}
+	if err != nil || release == nil {
+		opt := &gitlab.CreateReleaseOptions{
+			Name:        &name,
+			TagName:     &tag,
+			Description: &description,
+		}
+		_, _, err = g.Client.Releases.CreateRelease(pid, opt)
+		return err
+	}
+	opt := &gitlab.UpdateReleaseOptions{
+		Name:        &name,
+		Description: &description,
+	}
+	_, _, err = g.Client.Releases.UpdateRelease(pid, tag, opt)
+	return err
 }
 
 // UpdateReleaseStatus is not supported for this git provider
@@ -834,7 +1013,11 @@ func (g *GitlabProvider) UpdateReleaseStatus(owner string, repo string, tag stri
 
 // IssueURL returns the URL of the issue
 func (g *GitlabProvider) IssueURL(org string, name string, number int, isPull bool) string {
-	return ""
+	kind := "issues"
+	if isPull {
+		kind = "merge_requests"
+	}
+	return util.UrlJoin(g.ServerURL(), owner(org, g.Username), name, "-", kind, strconv.Itoa(number))
 }
 
 // AddCollaborator adds a collaborator
@@ -937,8 +1120,19 @@ func (g *GitlabProvider) AddLabelsToIssue(owner, repo string, number int, labels
 
 // GetLatestRelease fetches the latest release from the git provider for org and name
 func (g *GitlabProvider) GetLatestRelease(org string, name string) (*GitRelease, error) {
-	// TODO
-	return nil, nil
+	pid, err := g.projectId(org, g.Username, name)
+	if err != nil {
+		return nil, err
+	}
+	// releases are returned most recently released first
+	releases, _, err := g.Client.Releases.ListReleases(pid, &gitlab.ListReleasesOptions{Page: 1, PerPage: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(releases) == 0 {
+		return nil, nil
+	}
+	return g.fromGitlabRelease(org, name, releases[0]), nil
 }
 
 // UploadReleaseAsset will upload an asset to org/repo to a release with id, giving it a name, it will return the release asset from the git provider
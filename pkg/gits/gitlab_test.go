@@ -5,6 +5,7 @@ package gits_test
 import (
 	"testing"
 
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -31,6 +32,8 @@ type GitlabProviderSuite struct {
 	mux      *http.ServeMux
 	server   *httptest.Server
 	provider *gits.GitlabProvider
+	// hookRequests the methods and URLs of the webhooks added or edited
+	hookRequests []string
 }
 
 func (suite *GitlabProviderSuite) SetupSuite() {
@@ -114,10 +117,44 @@ func configureGitlabMock(suite *GitlabProviderSuite, mux *http.ServeMux) {
 		fmt.Sprintf("/api/v4/users"): util.MethodMap{
 			"GET": "list-users.json",
 		},
+		"/api/v4/user": util.MethodMap{
+			"GET": "current-user.json",
+		},
+		fmt.Sprintf("/api/v4/projects/%s/merge_requests/%d/approvals", gitlabProjectID, gitlabMergeRequestID): util.MethodMap{
+			"GET": "merge-request-approvals.json",
+		},
+		fmt.Sprintf("/api/v4/projects/%s/releases", gitlabProjectID): util.MethodMap{
+			"GET": "releases.json",
+		},
+		fmt.Sprintf("/api/v4/projects/%s/releases/v1.0.0", gitlabProjectID): util.MethodMap{
+			"GET": "release.json",
+			"PUT": "update-release.json",
+		},
 	}
 	for path, methodMap := range gitlabRouter {
 		mux.HandleFunc(path, util.GetMockAPIResponseFromFile("test_data/gitlab", methodMap))
 	}
+
+	recordHook := func(w http.ResponseWriter, r *http.Request) {
+		hook := gitlab.ProjectHook{}
+		err := json.NewDecoder(r.Body).Decode(&hook)
+		suite.Require().Nil(err)
+		suite.hookRequests = append(suite.hookRequests, r.Method+" "+hook.URL)
+
+		src, err := ioutil.ReadFile("test_data/gitlab/project-hook.json")
+		suite.Require().Nil(err)
+		w.Write(src)
+	}
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/hooks", gitlabProjectID), func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			recordHook(w, r)
+			return
+		}
+		src, err := ioutil.ReadFile("test_data/gitlab/project-hooks.json")
+		suite.Require().Nil(err)
+		w.Write(src)
+	})
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/hooks/1", gitlabProjectID), recordHook)
 }
 
 func (suite *GitlabProviderSuite) TestListOrganizations() {
@@ -236,6 +273,87 @@ func (suite *GitlabProviderSuite) TestUpdatePullRequest() {
 	suite.Require().Equal(pr.Owner, gitlabUserName)
 }
 
+func (suite *GitlabProviderSuite) TestReleases() {
+	require := suite.Require()
+	releases, err := suite.provider.ListReleases(gitlabOrgName, gitlabProjectName)
+	require.Nil(err)
+	require.Len(releases, 2)
+	require.Equal("v1.1.0", releases[0].TagName)
+	require.Equal("## Changes\n\n* fix the widget", releases[0].Body)
+	require.Equal(suite.server.URL+"/testorg/test-project/-/releases/v1.1.0", releases[0].HTMLURL)
+	require.Len(*releases[0].Assets, 1)
+	require.Equal("widget-linux-amd64.tar.gz", (*releases[0].Assets)[0].Name)
+
+	release, err := suite.provider.GetRelease(gitlabOrgName, gitlabProjectName, "v1.0.0")
+	require.Nil(err)
+	require.Equal("Release 1.0.0", release.Name)
+
+	latest, err := suite.provider.GetLatestRelease(gitlabOrgName, gitlabProjectName)
+	require.Nil(err)
+	require.Equal("v1.1.0", latest.TagName)
+
+	err = suite.provider.UpdateRelease(gitlabOrgName, gitlabProjectName, "v1.0.0", &gits.GitRelease{
+		Body: "## Changes\n\n* the changelog",
+	})
+	require.Nil(err)
+}
+
+func (suite *GitlabProviderSuite) TestUpdatePullRequestStatusApprovals() {
+	number := gitlabMergeRequestID
+	pr := &gits.GitPullRequest{
+		Owner:  gitlabUserName,
+		Repo:   gitlabProjectName,
+		Number: &number,
+	}
+	err := suite.provider.UpdatePullRequestStatus(pr)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(pr.RequestedReviewers, 1)
+	suite.Require().Equal("jane_doe", pr.RequestedReviewers[0].Login)
+	for _, label := range pr.Labels {
+		suite.Require().NotEqual(gits.ApprovedLabel, *label.Name)
+	}
+}
+
+func (suite *GitlabProviderSuite) TestCreateWebHook() {
+	suite.hookRequests = nil
+	repo := &gits.GitRepository{Name: gitlabProjectName}
+
+	err := suite.provider.CreateWebHook(&gits.GitWebHookArguments{
+		Owner:  gitlabOrgName,
+		Repo:   repo,
+		URL:    "http://hook.jx.example.com/hook",
+		Secret: "secret",
+	})
+	suite.Require().Nil(err)
+
+	err = suite.provider.CreateWebHook(&gits.GitWebHookArguments{
+		Owner:  gitlabOrgName,
+		Repo:   repo,
+		URL:    "http://jenkins.jx.example.com/project",
+		Secret: "secret",
+	})
+	suite.Require().Nil(err)
+
+	suite.Require().Equal([]string{
+		"PUT http://hook.jx.example.com/hook",
+		"POST http://jenkins.jx.example.com/project/testorg/test-project",
+	}, suite.hookRequests)
+}
+
+func (suite *GitlabProviderSuite) TestIssueURL() {
+	suite.Require().Equal(suite.server.URL+"/testorg/test-project/-/issues/3", suite.provider.IssueURL(gitlabOrgName, gitlabProjectName, 3, false))
+	suite.Require().Equal(suite.server.URL+"/testorg/test-project/-/merge_requests/12", suite.provider.IssueURL(gitlabOrgName, gitlabProjectName, 12, true))
+}
+
+func (suite *GitlabProviderSuite) TestValidateCredentials() {
+	suite.Require().Nil(suite.provider.ValidateCredentials())
+
+	provider, err := gits.WithGitlabClient(&suite.provider.Server, &auth.UserAuth{Username: "someone-else"}, suite.provider.Client, nil)
+	suite.Require().Nil(err)
+	suite.Require().Error(provider.(*gits.GitlabProvider).ValidateCredentials())
+}
+
 // In order for 'go test' to run this suite, we need to create
 // a normal test function and pass our suite to suite.Run
 func TestGitlabProviderSuite(t *testing.T) {
@@ -165,6 +165,10 @@ func FetchAndMergeSHAs(SHAs []string, baseBranch string, baseSha string, remote
 		log.Logger().Debugf("ran git merge %s in %s", sha, dir)
 
 	}
+	err = PullLFS(gitter, dir, remote)
+	if err != nil {
+		return errors.Wrapf(err, "pulling the Git LFS objects")
+	}
 	return nil
 }
 
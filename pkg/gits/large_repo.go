@@ -0,0 +1,284 @@
+package gits
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// EnvLFSSkipSmudge environment variable to skip downloading Git LFS objects when cloning
+	EnvLFSSkipSmudge = "JX_GIT_LFS_SKIP_SMUDGE"
+	// EnvLFSInclude environment variable with a comma separated list of paths of the Git LFS objects to download
+	EnvLFSInclude = "JX_GIT_LFS_INCLUDE"
+	// EnvCloneFilter environment variable with the partial clone filter spec such as blob:none
+	EnvCloneFilter = "JX_GIT_CLONE_FILTER"
+	// EnvSparseCheckout environment variable with a comma separated list of paths to checkout
+	EnvSparseCheckout = "JX_GIT_SPARSE_CHECKOUT"
+	// EnvCloneProgress environment variable to report the progress of fetches
+	EnvCloneProgress = "JX_GIT_CLONE_PROGRESS"
+
+	gitLFSSkipSmudge  = "GIT_LFS_SKIP_SMUDGE"
+	gitTerminalPrompt = "GIT_TERMINAL_PROMPT"
+)
+
+var cloneFilterRegex = regexp.MustCompile(`^(blob:none|blob:limit=[0-9]+[kmg]?|tree:[0-9]+)$`)
+
+// LargeRepoOptions configures how the git client clones large repositories to avoid timeouts and running out of disk
+// space in pipeline pods
+type LargeRepoOptions struct {
+	// SkipLFSSmudge leaves Git LFS pointer files in the working tree rather than downloading the LFS objects
+	SkipLFSSmudge bool
+	// LFSInclude only downloads the Git LFS objects matching these paths
+	LFSInclude []string
+	// Filter the partial clone filter spec (e.g. blob:none) so that objects are only downloaded when needed
+	Filter string
+	// SparseCheckout the paths to checkout, the whole tree is checked out if empty
+	SparseCheckout []string
+	// Progress reports the progress of fetches
+	Progress bool
+}
+
+// LargeRepoOptionsFromEnv creates the large repository options from the JX_GIT_* environment variables
+func LargeRepoOptionsFromEnv() LargeRepoOptions {
+	return LargeRepoOptions{
+		SkipLFSSmudge:  envBool(EnvLFSSkipSmudge),
+		LFSInclude:     splitPaths(os.Getenv(EnvLFSInclude)),
+		Filter:         strings.TrimSpace(os.Getenv(EnvCloneFilter)),
+		SparseCheckout: splitPaths(os.Getenv(EnvSparseCheckout)),
+		Progress:       envBool(EnvCloneProgress),
+	}
+}
+
+// Enabled returns true if any of the options are set
+func (o *LargeRepoOptions) Enabled() bool {
+	return o.SkipLFSSmudge || len(o.LFSInclude) > 0 || o.Filter != "" || len(o.SparseCheckout) > 0 || o.Progress
+}
+
+// Validate validates the options
+func (o *LargeRepoOptions) Validate() error {
+	if o.Filter != "" && !cloneFilterRegex.MatchString(o.Filter) {
+		return errors.Errorf("invalid clone filter %s, supported filters are blob:none, blob:limit=<n>[kmg] and tree:<depth>", o.Filter)
+	}
+	if o.SkipLFSSmudge && len(o.LFSInclude) > 0 {
+		return errors.New("cannot skip downloading Git LFS objects and include Git LFS paths at the same time")
+	}
+	return nil
+}
+
+// SkipSmudgeOnCheckout returns true if Git LFS objects should not be downloaded when checking out
+func (o *LargeRepoOptions) SkipSmudgeOnCheckout() bool {
+	return o.SkipLFSSmudge || len(o.LFSInclude) > 0
+}
+
+// SparseCheckoutPatterns returns the sparse checkout patterns for the paths
+func (o *LargeRepoOptions) SparseCheckoutPatterns() string {
+	var builder strings.Builder
+	for _, p := range o.SparseCheckout {
+		p = strings.Trim(filepath.ToSlash(p), "/")
+		if p == "" {
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("/%s/\n", p))
+	}
+	return builder.String()
+}
+
+// WithLargeRepo returns a copy of the git client which uses the large repository options so that they only apply to
+// the operations made with the copy rather than to every later clone made with the shared git client. It returns the
+// git client unchanged if it does not support them
+func WithLargeRepo(gitter Gitter, options LargeRepoOptions) Gitter {
+	cli, ok := gitter.(*GitCLI)
+	if !ok {
+		return gitter
+	}
+	answer := *cli
+	answer.Env = map[string]string{}
+	for k, v := range cli.Env {
+		answer.Env[k] = v
+	}
+	setLargeRepo(&answer, options)
+	return &answer
+}
+
+// setLargeRepo sets the large repository options on the git client
+func setLargeRepo(cli *GitCLI, options LargeRepoOptions) {
+	cli.LargeRepo = options
+	// skip the Git LFS objects for every checkout, merge and pull rather than only when cloning
+	if options.SkipLFSSmudge {
+		cli.Env[gitLFSSkipSmudge] = "1"
+	} else {
+		delete(cli.Env, gitLFSSkipSmudge)
+	}
+}
+
+// PullLFS downloads the Git LFS objects of the checked out commit from the remote if the repository uses Git LFS
+// and they have not been skipped. It does nothing if the git client does not support Git LFS
+func PullLFS(gitter Gitter, dir string, remoteName string) error {
+	cli, ok := gitter.(*GitCLI)
+	if !ok {
+		return nil
+	}
+	return cli.pullLFS(dir, remoteName)
+}
+
+// UsesLFS returns true if the .gitattributes file in the directory tracks any paths with Git LFS
+func UsesLFS(dir string) (bool, error) {
+	fileName := filepath.Join(dir, ".gitattributes")
+	exists, err := util.FileExists(fileName)
+	if err != nil || !exists {
+		return false, err
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return false, errors.Wrapf(err, "reading %s", fileName)
+	}
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, field := range strings.Fields(line) {
+			if field == "filter=lfs" {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func splitPaths(text string) []string {
+	answer := []string{}
+	for _, p := range strings.Split(text, ",") {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			answer = append(answer, p)
+		}
+	}
+	return answer
+}
+
+func envBool(name string) bool {
+	value, err := util.ParseBool(strings.TrimSpace(os.Getenv(name)))
+	return err == nil && value
+}
+
+// configureLargeRepo configures partial clone and sparse checkout on a freshly initialised repository before the
+// first fetch
+func (g *GitCLI) configureLargeRepo(dir string, remoteName string) error {
+	err := g.LargeRepo.Validate()
+	if err != nil {
+		return err
+	}
+	if g.LargeRepo.Filter != "" {
+		// later fetches from a promisor remote use the partial clone filter by default
+		configs := [][]string{
+			{"core.repositoryformatversion", "1"},
+			{"extensions.partialClone", remoteName},
+			{fmt.Sprintf("remote.%s.promisor", remoteName), "true"},
+			{fmt.Sprintf("remote.%s.partialclonefilter", remoteName), g.LargeRepo.Filter},
+		}
+		for _, config := range configs {
+			err = g.Config(dir, config...)
+			if err != nil {
+				return errors.Wrapf(err, "configuring partial clone with filter %s in directory %s", g.LargeRepo.Filter, dir)
+			}
+		}
+	}
+	patterns := g.LargeRepo.SparseCheckoutPatterns()
+	if patterns != "" {
+		err = g.Config(dir, "core.sparseCheckout", "true")
+		if err != nil {
+			return errors.Wrapf(err, "enabling sparse checkout in directory %s", dir)
+		}
+		infoDir := filepath.Join(dir, ".git", "info")
+		err = os.MkdirAll(infoDir, util.DefaultWritePermissions)
+		if err != nil {
+			return errors.Wrapf(err, "creating directory %s", infoDir)
+		}
+		fileName := filepath.Join(infoDir, "sparse-checkout")
+		err = ioutil.WriteFile(fileName, []byte(patterns), util.DefaultFileWritePermissions)
+		if err != nil {
+			return errors.Wrapf(err, "writing sparse checkout patterns to %s", fileName)
+		}
+	}
+	return nil
+}
+
+// largeRepoEnv returns the environment for commands which fetch or checkout files so that Git LFS objects are only
+// downloaded when required
+func (g *GitCLI) largeRepoEnv() map[string]string {
+	env := map[string]string{}
+	for k, v := range g.Env {
+		env[k] = v
+	}
+	if g.LargeRepo.SkipSmudgeOnCheckout() {
+		env[gitLFSSkipSmudge] = "1"
+	}
+	return env
+}
+
+// pullLFS downloads the Git LFS objects from the remote after a checkout. The checkout may have skipped them or git
+// may not have the Git LFS filters configured globally. The remote URL is used so that the same credentials as the
+// clone are used for the Git LFS server
+func (g *GitCLI) pullLFS(dir string, remoteName string) error {
+	if g.LargeRepo.SkipLFSSmudge {
+		return nil
+	}
+	lfs, err := UsesLFS(dir)
+	if err != nil || !lfs {
+		return err
+	}
+	env := g.largeRepoEnv()
+	// fail rather than hang if the Git LFS server needs different credentials to the remote
+	env[gitTerminalPrompt] = "0"
+	delete(env, gitLFSSkipSmudge)
+	_, err = g.gitCmdWithEnvAndOutput(dir, env, "lfs", "version")
+	if err != nil {
+		log.Logger().Warnf("git lfs is not installed so Git LFS objects in %s are not downloaded", dir)
+		return nil
+	}
+	// lets make sure later checkouts in the repository download the Git LFS objects too
+	_, err = g.gitCmdWithEnvAndOutput(dir, env, "lfs", "install", "--local")
+	if err != nil {
+		return errors.Wrapf(err, "configuring Git LFS in directory %s", dir)
+	}
+	args := []string{"lfs", "pull"}
+	if remoteName != "" {
+		args = append(args, remoteName)
+	}
+	if len(g.LargeRepo.LFSInclude) > 0 {
+		args = append(args, "--include", strings.Join(g.LargeRepo.LFSInclude, ","))
+	}
+	_, err = g.gitCmdWithEnvAndOutput(dir, env, args...)
+	if err != nil {
+		return errors.Wrapf(err, "pulling Git LFS objects in directory %s", dir)
+	}
+	return nil
+}
+
+func (g *GitCLI) gitCmdWithEnvAndOutput(dir string, env map[string]string, args ...string) (string, error) {
+	cmd := util.Command{
+		Dir:     dir,
+		Name:    "git",
+		Args:    args,
+		Env:     env,
+		Context: g.ctx,
+	}
+	if g.LargeRepo.Progress {
+		cmd.Out = os.Stdout
+		cmd.Err = os.Stderr
+	}
+	log.Logger().Debug(cmd.String())
+	output, err := cmd.RunWithoutRetry()
+	return output, errors.Wrapf(err, "git output: %s", output)
+}
@@ -0,0 +1,161 @@
+// +build unit
+
+package gits_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLargeRepoOptionsFromEnv(t *testing.T) {
+	envVars := map[string]string{
+		gits.EnvLFSSkipSmudge:  "",
+		gits.EnvLFSInclude:     "assets/images, models/small.bin",
+		gits.EnvCloneFilter:    "blob:none",
+		gits.EnvSparseCheckout: "src,docs/",
+		gits.EnvCloneProgress:  "true",
+	}
+	for k, v := range envVars {
+		original, exists := os.LookupEnv(k)
+		require.NoError(t, os.Setenv(k, v))
+		if exists {
+			defer os.Setenv(k, original) //nolint:errcheck
+		} else {
+			defer os.Unsetenv(k) //nolint:errcheck
+		}
+	}
+
+	options := gits.LargeRepoOptionsFromEnv()
+	assert.False(t, options.SkipLFSSmudge)
+	assert.Equal(t, []string{"assets/images", "models/small.bin"}, options.LFSInclude)
+	assert.Equal(t, "blob:none", options.Filter)
+	assert.Equal(t, []string{"src", "docs/"}, options.SparseCheckout)
+	assert.True(t, options.Progress)
+	assert.True(t, options.SkipSmudgeOnCheckout())
+	assert.Equal(t, "/src/\n/docs/\n", options.SparseCheckoutPatterns())
+	assert.NoError(t, options.Validate())
+}
+
+func TestLargeRepoOptionsValidate(t *testing.T) {
+	t.Parallel()
+
+	for _, filter := range []string{"", "blob:none", "blob:limit=1m", "blob:limit=1024", "tree:0"} {
+		options := gits.LargeRepoOptions{Filter: filter}
+		assert.NoError(t, options.Validate(), "filter %s", filter)
+	}
+	for _, filter := range []string{"none", "blob:limit=", "blob:limit=1x", "sparse:oid=main"} {
+		options := gits.LargeRepoOptions{Filter: filter}
+		assert.Error(t, options.Validate(), "filter %s", filter)
+	}
+
+	options := gits.LargeRepoOptions{SkipLFSSmudge: true, LFSInclude: []string{"assets"}}
+	assert.Error(t, options.Validate())
+}
+
+func TestUsesLFS(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-uses-lfs-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	lfs, err := gits.UsesLFS(dir)
+	require.NoError(t, err)
+	assert.False(t, lfs, "no .gitattributes file")
+
+	fileName := filepath.Join(dir, ".gitattributes")
+	err = ioutil.WriteFile(fileName, []byte("# *.bin filter=lfs\n*.sh text eol=lf\n"), util.DefaultFileWritePermissions)
+	require.NoError(t, err)
+	lfs, err = gits.UsesLFS(dir)
+	require.NoError(t, err)
+	assert.False(t, lfs, "commented out LFS tracking")
+
+	err = ioutil.WriteFile(fileName, []byte("*.psd filter=lfs diff=lfs merge=lfs -text\n"), util.DefaultFileWritePermissions)
+	require.NoError(t, err)
+	lfs, err = gits.UsesLFS(dir)
+	require.NoError(t, err)
+	assert.True(t, lfs)
+}
+
+func TestCloneWithSparseCheckoutAndPartialClone(t *testing.T) {
+	t.Parallel()
+
+	sourceDir, err := ioutil.TempDir("", "test-large-repo-source-")
+	require.NoError(t, err)
+	defer os.RemoveAll(sourceDir)
+
+	git := gits.NewGitCLI()
+	require.NoError(t, git.Init(sourceDir))
+	require.NoError(t, git.Config(sourceDir, "user.name", "test"))
+	require.NoError(t, git.Config(sourceDir, "user.email", "test@example.com"))
+	require.NoError(t, git.Config(sourceDir, "uploadpack.allowFilter", "true"))
+	for _, name := range []string{"src/main.go", "assets/big.bin", "README.md"} {
+		fileName := filepath.Join(sourceDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(fileName), util.DefaultWritePermissions))
+		require.NoError(t, ioutil.WriteFile(fileName, []byte(name), util.DefaultFileWritePermissions))
+	}
+	require.NoError(t, git.Add(sourceDir, "."))
+	require.NoError(t, git.CommitDir(sourceDir, "initial commit"))
+
+	cloneDir, err := ioutil.TempDir("", "test-large-repo-clone-")
+	require.NoError(t, err)
+	defer os.RemoveAll(cloneDir)
+
+	shared := gits.NewGitCLI()
+	cloner := gits.WithLargeRepo(shared, gits.LargeRepoOptions{
+		Filter:         "blob:none",
+		SparseCheckout: []string{"src"},
+	})
+	assert.Empty(t, shared.LargeRepo.SparseCheckout, "the shared git client should not be changed")
+	err = cloner.Clone("file://"+sourceDir, cloneDir)
+	require.NoError(t, err)
+
+	assert.FileExists(t, filepath.Join(cloneDir, "src", "main.go"))
+	exists, err := util.FileExists(filepath.Join(cloneDir, "assets", "big.bin"))
+	require.NoError(t, err)
+	assert.False(t, exists, "paths outside of the sparse checkout should not be checked out")
+
+	data, err := ioutil.ReadFile(filepath.Join(cloneDir, ".git", "config"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "partialclonefilter = blob:none")
+}
+
+func TestWithLargeRepoSkipsLFS(t *testing.T) {
+	t.Parallel()
+
+	shared := gits.NewGitCLI()
+	git := gits.WithLargeRepo(shared, gits.LargeRepoOptions{SkipLFSSmudge: true}).(*gits.GitCLI)
+	assert.Equal(t, "1", git.Env["GIT_LFS_SKIP_SMUDGE"])
+	assert.NotContains(t, shared.Env, "GIT_LFS_SKIP_SMUDGE", "the shared git client should not be changed")
+
+	dir, err := ioutil.TempDir("", "test-pull-lfs-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	err = ioutil.WriteFile(filepath.Join(dir, ".gitattributes"), []byte("*.psd filter=lfs diff=lfs merge=lfs -text\n"), util.DefaultFileWritePermissions)
+	require.NoError(t, err)
+
+	// nothing is pulled as the Git LFS objects are skipped
+	assert.NoError(t, gits.PullLFS(git, dir, "origin"))
+
+	git = gits.WithLargeRepo(git, gits.LargeRepoOptions{}).(*gits.GitCLI)
+	assert.NotContains(t, git.Env, "GIT_LFS_SKIP_SMUDGE")
+}
+
+func TestPullLFSWithoutLFS(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-pull-lfs-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// the repository does not use Git LFS so no git commands are run
+	assert.NoError(t, gits.PullLFS(gits.NewGitCLI(), dir, "origin"))
+	assert.NoError(t, gits.PullLFS(&gits.GitFake{}, dir, "origin"))
+}
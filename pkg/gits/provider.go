@@ -273,6 +273,10 @@ func CreateProvider(server *auth.AuthServer, user *auth.UserAuth, git Gitter) (G
 		return NewGiteaProvider(server, user, git)
 	} else if server.Kind == KindGitlab {
 		return NewGitlabProvider(server, user, git)
+	} else if server.Kind == KindAzureDevOps {
+		return NewAzureDevOpsProvider(server, user, git)
+	} else if server.Kind == KindCodeCommit {
+		return NewCodeCommitProvider(server, user, git)
 	} else if server.Kind == KindGitFake {
 		return NewFakeProvider(), nil
 	} else {
@@ -307,6 +311,10 @@ func ProviderAccessTokenURL(kind string, url string, username string) string {
 		return GiteaAccessTokenURL(url)
 	case KindGitlab:
 		return GitlabAccessTokenURL(url)
+	case KindAzureDevOps:
+		return AzureDevOpsAccessTokenURL(url)
+	case KindCodeCommit:
+		return CodeCommitAccessTokenURL(url)
 	default:
 		return GitHubAccessTokenURL(url)
 	}
@@ -333,7 +341,7 @@ func pickOwner(orgLister OrganisationLister, userName string, message string, ha
 
 	orgName := ""
 	surveyOpts := survey.WithStdio(handles.In, handles.Out, handles.Err)
-	err := survey.AskOne(prompt, &orgName, nil, surveyOpts)
+	err := util.AskOne(prompt, &orgName, nil, surveyOpts)
 	if err != nil {
 		return "", err
 	}
@@ -391,7 +399,7 @@ func PickRepositories(provider GitProvider, owner string, message string, select
 	}
 	repoNames := []string{}
 	surveyOpts := survey.WithStdio(handles.In, handles.Out, handles.Err)
-	err = survey.AskOne(prompt, &repoNames, nil, surveyOpts)
+	err = util.AskOne(prompt, &repoNames, nil, surveyOpts)
 
 	for _, n := range repoNames {
 		repo := repoMap[n]
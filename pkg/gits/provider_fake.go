@@ -86,6 +86,7 @@ type FakeProvider struct {
 	Type                     FakeProviderType
 	Users                    []*GitUser
 	WebHooks                 []*GitWebHookArguments
+	BranchProtections        map[string]*BranchProtection
 	Gitter                   Gitter
 	CreateRepositoryAddFiles func(dir string) error
 }
@@ -501,6 +502,15 @@ func (f *FakeProvider) CreateWebHook(data *GitWebHookArguments) error {
 	return nil
 }
 
+// ProtectBranch records the protection of the branch keyed by owner/repo/branch
+func (f *FakeProvider) ProtectBranch(owner string, repo string, branch string, protection *BranchProtection) error {
+	if f.BranchProtections == nil {
+		f.BranchProtections = map[string]*BranchProtection{}
+	}
+	f.BranchProtections[owner+"/"+repo+"/"+branch] = protection
+	return nil
+}
+
 func (p *FakeProvider) ListWebHooks(owner string, repo string) ([]*GitWebHookArguments, error) {
 	return p.WebHooks, nil
 }
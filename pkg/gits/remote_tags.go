@@ -0,0 +1,34 @@
+package gits
+
+import (
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// ListRemoteTags lists the tags of the remote git repository without cloning it
+func ListRemoteTags(gitURL string) ([]string, error) {
+	cmd := util.Command{
+		Name: "git",
+		Args: []string{"ls-remote", "--tags", "--refs", gitURL},
+	}
+	output, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the tags of %s", gitURL)
+	}
+	return ParseRemoteTags(output), nil
+}
+
+// ParseRemoteTags returns the tag names of the output of git ls-remote --tags
+func ParseRemoteTags(output string) []string {
+	var tags []string
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || !strings.HasPrefix(fields[1], "refs/tags/") {
+			continue
+		}
+		tags = append(tags, strings.TrimSuffix(strings.TrimPrefix(fields[1], "refs/tags/"), "^{}"))
+	}
+	return tags
+}
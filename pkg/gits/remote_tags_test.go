@@ -0,0 +1,21 @@
+// +build unit
+
+package gits_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRemoteTags(t *testing.T) {
+	t.Parallel()
+
+	output := `8c6a1d3b6e4f0a1e0d3f2c1b0a9f8e7d6c5b4a39	refs/tags/v1.0.0
+0d3f2c1b0a9f8e7d6c5b4a398c6a1d3b6e4f0a1e	refs/tags/v1.1.0
+1e0d3f2c1b0a9f8e7d6c5b4a398c6a1d3b6e4f0a	refs/tags/v1.1.0^{}
+`
+	assert.Equal(t, []string{"v1.0.0", "v1.1.0", "v1.1.0"}, gits.ParseRemoteTags(output))
+	assert.Empty(t, gits.ParseRemoteTags(""))
+}
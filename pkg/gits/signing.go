@@ -0,0 +1,205 @@
+package gits
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// EnvGitSigningFormat environment variable with the format commits are signed with: gpg or ssh. It is detected
+	// from the signing key if it is not set
+	EnvGitSigningFormat = "JX_GIT_SIGNING_FORMAT"
+	// EnvGitSigningKey environment variable with the private key file commits are signed with. For GPG it is an
+	// armored private key which is imported into a dedicated keyring
+	EnvGitSigningKey = "JX_GIT_SIGNING_KEY"
+	// EnvGitSigningKeyID environment variable with the ID of the GPG key commits are signed with if the key is already
+	// in the keyring or the armored key file contains several keys
+	EnvGitSigningKeyID = "JX_GIT_SIGNING_KEY_ID"
+	// EnvGitRequireSigned environment variable to fail rather than create unsigned commits
+	EnvGitRequireSigned = "JX_GIT_REQUIRE_SIGNED"
+
+	// SigningFormatGPG signs commits with a GPG key
+	SigningFormatGPG = "gpg"
+	// SigningFormatSSH signs commits with an SSH key
+	SigningFormatSSH = "ssh"
+
+	gnupgHome = "GNUPGHOME"
+)
+
+// SigningFormats the supported commit signing formats
+var SigningFormats = []string{SigningFormatGPG, SigningFormatSSH}
+
+// SigningOptions configures the git client to sign the commits it creates such as in promotion and version bump pull
+// requests
+type SigningOptions struct {
+	// Format the signing format, detected from the key file if empty
+	Format string
+	// KeyFile the private key file to sign with
+	KeyFile string
+	// KeyID the ID of the GPG key to sign with
+	KeyID string
+	// Required fails commits if signing is not configured
+	Required bool
+
+	// signingKey the resolved value of the user.signingkey git configuration
+	signingKey string
+}
+
+// SigningOptionsFromEnv creates the signing options from the JX_GIT_SIGNING* environment variables
+func SigningOptionsFromEnv() SigningOptions {
+	return SigningOptions{
+		Format:   strings.TrimSpace(os.Getenv(EnvGitSigningFormat)),
+		KeyFile:  strings.TrimSpace(os.Getenv(EnvGitSigningKey)),
+		KeyID:    strings.TrimSpace(os.Getenv(EnvGitSigningKeyID)),
+		Required: envBool(EnvGitRequireSigned),
+	}
+}
+
+// Enabled returns true if commits should be signed
+func (o *SigningOptions) Enabled() bool {
+	return o.KeyFile != "" || o.KeyID != ""
+}
+
+// Validate validates the options
+func (o *SigningOptions) Validate() error {
+	if o.Format != "" && util.StringArrayIndex(SigningFormats, o.Format) < 0 {
+		return util.InvalidOption(EnvGitSigningFormat, o.Format, SigningFormats)
+	}
+	if o.KeyFile != "" {
+		exists, err := util.FileExists(o.KeyFile)
+		if err != nil {
+			return errors.Wrapf(err, "checking the signing key file %s exists", o.KeyFile)
+		}
+		if !exists {
+			return errors.Errorf("the signing key file %s does not exist", o.KeyFile)
+		}
+	}
+	if o.Format == SigningFormatSSH && o.KeyFile == "" {
+		return errors.Errorf("the %s environment variable must be set to sign commits with SSH", EnvGitSigningKey)
+	}
+	if o.Required && !o.Enabled() {
+		return errors.Errorf("signed commits are required but no signing key is configured. Please set the %s environment variable", EnvGitSigningKey)
+	}
+	return nil
+}
+
+// DetectFormat returns the signing format of the options defaulting it from the contents of the key file
+func (o *SigningOptions) DetectFormat() (string, error) {
+	if o.Format != "" {
+		return o.Format, nil
+	}
+	if o.KeyFile == "" {
+		// only a GPG key ID is configured
+		return SigningFormatGPG, nil
+	}
+	data, err := ioutil.ReadFile(o.KeyFile)
+	if err != nil {
+		return "", errors.Wrapf(err, "reading the signing key file %s", o.KeyFile)
+	}
+	if strings.Contains(string(data), "BEGIN PGP PRIVATE KEY BLOCK") {
+		return SigningFormatGPG, nil
+	}
+	return SigningFormatSSH, nil
+}
+
+// ConfigureSigning configures the signing options on the git client. It returns false if the git client does not
+// support them
+func ConfigureSigning(gitter Gitter, options SigningOptions) bool {
+	cli, ok := gitter.(*GitCLI)
+	if !ok {
+		return false
+	}
+	cli.Signing = options
+	delete(cli.Env, gnupgHome)
+	return true
+}
+
+// signingConfigArgs returns the git configuration arguments which sign the commit being created. The GPG key is
+// imported the first time a commit is signed
+func (g *GitCLI) signingConfigArgs() ([]string, error) {
+	s := &g.Signing
+	if !s.Enabled() && !s.Required {
+		return nil, nil
+	}
+	if s.signingKey == "" {
+		err := s.Validate()
+		if err != nil {
+			return nil, err
+		}
+		s.Format, err = s.DetectFormat()
+		if err != nil {
+			return nil, err
+		}
+		if s.Format == SigningFormatSSH {
+			s.signingKey = s.KeyFile
+		} else {
+			s.signingKey, err = g.importGPGKey()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	format := "openpgp"
+	if s.Format == SigningFormatSSH {
+		format = SigningFormatSSH
+	}
+	return []string{"-c", "commit.gpgsign=true", "-c", "gpg.format=" + format, "-c", "user.signingkey=" + s.signingKey}, nil
+}
+
+// importGPGKey imports the armored GPG key file into a keyring used only by jx returning the ID of the signing key
+func (g *GitCLI) importGPGKey() (string, error) {
+	s := &g.Signing
+	if s.KeyFile == "" {
+		return s.KeyID, nil
+	}
+	configDir, err := util.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	home := filepath.Join(configDir, "gnupg")
+	err = os.MkdirAll(home, 0700)
+	if err != nil {
+		return "", errors.Wrapf(err, "creating the GPG home directory %s", home)
+	}
+	env := map[string]string{gnupgHome: home}
+	cmd := util.Command{
+		Name: "gpg",
+		Args: []string{"--batch", "--with-colons", "--import-options", "import-show", "--import", s.KeyFile},
+		Env:  env,
+	}
+	output, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return "", errors.Wrapf(err, "importing the GPG signing key %s", s.KeyFile)
+	}
+	g.Env[gnupgHome] = home
+	if s.KeyID != "" {
+		return s.KeyID, nil
+	}
+	keyID := ""
+	record := ""
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if fields[0] != "fpr" {
+			record = fields[0]
+			continue
+		}
+		// lets ignore the fingerprints of the subkeys
+		if len(fields) > 9 && (record == "sec" || record == "pub") {
+			if keyID != "" {
+				return "", errors.Errorf("the GPG signing key file %s contains several keys. Please choose one via the %s environment variable", s.KeyFile, EnvGitSigningKeyID)
+			}
+			keyID = fields[9]
+		}
+	}
+	if keyID == "" {
+		return "", errors.Errorf("no GPG key was found in the signing key file %s", s.KeyFile)
+	}
+	return keyID, nil
+}
@@ -0,0 +1,247 @@
+package gits
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	gitssh "gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
+)
+
+const (
+	// EnvGitSSH environment variable to clone and push over SSH instead of HTTPS
+	EnvGitSSH = "JX_GIT_SSH"
+	// EnvGitSSHKey environment variable with the private key file to use, otherwise the keys of the ssh-agent are used
+	EnvGitSSHKey = "JX_GIT_SSH_KEY"
+	// EnvGitSSHKnownHosts environment variable with the known hosts file used to verify the git servers
+	EnvGitSSHKnownHosts = "JX_GIT_SSH_KNOWN_HOSTS"
+	// EnvGitSSHStrictHostKeyChecking environment variable with the host key verification policy: yes, accept-new or no
+	EnvGitSSHStrictHostKeyChecking = "JX_GIT_SSH_STRICT_HOST_KEY_CHECKING"
+
+	// HostKeyCheckingYes only connects to git servers whose host key is in the known hosts file
+	HostKeyCheckingYes = "yes"
+	// HostKeyCheckingAcceptNew adds the host key of unknown git servers to the known hosts file but rejects changed keys
+	HostKeyCheckingAcceptNew = "accept-new"
+	// HostKeyCheckingNo does not verify the host keys of the git servers
+	HostKeyCheckingNo = "no"
+
+	gitSSHCommand = "GIT_SSH_COMMAND"
+	sshAuthSock   = "SSH_AUTH_SOCK"
+	sshGitUser    = "git"
+)
+
+// HostKeyCheckingValues the supported host key verification policies
+var HostKeyCheckingValues = []string{HostKeyCheckingYes, HostKeyCheckingAcceptNew, HostKeyCheckingNo}
+
+// SSHOptions configures the git client to clone and push using SSH URLs authenticated with a private key or
+// the ssh-agent rather than HTTPS URLs with API tokens
+type SSHOptions struct {
+	// Enabled converts HTTPS git URLs to SSH URLs
+	Enabled bool
+	// KeyFile the private key to authenticate with, the ssh-agent is used if empty
+	KeyFile string
+	// KnownHostsFile the known hosts file, the default ssh known hosts files are used if empty
+	KnownHostsFile string
+	// StrictHostKeyChecking the host key verification policy
+	StrictHostKeyChecking string
+}
+
+// SSHOptionsFromEnv creates the SSH options from the JX_GIT_SSH* environment variables
+func SSHOptionsFromEnv() SSHOptions {
+	return SSHOptions{
+		Enabled:               envBool(EnvGitSSH),
+		KeyFile:               strings.TrimSpace(os.Getenv(EnvGitSSHKey)),
+		KnownHostsFile:        strings.TrimSpace(os.Getenv(EnvGitSSHKnownHosts)),
+		StrictHostKeyChecking: strings.TrimSpace(os.Getenv(EnvGitSSHStrictHostKeyChecking)),
+	}
+}
+
+// Validate validates the options
+func (o *SSHOptions) Validate() error {
+	if o.StrictHostKeyChecking != "" && util.StringArrayIndex(HostKeyCheckingValues, o.StrictHostKeyChecking) < 0 {
+		return util.InvalidOption(EnvGitSSHStrictHostKeyChecking, o.StrictHostKeyChecking, HostKeyCheckingValues)
+	}
+	if o.KeyFile != "" {
+		exists, err := util.FileExists(o.KeyFile)
+		if err != nil {
+			return errors.Wrapf(err, "checking the SSH private key file %s exists", o.KeyFile)
+		}
+		if !exists {
+			return errors.Errorf("the SSH private key file %s does not exist", o.KeyFile)
+		}
+	}
+	return nil
+}
+
+// Command returns the ssh command git should use for the options or an empty string if the default ssh
+// configuration should be used
+func (o *SSHOptions) Command() string {
+	args := []string{}
+	if o.KeyFile != "" {
+		args = append(args, "-i", o.KeyFile)
+		if os.Getenv(sshAuthSock) == "" {
+			args = append(args, "-o", "IdentitiesOnly=yes")
+		}
+	}
+	if o.KnownHostsFile != "" {
+		args = append(args, "-o", "UserKnownHostsFile="+o.KnownHostsFile)
+	}
+	if o.StrictHostKeyChecking != "" {
+		args = append(args, "-o", "StrictHostKeyChecking="+o.StrictHostKeyChecking)
+	}
+	if len(args) == 0 {
+		return ""
+	}
+	return "ssh " + strings.Join(args, " ")
+}
+
+// SSHURL converts the HTTPS git URL to its SSH equivalent if SSH is enabled, any other URLs are returned as is
+func (o *SSHOptions) SSHURL(gitURL string) string {
+	if !o.Enabled {
+		return gitURL
+	}
+	u, err := url.Parse(gitURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Hostname() == "" {
+		return gitURL
+	}
+	path := strings.Trim(u.Path, "/")
+	if path == "" {
+		return gitURL
+	}
+	return fmt.Sprintf("%s%s:%s", gitPrefix, u.Hostname(), path)
+}
+
+// IsSSHURL returns true if the git URL uses SSH
+func IsSSHURL(gitURL string) bool {
+	if strings.HasPrefix(gitURL, "ssh://") {
+		return true
+	}
+	if strings.Contains(gitURL, "://") {
+		return false
+	}
+	// scp like syntax such as git@github.com:jenkins-x/jx.git
+	idx := strings.Index(gitURL, ":")
+	return idx > 0 && !strings.Contains(gitURL[:idx], "/")
+}
+
+// GoGitAuth returns the authentication for go-git to use to access the git URL or nil if the URL does not use SSH
+func (o *SSHOptions) GoGitAuth(gitURL string) (transport.AuthMethod, error) {
+	if !IsSSHURL(gitURL) {
+		return nil, nil
+	}
+	user := sshGitUser
+	if ep, err := transport.NewEndpoint(gitURL); err == nil && ep.User != "" {
+		user = ep.User
+	}
+	callback, err := o.HostKeyCallback()
+	if err != nil {
+		return nil, err
+	}
+	if o.KeyFile != "" {
+		auth, err := gitssh.NewPublicKeysFromFile(user, o.KeyFile, "")
+		if err != nil {
+			return nil, errors.Wrapf(err, "loading the SSH private key %s", o.KeyFile)
+		}
+		auth.HostKeyCallback = callback
+		return auth, nil
+	}
+	auth, err := gitssh.NewSSHAgentAuth(user)
+	if err != nil {
+		return nil, errors.Wrap(err, "connecting to the ssh-agent")
+	}
+	auth.HostKeyCallback = callback
+	return auth, nil
+}
+
+// GoGitRemote returns the URL and authentication go-git should use to clone the git URL
+func (o *SSHOptions) GoGitRemote(gitURL string) (string, transport.AuthMethod, error) {
+	gitURL = o.SSHURL(gitURL)
+	auth, err := o.GoGitAuth(gitURL)
+	if err != nil {
+		return "", nil, err
+	}
+	return gitURL, auth, nil
+}
+
+// HostKeyCallback returns the host key verification for go-git implementing the host key checking policy
+func (o *SSHOptions) HostKeyCallback() (ssh.HostKeyCallback, error) {
+	if o.StrictHostKeyChecking == HostKeyCheckingNo {
+		return ssh.InsecureIgnoreHostKey(), nil // #nosec
+	}
+	files := []string{}
+	if o.KnownHostsFile != "" {
+		files = append(files, o.KnownHostsFile)
+	}
+	if o.StrictHostKeyChecking != HostKeyCheckingAcceptNew {
+		return gitssh.NewKnownHostsCallback(files...)
+	}
+
+	knownHostsFile := o.KnownHostsFile
+	if knownHostsFile == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, errors.Wrap(err, "finding the home directory")
+		}
+		knownHostsFile = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	err := os.MkdirAll(filepath.Dir(knownHostsFile), 0700)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating the directory of %s", knownHostsFile)
+	}
+	f, err := os.OpenFile(knownHostsFile, os.O_CREATE|os.O_RDONLY, 0600)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating %s", knownHostsFile)
+	}
+	f.Close() //nolint:errcheck
+	callback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading the known hosts file %s", knownHostsFile)
+	}
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := callback(hostname, remote, key)
+		keyErr, ok := err.(*knownhosts.KeyError)
+		if !ok || len(keyErr.Want) > 0 {
+			// either known or the host key has changed
+			return err
+		}
+		return appendKnownHost(knownHostsFile, hostname, key)
+	}, nil
+}
+
+// appendKnownHost adds the host key to the known hosts file
+func appendKnownHost(fileName string, hostname string, key ssh.PublicKey) error {
+	f, err := os.OpenFile(fileName, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "opening %s", fileName)
+	}
+	defer f.Close() //nolint:errcheck
+	_, err = f.WriteString(knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key) + "\n")
+	if err != nil {
+		return errors.Wrapf(err, "adding the host key of %s to %s", hostname, fileName)
+	}
+	return nil
+}
+
+// ConfigureSSH configures the SSH options on the git client. It returns false if the git client does not support them
+func ConfigureSSH(gitter Gitter, options SSHOptions) bool {
+	cli, ok := gitter.(*GitCLI)
+	if !ok {
+		return false
+	}
+	cli.SSH = options
+	command := options.Command()
+	if command != "" {
+		cli.Env[gitSSHCommand] = command
+	} else {
+		delete(cli.Env, gitSSHCommand)
+	}
+	return true
+}
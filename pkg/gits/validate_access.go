@@ -0,0 +1,161 @@
+package gits
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// MinimumRateLimitRemaining the number of API requests which should remain in the current rate limit window for an
+// installation to complete without being throttled
+const MinimumRateLimitRemaining = 100
+
+// RequiredGitHubScopes the OAuth scopes the GitHub API token needs to create the environment repositories and their
+// webhooks
+var RequiredGitHubScopes = []string{"repo", "admin:repo_hook"}
+
+// AccessValidator is implemented by git providers which can check up front that their API token can be used to create
+// repositories and webhooks in an organisation
+type AccessValidator interface {
+	// ValidateAccess checks the scopes and rate limit of the API token and, if the organisation is not blank, that it
+	// exists and the user can create repositories in it
+	ValidateAccess(org string) error
+}
+
+// ValidateAccess validates the API token of the git provider can be used in the organisation if the provider supports
+// it, otherwise the checks are skipped
+func ValidateAccess(provider GitProvider, org string) error {
+	validator, ok := provider.(AccessValidator)
+	if !ok {
+		log.Logger().Debugf("skipping the access checks for the %s git provider %s", provider.Kind(), provider.ServerURL())
+		return nil
+	}
+	return validator.ValidateAccess(org)
+}
+
+// ValidateAccess checks the scopes and rate limit of the API token and that the organisation exists and the user can
+// create repositories in it
+func (p *GitHubProvider) ValidateAccess(org string) error {
+	tokenURL := GitHubAccessTokenURL(p.Server.URL)
+	username := p.Username
+	if !p.User.IsGitHubApp() {
+		user, resp, err := p.Client.Users.Get(p.Context, "")
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+				return fmt.Errorf("the API token of user %s is not valid for %s, it may have expired or been revoked. Please create a new token at %s", username, p.Server.URL, tokenURL)
+			}
+			return errors.Wrapf(err, "querying the current user of %s", p.Server.URL)
+		}
+		if username == "" {
+			username = user.GetLogin()
+		} else if !strings.EqualFold(user.GetLogin(), username) {
+			return fmt.Errorf("the API token for %s belongs to user %s rather than %s. Please create a token for %s at %s", p.Server.URL, user.GetLogin(), username, username, tokenURL)
+		}
+		err = validateGitHubScopes(resp, username, p.Server.URL, tokenURL)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := p.validateRateLimit()
+	if err != nil {
+		return err
+	}
+	if org == "" || strings.EqualFold(org, username) {
+		return nil
+	}
+	return p.validateOrganisationAccess(org, username)
+}
+
+// validateGitHubScopes checks the OAuth scopes of the response include the required scopes. Fine-grained tokens do
+// not report their permissions so are not checked
+func validateGitHubScopes(resp *github.Response, username string, serverURL string, tokenURL string) error {
+	values := resp.Header.Values("X-OAuth-Scopes")
+	if len(values) == 0 {
+		log.Logger().Debugf("the API token of user %s for %s does not report its scopes so they are not checked", username, serverURL)
+		return nil
+	}
+	scopes := map[string]bool{}
+	for _, scope := range strings.Split(strings.Join(values, ","), ",") {
+		scopes[strings.TrimSpace(scope)] = true
+	}
+	missing := []string{}
+	for _, scope := range RequiredGitHubScopes {
+		if !scopes[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("the API token of user %s for %s is missing the scopes %s which are required to create the environment repositories and their webhooks. Please create a token with the scopes %s at %s",
+			username, serverURL, strings.Join(missing, ", "), strings.Join(RequiredGitHubScopes, ", "), tokenURL)
+	}
+	return nil
+}
+
+// validateRateLimit checks there are enough API requests left in the current rate limit window
+func (p *GitHubProvider) validateRateLimit() error {
+	limits, resp, err := p.Client.RateLimits(p.Context)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			// rate limiting is disabled on this GitHub Enterprise server
+			return nil
+		}
+		return errors.Wrapf(err, "querying the rate limit of %s", p.Server.URL)
+	}
+	core := limits.GetCore()
+	if core == nil || core.Remaining >= MinimumRateLimitRemaining {
+		return nil
+	}
+	return fmt.Errorf("only %d of the %d API requests allowed per hour remain for user %s on %s. Please wait until the rate limit resets at %s or use a different token",
+		core.Remaining, core.Limit, p.Username, p.Server.URL, core.Reset.Format(time.RFC3339))
+}
+
+// validateOrganisationAccess checks the organisation exists and the user can create repositories in it
+func (p *GitHubProvider) validateOrganisationAccess(org string, username string) error {
+	organisation, resp, err := p.Client.Organizations.Get(p.Context, org)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("the organisation %s does not exist on %s or is not visible to user %s. Please create it at %s or choose a different organisation",
+				org, p.Server.URL, username, util.UrlJoin(p.serverWebURL(), "organizations/new"))
+		}
+		return errors.Wrapf(err, "querying the organisation %s on %s", org, p.Server.URL)
+	}
+	if p.User.IsGitHubApp() {
+		// the permissions of a GitHub App are granted by its installation in the organisation
+		return nil
+	}
+
+	membership, resp, err := p.Client.Organizations.GetOrgMembership(p.Context, username, org)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("user %s is not a member of the organisation %s on %s. Please ask an owner of %s to add %s to it with permission to create repositories",
+				username, org, p.Server.URL, org, username)
+		}
+		log.Logger().Warnf("could not check the membership of user %s in the organisation %s: %s", username, org, err.Error())
+		return nil
+	}
+	if membership.GetState() != "active" {
+		return fmt.Errorf("user %s has not accepted the invitation to join the organisation %s on %s yet. Please accept it at %s",
+			username, org, p.Server.URL, util.UrlJoin(p.serverWebURL(), "orgs", org, "invitation"))
+	}
+	if membership.GetRole() != "admin" && organisation.MembersCanCreateRepos != nil && !organisation.GetMembersCanCreateRepos() {
+		return fmt.Errorf("members of the organisation %s on %s cannot create repositories. Please ask an owner of %s to make user %s an owner or to allow members to create repositories",
+			org, p.Server.URL, org, username)
+	}
+	return nil
+}
+
+// serverWebURL returns the URL of the web UI of the server
+func (p *GitHubProvider) serverWebURL() string {
+	u := p.Server.URL
+	if strings.Index(u, "://") < 0 {
+		u = "https://" + u
+	}
+	return u
+}
@@ -0,0 +1,139 @@
+// +build unit
+
+package gits_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeGitHubAccess struct {
+	login          string
+	scopes         string
+	remaining      int
+	orgs           map[string]bool
+	role           string
+	state          string
+	membersCanRepo bool
+}
+
+func (f *fakeGitHubAccess) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/api/v3/user":
+		if f.scopes != "-" {
+			w.Header().Set("X-OAuth-Scopes", f.scopes)
+		}
+		fmt.Fprintf(w, `{"login": %q}`, f.login)
+	case "/api/v3/rate_limit":
+		fmt.Fprintf(w, `{"resources": {"core": {"limit": 5000, "remaining": %d, "reset": 1600000000}}}`, f.remaining)
+	case "/api/v3/orgs/acme":
+		if !f.orgs["acme"] {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"login": "acme", "members_can_create_repositories": %t}`, f.membersCanRepo)
+	case "/api/v3/orgs/acme/memberships/" + f.login:
+		if f.role == "" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"state": %q, "role": %q}`, f.state, f.role)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestGitHubValidateAccess(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		fake   fakeGitHubAccess
+		org    string
+		errMsg string
+	}{
+		{
+			name: "valid",
+			fake: fakeGitHubAccess{scopes: "repo, admin:repo_hook, read:org", remaining: 4000, orgs: map[string]bool{"acme": true}, role: "member", state: "active", membersCanRepo: true},
+			org:  "acme",
+		},
+		{
+			name: "user account",
+			fake: fakeGitHubAccess{scopes: "repo, admin:repo_hook", remaining: 4000},
+			org:  "bot",
+		},
+		{
+			name: "fine grained token",
+			fake: fakeGitHubAccess{scopes: "-", remaining: 4000},
+		},
+		{
+			name:   "missing scopes",
+			fake:   fakeGitHubAccess{scopes: "repo, write:repo_hook", remaining: 4000},
+			errMsg: "is missing the scopes admin:repo_hook",
+		},
+		{
+			name:   "rate limited",
+			fake:   fakeGitHubAccess{scopes: "repo, admin:repo_hook", remaining: 10},
+			errMsg: "only 10 of the 5000 API requests",
+		},
+		{
+			name:   "missing organisation",
+			fake:   fakeGitHubAccess{scopes: "repo, admin:repo_hook", remaining: 4000},
+			org:    "acme",
+			errMsg: "the organisation acme does not exist",
+		},
+		{
+			name:   "not a member",
+			fake:   fakeGitHubAccess{scopes: "repo, admin:repo_hook", remaining: 4000, orgs: map[string]bool{"acme": true}},
+			org:    "acme",
+			errMsg: "user bot is not a member of the organisation acme",
+		},
+		{
+			name:   "pending invitation",
+			fake:   fakeGitHubAccess{scopes: "repo, admin:repo_hook", remaining: 4000, orgs: map[string]bool{"acme": true}, role: "member", state: "pending"},
+			org:    "acme",
+			errMsg: "has not accepted the invitation",
+		},
+		{
+			name:   "members cannot create repositories",
+			fake:   fakeGitHubAccess{scopes: "repo, admin:repo_hook", remaining: 4000, orgs: map[string]bool{"acme": true}, role: "member", state: "active"},
+			org:    "acme",
+			errMsg: "members of the organisation acme",
+		},
+		{
+			name: "organisation owner",
+			fake: fakeGitHubAccess{scopes: "repo, admin:repo_hook", remaining: 4000, orgs: map[string]bool{"acme": true}, role: "admin", state: "active"},
+			org:  "acme",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			tc.fake.login = "bot"
+			server := httptest.NewServer(&tc.fake)
+			defer server.Close()
+
+			provider, err := gits.NewGitHubProvider(&auth.AuthServer{URL: server.URL, Kind: gits.KindGitHub},
+				&auth.UserAuth{Username: "bot", ApiToken: "token"}, nil)
+			require.NoError(t, err)
+
+			err = gits.ValidateAccess(provider, tc.org)
+			if tc.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package helm
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"gocloud.dev/blob"
+	"k8s.io/helm/pkg/provenance"
+	"k8s.io/helm/pkg/repo"
+
+	// register the buckets which can store a chart repository
+	_ "gocloud.dev/blob/azureblob"
+	_ "gocloud.dev/blob/gcsblob"
+	_ "gocloud.dev/blob/s3blob"
+)
+
+const (
+	// ChartRepositoryKindChartMuseum a chart repository served over HTTP such as chartmuseum or bucketrepo
+	ChartRepositoryKindChartMuseum = "chartmuseum"
+	// ChartRepositoryKindBucket a chart repository stored in a gs://, s3:// or azblob:// cloud bucket
+	ChartRepositoryKindBucket = "bucket"
+	// ChartRepositoryKindOCI a chart repository stored in an oci:// container registry
+	ChartRepositoryKindOCI = "oci"
+
+	// IndexFileName the name of the index of a chart repository
+	IndexFileName = "index.yaml"
+
+	// azureStorageAccountEnvVar the environment variable of the Azure storage account of azblob buckets
+	azureStorageAccountEnvVar = "AZURE_STORAGE_ACCOUNT"
+)
+
+// ChartRepositoryKind returns the kind of the chart repository of the URL
+func ChartRepositoryKind(repoURL string) string {
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return ChartRepositoryKindChartMuseum
+	}
+	switch u.Scheme {
+	case "gs", "s3", "azblob":
+		return ChartRepositoryKindBucket
+	case "oci":
+		return ChartRepositoryKindOCI
+	default:
+		return ChartRepositoryKindChartMuseum
+	}
+}
+
+// ChartRepositoryReadURL returns the URL helm downloads the charts of the chart repository from. The charts of a
+// bucket are read over HTTPS from the public endpoint of the bucket, the URLs of other repositories are unchanged
+func ChartRepositoryReadURL(repoURL string) string {
+	if ChartRepositoryKind(repoURL) != ChartRepositoryKindBucket {
+		return repoURL
+	}
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return repoURL
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	switch u.Scheme {
+	case "gs":
+		return fmt.Sprintf("https://storage.googleapis.com/%s%s", u.Host, path)
+	case "s3":
+		if region := u.Query().Get("region"); region != "" {
+			return fmt.Sprintf("https://%s.s3.%s.amazonaws.com%s", u.Host, region, path)
+		}
+		return fmt.Sprintf("https://%s.s3.amazonaws.com%s", u.Host, path)
+	default:
+		return fmt.Sprintf("https://%s.blob.core.windows.net/%s%s", os.Getenv(azureStorageAccountEnvVar), u.Host, path)
+	}
+}
+
+// PublishChartToBucket uploads the chart archive to the chart repository in a bucket and adds it to the index of the
+// repository. The chart file is the Chart.yaml of the chart the archive was packaged from
+func PublishChartToBucket(repoURL string, tarball string, chartFile string, timeout time.Duration) error {
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return errors.Wrapf(err, "parsing the chart repository URL %s", repoURL)
+	}
+	metadata, err := LoadChartFile(chartFile)
+	if err != nil {
+		return errors.Wrapf(err, "loading the chart file %s", chartFile)
+	}
+	digest, err := provenance.DigestFile(tarball)
+	if err != nil {
+		return errors.Wrapf(err, "generating the digest of the chart archive %s", tarball)
+	}
+	data, err := ioutil.ReadFile(tarball)
+	if err != nil {
+		return errors.Wrapf(err, "reading the chart archive %s", tarball)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	// the path of a bucket URL is the directory of the chart repository within the bucket except for file:// URLs
+	// whose path is the local directory of the chart repository
+	bucketURL := *u
+	prefix := ""
+	if u.Scheme != "file" {
+		bucketURL.Path = ""
+		prefix = strings.Trim(u.Path, "/")
+	}
+	bucket, err := blob.Open(ctx, bucketURL.String())
+	if err != nil {
+		return errors.Wrapf(err, "opening the bucket %s", bucketURL.String())
+	}
+	key := func(name string) string {
+		if prefix == "" {
+			return name
+		}
+		return prefix + "/" + name
+	}
+
+	index := repo.NewIndexFile()
+	indexData, err := bucket.ReadAll(ctx, key(IndexFileName))
+	if err != nil && !blob.IsNotExist(err) {
+		return errors.Wrapf(err, "reading the index of the chart repository %s", repoURL)
+	}
+	if err == nil {
+		err = yaml.Unmarshal(indexData, index)
+		if err != nil {
+			return errors.Wrapf(err, "parsing the index of the chart repository %s", repoURL)
+		}
+		if index.Entries == nil {
+			index.Entries = map[string]repo.ChartVersions{}
+		}
+	}
+	if index.Has(metadata.Name, metadata.Version) {
+		return fmt.Errorf("the chart repository %s already contains version %s of chart %s", repoURL, metadata.Version, metadata.Name)
+	}
+
+	fileName := filepath.Base(tarball)
+	log.Logger().Infof("Uploading chart file %s to %s", util.ColorInfo(fileName), util.ColorInfo(repoURL))
+	err = bucket.WriteAll(ctx, key(fileName), data, &blob.WriterOptions{ContentType: "application/gzip"})
+	if err != nil {
+		return errors.Wrapf(err, "uploading the chart archive %s to %s", fileName, repoURL)
+	}
+	// the URL of the chart is relative to the repository so that it can be read from the public endpoint of the bucket
+	index.Add(metadata, fileName, "", digest)
+	index.SortEntries()
+	index.Generated = time.Now()
+	indexData, err = yaml.Marshal(index)
+	if err != nil {
+		return errors.Wrap(err, "marshalling the index of the chart repository")
+	}
+	err = bucket.WriteAll(ctx, key(IndexFileName), indexData, &blob.WriterOptions{ContentType: "text/yaml"})
+	if err != nil {
+		return errors.Wrapf(err, "updating the index of the chart repository %s", repoURL)
+	}
+	return nil
+}
+
+// PushChartToOCI pushes the chart archive to the chart repository in an OCI registry using helm 3 which logs into the
+// registry with the docker credentials
+func PushChartToOCI(helmBinary string, repoURL string, tarball string) error {
+	log.Logger().Infof("Pushing chart file %s to %s", util.ColorInfo(filepath.Base(tarball)), util.ColorInfo(repoURL))
+	cmd := util.Command{
+		Name: helmBinary,
+		Args: []string{"push", tarball, strings.TrimSuffix(repoURL, "/")},
+		Env:  map[string]string{"HELM_EXPERIMENTAL_OCI": "1"},
+	}
+	_, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return errors.Wrapf(err, "pushing the chart archive %s to %s", tarball, repoURL)
+	}
+	return nil
+}
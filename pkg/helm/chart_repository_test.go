@@ -0,0 +1,91 @@
+// +build unit
+
+package helm_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	helmrepo "k8s.io/helm/pkg/repo"
+
+	_ "gocloud.dev/blob/fileblob"
+)
+
+func TestChartRepositoryKind(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, helm.ChartRepositoryKindChartMuseum, helm.ChartRepositoryKind("http://jenkins-x-chartmuseum:8080"))
+	assert.Equal(t, helm.ChartRepositoryKindBucket, helm.ChartRepositoryKind("gs://my-charts"))
+	assert.Equal(t, helm.ChartRepositoryKindBucket, helm.ChartRepositoryKind("s3://my-charts/releases?region=eu-west-1"))
+	assert.Equal(t, helm.ChartRepositoryKindBucket, helm.ChartRepositoryKind("azblob://charts"))
+	assert.Equal(t, helm.ChartRepositoryKindOCI, helm.ChartRepositoryKind("oci://gcr.io/my-project/charts"))
+}
+
+func TestChartRepositoryReadURL(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "http://jenkins-x-chartmuseum:8080", helm.ChartRepositoryReadURL("http://jenkins-x-chartmuseum:8080"))
+	assert.Equal(t, "oci://gcr.io/my-project/charts", helm.ChartRepositoryReadURL("oci://gcr.io/my-project/charts"))
+	assert.Equal(t, "https://storage.googleapis.com/my-charts/releases", helm.ChartRepositoryReadURL("gs://my-charts/releases/"))
+	assert.Equal(t, "https://my-charts.s3.amazonaws.com", helm.ChartRepositoryReadURL("s3://my-charts"))
+	assert.Equal(t, "https://my-charts.s3.eu-west-1.amazonaws.com/releases", helm.ChartRepositoryReadURL("s3://my-charts/releases?region=eu-west-1"))
+}
+
+func TestPublishChartToBucket(t *testing.T) {
+	t.Parallel()
+
+	bucketDir, err := ioutil.TempDir("", "test-chart-bucket")
+	require.NoError(t, err)
+	defer os.RemoveAll(bucketDir)
+	chartDir, err := ioutil.TempDir("", "test-chart")
+	require.NoError(t, err)
+	defer os.RemoveAll(chartDir)
+
+	chartFile := filepath.Join(chartDir, "Chart.yaml")
+	require.NoError(t, ioutil.WriteFile(chartFile, []byte("name: myapp\nversion: 1.0.0\ndescription: my app\n"), 0600))
+	tarball := filepath.Join(chartDir, "myapp-1.0.0.tgz")
+	require.NoError(t, ioutil.WriteFile(tarball, []byte("chart"), 0600))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(bucketDir, "charts"), 0700))
+	repoURL := "file://" + filepath.ToSlash(bucketDir) + "/charts"
+	err = helm.PublishChartToBucket(repoURL, tarball, chartFile, time.Minute)
+	require.NoError(t, err)
+
+	assert.FileExists(t, filepath.Join(bucketDir, "charts", "myapp-1.0.0.tgz"))
+	index := loadIndex(t, filepath.Join(bucketDir, "charts", helm.IndexFileName))
+	version, err := index.Get("myapp", "1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"myapp-1.0.0.tgz"}, version.URLs)
+	assert.Equal(t, "my app", version.Description)
+	assert.NotEmpty(t, version.Digest)
+
+	// the next version is added to the existing index
+	require.NoError(t, ioutil.WriteFile(chartFile, []byte("name: myapp\nversion: 1.1.0\n"), 0600))
+	tarball = filepath.Join(chartDir, "myapp-1.1.0.tgz")
+	require.NoError(t, ioutil.WriteFile(tarball, []byte("chart"), 0600))
+	err = helm.PublishChartToBucket(repoURL, tarball, chartFile, time.Minute)
+	require.NoError(t, err)
+
+	index = loadIndex(t, filepath.Join(bucketDir, "charts", helm.IndexFileName))
+	assert.True(t, index.Has("myapp", "1.0.0"))
+	assert.True(t, index.Has("myapp", "1.1.0"))
+
+	// versions cannot be overwritten
+	err = helm.PublishChartToBucket(repoURL, tarball, chartFile, time.Minute)
+	assert.Error(t, err)
+}
+
+func loadIndex(t *testing.T, fileName string) *helmrepo.IndexFile {
+	data, err := ioutil.ReadFile(fileName)
+	require.NoError(t, err)
+	index := helmrepo.NewIndexFile()
+	require.NoError(t, yaml.Unmarshal(data, index))
+	return index
+}
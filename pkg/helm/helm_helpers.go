@@ -3,6 +3,7 @@ package helm
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/base64"
 	"fmt"
 	"io/ioutil"
@@ -26,8 +27,10 @@ import (
 
 	"github.com/jenkins-x/jx-logging/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/metrics"
 	"github.com/jenkins-x/jx/v2/pkg/secreturl"
 	"github.com/jenkins-x/jx/v2/pkg/table"
+	"github.com/jenkins-x/jx/v2/pkg/tracing"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	"k8s.io/client-go/kubernetes"
 
@@ -336,7 +339,7 @@ func SaveFile(fileName string, contents interface{}) error {
 	if err != nil {
 		return errors.Wrapf(err, "failed to marshal helm file %s", fileName)
 	}
-	err = ioutil.WriteFile(fileName, data, util.DefaultWritePermissions)
+	err = util.WriteCanonicalYAMLFile(fileName, data, util.DefaultWritePermissions)
 	if err != nil {
 		return errors.Wrapf(err, "failed to save helm file %s", fileName)
 	}
@@ -666,6 +669,22 @@ type InstallChartOptions struct {
 // respecting the installTimeout, looking up or updating Vault with the username and password for the repo.
 // If secretURLClient is nil then username and passwords for repos will not be looked up in Vault.
 func InstallFromChartOptions(options InstallChartOptions, helmer Helmer, kubeClient kubernetes.Interface,
+	installTimeout string, secretURLClient secreturl.Client) error {
+	_, span := tracing.StartSpan(context.Background(), "helm install "+options.ReleaseName,
+		tracing.String("helm.chart", options.Chart),
+		tracing.String("helm.release", options.ReleaseName),
+		tracing.String("helm.namespace", options.Ns))
+	endOperation := util.StartLogOperation("helm install " + options.ReleaseName)
+	err := installFromChartOptions(options, helmer, kubeClient, installTimeout, secretURLClient)
+	if err != nil {
+		metrics.HelmInstallFailures.WithLabelValues(options.Chart).Inc()
+	}
+	endOperation()
+	span.End(err)
+	return err
+}
+
+func installFromChartOptions(options InstallChartOptions, helmer Helmer, kubeClient kubernetes.Interface,
 	installTimeout string, secretURLClient secreturl.Client) error {
 	chart := options.Chart
 	if options.Version == "" {
@@ -1010,7 +1029,7 @@ func PromptForRepoCredsIfNeeded(repo string, cred *HelmRepoCredential, handles u
 				Default: cred.Username,
 				Help:    fmt.Sprintf("Enter the username for %s", repo),
 			}
-			err := survey.AskOne(&usernamePrompt, &cred.Username, nil, surveyOpts)
+			err := util.AskOne(&usernamePrompt, &cred.Username, nil, surveyOpts)
 			if err != nil {
 				return errors.Wrapf(err, "asking for username")
 			}
@@ -1018,7 +1037,7 @@ func PromptForRepoCredsIfNeeded(repo string, cred *HelmRepoCredential, handles u
 				Message: "Repository password",
 				Help:    fmt.Sprintf("Enter the password for %s", repo),
 			}
-			err = survey.AskOne(&passwordPrompt, &cred.Password, nil, surveyOpts)
+			err = util.AskOne(&passwordPrompt, &cred.Password, nil, surveyOpts)
 			if err != nil {
 				return errors.Wrapf(err, "asking for password")
 			}
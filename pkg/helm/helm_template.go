@@ -66,6 +66,8 @@ type HelmTemplate struct {
 	KubectlValidate bool
 	KubeClient      kubernetes.Interface
 	Namespace       string
+	// NoPrune disables the deletion of the resources of a release which are no longer rendered by its chart
+	NoPrune bool
 }
 
 // NewHelmTemplate creates a new HelmTemplate instance configured to the given client side Helmer
@@ -285,19 +287,18 @@ func (h *HelmTemplate) InstallChart(chart string, releaseName string, ns string,
 	helmPostPhase := "post-install"
 	wait := true
 	create := true
-	force := true
 
-	err = h.runHooks(helmHooks, helmCrdPhase, ns, chart, releaseName, wait, create, force)
+	err = h.runHooks(helmHooks, helmCrdPhase, ns, chart, releaseName, wait, create)
 	if err != nil {
 		return err
 	}
 
-	err = h.runHooks(helmHooks, helmPrePhase, ns, chart, releaseName, wait, create, force)
+	err = h.runHooks(helmHooks, helmPrePhase, ns, chart, releaseName, wait, create)
 	if err != nil {
 		return err
 	}
 
-	err = h.kubectlApply(ns, releaseName, wait, create, force, outputDir)
+	err = h.kubectlApply(ns, releaseName, wait, create, outputDir)
 	if err != nil {
 		err2 := h.deleteHooks(helmHooks, helmPrePhase, hookFailed, ns)
 		return errorutil.CombineErrors(err, err2)
@@ -307,16 +308,17 @@ func (h *HelmTemplate) InstallChart(chart string, releaseName string, ns string,
 		log.Logger().Warnf("Failed to delete the %s hook, due to: %s", helmPrePhase, err)
 	}
 
-	err = h.runHooks(helmHooks, helmPostPhase, ns, chart, releaseName, wait, create, force)
+	err = h.runHooks(helmHooks, helmPostPhase, ns, chart, releaseName, wait, create)
 	if err != nil {
 		err2 := h.deleteHooks(helmHooks, helmPostPhase, hookFailed, ns)
 		return errorutil.CombineErrors(err, err2)
 	}
 
 	err = h.deleteHooks(helmHooks, helmPostPhase, hookSucceeded, ns)
-	err2 := h.deleteOldResources(ns, releaseName, versionText, wait)
+	err2 := h.pruneRemovedResources(ns, releaseName, versionText, outputDir)
+	err3 := h.deleteOldResources(ns, releaseName, versionText, wait)
 
-	return errorutil.CombineErrors(err, err2)
+	return errorutil.CombineErrors(err, err2, err3)
 }
 
 // FetchChart fetches a Helm Chart
@@ -326,7 +328,9 @@ func (h *HelmTemplate) FetchChart(chart string, version string, untar bool, unta
 	return err
 }
 
-// UpgradeChart upgrades a helm chart according with given helm flags
+// UpgradeChart upgrades a helm chart according with given helm flags. The force flag is ignored: the manifests are
+// applied server-side which kubectl does not allow to combine with --force, and the conflicting fields of the resources
+// of the release are always taken over by jx
 func (h *HelmTemplate) UpgradeChart(chart string, releaseName string, ns string, version string, install bool, timeout int, force bool, wait bool, values []string, valueStrings []string, valueFiles []string, repo string, username string, password string) error {
 	err := h.clearOutputDir(releaseName)
 	if err != nil {
@@ -375,17 +379,17 @@ func (h *HelmTemplate) UpgradeChart(chart string, releaseName string, ns string,
 	helmPostPhase := "post-upgrade"
 	create := false
 
-	err = h.runHooks(helmHooks, helmCrdPhase, ns, chart, releaseName, wait, create, force)
+	err = h.runHooks(helmHooks, helmCrdPhase, ns, chart, releaseName, wait, create)
 	if err != nil {
 		return err
 	}
 
-	err = h.runHooks(helmHooks, helmPrePhase, ns, chart, releaseName, wait, create, force)
+	err = h.runHooks(helmHooks, helmPrePhase, ns, chart, releaseName, wait, create)
 	if err != nil {
 		return err
 	}
 
-	err = h.kubectlApply(ns, releaseName, wait, create, force, outputDir)
+	err = h.kubectlApply(ns, releaseName, wait, create, outputDir)
 	if err != nil {
 		err2 := h.deleteHooks(helmHooks, helmPrePhase, hookFailed, ns)
 		return errorutil.CombineErrors(err, err2)
@@ -395,23 +399,24 @@ func (h *HelmTemplate) UpgradeChart(chart string, releaseName string, ns string,
 		log.Logger().Warnf("Failed to delete the %s hook, due to: %s", helmPrePhase, err)
 	}
 
-	err = h.runHooks(helmHooks, helmPostPhase, ns, chart, releaseName, wait, create, force)
+	err = h.runHooks(helmHooks, helmPostPhase, ns, chart, releaseName, wait, create)
 	if err != nil {
 		err2 := h.deleteHooks(helmHooks, helmPostPhase, hookFailed, ns)
 		return errorutil.CombineErrors(err, err2)
 	}
 
 	err = h.deleteHooks(helmHooks, helmPostPhase, hookSucceeded, ns)
-	err2 := h.deleteOldResources(ns, releaseName, versionText, wait)
+	err2 := h.pruneRemovedResources(ns, releaseName, versionText, outputDir)
+	err3 := h.deleteOldResources(ns, releaseName, versionText, wait)
 
-	return errorutil.CombineErrors(err, err2)
+	return errorutil.CombineErrors(err, err2, err3)
 }
 
 func (h *HelmTemplate) DecryptSecrets(location string) error {
 	return h.Client.DecryptSecrets(location)
 }
 
-func (h *HelmTemplate) kubectlApply(ns string, releaseName string, wait bool, create bool, force bool, dir string) error {
+func (h *HelmTemplate) kubectlApply(ns string, releaseName string, wait bool, create bool, dir string) error {
 	namespacesDir := filepath.Join(dir, "namespaces")
 	if _, err := os.Stat(namespacesDir); !os.IsNotExist(err) {
 
@@ -426,11 +431,11 @@ func (h *HelmTemplate) kubectlApply(ns string, releaseName string, wait bool, cr
 
 			log.Logger().Debugf("Applying generated chart %q YAML via kubectl in dir: %s to namespace %s", releaseName, fullPath, namespace)
 
-			command := "apply"
-			if create {
-				command = "create"
+			args := []string{"create"}
+			if !create {
+				args = append([]string{"apply"}, kube.ServerSideApplyArgs()...)
 			}
-			args := []string{command, "--recursive", "-f", fullPath, "-l", LabelReleaseName + "=" + releaseName}
+			args = append(args, "--recursive", "-f", fullPath, "-l", LabelReleaseName+"="+releaseName)
 			applyNs := namespace
 			if applyNs == "" {
 				applyNs = ns
@@ -454,20 +459,17 @@ func (h *HelmTemplate) kubectlApply(ns string, releaseName string, wait bool, cr
 	}
 
 	log.Logger().Debugf("Applying generated chart %q YAML via kubectl in dir: %s to namespace %s", releaseName, dir, ns)
-	command := "apply"
-	if create {
-		command = "create"
+	args := []string{"create"}
+	if !create {
+		args = append([]string{"apply"}, kube.ServerSideApplyArgs()...)
 	}
-	args := []string{command, "--recursive", "-f", dir, "-l", LabelReleaseName + "=" + releaseName}
+	args = append(args, "--recursive", "-f", dir, "-l", LabelReleaseName+"="+releaseName)
 	if ns != "" {
 		args = append(args, "--namespace", ns)
 	}
 	if wait && !create {
 		args = append(args, "--wait")
 	}
-	if force {
-		args = append(args, "--force")
-	}
 	if !h.KubectlValidate {
 		args = append(args, "--validate=false")
 	}
@@ -480,23 +482,20 @@ func (h *HelmTemplate) kubectlApply(ns string, releaseName string, wait bool, cr
 
 }
 
-func (h *HelmTemplate) kubectlApplyFile(ns string, helmHook string, wait bool, create bool, force bool, file string) error {
+func (h *HelmTemplate) kubectlApplyFile(ns string, helmHook string, wait bool, create bool, file string) error {
 	log.Logger().Debugf("Applying Helm hook %s YAML via kubectl in file: %s", helmHook, file)
 
-	command := "apply"
-	if create {
-		command = "create"
+	args := []string{"create"}
+	if !create {
+		args = append([]string{"apply"}, kube.ServerSideApplyArgs()...)
 	}
-	args := []string{command, "-f", file}
+	args = append(args, "-f", file)
 	if ns != "" {
 		args = append(args, "--namespace", ns)
 	}
 	if wait && !create {
 		args = append(args, "--wait")
 	}
-	if force && !create {
-		args = append(args, "--force")
-	}
 	if !h.KubectlValidate {
 		args = append(args, "--validate=false")
 	}
@@ -510,6 +509,11 @@ func (h *HelmTemplate) kubectlDeleteFile(ns string, file string) error {
 }
 
 func (h *HelmTemplate) deleteOldResources(ns string, releaseName string, versionText string, wait bool) error {
+	if h.NoPrune {
+		// the resources of the previous versions are kept along with those which are no longer rendered
+		log.Logger().Debugf("Not deleting the resources of older versions of release %s", releaseName)
+		return nil
+	}
 	selector := LabelReleaseName + "=" + releaseName + "," + LabelReleaseChartVersion + "!=" + versionText
 	err := h.deleteNamespacedResourcesBySelector(ns, selector, wait, "older releases")
 	if err != nil {
@@ -914,6 +918,10 @@ func processChartResource(partFile string, data []byte, kind string, ns string,
 	if err != nil {
 		return errors.Wrapf(err, "Failed to modify YAML of partFile %s", partFile)
 	}
+	err = setYamlValue(m, kube.ApplySetID(ns, releaseName), "metadata", "labels", kube.LabelApplySet)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to modify YAML of partFile %s", partFile)
+	}
 	chartName := ""
 
 	if metadata != nil {
@@ -1098,10 +1106,10 @@ func (h *HelmTemplate) getChart(chartDir string, version string) (*chart.Metadat
 	return metadata, version, err
 }
 
-func (h *HelmTemplate) runHooks(hooks []*HelmHook, hookPhase string, ns string, chart string, releaseName string, wait bool, create bool, force bool) error {
+func (h *HelmTemplate) runHooks(hooks []*HelmHook, hookPhase string, ns string, chart string, releaseName string, wait bool, create bool) error {
 	matchingHooks := MatchingHooks(hooks, hookPhase, "")
 	for _, hook := range matchingHooks {
-		err := h.kubectlApplyFile(ns, hookPhase, wait, create, force, hook.File)
+		err := h.kubectlApplyFile(ns, hookPhase, wait, create, hook.File)
 		if err != nil {
 			return err
 		}
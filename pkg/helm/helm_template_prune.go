@@ -0,0 +1,231 @@
+package helm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/errorutil"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// inventoryConfigMapPrefix the prefix of the ConfigMap of the resources applied by a release in template mode
+	inventoryConfigMapPrefix = "jx-applyset-"
+	// inventoryKey the key of the resources in the data of the inventory ConfigMap
+	inventoryKey = "resources"
+)
+
+// TemplateResource a resource applied by a release in template mode
+type TemplateResource struct {
+	APIVersion string `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string `json:"kind" yaml:"kind"`
+	Namespace  string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Name       string `json:"name" yaml:"name"`
+}
+
+// String returns the kind and name of the resource as used by kubectl such as deployment.apps/myapp
+func (r TemplateResource) String() string {
+	kind := strings.ToLower(r.Kind)
+	if i := strings.LastIndex(r.APIVersion, "/"); i > 0 {
+		kind += "." + r.APIVersion[:i]
+	}
+	return kind + "/" + r.Name
+}
+
+// key identifies the resource regardless of the version of its API
+func (r TemplateResource) key() string {
+	return r.Namespace + "/" + r.String()
+}
+
+// renderedResources returns the resources of the release rendered into the namespaces directory of the output directory
+// excluding the helm hooks which are moved out of it
+func renderedResources(outputDir string) ([]TemplateResource, error) {
+	resources := []TemplateResource{}
+	namespacesDir := filepath.Join(outputDir, "namespaces")
+	if _, err := os.Stat(namespacesDir); os.IsNotExist(err) {
+		return resources, nil
+	}
+	err := filepath.Walk(namespacesDir, func(path string, f os.FileInfo, err error) error {
+		if err != nil || f.IsDir() || filepath.Ext(path) != ".yaml" {
+			return err
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "reading %s", path)
+		}
+		m := yaml.MapSlice{}
+		err = yaml.Unmarshal(data, &m)
+		if err != nil {
+			return errors.Wrapf(err, "parsing %s", path)
+		}
+		resource := TemplateResource{
+			APIVersion: getYamlValueString(&m, "apiVersion"),
+			Kind:       getYamlValueString(&m, "kind"),
+			Name:       getYamlValueString(&m, "metadata", "name"),
+		}
+		if resource.Kind == "" || resource.Name == "" {
+			return nil
+		}
+		if !isClusterKind(resource.Kind) {
+			rel, err := filepath.Rel(namespacesDir, path)
+			if err != nil {
+				return err
+			}
+			resource.Namespace = strings.Split(filepath.ToSlash(rel), "/")[0]
+		}
+		resources = append(resources, resource)
+		return nil
+	})
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].key() < resources[j].key()
+	})
+	return resources, err
+}
+
+// removedResources returns the previously applied resources which are no longer rendered
+func removedResources(previous []TemplateResource, current []TemplateResource) []TemplateResource {
+	keys := map[string]bool{}
+	for _, r := range current {
+		keys[r.key()] = true
+	}
+	removed := []TemplateResource{}
+	for _, r := range previous {
+		if !keys[r.key()] {
+			removed = append(removed, r)
+		}
+	}
+	return removed
+}
+
+// pruneRemovedResources deletes the resources applied by the previous version of the release which are no longer
+// rendered, unless pruning is disabled, and then records the resources of the release for the next upgrade
+func (h *HelmTemplate) pruneRemovedResources(ns string, releaseName string, versionText string, outputDir string) error {
+	if h.KubeClient == nil {
+		return nil
+	}
+	current, err := renderedResources(outputDir)
+	if err != nil {
+		return errors.Wrapf(err, "listing the resources of release %s", releaseName)
+	}
+	previous, err := h.loadInventory(ns, releaseName)
+	if err != nil {
+		return err
+	}
+	errs := []error{}
+	if h.NoPrune {
+		for _, r := range removedResources(previous, current) {
+			log.Logger().Infof("Not pruning %s of release %s which is no longer rendered", util.ColorInfo(r.String()), releaseName)
+		}
+		// lets keep tracking the resources which were not pruned so that a later upgrade can prune them
+		current = append(current, removedResources(previous, current)...)
+	} else {
+		for _, r := range removedResources(previous, current) {
+			err = h.deleteIfInApplySet(r, kube.ApplySetID(ns, releaseName))
+			if err != nil {
+				errs = append(errs, err)
+				current = append(current, r)
+			}
+		}
+	}
+	errs = append(errs, h.saveInventory(ns, releaseName, versionText, current))
+	return errorutil.CombineErrors(errs...)
+}
+
+// deleteIfInApplySet deletes the resource if it is still labelled as part of the apply set of the release so that
+// resources since adopted by another release or created by something else are never deleted
+func (h *HelmTemplate) deleteIfInApplySet(r TemplateResource, applySet string) error {
+	args := []string{"get", r.String(), "--ignore-not-found", "-o", "jsonpath={.metadata.labels.jenkins\\.io/apply-set}"}
+	if r.Namespace != "" {
+		args = append(args, "--namespace", r.Namespace)
+	}
+	output, err := h.runKubectlWithOutput(args...)
+	if err != nil {
+		return errors.Wrapf(err, "getting %s", r.String())
+	}
+	if strings.TrimSpace(output) != applySet {
+		log.Logger().Debugf("Not pruning %s as it is not part of the apply set %s", r.String(), applySet)
+		return nil
+	}
+	log.Logger().Infof("Pruning %s which is no longer part of the release", util.ColorInfo(r.String()))
+	args = []string{"delete", r.String(), "--ignore-not-found"}
+	if r.Namespace != "" {
+		args = append(args, "--namespace", r.Namespace)
+	}
+	err = h.runKubectl(args...)
+	if err != nil {
+		return errors.Wrapf(err, "pruning %s", r.String())
+	}
+	return nil
+}
+
+// loadInventory loads the resources the release applied on its last install or upgrade
+func (h *HelmTemplate) loadInventory(ns string, releaseName string) ([]TemplateResource, error) {
+	resources := []TemplateResource{}
+	cm, err := h.KubeClient.CoreV1().ConfigMaps(ns).Get(inventoryConfigMapName(releaseName), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return resources, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading the resources of release %s", releaseName)
+	}
+	err = yaml.Unmarshal([]byte(cm.Data[inventoryKey]), &resources)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the resources of release %s", releaseName)
+	}
+	return resources, nil
+}
+
+// saveInventory records the resources applied by the release. The ConfigMap is labelled with the release and its
+// version so that it is removed along with the release
+func (h *HelmTemplate) saveInventory(ns string, releaseName string, versionText string, resources []TemplateResource) error {
+	data, err := yaml.Marshal(resources)
+	if err != nil {
+		return errors.Wrapf(err, "marshalling the resources of release %s", releaseName)
+	}
+	labels := map[string]string{
+		LabelReleaseName:         releaseName,
+		LabelReleaseChartVersion: versionText,
+		kube.LabelApplySet:       kube.ApplySetID(ns, releaseName),
+	}
+	configMaps := h.KubeClient.CoreV1().ConfigMaps(ns)
+	cm, err := configMaps.Get(inventoryConfigMapName(releaseName), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		cm = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   inventoryConfigMapName(releaseName),
+				Labels: labels,
+			},
+			Data: map[string]string{inventoryKey: string(data)},
+		}
+		_, err = configMaps.Create(cm)
+	} else if err == nil {
+		if cm.Labels == nil {
+			cm.Labels = map[string]string{}
+		}
+		for k, v := range labels {
+			cm.Labels[k] = v
+		}
+		cm.Data = map[string]string{inventoryKey: string(data)}
+		_, err = configMaps.Update(cm)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "saving the resources of release %s", releaseName)
+	}
+	return nil
+}
+
+// inventoryConfigMapName returns the name of the ConfigMap of the resources applied by the release
+func inventoryConfigMapName(releaseName string) string {
+	return fmt.Sprintf("%s%s", inventoryConfigMapPrefix, releaseName)
+}
@@ -15,9 +15,14 @@ import (
 	"k8s.io/helm/pkg/proto/hapi/chart"
 
 	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/jenkins-x/jx/v2/pkg/util"
+	mocks "github.com/jenkins-x/jx/v2/pkg/util/mocks"
+	. "github.com/petergtz/pegomock"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestAddYamlLabels(t *testing.T) {
@@ -66,6 +71,7 @@ func TestAddYamlLabels(t *testing.T) {
 					if labels != nil {
 						assertLabelValue(t, expectedChartRelease, labels, LabelReleaseName, path)
 						assertLabelValue(t, expectedChartVersion, labels, LabelReleaseChartVersion, path)
+						assertLabelValue(t, kube.ApplySetID(expectedNamespace, expectedChartRelease), labels, kube.LabelApplySet, path)
 
 						if !strings.HasSuffix(file, "clusterrole.yaml") {
 							assertLabelValue(t, expectedNamespace, labels, LabelNamespace, path)
@@ -207,3 +213,123 @@ func TestSplitObjectsInFilesWithHooks(t *testing.T) {
 		})
 	}
 }
+
+func TestKubectlApplyUsesServerSideApply(t *testing.T) {
+	RegisterMockTestingT(t)
+	runner := mocks.NewMockCommander()
+	When(runner.RunWithoutRetry()).ThenReturn("", nil)
+	h := &HelmTemplate{Runner: runner, Binary: "kubectl"}
+
+	dir, err := ioutil.TempDir("", "test-kubectl-apply")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	err = h.kubectlApply("jx", "cheese", true, false, dir)
+	require.NoError(t, err)
+	runner.VerifyWasCalledOnce().SetArgs([]string{"apply", "--server-side", "--field-manager=jx", "--force-conflicts",
+		"--recursive", "-f", dir, "-l", LabelReleaseName + "=cheese", "--namespace", "jx", "--wait", "--validate=false"})
+
+	err = h.kubectlApply("jx", "cheese", false, true, dir)
+	require.NoError(t, err)
+	runner.VerifyWasCalledOnce().SetArgs([]string{"create", "--recursive", "-f", dir, "-l", LabelReleaseName + "=cheese",
+		"--namespace", "jx", "--validate=false"})
+}
+
+func TestPruneRemovedResources(t *testing.T) {
+	RegisterMockTestingT(t)
+	runner := mocks.NewMockCommander()
+	When(runner.RunWithoutRetry()).ThenReturn(kube.ApplySetID("jx", "cheese"), nil)
+
+	previous := `- apiVersion: apps/v1
+  kind: Deployment
+  namespace: jx
+  name: cheese
+- apiVersion: v1
+  kind: Service
+  namespace: jx
+  name: old-cheese
+- apiVersion: rbac.authorization.k8s.io/v1
+  kind: ClusterRole
+  name: old-cheese
+`
+	kubeClient := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "jx-applyset-cheese", Namespace: "jx"},
+		Data:       map[string]string{"resources": previous},
+	})
+	h := &HelmTemplate{Runner: runner, Binary: "kubectl", KubeClient: kubeClient}
+
+	outputDir, err := ioutil.TempDir("", "test-prune-removed-resources")
+	require.NoError(t, err)
+	defer os.RemoveAll(outputDir)
+	templatesDir := filepath.Join(outputDir, "namespaces", "jx", "cheese", "templates")
+	require.NoError(t, os.MkdirAll(templatesDir, util.DefaultWritePermissions))
+	deployment := "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: cheese\n"
+	require.NoError(t, ioutil.WriteFile(filepath.Join(templatesDir, "deployment.yaml"), []byte(deployment), util.DefaultFileWritePermissions))
+
+	err = h.pruneRemovedResources("jx", "cheese", "1.0.1", outputDir)
+	require.NoError(t, err)
+
+	runner.VerifyWasCalledOnce().SetArgs([]string{"delete", "service/old-cheese", "--ignore-not-found", "--namespace", "jx"})
+	runner.VerifyWasCalledOnce().SetArgs([]string{"delete", "clusterrole.rbac.authorization.k8s.io/old-cheese", "--ignore-not-found"})
+	runner.VerifyWasCalled(Never()).SetArgs([]string{"delete", "deployment.apps/cheese", "--ignore-not-found", "--namespace", "jx"})
+
+	cm, err := kubeClient.CoreV1().ConfigMaps("jx").Get("jx-applyset-cheese", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.1", cm.Labels[LabelReleaseChartVersion])
+	resources, err := h.loadInventory("jx", "cheese")
+	require.NoError(t, err)
+	assert.Equal(t, []TemplateResource{{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "jx", Name: "cheese"}}, resources)
+}
+
+func TestPruneRemovedResourcesSkipsOtherApplySets(t *testing.T) {
+	RegisterMockTestingT(t)
+	runner := mocks.NewMockCommander()
+	When(runner.RunWithoutRetry()).ThenReturn("wine.jx", nil)
+
+	previous := "- apiVersion: v1\n  kind: Service\n  namespace: jx\n  name: old-cheese\n"
+	kubeClient := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "jx-applyset-cheese", Namespace: "jx"},
+		Data:       map[string]string{"resources": previous},
+	})
+	h := &HelmTemplate{Runner: runner, Binary: "kubectl", KubeClient: kubeClient}
+
+	outputDir, err := ioutil.TempDir("", "test-prune-removed-resources")
+	require.NoError(t, err)
+	defer os.RemoveAll(outputDir)
+
+	err = h.pruneRemovedResources("jx", "cheese", "1.0.1", outputDir)
+	require.NoError(t, err)
+	runner.VerifyWasCalled(Never()).SetArgs([]string{"delete", "service/old-cheese", "--ignore-not-found", "--namespace", "jx"})
+
+	h.NoPrune = true
+	kubeClient.CoreV1().ConfigMaps("jx").Delete("jx-applyset-cheese", nil)
+	_, err = kubeClient.CoreV1().ConfigMaps("jx").Create(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "jx-applyset-cheese", Namespace: "jx"},
+		Data:       map[string]string{"resources": previous},
+	})
+	require.NoError(t, err)
+	err = h.pruneRemovedResources("jx", "cheese", "1.0.2", outputDir)
+	require.NoError(t, err)
+	runner.VerifyWasCalledOnce().SetArgs([]string{"get", "service/old-cheese", "--ignore-not-found", "-o",
+		"jsonpath={.metadata.labels.jenkins\\.io/apply-set}", "--namespace", "jx"})
+	resources, err := h.loadInventory("jx", "cheese")
+	require.NoError(t, err)
+	assert.Len(t, resources, 1, "resources which are not pruned should still be tracked")
+}
+
+func TestDeleteOldResourcesKeepsOlderVersionsWhenNotPruning(t *testing.T) {
+	RegisterMockTestingT(t)
+	runner := mocks.NewMockCommander()
+	When(runner.RunWithoutRetry()).ThenReturn("No resources found", nil)
+	h := &HelmTemplate{Runner: runner, Binary: "kubectl", KubeClient: fake.NewSimpleClientset(), NoPrune: true}
+
+	err := h.deleteOldResources("jx", "cheese", "1.0.2", true)
+	require.NoError(t, err)
+	runner.VerifyWasCalled(Never()).RunWithoutRetry()
+
+	h.NoPrune = false
+	err = h.deleteOldResources("jx", "cheese", "1.0.2", true)
+	require.NoError(t, err)
+	runner.VerifyWasCalledOnce().SetArgs([]string{"delete", "pvc", "--ignore-not-found", "-l",
+		LabelReleaseName + "=cheese," + LabelReleaseChartVersion + "!=1.0.2", "--namespace", "jx", "--wait"})
+}
@@ -0,0 +1,56 @@
+package helm
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DiffValues compares two helm values trees and returns a sorted, human readable list of the leaf values which
+// were added (+), removed (-) or changed (~) when going from current to target
+func DiffValues(current map[string]interface{}, target map[string]interface{}) []string {
+	currentLeaves := map[string]interface{}{}
+	flattenValues("", current, currentLeaves)
+	targetLeaves := map[string]interface{}{}
+	flattenValues("", target, targetLeaves)
+
+	paths := []string{}
+	for p := range currentLeaves {
+		paths = append(paths, p)
+	}
+	for p := range targetLeaves {
+		if _, ok := currentLeaves[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	answer := []string{}
+	for _, p := range paths {
+		oldValue, oldExists := currentLeaves[p]
+		newValue, newExists := targetLeaves[p]
+		switch {
+		case !oldExists:
+			answer = append(answer, fmt.Sprintf("+ %s: %v", p, newValue))
+		case !newExists:
+			answer = append(answer, fmt.Sprintf("- %s: %v", p, oldValue))
+		case fmt.Sprintf("%v", oldValue) != fmt.Sprintf("%v", newValue):
+			answer = append(answer, fmt.Sprintf("~ %s: %v -> %v", p, oldValue, newValue))
+		}
+	}
+	return answer
+}
+
+func flattenValues(prefix string, values map[string]interface{}, leaves map[string]interface{}) {
+	for k, v := range values {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		child, ok := v.(map[string]interface{})
+		if ok && len(child) > 0 {
+			flattenValues(path, child, leaves)
+			continue
+		}
+		leaves[path] = v
+	}
+}
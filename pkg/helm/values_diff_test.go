@@ -0,0 +1,47 @@
+// +build unit
+
+package helm_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffValues(t *testing.T) {
+	t.Parallel()
+
+	current := map[string]interface{}{
+		"rbac": map[string]interface{}{
+			"create": true,
+		},
+		"controller": map[string]interface{}{
+			"replicaCount": 1,
+			"image": map[string]interface{}{
+				"tag": "0.25.1",
+			},
+		},
+	}
+	target := map[string]interface{}{
+		"rbac": map[string]interface{}{
+			"create": true,
+		},
+		"controller": map[string]interface{}{
+			"replicaCount": 2,
+			"service": map[string]interface{}{
+				"loadBalancerIP": "1.2.3.4",
+			},
+		},
+	}
+
+	actual := helm.DiffValues(current, target)
+	expected := []string{
+		"- controller.image.tag: 0.25.1",
+		"~ controller.replicaCount: 1 -> 2",
+		"+ controller.service.loadBalancerIP: 1.2.3.4",
+	}
+	assert.Equal(t, expected, actual)
+
+	assert.Empty(t, helm.DiffValues(target, target))
+}
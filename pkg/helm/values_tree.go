@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/template"
 
@@ -157,7 +158,14 @@ func GenerateValues(requirements *config.RequirementsConfig, funcMap template.Fu
 		element[key] = string(b)
 		return nil
 	}
-	for p, v := range values {
+	// merge in a stable order so that the generated values are identical on every run
+	valuePaths := make([]string, 0, len(values))
+	for p := range values {
+		valuePaths = append(valuePaths, p)
+	}
+	sort.Strings(valuePaths)
+	for _, p := range valuePaths {
+		v := values[p]
 		// First, do file substitution - but only if any files were actually found
 		if dirFiles := files[p]; dirFiles != nil && len(dirFiles) > 0 {
 			err := HandleExternalFileRefs(v, dirFiles, "", externalFileHandler)
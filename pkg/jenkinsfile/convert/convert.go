@@ -0,0 +1,426 @@
+package convert
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/jenkinsfile"
+	"github.com/jenkins-x/jx/v2/pkg/tekton/syntax"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var (
+	// groovyEnvRegex matches the references to environment variables of Groovy strings such as ${env.FOO}
+	groovyEnvRegex = regexp.MustCompile(`\$\{env\.([A-Za-z_][A-Za-z0-9_]*)\}`)
+	// groovyExpressionRegex matches the Groovy expressions of strings which are not environment variables
+	groovyExpressionRegex = regexp.MustCompile(`\$\{[^}]*[.(\[][^}]*\}`)
+	// envEntryRegex matches the entries of withEnv such as FOO=bar
+	envEntryRegex = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)=(.*)$`)
+
+	// ignoredSteps the steps which are done by the pipelines of Jenkins X already
+	ignoredSteps = map[string]bool{
+		"checkout":  true,
+		"deleteDir": true,
+		"cleanWs":   true,
+	}
+
+	// wrapperSteps the steps whose blocks are converted but whose behaviour is not
+	wrapperSteps = map[string]string{
+		"timeout":         "use options.timeout of the stage instead",
+		"retry":           "use options.retry of the stage instead",
+		"withCredentials": "mount the credentials from a Secret via the env or the containerOptions of the stage",
+		"container":       "use the agent image of the stage instead",
+		"node":            "use the agent image of the stage instead",
+		"timestamps":      "the logs of the steps are timestamped already",
+		"ansiColor":       "the logs of the steps keep their colors already",
+	}
+)
+
+// Target the pipelines of a project configuration a stage is converted to
+type Target int
+
+const (
+	// TargetAll the stage runs in the pull request and the release pipelines
+	TargetAll Target = iota
+	// TargetRelease the stage only runs in the release pipeline
+	TargetRelease
+	// TargetPullRequest the stage only runs in the pull request pipeline
+	TargetPullRequest
+)
+
+// Issue a construct of a Jenkinsfile which could not be converted
+type Issue struct {
+	Line    int
+	Message string
+}
+
+// String returns a description of the issue
+func (i Issue) String() string {
+	return fmt.Sprintf("line %d: %s", i.Line, i.Message)
+}
+
+// Result the pipelines converted from a Jenkinsfile along with the constructs which could not be converted
+type Result struct {
+	Release     *syntax.ParsedPipeline
+	PullRequest *syntax.ParsedPipeline
+	Issues      []Issue
+}
+
+// ProjectConfig returns the project configuration of the converted pipelines which do not use a build pack
+func (r *Result) ProjectConfig() *config.ProjectConfig {
+	pipelines := jenkinsfile.Pipelines{}
+	if r.Release != nil {
+		pipelines.Release = &jenkinsfile.PipelineLifecycles{Pipeline: r.Release}
+	}
+	if r.PullRequest != nil {
+		pipelines.PullRequest = &jenkinsfile.PipelineLifecycles{Pipeline: r.PullRequest}
+	}
+	return &config.ProjectConfig{
+		BuildPack: "none",
+		PipelineConfig: &jenkinsfile.PipelineConfig{
+			Pipelines: pipelines,
+		},
+	}
+}
+
+// Jenkinsfile converts a declarative Jenkinsfile to the pipelines of a project configuration. The default image is
+// used by the steps of the pipeline unless the Jenkinsfile uses a docker agent
+func Jenkinsfile(source string, defaultImage string) (*Result, error) {
+	nodes, err := Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	c := &converter{}
+	var pipeline *Node
+	for _, n := range nodes {
+		if n.Name == "pipeline" && n.Block {
+			pipeline = n
+			continue
+		}
+		c.unsupported(n, "only the pipeline block of declarative Jenkinsfiles is converted")
+	}
+	if pipeline == nil {
+		return nil, fmt.Errorf("no declarative pipeline block found, scripted pipelines are not supported")
+	}
+
+	release := &syntax.ParsedPipeline{}
+	pullRequest := &syntax.ParsedPipeline{}
+	var stages *Node
+	for _, n := range pipeline.Children {
+		switch n.Name {
+		case "agent":
+			release.Agent = c.agent(n)
+		case "environment":
+			release.Env = c.env(n)
+		case "options":
+			release.Options = c.options(n)
+		case "stages":
+			stages = n
+		case "post":
+			c.unsupported(n, "post conditions are not supported, run the steps as the last stage of the pipeline instead")
+		case "tools":
+			c.unsupported(n, "tools are not supported, use an agent image which contains them instead")
+		case "parameters", "triggers", "libraries":
+			c.unsupported(n, n.Name+" are not supported, pipelines are triggered by the git events of the repository")
+		default:
+			c.unsupported(n, "unsupported pipeline directive "+n.Name)
+		}
+	}
+	if release.Agent == nil {
+		release.Agent = &syntax.Agent{}
+	}
+	if release.Agent.Image == "" {
+		release.Agent.Image = defaultImage
+	}
+	if stages == nil {
+		return nil, fmt.Errorf("line %d: the pipeline has no stages", pipeline.Line)
+	}
+	pullRequest.Agent = release.Agent
+	pullRequest.Env = release.Env
+	pullRequest.Options = release.Options
+
+	for _, n := range stages.Children {
+		stage, target, ok := c.stage(n)
+		if !ok {
+			continue
+		}
+		if target != TargetPullRequest {
+			release.Stages = append(release.Stages, stage)
+		}
+		if target != TargetRelease {
+			pullRequest.Stages = append(pullRequest.Stages, stage)
+		}
+	}
+
+	sort.SliceStable(c.issues, func(i, j int) bool {
+		return c.issues[i].Line < c.issues[j].Line
+	})
+	answer := &Result{Issues: c.issues}
+	if len(release.Stages) > 0 {
+		answer.Release = release
+	}
+	if len(pullRequest.Stages) > 0 {
+		answer.PullRequest = pullRequest
+	}
+	return answer, nil
+}
+
+type converter struct {
+	issues []Issue
+}
+
+func (c *converter) unsupported(n *Node, message string) {
+	c.issues = append(c.issues, Issue{Line: n.Line, Message: fmt.Sprintf("%s: %s", message, n.Text())})
+}
+
+func (c *converter) agent(n *Node) *syntax.Agent {
+	args := ParseArgs(n.Args)
+	if !n.Block {
+		switch args.First("") {
+		case "any", "none", "":
+			return nil
+		}
+		c.unsupported(n, "unsupported agent")
+		return nil
+	}
+	for _, child := range n.Children {
+		switch child.Name {
+		case "docker":
+			image := ParseArgs(child.Args).First("image")
+			if child.Block {
+				for _, d := range child.Children {
+					switch d.Name {
+					case "image":
+						image = ParseArgs(d.Args).First("")
+					case "reuseNode", "alwaysPull":
+					default:
+						c.unsupported(d, "unsupported docker agent option")
+					}
+				}
+			}
+			if image != "" {
+				return &syntax.Agent{Image: image}
+			}
+			c.unsupported(child, "docker agent without an image")
+		case "label", "node":
+			c.unsupported(child, "agents are containers of the image of the pipeline or the stage rather than labelled nodes")
+		default:
+			c.unsupported(child, "unsupported agent, use the image of a container instead")
+		}
+	}
+	return nil
+}
+
+func (c *converter) env(n *Node) []corev1.EnvVar {
+	answer := []corev1.EnvVar{}
+	for _, child := range n.Children {
+		value := strings.TrimSpace(strings.TrimPrefix(child.Args, "="))
+		if !strings.HasPrefix(child.Args, "=") || child.Block {
+			c.unsupported(child, "unsupported environment variable")
+			continue
+		}
+		if strings.HasPrefix(value, "credentials(") {
+			c.unsupported(child, "credentials are not supported, reference a key of a Secret via valueFrom instead")
+			continue
+		}
+		if !IsLiteral(value) {
+			c.unsupported(child, "the values of environment variables must be string literals")
+			continue
+		}
+		answer = append(answer, corev1.EnvVar{Name: child.Name, Value: c.shell(child, Unquote(value))})
+	}
+	return answer
+}
+
+func (c *converter) options(n *Node) *syntax.RootOptions {
+	answer := &syntax.RootOptions{}
+	found := false
+	for _, child := range n.Children {
+		args := ParseArgs(child.Args)
+		switch child.Name {
+		case "timeout":
+			t, err := strconv.ParseInt(args.First("time"), 10, 64)
+			if err != nil {
+				c.unsupported(child, "invalid timeout")
+				continue
+			}
+			unit := strings.ToLower(args.Named["unit"])
+			if unit == "" {
+				unit = string(syntax.TimeoutUnitMinutes)
+			}
+			answer.Timeout = &syntax.Timeout{Time: t, Unit: syntax.TimeoutUnit(unit)}
+			found = true
+		case "retry":
+			r, err := strconv.ParseInt(args.First("count"), 10, 8)
+			if err != nil {
+				c.unsupported(child, "invalid retry")
+				continue
+			}
+			answer.Retry = int8(r)
+			found = true
+		case "skipDefaultCheckout", "timestamps", "ansiColor":
+		default:
+			c.unsupported(child, "unsupported option")
+		}
+	}
+	if !found {
+		return nil
+	}
+	return answer
+}
+
+// stage converts a stage returning the pipelines it belongs to and false if it could not be converted
+func (c *converter) stage(n *Node) (syntax.Stage, Target, bool) {
+	stage := syntax.Stage{}
+	target := TargetAll
+	if n.Name != "stage" || !n.Block {
+		c.unsupported(n, "expected a stage")
+		return stage, target, false
+	}
+	stage.Name = ParseArgs(n.Args).First("name")
+	if stage.Name == "" {
+		c.unsupported(n, "stage without a name")
+		return stage, target, false
+	}
+	for _, child := range n.Children {
+		switch child.Name {
+		case "agent":
+			stage.Agent = c.agent(child)
+		case "environment":
+			stage.Env = c.env(child)
+		case "options":
+			opts := c.options(child)
+			if opts != nil {
+				stage.Options = &syntax.StageOptions{RootOptions: opts}
+			}
+		case "when":
+			target = c.when(child)
+		case "steps":
+			stage.Steps = append(stage.Steps, c.steps(child.Children, "", nil)...)
+		case "stages", "parallel":
+			for _, s := range child.Children {
+				nested, _, ok := c.stage(s)
+				if !ok {
+					continue
+				}
+				if child.Name == "stages" {
+					stage.Stages = append(stage.Stages, nested)
+				} else {
+					stage.Parallel = append(stage.Parallel, nested)
+				}
+			}
+		case "post":
+			c.unsupported(child, "post conditions are not supported, run the steps as the last steps of the stage instead")
+		default:
+			c.unsupported(child, "unsupported stage directive")
+		}
+	}
+	if len(stage.Steps) == 0 && len(stage.Stages) == 0 && len(stage.Parallel) == 0 {
+		c.unsupported(n, "the stage has no steps which could be converted so it is skipped")
+		return stage, target, false
+	}
+	return stage, target, true
+}
+
+// when converts the conditions of a stage to the pipelines it runs in. Only the branch and pull request conditions
+// are supported
+func (c *converter) when(n *Node) Target {
+	target := TargetAll
+	for _, child := range n.Children {
+		switch {
+		case child.Name == "branch" || child.Name == "buildingTag":
+			target = TargetRelease
+		case child.Name == "changeRequest":
+			target = TargetPullRequest
+		case child.Name == "not" && len(child.Children) == 1 && child.Children[0].Name == "branch":
+			target = TargetPullRequest
+		case child.Name == "not" && len(child.Children) == 1 && child.Children[0].Name == "changeRequest":
+			target = TargetRelease
+		default:
+			c.unsupported(child, "only branch and changeRequest conditions are supported, the stage runs in both pipelines")
+		}
+	}
+	return target
+}
+
+func (c *converter) steps(nodes []*Node, dir string, env []corev1.EnvVar) []syntax.Step {
+	answer := []syntax.Step{}
+	for _, n := range nodes {
+		args := ParseArgs(n.Args)
+		switch {
+		case n.Name == "sh" && !n.Block:
+			script := strings.TrimSpace(n.Args)
+			if strings.HasPrefix(script, "(") || len(args.Named) > 0 {
+				script = args.Named["script"]
+				if script == "" && len(args.Positional) > 0 {
+					script = args.Positional[0]
+				}
+				if args.Named["returnStdout"] != "" || args.Named["returnStatus"] != "" {
+					c.unsupported(n, "the output and status of steps cannot be returned")
+					continue
+				}
+			} else if IsLiteral(script) {
+				script = Unquote(script)
+			} else {
+				script = ""
+			}
+			if script == "" {
+				c.unsupported(n, "the script of sh must be a string literal")
+				continue
+			}
+			answer = append(answer, c.step(c.shell(n, script), dir, env))
+		case n.Name == "echo" && !n.Block:
+			message := args.First("message")
+			answer = append(answer, c.step("echo "+strconv.Quote(c.shell(n, message)), dir, env))
+		case n.Name == "dir" && n.Block:
+			sub := args.First("path")
+			if dir != "" {
+				sub = path.Join(dir, sub)
+			}
+			answer = append(answer, c.steps(n.Children, sub, env)...)
+		case n.Name == "withEnv" && n.Block:
+			nested := append([]corev1.EnvVar{}, env...)
+			for _, e := range splitArgs(strings.Trim(strings.Trim(strings.TrimSpace(n.Args), "()"), "[]")) {
+				m := envEntryRegex.FindStringSubmatch(Unquote(e))
+				if m == nil {
+					c.unsupported(n, "unsupported withEnv entry "+e)
+					continue
+				}
+				nested = append(nested, corev1.EnvVar{Name: m[1], Value: c.shell(n, m[2])})
+			}
+			answer = append(answer, c.steps(n.Children, dir, nested)...)
+		case ignoredSteps[n.Name]:
+		case n.Block && wrapperSteps[n.Name] != "":
+			c.unsupported(n, "the steps are converted without their wrapper, "+wrapperSteps[n.Name])
+			answer = append(answer, c.steps(n.Children, dir, env)...)
+		case n.Name == "script":
+			c.unsupported(n, "script blocks are not supported, move the logic into a shell script of the repository")
+		default:
+			c.unsupported(n, "unsupported step")
+		}
+	}
+	return answer
+}
+
+func (c *converter) step(command string, dir string, env []corev1.EnvVar) syntax.Step {
+	step := syntax.Step{Command: command, Dir: dir}
+	if len(env) > 0 {
+		step.Env = append([]corev1.EnvVar{}, env...)
+	}
+	return step
+}
+
+// shell converts the Groovy interpolation of a string to shell variables reporting the expressions which cannot be
+// converted
+func (c *converter) shell(n *Node, text string) string {
+	text = groovyEnvRegex.ReplaceAllString(text, "$${$1}")
+	if groovyExpressionRegex.MatchString(text) {
+		c.unsupported(n, "Groovy expressions are not supported, use environment variables instead")
+	}
+	return text
+}
@@ -0,0 +1,145 @@
+// +build unit
+
+package convert_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/jenkinsfile/convert"
+	"github.com/jenkins-x/jx/v2/pkg/tekton/syntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const jenkinsfile = `
+@Library('shared') _
+
+pipeline {
+  agent {
+    docker {
+      image 'maven:3.6-jdk-11'
+    }
+  }
+  environment {
+    APP_NAME = 'myapp'
+    TOKEN = credentials('github-token')
+  }
+  options {
+    timeout(time: 30, unit: 'MINUTES')
+    buildDiscarder(logRotator(numToKeepStr: '10'))
+  }
+  stages {
+    stage('Build') {
+      steps {
+        checkout scm
+        sh 'mvn -B package' // build it
+        dir('charts/myapp') {
+          sh """
+            helm lint .
+            echo ${env.APP_NAME}
+          """
+        }
+      }
+    }
+    stage('Tests') {
+      when { changeRequest() }
+      parallel {
+        stage('Unit') {
+          steps {
+            withEnv(['PROFILE=unit']) {
+              sh(script: 'mvn -B test')
+            }
+          }
+        }
+        stage('Lint') {
+          steps {
+            echo 'linting'
+            script {
+              def x = readYaml file: 'pom.yml'
+            }
+          }
+        }
+      }
+    }
+    stage('Release') {
+      when {
+        branch 'master'
+      }
+      steps {
+        sh 'jx step changelog --batch-mode'
+      }
+    }
+  }
+  post {
+    always {
+      junit 'target/surefire-reports/*.xml'
+    }
+  }
+}
+`
+
+func TestConvertJenkinsfile(t *testing.T) {
+	t.Parallel()
+
+	result, err := convert.Jenkinsfile(jenkinsfile, "gcr.io/jenkinsxio/builder-maven")
+	require.NoError(t, err)
+	require.NotNil(t, result.Release)
+	require.NotNil(t, result.PullRequest)
+
+	release := result.Release
+	assert.Equal(t, "maven:3.6-jdk-11", release.Agent.Image)
+	assert.Equal(t, []corev1.EnvVar{{Name: "APP_NAME", Value: "myapp"}}, release.Env)
+	assert.Equal(t, &syntax.Timeout{Time: 30, Unit: syntax.TimeoutUnitMinutes}, release.Options.Timeout)
+
+	require.Len(t, release.Stages, 2)
+	build := release.Stages[0]
+	assert.Equal(t, "Build", build.Name)
+	require.Len(t, build.Steps, 2)
+	assert.Equal(t, "mvn -B package", build.Steps[0].Command)
+	assert.Equal(t, "helm lint .\necho ${APP_NAME}", build.Steps[1].Command)
+	assert.Equal(t, "charts/myapp", build.Steps[1].Dir)
+	assert.Equal(t, "Release", release.Stages[1].Name)
+
+	require.Len(t, result.PullRequest.Stages, 2)
+	tests := result.PullRequest.Stages[1]
+	assert.Equal(t, "Tests", tests.Name)
+	require.Len(t, tests.Parallel, 2)
+	unit := tests.Parallel[0].Steps[0]
+	assert.Equal(t, "mvn -B test", unit.Command)
+	assert.Equal(t, []corev1.EnvVar{{Name: "PROFILE", Value: "unit"}}, unit.Env)
+	assert.Equal(t, `echo "linting"`, tests.Parallel[1].Steps[0].Command)
+
+	lines := []int{}
+	for _, issue := range result.Issues {
+		lines = append(lines, issue.Line)
+	}
+	assert.Equal(t, []int{2, 12, 16, 44, 60}, lines, "%v", result.Issues)
+
+	config := result.ProjectConfig()
+	assert.Equal(t, "none", config.BuildPack)
+	assert.Equal(t, release, config.PipelineConfig.Pipelines.Release.Pipeline)
+}
+
+func TestConvertScriptedPipeline(t *testing.T) {
+	t.Parallel()
+
+	_, err := convert.Jenkinsfile("node {\n  sh 'make'\n}\n", "gcr.io/jenkinsxio/builder-go")
+	assert.Error(t, err)
+
+	_, err = convert.Jenkinsfile("pipeline {\n  stages {\n", "gcr.io/jenkinsxio/builder-go")
+	assert.Error(t, err)
+}
+
+func TestParseArgs(t *testing.T) {
+	t.Parallel()
+
+	args := convert.ParseArgs(`(time: 1, unit: 'HOURS')`)
+	assert.Equal(t, map[string]string{"time": "1", "unit": "HOURS"}, args.Named)
+
+	args = convert.ParseArgs(`'a, b', "c"`)
+	assert.Equal(t, []string{"a, b", "c"}, args.Positional)
+
+	assert.True(t, convert.IsLiteral(`'it\'s'`))
+	assert.False(t, convert.IsLiteral(`'a' + 'b'`))
+}
@@ -0,0 +1,389 @@
+package convert
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	// namedArgRegex matches the named arguments of Groovy method calls such as time: 30
+	namedArgRegex = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*:\s*(.*)$`)
+)
+
+// Node a statement of a Jenkinsfile along with the statements of its block if it has one
+type Node struct {
+	// Name the method or variable name of the statement such as stage or sh
+	Name string
+	// Args the remaining text of the statement such as ('Build') or 'make build'
+	Args string
+	// Block is true if the statement is followed by a closure
+	Block    bool
+	Children []*Node
+	Line     int
+
+	statement string
+}
+
+// Find returns the first child node with the name
+func (n *Node) Find(name string) *Node {
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Text returns the statement as it was written without the block
+func (n *Node) Text() string {
+	return n.statement
+}
+
+// Parse parses the Groovy source of a Jenkinsfile into its statements
+func Parse(source string) ([]*Node, error) {
+	p := &parser{src: []rune(source), line: 1}
+	nodes, err := p.parseBlock(false)
+	if err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+type parser struct {
+	src  []rune
+	pos  int
+	line int
+}
+
+func (p *parser) peek(offset int) rune {
+	if p.pos+offset < len(p.src) {
+		return p.src[p.pos+offset]
+	}
+	return 0
+}
+
+func (p *parser) next() rune {
+	r := p.src[p.pos]
+	p.pos++
+	if r == '\n' {
+		p.line++
+	}
+	return r
+}
+
+func (p *parser) parseBlock(nested bool) ([]*Node, error) {
+	nodes := []*Node{}
+	for {
+		p.skipSpace(true)
+		if p.pos >= len(p.src) {
+			if nested {
+				return nil, fmt.Errorf("line %d: missing closing }", p.line)
+			}
+			return nodes, nil
+		}
+		if p.peek(0) == '}' {
+			if !nested {
+				return nil, fmt.Errorf("line %d: unexpected }", p.line)
+			}
+			p.next()
+			return nodes, nil
+		}
+		node, err := p.parseStatement()
+		if err != nil {
+			return nil, err
+		}
+		if node != nil {
+			nodes = append(nodes, node)
+		}
+	}
+}
+
+// parseStatement reads a statement up to the end of its line or the start of its block
+func (p *parser) parseStatement() (*Node, error) {
+	line := p.line
+	text := &strings.Builder{}
+	depth := 0
+	block := false
+loop:
+	for p.pos < len(p.src) {
+		r := p.peek(0)
+		switch {
+		case r == '"' || r == '\'':
+			s, err := p.readString()
+			if err != nil {
+				return nil, err
+			}
+			text.WriteString(s)
+			continue
+		case r == '/' && (p.peek(1) == '/' || p.peek(1) == '*'):
+			p.skipComment()
+			continue
+		case r == '(' || r == '[':
+			depth++
+		case r == ')' || r == ']':
+			depth--
+		case depth > 0:
+		case r == '{':
+			p.next()
+			block = true
+			break loop
+		case r == '}':
+			break loop
+		case r == ';':
+			p.next()
+			break loop
+		case r == '\n':
+			trimmed := strings.TrimSpace(text.String())
+			if !strings.HasSuffix(trimmed, ",") && !strings.HasSuffix(trimmed, "+") && trimmed != "" {
+				p.next()
+				break loop
+			}
+		}
+		text.WriteRune(p.next())
+	}
+	if depth > 0 {
+		return nil, fmt.Errorf("line %d: missing closing bracket", line)
+	}
+
+	statement := strings.TrimSpace(text.String())
+	node := &Node{Line: line, Block: block, statement: statement}
+	i := strings.IndexFunc(statement, func(r rune) bool {
+		return !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == '@')
+	})
+	if i < 0 {
+		node.Name = statement
+	} else {
+		node.Name = statement[:i]
+		node.Args = strings.TrimSpace(statement[i:])
+	}
+	if block {
+		children, err := p.parseBlock(true)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = children
+	}
+	if node.Name == "" && node.Args == "" && !block {
+		return nil, nil
+	}
+	return node, nil
+}
+
+// readString reads a quoted string returning it with its quotes
+func (p *parser) readString() (string, error) {
+	line := p.line
+	quote := string(p.peek(0))
+	if p.peek(1) == p.peek(0) && p.peek(2) == p.peek(0) {
+		quote = strings.Repeat(quote, 3)
+	}
+	buf := &strings.Builder{}
+	for i := 0; i < len(quote); i++ {
+		buf.WriteRune(p.next())
+	}
+	for p.pos < len(p.src) {
+		if p.peek(0) == '\\' && p.pos+1 < len(p.src) {
+			buf.WriteRune(p.next())
+			buf.WriteRune(p.next())
+			continue
+		}
+		if strings.HasPrefix(string(p.src[p.pos:minInt(p.pos+len(quote), len(p.src))]), quote) {
+			for i := 0; i < len(quote); i++ {
+				buf.WriteRune(p.next())
+			}
+			return buf.String(), nil
+		}
+		if len(quote) == 1 && p.peek(0) == '\n' {
+			break
+		}
+		buf.WriteRune(p.next())
+	}
+	return "", fmt.Errorf("line %d: unterminated string", line)
+}
+
+func (p *parser) skipComment() {
+	if p.peek(1) == '/' {
+		for p.pos < len(p.src) && p.peek(0) != '\n' {
+			p.next()
+		}
+		return
+	}
+	p.next()
+	p.next()
+	for p.pos < len(p.src) && !(p.peek(0) == '*' && p.peek(1) == '/') {
+		p.next()
+	}
+	if p.pos < len(p.src) {
+		p.next()
+		p.next()
+	}
+}
+
+func (p *parser) skipSpace(newlines bool) {
+	for p.pos < len(p.src) {
+		r := p.peek(0)
+		switch {
+		case r == '/' && (p.peek(1) == '/' || p.peek(1) == '*'):
+			p.skipComment()
+		case r == ';' || (unicode.IsSpace(r) && (newlines || r != '\n')):
+			p.next()
+		default:
+			return
+		}
+	}
+}
+
+// Arguments the positional and named arguments of a statement
+type Arguments struct {
+	Positional []string
+	Named      map[string]string
+}
+
+// ParseArgs parses the arguments of a statement such as ('Build') or time: 30, unit: 'MINUTES'. String literals are
+// unquoted, other values are returned as written
+func ParseArgs(args string) Arguments {
+	answer := Arguments{Named: map[string]string{}}
+	args = strings.TrimSpace(args)
+	if strings.HasPrefix(args, "(") && strings.HasSuffix(args, ")") && closingParen(args) == len(args)-1 {
+		args = strings.TrimSpace(args[1 : len(args)-1])
+	}
+	for _, arg := range splitArgs(args) {
+		if arg == "" {
+			continue
+		}
+		if !isQuoted(arg) {
+			if m := namedArgRegex.FindStringSubmatch(arg); m != nil {
+				answer.Named[m[1]] = Unquote(m[2])
+				continue
+			}
+		}
+		answer.Positional = append(answer.Positional, Unquote(arg))
+	}
+	return answer
+}
+
+// First returns the first positional argument or the named argument
+func (a Arguments) First(name string) string {
+	if len(a.Positional) > 0 {
+		return a.Positional[0]
+	}
+	return a.Named[name]
+}
+
+// Unquote returns the value of a Groovy string literal or the text if it is not a literal. Triple quoted strings
+// have their common indentation removed
+func Unquote(text string) string {
+	text = strings.TrimSpace(text)
+	for _, q := range []string{`"""`, `'''`} {
+		if len(text) >= 6 && strings.HasPrefix(text, q) && strings.HasSuffix(text, q) {
+			return dedent(unescape(text[3 : len(text)-3]))
+		}
+	}
+	if len(text) >= 2 && (text[0] == '"' || text[0] == '\'') && text[len(text)-1] == text[0] {
+		return unescape(text[1 : len(text)-1])
+	}
+	return text
+}
+
+// IsLiteral returns true if the text is a single Groovy string literal
+func IsLiteral(text string) bool {
+	text = strings.TrimSpace(text)
+	if !isQuoted(text) {
+		return false
+	}
+	p := &parser{src: []rune(text), line: 1}
+	_, err := p.readString()
+	return err == nil && p.pos == len(p.src)
+}
+
+func isQuoted(text string) bool {
+	return len(text) >= 2 && (text[0] == '"' || text[0] == '\'') && text[len(text)-1] == text[0]
+}
+
+func unescape(text string) string {
+	r := strings.NewReplacer(`\\`, `\`, `\'`, `'`, `\"`, `"`, `\$`, `$`, `\n`, "\n", `\t`, "\t")
+	return r.Replace(text)
+}
+
+func dedent(text string) string {
+	lines := strings.Split(strings.Trim(text, "\n"), "\n")
+	indent := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		n := len(l) - len(strings.TrimLeft(l, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i, l := range lines {
+		if len(l) >= indent && indent > 0 {
+			lines[i] = l[indent:]
+		}
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), " \t\n")
+}
+
+// splitArgs splits the arguments at the commas which are not within strings or brackets
+func splitArgs(args string) []string {
+	answer := []string{}
+	depth := 0
+	var quote rune
+	start := 0
+	runes := []rune(args)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == '\\' {
+				i++
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '(' || r == '[' || r == '{':
+			depth++
+		case r == ')' || r == ']' || r == '}':
+			depth--
+		case r == ',' && depth == 0:
+			answer = append(answer, strings.TrimSpace(string(runes[start:i])))
+			start = i + 1
+		}
+	}
+	answer = append(answer, strings.TrimSpace(string(runes[start:])))
+	return answer
+}
+
+// closingParen returns the index of the parenthesis closing the one at the start of the text
+func closingParen(text string) int {
+	depth := 0
+	var quote rune
+	for i, r := range text {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -30,6 +30,37 @@ func InitBuildPack(gitter gits.Gitter, packURL string, packRef string) (string,
 		return "", fmt.Errorf("Could not create %s: %s", dir, err)
 	}
 
+	if isPinnedRef(packRef) {
+		empty, err := util.IsEmpty(dir)
+		if err != nil {
+			return "", errors.Wrapf(err, "checking if %s is empty", dir)
+		}
+		if !empty {
+			// lets keep using the pinned tag of an existing clone rather than pulling the latest changes
+			tag, err := findTag(gitter, dir, packRef)
+			if err != nil {
+				return "", err
+			}
+			if tag != "" {
+				err = checkoutTag(gitter, dir, tag)
+				if err != nil {
+					return "", err
+				}
+				return filepath.Join(dir, "packs"), nil
+			}
+		}
+	}
+
+	if !isPinnedRef(packRef) {
+		cloned, err := cloneFromCache(gitter, packURL, packRef, dir)
+		if err != nil {
+			return "", err
+		}
+		if cloned {
+			return filepath.Join(dir, "packs"), nil
+		}
+	}
+
 	err = ensureBranchTracksOrigin(dir, packRef, gitter)
 	if err != nil {
 		return "", errors.Wrapf(err, "there was a problem ensuring the branch %s has tracking info", packRef)
@@ -39,36 +70,21 @@ func InitBuildPack(gitter gits.Gitter, packURL string, packRef string) (string,
 	if err != nil {
 		return "", err
 	}
-	if packRef != "master" && packRef != "" {
+	if isPinnedRef(packRef) {
 		err = gitter.FetchTags(dir)
 		if err != nil {
 			return "", errors.Wrapf(err, "fetching tags from %s", packURL)
 		}
-		tags, err := gitter.FilterTags(dir, packRef)
+		tag, err := findTag(gitter, dir, packRef)
 		if err != nil {
-			return "", errors.Wrapf(err, "filtering tags for %s", packRef)
+			return "", err
 		}
-		if len(tags) == 0 {
-			tags, err = gitter.FilterTags(dir, fmt.Sprintf("v%s", packRef))
+		if tag != "" {
+			err = checkoutTag(gitter, dir, tag)
 			if err != nil {
-				return "", errors.Wrapf(err, "filtering tags for v%s", packRef)
-			}
-		}
-		if len(tags) == 1 {
-			tag := tags[0]
-			branchName := fmt.Sprintf("tag-%s", tag)
-			err = gitter.CreateBranchFrom(dir, branchName, tag)
-			if err != nil {
-				return "", errors.Wrapf(err, "creating branch %s from %s", branchName, tag)
-			}
-			err = gitter.Checkout(dir, branchName)
-			if err != nil {
-				return "", errors.Wrapf(err, "checking out branch %s", branchName)
+				return "", err
 			}
 		} else {
-			if len(tags) > 1 {
-				log.Logger().Debugf("more than one tag matched %s or v%s, ignoring tags", packRef, packRef)
-			}
 			err = gitter.CheckoutRemoteBranch(dir, packRef)
 			if err != nil {
 				return "", errors.Wrapf(err, "checking out tracking branch %s", packRef)
@@ -79,6 +95,78 @@ func InitBuildPack(gitter gits.Gitter, packURL string, packRef string) (string,
 	return filepath.Join(dir, "packs"), nil
 }
 
+// cloneFromCache makes a shallow clone of the build pack via the git clone cache if it has not been cloned yet. It
+// returns false if the build pack was already cloned or the cache could not be used
+func cloneFromCache(gitter gits.Gitter, packURL string, packRef string, dir string) (bool, error) {
+	empty, err := util.IsEmpty(dir)
+	if err != nil {
+		return false, errors.Wrapf(err, "checking if %s is empty", dir)
+	}
+	if !empty {
+		return false, nil
+	}
+	cache, err := gits.NewCloneCache(gitter)
+	if err != nil || cache == nil {
+		return false, err
+	}
+	err = cache.Clone(packURL, packRef, dir, true)
+	if err == nil {
+		return true, nil
+	}
+	log.Logger().Warnf("failed to clone build pack %s via the git clone cache: %s", packURL, err.Error())
+	err = os.RemoveAll(dir)
+	if err != nil {
+		return false, errors.Wrapf(err, "removing %s", dir)
+	}
+	return false, os.MkdirAll(dir, 0755)
+}
+
+// isPinnedRef returns true if the ref is a tag or a branch other than master
+func isPinnedRef(packRef string) bool {
+	return packRef != "master" && packRef != ""
+}
+
+// findTag returns the tag matching the ref or the ref prefixed with v or an empty string if there is no single match
+func findTag(gitter gits.Gitter, dir string, packRef string) (string, error) {
+	tags, err := gitter.FilterTags(dir, packRef)
+	if err != nil {
+		return "", errors.Wrapf(err, "filtering tags for %s", packRef)
+	}
+	if len(tags) == 0 {
+		tags, err = gitter.FilterTags(dir, fmt.Sprintf("v%s", packRef))
+		if err != nil {
+			return "", errors.Wrapf(err, "filtering tags for v%s", packRef)
+		}
+	}
+	if len(tags) > 1 {
+		log.Logger().Debugf("more than one tag matched %s or v%s, ignoring tags", packRef, packRef)
+	}
+	if len(tags) != 1 {
+		return "", nil
+	}
+	return tags[0], nil
+}
+
+// checkoutTag checks out the branch of the tag creating it if it does not exist yet
+func checkoutTag(gitter gits.Gitter, dir string, tag string) error {
+	branchName := fmt.Sprintf("tag-%s", tag)
+	branches, err := gitter.LocalBranches(dir)
+	if err != nil {
+		return errors.Wrapf(err, "listing the local branches of %s", dir)
+	}
+	if util.StringArrayIndex(branches, branchName) < 0 {
+		err = gitter.CreateBranchFrom(dir, branchName, tag)
+		if err != nil {
+			return errors.Wrapf(err, "creating branch %s from %s", branchName, tag)
+		}
+	}
+	err = gitter.Checkout(dir, branchName)
+	if err != nil {
+		return errors.Wrapf(err, "checking out branch %s", branchName)
+	}
+	return nil
+}
+
 func ensureBranchTracksOrigin(dir string, packRef string, gitter gits.Gitter) error {
 	empty, err := util.IsEmpty(dir)
 	if err != nil {
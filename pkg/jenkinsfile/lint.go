@@ -0,0 +1,210 @@
+package jenkinsfile
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/tekton/syntax"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var (
+	// imageReferenceRegex matches a docker image reference such as gcr.io/myorg/myimage:1.0.0
+	imageReferenceRegex = regexp.MustCompile(`^(?:(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])(?:\.(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9]))*(?::[0-9]+)?/)?` +
+		`[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*` +
+		`(?::[\w][\w.-]{0,127})?(?:@[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9a-fA-F]{32,})?$`)
+
+	// envReferenceRegex matches the $NAME and ${NAME} references to environment variables in shell commands along
+	// with any escaping backslash
+	envReferenceRegex = regexp.MustCompile(`(\\)?\$\{?([A-Za-z_][A-Za-z0-9_]*)`)
+
+	// envAssignmentRegex matches the environment variables assigned in shell commands
+	envAssignmentRegex = regexp.MustCompile(`(?:^|[\s;&|(])(?:export\s+)?([A-Za-z_][A-Za-z0-9_]*)=`)
+
+	// WellKnownEnvVars the environment variables injected into the steps of every pipeline
+	WellKnownEnvVars = []string{
+		"APP_NAME", "BRANCH_NAME", "BUILD_ID", "BUILD_NUMBER", "DOCKER_CONFIG", "DOCKER_REGISTRY", "DOCKER_REGISTRY_ORG",
+		"GIT_AUTHOR_EMAIL", "GIT_AUTHOR_NAME", "GIT_COMMITTER_EMAIL", "GIT_COMMITTER_NAME", "GOOGLE_APPLICATION_CREDENTIALS",
+		"HOME", "HOSTNAME", "JOB_NAME", "JOB_SPEC", "JOB_TYPE", "JX_BATCH_MODE", "JX_INTERPRET_PIPELINE", "KANIKO_FLAGS", "ORG",
+		"PATH", "PIPELINE_CONTEXT", "PIPELINE_KIND", "PREVIEW_VERSION", "PULL_BASE_REF", "PULL_BASE_SHA", "PULL_NUMBER",
+		"PULL_PULL_SHA", "PULL_REFS", "PWD", "REPO_NAME", "REPO_OWNER", "SHELL", "SOURCE_URL", "USER", "VERSION",
+	}
+)
+
+// LintIssue a problem found in the configuration of a pipeline
+type LintIssue struct {
+	// Location the path of the pipeline, stage or step with the problem
+	Location string
+	Message  string
+	// Warning is true if the problem may not fail the pipeline such as a reference to an undeclared variable
+	Warning bool
+}
+
+// String returns a description of the issue
+func (i LintIssue) String() string {
+	return fmt.Sprintf("%s: %s", i.Location, i.Message)
+}
+
+// Lint returns the invalid step images and the references to undeclared environment variables in the pipelines of
+// the configuration. The known env vars are treated as declared in every step along with the WellKnownEnvVars
+func (c *PipelineConfig) Lint(knownEnvVars ...string) []LintIssue {
+	l := &linter{}
+	env := envNames(nil, append(append([]string{}, WellKnownEnvVars...), knownEnvVars...))
+	env = envNames(env, nil, c.Env...)
+	if c.ContainerOptions != nil {
+		env = envNames(env, nil, c.ContainerOptions.Env...)
+	}
+	if c.Agent != nil {
+		l.lintImage("agent", c.Agent.Image)
+	}
+	for name, lifecycles := range c.Pipelines.AllMap() {
+		if lifecycles == nil {
+			continue
+		}
+		for _, lifecycle := range lifecycles.All() {
+			if lifecycle.Lifecycle == nil {
+				continue
+			}
+			location := fmt.Sprintf("pipelines.%s.%s", name, lifecycle.Name)
+			l.lintSteps(location+".preSteps", env, lifecycle.Lifecycle.PreSteps)
+			l.lintSteps(location+".steps", env, lifecycle.Lifecycle.Steps)
+		}
+		if lifecycles.Pipeline != nil {
+			l.lintPipeline(fmt.Sprintf("pipelines.%s.pipeline", name), env, lifecycles.Pipeline)
+		}
+	}
+	if c.Pipelines.Post != nil {
+		l.lintSteps("pipelines.post.steps", env, c.Pipelines.Post.Steps)
+	}
+	if c.Pipelines.Default != nil {
+		l.lintPipeline("pipelines.default", env, c.Pipelines.Default)
+	}
+	for i, override := range c.Pipelines.Overrides {
+		if override == nil {
+			continue
+		}
+		location := fmt.Sprintf("pipelines.overrides[%d]", i)
+		if override.Step != nil {
+			l.lintStep(location+".step", env, override.Step)
+		}
+		l.lintSteps(location+".steps", env, override.Steps)
+	}
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		return l.issues[i].Location < l.issues[j].Location
+	})
+	return l.issues
+}
+
+type linter struct {
+	issues []LintIssue
+}
+
+func (l *linter) lintPipeline(location string, env map[string]bool, pipeline *syntax.ParsedPipeline) {
+	if pipeline.Agent != nil {
+		l.lintImage(location+".agent", pipeline.Agent.Image)
+	}
+	env = envNames(env, nil, pipeline.GetEnv()...)
+	if pipeline.Options != nil && pipeline.Options.ContainerOptions != nil {
+		env = envNames(env, nil, pipeline.Options.ContainerOptions.Env...)
+	}
+	l.lintStages(location, env, pipeline.Stages)
+}
+
+func (l *linter) lintStages(location string, env map[string]bool, stages []syntax.Stage) {
+	for i := range stages {
+		stage := &stages[i]
+		stageLocation := fmt.Sprintf("%s.stages[%s]", location, stageName(stage.Name, i))
+		if stage.Agent != nil {
+			l.lintImage(stageLocation+".agent", stage.Agent.Image)
+		}
+		stageEnv := envNames(env, nil, stage.GetEnv()...)
+		if stage.Options != nil && stage.Options.RootOptions != nil && stage.Options.ContainerOptions != nil {
+			stageEnv = envNames(stageEnv, nil, stage.Options.ContainerOptions.Env...)
+		}
+		for j := range stage.Steps {
+			l.lintStep(fmt.Sprintf("%s.steps[%s]", stageLocation, stageName(stage.Steps[j].Name, j)), stageEnv, &stage.Steps[j])
+		}
+		l.lintStages(stageLocation, stageEnv, stage.Stages)
+		l.lintStages(stageLocation, stageEnv, stage.Parallel)
+	}
+}
+
+func (l *linter) lintSteps(location string, env map[string]bool, steps []*syntax.Step) {
+	for i, step := range steps {
+		if step != nil {
+			l.lintStep(fmt.Sprintf("%s[%s]", location, stageName(step.Name, i)), env, step)
+		}
+	}
+}
+
+func (l *linter) lintStep(location string, env map[string]bool, step *syntax.Step) {
+	l.lintImage(location+".image", step.Image)
+	if step.Agent != nil {
+		l.lintImage(location+".agent", step.Agent.Image)
+	}
+	commands := append([]string{step.Command, step.Dir, step.Sh}, step.Arguments...)
+	env = envNames(env, nil, step.Env...)
+	for _, command := range commands {
+		for _, m := range envAssignmentRegex.FindAllStringSubmatch(command, -1) {
+			env = envNames(env, []string{m[1]})
+		}
+	}
+	undeclared := map[string]bool{}
+	for _, command := range commands {
+		for _, m := range envReferenceRegex.FindAllStringSubmatch(command, -1) {
+			name := m[2]
+			if m[1] == "" && !env[name] && !undeclared[name] {
+				undeclared[name] = true
+				l.issues = append(l.issues, LintIssue{
+					Location: location,
+					Message:  fmt.Sprintf("environment variable %s is not declared in the step, stage, pipeline or project", name),
+					Warning:  true,
+				})
+			}
+		}
+	}
+	l.lintSteps(location+".steps", env, step.Steps)
+	if step.Loop != nil {
+		loopEnv := envNames(env, []string{step.Loop.Variable})
+		for i := range step.Loop.Steps {
+			l.lintStep(fmt.Sprintf("%s.loop.steps[%s]", location, stageName(step.Loop.Steps[i].Name, i)), loopEnv, &step.Loop.Steps[i])
+		}
+	}
+}
+
+func (l *linter) lintImage(location string, image string) {
+	// lets ignore images resolved from placeholders or the version stream
+	if image == "" || strings.ContainsAny(image, "${") {
+		return
+	}
+	if !imageReferenceRegex.MatchString(image) {
+		l.issues = append(l.issues, LintIssue{
+			Location: location,
+			Message:  fmt.Sprintf("invalid image reference %q", image),
+		})
+	}
+}
+
+// envNames returns a copy of the env var names with the given names and env vars added
+func envNames(env map[string]bool, names []string, vars ...corev1.EnvVar) map[string]bool {
+	answer := map[string]bool{}
+	for k := range env {
+		answer[k] = true
+	}
+	for _, name := range names {
+		answer[name] = true
+	}
+	for _, v := range vars {
+		answer[v.Name] = true
+	}
+	return answer
+}
+
+func stageName(name string, index int) string {
+	if name != "" {
+		return name
+	}
+	return fmt.Sprintf("%d", index)
+}
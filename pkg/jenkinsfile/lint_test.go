@@ -0,0 +1,85 @@
+// +build unit
+
+package jenkinsfile_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/jenkinsfile"
+	"github.com/jenkins-x/jx/v2/pkg/tekton/syntax"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestLintPipelineConfig(t *testing.T) {
+	t.Parallel()
+	pipelineConfig := &jenkinsfile.PipelineConfig{
+		Env: []corev1.EnvVar{{Name: "PROJECT_VAR", Value: "foo"}},
+		Pipelines: jenkinsfile.Pipelines{
+			Release: &jenkinsfile.PipelineLifecycles{
+				Build: &jenkinsfile.PipelineLifecycle{
+					Steps: []*syntax.Step{
+						{
+							Name:    "build",
+							Image:   "gcr.io/jenkinsxio/builder-go:2.0.1",
+							Command: "make build VERSION=$VERSION NAME=$APP_NAME EXTRA=$PROJECT_VAR",
+						},
+						{
+							Name:  "bad-image",
+							Image: "gcr.io/My Image:latest",
+							Sh:    "export LOCAL=1 && echo $LOCAL \\$ESCAPED $(cat VERSION)",
+						},
+						{
+							Name:  "placeholder-image",
+							Image: "${builderImage}",
+							Sh:    "echo ${UNDECLARED} $UNDECLARED",
+						},
+					},
+				},
+			},
+			PullRequest: &jenkinsfile.PipelineLifecycles{
+				Pipeline: &syntax.ParsedPipeline{
+					Stages: []syntax.Stage{
+						{
+							Name: "ci",
+							Env:  []corev1.EnvVar{{Name: "STAGE_VAR", Value: "bar"}},
+							Steps: []syntax.Step{
+								{
+									Name:    "test",
+									Image:   "maven",
+									Command: "mvn test -Dvalue=$STAGE_VAR",
+								},
+								{
+									Loop: &syntax.Loop{
+										Variable: "LANGUAGE",
+										Values:   []string{"en", "fr"},
+										Steps: []syntax.Step{
+											{
+												Image:   "golang@sha256:0123456789abcdef0123456789abcdef",
+												Command: "echo $LANGUAGE $STEP_VAR $OTHER",
+												Env:     []corev1.EnvVar{{Name: "STEP_VAR", Value: "baz"}},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	issues := pipelineConfig.Lint("OTHER")
+	assert.Equal(t, []jenkinsfile.LintIssue{
+		{
+			Location: "pipelines.release.build.steps[bad-image].image",
+			Message:  `invalid image reference "gcr.io/My Image:latest"`,
+		},
+		{
+			Location: "pipelines.release.build.steps[placeholder-image]",
+			Message:  "environment variable UNDECLARED is not declared in the step, stage, pipeline or project",
+			Warning:  true,
+		},
+	}, issues)
+}
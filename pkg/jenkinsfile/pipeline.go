@@ -116,12 +116,14 @@ func (x *PipelineExtends) ImportFile() *ImportFile {
 
 // PipelineConfig defines the pipeline configuration
 type PipelineConfig struct {
-	Extends          *PipelineExtends  `json:"extends,omitempty"`
-	Agent            *syntax.Agent     `json:"agent,omitempty"`
-	Env              []corev1.EnvVar   `json:"env,omitempty"`
-	Environment      string            `json:"environment,omitempty"`
-	Pipelines        Pipelines         `json:"pipelines,omitempty"`
-	ContainerOptions *corev1.Container `json:"containerOptions,omitempty"`
+	Extends          *PipelineExtends    `json:"extends,omitempty"`
+	Agent            *syntax.Agent       `json:"agent,omitempty"`
+	Env              []corev1.EnvVar     `json:"env,omitempty"`
+	Environment      string              `json:"environment,omitempty"`
+	Pipelines        Pipelines           `json:"pipelines,omitempty"`
+	ContainerOptions *corev1.Container   `json:"containerOptions,omitempty"`
+	Checkout         *syntax.Checkout    `json:"checkout,omitempty"`
+	BuildEngine      *syntax.BuildEngine `json:"buildEngine,omitempty"`
 }
 
 // CreateJenkinsfileArguments contains the arguents to generate a Jenkinsfiles dynamically
@@ -628,6 +630,10 @@ func (c *PipelineConfig) ExtendPipeline(base *PipelineConfig, clearContainer boo
 		return err
 	}
 	c.ContainerOptions = mergedContainer
+	if c.Checkout == nil {
+		c.Checkout = base.Checkout.DeepCopy()
+	}
+	c.BuildEngine = syntax.MergeBuildEngines(base.BuildEngine, c.BuildEngine)
 	base.defaultContainerAndDir()
 	c.defaultContainerAndDir()
 	c.Env = syntax.CombineEnv(c.Env, base.Env)
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/jenkins-x/jx/v2/pkg/jenkinsfile"
+	"github.com/jenkins-x/jx/v2/pkg/tekton/syntax"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -66,3 +67,20 @@ func TestGetLifecycleReturnsError(t *testing.T) {
 	_, err := lifecycles.GetLifecycle("something-else", false)
 	assert.Error(t, err)
 }
+
+func TestExtendPipelineInheritsCheckout(t *testing.T) {
+	base := &jenkinsfile.PipelineConfig{
+		Checkout: &syntax.Checkout{Submodules: true},
+	}
+	config := &jenkinsfile.PipelineConfig{}
+	err := config.ExtendPipeline(base, false)
+	assert.NoError(t, err)
+	assert.Equal(t, base.Checkout, config.Checkout)
+	assert.False(t, base.Checkout == config.Checkout, "the checkout should be copied from the base pipeline")
+
+	override := &syntax.Checkout{Repositories: []syntax.CheckoutRepository{{URL: "https://github.com/myorg/mylib.git"}}}
+	config = &jenkinsfile.PipelineConfig{Checkout: override}
+	err = config.ExtendPipeline(base, false)
+	assert.NoError(t, err)
+	assert.Equal(t, override, config.Checkout)
+}
@@ -131,6 +131,16 @@ func (in *PipelineConfig) DeepCopyInto(out *PipelineConfig) {
 		*out = new(v1.Container)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Checkout != nil {
+		in, out := &in.Checkout, &out.Checkout
+		*out = new(syntax.Checkout)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BuildEngine != nil {
+		in, out := &in.BuildEngine, &out.BuildEngine
+		*out = new(syntax.BuildEngine)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
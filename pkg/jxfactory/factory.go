@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/jenkins-x/jx/v2/pkg/util/trace"
 
@@ -13,6 +14,8 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	tektonclient "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
 	resourceclient "github.com/tektoncd/pipeline/pkg/client/resource/clientset/versioned"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -27,6 +30,31 @@ type factory struct {
 	impersonateUser string
 	bearerToken     string
 	kubeConfigCache *string
+	cache           *clientCache
+}
+
+// clientCache the lazily created kube configuration, current namespace and clients shared by all the calls of a
+// factory so that the kube config file is only read once and the clients reuse their connections and API discovery
+type clientCache struct {
+	lock           sync.Mutex
+	fingerprint    string
+	config         *rest.Config
+	namespace      *string
+	kubeClient     kubernetes.Interface
+	jxClient       versioned.Interface
+	tektonClient   tektonclient.Interface
+	resourceClient resourceclient.Interface
+}
+
+// cachedDiscoveryClientset a kube clientset whose API discovery results are cached in memory
+type cachedDiscoveryClientset struct {
+	kubernetes.Interface
+	discovery discovery.CachedDiscoveryInterface
+}
+
+// Discovery returns the in memory cached discovery client
+func (c *cachedDiscoveryClientset) Discovery() discovery.DiscoveryInterface {
+	return c.discovery
 }
 
 var _ Factory = (*factory)(nil)
@@ -37,6 +65,7 @@ var _ Factory = (*factory)(nil)
 func NewFactory() Factory {
 	f := &factory{}
 	f.kubeConfig = kube.NewKubeConfig()
+	f.cache = &clientCache{}
 	return f
 }
 
@@ -44,6 +73,7 @@ func NewFactory() Factory {
 func (f *factory) ImpersonateUser(user string) Factory {
 	copy := *f
 	copy.impersonateUser = user
+	copy.cache = &clientCache{}
 	return &copy
 }
 
@@ -51,6 +81,7 @@ func (f *factory) ImpersonateUser(user string) Factory {
 func (f *factory) WithBearerToken(token string) Factory {
 	copy := *f
 	copy.bearerToken = token
+	copy.cache = &clientCache{}
 	return &copy
 }
 
@@ -59,29 +90,100 @@ func (f *factory) KubeConfig() kube.Kuber {
 	return f.kubeConfig
 }
 
+// CreateKubeClient returns the Kubernetes client of the factory creating it on the first call. It also returns the
+// current namespace as per KUBECONFIG
 func (f *factory) CreateKubeClient() (kubernetes.Interface, string, error) {
-	cfg, err := f.CreateKubeConfig()
+	c := f.clients()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	cfg, err := f.restConfig()
 	if err != nil {
 		return nil, "", err
 	}
-	client, err := kubernetes.NewForConfig(cfg)
+	if c.kubeClient == nil {
+		client, err := kubernetes.NewForConfig(cfg)
+		if err != nil {
+			return nil, "", err
+		}
+		if client == nil {
+			return nil, "", fmt.Errorf("failed to create Kubernetes Client")
+		}
+		c.kubeClient = &cachedDiscoveryClientset{
+			Interface: client,
+			discovery: memory.NewMemCacheClient(client.Discovery()),
+		}
+	}
+	// TODO allow namsepace to be specified as a CLI argument!
+	ns, err := f.currentNamespace()
+	return c.kubeClient, ns, err
+}
+
+// CreateKubeConfig returns a copy of the kube configuration which is loaded on the first call
+func (f *factory) CreateKubeConfig() (*rest.Config, error) {
+	c := f.clients()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	config, err := f.restConfig()
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
-	if client == nil {
-		return nil, "", fmt.Errorf("failed to create Kubernetes Client")
+	return rest.CopyConfig(config), nil
+}
+
+// clients returns the cache of the clients of the factory
+func (f *factory) clients() *clientCache {
+	if f.cache == nil {
+		f.cache = &clientCache{}
 	}
-	ns := ""
-	config, _, err := f.kubeConfig.LoadConfig()
-	if err != nil {
-		return client, ns, err
+	return f.cache
+}
+
+// refresh discards the cached configuration and clients if the kube config files changed since they were created.
+// The lock of the cache must be held
+func (c *clientCache) refresh() {
+	fingerprint := kube.KubeConfigFingerprint()
+	if fingerprint == c.fingerprint {
+		return
 	}
-	ns = kube.CurrentNamespace(config)
-	// TODO allow namsepace to be specified as a CLI argument!
-	return client, ns, nil
+	c.fingerprint = fingerprint
+	c.config = nil
+	c.namespace = nil
+	c.kubeClient = nil
+	c.jxClient = nil
+	c.tektonClient = nil
+	c.resourceClient = nil
 }
 
-func (f *factory) CreateKubeConfig() (*rest.Config, error) {
+// restConfig returns the cached kube configuration loading it if required. The lock of the cache must be held
+func (f *factory) restConfig() (*rest.Config, error) {
+	f.cache.refresh()
+	if f.cache.config == nil {
+		config, err := f.loadKubeConfig()
+		if err != nil {
+			return nil, err
+		}
+		f.cache.config = config
+	}
+	return f.cache.config, nil
+}
+
+// currentNamespace returns the cached current namespace of the kube config file loading it if required. The lock of
+// the cache must be held
+func (f *factory) currentNamespace() (string, error) {
+	f.cache.refresh()
+	if f.cache.namespace == nil {
+		kubeConfig, _, err := f.kubeConfig.LoadConfig()
+		if err != nil {
+			return "", err
+		}
+		ns := kube.CurrentNamespace(kubeConfig)
+		f.cache.namespace = &ns
+	}
+	return *f.cache.namespace, nil
+}
+
+// loadKubeConfig loads the kube configuration from $KUBECONFIG, ~/.kube/config or the pod
+func (f *factory) loadKubeConfig() (*rest.Config, error) {
 	masterURL := ""
 	kubeConfigEnv := os.Getenv("KUBECONFIG")
 	if kubeConfigEnv != "" {
@@ -153,54 +255,68 @@ func (f *factory) getImpersonateUser() string {
 	return user
 }
 
+// CreateJXClient returns the client of the Jenkins X CRDs creating it on the first call
 func (f *factory) CreateJXClient() (versioned.Interface, string, error) {
-	config, err := f.CreateKubeConfig()
+	c := f.clients()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	config, err := f.restConfig()
 	if err != nil {
 		return nil, "", err
 	}
-
-	kubeConfig, _, err := f.kubeConfig.LoadConfig()
+	ns, err := f.currentNamespace()
 	if err != nil {
 		return nil, "", err
 	}
-	ns := kube.CurrentNamespace(kubeConfig)
-	client, err := versioned.NewForConfig(config)
-	if err != nil {
-		return nil, ns, err
+	if c.jxClient == nil {
+		c.jxClient, err = versioned.NewForConfig(config)
+		if err != nil {
+			return nil, ns, err
+		}
 	}
-	return client, ns, err
+	return c.jxClient, ns, nil
 }
 
+// CreateTektonClient returns the client of the Tekton resources creating it on the first call
 func (f *factory) CreateTektonClient() (tektonclient.Interface, string, error) {
-	config, err := f.CreateKubeConfig()
+	c := f.clients()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	config, err := f.restConfig()
 	if err != nil {
 		return nil, "", err
 	}
-	kubeConfig, _, err := f.kubeConfig.LoadConfig()
+	ns, err := f.currentNamespace()
 	if err != nil {
 		return nil, "", err
 	}
-	ns := kube.CurrentNamespace(kubeConfig)
-	client, err := tektonclient.NewForConfig(config)
-	if err != nil {
-		return nil, ns, err
+	if c.tektonClient == nil {
+		c.tektonClient, err = tektonclient.NewForConfig(config)
+		if err != nil {
+			return nil, ns, err
+		}
 	}
-	return client, ns, err
+	return c.tektonClient, ns, nil
 }
 
+// CreateTektonPipelineResourceClient returns the client of the Tekton PipelineResources creating it on the first call
 func (f *factory) CreateTektonPipelineResourceClient() (resourceclient.Interface, string, error) {
-	config, err := f.CreateKubeConfig()
+	c := f.clients()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	config, err := f.restConfig()
 	if err != nil {
 		return nil, "", err
 	}
-	kubeConfig, _, err := f.kubeConfig.LoadConfig()
+	ns, err := f.currentNamespace()
 	if err != nil {
 		return nil, "", err
 	}
-	ns := kube.CurrentNamespace(kubeConfig)
-	client, err := resourceclient.NewForConfig(config)
-	if err != nil {
-		return nil, ns, err
+	if c.resourceClient == nil {
+		c.resourceClient, err = resourceclient.NewForConfig(config)
+		if err != nil {
+			return nil, ns, err
+		}
 	}
-	return client, ns, err
+	return c.resourceClient, ns, nil
 }
@@ -0,0 +1,97 @@
+// +build unit
+
+package jxfactory
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeKubeConfig(t *testing.T, fileName string, server string, namespace string, modified time.Time) {
+	config := fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: %s
+contexts:
+- name: test
+  context:
+    cluster: test
+    namespace: %s
+    user: test
+current-context: test
+users:
+- name: test
+  user:
+    token: abc
+`, server, namespace)
+	err := ioutil.WriteFile(fileName, []byte(config), 0600)
+	require.NoError(t, err)
+	err = os.Chtimes(fileName, modified, modified)
+	require.NoError(t, err)
+}
+
+func TestFactoryCachesClients(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jxfactory")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fileName := filepath.Join(dir, "config")
+	modified := time.Now().Add(-time.Hour)
+	writeKubeConfig(t, fileName, "https://first.example.com", "jx", modified)
+	oldKubeConfig, hadKubeConfig := os.LookupEnv("KUBECONFIG")
+	os.Setenv("KUBECONFIG", fileName)
+	defer func() {
+		if hadKubeConfig {
+			os.Setenv("KUBECONFIG", oldKubeConfig)
+		} else {
+			os.Unsetenv("KUBECONFIG")
+		}
+	}()
+
+	f := NewFactory()
+	kubeClient, ns, err := f.CreateKubeClient()
+	require.NoError(t, err)
+	assert.Equal(t, "jx", ns)
+	cachedClient, _, err := f.CreateKubeClient()
+	require.NoError(t, err)
+	assert.True(t, kubeClient == cachedClient, "the kube client should be cached")
+
+	jxClient, _, err := f.CreateJXClient()
+	require.NoError(t, err)
+	cachedJXClient, _, err := f.CreateJXClient()
+	require.NoError(t, err)
+	assert.True(t, jxClient == cachedJXClient, "the jx client should be cached")
+
+	config, err := f.CreateKubeConfig()
+	require.NoError(t, err)
+	other, err := f.CreateKubeConfig()
+	require.NoError(t, err)
+	assert.False(t, config == other, "the kube configuration should be copied")
+	assert.Equal(t, "https://first.example.com", config.Host)
+	config.Host = "https://changed.example.com"
+	other, err = f.CreateKubeConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "https://first.example.com", other.Host)
+
+	impersonated, _, err := f.ImpersonateUser("jenkins").CreateKubeClient()
+	require.NoError(t, err)
+	assert.False(t, kubeClient == impersonated, "an impersonating factory should create its own clients")
+
+	writeKubeConfig(t, fileName, "https://second.example.com", "jx-staging", modified.Add(time.Minute))
+	changedClient, ns, err := f.CreateKubeClient()
+	require.NoError(t, err)
+	assert.Equal(t, "jx-staging", ns)
+	assert.False(t, kubeClient == changedClient, "the kube client should be created again when the kube config changes")
+	config, err = f.CreateKubeConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "https://second.example.com", config.Host)
+}
@@ -778,23 +778,69 @@ func ListSelectedPipelineActivities(activitiesClient typev1.PipelineActivityInte
 		return nil, err
 	}
 
-	if fieldSelector == nil {
-		return pipelineActivityList, nil
+	pipelineActivityList.Items, err = filterPipelineActivities(pipelineActivityList.Items, fieldSelector)
+	if err != nil {
+		return nil, err
 	}
+	return pipelineActivityList, nil
+}
 
+// ListPipelineActivitiesInPages lists the PipelineActivities matching the label and field selectors in chunks of the page
+// size so that the activities of large clusters are not all loaded into memory at once. The function is invoked with the
+// matching activities of each page and stops the listing if it returns false. Selectors can be empty or nil.
+func ListPipelineActivitiesInPages(activitiesClient typev1.PipelineActivityInterface, labelSelector fmt.Stringer, fieldSelector fields.Selector, pageSize int64, fn func([]v1.PipelineActivity) (bool, error)) error {
+	listOptions := metav1.ListOptions{
+		Limit: pageSize,
+	}
+	if labelSelector != nil {
+		listOptions.LabelSelector = labelSelector.String()
+	}
+	for {
+		pipelineActivityList, err := activitiesClient.List(listOptions)
+		if err != nil {
+			return errors.Wrap(err, "listing the PipelineActivities")
+		}
+		items, err := filterPipelineActivities(pipelineActivityList.Items, fieldSelector)
+		if err != nil {
+			return err
+		}
+		more, err := fn(items)
+		if err != nil || !more || pipelineActivityList.Continue == "" {
+			return err
+		}
+		listOptions.Continue = pipelineActivityList.Continue
+	}
+}
+
+// filterPipelineActivities applies the field selector client side as field selectors cannot directly be applied to
+// the list query for custom CRDs - https://github.com/kubernetes/kubernetes/issues/51046
+func filterPipelineActivities(pipelineActivities []v1.PipelineActivity, fieldSelector fields.Selector) ([]v1.PipelineActivity, error) {
+	if fieldSelector == nil {
+		return pipelineActivities, nil
+	}
 	var matchedItems []v1.PipelineActivity
-	for _, pipelineActivity := range pipelineActivityList.Items {
-		fieldMap, err := newFieldMap(pipelineActivity)
+	for _, pipelineActivity := range pipelineActivities {
+		matches, err := PipelineActivityMatchesFields(pipelineActivity, fieldSelector)
 		if err != nil {
-			return nil, errors.Wrap(err, "unable to convert struct to map")
+			return nil, err
 		}
-		if fieldSelector.Matches(fieldMap) {
+		if matches {
 			matchedItems = append(matchedItems, pipelineActivity)
 		}
 	}
+	return matchedItems, nil
+}
 
-	pipelineActivityList.Items = matchedItems
-	return pipelineActivityList, nil
+// PipelineActivityMatchesFields returns true if the fields of the PipelineActivity match the field selector
+func PipelineActivityMatchesFields(pipelineActivity v1.PipelineActivity, fieldSelector fields.Selector) (bool, error) {
+	if fieldSelector == nil {
+		return true, nil
+	}
+	fieldMap, err := newFieldMap(pipelineActivity)
+	if err != nil {
+		return false, errors.Wrap(err, "unable to convert struct to map")
+	}
+	return fieldSelector.Matches(fieldMap), nil
 }
 
 func asYaml(activity *v1.PipelineActivity) string {
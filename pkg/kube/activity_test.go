@@ -22,6 +22,8 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/stretchr/testify/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	kube_mocks "k8s.io/client-go/kubernetes/fake"
 )
 
@@ -684,3 +686,56 @@ func validatePipelineID(t *testing.T, pID kube.PipelineID, expectedID string, ex
 	assert.Equal(t, expectedID, pID.ID)
 	assert.Equal(t, expectedName, pID.Name)
 }
+
+// pagedActivities returns the activities in pages of two activities using the page number as the continue token
+type pagedActivities struct {
+	typev1.PipelineActivityInterface
+	requests []metav1.ListOptions
+}
+
+func (p *pagedActivities) List(options metav1.ListOptions) (*v1.PipelineActivityList, error) {
+	p.requests = append(p.requests, options)
+	page, _ := strconv.Atoi(options.Continue)
+	list := &v1.PipelineActivityList{}
+	for i := page * 2; i < page*2+2 && i < 5; i++ {
+		status := v1.ActivityStatusTypeSucceeded
+		if i%2 == 0 {
+			status = v1.ActivityStatusTypeFailed
+		}
+		list.Items = append(list.Items, v1.PipelineActivity{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("a%d", i)},
+			Spec:       v1.PipelineActivitySpec{Status: status},
+		})
+	}
+	if page < 2 {
+		list.Continue = strconv.Itoa(page + 1)
+	}
+	return list, nil
+}
+
+func TestListPipelineActivitiesInPages(t *testing.T) {
+	t.Parallel()
+	activities := &pagedActivities{}
+
+	var names []string
+	err := kube.ListPipelineActivitiesInPages(activities, labels.SelectorFromSet(labels.Set{"owner": "foo"}), fields.OneTermEqualSelector("spec.status", "Failed"), 2, func(items []v1.PipelineActivity) (bool, error) {
+		for _, item := range items {
+			names = append(names, item.Name)
+		}
+		return true, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a0", "a2", "a4"}, names)
+	assert.Len(t, activities.requests, 3)
+	assert.Equal(t, int64(2), activities.requests[0].Limit)
+	assert.Equal(t, "owner=foo", activities.requests[0].LabelSelector)
+	assert.Equal(t, "", activities.requests[0].Continue)
+	assert.Equal(t, "2", activities.requests[2].Continue)
+
+	activities = &pagedActivities{}
+	err = kube.ListPipelineActivitiesInPages(activities, nil, nil, 2, func(items []v1.PipelineActivity) (bool, error) {
+		return false, nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, activities.requests, 1)
+}
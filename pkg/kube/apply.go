@@ -0,0 +1,36 @@
+package kube
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// FieldManager the manager of the fields jx applies to resources with server-side apply
+	FieldManager = "jx"
+
+	// LabelApplySet the label of the resources applied together such as the resources of a chart release so that the
+	// resources removed from the set can be pruned
+	LabelApplySet = "jenkins.io/apply-set"
+)
+
+// ServerSideApplyArgs returns the arguments of kubectl apply to apply manifests server-side with the jx field manager.
+// The conflicts are always forced as jx owns the resources it applies: resources applied client-side by earlier
+// versions of jx have their fields owned by the kubectl client-side apply manager so that applying them server-side
+// would otherwise fail with conflicts, and client-side apply never reported conflicts either
+func ServerSideApplyArgs() []string {
+	return []string{"--server-side", "--field-manager=" + FieldManager, "--force-conflicts"}
+}
+
+// ApplySetID returns the value of the apply set label of the resources of a release in a namespace. Long names are
+// hashed to fit into a label value
+func ApplySetID(ns string, releaseName string) string {
+	id := releaseName + "." + ns
+	if len(id) <= validation.LabelValueMaxLength {
+		return id
+	}
+	hash := sha256.Sum256([]byte(id))
+	return "applyset-" + hex.EncodeToString(hash[:])[:40]
+}
@@ -0,0 +1,29 @@
+// +build unit
+
+package kube_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestServerSideApplyArgs(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"--server-side", "--field-manager=jx", "--force-conflicts"}, kube.ServerSideApplyArgs())
+}
+
+func TestApplySetID(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "jenkins-x-platform.jx", kube.ApplySetID("jx", "jenkins-x-platform"))
+
+	long := kube.ApplySetID(strings.Repeat("n", 63), "myapp")
+	assert.Empty(t, validation.IsValidLabelValue(long))
+	assert.Equal(t, long, kube.ApplySetID(strings.Repeat("n", 63), "myapp"))
+	assert.NotEqual(t, long, kube.ApplySetID(strings.Repeat("n", 63), "other"))
+}
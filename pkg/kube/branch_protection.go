@@ -0,0 +1,80 @@
+package kube
+
+import (
+	"strings"
+
+	"github.com/ghodss/yaml"
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// AnnotationBranchProtection the annotation of the dev Environment containing the YAML branch protection template
+// applied to the default branch of the application and environment repositories jx creates
+const AnnotationBranchProtection = "jenkins.io/branch-protection"
+
+// BranchProtectionTemplate returns the branch protection template of the team or nil if repositories are not protected
+func BranchProtectionTemplate(devEnv *v1.Environment) (*gits.BranchProtection, error) {
+	if devEnv == nil {
+		return nil, nil
+	}
+	text := strings.TrimSpace(devEnv.Annotations[AnnotationBranchProtection])
+	if text == "" {
+		return nil, nil
+	}
+	protection := &gits.BranchProtection{}
+	err := yaml.Unmarshal([]byte(text), protection)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshalling the %s annotation of environment %s", AnnotationBranchProtection, devEnv.Name)
+	}
+	return protection, nil
+}
+
+// SetBranchProtectionTemplate sets the branch protection template of the team, removing it if the protection is nil
+func SetBranchProtectionTemplate(devEnv *v1.Environment, protection *gits.BranchProtection) error {
+	if protection == nil {
+		delete(devEnv.Annotations, AnnotationBranchProtection)
+		return nil
+	}
+	err := protection.Validate()
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(protection)
+	if err != nil {
+		return errors.Wrap(err, "marshalling the branch protection")
+	}
+	if devEnv.Annotations == nil {
+		devEnv.Annotations = map[string]string{}
+	}
+	devEnv.Annotations[AnnotationBranchProtection] = string(data)
+	return nil
+}
+
+// ApplyBranchProtectionTemplate protects the branch of a repository jx created with the branch protection template of
+// the team if it has one
+func ApplyBranchProtectionTemplate(devEnv *v1.Environment, provider gits.GitProvider, repo *gits.GitRepository, branch string) error {
+	protection, err := BranchProtectionTemplate(devEnv)
+	if err != nil || protection == nil {
+		return err
+	}
+	owner := repo.Organisation
+	if owner == "" {
+		info, err := gits.ParseGitURL(repo.CloneURL)
+		if err != nil {
+			return errors.Wrapf(err, "parsing the git URL %s", repo.CloneURL)
+		}
+		owner = info.Organisation
+	}
+	if branch == "" {
+		branch = "master"
+	}
+	err = gits.ProtectBranch(provider, owner, repo.Name, branch, protection)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Protected the branch %s of %s with %s", util.ColorInfo(branch), util.ColorInfo(owner+"/"+repo.Name), protection.Description())
+	return nil
+}
@@ -0,0 +1,230 @@
+package cluster
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/cloud"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+var (
+	// eksctl names clusters <cluster-name>.<region>.eksctl.io
+	eksctlClusterRegex = regexp.MustCompile(`^([a-zA-Z][-a-zA-Z0-9]*)\.([a-z]{2}(?:-gov)?-[a-z]+-\d)\.eksctl\.io$`)
+	// the aws cli names clusters after their ARN
+	eksARNRegex = regexp.MustCompile(`^arn:aws[-a-z]*:eks:([a-z]{2}(?:-gov)?-[a-z]+-\d):[0-9]*:cluster/([a-zA-Z][-a-zA-Z0-9]*)$`)
+)
+
+// ManagedCluster a cluster of a managed Kubernetes provider referenced by a kube config
+type ManagedCluster struct {
+	// Provider the Kubernetes provider, one of gke, eks or aks
+	Provider string
+	// Project the GCP project of GKE clusters
+	Project string
+	// Location the zone or region of GKE clusters and the region of EKS clusters
+	Location string
+	// Name the name of the cluster at the provider
+	Name string
+	// Server the URL of the kube API server
+	Server string
+}
+
+// String returns a description of the cluster
+func (c *ManagedCluster) String() string {
+	return fmt.Sprintf("%s cluster %s", strings.ToUpper(c.Provider), c.Name)
+}
+
+// IsCertificateAuthorityError returns true if the error is caused by the kube API server presenting a certificate
+// which is not signed by the certificate authority in the kube config, which is what happens after the certificate
+// authority of a cluster has been rotated
+func IsCertificateAuthorityError(err error) bool {
+	for err != nil {
+		switch err.(type) {
+		case x509.UnknownAuthorityError, *x509.UnknownAuthorityError:
+			return true
+		}
+		if strings.Contains(err.Error(), "x509: certificate signed by unknown authority") {
+			return true
+		}
+		cause := errors.Cause(err)
+		if cause != err {
+			err = cause
+			continue
+		}
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+			continue
+		}
+		return false
+	}
+	return false
+}
+
+// ParseManagedCluster returns the managed cluster referenced by the given kube config context by parsing the cluster
+// names and server URLs generated by the gcloud, aws, eksctl and az command line tools
+func ParseManagedCluster(config *api.Config, contextName string) (*ManagedCluster, error) {
+	if config == nil {
+		return nil, errors.New("no kube config")
+	}
+	if contextName == "" {
+		contextName = config.CurrentContext
+	}
+	context := config.Contexts[contextName]
+	if context == nil {
+		return nil, errors.Errorf("kube context %s not found", contextName)
+	}
+	clusterName := context.Cluster
+	cluster := config.Clusters[clusterName]
+	if cluster == nil {
+		return nil, errors.Errorf("cluster %s of kube context %s not found", clusterName, contextName)
+	}
+
+	// gcloud names clusters gke_<project>_<location>_<cluster-name>
+	if strings.HasPrefix(clusterName, "gke_") {
+		parts := strings.SplitN(clusterName, "_", 4)
+		if len(parts) == 4 {
+			return &ManagedCluster{
+				Provider: cloud.GKE,
+				Project:  parts[1],
+				Location: parts[2],
+				Name:     parts[3],
+				Server:   cluster.Server,
+			}, nil
+		}
+	}
+	if result := eksARNRegex.FindStringSubmatch(clusterName); result != nil {
+		return &ManagedCluster{Provider: cloud.EKS, Location: result[1], Name: result[2], Server: cluster.Server}, nil
+	}
+	if result := eksctlClusterRegex.FindStringSubmatch(clusterName); result != nil {
+		return &ManagedCluster{Provider: cloud.EKS, Location: result[2], Name: result[1], Server: cluster.Server}, nil
+	}
+	// az names clusters after the AKS cluster whose API servers are hosted on azmk8s.io
+	u, err := url.Parse(cluster.Server)
+	if err == nil && strings.HasSuffix(u.Hostname(), ".azmk8s.io") {
+		return &ManagedCluster{Provider: cloud.AKS, Name: clusterName, Server: cluster.Server}, nil
+	}
+	return nil, errors.Errorf("kube context %s does not reference a GKE, EKS or AKS cluster", contextName)
+}
+
+// CertificateAuthorityFetcher fetches the current certificate authority of managed clusters from the provider API
+// using the provider command line tools
+type CertificateAuthorityFetcher struct {
+	Runner util.Commander
+}
+
+// NewCertificateAuthorityFetcher creates a new fetcher which runs the provider command line tools
+func NewCertificateAuthorityFetcher() *CertificateAuthorityFetcher {
+	return &CertificateAuthorityFetcher{
+		Runner: &util.Command{},
+	}
+}
+
+// Fetch returns the PEM encoded certificate authority data of the given cluster
+func (f *CertificateAuthorityFetcher) Fetch(c *ManagedCluster) ([]byte, error) {
+	switch c.Provider {
+	case cloud.GKE:
+		args := []string{"container", "clusters", "describe", c.Name, "--project", c.Project,
+			"--format", "value(masterAuth.clusterCaCertificate)"}
+		// zones have the form <region>-<zone> e.g. europe-west1-b
+		if strings.Count(c.Location, "-") > 1 {
+			args = append(args, "--zone", c.Location)
+		} else {
+			args = append(args, "--region", c.Location)
+		}
+		return f.fetchEncoded("gcloud", args...)
+	case cloud.EKS:
+		return f.fetchEncoded("aws", "eks", "describe-cluster", "--name", c.Name, "--region", c.Location,
+			"--query", "cluster.certificateAuthority.data", "--output", "text")
+	case cloud.AKS:
+		return f.fetchAKS(c)
+	default:
+		return nil, errors.Errorf("fetching the certificate authority of %s clusters is not supported", c.Provider)
+	}
+}
+
+func (f *CertificateAuthorityFetcher) fetchEncoded(name string, args ...string) ([]byte, error) {
+	output, err := f.run(name, args...)
+	if err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(output))
+	if err != nil {
+		return nil, errors.Wrapf(err, "decoding the certificate authority returned by %s", name)
+	}
+	return data, validateCertificateAuthorityData(data)
+}
+
+func (f *CertificateAuthorityFetcher) fetchAKS(c *ManagedCluster) ([]byte, error) {
+	output, err := f.run("az", "aks", "list", "--query",
+		fmt.Sprintf("[?name=='%s'].resourceGroup | [0]", c.Name), "--output", "tsv")
+	if err != nil {
+		return nil, err
+	}
+	group := strings.TrimSpace(output)
+	if group == "" {
+		return nil, errors.Errorf("AKS cluster %s not found", c.Name)
+	}
+	// az has no command which only describes the certificate authority so lets extract it from the cluster kube config
+	output, err = f.run("az", "aks", "get-credentials", "--resource-group", group, "--name", c.Name, "--file", "-")
+	if err != nil {
+		return nil, err
+	}
+	config, err := clientcmd.Load([]byte(output))
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the kube config of AKS cluster %s", c.Name)
+	}
+	for _, cluster := range config.Clusters {
+		if cluster != nil && len(cluster.CertificateAuthorityData) > 0 {
+			return cluster.CertificateAuthorityData, validateCertificateAuthorityData(cluster.CertificateAuthorityData)
+		}
+	}
+	return nil, errors.Errorf("no certificate authority found in the kube config of AKS cluster %s", c.Name)
+}
+
+func (f *CertificateAuthorityFetcher) run(name string, args ...string) (string, error) {
+	f.Runner.SetName(name)
+	f.Runner.SetArgs(args)
+	output, err := f.Runner.RunWithoutRetry()
+	if err != nil {
+		return "", errors.Wrapf(err, "running %s %s", name, strings.Join(args, " "))
+	}
+	return output, nil
+}
+
+func validateCertificateAuthorityData(data []byte) error {
+	if !x509.NewCertPool().AppendCertsFromPEM(data) {
+		return errors.New("the certificate authority returned by the provider does not contain any PEM encoded certificates")
+	}
+	return nil
+}
+
+// UpdateCertificateAuthority replaces the certificate authority of the cluster referenced by the given kube config
+// context, returning false if the certificate authority is already up to date
+func UpdateCertificateAuthority(config *api.Config, contextName string, data []byte) (bool, error) {
+	if contextName == "" {
+		contextName = config.CurrentContext
+	}
+	context := config.Contexts[contextName]
+	if context == nil {
+		return false, errors.Errorf("kube context %s not found", contextName)
+	}
+	cluster := config.Clusters[context.Cluster]
+	if cluster == nil {
+		return false, errors.Errorf("cluster %s of kube context %s not found", context.Cluster, contextName)
+	}
+	if cluster.CertificateAuthority == "" && bytes.Equal(cluster.CertificateAuthorityData, data) {
+		return false, nil
+	}
+	// the data takes precedence but a stale file reference would still confuse anyone reading the kube config
+	cluster.CertificateAuthority = ""
+	cluster.CertificateAuthorityData = data
+	return true, nil
+}
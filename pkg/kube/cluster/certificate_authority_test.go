@@ -0,0 +1,171 @@
+// +build unit
+
+package cluster_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/cloud"
+	"github.com/jenkins-x/jx/v2/pkg/kube/cluster"
+	mocks "github.com/jenkins-x/jx/v2/pkg/util/mocks"
+	. "github.com/petergtz/pegomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestIsCertificateAuthorityError(t *testing.T) {
+	tlsErr := &url.Error{Op: "Get", URL: "https://35.1.2.3/api", Err: x509.UnknownAuthorityError{}}
+	assert.True(t, cluster.IsCertificateAuthorityError(tlsErr))
+	assert.True(t, cluster.IsCertificateAuthorityError(errors.Wrap(tlsErr, "listing namespaces")))
+	assert.True(t, cluster.IsCertificateAuthorityError(fmt.Errorf("Get https://35.1.2.3/api: x509: certificate signed by unknown authority")))
+
+	assert.False(t, cluster.IsCertificateAuthorityError(nil))
+	assert.False(t, cluster.IsCertificateAuthorityError(&url.Error{Op: "Get", URL: "https://35.1.2.3/api", Err: errors.New("connection refused")}))
+	assert.False(t, cluster.IsCertificateAuthorityError(x509.HostnameError{Host: "35.1.2.3", Certificate: &x509.Certificate{}}))
+}
+
+func TestParseManagedCluster(t *testing.T) {
+	testCases := []struct {
+		cluster  string
+		server   string
+		expected *cluster.ManagedCluster
+	}{
+		{
+			cluster:  "gke_myproject_europe-west1-b_my_cluster",
+			server:   "https://35.1.2.3",
+			expected: &cluster.ManagedCluster{Provider: cloud.GKE, Project: "myproject", Location: "europe-west1-b", Name: "my_cluster", Server: "https://35.1.2.3"},
+		},
+		{
+			cluster:  "arn:aws:eks:us-west-2:123456789012:cluster/mycluster",
+			server:   "https://ABC.gr7.us-west-2.eks.amazonaws.com",
+			expected: &cluster.ManagedCluster{Provider: cloud.EKS, Location: "us-west-2", Name: "mycluster", Server: "https://ABC.gr7.us-west-2.eks.amazonaws.com"},
+		},
+		{
+			cluster:  "mycluster.eu-central-1.eksctl.io",
+			server:   "https://ABC.gr7.eu-central-1.eks.amazonaws.com",
+			expected: &cluster.ManagedCluster{Provider: cloud.EKS, Location: "eu-central-1", Name: "mycluster", Server: "https://ABC.gr7.eu-central-1.eks.amazonaws.com"},
+		},
+		{
+			cluster:  "myaks",
+			server:   "https://myaks-dns-123.hcp.westeurope.azmk8s.io:443",
+			expected: &cluster.ManagedCluster{Provider: cloud.AKS, Name: "myaks", Server: "https://myaks-dns-123.hcp.westeurope.azmk8s.io:443"},
+		},
+		{
+			cluster: "minikube",
+			server:  "https://192.168.99.100:8443",
+		},
+	}
+	for _, tc := range testCases {
+		config := kubeConfig(tc.cluster, tc.server)
+		actual, err := cluster.ParseManagedCluster(config, "")
+		if tc.expected == nil {
+			assert.Error(t, err, "cluster %s", tc.cluster)
+			continue
+		}
+		require.NoError(t, err, "cluster %s", tc.cluster)
+		assert.Equal(t, tc.expected, actual, "cluster %s", tc.cluster)
+	}
+}
+
+func TestFetchCertificateAuthority(t *testing.T) {
+	RegisterMockTestingT(t)
+	caData := createCertificateAuthority(t)
+
+	testCases := []struct {
+		cluster *cluster.ManagedCluster
+		name    string
+		args    []string
+	}{
+		{
+			cluster: &cluster.ManagedCluster{Provider: cloud.GKE, Project: "myproject", Location: "europe-west1-b", Name: "mycluster"},
+			name:    "gcloud",
+			args:    []string{"container", "clusters", "describe", "mycluster", "--project", "myproject", "--format", "value(masterAuth.clusterCaCertificate)", "--zone", "europe-west1-b"},
+		},
+		{
+			cluster: &cluster.ManagedCluster{Provider: cloud.GKE, Project: "myproject", Location: "europe-west1", Name: "mycluster"},
+			name:    "gcloud",
+			args:    []string{"container", "clusters", "describe", "mycluster", "--project", "myproject", "--format", "value(masterAuth.clusterCaCertificate)", "--region", "europe-west1"},
+		},
+		{
+			cluster: &cluster.ManagedCluster{Provider: cloud.EKS, Location: "us-west-2", Name: "mycluster"},
+			name:    "aws",
+			args:    []string{"eks", "describe-cluster", "--name", "mycluster", "--region", "us-west-2", "--query", "cluster.certificateAuthority.data", "--output", "text"},
+		},
+	}
+	for _, tc := range testCases {
+		runner := mocks.NewMockCommander()
+		When(runner.RunWithoutRetry()).ThenReturn(base64.StdEncoding.EncodeToString(caData)+"\n", nil)
+		fetcher := &cluster.CertificateAuthorityFetcher{Runner: runner}
+
+		data, err := fetcher.Fetch(tc.cluster)
+		require.NoError(t, err, "fetching %s", tc.cluster.String())
+		assert.Equal(t, caData, data, "certificate authority of %s", tc.cluster.String())
+		runner.VerifyWasCalledOnce().SetName(tc.name)
+		runner.VerifyWasCalledOnce().SetArgs(tc.args)
+	}
+
+	runner := mocks.NewMockCommander()
+	When(runner.RunWithoutRetry()).ThenReturn(base64.StdEncoding.EncodeToString([]byte("not a certificate")), nil)
+	fetcher := &cluster.CertificateAuthorityFetcher{Runner: runner}
+	_, err := fetcher.Fetch(&cluster.ManagedCluster{Provider: cloud.EKS, Location: "us-west-2", Name: "mycluster"})
+	assert.Error(t, err, "the provider should return a PEM encoded certificate")
+}
+
+func TestUpdateCertificateAuthority(t *testing.T) {
+	config := kubeConfig("gke_myproject_europe-west1-b_mycluster", "https://35.1.2.3")
+	config.Clusters["gke_myproject_europe-west1-b_mycluster"].CertificateAuthority = "/tmp/old-ca.crt"
+
+	changed, err := cluster.UpdateCertificateAuthority(config, "", []byte("new"))
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, []byte("new"), config.Clusters["gke_myproject_europe-west1-b_mycluster"].CertificateAuthorityData)
+	assert.Equal(t, "", config.Clusters["gke_myproject_europe-west1-b_mycluster"].CertificateAuthority)
+
+	changed, err = cluster.UpdateCertificateAuthority(config, "", []byte("new"))
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	_, err = cluster.UpdateCertificateAuthority(config, "missing", []byte("new"))
+	assert.Error(t, err)
+}
+
+func kubeConfig(clusterName string, server string) *api.Config {
+	return &api.Config{
+		CurrentContext: "mycontext",
+		Contexts: map[string]*api.Context{
+			"mycontext": {Cluster: clusterName},
+		},
+		Clusters: map[string]*api.Cluster{
+			clusterName: {Server: server, CertificateAuthorityData: []byte("old")},
+		},
+	}
+}
+
+func createCertificateAuthority(t *testing.T) []byte {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "cluster-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
@@ -3,6 +3,9 @@ package kube
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/pkg/errors"
 	"k8s.io/client-go/tools/clientcmd"
@@ -169,6 +172,25 @@ func (k *KubeConfig) LoadConfig() (*api.Config, *clientcmd.PathOptions, error) {
 	return config, po, err
 }
 
+// KubeConfigFingerprint returns a fingerprint of the kube config files which changes whenever the files are modified,
+// such as when switching context or namespace, so that the configuration and clients created from them can be cached
+func KubeConfigFingerprint() string {
+	files := filepath.SplitList(os.Getenv(clientcmd.RecommendedConfigPathEnvVar))
+	if len(files) == 0 {
+		files = []string{clientcmd.RecommendedHomeFile}
+	}
+	var fingerprint strings.Builder
+	for _, file := range files {
+		fingerprint.WriteString(file)
+		info, err := os.Stat(file)
+		if err == nil {
+			fmt.Fprintf(&fingerprint, ":%d:%d", info.ModTime().UnixNano(), info.Size())
+		}
+		fingerprint.WriteString(";")
+	}
+	return fingerprint.String()
+}
+
 // CurrentNamespace returns the current namespace in the context
 func CurrentNamespace(config *api.Config) string {
 	ctx := CurrentContext(config)
@@ -26,6 +26,18 @@ const (
 	ChartFlaggerGrafana       = "flagger/grafana"
 	DefaultFlaggerReleaseName = "flagger"
 
+	// ChartCosignPolicyController the default chart of the sigstore policy controller admitting only signed images
+	ChartCosignPolicyController = "sigstore/policy-controller"
+	DefaultCosignReleaseName    = "cosign"
+
+	// ChartCrossplane the default chart of Crossplane provisioning the cloud resources claimed by apps
+	ChartCrossplane              = "crossplane-stable/crossplane"
+	DefaultCrossplaneReleaseName = "crossplane"
+
+	// ChartVelero the default chart of Velero backing up the jx namespaces and CRDs
+	ChartVelero              = "vmware-tanzu/velero"
+	DefaultVeleroReleaseName = "velero"
+
 	// ChartIstio the default chart for the Istio chart
 	ChartIstio = "install/kubernetes/helm/istio"
 
@@ -133,6 +145,12 @@ const (
 	// ConfigMapJenkinsDockerRegistry is the ConfigMap containing the Docker Registry configuration
 	ConfigMapJenkinsDockerRegistry = "jenkins-x-docker-registry"
 
+	// ConfigMapJenkinsBuildCache is the ConfigMap containing the layer cache configuration of the image builds
+	ConfigMapJenkinsBuildCache = "jenkins-x-build-cache"
+
+	// ConfigMapJenkinsBuildPackRepositories is the ConfigMap containing the ordered build pack repositories of the team
+	ConfigMapJenkinsBuildPackRepositories = "jenkins-x-buildpack-repositories"
+
 	// ConfigMapNameJXInstallConfig is the ConfigMap containing the jx installation's CA and server url. Used by jx login
 	ConfigMapNameJXInstallConfig = "jx-install-config"
 
@@ -227,9 +245,15 @@ const (
 	// LabelUsername the user name owner of a namespace or resource
 	LabelUsername = "jenkins.io/user"
 
+	// LabelProtected marks a namespace as protected from destructive jx commands
+	LabelProtected = "jenkins.io/protected"
+
 	// ValueCreatedByJX for resources created by the Jenkins X CLI
 	ValueCreatedByJX = "jx"
 
+	// ValueCreatedByJXBoot for namespaces created by older versions of jx boot
+	ValueCreatedByJXBoot = "jx-boot"
+
 	// LabelCredentialsType the kind of jenkins credential for a secret
 	LabelCredentialsType = "jenkins.io/credentials-type"
 
@@ -287,12 +311,25 @@ const (
 	// AnnotationGitReportRunningStages used to annotate what stages were last reported to git as running
 	AnnotationGitReportRunningStages = "jenkins.io/git-report-running-stages"
 
+	// AnnotationTriggerPaths the comma separated glob patterns of the files of a SourceRepository whose changes trigger
+	// its pipelines
+	AnnotationTriggerPaths = "jenkins.io/trigger-paths"
+
 	// AnnotationIsDefaultStorageClass used to indicate a storageclass is default
 	AnnotationIsDefaultStorageClass = "storageclass.kubernetes.io/is-default-class"
 
 	// AnnotationReleaseName is the name of the annotation that stores the release name in the preview environment
 	AnnotationReleaseName = "jenkins.io/chart-release"
 
+	// AnnotationPreviewTTL the duration a preview environment is kept after it was last deployed
+	AnnotationPreviewTTL = "jenkins.io/preview-ttl"
+
+	// AnnotationPreviewLastDeployed the RFC 3339 time a preview environment was last deployed
+	AnnotationPreviewLastDeployed = "jenkins.io/preview-last-deployed"
+
+	// AnnotationRestartedAt the pod template annotation used to trigger a rolling restart of a deployment
+	AnnotationRestartedAt = "kubectl.kubernetes.io/restartedAt"
+
 	// SecretDataUsername the username in a Secret/Credentials
 	SecretDataUsername = "username"
 
@@ -336,6 +373,8 @@ var (
 	AddonCharts = map[string]string{
 		"ambassador":                    ChartAmbassador,
 		"anchore":                       ChartAnchore,
+		DefaultCosignReleaseName:        ChartCosignPolicyController,
+		DefaultCrossplaneReleaseName:    ChartCrossplane,
 		DefaultFlaggerReleaseName:       ChartFlagger,
 		"gitea":                         ChartGitea,
 		"istio":                         ChartIstio,
@@ -349,6 +388,7 @@ var (
 		DefaultSsoDexReleaseName:        ChartSsoDex,
 		DefaultSsoOperatorReleaseName:   ChartSsoOperator,
 		DefaultVaultOperatorReleaseName: ChartVaultOperator,
+		DefaultVeleroReleaseName:        ChartVelero,
 	}
 
 	AddonServices = map[string]string{
@@ -0,0 +1,84 @@
+package kube
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx-api/pkg/client/clientset/versioned/scheme"
+	"github.com/jenkins-x/jx/v2/pkg/kube/crds"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// DesiredCRDs returns the CRDs which RegisterAllCRDs registers for the current binary without modifying the cluster
+func DesiredCRDs() ([]v1beta1.CustomResourceDefinition, error) {
+	desired, err := crds.Desired()
+	if err != nil {
+		return nil, errors.Wrap(err, "rendering the CRDs")
+	}
+	answer := []v1beta1.CustomResourceDefinition{}
+	for _, crd := range desired {
+		answer = append(answer, *crd)
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Name < answer[j].Name
+	})
+	return answer, nil
+}
+
+// CRDServedVersions returns the versions served by the CRD
+func CRDServedVersions(crd *v1beta1.CustomResourceDefinition) []string {
+	return crds.ServedVersions(crd)
+}
+
+// CheckCRDUpgrade returns the problems which would make upgrading the existing CRD to the desired CRD unsafe
+func CheckCRDUpgrade(existing *v1beta1.CustomResourceDefinition, desired *v1beta1.CustomResourceDefinition) []string {
+	problems := []string{}
+	if existing.Spec.Scope != desired.Spec.Scope {
+		problems = append(problems, fmt.Sprintf("CRD %s changes scope from %s to %s", desired.Name, existing.Spec.Scope, desired.Spec.Scope))
+	}
+	if existing.Spec.Names.Kind != desired.Spec.Names.Kind {
+		problems = append(problems, fmt.Sprintf("CRD %s changes kind from %s to %s", desired.Name, existing.Spec.Names.Kind, desired.Spec.Names.Kind))
+	}
+	served := CRDServedVersions(desired)
+	for _, v := range existing.Status.StoredVersions {
+		if util.StringArrayIndex(served, v) < 0 {
+			problems = append(problems, fmt.Sprintf("CRD %s has resources stored as version %s which is not served by the new CRD versions %s",
+				desired.Name, v, strings.Join(served, ", ")))
+		}
+	}
+	return problems
+}
+
+// DecodeCustomResource checks the custom resource can be decoded into the type known by the current binary. Fields
+// which the binary does not know about, and which would be dropped when it updates the resource, are returned as
+// warnings. An error is returned if the resource cannot be decoded at all
+func DecodeCustomResource(u *unstructured.Unstructured) ([]string, error) {
+	gvk := u.GroupVersionKind()
+	obj, err := scheme.Scheme.New(gvk)
+	if err != nil {
+		return nil, errors.Wrapf(err, "kind %s is not known by this binary", gvk.String())
+	}
+	data, err := u.MarshalJSON()
+	if err != nil {
+		return nil, errors.Wrapf(err, "marshalling %s %s", gvk.Kind, u.GetName())
+	}
+	warnings := []string{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	strictErr := decoder.Decode(obj)
+	if strictErr != nil {
+		obj, _ = scheme.Scheme.New(gvk)
+		err = json.Unmarshal(data, obj)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding %s %s/%s", gvk.Kind, u.GetNamespace(), u.GetName())
+		}
+		warnings = append(warnings, fmt.Sprintf("%s %s/%s: %s", gvk.Kind, u.GetNamespace(), u.GetName(), strictErr.Error()))
+	}
+	return warnings, nil
+}
@@ -0,0 +1,104 @@
+// +build unit
+
+package kube_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDesiredCRDs(t *testing.T) {
+	t.Parallel()
+
+	crds, err := kube.DesiredCRDs()
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, crd := range crds {
+		names = append(names, crd.Name)
+	}
+	assert.Contains(t, names, "environments.jenkins.io")
+	assert.Contains(t, names, "pipelineactivities.jenkins.io")
+	assert.True(t, sort.StringsAreSorted(names), "CRDs should be sorted by name")
+}
+
+func TestCheckCRDUpgrade(t *testing.T) {
+	t.Parallel()
+
+	desired := &v1beta1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "environments.jenkins.io",
+		},
+		Spec: v1beta1.CustomResourceDefinitionSpec{
+			Group:   "jenkins.io",
+			Version: "v1",
+			Scope:   v1beta1.NamespaceScoped,
+			Names: v1beta1.CustomResourceDefinitionNames{
+				Kind: "Environment",
+			},
+		},
+	}
+	assert.Equal(t, []string{"v1"}, kube.CRDServedVersions(desired))
+
+	existing := desired.DeepCopy()
+	existing.Status.StoredVersions = []string{"v1"}
+	assert.Empty(t, kube.CheckCRDUpgrade(existing, desired))
+
+	existing.Spec.Version = ""
+	existing.Spec.Versions = []v1beta1.CustomResourceDefinitionVersion{
+		{Name: "v1alpha1", Served: true},
+		{Name: "v1", Served: true, Storage: true},
+	}
+	existing.Status.StoredVersions = []string{"v1alpha1", "v1"}
+	existing.Spec.Scope = v1beta1.ClusterScoped
+	problems := kube.CheckCRDUpgrade(existing, desired)
+	assert.Len(t, problems, 2)
+	assert.Contains(t, problems[0], "changes scope")
+	assert.Contains(t, problems[1], "version v1alpha1")
+}
+
+func TestDecodeCustomResource(t *testing.T) {
+	t.Parallel()
+
+	env := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "jenkins.io/v1",
+			"kind":       "Environment",
+			"metadata": map[string]interface{}{
+				"name":      "staging",
+				"namespace": "jx",
+			},
+			"spec": map[string]interface{}{
+				"namespace": "jx-staging",
+				"order":     int64(100),
+			},
+		},
+	}
+	warnings, err := kube.DecodeCustomResource(env)
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	unknownField := env.DeepCopy()
+	require.NoError(t, unstructured.SetNestedField(unknownField.Object, "bar", "spec", "foo"))
+	warnings, err = kube.DecodeCustomResource(unknownField)
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "foo")
+
+	wrongType := env.DeepCopy()
+	require.NoError(t, unstructured.SetNestedField(wrongType.Object, "first", "spec", "order"))
+	_, err = kube.DecodeCustomResource(wrongType)
+	assert.Error(t, err)
+
+	unknownKind := env.DeepCopy()
+	unknownKind.SetKind("Spaceship")
+	_, err = kube.DecodeCustomResource(unknownKind)
+	assert.Error(t, err)
+}
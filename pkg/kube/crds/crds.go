@@ -0,0 +1,168 @@
+package crds
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	jenkinsio "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CategoryJenkinsX the category of all the Jenkins X custom resources so that they can be listed with
+// kubectl get jenkins-x
+const CategoryJenkinsX = "jenkins-x"
+
+// Definition the definition of a Jenkins X custom resource from which its CRD is generated
+type Definition struct {
+	Group   string
+	Version string
+	// Package the Go package of the API types the schema is generated from
+	Package string
+	Names   v1beta1.CustomResourceDefinitionNames
+	Columns []v1beta1.CustomResourceColumnDefinition
+	// Schema the schema of custom resources which are not part of the API types
+	Schema *v1beta1.JSONSchemaProps
+}
+
+// Name returns the name of the CRD
+func (d *Definition) Name() string {
+	return d.Names.Plural + "." + d.Group
+}
+
+// CRD generates the CRD of the definition with a structural schema
+func (d *Definition) CRD() (*v1beta1.CustomResourceDefinition, error) {
+	schema := d.Schema
+	if schema == nil {
+		var err error
+		schema, err = GenerateSchema(d.Package, d.Group, d.Version, d.Names.Kind)
+		if err != nil {
+			return nil, err
+		}
+	}
+	schema = schema.DeepCopy()
+	MakeStructural(schema)
+	err := ValidateStructural(schema)
+	if err != nil {
+		return nil, errors.Wrapf(err, "the schema of %s is not structural", d.Name())
+	}
+
+	names := *d.Names.DeepCopy()
+	if util.StringArrayIndex(names.Categories, CategoryJenkinsX) < 0 {
+		names.Categories = append(names.Categories, CategoryJenkinsX)
+	}
+	return &v1beta1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: d.Name(),
+		},
+		Spec: v1beta1.CustomResourceDefinitionSpec{
+			Group:                    d.Group,
+			Version:                  d.Version,
+			Scope:                    v1beta1.NamespaceScoped,
+			Names:                    names,
+			AdditionalPrinterColumns: d.Columns,
+			Validation: &v1beta1.CustomResourceValidation{
+				OpenAPIV3Schema: schema,
+			},
+		},
+	}, nil
+}
+
+// Register ensures that the CRD of the definition is registered and up to date
+func Register(apiClient apiextensionsclientset.Interface, d *Definition) error {
+	crd, err := d.CRD()
+	if err != nil {
+		return err
+	}
+	return register(apiClient, crd)
+}
+
+// RegisterAll ensures that all the Jenkins X CRDs are registered and up to date
+func RegisterAll(apiClient apiextensionsclientset.Interface) error {
+	for _, d := range All() {
+		err := Register(apiClient, d)
+		if err != nil {
+			return errors.Wrapf(err, "failed to register the %s CRD", d.Names.Kind)
+		}
+	}
+	return nil
+}
+
+// RegisterPipeline ensures that the CRDs used by the pipelines are registered and up to date
+func RegisterPipeline(apiClient apiextensionsclientset.Interface) error {
+	for _, d := range Pipeline() {
+		err := Register(apiClient, d)
+		if err != nil {
+			return errors.Wrapf(err, "failed to register the %s CRD", d.Names.Kind)
+		}
+	}
+	return nil
+}
+
+// Desired returns the CRDs which RegisterAll registers without modifying the cluster
+func Desired() ([]*v1beta1.CustomResourceDefinition, error) {
+	answer := []*v1beta1.CustomResourceDefinition{}
+	for _, d := range All() {
+		crd, err := d.CRD()
+		if err != nil {
+			return nil, err
+		}
+		answer = append(answer, crd)
+	}
+	return answer, nil
+}
+
+// UpToDate returns true if the existing CRD has the names, printed columns and schema of the desired CRD
+func UpToDate(existing *v1beta1.CustomResourceDefinition, desired *v1beta1.CustomResourceDefinition) bool {
+	if existing.Spec.Scope != desired.Spec.Scope || !reflect.DeepEqual(existing.Spec.Names, desired.Spec.Names) {
+		return false
+	}
+	// the API server adds an Age column when none is specified
+	if len(desired.Spec.AdditionalPrinterColumns) > 0 &&
+		!equality.Semantic.DeepEqual(existing.Spec.AdditionalPrinterColumns, desired.Spec.AdditionalPrinterColumns) {
+		return false
+	}
+	return equality.Semantic.DeepEqual(existing.Spec.Validation, desired.Spec.Validation)
+}
+
+func register(apiClient apiextensionsclientset.Interface, crd *v1beta1.CustomResourceDefinition) error {
+	crdResources := apiClient.ApiextensionsV1beta1().CustomResourceDefinitions()
+
+	f := func() error {
+		old, err := crdResources.Get(crd.Name, metav1.GetOptions{})
+		if err == nil {
+			if !UpToDate(old, crd) {
+				old.Spec = crd.Spec
+				_, err = crdResources.Update(old)
+				if err != nil {
+					log.Logger().Infof("Error doing update to %s %v\n%v", old.Name, err, old.Spec)
+				}
+				return err
+			}
+			return nil
+		}
+
+		_, err = crdResources.Create(crd)
+		if err != nil {
+			log.Logger().Infof("Error creating %s: %v", crd.Name, err)
+		}
+		return err
+	}
+
+	exponentialBackOff := backoff.NewExponentialBackOff()
+	timeout := 60 * time.Second
+	exponentialBackOff.MaxElapsedTime = timeout
+	exponentialBackOff.Reset()
+	return backoff.Retry(f, exponentialBackOff)
+}
+
+// IsJenkinsX returns true if the CRD is in the jenkins.io group
+func IsJenkinsX(crd *v1beta1.CustomResourceDefinition) bool {
+	return crd.Spec.Group == jenkinsio.GroupName
+}
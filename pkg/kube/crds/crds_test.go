@@ -0,0 +1,156 @@
+// +build unit
+
+package crds_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/kube/crds"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDesiredCRDsHaveStructuralSchemas(t *testing.T) {
+	t.Parallel()
+
+	desired, err := crds.Desired()
+	require.NoError(t, err)
+	require.Len(t, desired, len(crds.All()))
+	for _, crd := range desired {
+		require.NotNil(t, crd.Spec.Validation, "CRD %s has no schema", crd.Name)
+		assert.NoError(t, crds.ValidateStructural(crd.Spec.Validation.OpenAPIV3Schema), "CRD %s", crd.Name)
+		assert.Contains(t, crd.Spec.Names.Categories, crds.CategoryJenkinsX, "CRD %s", crd.Name)
+	}
+
+	crd, err := crds.CloudDatabase.CRD()
+	require.NoError(t, err)
+	assert.NoError(t, crds.ValidateStructural(crd.Spec.Validation.OpenAPIV3Schema))
+}
+
+func TestRegisterUpdatesOutOfDateCRDs(t *testing.T) {
+	t.Parallel()
+
+	old := &v1beta1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "environments.jenkins.io"},
+		Spec: v1beta1.CustomResourceDefinitionSpec{
+			Group:   "jenkins.io",
+			Version: "v1",
+			Scope:   v1beta1.NamespaceScoped,
+			Names: v1beta1.CustomResourceDefinitionNames{
+				Kind:     "Environment",
+				ListKind: "EnvironmentList",
+				Plural:   "environments",
+				Singular: "environment",
+			},
+		},
+	}
+	apiClient := fake.NewSimpleClientset(old)
+
+	err := crds.Register(apiClient, crds.Environment)
+	require.NoError(t, err)
+
+	crd, err := apiClient.ApiextensionsV1beta1().CustomResourceDefinitions().Get("environments.jenkins.io", metav1.GetOptions{})
+	require.NoError(t, err)
+	desired, err := crds.Environment.CRD()
+	require.NoError(t, err)
+	assert.True(t, crds.UpToDate(crd, desired), "the CRD should have been updated")
+	assert.Len(t, crd.Spec.AdditionalPrinterColumns, 6)
+
+	apiClient.ClearActions()
+	err = crds.Register(apiClient, crds.Environment)
+	require.NoError(t, err)
+	for _, action := range apiClient.Actions() {
+		assert.Equal(t, "get", action.GetVerb(), "an up to date CRD should not be updated")
+	}
+}
+
+func TestValidateCustomResource(t *testing.T) {
+	t.Parallel()
+
+	crd, err := crds.Environment.CRD()
+	require.NoError(t, err)
+
+	valid := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "jenkins.io/v1",
+		"kind":       "Environment",
+		"metadata":   map[string]interface{}{"name": "staging", "namespace": "jx"},
+		"spec": map[string]interface{}{
+			"label":     "Staging",
+			"namespace": "jx-staging",
+			"order":     int64(100),
+			"source":    map[string]interface{}{"url": "https://github.com/cheese/environment-staging.git"},
+		},
+	}}
+	problems, err := crds.ValidateCustomResource(crd, valid)
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+
+	invalid := valid.DeepCopy()
+	invalid.Object["spec"] = map[string]interface{}{
+		"label":  int64(3),
+		"order":  "first",
+		"source": "https://github.com/cheese/environment-staging.git",
+	}
+	problems, err = crds.ValidateCustomResource(crd, invalid)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"Environment jx/staging: .spec.label must be a string",
+		"Environment jx/staging: .spec.order must be an integer",
+		"Environment jx/staging: .spec.source must be an object",
+	}, problems)
+}
+
+func TestGetStatuses(t *testing.T) {
+	t.Parallel()
+
+	environments, err := crds.Environment.CRD()
+	require.NoError(t, err)
+	environments.Status.Conditions = []v1beta1.CustomResourceDefinitionCondition{
+		{Type: v1beta1.Established, Status: v1beta1.ConditionTrue},
+	}
+	releases, err := crds.Release.CRD()
+	require.NoError(t, err)
+	releases.Spec.AdditionalPrinterColumns = nil
+	other := &v1beta1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "certificates.cert-manager.io"},
+		Spec: v1beta1.CustomResourceDefinitionSpec{
+			Group:   "cert-manager.io",
+			Version: "v1alpha2",
+			Names:   v1beta1.CustomResourceDefinitionNames{Kind: "Certificate", Plural: "certificates"},
+		},
+	}
+	apiClient := fake.NewSimpleClientset(environments, releases, other)
+
+	statuses, err := crds.GetStatuses(apiClient, false)
+	require.NoError(t, err)
+	byName := map[string]crds.Status{}
+	for _, s := range statuses {
+		byName[s.Name] = s
+	}
+	assert.Len(t, statuses, len(crds.All())+1)
+	assert.Equal(t, crds.Status{
+		Name:        "environments.jenkins.io",
+		Kind:        "Environment",
+		Versions:    []string{"v1"},
+		JenkinsX:    true,
+		Installed:   true,
+		Established: true,
+		Structural:  true,
+		UpToDate:    true,
+	}, byName["environments.jenkins.io"])
+	assert.False(t, byName["releases.jenkins.io"].UpToDate, "the columns of the releases CRD are out of date")
+	assert.True(t, byName["certificates.cert-manager.io"].Installed)
+	assert.False(t, byName["certificates.cert-manager.io"].JenkinsX)
+	assert.False(t, byName["pipelineactivities.jenkins.io"].Installed)
+
+	statuses, err = crds.GetStatuses(apiClient, true)
+	require.NoError(t, err)
+	assert.Len(t, statuses, len(crds.All()))
+	for _, s := range statuses {
+		assert.True(t, s.JenkinsX, "CRD %s is not a Jenkins X CRD", s.Name)
+	}
+}
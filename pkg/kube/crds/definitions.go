@@ -0,0 +1,325 @@
+package crds
+
+import (
+	jenkinsio "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+)
+
+var (
+	// Environment the definition of the Environment CRD
+	Environment = jenkinsX(v1beta1.CustomResourceDefinitionNames{
+		Kind:       "Environment",
+		ListKind:   "EnvironmentList",
+		Plural:     "environments",
+		Singular:   "environment",
+		ShortNames: []string{"env"},
+	},
+		column("Namespace", "string", "The namespace used for the environment", ".spec.namespace"),
+		column("Kind", "string", "The kind of environment", ".spec.kind"),
+		column("Promotion", "string", "The strategy used for promoting to this environment", ".spec.promotionStrategy"),
+		column("Order", "integer", "The order in which environments are automatically promoted", ".spec.order"),
+		column("Git URL", "string", "The Git repository URL for the source of the environment configuration", ".spec.source.url"),
+		column("Git Branch", "string", "The git branch for the source of the environment configuration", ".spec.source.ref"),
+	)
+
+	// EnvironmentRoleBinding the definition of the EnvironmentRoleBinding CRD
+	EnvironmentRoleBinding = jenkinsX(v1beta1.CustomResourceDefinitionNames{
+		Kind:       "EnvironmentRoleBinding",
+		ListKind:   "EnvironmentRoleBindingList",
+		Plural:     "environmentrolebindings",
+		Singular:   "environmentrolebinding",
+		ShortNames: []string{"envrolebindings", "envrolebinding", "envrb", "erb"},
+		Categories: []string{"all"},
+	})
+
+	// GitService the definition of the GitService CRD
+	GitService = jenkinsX(v1beta1.CustomResourceDefinitionNames{
+		Kind:       "GitService",
+		ListKind:   "GitServiceList",
+		Plural:     "gitservices",
+		Singular:   "gitservice",
+		ShortNames: []string{"gits", "gs"},
+		Categories: []string{"all"},
+	},
+		column("Git URL", "string", "The URL of the Git repository", ".spec.url"),
+		column("Kind", "string", "The kind of the Git provider", ".spec.gitKind"),
+	)
+
+	// PipelineActivity the definition of the PipelineActivity CRD
+	PipelineActivity = jenkinsX(v1beta1.CustomResourceDefinitionNames{
+		Kind:       "PipelineActivity",
+		ListKind:   "PipelineActivityList",
+		Plural:     "pipelineactivities",
+		Singular:   "pipelineactivity",
+		ShortNames: []string{"activity", "act", "pa"},
+		Categories: []string{"all"},
+	},
+		column("Git URL", "string", "The URL of the Git repository", ".spec.gitUrl"),
+		column("Status", "string", "The status of the pipeline", ".spec.status"),
+	)
+
+	// PipelineStructure the definition of the PipelineStructure CRD
+	PipelineStructure = jenkinsX(v1beta1.CustomResourceDefinitionNames{
+		Kind:       "PipelineStructure",
+		ListKind:   "PipelineStructureList",
+		Plural:     "pipelinestructures",
+		Singular:   "pipelinestructure",
+		ShortNames: []string{"structure", "ps"},
+		Categories: []string{"all"},
+	})
+
+	// Fact the definition of the Fact CRD
+	Fact = jenkinsX(v1beta1.CustomResourceDefinitionNames{
+		Kind:       "Fact",
+		ListKind:   "FactList",
+		Plural:     "facts",
+		Singular:   "fact",
+		ShortNames: []string{"fact"},
+		Categories: []string{"all"},
+	},
+		column("Name", "string", "The name of the fact", ".spec.name"),
+		column("Type", "string", "The type of the fact", ".spec.factType"),
+	)
+
+	// Extension the definition of the Extension CRD
+	Extension = jenkinsX(v1beta1.CustomResourceDefinitionNames{
+		Kind:       "Extension",
+		ListKind:   "ExtensionList",
+		Plural:     "extensions",
+		Singular:   "extensions",
+		ShortNames: []string{"extension", "ext"},
+		Categories: []string{"all"},
+	},
+		column("Name", "string", "The name of the extension", ".spec.name"),
+		column("Description", "string", "A description of the extension", ".spec.description"),
+	)
+
+	// BuildPack the definition of the BuildPack CRD
+	BuildPack = jenkinsX(v1beta1.CustomResourceDefinitionNames{
+		Kind:       "BuildPack",
+		ListKind:   "BuildPackList",
+		Plural:     "buildpacks",
+		Singular:   "buildpack",
+		ShortNames: []string{"bp"},
+		Categories: []string{"all"},
+	},
+		column("LABEL", "string", "The label of the BuildPack", ".spec.Label"),
+		column("GIT URL", "string", "The Git URL of the BuildPack", ".spec.gitUrl"),
+		column("Git Ref", "string", "The Git REf of the BuildPack", ".spec.gitRef"),
+	)
+
+	// App the definition of the App CRD
+	App = jenkinsX(v1beta1.CustomResourceDefinitionNames{
+		Kind:       "App",
+		ListKind:   "AppList",
+		Plural:     "apps",
+		Singular:   "app",
+		ShortNames: []string{"app"},
+		Categories: []string{"all"},
+	})
+
+	// Scheduler the definition of the Scheduler CRD
+	Scheduler = jenkinsX(v1beta1.CustomResourceDefinitionNames{
+		Kind:       "Scheduler",
+		ListKind:   "SchedulerList",
+		Plural:     "schedulers",
+		Singular:   "scheduler",
+		ShortNames: []string{"scheduler"},
+		Categories: []string{"all"},
+	})
+
+	// SourceRepositoryGroup the definition of the SourceRepositoryGroup CRD
+	SourceRepositoryGroup = jenkinsX(v1beta1.CustomResourceDefinitionNames{
+		Kind:       "SourceRepositoryGroup",
+		ListKind:   "SourceRepositoryGroupList",
+		Plural:     "sourcerepositorygroups",
+		Singular:   "sourcerepositorygroup",
+		ShortNames: []string{"srg"},
+		Categories: []string{"all"},
+	},
+		column("Scheduler", "string", "The pipeline scheduler used by the source repository group", ".spec.scheduler.name"),
+	)
+
+	// SourceRepository the definition of the SourceRepository CRD
+	SourceRepository = jenkinsX(v1beta1.CustomResourceDefinitionNames{
+		Kind:       "SourceRepository",
+		ListKind:   "SourceRepositoryList",
+		Plural:     "sourcerepositories",
+		Singular:   "sourcerepository",
+		ShortNames: []string{"sourcerepo", "srcrepo", "sr"},
+		Categories: []string{"all"},
+	},
+		column("URL", "string", "The URL of the git repository", ".spec.url"),
+		column("Description", "string", "A description of the source code repository - non-functional user-data", ".spec.description"),
+	)
+
+	// Plugin the definition of the Plugin CRD
+	Plugin = jenkinsX(v1beta1.CustomResourceDefinitionNames{
+		Kind:       "Plugin",
+		ListKind:   "PluginList",
+		Plural:     "plugins",
+		Singular:   "plugin",
+		Categories: []string{"all"},
+	},
+		column("Name", "string", "The name of the plugin", ".spec.name"),
+		column("Description", "string", "A description of the plugin", ".spec.description"),
+	)
+
+	// CommitStatus the definition of the CommitStatus CRD
+	CommitStatus = jenkinsX(v1beta1.CustomResourceDefinitionNames{
+		Kind:       "CommitStatus",
+		ListKind:   "CommitStatusList",
+		Plural:     "commitstatuses",
+		Singular:   "commitstatus",
+		ShortNames: []string{"commitstatus"},
+		Categories: []string{"all"},
+	})
+
+	// Release the definition of the Release CRD
+	Release = jenkinsX(v1beta1.CustomResourceDefinitionNames{
+		Kind:       "Release",
+		ListKind:   "ReleaseList",
+		Plural:     "releases",
+		Singular:   "release",
+		ShortNames: []string{"rel"},
+		Categories: []string{"all"},
+	},
+		column("Name", "string", "The name of the Release", ".spec.name"),
+		column("Version", "string", "The version number of the Release", ".spec.version"),
+		column("Git URL", "string", "The URL of the Git repository", ".spec.gitHttpUrl"),
+	)
+
+	// User the definition of the User CRD
+	User = jenkinsX(v1beta1.CustomResourceDefinitionNames{
+		Kind:       "User",
+		ListKind:   "UserList",
+		Plural:     "users",
+		Singular:   "user",
+		ShortNames: []string{"usr"},
+		Categories: []string{"all"},
+	},
+		column("Name", "string", "The name of the user", ".spec.name"),
+		column("Email", "string", "The email address of the user", ".spec.email"),
+	)
+
+	// Team the definition of the Team CRD
+	Team = jenkinsX(v1beta1.CustomResourceDefinitionNames{
+		Kind:       "Team",
+		ListKind:   "TeamList",
+		Plural:     "teams",
+		Singular:   "team",
+		ShortNames: []string{"tm"},
+		Categories: []string{"all"},
+	},
+		column("Kind", "string", "The kind of Team", ".spec.kind"),
+		column("Status", "string", "The provision status of the Team", ".status.provisionStatus"),
+	)
+
+	// Workflow the definition of the Workflow CRD
+	Workflow = jenkinsX(v1beta1.CustomResourceDefinitionNames{
+		Kind:       "Workflow",
+		ListKind:   "WorkflowList",
+		Plural:     "workflows",
+		Singular:   "workflow",
+		ShortNames: []string{"flow"},
+		Categories: []string{"all"},
+	})
+
+	// CloudDatabase the definition of the CRD of the managed cloud databases of the environments. The CloudDatabase
+	// resources are not part of the jenkins.io API types so the spec and status keep any fields
+	CloudDatabase = &Definition{
+		Group:   jenkinsio.GroupName,
+		Version: jenkinsio.Version,
+		Names: v1beta1.CustomResourceDefinitionNames{
+			Kind:       "CloudDatabase",
+			ListKind:   "CloudDatabaseList",
+			Plural:     "clouddatabases",
+			Singular:   "clouddatabase",
+			ShortNames: []string{"clouddb"},
+			Categories: []string{"all"},
+		},
+		Columns: []v1beta1.CustomResourceColumnDefinition{
+			column("Engine", "string", "The engine of the database", ".spec.engine"),
+			column("Environment", "string", "The environment whose namespace has the credentials of the database", ".spec.environment"),
+			column("Secret", "string", "The Secret of the credentials of the database", ".spec.secret"),
+			column("Status", "string", "The provisioning status of the database", ".status.phase"),
+		},
+		Schema: &v1beta1.JSONSchemaProps{
+			Type: "object",
+			Properties: map[string]v1beta1.JSONSchemaProps{
+				"apiVersion": {Type: "string"},
+				"kind":       {Type: "string"},
+				"metadata":   {Type: "object"},
+				"spec":       {Type: "object", XPreserveUnknownFields: boolPtr(true)},
+				"status":     {Type: "object", XPreserveUnknownFields: boolPtr(true)},
+			},
+		},
+	}
+)
+
+// All returns the definitions of the CRDs registered when installing or upgrading Jenkins X
+func All() []*Definition {
+	return append([]*Definition{
+		CommitStatus,
+		Extension,
+		App,
+		EnvironmentRoleBinding,
+		GitService,
+		Fact,
+		Team,
+		User,
+		Workflow,
+	}, Pipeline()...)
+}
+
+// Pipeline returns the definitions of the CRDs used by the pipelines
+func Pipeline() []*Definition {
+	return []*Definition{
+		BuildPack,
+		Environment,
+		Release,
+		PipelineActivity,
+		PipelineStructure,
+		Plugin,
+		SourceRepository,
+		Scheduler,
+		SourceRepositoryGroup,
+	}
+}
+
+// Find returns the definition of the CRD with the given name or nil if it is not a Jenkins X CRD
+func Find(name string) *Definition {
+	for _, d := range append(All(), CloudDatabase) {
+		if d.Name() == name {
+			return d
+		}
+	}
+	return nil
+}
+
+// jenkinsX returns the definition of a CRD whose schema is generated from the jenkins.io API types
+func jenkinsX(names v1beta1.CustomResourceDefinitionNames, columns ...v1beta1.CustomResourceColumnDefinition) *Definition {
+	if columns == nil {
+		columns = []v1beta1.CustomResourceColumnDefinition{}
+	}
+	return &Definition{
+		Group:   jenkinsio.GroupName,
+		Version: jenkinsio.Version,
+		Package: jenkinsio.Package,
+		Names:   names,
+		Columns: columns,
+	}
+}
+
+func column(name string, columnType string, description string, jsonPath string) v1beta1.CustomResourceColumnDefinition {
+	return v1beta1.CustomResourceColumnDefinition{
+		Name:        name,
+		Type:        columnType,
+		Description: description,
+		JSONPath:    jsonPath,
+	}
+}
+
+func boolPtr(value bool) *bool {
+	return &value
+}
@@ -0,0 +1,184 @@
+package crds
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/go-openapi/jsonreference"
+	"github.com/go-openapi/spec"
+	openapi "github.com/jenkins-x/jx-api/pkg/client/openapi/all"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/pkg/errors"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/kube-openapi/pkg/common"
+)
+
+var (
+	openAPIDefinitions     map[string]common.OpenAPIDefinition
+	openAPIDefinitionsOnce sync.Once
+)
+
+// GenerateSchema generates the OpenAPI v3 schema of a kind from the OpenAPI definitions of the API types
+func GenerateSchema(pkg string, group string, version string, kind string) (*v1beta1.JSONSchemaProps, error) {
+	//"github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1.PipelineActivity":
+	schemaPath := fmt.Sprintf("%s/%s/%s.%s", pkg, group, version, kind)
+	def := getOpenAPIDefinition(schemaPath)
+	if def == nil {
+		return nil, fmt.Errorf("no OpenAPI definition found for %s", schemaPath)
+	}
+	// resolve references
+	schema, err := FixSchema(def.Schema, refCallBack)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error generating OpenAPI Schema for %s", schemaPath)
+	}
+	// Unfortunately the schema is generated into one type, and the validation takes another type.
+	// However both define the OpenAPI v3 data structures and are compatible, so we convert via JSON
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, errors.Wrapf(err, "marshalling the OpenAPI Schema for %s", schemaPath)
+	}
+	answer := &v1beta1.JSONSchemaProps{}
+	err = json.Unmarshal(data, answer)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshalling the OpenAPI Schema for %s", schemaPath)
+	}
+	return answer, nil
+}
+
+// FixSchema walks the schema and automatically fixes it up to be better supported by Kubernetes.
+// Current automatic fixes are:
+// * resolving $ref
+// * remove unresolved $ref
+// * clear additionalProperties (this is unsupported in older kubernetes, when we drop them,
+// we can investigate adding support, for now use patternProperties)
+//
+// as these are all unsupported
+func FixSchema(schema spec.Schema, ref common.ReferenceCallback) (spec.Schema, error) {
+	// the maps and items are copied as the definitions are shared
+	if schema.Type.Contains("object") {
+		properties := make(map[string]spec.Schema, len(schema.Properties))
+		for k, v := range schema.Properties {
+			resolved, err := FixSchema(v, ref)
+			if err != nil {
+				return schema, err
+			}
+			properties[k] = resolved
+		}
+		schema.Properties = properties
+		schema.AdditionalProperties = nil
+	} else if schema.Type.Contains("array") && schema.Items != nil {
+		items := *schema.Items
+		schema.Items = &items
+		if schema.Items.Len() == 1 {
+			resolved, err := FixSchema(*schema.Items.Schema, ref)
+			if err != nil {
+				return schema, err
+			}
+			schema.Items.Schema = &resolved
+		} else {
+			result := make([]spec.Schema, 0)
+			for _, v := range schema.Items.Schemas {
+				resolved, err := FixSchema(v, ref)
+				if err != nil {
+					return schema, err
+				}
+				result = append(result, resolved)
+			}
+			schema.Items.Schemas = result
+		}
+
+	} else if path := schema.Ref.String(); path != "" {
+		def := getOpenAPIDefinition(path)
+		if def != nil {
+			return FixSchema(def.Schema, ref)
+		}
+		// return an empty schema if we can't resolve
+		return spec.Schema{}, nil
+	}
+	return schema, nil
+}
+
+// MakeStructural changes the generated schema of a custom resource so that it is a structural schema:
+// * the metadata is only specified as an object as the API server validates it
+// * values whose type is unknown, such as unresolved references, keep any fields
+// * objects without properties, such as maps whose additionalProperties were cleared, keep any fields
+// * int-or-string values use the x-kubernetes-int-or-string extension rather than a format
+func MakeStructural(schema *v1beta1.JSONSchemaProps) {
+	makeStructural(schema)
+	if _, ok := schema.Properties["metadata"]; ok {
+		schema.Properties["metadata"] = v1beta1.JSONSchemaProps{Type: "object"}
+	}
+}
+
+func makeStructural(schema *v1beta1.JSONSchemaProps) {
+	if schema.Format == "int-or-string" {
+		schema.Type = ""
+		schema.Format = ""
+		schema.XIntOrString = true
+		return
+	}
+	if schema.Type == "" && len(schema.Properties) > 0 {
+		schema.Type = "object"
+	}
+	preserve := schema.XPreserveUnknownFields != nil && *schema.XPreserveUnknownFields
+	if (schema.Type == "" || (schema.Type == "object" && len(schema.Properties) == 0)) && !preserve {
+		schema.XPreserveUnknownFields = boolPtr(true)
+	}
+	for k, v := range schema.Properties {
+		makeStructural(&v)
+		schema.Properties[k] = v
+	}
+	if schema.Items != nil {
+		if schema.Items.Schema != nil {
+			makeStructural(schema.Items.Schema)
+		}
+		for i := range schema.Items.JSONSchemas {
+			makeStructural(&schema.Items.JSONSchemas[i])
+		}
+	}
+}
+
+// ValidateStructural returns an error if the schema is not a structural schema, which the API server requires for
+// pruning, defaulting and the printing of the schema with kubectl explain
+func ValidateStructural(schema *v1beta1.JSONSchemaProps) error {
+	s, err := NewStructural(schema)
+	if err != nil {
+		return err
+	}
+	return structuralschema.ValidateStructural(s, nil).ToAggregate()
+}
+
+// NewStructural converts the schema into the structural schema used by the API server
+func NewStructural(schema *v1beta1.JSONSchemaProps) (*structuralschema.Structural, error) {
+	internal := &apiextensions.JSONSchemaProps{}
+	err := v1beta1.Convert_v1beta1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(schema, internal, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting the schema")
+	}
+	return structuralschema.NewStructural(internal)
+}
+
+// getOpenAPIDefinition returns the OpenAPI definition of the type or nil if there is none. The definitions are
+// generated once as resolving the references of each schema looks up many of them
+func getOpenAPIDefinition(name string) *common.OpenAPIDefinition {
+	openAPIDefinitionsOnce.Do(func() {
+		openAPIDefinitions = openapi.GetOpenAPIDefinitions(refCallBack)
+	})
+	if def, ok := openAPIDefinitions[name]; ok {
+		return &def
+	}
+	return nil
+}
+
+func refCallBack(path string) spec.Ref {
+	ref, err := jsonreference.New(path)
+	if err != nil {
+		log.Logger().Warnf("Error resolving ref %s %v", path, err)
+	}
+	return spec.Ref{
+		Ref: ref,
+	}
+}
@@ -0,0 +1,103 @@
+package crds
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Status the status of a CRD in the cluster
+type Status struct {
+	Name     string
+	Kind     string
+	Versions []string
+	// JenkinsX true if the CRD is one of the Jenkins X CRDs registered by this binary
+	JenkinsX bool
+	// Installed false if a Jenkins X CRD is not registered in the cluster
+	Installed   bool
+	Established bool
+	// Structural true if the CRD has a structural schema
+	Structural bool
+	// UpToDate true if a Jenkins X CRD matches the CRD registered by this binary
+	UpToDate bool
+}
+
+// GetStatuses returns the status of the CRDs in the cluster sorted by name. If jenkinsXOnly is true only the
+// Jenkins X CRDs are returned, including those which are not registered yet
+func GetStatuses(apiClient apiextensionsclientset.Interface, jenkinsXOnly bool) ([]Status, error) {
+	list, err := apiClient.ApiextensionsV1beta1().CustomResourceDefinitions().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing the CRDs")
+	}
+	desired := map[string]*v1beta1.CustomResourceDefinition{}
+	for _, d := range All() {
+		crd, err := d.CRD()
+		if err != nil {
+			return nil, err
+		}
+		desired[crd.Name] = crd
+	}
+
+	answer := []Status{}
+	for i := range list.Items {
+		crd := &list.Items[i]
+		d := desired[crd.Name]
+		if jenkinsXOnly && d == nil && !IsJenkinsX(crd) {
+			continue
+		}
+		status := Status{
+			Name:        crd.Name,
+			Kind:        crd.Spec.Names.Kind,
+			Versions:    ServedVersions(crd),
+			JenkinsX:    d != nil,
+			Installed:   true,
+			Established: hasCondition(crd, v1beta1.Established),
+		}
+		if crd.Spec.Validation != nil && crd.Spec.Validation.OpenAPIV3Schema != nil {
+			status.Structural = ValidateStructural(crd.Spec.Validation.OpenAPIV3Schema) == nil
+		}
+		if d != nil {
+			status.UpToDate = UpToDate(crd, d)
+			delete(desired, crd.Name)
+		}
+		answer = append(answer, status)
+	}
+	for _, crd := range desired {
+		answer = append(answer, Status{
+			Name:     crd.Name,
+			Kind:     crd.Spec.Names.Kind,
+			Versions: ServedVersions(crd),
+			JenkinsX: true,
+		})
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Name < answer[j].Name
+	})
+	return answer, nil
+}
+
+// ServedVersions returns the versions served by the CRD
+func ServedVersions(crd *v1beta1.CustomResourceDefinition) []string {
+	answer := []string{}
+	for _, v := range crd.Spec.Versions {
+		if v.Served {
+			answer = append(answer, v.Name)
+		}
+	}
+	if len(crd.Spec.Versions) == 0 && crd.Spec.Version != "" {
+		answer = append(answer, crd.Spec.Version)
+	}
+	return answer
+}
+
+func hasCondition(crd *v1beta1.CustomResourceDefinition, conditionType v1beta1.CustomResourceDefinitionConditionType) bool {
+	for _, c := range crd.Status.Conditions {
+		if c.Type == conditionType {
+			return c.Status == v1beta1.ConditionTrue
+		}
+	}
+	return false
+}
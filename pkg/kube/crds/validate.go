@@ -0,0 +1,147 @@
+package crds
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ValidateCustomResource validates the custom resource against the schema of the CRD returning the problems which
+// would make the API server reject updates of the resource once the CRD is upgraded. The types, required fields and
+// enums of the schema are checked while the metadata is only validated by the API server
+func ValidateCustomResource(crd *v1beta1.CustomResourceDefinition, u *unstructured.Unstructured) ([]string, error) {
+	if crd.Spec.Validation == nil || crd.Spec.Validation.OpenAPIV3Schema == nil {
+		return nil, nil
+	}
+	s, err := NewStructural(crd.Spec.Validation.OpenAPIV3Schema)
+	if err != nil {
+		return nil, errors.Wrapf(err, "converting the schema of %s", crd.Name)
+	}
+	v := &validator{}
+	v.validate(u.Object, s, "")
+
+	name := u.GetName()
+	if u.GetNamespace() != "" {
+		name = u.GetNamespace() + "/" + name
+	}
+	problems := []string{}
+	for _, p := range v.problems {
+		problems = append(problems, fmt.Sprintf("%s %s: %s", u.GetKind(), name, p))
+	}
+	return problems, nil
+}
+
+type validator struct {
+	problems []string
+}
+
+func (v *validator) addf(path string, format string, args ...interface{}) {
+	if path == "" {
+		path = "."
+	}
+	v.problems = append(v.problems, path+" "+fmt.Sprintf(format, args...))
+}
+
+func (v *validator) validate(value interface{}, s *structuralschema.Structural, path string) {
+	if value == nil {
+		if !s.Nullable {
+			v.addf(path, "must not be null")
+		}
+		return
+	}
+	if s.XIntOrString {
+		if _, ok := value.(string); !ok && !isInteger(value) {
+			v.addf(path, "must be an integer or a string")
+		}
+		return
+	}
+	switch s.Type {
+	case "object":
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			v.addf(path, "must be an object")
+			return
+		}
+		v.validateObject(m, s, path)
+	case "array":
+		a, ok := value.([]interface{})
+		if !ok {
+			v.addf(path, "must be an array")
+			return
+		}
+		if s.Items != nil {
+			for i, item := range a {
+				v.validate(item, s.Items, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			v.addf(path, "must be a string")
+		}
+	case "integer":
+		if !isInteger(value) {
+			v.addf(path, "must be an integer")
+		}
+	case "number":
+		if !isInteger(value) {
+			if _, ok := value.(float64); !ok {
+				v.addf(path, "must be a number")
+			}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			v.addf(path, "must be a boolean")
+		}
+	}
+	if s.ValueValidation != nil && len(s.ValueValidation.Enum) > 0 {
+		found := false
+		for _, e := range s.ValueValidation.Enum {
+			if reflect.DeepEqual(e.Object, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.addf(path, "has the value %v which is not one of the allowed values", value)
+		}
+	}
+}
+
+func (v *validator) validateObject(m map[string]interface{}, s *structuralschema.Structural, path string) {
+	if s.ValueValidation != nil {
+		for _, required := range s.ValueValidation.Required {
+			if _, ok := m[required]; !ok {
+				v.addf(path+"."+required, "is required")
+			}
+		}
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if ps, ok := s.Properties[k]; ok {
+			v.validate(m[k], &ps, path+"."+k)
+		} else if s.AdditionalProperties != nil && s.AdditionalProperties.Structural != nil {
+			v.validate(m[k], s.AdditionalProperties.Structural, path+"."+k)
+		}
+	}
+}
+
+// isInteger returns true if the value decoded from JSON is an integer
+func isInteger(value interface{}) bool {
+	switch n := value.(type) {
+	case int64, int32, int:
+		return true
+	case float64:
+		return n == math.Trunc(n)
+	}
+	return false
+}
@@ -8,13 +8,17 @@ import (
 	"time"
 
 	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/tracing"
+	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
-	tools_watch "k8s.io/client-go/tools/watch"
+	"k8s.io/client-go/tools/cache"
 )
 
 // GetDeployments get deployments in the given namespace
@@ -97,63 +101,169 @@ func WaitForAllDeploymentsToBeReady(client kubernetes.Interface, namespace strin
 
 // WaitForDeploymentToBeCreatedAndReady waits for the pods of a deployment created and to become ready
 func WaitForDeploymentToBeCreatedAndReady(client kubernetes.Interface, name, namespace string, timeoutPerDeploy time.Duration) error {
+	_, span := tracing.StartSpan(context.Background(), "wait for deployment "+name, tracing.String("k8s.namespace", namespace), tracing.String("k8s.deployment", name))
+	err := waitForDeploymentToBeCreatedAndReady(client, name, namespace, timeoutPerDeploy)
+	span.End(err)
+	return err
+}
 
-	options := metav1.ListOptions{FieldSelector: fmt.Sprintf("metadata.name=%s", name)}
+func waitForDeploymentToBeCreatedAndReady(client kubernetes.Interface, name, namespace string, timeoutPerDeploy time.Duration) error {
+	err := waitForDeployment(client, name, namespace, timeoutPerDeploy, func(d *appsv1.Deployment) bool {
+		return d.Status.ReadyReplicas > 0
+	})
+	if err == wait.ErrWaitTimeout {
+		return NotReadyError(fmt.Sprintf("deployment %s in namespace %s", name, namespace), timeoutPerDeploy, DeploymentFailureReasons(client, name, namespace))
+	}
+	return err
+}
 
-	w, err := client.AppsV1().Deployments(namespace).Watch(options)
+// WaitForDeploymentToBeReady waits for the pods of a deployment to become ready
+func WaitForDeploymentToBeReady(client kubernetes.Interface, name, namespace string, timeout time.Duration) error {
+	_, span := tracing.StartSpan(context.Background(), "wait for deployment "+name, tracing.String("k8s.namespace", namespace), tracing.String("k8s.deployment", name))
+	err := waitForDeploymentToBeReady(client, name, namespace, timeout)
+	span.End(err)
+	return err
+}
+
+func waitForDeploymentToBeReady(client kubernetes.Interface, name, namespace string, timeout time.Duration) error {
+	_, err := client.AppsV1().Deployments(namespace).Get(name, metav1.GetOptions{})
 	if err != nil {
 		return err
 	}
-	defer w.Stop()
-
-	condition := func(event watch.Event) (bool, error) {
-		running, _ := IsDeploymentRunning(client, name, namespace)
-		return running, nil
+	err = waitForDeployment(client, name, namespace, timeout, func(d *appsv1.Deployment) bool {
+		return d.Status.Replicas == d.Status.ReadyReplicas || d.Status.ReadyReplicas > 0
+	})
+	if err == wait.ErrWaitTimeout {
+		return NotReadyError(fmt.Sprintf("deployment %s in namespace %s", name, namespace), timeout, DeploymentFailureReasons(client, name, namespace))
 	}
-	ctx, _ := context.WithTimeout(context.Background(), timeoutPerDeploy)
-	_, err = tools_watch.UntilWithoutRetry(ctx, w, condition)
+	return err
+}
 
-	if err == wait.ErrWaitTimeout {
-		return fmt.Errorf("deployment %s never became ready", name)
+// waitForDeployment watches the deployment until it is ready
+func waitForDeployment(client kubernetes.Interface, name, namespace string, timeout time.Duration, ready func(d *appsv1.Deployment) bool) error {
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", name).String()
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			return client.AppsV1().Deployments(namespace).List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return client.AppsV1().Deployments(namespace).Watch(options)
+		},
 	}
-	return nil
+	return WaitUntilReady(lw, &appsv1.Deployment{}, timeout, func(obj runtime.Object) bool {
+		d, ok := obj.(*appsv1.Deployment)
+		return ok && d.Name == name && ready(d)
+	})
 }
 
-// WaitForDeploymentToBeReady waits for the pods of a deployment to become ready
-func WaitForDeploymentToBeReady(client kubernetes.Interface, name, namespace string, timeout time.Duration) error {
+// DeploymentFailureReasons returns why the pods of a deployment are not ready from the conditions of the deployment,
+// the waiting containers of its pods and the warning events of the deployment, its replica sets and pods
+func DeploymentFailureReasons(client kubernetes.Interface, name, namespace string) []string {
+	reasons := []string{}
 	d, err := client.AppsV1().Deployments(namespace).Get(name, metav1.GetOptions{})
 	if err != nil {
-		return err
+		return append(reasons, err.Error())
+	}
+	for _, condition := range d.Status.Conditions {
+		if condition.Status == v1.ConditionFalse || condition.Type == appsv1.DeploymentReplicaFailure {
+			reasons = append(reasons, fmt.Sprintf("Deployment %s: %s: %s", name, condition.Reason, condition.Message))
+		}
+	}
+	events, err := WarningEvents(client, namespace, "Deployment", name)
+	if err == nil {
+		reasons = append(reasons, events...)
 	}
-
 	selector, err := metav1.LabelSelectorAsSelector(d.Spec.Selector)
 	if err != nil {
-		return err
+		return reasons
 	}
-
-	// Skip watching if the deployment is ready
-	if d.Status.Replicas != d.Status.ReadyReplicas {
-		options := metav1.ListOptions{LabelSelector: selector.String()}
-		w, err := client.CoreV1().Pods(namespace).Watch(options)
-
-		if err != nil {
-			return err
+	options := metav1.ListOptions{LabelSelector: selector.String()}
+	replicaSets, err := client.AppsV1().ReplicaSets(namespace).List(options)
+	if err == nil {
+		names := []string{}
+		for _, rs := range replicaSets.Items {
+			if metav1.IsControlledBy(&rs, d) {
+				names = append(names, rs.Name)
+			}
 		}
-		defer w.Stop()
-
-		condition := func(event watch.Event) (bool, error) {
-			pod := event.Object.(*v1.Pod)
-			return IsPodReady(pod), nil
+		events, err = WarningEvents(client, namespace, "ReplicaSet", names...)
+		if err == nil {
+			reasons = append(reasons, events...)
+		}
+	}
+	pods, err := client.CoreV1().Pods(namespace).List(options)
+	if err == nil {
+		names := []string{}
+		for _, pod := range pods.Items {
+			names = append(names, pod.Name)
+			for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+				if waiting := status.State.Waiting; waiting != nil && waiting.Reason != "" && waiting.Reason != "ContainerCreating" && waiting.Reason != "PodInitializing" {
+					reasons = append(reasons, fmt.Sprintf("Pod %s: container %s is waiting: %s: %s", pod.Name, status.Name, waiting.Reason, waiting.Message))
+				}
+			}
+		}
+		events, err = WarningEvents(client, namespace, "Pod", names...)
+		if err == nil {
+			reasons = append(reasons, events...)
 		}
+	}
+	return reasons
+}
 
-		ctx, _ := context.WithTimeout(context.Background(), timeout)
-		_, err = tools_watch.UntilWithoutRetry(ctx, w, condition)
+// WaitForDeploymentRollout waits for the latest revision of a deployment to be fully rolled out, i.e. the controller
+// has observed the latest spec and all desired replicas are updated, ready and available
+func WaitForDeploymentRollout(client kubernetes.Interface, name, namespace string, timeout time.Duration) error {
+	_, span := tracing.StartSpan(context.Background(), "wait for rollout of deployment "+name, tracing.String("k8s.namespace", namespace), tracing.String("k8s.deployment", name))
+	err := waitForDeploymentRollout(client, name, namespace, timeout)
+	span.End(err)
+	return err
+}
 
-		if err == wait.ErrWaitTimeout {
-			return fmt.Errorf("deployment %s never became ready", name)
+func waitForDeploymentRollout(client kubernetes.Interface, name, namespace string, timeout time.Duration) error {
+	err := wait.PollImmediate(time.Second, timeout, func() (bool, error) {
+		d, err := client.AppsV1().Deployments(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
 		}
+		return IsDeploymentRolledOut(d), nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("deployment %s in namespace %s did not finish rolling out within %s", name, namespace, timeout.String())
+	}
+	return err
+}
+
+// IsDeploymentRolledOut returns true if the latest revision of the deployment is fully rolled out
+func IsDeploymentRolledOut(d *appsv1.Deployment) bool {
+	desired := int32(1)
+	if d.Spec.Replicas != nil {
+		desired = *d.Spec.Replicas
 	}
+	status := d.Status
+	return status.ObservedGeneration >= d.Generation &&
+		status.UpdatedReplicas == desired &&
+		status.ReadyReplicas == desired &&
+		status.AvailableReplicas == desired &&
+		status.Replicas == desired
+}
 
+// RestartDeployment triggers a rolling restart of the pods of the deployment in the same way as
+// 'kubectl rollout restart' so that they pick up changed secrets
+func RestartDeployment(client kubernetes.Interface, name, namespace string) error {
+	d, err := client.AppsV1().Deployments(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if d.Spec.Template.Annotations == nil {
+		d.Spec.Template.Annotations = map[string]string{}
+	}
+	d.Spec.Template.Annotations[AnnotationRestartedAt] = time.Now().Format(time.RFC3339)
+	_, err = client.AppsV1().Deployments(namespace).Update(d)
+	if err != nil {
+		return errors.Wrapf(err, "restarting deployment %s in namespace %s", name, namespace)
+	}
 	return nil
 }
 
@@ -8,6 +8,7 @@ import (
 
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -88,3 +89,126 @@ func TestWaitForDeploymentToBeReady(t *testing.T) {
 	assert.NoError(t, err, "Should not error")
 
 }
+
+func TestWaitForDeploymentRollout(t *testing.T) {
+	t.Parallel()
+
+	replicas := int32(2)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:       "jxing-nginx-ingress-controller",
+			Namespace:  "kube-system",
+			Generation: 3,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 3,
+			Replicas:           2,
+			UpdatedReplicas:    2,
+			ReadyReplicas:      2,
+			AvailableReplicas:  2,
+		},
+	}
+	assert.True(t, kube.IsDeploymentRolledOut(deployment))
+
+	client := kube_mocks.NewSimpleClientset(deployment)
+	err := kube.WaitForDeploymentRollout(client, deployment.Name, deployment.Namespace, 5*time.Second)
+	assert.NoError(t, err)
+
+	rolling := deployment.DeepCopy()
+	rolling.Name = "rolling"
+	rolling.Status.UpdatedReplicas = 1
+	assert.False(t, kube.IsDeploymentRolledOut(rolling))
+
+	stale := deployment.DeepCopy()
+	stale.Status.ObservedGeneration = 2
+	assert.False(t, kube.IsDeploymentRolledOut(stale))
+
+	client = kube_mocks.NewSimpleClientset(rolling)
+	err = kube.WaitForDeploymentRollout(client, rolling.Name, rolling.Namespace, 2*time.Second)
+	assert.Error(t, err)
+}
+
+func TestWaitForDeploymentToBeCreatedAndReady(t *testing.T) {
+	t.Parallel()
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "myapp",
+			Namespace: "jx-staging",
+		},
+	}
+	client := kube_mocks.NewSimpleClientset()
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		created, err := client.AppsV1().Deployments("jx-staging").Create(deployment)
+		require.NoError(t, err)
+		time.Sleep(100 * time.Millisecond)
+		created.Status.Replicas = 1
+		created.Status.ReadyReplicas = 1
+		_, err = client.AppsV1().Deployments("jx-staging").UpdateStatus(created)
+		require.NoError(t, err)
+	}()
+
+	err := kube.WaitForDeploymentToBeCreatedAndReady(client, "myapp", "jx-staging", 10*time.Second)
+	assert.NoError(t, err)
+}
+
+func TestWaitForDeploymentToBeReadyReportsFailureReasons(t *testing.T) {
+	t.Parallel()
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "myapp",
+			Namespace: "jx-staging",
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &meta_v1.LabelSelector{MatchLabels: map[string]string{"app": "myapp"}},
+		},
+		Status: appsv1.DeploymentStatus{
+			Replicas: 1,
+		},
+	}
+	pod := &v1.Pod{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "myapp-abc",
+			Namespace: "jx-staging",
+			Labels:    map[string]string{"app": "myapp"},
+		},
+		Status: v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{{
+				Name: "myapp",
+				State: v1.ContainerState{
+					Waiting: &v1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image myapp:0.0.1"},
+				},
+			}},
+		},
+	}
+	event := &v1.Event{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "myapp-abc.1",
+			Namespace: "jx-staging",
+		},
+		InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: "myapp-abc"},
+		Type:           v1.EventTypeWarning,
+		Reason:         "FailedScheduling",
+		Message:        "0/3 nodes are available: 3 Insufficient memory.",
+	}
+	normal := event.DeepCopy()
+	normal.Name = "myapp-abc.2"
+	normal.Type = v1.EventTypeNormal
+	normal.Reason = "Scheduled"
+
+	client := kube_mocks.NewSimpleClientset(deployment, pod, event, normal)
+	err := kube.WaitForDeploymentToBeReady(client, "myapp", "jx-staging", time.Second)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "deployment myapp in namespace jx-staging did not become ready within 1s")
+	assert.Contains(t, err.Error(), "Pod myapp-abc: container myapp is waiting: ImagePullBackOff: Back-off pulling image myapp:0.0.1")
+	assert.Contains(t, err.Error(), "Pod myapp-abc: FailedScheduling: 0/3 nodes are available: 3 Insufficient memory.")
+	assert.NotContains(t, err.Error(), "Scheduled:")
+
+	err = kube.WaitForDeploymentToBeReady(client, "missing", "jx-staging", time.Second)
+	assert.Error(t, err)
+}
@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"os"
 	"os/user"
 	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/jenkins-x/jx/v2/pkg/jenkinsfile"
 
@@ -76,7 +78,7 @@ func CreateEnvironmentSurvey(batchMode bool, authConfigSvc auth.ConfigService, d
 				Message: "Name:",
 				Help:    "The Environment name must be unique, lower case and a valid DNS name",
 			}
-			err := survey.AskOne(q, &data.Name, validator, surveyOpts)
+			err := util.AskOne(q, &data.Name, validator, surveyOpts)
 			if err != nil {
 				return nil, err
 			}
@@ -101,7 +103,7 @@ func CreateEnvironmentSurvey(batchMode bool, authConfigSvc auth.ConfigService, d
 			Default: defaultValue,
 			Help:    "The Environment label is a person friendly descriptive text like 'Staging' or 'Production'",
 		}
-		err := survey.AskOne(q, &data.Spec.Label, survey.Required, surveyOpts)
+		err := util.AskOne(q, &data.Spec.Label, survey.Required, surveyOpts)
 		if err != nil {
 			return nil, err
 		}
@@ -136,7 +138,7 @@ func CreateEnvironmentSurvey(batchMode bool, authConfigSvc auth.ConfigService, d
 				Default: defaultValue,
 				Help:    "The Kubernetes namespace name to use for this Environment",
 			}
-			err := survey.AskOne(q, &data.Spec.Namespace, ValidateName, surveyOpts)
+			err := util.AskOne(q, &data.Spec.Namespace, ValidateName, surveyOpts)
 			if err != nil {
 				return nil, err
 			}
@@ -158,7 +160,7 @@ func CreateEnvironmentSurvey(batchMode bool, authConfigSvc auth.ConfigService, d
 				Default: ic.Domain,
 				Help:    "Domain to expose ingress endpoints.  Example: jenkinsx.io, leave blank if no appplications are to be exposed via ingress rules",
 			}
-			err := survey.AskOne(q, &helmValues.ExposeController.Config.Domain, nil, surveyOpts)
+			err := util.AskOne(q, &helmValues.ExposeController.Config.Domain, nil, surveyOpts)
 			if err != nil {
 				return nil, err
 			}
@@ -190,7 +192,7 @@ func CreateEnvironmentSurvey(batchMode bool, authConfigSvc auth.ConfigService, d
 						Help:    "The Kubernetes cluster URL to use to host this Environment. You can leave this blank for now.",
 					}
 					// TODO validate/transform to match valid kubnernetes cluster syntax
-					err := survey.AskOne(q, &data.Spec.Cluster, nil, surveyOpts)
+					err := util.AskOne(q, &data.Spec.Cluster, nil, surveyOpts)
 					if err != nil {
 						return nil, err
 					}
@@ -217,7 +219,7 @@ func CreateEnvironmentSurvey(batchMode bool, authConfigSvc auth.ConfigService, d
 			Help:    "Whether we promote to this Environment automatically, manually or never",
 		}
 		textValue := ""
-		err := survey.AskOne(q, &textValue, survey.Required, surveyOpts)
+		err := util.AskOne(q, &textValue, survey.Required, surveyOpts)
 		if err != nil {
 			return nil, err
 		}
@@ -243,7 +245,7 @@ func CreateEnvironmentSurvey(batchMode bool, authConfigSvc auth.ConfigService, d
 			Help:    "This number is used to sort Environments in sequential order, lowest first",
 		}
 		textValue := ""
-		err := survey.AskOne(q, &textValue, survey.Required, surveyOpts)
+		err := util.AskOne(q, &textValue, survey.Required, surveyOpts)
 		if err != nil {
 			return nil, err
 		}
@@ -287,7 +289,7 @@ func CreateEnvGitRepository(batchMode bool, authConfigSvc auth.ConfigService, de
 					Message: "Would you like to use GitOps to manage this environment? :",
 					Default: false,
 				}
-				err := survey.AskOne(confirm, &showURLEdit, nil, surveyOpts)
+				err := util.AskOne(confirm, &showURLEdit, nil, surveyOpts)
 				if err != nil {
 					return repo, nil, errors.Wrap(err, "asking enable GitOps question")
 				}
@@ -304,7 +306,7 @@ func CreateEnvGitRepository(batchMode bool, authConfigSvc auth.ConfigService, de
 						Message: fmt.Sprintf("We will now create a Git repository to store your %s environment, ok? :", data.Name),
 						Default: true,
 					}
-					err := survey.AskOne(confirm, &createRepo, nil, surveyOpts)
+					err := util.AskOne(confirm, &createRepo, nil, surveyOpts)
 					if err != nil {
 						return repo, nil, errors.Wrapf(err, "asking to create the git repository %q", data.Name)
 					}
@@ -318,6 +320,12 @@ func CreateEnvGitRepository(batchMode bool, authConfigSvc auth.ConfigService, de
 						return repo, gitProvider, errors.Wrap(err, "creating environment git repository")
 					}
 					data.Spec.Source.URL = repo.CloneURL
+					if !data.Spec.RemoteCluster {
+						err = ApplyBranchProtectionTemplate(devEnv, gitProvider, repo, data.Spec.Source.Ref)
+						if err != nil {
+							return repo, gitProvider, errors.Wrap(err, "protecting the environment git repository")
+						}
+					}
 				}
 			} else {
 				showURLEdit = true
@@ -328,7 +336,7 @@ func CreateEnvGitRepository(batchMode bool, authConfigSvc auth.ConfigService, de
 					Default: data.Spec.Source.URL,
 					Help:    "The git clone URL for the Environment's Helm charts source code and custom configuration",
 				}
-				err := survey.AskOne(q, &data.Spec.Source.URL, survey.Required, surveyOpts)
+				err := util.AskOne(q, &data.Spec.Source.URL, survey.Required, surveyOpts)
 				if err != nil {
 					return repo, nil, errors.Wrap(err, "asking for environment git clone URL")
 				}
@@ -351,7 +359,7 @@ func CreateEnvGitRepository(batchMode bool, authConfigSvc auth.ConfigService, de
 					Default: defaultBranch,
 					Help:    "The Git release branch in the Environments Git repository used to store Helm charts source code and custom configuration",
 				}
-				err := survey.AskOne(q, &data.Spec.Source.Ref, nil, surveyOpts)
+				err := util.AskOne(q, &data.Spec.Source.Ref, nil, surveyOpts)
 				if err != nil {
 					return repo, nil, errors.Wrap(err, "asking git branch for environment source")
 				}
@@ -398,7 +406,7 @@ func DoCreateEnvironmentGitRepo(batchMode bool, authConfigSvc auth.ConfigService
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "creating push URL for environment repo")
 		}
-		err = git.Clone(pushGitURL, dir)
+		err = cloneEnvironmentRepo(git, pushGitURL, dir)
 		if err != nil {
 			return nil, nil, errors.Wrapf(err, "cloning environment from %q into %q", pushGitURL, dir)
 		}
@@ -546,6 +554,28 @@ func GetDevEnvGitOwner(jxClient versioned.Interface) (string, error) {
 	return "", errors.New("Unable to find development environment in 'jx' to take git owner from")
 }
 
+// cloneEnvironmentRepo clones an existing environment repository via the git clone cache falling back to a regular
+// clone if the cache cannot be used. The whole history is cloned as later operations on the repository may need it
+func cloneEnvironmentRepo(git gits.Gitter, gitURL string, dir string) error {
+	cache, err := gits.NewCloneCache(git)
+	if err == nil && cache != nil {
+		err = cache.Clone(gitURL, "", dir, false)
+		if err == nil {
+			return nil
+		}
+		log.Logger().Warnf("failed to clone environment repository %s via the git clone cache: %s", util.SanitizeURL(gitURL), err.Error())
+		err = os.RemoveAll(dir)
+		if err != nil {
+			return errors.Wrapf(err, "removing %s", dir)
+		}
+		err = os.MkdirAll(dir, util.DefaultWritePermissions)
+		if err != nil {
+			return errors.Wrapf(err, "creating %s", dir)
+		}
+	}
+	return git.Clone(gitURL, dir)
+}
+
 // ModifyNamespace modifies the namespace
 func ModifyNamespace(out io.Writer, dir string, env *v1.Environment, git gits.Gitter, chartMusemFn ResolveChartMuseumURLFn) error {
 	ns := env.Spec.Namespace
@@ -781,6 +811,43 @@ func IsPreviewEnvironment(env *v1.Environment) bool {
 	return env != nil && env.Spec.Kind == v1.EnvironmentKindTypePreview
 }
 
+// PreviewLastDeployed returns when the preview environment was last deployed, defaulting to when it was created
+func PreviewLastDeployed(env *v1.Environment) time.Time {
+	answer := env.CreationTimestamp.Time
+	if text := env.Annotations[AnnotationPreviewLastDeployed]; text != "" {
+		t, err := time.Parse(time.RFC3339, text)
+		if err != nil {
+			log.Logger().Warnf("ignoring the invalid %s annotation %s of environment %s", AnnotationPreviewLastDeployed, text, env.Name)
+		} else if t.After(answer) {
+			answer = t
+		}
+	}
+	return answer
+}
+
+// PreviewTTL returns the duration the preview environment is kept after it was last deployed. The TTL annotation of
+// the environment overrides the default TTL. Returns zero if the preview environment never expires
+func PreviewTTL(env *v1.Environment, defaultTTL time.Duration) (time.Duration, error) {
+	text := env.Annotations[AnnotationPreviewTTL]
+	if text == "" {
+		return defaultTTL, nil
+	}
+	ttl, err := time.ParseDuration(text)
+	if err != nil {
+		return 0, errors.Wrapf(err, "parsing the %s annotation of environment %s", AnnotationPreviewTTL, env.Name)
+	}
+	return ttl, nil
+}
+
+// IsPreviewExpired returns true if the TTL of the preview environment elapsed since it was last deployed
+func IsPreviewExpired(env *v1.Environment, defaultTTL time.Duration, now time.Time) (bool, error) {
+	ttl, err := PreviewTTL(env, defaultTTL)
+	if err != nil || ttl <= 0 {
+		return false, err
+	}
+	return now.Sub(PreviewLastDeployed(env)) > ttl, nil
+}
+
 // GetFilteredEnvironmentNames returns the sorted list of environment names
 func GetFilteredEnvironmentNames(jxClient versioned.Interface, ns string, fn func(environment *v1.Environment) bool) ([]string, error) {
 	envNames := []string{}
@@ -870,6 +937,20 @@ func GetEnvironmentsByPrURL(jxClient versioned.Interface, ns string, prURL strin
 	return nil, fmt.Errorf("no environment found for PR '%s'", prURL)
 }
 
+// GetEnvironmentForNamespace find the permanent environment deployed to a namespace, returning nil if there is none
+func GetEnvironmentForNamespace(jxClient versioned.Interface, ns string, envNamespace string) (*v1.Environment, error) {
+	envs, err := jxClient.JenkinsV1().Environments(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, env := range envs.Items {
+		if env.Spec.Namespace == envNamespace && env.Spec.Kind.IsPermanent() {
+			return &env, nil
+		}
+	}
+	return nil, nil
+}
+
 // GetEnvironments returns the namespace name for a given environment
 func GetEnvironmentNamespace(jxClient versioned.Interface, ns, environment string) (string, error) {
 	env, err := jxClient.JenkinsV1().Environments(ns).Get(environment, metav1.GetOptions{})
@@ -952,7 +1033,7 @@ func PickEnvironment(envNames []string, defaultEnv string, handles util.IOFileHa
 			Options: envNames,
 			Default: defaultEnv,
 		}
-		err := survey.AskOne(prompt, &name, nil, surveyOpts)
+		err := util.AskOne(prompt, &name, nil, surveyOpts)
 		if err != nil {
 			return "", err
 		}
@@ -0,0 +1,57 @@
+package kube
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+// WarningEvents returns the distinct reasons and messages of the warning events of the resources of the kind with the
+// given names, most recent first, such as a failed image pull or a load balancer which could not be provisioned
+func WarningEvents(client kubernetes.Interface, namespace string, kind string, names ...string) ([]string, error) {
+	selector := fields.Set{"type": v1.EventTypeWarning, "involvedObject.kind": kind}.AsSelector()
+	list, err := client.CoreV1().Events(namespace).List(metav1.ListOptions{FieldSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	matches := map[string]bool{}
+	for _, name := range names {
+		matches[name] = true
+	}
+	events := []v1.Event{}
+	for _, event := range list.Items {
+		if event.Type == v1.EventTypeWarning && event.InvolvedObject.Kind == kind && matches[event.InvolvedObject.Name] {
+			events = append(events, event)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[j]).Before(eventTime(events[i]))
+	})
+	answer := []string{}
+	found := map[string]bool{}
+	for _, event := range events {
+		reason := fmt.Sprintf("%s %s: %s: %s", kind, event.InvolvedObject.Name, event.Reason, event.Message)
+		if !found[reason] {
+			found[reason] = true
+			answer = append(answer, reason)
+		}
+	}
+	return answer, nil
+}
+
+// eventTime returns when the event last happened
+func eventTime(event v1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
@@ -0,0 +1,101 @@
+package kube
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+)
+
+const (
+	// IngressAccessLogFormat the nginx ingress controller log-format-upstream which writes each request as a JSON object.
+	// The namespace and service fields identify the environment and app of the request so that a logging addon can
+	// route the access logs of each app
+	IngressAccessLogFormat = `{"time": "$time_iso8601", "requestID": "$req_id", "remoteAddr": "$remote_addr", ` +
+		`"host": "$host", "method": "$request_method", "path": "$uri", "protocol": "$server_protocol", ` +
+		`"status": "$status", "bytesSent": "$bytes_sent", "requestTime": "$request_time", "userAgent": "$http_user_agent", ` +
+		`"upstreamAddr": "$upstream_addr", "upstreamStatus": "$upstream_status", "upstreamResponseTime": "$upstream_response_time", ` +
+		`"namespace": "$namespace", "ingress": "$ingress_name", "service": "$service_name", "servicePort": "$service_port"}`
+)
+
+// IngressAccessLog a single request logged by the ingress controller using the IngressAccessLogFormat
+type IngressAccessLog struct {
+	Time                 string `json:"time"`
+	RequestID            string `json:"requestID"`
+	RemoteAddr           string `json:"remoteAddr"`
+	Host                 string `json:"host"`
+	Method               string `json:"method"`
+	Path                 string `json:"path"`
+	Protocol             string `json:"protocol"`
+	Status               string `json:"status"`
+	BytesSent            string `json:"bytesSent"`
+	RequestTime          string `json:"requestTime"`
+	UserAgent            string `json:"userAgent"`
+	UpstreamAddr         string `json:"upstreamAddr"`
+	UpstreamStatus       string `json:"upstreamStatus"`
+	UpstreamResponseTime string `json:"upstreamResponseTime"`
+	Namespace            string `json:"namespace"`
+	Ingress              string `json:"ingress"`
+	Service              string `json:"service"`
+	ServicePort          string `json:"servicePort"`
+}
+
+// ParseIngressAccessLog parses a line of the ingress controller logs returning nil if the line is not a JSON access log
+// such as the error log lines of the controller
+func ParseIngressAccessLog(line string) *IngressAccessLog {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "{") {
+		return nil
+	}
+	answer := &IngressAccessLog{}
+	err := json.Unmarshal([]byte(line), answer)
+	if err != nil || answer.Status == "" {
+		return nil
+	}
+	return answer
+}
+
+// String returns a single line summary of the request
+func (l *IngressAccessLog) String() string {
+	upstream := l.UpstreamStatus
+	if upstream == "" || upstream == "-" {
+		upstream = "no upstream"
+	} else {
+		upstream = "upstream " + upstream
+	}
+	return fmt.Sprintf("%s %s %s %s%s %s %ss", l.Time, l.Status, l.Method, l.Host, l.Path, upstream, l.RequestTime)
+}
+
+// IngressAccessLogWriter writes the access logs of any of the services in the namespace from the ingress controller
+// logs written to it, discarding all other lines
+type IngressAccessLogWriter struct {
+	Out       io.Writer
+	Namespace string
+	Services  []string
+
+	buffer bytes.Buffer
+}
+
+// Write filters the complete lines in the data
+func (w *IngressAccessLogWriter) Write(data []byte) (int, error) {
+	w.buffer.Write(data)
+	for {
+		text := w.buffer.String()
+		idx := strings.Index(text, "\n")
+		if idx < 0 {
+			return len(data), nil
+		}
+		w.buffer.Next(idx + 1)
+		entry := ParseIngressAccessLog(text[:idx])
+		if entry == nil || entry.Namespace != w.Namespace || util.StringArrayIndex(w.Services, entry.Service) < 0 {
+			continue
+		}
+		_, err := fmt.Fprintln(w.Out, entry.String())
+		if err != nil {
+			return 0, err
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// +build unit
+
+package kube_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIngressAccessLog(t *testing.T) {
+	t.Parallel()
+
+	// lets render the format the way the ingress controller does
+	line := strings.NewReplacer(
+		"$time_iso8601", "2020-05-01T10:00:00+00:00",
+		"$request_method", "GET",
+		"$uri", "/api",
+		"$status", "502",
+		"$upstream_status", "502",
+		"$request_time", "0.012",
+		"$host", "myapp-jx-staging.example.com",
+		"$namespace", "jx-staging",
+		"$service_name", "myapp",
+	).Replace(kube.IngressAccessLogFormat)
+
+	entry := kube.ParseIngressAccessLog(line)
+	require.NotNil(t, entry)
+	assert.Equal(t, "502", entry.Status)
+	assert.Equal(t, "jx-staging", entry.Namespace)
+	assert.Equal(t, "myapp", entry.Service)
+	assert.Equal(t, "2020-05-01T10:00:00+00:00 502 GET myapp-jx-staging.example.com/api upstream 502 0.012s", entry.String())
+
+	assert.Nil(t, kube.ParseIngressAccessLog("I0501 10:00:00.000000       6 controller.go:137] Configuration changes detected"))
+	assert.Nil(t, kube.ParseIngressAccessLog(`{"level": "info"}`))
+}
+
+func TestIngressAccessLogWriter(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := &kube.IngressAccessLogWriter{
+		Out:       &buf,
+		Namespace: "jx-staging",
+		Services:  []string{"jx-myapp", "myapp"},
+	}
+	lines := `I0501 10:00:00.000000       6 controller.go:137] Configuration changes detected
+{"time": "t1", "status": "404", "method": "GET", "host": "a", "path": "/x", "upstreamStatus": "404", "requestTime": "0.1", "namespace": "jx-staging", "service": "myapp"}
+{"time": "t2", "status": "200", "method": "GET", "host": "b", "path": "/", "upstreamStatus": "200", "requestTime": "0.1", "namespace": "jx-staging", "service": "other"}
+{"time": "t3", "status": "200", "method": "GET", "host": "a", "path": "/", "upstreamStatus": "200", "requestTime": "0.1", "namespace": "jx-production", "service": "myapp"}
+{"time": "t4", "status": "503", "method": "POST", "host": "a", "path": "/y", "upstreamStatus": "-", "requestTime": "0.0", "namespace": "jx-staging", "service": "myapp"}
+`
+	// lets write the logs in chunks which split lines
+	for i := 0; i < len(lines); i += 50 {
+		end := i + 50
+		if end > len(lines) {
+			end = len(lines)
+		}
+		n, err := w.Write([]byte(lines[i:end]))
+		require.NoError(t, err)
+		assert.Equal(t, end-i, n)
+	}
+	assert.Equal(t, "t1 404 GET a/x upstream 404 0.1s\nt4 503 POST a/y no upstream 0.0s\n", buf.String())
+}
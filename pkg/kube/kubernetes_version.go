@@ -0,0 +1,64 @@
+package kube
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/jenkins-x/jx/v2/pkg/versionstream"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/discovery"
+)
+
+// VerifyKubernetesVersion checks the cluster version is within the range supported by the version stream and that
+// all the APIs required by the version stream charts are still served. It returns a list of the incompatibilities
+// found which is empty if the cluster is supported
+func VerifyKubernetesVersion(client discovery.DiscoveryInterface, versions *versionstream.KubernetesVersions) ([]string, error) {
+	var problems []string
+	serverVersion, err := client.ServerVersion()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the Kubernetes server version")
+	}
+	if serverVersion != nil {
+		err = versions.VerifyVersion(serverVersion.GitVersion)
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	if len(versions.RequiredAPIs) == 0 {
+		return problems, nil
+	}
+	groups, err := client.ServerGroups()
+	if err != nil {
+		return problems, errors.Wrap(err, "failed to discover the API groups")
+	}
+	// the legacy core API is served from /api rather than as an API group
+	servedGroupVersions := map[string]bool{"v1": true}
+	for _, group := range groups.Groups {
+		for _, v := range group.Versions {
+			servedGroupVersions[v.GroupVersion] = true
+		}
+	}
+
+	resourcesByGroupVersion := map[string]map[string]bool{}
+	for _, api := range versions.RequiredAPIs {
+		resources, ok := resourcesByGroupVersion[api.GroupVersion]
+		if !ok {
+			resources = map[string]bool{}
+			if servedGroupVersions[api.GroupVersion] {
+				list, err := client.ServerResourcesForGroupVersion(api.GroupVersion)
+				if err != nil {
+					return problems, errors.Wrapf(err, "failed to discover the resources for API %s", api.GroupVersion)
+				}
+				for _, r := range list.APIResources {
+					resources[r.Name] = true
+				}
+			}
+			resourcesByGroupVersion[api.GroupVersion] = resources
+		}
+		if !resources[api.Resource] {
+			problems = append(problems, fmt.Sprintf("the cluster no longer serves the API %s which is used by charts in the version stream", util.ColorInfo(api.String())))
+		}
+	}
+	return problems, nil
+}
@@ -0,0 +1,83 @@
+// +build unit
+
+package kube_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/versionstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestVerifyKubernetesVersion(t *testing.T) {
+	t.Parallel()
+
+	versions := &versionstream.KubernetesVersions{
+		MinVersion: "1.13.0",
+		UpperLimit: "1.18.0",
+		RequiredAPIs: []versionstream.KubernetesAPI{
+			{GroupVersion: "extensions/v1beta1", Resource: "ingresses"},
+			{GroupVersion: "apps/v1", Resource: "deployments"},
+			{GroupVersion: "v1", Resource: "pods"},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		serverVersion string
+		groupVersions []string
+		problems      int
+	}{
+		{
+			name:          "supported",
+			serverVersion: "v1.15.11-gke.3",
+			groupVersions: []string{"extensions/v1beta1", "apps/v1"},
+		},
+		{
+			name:          "too new",
+			serverVersion: "v1.18.2",
+			groupVersions: []string{"extensions/v1beta1", "apps/v1"},
+			problems:      1,
+		},
+		{
+			name:          "removed ingress API",
+			serverVersion: "v1.16.0",
+			groupVersions: []string{"apps/v1"},
+			problems:      1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := kubefake.NewSimpleClientset()
+			disc := client.Discovery().(*fakediscovery.FakeDiscovery)
+			disc.FakedServerVersion = &version.Info{GitVersion: tc.serverVersion}
+			disc.Resources = []*metav1.APIResourceList{
+				{
+					GroupVersion: "v1",
+					APIResources: []metav1.APIResource{{Name: "pods"}},
+				},
+			}
+			for _, gv := range tc.groupVersions {
+				resource := "deployments"
+				if gv == "extensions/v1beta1" {
+					resource = "ingresses"
+				}
+				disc.Resources = append(disc.Resources, &metav1.APIResourceList{
+					GroupVersion: gv,
+					APIResources: []metav1.APIResource{{Name: resource}},
+				})
+			}
+
+			problems, err := kube.VerifyKubernetesVersion(disc, versions)
+			require.NoError(t, err)
+			assert.Len(t, problems, tc.problems, "problems: %v", problems)
+		})
+	}
+}
@@ -21,6 +21,20 @@ func TailLogs(ns string, pod string, containerName string, errOut io.Writer, out
 		args = append(args, "-c", containerName)
 	}
 	args = append(args, pod)
+	return tailLogs(args, errOut, out)
+}
+
+// TailLogsForSelector will tail the logs of all the pods in ns matching the label selector,
+// returning when the logs are complete. It writes to errOut and out.
+func TailLogsForSelector(ns string, selector string, containerName string, errOut io.Writer, out io.Writer) error {
+	args := []string{"logs", "-n", ns, "-f", "-l", selector, "--max-log-requests", "20"}
+	if containerName != "" {
+		args = append(args, "-c", containerName)
+	}
+	return tailLogs(args, errOut, out)
+}
+
+func tailLogs(args []string, errOut io.Writer, out io.Writer) error {
 	name := "kubectl"
 	e := exec.Command(name, args...)
 	e.Stderr = errOut
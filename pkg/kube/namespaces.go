@@ -1,15 +1,17 @@
 package kube
 
 import (
-	"fmt"
-
 	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx-api/pkg/client/clientset/versioned"
 	"github.com/jenkins-x/jx-logging/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
 )
 
 func EnsureEnvironmentNamespaceSetup(kubeClient kubernetes.Interface, jxClient versioned.Interface, env *v1.Environment, ns string) error {
@@ -222,55 +224,134 @@ func EnsureEditEnvironmentSetup(kubeClient kubernetes.Interface, jxClient versio
 	return env, nil
 }
 
-// Ensure that the namespace exists for the given name
+// EnsureNamespaceCreated ensures the namespace exists with the given labels and annotations. The labels and
+// annotations are added to an existing namespace keeping its other labels and annotations. Namespaces created by jx
+// are labelled with their owner so that DeleteNamespaceIfOwned only ever deletes the namespaces jx created. The owner
+// label cannot be given by the caller so that existing namespaces are never claimed
 func EnsureNamespaceCreated(kubeClient kubernetes.Interface, name string, labels map[string]string, annotations map[string]string) error {
-	n, err := kubeClient.CoreV1().Namespaces().Get(name, metav1.GetOptions{})
-	if err == nil {
-		// lets check if we have the labels setup
-		if n.Annotations == nil {
-			n.Annotations = map[string]string{}
-		}
-		if n.Labels == nil {
-			n.Labels = map[string]string{}
-		}
-		changed := false
-		if labels != nil {
-			for k, v := range labels {
-				if n.Labels[k] != v {
-					n.Labels[k] = v
-					changed = true
-				}
+	if _, ok := labels[LabelCreatedBy]; ok {
+		copied := map[string]string{}
+		for k, v := range labels {
+			if k != LabelCreatedBy {
+				copied[k] = v
 			}
 		}
-		if annotations != nil {
-			for k, v := range annotations {
-				if n.Annotations[k] != v {
-					n.Annotations[k] = v
-					changed = true
-				}
-			}
+		labels = copied
+	}
+	namespaces := kubeClient.CoreV1().Namespaces()
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		n, err := namespaces.Get(name, metav1.GetOptions{})
+		if err != nil {
+			return err
 		}
-		if changed {
-			_, err = kubeClient.CoreV1().Namespaces().Update(n)
-			if err != nil {
-				return fmt.Errorf("Failed to label Namespace %s %s", name, err)
-			}
+		if !reconcileNamespaceMetadata(n, labels, annotations) {
+			return nil
 		}
+		_, err = namespaces.Update(n)
+		return err
+	})
+	if err == nil {
 		return nil
 	}
+	if !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to label Namespace %s", name)
+	}
 
 	namespace := &corev1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        name,
-			Labels:      labels,
-			Annotations: annotations,
+			Labels:      map[string]string{LabelCreatedBy: ValueCreatedByJX},
+			Annotations: map[string]string{},
 		},
 	}
-	_, err = kubeClient.CoreV1().Namespaces().Create(namespace)
+	reconcileNamespaceMetadata(namespace, labels, annotations)
+	_, err = namespaces.Create(namespace)
+	if apierrors.IsAlreadyExists(err) {
+		// created concurrently so lets label it
+		return EnsureNamespaceCreated(kubeClient, name, labels, annotations)
+	}
 	if err != nil {
-		return fmt.Errorf("Failed to create Namespace %s %s", name, err)
-	} else {
-		log.Logger().Infof("Namespace %s created ", name)
+		return errors.Wrapf(err, "failed to create Namespace %s", name)
 	}
-	return err
+	log.Logger().Infof("Namespace %s created ", name)
+	return nil
+}
+
+// reconcileNamespaceMetadata adds the labels and annotations to the namespace returning true if it changed
+func reconcileNamespaceMetadata(n *corev1.Namespace, labels map[string]string, annotations map[string]string) bool {
+	if n.Labels == nil {
+		n.Labels = map[string]string{}
+	}
+	if n.Annotations == nil {
+		n.Annotations = map[string]string{}
+	}
+	changed := false
+	for k, v := range labels {
+		if current, ok := n.Labels[k]; !ok || current != v {
+			n.Labels[k] = v
+			changed = true
+		}
+	}
+	for k, v := range annotations {
+		if current, ok := n.Annotations[k]; !ok || current != v {
+			n.Annotations[k] = v
+			changed = true
+		}
+	}
+	return changed
+}
+
+// IsNamespaceOwned returns true if the namespace was created by jx
+func IsNamespaceOwned(n *corev1.Namespace) bool {
+	switch n.Labels[LabelCreatedBy] {
+	case ValueCreatedByJX, ValueCreatedByJXBoot:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsEnvironmentNamespaceOwned returns true if the namespace was created by jx or is the namespace of the environment
+// which jx set up before the namespaces it created were labelled with their owner
+func IsEnvironmentNamespaceOwned(n *corev1.Namespace, env *v1.Environment) bool {
+	if IsNamespaceOwned(n) {
+		return true
+	}
+	return env != nil && env.Spec.Namespace == n.Name && n.Labels[LabelEnvironment] == env.Name && n.Labels[LabelTeam] != ""
+}
+
+// DeleteNamespaceIfOwned deletes the namespace if it was created by jx. It returns false without deleting the
+// namespace if it does not exist or was created by something else such as the user or another tool
+func DeleteNamespaceIfOwned(kubeClient kubernetes.Interface, name string) (bool, error) {
+	return deleteNamespaceIfOwned(kubeClient, name, IsNamespaceOwned)
+}
+
+// DeleteEnvironmentNamespaceIfOwned deletes the namespace of the environment if it was created by jx, including the
+// namespaces set up for environments and previews before they were labelled with their owner
+func DeleteEnvironmentNamespaceIfOwned(kubeClient kubernetes.Interface, env *v1.Environment) (bool, error) {
+	return deleteNamespaceIfOwned(kubeClient, env.Spec.Namespace, func(n *corev1.Namespace) bool {
+		return IsEnvironmentNamespaceOwned(n, env)
+	})
+}
+
+func deleteNamespaceIfOwned(kubeClient kubernetes.Interface, name string, owned func(*corev1.Namespace) bool) (bool, error) {
+	n, err := kubeClient.CoreV1().Namespaces().Get(name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "getting the namespace %s", name)
+	}
+	if !owned(n) {
+		log.Logger().Warnf("Not deleting the namespace %s as it was not created by jx. To delete it run: %s", name, util.ColorInfo("kubectl delete namespace "+name))
+		return false, nil
+	}
+	err = kubeClient.CoreV1().Namespaces().Delete(name, &metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "deleting the namespace %s", name)
+	}
+	return true, nil
 }
@@ -9,7 +9,10 @@ import (
 	versiond_mocks "github.com/jenkins-x/jx-api/pkg/client/clientset/versioned/fake"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kube_mocks "k8s.io/client-go/kubernetes/fake"
 )
 
 func TestEnsureDevEnvironmentSetup(t *testing.T) {
@@ -52,3 +55,125 @@ func TestEnsureDevEnvironmentSetup(t *testing.T) {
 	assert.Equal(t, jenkinsio_v1.PromotionEngineType("Jenkins"), env.Spec.TeamSettings.PromotionEngine)
 	assert.Equal(t, envFixture.Spec.TeamSettings.AppsRepository, env.Spec.TeamSettings.AppsRepository)
 }
+
+func TestEnsureNamespaceCreated(t *testing.T) {
+	t.Parallel()
+
+	existing := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "existing",
+			Labels:      map[string]string{"team": "a"},
+			Annotations: map[string]string{"note": "keep"},
+		},
+	}
+	client := kube_mocks.NewSimpleClientset(existing)
+
+	err := kube.EnsureNamespaceCreated(client, "jx-staging", map[string]string{kube.LabelEnvironment: "staging"}, map[string]string{"note": "env"})
+	require.NoError(t, err)
+	created, err := client.CoreV1().Namespaces().Get("jx-staging", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{kube.LabelEnvironment: "staging", kube.LabelCreatedBy: kube.ValueCreatedByJX}, created.Labels)
+	assert.Equal(t, map[string]string{"note": "env"}, created.Annotations)
+	assert.True(t, kube.IsNamespaceOwned(created))
+
+	err = kube.EnsureNamespaceCreated(client, "existing", map[string]string{kube.LabelEnvironment: "production"}, map[string]string{"jenkins.io/env": "production"})
+	require.NoError(t, err)
+	updated, err := client.CoreV1().Namespaces().Get("existing", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "a", kube.LabelEnvironment: "production"}, updated.Labels)
+	assert.Equal(t, map[string]string{"note": "keep", "jenkins.io/env": "production"}, updated.Annotations)
+	assert.False(t, kube.IsNamespaceOwned(updated), "an existing namespace should not be labelled as created by jx")
+
+	err = kube.EnsureNamespaceCreated(client, "existing", map[string]string{kube.LabelCreatedBy: kube.ValueCreatedByJXBoot}, nil)
+	require.NoError(t, err)
+	updated, err = client.CoreV1().Namespaces().Get("existing", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.False(t, kube.IsNamespaceOwned(updated), "callers should not be able to label a namespace as created by jx")
+
+	err = kube.EnsureNamespaceCreated(client, "velero", map[string]string{kube.LabelCreatedBy: kube.ValueCreatedByJXBoot}, nil)
+	require.NoError(t, err)
+	created, err = client.CoreV1().Namespaces().Get("velero", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, kube.ValueCreatedByJX, created.Labels[kube.LabelCreatedBy])
+}
+
+func TestDeleteNamespaceIfOwned(t *testing.T) {
+	t.Parallel()
+
+	owned := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "jx-preview",
+			Labels: map[string]string{kube.LabelCreatedBy: kube.ValueCreatedByJX},
+		},
+	}
+	other := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "production",
+		},
+	}
+	client := kube_mocks.NewSimpleClientset(owned, other)
+
+	deleted, err := kube.DeleteNamespaceIfOwned(client, "jx-preview")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	_, err = client.CoreV1().Namespaces().Get("jx-preview", metav1.GetOptions{})
+	assert.Error(t, err)
+
+	deleted, err = kube.DeleteNamespaceIfOwned(client, "production")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+	_, err = client.CoreV1().Namespaces().Get("production", metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	deleted, err = kube.DeleteNamespaceIfOwned(client, "missing")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+}
+
+func TestDeleteEnvironmentNamespaceIfOwned(t *testing.T) {
+	t.Parallel()
+
+	legacy := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "jx-staging",
+			Labels: map[string]string{kube.LabelTeam: "jx", kube.LabelEnvironment: "staging"},
+		},
+	}
+	boot := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "jx-production",
+			Labels: map[string]string{kube.LabelCreatedBy: kube.ValueCreatedByJXBoot},
+		},
+	}
+	other := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "production",
+			Labels: map[string]string{kube.LabelEnvironment: "staging"},
+		},
+	}
+	client := kube_mocks.NewSimpleClientset(legacy, boot, other)
+	env := func(name string, ns string) *jenkinsio_v1.Environment {
+		return &jenkinsio_v1.Environment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "jx"},
+			Spec:       jenkinsio_v1.EnvironmentSpec{Namespace: ns},
+		}
+	}
+
+	deleted, err := kube.DeleteNamespaceIfOwned(client, "jx-staging")
+	require.NoError(t, err)
+	assert.False(t, deleted, "only the environment should own an unlabelled environment namespace")
+
+	deleted, err = kube.DeleteEnvironmentNamespaceIfOwned(client, env("staging", "jx-staging"))
+	require.NoError(t, err)
+	assert.True(t, deleted, "environment namespaces set up before they were labelled should be owned")
+
+	deleted, err = kube.DeleteEnvironmentNamespaceIfOwned(client, env("production", "jx-production"))
+	require.NoError(t, err)
+	assert.True(t, deleted, "namespaces created by jx boot should be owned")
+
+	deleted, err = kube.DeleteEnvironmentNamespaceIfOwned(client, env("production", "production"))
+	require.NoError(t, err)
+	assert.False(t, deleted, "namespaces not set up for the environment should not be owned")
+	_, err = client.CoreV1().Namespaces().Get("production", metav1.GetOptions{})
+	assert.NoError(t, err)
+}
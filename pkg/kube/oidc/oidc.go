@@ -0,0 +1,482 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientauthv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
+)
+
+const (
+	// DeviceCodeGrantType the grant type of the OAuth 2.0 device authorization grant
+	DeviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+	// CallbackPath the path of the local redirect URI of the browser login
+	CallbackPath = "/callback"
+
+	// ExecCredentialAPIVersion the API version of the credentials written by the kubectl exec plugin
+	ExecCredentialAPIVersion = "client.authentication.k8s.io/v1beta1"
+
+	// tokenExpiryLeeway how long before it expires an ID token gets refreshed
+	tokenExpiryLeeway = time.Minute
+)
+
+// DefaultScopes the scopes requested when no scopes are configured
+var DefaultScopes = []string{"openid", "email", "profile", "offline_access"}
+
+// Provider the endpoints of an OpenID Connect identity provider such as Dex, Keycloak or Azure AD
+type Provider struct {
+	Issuer                      string `json:"issuer"`
+	AuthorizationEndpoint       string `json:"authorization_endpoint"`
+	TokenEndpoint               string `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint,omitempty"`
+}
+
+// SupportsDeviceLogin returns true if the provider supports the device authorization grant
+func (p *Provider) SupportsDeviceLogin() bool {
+	return p.DeviceAuthorizationEndpoint != ""
+}
+
+// Client the OAuth client registered with the identity provider which the API server trusts
+type Client struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	HTTPClient   *http.Client
+}
+
+// Token the tokens returned by the identity provider
+type Token struct {
+	IDToken      string    `json:"id_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry"`
+}
+
+// Valid returns true if the ID token does not expire within a minute
+func (t *Token) Valid() bool {
+	return t != nil && t.IDToken != "" && time.Now().Add(tokenExpiryLeeway).Before(t.Expiry)
+}
+
+// Claims the claims of the ID token which identify the user
+type Claims struct {
+	Issuer            string `json:"iss"`
+	Subject           string `json:"sub"`
+	Email             string `json:"email,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Expiry            int64  `json:"exp"`
+}
+
+// Username returns the name of the user the token identifies
+func (c *Claims) Username() string {
+	if c.Email != "" {
+		return c.Email
+	}
+	if c.PreferredUsername != "" {
+		return c.PreferredUsername
+	}
+	return c.Subject
+}
+
+// DeviceAuthorization the codes of a pending device login which the user confirms at the verification URI
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	// VerificationURL the verification URI returned by providers predating RFC 8628 such as Google
+	VerificationURL string `json:"verification_url,omitempty"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval,omitempty"`
+}
+
+// URI returns the URI the user opens to confirm the login
+func (d *DeviceAuthorization) URI() string {
+	if d.VerificationURIComplete != "" {
+		return d.VerificationURIComplete
+	}
+	if d.VerificationURI != "" {
+		return d.VerificationURI
+	}
+	return d.VerificationURL
+}
+
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	RefreshToken     string `json:"refresh_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// tokenError an error response of the token endpoint
+type tokenError struct {
+	Code        string
+	Description string
+}
+
+func (e *tokenError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("%s: %s", e.Code, e.Description)
+	}
+	return e.Code
+}
+
+// Discover looks up the endpoints of the identity provider in its OpenID Connect discovery document
+func (c *Client) Discover() (*Provider, error) {
+	u := strings.TrimSuffix(c.IssuerURL, "/") + "/.well-known/openid-configuration"
+	resp, err := c.httpClient().Get(u)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting the OpenID configuration %s", u)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("getting the OpenID configuration %s returned status %d", u, resp.StatusCode)
+	}
+	provider := &Provider{}
+	err = json.NewDecoder(resp.Body).Decode(provider)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the OpenID configuration %s", u)
+	}
+	if provider.TokenEndpoint == "" {
+		return nil, fmt.Errorf("the OpenID configuration %s has no token endpoint", u)
+	}
+	return provider, nil
+}
+
+// StartDeviceLogin starts a device login returning the code the user confirms at the verification URI
+func (c *Client) StartDeviceLogin(provider *Provider) (*DeviceAuthorization, error) {
+	if !provider.SupportsDeviceLogin() {
+		return nil, fmt.Errorf("the identity provider %s does not support the device login", provider.Issuer)
+	}
+	form := url.Values{
+		"client_id": {c.ClientID},
+		"scope":     {strings.Join(c.scopes(), " ")},
+	}
+	resp, err := c.post(provider.DeviceAuthorizationEndpoint, form)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading the device authorization")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("the device authorization endpoint returned status %d: %s", resp.StatusCode, string(data))
+	}
+	auth := &DeviceAuthorization{}
+	err = json.Unmarshal(data, auth)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing the device authorization")
+	}
+	if auth.DeviceCode == "" || auth.URI() == "" {
+		return nil, fmt.Errorf("the device authorization endpoint returned no device code or verification URI")
+	}
+	return auth, nil
+}
+
+// WaitForDeviceLogin polls the token endpoint until the user confirmed the device login, the login was denied or it
+// expired
+func (c *Client) WaitForDeviceLogin(provider *Provider, auth *DeviceAuthorization, sleep func(time.Duration)) (*Token, error) {
+	interval := time.Duration(auth.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	expiresIn := time.Duration(auth.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		expiresIn = 10 * time.Minute
+	}
+	form := url.Values{
+		"grant_type":  {DeviceCodeGrantType},
+		"device_code": {auth.DeviceCode},
+		"client_id":   {c.ClientID},
+	}
+	for waited := time.Duration(0); waited < expiresIn; waited += interval {
+		sleep(interval)
+		token, err := c.requestToken(provider, form, "")
+		if err == nil {
+			return token, nil
+		}
+		tokenErr, ok := errors.Cause(err).(*tokenError)
+		if !ok {
+			return nil, err
+		}
+		switch tokenErr.Code {
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		default:
+			return nil, errors.Wrap(err, "the device login failed")
+		}
+	}
+	return nil, fmt.Errorf("the device login expired before it was confirmed")
+}
+
+// BrowserLogin logs in with the authorization code flow with PKCE. It serves the redirect URI on the given local port
+// and calls open with the URL of the login page of the identity provider
+func (c *Client) BrowserLogin(provider *Provider, port int, open func(string) error, timeout time.Duration) (*Token, error) {
+	if provider.AuthorizationEndpoint == "" {
+		return nil, fmt.Errorf("the identity provider %s does not support the browser login", provider.Issuer)
+	}
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		return nil, errors.Wrapf(err, "listening on port %d for the login redirect", port)
+	}
+	defer listener.Close()
+	redirectURI := fmt.Sprintf("http://localhost:%d%s", listener.Addr().(*net.TCPAddr).Port, CallbackPath)
+
+	state, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+	authURL, err := url.Parse(provider.AuthorizationEndpoint)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the authorization endpoint %s", provider.AuthorizationEndpoint)
+	}
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", c.ClientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("scope", strings.Join(c.scopes(), " "))
+	query.Set("state", state)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	type result struct {
+		token *Token
+		err   error
+	}
+	results := make(chan result, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc(CallbackPath, func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+		var res result
+		switch {
+		case params.Get("state") != state:
+			res.err = fmt.Errorf("the login redirect has an invalid state")
+		case params.Get("error") != "":
+			res.err = &tokenError{Code: params.Get("error"), Description: params.Get("error_description")}
+		default:
+			res.token, res.err = c.requestToken(provider, url.Values{
+				"grant_type":    {"authorization_code"},
+				"code":          {params.Get("code")},
+				"redirect_uri":  {redirectURI},
+				"client_id":     {c.ClientID},
+				"code_verifier": {verifier},
+			}, "")
+		}
+		if res.err != nil {
+			http.Error(w, fmt.Sprintf("Login failed: %s", res.err), http.StatusBadRequest)
+		} else {
+			fmt.Fprintln(w, "Login succeeded, you can close this window and return to the terminal.")
+		}
+		select {
+		case results <- res:
+		default:
+		}
+	})
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener) //nolint:errcheck
+	defer server.Close()
+
+	err = open(authURL.String())
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case res := <-results:
+		return res.token, res.err
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("timed out after %s waiting for the browser login", timeout)
+	}
+}
+
+// Refresh returns a new ID token using the refresh token of the token
+func (c *Client) Refresh(provider *Provider, token *Token) (*Token, error) {
+	if token == nil || token.RefreshToken == "" {
+		return nil, fmt.Errorf("there is no refresh token")
+	}
+	return c.requestToken(provider, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {token.RefreshToken},
+		"client_id":     {c.ClientID},
+	}, token.RefreshToken)
+}
+
+func (c *Client) requestToken(provider *Provider, form url.Values, refreshToken string) (*Token, error) {
+	resp, err := c.post(provider.TokenEndpoint, form)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading the token response")
+	}
+	tr := &tokenResponse{}
+	err = json.Unmarshal(data, tr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the token response with status %d", resp.StatusCode)
+	}
+	if tr.Error != "" {
+		return nil, &tokenError{Code: tr.Error, Description: tr.ErrorDescription}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("the token endpoint returned status %d", resp.StatusCode)
+	}
+	if tr.IDToken == "" {
+		return nil, fmt.Errorf("the token response has no ID token, check the openid scope is requested")
+	}
+	claims, err := ParseClaims(tr.IDToken)
+	if err != nil {
+		return nil, err
+	}
+	token := &Token{
+		IDToken:      tr.IDToken,
+		RefreshToken: tr.RefreshToken,
+		Expiry:       time.Unix(claims.Expiry, 0).UTC(),
+	}
+	// providers may not rotate the refresh token so keep using the previous one
+	if token.RefreshToken == "" {
+		token.RefreshToken = refreshToken
+	}
+	return token, nil
+}
+
+func (c *Client) post(endpoint string, form url.Values) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(c.ClientID), url.QueryEscape(c.ClientSecret))
+	}
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "calling %s", endpoint)
+	}
+	return resp, nil
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return util.GetClient()
+}
+
+func (c *Client) scopes() []string {
+	if len(c.Scopes) == 0 {
+		return DefaultScopes
+	}
+	if util.StringArrayIndex(c.Scopes, "openid") < 0 {
+		return append([]string{"openid"}, c.Scopes...)
+	}
+	return c.Scopes
+}
+
+// ParseClaims returns the claims of the ID token without verifying its signature which the API server verifies
+func ParseClaims(idToken string) (*Claims, error) {
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("the ID token is not a JWT")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding the claims of the ID token")
+	}
+	claims := &Claims{}
+	err = json.Unmarshal(data, claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing the claims of the ID token")
+	}
+	return claims, nil
+}
+
+// TokenCacheFile returns the file in the directory caching the tokens of the client of the issuer
+func TokenCacheFile(dir string, issuerURL string, clientID string) string {
+	hash := sha256.Sum256([]byte(strings.TrimSuffix(issuerURL, "/") + "\n" + clientID))
+	return filepath.Join(dir, "oidc", hex.EncodeToString(hash[:8])+".json")
+}
+
+// LoadToken loads the cached token returning nil if there is none
+func LoadToken(fileName string) (*Token, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading the token cache %s", fileName)
+	}
+	token := &Token{}
+	err = json.Unmarshal(data, token)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the token cache %s", fileName)
+	}
+	return token, nil
+}
+
+// SaveToken caches the token in a file only the user can read
+func SaveToken(fileName string, token *Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return errors.Wrap(err, "marshalling the token")
+	}
+	err = os.MkdirAll(filepath.Dir(fileName), util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "creating the directory of the token cache %s", fileName)
+	}
+	err = ioutil.WriteFile(fileName, data, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "saving the token cache %s", fileName)
+	}
+	return nil
+}
+
+// ExecCredential returns the JSON of the ID token which a kubectl exec plugin writes to its standard output
+func ExecCredential(token *Token) ([]byte, error) {
+	expiry := metav1.NewTime(token.Expiry)
+	credential := &clientauthv1beta1.ExecCredential{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: ExecCredentialAPIVersion,
+			Kind:       "ExecCredential",
+		},
+		Status: &clientauthv1beta1.ExecCredentialStatus{
+			Token:               token.IDToken,
+			ExpirationTimestamp: &expiry,
+		},
+	}
+	return json.Marshal(credential)
+}
+
+func randomString() (string, error) {
+	data := make([]byte, 32)
+	_, err := rand.Read(data)
+	if err != nil {
+		return "", errors.Wrap(err, "generating a random string")
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
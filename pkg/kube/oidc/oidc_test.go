@@ -0,0 +1,181 @@
+// +build unit
+
+package oidc_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/kube/oidc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func idToken(t *testing.T, expiry time.Time) string {
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":   "https://dex.example.com",
+		"sub":   "123",
+		"email": "jdoe@example.com",
+		"exp":   expiry.Unix(),
+	})
+	require.NoError(t, err)
+	return "e30." + base64.RawURLEncoding.EncodeToString(claims) + ".c2ln"
+}
+
+type fakeProvider struct {
+	*httptest.Server
+	pendingPolls int
+	forms        []url.Values
+	idToken      string
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	p := &fakeProvider{idToken: idToken(t, time.Now().Add(time.Hour))}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                        p.URL,
+			"authorization_endpoint":        p.URL + "/auth",
+			"token_endpoint":                p.URL + "/token",
+			"device_authorization_endpoint": p.URL + "/device/code",
+		})
+	})
+	mux.HandleFunc("/device/code", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"device_code":      "device-123",
+			"user_code":        "ABCD-EFGH",
+			"verification_uri": p.URL + "/device",
+			"expires_in":       300,
+			"interval":         1,
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		p.forms = append(p.forms, r.PostForm)
+		if r.PostForm.Get("grant_type") == oidc.DeviceCodeGrantType && p.pendingPolls > 0 {
+			p.pendingPolls--
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"authorization_pending"}`)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"id_token":      p.idToken,
+			"refresh_token": "refresh-" + r.PostForm.Get("grant_type"),
+		})
+	})
+	p.Server = httptest.NewServer(mux)
+	return p
+}
+
+func TestDeviceLogin(t *testing.T) {
+	p := newFakeProvider(t)
+	defer p.Close()
+	p.pendingPolls = 2
+
+	client := &oidc.Client{IssuerURL: p.URL, ClientID: "kubernetes"}
+	provider, err := client.Discover()
+	require.NoError(t, err)
+	assert.True(t, provider.SupportsDeviceLogin())
+
+	auth, err := client.StartDeviceLogin(provider)
+	require.NoError(t, err)
+	assert.Equal(t, "ABCD-EFGH", auth.UserCode)
+	assert.Equal(t, p.URL+"/device", auth.URI())
+
+	sleeps := 0
+	token, err := client.WaitForDeviceLogin(provider, auth, func(time.Duration) { sleeps++ })
+	require.NoError(t, err)
+	assert.Equal(t, 3, sleeps)
+	assert.Equal(t, p.idToken, token.IDToken)
+	assert.True(t, token.Valid())
+	assert.Equal(t, "device-123", p.forms[len(p.forms)-1].Get("device_code"))
+}
+
+func TestBrowserLogin(t *testing.T) {
+	p := newFakeProvider(t)
+	defer p.Close()
+
+	client := &oidc.Client{IssuerURL: p.URL, ClientID: "kubernetes"}
+	provider, err := client.Discover()
+	require.NoError(t, err)
+
+	open := func(authURL string) error {
+		u, err := url.Parse(authURL)
+		require.NoError(t, err)
+		query := u.Query()
+		assert.Equal(t, "S256", query.Get("code_challenge_method"))
+		assert.Equal(t, "openid email profile offline_access", query.Get("scope"))
+		go func() {
+			resp, err := http.Get(query.Get("redirect_uri") + "?code=abc&state=" + url.QueryEscape(query.Get("state")))
+			if err == nil {
+				_, _ = ioutil.ReadAll(resp.Body)
+				resp.Body.Close()
+			}
+		}()
+		return nil
+	}
+	token, err := client.BrowserLogin(provider, 0, open, 10*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, p.idToken, token.IDToken)
+	assert.Equal(t, "refresh-authorization_code", token.RefreshToken)
+
+	form := p.forms[0]
+	assert.Equal(t, "abc", form.Get("code"))
+	assert.NotEmpty(t, form.Get("code_verifier"))
+}
+
+func TestRefresh(t *testing.T) {
+	p := newFakeProvider(t)
+	defer p.Close()
+
+	client := &oidc.Client{IssuerURL: p.URL, ClientID: "kubernetes"}
+	provider, err := client.Discover()
+	require.NoError(t, err)
+
+	expired := &oidc.Token{IDToken: idToken(t, time.Now().Add(-time.Hour)), RefreshToken: "old", Expiry: time.Now().Add(-time.Hour)}
+	assert.False(t, expired.Valid())
+	token, err := client.Refresh(provider, expired)
+	require.NoError(t, err)
+	assert.True(t, token.Valid())
+	assert.Equal(t, "old", p.forms[0].Get("refresh_token"))
+}
+
+func TestTokenCacheAndExecCredential(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-oidc")
+	require.NoError(t, err)
+
+	fileName := oidc.TokenCacheFile(dir, "https://dex.example.com/", "kubernetes")
+	assert.Equal(t, fileName, oidc.TokenCacheFile(dir, "https://dex.example.com", "kubernetes"))
+	assert.Equal(t, filepath.Join(dir, "oidc"), filepath.Dir(fileName))
+
+	token, err := oidc.LoadToken(fileName)
+	require.NoError(t, err)
+	assert.Nil(t, token)
+
+	expiry := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	err = oidc.SaveToken(fileName, &oidc.Token{IDToken: idToken(t, expiry), RefreshToken: "refresh", Expiry: expiry})
+	require.NoError(t, err)
+	token, err = oidc.LoadToken(fileName)
+	require.NoError(t, err)
+	assert.Equal(t, "refresh", token.RefreshToken)
+
+	claims, err := oidc.ParseClaims(token.IDToken)
+	require.NoError(t, err)
+	assert.Equal(t, "jdoe@example.com", claims.Username())
+
+	data, err := oidc.ExecCredential(token)
+	require.NoError(t, err)
+	credential := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(data, &credential))
+	assert.Equal(t, "ExecCredential", credential["kind"])
+	assert.Equal(t, oidc.ExecCredentialAPIVersion, credential["apiVersion"])
+	assert.Equal(t, token.IDToken, credential["status"].(map[string]interface{})["token"])
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
+	"github.com/jenkins-x/jx/v2/pkg/tracing"
 	v1 "k8s.io/api/core/v1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
@@ -142,7 +143,10 @@ func WaitForPodNameToBeReady(client kubernetes.Interface, namespace string, name
 
 		return IsPodReady(pod), nil
 	}
-	return waitForPodSelector(client, namespace, options, timeout, condition)
+	_, span := tracing.StartSpan(context.Background(), "wait for pod "+name, tracing.String("k8s.namespace", namespace), tracing.String("k8s.pod", name))
+	err := waitForPodSelector(client, namespace, options, timeout, condition)
+	span.End(err)
+	return err
 }
 
 // WaitForPodNameToBeComplete waits for the pod to complete (succeed or fail) using the pod name
@@ -158,7 +162,10 @@ func WaitForPodNameToBeComplete(client kubernetes.Interface, namespace string, n
 
 		return IsPodCompleted(pod), nil
 	}
-	return waitForPodSelector(client, namespace, options, timeout, condition)
+	_, span := tracing.StartSpan(context.Background(), "wait for pod "+name+" to complete", tracing.String("k8s.namespace", namespace), tracing.String("k8s.pod", name))
+	err := waitForPodSelector(client, namespace, options, timeout, condition)
+	span.End(err)
+	return err
 }
 
 func GetPodNames(client kubernetes.Interface, ns string, filter string) ([]string, error) {
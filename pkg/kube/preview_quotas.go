@@ -0,0 +1,115 @@
+package kube
+
+import (
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// ConfigMapPreviewQuotas the ConfigMap in the dev namespace containing the ResourceQuota and LimitRange applied
+	// to every preview namespace. It can be added to the templates of the environment repository when using GitOps
+	ConfigMapPreviewQuotas = "jenkins-x-preview-quotas"
+	// PreviewResourceQuotaKey the key of the ResourceQuota spec in the preview quotas ConfigMap
+	PreviewResourceQuotaKey = "resourceQuota"
+	// PreviewLimitRangeKey the key of the LimitRange spec in the preview quotas ConfigMap
+	PreviewLimitRangeKey = "limitRange"
+	// PreviewQuotaName the name of the ResourceQuota and LimitRange created in the preview namespaces
+	PreviewQuotaName = "jx-preview"
+)
+
+// PreviewQuotas the ResourceQuota and LimitRange applied to every preview namespace
+type PreviewQuotas struct {
+	ResourceQuota *corev1.ResourceQuotaSpec
+	LimitRange    *corev1.LimitRangeSpec
+}
+
+// LoadPreviewQuotas loads the quotas of the preview namespaces from the ConfigMap in the dev namespace, returning
+// empty quotas if there is no ConfigMap
+func LoadPreviewQuotas(kubeClient kubernetes.Interface, devNs string) (*PreviewQuotas, error) {
+	answer := &PreviewQuotas{}
+	cm, err := kubeClient.CoreV1().ConfigMaps(devNs).Get(ConfigMapPreviewQuotas, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return answer, nil
+		}
+		return answer, errors.Wrapf(err, "getting ConfigMap %s in namespace %s", ConfigMapPreviewQuotas, devNs)
+	}
+	if text := cm.Data[PreviewResourceQuotaKey]; text != "" {
+		answer.ResourceQuota = &corev1.ResourceQuotaSpec{}
+		err = yaml.Unmarshal([]byte(text), answer.ResourceQuota)
+		if err != nil {
+			return answer, errors.Wrapf(err, "unmarshalling the %s key of ConfigMap %s", PreviewResourceQuotaKey, ConfigMapPreviewQuotas)
+		}
+	}
+	if text := cm.Data[PreviewLimitRangeKey]; text != "" {
+		answer.LimitRange = &corev1.LimitRangeSpec{}
+		err = yaml.Unmarshal([]byte(text), answer.LimitRange)
+		if err != nil {
+			return answer, errors.Wrapf(err, "unmarshalling the %s key of ConfigMap %s", PreviewLimitRangeKey, ConfigMapPreviewQuotas)
+		}
+	}
+	return answer, nil
+}
+
+// EnsurePreviewQuotas creates or updates the ResourceQuota and LimitRange configured in the dev namespace in the
+// preview namespace, deleting the ones created previously which are no longer configured
+func EnsurePreviewQuotas(kubeClient kubernetes.Interface, devNs string, ns string) error {
+	quotas, err := LoadPreviewQuotas(kubeClient, devNs)
+	if err != nil {
+		return err
+	}
+	labels := map[string]string{LabelCreatedBy: ValueCreatedByJX}
+
+	resourceQuotas := kubeClient.CoreV1().ResourceQuotas(ns)
+	existingQuota, getErr := resourceQuotas.Get(PreviewQuotaName, metav1.GetOptions{})
+	if getErr != nil && !apierrors.IsNotFound(getErr) {
+		return errors.Wrapf(getErr, "getting ResourceQuota %s in namespace %s", PreviewQuotaName, ns)
+	}
+	found := getErr == nil
+	switch {
+	case quotas.ResourceQuota == nil && found:
+		err = resourceQuotas.Delete(PreviewQuotaName, nil)
+	case quotas.ResourceQuota != nil && found:
+		existingQuota.Spec = *quotas.ResourceQuota
+		_, err = resourceQuotas.Update(existingQuota)
+	case quotas.ResourceQuota != nil:
+		_, err = resourceQuotas.Create(&corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: PreviewQuotaName, Namespace: ns, Labels: labels},
+			Spec:       *quotas.ResourceQuota,
+		})
+		log.Logger().Infof("Limiting the resources of preview namespace %s with ResourceQuota %s", util.ColorInfo(ns), util.ColorInfo(PreviewQuotaName))
+	}
+	if err != nil {
+		return errors.Wrapf(err, "applying ResourceQuota %s in namespace %s", PreviewQuotaName, ns)
+	}
+
+	limitRanges := kubeClient.CoreV1().LimitRanges(ns)
+	existingLimits, getErr := limitRanges.Get(PreviewQuotaName, metav1.GetOptions{})
+	if getErr != nil && !apierrors.IsNotFound(getErr) {
+		return errors.Wrapf(getErr, "getting LimitRange %s in namespace %s", PreviewQuotaName, ns)
+	}
+	found = getErr == nil
+	switch {
+	case quotas.LimitRange == nil && found:
+		err = limitRanges.Delete(PreviewQuotaName, nil)
+	case quotas.LimitRange != nil && found:
+		existingLimits.Spec = *quotas.LimitRange
+		_, err = limitRanges.Update(existingLimits)
+	case quotas.LimitRange != nil:
+		_, err = limitRanges.Create(&corev1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{Name: PreviewQuotaName, Namespace: ns, Labels: labels},
+			Spec:       *quotas.LimitRange,
+		})
+		log.Logger().Infof("Limiting the containers of preview namespace %s with LimitRange %s", util.ColorInfo(ns), util.ColorInfo(PreviewQuotaName))
+	}
+	if err != nil {
+		return errors.Wrapf(err, "applying LimitRange %s in namespace %s", PreviewQuotaName, ns)
+	}
+	return nil
+}
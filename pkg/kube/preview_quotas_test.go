@@ -0,0 +1,88 @@
+// +build unit
+
+package kube_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestEnsurePreviewQuotas(t *testing.T) {
+	t.Parallel()
+
+	devNs := "jx"
+	previewNs := "jx-myorg-myapp-pr-1"
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: kube.ConfigMapPreviewQuotas, Namespace: devNs},
+		Data: map[string]string{
+			kube.PreviewResourceQuotaKey: `
+hard:
+  requests.cpu: "2"
+  requests.memory: 4Gi
+  pods: "10"
+`,
+			kube.PreviewLimitRangeKey: `
+limits:
+- type: Container
+  default:
+    cpu: 500m
+    memory: 512Mi
+  defaultRequest:
+    cpu: 100m
+    memory: 128Mi
+`,
+		},
+	}
+	kubeClient := fake.NewSimpleClientset(cm)
+
+	err := kube.EnsurePreviewQuotas(kubeClient, devNs, previewNs)
+	require.NoError(t, err)
+
+	quota, err := kubeClient.CoreV1().ResourceQuotas(previewNs).Get(kube.PreviewQuotaName, metav1.GetOptions{})
+	require.NoError(t, err)
+	pods := quota.Spec.Hard[corev1.ResourcePods]
+	assert.Equal(t, "10", pods.String())
+	memory := quota.Spec.Hard[corev1.ResourceRequestsMemory]
+	assert.Equal(t, "4Gi", memory.String())
+
+	limits, err := kubeClient.CoreV1().LimitRanges(previewNs).Get(kube.PreviewQuotaName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, limits.Spec.Limits, 1)
+	assert.Equal(t, corev1.LimitTypeContainer, limits.Spec.Limits[0].Type)
+	cpu := limits.Spec.Limits[0].Default[corev1.ResourceCPU]
+	assert.Equal(t, resource.MustParse("500m"), cpu)
+
+	// updating the ConfigMap updates the quota and removes the limits on the next preview
+	cm.Data = map[string]string{kube.PreviewResourceQuotaKey: "hard:\n  pods: \"5\"\n"}
+	_, err = kubeClient.CoreV1().ConfigMaps(devNs).Update(cm)
+	require.NoError(t, err)
+	err = kube.EnsurePreviewQuotas(kubeClient, devNs, previewNs)
+	require.NoError(t, err)
+
+	quota, err = kubeClient.CoreV1().ResourceQuotas(previewNs).Get(kube.PreviewQuotaName, metav1.GetOptions{})
+	require.NoError(t, err)
+	pods = quota.Spec.Hard[corev1.ResourcePods]
+	assert.Equal(t, "5", pods.String())
+	_, err = kubeClient.CoreV1().LimitRanges(previewNs).Get(kube.PreviewQuotaName, metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err), "the LimitRange should be deleted")
+}
+
+func TestEnsurePreviewQuotasWithoutConfigMap(t *testing.T) {
+	t.Parallel()
+
+	kubeClient := fake.NewSimpleClientset()
+	err := kube.EnsurePreviewQuotas(kubeClient, "jx", "jx-myorg-myapp-pr-1")
+	require.NoError(t, err)
+
+	quotas, err := kubeClient.CoreV1().ResourceQuotas("jx-myorg-myapp-pr-1").List(metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, quotas.Items)
+}
@@ -0,0 +1,72 @@
+package kube
+
+import (
+	"sort"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx-api/pkg/client/clientset/versioned"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DefaultProtectedNamespaces the system namespaces which are always protected from destructive commands
+var DefaultProtectedNamespaces = []string{"kube-system", "kube-public", "kube-node-lease"}
+
+// IsProductionEnvironment returns true if the environment is a permanent environment which is only promoted to manually
+func IsProductionEnvironment(env *v1.Environment) bool {
+	return env.Spec.Kind == v1.EnvironmentKindTypePermanent && env.Spec.PromotionStrategy == v1.PromotionStrategyTypeManual
+}
+
+// GetProtectedNamespaces returns the sorted list of namespaces that destructive commands should refuse to touch.
+// This is the default system namespaces, the namespaces of any production environments in the dev namespace and
+// any namespace labelled with LabelProtected=true
+func GetProtectedNamespaces(kubeClient kubernetes.Interface, jxClient versioned.Interface, devNs string) ([]string, error) {
+	namespaces := map[string]bool{}
+	for _, ns := range DefaultProtectedNamespaces {
+		namespaces[ns] = true
+	}
+	if jxClient != nil && devNs != "" {
+		envList, err := jxClient.JenkinsV1().Environments(devNs).List(metav1.ListOptions{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing environments in namespace %s", devNs)
+		}
+		for i := range envList.Items {
+			env := &envList.Items[i]
+			if IsProductionEnvironment(env) && env.Spec.Namespace != "" && env.Spec.Cluster == "" {
+				namespaces[env.Spec.Namespace] = true
+			}
+		}
+	}
+	if kubeClient != nil {
+		nsList, err := kubeClient.CoreV1().Namespaces().List(metav1.ListOptions{
+			LabelSelector: LabelProtected + "=true",
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "listing protected namespaces")
+		}
+		for _, ns := range nsList.Items {
+			namespaces[ns.Name] = true
+		}
+	}
+	answer := []string{}
+	for ns := range namespaces {
+		answer = append(answer, ns)
+	}
+	sort.Strings(answer)
+	return answer, nil
+}
+
+// FilterProtectedNamespaces returns the namespaces which are contained in the protected list
+func FilterProtectedNamespaces(protected []string, namespaces ...string) []string {
+	answer := []string{}
+	for _, ns := range namespaces {
+		for _, p := range protected {
+			if ns == p {
+				answer = append(answer, ns)
+				break
+			}
+		}
+	}
+	return answer
+}
@@ -0,0 +1,59 @@
+// +build unit
+
+package kube_test
+
+import (
+	"testing"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	jxfake "github.com/jenkins-x/jx-api/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetProtectedNamespaces(t *testing.T) {
+	t.Parallel()
+
+	kubeClient := kubefake.NewSimpleClientset(
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "payments",
+				Labels: map[string]string{kube.LabelProtected: "true"},
+			},
+		},
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "scratch",
+			},
+		},
+	)
+	jxClient := jxfake.NewSimpleClientset(
+		&v1.Environment{
+			ObjectMeta: metav1.ObjectMeta{Name: "staging", Namespace: "jx"},
+			Spec: v1.EnvironmentSpec{
+				Namespace:         "jx-staging",
+				Kind:              v1.EnvironmentKindTypePermanent,
+				PromotionStrategy: v1.PromotionStrategyTypeAutomatic,
+			},
+		},
+		&v1.Environment{
+			ObjectMeta: metav1.ObjectMeta{Name: "production", Namespace: "jx"},
+			Spec: v1.EnvironmentSpec{
+				Namespace:         "jx-production",
+				Kind:              v1.EnvironmentKindTypePermanent,
+				PromotionStrategy: v1.PromotionStrategyTypeManual,
+			},
+		},
+	)
+
+	protected, err := kube.GetProtectedNamespaces(kubeClient, jxClient, "jx")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"jx-production", "kube-node-lease", "kube-public", "kube-system", "payments"}, protected)
+
+	assert.Equal(t, []string{"kube-system", "jx-production"}, kube.FilterProtectedNamespaces(protected, "jx", "kube-system", "jx-staging", "jx-production"))
+	assert.Empty(t, kube.FilterProtectedNamespaces(protected, "scratch"))
+}
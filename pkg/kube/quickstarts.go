@@ -3,12 +3,21 @@ package kube
 import (
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 
+	"github.com/ghodss/yaml"
 	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx-api/pkg/client/clientset/versioned"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/quickstarts"
+	"github.com/pkg/errors"
 )
 
+// AnnotationQuickstartCatalogs the annotation of the dev Environment containing the YAML list of the quickstart
+// catalogs registered by the team
+const AnnotationQuickstartCatalogs = "jenkins.io/quickstart-catalogs"
+
 var (
 	DefaultQuickstartLocations = []v1.QuickStartLocation{
 		{
@@ -45,3 +54,46 @@ func IsDefaultQuickstartLocation(location v1.QuickStartLocation) bool {
 	}
 	return false
 }
+
+// GetQuickstartCatalogs returns the quickstart catalogs registered by the team sorted by name
+func GetQuickstartCatalogs(devEnv *v1.Environment) ([]quickstarts.QuickstartCatalog, error) {
+	var answer []quickstarts.QuickstartCatalog
+	if devEnv == nil {
+		return answer, nil
+	}
+	text := strings.TrimSpace(devEnv.Annotations[AnnotationQuickstartCatalogs])
+	if text == "" {
+		return answer, nil
+	}
+	err := yaml.Unmarshal([]byte(text), &answer)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshalling the %s annotation of environment %s", AnnotationQuickstartCatalogs, devEnv.Name)
+	}
+	return answer, nil
+}
+
+// SetQuickstartCatalogs sets the quickstart catalogs registered by the team
+func SetQuickstartCatalogs(devEnv *v1.Environment, catalogs []quickstarts.QuickstartCatalog) error {
+	if len(catalogs) == 0 {
+		delete(devEnv.Annotations, AnnotationQuickstartCatalogs)
+		return nil
+	}
+	for i := range catalogs {
+		err := catalogs[i].Validate()
+		if err != nil {
+			return err
+		}
+	}
+	sort.Slice(catalogs, func(i, j int) bool {
+		return catalogs[i].Name < catalogs[j].Name
+	})
+	data, err := yaml.Marshal(catalogs)
+	if err != nil {
+		return errors.Wrap(err, "marshalling the quickstart catalogs")
+	}
+	if devEnv.Annotations == nil {
+		devEnv.Annotations = map[string]string{}
+	}
+	devEnv.Annotations[AnnotationQuickstartCatalogs] = string(data)
+	return nil
+}
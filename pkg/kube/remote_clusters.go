@@ -0,0 +1,157 @@
+package kube
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	// ValueKindClusterKubeConfig a Secret containing the kube config of a remote cluster environments are deployed to
+	ValueKindClusterKubeConfig = "cluster-kubeconfig"
+
+	// LabelClusterName the name of the remote cluster of a kube config Secret
+	LabelClusterName = "jenkins.io/cluster"
+
+	// ClusterKubeConfigKey the key of the kube config in the Secret of a remote cluster
+	ClusterKubeConfigKey = "kubeconfig"
+)
+
+// RemoteCluster a cluster other than the development cluster which environments are deployed to
+type RemoteCluster struct {
+	Name       string
+	KubeConfig []byte
+	KubeClient kubernetes.Interface
+}
+
+// ClusterKubeConfigSecretName returns the name of the Secret in the dev namespace containing the kube config of the
+// cluster
+func ClusterKubeConfigSecretName(cluster string) string {
+	return naming.ToValidNameTruncated("jx-cluster-"+cluster, 63)
+}
+
+// SaveClusterKubeConfig creates or updates the Secret in the dev namespace containing the kube config of the cluster
+func SaveClusterKubeConfig(kubeClient kubernetes.Interface, devNs string, cluster string, kubeConfig []byte) error {
+	_, err := clientcmd.Load(kubeConfig)
+	if err != nil {
+		return errors.Wrapf(err, "parsing the kube config of cluster %s", cluster)
+	}
+	name := ClusterKubeConfigSecretName(cluster)
+	defaultSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+	}
+	_, err = DefaultModifySecret(kubeClient, devNs, name, func(secret *corev1.Secret) error {
+		if secret.Labels == nil {
+			secret.Labels = map[string]string{}
+		}
+		secret.Labels[LabelKind] = ValueKindClusterKubeConfig
+		secret.Labels[LabelClusterName] = naming.ToValidValue(cluster)
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		secret.Data[ClusterKubeConfigKey] = kubeConfig
+		return nil
+	}, defaultSecret)
+	if err != nil {
+		return errors.Wrapf(err, "saving the kube config of cluster %s", cluster)
+	}
+	return nil
+}
+
+// LoadRemoteCluster loads the cluster from its kube config Secret in the dev namespace, returning nil if there is
+// no kube config stored for the cluster
+func LoadRemoteCluster(kubeClient kubernetes.Interface, devNs string, cluster string) (*RemoteCluster, error) {
+	name := ClusterKubeConfigSecretName(cluster)
+	secret, err := kubeClient.CoreV1().Secrets(devNs).Get(name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "getting Secret %s in namespace %s", name, devNs)
+	}
+	kubeConfig := secret.Data[ClusterKubeConfigKey]
+	if len(kubeConfig) == 0 {
+		return nil, errors.Errorf("the Secret %s of cluster %s has no %s key", name, cluster, ClusterKubeConfigKey)
+	}
+	config, err := clientcmd.RESTConfigFromKubeConfig(kubeConfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the kube config of cluster %s", cluster)
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating the kube client of cluster %s", cluster)
+	}
+	return &RemoteCluster{
+		Name:       cluster,
+		KubeConfig: kubeConfig,
+		KubeClient: client,
+	}, nil
+}
+
+// EnvironmentCluster returns the remote cluster of the environment after verifying it can be reached or nil if the
+// environment is deployed to the current cluster. Environments whose cluster has no kube config stored in the dev
+// namespace are assumed to be in the current cluster
+func EnvironmentCluster(kubeClient kubernetes.Interface, devNs string, env *v1.Environment) (*RemoteCluster, error) {
+	if env == nil || env.Spec.Cluster == "" {
+		return nil, nil
+	}
+	cluster, err := LoadRemoteCluster(kubeClient, devNs, env.Spec.Cluster)
+	if err != nil || cluster == nil {
+		return nil, err
+	}
+	err = cluster.Verify()
+	if err != nil {
+		return nil, errors.Wrapf(err, "verifying the cluster of Environment %s", env.Name)
+	}
+	return cluster, nil
+}
+
+// Verify verifies the cluster can be reached with its kube config
+func (c *RemoteCluster) Verify() error {
+	_, err := c.KubeClient.Discovery().ServerVersion()
+	if err != nil {
+		return errors.Wrapf(err, "connecting to cluster %s", c.Name)
+	}
+	return nil
+}
+
+// Run runs the function with the KUBECONFIG environment variable pointing at the kube config of the cluster so that
+// the helm and kubectl commands it runs apply to the cluster
+func (c *RemoteCluster) Run(fn func() error) error {
+	dir, err := ioutil.TempDir("", "jx-cluster-")
+	if err != nil {
+		return errors.Wrap(err, "creating a temporary directory for the kube config")
+	}
+	defer os.RemoveAll(dir) //nolint:errcheck
+
+	file := filepath.Join(dir, "config")
+	err = ioutil.WriteFile(file, c.KubeConfig, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "writing the kube config of cluster %s", c.Name)
+	}
+
+	old, found := os.LookupEnv("KUBECONFIG")
+	err = os.Setenv("KUBECONFIG", file)
+	if err != nil {
+		return errors.Wrap(err, "setting $KUBECONFIG")
+	}
+	defer func() {
+		if found {
+			os.Setenv("KUBECONFIG", old) //nolint:errcheck
+		} else {
+			os.Unsetenv("KUBECONFIG") //nolint:errcheck
+		}
+	}()
+	return fn()
+}
@@ -0,0 +1,77 @@
+// +build unit
+
+package kube_test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const prodKubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://prod.example.com
+  name: prod
+contexts:
+- context:
+    cluster: prod
+    user: jx
+  name: prod
+current-context: prod
+users:
+- name: jx
+  user:
+    token: abc
+`
+
+func TestRemoteClusters(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	ns := "jx"
+
+	cluster, err := kube.LoadRemoteCluster(kubeClient, ns, "prod-cluster")
+	require.NoError(t, err)
+	assert.Nil(t, cluster, "clusters without a kube config should be the current cluster")
+
+	err = kube.SaveClusterKubeConfig(kubeClient, ns, "prod-cluster", []byte("not: [a kube config"))
+	assert.Error(t, err)
+
+	err = kube.SaveClusterKubeConfig(kubeClient, ns, "prod-cluster", []byte(prodKubeConfig))
+	require.NoError(t, err)
+	secret, err := kubeClient.CoreV1().Secrets(ns).Get(kube.ClusterKubeConfigSecretName("prod-cluster"), metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, kube.ValueKindClusterKubeConfig, secret.Labels[kube.LabelKind])
+
+	cluster, err = kube.LoadRemoteCluster(kubeClient, ns, "prod-cluster")
+	require.NoError(t, err)
+	require.NotNil(t, cluster)
+	assert.Equal(t, "prod-cluster", cluster.Name)
+
+	// lets verify the connectivity against a fake cluster
+	cluster.KubeClient = fake.NewSimpleClientset()
+	require.NoError(t, cluster.Verify())
+
+	env, err := kube.EnvironmentCluster(kubeClient, ns, &v1.Environment{ObjectMeta: metav1.ObjectMeta{Name: "staging"}})
+	require.NoError(t, err)
+	assert.Nil(t, env, "environments without a cluster should be in the current cluster")
+
+	old, found := os.LookupEnv("KUBECONFIG")
+	err = cluster.Run(func() error {
+		data, err := ioutil.ReadFile(os.Getenv("KUBECONFIG"))
+		require.NoError(t, err)
+		assert.Equal(t, prodKubeConfig, string(data))
+		return nil
+	})
+	require.NoError(t, err)
+	restored, foundAfter := os.LookupEnv("KUBECONFIG")
+	assert.Equal(t, found, foundAfter)
+	assert.Equal(t, old, restored, "the KUBECONFIG should be restored")
+}
@@ -1,6 +1,7 @@
 package resources
 
 import (
+	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 )
 
@@ -22,7 +23,7 @@ type KubeCtlInstaller struct {
 
 // NewKubeCtlInstaller creates a new kubectl installer
 func NewKubeCtlInstaller(cwd string, wait, validate bool) *KubeCtlInstaller {
-	args := []string{"apply"}
+	args := append([]string{"apply"}, kube.ServerSideApplyArgs()...)
 	if wait {
 		args = append(args, "--wait")
 	}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// PortForward forwards a local port to a pod of a service until it is closed
+type PortForward struct {
+	// Pod the name of the pod the port is forwarded to
+	Pod string
+	// LocalPort the local port which is forwarded
+	LocalPort int
+	// URL the local URL of the service
+	URL string
+
+	stop chan struct{}
+	done chan error
+}
+
+// Done returns the channel receiving the error which stopped the port forwarding, which is nil once it is closed
+func (p *PortForward) Done() <-chan error {
+	return p.done
+}
+
+// Close stops the port forwarding
+func (p *PortForward) Close() {
+	select {
+	case <-p.stop:
+	default:
+		close(p.stop)
+	}
+}
+
+// ForwardService forwards the local port to the port of a ready pod of the service which would be used by the URL
+// of the service, preferring https. If localPort is zero a free local port is chosen. The output of the forwarding
+// is written to out and errOut
+func ForwardService(config *rest.Config, client kubernetes.Interface, ns string, name string, localPort int, out io.Writer, errOut io.Writer) (*PortForward, error) {
+	svc, err := client.CoreV1().Services(ns).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting service %s in namespace %s", name, ns)
+	}
+	pod, err := ServiceReadyPod(client, svc)
+	if err != nil {
+		return nil, err
+	}
+	scheme, port, err := ServiceTargetPort(svc, pod)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating the port forwarding transport")
+	}
+	req := client.CoreV1().RESTClient().Post().Resource("pods").Namespace(ns).Name(pod.Name).SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+
+	answer := &PortForward{
+		Pod:  pod.Name,
+		stop: make(chan struct{}),
+		done: make(chan error, 1),
+	}
+	ready := make(chan struct{})
+	ports := []string{fmt.Sprintf("%d:%d", localPort, port)}
+	forwarder, err := portforward.New(dialer, ports, answer.stop, ready, out, errOut)
+	if err != nil {
+		return nil, errors.Wrapf(err, "forwarding to pod %s of service %s", pod.Name, name)
+	}
+	go func() {
+		answer.done <- forwarder.ForwardPorts()
+	}()
+
+	select {
+	case err = <-answer.done:
+		if err == nil {
+			err = errors.New("the port forwarding stopped")
+		}
+		return nil, errors.Wrapf(err, "forwarding to pod %s of service %s", pod.Name, name)
+	case <-ready:
+	}
+	forwarded, err := forwarder.GetPorts()
+	if err != nil || len(forwarded) == 0 {
+		answer.Close()
+		return nil, errors.Wrapf(err, "getting the local port forwarded to pod %s of service %s", pod.Name, name)
+	}
+	answer.LocalPort = int(forwarded[0].Local)
+	answer.URL = fmt.Sprintf("%s://localhost:%d", scheme, answer.LocalPort)
+	return answer, nil
+}
+
+// ServiceReadyPod returns a ready pod selected by the service, preferring the oldest one
+func ServiceReadyPod(client kubernetes.Interface, svc *v1.Service) (*v1.Pod, error) {
+	if len(svc.Spec.Selector) == 0 {
+		return nil, errors.Errorf("service %s has no selector so there are no pods to forward to", svc.Name)
+	}
+	selector := labels.SelectorFromSet(svc.Spec.Selector).String()
+	pods, err := client.CoreV1().Pods(svc.Namespace).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the pods of service %s in namespace %s", svc.Name, svc.Namespace)
+	}
+	items := pods.Items
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreationTimestamp.Before(&items[j].CreationTimestamp)
+	})
+	for i := range items {
+		pod := &items[i]
+		if pod.DeletionTimestamp == nil && pod.Status.Phase == v1.PodRunning && isPodReady(pod) {
+			return pod, nil
+		}
+	}
+	return nil, errors.Errorf("service %s in namespace %s has no ready pods", svc.Name, svc.Namespace)
+}
+
+// ServiceTargetPort returns the scheme and the container port of the pod which the port of the URL of the service
+// targets, resolving named target ports via the ports of the containers of the pod
+func ServiceTargetPort(svc *v1.Service, pod *v1.Pod) (string, int, error) {
+	if len(svc.Spec.Ports) == 0 {
+		return "", 0, errors.Errorf("service %s has no ports", svc.Name)
+	}
+	scheme, portText, _ := ExtractServiceSchemePort(svc)
+	if scheme == "" {
+		scheme = "http"
+	}
+	servicePort := svc.Spec.Ports[0]
+	for _, p := range svc.Spec.Ports {
+		if strconv.Itoa(int(p.Port)) == portText {
+			servicePort = p
+			break
+		}
+	}
+	target := servicePort.TargetPort
+	if target.Type == intstr.Int {
+		if target.IntVal == 0 {
+			return scheme, int(servicePort.Port), nil
+		}
+		return scheme, int(target.IntVal), nil
+	}
+	for _, c := range pod.Spec.Containers {
+		for _, p := range c.Ports {
+			if p.Name == target.StrVal {
+				return scheme, int(p.ContainerPort), nil
+			}
+		}
+	}
+	return "", 0, errors.Errorf("pod %s has no container port named %s targeted by service %s", pod.Name, target.StrVal, svc.Name)
+}
+
+func isPodReady(pod *v1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == v1.PodReady {
+			return c.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+// +build unit
+
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/kube/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func podOfService(name string, created time.Time, ready bool) *v1.Pod {
+	status := v1.ConditionFalse
+	if ready {
+		status = v1.ConditionTrue
+	}
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "jx",
+			Labels:            map[string]string{"app": "nexus"},
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{Name: "nexus", Ports: []v1.ContainerPort{{Name: "web", ContainerPort: 8081}}},
+			},
+		},
+		Status: v1.PodStatus{
+			Phase:      v1.PodRunning,
+			Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: status}},
+		},
+	}
+}
+
+func TestServiceReadyPod(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "nexus", Namespace: "jx"},
+		Spec:       v1.ServiceSpec{Selector: map[string]string{"app": "nexus"}},
+	}
+	kubeClient := fake.NewSimpleClientset(
+		podOfService("nexus-unready", now.Add(-2*time.Hour), false),
+		podOfService("nexus-new", now, true),
+		podOfService("nexus-old", now.Add(-time.Hour), true),
+	)
+
+	pod, err := services.ServiceReadyPod(kubeClient, svc)
+	require.NoError(t, err)
+	assert.Equal(t, "nexus-old", pod.Name)
+
+	svc.Spec.Selector = map[string]string{"app": "other"}
+	_, err = services.ServiceReadyPod(kubeClient, svc)
+	assert.Error(t, err)
+
+	svc.Spec.Selector = nil
+	_, err = services.ServiceReadyPod(kubeClient, svc)
+	assert.Error(t, err)
+}
+
+func TestServiceTargetPort(t *testing.T) {
+	t.Parallel()
+
+	pod := podOfService("nexus", time.Now(), true)
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "nexus", Namespace: "jx"},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{Name: "metrics", Port: 9090, TargetPort: intstr.FromInt(9090)},
+				{Name: "http", Port: 80, TargetPort: intstr.FromString("web")},
+			},
+		},
+	}
+	scheme, port, err := services.ServiceTargetPort(svc, pod)
+	require.NoError(t, err)
+	assert.Equal(t, "http", scheme)
+	assert.Equal(t, 8081, port)
+
+	svc.Spec.Ports = []v1.ServicePort{{Name: "https", Port: 443, TargetPort: intstr.FromInt(8443)}}
+	scheme, port, err = services.ServiceTargetPort(svc, pod)
+	require.NoError(t, err)
+	assert.Equal(t, "https", scheme)
+	assert.Equal(t, 8443, port)
+
+	svc.Spec.Ports = []v1.ServicePort{{Port: 8080}}
+	scheme, port, err = services.ServiceTargetPort(svc, pod)
+	require.NoError(t, err)
+	assert.Equal(t, "http", scheme)
+	assert.Equal(t, 8080, port, "the service port is targeted if there is no target port")
+
+	svc.Spec.Ports = []v1.ServicePort{{Name: "http", Port: 80, TargetPort: intstr.FromString("missing")}}
+	_, _, err = services.ServiceTargetPort(svc, pod)
+	assert.Error(t, err)
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultProbeTimeout the default timeout of the HTTP request probing a URL
+	DefaultProbeTimeout = 5 * time.Second
+	// DefaultProbeConcurrency the default number of URLs probed concurrently
+	DefaultProbeConcurrency = 10
+)
+
+// URLProbe the result of probing a URL with an HTTP GET request
+type URLProbe struct {
+	URL string `json:"url"`
+	// StatusCode the HTTP status code of the response or zero if there was no response
+	StatusCode int `json:"statusCode,omitempty"`
+	// Healthy true if the URL responded with a status code below 400 and any TLS certificate has not expired
+	Healthy bool `json:"healthy"`
+	// Error why the probe failed
+	Error string `json:"error,omitempty"`
+	// Latency the time taken to receive the response
+	Latency time.Duration `json:"latency,omitempty"`
+	// CertificateExpiry when the TLS certificate of an https URL expires
+	CertificateExpiry *time.Time `json:"certificateExpiry,omitempty"`
+}
+
+// NewProbeClient creates the HTTP client used to probe URLs. The TLS certificates are not verified so that the
+// expiry of self signed and expired certificates is still reported
+func NewProbeClient(timeout time.Duration) *http.Client {
+	if timeout <= 0 {
+		timeout = DefaultProbeTimeout
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // #nosec
+		},
+	}
+}
+
+// ProbeURL probes the URL with an HTTP GET request
+func ProbeURL(client *http.Client, url string) URLProbe {
+	answer := URLProbe{URL: url}
+	start := time.Now()
+	resp, err := client.Get(url)
+	if err != nil {
+		answer.Error = err.Error()
+		return answer
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	answer.Latency = time.Since(start).Round(time.Millisecond)
+	answer.StatusCode = resp.StatusCode
+	answer.Healthy = resp.StatusCode < http.StatusBadRequest
+	if resp.StatusCode >= http.StatusBadRequest {
+		answer.Error = resp.Status
+	}
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		expiry := resp.TLS.PeerCertificates[0].NotAfter
+		answer.CertificateExpiry = &expiry
+		if expiry.Before(time.Now()) {
+			answer.Healthy = false
+			answer.Error = "the TLS certificate expired"
+		}
+	}
+	return answer
+}
+
+// ProbeURLs probes the URLs concurrently, each request timing out after the timeout, and returns the results in the
+// order of the URLs
+func ProbeURLs(urls []string, timeout time.Duration, concurrency int) []URLProbe {
+	if concurrency <= 0 {
+		concurrency = DefaultProbeConcurrency
+	}
+	client := NewProbeClient(timeout)
+	answer := make([]URLProbe, len(urls))
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, url string) {
+			defer wg.Done()
+			answer[i] = ProbeURL(client, url)
+			<-slots
+		}(i, url)
+	}
+	wg.Wait()
+	return answer
+}
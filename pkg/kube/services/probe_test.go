@@ -0,0 +1,49 @@
+// +build unit
+
+package services_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/kube/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbeURLs(t *testing.T) {
+	t.Parallel()
+
+	healthy := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthy.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Second)
+	}))
+	defer slow.Close()
+
+	probes := services.ProbeURLs([]string{healthy.URL, failing.URL, slow.URL}, 500*time.Millisecond, 2)
+	require.Len(t, probes, 3)
+
+	assert.Equal(t, healthy.URL, probes[0].URL)
+	assert.True(t, probes[0].Healthy)
+	assert.Equal(t, http.StatusOK, probes[0].StatusCode)
+	require.NotNil(t, probes[0].CertificateExpiry, "the expiry of the TLS certificate should be reported")
+	assert.True(t, probes[0].CertificateExpiry.After(time.Now()))
+
+	assert.False(t, probes[1].Healthy)
+	assert.Equal(t, http.StatusServiceUnavailable, probes[1].StatusCode)
+	assert.Equal(t, "503 Service Unavailable", probes[1].Error)
+	assert.Nil(t, probes[1].CertificateExpiry)
+
+	assert.False(t, probes[2].Healthy)
+	assert.Equal(t, 0, probes[2].StatusCode)
+	assert.NotEmpty(t, probes[2].Error, "the probe should time out")
+}
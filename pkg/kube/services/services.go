@@ -11,15 +11,18 @@ import (
 	"github.com/jenkins-x/jx-logging/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 
+	"github.com/jenkins-x/jx/v2/pkg/tracing"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/api/extensions/v1beta1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
 	tools_watch "k8s.io/client-go/tools/watch"
 )
 
@@ -292,32 +295,35 @@ func FindServiceURLs(client kubernetes.Interface, namespace string) ([]ServiceUR
 	return urls, nil
 }
 
-// WaitForExternalIP waits for the pods of a deployment to become ready
+// WaitForExternalIP waits for the service to be given an external address such as the load balancer address
 func WaitForExternalIP(client kubernetes.Interface, name, namespace string, timeout time.Duration) error {
+	_, span := tracing.StartSpan(context.Background(), "wait for external address of service "+name, tracing.String("k8s.namespace", namespace), tracing.String("k8s.service", name))
+	err := waitForExternalIP(client, name, namespace, timeout)
+	span.End(err)
+	return err
+}
 
-	options := meta_v1.ListOptions{
-		FieldSelector: fields.OneTermEqualSelector("metadata.name", name).String(),
-	}
-
-	w, err := client.CoreV1().Services(namespace).Watch(options)
-
-	if err != nil {
-		return err
-	}
-	defer w.Stop()
-
-	condition := func(event watch.Event) (bool, error) {
-		svc := event.Object.(*v1.Service)
-		return HasExternalAddress(svc), nil
+func waitForExternalIP(client kubernetes.Interface, name, namespace string, timeout time.Duration) error {
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", name).String()
+	lw := &cache.ListWatch{
+		ListFunc: func(options meta_v1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			return client.CoreV1().Services(namespace).List(options)
+		},
+		WatchFunc: func(options meta_v1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return client.CoreV1().Services(namespace).Watch(options)
+		},
 	}
-
-	ctx, _ := context.WithTimeout(context.Background(), timeout)
-	_, err = tools_watch.UntilWithoutRetry(ctx, w, condition)
-
+	err := kube.WaitUntilReady(lw, &v1.Service{}, timeout, func(obj runtime.Object) bool {
+		svc, ok := obj.(*v1.Service)
+		return ok && svc.Name == name && HasExternalAddress(svc)
+	})
 	if err == wait.ErrWaitTimeout {
-		return fmt.Errorf("service %s never became ready", name)
+		reasons, _ := kube.WarningEvents(client, namespace, "Service", name)
+		return kube.NotReadyError(fmt.Sprintf("the external address of service %s in namespace %s", name, namespace), timeout, reasons)
 	}
-	return nil
+	return err
 }
 
 // WaitForService waits for a service to become ready
@@ -4,11 +4,14 @@ package services_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/jenkins-x/jx/v2/pkg/kube/services"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestExtractServiceSchemePortDefault(t *testing.T) {
@@ -288,3 +291,40 @@ func TestExtractServiceSchemePortInconclusive(t *testing.T) {
 	assert.Equal(t, "", schema)
 	assert.Equal(t, "", port)
 }
+
+func TestWaitForExternalIP(t *testing.T) {
+	t.Parallel()
+
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nginx-ingress-controller",
+			Namespace: "kube-system",
+		},
+		Spec: v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer},
+	}
+	event := &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nginx-ingress-controller.1",
+			Namespace: "kube-system",
+		},
+		InvolvedObject: v1.ObjectReference{Kind: "Service", Name: "nginx-ingress-controller"},
+		Type:           v1.EventTypeWarning,
+		Reason:         "SyncLoadBalancerFailed",
+		Message:        "quota exceeded",
+	}
+	client := fake.NewSimpleClientset(svc, event)
+
+	err := services.WaitForExternalIP(client, svc.Name, svc.Namespace, time.Second)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Service nginx-ingress-controller: SyncLoadBalancerFailed: quota exceeded")
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		ready := svc.DeepCopy()
+		ready.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+		_, err := client.CoreV1().Services(svc.Namespace).UpdateStatus(ready)
+		require.NoError(t, err)
+	}()
+	err = services.WaitForExternalIP(client, svc.Name, svc.Namespace, 10*time.Second)
+	assert.NoError(t, err)
+}
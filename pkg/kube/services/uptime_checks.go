@@ -0,0 +1,79 @@
+package services
+
+import (
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// ProbeAnnotation the annotation which makes the Prometheus 'kubernetes-services' job probe the service via the
+	// blackbox exporter
+	ProbeAnnotation = "prometheus.io/probe"
+	// UptimeCheckLabel marks the services whose uptime checks were registered by jx so they can be deregistered
+	UptimeCheckLabel = "jenkins.io/uptime-check"
+	// AlertReceiverLabel the Alertmanager receiver the alerts of a failing uptime check are routed to. The Prometheus
+	// service discovery maps it to the 'jenkins_io_alert_receiver' label of the probe metrics
+	AlertReceiverLabel = "jenkins.io/alert-receiver"
+)
+
+// RegisterUptimeCheck annotates the first of the services with the given names which exists in the namespace so that
+// it is probed by the in-cluster blackbox exporter, labelling it with the receiver the alerts should be routed to.
+// Returns nil if none of the services exist
+func RegisterUptimeCheck(client kubernetes.Interface, ns string, names []string, alertReceiver string) (*v1.Service, error) {
+	for _, name := range names {
+		svc, err := client.CoreV1().Services(ns).Get(name, meta_v1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "getting service %s in namespace %s", name, ns)
+		}
+		if svc.Annotations == nil {
+			svc.Annotations = map[string]string{}
+		}
+		if svc.Labels == nil {
+			svc.Labels = map[string]string{}
+		}
+		svc.Annotations[ProbeAnnotation] = "true"
+		svc.Labels[UptimeCheckLabel] = "true"
+		if alertReceiver != "" {
+			svc.Labels[AlertReceiverLabel] = alertReceiver
+		} else {
+			delete(svc.Labels, AlertReceiverLabel)
+		}
+		svc, err = client.CoreV1().Services(ns).Update(svc)
+		if err != nil {
+			return nil, errors.Wrapf(err, "registering the uptime check of service %s in namespace %s", name, ns)
+		}
+		return svc, nil
+	}
+	return nil, nil
+}
+
+// DeregisterUptimeCheck removes the uptime checks registered by jx from the services with the given names in the
+// namespace, ignoring services which do not exist or whose checks were not registered by jx
+func DeregisterUptimeCheck(client kubernetes.Interface, ns string, names []string) error {
+	for _, name := range names {
+		svc, err := client.CoreV1().Services(ns).Get(name, meta_v1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return errors.Wrapf(err, "getting service %s in namespace %s", name, ns)
+		}
+		if svc.Labels[UptimeCheckLabel] != "true" {
+			continue
+		}
+		delete(svc.Annotations, ProbeAnnotation)
+		delete(svc.Labels, UptimeCheckLabel)
+		delete(svc.Labels, AlertReceiverLabel)
+		_, err = client.CoreV1().Services(ns).Update(svc)
+		if err != nil {
+			return errors.Wrapf(err, "deregistering the uptime check of service %s in namespace %s", name, ns)
+		}
+	}
+	return nil
+}
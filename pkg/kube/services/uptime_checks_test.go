@@ -0,0 +1,67 @@
+// +build unit
+
+package services_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/kube/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRegisterAndDeregisterUptimeCheck(t *testing.T) {
+	t.Parallel()
+
+	ns := "jx-production"
+	client := fake.NewSimpleClientset(
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "myapp",
+				Namespace:   ns,
+				Labels:      map[string]string{"app": "myapp"},
+				Annotations: map[string]string{services.ExposeAnnotation: "true"},
+			},
+		},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "other",
+				Namespace:   ns,
+				Annotations: map[string]string{services.ProbeAnnotation: "true"},
+			},
+		},
+	)
+
+	svc, err := services.RegisterUptimeCheck(client, ns, []string{"jx-production-myapp", "myapp"}, "oncall")
+	require.NoError(t, err)
+	require.NotNil(t, svc)
+	assert.Equal(t, "myapp", svc.Name)
+
+	svc, err = client.CoreV1().Services(ns).Get("myapp", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "true", svc.Annotations[services.ProbeAnnotation])
+	assert.Equal(t, "true", svc.Annotations[services.ExposeAnnotation], "existing annotations should be preserved")
+	assert.Equal(t, "oncall", svc.Labels[services.AlertReceiverLabel])
+	assert.Equal(t, "myapp", svc.Labels["app"])
+
+	svc, err = services.RegisterUptimeCheck(client, ns, []string{"missing"}, "")
+	require.NoError(t, err)
+	assert.Nil(t, svc)
+
+	err = services.DeregisterUptimeCheck(client, ns, []string{"myapp", "other", "missing"})
+	require.NoError(t, err)
+
+	svc, err = client.CoreV1().Services(ns).Get("myapp", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, svc.Annotations, services.ProbeAnnotation)
+	assert.NotContains(t, svc.Labels, services.UptimeCheckLabel)
+	assert.NotContains(t, svc.Labels, services.AlertReceiverLabel)
+	assert.Equal(t, "true", svc.Annotations[services.ExposeAnnotation])
+
+	svc, err = client.CoreV1().Services(ns).Get("other", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "true", svc.Annotations[services.ProbeAnnotation], "probes not registered by jx should be left alone")
+}
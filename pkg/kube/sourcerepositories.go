@@ -123,7 +123,7 @@ func GetOrCreateSourceRepositoryCallback(jxClient versioned.Interface, ns string
 	srCopy.Sanitize()
 
 	// If we don't need to update the found SourceRepository, return it.
-	if reflect.DeepEqual(srCopy.Spec, foundSr.Spec) && reflect.DeepEqual(srCopy.Labels, foundSr.Labels) {
+	if reflect.DeepEqual(srCopy.Spec, foundSr.Spec) && reflect.DeepEqual(srCopy.Labels, foundSr.Labels) && reflect.DeepEqual(srCopy.Annotations, foundSr.Annotations) {
 		return foundSr, nil
 	}
 
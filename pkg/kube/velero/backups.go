@@ -0,0 +1,286 @@
+package velero
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/ghodss/yaml"
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx-api/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/v2/pkg/cloud"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// PhaseCompleted the backup or restore completed without errors
+	PhaseCompleted = "Completed"
+	// PhasePartiallyFailed the backup or restore completed with errors
+	PhasePartiallyFailed = "PartiallyFailed"
+	// PhaseFailed the backup or restore failed
+	PhaseFailed = "Failed"
+
+	// LabelBackupKind the label of the backups and schedules created by jx
+	LabelBackupKind = "jenkins.io/backup"
+)
+
+var (
+	// ScheduleResource the resource of the Velero schedules
+	ScheduleResource = schema.GroupVersionResource{Group: "velero.io", Version: "v1", Resource: "schedules"}
+	// BackupResource the resource of the Velero backups
+	BackupResource = schema.GroupVersionResource{Group: "velero.io", Version: "v1", Resource: "backups"}
+	// RestoreResource the resource of the Velero restores
+	RestoreResource = schema.GroupVersionResource{Group: "velero.io", Version: "v1", Resource: "restores"}
+
+	// excludedResources the resources which are recreated by the cluster rather than restored
+	excludedResources = []string{"events", "events.events.k8s.io", "nodes", "pods"}
+
+	storageProviders = map[string]StorageProvider{
+		cloud.GKE: {Name: "gcp", Scheme: "gs", PluginImage: "velero/velero-plugin-for-gcp:v1.1.0"},
+		cloud.EKS: {Name: "aws", Scheme: "s3", PluginImage: "velero/velero-plugin-for-aws:v1.1.0"},
+		cloud.AWS: {Name: "aws", Scheme: "s3", PluginImage: "velero/velero-plugin-for-aws:v1.1.0"},
+		cloud.AKS: {Name: "azure", Scheme: "azblob", PluginImage: "velero/velero-plugin-for-microsoft-azure:v1.1.0"},
+	}
+)
+
+// StorageProvider the Velero object storage provider of a cloud
+type StorageProvider struct {
+	// Name the name of the provider in the Velero configuration
+	Name string
+	// Scheme the scheme of the URLs of the buckets of the cloud
+	Scheme string
+	// PluginImage the image of the Velero plugin of the cloud
+	PluginImage string
+}
+
+// Backup a Velero backup
+type Backup struct {
+	Name     string
+	Phase    string
+	Schedule string
+	Created  time.Time
+}
+
+// GetStorageProvider returns the Velero storage provider of the kubernetes provider
+func GetStorageProvider(kubeProvider string) (*StorageProvider, error) {
+	p, ok := storageProviders[kubeProvider]
+	if !ok {
+		return nil, fmt.Errorf("no Velero object storage for kubernetes provider %s", kubeProvider)
+	}
+	return &p, nil
+}
+
+// Bucket returns the name of the bucket of the backup storage URL such as gs://mybucket
+func (p *StorageProvider) Bucket(storageURL string) (string, error) {
+	u, err := url.Parse(storageURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing the backup storage URL %s", storageURL)
+	}
+	if u.Scheme != p.Scheme || u.Host == "" {
+		return "", fmt.Errorf("the backup storage URL %s is not a %s:// bucket", storageURL, p.Scheme)
+	}
+	return u.Host, nil
+}
+
+// ChartValues returns the values of the Velero chart storing the backups in the bucket. The credentials are read
+// from the secret if it is not empty, otherwise Velero uses the identity of its service account
+func (p *StorageProvider) ChartValues(bucket string, region string, resourceGroup string, secret string) ([]byte, error) {
+	config := map[string]interface{}{}
+	switch p.Name {
+	case "aws":
+		config["region"] = region
+	case "azure":
+		config["resourceGroup"] = resourceGroup
+	}
+	values := map[string]interface{}{
+		"configuration": map[string]interface{}{
+			"provider": p.Name,
+			"backupStorageLocation": map[string]interface{}{
+				"name":   "default",
+				"bucket": bucket,
+				"config": config,
+			},
+			"volumeSnapshotLocation": map[string]interface{}{
+				"name":   "default",
+				"config": config,
+			},
+		},
+		"initContainers": []interface{}{
+			map[string]interface{}{
+				"name":  "velero-plugin-for-" + p.Name,
+				"image": p.PluginImage,
+				"volumeMounts": []interface{}{
+					map[string]interface{}{"mountPath": "/target", "name": "plugins"},
+				},
+			},
+		},
+		"credentials": map[string]interface{}{
+			"useSecret":      secret != "",
+			"existingSecret": secret,
+		},
+		"deployRestic": false,
+	}
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling the values of the Velero chart")
+	}
+	return data, nil
+}
+
+// BackupNamespaces returns the namespaces backed up: the dev namespace and the namespaces of the permanent
+// environments of the team
+func BackupNamespaces(jxClient versioned.Interface, devNs string) ([]string, error) {
+	envs, err := jxClient.JenkinsV1().Environments(devNs).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the environments in namespace %s", devNs)
+	}
+	answer := []string{devNs}
+	for _, env := range envs.Items {
+		ns := env.Spec.Namespace
+		if ns == "" || env.Spec.Kind != v1.EnvironmentKindTypePermanent || util.StringArrayIndex(answer, ns) >= 0 {
+			continue
+		}
+		answer = append(answer, ns)
+	}
+	sort.Strings(answer[1:])
+	return answer, nil
+}
+
+// BackupTemplate returns the spec of the backups of the namespaces which also include the cluster resources such
+// as the CRDs. The backups expire after the time to live such as 720h
+func BackupTemplate(namespaces []string, ttl string) map[string]interface{} {
+	included := []interface{}{}
+	for _, ns := range namespaces {
+		included = append(included, ns)
+	}
+	excluded := []interface{}{}
+	for _, r := range excludedResources {
+		excluded = append(excluded, r)
+	}
+	spec := map[string]interface{}{
+		"includedNamespaces":      included,
+		"excludedResources":       excluded,
+		"includeClusterResources": true,
+	}
+	if ttl != "" {
+		spec["ttl"] = ttl
+	}
+	return spec
+}
+
+// ApplySchedule creates or updates the schedule of the backups in the namespace of Velero
+func ApplySchedule(client dynamic.Interface, ns string, name string, cron string, template map[string]interface{}) error {
+	u := newResource("Schedule", ns, name, map[string]interface{}{
+		"schedule": cron,
+		"template": template,
+	})
+	resources := client.Resource(ScheduleResource).Namespace(ns)
+	existing, err := resources.Get(name, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "getting Schedule %s in namespace %s", name, ns)
+		}
+		_, err = resources.Create(u, metav1.CreateOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "creating Schedule %s in namespace %s", name, ns)
+		}
+		return nil
+	}
+	u.SetResourceVersion(existing.GetResourceVersion())
+	_, err = resources.Update(u, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "updating Schedule %s in namespace %s", name, ns)
+	}
+	return nil
+}
+
+// CreateBackup creates a backup of the template in the namespace of Velero
+func CreateBackup(client dynamic.Interface, ns string, name string, template map[string]interface{}) error {
+	_, err := client.Resource(BackupResource).Namespace(ns).Create(newResource("Backup", ns, name, template), metav1.CreateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "creating Backup %s in namespace %s", name, ns)
+	}
+	return nil
+}
+
+// ListBackups returns the backups in the namespace of Velero, the latest one first
+func ListBackups(client dynamic.Interface, ns string) ([]Backup, error) {
+	list, err := client.Resource(BackupResource).Namespace(ns).List(metav1.ListOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "listing the Backups in namespace %s", ns)
+	}
+	answer := []Backup{}
+	for i := range list.Items {
+		u := &list.Items[i]
+		b := Backup{
+			Name:     u.GetName(),
+			Schedule: u.GetLabels()["velero.io/schedule-name"],
+			Created:  u.GetCreationTimestamp().Time,
+		}
+		b.Phase, _, _ = unstructured.NestedString(u.Object, "status", "phase")
+		answer = append(answer, b)
+	}
+	sort.SliceStable(answer, func(i, j int) bool {
+		return answer[i].Created.After(answer[j].Created)
+	})
+	return answer, nil
+}
+
+// CreateRestore creates the restore of the backup in the namespace of Velero. The existing resources are not
+// modified
+func CreateRestore(client dynamic.Interface, ns string, name string, backup string) error {
+	spec := map[string]interface{}{
+		"backupName":        backup,
+		"excludedResources": []interface{}{"nodes", "events", "events.events.k8s.io", "backups.velero.io", "restores.velero.io"},
+	}
+	_, err := client.Resource(RestoreResource).Namespace(ns).Create(newResource("Restore", ns, name, spec), metav1.CreateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "creating Restore %s of Backup %s in namespace %s", name, backup, ns)
+	}
+	return nil
+}
+
+// WaitForRestore waits for the restore to complete returning its phase
+func WaitForRestore(client dynamic.Interface, ns string, name string, timeout time.Duration) (string, error) {
+	phase := ""
+	err := util.Retry(timeout, func() error {
+		u, err := client.Resource(RestoreResource).Namespace(ns).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "getting Restore %s in namespace %s", name, ns)
+		}
+		phase, _, _ = unstructured.NestedString(u.Object, "status", "phase")
+		switch phase {
+		case PhaseCompleted, PhasePartiallyFailed, PhaseFailed:
+			return nil
+		}
+		return fmt.Errorf("the Restore %s is %s", name, phase)
+	})
+	return phase, err
+}
+
+func newResource(kind string, ns string, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "velero.io/v1",
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": ns,
+				"labels": map[string]interface{}{
+					LabelBackupKind: "jx",
+				},
+			},
+			"spec": spec,
+		},
+	}
+}
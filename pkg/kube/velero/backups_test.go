@@ -0,0 +1,79 @@
+// +build unit
+
+package velero
+
+import (
+	"testing"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	jxfake "github.com/jenkins-x/jx-api/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cloud"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestStorageProvider(t *testing.T) {
+	t.Parallel()
+
+	p, err := GetStorageProvider(cloud.GKE)
+	require.NoError(t, err)
+	bucket, err := p.Bucket("gs://mycluster-backups")
+	require.NoError(t, err)
+	assert.Equal(t, "mycluster-backups", bucket)
+	_, err = p.Bucket("s3://mycluster-backups")
+	assert.Error(t, err)
+
+	values, err := p.ChartValues(bucket, "europe-west1", "", "velero-secret")
+	require.NoError(t, err)
+	assert.Contains(t, string(values), "bucket: mycluster-backups")
+	assert.Contains(t, string(values), "existingSecret: velero-secret")
+	assert.Contains(t, string(values), "image: velero/velero-plugin-for-gcp")
+
+	_, err = GetStorageProvider(cloud.IKS)
+	assert.Error(t, err)
+}
+
+func TestBackupNamespaces(t *testing.T) {
+	t.Parallel()
+
+	env := func(name string, ns string, kind v1.EnvironmentKindType) runtime.Object {
+		return &v1.Environment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "jx"},
+			Spec:       v1.EnvironmentSpec{Namespace: ns, Kind: kind},
+		}
+	}
+	jxClient := jxfake.NewSimpleClientset(
+		env("production", "jx-production", v1.EnvironmentKindTypePermanent),
+		env("staging", "jx-staging", v1.EnvironmentKindTypePermanent),
+		env("pr-1", "jx-myapp-pr-1", v1.EnvironmentKindTypePreview),
+	)
+	namespaces, err := BackupNamespaces(jxClient, "jx")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"jx", "jx-production", "jx-staging"}, namespaces)
+}
+
+func TestSchedulesAndBackups(t *testing.T) {
+	t.Parallel()
+
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	template := BackupTemplate([]string{"jx", "jx-staging"}, "720h0m0s")
+
+	require.NoError(t, ApplySchedule(client, "velero", "jx-backups", "0 */6 * * *", template))
+	require.NoError(t, ApplySchedule(client, "velero", "jx-backups", "0 2 * * *", template), "applying an existing Schedule should update it")
+	schedule, err := client.Resource(ScheduleResource).Namespace("velero").Get("jx-backups", metav1.GetOptions{})
+	require.NoError(t, err)
+	cron, _, _ := unstructured.NestedString(schedule.Object, "spec", "schedule")
+	assert.Equal(t, "0 2 * * *", cron)
+	included, _, _ := unstructured.NestedBool(schedule.Object, "spec", "template", "includeClusterResources")
+	assert.True(t, included)
+
+	require.NoError(t, CreateBackup(client, "velero", "before-upgrade", template))
+	backups, err := ListBackups(client, "velero")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Equal(t, "before-upgrade", backups[0].Name)
+}
@@ -0,0 +1,53 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	tools_watch "k8s.io/client-go/tools/watch"
+)
+
+// maxFailureReasons the maximum number of reasons reported when a resource fails to become ready
+const maxFailureReasons = 10
+
+// WaitUntilReady waits until a resource of the list watch is ready using an informer so that the wait reacts to
+// changes immediately and recovers from dropped watches. It returns wait.ErrWaitTimeout if no resource is ready
+// within the timeout
+func WaitUntilReady(lw cache.ListerWatcher, objType runtime.Object, timeout time.Duration, ready func(obj runtime.Object) bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	precondition := func(store cache.Store) (bool, error) {
+		for _, obj := range store.List() {
+			if o, ok := obj.(runtime.Object); ok && ready(o) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	condition := func(event watch.Event) (bool, error) {
+		return event.Type != watch.Deleted && ready(event.Object), nil
+	}
+	_, err := tools_watch.UntilWithSync(ctx, lw, objType, precondition, condition)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return wait.ErrWaitTimeout
+	}
+	return err
+}
+
+// NotReadyError returns the error of a resource which did not become ready within the timeout with the reasons why
+func NotReadyError(description string, timeout time.Duration, reasons []string) error {
+	if len(reasons) == 0 {
+		return fmt.Errorf("%s did not become ready within %s", description, timeout.String())
+	}
+	if len(reasons) > maxFailureReasons {
+		reasons = reasons[:maxFailureReasons]
+	}
+	return fmt.Errorf("%s did not become ready within %s:\n  %s", description, timeout.String(), strings.Join(reasons, "\n  "))
+}
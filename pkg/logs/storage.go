@@ -0,0 +1,97 @@
+package logs
+
+import (
+	"io"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/cloud/buckets"
+	"github.com/jenkins-x/jx/v2/pkg/cloud/gke"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/step"
+	"github.com/jenkins-x/jx/v2/pkg/errorutil"
+	"github.com/pkg/errors"
+)
+
+const (
+	// logsStorageTimeout the timeout of reading the logs of a build from long term storage
+	logsStorageTimeout = 30 * time.Second
+)
+
+var (
+	// containerHeaderRegex matches the line logged before the logs of each container of a build
+	containerHeaderRegex = regexp.MustCompile(`^\s*Showing logs for build .* stage (.+) and container (\S+)\s*$`)
+
+	logsStorageLock    sync.RWMutex
+	logsStorageReaders = map[string]LogsStorageReader{
+		"gs":     readGCSLogs,
+		"s3":     readProviderLogs,
+		"azblob": readBucketLogs,
+		"http":   readHTTPLogs,
+		"https":  readHTTPLogs,
+	}
+)
+
+// LogsStorageReader reads the logs of a completed build stored at the given URL in long term storage
+type LogsStorageReader func(t *TektonLogger, logsURL string, authSvc auth.ConfigService) (io.ReadCloser, error)
+
+// RegisterLogsStorage registers the reader of the logs stored at URLs with the given scheme, replacing any
+// reader already registered for the scheme
+func RegisterLogsStorage(scheme string, reader LogsStorageReader) {
+	logsStorageLock.Lock()
+	defer logsStorageLock.Unlock()
+	logsStorageReaders[scheme] = reader
+}
+
+func logsStorageReader(scheme string) LogsStorageReader {
+	logsStorageLock.RLock()
+	defer logsStorageLock.RUnlock()
+	return logsStorageReaders[scheme]
+}
+
+// readGCSLogs reads the logs using the bucket provider of the team falling back to gsutil for clusters not
+// installed via boot
+func readGCSLogs(t *TektonLogger, logsURL string, authSvc auth.ConfigService) (io.ReadCloser, error) {
+	reader, err := performProviderDownload(logsURL, t)
+	if err != nil {
+		// TODO: This is only here as long as we keep supporting non boot clusters, as GKE are the only ones with LTS supported outside of boot
+		reader, err2 := gke.StreamTransferFileFromBucket(logsURL)
+		if err2 != nil {
+			return nil, errorutil.CombineErrors(err, err2)
+		}
+		return reader, nil
+	}
+	return reader, nil
+}
+
+// readProviderLogs reads the logs using the bucket provider of the team falling back to reading the bucket directly
+func readProviderLogs(t *TektonLogger, logsURL string, authSvc auth.ConfigService) (io.ReadCloser, error) {
+	reader, err := performProviderDownload(logsURL, t)
+	if err != nil {
+		reader, err2 := buckets.ReadURL(logsURL, logsStorageTimeout, nil)
+		if err2 != nil {
+			return nil, errorutil.CombineErrors(err, err2)
+		}
+		return reader, nil
+	}
+	return reader, nil
+}
+
+// readBucketLogs reads the logs from the bucket using the credentials of the environment
+func readBucketLogs(t *TektonLogger, logsURL string, authSvc auth.ConfigService) (io.ReadCloser, error) {
+	return buckets.ReadURL(logsURL, logsStorageTimeout, nil)
+}
+
+// readHTTPLogs reads the logs from a git provider such as GitHub pages using the git credentials
+func readHTTPLogs(t *TektonLogger, logsURL string, authSvc auth.ConfigService) (io.ReadCloser, error) {
+	return buckets.ReadURL(logsURL, logsStorageTimeout, step.CreateBucketHTTPFn(authSvc))
+}
+
+func performProviderDownload(logsURL string, t *TektonLogger) (io.ReadCloser, error) {
+	provider, err := NewBucketProviderFromTeamSettingsConfiguration(t.JXClient, t.Namespace)
+	if err != nil {
+		return nil, errors.Wrapf(err, "There was a problem obtaining a Bucket provider for %s", logsURL)
+	}
+	return provider.DownloadFileFromBucket(logsURL)
+}
@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jenkins-x/jx/v2/pkg/auth"
@@ -15,14 +16,13 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/errorutil"
 	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
 
+	"github.com/acarl005/stripansi"
 	"github.com/fatih/color"
 	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx-api/pkg/client/clientset/versioned"
 	"github.com/jenkins-x/jx-logging/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/builds"
 	"github.com/jenkins-x/jx/v2/pkg/cloud/factory"
-	"github.com/jenkins-x/jx/v2/pkg/cloud/gke"
-	"github.com/jenkins-x/jx/v2/pkg/cmd/step"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/jenkins-x/jx/v2/pkg/tekton"
 	"github.com/pkg/errors"
@@ -44,15 +44,30 @@ type TektonLogger struct {
 	BytesLimit        int64
 	FailIfPodFails    bool
 	LogsRetrieverFunc retrieverFunc
-	err               error
+	// StageFilter only logs the stages whose name contains the filter
+	StageFilter string
+	// StepFilter only logs the steps whose container name contains the filter
+	StepFilter string
+	// FollowParallel follows the logs of the pods of all stages at the same time rather than one stage after another
+	FollowParallel bool
+	err            error
+	errLock        sync.Mutex
 }
 
 // Err returns the last error that occurred during streaming logs.
 // It should be checked after the log stream channel has been closed.
 func (t *TektonLogger) Err() error {
+	t.errLock.Lock()
+	defer t.errLock.Unlock()
 	return t.err
 }
 
+func (t *TektonLogger) setErr(err error) {
+	t.errLock.Lock()
+	defer t.errLock.Unlock()
+	t.err = err
+}
+
 // retrieverFunc is a func signature used to define the LogsRetrieverFunc in TektonLogger
 type retrieverFunc func(pod *corev1.Pod, container *corev1.Container, limitBytes int64, c kubernetes.Interface) (io.ReadCloser, error)
 
@@ -61,10 +76,14 @@ type retrieverFunc func(pod *corev1.Pod, container *corev1.Container, limitBytes
 type LogLine struct {
 	Line       string
 	ShouldMask bool
+	// Stage the name of the stage the line was logged by if known
+	Stage string
+	// Step the name of the container of the step the line was logged by if known
+	Step string
 }
 
 // GetTektonPipelinesWithActivePipelineActivity returns list of all PipelineActivities with corresponding Tekton PipelineRuns ordered by the PipelineRun creation timestamp and a map to obtain its reference once a name has been selected
-func (t TektonLogger) GetTektonPipelinesWithActivePipelineActivity(filters []string) ([]string, map[string]*v1.PipelineActivity, error) {
+func (t *TektonLogger) GetTektonPipelinesWithActivePipelineActivity(filters []string) ([]string, map[string]*v1.PipelineActivity, error) {
 	labelsFilter := strings.Join(filters, ",")
 	paList, err := t.JXClient.JenkinsV1().PipelineActivities(t.Namespace).List(metav1.ListOptions{
 		LabelSelector: labelsFilter,
@@ -220,13 +239,13 @@ func (t *TektonLogger) GetRunningBuildLogs(pa *v1.PipelineActivity, buildName st
 		defer close(ch)
 		err := t.getRunningBuildLogs(pa, buildName, noWaitForRuns, ch)
 		if err != nil {
-			t.err = err
+			t.setErr(err)
 		}
 	}()
 	return ch
 }
 
-func (t TektonLogger) getRunningBuildLogs(pa *v1.PipelineActivity, buildName string, noWaitForRuns bool, out chan<- LogLine) error {
+func (t *TektonLogger) getRunningBuildLogs(pa *v1.PipelineActivity, buildName string, noWaitForRuns bool, out chan<- LogLine) error {
 	loggedAllRunsForActivity := false
 	foundLogs := false
 
@@ -276,6 +295,7 @@ func (t TektonLogger) getRunningBuildLogs(pa *v1.PipelineActivity, buildName str
 				stagesToCheckCount = len(allStages)
 			}
 			stagesSeen := make(map[string]bool)
+			followers := &parallelFollowers{}
 
 			// Repeat until we've seen pods for all stages
 			for stagesToCheckCount > len(stagesSeen) {
@@ -295,6 +315,13 @@ func (t TektonLogger) getRunningBuildLogs(pa *v1.PipelineActivity, buildName str
 						strings.ToLower(params.Branch) == strings.ToLower(pa.Spec.GitBranch) && params.Build == pa.Spec.Build {
 						stagesSeen[stageName] = true
 						foundLogs = true
+						if !matchesFilter(stageName, t.StageFilter) {
+							continue
+						}
+						if t.FollowParallel {
+							followers.follow(t, pod, pa, buildName, stageName, out)
+							continue
+						}
 						err := t.getContainerLogsFromPod(pod, pa, buildName, stageName, out)
 						if err != nil {
 							return errors.Wrapf(err, "failed to obtain the logs for build %s and stage %s", buildName, stageName)
@@ -304,6 +331,13 @@ func (t TektonLogger) getRunningBuildLogs(pa *v1.PipelineActivity, buildName str
 				if !foundLogs {
 					break
 				}
+				if t.FollowParallel && stagesToCheckCount > len(stagesSeen) {
+					time.Sleep(time.Second)
+				}
+			}
+			err = followers.wait()
+			if err != nil {
+				return err
 			}
 		}
 		if !foundLogs {
@@ -330,21 +364,28 @@ func (t *TektonLogger) getContainerLogsFromPod(pod *corev1.Pod, pa *v1.PipelineA
 	containers, _, _ := kube.GetContainersWithStatusAndIsInit(pod)
 	for i, initContainer := range containers {
 		ic := initContainer
+		if !matchesFilter(ic.Name, t.StepFilter) {
+			continue
+		}
 		pod, err := t.waitForContainerToStart(pa.Namespace, pod, i, stageName, out)
 		out <- LogLine{
 			Line: fmt.Sprintf("\nShowing logs for build %v stage %s and container %s",
 				infoColor.Sprintf(buildName), infoColor.Sprintf(stageName), infoColor.Sprintf(ic.Name)),
+			Stage: stageName,
+			Step:  ic.Name,
 		}
 		if err != nil {
 			return errors.Wrapf(err, "there was a problem writing a single line into the logs writer")
 		}
-		err = t.fetchLogsToChannel(pa.Namespace, pod, &ic, out)
+		err = t.fetchLogsToChannel(pa.Namespace, pod, &ic, LogLine{Stage: stageName, Step: ic.Name}, out)
 		if err != nil {
 			return errors.Wrap(err, "couldn't fetch logs into the logs channel")
 		}
 		if hasStepFailed(pod, i, t.KubeClient, pa.Namespace) {
 			out <- LogLine{
-				Line: errorColor.Sprintf("\nPipeline failed on stage '%s' : container '%s'. The execution of the pipeline has stopped.", stageName, ic.Name),
+				Line:  errorColor.Sprintf("\nPipeline failed on stage '%s' : container '%s'. The execution of the pipeline has stopped.", stageName, ic.Name),
+				Stage: stageName,
+				Step:  ic.Name,
 			}
 			if err != nil {
 				return err
@@ -358,7 +399,7 @@ func (t *TektonLogger) getContainerLogsFromPod(pod *corev1.Pod, pa *v1.PipelineA
 	return nil
 }
 
-func (t *TektonLogger) fetchLogsToChannel(ns string, pod *corev1.Pod, container *corev1.Container, out chan<- LogLine) error {
+func (t *TektonLogger) fetchLogsToChannel(ns string, pod *corev1.Pod, container *corev1.Container, source LogLine, out chan<- LogLine) error {
 	logsRetrieverFunc := t.LogsRetrieverFunc
 	if logsRetrieverFunc == nil {
 		logsRetrieverFunc = retrieveLogsFromPod
@@ -368,10 +409,11 @@ func (t *TektonLogger) fetchLogsToChannel(ns string, pod *corev1.Pod, container
 		return err
 	}
 	defer reader.Close()
-	return writeStreamLines(reader, out)
+	return writeStreamLines(reader, source, out)
 }
 
-func writeStreamLines(reader io.Reader, out chan<- LogLine) error {
+// writeStreamLines writes the lines of the reader to the channel with the stage and step of the source line
+func writeStreamLines(reader io.Reader, source LogLine, out chan<- LogLine) error {
 	buffReader := bufio.NewReader(reader)
 	for {
 		line, _, err := buffReader.ReadLine()
@@ -381,7 +423,7 @@ func writeStreamLines(reader io.Reader, out chan<- LogLine) error {
 			}
 			return errors.Wrap(err, "failed to read stream")
 		}
-		out <- LogLine{Line: string(line), ShouldMask: true}
+		out <- LogLine{Line: string(line), ShouldMask: true, Stage: source.Stage, Step: source.Step}
 	}
 }
 
@@ -398,7 +440,7 @@ func hasStepFailed(pod *corev1.Pod, stepNumber int, kubeClient kubernetes.Interf
 	return false
 }
 
-func (t TektonLogger) waitForContainerToStart(ns string, pod *corev1.Pod, idx int, stageName string, out chan<- LogLine) (*corev1.Pod, error) {
+func (t *TektonLogger) waitForContainerToStart(ns string, pod *corev1.Pod, idx int, stageName string, out chan<- LogLine) (*corev1.Pod, error) {
 	if pod.Status.Phase == corev1.PodFailed {
 		return pod, nil
 	}
@@ -414,7 +456,9 @@ func (t TektonLogger) waitForContainerToStart(ns string, pod *corev1.Pod, idx in
 	c := color.New(color.FgGreen)
 	c.EnableColor()
 	out <- LogLine{
-		Line: fmt.Sprintf("\nwaiting for stage %s : container %s to start...\n", c.Sprintf(stageName), c.Sprintf(containerName)),
+		Line:  fmt.Sprintf("\nwaiting for stage %s : container %s to start...\n", c.Sprintf(stageName), c.Sprintf(containerName)),
+		Stage: stageName,
+		Step:  containerName,
 	}
 	for {
 		time.Sleep(time.Second)
@@ -435,7 +479,7 @@ func (t *TektonLogger) StreamPipelinePersistentLogs(logsURL string, authSvc auth
 		defer close(ch)
 		err := t.streamPipelinePersistentLogs(logsURL, authSvc, ch)
 		if err != nil {
-			t.err = err
+			t.setErr(err)
 		}
 	}()
 	return ch
@@ -446,36 +490,18 @@ func (t *TektonLogger) streamPipelinePersistentLogs(logsURL string, authSvc auth
 	if err != nil {
 		return errors.Wrapf(err, "unable to parse logs URL %s to retrieve scheme", logsURL)
 	}
-	switch u.Scheme {
-	case "gs":
-		reader, err := performProviderDownload(logsURL, t.JXClient, t.Namespace)
-		if err != nil {
-			// TODO: This is only here as long as we keep supporting non boot clusters, as GKE are the only ones with LTS supported outside of boot
-			reader, err2 := gke.StreamTransferFileFromBucket(logsURL)
-			if err2 != nil {
-				return errorutil.CombineErrors(err, err2)
-			}
-			return t.streamPipedLogs(reader, out)
-		}
-		return t.streamPipedLogs(reader, out)
-	case "s3":
-		reader, err := performProviderDownload(logsURL, t.JXClient, t.Namespace)
-		if err != nil {
-			return errors.Wrap(err, "there was a problem downloading logs from s3 bucket")
-		}
-		return t.streamPipedLogs(reader, out)
-	case "http", "https":
-		reader, err := downloadLogFile(logsURL, authSvc)
-		if err != nil {
-			return errors.Wrapf(err, "there was a problem obtaining the log file from the github pages URL %s", logsURL)
-		}
-		return t.streamPipedLogs(reader, out)
-	default:
+	reader := logsStorageReader(u.Scheme)
+	if reader == nil {
 		out <- LogLine{
 			Line: fmt.Sprintf("The provided logsURL scheme is not supported: %s", u.Scheme),
 		}
+		return nil
 	}
-	return nil
+	src, err := reader(t, logsURL, authSvc)
+	if err != nil {
+		return errors.Wrapf(err, "there was a problem obtaining the logs from %s", logsURL)
+	}
+	return t.streamPipedLogs(src, out)
 }
 
 func (t *TektonLogger) streamPipedLogs(src io.ReadCloser, out chan<- LogLine) (err error) {
@@ -486,9 +512,19 @@ func (t *TektonLogger) streamPipedLogs(src io.ReadCloser, out chan<- LogLine) (e
 	}()
 	scanner := bufio.NewScanner(src)
 	scanner.Split(bufio.ScanLines)
+	source := LogLine{}
 	for scanner.Scan() {
 		text := scanner.Text()
-		out <- LogLine{Line: text}
+		// the stored logs contain the same headers as the logs of running builds so the lines can be filtered alike
+		if m := containerHeaderRegex.FindStringSubmatch(stripansi.Strip(text)); m != nil {
+			source = LogLine{Stage: m[1], Step: m[2]}
+		}
+		if t.StageFilter != "" || t.StepFilter != "" {
+			if source.Stage == "" || !matchesFilter(source.Stage, t.StageFilter) || !matchesFilter(source.Step, t.StepFilter) {
+				continue
+			}
+		}
+		out <- LogLine{Line: text, Stage: source.Stage, Step: source.Step}
 		if t.FailIfPodFails && strings.Contains(text, "The execution of the pipeline has stopped.") {
 			return errors.New("the execution of the pipeline has stopped")
 		}
@@ -496,6 +532,39 @@ func (t *TektonLogger) streamPipedLogs(src io.ReadCloser, out chan<- LogLine) (e
 	return nil
 }
 
+// matchesFilter returns true if the name contains the filter ignoring case or the filter is empty
+func matchesFilter(name string, filter string) bool {
+	return filter == "" || strings.Contains(strings.ToLower(name), strings.ToLower(filter))
+}
+
+// parallelFollowers follows the logs of the pods of several stages at the same time
+type parallelFollowers struct {
+	wg   sync.WaitGroup
+	lock sync.Mutex
+	err  error
+}
+
+func (f *parallelFollowers) follow(t *TektonLogger, pod *corev1.Pod, pa *v1.PipelineActivity, buildName string, stageName string, out chan<- LogLine) {
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		err := t.getContainerLogsFromPod(pod, pa, buildName, stageName, out)
+		if err != nil {
+			f.lock.Lock()
+			defer f.lock.Unlock()
+			if f.err == nil {
+				f.err = errors.Wrapf(err, "failed to obtain the logs for build %s and stage %s", buildName, stageName)
+			}
+		}
+	}()
+}
+
+// wait waits for the logs of all the pods to be followed returning the first error
+func (f *parallelFollowers) wait() error {
+	f.wg.Wait()
+	return f.err
+}
+
 // Uses the same signature as retrieverFunc so it can be used in TektonLogger
 func retrieveLogsFromPod(pod *corev1.Pod, container *corev1.Container, limitBytes int64, client kubernetes.Interface) (io.ReadCloser, error) {
 	options := &corev1.PodLogOptions{
@@ -513,19 +582,6 @@ func retrieveLogsFromPod(pod *corev1.Pod, container *corev1.Container, limitByte
 	return stream, nil
 }
 
-func downloadLogFile(logsURL string, authSvc auth.ConfigService) (io.ReadCloser, error) {
-	reader, err := buckets.ReadURL(logsURL, 30*time.Second, step.CreateBucketHTTPFn(authSvc))
-	return reader, err
-}
-
-func performProviderDownload(logsURL string, jxClient versioned.Interface, ns string) (io.ReadCloser, error) {
-	provider, err := NewBucketProviderFromTeamSettingsConfiguration(jxClient, ns)
-	if err != nil {
-		return nil, errors.Wrap(err, "There was a problem obtaining a Bucket provider for bucket scheme gs://")
-	}
-	return provider.DownloadFileFromBucket(logsURL)
-}
-
 func NewBucketProviderFromTeamSettingsConfiguration(jxClient versioned.Interface, ns string) (buckets.Provider, error) {
 	teamSettings, err := kube.GetDevEnvTeamSettings(jxClient, ns)
 	if err != nil {
@@ -9,12 +9,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"path"
+	"strings"
 	"testing"
 
 	"github.com/acarl005/stripansi"
 	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx-api/pkg/client/clientset/versioned"
 	jxfake "github.com/jenkins-x/jx-api/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/v2/pkg/auth"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/clients/fake"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/testhelpers"
@@ -375,11 +377,11 @@ func TestStreamPipelinePersistentLogsInUnsupportedBucketProvider(t *testing.T) {
 
 	authSvc, err := commonOptions.GitAuthConfigService()
 	assert.NoError(t, err)
-	ch := tl.StreamPipelinePersistentLogs("azblob://nonSupportedBucket", authSvc)
+	ch := tl.StreamPipelinePersistentLogs("ftp://nonSupportedBucket", authSvc)
 	lines := readLogChannel(ch)
 	err = tl.Err()
 	assert.NoError(t, err)
-	assert.Contains(t, lines[0], "The provided logsURL scheme is not supported: azblob")
+	assert.Contains(t, lines[0], "The provided logsURL scheme is not supported: ftp")
 }
 
 func TestGetRunningBuildLogsWithMultipleStages(t *testing.T) {
@@ -434,6 +436,111 @@ func TestGetRunningBuildLogsWithMultipleStages(t *testing.T) {
 		stripansi.Strip(lines[0]), "'build' should be the first stage logged")
 }
 
+func multipleStagesLogger(t *testing.T) (*TektonLogger, *v1.PipelineActivity, *corev1.PodList) {
+	testCaseDir := path.Join("test_data", "multiple_stages")
+	_, _, _, _, ns := getFakeClientsAndNs(t)
+
+	podsList := tekton_helpers_test.AssertLoadPods(t, testCaseDir)
+	pipelineRuns := tekton_helpers_test.AssertLoadSinglePipelineRun(t, testCaseDir)
+	structures := tekton_helpers_test.AssertLoadSinglePipelineStructure(t, testCaseDir)
+
+	tl := &TektonLogger{
+		KubeClient:        kubeMocks.NewSimpleClientset(podsList),
+		JXClient:          jxfake.NewSimpleClientset(structures),
+		TektonClient:      tektonMocks.NewSimpleClientset(pipelineRuns),
+		Namespace:         ns,
+		LogsRetrieverFunc: fakeLogsRetriever,
+	}
+	pa := &v1.PipelineActivity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "abayer-js-test-repo-master-1",
+			Namespace: ns,
+		},
+		Spec: v1.PipelineActivitySpec{
+			Build:         "1",
+			GitBranch:     "master",
+			GitRepository: "js-test-repo",
+			GitOwner:      "abayer",
+		},
+	}
+	return tl, pa, podsList
+}
+
+func readLogLines(ch <-chan LogLine) (l []LogLine) {
+	for line := range ch {
+		l = append(l, line)
+	}
+	return
+}
+
+func TestGetRunningBuildLogsWithStageAndStepFilters(t *testing.T) {
+	tl, pa, _ := multipleStagesLogger(t)
+	tl.StageFilter = "Second"
+	tl.StepFilter = "step3"
+
+	lines := readLogLines(tl.GetRunningBuildLogs(pa, "abayer/js-test-repo/master/1", false))
+	assert.NoError(t, tl.Err())
+
+	require.Len(t, lines, LogsHeadersMultiplier)
+	for _, line := range lines {
+		assert.Equal(t, "second", line.Stage)
+		assert.Equal(t, "step-step3", line.Step)
+	}
+	assert.Contains(t, lines[1].Line, "container step-step3")
+}
+
+func TestGetRunningBuildLogsFollowingParallelStages(t *testing.T) {
+	tl, pa, podsList := multipleStagesLogger(t)
+	tl.FollowParallel = true
+
+	lines := readLogLines(tl.GetRunningBuildLogs(pa, "abayer/js-test-repo/master/1", false))
+	assert.NoError(t, tl.Err())
+
+	containers1, _, _ := kube.GetContainersWithStatusAndIsInit(&podsList.Items[0])
+	containers2, _, _ := kube.GetContainersWithStatusAndIsInit(&podsList.Items[1])
+	require.Len(t, lines, (len(containers1)+len(containers2))*LogsHeadersMultiplier)
+	stages := map[string]int{}
+	for _, line := range lines {
+		stages[line.Stage]++
+	}
+	assert.Equal(t, map[string]int{
+		"build":  len(containers1) * LogsHeadersMultiplier,
+		"second": len(containers2) * LogsHeadersMultiplier,
+	}, stages)
+}
+
+func TestStreamPipelinePersistentLogsFromRegisteredStorage(t *testing.T) {
+	storedLogs := strings.Join([]string{
+		"",
+		"Showing logs for build \x1b[32mabayer/js-test-repo/master #1\x1b[0m stage \x1b[32mbuild\x1b[0m and container \x1b[32mstep-build\x1b[0m",
+		"compiling",
+		"",
+		"Showing logs for build \x1b[32mabayer/js-test-repo/master #1\x1b[0m stage \x1b[32mbuild\x1b[0m and container \x1b[32mstep-test\x1b[0m",
+		"testing",
+		"",
+		"Showing logs for build \x1b[32mabayer/js-test-repo/master #1\x1b[0m stage \x1b[32mpromote\x1b[0m and container \x1b[32mstep-test\x1b[0m",
+		"promoting",
+	}, "\n")
+	RegisterLogsStorage("teststorage", func(t *TektonLogger, logsURL string, authSvc auth.ConfigService) (io.ReadCloser, error) {
+		return &fakeReadCloser{strings.NewReader(storedLogs)}, nil
+	})
+
+	tl := &TektonLogger{
+		StageFilter: "build",
+		StepFilter:  "test",
+	}
+	lines := readLogLines(tl.StreamPipelinePersistentLogs("teststorage://mybucket/jenkins-x/logs/abayer/js-test-repo/master/1.log", nil))
+	assert.NoError(t, tl.Err())
+
+	require.Len(t, lines, 3)
+	assert.Equal(t, "testing", lines[1].Line)
+	assert.Equal(t, "", lines[2].Line)
+	for _, line := range lines {
+		assert.Equal(t, "build", line.Stage)
+		assert.Equal(t, "step-test", line.Step)
+	}
+}
+
 func TestGetRunningBuildLogsWithMultipleStagesWithFailureInFirstStage(t *testing.T) {
 	testCaseDir := path.Join("test_data", "multiple_stages_with_failure_in_first_stage")
 	_, _, _, _, ns := getFakeClientsAndNs(t)
@@ -156,7 +156,7 @@ func (model *ArchetypeModel) CreateSurvey(data *ArchetypeFilter, pickVersion boo
 			Message: "Group ID:",
 			Options: filteredGroups,
 		}
-		err := survey.AskOne(prompt, &form.ArchetypeGroupId, survey.Required, surveyOpts)
+		err := util.AskOne(prompt, &form.ArchetypeGroupId, survey.Required, surveyOpts)
 		if err != nil {
 			return err
 		}
@@ -190,7 +190,7 @@ func (model *ArchetypeModel) CreateSurvey(data *ArchetypeFilter, pickVersion boo
 			Message: "Artifact ID:",
 			Options: artifactIds,
 		}
-		err := survey.AskOne(prompt, &form.ArchetypeArtifactId, survey.Required, surveyOpts)
+		err := util.AskOne(prompt, &form.ArchetypeArtifactId, survey.Required, surveyOpts)
 		if err != nil {
 			return err
 		}
@@ -212,7 +212,7 @@ func (model *ArchetypeModel) CreateSurvey(data *ArchetypeFilter, pickVersion boo
 			Message: "Version:",
 			Options: versions,
 		}
-		err := survey.AskOne(prompt, &form.ArchetypeVersion, survey.Required, surveyOpts)
+		err := util.AskOne(prompt, &form.ArchetypeVersion, survey.Required, surveyOpts)
 		if err != nil {
 			return err
 		}
@@ -226,7 +226,7 @@ func (model *ArchetypeModel) CreateSurvey(data *ArchetypeFilter, pickVersion boo
 			Message: "Project Group ID:",
 			Default: "com.acme",
 		}
-		err := survey.AskOne(q, &form.GroupId, survey.Required, surveyOpts)
+		err := util.AskOne(q, &form.GroupId, survey.Required, surveyOpts)
 		if err != nil {
 			return err
 		}
@@ -236,7 +236,7 @@ func (model *ArchetypeModel) CreateSurvey(data *ArchetypeFilter, pickVersion boo
 			Message: "Project Artifact ID:",
 			Default: "",
 		}
-		err := survey.AskOne(q, &form.ArtifactId, survey.Required, surveyOpts)
+		err := util.AskOne(q, &form.ArtifactId, survey.Required, surveyOpts)
 		if err != nil {
 			return err
 		}
@@ -246,7 +246,7 @@ func (model *ArchetypeModel) CreateSurvey(data *ArchetypeFilter, pickVersion boo
 			Message: "Project Version:",
 			Default: "1.0.0-SNAPSHOT",
 		}
-		err := survey.AskOne(q, &form.Version, survey.Required, surveyOpts)
+		err := util.AskOne(q, &form.Version, survey.Required, surveyOpts)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,168 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	// Namespace the prefix of the names of the metrics
+	Namespace = "jx"
+	// Path the URL path of the HTTP endpoint returning the metrics
+	Path = "/metrics"
+
+	// maxObservedActivities the number of completed pipeline activities remembered so that each is only observed once
+	maxObservedActivities = 10000
+)
+
+var (
+	// Registry the registry of the metrics of the jx controllers
+	Registry = prometheus.NewRegistry()
+
+	// PipelineDuration the duration of the completed pipelines
+	PipelineDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "pipeline_duration_seconds",
+		Help:      "The duration of the completed pipelines.",
+		Buckets:   prometheus.ExponentialBuckets(30, 2, 9),
+	}, []string{"owner", "repository", "status"})
+
+	// Promotions the number of completed promotions to environments
+	Promotions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "promotions_total",
+		Help:      "The number of completed promotions to environments.",
+	}, []string{"environment", "status"})
+
+	// WebhookDuration the time taken to process the webhooks received by the controllers
+	WebhookDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "webhook_processing_seconds",
+		Help:      "The time taken to process the webhooks received by the controllers.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"controller", "status"})
+
+	// HelmInstallFailures the number of failed helm chart installs and upgrades
+	HelmInstallFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "helm_install_failures_total",
+		Help:      "The number of failed helm chart installs and upgrades.",
+	}, []string{"chart"})
+
+	// ComponentHealthy whether the core jx components checked by the health controller are healthy
+	ComponentHealthy = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "component_healthy",
+		Help:      "Whether the Jenkins X component is healthy.",
+	}, []string{"component"})
+
+	// ComponentFailures the number of failed health checks of the core jx components
+	ComponentFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "component_failures_total",
+		Help:      "The number of failed health checks of the Jenkins X component.",
+	}, []string{"component"})
+
+	// ComponentLastTransition the time the health of the core jx components last changed
+	ComponentLastTransition = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "component_last_transition_timestamp_seconds",
+		Help:      "The time the health of the Jenkins X component last changed.",
+	}, []string{"component"})
+
+	// HealthChecks the number of health checks run by the health controller
+	HealthChecks = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "health_checks_total",
+		Help:      "The number of health checks run.",
+	})
+
+	// ActivitiesPruned the number of PipelineActivities deleted by the retention rules
+	ActivitiesPruned = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "activities_pruned_total",
+		Help:      "The number of PipelineActivities deleted by the retention rules.",
+	}, []string{"owner", "repository", "reason"})
+
+	// ActivityPruneFailures the number of PipelineActivities which failed to be deleted by the retention rules
+	ActivityPruneFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "activity_prune_failures_total",
+		Help:      "The number of PipelineActivities which failed to be deleted by the retention rules.",
+	})
+
+	observedLock       sync.Mutex
+	observedActivities = map[string]bool{}
+)
+
+func init() {
+	Registry.MustRegister(PipelineDuration, Promotions, WebhookDuration, HelmInstallFailures,
+		ComponentHealthy, ComponentFailures, ComponentLastTransition, HealthChecks, ActivitiesPruned, ActivityPruneFailures)
+}
+
+// Handler returns the handler of the metrics endpoint
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// Serve serves the metrics endpoint in the background on the given port
+func Serve(bindAddress string, port int) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle(Path, Handler())
+	srv := &http.Server{
+		Addr:    fmt.Sprintf("%s:%d", bindAddress, port),
+		Handler: mux,
+	}
+	go func() {
+		log.Logger().Infof("serving the metrics on %s port %d", bindAddress, port)
+		err := srv.ListenAndServe()
+		if err != nil && err != http.ErrServerClosed {
+			log.Logger().Errorf("unexpected error serving the metrics: %s", err.Error())
+		}
+	}()
+	return srv
+}
+
+// ObserveWebhook records the time taken to process a webhook received by the controller since the given start time
+func ObserveWebhook(controller string, start time.Time, err error) {
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	WebhookDuration.WithLabelValues(controller, status).Observe(time.Since(start).Seconds())
+}
+
+// ObservePipelineActivity records the duration and promotions of a completed pipeline. Controllers update an
+// activity many times after it completes so each activity is only observed once
+func ObservePipelineActivity(activity *v1.PipelineActivity) {
+	spec := &activity.Spec
+	if !spec.Status.IsTerminated() || spec.StartedTimestamp == nil || spec.CompletedTimestamp == nil {
+		return
+	}
+	observedLock.Lock()
+	if observedActivities[activity.Name] {
+		observedLock.Unlock()
+		return
+	}
+	if len(observedActivities) >= maxObservedActivities {
+		observedActivities = map[string]bool{}
+	}
+	observedActivities[activity.Name] = true
+	observedLock.Unlock()
+
+	duration := spec.CompletedTimestamp.Sub(spec.StartedTimestamp.Time)
+	PipelineDuration.WithLabelValues(spec.GitOwner, spec.GitRepository, string(spec.Status)).Observe(duration.Seconds())
+	for _, step := range spec.Steps {
+		promote := step.Promote
+		if step.Kind == v1.ActivityStepKindTypePromote && promote != nil && promote.Status.IsTerminated() {
+			Promotions.WithLabelValues(promote.Environment, string(promote.Status)).Inc()
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// +build unit
+
+package metrics_test
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestObservePipelineActivity(t *testing.T) {
+	started := metav1.NewTime(time.Now().Add(-5 * time.Minute))
+	completed := metav1.Now()
+	activity := &v1.PipelineActivity{
+		ObjectMeta: metav1.ObjectMeta{Name: "myorg-myapp-master-1"},
+		Spec: v1.PipelineActivitySpec{
+			GitOwner:           "myorg",
+			GitRepository:      "myapp",
+			Status:             v1.ActivityStatusTypeSucceeded,
+			StartedTimestamp:   &started,
+			CompletedTimestamp: &completed,
+			Steps: []v1.PipelineActivityStep{
+				{
+					Kind: v1.ActivityStepKindTypePromote,
+					Promote: &v1.PromoteActivityStep{
+						CoreActivityStep: v1.CoreActivityStep{Status: v1.ActivityStatusTypeSucceeded},
+						Environment:      "staging",
+					},
+				},
+			},
+		},
+	}
+	promotions := metrics.Promotions.WithLabelValues("staging", string(v1.ActivityStatusTypeSucceeded))
+	before := testutil.ToFloat64(promotions)
+
+	metrics.ObservePipelineActivity(activity)
+	metrics.ObservePipelineActivity(activity)
+	assert.Equal(t, before+1, testutil.ToFloat64(promotions), "an activity should only be observed once")
+
+	running := activity.DeepCopy()
+	running.Name = "myorg-myapp-master-2"
+	running.Spec.Status = v1.ActivityStatusTypeRunning
+	metrics.ObservePipelineActivity(running)
+	assert.Equal(t, before+1, testutil.ToFloat64(promotions), "running activities should not be observed")
+}
+
+func TestEnsureServiceMonitor(t *testing.T) {
+	ns := "jx"
+	selector := map[string]string{"app": "controllerbuild"}
+	kubeClient := kubefake.NewSimpleClientset()
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+
+	installed, err := metrics.EnsureServiceMonitor(kubeClient, apiextensionsfake.NewSimpleClientset(), dynamicClient, "controllerbuild", ns, selector, 9090)
+	require.NoError(t, err)
+	assert.False(t, installed, "nothing should be created without the Prometheus operator")
+
+	apiClient := apiextensionsfake.NewSimpleClientset(&apiextensions.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: metrics.ServiceMonitorCRD},
+	})
+	for i := 0; i < 2; i++ {
+		installed, err = metrics.EnsureServiceMonitor(kubeClient, apiClient, dynamicClient, "controllerbuild", ns, selector, 9090)
+		require.NoError(t, err)
+		assert.True(t, installed)
+	}
+
+	svc, err := kubeClient.CoreV1().Services(ns).Get("controllerbuild-metrics", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, selector, svc.Spec.Selector)
+	require.Len(t, svc.Spec.Ports, 1)
+	assert.Equal(t, corev1.ServicePort{Name: "metrics", Port: 9090, TargetPort: svc.Spec.Ports[0].TargetPort}, svc.Spec.Ports[0])
+	assert.Equal(t, 9090, svc.Spec.Ports[0].TargetPort.IntValue())
+
+	monitor, err := dynamicClient.Resource(metrics.ServiceMonitorResource).Namespace(ns).Get("controllerbuild", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "ServiceMonitor", monitor.GetKind())
+	assert.Equal(t, map[string]string{metrics.LabelMetrics: "controllerbuild"}, monitor.GetLabels())
+}
@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// ServiceMonitorCRD the name of the custom resource definition of the ServiceMonitors of the Prometheus operator
+	ServiceMonitorCRD = "servicemonitors.monitoring.coreos.com"
+	// LabelMetrics the label of the services exposing the metrics of a controller
+	LabelMetrics = "jenkins.io/metrics"
+
+	portName = "metrics"
+)
+
+// ServiceMonitorResource the resource of the ServiceMonitors of the Prometheus operator
+var ServiceMonitorResource = schema.GroupVersionResource{Group: "monitoring.coreos.com", Version: "v1", Resource: "servicemonitors"}
+
+// NewService creates the service exposing the metrics port of the pods of the controller matching the selector
+func NewService(name string, ns string, selector map[string]string, port int) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-metrics",
+			Namespace: ns,
+			Labels:    map[string]string{LabelMetrics: name},
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: selector,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       portName,
+					Port:       int32(port),
+					TargetPort: intstr.FromInt(port),
+				},
+			},
+		},
+	}
+}
+
+// NewServiceMonitor creates the ServiceMonitor scraping the metrics service of the controller
+func NewServiceMonitor(name string, ns string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": ServiceMonitorResource.GroupVersion().String(),
+			"kind":       "ServiceMonitor",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": ns,
+				"labels":    map[string]interface{}{LabelMetrics: name},
+			},
+			"spec": map[string]interface{}{
+				"selector": map[string]interface{}{
+					"matchLabels": map[string]interface{}{LabelMetrics: name},
+				},
+				"endpoints": []interface{}{
+					map[string]interface{}{"port": portName, "path": Path},
+				},
+			},
+		},
+	}
+}
+
+// EnsureServiceMonitor creates or updates the metrics service and ServiceMonitor of the controller so that the
+// Prometheus operator scrapes its metrics. It returns false if the Prometheus operator is not installed
+func EnsureServiceMonitor(kubeClient kubernetes.Interface, apiClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, name string, ns string, selector map[string]string, port int) (bool, error) {
+	_, err := apiClient.ApiextensionsV1beta1().CustomResourceDefinitions().Get(ServiceMonitorCRD, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "getting the custom resource definition %s", ServiceMonitorCRD)
+	}
+
+	svc := NewService(name, ns, selector, port)
+	services := kubeClient.CoreV1().Services(ns)
+	existing, err := services.Get(svc.Name, metav1.GetOptions{})
+	if err == nil {
+		existing.Labels = svc.Labels
+		existing.Spec.Selector = svc.Spec.Selector
+		existing.Spec.Ports = svc.Spec.Ports
+		_, err = services.Update(existing)
+	} else if apierrors.IsNotFound(err) {
+		_, err = services.Create(svc)
+	}
+	if err != nil {
+		return true, errors.Wrapf(err, "saving service %s in namespace %s", svc.Name, ns)
+	}
+
+	monitor := NewServiceMonitor(name, ns)
+	monitors := dynamicClient.Resource(ServiceMonitorResource).Namespace(ns)
+	current, err := monitors.Get(name, metav1.GetOptions{})
+	if err == nil {
+		monitor.SetResourceVersion(current.GetResourceVersion())
+		_, err = monitors.Update(monitor, metav1.UpdateOptions{})
+	} else if apierrors.IsNotFound(err) {
+		_, err = monitors.Create(monitor, metav1.CreateOptions{})
+	}
+	if err != nil {
+		return true, errors.Wrapf(err, "saving ServiceMonitor %s in namespace %s", name, ns)
+	}
+	return true, nil
+}
@@ -0,0 +1,401 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/ghodss/yaml"
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// ConfigMapName the name of the ConfigMap in the dev namespace containing the notifications
+	ConfigMapName = "jenkins-x-notifications"
+	// ConfigKey the key of the notifications in the ConfigMap
+	ConfigKey = "notifications.yaml"
+	// SecretKeyURL the key of the webhook URL in the Secret of a notification
+	SecretKeyURL = "url"
+
+	// KindSlack notifications posted to a Slack incoming webhook
+	KindSlack = "slack"
+	// KindTeams notifications posted to a Microsoft Teams incoming webhook
+	KindTeams = "teams"
+	// KindDiscord notifications posted to a Discord webhook
+	KindDiscord = "discord"
+
+	// EventPipelineStarted a pipeline started running
+	EventPipelineStarted = "pipeline-started"
+	// EventPipelineSucceeded a pipeline succeeded
+	EventPipelineSucceeded = "pipeline-succeeded"
+	// EventPipelineFailed a pipeline failed, errored or was aborted
+	EventPipelineFailed = "pipeline-failed"
+	// EventPromotionSucceeded a promotion to an environment succeeded
+	EventPromotionSucceeded = "promotion-succeeded"
+	// EventPromotionFailed a promotion to an environment failed
+	EventPromotionFailed = "promotion-failed"
+	// EventCredentialWarning a credential is about to expire, has expired or is no longer valid
+	EventCredentialWarning = "credential-warning"
+
+	postTimeout = 10 * time.Second
+
+	// maxSentEvents the number of sent events remembered so that each event is only sent once
+	maxSentEvents = 10000
+)
+
+var (
+	// Kinds the kinds of webhooks notifications can be sent to
+	Kinds = []string{KindSlack, KindTeams, KindDiscord}
+
+	// EventTypes the types of events notifications can be sent for
+	EventTypes = []string{EventPipelineStarted, EventPipelineSucceeded, EventPipelineFailed, EventPromotionSucceeded, EventPromotionFailed, EventCredentialWarning}
+
+	defaultTemplates = map[string]string{
+		EventPipelineStarted:    `Pipeline {{.Owner}}/{{.Repository}} {{.Branch}} #{{.Build}} started`,
+		EventPipelineSucceeded:  `Pipeline {{.Owner}}/{{.Repository}} {{.Branch}} #{{.Build}} succeeded{{if .Duration}} in {{.Duration}}{{end}}`,
+		EventPipelineFailed:     `Pipeline {{.Owner}}/{{.Repository}} {{.Branch}} #{{.Build}} {{.Status | lower}}{{if .Duration}} after {{.Duration}}{{end}}`,
+		EventPromotionSucceeded: `Promoted {{.Owner}}/{{.Repository}}{{if .Version}} version {{.Version}}{{end}} to {{.Environment}}{{if .ApplicationURL}} {{.ApplicationURL}}{{end}}`,
+		EventPromotionFailed:    `Promotion of {{.Owner}}/{{.Repository}}{{if .Version}} version {{.Version}}{{end}} to {{.Environment}} failed`,
+		EventCredentialWarning:  `Credential {{.Credential}} is {{.Status | lower}}{{if .Message}}: {{.Message}}{{end}}`,
+	}
+
+	templateFuncs = template.FuncMap{
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+	}
+)
+
+// Config the notifications of a team
+type Config struct {
+	Notifications []Notification `json:"notifications,omitempty"`
+}
+
+// Notification a webhook the events of pipelines and promotions are sent to
+type Notification struct {
+	// Name the name of the notification
+	Name string `json:"name"`
+	// Kind the kind of webhook: slack, teams or discord
+	Kind string `json:"kind"`
+	// Secret the name of the Secret containing the webhook URL in the url key
+	Secret string `json:"secret"`
+	// Events the types of events sent, defaults to all events
+	Events []string `json:"events,omitempty"`
+	// Environments routes only the promotions to these environments, defaults to all environments
+	Environments []string `json:"environments,omitempty"`
+	// Repositories only sends the events of these owner/repository names, defaults to all repositories
+	Repositories []string `json:"repositories,omitempty"`
+	// Template the Go template of the message, defaults to a message for each type of event
+	Template string `json:"template,omitempty"`
+}
+
+// Event a pipeline or promotion event
+type Event struct {
+	Type           string
+	Owner          string
+	Repository     string
+	Branch         string
+	Build          string
+	Version        string
+	Status         string
+	Environment    string
+	Duration       string
+	URL            string
+	ApplicationURL string
+	Credential     string
+	Message        string
+}
+
+// Success returns true if the event is not a failure
+func (e *Event) Success() bool {
+	return e.Type != EventPipelineFailed && e.Type != EventPromotionFailed && e.Type != EventCredentialWarning
+}
+
+// LoadConfig loads the notifications from the ConfigMap in the namespace, returning an empty configuration if there
+// is no ConfigMap
+func LoadConfig(kubeClient kubernetes.Interface, ns string) (*Config, error) {
+	config := &Config{}
+	cm, err := kubeClient.CoreV1().ConfigMaps(ns).Get(ConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return config, nil
+		}
+		return config, errors.Wrapf(err, "getting ConfigMap %s in namespace %s", ConfigMapName, ns)
+	}
+	err = yaml.Unmarshal([]byte(cm.Data[ConfigKey]), config)
+	if err != nil {
+		return config, errors.Wrapf(err, "unmarshalling the %s key of ConfigMap %s", ConfigKey, ConfigMapName)
+	}
+	return config, nil
+}
+
+// SaveConfig saves the notifications to the ConfigMap in the namespace
+func SaveConfig(kubeClient kubernetes.Interface, ns string, config *Config) error {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return errors.Wrap(err, "marshalling the notifications")
+	}
+	_, err = kube.DefaultModifyConfigMap(kubeClient, ns, ConfigMapName, func(cm *corev1.ConfigMap) error {
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[ConfigKey] = string(data)
+		return nil
+	}, nil)
+	return err
+}
+
+// SetNotification adds the notification replacing any notification with the same name
+func (c *Config) SetNotification(n Notification) {
+	for i := range c.Notifications {
+		if c.Notifications[i].Name == n.Name {
+			c.Notifications[i] = n
+			return
+		}
+	}
+	c.Notifications = append(c.Notifications, n)
+}
+
+// Validate returns an error if the notification is not valid
+func (n *Notification) Validate() error {
+	if n.Name == "" {
+		return util.MissingOption("name")
+	}
+	if util.StringArrayIndex(Kinds, n.Kind) < 0 {
+		return util.InvalidOption("kind", n.Kind, Kinds)
+	}
+	for _, e := range n.Events {
+		if util.StringArrayIndex(EventTypes, e) < 0 {
+			return util.InvalidOption("events", e, EventTypes)
+		}
+	}
+	if n.Template != "" {
+		_, err := template.New(n.Name).Funcs(templateFuncs).Parse(n.Template)
+		if err != nil {
+			return errors.Wrapf(err, "parsing the template of notification %s", n.Name)
+		}
+	}
+	return nil
+}
+
+// Matches returns true if the event should be sent to the notification
+func (n *Notification) Matches(e *Event) bool {
+	if len(n.Events) > 0 && util.StringArrayIndex(n.Events, e.Type) < 0 {
+		return false
+	}
+	if len(n.Repositories) > 0 && util.StringArrayIndex(n.Repositories, e.Owner+"/"+e.Repository) < 0 {
+		return false
+	}
+	if e.Environment != "" && len(n.Environments) > 0 && util.StringArrayIndex(n.Environments, e.Environment) < 0 {
+		return false
+	}
+	return true
+}
+
+// Render renders the message of the event using the template of the notification
+func (n *Notification) Render(e *Event) (string, error) {
+	text := n.Template
+	if text == "" {
+		text = defaultTemplates[e.Type]
+		if e.URL != "" && !strings.HasPrefix(e.Type, "promotion") {
+			text += " {{.URL}}"
+		}
+	}
+	tmpl, err := template.New(n.Name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing the template of notification %s", n.Name)
+	}
+	var buffer bytes.Buffer
+	err = tmpl.Execute(&buffer, e)
+	if err != nil {
+		return "", errors.Wrapf(err, "rendering the template of notification %s", n.Name)
+	}
+	return buffer.String(), nil
+}
+
+// Payload returns the JSON payload of a message for the kind of webhook
+func Payload(kind string, text string, success bool) map[string]interface{} {
+	switch kind {
+	case KindTeams:
+		color := "2EB886"
+		if !success {
+			color = "D00000"
+		}
+		return map[string]interface{}{
+			"@type":      "MessageCard",
+			"@context":   "https://schema.org/extensions",
+			"summary":    text,
+			"themeColor": color,
+			"text":       text,
+		}
+	case KindDiscord:
+		return map[string]interface{}{"content": text}
+	default:
+		return map[string]interface{}{"text": text}
+	}
+}
+
+// Post posts the payload to the webhook URL
+func Post(url string, payload map[string]interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "marshalling the payload")
+	}
+	client := &http.Client{Timeout: postTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrapf(err, "posting to %s", util.SanitizeURL(url))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("posting to %s returned status %s", util.SanitizeURL(url), resp.Status)
+	}
+	return nil
+}
+
+// ActivityEvents returns the events of the current state of the pipeline activity
+func ActivityEvents(activity *v1.PipelineActivity) []Event {
+	spec := &activity.Spec
+	base := Event{
+		Owner:      spec.GitOwner,
+		Repository: spec.GitRepository,
+		Branch:     spec.GitBranch,
+		Build:      spec.Build,
+		Version:    spec.Version,
+		Status:     string(spec.Status),
+		URL:        spec.BuildLogsURL,
+	}
+	if base.URL == "" {
+		base.URL = spec.BuildURL
+	}
+	if spec.StartedTimestamp != nil && spec.CompletedTimestamp != nil {
+		base.Duration = util.DurationString(spec.StartedTimestamp, spec.CompletedTimestamp)
+	}
+
+	answer := []Event{}
+	switch spec.Status {
+	case v1.ActivityStatusTypeRunning:
+		e := base
+		e.Type = EventPipelineStarted
+		answer = append(answer, e)
+	case v1.ActivityStatusTypeSucceeded:
+		e := base
+		e.Type = EventPipelineSucceeded
+		answer = append(answer, e)
+	case v1.ActivityStatusTypeFailed, v1.ActivityStatusTypeError, v1.ActivityStatusTypeAborted:
+		e := base
+		e.Type = EventPipelineFailed
+		answer = append(answer, e)
+	}
+	for _, step := range spec.Steps {
+		promote := step.Promote
+		if step.Kind != v1.ActivityStepKindTypePromote || promote == nil {
+			continue
+		}
+		e := base
+		e.Environment = promote.Environment
+		e.Status = string(promote.Status)
+		e.ApplicationURL = promote.ApplicationURL
+		e.Duration = ""
+		switch promote.Status {
+		case v1.ActivityStatusTypeSucceeded:
+			e.Type = EventPromotionSucceeded
+		case v1.ActivityStatusTypeFailed, v1.ActivityStatusTypeError, v1.ActivityStatusTypeAborted:
+			e.Type = EventPromotionFailed
+		default:
+			continue
+		}
+		answer = append(answer, e)
+	}
+	return answer
+}
+
+// Notifier sends the events of pipeline activities to the notifications configured in a namespace
+type Notifier struct {
+	KubeClient kubernetes.Interface
+	Namespace  string
+	// Post posts the payload to the webhook URL, defaults to Post
+	Post func(url string, payload map[string]interface{}) error
+
+	lock sync.Mutex
+	sent map[string]bool
+}
+
+// OnActivity sends the events of the pipeline activity which have not been sent yet. Controllers update an activity
+// many times so each event is only sent once
+func (n *Notifier) OnActivity(activity *v1.PipelineActivity) {
+	for _, e := range ActivityEvents(activity) {
+		e := e
+		key := strings.Join([]string{activity.Name, e.Type, e.Environment}, "/")
+		n.lock.Lock()
+		if n.sent == nil || len(n.sent) >= maxSentEvents {
+			n.sent = map[string]bool{}
+		}
+		sent := n.sent[key]
+		n.sent[key] = true
+		n.lock.Unlock()
+		if sent {
+			continue
+		}
+		err := n.Notify(&e)
+		if err != nil {
+			log.Logger().Warnf("failed to send the %s notifications of %s: %s", e.Type, activity.Name, err)
+		}
+	}
+}
+
+// Notify sends the event to the matching notifications
+func (n *Notifier) Notify(e *Event) error {
+	config, err := LoadConfig(n.KubeClient, n.Namespace)
+	if err != nil {
+		return err
+	}
+	post := n.Post
+	if post == nil {
+		post = Post
+	}
+	var failed []string
+	for i := range config.Notifications {
+		notification := &config.Notifications[i]
+		if !notification.Matches(e) {
+			continue
+		}
+		err = n.send(notification, e, post)
+		if err != nil {
+			log.Logger().Warnf("failed to send notification %s: %s", notification.Name, err)
+			failed = append(failed, notification.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to send the notifications %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func (n *Notifier) send(notification *Notification, e *Event, post func(url string, payload map[string]interface{}) error) error {
+	secret, err := n.KubeClient.CoreV1().Secrets(n.Namespace).Get(notification.Secret, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "getting Secret %s", notification.Secret)
+	}
+	url := string(secret.Data[SecretKeyURL])
+	if url == "" {
+		return errors.Errorf("Secret %s has no %s key", notification.Secret, SecretKeyURL)
+	}
+	text, err := notification.Render(e)
+	if err != nil {
+		return err
+	}
+	return post(url, Payload(notification.Kind, text, e.Success()))
+}
@@ -0,0 +1,146 @@
+// +build unit
+
+package notify_test
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func newActivity(status v1.ActivityStatusType, promotions map[string]v1.ActivityStatusType) *v1.PipelineActivity {
+	started := metav1.NewTime(time.Now().Add(-90 * time.Second))
+	completed := metav1.NewTime(started.Add(90 * time.Second))
+	activity := &v1.PipelineActivity{
+		ObjectMeta: metav1.ObjectMeta{Name: "myorg-myapp-master-3"},
+		Spec: v1.PipelineActivitySpec{
+			GitOwner:           "myorg",
+			GitRepository:      "myapp",
+			GitBranch:          "master",
+			Build:              "3",
+			Version:            "1.0.3",
+			Status:             status,
+			StartedTimestamp:   &started,
+			CompletedTimestamp: &completed,
+		},
+	}
+	for env, s := range promotions {
+		activity.Spec.Steps = append(activity.Spec.Steps, v1.PipelineActivityStep{
+			Kind: v1.ActivityStepKindTypePromote,
+			Promote: &v1.PromoteActivityStep{
+				CoreActivityStep: v1.CoreActivityStep{Status: s},
+				Environment:      env,
+				ApplicationURL:   "http://myapp." + env + ".example.com",
+			},
+		})
+	}
+	return activity
+}
+
+func TestActivityEvents(t *testing.T) {
+	events := notify.ActivityEvents(newActivity(v1.ActivityStatusTypeRunning, map[string]v1.ActivityStatusType{
+		"staging": v1.ActivityStatusTypeRunning,
+	}))
+	require.Len(t, events, 1)
+	assert.Equal(t, notify.EventPipelineStarted, events[0].Type)
+
+	events = notify.ActivityEvents(newActivity(v1.ActivityStatusTypeFailed, map[string]v1.ActivityStatusType{
+		"staging": v1.ActivityStatusTypeError,
+	}))
+	require.Len(t, events, 2)
+	assert.Equal(t, notify.EventPipelineFailed, events[0].Type)
+	assert.Equal(t, "1m30s", events[0].Duration)
+	assert.Equal(t, notify.EventPromotionFailed, events[1].Type)
+	assert.Equal(t, "staging", events[1].Environment)
+	assert.False(t, events[1].Success())
+}
+
+func TestNotificationMatchesAndRender(t *testing.T) {
+	n := &notify.Notification{
+		Name:         "prod",
+		Kind:         notify.KindTeams,
+		Events:       []string{notify.EventPipelineFailed, notify.EventPromotionSucceeded},
+		Environments: []string{"production"},
+	}
+	require.NoError(t, n.Validate())
+
+	failed := &notify.Event{Type: notify.EventPipelineFailed, Owner: "myorg", Repository: "myapp", Branch: "master", Build: "3", Status: "Error"}
+	staging := &notify.Event{Type: notify.EventPromotionSucceeded, Owner: "myorg", Repository: "myapp", Version: "1.0.3", Environment: "staging"}
+	production := &notify.Event{Type: notify.EventPromotionSucceeded, Owner: "myorg", Repository: "myapp", Version: "1.0.3", Environment: "production"}
+	assert.True(t, n.Matches(failed), "the environments should only route promotions")
+	assert.False(t, n.Matches(staging))
+	assert.True(t, n.Matches(production))
+	assert.False(t, n.Matches(&notify.Event{Type: notify.EventPipelineSucceeded}))
+
+	text, err := n.Render(failed)
+	require.NoError(t, err)
+	assert.Equal(t, "Pipeline myorg/myapp master #3 error", text)
+
+	text, err = n.Render(production)
+	require.NoError(t, err)
+	assert.Equal(t, "Promoted myorg/myapp version 1.0.3 to production", text)
+
+	n.Template = "{{.Repository | upper}} {{.Version}} is now in {{.Environment}}"
+	text, err = n.Render(production)
+	require.NoError(t, err)
+	assert.Equal(t, "MYAPP 1.0.3 is now in production", text)
+
+	assert.Error(t, (&notify.Notification{Name: "bad", Kind: "irc"}).Validate())
+	assert.Error(t, (&notify.Notification{Name: "bad", Kind: notify.KindSlack, Events: []string{"deployed"}}).Validate())
+	assert.Error(t, (&notify.Notification{Name: "bad", Kind: notify.KindSlack, Template: "{{.Repository"}).Validate())
+}
+
+func TestPayload(t *testing.T) {
+	assert.Equal(t, map[string]interface{}{"text": "hello"}, notify.Payload(notify.KindSlack, "hello", true))
+	assert.Equal(t, map[string]interface{}{"content": "hello"}, notify.Payload(notify.KindDiscord, "hello", true))
+	teams := notify.Payload(notify.KindTeams, "hello", false)
+	assert.Equal(t, "MessageCard", teams["@type"])
+	assert.Equal(t, "hello", teams["text"])
+	assert.Equal(t, "D00000", teams["themeColor"])
+}
+
+func TestNotifierOnActivity(t *testing.T) {
+	ns := "jx"
+	kubeClient := kubefake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "jx-notification-team", Namespace: ns},
+		Data:       map[string][]byte{notify.SecretKeyURL: []byte("https://discord.example.com/webhook")},
+	})
+	config := &notify.Config{}
+	config.SetNotification(notify.Notification{Name: "team", Kind: notify.KindDiscord, Secret: "jx-notification-team"})
+	require.NoError(t, notify.SaveConfig(kubeClient, ns, config))
+
+	loaded, err := notify.LoadConfig(kubeClient, ns)
+	require.NoError(t, err)
+	assert.Equal(t, config, loaded)
+
+	posted := []string{}
+	notifier := &notify.Notifier{
+		KubeClient: kubeClient,
+		Namespace:  ns,
+		Post: func(url string, payload map[string]interface{}) error {
+			assert.Equal(t, "https://discord.example.com/webhook", url)
+			posted = append(posted, payload["content"].(string))
+			return nil
+		},
+	}
+	notifier.OnActivity(newActivity(v1.ActivityStatusTypeRunning, nil))
+	notifier.OnActivity(newActivity(v1.ActivityStatusTypeRunning, nil))
+	activity := newActivity(v1.ActivityStatusTypeSucceeded, map[string]v1.ActivityStatusType{
+		"staging": v1.ActivityStatusTypeSucceeded,
+	})
+	notifier.OnActivity(activity)
+	notifier.OnActivity(activity)
+
+	assert.Equal(t, []string{
+		"Pipeline myorg/myapp master #3 started",
+		"Pipeline myorg/myapp master #3 succeeded in 1m30s",
+		"Promoted myorg/myapp version 1.0.3 to staging http://myapp.staging.example.com",
+	}, posted, "each event should only be sent once")
+}
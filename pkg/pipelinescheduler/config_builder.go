@@ -28,6 +28,9 @@ func BuildProwConfig(schedulers []*SchedulerLeaf) (*config.Config, *plugins.Conf
 		if err != nil {
 			return nil, nil, errors.Wrapf(err, "building JobConfig for %v", scheduler)
 		}
+		if scheduler.RunIfChanged != "" {
+			applyRunIfChanged(&configResult.JobConfig, scheduler.RunIfChanged, scheduler.Org, scheduler.Repo)
+		}
 		err = buildProwConfig(&configResult.ProwConfig, scheduler.SchedulerSpec, scheduler.Org, scheduler.Repo)
 		if err != nil {
 			return nil, nil, errors.Wrapf(err, "building ProwConfig for %v", scheduler)
@@ -346,6 +349,25 @@ func buildJobConfig(jobConfig *config.JobConfig, prowConfig *config.ProwConfig,
 	return nil
 }
 
+// applyRunIfChanged only triggers the jobs of the repository which run on every change when the files matching the
+// regular expression change
+func applyRunIfChanged(jobConfig *config.JobConfig, runIfChanged string, orgName string, repoName string) {
+	orgSlashRepo := orgSlashRepo(orgName, repoName)
+	presubmits := jobConfig.Presubmits[orgSlashRepo]
+	for i := range presubmits {
+		if presubmits[i].RunIfChanged == "" && presubmits[i].AlwaysRun {
+			presubmits[i].AlwaysRun = false
+			presubmits[i].RunIfChanged = runIfChanged
+		}
+	}
+	postsubmits := jobConfig.Postsubmits[orgSlashRepo]
+	for i := range postsubmits {
+		if postsubmits[i].RunIfChanged == "" {
+			postsubmits[i].RunIfChanged = runIfChanged
+		}
+	}
+}
+
 func buildPostsubmits(jobConfig *config.JobConfig, items []*jenkinsv1.Postsubmit, orgName string, repoName string) error {
 	if jobConfig.Postsubmits == nil {
 		jobConfig.Postsubmits = make(map[string][]job.Postsubmit)
@@ -40,6 +40,41 @@ func TestBuild(t *testing.T) {
 	assert.Equal(t, &cfg.Presubmits[fmt.Sprintf("%s/%s", org, leaf1.Repo)][0].Name, leaf1.Presubmits.Items[0].Name)
 }
 
+func TestBuildWithRunIfChanged(t *testing.T) {
+	t.Parallel()
+
+	org := uuid.New().String()
+	leaf1 := &pipelinescheduler.SchedulerLeaf{
+		Org:           org,
+		Repo:          uuid.New().String(),
+		SchedulerSpec: testhelpers.CompleteScheduler(),
+		RunIfChanged:  "^(services/api(/.*)?)$",
+	}
+	leaf1.Presubmits.Items[0].RegexpChangeMatcher = nil
+	leaf1.Postsubmits.Items[0].RegexpChangeMatcher = nil
+	leaf2 := &pipelinescheduler.SchedulerLeaf{
+		Org:           org,
+		Repo:          uuid.New().String(),
+		SchedulerSpec: testhelpers.CompleteScheduler(),
+		RunIfChanged:  "^(web(/.*)?)$",
+	}
+	leaves := []*pipelinescheduler.SchedulerLeaf{
+		leaf1,
+		leaf2,
+	}
+	cfg, _, err := pipelinescheduler.BuildProwConfig(leaves)
+	assert.NoError(t, err)
+
+	presubmit := cfg.Presubmits[fmt.Sprintf("%s/%s", org, leaf1.Repo)][0]
+	assert.Equal(t, leaf1.RunIfChanged, presubmit.RunIfChanged)
+	assert.False(t, presubmit.AlwaysRun)
+	assert.Equal(t, leaf1.RunIfChanged, cfg.Postsubmits[fmt.Sprintf("%s/%s", org, leaf1.Repo)][0].RunIfChanged)
+
+	// the run if changed of the scheduler is kept
+	presubmit = cfg.Presubmits[fmt.Sprintf("%s/%s", org, leaf2.Repo)][0]
+	assert.Equal(t, *leaf2.Presubmits.Items[0].RunIfChanged, presubmit.RunIfChanged)
+}
+
 func TestRepo(t *testing.T) {
 	wd, err := os.Getwd()
 	assert.NoError(t, err)
@@ -12,6 +12,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/jenkins-x/jx-logging/pkg/log"
+	jxconfig "github.com/jenkins-x/jx/v2/pkg/config"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
 	"github.com/jenkins-x/jx/v2/pkg/prow"
@@ -75,6 +76,7 @@ func GenerateProw(gitOps bool, autoApplyConfigUpdater bool, jxClient versioned.I
 			Repo:          sourceRepo.Spec.Repo,
 			Org:           sourceRepo.Spec.Org,
 			SchedulerSpec: merged,
+			RunIfChanged:  sourceRepositoryRunIfChanged(&sourceRepo),
 		})
 		if err != nil {
 			return nil, nil, errors.Wrapf(err, "building prow config")
@@ -91,6 +93,15 @@ func GenerateProw(gitOps bool, autoApplyConfigUpdater bool, jxClient versioned.I
 	return cfg, plugs, nil
 }
 
+// sourceRepositoryRunIfChanged returns the regular expression of the trigger paths of the source repository
+func sourceRepositoryRunIfChanged(sourceRepo *jenkinsv1.SourceRepository) string {
+	paths := sourceRepo.Annotations[kube.AnnotationTriggerPaths]
+	if paths == "" {
+		return ""
+	}
+	return jxconfig.TriggerPathsRegex(strings.Split(paths, ","))
+}
+
 func loadSchedulerResources(jxClient versioned.Interface, namespace string) (map[string]*jenkinsv1.Scheduler, *jenkinsv1.SourceRepositoryGroupList, *jenkinsv1.SourceRepositoryList, error) {
 	schedulers, err := jxClient.JenkinsV1().Schedulers(namespace).List(metav1.ListOptions{})
 	if err != nil {
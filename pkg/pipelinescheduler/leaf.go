@@ -7,4 +7,6 @@ type SchedulerLeaf struct {
 	*jenkinsv1.SchedulerSpec
 	Org  string
 	Repo string
+	// RunIfChanged the regular expression of the changed files which trigger the jobs of the repository
+	RunIfChanged string
 }
@@ -0,0 +1,307 @@
+package previews
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// AnnotationDependencies the annotation of a preview environment containing the JSON of its provisioned
+	// dependencies so that they are torn down with the preview environment
+	AnnotationDependencies = "jenkins.io/preview-dependencies"
+
+	// KindHelm dependencies installed from a helm chart into the preview namespace
+	KindHelm = "helm"
+	// KindCommand dependencies provisioned and torn down by an external command, such as a script creating a cloud
+	// database
+	KindCommand = "command"
+
+	// ActionProvision the argument appended to the command of a command dependency to provision it
+	ActionProvision = "provision"
+	// ActionDeprovision the argument appended to the command of a command dependency to tear it down
+	ActionDeprovision = "deprovision"
+
+	defaultHelmTimeout = 600
+)
+
+// Dependency an ephemeral service a preview environment depends on, such as a Postgres, Redis or Kafka
+type Dependency struct {
+	// Name the name of the dependency
+	Name string `json:"name"`
+	// Kind the kind of provisioner of the dependency, defaults to helm
+	Kind string `json:"kind,omitempty"`
+	// Chart the chart of a helm dependency, such as bitnami/postgresql
+	Chart string `json:"chart,omitempty"`
+	// Repository the URL of the chart repository of a helm dependency
+	Repository string `json:"repository,omitempty"`
+	// Version the version of the chart of a helm dependency
+	Version string `json:"version,omitempty"`
+	// Release the release name of a helm dependency, defaults to the preview namespace and the dependency name
+	Release string `json:"release,omitempty"`
+	// Values the values of the chart of a helm dependency
+	Values map[string]interface{} `json:"values,omitempty"`
+	// Command the command of a command dependency which is invoked with the provision or deprovision argument
+	Command []string `json:"command,omitempty"`
+}
+
+// Provisioner provisions and tears down a kind of dependency in a preview namespace
+type Provisioner interface {
+	// Provision creates or updates the dependency
+	Provision(dep *Dependency, ns string) error
+	// Deprovision tears down the dependency
+	Deprovision(dep *Dependency, ns string) error
+}
+
+// Manager provisions dependencies with the provisioner registered for their kind
+type Manager struct {
+	provisioners map[string]Provisioner
+}
+
+// NewManager creates a manager with the helm and command provisioners registered
+func NewManager(helmer helm.Helmer) *Manager {
+	m := &Manager{provisioners: map[string]Provisioner{}}
+	m.Register(KindHelm, &HelmProvisioner{Helmer: helmer})
+	m.Register(KindCommand, &CommandProvisioner{})
+	return m
+}
+
+// Register registers the provisioner of a kind of dependency, replacing any provisioner of the same kind
+func (m *Manager) Register(kind string, p Provisioner) {
+	m.provisioners[kind] = p
+}
+
+// Kinds returns the sorted kinds of dependencies which can be provisioned
+func (m *Manager) Kinds() []string {
+	answer := []string{}
+	for k := range m.provisioners {
+		answer = append(answer, k)
+	}
+	sort.Strings(answer)
+	return answer
+}
+
+func (m *Manager) provisioner(dep *Dependency) (Provisioner, error) {
+	kind := dep.Kind
+	if kind == "" {
+		kind = KindHelm
+	}
+	p := m.provisioners[kind]
+	if p == nil {
+		return nil, errors.Errorf("unknown kind %s of preview dependency %s, should be one of: %s", kind, dep.Name, strings.Join(m.Kinds(), ", "))
+	}
+	return p, nil
+}
+
+// Provision provisions the dependencies in the preview namespace, stopping at the first failure
+func (m *Manager) Provision(deps []Dependency, ns string) error {
+	for i := range deps {
+		dep := &deps[i]
+		p, err := m.provisioner(dep)
+		if err != nil {
+			return err
+		}
+		log.Logger().Infof("Provisioning preview dependency %s in namespace %s", util.ColorInfo(dep.Name), util.ColorInfo(ns))
+		err = p.Provision(dep, ns)
+		if err != nil {
+			return errors.Wrapf(err, "provisioning preview dependency %s", dep.Name)
+		}
+	}
+	return nil
+}
+
+// Deprovision tears down the dependencies in the preview namespace in the reverse order, returning the failures
+// after trying to tear down all the dependencies
+func (m *Manager) Deprovision(deps []Dependency, ns string) error {
+	var failed []string
+	for i := len(deps) - 1; i >= 0; i-- {
+		dep := &deps[i]
+		p, err := m.provisioner(dep)
+		if err == nil {
+			log.Logger().Infof("Tearing down preview dependency %s in namespace %s", util.ColorInfo(dep.Name), util.ColorInfo(ns))
+			err = p.Deprovision(dep, ns)
+		}
+		if err != nil {
+			log.Logger().Warnf("Failed to tear down preview dependency %s: %s", dep.Name, err)
+			failed = append(failed, dep.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return errors.Errorf("failed to tear down the preview dependencies %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// LoadDependencies loads the dependencies in the preview.dependencies key of the values file of a preview chart,
+// returning nil if the file does not exist
+func LoadDependencies(valuesFile string) ([]Dependency, error) {
+	exists, err := util.FileExists(valuesFile)
+	if err != nil || !exists {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(valuesFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", valuesFile)
+	}
+	values := struct {
+		Preview struct {
+			Dependencies []Dependency `json:"dependencies,omitempty"`
+		} `json:"preview,omitempty"`
+	}{}
+	err = yaml.Unmarshal(data, &values)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshalling the preview dependencies of %s", valuesFile)
+	}
+	deps := values.Preview.Dependencies
+	names := map[string]bool{}
+	for _, dep := range deps {
+		if dep.Name == "" {
+			return nil, errors.Errorf("a preview dependency in %s has no name", valuesFile)
+		}
+		if names[dep.Name] {
+			return nil, errors.Errorf("duplicate preview dependency %s in %s", dep.Name, valuesFile)
+		}
+		names[dep.Name] = true
+	}
+	return deps, nil
+}
+
+// SetEnvironmentDependencies records the dependencies on the preview environment
+func SetEnvironmentDependencies(env *v1.Environment, deps []Dependency) error {
+	if len(deps) == 0 {
+		delete(env.Annotations, AnnotationDependencies)
+		return nil
+	}
+	data, err := json.Marshal(deps)
+	if err != nil {
+		return errors.Wrap(err, "marshalling the preview dependencies")
+	}
+	if env.Annotations == nil {
+		env.Annotations = map[string]string{}
+	}
+	env.Annotations[AnnotationDependencies] = string(data)
+	return nil
+}
+
+// GetEnvironmentDependencies returns the dependencies recorded on the preview environment
+func GetEnvironmentDependencies(env *v1.Environment) ([]Dependency, error) {
+	text := env.Annotations[AnnotationDependencies]
+	if text == "" {
+		return nil, nil
+	}
+	deps := []Dependency{}
+	err := json.Unmarshal([]byte(text), &deps)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshalling the %s annotation of environment %s", AnnotationDependencies, env.Name)
+	}
+	return deps, nil
+}
+
+// HelmProvisioner installs helm dependencies into the preview namespace
+type HelmProvisioner struct {
+	Helmer helm.Helmer
+}
+
+// ReleaseName returns the release name of the helm dependency
+func (p *HelmProvisioner) ReleaseName(dep *Dependency, ns string) string {
+	if dep.Release != "" {
+		return dep.Release
+	}
+	return naming.ToValidNameTruncated(ns+"-"+dep.Name, 53)
+}
+
+// Provision installs or upgrades the chart of the dependency
+func (p *HelmProvisioner) Provision(dep *Dependency, ns string) error {
+	if dep.Chart == "" {
+		return errors.Errorf("no chart specified")
+	}
+	valueFiles := []string{}
+	if len(dep.Values) > 0 {
+		data, err := yaml.Marshal(dep.Values)
+		if err != nil {
+			return errors.Wrap(err, "marshalling the values")
+		}
+		file, err := ioutil.TempFile("", "preview-dependency-"+dep.Name+"-*.yaml")
+		if err != nil {
+			return errors.Wrap(err, "creating the values file")
+		}
+		defer os.Remove(file.Name())
+		_, err = file.Write(data)
+		file.Close()
+		if err != nil {
+			return errors.Wrapf(err, "writing the values file %s", file.Name())
+		}
+		valueFiles = append(valueFiles, file.Name())
+	}
+	return p.Helmer.UpgradeChart(dep.Chart, p.ReleaseName(dep, ns), ns, dep.Version, true, defaultHelmTimeout, false, true,
+		nil, nil, valueFiles, dep.Repository, "", "")
+}
+
+// Deprovision deletes the release of the dependency
+func (p *HelmProvisioner) Deprovision(dep *Dependency, ns string) error {
+	return p.Helmer.DeleteRelease(ns, p.ReleaseName(dep, ns), true)
+}
+
+// CommandProvisioner provisions dependencies by running their command with the provision or deprovision argument.
+// The PREVIEW_NAMESPACE and PREVIEW_DEPENDENCY environment variables are passed to the command
+type CommandProvisioner struct {
+	// Run runs the command, defaults to running it with util.Command
+	Run func(cmd *util.Command) (string, error)
+}
+
+// Provision runs the command with the provision argument
+func (p *CommandProvisioner) Provision(dep *Dependency, ns string) error {
+	return p.run(dep, ns, ActionProvision)
+}
+
+// Deprovision runs the command with the deprovision argument
+func (p *CommandProvisioner) Deprovision(dep *Dependency, ns string) error {
+	return p.run(dep, ns, ActionDeprovision)
+}
+
+func (p *CommandProvisioner) run(dep *Dependency, ns string, action string) error {
+	if len(dep.Command) == 0 {
+		return errors.Errorf("no command specified")
+	}
+	cmd := &util.Command{
+		Name: dep.Command[0],
+		Args: append(append([]string{}, dep.Command[1:]...), action),
+		Env: map[string]string{
+			"PREVIEW_NAMESPACE":  ns,
+			"PREVIEW_DEPENDENCY": dep.Name,
+		},
+	}
+	run := p.Run
+	if run == nil {
+		run = func(cmd *util.Command) (string, error) {
+			return cmd.RunWithoutRetry()
+		}
+	}
+	output, err := run(cmd)
+	if err != nil {
+		return errors.Wrapf(err, "running %s", cmd.String())
+	}
+	if output != "" {
+		log.Logger().Info(output)
+	}
+	return nil
+}
+
+// String returns a description of the dependency
+func (d *Dependency) String() string {
+	if d.Kind == KindCommand {
+		return fmt.Sprintf("%s (%s)", d.Name, strings.Join(d.Command, " "))
+	}
+	return fmt.Sprintf("%s (%s %s)", d.Name, d.Chart, d.Version)
+}
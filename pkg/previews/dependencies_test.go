@@ -0,0 +1,165 @@
+// +build unit
+
+package previews_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	helm_test "github.com/jenkins-x/jx/v2/pkg/helm/mocks"
+	"github.com/jenkins-x/jx/v2/pkg/previews"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	. "github.com/petergtz/pegomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProvisioner struct {
+	provisioned   []string
+	deprovisioned []string
+	fail          string
+}
+
+func (p *fakeProvisioner) Provision(dep *previews.Dependency, ns string) error {
+	if dep.Name == p.fail {
+		return errors.New("boom")
+	}
+	p.provisioned = append(p.provisioned, ns+"/"+dep.Name)
+	return nil
+}
+
+func (p *fakeProvisioner) Deprovision(dep *previews.Dependency, ns string) error {
+	if dep.Name == p.fail {
+		return errors.New("boom")
+	}
+	p.deprovisioned = append(p.deprovisioned, ns+"/"+dep.Name)
+	return nil
+}
+
+func TestLoadDependencies(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "preview-dependencies")
+	require.NoError(t, err)
+	valuesFile := filepath.Join(dir, "values.yaml")
+
+	deps, err := previews.LoadDependencies(valuesFile)
+	require.NoError(t, err)
+	assert.Empty(t, deps, "a missing values file has no dependencies")
+
+	err = ioutil.WriteFile(valuesFile, []byte(`
+preview:
+  image:
+    repository: myapp
+  dependencies:
+  - name: postgres
+    chart: bitnami/postgresql
+    version: 8.6.4
+    values:
+      postgresqlDatabase: myapp
+  - name: queue
+    kind: command
+    command: ["./scripts/queue.sh", "--region", "eu-west-1"]
+`), 0600)
+	require.NoError(t, err)
+	deps, err = previews.LoadDependencies(valuesFile)
+	require.NoError(t, err)
+	require.Len(t, deps, 2)
+	assert.Equal(t, "postgres", deps[0].Name)
+	assert.Equal(t, "bitnami/postgresql", deps[0].Chart)
+	assert.Equal(t, "myapp", deps[0].Values["postgresqlDatabase"])
+	assert.Equal(t, previews.KindCommand, deps[1].Kind)
+
+	err = ioutil.WriteFile(valuesFile, []byte("preview:\n  dependencies:\n  - name: redis\n  - name: redis\n"), 0600)
+	require.NoError(t, err)
+	_, err = previews.LoadDependencies(valuesFile)
+	assert.Error(t, err, "duplicate dependencies should be rejected")
+}
+
+func TestEnvironmentDependencies(t *testing.T) {
+	t.Parallel()
+
+	env := &v1.Environment{}
+	deps := []previews.Dependency{{Name: "redis", Chart: "bitnami/redis"}}
+	err := previews.SetEnvironmentDependencies(env, deps)
+	require.NoError(t, err)
+
+	actual, err := previews.GetEnvironmentDependencies(env)
+	require.NoError(t, err)
+	assert.Equal(t, deps, actual)
+
+	err = previews.SetEnvironmentDependencies(env, nil)
+	require.NoError(t, err)
+	assert.NotContains(t, env.Annotations, previews.AnnotationDependencies)
+}
+
+func TestManagerProvisionAndDeprovision(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeProvisioner{fail: "kafka"}
+	manager := previews.NewManager(helm_test.NewMockHelmer())
+	manager.Register("fake", fake)
+	ns := "jx-myorg-myapp-pr-1"
+
+	deps := []previews.Dependency{{Name: "postgres", Kind: "fake"}, {Name: "redis", Kind: "fake"}}
+	err := manager.Provision(deps, ns)
+	require.NoError(t, err)
+	assert.Equal(t, []string{ns + "/postgres", ns + "/redis"}, fake.provisioned)
+
+	err = manager.Provision([]previews.Dependency{{Name: "mongo", Kind: "unknown"}}, ns)
+	assert.Error(t, err)
+
+	// all the dependencies are torn down in the reverse order even if one fails
+	deps = append(deps, previews.Dependency{Name: "kafka", Kind: "fake"})
+	deps = append([]previews.Dependency{{Name: "elasticsearch", Kind: "fake"}}, deps...)
+	err = manager.Deprovision(deps, ns)
+	assert.EqualError(t, err, "failed to tear down the preview dependencies kafka")
+	assert.Equal(t, []string{ns + "/redis", ns + "/postgres", ns + "/elasticsearch"}, fake.deprovisioned)
+}
+
+func TestHelmProvisioner(t *testing.T) {
+	RegisterMockTestingT(t)
+
+	helmer := helm_test.NewMockHelmer()
+	manager := previews.NewManager(helmer)
+	ns := "jx-myorg-myapp-pr-1"
+	deps := []previews.Dependency{{Name: "postgres", Chart: "bitnami/postgresql", Version: "8.6.4"}}
+
+	err := manager.Provision(deps, ns)
+	require.NoError(t, err)
+	helmer.VerifyWasCalledOnce().UpgradeChart(EqString("bitnami/postgresql"), EqString(ns+"-postgres"), EqString(ns),
+		EqString("8.6.4"), EqBool(true), AnyInt(), EqBool(false), EqBool(true), AnyStringSlice(), AnyStringSlice(),
+		AnyStringSlice(), EqString(""), EqString(""), EqString(""))
+
+	err = manager.Deprovision(deps, ns)
+	require.NoError(t, err)
+	helmer.VerifyWasCalledOnce().DeleteRelease(EqString(ns), EqString(ns+"-postgres"), EqBool(true))
+}
+
+func TestCommandProvisioner(t *testing.T) {
+	t.Parallel()
+
+	var commands []*util.Command
+	p := &previews.CommandProvisioner{
+		Run: func(cmd *util.Command) (string, error) {
+			commands = append(commands, cmd)
+			return "", nil
+		},
+	}
+	dep := &previews.Dependency{Name: "queue", Kind: previews.KindCommand, Command: []string{"./queue.sh", "--region", "eu-west-1"}}
+
+	err := p.Provision(dep, "jx-myorg-myapp-pr-1")
+	require.NoError(t, err)
+	err = p.Deprovision(dep, "jx-myorg-myapp-pr-1")
+	require.NoError(t, err)
+
+	require.Len(t, commands, 2)
+	assert.Equal(t, "./queue.sh", commands[0].Name)
+	assert.Equal(t, []string{"--region", "eu-west-1", previews.ActionProvision}, commands[0].Args)
+	assert.Equal(t, []string{"--region", "eu-west-1", previews.ActionDeprovision}, commands[1].Args)
+	assert.Equal(t, "jx-myorg-myapp-pr-1", commands[1].Env["PREVIEW_NAMESPACE"])
+	assert.Equal(t, "queue", commands[1].Env["PREVIEW_DEPENDENCY"])
+}
@@ -0,0 +1,187 @@
+package profiles
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ProfileDemo a lightweight single replica installation for trying out Jenkins X
+	ProfileDemo = "demo"
+	// ProfileTeam a GitOps installation with TLS and vault for a team
+	ProfileTeam = "team"
+	// ProfileProduction an installation with TLS, vault, the monitoring addons and multiple replicas of the platform
+	// components
+	ProfileProduction = "production"
+
+	// SecretStorageLocal stores secrets in local files or kubernetes secrets
+	SecretStorageLocal = "local"
+	// SecretStorageVault stores secrets in vault
+	SecretStorageVault = "vault"
+
+	// ProfilesDir the directory inside the jx home dir where custom profiles are stored
+	ProfilesDir = "install-profiles"
+)
+
+// InstallProfile a coherent set of installation defaults for a given use case. Settings which are omitted from the
+// profile leave the corresponding install flags unchanged
+type InstallProfile struct {
+	// Name the name of the profile
+	Name string `json:"name"`
+	// Description a human readable description of the profile
+	Description string `json:"description,omitempty"`
+	// TLS enables TLS on the exposed ingress endpoints
+	TLS *bool `json:"tls,omitempty"`
+	// ExternalDNS installs external-dns to manage the DNS records of the domain
+	ExternalDNS *bool `json:"externalDNS,omitempty"`
+	// GitOps manages the dev environment via GitOps
+	GitOps *bool `json:"gitops,omitempty"`
+	// SecretStorage where secrets are stored: local or vault
+	SecretStorage string `json:"secretStorage,omitempty"`
+	// Addons additional addons to install
+	Addons []string `json:"addons,omitempty"`
+	// Values the helm values to apply to the platform chart
+	Values map[string]interface{} `json:"values,omitempty"`
+}
+
+var builtinProfiles = map[string]string{
+	ProfileDemo: `name: demo
+description: A lightweight single replica installation for trying out Jenkins X. Not suitable for real workloads
+tls: false
+externalDNS: false
+gitops: false
+secretStorage: local
+values:
+  chartmuseum:
+    persistence:
+      size: 2Gi
+  nexus:
+    enabled: false
+`,
+	ProfileTeam: `name: team
+description: A GitOps managed installation for a team with TLS and secrets stored in vault
+tls: true
+externalDNS: true
+gitops: true
+secretStorage: vault
+values:
+  chartmuseum:
+    persistence:
+      size: 8Gi
+`,
+	ProfileProduction: `name: production
+description: A highly available GitOps managed installation with TLS, secrets stored in vault and monitoring
+tls: true
+externalDNS: true
+gitops: true
+secretStorage: vault
+addons:
+- prometheus
+values:
+  chartmuseum:
+    replicaCount: 2
+    persistence:
+      size: 20Gi
+  controllerbuild:
+    replicaCount: 2
+  expose:
+    replicaCount: 2
+`,
+}
+
+// BuiltinProfileNames returns the sorted names of the profiles embedded in the binary
+func BuiltinProfileNames() []string {
+	answer := []string{}
+	for k := range builtinProfiles {
+		answer = append(answer, k)
+	}
+	sort.Strings(answer)
+	return answer
+}
+
+// BuiltinProfileYAML returns the YAML of the embedded profile so it can be exported and customized
+func BuiltinProfileYAML(name string) (string, error) {
+	text, ok := builtinProfiles[name]
+	if !ok {
+		return "", fmt.Errorf("unknown install profile '%s'. Supported profiles: %s", name, strings.Join(BuiltinProfileNames(), ", "))
+	}
+	return text, nil
+}
+
+// LoadProfile loads the profile for the given name or file. A custom profile is resolved first from a file path,
+// then from the install-profiles directory of the jx home dir and finally from the embedded profiles
+func LoadProfile(nameOrFile string) (*InstallProfile, error) {
+	exists, err := util.FileExists(nameOrFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "checking if file %s exists", nameOrFile)
+	}
+	if !exists {
+		dir, err := util.ConfigDir()
+		if err == nil {
+			customFile := filepath.Join(dir, ProfilesDir, nameOrFile+".yaml")
+			exists, err = util.FileExists(customFile)
+			if err != nil {
+				return nil, errors.Wrapf(err, "checking if file %s exists", customFile)
+			}
+			if exists {
+				nameOrFile = customFile
+			}
+		}
+	}
+	if exists {
+		data, err := ioutil.ReadFile(nameOrFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading install profile %s", nameOrFile)
+		}
+		return ParseProfile(data)
+	}
+	text, err := BuiltinProfileYAML(nameOrFile)
+	if err != nil {
+		return nil, err
+	}
+	return ParseProfile([]byte(text))
+}
+
+// ParseProfile parses and validates the given profile YAML
+func ParseProfile(data []byte) (*InstallProfile, error) {
+	profile := &InstallProfile{}
+	err := yaml.Unmarshal(data, profile)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshalling install profile")
+	}
+	if profile.SecretStorage != "" && profile.SecretStorage != SecretStorageLocal && profile.SecretStorage != SecretStorageVault {
+		return nil, fmt.Errorf("install profile %s has an invalid secretStorage '%s'. Supported values: %s, %s", profile.Name, profile.SecretStorage, SecretStorageLocal, SecretStorageVault)
+	}
+	return profile, nil
+}
+
+// WriteValuesFile writes the helm values of the profile to a temporary file returning the file name or an empty
+// string if the profile has no values
+func (p *InstallProfile) WriteValuesFile() (string, error) {
+	if len(p.Values) == 0 {
+		return "", nil
+	}
+	data, err := yaml.Marshal(p.Values)
+	if err != nil {
+		return "", errors.Wrapf(err, "marshalling the values of install profile %s", p.Name)
+	}
+	f, err := ioutil.TempFile("", "jx-profile-"+p.Name+"-*.yaml")
+	if err != nil {
+		return "", errors.Wrap(err, "creating temporary values file")
+	}
+	defer f.Close()
+	_, err = f.Write(data)
+	if err != nil {
+		os.Remove(f.Name()) //nolint:errcheck
+		return "", errors.Wrapf(err, "writing file %s", f.Name())
+	}
+	return f.Name(), nil
+}
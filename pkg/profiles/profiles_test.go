@@ -0,0 +1,67 @@
+// +build unit
+
+package profiles_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/profiles"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinProfiles(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"demo", "production", "team"}, profiles.BuiltinProfileNames())
+
+	for _, name := range profiles.BuiltinProfileNames() {
+		profile, err := profiles.LoadProfile(name)
+		require.NoError(t, err, "loading profile %s", name)
+		assert.Equal(t, name, profile.Name)
+	}
+
+	production, err := profiles.LoadProfile(profiles.ProfileProduction)
+	require.NoError(t, err)
+	require.NotNil(t, production.TLS)
+	assert.True(t, *production.TLS)
+	assert.Equal(t, profiles.SecretStorageVault, production.SecretStorage)
+	assert.Equal(t, []string{"prometheus"}, production.Addons)
+
+	_, err = profiles.LoadProfile("does-not-exist")
+	assert.Error(t, err)
+}
+
+func TestLoadCustomProfileFile(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-install-profile")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fileName := filepath.Join(dir, "custom.yaml")
+	err = ioutil.WriteFile(fileName, []byte("name: custom\ntls: true\nvalues:\n  nexus:\n    enabled: false\n"), 0600)
+	require.NoError(t, err)
+
+	profile, err := profiles.LoadProfile(fileName)
+	require.NoError(t, err)
+	assert.Equal(t, "custom", profile.Name)
+	require.NotNil(t, profile.TLS)
+	assert.True(t, *profile.TLS)
+	assert.Nil(t, profile.GitOps, "settings omitted from the profile should not be set")
+	assert.Nil(t, profile.ExternalDNS, "settings omitted from the profile should not be set")
+	assert.Equal(t, "", profile.SecretStorage)
+
+	valuesFile, err := profile.WriteValuesFile()
+	require.NoError(t, err)
+	defer os.Remove(valuesFile)
+	data, err := ioutil.ReadFile(valuesFile)
+	require.NoError(t, err)
+	assert.Equal(t, "nexus:\n  enabled: false\n", string(data))
+
+	_, err = profiles.ParseProfile([]byte("name: broken\nsecretStorage: floppy\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,444 @@
+package promotion
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// AnnotationGates the annotation of an Environment containing the comma separated gates a promotion has to pass
+	// before its Pull Request is merged
+	AnnotationGates = "jenkins.io/promotion-gates"
+	// AnnotationSmokeTest the annotation of an Environment containing the smoke test command of the smoke-tests gate
+	AnnotationSmokeTest = "jenkins.io/promotion-smoke-test"
+	// AnnotationCheckURL the annotation of an Environment containing the URL of the webhook of the check gate
+	AnnotationCheckURL = "jenkins.io/promotion-check-url"
+
+	// GateApproval the gate passed when the promotion is approved via jx approve promotion
+	GateApproval = "approval"
+	// GateSmokeTests the gate passed when the smoke test command succeeds
+	GateSmokeTests = "smoke-tests"
+	// GateCheck the gate passed when the check webhook accepts the promotion
+	GateCheck = "check"
+
+	// StatePending the gate has not been passed yet
+	StatePending = "pending"
+	// StatePassed the gate has been passed
+	StatePassed = "passed"
+	// StateFailed the gate failed so the promotion is not merged
+	StateFailed = "failed"
+
+	// ValueKindPromotion the kind label of the ConfigMaps storing the state of promotions
+	ValueKindPromotion = "promotion"
+	// ConfigKey the key of the promotion in its ConfigMap
+	ConfigKey = "promotion.yaml"
+
+	configMapPrefix = "jx-promotion-"
+)
+
+// Gates the gates a promotion can be configured with
+var Gates = []string{GateApproval, GateSmokeTests, GateCheck}
+
+// GateStatus the state of a gate of a promotion
+type GateStatus struct {
+	Name        string `json:"name"`
+	State       string `json:"state"`
+	Description string `json:"description,omitempty"`
+}
+
+// Promotion the state of the gates of the promotion of a version of an application to an environment
+type Promotion struct {
+	ID             string `json:"id"`
+	Application    string `json:"application"`
+	Version        string `json:"version"`
+	Environment    string `json:"environment"`
+	PullRequestURL string `json:"pullRequestURL,omitempty"`
+	// PullRequestNumber the number of the Pull Request so that queued promotions can be merged later
+	PullRequestNumber int    `json:"pullRequestNumber,omitempty"`
+	Created           string `json:"created,omitempty"`
+	ApprovedBy        string `json:"approvedBy,omitempty"`
+	// WindowOverridden indicates the promotion is merged outside the deployment windows of the environment
+	WindowOverridden bool         `json:"windowOverridden,omitempty"`
+	Gates            []GateStatus `json:"gates,omitempty"`
+}
+
+// ID returns the ID of the promotion of the version of the application to the environment
+func ID(app string, version string, env string) string {
+	return naming.ToValidNameWithDotsTruncated(app+"-"+version+"-"+env, 253-len(configMapPrefix))
+}
+
+// EnvironmentGates returns the gates configured on the environment
+func EnvironmentGates(env *v1.Environment) ([]string, error) {
+	answer := []string{}
+	for _, gate := range strings.Split(env.Annotations[AnnotationGates], ",") {
+		gate = strings.TrimSpace(gate)
+		if gate == "" {
+			continue
+		}
+		if util.StringArrayIndex(Gates, gate) < 0 {
+			return nil, errors.Errorf("unknown promotion gate %s in the %s annotation of environment %s, should be one of: %s",
+				gate, AnnotationGates, env.Name, strings.Join(Gates, ", "))
+		}
+		switch {
+		case gate == GateSmokeTests && env.Annotations[AnnotationSmokeTest] == "":
+			return nil, errors.Errorf("environment %s has the %s gate but no %s annotation", env.Name, gate, AnnotationSmokeTest)
+		case gate == GateCheck && env.Annotations[AnnotationCheckURL] == "":
+			return nil, errors.Errorf("environment %s has the %s gate but no %s annotation", env.Name, gate, AnnotationCheckURL)
+		}
+		answer = append(answer, gate)
+	}
+	windows, err := EnvironmentWindows(env)
+	if err != nil {
+		return nil, err
+	}
+	if windows != nil {
+		answer = append(answer, GateWindow)
+	}
+	return answer, nil
+}
+
+// State returns failed if any gate failed, pending if any gate is pending and otherwise passed
+func (p *Promotion) State() string {
+	answer := StatePassed
+	for _, g := range p.Gates {
+		if g.State == StateFailed {
+			return StateFailed
+		}
+		if g.State == StatePending {
+			answer = StatePending
+		}
+	}
+	return answer
+}
+
+// Gate returns the status of the gate or nil if the promotion does not have the gate
+func (p *Promotion) Gate(name string) *GateStatus {
+	for i := range p.Gates {
+		if p.Gates[i].Name == name {
+			return &p.Gates[i]
+		}
+	}
+	return nil
+}
+
+// Summary returns the states of the gates such as 'approval:pending smoke-tests:passed'
+func (p *Promotion) Summary() string {
+	answer := []string{}
+	for _, g := range p.Gates {
+		answer = append(answer, g.Name+":"+g.State)
+	}
+	return strings.Join(answer, " ")
+}
+
+// Approve approves the promotion by the user
+func (p *Promotion) Approve(user string) error {
+	gate := p.Gate(GateApproval)
+	if gate == nil {
+		return errors.Errorf("promotion %s does not require approval", p.ID)
+	}
+	p.ApprovedBy = user
+	gate.State = StatePassed
+	gate.Description = "approved by " + user
+	return nil
+}
+
+func configMapName(id string) string {
+	return configMapPrefix + id
+}
+
+// GetPromotion returns the promotion with the ID or nil if there is no such promotion
+func GetPromotion(kubeClient kubernetes.Interface, ns string, id string) (*Promotion, error) {
+	cm, err := kubeClient.CoreV1().ConfigMaps(ns).Get(configMapName(id), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "getting promotion %s in namespace %s", id, ns)
+	}
+	return unmarshalPromotion(cm)
+}
+
+// ListPromotions returns the promotions in the namespace sorted by the newest first
+func ListPromotions(kubeClient kubernetes.Interface, ns string) ([]*Promotion, error) {
+	list, err := kubeClient.CoreV1().ConfigMaps(ns).List(metav1.ListOptions{
+		LabelSelector: kube.LabelKind + "=" + ValueKindPromotion,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the promotions in namespace %s", ns)
+	}
+	answer := []*Promotion{}
+	for i := range list.Items {
+		p, err := unmarshalPromotion(&list.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		answer = append(answer, p)
+	}
+	sort.SliceStable(answer, func(i, j int) bool {
+		if answer[i].Created != answer[j].Created {
+			return answer[i].Created > answer[j].Created
+		}
+		return answer[i].ID < answer[j].ID
+	})
+	return answer, nil
+}
+
+// SavePromotion creates or updates the promotion
+func SavePromotion(kubeClient kubernetes.Interface, ns string, p *Promotion) error {
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return errors.Wrapf(err, "marshalling promotion %s", p.ID)
+	}
+	_, err = kube.DefaultModifyConfigMap(kubeClient, ns, configMapName(p.ID), func(cm *corev1.ConfigMap) error {
+		if cm.Labels == nil {
+			cm.Labels = map[string]string{}
+		}
+		cm.Labels[kube.LabelKind] = ValueKindPromotion
+		cm.Labels[kube.LabelCreatedBy] = kube.ValueCreatedByJX
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[ConfigKey] = string(data)
+		return nil
+	}, nil)
+	return err
+}
+
+func unmarshalPromotion(cm *corev1.ConfigMap) (*Promotion, error) {
+	p := &Promotion{}
+	err := yaml.Unmarshal([]byte(cm.Data[ConfigKey]), p)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshalling the %s key of ConfigMap %s", ConfigKey, cm.Name)
+	}
+	return p, nil
+}
+
+// EnsurePromotion creates or updates the promotion of the version of the application to the environment with the
+// gates of the environment from the application, version and Pull Request of the request, returning nil if the
+// environment has no gates. Any previous approval is kept but the smoke tests and the check are run again
+func EnsurePromotion(kubeClient kubernetes.Interface, ns string, env *v1.Environment, request *Promotion) (*Promotion, error) {
+	gates, err := EnvironmentGates(env)
+	if err != nil || len(gates) == 0 {
+		return nil, err
+	}
+	id := ID(request.Application, request.Version, env.Name)
+	p, err := GetPromotion(kubeClient, ns, id)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		p = &Promotion{
+			ID:          id,
+			Application: request.Application,
+			Version:     request.Version,
+			Environment: env.Name,
+			Created:     time.Now().UTC().Format(time.RFC3339),
+		}
+	}
+	p.PullRequestURL = request.PullRequestURL
+	p.PullRequestNumber = request.PullRequestNumber
+	p.WindowOverridden = request.WindowOverridden
+	statuses := []GateStatus{}
+	for _, gate := range gates {
+		status := GateStatus{Name: gate, State: StatePending}
+		if gate == GateApproval && p.ApprovedBy != "" {
+			status.State = StatePassed
+			status.Description = "approved by " + p.ApprovedBy
+		}
+		statuses = append(statuses, status)
+	}
+	p.Gates = statuses
+	err = SavePromotion(kubeClient, ns, p)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// CheckRequest the JSON body posted to the webhook of the check gate
+type CheckRequest struct {
+	ID             string `json:"id"`
+	Application    string `json:"application"`
+	Version        string `json:"version"`
+	Environment    string `json:"environment"`
+	PullRequestURL string `json:"pullRequestURL,omitempty"`
+}
+
+// CheckResponse the optional JSON body returned by the webhook of the check gate
+type CheckResponse struct {
+	// State one of pending, passed or failed
+	State       string `json:"state,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Evaluator evaluates the pending gates of promotions
+type Evaluator struct {
+	KubeClient kubernetes.Interface
+	Namespace  string
+	// HTTPClient the client posting to the check webhooks
+	HTTPClient *http.Client
+	// RunCommand runs the smoke tests, defaults to running them with util.Command
+	RunCommand func(cmd *util.Command) (string, error)
+	// Now returns the current time, defaults to time.Now
+	Now func() time.Time
+}
+
+// Evaluate evaluates the pending gates of the promotion to the environment, saving their new states. The smoke
+// tests are run at most once per promotion whereas the check webhook is polled until it passes or fails. The window
+// gate is evaluated every time as the deployment window may close again
+func (e *Evaluator) Evaluate(p *Promotion, env *v1.Environment) error {
+	if p.State() == StateFailed {
+		return nil
+	}
+	// lets pick up approvals made since the last evaluation
+	latest, err := GetPromotion(e.KubeClient, e.Namespace, p.ID)
+	if err != nil {
+		return err
+	}
+	if latest != nil && latest.ApprovedBy != "" && p.Gate(GateApproval) != nil {
+		err = p.Approve(latest.ApprovedBy)
+		if err != nil {
+			return err
+		}
+	}
+	for i := range p.Gates {
+		gate := &p.Gates[i]
+		if gate.Name == GateWindow {
+			err = e.checkWindow(p, env, gate)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if gate.State != StatePending {
+			continue
+		}
+		switch gate.Name {
+		case GateSmokeTests:
+			e.runSmokeTests(p, env, gate)
+		case GateCheck:
+			e.check(p, env, gate)
+		}
+	}
+	return SavePromotion(e.KubeClient, e.Namespace, p)
+}
+
+func (e *Evaluator) checkWindow(p *Promotion, env *v1.Environment, gate *GateStatus) error {
+	if p.WindowOverridden {
+		gate.State = StatePassed
+		gate.Description = "deployment window overridden"
+		return nil
+	}
+	windows, err := EnvironmentWindows(env)
+	if err != nil {
+		return err
+	}
+	if windows == nil {
+		gate.State = StatePassed
+		gate.Description = ""
+		return nil
+	}
+	now := time.Now()
+	if e.Now != nil {
+		now = e.Now()
+	}
+	gate.State = StatePending
+	if open, _ := windows.Open(now); open {
+		gate.State = StatePassed
+	}
+	gate.Description = windows.Describe(now)
+	return nil
+}
+
+func (e *Evaluator) runSmokeTests(p *Promotion, env *v1.Environment, gate *GateStatus) {
+	args := strings.Fields(env.Annotations[AnnotationSmokeTest])
+	cmd := &util.Command{
+		Name: args[0],
+		Args: args[1:],
+		Env: map[string]string{
+			"PROMOTION_ID":          p.ID,
+			"PROMOTION_APPLICATION": p.Application,
+			"PROMOTION_VERSION":     p.Version,
+			"PROMOTION_ENVIRONMENT": p.Environment,
+		},
+	}
+	run := e.RunCommand
+	if run == nil {
+		run = func(cmd *util.Command) (string, error) {
+			return cmd.RunWithoutRetry()
+		}
+	}
+	_, err := run(cmd)
+	if err != nil {
+		gate.State = StateFailed
+		gate.Description = fmt.Sprintf("%s failed: %s", cmd.String(), err)
+		return
+	}
+	gate.State = StatePassed
+	gate.Description = cmd.String() + " succeeded"
+}
+
+// check posts the promotion to the webhook. A 200 response passes the gate unless its body has another state, a 202
+// or 5xx response or a failure to connect leaves the gate pending and any other response fails the gate
+func (e *Evaluator) check(p *Promotion, env *v1.Environment, gate *GateStatus) {
+	url := env.Annotations[AnnotationCheckURL]
+	data, err := json.Marshal(&CheckRequest{
+		ID:             p.ID,
+		Application:    p.Application,
+		Version:        p.Version,
+		Environment:    p.Environment,
+		PullRequestURL: p.PullRequestURL,
+	})
+	if err != nil {
+		gate.Description = err.Error()
+		return
+	}
+	client := e.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		gate.Description = fmt.Sprintf("failed to post to %s: %s", url, err)
+		return
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	result := CheckResponse{}
+	if len(bytes.TrimSpace(body)) > 0 {
+		// the body is optional so ignore responses which are not JSON
+		_ = json.Unmarshal(body, &result)
+	}
+	gate.Description = result.Description
+	switch {
+	case resp.StatusCode == http.StatusAccepted || resp.StatusCode >= 500:
+		gate.State = StatePending
+	case resp.StatusCode == http.StatusOK:
+		gate.State = StatePassed
+		if result.State == StatePending || result.State == StateFailed {
+			gate.State = result.State
+		}
+	default:
+		gate.State = StateFailed
+	}
+	if gate.Description == "" {
+		gate.Description = fmt.Sprintf("%s returned %s", url, resp.Status)
+	}
+}
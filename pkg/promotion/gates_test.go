@@ -0,0 +1,159 @@
+// +build unit
+
+package promotion_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/promotion"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newEnvironment(annotations map[string]string) *v1.Environment {
+	return &v1.Environment{
+		ObjectMeta: metav1.ObjectMeta{Name: "production", Namespace: "jx", Annotations: annotations},
+		Spec:       v1.EnvironmentSpec{Kind: v1.EnvironmentKindTypePermanent, Namespace: "jx-production"},
+	}
+}
+
+func TestEnvironmentGates(t *testing.T) {
+	t.Parallel()
+
+	gates, err := promotion.EnvironmentGates(newEnvironment(nil))
+	require.NoError(t, err)
+	assert.Empty(t, gates)
+
+	gates, err = promotion.EnvironmentGates(newEnvironment(map[string]string{
+		promotion.AnnotationGates:     "approval, smoke-tests",
+		promotion.AnnotationSmokeTest: "./smoke-test.sh",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{promotion.GateApproval, promotion.GateSmokeTests}, gates)
+
+	_, err = promotion.EnvironmentGates(newEnvironment(map[string]string{promotion.AnnotationGates: "approvals"}))
+	assert.Error(t, err, "unknown gates should be rejected")
+	_, err = promotion.EnvironmentGates(newEnvironment(map[string]string{promotion.AnnotationGates: "check"}))
+	assert.Error(t, err, "the check gate requires a URL")
+}
+
+func TestPromotionApproval(t *testing.T) {
+	t.Parallel()
+
+	kubeClient := fake.NewSimpleClientset()
+	env := newEnvironment(map[string]string{promotion.AnnotationGates: promotion.GateApproval})
+
+	p, err := promotion.EnsurePromotion(kubeClient, "jx", env, &promotion.Promotion{Application: "myapp", Version: "1.2.3", PullRequestURL: "https://github.com/myorg/environment-production/pull/1"})
+	require.NoError(t, err)
+	require.NotNil(t, p)
+	assert.Equal(t, "myapp-1.2.3-production", p.ID)
+	assert.Equal(t, promotion.StatePending, p.State())
+
+	evaluator := &promotion.Evaluator{KubeClient: kubeClient, Namespace: "jx"}
+	err = evaluator.Evaluate(p, env)
+	require.NoError(t, err)
+	assert.Equal(t, promotion.StatePending, p.State())
+
+	// approve the promotion as jx approve promotion does
+	approval, err := promotion.GetPromotion(kubeClient, "jx", p.ID)
+	require.NoError(t, err)
+	require.NoError(t, approval.Approve("james"))
+	require.NoError(t, promotion.SavePromotion(kubeClient, "jx", approval))
+
+	err = evaluator.Evaluate(p, env)
+	require.NoError(t, err)
+	assert.Equal(t, promotion.StatePassed, p.State())
+	assert.Equal(t, "approval:passed", p.Summary())
+
+	// promoting the same version again keeps the approval
+	p, err = promotion.EnsurePromotion(kubeClient, "jx", env, &promotion.Promotion{Application: "myapp", Version: "1.2.3", PullRequestURL: "https://github.com/myorg/environment-production/pull/2"})
+	require.NoError(t, err)
+	assert.Equal(t, promotion.StatePassed, p.State())
+
+	promotions, err := promotion.ListPromotions(kubeClient, "jx")
+	require.NoError(t, err)
+	require.Len(t, promotions, 1)
+	assert.Equal(t, "https://github.com/myorg/environment-production/pull/2", promotions[0].PullRequestURL)
+	assert.Equal(t, "james", promotions[0].ApprovedBy)
+}
+
+func TestPromotionSmokeTestsAndCheck(t *testing.T) {
+	t.Parallel()
+
+	responses := []int{http.StatusAccepted, http.StatusOK}
+	requests := []promotion.CheckRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := promotion.CheckRequest{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		w.WriteHeader(responses[0])
+		responses = responses[1:]
+	}))
+	defer server.Close()
+
+	kubeClient := fake.NewSimpleClientset()
+	env := newEnvironment(map[string]string{
+		promotion.AnnotationGates:     "smoke-tests,check",
+		promotion.AnnotationSmokeTest: "./smoke-test.sh --env production",
+		promotion.AnnotationCheckURL:  server.URL,
+	})
+	p, err := promotion.EnsurePromotion(kubeClient, "jx", env, &promotion.Promotion{Application: "myapp", Version: "1.2.3"})
+	require.NoError(t, err)
+
+	commands := []*util.Command{}
+	evaluator := &promotion.Evaluator{
+		KubeClient: kubeClient,
+		Namespace:  "jx",
+		RunCommand: func(cmd *util.Command) (string, error) {
+			commands = append(commands, cmd)
+			return "", nil
+		},
+	}
+	err = evaluator.Evaluate(p, env)
+	require.NoError(t, err)
+	assert.Equal(t, "smoke-tests:passed check:pending", p.Summary())
+
+	err = evaluator.Evaluate(p, env)
+	require.NoError(t, err)
+	assert.Equal(t, promotion.StatePassed, p.State())
+
+	require.Len(t, commands, 1, "the smoke tests should only run once")
+	assert.Equal(t, "./smoke-test.sh", commands[0].Name)
+	assert.Equal(t, []string{"--env", "production"}, commands[0].Args)
+	assert.Equal(t, "1.2.3", commands[0].Env["PROMOTION_VERSION"])
+	require.Len(t, requests, 2)
+	assert.Equal(t, "myapp-1.2.3-production", requests[0].ID)
+	assert.Equal(t, "production", requests[0].Environment)
+}
+
+func TestPromotionFailedSmokeTests(t *testing.T) {
+	t.Parallel()
+
+	kubeClient := fake.NewSimpleClientset()
+	env := newEnvironment(map[string]string{
+		promotion.AnnotationGates:     "approval,smoke-tests",
+		promotion.AnnotationSmokeTest: "./smoke-test.sh",
+	})
+	p, err := promotion.EnsurePromotion(kubeClient, "jx", env, &promotion.Promotion{Application: "myapp", Version: "1.2.3"})
+	require.NoError(t, err)
+
+	evaluator := &promotion.Evaluator{
+		KubeClient: kubeClient,
+		Namespace:  "jx",
+		RunCommand: func(cmd *util.Command) (string, error) {
+			return "", errors.New("exit status 1")
+		},
+	}
+	err = evaluator.Evaluate(p, env)
+	require.NoError(t, err)
+	assert.Equal(t, promotion.StateFailed, p.State())
+	assert.Contains(t, p.Gate(promotion.GateSmokeTests).Description, "exit status 1")
+}
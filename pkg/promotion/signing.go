@@ -0,0 +1,21 @@
+package promotion
+
+import (
+	"strconv"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+)
+
+// AnnotationRequireSignedCommits the annotation of an Environment which, if true, requires the commits of the
+// promotions to the environment to be signed
+const AnnotationRequireSignedCommits = "jenkins.io/require-signed-commits"
+
+// RequiresSignedCommits returns true if the commits of the promotions to the environment have to be signed
+func RequiresSignedCommits(env *v1.Environment) bool {
+	if env == nil {
+		return false
+	}
+	required, _ := strconv.ParseBool(strings.TrimSpace(env.Annotations[AnnotationRequireSignedCommits]))
+	return required
+}
@@ -0,0 +1,250 @@
+package promotion
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/pkg/errors"
+)
+
+const (
+	// AnnotationWindows the annotation of an Environment containing the semicolon separated cron expressions of the
+	// minutes during which promotions to the environment can be merged, such as '* 9-16 * * 1-5'
+	AnnotationWindows = "jenkins.io/promotion-windows"
+	// AnnotationFreezes the annotation of an Environment containing the comma separated periods during which no
+	// promotions are merged, each one an RFC 3339 start and end time separated by a slash
+	AnnotationFreezes = "jenkins.io/promotion-freezes"
+	// AnnotationTimeZone the annotation of an Environment containing the time zone of its deployment windows,
+	// defaults to UTC
+	AnnotationTimeZone = "jenkins.io/promotion-timezone"
+
+	// GateWindow the gate passed while the deployment window of the environment is open. It is added to the
+	// promotions of the environments with deployment windows or freeze periods
+	GateWindow = "window"
+
+	// maxWindowSearch how far ahead to look for the next time a deployment window opens
+	maxWindowSearch = 366 * 24 * time.Hour
+)
+
+// Freeze a period during which no promotions are merged
+type Freeze struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Windows the deployment windows and freeze periods of an environment
+type Windows struct {
+	Schedules []*Schedule
+	Freezes   []Freeze
+	Location  *time.Location
+}
+
+// EnvironmentWindows returns the deployment windows of the environment or nil if it has none
+func EnvironmentWindows(env *v1.Environment) (*Windows, error) {
+	windowsText := strings.TrimSpace(env.Annotations[AnnotationWindows])
+	freezesText := strings.TrimSpace(env.Annotations[AnnotationFreezes])
+	if windowsText == "" && freezesText == "" {
+		return nil, nil
+	}
+	w := &Windows{Location: time.UTC}
+	if tz := env.Annotations[AnnotationTimeZone]; tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, errors.Wrapf(err, "loading the time zone %s of environment %s", tz, env.Name)
+		}
+		w.Location = loc
+	}
+	for _, expr := range strings.Split(windowsText, ";") {
+		expr = strings.TrimSpace(expr)
+		if expr == "" {
+			continue
+		}
+		s, err := ParseSchedule(expr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing the %s annotation of environment %s", AnnotationWindows, env.Name)
+		}
+		w.Schedules = append(w.Schedules, s)
+	}
+	for _, text := range strings.Split(freezesText, ",") {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		f, err := parseFreeze(text)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing the %s annotation of environment %s", AnnotationFreezes, env.Name)
+		}
+		w.Freezes = append(w.Freezes, f)
+	}
+	return w, nil
+}
+
+func parseFreeze(text string) (Freeze, error) {
+	f := Freeze{}
+	parts := strings.Split(text, "/")
+	if len(parts) != 2 {
+		return f, errors.Errorf("freeze period %s should be a start and end time separated by a slash", text)
+	}
+	var err error
+	f.Start, err = time.Parse(time.RFC3339, strings.TrimSpace(parts[0]))
+	if err != nil {
+		return f, errors.Wrapf(err, "parsing the start of freeze period %s", text)
+	}
+	f.End, err = time.Parse(time.RFC3339, strings.TrimSpace(parts[1]))
+	if err != nil {
+		return f, errors.Wrapf(err, "parsing the end of freeze period %s", text)
+	}
+	if !f.End.After(f.Start) {
+		return f, errors.Errorf("freeze period %s ends before it starts", text)
+	}
+	return f, nil
+}
+
+// Open returns true if promotions can be merged at the time, otherwise the reason why not
+func (w *Windows) Open(t time.Time) (bool, string) {
+	for _, f := range w.Freezes {
+		if !t.Before(f.Start) && t.Before(f.End) {
+			return false, fmt.Sprintf("frozen until %s", f.End.Format(time.RFC3339))
+		}
+	}
+	if len(w.Schedules) == 0 {
+		return true, ""
+	}
+	local := t.In(w.Location)
+	for _, s := range w.Schedules {
+		if s.Matches(local) {
+			return true, ""
+		}
+	}
+	return false, "outside the deployment window"
+}
+
+// NextOpen returns the next time from the time promotions can be merged, returning false if the windows do not open
+// within a year
+func (w *Windows) NextOpen(t time.Time) (time.Time, bool) {
+	next := t.Truncate(time.Minute)
+	end := t.Add(maxWindowSearch)
+	for !next.After(end) {
+		if open, _ := w.Open(next); open {
+			if next.Before(t) {
+				return t, true
+			}
+			return next, true
+		}
+		next = next.Add(time.Minute)
+	}
+	return time.Time{}, false
+}
+
+// Describe describes when promotions can be merged after the time
+func (w *Windows) Describe(t time.Time) string {
+	open, reason := w.Open(t)
+	if open {
+		return "deployment window open"
+	}
+	next, ok := w.NextOpen(t)
+	if !ok {
+		return reason + ", queued as the window does not open within a year"
+	}
+	return fmt.Sprintf("%s, queued until %s", reason, next.In(w.Location).Format(time.RFC3339))
+}
+
+// Schedule a cron expression of minute, hour, day of month, month and day of week
+type Schedule struct {
+	minutes     []bool
+	hours       []bool
+	daysOfMonth []bool
+	months      []bool
+	daysOfWeek  []bool
+
+	anyDayOfMonth bool
+	anyDayOfWeek  bool
+}
+
+// ParseSchedule parses a cron expression of five fields supporting '*', lists, ranges and steps
+func ParseSchedule(expr string) (*Schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("cron expression %s should have 5 fields but has %d", expr, len(fields))
+	}
+	s := &Schedule{
+		anyDayOfMonth: fields[2] == "*",
+		anyDayOfWeek:  fields[4] == "*",
+	}
+	var err error
+	if s.minutes, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, errors.Wrapf(err, "parsing the minutes of %s", expr)
+	}
+	if s.hours, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, errors.Wrapf(err, "parsing the hours of %s", expr)
+	}
+	if s.daysOfMonth, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, errors.Wrapf(err, "parsing the days of month of %s", expr)
+	}
+	if s.months, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, errors.Wrapf(err, "parsing the months of %s", expr)
+	}
+	if s.daysOfWeek, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, errors.Wrapf(err, "parsing the days of week of %s", expr)
+	}
+	// both 0 and 7 are Sunday
+	s.daysOfWeek[0] = s.daysOfWeek[0] || s.daysOfWeek[7]
+	return s, nil
+}
+
+// Matches returns true if the minute of the time matches the schedule. Like cron, if both the day of month and the
+// day of week are restricted then either of them has to match
+func (s *Schedule) Matches(t time.Time) bool {
+	if !s.minutes[t.Minute()] || !s.hours[t.Hour()] || !s.months[int(t.Month())] {
+		return false
+	}
+	dom := s.daysOfMonth[t.Day()]
+	dow := s.daysOfWeek[int(t.Weekday())]
+	if !s.anyDayOfMonth && !s.anyDayOfWeek {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+func parseCronField(field string, min int, max int) ([]bool, error) {
+	answer := make([]bool, max+1)
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return nil, errors.Errorf("invalid step %s", part[i+1:])
+			}
+			step = n
+			part = part[:i]
+		}
+		from, to := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			from, err = strconv.Atoi(bounds[0])
+			if err != nil {
+				return nil, errors.Errorf("invalid value %s", bounds[0])
+			}
+			to = from
+			if len(bounds) == 2 {
+				to, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return nil, errors.Errorf("invalid value %s", bounds[1])
+				}
+			} else if step > 1 {
+				to = max
+			}
+		}
+		if from < min || to > max || from > to {
+			return nil, errors.Errorf("%s is outside the range %d-%d", part, min, max)
+		}
+		for v := from; v <= to; v += step {
+			answer[v] = true
+		}
+	}
+	return answer, nil
+}
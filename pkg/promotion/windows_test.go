@@ -0,0 +1,120 @@
+// +build unit
+
+package promotion_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/promotion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func parseTime(t *testing.T, text string) time.Time {
+	answer, err := time.Parse(time.RFC3339, text)
+	require.NoError(t, err)
+	return answer
+}
+
+func TestParseSchedule(t *testing.T) {
+	t.Parallel()
+
+	s, err := promotion.ParseSchedule("*/15 9-16 * * 1-5")
+	require.NoError(t, err)
+	// 2026-10-19 is a Monday
+	assert.True(t, s.Matches(parseTime(t, "2026-10-19T09:30:00Z")))
+	assert.False(t, s.Matches(parseTime(t, "2026-10-19T09:31:00Z")))
+	assert.False(t, s.Matches(parseTime(t, "2026-10-19T17:00:00Z")))
+	assert.False(t, s.Matches(parseTime(t, "2026-10-18T10:00:00Z")), "Sunday is outside the window")
+
+	s, err = promotion.ParseSchedule("* * 1 * 7")
+	require.NoError(t, err)
+	assert.True(t, s.Matches(parseTime(t, "2026-10-18T10:00:00Z")), "either the day of month or the day of week should match")
+	assert.True(t, s.Matches(parseTime(t, "2026-10-01T10:00:00Z")))
+	assert.False(t, s.Matches(parseTime(t, "2026-10-19T10:00:00Z")))
+
+	for _, expr := range []string{"* * * *", "60 * * * *", "* 5-2 * * *", "*/0 * * * *", "a * * * *"} {
+		_, err = promotion.ParseSchedule(expr)
+		assert.Error(t, err, "cron expression %s should be invalid", expr)
+	}
+}
+
+func TestEnvironmentWindows(t *testing.T) {
+	t.Parallel()
+
+	windows, err := promotion.EnvironmentWindows(newEnvironment(nil))
+	require.NoError(t, err)
+	assert.Nil(t, windows)
+
+	env := newEnvironment(map[string]string{
+		promotion.AnnotationWindows:  "* 9-16 * * 1-5",
+		promotion.AnnotationFreezes:  "2026-12-20T00:00:00Z/2027-01-04T00:00:00Z",
+		promotion.AnnotationTimeZone: "UTC",
+	})
+	windows, err = promotion.EnvironmentWindows(env)
+	require.NoError(t, err)
+	require.NotNil(t, windows)
+
+	open, _ := windows.Open(parseTime(t, "2026-10-19T10:00:00Z"))
+	assert.True(t, open)
+
+	friday := parseTime(t, "2026-10-23T18:00:00Z")
+	open, reason := windows.Open(friday)
+	assert.False(t, open)
+	assert.Equal(t, "outside the deployment window", reason)
+	next, ok := windows.NextOpen(friday)
+	require.True(t, ok)
+	assert.Equal(t, parseTime(t, "2026-10-26T09:00:00Z"), next, "the window should open on Monday morning")
+
+	open, reason = windows.Open(parseTime(t, "2026-12-21T10:00:00Z"))
+	assert.False(t, open, "freeze periods close the window")
+	assert.Equal(t, "frozen until 2027-01-04T00:00:00Z", reason)
+
+	gates, err := promotion.EnvironmentGates(env)
+	require.NoError(t, err)
+	assert.Equal(t, []string{promotion.GateWindow}, gates)
+
+	_, err = promotion.EnvironmentWindows(newEnvironment(map[string]string{promotion.AnnotationFreezes: "2027-01-04T00:00:00Z/2026-12-20T00:00:00Z"}))
+	assert.Error(t, err, "freeze periods ending before they start should be rejected")
+}
+
+func TestPromotionWindowGate(t *testing.T) {
+	t.Parallel()
+
+	kubeClient := fake.NewSimpleClientset()
+	env := newEnvironment(map[string]string{promotion.AnnotationWindows: "* 9-16 * * 1-5"})
+	now := parseTime(t, "2026-10-24T12:00:00Z")
+	evaluator := &promotion.Evaluator{
+		KubeClient: kubeClient,
+		Namespace:  "jx",
+		Now: func() time.Time {
+			return now
+		},
+	}
+
+	p, err := promotion.EnsurePromotion(kubeClient, "jx", env, &promotion.Promotion{Application: "myapp", Version: "1.2.3", PullRequestNumber: 7})
+	require.NoError(t, err)
+	err = evaluator.Evaluate(p, env)
+	require.NoError(t, err)
+	assert.Equal(t, promotion.StatePending, p.State())
+	assert.Equal(t, "outside the deployment window, queued until 2026-10-26T09:00:00Z", p.Gate(promotion.GateWindow).Description)
+
+	now = parseTime(t, "2026-10-26T09:05:00Z")
+	err = evaluator.Evaluate(p, env)
+	require.NoError(t, err)
+	assert.Equal(t, promotion.StatePassed, p.State())
+
+	// overriding the window passes the gate straight away
+	p, err = promotion.EnsurePromotion(kubeClient, "jx", env, &promotion.Promotion{Application: "myapp", Version: "1.2.4", WindowOverridden: true})
+	require.NoError(t, err)
+	now = parseTime(t, "2026-10-24T12:00:00Z")
+	err = evaluator.Evaluate(p, env)
+	require.NoError(t, err)
+	assert.Equal(t, promotion.StatePassed, p.State())
+
+	promotions, err := promotion.ListPromotions(kubeClient, "jx")
+	require.NoError(t, err)
+	require.Len(t, promotions, 2)
+}
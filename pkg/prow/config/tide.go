@@ -115,6 +115,11 @@ func RemoveRepoFromTideConfig(t *keeper.Config, repo string, kind Kind) error {
 	return nil
 }
 
+// ApplicationTideQuery returns the default Tide query of the Pull Requests of applications which can be merged
+func ApplicationTideQuery() keeper.Query {
+	return createApplicationTideQuery()
+}
+
 func createApplicationTideQuery() keeper.Query {
 	return keeper.Query{
 		Repos:         []string{"jenkins-x/dummy"},
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"regexp"
 	"strings"
 
 	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
@@ -50,6 +51,19 @@ type Options struct {
 	IgnoreBranch         bool
 	PluginsFileLocation  string
 	ConfigFileLocation   string
+	// Modules the modules of a monorepo application with a pipeline each
+	Modules []Module
+	// RunIfChanged the regular expression of the changed files which trigger the pipelines of an application, they
+	// are triggered by any change if empty
+	RunIfChanged string
+}
+
+// Module a module of a monorepo whose pipelines are triggered by the changes in its directory
+type Module struct {
+	// Name the name of the context of the pipelines of the module
+	Name string
+	// Dir the directory of the module relative to the root of the repository
+	Dir string
 }
 
 type ExternalPlugins struct {
@@ -108,6 +122,53 @@ func AddApplication(kubeClient kubernetes.Interface, repos []string, ns, draftPa
 	return add(kubeClient, repos, ns, prowconfig.Application, draftPack, "", "", teamSettings)
 }
 
+// AddApplicationModules adds a monorepo app git repo config with the pipelines of its modules
+func AddApplicationModules(kubeClient kubernetes.Interface, repos []string, ns, draftPack string, modules []Module) error {
+	if len(repos) == 0 {
+		return fmt.Errorf("no repo defined")
+	}
+	o := Options{
+		KubeClient: kubeClient,
+		Repos:      repos,
+		NS:         ns,
+		Kind:       prowconfig.Application,
+		DraftPack:  draftPack,
+		Agent:      TektonAgent,
+		Modules:    modules,
+	}
+	if err := o.AddProwConfig(); err != nil {
+		return errors.Wrap(err, "adding prow config")
+	}
+	if err := o.AddProwPlugins(); err != nil {
+		return errors.Wrap(err, "adding prow plugins")
+	}
+	return nil
+}
+
+// AddApplicationWithRunIfChanged adds an app git repo config whose pipelines are only triggered by changes to the
+// files matching the regular expression
+func AddApplicationWithRunIfChanged(kubeClient kubernetes.Interface, repos []string, ns, draftPack string, runIfChanged string) error {
+	if len(repos) == 0 {
+		return fmt.Errorf("no repo defined")
+	}
+	o := Options{
+		KubeClient:   kubeClient,
+		Repos:        repos,
+		NS:           ns,
+		Kind:         prowconfig.Application,
+		DraftPack:    draftPack,
+		Agent:        TektonAgent,
+		RunIfChanged: runIfChanged,
+	}
+	if err := o.AddProwConfig(); err != nil {
+		return errors.Wrap(err, "adding prow config")
+	}
+	if err := o.AddProwPlugins(); err != nil {
+		return errors.Wrap(err, "adding prow plugins")
+	}
+	return nil
+}
+
 // DeleteApplication will delete the Prow configuration for a given set of repositories
 func DeleteApplication(kubeClient kubernetes.Interface, repos []string, ns string) error {
 	return remove(kubeClient, repos, ns, prowconfig.Application)
@@ -168,6 +229,7 @@ func (o *Options) createPostSubmitApplication() job.Postsubmit {
 	ps.Branches = []string{"^master$"}
 	ps.Name = "release"
 	ps.Agent = o.Agent
+	ps.RunIfChanged = o.RunIfChanged
 
 	return ps
 }
@@ -186,23 +248,58 @@ func (o *Options) createPreSubmitApplication() job.Presubmit {
 	ps.RerunCommand = "/test this"
 	ps.Trigger = "(?m)^/test( all| this),?(\\s+|$)"
 
+	if o.RunIfChanged != "" {
+		ps.AlwaysRun = false
+		ps.RunIfChanged = o.RunIfChanged
+	}
 	return ps
 }
 
+// createModuleJobs creates the jobs of the modules of a monorepo only running when their directories change
+func (o *Options) createModuleJobs() ([]job.Presubmit, []job.Postsubmit) {
+	preSubmits := []job.Presubmit{}
+	postSubmits := []job.Postsubmit{}
+	for _, m := range o.Modules {
+		runIfChanged := "^" + regexp.QuoteMeta(strings.TrimSuffix(m.Dir, "/")) + "/"
+
+		ps := job.Presubmit{}
+		ps.Name = m.Name
+		ps.Context = m.Name
+		ps.RunIfChanged = runIfChanged
+		ps.RerunCommand = "/test " + m.Name
+		ps.Trigger = fmt.Sprintf("(?m)^/test( all| %s),?(\\s+|$)", regexp.QuoteMeta(m.Name))
+		ps.Agent = o.Agent
+		preSubmits = append(preSubmits, ps)
+
+		post := job.Postsubmit{}
+		post.Branches = []string{"^master$"}
+		post.Name = "release-" + m.Name
+		post.Context = m.Name
+		post.RunIfChanged = runIfChanged
+		post.Agent = o.Agent
+		postSubmits = append(postSubmits, post)
+	}
+	return preSubmits, postSubmits
+}
+
 // AddProwConfig adds config to Prow
 func (o *Options) AddProwConfig() error {
-	var preSubmit job.Presubmit
-	var postSubmit job.Postsubmit
+	var preSubmits []job.Presubmit
+	var postSubmits []job.Postsubmit
 
 	switch o.Kind {
 	case prowconfig.Application:
-		preSubmit = o.createPreSubmitApplication()
-		postSubmit = o.createPostSubmitApplication()
+		if len(o.Modules) > 0 {
+			preSubmits, postSubmits = o.createModuleJobs()
+		} else {
+			preSubmits = []job.Presubmit{o.createPreSubmitApplication()}
+			postSubmits = []job.Postsubmit{o.createPostSubmitApplication()}
+		}
 	case prowconfig.Environment:
-		preSubmit = o.createPreSubmitEnvironment()
-		postSubmit = o.createPostSubmitEnvironment()
+		preSubmits = []job.Presubmit{o.createPreSubmitEnvironment()}
+		postSubmits = []job.Postsubmit{o.createPostSubmitEnvironment()}
 	case prowconfig.RemoteEnvironment:
-		preSubmit = o.createPreSubmitEnvironment()
+		preSubmits = []job.Presubmit{o.createPreSubmitEnvironment()}
 	case prowconfig.Protection:
 		// Nothing needed
 	default:
@@ -222,6 +319,10 @@ func (o *Options) AddProwConfig() error {
 		if err != nil {
 			return errors.Wrapf(err, "adding repo %q to tide config", r)
 		}
+		if len(o.Modules) > 0 || o.RunIfChanged != "" {
+			// the jobs only run on changes to some files so cannot be required
+			continue
+		}
 		err = prowconfig.AddRepoToBranchProtection(&prowConfig.BranchProtection, r, o.Context, o.Kind)
 		if err != nil {
 			return errors.Wrapf(err, "adding repo %q to branch protection", r)
@@ -235,7 +336,10 @@ func (o *Options) AddProwConfig() error {
 		if prowConfig.Postsubmits[r] == nil {
 			prowConfig.Postsubmits[r] = make([]job.Postsubmit, 0)
 		}
-		if preSubmit.Name != "" {
+		for _, preSubmit := range preSubmits {
+			if preSubmit.Name == "" {
+				continue
+			}
 			found := false
 			for i, j := range prowConfig.Presubmits[r] {
 				if j.Name == preSubmit.Name {
@@ -248,7 +352,10 @@ func (o *Options) AddProwConfig() error {
 				prowConfig.Presubmits[r] = append(prowConfig.Presubmits[r], preSubmit)
 			}
 		}
-		if o.Kind != prowconfig.RemoteEnvironment && postSubmit.Name != "" {
+		for _, postSubmit := range postSubmits {
+			if o.Kind == prowconfig.RemoteEnvironment || postSubmit.Name == "" {
+				continue
+			}
 			found := false
 			for i, j := range prowConfig.Postsubmits[r] {
 				if j.Name == postSubmit.Name {
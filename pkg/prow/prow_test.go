@@ -280,6 +280,78 @@ func TestAddProwConfig(t *testing.T) {
 	}
 }
 
+func TestAddProwConfigApplicationModules(t *testing.T) {
+	t.Parallel()
+	o := TestOptions{}
+	o.Setup()
+	o.Kind = prowconfig.Application
+	o.Modules = []prow.Module{{Name: "api", Dir: "services/api"}, {Name: "web", Dir: "web/"}}
+
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: kube.IngressConfigConfigmap,
+		},
+		Data: map[string]string{"domain": "dummy.domain.nip.io", "tls": "false"},
+	}
+	_, err := o.KubeClient.CoreV1().ConfigMaps(o.NS).Create(cm)
+	assert.NoError(t, err)
+
+	err = o.AddProwConfig()
+	assert.NoError(t, err)
+
+	prowConfig, err := getProwConfig(t, o)
+	assert.NoError(t, err)
+
+	presubmits := prowConfig.Presubmits["test/repo"]
+	assert.Len(t, presubmits, 2)
+	assert.Equal(t, "api", presubmits[0].Context)
+	assert.Equal(t, "^services/api/", presubmits[0].RunIfChanged)
+	assert.False(t, presubmits[0].AlwaysRun)
+	assert.Equal(t, "/test api", presubmits[0].RerunCommand)
+
+	postsubmits := prowConfig.Postsubmits["test/repo"]
+	assert.Len(t, postsubmits, 2)
+	assert.Equal(t, "release-web", postsubmits[1].Name)
+	assert.Equal(t, "web", postsubmits[1].Context)
+	assert.Equal(t, "^web/", postsubmits[1].RunIfChanged)
+
+	assert.Empty(t, prowConfig.BranchProtection.Orgs, "the jobs of modules should not be required status checks")
+}
+
+func TestAddProwConfigApplicationWithRunIfChanged(t *testing.T) {
+	t.Parallel()
+	o := TestOptions{}
+	o.Setup()
+	o.Kind = prowconfig.Application
+	o.RunIfChanged = "^(services/api(/.*)?)$"
+
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: kube.IngressConfigConfigmap,
+		},
+		Data: map[string]string{"domain": "dummy.domain.nip.io", "tls": "false"},
+	}
+	_, err := o.KubeClient.CoreV1().ConfigMaps(o.NS).Create(cm)
+	assert.NoError(t, err)
+
+	err = o.AddProwConfig()
+	assert.NoError(t, err)
+
+	prowConfig, err := getProwConfig(t, o)
+	assert.NoError(t, err)
+
+	presubmits := prowConfig.Presubmits["test/repo"]
+	assert.Len(t, presubmits, 1)
+	assert.Equal(t, o.RunIfChanged, presubmits[0].RunIfChanged)
+	assert.False(t, presubmits[0].AlwaysRun)
+
+	postsubmits := prowConfig.Postsubmits["test/repo"]
+	assert.Len(t, postsubmits, 1)
+	assert.Equal(t, o.RunIfChanged, postsubmits[0].RunIfChanged)
+
+	assert.Empty(t, prowConfig.BranchProtection.Orgs, "jobs triggered by changes to some files should not be required status checks")
+}
+
 func TestRemoveProwConfig(t *testing.T) {
 	t.Parallel()
 	o := TestOptions{}
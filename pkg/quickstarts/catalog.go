@@ -0,0 +1,191 @@
+package quickstarts
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// QuickstartCatalog a named catalog of quickstarts registered by a team. The quickstarts are either the repositories
+// of a git organisation or are listed in a catalog YAML file
+type QuickstartCatalog struct {
+	// Name the name of the catalog used to browse it via jx create quickstart --catalog
+	Name string `json:"name"`
+	// Description the description of the catalog
+	Description string `json:"description,omitempty"`
+	// URL the URL or file name of the catalog YAML file
+	URL string `json:"url,omitempty"`
+	// GitURL the URL of the git server of the organisation
+	GitURL string `json:"gitUrl,omitempty"`
+	// GitKind the kind of the git server of the organisation
+	GitKind string `json:"gitKind,omitempty"`
+	// Owner the git organisation whose repositories are the quickstarts
+	Owner string `json:"owner,omitempty"`
+	// Includes the patterns of the repositories to include
+	Includes []string `json:"includes,omitempty"`
+	// Excludes the patterns of the repositories to exclude
+	Excludes []string `json:"excludes,omitempty"`
+}
+
+// CatalogFile the contents of a catalog YAML file
+type CatalogFile struct {
+	Quickstarts []CatalogQuickstart `json:"quickstarts"`
+}
+
+// CatalogQuickstart a quickstart in a catalog YAML file
+type CatalogQuickstart struct {
+	ID             string   `json:"id,omitempty"`
+	Owner          string   `json:"owner,omitempty"`
+	Name           string   `json:"name"`
+	Description    string   `json:"description,omitempty"`
+	Version        string   `json:"version,omitempty"`
+	Language       string   `json:"language,omitempty"`
+	Framework      string   `json:"framework,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+	DownloadZipURL string   `json:"downloadZipURL"`
+	GitServer      string   `json:"gitServer,omitempty"`
+	GitKind        string   `json:"gitKind,omitempty"`
+	// MinJxVersion the minimum version of jx the quickstart works with
+	MinJxVersion string `json:"minJxVersion,omitempty"`
+}
+
+// Validate validates the catalog
+func (c *QuickstartCatalog) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("the quickstart catalog has no name")
+	}
+	if c.URL == "" && c.Owner == "" {
+		return fmt.Errorf("the quickstart catalog %s needs either a catalog URL or a git owner", c.Name)
+	}
+	if c.URL != "" && c.Owner != "" {
+		return fmt.Errorf("the quickstart catalog %s cannot have both a catalog URL and a git owner", c.Name)
+	}
+	return nil
+}
+
+// Source returns a description of where the quickstarts of the catalog are loaded from
+func (c *QuickstartCatalog) Source() string {
+	if c.URL != "" {
+		return c.URL
+	}
+	gitURL := c.GitURL
+	if gitURL == "" {
+		gitURL = gits.GitHubURL
+	}
+	return util.UrlJoin(gitURL, c.Owner)
+}
+
+// LoadCatalogFile loads the catalog YAML file from the URL or file name
+func LoadCatalogFile(u string) (*CatalogFile, error) {
+	var data []byte
+	var err error
+	if strings.HasPrefix(u, "http://") || strings.HasPrefix(u, "https://") {
+		data, err = downloadCatalogFile(u)
+	} else {
+		data, err = ioutil.ReadFile(u)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading the quickstart catalog %s", u)
+	}
+	return ParseCatalogFile(data)
+}
+
+func downloadCatalogFile(u string) ([]byte, error) {
+	resp, err := util.GetClient().Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("status %s", resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// ParseCatalogFile parses the YAML of a catalog file
+func ParseCatalogFile(data []byte) (*CatalogFile, error) {
+	file := &CatalogFile{}
+	err := yaml.Unmarshal(data, file)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshalling the quickstart catalog YAML")
+	}
+	for i, q := range file.Quickstarts {
+		if q.Name == "" {
+			return nil, errors.Errorf("quickstart %d of the catalog has no name", i+1)
+		}
+		if q.DownloadZipURL == "" {
+			return nil, errors.Errorf("the quickstart %s of the catalog has no downloadZipURL", q.Name)
+		}
+		if q.MinJxVersion != "" {
+			_, err = semver.ParseTolerant(q.MinJxVersion)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parsing the minJxVersion of the quickstart %s", q.Name)
+			}
+		}
+	}
+	return file, nil
+}
+
+// LoadCatalog adds the quickstarts of the catalog file to the model skipping the ones which need a newer version of jx.
+// The minimum versions are ignored if the jx version is nil
+func (model *QuickstartModel) LoadCatalog(catalog string, file *CatalogFile, jxVersion *semver.Version) {
+	for _, from := range file.Quickstarts {
+		if from.MinJxVersion != "" && jxVersion != nil {
+			minVersion, err := semver.ParseTolerant(from.MinJxVersion)
+			if err == nil && jxVersion.LT(minVersion) {
+				log.Logger().Debugf("ignoring the quickstart %s of catalog %s as it needs jx %s or later", from.Name, catalog, from.MinJxVersion)
+				continue
+			}
+		}
+		owner := from.Owner
+		if owner == "" {
+			owner = catalog
+		}
+		id := from.ID
+		if id == "" {
+			id = owner + "/" + from.Name
+		}
+		model.Add(&Quickstart{
+			ID:             id,
+			Owner:          owner,
+			Name:           from.Name,
+			Description:    from.Description,
+			Version:        from.Version,
+			Language:       from.Language,
+			Framework:      from.Framework,
+			Tags:           from.Tags,
+			DownloadZipURL: from.DownloadZipURL,
+			GitServer:      from.GitServer,
+			GitKind:        from.GitKind,
+			Catalog:        catalog,
+			MinJxVersion:   from.MinJxVersion,
+		})
+	}
+}
+
+// AddCatalog adds the quickstarts of the other model to this model as part of the catalog
+func (model *QuickstartModel) AddCatalog(catalog string, other *QuickstartModel) {
+	for _, q := range other.Quickstarts {
+		q.Catalog = catalog
+		model.Add(q)
+	}
+}
+
+// Catalogs returns the names of the catalogs of the quickstarts sorted
+func (model *QuickstartModel) Catalogs() []string {
+	m := map[string]string{}
+	for _, q := range model.Quickstarts {
+		if q.Catalog != "" {
+			m[q.Catalog] = q.Catalog
+		}
+	}
+	return util.SortedMapKeys(m)
+}
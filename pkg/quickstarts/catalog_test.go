@@ -0,0 +1,141 @@
+// +build unit
+
+package quickstarts_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/jenkins-x/jx/v2/pkg/quickstarts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCatalogYAML = `quickstarts:
+- name: spring-service
+  description: A Spring Boot service
+  language: java
+  framework: spring
+  tags: [spring, service]
+  downloadZipURL: https://github.com/mycompany/spring-service/archive/master.zip
+- name: node-service
+  owner: mycompany-node
+  language: javascript
+  tags: [service]
+  downloadZipURL: https://github.com/mycompany-node/node-service/archive/master.zip
+  minJxVersion: 2.1.0
+`
+
+func TestQuickstartCatalogValidate(t *testing.T) {
+	t.Parallel()
+
+	assert.Error(t, (&quickstarts.QuickstartCatalog{Owner: "mycompany"}).Validate(), "a name is required")
+	assert.Error(t, (&quickstarts.QuickstartCatalog{Name: "mycompany"}).Validate(), "a source is required")
+	assert.Error(t, (&quickstarts.QuickstartCatalog{Name: "mycompany", Owner: "mycompany", URL: "catalog.yaml"}).Validate())
+	assert.NoError(t, (&quickstarts.QuickstartCatalog{Name: "mycompany", Owner: "mycompany"}).Validate())
+
+	assert.Equal(t, "https://github.com/mycompany", (&quickstarts.QuickstartCatalog{Name: "mycompany", Owner: "mycompany"}).Source())
+}
+
+func TestParseCatalogFile(t *testing.T) {
+	t.Parallel()
+
+	file, err := quickstarts.ParseCatalogFile([]byte(testCatalogYAML))
+	require.NoError(t, err)
+	require.Len(t, file.Quickstarts, 2)
+	assert.Equal(t, "spring-service", file.Quickstarts[0].Name)
+	assert.Equal(t, []string{"spring", "service"}, file.Quickstarts[0].Tags)
+	assert.Equal(t, "2.1.0", file.Quickstarts[1].MinJxVersion)
+
+	_, err = quickstarts.ParseCatalogFile([]byte("quickstarts:\n- name: foo\n"))
+	assert.Error(t, err, "the download URL is required")
+
+	_, err = quickstarts.ParseCatalogFile([]byte("quickstarts:\n- name: foo\n  downloadZipURL: https://foo\n  minJxVersion: latest\n"))
+	assert.Error(t, err, "the minimum version must be a semantic version")
+}
+
+func TestLoadCatalogFileFromURL(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/catalog.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(testCatalogYAML)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	file, err := quickstarts.LoadCatalogFile(server.URL + "/catalog.yaml")
+	require.NoError(t, err)
+	assert.Len(t, file.Quickstarts, 2)
+
+	_, err = quickstarts.LoadCatalogFile(server.URL + "/missing.yaml")
+	assert.Error(t, err)
+
+	tmpFile, err := ioutil.TempFile("", "test-catalog-")
+	require.NoError(t, err)
+	_, err = tmpFile.WriteString(testCatalogYAML)
+	require.NoError(t, err)
+	require.NoError(t, tmpFile.Close())
+	defer os.Remove(tmpFile.Name()) //nolint:errcheck
+
+	file, err = quickstarts.LoadCatalogFile(tmpFile.Name())
+	require.NoError(t, err)
+	assert.Len(t, file.Quickstarts, 2)
+}
+
+func TestQuickstartModelLoadCatalog(t *testing.T) {
+	t.Parallel()
+
+	file, err := quickstarts.ParseCatalogFile([]byte(testCatalogYAML))
+	require.NoError(t, err)
+
+	oldVersion := semver.MustParse("2.0.100")
+	model := quickstarts.NewQuickstartModel()
+	model.LoadCatalog("mycompany", file, &oldVersion)
+	assert.Equal(t, []string{"mycompany/spring-service"}, model.SortedNames(), "quickstarts needing a newer jx are ignored")
+
+	newVersion := semver.MustParse("2.1.3")
+	model = quickstarts.NewQuickstartModel()
+	model.LoadCatalog("mycompany", file, &newVersion)
+	assert.Equal(t, []string{"mycompany-node/node-service", "mycompany/spring-service"}, model.SortedNames())
+	assert.Equal(t, []string{"mycompany"}, model.Catalogs())
+
+	q := model.Quickstarts["mycompany/spring-service"]
+	require.NotNil(t, q)
+	assert.Equal(t, "mycompany", q.Owner)
+	assert.Equal(t, "mycompany", q.Catalog)
+	assert.Equal(t, "A Spring Boot service", q.Description)
+
+	model = quickstarts.NewQuickstartModel()
+	model.LoadCatalog("mycompany", file, nil)
+	assert.Len(t, model.Quickstarts, 2, "the minimum versions are ignored without a jx version")
+}
+
+func TestQuickstartModelFilterCatalogAndTags(t *testing.T) {
+	t.Parallel()
+
+	file, err := quickstarts.ParseCatalogFile([]byte(testCatalogYAML))
+	require.NoError(t, err)
+	model := quickstarts.NewQuickstartModel()
+	model.Add(&quickstarts.Quickstart{ID: "jenkins-x-quickstarts/spring-boot-http-gradle", Name: "spring-boot-http-gradle", Tags: []string{"spring"}})
+	model.LoadCatalog("mycompany", file, nil)
+
+	results := model.Filter(&quickstarts.QuickstartFilter{Catalogs: []string{"mycompany"}})
+	assert.Len(t, results, 2)
+
+	results = model.Filter(&quickstarts.QuickstartFilter{Tags: []string{"Spring"}})
+	assert.Len(t, results, 2)
+
+	results = model.Filter(&quickstarts.QuickstartFilter{Catalogs: []string{"mycompany"}, Tags: []string{"spring", "service"}})
+	require.Len(t, results, 1)
+	assert.Equal(t, "spring-service", results[0].Name)
+
+	results = model.Filter(&quickstarts.QuickstartFilter{Catalogs: []string{"other"}})
+	assert.Empty(t, results)
+}
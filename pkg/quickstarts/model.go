@@ -154,7 +154,7 @@ func (model *QuickstartModel) CreateSurvey(filter *QuickstartFilter, batchMode b
 			Message: "select the quickstart you wish to create",
 			Options: names,
 		}
-		err := survey.AskOne(prompt, &answer, survey.Required, surveyOpts)
+		err := util.AskOne(prompt, &answer, survey.Required, surveyOpts)
 		if err != nil {
 			return nil, err
 		}
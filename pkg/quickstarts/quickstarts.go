@@ -34,6 +34,14 @@ func (f *QuickstartFilter) Matches(q *Quickstart) bool {
 	if framework != "" && strings.ToLower(q.Framework) != framework {
 		return false
 	}
+	if len(f.Catalogs) > 0 && util.StringArrayIndex(f.Catalogs, q.Catalog) < 0 {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if util.StringArrayIndex(util.StringArrayToLower(q.Tags), strings.ToLower(tag)) < 0 {
+			return false
+		}
+	}
 	if !f.AllowML && util.StartsWith(q.Name, "ML-") {
 		return false
 	}
@@ -8,6 +8,7 @@ type Quickstart struct {
 	ID             string
 	Owner          string
 	Name           string
+	Description    string
 	Version        string
 	Language       string
 	Framework      string
@@ -16,6 +17,8 @@ type Quickstart struct {
 	GitServer      string
 	GitKind        string
 	GitProvider    gits.GitProvider
+	Catalog        string
+	MinJxVersion   string
 }
 
 type QuickstartModel struct {
@@ -29,6 +32,7 @@ type QuickstartFilter struct {
 	Text        string
 	ProjectName string
 	Tags        []string
+	Catalogs    []string
 	AllowML     bool
 }
 
@@ -0,0 +1,112 @@
+package registries
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// acrTokenUser the user of the access tokens of Azure Container Registry
+const acrTokenUser = "00000000-0000-0000-0000-000000000000"
+
+// acr an Azure Container Registry managed via the az CLI
+type acr struct {
+	host string
+	run  func(name string, args ...string) (string, error)
+}
+
+// Kind returns the kind of the registry
+func (r *acr) Kind() string {
+	return KindACR
+}
+
+// Host returns the host of the registry
+func (r *acr) Host() string {
+	return r.host
+}
+
+// CreateRepository does nothing as Azure Container Registry creates the repositories on push
+func (r *acr) CreateRepository(repository string) error {
+	return nil
+}
+
+// Credentials returns an access token of the registry for the current az account which is valid for three hours
+func (r *acr) Credentials(repository string) (*Credentials, error) {
+	output, err := r.run("az", "acr", "login", "--name", r.name(), "--expose-token", "--output", "json")
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting an access token of %s via az", r.host)
+	}
+	token := struct {
+		AccessToken string `json:"accessToken"`
+	}{}
+	err = json.Unmarshal([]byte(output), &token)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing the access token returned by az")
+	}
+	return &Credentials{Username: acrTokenUser, Password: token.AccessToken}, nil
+}
+
+// ListRepositories lists the repositories whose names start with the prefix
+func (r *acr) ListRepositories(prefix string) ([]string, error) {
+	output, err := r.run("az", "acr", "repository", "list", "--name", r.name(), "--output", "json")
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the repositories of %s", r.host)
+	}
+	var names []string
+	err = json.Unmarshal([]byte(output), &names)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing the repositories listed by az")
+	}
+	var repositories []string
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) {
+			repositories = append(repositories, name)
+		}
+	}
+	return repositories, nil
+}
+
+// ListImages lists the images of the repository
+func (r *acr) ListImages(repository string) ([]Image, error) {
+	output, err := r.run("az", "acr", "repository", "show-manifests", "--name", r.name(), "--repository", repository, "--detail", "--output", "json")
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the images of %s/%s", r.host, repository)
+	}
+	var results []struct {
+		Digest      string    `json:"digest"`
+		Tags        []string  `json:"tags"`
+		CreatedTime time.Time `json:"createdTime"`
+		ImageSize   int64     `json:"imageSize"`
+	}
+	err = json.Unmarshal([]byte(output), &results)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing the images listed by az")
+	}
+	var images []Image
+	for _, result := range results {
+		images = append(images, Image{
+			Repository: repository,
+			Digest:     result.Digest,
+			Tags:       result.Tags,
+			Created:    result.CreatedTime,
+			Size:       result.ImageSize,
+		})
+	}
+	return images, nil
+}
+
+// DeleteImage deletes the manifest of the image and all of its tags
+func (r *acr) DeleteImage(image Image) error {
+	_, err := r.run("az", "acr", "repository", "delete", "--name", r.name(), "--image", image.Repository+"@"+image.Digest, "--yes")
+	if err != nil {
+		return errors.Wrapf(err, "deleting the image %s@%s", image.Repository, image.Digest)
+	}
+	return nil
+}
+
+// name returns the name of the registry which is the first label of its host
+func (r *acr) name() string {
+	return strings.Split(r.host, ".")[0]
+}
@@ -0,0 +1,174 @@
+package registries
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	dockerHubURL      = "https://hub.docker.com"
+	dockerHubPageSize = 100
+)
+
+// dockerHub the Docker Hub registry managed via the REST API of hub.docker.com
+type dockerHub struct {
+	options Options
+	baseURL string
+	token   string
+}
+
+// dockerHubPage a page of the results of the Docker Hub API
+type dockerHubPage struct {
+	Next    string `json:"next"`
+	Results []struct {
+		Name        string    `json:"name"`
+		Digest      string    `json:"digest"`
+		FullSize    int64     `json:"full_size"`
+		LastUpdated time.Time `json:"last_updated"`
+		Images      []struct {
+			Digest string `json:"digest"`
+		} `json:"images"`
+	} `json:"results"`
+}
+
+// Kind returns the kind of the registry
+func (r *dockerHub) Kind() string {
+	return KindDockerHub
+}
+
+// Host returns the host of the registry
+func (r *dockerHub) Host() string {
+	return "docker.io"
+}
+
+// CreateRepository does nothing as Docker Hub creates the repositories of the namespace of the user on push
+func (r *dockerHub) CreateRepository(repository string) error {
+	return nil
+}
+
+// Credentials returns the user and access token of the options as Docker Hub has no short-lived credentials
+func (r *dockerHub) Credentials(repository string) (*Credentials, error) {
+	if r.options.Username == "" {
+		return nil, fmt.Errorf("no user of Docker Hub specified")
+	}
+	return &Credentials{Username: r.options.Username, Password: r.options.Password}, nil
+}
+
+// ListRepositories lists the repositories of the namespace of the prefix whose names start with the prefix
+func (r *dockerHub) ListRepositories(prefix string) ([]string, error) {
+	namespace := strings.Split(strings.Trim(prefix, "/"), "/")[0]
+	if namespace == "" {
+		return nil, fmt.Errorf("the prefix of the repositories of Docker Hub must start with the namespace")
+	}
+	var repositories []string
+	next := fmt.Sprintf("%s/v2/repositories/%s/?page_size=%d", r.baseURL, url.PathEscape(namespace), dockerHubPageSize)
+	for next != "" {
+		page := dockerHubPage{}
+		err := r.get(next, &page)
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing the repositories of the Docker Hub namespace %s", namespace)
+		}
+		for _, result := range page.Results {
+			name := namespace + "/" + result.Name
+			if strings.HasPrefix(name, prefix) {
+				repositories = append(repositories, name)
+			}
+		}
+		next = page.Next
+	}
+	return repositories, nil
+}
+
+// ListImages lists the images of the repository grouping its tags by their digest
+func (r *dockerHub) ListImages(repository string) ([]Image, error) {
+	var images []Image
+	index := map[string]int{}
+	next := fmt.Sprintf("%s/v2/repositories/%s/tags/?page_size=%d", r.baseURL, repository, dockerHubPageSize)
+	for next != "" {
+		page := dockerHubPage{}
+		err := r.get(next, &page)
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing the tags of the Docker Hub repository %s", repository)
+		}
+		for _, result := range page.Results {
+			digest := result.Digest
+			if digest == "" && len(result.Images) > 0 {
+				digest = result.Images[0].Digest
+			}
+			i, ok := index[digest]
+			if !ok || digest == "" {
+				index[digest] = len(images)
+				images = append(images, Image{
+					Repository: repository,
+					Digest:     digest,
+					Created:    result.LastUpdated,
+					Size:       result.FullSize,
+				})
+				i = len(images) - 1
+			}
+			images[i].Tags = append(images[i].Tags, result.Name)
+			if result.LastUpdated.After(images[i].Created) {
+				images[i].Created = result.LastUpdated
+			}
+		}
+		next = page.Next
+	}
+	return images, nil
+}
+
+// DeleteImage deletes all the tags of the image which Docker Hub then removes
+func (r *dockerHub) DeleteImage(image Image) error {
+	err := r.login()
+	if err != nil {
+		return err
+	}
+	for _, tag := range image.Tags {
+		u := fmt.Sprintf("%s/v2/repositories/%s/tags/%s/", r.baseURL, image.Repository, url.PathEscape(tag))
+		_, err = doJSON(r.options.HTTPClient, http.MethodDelete, u, r.authorize, nil, nil)
+		if err != nil {
+			return errors.Wrapf(err, "deleting the tag %s of %s", tag, image.Repository)
+		}
+	}
+	return nil
+}
+
+func (r *dockerHub) get(u string, result interface{}) error {
+	err := r.login()
+	if err != nil {
+		return err
+	}
+	_, err = doJSON(r.options.HTTPClient, http.MethodGet, u, r.authorize, nil, result)
+	return err
+}
+
+// login gets a token of the Docker Hub API for the user of the options. Public repositories are accessed anonymously
+// if no user is specified
+func (r *dockerHub) login() error {
+	if r.token != "" || r.options.Username == "" {
+		return nil
+	}
+	body := map[string]string{
+		"username": r.options.Username,
+		"password": r.options.Password,
+	}
+	result := struct {
+		Token string `json:"token"`
+	}{}
+	_, err := doJSON(r.options.HTTPClient, http.MethodPost, r.baseURL+"/v2/users/login", nil, body, &result)
+	if err != nil {
+		return errors.Wrapf(err, "logging into Docker Hub as %s", r.options.Username)
+	}
+	r.token = result.Token
+	return nil
+}
+
+func (r *dockerHub) authorize(req *http.Request) {
+	if r.token != "" {
+		req.Header.Set("Authorization", "JWT "+r.token)
+	}
+}
@@ -0,0 +1,150 @@
+package registries
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecr/ecriface"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cloud/amazon/session"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// ecrRegistry an Amazon Elastic Container Registry
+type ecrRegistry struct {
+	host string
+	api  ecriface.ECRAPI
+}
+
+func newECR(options Options) (*ecrRegistry, error) {
+	region := options.Region
+	if region == "" {
+		if m := ecrHostRegex.FindStringSubmatch(strings.ToLower(options.Host)); m != nil {
+			region = m[1]
+		}
+	}
+	sess, err := session.NewAwsSession("", region)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating the AWS session of the registry %s", options.Host)
+	}
+	return &ecrRegistry{host: options.Host, api: ecr.New(sess)}, nil
+}
+
+// Kind returns the kind of the registry
+func (r *ecrRegistry) Kind() string {
+	return KindECR
+}
+
+// Host returns the host of the registry
+func (r *ecrRegistry) Host() string {
+	return r.host
+}
+
+// CreateRepository creates the ECR repository unless it exists as ECR does not create repositories on push
+func (r *ecrRegistry) CreateRepository(repository string) error {
+	repository = strings.ToLower(repository)
+	_, err := r.api.DescribeRepositories(&ecr.DescribeRepositoriesInput{
+		RepositoryNames: []*string{aws.String(repository)},
+	})
+	if err == nil {
+		return nil
+	}
+	if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != ecr.ErrCodeRepositoryNotFoundException {
+		return errors.Wrapf(err, "describing the ECR repository %s", repository)
+	}
+	result, err := r.api.CreateRepository(&ecr.CreateRepositoryInput{
+		RepositoryName: aws.String(repository),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "creating the ECR repository %s", repository)
+	}
+	if result.Repository != nil && result.Repository.RepositoryUri != nil {
+		log.Logger().Infof("Created ECR repository: %s", util.ColorInfo(*result.Repository.RepositoryUri))
+	}
+	return nil
+}
+
+// Credentials returns an authorization token of ECR which is valid for 12 hours
+func (r *ecrRegistry) Credentials(repository string) (*Credentials, error) {
+	result, err := r.api.GetAuthorizationToken(&ecr.GetAuthorizationTokenInput{})
+	if err != nil {
+		return nil, errors.Wrap(err, "getting an ECR authorization token")
+	}
+	if len(result.AuthorizationData) == 0 || result.AuthorizationData[0].AuthorizationToken == nil {
+		return nil, fmt.Errorf("no ECR authorization token returned for %s", r.host)
+	}
+	data := result.AuthorizationData[0]
+	decoded, err := base64.StdEncoding.DecodeString(*data.AuthorizationToken)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding the ECR authorization token")
+	}
+	parts := strings.SplitN(string(decoded), ":", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid ECR authorization token returned for %s", r.host)
+	}
+	return &Credentials{
+		Username: parts[0],
+		Password: parts[1],
+		Expiry:   data.ExpiresAt,
+	}, nil
+}
+
+// ListRepositories lists the ECR repositories whose names start with the prefix
+func (r *ecrRegistry) ListRepositories(prefix string) ([]string, error) {
+	var repositories []string
+	err := r.api.DescribeRepositoriesPages(&ecr.DescribeRepositoriesInput{}, func(page *ecr.DescribeRepositoriesOutput, last bool) bool {
+		for _, repository := range page.Repositories {
+			name := aws.StringValue(repository.RepositoryName)
+			if strings.HasPrefix(name, prefix) {
+				repositories = append(repositories, name)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing the ECR repositories")
+	}
+	return repositories, nil
+}
+
+// ListImages lists the images of the ECR repository
+func (r *ecrRegistry) ListImages(repository string) ([]Image, error) {
+	var images []Image
+	input := &ecr.DescribeImagesInput{RepositoryName: aws.String(repository)}
+	err := r.api.DescribeImagesPages(input, func(page *ecr.DescribeImagesOutput, last bool) bool {
+		for _, detail := range page.ImageDetails {
+			images = append(images, Image{
+				Repository: repository,
+				Digest:     aws.StringValue(detail.ImageDigest),
+				Tags:       aws.StringValueSlice(detail.ImageTags),
+				Created:    aws.TimeValue(detail.ImagePushedAt),
+				Size:       aws.Int64Value(detail.ImageSizeInBytes),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the images of the ECR repository %s", repository)
+	}
+	return images, nil
+}
+
+// DeleteImage deletes the image of the ECR repository
+func (r *ecrRegistry) DeleteImage(image Image) error {
+	result, err := r.api.BatchDeleteImage(&ecr.BatchDeleteImageInput{
+		RepositoryName: aws.String(image.Repository),
+		ImageIds:       []*ecr.ImageIdentifier{{ImageDigest: aws.String(image.Digest)}},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "deleting the image %s@%s", image.Repository, image.Digest)
+	}
+	if len(result.Failures) > 0 {
+		return fmt.Errorf("deleting the image %s@%s failed: %s", image.Repository, image.Digest, aws.StringValue(result.Failures[0].FailureReason))
+	}
+	return nil
+}
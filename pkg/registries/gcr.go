@@ -0,0 +1,131 @@
+package registries
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// gcrTimestampLayout the layout of the timestamps of the images listed by gcloud
+	gcrTimestampLayout = "2006-01-02 15:04:05-07:00"
+	// gcrTokenUser the user of the access tokens of Google Container Registry and Artifact Registry
+	gcrTokenUser = "oauth2accesstoken"
+)
+
+// gcr a Google Container Registry or Artifact Registry managed via gcloud
+type gcr struct {
+	host string
+	run  func(name string, args ...string) (string, error)
+}
+
+// Kind returns the kind of the registry
+func (r *gcr) Kind() string {
+	return KindGCR
+}
+
+// Host returns the host of the registry
+func (r *gcr) Host() string {
+	return r.host
+}
+
+// CreateRepository creates the docker repository of Artifact Registry unless it exists. The repository of an
+// Artifact Registry image is the second path element such as LOCATION-docker.pkg.dev/PROJECT/REPOSITORY/IMAGE.
+// Google Container Registry creates the repositories on push
+func (r *gcr) CreateRepository(repository string) error {
+	if !strings.HasSuffix(r.host, "-docker.pkg.dev") {
+		return nil
+	}
+	parts := strings.Split(repository, "/")
+	if len(parts) < 2 {
+		return fmt.Errorf("the Artifact Registry repository %s is not of the form PROJECT/REPOSITORY/IMAGE", repository)
+	}
+	project, name := parts[0], parts[1]
+	location := strings.TrimSuffix(r.host, "-docker.pkg.dev")
+	_, err := r.run("gcloud", "artifacts", "repositories", "describe", name, "--location", location, "--project", project, "--format", "json")
+	if err == nil {
+		return nil
+	}
+	_, err = r.run("gcloud", "artifacts", "repositories", "create", name, "--repository-format", "docker", "--location", location, "--project", project)
+	if err != nil {
+		return errors.Wrapf(err, "creating the Artifact Registry repository %s in %s", name, location)
+	}
+	log.Logger().Infof("Created Artifact Registry repository: %s", util.ColorInfo(r.host+"/"+project+"/"+name))
+	return nil
+}
+
+// Credentials returns an access token of the current gcloud account which is valid for at most an hour
+func (r *gcr) Credentials(repository string) (*Credentials, error) {
+	token, err := r.run("gcloud", "auth", "print-access-token")
+	if err != nil {
+		return nil, errors.Wrap(err, "getting an access token via gcloud")
+	}
+	return &Credentials{Username: gcrTokenUser, Password: strings.TrimSpace(token)}, nil
+}
+
+// ListRepositories lists the repositories below the prefix such as PROJECT/ORGANISATION
+func (r *gcr) ListRepositories(prefix string) ([]string, error) {
+	output, err := r.run("gcloud", "container", "images", "list", "--repository", r.host+"/"+strings.Trim(prefix, "/"), "--format", "json")
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the repositories of %s/%s", r.host, prefix)
+	}
+	var results []struct {
+		Name string `json:"name"`
+	}
+	err = json.Unmarshal([]byte(output), &results)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing the repositories listed by gcloud")
+	}
+	var repositories []string
+	for _, result := range results {
+		repositories = append(repositories, strings.TrimPrefix(result.Name, r.host+"/"))
+	}
+	return repositories, nil
+}
+
+// ListImages lists the images of the repository
+func (r *gcr) ListImages(repository string) ([]Image, error) {
+	output, err := r.run("gcloud", "container", "images", "list-tags", r.host+"/"+repository, "--format", "json")
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the images of %s/%s", r.host, repository)
+	}
+	var results []struct {
+		Digest    string   `json:"digest"`
+		Tags      []string `json:"tags"`
+		Timestamp struct {
+			Datetime string `json:"datetime"`
+		} `json:"timestamp"`
+	}
+	err = json.Unmarshal([]byte(output), &results)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing the images listed by gcloud")
+	}
+	var images []Image
+	for _, result := range results {
+		created, err := time.Parse(gcrTimestampLayout, result.Timestamp.Datetime)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing the timestamp of the image %s@%s", repository, result.Digest)
+		}
+		images = append(images, Image{
+			Repository: repository,
+			Digest:     result.Digest,
+			Tags:       result.Tags,
+			Created:    created,
+		})
+	}
+	return images, nil
+}
+
+// DeleteImage deletes the image and all of its tags
+func (r *gcr) DeleteImage(image Image) error {
+	_, err := r.run("gcloud", "container", "images", "delete", r.host+"/"+image.Repository+"@"+image.Digest, "--force-delete-tags", "--quiet")
+	if err != nil {
+		return errors.Wrapf(err, "deleting the image %s@%s", image.Repository, image.Digest)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package registries
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	harborAPIPath  = "/api/v2.0"
+	harborPageSize = 100
+	// harborRobotDurationDays the number of days the robot accounts of the credentials are valid
+	harborRobotDurationDays = 1
+)
+
+// harbor a Harbor registry managed via its REST API
+type harbor struct {
+	options Options
+	host    string
+	baseURL string
+}
+
+func newHarbor(options Options) (*harbor, error) {
+	if options.Host == "" {
+		return nil, fmt.Errorf("no host of the Harbor registry specified")
+	}
+	baseURL := options.Host
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "https://" + baseURL
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the Harbor host %s", options.Host)
+	}
+	return &harbor{options: options, host: u.Host, baseURL: strings.TrimSuffix(baseURL, "/")}, nil
+}
+
+// Kind returns the kind of the registry
+func (r *harbor) Kind() string {
+	return KindHarbor
+}
+
+// Host returns the host of the registry
+func (r *harbor) Host() string {
+	return r.host
+}
+
+// CreateRepository creates the Harbor project of the repository unless it exists. Harbor creates the repositories
+// within a project on push
+func (r *harbor) CreateRepository(repository string) error {
+	project, _ := r.splitRepository(repository)
+	status, err := doJSON(r.options.HTTPClient, http.MethodHead, r.apiURL("projects")+"?project_name="+url.QueryEscape(project), r.authorize, nil, nil)
+	if err == nil {
+		return nil
+	}
+	if status != http.StatusNotFound {
+		return errors.Wrapf(err, "checking the Harbor project %s", project)
+	}
+	body := map[string]interface{}{
+		"project_name": project,
+		"metadata":     map[string]string{"public": "false"},
+	}
+	status, err = doJSON(r.options.HTTPClient, http.MethodPost, r.apiURL("projects"), r.authorize, body, nil)
+	if err != nil && status != http.StatusConflict {
+		return errors.Wrapf(err, "creating the Harbor project %s", project)
+	}
+	log.Logger().Infof("Created Harbor project: %s", util.ColorInfo(r.host+"/"+project))
+	return nil
+}
+
+// Credentials creates a robot account which can pull and push the images of the project of the repository for a day
+func (r *harbor) Credentials(repository string) (*Credentials, error) {
+	project, _ := r.splitRepository(repository)
+	body := map[string]interface{}{
+		"name":     fmt.Sprintf("jx-%d", time.Now().Unix()),
+		"duration": harborRobotDurationDays,
+		"level":    "project",
+		"permissions": []map[string]interface{}{
+			{
+				"kind":      "project",
+				"namespace": project,
+				"access": []map[string]string{
+					{"resource": "repository", "action": "pull"},
+					{"resource": "repository", "action": "push"},
+				},
+			},
+		},
+	}
+	robot := struct {
+		Name      string `json:"name"`
+		Secret    string `json:"secret"`
+		ExpiresAt int64  `json:"expires_at"`
+	}{}
+	_, err := doJSON(r.options.HTTPClient, http.MethodPost, r.apiURL("robots"), r.authorize, body, &robot)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating a robot account of the Harbor project %s", project)
+	}
+	credentials := &Credentials{Username: robot.Name, Password: robot.Secret}
+	if robot.ExpiresAt > 0 {
+		expiry := time.Unix(robot.ExpiresAt, 0).UTC()
+		credentials.Expiry = &expiry
+	}
+	return credentials, nil
+}
+
+// ListRepositories lists the repositories whose names start with the prefix. The prefix starts with the project
+func (r *harbor) ListRepositories(prefix string) ([]string, error) {
+	project, _ := r.splitRepository(prefix)
+	if project == "" {
+		return nil, fmt.Errorf("the prefix of the repositories of a Harbor registry must start with the project")
+	}
+	var repositories []string
+	for page := 1; ; page++ {
+		var results []struct {
+			Name string `json:"name"`
+		}
+		u := fmt.Sprintf("%s?page=%d&page_size=%d", r.apiURL("projects", url.PathEscape(project), "repositories"), page, harborPageSize)
+		_, err := doJSON(r.options.HTTPClient, http.MethodGet, u, r.authorize, nil, &results)
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing the repositories of the Harbor project %s", project)
+		}
+		for _, result := range results {
+			if strings.HasPrefix(result.Name, prefix) {
+				repositories = append(repositories, result.Name)
+			}
+		}
+		if len(results) < harborPageSize {
+			return repositories, nil
+		}
+	}
+}
+
+// ListImages lists the artifacts of the repository
+func (r *harbor) ListImages(repository string) ([]Image, error) {
+	var images []Image
+	for page := 1; ; page++ {
+		var results []struct {
+			Digest   string    `json:"digest"`
+			Size     int64     `json:"size"`
+			PushTime time.Time `json:"push_time"`
+			Tags     []struct {
+				Name string `json:"name"`
+			} `json:"tags"`
+		}
+		u := fmt.Sprintf("%s?with_tag=true&page=%d&page_size=%d", r.artifactsURL(repository), page, harborPageSize)
+		_, err := doJSON(r.options.HTTPClient, http.MethodGet, u, r.authorize, nil, &results)
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing the artifacts of the Harbor repository %s", repository)
+		}
+		for _, result := range results {
+			image := Image{
+				Repository: repository,
+				Digest:     result.Digest,
+				Created:    result.PushTime,
+				Size:       result.Size,
+			}
+			for _, tag := range result.Tags {
+				image.Tags = append(image.Tags, tag.Name)
+			}
+			images = append(images, image)
+		}
+		if len(results) < harborPageSize {
+			return images, nil
+		}
+	}
+}
+
+// DeleteImage deletes the artifact and all of its tags
+func (r *harbor) DeleteImage(image Image) error {
+	_, err := doJSON(r.options.HTTPClient, http.MethodDelete, r.artifactsURL(image.Repository)+"/"+image.Digest, r.authorize, nil, nil)
+	if err != nil {
+		return errors.Wrapf(err, "deleting the image %s@%s", image.Repository, image.Digest)
+	}
+	return nil
+}
+
+func (r *harbor) authorize(req *http.Request) {
+	if r.options.Username != "" {
+		req.SetBasicAuth(r.options.Username, r.options.Password)
+	}
+}
+
+func (r *harbor) apiURL(paths ...string) string {
+	return r.baseURL + harborAPIPath + "/" + strings.Join(paths, "/")
+}
+
+// artifactsURL returns the URL of the artifacts of the repository whose name within the project is URL encoded twice
+// as required by the Harbor API for names containing slashes
+func (r *harbor) artifactsURL(repository string) string {
+	project, name := r.splitRepository(repository)
+	return r.apiURL("projects", url.PathEscape(project), "repositories", url.PathEscape(url.PathEscape(name)), "artifacts")
+}
+
+// splitRepository splits the repository into its project and its name within the project
+func (r *harbor) splitRepository(repository string) (string, string) {
+	parts := strings.SplitN(strings.Trim(repository, "/"), "/", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
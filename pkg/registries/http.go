@@ -0,0 +1,50 @@
+package registries
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// doJSON sends the request with the JSON body to the REST API of a registry and decodes the JSON response into the
+// result unless it is nil. The status code is returned so that callers can handle expected failures such as conflicts
+func doJSON(client *http.Client, method string, u string, authorize func(*http.Request), body interface{}, result interface{}) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, errors.Wrapf(err, "marshalling the body of %s %s", method, u)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, u, reader)
+	if err != nil {
+		return 0, errors.Wrapf(err, "creating the request %s %s", method, u)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if authorize != nil {
+		authorize(req)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, errors.Wrapf(err, "calling %s %s", method, u)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("%s %s returned status %d", method, u, resp.StatusCode)
+	}
+	if result != nil {
+		err = json.NewDecoder(resp.Body).Decode(result)
+		if err != nil {
+			return resp.StatusCode, errors.Wrapf(err, "parsing the response of %s %s", method, u)
+		}
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,148 @@
+package registries
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+)
+
+const (
+	// KindECR Amazon Elastic Container Registry
+	KindECR = "ecr"
+	// KindGCR Google Container Registry and Google Artifact Registry
+	KindGCR = "gcr"
+	// KindACR Azure Container Registry
+	KindACR = "acr"
+	// KindHarbor a Harbor registry
+	KindHarbor = "harbor"
+	// KindDockerHub Docker Hub
+	KindDockerHub = "dockerhub"
+
+	// defaultTimeout the timeout of the requests to the registries
+	defaultTimeout = time.Minute
+)
+
+var (
+	// Kinds the kinds of the supported registries
+	Kinds = []string{KindECR, KindGCR, KindACR, KindHarbor, KindDockerHub}
+
+	ecrHostRegex = regexp.MustCompile(`^\d+\.dkr\.ecr\.([a-z0-9-]+)\.amazonaws\.com$`)
+)
+
+// Registry a container registry which stores the images of the applications
+type Registry interface {
+	// Kind returns the kind of the registry
+	Kind() string
+
+	// Host returns the host of the registry
+	Host() string
+
+	// CreateRepository creates the repository of an image unless it exists. Registries which create the repositories
+	// when the first image is pushed do nothing
+	CreateRepository(repository string) error
+
+	// Credentials returns credentials which can pull and push the images of the repository. The credentials are
+	// short-lived tokens if the registry supports them
+	Credentials(repository string) (*Credentials, error)
+
+	// ListRepositories lists the repositories of the registry whose names start with the prefix
+	ListRepositories(prefix string) ([]string, error)
+
+	// ListImages lists the images of the repository
+	ListImages(repository string) ([]Image, error)
+
+	// DeleteImage deletes the image and all of its tags
+	DeleteImage(image Image) error
+}
+
+// Credentials the credentials of a registry
+type Credentials struct {
+	Username string
+	Password string
+	// Expiry the time the credentials expire or nil if they do not expire
+	Expiry *time.Time
+}
+
+// Image an image stored in a repository of a registry
+type Image struct {
+	Repository string
+	Digest     string
+	Tags       []string
+	Created    time.Time
+	// Size the size of the image in bytes or 0 if the registry does not report it
+	Size int64
+}
+
+// Options the options to connect to a registry
+type Options struct {
+	// Kind the kind of the registry. Defaults to the kind detected from the host
+	Kind string
+	// Host the host of the registry
+	Host string
+	// Username the user of registries which use static credentials such as Harbor and Docker Hub
+	Username string
+	// Password the password or access token of the user
+	Password string
+	// Region the region of an ECR registry. Defaults to the region of the host
+	Region string
+	// HTTPClient the client of the registries accessed via their REST API
+	HTTPClient *http.Client
+}
+
+// DetectKind returns the kind of the registry of the host or an empty string if it cannot be detected from the host
+// such as for Harbor registries
+func DetectKind(host string) string {
+	host = strings.ToLower(strings.TrimSuffix(host, "/"))
+	switch {
+	case host == "" || host == "docker.io" || host == "index.docker.io" || host == "registry-1.docker.io":
+		return KindDockerHub
+	case ecrHostRegex.MatchString(host):
+		return KindECR
+	case host == "gcr.io" || strings.HasSuffix(host, ".gcr.io") || strings.HasSuffix(host, "-docker.pkg.dev"):
+		return KindGCR
+	case strings.HasSuffix(host, ".azurecr.io"):
+		return KindACR
+	default:
+		return ""
+	}
+}
+
+// NewRegistry creates the registry of the options
+func NewRegistry(options Options) (Registry, error) {
+	kind := options.Kind
+	if kind == "" {
+		kind = DetectKind(options.Host)
+	}
+	if options.HTTPClient == nil {
+		options.HTTPClient = util.GetClientWithTimeout(defaultTimeout)
+	}
+	switch kind {
+	case KindECR:
+		return newECR(options)
+	case KindGCR:
+		return &gcr{host: options.Host, run: runCommand}, nil
+	case KindACR:
+		return &acr{host: options.Host, run: runCommand}, nil
+	case KindHarbor:
+		return newHarbor(options)
+	case KindDockerHub:
+		return &dockerHub{options: options, baseURL: dockerHubURL}, nil
+	case "":
+		return nil, fmt.Errorf("cannot detect the kind of the registry %s, please specify one of: %s", options.Host, strings.Join(Kinds, ", "))
+	default:
+		return nil, fmt.Errorf("unsupported kind of registry %s, the supported kinds are: %s", kind, strings.Join(Kinds, ", "))
+	}
+}
+
+// runCommand runs the CLI of a registry and returns its output
+func runCommand(name string, args ...string) (string, error) {
+	cmd := util.Command{
+		Name: name,
+		Args: args,
+	}
+	return cmd.RunWithoutRetry()
+}
@@ -0,0 +1,276 @@
+// +build unit
+
+package registries
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecr/ecriface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectKind(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, KindDockerHub, DetectKind(""))
+	assert.Equal(t, KindDockerHub, DetectKind("docker.io"))
+	assert.Equal(t, KindECR, DetectKind("123456789012.dkr.ecr.eu-west-1.amazonaws.com"))
+	assert.Equal(t, KindGCR, DetectKind("gcr.io"))
+	assert.Equal(t, KindGCR, DetectKind("eu.gcr.io"))
+	assert.Equal(t, KindGCR, DetectKind("europe-west1-docker.pkg.dev"))
+	assert.Equal(t, KindACR, DetectKind("myregistry.azurecr.io"))
+	assert.Equal(t, "", DetectKind("harbor.example.com"))
+
+	_, err := NewRegistry(Options{Host: "harbor.example.com"})
+	assert.Error(t, err)
+	registry, err := NewRegistry(Options{Host: "harbor.example.com", Kind: KindHarbor})
+	require.NoError(t, err)
+	assert.Equal(t, "harbor.example.com", registry.Host())
+}
+
+// fakeRunner records the commands and returns the output of the command prefix which matches
+type fakeRunner struct {
+	commands []string
+	outputs  map[string]string
+	failures map[string]bool
+}
+
+func (f *fakeRunner) run(name string, args ...string) (string, error) {
+	command := name + " " + strings.Join(args, " ")
+	f.commands = append(f.commands, command)
+	for prefix := range f.failures {
+		if strings.HasPrefix(command, prefix) {
+			return "", assert.AnError
+		}
+	}
+	for prefix, output := range f.outputs {
+		if strings.HasPrefix(command, prefix) {
+			return output, nil
+		}
+	}
+	return "", nil
+}
+
+func TestGCR(t *testing.T) {
+	t.Parallel()
+
+	runner := &fakeRunner{
+		outputs: map[string]string{
+			"gcloud container images list-tags": `[{"digest":"sha256:abc","tags":["0.0.1","latest"],"timestamp":{"datetime":"2020-05-01 10:00:00+00:00"}}]`,
+			"gcloud container images list ":     `[{"name":"gcr.io/myproject/myorg/myapp"}]`,
+		},
+	}
+	registry := &gcr{host: "gcr.io", run: runner.run}
+
+	repositories, err := registry.ListRepositories("myproject/myorg")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"myproject/myorg/myapp"}, repositories)
+
+	images, err := registry.ListImages("myproject/myorg/myapp")
+	require.NoError(t, err)
+	require.Len(t, images, 1)
+	assert.Equal(t, []string{"0.0.1", "latest"}, images[0].Tags)
+	assert.Equal(t, time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC), images[0].Created.UTC())
+
+	err = registry.DeleteImage(images[0])
+	require.NoError(t, err)
+	err = registry.CreateRepository("myproject/myorg/myapp")
+	require.NoError(t, err)
+	assert.Equal(t, "gcloud container images delete gcr.io/myproject/myorg/myapp@sha256:abc --force-delete-tags --quiet", runner.commands[len(runner.commands)-1])
+}
+
+func TestArtifactRegistryCreateRepository(t *testing.T) {
+	t.Parallel()
+
+	runner := &fakeRunner{failures: map[string]bool{"gcloud artifacts repositories describe": true}}
+	registry := &gcr{host: "europe-west1-docker.pkg.dev", run: runner.run}
+	err := registry.CreateRepository("myproject/myrepo/myapp")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"gcloud artifacts repositories describe myrepo --location europe-west1 --project myproject --format json",
+		"gcloud artifacts repositories create myrepo --repository-format docker --location europe-west1 --project myproject",
+	}, runner.commands)
+}
+
+func TestACR(t *testing.T) {
+	t.Parallel()
+
+	runner := &fakeRunner{
+		outputs: map[string]string{
+			"az acr login":                     `{"accessToken":"token","loginServer":"myregistry.azurecr.io"}`,
+			"az acr repository list":           `["myorg/myapp","other/app"]`,
+			"az acr repository show-manifests": `[{"digest":"sha256:abc","tags":["0.0.1"],"createdTime":"2020-05-01T10:00:00Z","imageSize":1024}]`,
+		},
+	}
+	registry := &acr{host: "myregistry.azurecr.io", run: runner.run}
+
+	credentials, err := registry.Credentials("myorg/myapp")
+	require.NoError(t, err)
+	assert.Equal(t, acrTokenUser, credentials.Username)
+	assert.Equal(t, "token", credentials.Password)
+
+	repositories, err := registry.ListRepositories("myorg/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"myorg/myapp"}, repositories)
+
+	images, err := registry.ListImages("myorg/myapp")
+	require.NoError(t, err)
+	require.Len(t, images, 1)
+	assert.Equal(t, int64(1024), images[0].Size)
+
+	err = registry.DeleteImage(images[0])
+	require.NoError(t, err)
+	assert.Equal(t, "az acr repository delete --name myregistry --image myorg/myapp@sha256:abc --yes", runner.commands[len(runner.commands)-1])
+}
+
+func TestHarbor(t *testing.T) {
+	t.Parallel()
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		user, _, _ := r.BasicAuth()
+		assert.Equal(t, "admin", user)
+		switch {
+		case r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v2.0/projects":
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v2.0/robots":
+			w.Write([]byte(`{"name":"robot$jx","secret":"s3cret","expires_at":1590000000}`))
+		case strings.HasSuffix(r.URL.Path, "/artifacts"):
+			w.Write([]byte(`[{"digest":"sha256:abc","size":2048,"push_time":"2020-05-01T10:00:00Z","tags":[{"name":"0.0.1"}]}]`))
+		}
+	}))
+	defer server.Close()
+
+	registry, err := NewRegistry(Options{Kind: KindHarbor, Host: server.URL, Username: "admin", Password: "pwd"})
+	require.NoError(t, err)
+
+	err = registry.CreateRepository("myorg/myapp")
+	require.NoError(t, err)
+
+	credentials, err := registry.Credentials("myorg/myapp")
+	require.NoError(t, err)
+	assert.Equal(t, "robot$jx", credentials.Username)
+	assert.Equal(t, "s3cret", credentials.Password)
+	require.NotNil(t, credentials.Expiry)
+	assert.Equal(t, int64(1590000000), credentials.Expiry.Unix())
+
+	images, err := registry.ListImages("myorg/charts/myapp")
+	require.NoError(t, err)
+	require.Len(t, images, 1)
+	assert.Equal(t, []string{"0.0.1"}, images[0].Tags)
+	assert.Equal(t, int64(2048), images[0].Size)
+
+	err = registry.DeleteImage(images[0])
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"HEAD /api/v2.0/projects?project_name=myorg",
+		"POST /api/v2.0/projects",
+		"POST /api/v2.0/robots",
+		"GET /api/v2.0/projects/myorg/repositories/charts%252Fmyapp/artifacts?with_tag=true&page=1&page_size=100",
+		"DELETE /api/v2.0/projects/myorg/repositories/charts%252Fmyapp/artifacts/sha256:abc",
+	}, requests)
+}
+
+func TestDockerHubListImages(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/users/login":
+			w.Write([]byte(`{"token":"jwt"}`))
+		case "/v2/repositories/myorg/myapp/tags/":
+			assert.Equal(t, "JWT jwt", r.Header.Get("Authorization"))
+			page := map[string]interface{}{
+				"results": []map[string]interface{}{
+					{"name": "0.0.2", "digest": "sha256:def", "full_size": 10, "last_updated": "2020-05-02T10:00:00Z"},
+					{"name": "latest", "digest": "sha256:def", "full_size": 10, "last_updated": "2020-05-03T10:00:00Z"},
+					{"name": "0.0.1", "digest": "sha256:abc", "full_size": 20, "last_updated": "2020-05-01T10:00:00Z"},
+				},
+			}
+			json.NewEncoder(w).Encode(page)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	registry := &dockerHub{options: Options{Username: "me", Password: "token", HTTPClient: http.DefaultClient}, baseURL: server.URL}
+	images, err := registry.ListImages("myorg/myapp")
+	require.NoError(t, err)
+	require.Len(t, images, 2)
+	assert.Equal(t, []string{"0.0.2", "latest"}, images[0].Tags)
+	assert.Equal(t, time.Date(2020, 5, 3, 10, 0, 0, 0, time.UTC), images[0].Created)
+	assert.Equal(t, []string{"0.0.1"}, images[1].Tags)
+}
+
+// fakeECR implements the ECR API calls used by the registry
+type fakeECR struct {
+	ecriface.ECRAPI
+	repositories map[string]bool
+	deleted      []string
+}
+
+func (f *fakeECR) DescribeRepositories(input *ecr.DescribeRepositoriesInput) (*ecr.DescribeRepositoriesOutput, error) {
+	name := aws.StringValue(input.RepositoryNames[0])
+	if !f.repositories[name] {
+		return nil, awserr.New(ecr.ErrCodeRepositoryNotFoundException, "not found", nil)
+	}
+	return &ecr.DescribeRepositoriesOutput{}, nil
+}
+
+func (f *fakeECR) CreateRepository(input *ecr.CreateRepositoryInput) (*ecr.CreateRepositoryOutput, error) {
+	f.repositories[aws.StringValue(input.RepositoryName)] = true
+	return &ecr.CreateRepositoryOutput{}, nil
+}
+
+func (f *fakeECR) GetAuthorizationToken(input *ecr.GetAuthorizationTokenInput) (*ecr.GetAuthorizationTokenOutput, error) {
+	expiry := time.Date(2020, 5, 1, 22, 0, 0, 0, time.UTC)
+	return &ecr.GetAuthorizationTokenOutput{
+		AuthorizationData: []*ecr.AuthorizationData{{
+			AuthorizationToken: aws.String(base64.StdEncoding.EncodeToString([]byte("AWS:token"))),
+			ExpiresAt:          &expiry,
+		}},
+	}, nil
+}
+
+func (f *fakeECR) BatchDeleteImage(input *ecr.BatchDeleteImageInput) (*ecr.BatchDeleteImageOutput, error) {
+	f.deleted = append(f.deleted, aws.StringValue(input.ImageIds[0].ImageDigest))
+	return &ecr.BatchDeleteImageOutput{}, nil
+}
+
+func TestECR(t *testing.T) {
+	t.Parallel()
+
+	api := &fakeECR{repositories: map[string]bool{}}
+	registry := &ecrRegistry{host: "123456789012.dkr.ecr.eu-west-1.amazonaws.com", api: api}
+
+	err := registry.CreateRepository("MyOrg/myapp")
+	require.NoError(t, err)
+	assert.True(t, api.repositories["myorg/myapp"])
+	err = registry.CreateRepository("myorg/myapp")
+	require.NoError(t, err)
+
+	credentials, err := registry.Credentials("myorg/myapp")
+	require.NoError(t, err)
+	assert.Equal(t, "AWS", credentials.Username)
+	assert.Equal(t, "token", credentials.Password)
+	assert.NotNil(t, credentials.Expiry)
+
+	err = registry.DeleteImage(Image{Repository: "myorg/myapp", Digest: "sha256:abc"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sha256:abc"}, api.deleted)
+}
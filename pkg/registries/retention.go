@@ -0,0 +1,183 @@
+package registries
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+// RetentionPolicy the policy of which images of a repository are kept by the garbage collection
+type RetentionPolicy struct {
+	// KeepLast the number of the most recent images which are kept. All images are kept if it is negative
+	KeepLast int `json:"keepLast"`
+	// KeepReleases keeps the images tagged with a release version
+	KeepReleases bool `json:"keepReleases"`
+	// OlderThan only deletes the images older than the duration such as 720h
+	OlderThan string `json:"olderThan,omitempty"`
+}
+
+// RepositoryRetentionPolicy the retention policy of the repositories whose names or application names match the pattern
+type RepositoryRetentionPolicy struct {
+	RetentionPolicy
+
+	// Repository the pattern of the repositories of the policy such as myorg/myapp, myorg/* or the application name
+	Repository string `json:"repository"`
+}
+
+// RetentionPolicies the retention policies of the repositories of a registry
+type RetentionPolicies struct {
+	// Default the policy of the repositories without a policy of their own
+	Default RetentionPolicy `json:"default"`
+	// Repositories the policies of the repositories. The first policy whose pattern matches is used
+	Repositories []RepositoryRetentionPolicy `json:"repositories,omitempty"`
+}
+
+// RetentionResult the images of a repository deleted or kept by a retention policy
+type RetentionResult struct {
+	Repository string
+	Deleted    []Image
+	Kept       []Image
+}
+
+// LoadRetentionPolicies loads the retention policies of the YAML file. The settings missing from the default policy of
+// the file are taken from the given default policy and the settings missing from the policy of a repository are
+// taken from the default policy
+func LoadRetentionPolicies(fileName string, defaultPolicy RetentionPolicy) (*RetentionPolicies, error) {
+	policies := &RetentionPolicies{Default: defaultPolicy}
+	if fileName == "" {
+		return policies, nil
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading the retention policies %s", fileName)
+	}
+	data, err = yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the retention policies %s", fileName)
+	}
+	file := struct {
+		Default      json.RawMessage   `json:"default"`
+		Repositories []json.RawMessage `json:"repositories"`
+	}{}
+	err = json.Unmarshal(data, &file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the retention policies %s", fileName)
+	}
+	if len(file.Default) > 0 {
+		err = json.Unmarshal(file.Default, &policies.Default)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing the default retention policy of %s", fileName)
+		}
+	}
+	_, err = policies.Default.olderThan()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid default retention policy in %s", fileName)
+	}
+	for _, raw := range file.Repositories {
+		policy := RepositoryRetentionPolicy{RetentionPolicy: policies.Default}
+		err = json.Unmarshal(raw, &policy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing a repository retention policy of %s", fileName)
+		}
+		if policy.Repository == "" {
+			return nil, fmt.Errorf("a repository retention policy of %s has no repository", fileName)
+		}
+		_, err = policy.olderThan()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid retention policy of %s in %s", policy.Repository, fileName)
+		}
+		policies.Repositories = append(policies.Repositories, policy)
+	}
+	return policies, nil
+}
+
+// PolicyFor returns the retention policy of the repository
+func (p *RetentionPolicies) PolicyFor(repository string) RetentionPolicy {
+	app := repository[strings.LastIndex(repository, "/")+1:]
+	for _, policy := range p.Repositories {
+		for _, name := range []string{repository, app} {
+			if matched, _ := path.Match(policy.Repository, name); matched {
+				return policy.RetentionPolicy
+			}
+		}
+	}
+	return p.Default
+}
+
+// Apply splits the images of the repository into the images which are deleted and kept by the policy at the given time
+func (p RetentionPolicy) Apply(repository string, images []Image, now time.Time) (*RetentionResult, error) {
+	olderThan, err := p.olderThan()
+	if err != nil {
+		return nil, err
+	}
+	sorted := append([]Image{}, images...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Created.After(sorted[j].Created)
+	})
+	result := &RetentionResult{Repository: repository}
+	for i, image := range sorted {
+		keep := p.KeepLast < 0 || i < p.KeepLast ||
+			(p.KeepReleases && IsRelease(image)) ||
+			image.Created.IsZero() || image.Created.After(now.Add(-olderThan))
+		if keep {
+			result.Kept = append(result.Kept, image)
+		} else {
+			result.Deleted = append(result.Deleted, image)
+		}
+	}
+	return result, nil
+}
+
+// ReclaimedSize returns the total size of the deleted images
+func (r *RetentionResult) ReclaimedSize() int64 {
+	var size int64
+	for _, image := range r.Deleted {
+		size += image.Size
+	}
+	return size
+}
+
+// IsRelease returns true if the image is tagged with a release version which is a semantic version without a
+// pre-release such as the snapshot versions of preview images
+func IsRelease(image Image) bool {
+	for _, tag := range image.Tags {
+		version, err := semver.Parse(strings.TrimPrefix(tag, "v"))
+		if err == nil && len(version.Pre) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatSize formats the size in bytes using binary units
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func (p RetentionPolicy) olderThan() (time.Duration, error) {
+	if p.OlderThan == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(p.OlderThan)
+	if err != nil {
+		return 0, errors.Wrapf(err, "parsing the age %s of a retention policy", p.OlderThan)
+	}
+	return duration, nil
+}
@@ -0,0 +1,107 @@
+// +build unit
+
+package registries
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetentionPolicyApply(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	images := []Image{
+		{Digest: "snapshot-old", Tags: []string{"0.0.0-SNAPSHOT-PR-12-1"}, Created: now.Add(-40 * day), Size: 100},
+		{Digest: "release-old", Tags: []string{"v1.0.0"}, Created: now.Add(-50 * day), Size: 1000},
+		{Digest: "untagged", Created: now.Add(-45 * day), Size: 10},
+		{Digest: "latest", Tags: []string{"1.0.1", "latest"}, Created: now.Add(-1 * day), Size: 1000},
+		{Digest: "snapshot-new", Tags: []string{"0.0.0-SNAPSHOT-PR-13-1"}, Created: now.Add(-2 * day), Size: 100},
+	}
+
+	result, err := RetentionPolicy{KeepLast: 2, KeepReleases: true}.Apply("myorg/myapp", images, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"latest", "snapshot-new", "release-old"}, digests(result.Kept))
+	assert.Equal(t, []string{"snapshot-old", "untagged"}, digests(result.Deleted))
+	assert.Equal(t, int64(110), result.ReclaimedSize())
+
+	result, err = RetentionPolicy{KeepLast: 1}.Apply("myorg/myapp", images, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"snapshot-new", "snapshot-old", "untagged", "release-old"}, digests(result.Deleted))
+
+	result, err = RetentionPolicy{OlderThan: "1008h"}.Apply("myorg/myapp", images, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"untagged", "release-old"}, digests(result.Deleted))
+
+	result, err = RetentionPolicy{KeepLast: -1}.Apply("myorg/myapp", images, now)
+	require.NoError(t, err)
+	assert.Empty(t, result.Deleted)
+
+	_, err = RetentionPolicy{OlderThan: "a month"}.Apply("myorg/myapp", images, now)
+	assert.Error(t, err)
+}
+
+func TestLoadRetentionPolicies(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-retention-")
+	require.NoError(t, err)
+	fileName := filepath.Join(dir, "retention.yaml")
+	err = ioutil.WriteFile(fileName, []byte(`default:
+  keepLast: 5
+repositories:
+- repository: myorg/myapp
+  olderThan: 168h
+- repository: legacy-*
+  keepLast: -1
+  keepReleases: false
+`), 0600)
+	require.NoError(t, err)
+
+	policies, err := LoadRetentionPolicies(fileName, RetentionPolicy{KeepLast: 10, KeepReleases: true})
+	require.NoError(t, err)
+	assert.Equal(t, RetentionPolicy{KeepLast: 5, KeepReleases: true}, policies.PolicyFor("myorg/other"))
+	assert.Equal(t, RetentionPolicy{KeepLast: 5, KeepReleases: true, OlderThan: "168h"}, policies.PolicyFor("myorg/myapp"))
+	assert.Equal(t, RetentionPolicy{KeepLast: -1}, policies.PolicyFor("myorg/legacy-app"))
+
+	policies, err = LoadRetentionPolicies("", RetentionPolicy{KeepLast: 10})
+	require.NoError(t, err)
+	assert.Equal(t, RetentionPolicy{KeepLast: 10}, policies.PolicyFor("myorg/myapp"))
+
+	err = ioutil.WriteFile(fileName, []byte("repositories:\n- keepLast: 1\n"), 0600)
+	require.NoError(t, err)
+	_, err = LoadRetentionPolicies(fileName, RetentionPolicy{})
+	assert.Error(t, err)
+}
+
+func TestIsRelease(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsRelease(Image{Tags: []string{"1.2.3"}}))
+	assert.True(t, IsRelease(Image{Tags: []string{"latest", "v1.2.3"}}))
+	assert.False(t, IsRelease(Image{Tags: []string{"0.0.0-SNAPSHOT-PR-12-3"}}))
+	assert.False(t, IsRelease(Image{Tags: []string{"latest"}}))
+	assert.False(t, IsRelease(Image{}))
+}
+
+func TestFormatSize(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "512 B", FormatSize(512))
+	assert.Equal(t, "1.5 KiB", FormatSize(1536))
+	assert.Equal(t, "2.0 GiB", FormatSize(2*1024*1024*1024))
+}
+
+func digests(images []Image) []string {
+	answer := []string{}
+	for _, image := range images {
+		answer = append(answer, image.Digest)
+	}
+	return answer
+}
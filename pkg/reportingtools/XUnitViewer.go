@@ -34,7 +34,7 @@ func (c XUnitViewer) EnsureXUnitViewer(o *opts.CommonOptions) error {
 			Default: true,
 		}
 
-		err = survey.AskOne(confirm, &installBinary, nil, surveyOpts)
+		err = util.AskOne(confirm, &installBinary, nil, surveyOpts)
 		if err != nil {
 			return err
 		}
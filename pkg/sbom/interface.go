@@ -0,0 +1,13 @@
+package sbom
+
+// Interface generates the Software Bill of Materials (SBOM) of images and publishes them alongside the images
+type Interface interface {
+	// Generate generates the SBOM of the image in the format to the file
+	Generate(image string, format string, file string) error
+
+	// Attach pushes the SBOM file alongside the image in its registry as an OCI artifact
+	Attach(image string, format string, file string) error
+
+	// Download returns the SBOM attached to the image in its registry
+	Download(image string) (string, error)
+}
@@ -0,0 +1,97 @@
+package sbom
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// FormatSPDX the SPDX JSON format of SBOMs
+	FormatSPDX = "spdx-json"
+	// FormatCycloneDX the CycloneDX JSON format of SBOMs
+	FormatCycloneDX = "cyclonedx-json"
+
+	syftBinary   = "syft"
+	cosignBinary = "cosign"
+)
+
+// Formats the supported formats of SBOMs
+var Formats = []string{FormatSPDX, FormatCycloneDX}
+
+// CLI implements the SBOM actions with the syft CLI which generates the SBOMs and the cosign CLI which pushes them to
+// the registries of the images
+type CLI struct {
+	Runner util.Commander
+}
+
+// NewCLI creates a new CLI running the syft and cosign binaries on the PATH
+func NewCLI() *CLI {
+	return &CLI{
+		Runner: &util.Command{},
+	}
+}
+
+// ValidateFormat returns an error if the format of SBOMs is not supported
+func ValidateFormat(format string) error {
+	if util.StringArrayIndex(Formats, format) < 0 {
+		return util.InvalidOption("format", format, Formats)
+	}
+	return nil
+}
+
+// FileName returns the name of the SBOM file of the image in the format such as myapp-1.2.3.spdx.json
+func FileName(image string, format string) string {
+	name := image
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.NewReplacer(":", "-", "@", "-").Replace(name)
+	if format == FormatCycloneDX {
+		return name + ".cdx.json"
+	}
+	return name + ".spdx.json"
+}
+
+// Generate generates the SBOM of the image in the format to the file
+func (c *CLI) Generate(image string, format string, file string) error {
+	err := ValidateFormat(format)
+	if err != nil {
+		return err
+	}
+	_, err = c.run(syftBinary, "packages", image, "--output", fmt.Sprintf("%s=%s", format, file))
+	if err != nil {
+		return errors.Wrapf(err, "generating the SBOM of image %s", image)
+	}
+	return nil
+}
+
+// Attach pushes the SBOM file alongside the image in its registry as an OCI artifact
+func (c *CLI) Attach(image string, format string, file string) error {
+	err := ValidateFormat(format)
+	if err != nil {
+		return err
+	}
+	_, err = c.run(cosignBinary, "attach", "sbom", "--sbom", file, "--type", strings.TrimSuffix(format, "-json"), image)
+	if err != nil {
+		return errors.Wrapf(err, "attaching the SBOM %s to image %s", file, image)
+	}
+	return nil
+}
+
+// Download returns the SBOM attached to the image in its registry
+func (c *CLI) Download(image string) (string, error) {
+	out, err := c.run(cosignBinary, "download", "sbom", image)
+	if err != nil {
+		return "", errors.Wrapf(err, "downloading the SBOM of image %s", image)
+	}
+	return out, nil
+}
+
+func (c *CLI) run(name string, args ...string) (string, error) {
+	c.Runner.SetName(name)
+	c.Runner.SetArgs(args)
+	return c.Runner.RunWithoutRetry()
+}
@@ -0,0 +1,61 @@
+// +build unit
+
+package sbom_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/sbom"
+	mocks "github.com/jenkins-x/jx/v2/pkg/util/mocks"
+	. "github.com/petergtz/pegomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const image = "gcr.io/myorg/myapp:1.2.3"
+
+func createCLI(t *testing.T, expectedOutput string) (*sbom.CLI, *mocks.MockCommander) {
+	RegisterMockTestingT(t)
+	runner := mocks.NewMockCommander()
+	When(runner.RunWithoutRetry()).ThenReturn(expectedOutput, nil)
+	return &sbom.CLI{Runner: runner}, runner
+}
+
+func TestGenerate(t *testing.T) {
+	cli, runner := createCLI(t, "")
+
+	err := cli.Generate(image, sbom.FormatCycloneDX, "myapp.cdx.json")
+
+	assert.NoError(t, err)
+	runner.VerifyWasCalledOnce().SetName("syft")
+	runner.VerifyWasCalledOnce().SetArgs([]string{"packages", image, "--output", "cyclonedx-json=myapp.cdx.json"})
+
+	err = cli.Generate(image, "xml", "myapp.xml")
+	assert.Error(t, err, "unsupported formats should be rejected")
+}
+
+func TestAttach(t *testing.T) {
+	cli, runner := createCLI(t, "")
+
+	err := cli.Attach(image, sbom.FormatSPDX, "myapp.spdx.json")
+
+	assert.NoError(t, err)
+	runner.VerifyWasCalledOnce().SetName("cosign")
+	runner.VerifyWasCalledOnce().SetArgs([]string{"attach", "sbom", "--sbom", "myapp.spdx.json", "--type", "spdx", image})
+}
+
+func TestDownload(t *testing.T) {
+	cli, runner := createCLI(t, `{"spdxVersion": "SPDX-2.2"}`)
+
+	text, err := cli.Download(image)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"spdxVersion": "SPDX-2.2"}`, text)
+	runner.VerifyWasCalledOnce().SetArgs([]string{"download", "sbom", image})
+}
+
+func TestFileName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "myapp-1.2.3.spdx.json", sbom.FileName(image, sbom.FormatSPDX))
+	assert.Equal(t, "myapp-1.2.3.cdx.json", sbom.FileName(image, sbom.FormatCycloneDX))
+	assert.Equal(t, "myapp-sha256-abc.spdx.json", sbom.FileName("myorg/myapp@sha256:abc", sbom.FormatSPDX))
+}
@@ -0,0 +1,149 @@
+package scan
+
+import (
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// PolicyConfigMapName the name of the ConfigMap in the dev namespace containing the scan policy of the team
+	PolicyConfigMapName = "jenkins-x-scan-policy"
+	// PolicyKey the key of the policy in the ConfigMap
+	PolicyKey = "policy.yaml"
+
+	// SeverityUnknown vulnerabilities of an unknown severity
+	SeverityUnknown = "UNKNOWN"
+	// SeverityLow low severity vulnerabilities
+	SeverityLow = "LOW"
+	// SeverityMedium medium severity vulnerabilities
+	SeverityMedium = "MEDIUM"
+	// SeverityHigh high severity vulnerabilities
+	SeverityHigh = "HIGH"
+	// SeverityCritical critical vulnerabilities
+	SeverityCritical = "CRITICAL"
+
+	// ResultPassed no vulnerability reaches the thresholds of the policy
+	ResultPassed = "passed"
+	// ResultWarned some vulnerabilities reach the warning threshold of the policy
+	ResultWarned = "warned"
+	// ResultFailed some vulnerabilities reach the failure threshold of the policy so the build fails
+	ResultFailed = "failed"
+)
+
+// Severities the severities of vulnerabilities from the lowest to the highest
+var Severities = []string{SeverityUnknown, SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+// Policy the scan policy of a team
+type Policy struct {
+	// Scanner the scanner of the images: trivy or grype
+	Scanner string `json:"scanner,omitempty"`
+	// FailOn the lowest severity of the vulnerabilities failing the build. The build never fails if empty
+	FailOn string `json:"failOn,omitempty"`
+	// WarnOn the lowest severity of the vulnerabilities reported as warnings
+	WarnOn string `json:"warnOn,omitempty"`
+	// IgnoreUnfixed ignores the vulnerabilities without a fixed version
+	IgnoreUnfixed bool `json:"ignoreUnfixed,omitempty"`
+	// Ignore the IDs of the vulnerabilities to ignore such as CVE-2020-1234
+	Ignore []string `json:"ignore,omitempty"`
+}
+
+// DefaultPolicy returns the policy used by teams which did not configure one, which fails on critical vulnerabilities
+// and warns on high ones
+func DefaultPolicy() *Policy {
+	return &Policy{
+		Scanner: ScannerTrivy,
+		FailOn:  SeverityCritical,
+		WarnOn:  SeverityHigh,
+	}
+}
+
+// SeverityIndex returns the index of the severity in the severities, -1 if it is not a severity
+func SeverityIndex(severity string) int {
+	return util.StringArrayIndex(Severities, strings.ToUpper(severity))
+}
+
+// Validate returns an error if the scanner or the thresholds of the policy are invalid
+func (p *Policy) Validate() error {
+	if util.StringArrayIndex(Scanners, p.Scanner) < 0 {
+		return util.InvalidOption("scanner", p.Scanner, Scanners)
+	}
+	if p.FailOn != "" && SeverityIndex(p.FailOn) < 0 {
+		return util.InvalidOption("fail-on", p.FailOn, Severities)
+	}
+	if p.WarnOn != "" && SeverityIndex(p.WarnOn) < 0 {
+		return util.InvalidOption("warn-on", p.WarnOn, Severities)
+	}
+	return nil
+}
+
+// Evaluate returns the result of the vulnerabilities against the policy and the vulnerabilities which are not ignored
+func (p *Policy) Evaluate(vulnerabilities []Vulnerability) (string, []Vulnerability) {
+	failOn := len(Severities)
+	if p.FailOn != "" {
+		failOn = SeverityIndex(p.FailOn)
+	}
+	warnOn := len(Severities)
+	if p.WarnOn != "" {
+		warnOn = SeverityIndex(p.WarnOn)
+	}
+	result := ResultPassed
+	answer := []Vulnerability{}
+	for _, v := range vulnerabilities {
+		if util.StringArrayIndex(p.Ignore, v.ID) >= 0 || (p.IgnoreUnfixed && v.FixedVersion == "") {
+			continue
+		}
+		answer = append(answer, v)
+		severity := SeverityIndex(v.Severity)
+		if severity >= failOn {
+			result = ResultFailed
+		} else if severity >= warnOn && result == ResultPassed {
+			result = ResultWarned
+		}
+	}
+	return result, answer
+}
+
+// LoadPolicy loads the scan policy from the ConfigMap in the namespace, returning the default policy if there is no
+// ConfigMap
+func LoadPolicy(kubeClient kubernetes.Interface, ns string) (*Policy, error) {
+	cm, err := kubeClient.CoreV1().ConfigMaps(ns).Get(PolicyConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return DefaultPolicy(), nil
+		}
+		return nil, errors.Wrapf(err, "getting ConfigMap %s in namespace %s", PolicyConfigMapName, ns)
+	}
+	policy := &Policy{}
+	err = yaml.Unmarshal([]byte(cm.Data[PolicyKey]), policy)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshalling the %s key of ConfigMap %s", PolicyKey, PolicyConfigMapName)
+	}
+	if policy.Scanner == "" {
+		policy.Scanner = ScannerTrivy
+	}
+	return policy, nil
+}
+
+// SavePolicy saves the scan policy to the ConfigMap in the namespace
+func SavePolicy(kubeClient kubernetes.Interface, ns string, policy *Policy) error {
+	data, err := yaml.Marshal(policy)
+	if err != nil {
+		return errors.Wrap(err, "marshalling the scan policy")
+	}
+	_, err = kube.DefaultModifyConfigMap(kubeClient, ns, PolicyConfigMapName, func(cm *corev1.ConfigMap) error {
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[PolicyKey] = string(data)
+		return nil
+	}, nil)
+	return err
+}
@@ -0,0 +1,128 @@
+package scan
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// ValueKindScanReport the kind label of the ConfigMaps storing the scan reports
+	ValueKindScanReport = "scan-report"
+	// LabelApplication the label of the ConfigMaps of the scan reports containing the name of the application
+	LabelApplication = "jenkins.io/application"
+	// ReportKey the key of the report in its ConfigMap
+	ReportKey = "report.yaml"
+
+	reportPrefix = "jx-scan-"
+)
+
+// Report the result of the scan of an image against the policy of the team
+type Report struct {
+	Name        string `json:"name"`
+	Image       string `json:"image"`
+	Application string `json:"application,omitempty"`
+	Version     string `json:"version,omitempty"`
+	Scanner     string `json:"scanner"`
+	Created     string `json:"created,omitempty"`
+	Result      string `json:"result"`
+	// Counts the number of vulnerabilities by severity
+	Counts          map[string]int  `json:"counts,omitempty"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities,omitempty"`
+}
+
+// NewReport creates the report of the vulnerabilities of the image against the policy
+func NewReport(image string, policy *Policy, vulnerabilities []Vulnerability) *Report {
+	result, vulnerabilities := policy.Evaluate(vulnerabilities)
+	counts := map[string]int{}
+	for _, v := range vulnerabilities {
+		counts[v.Severity]++
+	}
+	sort.SliceStable(vulnerabilities, func(i, j int) bool {
+		return SeverityIndex(vulnerabilities[i].Severity) > SeverityIndex(vulnerabilities[j].Severity)
+	})
+	return &Report{
+		Name:            naming.ToValidNameWithDotsTruncated(reportPrefix+image, 253),
+		Image:           image,
+		Scanner:         policy.Scanner,
+		Result:          result,
+		Counts:          counts,
+		Vulnerabilities: vulnerabilities,
+	}
+}
+
+// Summary returns the number of vulnerabilities of each severity such as 'CRITICAL:1 HIGH:3'
+func (r *Report) Summary() string {
+	answer := ""
+	for i := len(Severities) - 1; i >= 0; i-- {
+		if count := r.Counts[Severities[i]]; count > 0 {
+			if answer != "" {
+				answer += " "
+			}
+			answer += fmt.Sprintf("%s:%d", Severities[i], count)
+		}
+	}
+	return answer
+}
+
+// SaveReport creates or updates the report in the namespace
+func SaveReport(kubeClient kubernetes.Interface, ns string, report *Report) error {
+	data, err := yaml.Marshal(report)
+	if err != nil {
+		return errors.Wrapf(err, "marshalling scan report %s", report.Name)
+	}
+	_, err = kube.DefaultModifyConfigMap(kubeClient, ns, report.Name, func(cm *corev1.ConfigMap) error {
+		if cm.Labels == nil {
+			cm.Labels = map[string]string{}
+		}
+		cm.Labels[kube.LabelKind] = ValueKindScanReport
+		cm.Labels[kube.LabelCreatedBy] = kube.ValueCreatedByJX
+		if report.Application != "" {
+			cm.Labels[LabelApplication] = naming.ToValidValue(report.Application)
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[ReportKey] = string(data)
+		return nil
+	}, nil)
+	return err
+}
+
+// ListReports returns the reports in the namespace of the application or of all applications if it is empty, sorted
+// by the newest first
+func ListReports(kubeClient kubernetes.Interface, ns string, app string) ([]*Report, error) {
+	selector := kube.LabelKind + "=" + ValueKindScanReport
+	if app != "" {
+		selector += "," + LabelApplication + "=" + naming.ToValidValue(app)
+	}
+	list, err := kubeClient.CoreV1().ConfigMaps(ns).List(metav1.ListOptions{
+		LabelSelector: selector,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the scan reports in namespace %s", ns)
+	}
+	answer := []*Report{}
+	for _, cm := range list.Items {
+		report := &Report{}
+		err := yaml.Unmarshal([]byte(cm.Data[ReportKey]), report)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unmarshalling the %s key of ConfigMap %s", ReportKey, cm.Name)
+		}
+		answer = append(answer, report)
+	}
+	sort.SliceStable(answer, func(i, j int) bool {
+		if answer[i].Created != answer[j].Created {
+			return answer[i].Created > answer[j].Created
+		}
+		return answer[i].Name < answer[j].Name
+	})
+	return answer, nil
+}
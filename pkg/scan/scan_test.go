@@ -0,0 +1,127 @@
+// +build unit
+
+package scan_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/scan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const trivyReport = `{
+  "Results": [
+    {
+      "Target": "gcr.io/myorg/myapp:1.2.3 (alpine 3.12.0)",
+      "Vulnerabilities": [
+        {"VulnerabilityID": "CVE-2020-1111", "PkgName": "openssl", "InstalledVersion": "1.1.1g", "FixedVersion": "1.1.1i", "Severity": "HIGH"},
+        {"VulnerabilityID": "CVE-2020-2222", "PkgName": "musl", "InstalledVersion": "1.1.24", "Severity": "CRITICAL"},
+        {"VulnerabilityID": "CVE-2020-3333", "PkgName": "zlib", "InstalledVersion": "1.2.11", "FixedVersion": "1.2.12", "Severity": "LOW"}
+      ]
+    }
+  ]
+}`
+
+const grypeReport = `{
+  "matches": [
+    {
+      "vulnerability": {"id": "CVE-2020-1111", "severity": "High", "fix": {"versions": ["1.1.1i"]}},
+      "artifact": {"name": "openssl", "version": "1.1.1g"}
+    },
+    {
+      "vulnerability": {"id": "CVE-2020-4444", "severity": "Negligible", "fix": {"versions": []}},
+      "artifact": {"name": "bash", "version": "5.0"}
+    }
+  ]
+}`
+
+func TestParseReports(t *testing.T) {
+	t.Parallel()
+	vulnerabilities, err := scan.ParseTrivy([]byte(trivyReport))
+	require.NoError(t, err)
+	require.Len(t, vulnerabilities, 3)
+	assert.Equal(t, scan.Vulnerability{ID: "CVE-2020-1111", Package: "openssl", InstalledVersion: "1.1.1g", FixedVersion: "1.1.1i", Severity: scan.SeverityHigh}, vulnerabilities[0])
+
+	vulnerabilities, err = scan.ParseTrivy([]byte("null"))
+	require.NoError(t, err)
+	assert.Empty(t, vulnerabilities)
+
+	vulnerabilities, err = scan.ParseGrype([]byte(grypeReport))
+	require.NoError(t, err)
+	require.Len(t, vulnerabilities, 2)
+	assert.Equal(t, scan.Vulnerability{ID: "CVE-2020-1111", Package: "openssl", InstalledVersion: "1.1.1g", FixedVersion: "1.1.1i", Severity: scan.SeverityHigh}, vulnerabilities[0])
+	assert.Equal(t, scan.SeverityLow, vulnerabilities[1].Severity)
+}
+
+func TestPolicyEvaluate(t *testing.T) {
+	t.Parallel()
+	vulnerabilities, err := scan.ParseTrivy([]byte(trivyReport))
+	require.NoError(t, err)
+
+	result, reported := scan.DefaultPolicy().Evaluate(vulnerabilities)
+	assert.Equal(t, scan.ResultFailed, result)
+	assert.Len(t, reported, 3)
+
+	policy := scan.DefaultPolicy()
+	policy.IgnoreUnfixed = true
+	result, reported = policy.Evaluate(vulnerabilities)
+	assert.Equal(t, scan.ResultWarned, result, "the unfixed critical vulnerability should be ignored")
+	assert.Len(t, reported, 2)
+
+	policy.Ignore = []string{"CVE-2020-1111"}
+	result, _ = policy.Evaluate(vulnerabilities)
+	assert.Equal(t, scan.ResultPassed, result)
+
+	policy = &scan.Policy{Scanner: scan.ScannerTrivy, WarnOn: scan.SeverityLow}
+	result, _ = policy.Evaluate(vulnerabilities)
+	assert.Equal(t, scan.ResultWarned, result, "builds should not fail without a failure threshold")
+
+	policy.FailOn = "SEVERE"
+	assert.Error(t, policy.Validate())
+	policy = &scan.Policy{Scanner: "clair"}
+	assert.Error(t, policy.Validate())
+}
+
+func TestPoliciesAndReports(t *testing.T) {
+	t.Parallel()
+	kubeClient := fake.NewSimpleClientset()
+	ns := "jx"
+
+	policy, err := scan.LoadPolicy(kubeClient, ns)
+	require.NoError(t, err)
+	assert.Equal(t, scan.DefaultPolicy(), policy)
+
+	policy.FailOn = scan.SeverityHigh
+	policy.Scanner = scan.ScannerGrype
+	require.NoError(t, scan.SavePolicy(kubeClient, ns, policy))
+	loaded, err := scan.LoadPolicy(kubeClient, ns)
+	require.NoError(t, err)
+	assert.Equal(t, policy, loaded)
+
+	vulnerabilities, err := scan.ParseTrivy([]byte(trivyReport))
+	require.NoError(t, err)
+	report := scan.NewReport("gcr.io/myorg/myapp:1.2.3", policy, vulnerabilities)
+	report.Application = "myapp"
+	report.Created = "2020-12-01T10:00:00Z"
+	assert.Equal(t, "CRITICAL:1 HIGH:1 LOW:1", report.Summary())
+	assert.Equal(t, scan.SeverityCritical, report.Vulnerabilities[0].Severity, "the most severe vulnerabilities should come first")
+	require.NoError(t, scan.SaveReport(kubeClient, ns, report))
+
+	other := scan.NewReport("gcr.io/myorg/other:1.0.0", policy, nil)
+	other.Application = "other"
+	other.Created = "2020-12-02T10:00:00Z"
+	require.NoError(t, scan.SaveReport(kubeClient, ns, other))
+
+	reports, err := scan.ListReports(kubeClient, ns, "")
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+	assert.Equal(t, "other", reports[0].Application, "the newest reports should come first")
+
+	reports, err = scan.ListReports(kubeClient, ns, "myapp")
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, scan.ResultFailed, reports[0].Result)
+	assert.Len(t, reports[0].Vulnerabilities, 3)
+}
@@ -0,0 +1,164 @@
+package scan
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ScannerTrivy scans the images with trivy
+	ScannerTrivy = "trivy"
+	// ScannerGrype scans the images with grype
+	ScannerGrype = "grype"
+)
+
+// Scanners the supported scanners of images
+var Scanners = []string{ScannerTrivy, ScannerGrype}
+
+// Vulnerability a vulnerability of a package of an image
+type Vulnerability struct {
+	ID               string `json:"id"`
+	Package          string `json:"package,omitempty"`
+	InstalledVersion string `json:"installedVersion,omitempty"`
+	FixedVersion     string `json:"fixedVersion,omitempty"`
+	Severity         string `json:"severity"`
+	Title            string `json:"title,omitempty"`
+}
+
+// Scanner scans images for vulnerabilities
+type Scanner interface {
+	// Scan returns the vulnerabilities of the image
+	Scan(image string) ([]Vulnerability, error)
+}
+
+// CLI scans the images by running the trivy or grype binary
+type CLI struct {
+	Name   string
+	Runner util.Commander
+}
+
+// NewCLI creates a new CLI for the scanner running its binary on the PATH
+func NewCLI(scanner string) (*CLI, error) {
+	if util.StringArrayIndex(Scanners, scanner) < 0 {
+		return nil, util.InvalidOption("scanner", scanner, Scanners)
+	}
+	return &CLI{
+		Name: scanner,
+		Runner: &util.Command{
+			Name: scanner,
+		},
+	}, nil
+}
+
+// Scan returns the vulnerabilities of the image
+func (c *CLI) Scan(image string) ([]Vulnerability, error) {
+	var args []string
+	if c.Name == ScannerGrype {
+		args = []string{image, "--output", "json", "--quiet"}
+	} else {
+		args = []string{"image", "--format", "json", "--quiet", image}
+	}
+	c.Runner.SetArgs(args)
+	out, err := c.Runner.RunWithoutRetry()
+	if err != nil {
+		return nil, errors.Wrapf(err, "scanning image %s with %s", image, c.Name)
+	}
+	if c.Name == ScannerGrype {
+		return ParseGrype([]byte(out))
+	}
+	return ParseTrivy([]byte(out))
+}
+
+type trivyResult struct {
+	Vulnerabilities []struct {
+		VulnerabilityID  string
+		PkgName          string
+		InstalledVersion string
+		FixedVersion     string
+		Severity         string
+		Title            string
+	}
+}
+
+// ParseTrivy parses the JSON report of trivy
+func ParseTrivy(data []byte) ([]Vulnerability, error) {
+	report := struct {
+		Results []trivyResult
+	}{}
+	text := strings.TrimSpace(string(data))
+	var err error
+	if strings.HasPrefix(text, "[") {
+		// older versions of trivy output the results directly
+		err = json.Unmarshal(data, &report.Results)
+	} else if text != "" {
+		err = json.Unmarshal(data, &report)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing the trivy report")
+	}
+	answer := []Vulnerability{}
+	for _, r := range report.Results {
+		for _, v := range r.Vulnerabilities {
+			answer = append(answer, Vulnerability{
+				ID:               v.VulnerabilityID,
+				Package:          v.PkgName,
+				InstalledVersion: v.InstalledVersion,
+				FixedVersion:     v.FixedVersion,
+				Severity:         normalizeSeverity(v.Severity),
+				Title:            v.Title,
+			})
+		}
+	}
+	return answer, nil
+}
+
+// ParseGrype parses the JSON report of grype
+func ParseGrype(data []byte) ([]Vulnerability, error) {
+	report := struct {
+		Matches []struct {
+			Vulnerability struct {
+				ID          string `json:"id"`
+				Severity    string `json:"severity"`
+				Description string `json:"description"`
+				Fix         struct {
+					Versions []string `json:"versions"`
+				} `json:"fix"`
+			} `json:"vulnerability"`
+			Artifact struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"artifact"`
+		} `json:"matches"`
+	}{}
+	err := json.Unmarshal(data, &report)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing the grype report")
+	}
+	answer := []Vulnerability{}
+	for _, m := range report.Matches {
+		answer = append(answer, Vulnerability{
+			ID:               m.Vulnerability.ID,
+			Package:          m.Artifact.Name,
+			InstalledVersion: m.Artifact.Version,
+			FixedVersion:     strings.Join(m.Vulnerability.Fix.Versions, ", "),
+			Severity:         normalizeSeverity(m.Vulnerability.Severity),
+			Title:            m.Vulnerability.Description,
+		})
+	}
+	return answer, nil
+}
+
+// normalizeSeverity converts the severities of the scanners such as High or Negligible to the severities
+func normalizeSeverity(severity string) string {
+	severity = strings.ToUpper(severity)
+	if severity == "NEGLIGIBLE" {
+		return SeverityLow
+	}
+	if SeverityIndex(severity) < 0 {
+		return SeverityUnknown
+	}
+	return severity
+}
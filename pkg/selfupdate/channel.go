@@ -0,0 +1,150 @@
+package selfupdate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/google/go-github/v32/github"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// Channel the release channel used to pick the version of jx to upgrade to
+type Channel string
+
+const (
+	// ChannelStable only full releases
+	ChannelStable Channel = "stable"
+	// ChannelBeta full releases and beta or release candidate pre-releases
+	ChannelBeta Channel = "beta"
+	// ChannelNightly all releases including nightly builds
+	ChannelNightly Channel = "nightly"
+
+	// DefaultOwner the GitHub owner of the jx releases
+	DefaultOwner = "jenkins-x"
+	// DefaultRepository the GitHub repository of the jx releases
+	DefaultRepository = "jx"
+)
+
+// Channels the supported release channels
+var Channels = []string{string(ChannelStable), string(ChannelBeta), string(ChannelNightly)}
+
+// ParseChannel parses the channel name, defaulting to the stable channel
+func ParseChannel(name string) (Channel, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", string(ChannelStable):
+		return ChannelStable, nil
+	case string(ChannelBeta):
+		return ChannelBeta, nil
+	case string(ChannelNightly):
+		return ChannelNightly, nil
+	}
+	return "", fmt.Errorf("invalid channel %s, supported channels are %s", name, strings.Join(Channels, ", "))
+}
+
+// Includes returns true if the given version is published on the channel
+func (c Channel) Includes(v semver.Version) bool {
+	if len(v.Pre) == 0 {
+		return true
+	}
+	pre := strings.ToLower(v.Pre[0].String())
+	switch c {
+	case ChannelBeta:
+		return strings.HasPrefix(pre, "beta") || strings.HasPrefix(pre, "rc")
+	case ChannelNightly:
+		return true
+	}
+	return false
+}
+
+// Release a release of jx in the release feed
+type Release struct {
+	Version semver.Version
+	Tag     string
+	// Assets the download URLs of the release assets indexed by file name
+	Assets map[string]string
+}
+
+// ReleasesFromGitHub converts the GitHub releases into the release feed ignoring drafts and non semantic versions
+func ReleasesFromGitHub(ghReleases []*github.RepositoryRelease) []Release {
+	answer := []Release{}
+	for _, r := range ghReleases {
+		if r == nil || r.GetDraft() {
+			continue
+		}
+		v, err := semver.ParseTolerant(r.GetTagName())
+		if err != nil {
+			continue
+		}
+		release := Release{
+			Version: v,
+			Tag:     r.GetTagName(),
+			Assets:  map[string]string{},
+		}
+		for _, asset := range r.Assets {
+			release.Assets[asset.GetName()] = asset.GetBrowserDownloadURL()
+		}
+		answer = append(answer, release)
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Version.GT(answer[j].Version)
+	})
+	return answer
+}
+
+// FetchReleases fetches the release feed of the GitHub repository, newest first
+func FetchReleases(owner string, repo string) ([]Release, error) {
+	ghReleases, err := util.GetReleasesFromGitHub(owner, repo)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching the release feed")
+	}
+	return ReleasesFromGitHub(ghReleases), nil
+}
+
+// FetchRelease fetches the release with the given version from the GitHub repository
+func FetchRelease(owner string, repo string, version string) (*Release, error) {
+	v, err := semver.ParseTolerant(version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid version %s", version)
+	}
+	ghRelease, err := util.GetReleaseByTagFromGitHub(owner, repo, "v"+v.String())
+	if err != nil {
+		return nil, err
+	}
+	releases := ReleasesFromGitHub([]*github.RepositoryRelease{ghRelease})
+	if len(releases) == 0 {
+		return nil, fmt.Errorf("release %s is not a published release", ghRelease.GetTagName())
+	}
+	return &releases[0], nil
+}
+
+// LatestRelease returns the newest release on the channel
+func LatestRelease(releases []Release, channel Channel) (*Release, error) {
+	var answer *Release
+	for i := range releases {
+		r := &releases[i]
+		if channel.Includes(r.Version) && (answer == nil || r.Version.GT(answer.Version)) {
+			answer = r
+		}
+	}
+	if answer == nil {
+		return nil, fmt.Errorf("no releases found on the %s channel", channel)
+	}
+	return answer, nil
+}
+
+// FindRelease returns the release with the given version
+func FindRelease(releases []Release, version string) (*Release, error) {
+	v, err := semver.ParseTolerant(version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid version %s", version)
+	}
+	for i := range releases {
+		if releases[i].Version.EQ(v) {
+			return &releases[i], nil
+		}
+	}
+	return nil, fmt.Errorf("version %s not found in the release feed", version)
+}
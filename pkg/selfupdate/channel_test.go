@@ -0,0 +1,66 @@
+// +build unit
+
+package selfupdate_test
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/jenkins-x/jx/v2/pkg/selfupdate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChannel(t *testing.T) {
+	for name, expected := range map[string]selfupdate.Channel{
+		"":        selfupdate.ChannelStable,
+		"stable":  selfupdate.ChannelStable,
+		"Beta":    selfupdate.ChannelBeta,
+		"nightly": selfupdate.ChannelNightly,
+	} {
+		channel, err := selfupdate.ParseChannel(name)
+		require.NoError(t, err, "parsing channel %s", name)
+		assert.Equal(t, expected, channel, "channel %s", name)
+	}
+
+	_, err := selfupdate.ParseChannel("edge")
+	assert.Error(t, err)
+}
+
+func TestLatestRelease(t *testing.T) {
+	releases := selfupdate.ReleasesFromGitHub([]*github.RepositoryRelease{
+		{TagName: github.String("v2.1.100")},
+		{TagName: github.String("v2.1.102-nightly.20201001")},
+		{TagName: github.String("v2.1.101-rc.1")},
+		{TagName: github.String("v2.1.103"), Draft: github.Bool(true)},
+		{TagName: github.String("latest")},
+		{
+			TagName: github.String("v2.1.99"),
+			Assets: []*github.ReleaseAsset{
+				{Name: github.String("jx-linux-amd64.tar.gz"), BrowserDownloadURL: github.String("https://example.com/jx-linux-amd64.tar.gz")},
+			},
+		},
+	})
+	require.Len(t, releases, 4, "drafts and non semantic versions should be ignored")
+	assert.Equal(t, "v2.1.102-nightly.20201001", releases[0].Tag, "releases should be sorted newest first")
+
+	testCases := map[selfupdate.Channel]string{
+		selfupdate.ChannelStable:  "2.1.100",
+		selfupdate.ChannelBeta:    "2.1.101-rc.1",
+		selfupdate.ChannelNightly: "2.1.102-nightly.20201001",
+	}
+	for channel, expected := range testCases {
+		release, err := selfupdate.LatestRelease(releases, channel)
+		require.NoError(t, err, "channel %s", channel)
+		assert.Equal(t, expected, release.Version.String(), "channel %s", channel)
+	}
+
+	release, err := selfupdate.FindRelease(releases, "v2.1.99")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/jx-linux-amd64.tar.gz", release.Assets["jx-linux-amd64.tar.gz"])
+
+	_, err = selfupdate.FindRelease(releases, "2.0.0")
+	assert.Error(t, err)
+	_, err = selfupdate.LatestRelease(releases[:1], selfupdate.ChannelStable)
+	assert.Error(t, err, "there are no stable releases")
+}
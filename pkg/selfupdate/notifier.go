@@ -0,0 +1,206 @@
+package selfupdate
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// EnvNoUpdateNotifier environment variable to disable the new version available notice
+	EnvNoUpdateNotifier = "JX_NO_UPDATE_NOTIFIER"
+	// SettingsFileName the name of the file in ~/.jx storing the update notifier settings and last check
+	SettingsFileName = "update-notifier.yaml"
+	// DefaultCheckInterval how often the release feed is checked for new versions
+	DefaultCheckInterval = 24 * time.Hour
+)
+
+// Settings the update notifier settings and the result of the last check for a new version
+type Settings struct {
+	// Disabled opts out of the new version available notice
+	Disabled bool `json:"disabled,omitempty"`
+	// Channel the release channel to check, defaults to stable
+	Channel string `json:"channel,omitempty"`
+	// LastChecked when the release feed was last checked
+	LastChecked time.Time `json:"lastChecked,omitempty"`
+	// LatestVersion the latest version on the channel at the last check
+	LatestVersion string `json:"latestVersion,omitempty"`
+}
+
+// Notifier checks the release feed in the background and tells the user when a new version is available
+type Notifier struct {
+	// Dir the directory containing the settings file, defaults to ~/.jx
+	Dir string
+	// Interval the minimum time between checks of the release feed
+	Interval time.Duration
+	// Now returns the current time
+	Now func() time.Time
+	// Latest returns the latest release on the channel, defaults to fetching the jx release feed
+	Latest func(channel Channel) (*Release, error)
+}
+
+// SettingsFile returns the file name of the settings
+func (n *Notifier) SettingsFile() (string, error) {
+	dir := n.Dir
+	if dir == "" {
+		var err error
+		dir, err = util.ConfigDir()
+		if err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(dir, SettingsFileName), nil
+}
+
+// LoadSettings loads the settings returning empty settings if the file does not exist
+func (n *Notifier) LoadSettings() (*Settings, error) {
+	settings := &Settings{}
+	fileName, err := n.SettingsFile()
+	if err != nil {
+		return settings, err
+	}
+	exists, err := util.FileExists(fileName)
+	if err != nil || !exists {
+		return settings, err
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return settings, errors.Wrapf(err, "reading %s", fileName)
+	}
+	err = yaml.Unmarshal(data, settings)
+	if err != nil {
+		return settings, errors.Wrapf(err, "unmarshalling %s", fileName)
+	}
+	return settings, nil
+}
+
+// SaveSettings saves the settings. The file is replaced atomically as it may be written by a background check which
+// is killed when the command exits
+func (n *Notifier) SaveSettings(settings *Settings) error {
+	fileName, err := n.SettingsFile()
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(settings)
+	if err != nil {
+		return errors.Wrap(err, "marshalling the update notifier settings")
+	}
+	dir := filepath.Dir(fileName)
+	err = os.MkdirAll(dir, util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "creating directory %s", dir)
+	}
+	tmpFile, err := ioutil.TempFile(dir, "."+SettingsFileName)
+	if err != nil {
+		return errors.Wrapf(err, "creating a temporary file in %s", dir)
+	}
+	_, err = tmpFile.Write(data)
+	closeErr := tmpFile.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpFile.Name(), fileName)
+	}
+	if err != nil {
+		os.Remove(tmpFile.Name()) //nolint:errcheck
+		return errors.Wrapf(err, "writing %s", fileName)
+	}
+	return nil
+}
+
+// Disabled returns true if the user has opted out of the notice via the environment or the settings
+func (n *Notifier) Disabled(settings *Settings) bool {
+	value, err := util.ParseBool(strings.TrimSpace(os.Getenv(EnvNoUpdateNotifier)))
+	if err == nil && value {
+		return true
+	}
+	return settings.Disabled
+}
+
+// Notice returns the new version available message from the last check of the release feed, or an empty string if
+// the current version is up to date or the notice is disabled
+func (n *Notifier) Notice(settings *Settings, current semver.Version) string {
+	if n.Disabled(settings) || settings.LatestVersion == "" || isDevBuild(current) {
+		return ""
+	}
+	latest, err := semver.ParseTolerant(settings.LatestVersion)
+	if err != nil || !latest.GT(current) {
+		return ""
+	}
+	upgrade := "jx upgrade cli"
+	if settings.Channel != "" && settings.Channel != string(ChannelStable) {
+		upgrade += " --channel " + settings.Channel
+	}
+	return fmt.Sprintf("A new version of jx is available: %s (current %s). Run '%s' to upgrade or set %s=true to disable this notice",
+		util.ColorInfo(latest.String()), util.ColorInfo(current.String()), util.ColorInfo(upgrade), EnvNoUpdateNotifier)
+}
+
+// NeedsCheck returns true if the release feed has not been checked within the interval
+func (n *Notifier) NeedsCheck(settings *Settings) bool {
+	if n.Disabled(settings) {
+		return false
+	}
+	interval := n.Interval
+	if interval <= 0 {
+		interval = DefaultCheckInterval
+	}
+	return n.now().Sub(settings.LastChecked) >= interval
+}
+
+// Check fetches the latest release on the channel in the settings and saves it for the next notice
+func (n *Notifier) Check(settings *Settings) error {
+	channel, err := ParseChannel(settings.Channel)
+	if err != nil {
+		return err
+	}
+	latest := n.Latest
+	if latest == nil {
+		latest = fetchLatestRelease
+	}
+	// record the check before fetching so that it is not repeated on every command when the background check is killed
+	// by a short command exiting, or fails because the user is offline
+	settings.LastChecked = n.now()
+	err = n.SaveSettings(settings)
+	if err != nil {
+		return err
+	}
+	release, err := latest(channel)
+	if err != nil {
+		return err
+	}
+	settings.LatestVersion = release.Version.String()
+	return n.SaveSettings(settings)
+}
+
+func (n *Notifier) now() time.Time {
+	if n.Now != nil {
+		return n.Now()
+	}
+	return time.Now()
+}
+
+func fetchLatestRelease(channel Channel) (*Release, error) {
+	releases, err := FetchReleases(DefaultOwner, DefaultRepository)
+	if err != nil {
+		return nil, err
+	}
+	return LatestRelease(releases, channel)
+}
+
+func isDevBuild(v semver.Version) bool {
+	for _, pre := range v.Pre {
+		if pre.VersionStr == "dev" {
+			return true
+		}
+	}
+	return false
+}
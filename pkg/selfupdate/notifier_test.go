@@ -0,0 +1,78 @@
+// +build unit
+
+package selfupdate_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/jenkins-x/jx/v2/pkg/selfupdate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifier(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-selfupdate-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	os.Unsetenv(selfupdate.EnvNoUpdateNotifier)
+
+	now := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	checkedChannel := selfupdate.Channel("")
+	notifier := &selfupdate.Notifier{
+		Dir: dir,
+		Now: func() time.Time { return now },
+		Latest: func(channel selfupdate.Channel) (*selfupdate.Release, error) {
+			checkedChannel = channel
+			return &selfupdate.Release{Version: semver.MustParse("2.1.100")}, nil
+		},
+	}
+	current := semver.MustParse("2.1.90")
+
+	settings, err := notifier.LoadSettings()
+	require.NoError(t, err)
+	assert.Empty(t, notifier.Notice(settings, current), "nothing has been checked yet")
+	assert.True(t, notifier.NeedsCheck(settings))
+
+	settings.Channel = "beta"
+	require.NoError(t, notifier.Check(settings))
+	assert.Equal(t, selfupdate.ChannelBeta, checkedChannel)
+
+	settings, err = notifier.LoadSettings()
+	require.NoError(t, err)
+	assert.Equal(t, "2.1.100", settings.LatestVersion)
+	assert.False(t, notifier.NeedsCheck(settings), "should not check again within the interval")
+	assert.Contains(t, notifier.Notice(settings, current), "jx upgrade cli --channel beta")
+	assert.Empty(t, notifier.Notice(settings, semver.MustParse("2.1.100")), "already on the latest version")
+	assert.Empty(t, notifier.Notice(settings, semver.MustParse("2.1.90-dev+1234")), "dev builds are not notified")
+
+	now = now.Add(selfupdate.DefaultCheckInterval)
+	assert.True(t, notifier.NeedsCheck(settings))
+
+	// a failed check is recorded before fetching so that it is not retried on every command
+	notifier.Latest = func(channel selfupdate.Channel) (*selfupdate.Release, error) {
+		saved, err := notifier.LoadSettings()
+		require.NoError(t, err)
+		assert.Equal(t, now, saved.LastChecked.UTC(), "the check should be saved before fetching")
+		return nil, errors.New("offline")
+	}
+	assert.Error(t, notifier.Check(settings))
+	settings, err = notifier.LoadSettings()
+	require.NoError(t, err)
+	assert.False(t, notifier.NeedsCheck(settings))
+	assert.Equal(t, "2.1.100", settings.LatestVersion)
+
+	settings.Disabled = true
+	assert.Empty(t, notifier.Notice(settings, current), "the notice is disabled in the settings")
+
+	settings.Disabled = false
+	os.Setenv(selfupdate.EnvNoUpdateNotifier, "true")
+	defer os.Unsetenv(selfupdate.EnvNoUpdateNotifier)
+	assert.Empty(t, notifier.Notice(settings, current), "the notice is disabled via the environment")
+	now = now.Add(2 * selfupdate.DefaultCheckInterval)
+	assert.False(t, notifier.NeedsCheck(settings))
+}
@@ -0,0 +1,207 @@
+package selfupdate
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	binaryName       = "jx"
+	checksumsName    = binaryName + "-checksums.txt"
+	signatureSuffix  = ".sig"
+	windowsBinary    = "jx-windows-amd64.exe"
+	windowsOldSuffix = ".old"
+)
+
+// Updater downloads, verifies and installs a release of the jx binary
+type Updater struct {
+	// PublicKey the ed25519 key which must have signed the checksums file. Defaults to the release key embedded in the
+	// binary
+	PublicKey ed25519.PublicKey
+	// Download downloads the URL to the file, defaults to util.DownloadFile
+	Download func(fileName string, url string) error
+	GOOS     string
+	GOARCH   string
+}
+
+// ArchiveName returns the name of the release asset containing the binary for the platform
+func (u *Updater) ArchiveName() string {
+	extension := "tar.gz"
+	if u.goos() == "windows" {
+		extension = "zip"
+	}
+	return fmt.Sprintf("%s-%s-%s.%s", binaryName, u.goos(), u.goarch(), extension)
+}
+
+// Install downloads the release, verifies the signature of its checksums and its checksum then atomically replaces the
+// jx binary in the given directory. Releases with a missing or invalid signature are never installed
+func (u *Updater) Install(release *Release, binDir string) error {
+	archiveName := u.ArchiveName()
+	archiveURL := release.Assets[archiveName]
+	if archiveURL == "" {
+		return fmt.Errorf("release %s has no binary %s for this platform", release.Tag, archiveName)
+	}
+	if release.Assets[checksumsName] == "" {
+		return fmt.Errorf("release %s has no checksums file %s so the download cannot be verified", release.Tag, checksumsName)
+	}
+
+	tmpDir, err := ioutil.TempDir("", "jx-upgrade-")
+	if err != nil {
+		return errors.Wrap(err, "creating a temporary directory")
+	}
+	defer os.RemoveAll(tmpDir) //nolint:errcheck
+
+	checksumsFile := filepath.Join(tmpDir, checksumsName)
+	err = u.download(checksumsFile, release.Assets[checksumsName])
+	if err != nil {
+		return errors.Wrapf(err, "downloading %s", checksumsName)
+	}
+	checksums, err := ioutil.ReadFile(checksumsFile)
+	if err != nil {
+		return errors.Wrapf(err, "reading %s", checksumsFile)
+	}
+	publicKey := u.PublicKey
+	if publicKey == nil {
+		publicKey, err = DefaultPublicKey()
+		if err != nil {
+			return errors.Wrapf(err, "cannot verify the signature of release %s", release.Tag)
+		}
+	}
+	signatureName := checksumsName + signatureSuffix
+	signatureURL := release.Assets[signatureName]
+	if signatureURL == "" {
+		return fmt.Errorf("release %s has no signature %s so the download cannot be verified", release.Tag, signatureName)
+	}
+	signatureFile := filepath.Join(tmpDir, signatureName)
+	err = u.download(signatureFile, signatureURL)
+	if err != nil {
+		return errors.Wrapf(err, "downloading %s", signatureName)
+	}
+	signature, err := ioutil.ReadFile(signatureFile)
+	if err != nil {
+		return errors.Wrapf(err, "reading %s", signatureFile)
+	}
+	err = VerifySignature(checksums, signature, publicKey)
+	if err != nil {
+		return errors.Wrapf(err, "verifying %s", checksumsName)
+	}
+	log.Logger().Debugf("verified the signature of %s", checksumsName)
+
+	archiveFile := filepath.Join(tmpDir, archiveName)
+	err = u.download(archiveFile, archiveURL)
+	if err != nil {
+		return errors.Wrapf(err, "downloading %s", archiveName)
+	}
+	err = VerifyChecksum(archiveFile, archiveName, checksums)
+	if err != nil {
+		return err
+	}
+	log.Logger().Debugf("verified the checksum of %s", archiveName)
+
+	extractDir := filepath.Join(tmpDir, "bin")
+	err = os.MkdirAll(extractDir, util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "creating directory %s", extractDir)
+	}
+	source := filepath.Join(extractDir, binaryName)
+	target := filepath.Join(binDir, binaryName)
+	if u.goos() == "windows" {
+		err = util.UnzipSpecificFiles(archiveFile, extractDir, windowsBinary)
+		source = filepath.Join(extractDir, windowsBinary)
+		target += ".exe"
+	} else {
+		err = util.UnTargz(archiveFile, extractDir, []string{binaryName})
+	}
+	if err != nil {
+		return errors.Wrapf(err, "extracting %s", archiveName)
+	}
+	return ReplaceBinary(source, target)
+}
+
+// ReplaceBinary atomically replaces the target binary with the source binary. The source is first copied next to the
+// target so that the final rename never crosses file systems and the target is either the old or the new binary if
+// the upgrade is interrupted
+func ReplaceBinary(source string, target string) error {
+	dir := filepath.Dir(target)
+	tmpFile, err := ioutil.TempFile(dir, "."+filepath.Base(target)+".new")
+	if err != nil {
+		return errors.Wrapf(err, "creating a temporary file in %s", dir)
+	}
+	tmpName := tmpFile.Name()
+	err = copyToFile(source, tmpFile)
+	if err == nil {
+		err = os.Chmod(tmpName, 0755)
+	}
+	if err != nil {
+		os.Remove(tmpName) //nolint:errcheck
+		return errors.Wrapf(err, "copying %s to %s", source, tmpName)
+	}
+
+	// windows cannot replace a running executable but it can rename it
+	oldName := ""
+	if runtime.GOOS == "windows" {
+		exists, err := util.FileExists(target)
+		if err == nil && exists {
+			oldName = target + windowsOldSuffix
+			os.Remove(oldName) //nolint:errcheck
+			err = os.Rename(target, oldName)
+			if err != nil {
+				os.Remove(tmpName) //nolint:errcheck
+				return errors.Wrapf(err, "moving %s out of the way", target)
+			}
+		}
+	}
+	err = os.Rename(tmpName, target)
+	if err != nil {
+		os.Remove(tmpName) //nolint:errcheck
+		if oldName != "" {
+			os.Rename(oldName, target) //nolint:errcheck
+		}
+		return errors.Wrapf(err, "replacing %s", target)
+	}
+	return nil
+}
+
+func copyToFile(source string, out *os.File) error {
+	defer out.Close() //nolint:errcheck
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close() //nolint:errcheck
+	_, err = io.Copy(out, in)
+	if err != nil {
+		return err
+	}
+	return out.Sync()
+}
+
+func (u *Updater) download(fileName string, url string) error {
+	if u.Download != nil {
+		return u.Download(fileName, url)
+	}
+	return util.DownloadFile(fileName, url)
+}
+
+func (u *Updater) goos() string {
+	if u.GOOS != "" {
+		return u.GOOS
+	}
+	return runtime.GOOS
+}
+
+func (u *Updater) goarch() string {
+	if u.GOARCH != "" {
+		return u.GOARCH
+	}
+	return runtime.GOARCH
+}
@@ -0,0 +1,161 @@
+// +build unit
+
+package selfupdate_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/jenkins-x/jx/v2/pkg/selfupdate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyChecksum(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-selfupdate-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fileName := filepath.Join(dir, "jx-linux-amd64.tar.gz")
+	err = ioutil.WriteFile(fileName, []byte("hello"), 0600)
+	require.NoError(t, err)
+
+	checksums := []byte("2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824  jx-linux-amd64.tar.gz\n" +
+		"0000000000000000000000000000000000000000000000000000000000000000 *jx-darwin-amd64.tar.gz\n")
+	assert.NoError(t, selfupdate.VerifyChecksum(fileName, "jx-linux-amd64.tar.gz", checksums))
+	assert.Error(t, selfupdate.VerifyChecksum(fileName, "jx-darwin-amd64.tar.gz", checksums), "checksum should not match")
+	assert.Error(t, selfupdate.VerifyChecksum(fileName, "jx-windows-amd64.zip", checksums), "checksum should be missing")
+}
+
+func TestVerifySignature(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	data := []byte("checksums")
+	signature := ed25519.Sign(privateKey, data)
+
+	key, err := selfupdate.ParsePublicKey(base64.StdEncoding.EncodeToString(publicKey))
+	require.NoError(t, err)
+	assert.NoError(t, selfupdate.VerifySignature(data, signature, key), "raw signature")
+	assert.NoError(t, selfupdate.VerifySignature(data, []byte(base64.StdEncoding.EncodeToString(signature)+"\n"), key), "base64 signature")
+	assert.Error(t, selfupdate.VerifySignature([]byte("tampered"), signature, key))
+
+	_, err = selfupdate.ParsePublicKey("bm90IGEga2V5")
+	assert.Error(t, err)
+}
+
+func TestInstall(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-selfupdate-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// the published assets
+	assetsDir := filepath.Join(dir, "assets")
+	require.NoError(t, os.MkdirAll(assetsDir, 0700))
+	archiveName := "jx-linux-amd64.tar.gz"
+	writeTarGz(t, filepath.Join(assetsDir, archiveName), "jx", "new binary")
+	checksum, err := selfupdate.FileChecksum(filepath.Join(assetsDir, archiveName))
+	require.NoError(t, err)
+	checksums := []byte(fmt.Sprintf("%s  %s\n", checksum, archiveName))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(assetsDir, "jx-checksums.txt"), checksums, 0600))
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(assetsDir, "jx-checksums.txt.sig"), ed25519.Sign(privateKey, checksums), 0600))
+
+	release := &selfupdate.Release{
+		Version: semver.MustParse("2.1.100"),
+		Tag:     "v2.1.100",
+		Assets: map[string]string{
+			archiveName:            "file://" + archiveName,
+			"jx-checksums.txt":     "file://jx-checksums.txt",
+			"jx-checksums.txt.sig": "file://jx-checksums.txt.sig",
+		},
+	}
+	download := func(fileName string, url string) error {
+		data, err := ioutil.ReadFile(filepath.Join(assetsDir, url[len("file://"):]))
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(fileName, data, 0600)
+	}
+
+	binDir := filepath.Join(dir, "bin")
+	require.NoError(t, os.MkdirAll(binDir, 0700))
+	target := filepath.Join(binDir, "jx")
+	require.NoError(t, ioutil.WriteFile(target, []byte("old binary"), 0755))
+
+	updater := &selfupdate.Updater{
+		PublicKey: publicKey,
+		Download:  download,
+		GOOS:      "linux",
+		GOARCH:    "amd64",
+	}
+	err = updater.Install(release, binDir)
+	require.NoError(t, err)
+	assertFileContent(t, target, "new binary")
+	info, err := os.Stat(target)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+	files, err := ioutil.ReadDir(binDir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1, "no temporary files should be left behind")
+
+	// a tampered archive must not replace the binary
+	writeTarGz(t, filepath.Join(assetsDir, archiveName), "jx", "malicious binary")
+	err = updater.Install(release, binDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch")
+	assertFileContent(t, target, "new binary")
+
+	// a checksums file signed with another key must be rejected
+	otherKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	updater.PublicKey = otherKey
+	err = updater.Install(release, binDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "signature verification failed")
+
+	// releases are never installed without verifying their signature
+	updater.PublicKey = nil
+	err = updater.Install(release, binDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "without the release public key")
+	updater.PublicKey = publicKey
+	delete(release.Assets, "jx-checksums.txt.sig")
+	err = updater.Install(release, binDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "has no signature")
+	assertFileContent(t, target, "new binary")
+
+	// releases without checksums cannot be verified
+	delete(release.Assets, "jx-checksums.txt")
+	err = updater.Install(release, binDir)
+	assert.Error(t, err)
+}
+
+func writeTarGz(t *testing.T, fileName string, name string, content string) {
+	f, err := os.Create(fileName)
+	require.NoError(t, err)
+	defer f.Close()
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+	_, err = tw.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+}
+
+func assertFileContent(t *testing.T, fileName string, expected string) {
+	data, err := ioutil.ReadFile(fileName)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(data), "content of %s", fileName)
+}
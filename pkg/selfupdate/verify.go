@@ -0,0 +1,101 @@
+package selfupdate
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ReleasePublicKey the base64 encoded ed25519 public key which signs the checksums of the jx releases. It is embedded
+// into the binary at build time
+var ReleasePublicKey string
+
+// DefaultPublicKey returns the release key embedded in the binary or an error if this binary was built without one
+func DefaultPublicKey() (ed25519.PublicKey, error) {
+	if ReleasePublicKey == "" {
+		return nil, errors.New("this jx binary was built without the release public key")
+	}
+	return ParsePublicKey(ReleasePublicKey)
+}
+
+// ParseChecksums parses a checksums file in the sha256sum format into a map of file name to hex encoded checksum
+func ParseChecksums(data []byte) map[string]string {
+	answer := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		// sha256sum prefixes the file name with '*' in binary mode
+		answer[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	return answer
+}
+
+// FileChecksum returns the hex encoded sha256 checksum of the file
+func FileChecksum(fileName string) (string, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return "", errors.Wrapf(err, "opening %s", fileName)
+	}
+	defer f.Close() //nolint:errcheck
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, f)
+	if err != nil {
+		return "", errors.Wrapf(err, "reading %s", fileName)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// VerifyChecksum verifies the file matches the checksum of the asset in the checksums file
+func VerifyChecksum(fileName string, assetName string, checksums []byte) error {
+	expected, ok := ParseChecksums(checksums)[assetName]
+	if !ok {
+		return fmt.Errorf("no checksum found for %s", assetName)
+	}
+	actual, err := FileChecksum(fileName)
+	if err != nil {
+		return err
+	}
+	if actual != expected {
+		return fmt.Errorf("checksum mismatch for %s, expected %s but got %s", assetName, expected, actual)
+	}
+	return nil
+}
+
+// ParsePublicKey parses a base64 encoded ed25519 public key
+func ParsePublicKey(text string) (ed25519.PublicKey, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding the public key")
+	}
+	if len(data) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid ed25519 public key size %d", len(data))
+	}
+	return ed25519.PublicKey(data), nil
+}
+
+// VerifySignature verifies the ed25519 signature of the data. The signature may be raw or base64 encoded
+func VerifySignature(data []byte, signature []byte, publicKey ed25519.PublicKey) error {
+	if len(signature) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+		if err != nil {
+			return errors.Wrap(err, "decoding the signature")
+		}
+		signature = decoded
+	}
+	if !ed25519.Verify(publicKey, data, signature) {
+		return errors.New("signature verification failed")
+	}
+	return nil
+}
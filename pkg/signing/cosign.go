@@ -0,0 +1,130 @@
+package signing
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// KeySecretName the name of the Kubernetes Secret in the dev namespace containing the cosign key pair of the team
+	// generated via 'cosign generate-key-pair k8s://<namespace>/jx-cosign'
+	KeySecretName = "jx-cosign"
+	// SecretKeyPrivateKey the key of the private key in the Secret
+	SecretKeyPrivateKey = "cosign.key"
+	// SecretKeyPublicKey the key of the public key in the Secret
+	SecretKeyPublicKey = "cosign.pub"
+	// SecretKeyPassword the key of the password of the private key in the Secret
+	SecretKeyPassword = "cosign.password"
+
+	// EnvPassword the environment variable of the password of the private key used by cosign
+	EnvPassword = "COSIGN_PASSWORD"
+	// EnvExperimental the environment variable enabling keyless signing in cosign
+	EnvExperimental = "COSIGN_EXPERIMENTAL"
+
+	cosignBinary = "cosign"
+)
+
+// SignOptions the options to sign an image
+type SignOptions struct {
+	// Key the reference of the private key such as a file, k8s://namespace/secret or a KMS URI
+	Key string
+	// Password the password of the private key
+	Password string
+	// Keyless signs with an ephemeral key and a certificate issued for the OIDC identity of the pipeline
+	Keyless bool
+	// IdentityToken the OIDC identity token used by keyless signing, defaults to the ambient credentials
+	IdentityToken string
+	// Annotations the annotations added to the signature
+	Annotations map[string]string
+}
+
+// Signer signs images and verifies their signatures
+type Signer interface {
+	// Sign signs the image pushing the signature to its registry
+	Sign(image string, options *SignOptions) error
+	// SignatureRef returns the reference of the signature of the image in its registry
+	SignatureRef(image string) (string, error)
+	// Verify verifies the signature of the image with the public key, or the keyless certificate if the key is empty
+	Verify(image string, key string) error
+}
+
+// CLI signs the images by running the cosign binary
+type CLI struct {
+	Runner util.Commander
+}
+
+// NewCLI creates a new CLI running the cosign binary on the PATH
+func NewCLI() *CLI {
+	return &CLI{
+		Runner: &util.Command{
+			Name: cosignBinary,
+		},
+	}
+}
+
+// Sign signs the image pushing the signature to its registry
+func (c *CLI) Sign(image string, options *SignOptions) error {
+	args := []string{"sign"}
+	env := map[string]string{}
+	if options.Keyless {
+		env[EnvExperimental] = "1"
+		if options.IdentityToken != "" {
+			args = append(args, "--identity-token", options.IdentityToken)
+		}
+	} else {
+		if options.Key == "" {
+			return errors.Errorf("no key to sign image %s", image)
+		}
+		args = append(args, "--key", options.Key)
+		env[EnvPassword] = options.Password
+	}
+	keys := []string{}
+	for k := range options.Annotations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "-a", k+"="+options.Annotations[k])
+	}
+	args = append(args, image)
+	_, err := c.run(env, args...)
+	if err != nil {
+		return errors.Wrapf(err, "signing image %s", image)
+	}
+	return nil
+}
+
+// SignatureRef returns the reference of the signature of the image in its registry
+func (c *CLI) SignatureRef(image string) (string, error) {
+	out, err := c.run(nil, "triangulate", image)
+	if err != nil {
+		return "", errors.Wrapf(err, "finding the signature of image %s", image)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// Verify verifies the signature of the image with the public key, or the keyless certificate if the key is empty
+func (c *CLI) Verify(image string, key string) error {
+	args := []string{"verify"}
+	env := map[string]string{}
+	if key == "" {
+		env[EnvExperimental] = "1"
+	} else {
+		args = append(args, "--key", key)
+	}
+	args = append(args, image)
+	_, err := c.run(env, args...)
+	if err != nil {
+		return errors.Wrapf(err, "verifying the signature of image %s", image)
+	}
+	return nil
+}
+
+func (c *CLI) run(env map[string]string, args ...string) (string, error) {
+	c.Runner.SetEnv(env)
+	c.Runner.SetArgs(args)
+	return c.Runner.RunWithoutRetry()
+}
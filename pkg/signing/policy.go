@@ -0,0 +1,105 @@
+package signing
+
+import (
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// LabelPolicyInclude the label of the namespaces whose pods are only admitted if their images are signed
+	LabelPolicyInclude = "policy.sigstore.dev/include"
+
+	// DefaultFulcioURL the URL of the certificate authority issuing the certificates of keyless signatures
+	DefaultFulcioURL = "https://fulcio.sigstore.dev"
+)
+
+// ClusterImagePolicyResource the resource of the policies of the sigstore policy controller verifying the signatures
+// of images when admitting pods
+var ClusterImagePolicyResource = schema.GroupVersionResource{Group: "policy.sigstore.dev", Version: "v1beta1", Resource: "clusterimagepolicies"}
+
+// ImagePolicy the images which have to be signed and the authority of their signatures
+type ImagePolicy struct {
+	Name string
+	// Images the glob of the images the policy applies to such as gcr.io/myorg/**
+	Images string
+	// PublicKey the PEM public key of the key signing the images
+	PublicKey string
+	// Issuer the OIDC issuer of the identities of keyless signatures
+	Issuer string
+	// Subject the identity of keyless signatures such as the service account of the pipelines
+	Subject string
+}
+
+// ToUnstructured returns the ClusterImagePolicy of the policy
+func (p *ImagePolicy) ToUnstructured() (*unstructured.Unstructured, error) {
+	var authority map[string]interface{}
+	switch {
+	case p.PublicKey != "":
+		authority = map[string]interface{}{
+			"key": map[string]interface{}{
+				"data": p.PublicKey,
+			},
+		}
+	case p.Issuer != "" && p.Subject != "":
+		authority = map[string]interface{}{
+			"keyless": map[string]interface{}{
+				"url": DefaultFulcioURL,
+				"identities": []interface{}{
+					map[string]interface{}{
+						"issuer":  p.Issuer,
+						"subject": p.Subject,
+					},
+				},
+			},
+		}
+	default:
+		return nil, errors.Errorf("image policy %s has no public key nor keyless issuer and subject", p.Name)
+	}
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": ClusterImagePolicyResource.GroupVersion().String(),
+			"kind":       "ClusterImagePolicy",
+			"metadata": map[string]interface{}{
+				"name": p.Name,
+			},
+			"spec": map[string]interface{}{
+				"images": []interface{}{
+					map[string]interface{}{
+						"glob": p.Images,
+					},
+				},
+				"authorities": []interface{}{authority},
+			},
+		},
+	}, nil
+}
+
+// ApplyImagePolicy creates or updates the ClusterImagePolicy of the policy
+func ApplyImagePolicy(client dynamic.Interface, policy *ImagePolicy) error {
+	u, err := policy.ToUnstructured()
+	if err != nil {
+		return err
+	}
+	resources := client.Resource(ClusterImagePolicyResource)
+	existing, err := resources.Get(policy.Name, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "getting ClusterImagePolicy %s", policy.Name)
+		}
+		_, err = resources.Create(u, metav1.CreateOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "creating ClusterImagePolicy %s", policy.Name)
+		}
+		return nil
+	}
+	u.SetResourceVersion(existing.GetResourceVersion())
+	_, err = resources.Update(u, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "updating ClusterImagePolicy %s", policy.Name)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package signing
+
+import (
+	"sort"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// ValueKindImageSignature the kind label of the ConfigMaps recording the signatures of images
+	ValueKindImageSignature = "image-signature"
+	// SignatureKey the key of the signature in its ConfigMap
+	SignatureKey = "signature.yaml"
+
+	signaturePrefix = "jx-signature-"
+)
+
+// Signature the record of the signature of an image
+type Signature struct {
+	Name        string `json:"name"`
+	Image       string `json:"image"`
+	Application string `json:"application,omitempty"`
+	Version     string `json:"version,omitempty"`
+	// Ref the reference of the signature in the registry of the image
+	Ref string `json:"ref,omitempty"`
+	// Key the reference of the key which signed the image, empty for keyless signatures
+	Key     string `json:"key,omitempty"`
+	Keyless bool   `json:"keyless,omitempty"`
+	Signed  string `json:"signed,omitempty"`
+}
+
+// SignatureName returns the name of the ConfigMap of the signature of the image
+func SignatureName(image string) string {
+	return naming.ToValidNameWithDotsTruncated(signaturePrefix+image, 253)
+}
+
+// SaveSignature creates or updates the record of the signature in the namespace
+func SaveSignature(kubeClient kubernetes.Interface, ns string, signature *Signature) error {
+	if signature.Name == "" {
+		signature.Name = SignatureName(signature.Image)
+	}
+	data, err := yaml.Marshal(signature)
+	if err != nil {
+		return errors.Wrapf(err, "marshalling the signature of image %s", signature.Image)
+	}
+	_, err = kube.DefaultModifyConfigMap(kubeClient, ns, signature.Name, func(cm *corev1.ConfigMap) error {
+		if cm.Labels == nil {
+			cm.Labels = map[string]string{}
+		}
+		cm.Labels[kube.LabelKind] = ValueKindImageSignature
+		cm.Labels[kube.LabelCreatedBy] = kube.ValueCreatedByJX
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[SignatureKey] = string(data)
+		return nil
+	}, nil)
+	return err
+}
+
+// ListSignatures returns the signatures recorded in the namespace sorted by the newest first
+func ListSignatures(kubeClient kubernetes.Interface, ns string) ([]*Signature, error) {
+	list, err := kubeClient.CoreV1().ConfigMaps(ns).List(metav1.ListOptions{
+		LabelSelector: kube.LabelKind + "=" + ValueKindImageSignature,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the image signatures in namespace %s", ns)
+	}
+	answer := []*Signature{}
+	for _, cm := range list.Items {
+		signature := &Signature{}
+		err := yaml.Unmarshal([]byte(cm.Data[SignatureKey]), signature)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unmarshalling the %s key of ConfigMap %s", SignatureKey, cm.Name)
+		}
+		answer = append(answer, signature)
+	}
+	sort.SliceStable(answer, func(i, j int) bool {
+		if answer[i].Signed != answer[j].Signed {
+			return answer[i].Signed > answer[j].Signed
+		}
+		return answer[i].Name < answer[j].Name
+	})
+	return answer, nil
+}
@@ -0,0 +1,99 @@
+// +build unit
+
+package signing_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/signing"
+	mocks "github.com/jenkins-x/jx/v2/pkg/util/mocks"
+	. "github.com/petergtz/pegomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const image = "gcr.io/myorg/myapp:1.2.3"
+
+func createCLI(t *testing.T, expectedOutput string) (*signing.CLI, *mocks.MockCommander) {
+	RegisterMockTestingT(t)
+	runner := mocks.NewMockCommander()
+	When(runner.RunWithoutRetry()).ThenReturn(expectedOutput, nil)
+	return &signing.CLI{Runner: runner}, runner
+}
+
+func TestSignWithKey(t *testing.T) {
+	cli, runner := createCLI(t, "")
+
+	err := cli.Sign(image, &signing.SignOptions{
+		Key:         "k8s://jx/jx-cosign",
+		Password:    "secret",
+		Annotations: map[string]string{"version": "1.2.3", "app": "myapp"},
+	})
+
+	require.NoError(t, err)
+	runner.VerifyWasCalledOnce().SetEnv(map[string]string{signing.EnvPassword: "secret"})
+	runner.VerifyWasCalledOnce().SetArgs([]string{"sign", "--key", "k8s://jx/jx-cosign", "-a", "app=myapp", "-a", "version=1.2.3", image})
+
+	err = cli.Sign(image, &signing.SignOptions{})
+	assert.Error(t, err, "signing without a key nor keyless should fail")
+}
+
+func TestSignKeyless(t *testing.T) {
+	cli, runner := createCLI(t, "gcr.io/myorg/myapp:sha256-abc.sig\n")
+
+	err := cli.Sign(image, &signing.SignOptions{Keyless: true, IdentityToken: "token"})
+	require.NoError(t, err)
+	runner.VerifyWasCalledOnce().SetEnv(map[string]string{signing.EnvExperimental: "1"})
+	runner.VerifyWasCalledOnce().SetArgs([]string{"sign", "--identity-token", "token", image})
+
+	ref, err := cli.SignatureRef(image)
+	require.NoError(t, err)
+	assert.Equal(t, "gcr.io/myorg/myapp:sha256-abc.sig", ref)
+}
+
+func TestSignatures(t *testing.T) {
+	t.Parallel()
+	kubeClient := fake.NewSimpleClientset()
+	ns := "jx"
+
+	require.NoError(t, signing.SaveSignature(kubeClient, ns, &signing.Signature{Image: image, Key: "k8s://jx/jx-cosign", Signed: "2020-12-01T10:00:00Z"}))
+	require.NoError(t, signing.SaveSignature(kubeClient, ns, &signing.Signature{Image: "gcr.io/myorg/other:1.0.0", Keyless: true, Signed: "2020-12-02T10:00:00Z"}))
+
+	signatures, err := signing.ListSignatures(kubeClient, ns)
+	require.NoError(t, err)
+	require.Len(t, signatures, 2)
+	assert.Equal(t, "gcr.io/myorg/other:1.0.0", signatures[0].Image, "the newest signatures should come first")
+	assert.Equal(t, signing.SignatureName(image), signatures[1].Name)
+	assert.Equal(t, "k8s://jx/jx-cosign", signatures[1].Key)
+}
+
+func TestApplyImagePolicy(t *testing.T) {
+	t.Parallel()
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+
+	policy := &signing.ImagePolicy{Name: "jx-signed-images", Images: "gcr.io/myorg/**", PublicKey: "-----BEGIN PUBLIC KEY-----"}
+	require.NoError(t, signing.ApplyImagePolicy(client, policy))
+
+	policy.PublicKey = ""
+	policy.Issuer = "https://token.actions.githubusercontent.com"
+	policy.Subject = "https://github.com/myorg/myapp/.github/workflows/release.yaml@refs/heads/master"
+	require.NoError(t, signing.ApplyImagePolicy(client, policy))
+
+	u, err := client.Resource(signing.ClusterImagePolicyResource).Get("jx-signed-images", metav1.GetOptions{})
+	require.NoError(t, err)
+	authorities, _, err := unstructured.NestedSlice(u.Object, "spec", "authorities")
+	require.NoError(t, err)
+	require.Len(t, authorities, 1)
+	keyless, found, err := unstructured.NestedMap(authorities[0].(map[string]interface{}), "keyless")
+	require.NoError(t, err)
+	require.True(t, found, "the policy should be updated to keyless")
+	assert.Equal(t, signing.DefaultFulcioURL, keyless["url"])
+
+	policy.Issuer = ""
+	assert.Error(t, signing.ApplyImagePolicy(client, policy), "policies without a public key nor identity should fail")
+}
@@ -205,7 +205,7 @@ func (model *SpringBootModel) CreateSurvey(data *SpringBootForm, advanced bool,
 	if emptyArray(data.Dependencies) {
 		qs = append(qs, CreateSpringTreeSelect("Dependencies", "dependencies", &model.Dependencies, data))
 	}
-	return survey.Ask(qs, data)
+	return util.Ask(qs, data)
 }
 
 func (options *SpringOptions) StringArray() []string {
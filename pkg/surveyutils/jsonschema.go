@@ -602,7 +602,7 @@ func (o *JSONSchemaOptions) handleArrayProperty(name string, t *Type, output *or
 				Message: message,
 				Options: options,
 			}
-			err := survey.AskOne(prompt, &answer, validator, surveyOpts)
+			err := util.AskOne(prompt, &answer, validator, surveyOpts)
 			if err != nil {
 				return err
 			}
@@ -774,7 +774,7 @@ func (o *JSONSchemaOptions) handleBasicProperty(name string, prefixes []string,
 			Help:    help,
 		}
 		if ask {
-			err := survey.AskOne(prompt, &enumResult, validator, surveyOpts)
+			err := util.AskOne(prompt, &enumResult, validator, surveyOpts)
 			if err != nil {
 				return err
 			}
@@ -806,7 +806,7 @@ func (o *JSONSchemaOptions) handleBasicProperty(name string, prefixes []string,
 		}
 
 		if ask {
-			err = survey.AskOne(prompt, &answer, validator, surveyOpts)
+			err = util.AskOne(prompt, &answer, validator, surveyOpts)
 			if err != nil {
 				return errors.Wrapf(err, "error asking user %s using validators %v", message, validators)
 			}
@@ -829,7 +829,7 @@ func (o *JSONSchemaOptions) handleBasicProperty(name string, prefixes []string,
 		var answer string
 		var err error
 		if ask {
-			err = survey.AskOne(prompt, &answer, validator, surveyOpts)
+			err = util.AskOne(prompt, &answer, validator, surveyOpts)
 			if err != nil {
 				return errors.Wrapf(err, "error asking user %s using validators %v", message, validators)
 			}
@@ -899,7 +899,7 @@ func handlePasswordProperty(message string, help string, kind string, ask bool,
 
 	var answer string
 	if ask {
-		err := survey.AskOne(prompt, &answer, validator, surveyOpts)
+		err := util.AskOne(prompt, &answer, validator, surveyOpts)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,52 @@
+package tekton
+
+import (
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/tekton/syntax"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// BuildCacheKey the key of the build cache configuration in its ConfigMap
+const BuildCacheKey = "cache.yaml"
+
+// LoadBuildCache loads the layer cache configuration of the installation or returns nil if there is none
+func LoadBuildCache(kubeClient kubernetes.Interface, ns string) (*syntax.BuildCache, error) {
+	cm, err := kubeClient.CoreV1().ConfigMaps(ns).Get(kube.ConfigMapJenkinsBuildCache, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "getting ConfigMap %s in namespace %s", kube.ConfigMapJenkinsBuildCache, ns)
+	}
+	data := cm.Data[BuildCacheKey]
+	if data == "" {
+		return nil, nil
+	}
+	cache := &syntax.BuildCache{}
+	err = yaml.Unmarshal([]byte(data), cache)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshalling the %s key of ConfigMap %s", BuildCacheKey, kube.ConfigMapJenkinsBuildCache)
+	}
+	return cache, nil
+}
+
+// SaveBuildCache saves the layer cache configuration of the installation in the namespace
+func SaveBuildCache(kubeClient kubernetes.Interface, ns string, cache *syntax.BuildCache) error {
+	data, err := yaml.Marshal(cache)
+	if err != nil {
+		return errors.Wrap(err, "marshalling the build cache")
+	}
+	_, err = kube.DefaultModifyConfigMap(kubeClient, ns, kube.ConfigMapJenkinsBuildCache, func(cm *corev1.ConfigMap) error {
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[BuildCacheKey] = string(data)
+		return nil
+	}, nil)
+	return err
+}
@@ -0,0 +1,30 @@
+// +build unit
+
+package tekton_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/tekton"
+	"github.com/jenkins-x/jx/v2/pkg/tekton/syntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLoadAndSaveBuildCache(t *testing.T) {
+	t.Parallel()
+	kubeClient := fake.NewSimpleClientset()
+
+	cache, err := tekton.LoadBuildCache(kubeClient, ns)
+	require.NoError(t, err)
+	assert.Nil(t, cache, "there should be no build cache by default")
+
+	expected := &syntax.BuildCache{Type: syntax.BuildCacheS3, URL: "s3://mybucket/cache", Region: "us-east-1"}
+	err = tekton.SaveBuildCache(kubeClient, ns, expected)
+	require.NoError(t, err)
+
+	cache, err = tekton.LoadBuildCache(kubeClient, ns)
+	require.NoError(t, err)
+	assert.Equal(t, expected, cache)
+}
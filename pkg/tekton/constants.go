@@ -26,6 +26,9 @@ const (
 	// LabelType is the label added to Tekton CRDs for the type of pipeline.
 	LabelType = "jenkins.io/pipelineType"
 
+	// AnnotationRestartedFrom is the annotation added to a PipelineRun restarting a failed PipelineRun with its name
+	AnnotationRestartedFrom = "jenkins.io/restarted-from"
+
 	// DefaultPipelineSA is the default service account used for pipelines
 	DefaultPipelineSA = "tekton-bot"
 )
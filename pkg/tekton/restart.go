@@ -0,0 +1,280 @@
+package tekton
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/tekton/syntax"
+	"github.com/pkg/errors"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// restartWorkspaceVolume the volume of the PVC of the failed PipelineRun the workspace is copied from
+	restartWorkspaceVolume = "restart-workspace"
+	restartWorkspaceDir    = "/restart-workspace"
+	workspaceResource      = "workspace"
+)
+
+// RestartPoint the stage and step a failed PipelineRun is restarted from
+type RestartPoint struct {
+	// PipelineTask the name of the task of the stage in the Pipeline
+	PipelineTask string
+	// Step the name of the step in the Task of the stage
+	Step string
+}
+
+// PipelineRunFailed returns true if the PipelineRun completed unsuccessfully
+func PipelineRunFailed(pr *pipelineapi.PipelineRun) bool {
+	condition := pr.Status.GetCondition(apis.ConditionSucceeded)
+	return condition != nil && condition.IsFalse()
+}
+
+// FailedRestartPoint returns the stage and step which failed in the PipelineRun
+func FailedRestartPoint(pr *pipelineapi.PipelineRun) (*RestartPoint, error) {
+	names := []string{}
+	for name := range pr.Status.TaskRuns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		taskRun := pr.Status.TaskRuns[name]
+		if taskRun == nil || taskRun.Status == nil {
+			continue
+		}
+		condition := taskRun.Status.GetCondition(apis.ConditionSucceeded)
+		if condition == nil || !condition.IsFalse() {
+			continue
+		}
+		point := &RestartPoint{PipelineTask: taskRun.PipelineTaskName}
+		for _, step := range taskRun.Status.Steps {
+			if step.Terminated != nil && step.Terminated.ExitCode != 0 {
+				point.Step = step.Name
+				break
+			}
+		}
+		return point, nil
+	}
+	return nil, errors.Errorf("no failed stage found in PipelineRun %s", pr.Name)
+}
+
+// RestartCRDs returns the Task, Pipeline and PipelineRun re-running a failed PipelineRun from the restart point.
+// The stages preceding the stage of the restart point are not run again and only the setup steps of its task run
+// before the step of the restart point. If the PVC of the failed PipelineRun is given the workspace of the stage is
+// copied from the output of the stage which passed it on in the failed PipelineRun rather than fetched from source
+func RestartCRDs(pr *pipelineapi.PipelineRun, pl *pipelineapi.Pipeline, task *pipelineapi.Task, point RestartPoint, suffix string, pvcName string) (*pipelineapi.Pipeline, *pipelineapi.Task, *pipelineapi.PipelineRun, error) {
+	var restarted *pipelineapi.PipelineTask
+	for i := range pl.Spec.Tasks {
+		if pl.Spec.Tasks[i].Name == point.PipelineTask {
+			restarted = &pl.Spec.Tasks[i]
+		}
+	}
+	if restarted == nil {
+		return nil, nil, nil, errors.Errorf("no stage %s in Pipeline %s", point.PipelineTask, pl.Name)
+	}
+	skipped := precedingTasks(pl, point.PipelineTask)
+
+	newTask := task.DeepCopy()
+	newTask.ObjectMeta = restartedMeta(task.ObjectMeta, suffix)
+	err := skipStepsBefore(newTask, point.Step)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	newPipeline := pl.DeepCopy()
+	newPipeline.ObjectMeta = restartedMeta(pl.ObjectMeta, suffix)
+	pipelineTasks := []pipelineapi.PipelineTask{}
+	for _, pt := range newPipeline.Spec.Tasks {
+		if skipped[pt.Name] {
+			continue
+		}
+		pt.RunAfter = withoutTasks(pt.RunAfter, skipped)
+		if pt.Resources != nil {
+			for i, input := range pt.Resources.Inputs {
+				from := withoutTasks(input.From, skipped)
+				if pt.Name == point.PipelineTask && input.Name == workspaceResource && len(from) < len(input.From) && pvcName != "" {
+					copyWorkspace(newTask, pvcName, input.From[0])
+				}
+				pt.Resources.Inputs[i].From = from
+			}
+		}
+		if pt.Name == point.PipelineTask {
+			pt.TaskRef = &pipelineapi.TaskRef{Name: newTask.Name}
+		}
+		pipelineTasks = append(pipelineTasks, pt)
+	}
+	newPipeline.Spec.Tasks = pipelineTasks
+
+	newRun := &pipelineapi.PipelineRun{
+		TypeMeta:   pr.TypeMeta,
+		ObjectMeta: restartedMeta(pr.ObjectMeta, suffix),
+		Spec:       *pr.Spec.DeepCopy(),
+	}
+	if newRun.Annotations == nil {
+		newRun.Annotations = map[string]string{}
+	}
+	newRun.Annotations[AnnotationRestartedFrom] = pr.Name
+	newRun.Spec.Status = ""
+	if newRun.Spec.PipelineRef != nil {
+		newRun.Spec.PipelineRef.Name = newPipeline.Name
+	}
+	return newPipeline, newTask, newRun, nil
+}
+
+// RestartStructure returns a copy of the PipelineStructure of a failed PipelineRun for the PipelineRun restarting it
+func RestartStructure(structure *v1.PipelineStructure, task *pipelineapi.Task, newTask *pipelineapi.Task, newPipeline *pipelineapi.Pipeline, newRun *pipelineapi.PipelineRun) *v1.PipelineStructure {
+	answer := structure.DeepCopy()
+	answer.ObjectMeta = metav1.ObjectMeta{
+		Name:      newRun.Name,
+		Namespace: newRun.Namespace,
+		Labels:    copyMap(structure.Labels),
+	}
+	answer.PipelineRef = &newPipeline.Name
+	answer.PipelineRunRef = &newRun.Name
+	for i := range answer.Stages {
+		stage := &answer.Stages[i]
+		if stage.TaskRef != nil && *stage.TaskRef == task.Name {
+			stage.TaskRef = &newTask.Name
+		}
+	}
+	return answer
+}
+
+// isRestartSetupStep returns true if the step prepares the workspace or credentials of a stage so has to run before
+// the step a stage is restarted from
+func isRestartSetupStep(name string) bool {
+	return name == "git-merge" || name == "git-checkout" || strings.HasPrefix(name, "setup-")
+}
+
+func skipStepsBefore(task *pipelineapi.Task, stepName string) error {
+	if stepName == "" {
+		return nil
+	}
+	names := []string{}
+	for i, step := range task.Spec.Steps {
+		names = append(names, step.Name)
+		if step.Name != stepName {
+			continue
+		}
+		steps := []pipelineapi.Step{}
+		for _, s := range task.Spec.Steps[:i] {
+			if isRestartSetupStep(s.Name) {
+				steps = append(steps, s)
+			}
+		}
+		task.Spec.Steps = append(steps, task.Spec.Steps[i:]...)
+		return nil
+	}
+	return errors.Errorf("no step %s in Task %s, the steps are: %s", stepName, task.Name, strings.Join(names, ", "))
+}
+
+// copyWorkspace adds a first step to the task copying the workspace output by the given stage from the PVC
+func copyWorkspace(task *pipelineapi.Task, pvcName string, fromTask string) {
+	task.Spec.Volumes = append(task.Spec.Volumes, corev1.Volume{
+		Name: restartWorkspaceVolume,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: pvcName,
+				ReadOnly:  true,
+			},
+		},
+	})
+	targetDir := filepath.Join(syntax.WorkingDirRoot, "source")
+	if task.Spec.Inputs != nil {
+		for _, r := range task.Spec.Inputs.Resources {
+			if r.Name == workspaceResource {
+				targetDir = pipelineapi.InputResourcePath(r.ResourceDeclaration)
+			}
+		}
+	}
+	image := syntax.DefaultContainerImage
+	if len(task.Spec.Steps) > 0 {
+		image = task.Spec.Steps[0].Image
+	}
+	sourceDir := filepath.Join(restartWorkspaceDir, fromTask, workspaceResource)
+	step := pipelineapi.Step{
+		Container: corev1.Container{
+			Name:    "restore-workspace",
+			Image:   image,
+			Command: []string{"/bin/sh", "-c"},
+			Args:    []string{fmt.Sprintf("cp -a %s/. %s/", sourceDir, targetDir)},
+			VolumeMounts: []corev1.VolumeMount{
+				{
+					Name:      restartWorkspaceVolume,
+					MountPath: restartWorkspaceDir,
+					ReadOnly:  true,
+				},
+			},
+		},
+	}
+	task.Spec.Steps = append([]pipelineapi.Step{step}, task.Spec.Steps...)
+}
+
+// precedingTasks returns the names of the tasks which have to complete before the given task of the pipeline
+func precedingTasks(pipeline *pipelineapi.Pipeline, name string) map[string]bool {
+	runAfter := map[string][]string{}
+	for _, pt := range pipeline.Spec.Tasks {
+		runAfter[pt.Name] = pt.RunAfter
+		if pt.Resources != nil {
+			for _, input := range pt.Resources.Inputs {
+				runAfter[pt.Name] = append(runAfter[pt.Name], input.From...)
+			}
+		}
+	}
+	answer := map[string]bool{}
+	pending := append([]string{}, runAfter[name]...)
+	for len(pending) > 0 {
+		n := pending[0]
+		pending = pending[1:]
+		if answer[n] || n == name {
+			continue
+		}
+		answer[n] = true
+		pending = append(pending, runAfter[n]...)
+	}
+	return answer
+}
+
+func withoutTasks(names []string, skipped map[string]bool) []string {
+	var answer []string
+	for _, n := range names {
+		if !skipped[n] {
+			answer = append(answer, n)
+		}
+	}
+	return answer
+}
+
+func restartedMeta(meta metav1.ObjectMeta, suffix string) metav1.ObjectMeta {
+	answer := metav1.ObjectMeta{
+		Name:        syntax.MangleToRfc1035Label(meta.Name, suffix),
+		Namespace:   meta.Namespace,
+		Labels:      copyMap(meta.Labels),
+		Annotations: copyMap(meta.Annotations),
+	}
+	// the labels tekton adds refer to the failed resources
+	for k := range answer.Labels {
+		if strings.HasPrefix(k, pipeline.GroupName+"/") {
+			delete(answer.Labels, k)
+		}
+	}
+	return answer
+}
+
+func copyMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	answer := map[string]string{}
+	for k, v := range m {
+		answer[k] = v
+	}
+	return answer
+}
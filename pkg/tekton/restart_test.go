@@ -0,0 +1,182 @@
+// +build unit
+
+package tekton_test
+
+import (
+	"testing"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/tekton"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+func restartFixture() (*pipelineapi.PipelineRun, *pipelineapi.Pipeline, *pipelineapi.Task) {
+	pr := &pipelineapi.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myorg-myrepo-master-3",
+			Namespace: "jx",
+			Labels: map[string]string{
+				tekton.LabelOwner:     "myorg",
+				tekton.LabelRepo:      "myrepo",
+				tekton.LabelBranch:    "master",
+				tekton.LabelBuild:     "3",
+				"tekton.dev/pipeline": "myorg-myrepo-master-3",
+			},
+		},
+		Spec: pipelineapi.PipelineRunSpec{
+			PipelineRef: &pipelineapi.PipelineRef{Name: "myorg-myrepo-master-3"},
+		},
+	}
+	pr.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse})
+	buildStatus := &pipelineapi.TaskRunStatus{}
+	buildStatus.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue})
+	promoteStatus := &pipelineapi.TaskRunStatus{}
+	promoteStatus.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse})
+	promoteStatus.Steps = []pipelineapi.StepState{
+		{Name: "git-merge", ContainerState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}}},
+		{Name: "promote-changelog", ContainerState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}}},
+		{Name: "promote-jx-promote", ContainerState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1}}},
+	}
+	pr.Status.TaskRuns = map[string]*pipelineapi.PipelineRunTaskRunStatus{
+		"myorg-myrepo-master-3-build-abcde":   {PipelineTaskName: "build", Status: buildStatus},
+		"myorg-myrepo-master-3-promote-fghij": {PipelineTaskName: "promote", Status: promoteStatus},
+	}
+
+	pipeline := &pipelineapi.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Name: "myorg-myrepo-master-3", Namespace: "jx"},
+		Spec: pipelineapi.PipelineSpec{
+			Tasks: []pipelineapi.PipelineTask{
+				{
+					Name:    "build",
+					TaskRef: &pipelineapi.TaskRef{Name: "myorg-myrepo-master-build-3"},
+					Resources: &pipelineapi.PipelineTaskResources{
+						Inputs:  []pipelineapi.PipelineTaskInputResource{{Name: "workspace", Resource: "myorg-myrepo-master"}},
+						Outputs: []pipelineapi.PipelineTaskOutputResource{{Name: "workspace", Resource: "myorg-myrepo-master"}},
+					},
+				},
+				{
+					Name:     "promote",
+					TaskRef:  &pipelineapi.TaskRef{Name: "myorg-myrepo-master-promote-3"},
+					RunAfter: []string{"build"},
+					Resources: &pipelineapi.PipelineTaskResources{
+						Inputs: []pipelineapi.PipelineTaskInputResource{{Name: "workspace", Resource: "myorg-myrepo-master", From: []string{"build"}}},
+					},
+				},
+			},
+		},
+	}
+
+	task := &pipelineapi.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "myorg-myrepo-master-promote-3", Namespace: "jx"},
+		Spec: pipelineapi.TaskSpec{
+			TaskSpec: stepsTaskSpec("git-merge", "setup-jx-git-credentials", "promote-changelog", "promote-helm-release", "promote-jx-promote"),
+		},
+	}
+	return pr, pipeline, task
+}
+
+func stepsTaskSpec(names ...string) v1beta1.TaskSpec {
+	spec := v1beta1.TaskSpec{}
+	for _, name := range names {
+		spec.Steps = append(spec.Steps, pipelineapi.Step{Container: corev1.Container{Name: name, Image: "gcr.io/jenkinsxio/builder-go"}})
+	}
+	return spec
+}
+
+func stepNames(task *pipelineapi.Task) []string {
+	names := []string{}
+	for _, s := range task.Spec.Steps {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+func TestFailedRestartPoint(t *testing.T) {
+	t.Parallel()
+	pr, _, _ := restartFixture()
+	assert.True(t, tekton.PipelineRunFailed(pr))
+
+	point, err := tekton.FailedRestartPoint(pr)
+	require.NoError(t, err)
+	assert.Equal(t, &tekton.RestartPoint{PipelineTask: "promote", Step: "promote-jx-promote"}, point)
+}
+
+func TestRestartCRDsWithWorkspace(t *testing.T) {
+	t.Parallel()
+	pr, pipeline, task := restartFixture()
+	point := tekton.RestartPoint{PipelineTask: "promote", Step: "promote-jx-promote"}
+
+	newPipeline, newTask, newRun, err := tekton.RestartCRDs(pr, pipeline, task, point, "restart-1", "myorg-myrepo-master-3-pvc")
+	require.NoError(t, err)
+
+	assert.Equal(t, "myorg-myrepo-master-3-restart-1", newPipeline.Name)
+	require.Len(t, newPipeline.Spec.Tasks, 1, "the stages before the failed stage should not run again")
+	promote := newPipeline.Spec.Tasks[0]
+	assert.Equal(t, "promote", promote.Name)
+	assert.Empty(t, promote.RunAfter)
+	assert.Empty(t, promote.Resources.Inputs[0].From)
+	assert.Equal(t, newTask.Name, promote.TaskRef.Name)
+
+	assert.Equal(t, "myorg-myrepo-master-promote-3-restart-1", newTask.Name)
+	assert.Equal(t, []string{"restore-workspace", "git-merge", "setup-jx-git-credentials", "promote-jx-promote"}, stepNames(newTask))
+	assert.Equal(t, "myorg-myrepo-master-3-pvc", newTask.Spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+	assert.Equal(t, []string{"cp -a /restart-workspace/build/workspace/. /workspace/source/"}, newTask.Spec.Steps[0].Args)
+	assert.Len(t, task.Spec.Steps, 5, "the failed Task should not be modified")
+
+	assert.Equal(t, "myorg-myrepo-master-3-restart-1", newRun.Name)
+	assert.Equal(t, newPipeline.Name, newRun.Spec.PipelineRef.Name)
+	assert.Equal(t, pr.Name, newRun.Annotations[tekton.AnnotationRestartedFrom])
+	assert.Equal(t, "3", newRun.Labels[tekton.LabelBuild])
+	assert.NotContains(t, newRun.Labels, "tekton.dev/pipeline")
+	assert.Nil(t, newRun.Status.GetCondition(apis.ConditionSucceeded))
+	assert.Equal(t, "myorg-myrepo-master-3", pr.Spec.PipelineRef.Name, "the failed PipelineRun should not be modified")
+}
+
+func TestRestartCRDsWithoutWorkspace(t *testing.T) {
+	t.Parallel()
+	pr, pipeline, task := restartFixture()
+	point := tekton.RestartPoint{PipelineTask: "promote", Step: "promote-helm-release"}
+
+	_, newTask, _, err := tekton.RestartCRDs(pr, pipeline, task, point, "restart-1", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"git-merge", "setup-jx-git-credentials", "promote-helm-release", "promote-jx-promote"}, stepNames(newTask))
+	assert.Empty(t, newTask.Spec.Volumes)
+}
+
+func TestRestartCRDsUnknownStep(t *testing.T) {
+	t.Parallel()
+	pr, pipeline, task := restartFixture()
+	point := tekton.RestartPoint{PipelineTask: "promote", Step: "does-not-exist"}
+
+	_, _, _, err := tekton.RestartCRDs(pr, pipeline, task, point, "restart-1", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "promote-changelog, promote-helm-release, promote-jx-promote")
+}
+
+func TestRestartStructure(t *testing.T) {
+	t.Parallel()
+	pr, pipeline, task := restartFixture()
+	newPipeline, newTask, newRun, err := tekton.RestartCRDs(pr, pipeline, task, tekton.RestartPoint{PipelineTask: "promote"}, "restart-1", "")
+	require.NoError(t, err)
+
+	buildTask := "myorg-myrepo-master-build-3"
+	structure := &v1.PipelineStructure{
+		ObjectMeta: metav1.ObjectMeta{Name: pipeline.Name, Namespace: "jx"},
+		Stages: []v1.PipelineStructureStage{
+			{Name: "build", TaskRef: &buildTask},
+			{Name: "promote", TaskRef: &task.Name},
+		},
+	}
+	answer := tekton.RestartStructure(structure, task, newTask, newPipeline, newRun)
+	assert.Equal(t, newRun.Name, answer.Name)
+	assert.Equal(t, newPipeline.Name, *answer.PipelineRef)
+	assert.Equal(t, newRun.Name, *answer.PipelineRunRef)
+	assert.Equal(t, buildTask, *answer.Stages[0].TaskRef)
+	assert.Equal(t, newTask.Name, *answer.Stages[1].TaskRef)
+}
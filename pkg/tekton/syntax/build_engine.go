@@ -0,0 +1,380 @@
+package syntax
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// BuildEngineKind the kind of engine building the images of a pipeline
+type BuildEngineKind string
+
+const (
+	// BuildEngineKaniko builds images in the pipeline pod with Kaniko
+	BuildEngineKaniko BuildEngineKind = "kaniko"
+	// BuildEngineBuildKit builds images in the pipeline pod with rootless BuildKit
+	BuildEngineBuildKit BuildEngineKind = "buildkit"
+	// BuildEngineGCB submits the builds of images to Google Cloud Build
+	BuildEngineGCB BuildEngineKind = "gcb"
+	// BuildEngineACR submits the builds of images to Azure Container Registry Tasks
+	BuildEngineACR BuildEngineKind = "acr"
+	// BuildEngineCNB builds images in the pipeline pod from the sources with the Cloud Native Buildpacks of a builder
+	// image, running the same lifecycle as 'pack build' without a Dockerfile
+	BuildEngineCNB BuildEngineKind = "cnb"
+)
+
+// BuildEngineKinds the supported kinds of build engines
+var BuildEngineKinds = []string{string(BuildEngineKaniko), string(BuildEngineBuildKit), string(BuildEngineGCB), string(BuildEngineACR), string(BuildEngineCNB)}
+
+// BuildCacheType the type of storage of the layer cache of the builds
+type BuildCacheType string
+
+const (
+	// BuildCacheRegistry caches the layers in a repository of a docker registry
+	BuildCacheRegistry BuildCacheType = "registry"
+	// BuildCacheS3 caches the layers in an S3 bucket
+	BuildCacheS3 BuildCacheType = "s3"
+	// BuildCacheGCS caches the layers in a GCS bucket via its S3 compatible API
+	BuildCacheGCS BuildCacheType = "gcs"
+
+	gcsEndpointURL = "https://storage.googleapis.com"
+)
+
+// BuildCacheTypes the supported types of layer caches
+var BuildCacheTypes = []string{string(BuildCacheRegistry), string(BuildCacheS3), string(BuildCacheGCS)}
+
+// BuildResourcesPreset the name of a preset of the resources of the builds of images
+type BuildResourcesPreset string
+
+const (
+	// BuildResourcesSmall the resources of small images
+	BuildResourcesSmall BuildResourcesPreset = "small"
+	// BuildResourcesMedium the resources of most images
+	BuildResourcesMedium BuildResourcesPreset = "medium"
+	// BuildResourcesLarge the resources of large images such as the ones compiling their sources
+	BuildResourcesLarge BuildResourcesPreset = "large"
+)
+
+// buildResourcesPreset the requests and limits of a preset and the machine type of the cloud builders
+type buildResourcesPreset struct {
+	requestCPU, requestMemory, limitCPU, limitMemory string
+	gcbMachineType                                   string
+}
+
+var buildResourcesPresets = map[BuildResourcesPreset]buildResourcesPreset{
+	BuildResourcesSmall:  {"500m", "1Gi", "1", "2Gi", ""},
+	BuildResourcesMedium: {"1", "2Gi", "2", "4Gi", "e2-highcpu-8"},
+	BuildResourcesLarge:  {"2", "4Gi", "4", "8Gi", "e2-highcpu-32"},
+}
+
+// BuildEngine configures the engine which replaces the 'skaffold build' steps building the images of a pipeline
+type BuildEngine struct {
+	// Kind the kind of the engine: kaniko, buildkit, gcb or acr. Defaults to kaniko
+	Kind BuildEngineKind `json:"kind,omitempty"`
+	// Image overrides the image of the build step. For Cloud Native Buildpacks it is the builder image
+	Image string `json:"image,omitempty"`
+	// Resources the preset of the resources of the build: small, medium or large. For Google Cloud Build it selects
+	// the machine type of the build
+	Resources BuildResourcesPreset `json:"resources,omitempty"`
+	// Registry the name of the Azure container registry running the builds. Defaults to the name of the docker registry
+	Registry string `json:"registry,omitempty"`
+	// Cache configures the layer cache of the builds
+	Cache *BuildCache `json:"cache,omitempty"`
+}
+
+// BuildCache configures the layer cache of the builds of images
+type BuildCache struct {
+	// Disabled disables the layer cache
+	Disabled bool `json:"disabled,omitempty"`
+	// Type the storage of the cached layers: registry, s3 or gcs. Defaults to registry. The buckets are only
+	// supported by BuildKit
+	Type BuildCacheType `json:"type,omitempty"`
+	// Repo the repository of the cached layers. Defaults to <docker registry>/<project id>/cache
+	Repo string `json:"repo,omitempty"`
+	// URL the URL of the bucket and optional path of the cached layers such as s3://mybucket/cache or gs://mybucket
+	URL string `json:"url,omitempty"`
+	// Region the region of the S3 bucket
+	Region string `json:"region,omitempty"`
+	// TTL how long the cached layers are used such as 24h. Only used by Kaniko
+	TTL string `json:"ttl,omitempty"`
+}
+
+// GetKind returns the kind of the engine defaulting to Kaniko
+func (e *BuildEngine) GetKind() BuildEngineKind {
+	if e == nil || e.Kind == "" {
+		return BuildEngineKaniko
+	}
+	return e.Kind
+}
+
+// Validate returns an error if the kind or resources of the engine are not supported
+func (e *BuildEngine) Validate() error {
+	if e == nil {
+		return nil
+	}
+	kind := string(e.GetKind())
+	found := false
+	for _, k := range BuildEngineKinds {
+		if k == kind {
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("unsupported build engine %s, the supported engines are: %s", kind, strings.Join(BuildEngineKinds, ", "))
+	}
+	if e.Resources != "" {
+		if _, ok := buildResourcesPresets[e.Resources]; !ok {
+			presets := []string{}
+			for k := range buildResourcesPresets {
+				presets = append(presets, string(k))
+			}
+			sort.Strings(presets)
+			return fmt.Errorf("unsupported build resources %s, the supported presets are: %s", e.Resources, strings.Join(presets, ", "))
+		}
+	}
+	err := e.Cache.Validate()
+	if err != nil {
+		return err
+	}
+	if e.Cache.IsBucket() && !e.SupportsBucketCache() {
+		return fmt.Errorf("the %s build engine only supports %s caches, use the %s build engine to cache the layers in buckets",
+			e.GetKind(), BuildCacheRegistry, BuildEngineBuildKit)
+	}
+	return nil
+}
+
+// SupportsBucketCache returns false if the engine can only cache the layers in a registry
+func (e *BuildEngine) SupportsBucketCache() bool {
+	kind := e.GetKind()
+	return kind != BuildEngineKaniko && kind != BuildEngineCNB
+}
+
+// GetType returns the type of the cache defaulting to a registry
+func (c *BuildCache) GetType() BuildCacheType {
+	if c == nil || c.Type == "" {
+		return BuildCacheRegistry
+	}
+	return c.Type
+}
+
+// IsBucket returns true if the layers are cached in a bucket
+func (c *BuildCache) IsBucket() bool {
+	return c != nil && !c.Disabled && c.GetType() != BuildCacheRegistry
+}
+
+// Validate returns an error if the type of the cache is not supported or its bucket URL is missing
+func (c *BuildCache) Validate() error {
+	if c == nil {
+		return nil
+	}
+	cacheType := c.GetType()
+	switch cacheType {
+	case BuildCacheRegistry:
+		return nil
+	case BuildCacheS3, BuildCacheGCS:
+		scheme := "s3://"
+		if cacheType == BuildCacheGCS {
+			scheme = "gs://"
+		}
+		if !strings.HasPrefix(c.URL, scheme) || strings.TrimPrefix(c.URL, scheme) == "" {
+			return fmt.Errorf("the URL of the %s cache must be a bucket URL such as %smybucket/cache but was '%s'", cacheType, scheme, c.URL)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported build cache type %s, the supported types are: %s", cacheType, strings.Join(BuildCacheTypes, ", "))
+	}
+}
+
+// buildKitArgs returns the BuildKit arguments exporting and importing the layers of the image to and from the cache
+func (c *BuildCache) buildKitArgs(cacheRepo string, image string) []string {
+	if c.Disabled {
+		return nil
+	}
+	var ref string
+	switch c.GetType() {
+	case BuildCacheS3, BuildCacheGCS:
+		bucket := c.URL[strings.Index(c.URL, "://")+3:]
+		prefix := ""
+		i := strings.Index(bucket, "/")
+		if i > 0 {
+			prefix = strings.Trim(bucket[i+1:], "/")
+			bucket = bucket[:i]
+		}
+		region := c.Region
+		ref = "type=s3"
+		if c.GetType() == BuildCacheGCS {
+			ref += ",endpoint_url=" + gcsEndpointURL
+			if region == "" {
+				region = "auto"
+			}
+		}
+		if region != "" {
+			ref += ",region=" + region
+		}
+		ref += ",bucket=" + bucket
+		if prefix != "" {
+			ref += ",prefix=" + prefix + "/"
+		}
+		ref += ",name=" + image
+	default:
+		ref = "type=registry,ref=" + cacheRepo + "/" + image + ":buildcache"
+	}
+	return []string{"--export-cache", ref + ",mode=max", "--import-cache", ref}
+}
+
+// MergeBuildEngines returns the build engine with the empty fields of the override defaulted from the base. A different
+// kind in the override discards the image and registry of the base
+func MergeBuildEngines(base, override *BuildEngine) *BuildEngine {
+	if override == nil {
+		return base.DeepCopy()
+	}
+	answer := override.DeepCopy()
+	if base == nil {
+		return answer
+	}
+	if answer.Kind == "" {
+		answer.Kind = base.Kind
+	}
+	if answer.GetKind() == base.GetKind() {
+		if answer.Image == "" {
+			answer.Image = base.Image
+		}
+		if answer.Registry == "" {
+			answer.Registry = base.Registry
+		}
+	}
+	if answer.Resources == "" {
+		answer.Resources = base.Resources
+	}
+	if answer.Cache == nil {
+		answer.Cache = base.Cache.DeepCopy()
+	}
+	return answer
+}
+
+// modifyBuildStep replaces the step building the image with the engine
+func (e *BuildEngine) modifyBuildStep(s *Step, params StepPlaceholderReplacementArgs) {
+	sourceDir := params.WorkspaceDir
+	image := naming.ToValidName(params.GitName)
+	destination := params.DockerRegistry + "/" + params.DockerRegistryOrg + "/" + image + ":${inputs.params.version}"
+	cacheRepo := params.DockerRegistry + "/" + params.ProjectID + "/cache"
+	cache := e.cache()
+	if cache.Repo != "" {
+		cacheRepo = cache.Repo
+	}
+	insecure := ipAddressRegistryRegex.MatchString(params.DockerRegistry)
+
+	switch e.GetKind() {
+	case BuildEngineBuildKit:
+		output := "type=image,name=" + destination + ",push=true"
+		if insecure {
+			output += ",registry.insecure=true"
+		}
+		args := []string{"build", "--frontend=dockerfile.v0",
+			"--local", "context=" + sourceDir,
+			"--local", "dockerfile=" + sourceDir,
+			"--output", output,
+		}
+		args = append(args, cache.buildKitArgs(cacheRepo, image)...)
+		s.Command = "buildctl-daemonless.sh"
+		s.Arguments = args
+		s.Image = e.imageOrDefault(BuildKitDockerImage)
+		s.Env = append(s.Env, corev1.EnvVar{Name: "BUILDKITD_FLAGS", Value: "--oci-worker-no-process-sandbox"})
+		s.Resources = e.resources()
+
+	case BuildEngineGCB:
+		args := []string{"builds", "submit", sourceDir, "--tag=" + destination, "--project=" + params.ProjectID}
+		if preset, ok := buildResourcesPresets[e.Resources]; ok && preset.gcbMachineType != "" {
+			args = append(args, "--machine-type="+preset.gcbMachineType)
+		}
+		s.Command = "gcloud"
+		s.Arguments = args
+		s.Image = e.imageOrDefault(GCBDockerImage)
+
+	case BuildEngineACR:
+		registry := e.Registry
+		if registry == "" {
+			registry = strings.Split(params.DockerRegistry, ".")[0]
+		}
+		args := []string{"acr", "build", "--registry", registry,
+			"--image", params.DockerRegistryOrg + "/" + image + ":${inputs.params.version}",
+			"--file", filepath.Join(sourceDir, "Dockerfile"),
+			sourceDir,
+		}
+		s.Command = "az"
+		s.Arguments = args
+		s.Image = e.imageOrDefault(ACRDockerImage)
+
+	case BuildEngineCNB:
+		args := []string{"-app=" + sourceDir}
+		if !cache.Disabled {
+			args = append(args, "-cache-image="+cacheRepo+"/"+image+":cnb-cache")
+		}
+		if insecure {
+			args = append(args, "-insecure-registry="+params.DockerRegistry)
+		}
+		args = append(args, destination)
+		s.Command = "/cnb/lifecycle/creator"
+		s.Arguments = args
+		s.Image = e.imageOrDefault(CNBBuilderImage)
+		s.Resources = e.resources()
+
+	default:
+		args := []string{"--cache=" + fmt.Sprintf("%t", !cache.Disabled), "--cache-dir=/workspace",
+			"--context=" + sourceDir,
+			"--dockerfile=" + filepath.Join(sourceDir, "Dockerfile"),
+			"--destination=" + destination,
+			"--cache-repo=" + cacheRepo,
+		}
+		if cache.TTL != "" {
+			args = append(args, "--cache-ttl="+cache.TTL)
+		}
+		if params.DockerRegistry != "gcr.io" {
+			args = append(args, "--skip-tls-verify-registry="+params.DockerRegistry)
+		}
+		if insecure {
+			args = append(args, "--insecure")
+		}
+		s.Command = "/kaniko/executor"
+		s.Arguments = args
+		s.Image = e.imageOrDefault(params.KanikoImage)
+		s.Resources = e.resources()
+	}
+}
+
+func (e *BuildEngine) cache() *BuildCache {
+	if e.Cache == nil {
+		return &BuildCache{}
+	}
+	return e.Cache
+}
+
+func (e *BuildEngine) imageOrDefault(defaultImage string) string {
+	if e.Image != "" {
+		return e.Image
+	}
+	return defaultImage
+}
+
+// resources returns the requests and limits of the preset of the engine or nil if there is none
+func (e *BuildEngine) resources() *corev1.ResourceRequirements {
+	preset, ok := buildResourcesPresets[e.Resources]
+	if !ok {
+		return nil
+	}
+	return &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(preset.requestCPU),
+			corev1.ResourceMemory: resource.MustParse(preset.requestMemory),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(preset.limitCPU),
+			corev1.ResourceMemory: resource.MustParse(preset.limitMemory),
+		},
+	}
+}
@@ -0,0 +1,188 @@
+// +build unit
+
+package syntax_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/tekton/syntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func buildEngineStep(t *testing.T, engine *syntax.BuildEngine) syntax.Step {
+	parsed := &syntax.ParsedPipeline{
+		Stages: []syntax.Stage{{
+			Name: "build",
+			Steps: []syntax.Step{{
+				Name:    "container-build",
+				Command: "skaffold build -f skaffold.yaml",
+			}},
+		}},
+	}
+	parsed.ReplacePlaceholdersInStepAndStageDirs(syntax.StepPlaceholderReplacementArgs{
+		WorkspaceDir:      "/workspace/source",
+		GitName:           "myapp",
+		GitOrg:            "myorg",
+		GitHost:           "github.com",
+		DockerRegistry:    "myregistry.azurecr.io",
+		DockerRegistryOrg: "myorg",
+		ProjectID:         "myproject",
+		KanikoImage:       syntax.KanikoDockerImage,
+		UseKaniko:         true,
+		BuildEngine:       engine,
+	})
+	require.Len(t, parsed.Stages[0].Steps, 1)
+	return parsed.Stages[0].Steps[0]
+}
+
+func TestBuildEngineKaniko(t *testing.T) {
+	t.Parallel()
+	step := buildEngineStep(t, nil)
+
+	assert.Equal(t, "/kaniko/executor", step.Command)
+	assert.Equal(t, syntax.KanikoDockerImage, step.Image)
+	assert.Equal(t, []string{"--cache=true", "--cache-dir=/workspace",
+		"--context=/workspace/source",
+		"--dockerfile=/workspace/source/Dockerfile",
+		"--destination=myregistry.azurecr.io/myorg/myapp:${inputs.params.version}",
+		"--cache-repo=myregistry.azurecr.io/myproject/cache",
+		"--skip-tls-verify-registry=myregistry.azurecr.io",
+	}, step.Arguments)
+	assert.Nil(t, step.Resources)
+
+	step = buildEngineStep(t, &syntax.BuildEngine{
+		Resources: syntax.BuildResourcesLarge,
+		Cache:     &syntax.BuildCache{Repo: "myregistry.azurecr.io/cache", TTL: "24h"},
+	})
+	assert.Contains(t, step.Arguments, "--cache-repo=myregistry.azurecr.io/cache")
+	assert.Contains(t, step.Arguments, "--cache-ttl=24h")
+	require.NotNil(t, step.Resources)
+	assert.Equal(t, resource.MustParse("8Gi"), step.Resources.Limits[corev1.ResourceMemory])
+}
+
+func TestBuildEngineBuildKit(t *testing.T) {
+	t.Parallel()
+	step := buildEngineStep(t, &syntax.BuildEngine{Kind: syntax.BuildEngineBuildKit, Resources: syntax.BuildResourcesSmall})
+
+	assert.Equal(t, "buildctl-daemonless.sh", step.Command)
+	assert.Equal(t, syntax.BuildKitDockerImage, step.Image)
+	assert.Equal(t, []string{"build", "--frontend=dockerfile.v0",
+		"--local", "context=/workspace/source",
+		"--local", "dockerfile=/workspace/source",
+		"--output", "type=image,name=myregistry.azurecr.io/myorg/myapp:${inputs.params.version},push=true",
+		"--export-cache", "type=registry,ref=myregistry.azurecr.io/myproject/cache/myapp:buildcache,mode=max",
+		"--import-cache", "type=registry,ref=myregistry.azurecr.io/myproject/cache/myapp:buildcache",
+	}, step.Arguments)
+	assert.Equal(t, []corev1.EnvVar{{Name: "BUILDKITD_FLAGS", Value: "--oci-worker-no-process-sandbox"}}, step.Env)
+	require.NotNil(t, step.Resources)
+	assert.Equal(t, resource.MustParse("500m"), step.Resources.Requests[corev1.ResourceCPU])
+
+	step = buildEngineStep(t, &syntax.BuildEngine{Kind: syntax.BuildEngineBuildKit, Cache: &syntax.BuildCache{Disabled: true}})
+	assert.NotContains(t, step.Arguments, "--export-cache")
+}
+
+func TestBuildEngineCloudBuilders(t *testing.T) {
+	t.Parallel()
+	step := buildEngineStep(t, &syntax.BuildEngine{Kind: syntax.BuildEngineGCB, Resources: syntax.BuildResourcesMedium})
+	assert.Equal(t, "gcloud", step.Command)
+	assert.Equal(t, syntax.GCBDockerImage, step.Image)
+	assert.Equal(t, []string{"builds", "submit", "/workspace/source",
+		"--tag=myregistry.azurecr.io/myorg/myapp:${inputs.params.version}",
+		"--project=myproject",
+		"--machine-type=e2-highcpu-8",
+	}, step.Arguments)
+	assert.Nil(t, step.Resources, "the resources of cloud builds should not be requested in the pipeline pod")
+
+	step = buildEngineStep(t, &syntax.BuildEngine{Kind: syntax.BuildEngineACR, Image: "mcr.microsoft.com/azure-cli:latest"})
+	assert.Equal(t, "az", step.Command)
+	assert.Equal(t, "mcr.microsoft.com/azure-cli:latest", step.Image)
+	assert.Equal(t, []string{"acr", "build", "--registry", "myregistry",
+		"--image", "myorg/myapp:${inputs.params.version}",
+		"--file", "/workspace/source/Dockerfile",
+		"/workspace/source",
+	}, step.Arguments)
+}
+
+func TestBuildEngineCNB(t *testing.T) {
+	t.Parallel()
+	step := buildEngineStep(t, &syntax.BuildEngine{Kind: syntax.BuildEngineCNB, Image: "paketobuildpacks/builder:tiny"})
+	assert.Equal(t, "/cnb/lifecycle/creator", step.Command)
+	assert.Equal(t, "paketobuildpacks/builder:tiny", step.Image)
+	assert.Equal(t, []string{"-app=/workspace/source",
+		"-cache-image=myregistry.azurecr.io/myproject/cache/myapp:cnb-cache",
+		"myregistry.azurecr.io/myorg/myapp:${inputs.params.version}",
+	}, step.Arguments)
+
+	step = buildEngineStep(t, &syntax.BuildEngine{Kind: syntax.BuildEngineCNB, Cache: &syntax.BuildCache{Disabled: true}})
+	assert.Equal(t, syntax.CNBBuilderImage, step.Image)
+	assert.Equal(t, []string{"-app=/workspace/source", "myregistry.azurecr.io/myorg/myapp:${inputs.params.version}"}, step.Arguments)
+	assert.Error(t, (&syntax.BuildEngine{Kind: syntax.BuildEngineCNB, Cache: &syntax.BuildCache{Type: syntax.BuildCacheGCS, URL: "gs://mybucket"}}).Validate())
+}
+
+func TestMergeBuildEngines(t *testing.T) {
+	t.Parallel()
+	team := &syntax.BuildEngine{
+		Kind:      syntax.BuildEngineACR,
+		Registry:  "teamregistry",
+		Resources: syntax.BuildResourcesMedium,
+		Cache:     &syntax.BuildCache{TTL: "6h"},
+	}
+
+	merged := syntax.MergeBuildEngines(team, &syntax.BuildEngine{Resources: syntax.BuildResourcesLarge})
+	assert.Equal(t, &syntax.BuildEngine{
+		Kind:      syntax.BuildEngineACR,
+		Registry:  "teamregistry",
+		Resources: syntax.BuildResourcesLarge,
+		Cache:     &syntax.BuildCache{TTL: "6h"},
+	}, merged)
+
+	merged = syntax.MergeBuildEngines(team, &syntax.BuildEngine{Kind: syntax.BuildEngineBuildKit})
+	assert.Equal(t, syntax.BuildEngineBuildKit, merged.GetKind())
+	assert.Empty(t, merged.Registry, "the registry of another kind of engine should not be inherited")
+	assert.Equal(t, syntax.BuildResourcesMedium, merged.Resources)
+
+	assert.Nil(t, syntax.MergeBuildEngines(nil, nil))
+	assert.Equal(t, syntax.BuildEngineKaniko, syntax.MergeBuildEngines(nil, nil).GetKind())
+}
+
+func TestValidateBuildEngine(t *testing.T) {
+	t.Parallel()
+	var engine *syntax.BuildEngine
+	assert.NoError(t, engine.Validate())
+	assert.NoError(t, (&syntax.BuildEngine{Kind: syntax.BuildEngineGCB, Resources: syntax.BuildResourcesLarge}).Validate())
+	assert.Error(t, (&syntax.BuildEngine{Kind: "docker"}).Validate())
+	assert.Error(t, (&syntax.BuildEngine{Resources: "huge"}).Validate())
+}
+
+func TestBuildEngineBucketCache(t *testing.T) {
+	t.Parallel()
+	step := buildEngineStep(t, &syntax.BuildEngine{
+		Kind:  syntax.BuildEngineBuildKit,
+		Cache: &syntax.BuildCache{Type: syntax.BuildCacheS3, URL: "s3://mybucket/layers/", Region: "eu-west-1"},
+	})
+	ref := "type=s3,region=eu-west-1,bucket=mybucket,prefix=layers/,name=myapp"
+	assert.Equal(t, []string{"--export-cache", ref + ",mode=max", "--import-cache", ref}, step.Arguments[len(step.Arguments)-4:])
+
+	step = buildEngineStep(t, &syntax.BuildEngine{
+		Kind:  syntax.BuildEngineBuildKit,
+		Cache: &syntax.BuildCache{Type: syntax.BuildCacheGCS, URL: "gs://mybucket"},
+	})
+	ref = "type=s3,endpoint_url=https://storage.googleapis.com,region=auto,bucket=mybucket,name=myapp"
+	assert.Equal(t, []string{"--export-cache", ref + ",mode=max", "--import-cache", ref}, step.Arguments[len(step.Arguments)-4:])
+}
+
+func TestValidateBuildCache(t *testing.T) {
+	t.Parallel()
+	assert.NoError(t, (&syntax.BuildCache{Repo: "gcr.io/myproject/cache"}).Validate())
+	assert.NoError(t, (&syntax.BuildCache{Type: syntax.BuildCacheGCS, URL: "gs://mybucket/cache"}).Validate())
+	assert.Error(t, (&syntax.BuildCache{Type: syntax.BuildCacheS3, URL: "gs://mybucket"}).Validate())
+	assert.Error(t, (&syntax.BuildCache{Type: syntax.BuildCacheS3}).Validate())
+	assert.Error(t, (&syntax.BuildCache{Type: "azblob", URL: "azblob://mybucket"}).Validate())
+
+	bucket := &syntax.BuildCache{Type: syntax.BuildCacheS3, URL: "s3://mybucket"}
+	assert.Error(t, (&syntax.BuildEngine{Cache: bucket}).Validate(), "kaniko should not support bucket caches")
+	assert.NoError(t, (&syntax.BuildEngine{Kind: syntax.BuildEngineBuildKit, Cache: bucket}).Validate())
+}
@@ -0,0 +1,187 @@
+package syntax
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/versionstream"
+	tektonv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+)
+
+// lfsSkipSmudgeEnv the environment variable which makes the git steps skip downloading Git LFS objects
+const lfsSkipSmudgeEnv = "JX_GIT_LFS_SKIP_SMUDGE"
+
+// Checkout configures what is checked out in addition to the source repository before the steps of each stage run
+type Checkout struct {
+	// Submodules checks out the git submodules of the source repository recursively
+	Submodules bool `json:"submodules,omitempty"`
+	// Repositories the sibling repositories to check out next to the source repository
+	Repositories []CheckoutRepository `json:"repositories,omitempty"`
+	// SkipLFS skips downloading the Git LFS objects of the checked out repositories to speed up builds which do
+	// not need them
+	SkipLFS bool `json:"skipLfs,omitempty"`
+}
+
+// CheckoutRepository a sibling repository which is checked out next to the source repository
+type CheckoutRepository struct {
+	// URL the git URL of the repository
+	URL string `json:"url"`
+	// Revision the branch, tag or commit to check out. Defaults to the default branch of the repository
+	Revision string `json:"revision,omitempty"`
+	// Dir the directory relative to the workspace to check out the repository into. Defaults to the repository name
+	Dir string `json:"dir,omitempty"`
+}
+
+// Enabled returns true if anything needs to be checked out in addition to the source repository
+func (c *Checkout) Enabled() bool {
+	return c != nil && (c.Submodules || len(c.Repositories) > 0)
+}
+
+// StepArgs returns the arguments of the 'jx step git checkout' command for the checkout configuration
+func (c *Checkout) StepArgs() []string {
+	args := []string{"step", "git", "checkout", "--verbose"}
+	if c.Submodules {
+		args = append(args, "--submodules")
+	}
+	if c.SkipLFS {
+		args = append(args, "--lfs-skip-smudge")
+	}
+	for _, r := range c.Repositories {
+		args = append(args, "--repository", r.String())
+	}
+	return args
+}
+
+// gitStepEnv returns the environment of the step which merges the source repository
+func (c *Checkout) gitStepEnv(envs []corev1.EnvVar) []corev1.EnvVar {
+	if c == nil || !c.SkipLFS {
+		return envs
+	}
+	return append(append([]corev1.EnvVar{}, envs...), corev1.EnvVar{Name: lfsSkipSmudgeEnv, Value: "true"})
+}
+
+// GetDir returns the directory relative to the workspace the repository is checked out into
+func (r *CheckoutRepository) GetDir() string {
+	if r.Dir != "" {
+		return r.Dir
+	}
+	name := strings.TrimSuffix(strings.TrimRight(r.URL, "/"), ".git")
+	i := strings.LastIndexAny(name, "/:")
+	return name[i+1:]
+}
+
+// String formats the repository as [dir=]url[#revision] which can be parsed via ParseCheckoutRepository
+func (r *CheckoutRepository) String() string {
+	text := r.URL
+	if r.Dir != "" {
+		text = r.Dir + "=" + text
+	}
+	if r.Revision != "" {
+		text += "#" + r.Revision
+	}
+	return text
+}
+
+// ParseCheckoutRepository parses a repository in the [dir=]url[#revision] format
+func ParseCheckoutRepository(text string) (*CheckoutRepository, error) {
+	r := &CheckoutRepository{}
+	text = strings.TrimSpace(text)
+	i := strings.Index(text, "=")
+	// an '=' after the URL scheme or host is part of the URL rather than the directory separator
+	if i > 0 && !strings.ContainsAny(text[:i], ":@") {
+		r.Dir = text[:i]
+		text = text[i+1:]
+	}
+	i = strings.LastIndex(text, "#")
+	if i >= 0 {
+		r.Revision = text[i+1:]
+		text = text[:i]
+	}
+	r.URL = text
+	if err := r.validate(); err != nil {
+		return nil, fmt.Errorf("invalid repository %s: %s", text, err.Message)
+	}
+	return r, nil
+}
+
+func (r *CheckoutRepository) validate() *apis.FieldError {
+	if r.URL == "" {
+		return apis.ErrMissingField("url")
+	}
+	dir := r.GetDir()
+	clean := path.Clean(dir)
+	if dir == "" || path.IsAbs(dir) || clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+		return &apis.FieldError{
+			Message: "The repository directory must be a path inside the workspace",
+			Paths:   []string{"dir"},
+		}
+	}
+	return nil
+}
+
+func validateCheckout(c *Checkout) *apis.FieldError {
+	if c == nil {
+		return nil
+	}
+	dirs := map[string]bool{}
+	for i := range c.Repositories {
+		r := &c.Repositories[i]
+		if err := r.validate(); err != nil {
+			return err.ViaFieldIndex("repositories", i)
+		}
+		dir := path.Clean(r.GetDir())
+		if dirs[dir] {
+			err := &apis.FieldError{
+				Message: fmt.Sprintf("More than one repository is checked out into %s", dir),
+				Paths:   []string{"dir"},
+			}
+			return err.ViaFieldIndex("repositories", i)
+		}
+		dirs[dir] = true
+	}
+	return nil
+}
+
+// checkoutStep returns the step which checks out the submodules and sibling repositories after the source has been
+// checked out. It is added to every task as the sibling repositories are not part of the workspace passed between tasks
+func checkoutStep(checkout *Checkout, envs []corev1.EnvVar, parentContainer *corev1.Container, defaultImage string, versionsDir string) ([]tektonv1alpha1.Step, error) {
+	if !checkout.Enabled() {
+		return nil, nil
+	}
+	var err error
+	image := defaultImage
+	if image == "" {
+		image = os.Getenv("BUILDER_JX_IMAGE")
+		if image == "" {
+			image, err = versionstream.ResolveDockerImage(versionsDir, GitMergeImage)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	checkoutContainer := &corev1.Container{
+		Name:       "git-checkout",
+		Image:      image,
+		Command:    []string{"jx"},
+		Args:       checkout.StepArgs(),
+		WorkingDir: "/workspace/source",
+		Env:        envs,
+	}
+
+	if parentContainer != nil {
+		merged, err := MergeContainers(parentContainer, checkoutContainer)
+		if err != nil {
+			return nil, err
+		}
+		checkoutContainer = merged
+	}
+
+	return []tektonv1alpha1.Step{{
+		Container: *checkoutContainer,
+	}}, nil
+}
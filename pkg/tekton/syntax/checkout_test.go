@@ -0,0 +1,175 @@
+// +build unit
+
+package syntax_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/tekton/syntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParseCheckoutRepository(t *testing.T) {
+	testCases := []struct {
+		text     string
+		expected syntax.CheckoutRepository
+		dir      string
+	}{
+		{
+			text:     "https://github.com/myorg/mylib.git",
+			expected: syntax.CheckoutRepository{URL: "https://github.com/myorg/mylib.git"},
+			dir:      "mylib",
+		},
+		{
+			text:     "libs/mylib=https://github.com/myorg/mylib.git#v1.2.3",
+			expected: syntax.CheckoutRepository{URL: "https://github.com/myorg/mylib.git", Revision: "v1.2.3", Dir: "libs/mylib"},
+			dir:      "libs/mylib",
+		},
+		{
+			text:     "git@github.com:myorg/other.git#master",
+			expected: syntax.CheckoutRepository{URL: "git@github.com:myorg/other.git", Revision: "master"},
+			dir:      "other",
+		},
+		{
+			text:     "https://example.com/scm/repo?a=b",
+			expected: syntax.CheckoutRepository{URL: "https://example.com/scm/repo?a=b"},
+			dir:      "repo?a=b",
+		},
+	}
+	for _, tc := range testCases {
+		r, err := syntax.ParseCheckoutRepository(tc.text)
+		require.NoError(t, err, "parsing %s", tc.text)
+		assert.Equal(t, tc.expected, *r, "parsing %s", tc.text)
+		assert.Equal(t, tc.dir, r.GetDir(), "directory of %s", tc.text)
+		assert.Equal(t, tc.text, r.String(), "formatting %s", tc.text)
+	}
+
+	for _, text := range []string{"", "../escape=https://github.com/myorg/mylib.git", "/abs=https://github.com/myorg/mylib.git"} {
+		_, err := syntax.ParseCheckoutRepository(text)
+		assert.Error(t, err, "parsing %s", text)
+	}
+}
+
+func TestCheckoutValidation(t *testing.T) {
+	parsed := &syntax.ParsedPipeline{
+		Agent: &syntax.Agent{Image: "maven"},
+		Options: &syntax.RootOptions{
+			Checkout: &syntax.Checkout{
+				Repositories: []syntax.CheckoutRepository{
+					{URL: "https://github.com/myorg/mylib.git"},
+					{URL: "https://github.com/otherorg/mylib.git"},
+				},
+			},
+		},
+		Stages: []syntax.Stage{{
+			Name:  "build",
+			Steps: []syntax.Step{{Command: "mvn", Arguments: []string{"install"}}},
+		}},
+	}
+	err := parsed.Validate(context.Background())
+	require.NotNil(t, err, "two repositories are checked out into the same directory")
+	assert.Contains(t, err.Error(), "options.checkout.repositories[1].dir")
+
+	parsed.Options.Checkout.Repositories[1].Dir = "otherlib"
+	assert.Nil(t, parsed.Validate(context.Background()))
+}
+
+func TestGenerateCRDsWithCheckout(t *testing.T) {
+	parsed := &syntax.ParsedPipeline{
+		Agent: &syntax.Agent{Image: "maven"},
+		Options: &syntax.RootOptions{
+			Checkout: &syntax.Checkout{
+				Submodules: true,
+				Repositories: []syntax.CheckoutRepository{
+					{URL: "https://github.com/myorg/mylib.git", Revision: "v1.2.3"},
+				},
+			},
+		},
+		Stages: []syntax.Stage{
+			{
+				Name:  "build",
+				Steps: []syntax.Step{{Command: "mvn", Arguments: []string{"install"}}},
+			},
+			{
+				Name:  "test",
+				Steps: []syntax.Step{{Command: "mvn", Arguments: []string{"test"}}},
+			},
+		},
+	}
+
+	_, tasks, _, err := parsed.GenerateCRDs(syntax.CRDsFromPipelineParams{
+		PipelineIdentifier: "somepipeline",
+		BuildIdentifier:    "1",
+		Namespace:          "jx",
+		VersionsDir:        filepath.Join("test_data", "stable_versions"),
+		SourceDir:          "source",
+		DefaultImage:       "builder-jx",
+	})
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+
+	expectedArgs := []string{"step", "git", "checkout", "--verbose", "--submodules", "--repository", "https://github.com/myorg/mylib.git#v1.2.3"}
+	for i, task := range tasks {
+		names := []string{}
+		for _, step := range task.Spec.Steps {
+			names = append(names, step.Name)
+			if step.Name == "git-checkout" {
+				assert.Equal(t, expectedArgs, step.Args, "task %s", task.Name)
+				assert.Equal(t, "/workspace/source", step.WorkingDir, "task %s", task.Name)
+			}
+		}
+		if i == 0 {
+			assert.Equal(t, []string{"setup-builder-home", "git-merge", "git-checkout", "step2"}, names, "task %s", task.Name)
+		} else {
+			assert.Equal(t, []string{"setup-builder-home", "git-checkout", "step2"}, names, "task %s", task.Name)
+		}
+	}
+}
+
+func TestGenerateCRDsWithCheckoutSkipLFS(t *testing.T) {
+	parsed := &syntax.ParsedPipeline{
+		Agent: &syntax.Agent{Image: "maven"},
+		Options: &syntax.RootOptions{
+			Checkout: &syntax.Checkout{
+				SkipLFS: true,
+				Repositories: []syntax.CheckoutRepository{
+					{URL: "https://github.com/myorg/assets.git"},
+				},
+			},
+		},
+		Stages: []syntax.Stage{{
+			Name:  "build",
+			Steps: []syntax.Step{{Command: "mvn", Arguments: []string{"install"}}},
+		}},
+	}
+
+	_, tasks, _, err := parsed.GenerateCRDs(syntax.CRDsFromPipelineParams{
+		PipelineIdentifier: "somepipeline",
+		BuildIdentifier:    "1",
+		Namespace:          "jx",
+		VersionsDir:        filepath.Join("test_data", "stable_versions"),
+		SourceDir:          "source",
+		DefaultImage:       "builder-jx",
+	})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+
+	found := map[string]bool{}
+	for _, step := range tasks[0].Spec.Steps {
+		switch step.Name {
+		case "git-merge":
+			found[step.Name] = true
+			assert.Contains(t, step.Env, corev1.EnvVar{Name: "JX_GIT_LFS_SKIP_SMUDGE", Value: "true"})
+		case "git-checkout":
+			found[step.Name] = true
+			assert.Equal(t, []string{"step", "git", "checkout", "--verbose", "--lfs-skip-smudge", "--repository", "https://github.com/myorg/assets.git"}, step.Args)
+		default:
+			assert.NotContains(t, step.Env, corev1.EnvVar{Name: "JX_GIT_LFS_SKIP_SMUDGE", Value: "true"}, "step %s", step.Name)
+		}
+	}
+	assert.Equal(t, map[string]bool{"git-merge": true, "git-checkout": true}, found)
+}
@@ -13,6 +13,18 @@ const (
 	// KanikoDockerImage - the default image used for Kaniko builds
 	KanikoDockerImage = "gcr.io/kaniko-project/executor:v0.22.0"
 
+	// BuildKitDockerImage - the default image used for rootless BuildKit builds
+	BuildKitDockerImage = "moby/buildkit:v0.8.1-rootless"
+
+	// GCBDockerImage - the default image used to submit builds to Google Cloud Build
+	GCBDockerImage = "gcr.io/google.com/cloudsdktool/cloud-sdk:319.0.0-alpine"
+
+	// CNBBuilderImage - the default builder image used for Cloud Native Buildpacks builds
+	CNBBuilderImage = "paketobuildpacks/builder:base"
+
+	// ACRDockerImage - the default image used to submit builds to Azure Container Registry Tasks
+	ACRDockerImage = "mcr.microsoft.com/azure-cli:2.15.1"
+
 	// DefaultContainerImage - the default image used for pipelines if none is specified.
 	DefaultContainerImage = "gcr.io/jenkinsxio/builder-maven"
 )
@@ -15,7 +15,6 @@ import (
 
 	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx-logging/pkg/log"
-	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/jenkins-x/jx/v2/pkg/versionstream"
 	"github.com/pkg/errors"
@@ -133,6 +132,7 @@ type RootOptions struct {
 	DistributeParallelAcrossNodes bool                `json:"distributeParallelAcrossNodes,omitempty"`
 	Tolerations                   []corev1.Toleration `json:"tolerations,omitempty"`
 	PodLabels                     map[string]string   `json:"podLabels,omitempty"`
+	Checkout                      *Checkout           `json:"checkout,omitempty"`
 }
 
 // Stash defines files to be saved for use in a later stage, marked with a name
@@ -189,6 +189,9 @@ type Step struct {
 	// env allows defining per-step environment variables
 	Env []corev1.EnvVar `json:"env,omitempty"`
 
+	// Resources the compute resources of the step container
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
 	// Legacy fields from jenkinsfile.PipelineStep before it was eliminated.
 	Comment   string  `json:"comment,omitempty"`
 	Groovy    string  `json:"groovy,omitempty"`
@@ -822,6 +825,10 @@ func validateRootOptions(o *RootOptions, volumes []*corev1.Volume, kubeClient ku
 			}
 		}
 
+		if err := validateCheckout(o.Checkout); err != nil {
+			return err.ViaField("checkout")
+		}
+
 		return validateContainerOptions(o.ContainerOptions, volumes).ViaField("containerOptions")
 	}
 
@@ -1145,6 +1152,8 @@ type StepPlaceholderReplacementArgs struct {
 	ProjectID         string
 	KanikoImage       string
 	UseKaniko         bool
+	// BuildEngine the engine replacing the 'skaffold build' steps, defaults to Kaniko if UseKaniko is enabled
+	BuildEngine *BuildEngine
 }
 
 func (p *StepPlaceholderReplacementArgs) workingDirAsPointer() *string {
@@ -1245,29 +1254,11 @@ func (s *Step) modifyStep(params StepPlaceholderReplacementArgs) {
 			(len(s.Arguments) > 0 && strings.HasPrefix(strings.Join(s.Arguments[1:], " "), "skaffold build")) ||
 			commandIsSkaffoldRegex.MatchString(s.GetCommand()) {
 
-			sourceDir := params.WorkspaceDir
-			dockerfile := filepath.Join(sourceDir, "Dockerfile")
-			localRepo := params.DockerRegistry
-			destination := params.DockerRegistry + "/" + params.DockerRegistryOrg + "/" + naming.ToValidName(params.GitName)
-
-			args := []string{"--cache=true", "--cache-dir=/workspace",
-				"--context=" + sourceDir,
-				"--dockerfile=" + dockerfile,
-				"--destination=" + destination + ":${inputs.params.version}",
-				"--cache-repo=" + localRepo + "/" + params.ProjectID + "/cache",
-			}
-			if localRepo != "gcr.io" {
-				args = append(args, "--skip-tls-verify-registry="+localRepo)
-			}
-
-			if ipAddressRegistryRegex.MatchString(localRepo) {
-				args = append(args, "--insecure")
+			engine := params.BuildEngine
+			if engine == nil {
+				engine = &BuildEngine{}
 			}
-
-			s.Command = "/kaniko/executor"
-			s.Arguments = args
-
-			s.Image = params.KanikoImage
+			engine.modifyBuildStep(s, params)
 		}
 	}
 }
@@ -1513,7 +1504,7 @@ func stageToTask(params stageToTaskParams) (*transformedStage, error) {
 	}
 
 	stepCounter := 0
-	defaultTaskSpec, err := getDefaultTaskSpec(env, stageContainer, params.parentParams.DefaultImage, params.parentParams.VersionsDir)
+	defaultTaskSpec, err := getDefaultTaskSpec(params.parentParams.checkout.gitStepEnv(env), stageContainer, params.parentParams.DefaultImage, params.parentParams.VersionsDir)
 	if err != nil {
 		return nil, err
 	}
@@ -1540,6 +1531,11 @@ func stageToTask(params stageToTaskParams) (*transformedStage, error) {
 			return nil, err
 		}
 		t.Spec.Steps = append(prependedSteps, t.Spec.Steps...)
+		checkoutSteps, err := checkoutStep(params.parentParams.checkout, env, stageContainer, params.parentParams.DefaultImage, params.parentParams.VersionsDir)
+		if err != nil {
+			return nil, err
+		}
+		t.Spec.Steps = append(t.Spec.Steps, checkoutSteps...)
 		t.SetDefaults(context.Background())
 
 		ws := &tektonv1alpha1.TaskResource{
@@ -1855,6 +1851,9 @@ func generateSteps(params generateStepsParams) ([]tektonv1alpha1.Step, map[strin
 		c.Stdin = false
 		c.TTY = false
 		c.Env = scopedEnv(params.step.Env, scopedEnv(params.env, c.Env))
+		if params.step.Resources != nil {
+			c.Resources = *params.step.Resources.DeepCopy()
+		}
 
 		steps = append(steps, tektonv1alpha1.Step{
 			Container: *c,
@@ -1925,6 +1924,8 @@ type CRDsFromPipelineParams struct {
 	Labels             map[string]string
 	DefaultImage       string
 	InterpretMode      bool
+
+	checkout *Checkout
 }
 
 // GenerateCRDs translates the Pipeline structure into the corresponding Pipeline and Task CRDs
@@ -1947,6 +1948,7 @@ func (j *ParsedPipeline) GenerateCRDs(params CRDsFromPipelineParams) (*tektonv1a
 		parentContainer = o.ContainerOptions
 		parentSidecars = o.Sidecars
 		parentVolumes = o.Volumes
+		params.checkout = o.Checkout
 	}
 
 	p := &tektonv1alpha1.Pipeline{
@@ -25,6 +25,43 @@ func (in *Agent) DeepCopy() *Agent {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildCache) DeepCopyInto(out *BuildCache) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildCache.
+func (in *BuildCache) DeepCopy() *BuildCache {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildCache)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildEngine) DeepCopyInto(out *BuildEngine) {
+	*out = *in
+	if in.Cache != nil {
+		in, out := &in.Cache, &out.Cache
+		*out = new(BuildCache)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildEngine.
+func (in *BuildEngine) DeepCopy() *BuildEngine {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildEngine)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CRDsFromPipelineParams) DeepCopyInto(out *CRDsFromPipelineParams) {
 	*out = *in
@@ -57,6 +94,11 @@ func (in *CRDsFromPipelineParams) DeepCopyInto(out *CRDsFromPipelineParams) {
 			(*out)[key] = val
 		}
 	}
+	if in.checkout != nil {
+		in, out := &in.checkout, &out.checkout
+		*out = new(Checkout)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -70,6 +112,43 @@ func (in *CRDsFromPipelineParams) DeepCopy() *CRDsFromPipelineParams {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Checkout) DeepCopyInto(out *Checkout) {
+	*out = *in
+	if in.Repositories != nil {
+		in, out := &in.Repositories, &out.Repositories
+		*out = make([]CheckoutRepository, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Checkout.
+func (in *Checkout) DeepCopy() *Checkout {
+	if in == nil {
+		return nil
+	}
+	out := new(Checkout)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CheckoutRepository) DeepCopyInto(out *CheckoutRepository) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CheckoutRepository.
+func (in *CheckoutRepository) DeepCopy() *CheckoutRepository {
+	if in == nil {
+		return nil
+	}
+	out := new(CheckoutRepository)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Loop) DeepCopyInto(out *Loop) {
 	*out = *in
@@ -299,6 +378,11 @@ func (in *RootOptions) DeepCopyInto(out *RootOptions) {
 			(*out)[key] = val
 		}
 	}
+	if in.Checkout != nil {
+		in, out := &in.Checkout, &out.Checkout
+		*out = new(Checkout)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -469,6 +553,11 @@ func (in *Step) DeepCopyInto(out *Step) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Steps != nil {
 		in, out := &in.Steps, &out.Steps
 		*out = make([]*Step, len(*in))
@@ -496,6 +585,11 @@ func (in *Step) DeepCopy() *Step {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StepPlaceholderReplacementArgs) DeepCopyInto(out *StepPlaceholderReplacementArgs) {
 	*out = *in
+	if in.BuildEngine != nil {
+		in, out := &in.BuildEngine, &out.BuildEngine
+		*out = new(BuildEngine)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
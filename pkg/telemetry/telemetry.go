@@ -0,0 +1,341 @@
+package telemetry
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// EnvTelemetry environment variable which disables telemetry when false regardless of the settings
+	EnvTelemetry = "JX_TELEMETRY"
+	// EnvTelemetryEndpoint environment variable overriding the endpoint the usage metrics are sent to
+	EnvTelemetryEndpoint = "JX_TELEMETRY_ENDPOINT"
+	// SettingsFileName the name of the file in ~/.jx storing the telemetry settings
+	SettingsFileName = "telemetry.yaml"
+	// SpoolFileName the name of the file in ~/.jx storing the events which could not be sent yet
+	SpoolFileName = "telemetry-spool.jsonl"
+	// MaxSpooledEvents the maximum number of events kept while offline, the oldest are dropped first
+	MaxSpooledEvents = 1000
+
+	// ErrorClassNone the error class of a command which succeeded
+	ErrorClassNone = ""
+	// ErrorClassTimeout the error class of a command which timed out
+	ErrorClassTimeout = "timeout"
+	// ErrorClassNetwork the error class of a command which failed to connect
+	ErrorClassNetwork = "network"
+	// ErrorClassNotFound the error class of a command which failed to find a Kubernetes resource
+	ErrorClassNotFound = "not-found"
+	// ErrorClassUnauthorized the error class of a command which was not allowed to access a Kubernetes resource
+	ErrorClassUnauthorized = "unauthorized"
+	// ErrorClassCommand the error class of a command which failed running an external command such as git or helm
+	ErrorClassCommand = "command"
+	// ErrorClassOther the error class of any other failure
+	ErrorClassOther = "other"
+
+	sendTimeout = 2 * time.Second
+)
+
+// Settings the telemetry settings of the user
+type Settings struct {
+	// Enabled the user has opted in to sending anonymized usage metrics
+	Enabled bool `json:"enabled,omitempty"`
+	// NoticeShown the first run notice explaining telemetry has been shown
+	NoticeShown bool `json:"noticeShown,omitempty"`
+	// Endpoint the endpoint the usage metrics are sent to. There is no default so nothing is sent until one is configured
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// Event the anonymized usage metrics of a command. It must never contain identifying data such as the arguments or
+// flag values of the command, names, URLs or the error message
+type Event struct {
+	Command    string    `json:"command"`
+	DurationMS int64     `json:"durationMs"`
+	Provider   string    `json:"provider,omitempty"`
+	ErrorClass string    `json:"errorClass,omitempty"`
+	Version    string    `json:"version,omitempty"`
+	OS         string    `json:"os"`
+	Arch       string    `json:"arch"`
+	Time       time.Time `json:"time"`
+}
+
+// Client records the usage metrics of commands when the user has opted in, spooling them locally when offline
+type Client struct {
+	// Dir the directory containing the settings and spool files, defaults to ~/.jx
+	Dir string
+	// Send sends the events to the endpoint, defaults to posting them as JSON
+	Send func(endpoint string, events []Event) error
+}
+
+// NewEvent creates the event of a command which took the given time and failed with the given error, if any
+func NewEvent(command string, duration time.Duration, provider string, err error, version string) Event {
+	return Event{
+		Command:    command,
+		DurationMS: duration.Milliseconds(),
+		Provider:   provider,
+		ErrorClass: ClassifyError(err),
+		Version:    version,
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		Time:       time.Now().UTC(),
+	}
+}
+
+// ClassifyError returns the class of the error which is all that is recorded about a failure
+func ClassifyError(err error) string {
+	if err == nil {
+		return ErrorClassNone
+	}
+	cause := errors.Cause(err)
+	if netErr, ok := cause.(net.Error); ok {
+		if netErr.Timeout() {
+			return ErrorClassTimeout
+		}
+		return ErrorClassNetwork
+	}
+	if _, ok := cause.(*exec.ExitError); ok {
+		return ErrorClassCommand
+	}
+	switch {
+	case apierrors.IsNotFound(cause):
+		return ErrorClassNotFound
+	case apierrors.IsUnauthorized(cause), apierrors.IsForbidden(cause):
+		return ErrorClassUnauthorized
+	case apierrors.IsTimeout(cause), apierrors.IsServerTimeout(cause):
+		return ErrorClassTimeout
+	}
+	message := strings.ToLower(err.Error())
+	if strings.Contains(message, "timed out") || strings.Contains(message, "timeout") {
+		return ErrorClassTimeout
+	}
+	return ErrorClassOther
+}
+
+// Disabled returns true if telemetry is disabled via the environment
+func Disabled() bool {
+	text := strings.TrimSpace(os.Getenv(EnvTelemetry))
+	value, err := util.ParseBool(text)
+	return text != "" && err == nil && !value
+}
+
+// Enabled returns true if the user has opted in, an endpoint is configured and telemetry is not disabled via the
+// environment
+func (c *Client) Enabled(settings *Settings) bool {
+	return settings.Enabled && Endpoint(settings) != "" && !Disabled()
+}
+
+// Notice returns the message explaining telemetry which is shown once on the first run
+func Notice() string {
+	return fmt.Sprintf("jx can send anonymized usage metrics (the command name, its duration, the cloud provider and the class of any error) to help the maintainers prioritize their work.\n"+
+		"No arguments, names, URLs or other identifying data are sent. Telemetry is off unless you opt in with '%s'. Run '%s' to see what is recorded",
+		util.ColorInfo("jx telemetry on --endpoint <url>"), util.ColorInfo("jx telemetry status"))
+}
+
+// FirstRunNotice returns the notice explaining telemetry if it has not been shown yet and records that it was shown
+func (c *Client) FirstRunNotice(settings *Settings) (string, error) {
+	if settings.NoticeShown {
+		return "", nil
+	}
+	settings.NoticeShown = true
+	return Notice(), c.SaveSettings(settings)
+}
+
+// SettingsFile returns the file name of the settings
+func (c *Client) SettingsFile() (string, error) {
+	return c.file(SettingsFileName)
+}
+
+// SpoolFile returns the file name of the spooled events
+func (c *Client) SpoolFile() (string, error) {
+	return c.file(SpoolFileName)
+}
+
+func (c *Client) file(name string) (string, error) {
+	dir := c.Dir
+	if dir == "" {
+		var err error
+		dir, err = util.ConfigDir()
+		if err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// LoadSettings loads the settings returning empty settings, so telemetry is off, if the file does not exist
+func (c *Client) LoadSettings() (*Settings, error) {
+	settings := &Settings{}
+	fileName, err := c.SettingsFile()
+	if err != nil {
+		return settings, err
+	}
+	exists, err := util.FileExists(fileName)
+	if err != nil || !exists {
+		return settings, err
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return settings, errors.Wrapf(err, "reading %s", fileName)
+	}
+	err = yaml.Unmarshal(data, settings)
+	if err != nil {
+		return settings, errors.Wrapf(err, "unmarshalling %s", fileName)
+	}
+	return settings, nil
+}
+
+// SaveSettings saves the settings
+func (c *Client) SaveSettings(settings *Settings) error {
+	fileName, err := c.SettingsFile()
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(settings)
+	if err != nil {
+		return errors.Wrap(err, "marshalling the telemetry settings")
+	}
+	err = os.MkdirAll(filepath.Dir(fileName), util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "creating directory %s", filepath.Dir(fileName))
+	}
+	err = ioutil.WriteFile(fileName, data, util.DefaultFileWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "writing %s", fileName)
+	}
+	return nil
+}
+
+// Record records the event if the user has opted in. The event is sent together with any spooled events and is
+// spooled if they cannot be sent, e.g. when offline, so that it is sent by a later command
+func (c *Client) Record(settings *Settings, event Event) error {
+	if !c.Enabled(settings) {
+		return nil
+	}
+	events, err := c.SpooledEvents()
+	if err != nil {
+		return err
+	}
+	events = append(events, event)
+
+	send := c.Send
+	if send == nil {
+		send = postEvents
+	}
+	err = send(Endpoint(settings), events)
+	if err == nil {
+		return c.writeSpool(nil)
+	}
+	if len(events) > MaxSpooledEvents {
+		events = events[len(events)-MaxSpooledEvents:]
+	}
+	return c.writeSpool(events)
+}
+
+// SpooledEvents returns the events which have not been sent yet
+func (c *Client) SpooledEvents() ([]Event, error) {
+	fileName, err := c.SpoolFile()
+	if err != nil {
+		return nil, err
+	}
+	exists, err := util.FileExists(fileName)
+	if err != nil || !exists {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", fileName)
+	}
+	var events []Event
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		event := Event{}
+		// skip corrupted lines rather than losing the rest of the spool
+		if json.Unmarshal([]byte(line), &event) == nil {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// ClearSpool deletes the events which have not been sent yet
+func (c *Client) ClearSpool() error {
+	return c.writeSpool(nil)
+}
+
+func (c *Client) writeSpool(events []Event) error {
+	fileName, err := c.SpoolFile()
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		err = os.Remove(fileName)
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "removing %s", fileName)
+		}
+		return nil
+	}
+	var buffer bytes.Buffer
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return errors.Wrap(err, "marshalling a telemetry event")
+		}
+		buffer.Write(data)
+		buffer.WriteString("\n")
+	}
+	err = os.MkdirAll(filepath.Dir(fileName), util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "creating directory %s", filepath.Dir(fileName))
+	}
+	err = ioutil.WriteFile(fileName, buffer.Bytes(), util.DefaultFileWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "writing %s", fileName)
+	}
+	return nil
+}
+
+// Endpoint returns the endpoint the usage metrics are sent to from the environment or the settings, or an empty string
+// if none is configured
+func Endpoint(settings *Settings) string {
+	if value := os.Getenv(EnvTelemetryEndpoint); value != "" {
+		return value
+	}
+	return settings.Endpoint
+}
+
+// postEvents posts the events as a JSON array with a short timeout so that commands are not slowed down when offline
+func postEvents(endpoint string, events []Event) error {
+	data, err := json.Marshal(events)
+	if err != nil {
+		return errors.Wrap(err, "marshalling the telemetry events")
+	}
+	client := &http.Client{Timeout: sendTimeout}
+	resp, err := client.Post(endpoint, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrapf(err, "sending the telemetry events to %s", endpoint)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("sending the telemetry events to %s returned status %s", endpoint, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+// +build unit
+
+package telemetry_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/telemetry"
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestRecordIsOptIn(t *testing.T) {
+	client, sent := newTestClient(t, nil)
+	defer os.RemoveAll(client.Dir)
+
+	settings, err := client.LoadSettings()
+	require.NoError(t, err)
+	assert.False(t, client.Enabled(settings), "telemetry should be off by default")
+
+	err = client.Record(settings, telemetry.NewEvent("jx get env", time.Second, "", nil, "2.1.0"))
+	require.NoError(t, err)
+	assert.Empty(t, *sent)
+	events, err := client.SpooledEvents()
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestRecordSpoolsWhenOffline(t *testing.T) {
+	offline := true
+	client, sent := newTestClient(t, &offline)
+	defer os.RemoveAll(client.Dir)
+	os.Unsetenv(telemetry.EnvTelemetry)
+
+	os.Unsetenv(telemetry.EnvTelemetryEndpoint)
+
+	settings := &telemetry.Settings{Enabled: true}
+	assert.False(t, client.Enabled(settings), "nothing should be sent without an endpoint")
+
+	settings.Endpoint = "http://telemetry.example.com/events"
+	err := client.Record(settings, telemetry.NewEvent("jx create cluster gke", 2*time.Minute, "gke", errors.New("boom"), "2.1.0"))
+	require.NoError(t, err)
+	events, err := client.SpooledEvents()
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "jx create cluster gke", events[0].Command)
+	assert.Equal(t, int64(120000), events[0].DurationMS)
+	assert.Equal(t, "gke", events[0].Provider)
+	assert.Equal(t, telemetry.ErrorClassOther, events[0].ErrorClass)
+
+	offline = false
+	err = client.Record(settings, telemetry.NewEvent("jx get env", time.Second, "", nil, "2.1.0"))
+	require.NoError(t, err)
+	require.Len(t, *sent, 2, "the spooled event should be sent with the new one")
+	assert.Equal(t, "jx create cluster gke", (*sent)[0].Command)
+	assert.Equal(t, "jx get env", (*sent)[1].Command)
+	events, err = client.SpooledEvents()
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	err = os.Setenv(telemetry.EnvTelemetry, "false")
+	require.NoError(t, err)
+	defer os.Unsetenv(telemetry.EnvTelemetry)
+	assert.False(t, client.Enabled(settings), "the environment should disable telemetry")
+}
+
+func TestFirstRunNotice(t *testing.T) {
+	client, _ := newTestClient(t, nil)
+	defer os.RemoveAll(client.Dir)
+
+	settings, err := client.LoadSettings()
+	require.NoError(t, err)
+	notice, err := client.FirstRunNotice(settings)
+	require.NoError(t, err)
+	assert.Contains(t, notice, "jx telemetry on")
+
+	settings, err = client.LoadSettings()
+	require.NoError(t, err)
+	notice, err = client.FirstRunNotice(settings)
+	require.NoError(t, err)
+	assert.Empty(t, notice, "the notice should only be shown once")
+	assert.False(t, settings.Enabled)
+}
+
+func TestClassifyError(t *testing.T) {
+	resource := schema.GroupResource{Resource: "environments"}
+	assert.Equal(t, telemetry.ErrorClassNone, telemetry.ClassifyError(nil))
+	assert.Equal(t, telemetry.ErrorClassNotFound, telemetry.ClassifyError(pkgerrors.Wrap(apierrors.NewNotFound(resource, "dev"), "getting the dev environment")))
+	assert.Equal(t, telemetry.ErrorClassUnauthorized, telemetry.ClassifyError(apierrors.NewForbidden(resource, "dev", errors.New("denied"))))
+	assert.Equal(t, telemetry.ErrorClassNetwork, telemetry.ClassifyError(&net.OpError{Op: "dial", Err: errors.New("connection refused")}))
+	assert.Equal(t, telemetry.ErrorClassTimeout, telemetry.ClassifyError(errors.New("timed out waiting for the condition")))
+	assert.Equal(t, telemetry.ErrorClassOther, telemetry.ClassifyError(errors.New("boom")))
+}
+
+func newTestClient(t *testing.T, offline *bool) (*telemetry.Client, *[]telemetry.Event) {
+	dir, err := ioutil.TempDir("", "test-telemetry-")
+	require.NoError(t, err)
+	sent := []telemetry.Event{}
+	client := &telemetry.Client{
+		Dir: dir,
+		Send: func(endpoint string, events []telemetry.Event) error {
+			if offline != nil && *offline {
+				return errors.New("no route to host")
+			}
+			sent = append(sent, events...)
+			return nil
+		},
+	}
+	return client, &sent
+}
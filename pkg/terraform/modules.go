@@ -0,0 +1,398 @@
+package terraform
+
+import "github.com/jenkins-x/jx/v2/pkg/cloud"
+
+// module the terraform files of a provider
+type module struct {
+	main      string
+	variables string
+	outputs   string
+}
+
+const commonVariables = `variable "cluster_name" {
+  description = "The name of the cluster"
+  type        = string
+}
+
+variable "region" {
+  description = "The cloud region"
+  type        = string
+}
+
+variable "domain" {
+  description = "The domain whose DNS zone is created. Leave empty to skip the DNS zone"
+  type        = string
+  default     = ""
+}
+
+variable "namespace" {
+  description = "The namespace jx is installed in"
+  type        = string
+  default     = "jx"
+}
+
+variable "machine_type" {
+  description = "The machine type of the nodes"
+  type        = string
+}
+
+variable "min_nodes" {
+  description = "The minimum number of nodes"
+  type        = number
+  default     = 3
+}
+
+variable "max_nodes" {
+  description = "The maximum number of nodes"
+  type        = number
+  default     = 5
+}
+
+variable "force_destroy" {
+  description = "Destroys the buckets even if they are not empty"
+  type        = bool
+  default     = false
+}
+`
+
+var modules = map[string]module{
+	cloud.GKE: {
+		main: `provider "google" {
+  project = var.project_id
+  region  = var.region
+}
+
+resource "google_project_service" "apis" {
+  for_each           = toset(["container.googleapis.com", "containerregistry.googleapis.com", "dns.googleapis.com", "iam.googleapis.com"])
+  service            = each.value
+  disable_on_destroy = false
+}
+
+resource "google_container_cluster" "jx" {
+  name                     = var.cluster_name
+  location                 = var.zone
+  remove_default_node_pool = true
+  initial_node_count       = 1
+
+  workload_identity_config {
+    identity_namespace = "${var.project_id}.svc.id.goog"
+  }
+
+  depends_on = [google_project_service.apis]
+}
+
+resource "google_container_node_pool" "jx" {
+  name     = "${var.cluster_name}-nodes"
+  location = var.zone
+  cluster  = google_container_cluster.jx.name
+
+  autoscaling {
+    min_node_count = var.min_nodes
+    max_node_count = var.max_nodes
+  }
+
+  node_config {
+    machine_type = var.machine_type
+    oauth_scopes = ["https://www.googleapis.com/auth/cloud-platform"]
+
+    workload_metadata_config {
+      node_metadata = "GKE_METADATA_SERVER"
+    }
+  }
+}
+
+resource "google_dns_managed_zone" "jx" {
+  count    = var.domain == "" ? 0 : 1
+  name     = replace(var.domain, ".", "-")
+  dns_name = "${var.domain}."
+}
+
+resource "google_storage_bucket" "storage" {
+  for_each      = toset(["logs", "reports", "repository"])
+  name          = "${var.cluster_name}-${each.value}-${var.project_id}"
+  location      = var.region
+  force_destroy = var.force_destroy
+}
+
+resource "google_service_account" "kaniko" {
+  account_id   = "${var.cluster_name}-ko"
+  display_name = "${var.cluster_name}-ko"
+}
+
+resource "google_project_iam_member" "kaniko" {
+  for_each = toset(["roles/storage.admin", "roles/storage.objectAdmin", "roles/storage.objectCreator"])
+  role     = each.value
+  member   = "serviceAccount:${google_service_account.kaniko.email}"
+}
+
+resource "google_service_account_iam_member" "kaniko_workload_identity" {
+  service_account_id = google_service_account.kaniko.name
+  role               = "roles/iam.workloadIdentityUser"
+  member             = "serviceAccount:${var.project_id}.svc.id.goog[${var.namespace}/tekton-bot]"
+  depends_on         = [google_container_cluster.jx]
+}
+`,
+		variables: `
+variable "project_id" {
+  description = "The GCP project"
+  type        = string
+}
+
+variable "zone" {
+  description = "The zone of the cluster"
+  type        = string
+}
+`,
+		outputs: `output "cluster_name" {
+  value = google_container_cluster.jx.name
+}
+
+output "dns_name_servers" {
+  value = flatten(google_dns_managed_zone.jx[*].name_servers)
+}
+
+output "jx_requirements" {
+  value = yamlencode({
+    cluster = {
+      provider         = "gke"
+      clusterName      = google_container_cluster.jx.name
+      project          = var.project_id
+      region           = var.region
+      zone             = var.zone
+      namespace        = var.namespace
+      registry         = "gcr.io"
+      kanikoSAName     = google_service_account.kaniko.account_id
+      workloadIdentity = true
+    }
+    ingress = {
+      domain      = var.domain
+      externalDNS = var.domain != ""
+    }
+    storage = {
+      logs       = { enabled = true, url = "gs://${google_storage_bucket.storage["logs"].name}" }
+      reports    = { enabled = true, url = "gs://${google_storage_bucket.storage["reports"].name}" }
+      repository = { enabled = true, url = "gs://${google_storage_bucket.storage["repository"].name}" }
+    }
+    kaniko    = true
+    terraform = true
+  })
+}
+`,
+	},
+	cloud.EKS: {
+		main: `provider "aws" {
+  region = var.region
+}
+
+data "aws_availability_zones" "available" {}
+
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "~> 2.0"
+
+  name                 = var.cluster_name
+  cidr                 = "10.0.0.0/16"
+  azs                  = slice(data.aws_availability_zones.available.names, 0, 3)
+  public_subnets       = ["10.0.1.0/24", "10.0.2.0/24", "10.0.3.0/24"]
+  enable_dns_hostnames = true
+
+  tags = {
+    "kubernetes.io/cluster/${var.cluster_name}" = "shared"
+  }
+}
+
+module "eks" {
+  source  = "terraform-aws-modules/eks/aws"
+  version = "~> 12.0"
+
+  cluster_name = var.cluster_name
+  subnets      = module.vpc.public_subnets
+  vpc_id       = module.vpc.vpc_id
+  enable_irsa  = true
+
+  worker_groups = [{
+    instance_type        = var.machine_type
+    asg_min_size         = var.min_nodes
+    asg_max_size         = var.max_nodes
+    asg_desired_capacity = var.min_nodes
+  }]
+}
+
+resource "aws_route53_zone" "jx" {
+  count = var.domain == "" ? 0 : 1
+  name  = var.domain
+}
+
+resource "aws_s3_bucket" "storage" {
+  for_each      = toset(["logs", "reports", "repository"])
+  bucket_prefix = "${var.cluster_name}-${each.value}-"
+  force_destroy = var.force_destroy
+}
+
+module "kaniko_role" {
+  source  = "terraform-aws-modules/iam/aws//modules/iam-assumable-role-with-oidc"
+  version = "~> 2.0"
+
+  create_role                   = true
+  role_name                     = "${var.cluster_name}-ko"
+  provider_url                  = replace(module.eks.cluster_oidc_issuer_url, "https://", "")
+  role_policy_arns              = ["arn:aws:iam::aws:policy/AmazonEC2ContainerRegistryPowerUser", "arn:aws:iam::aws:policy/AmazonS3FullAccess"]
+  oidc_fully_qualified_subjects = ["system:serviceaccount:${var.namespace}:tekton-bot"]
+}
+`,
+		outputs: `output "cluster_name" {
+  value = module.eks.cluster_id
+}
+
+output "dns_name_servers" {
+  value = flatten(aws_route53_zone.jx[*].name_servers)
+}
+
+output "jx_requirements" {
+  value = yamlencode({
+    cluster = {
+      provider         = "eks"
+      clusterName      = module.eks.cluster_id
+      region           = var.region
+      namespace        = var.namespace
+      kanikoSAName     = "${var.cluster_name}-ko"
+      workloadIdentity = true
+    }
+    ingress = {
+      domain      = var.domain
+      externalDNS = var.domain != ""
+    }
+    storage = {
+      logs       = { enabled = true, url = "s3://${aws_s3_bucket.storage["logs"].id}" }
+      reports    = { enabled = true, url = "s3://${aws_s3_bucket.storage["reports"].id}" }
+      repository = { enabled = true, url = "s3://${aws_s3_bucket.storage["repository"].id}" }
+    }
+    kaniko    = true
+    terraform = true
+  })
+}
+`,
+	},
+	cloud.AKS: {
+		main: `provider "azurerm" {
+  features {}
+}
+
+resource "azurerm_resource_group" "jx" {
+  name     = var.resource_group
+  location = var.region
+}
+
+resource "azurerm_kubernetes_cluster" "jx" {
+  name                      = var.cluster_name
+  location                  = azurerm_resource_group.jx.location
+  resource_group_name       = azurerm_resource_group.jx.name
+  dns_prefix                = var.cluster_name
+  oidc_issuer_enabled       = true
+  workload_identity_enabled = true
+
+  default_node_pool {
+    name                = "default"
+    vm_size             = var.machine_type
+    enable_auto_scaling = true
+    min_count           = var.min_nodes
+    max_count           = var.max_nodes
+  }
+
+  identity {
+    type = "SystemAssigned"
+  }
+}
+
+resource "azurerm_container_registry" "jx" {
+  name                = replace("${var.cluster_name}registry", "-", "")
+  resource_group_name = azurerm_resource_group.jx.name
+  location            = azurerm_resource_group.jx.location
+  sku                 = "Standard"
+}
+
+resource "azurerm_dns_zone" "jx" {
+  count               = var.domain == "" ? 0 : 1
+  name                = var.domain
+  resource_group_name = azurerm_resource_group.jx.name
+}
+
+resource "azurerm_storage_account" "jx" {
+  name                     = substr(replace("${var.cluster_name}storage", "-", ""), 0, 24)
+  resource_group_name      = azurerm_resource_group.jx.name
+  location                 = azurerm_resource_group.jx.location
+  account_tier             = "Standard"
+  account_replication_type = "LRS"
+}
+
+resource "azurerm_storage_container" "storage" {
+  for_each             = toset(["logs", "reports", "repository"])
+  name                 = each.value
+  storage_account_name = azurerm_storage_account.jx.name
+}
+
+resource "azurerm_user_assigned_identity" "kaniko" {
+  name                = "${var.cluster_name}-ko"
+  resource_group_name = azurerm_resource_group.jx.name
+  location            = azurerm_resource_group.jx.location
+}
+
+resource "azurerm_federated_identity_credential" "kaniko" {
+  name                = "${var.namespace}-tekton-bot"
+  resource_group_name = azurerm_resource_group.jx.name
+  parent_id           = azurerm_user_assigned_identity.kaniko.id
+  issuer              = azurerm_kubernetes_cluster.jx.oidc_issuer_url
+  subject             = "system:serviceaccount:${var.namespace}:tekton-bot"
+  audience            = ["api://AzureADTokenExchange"]
+}
+
+resource "azurerm_role_assignment" "kaniko" {
+  scope                = azurerm_container_registry.jx.id
+  role_definition_name = "AcrPush"
+  principal_id         = azurerm_user_assigned_identity.kaniko.principal_id
+}
+`,
+		variables: `
+variable "resource_group" {
+  description = "The resource group of the cluster"
+  type        = string
+}
+`,
+		outputs: `output "cluster_name" {
+  value = azurerm_kubernetes_cluster.jx.name
+}
+
+output "dns_name_servers" {
+  value = flatten(azurerm_dns_zone.jx[*].name_servers)
+}
+
+output "jx_requirements" {
+  value = yamlencode({
+    cluster = {
+      provider         = "aks"
+      clusterName      = azurerm_kubernetes_cluster.jx.name
+      region           = var.region
+      namespace        = var.namespace
+      registry         = azurerm_container_registry.jx.login_server
+      kanikoSAName     = azurerm_user_assigned_identity.kaniko.name
+      workloadIdentity = true
+      azure = {
+        resourceGroup = azurerm_resource_group.jx.name
+      }
+    }
+    ingress = {
+      domain      = var.domain
+      externalDNS = var.domain != ""
+    }
+    storage = {
+      logs       = { enabled = true, url = "azblob://${azurerm_storage_container.storage["logs"].name}" }
+      reports    = { enabled = true, url = "azblob://${azurerm_storage_container.storage["reports"].name}" }
+      repository = { enabled = true, url = "azblob://${azurerm_storage_container.storage["repository"].name}" }
+    }
+    kaniko    = true
+    terraform = true
+  })
+}
+`,
+	},
+}
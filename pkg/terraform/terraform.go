@@ -0,0 +1,226 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cloud"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// MainFileName the name of the generated file containing the resources
+	MainFileName = "main.tf"
+	// VariablesFileName the name of the generated file declaring the variables
+	VariablesFileName = "variables.tf"
+	// OutputsFileName the name of the generated file declaring the outputs
+	OutputsFileName = "outputs.tf"
+	// ValuesFileName the name of the generated file containing the values of the variables
+	ValuesFileName = "terraform.tfvars"
+
+	// RequirementsOutput the name of the output containing the jx-requirements.yml of the cluster
+	RequirementsOutput = "jx_requirements"
+)
+
+// Config the settings of the infrastructure to generate
+type Config struct {
+	// Provider the kubernetes provider: gke, eks or aks
+	Provider string
+	// ClusterName the name of the cluster
+	ClusterName string
+	// ProjectID the GCP project
+	ProjectID string
+	// ResourceGroup the Azure resource group
+	ResourceGroup string
+	// Region the cloud region
+	Region string
+	// Zone the cloud zone of a zonal GKE cluster
+	Zone string
+	// Domain the optional domain whose DNS zone is created
+	Domain string
+	// Namespace the namespace jx is installed in
+	Namespace string
+	// MachineType the machine type of the nodes
+	MachineType string
+	// MinNodes the minimum number of nodes
+	MinNodes int
+	// MaxNodes the maximum number of nodes
+	MaxNodes int
+	// ForceDestroy lets terraform destroy buckets which are not empty
+	ForceDestroy bool
+}
+
+// SupportedProviders returns the providers terraform modules can be generated for
+func SupportedProviders() []string {
+	return []string{cloud.AKS, cloud.EKS, cloud.GKE}
+}
+
+// Validate validates the configuration
+func (c *Config) Validate() error {
+	if util.StringArrayIndex(SupportedProviders(), c.Provider) < 0 {
+		return util.InvalidOption("provider", c.Provider, SupportedProviders())
+	}
+	if c.ClusterName == "" {
+		return util.MissingOption("cluster-name")
+	}
+	switch c.Provider {
+	case cloud.GKE:
+		if c.ProjectID == "" {
+			return util.MissingOption("project-id")
+		}
+		if c.Zone == "" {
+			return util.MissingOption("zone")
+		}
+	case cloud.AKS:
+		if c.ResourceGroup == "" {
+			return util.MissingOption("resource-group")
+		}
+	}
+	if c.Region == "" {
+		return util.MissingOption("region")
+	}
+	if c.MinNodes < 1 || c.MaxNodes < c.MinNodes {
+		return fmt.Errorf("invalid number of nodes: min %d max %d", c.MinNodes, c.MaxNodes)
+	}
+	return nil
+}
+
+// Values returns the values of the terraform variables
+func (c *Config) Values() map[string]string {
+	values := map[string]string{
+		"cluster_name":  c.ClusterName,
+		"region":        c.Region,
+		"domain":        c.Domain,
+		"namespace":     c.Namespace,
+		"machine_type":  c.MachineType,
+		"min_nodes":     strconv.Itoa(c.MinNodes),
+		"max_nodes":     strconv.Itoa(c.MaxNodes),
+		"force_destroy": strconv.FormatBool(c.ForceDestroy),
+	}
+	switch c.Provider {
+	case cloud.GKE:
+		values["project_id"] = c.ProjectID
+		values["zone"] = c.Zone
+	case cloud.AKS:
+		values["resource_group"] = c.ResourceGroup
+	}
+	return values
+}
+
+// Generate writes the terraform module of the configuration into the directory returning the names of the files
+func Generate(dir string, c *Config) ([]string, error) {
+	err := c.Validate()
+	if err != nil {
+		return nil, err
+	}
+	module, ok := modules[c.Provider]
+	if !ok {
+		return nil, fmt.Errorf("no terraform module for provider %s", c.Provider)
+	}
+	err = os.MkdirAll(dir, util.DefaultWritePermissions)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating directory %s", dir)
+	}
+	files := map[string]string{
+		MainFileName:      module.main,
+		VariablesFileName: commonVariables + module.variables,
+		OutputsFileName:   module.outputs,
+		ValuesFileName:    toTFVars(c.Values()),
+	}
+	var answer []string
+	for _, name := range []string{MainFileName, VariablesFileName, OutputsFileName, ValuesFileName} {
+		fileName := filepath.Join(dir, name)
+		err = ioutil.WriteFile(fileName, []byte(files[name]), util.DefaultFileWritePermissions)
+		if err != nil {
+			return nil, errors.Wrapf(err, "writing %s", fileName)
+		}
+		answer = append(answer, fileName)
+	}
+	return answer, nil
+}
+
+// Apply runs terraform init and apply in the directory of the module
+func Apply(dir string) error {
+	for _, args := range [][]string{{"init", "-input=false"}, {"apply", "-input=false", "-auto-approve"}} {
+		log.Logger().Infof("running %s", util.ColorInfo("terraform "+strings.Join(args, " ")))
+		cmd := util.Command{
+			Dir:  dir,
+			Name: "terraform",
+			Args: args,
+			Out:  os.Stdout,
+			Err:  os.Stderr,
+		}
+		_, err := cmd.RunWithoutRetry()
+		if err != nil {
+			return errors.Wrapf(err, "running terraform %s", args[0])
+		}
+	}
+	return nil
+}
+
+// LoadRequirements reads the jx requirements from the outputs of the applied module in the directory
+func LoadRequirements(dir string) (*config.RequirementsConfig, error) {
+	cmd := util.Command{
+		Dir:  dir,
+		Name: "terraform",
+		Args: []string{"output", "-json"},
+	}
+	output, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return nil, errors.Wrap(err, "reading the terraform outputs")
+	}
+	return ParseRequirements([]byte(output))
+}
+
+// ParseRequirements parses the jx requirements from the JSON of 'terraform output -json'
+func ParseRequirements(data []byte) (*config.RequirementsConfig, error) {
+	outputs := map[string]struct {
+		Value interface{} `json:"value"`
+	}{}
+	err := json.Unmarshal(data, &outputs)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshalling the terraform outputs")
+	}
+	output, ok := outputs[RequirementsOutput]
+	if !ok {
+		return nil, fmt.Errorf("the terraform outputs have no %s output", RequirementsOutput)
+	}
+	text, ok := output.Value.(string)
+	if !ok {
+		return nil, fmt.Errorf("the terraform output %s is not a string", RequirementsOutput)
+	}
+	requirements := config.NewRequirementsConfig()
+	err = yaml.Unmarshal([]byte(text), requirements)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshalling the terraform output %s", RequirementsOutput)
+	}
+	return requirements, nil
+}
+
+func toTFVars(values map[string]string) string {
+	var keys []string
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var buf strings.Builder
+	for _, k := range keys {
+		v := values[k]
+		if _, err := strconv.Atoi(v); err == nil || v == "true" || v == "false" {
+			fmt.Fprintf(&buf, "%s = %s\n", k, v)
+		} else {
+			fmt.Fprintf(&buf, "%s = %s\n", k, strconv.Quote(v))
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,101 @@
+// +build unit
+
+package terraform_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/cloud"
+	"github.com/jenkins-x/jx/v2/pkg/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	c := &terraform.Config{Provider: cloud.GKE, ClusterName: "mycluster", Region: "europe-west1", MinNodes: 3, MaxNodes: 5}
+	assert.Error(t, c.Validate(), "the project is required on gke")
+	c.ProjectID = "myproject"
+	assert.Error(t, c.Validate(), "the zone is required on gke")
+	c.Zone = "europe-west1-b"
+	assert.NoError(t, c.Validate())
+
+	c.MaxNodes = 2
+	assert.Error(t, c.Validate())
+
+	assert.Error(t, (&terraform.Config{Provider: cloud.AKS, ClusterName: "mycluster", Region: "westeurope", MinNodes: 1, MaxNodes: 1}).Validate())
+	assert.Error(t, (&terraform.Config{Provider: cloud.IKS, ClusterName: "mycluster", Region: "us-south", MinNodes: 1, MaxNodes: 1}).Validate())
+}
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-terraform-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) //nolint:errcheck
+
+	for _, c := range []*terraform.Config{
+		{Provider: cloud.GKE, ClusterName: "mycluster", ProjectID: "myproject", Region: "europe-west1", Zone: "europe-west1-b", Namespace: "jx", MachineType: "n1-standard-2", MinNodes: 3, MaxNodes: 5},
+		{Provider: cloud.EKS, ClusterName: "mycluster", Region: "us-east-1", Domain: "acme.com", Namespace: "jx", MachineType: "m5.large", MinNodes: 3, MaxNodes: 5},
+		{Provider: cloud.AKS, ClusterName: "mycluster", ResourceGroup: "jx", Region: "westeurope", Namespace: "jx", MachineType: "Standard_D2s_v3", MinNodes: 1, MaxNodes: 3},
+	} {
+		providerDir := filepath.Join(dir, c.Provider)
+		files, err := terraform.Generate(providerDir, c)
+		require.NoError(t, err, "provider %s", c.Provider)
+		assert.Len(t, files, 4)
+
+		data, err := ioutil.ReadFile(filepath.Join(providerDir, terraform.ValuesFileName))
+		require.NoError(t, err)
+		values := string(data)
+		assert.Contains(t, values, `cluster_name = "mycluster"`)
+		assert.Contains(t, values, "force_destroy = false")
+
+		data, err = ioutil.ReadFile(filepath.Join(providerDir, terraform.OutputsFileName))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `output "jx_requirements"`)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, cloud.GKE, terraform.ValuesFileName))
+	require.NoError(t, err)
+	assert.Equal(t, `cluster_name = "mycluster"
+domain = ""
+force_destroy = false
+machine_type = "n1-standard-2"
+max_nodes = 5
+min_nodes = 3
+namespace = "jx"
+project_id = "myproject"
+region = "europe-west1"
+zone = "europe-west1-b"
+`, string(data))
+}
+
+func TestParseRequirements(t *testing.T) {
+	t.Parallel()
+
+	outputs := `{
+  "cluster_name": {"sensitive": false, "type": "string", "value": "mycluster"},
+  "jx_requirements": {
+    "sensitive": false,
+    "type": "string",
+    "value": "\"cluster\":\n  \"clusterName\": \"mycluster\"\n  \"project\": \"myproject\"\n  \"provider\": \"gke\"\n  \"workloadIdentity\": true\n\"ingress\":\n  \"domain\": \"acme.com\"\n\"kaniko\": true\n\"storage\":\n  \"logs\":\n    \"enabled\": true\n    \"url\": \"gs://mycluster-logs-myproject\"\n\"terraform\": true\n"
+  }
+}`
+	requirements, err := terraform.ParseRequirements([]byte(outputs))
+	require.NoError(t, err)
+	assert.Equal(t, "gke", requirements.Cluster.Provider)
+	assert.Equal(t, "mycluster", requirements.Cluster.ClusterName)
+	assert.Equal(t, "myproject", requirements.Cluster.ProjectID)
+	assert.True(t, requirements.Cluster.WorkloadIdentity)
+	assert.Equal(t, "acme.com", requirements.Ingress.Domain)
+	assert.Equal(t, "gs://mycluster-logs-myproject", requirements.Storage.Logs.URL)
+	assert.True(t, requirements.Kaniko)
+	assert.True(t, requirements.Terraform)
+
+	_, err = terraform.ParseRequirements([]byte(`{"cluster_name": {"value": "mycluster"}}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,263 @@
+package testreports
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx-api/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// FactTypeTests the type of the Facts storing the summary of the tests of a build
+	FactTypeTests = "jx.tests"
+
+	// MeasurementTests the measurement of the number of tests run
+	MeasurementTests = "Tests"
+	// MeasurementFailures the measurement of the number of tests which failed
+	MeasurementFailures = "Failures"
+	// MeasurementErrors the measurement of the number of tests which errored
+	MeasurementErrors = "Errors"
+	// MeasurementSkipped the measurement of the number of tests which were skipped
+	MeasurementSkipped = "Skipped"
+	// MeasurementSeconds the measurement of the time taken to run the tests
+	MeasurementSeconds = "Seconds"
+
+	// LabelFactType the label of the Facts containing their type
+	LabelFactType = "factType"
+	// LabelSubjectKind the label of the Facts containing the kind of their subject
+	LabelSubjectKind = "subjectkind"
+	// LabelPipelineName the label of the Facts containing the name of their PipelineActivity
+	LabelPipelineName = "pipelineName"
+	// LabelOrg the label of the Facts containing the git owner of the build
+	LabelOrg = "org"
+	// LabelRepo the label of the Facts containing the git repository of the build
+	LabelRepo = "repo"
+	// LabelBranch the label of the Facts containing the branch of the build
+	LabelBranch = "branch"
+	// LabelBuildNumber the label of the Facts containing the build number
+	LabelBuildNumber = "buildNumber"
+
+	factPrefix = "jx-tests-"
+)
+
+// NewFact creates the Fact storing the summary of the tests of the build of the branch of the repository
+func NewFact(owner string, repository string, branch string, build string, summary *Summary) *v1.Fact {
+	pipelineName := naming.ToValidName(fmt.Sprintf("%s-%s-%s-%s", owner, repository, branch, build))
+	fact := &v1.Fact{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: factPrefix + pipelineName,
+			Labels: map[string]string{
+				LabelFactType:     FactTypeTests,
+				LabelSubjectKind:  "PipelineActivity",
+				LabelPipelineName: pipelineName,
+				LabelOrg:          naming.ToValidValue(owner),
+				LabelRepo:         naming.ToValidValue(repository),
+				LabelBranch:       naming.ToValidValue(branch),
+				LabelBuildNumber:  build,
+			},
+		},
+		Spec: v1.FactSpec{
+			Name:     factPrefix + pipelineName,
+			FactType: FactTypeTests,
+			Measurements: []v1.Measurement{
+				countMeasurement(MeasurementTests, summary.Tests),
+				countMeasurement(MeasurementFailures, summary.Failures),
+				countMeasurement(MeasurementErrors, summary.Errors),
+				countMeasurement(MeasurementSkipped, summary.Skipped),
+				countMeasurement(MeasurementSeconds, int(math.Round(summary.Seconds))),
+			},
+			SubjectReference: v1.ResourceReference{
+				APIVersion: v1.SchemeGroupVersion.String(),
+				Kind:       "PipelineActivity",
+				Name:       pipelineName,
+			},
+		},
+	}
+	if summary.Coverage >= 0 {
+		fact.Spec.Measurements = append(fact.Spec.Measurements, v1.Measurement{
+			Name:             v1.CodeCoverageMeasurementCoverage,
+			MeasurementType:  v1.MeasurementPercent,
+			MeasurementValue: summary.Coverage,
+		})
+	}
+	return fact
+}
+
+func countMeasurement(name string, value int) v1.Measurement {
+	return v1.Measurement{
+		Name:             name,
+		MeasurementType:  v1.MeasurementCount,
+		MeasurementValue: value,
+	}
+}
+
+// SummaryFromFact returns the summary of the tests stored in the Fact
+func SummaryFromFact(fact *v1.Fact) *Summary {
+	answer := &Summary{Coverage: -1}
+	for _, m := range fact.Spec.Measurements {
+		switch m.Name {
+		case MeasurementTests:
+			answer.Tests = m.MeasurementValue
+		case MeasurementFailures:
+			answer.Failures = m.MeasurementValue
+		case MeasurementErrors:
+			answer.Errors = m.MeasurementValue
+		case MeasurementSkipped:
+			answer.Skipped = m.MeasurementValue
+		case MeasurementSeconds:
+			answer.Seconds = float64(m.MeasurementValue)
+		case v1.CodeCoverageMeasurementCoverage:
+			answer.Coverage = m.MeasurementValue
+		}
+	}
+	return answer
+}
+
+// SaveFact creates or updates the Fact in the namespace
+func SaveFact(jxClient versioned.Interface, ns string, fact *v1.Fact) (*v1.Fact, error) {
+	facts := jxClient.JenkinsV1().Facts(ns)
+	existing, err := facts.Get(fact.Name, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, errors.Wrapf(err, "getting Fact %s in namespace %s", fact.Name, ns)
+		}
+		answer, err := facts.Create(fact)
+		if err != nil {
+			return nil, errors.Wrapf(err, "creating Fact %s in namespace %s", fact.Name, ns)
+		}
+		return answer, nil
+	}
+	existing.Labels = fact.Labels
+	existing.Spec = fact.Spec
+	answer, err := facts.Update(existing)
+	if err != nil {
+		return nil, errors.Wrapf(err, "updating Fact %s in namespace %s", fact.Name, ns)
+	}
+	return answer, nil
+}
+
+// ListFacts returns the Facts of the tests of the builds in the namespace sorted by pipeline and build number,
+// filtered by the owner, repository and branch if they are not empty
+func ListFacts(jxClient versioned.Interface, ns string, owner string, repository string, branch string) ([]v1.Fact, error) {
+	selector := map[string]string{LabelFactType: FactTypeTests}
+	if owner != "" {
+		selector[LabelOrg] = naming.ToValidValue(owner)
+	}
+	if repository != "" {
+		selector[LabelRepo] = naming.ToValidValue(repository)
+	}
+	if branch != "" {
+		selector[LabelBranch] = naming.ToValidValue(branch)
+	}
+	list, err := jxClient.JenkinsV1().Facts(ns).List(metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(selector).String(),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the Facts in namespace %s", ns)
+	}
+	answer := list.Items
+	sort.SliceStable(answer, func(i, j int) bool {
+		pi, pj := pipelineOf(&answer[i]), pipelineOf(&answer[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return buildNumberOf(&answer[i]) < buildNumberOf(&answer[j])
+	})
+	return answer, nil
+}
+
+// PreviousSummary returns the summary of the tests of the latest build of the branch before the given build or
+// nil if there is none. An empty build returns the latest build of the branch
+func PreviousSummary(jxClient versioned.Interface, ns string, owner string, repository string, branch string, build string) (*Summary, error) {
+	facts, err := ListFacts(jxClient, ns, owner, repository, branch)
+	if err != nil {
+		return nil, err
+	}
+	buildNumber, err := strconv.Atoi(build)
+	if err != nil {
+		buildNumber = -1
+	}
+	for i := len(facts) - 1; i >= 0; i-- {
+		n := buildNumberOf(&facts[i])
+		if buildNumber < 0 || n < buildNumber {
+			return SummaryFromFact(&facts[i]), nil
+		}
+	}
+	return nil, nil
+}
+
+// Trend describes the changes in the results of the tests since the previous summary
+func Trend(current *Summary, previous *Summary) string {
+	if previous == nil {
+		return ""
+	}
+	changes := []string{}
+	addChange := func(delta int, format string) {
+		if delta != 0 {
+			changes = append(changes, fmt.Sprintf(format, delta))
+		}
+	}
+	addChange(current.Passed()-previous.Passed(), "%+d passed")
+	addChange(current.Failures+current.Errors-previous.Failures-previous.Errors, "%+d failed")
+	addChange(current.Skipped-previous.Skipped, "%+d skipped")
+	if current.Coverage >= 0 && previous.Coverage >= 0 {
+		addChange(current.Coverage-previous.Coverage, "%+d%% coverage")
+	}
+	if len(changes) == 0 {
+		return "no change"
+	}
+	return strings.Join(changes, ", ")
+}
+
+// Markdown returns the summary of the tests as markdown for a Pull Request comment comparing it to the previous
+// summary of the base branch if it is not nil
+func Markdown(current *Summary, previous *Summary, baseBranch string) string {
+	status := ":heavy_check_mark: **All tests passed**"
+	if !current.Succeeded() {
+		status = fmt.Sprintf(":x: **%d tests failed**", current.Failures+current.Errors)
+	}
+	coverage := func(s *Summary) string {
+		if s == nil || s.Coverage < 0 {
+			return ""
+		}
+		return fmt.Sprintf("%d%%", s.Coverage)
+	}
+
+	lines := []string{
+		status,
+		"",
+		"| | Tests | Passed | Failed | Skipped | Coverage |",
+		"|---|---|---|---|---|---|",
+		fmt.Sprintf("| This Pull Request | %d | %d | %d | %d | %s |", current.Tests, current.Passed(), current.Failures+current.Errors, current.Skipped, coverage(current)),
+	}
+	if previous != nil {
+		lines = append(lines,
+			fmt.Sprintf("| %s | %d | %d | %d | %d | %s |", baseBranch, previous.Tests, previous.Passed(), previous.Failures+previous.Errors, previous.Skipped, coverage(previous)),
+			"",
+			fmt.Sprintf("Compared to %s: %s", baseBranch, Trend(current, previous)),
+		)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func pipelineOf(fact *v1.Fact) string {
+	l := fact.Labels
+	return l[LabelOrg] + "/" + l[LabelRepo] + "/" + l[LabelBranch]
+}
+
+func buildNumberOf(fact *v1.Fact) int {
+	n, err := strconv.Atoi(fact.Labels[LabelBuildNumber])
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,155 @@
+package testreports
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Summary the number of tests run by a build and their results
+type Summary struct {
+	Tests    int `json:"tests"`
+	Failures int `json:"failures"`
+	Errors   int `json:"errors"`
+	Skipped  int `json:"skipped"`
+	// Seconds the time taken to run the tests
+	Seconds float64 `json:"seconds"`
+	// Coverage the percentage of code covered by the tests or -1 if it is not known
+	Coverage int `json:"coverage"`
+}
+
+// Passed returns the number of tests which passed
+func (s *Summary) Passed() int {
+	answer := s.Tests - s.Failures - s.Errors - s.Skipped
+	if answer < 0 {
+		return 0
+	}
+	return answer
+}
+
+// Succeeded returns true if no tests failed or errored
+func (s *Summary) Succeeded() bool {
+	return s.Failures == 0 && s.Errors == 0
+}
+
+// Add adds the results of the other summary to this one
+func (s *Summary) Add(other *Summary) {
+	s.Tests += other.Tests
+	s.Failures += other.Failures
+	s.Errors += other.Errors
+	s.Skipped += other.Skipped
+	s.Seconds += other.Seconds
+}
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	XMLName    xml.Name         `xml:"testsuite"`
+	Tests      string           `xml:"tests,attr"`
+	Failures   string           `xml:"failures,attr"`
+	Errors     string           `xml:"errors,attr"`
+	Skipped    string           `xml:"skipped,attr"`
+	Disabled   string           `xml:"disabled,attr"`
+	Time       string           `xml:"time,attr"`
+	TestCases  []junitTestCase  `xml:"testcase"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestCase struct {
+	Failure *struct{} `xml:"failure"`
+	Error   *struct{} `xml:"error"`
+	Skipped *struct{} `xml:"skipped"`
+}
+
+// ParseJUnitFiles parses the JUnit or XUnit XML files returning the summary of their tests
+func ParseJUnitFiles(fileNames []string) (*Summary, error) {
+	answer := &Summary{Coverage: -1}
+	for _, fileName := range fileNames {
+		data, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", fileName)
+		}
+		summary, err := ParseJUnit(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s", fileName)
+		}
+		answer.Add(summary)
+	}
+	return answer, nil
+}
+
+// ParseJUnit parses the JUnit or XUnit XML returning the summary of its tests
+func ParseJUnit(data []byte) (*Summary, error) {
+	// trying to parse <testsuites></testsuites>
+	suites := junitTestSuites{}
+	err := xml.Unmarshal(data, &suites)
+	if err != nil {
+		// If no <testsuites></testsuites>, trying to parse <testsuite></testsuite>
+		suite := junitTestSuite{}
+		err2 := xml.Unmarshal(data, &suite)
+		if err2 != nil {
+			return nil, errors.Wrap(err2, "the XML is neither testsuites nor a testsuite")
+		}
+		suites.TestSuites = []junitTestSuite{suite}
+	}
+	answer := &Summary{Coverage: -1}
+	for i := range suites.TestSuites {
+		answer.Add(summarizeSuite(&suites.TestSuites[i]))
+	}
+	return answer, nil
+}
+
+// summarizeSuite counts the test cases of the suite, falling back to its attributes for the tools which only
+// report the counts
+func summarizeSuite(suite *junitTestSuite) *Summary {
+	answer := &Summary{}
+	for i := range suite.TestSuites {
+		answer.Add(summarizeSuite(&suite.TestSuites[i]))
+	}
+	if len(suite.TestSuites) == 0 {
+		answer.Seconds = parseFloat(suite.Time)
+	}
+	if len(suite.TestCases) == 0 {
+		if len(suite.TestSuites) == 0 {
+			answer.Tests = parseInt(suite.Tests)
+			answer.Failures = parseInt(suite.Failures)
+			answer.Errors = parseInt(suite.Errors)
+			answer.Skipped = parseInt(suite.Skipped) + parseInt(suite.Disabled)
+		}
+		return answer
+	}
+	for _, tc := range suite.TestCases {
+		answer.Tests++
+		switch {
+		case tc.Failure != nil:
+			answer.Failures++
+		case tc.Error != nil:
+			answer.Errors++
+		case tc.Skipped != nil:
+			answer.Skipped++
+		}
+	}
+	return answer
+}
+
+func parseInt(text string) int {
+	answer, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil {
+		return 0
+	}
+	return answer
+}
+
+func parseFloat(text string) float64 {
+	answer, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	if err != nil {
+		return 0
+	}
+	return answer
+}
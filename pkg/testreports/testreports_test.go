@@ -0,0 +1,124 @@
+// +build unit
+
+package testreports_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx-api/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/v2/pkg/testreports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJUnit(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name     string
+		xml      string
+		expected testreports.Summary
+	}{
+		{
+			name: "testcases",
+			xml: `<testsuite name="a" tests="4" time="2.6">
+  <testcase name="passes"/>
+  <testcase name="fails"><failure message="expected 1"/></testcase>
+  <testcase name="errors"><error message="boom"/></testcase>
+  <testcase name="skips"><skipped/></testcase>
+</testsuite>`,
+			expected: testreports.Summary{Tests: 4, Failures: 1, Errors: 1, Skipped: 1, Seconds: 2.6, Coverage: -1},
+		},
+		{
+			name: "nested suites",
+			xml: `<testsuites>
+  <testsuite name="a" time="1">
+    <testsuite name="b" time="1.25"><testcase name="passes"/></testsuite>
+    <testsuite name="c" time="0.25"><testcase name="fails"><failure/></testcase></testsuite>
+  </testsuite>
+  <testsuite name="d" time="1"><testcase name="passes"/></testsuite>
+</testsuites>`,
+			expected: testreports.Summary{Tests: 3, Failures: 1, Seconds: 2.5, Coverage: -1},
+		},
+		{
+			name:     "attributes only",
+			xml:      `<testsuite name="a" tests="10" failures="2" errors="1" skipped="3" disabled="1" time="4"></testsuite>`,
+			expected: testreports.Summary{Tests: 10, Failures: 2, Errors: 1, Skipped: 4, Seconds: 4, Coverage: -1},
+		},
+	}
+	for _, tc := range testCases {
+		summary, err := testreports.ParseJUnit([]byte(tc.xml))
+		require.NoError(t, err, tc.name)
+		assert.Equal(t, tc.expected, *summary, tc.name)
+	}
+
+	_, err := testreports.ParseJUnit([]byte("<html></html>"))
+	assert.Error(t, err)
+}
+
+func TestParseJUnitFiles(t *testing.T) {
+	t.Parallel()
+	summary, err := testreports.ParseJUnitFiles([]string{"test_data/passed.xml", "test_data/failed.xml"})
+	require.NoError(t, err)
+	assert.Equal(t, testreports.Summary{Tests: 5, Failures: 1, Skipped: 1, Seconds: 4, Coverage: -1}, *summary)
+	assert.Equal(t, 3, summary.Passed())
+	assert.False(t, summary.Succeeded())
+}
+
+func TestFacts(t *testing.T) {
+	t.Parallel()
+	jxClient := fake.NewSimpleClientset()
+	ns := "jx"
+
+	save := func(branch string, build string, summary testreports.Summary) {
+		_, err := testreports.SaveFact(jxClient, ns, testreports.NewFact("MyOrg", "myrepo", branch, build, &summary))
+		require.NoError(t, err)
+	}
+	save("master", "9", testreports.Summary{Tests: 10, Failures: 1, Coverage: 70})
+	save("master", "10", testreports.Summary{Tests: 12, Coverage: 75})
+	save("master", "2", testreports.Summary{Tests: 8, Coverage: -1})
+	save("PR-3", "1", testreports.Summary{Tests: 13, Failures: 2, Skipped: 1, Coverage: 72})
+	// updates the existing Fact
+	save("master", "10", testreports.Summary{Tests: 12, Skipped: 1, Coverage: 76})
+
+	facts, err := testreports.ListFacts(jxClient, ns, "MyOrg", "myrepo", "master")
+	require.NoError(t, err)
+	builds := []string{}
+	for _, f := range facts {
+		builds = append(builds, f.Labels[testreports.LabelBuildNumber])
+	}
+	assert.Equal(t, []string{"2", "9", "10"}, builds)
+	assert.Equal(t, "myorg-myrepo-master-10", facts[2].Spec.SubjectReference.Name)
+
+	facts, err = testreports.ListFacts(jxClient, ns, "", "", "")
+	require.NoError(t, err)
+	assert.Len(t, facts, 4)
+
+	latest, err := testreports.PreviousSummary(jxClient, ns, "MyOrg", "myrepo", "master", "")
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.Equal(t, testreports.Summary{Tests: 12, Skipped: 1, Coverage: 76}, *latest)
+
+	previous, err := testreports.PreviousSummary(jxClient, ns, "MyOrg", "myrepo", "master", "10")
+	require.NoError(t, err)
+	require.NotNil(t, previous)
+	assert.Equal(t, testreports.Summary{Tests: 10, Failures: 1, Coverage: 70}, *previous)
+
+	first, err := testreports.PreviousSummary(jxClient, ns, "MyOrg", "myrepo", "master", "2")
+	require.NoError(t, err)
+	assert.Nil(t, first)
+
+	current := &testreports.Summary{Tests: 13, Failures: 2, Skipped: 1, Coverage: 72}
+	assert.Equal(t, "-1 passed, +2 failed, -4% coverage", testreports.Trend(current, latest))
+	assert.Equal(t, "no change", testreports.Trend(latest, latest))
+	assert.Equal(t, "", testreports.Trend(current, nil))
+
+	markdown := testreports.Markdown(current, latest, "master")
+	assert.Contains(t, markdown, ":x: **2 tests failed**")
+	assert.Contains(t, markdown, "| This Pull Request | 13 | 10 | 2 | 1 | 72% |")
+	assert.Contains(t, markdown, "| master | 12 | 11 | 0 | 1 | 76% |")
+	assert.Contains(t, markdown, "Compared to master: -1 passed, +2 failed, -4% coverage")
+
+	markdown = testreports.Markdown(latest, nil, "master")
+	assert.Contains(t, markdown, ":heavy_check_mark: **All tests passed**")
+	assert.NotContains(t, markdown, "Compared to")
+}
@@ -0,0 +1,365 @@
+// Package tracing traces the major operations of jx such as chart installs, waits, git clones and cloud API calls as
+// OpenTelemetry spans which are exported to an OTLP/HTTP endpoint.
+//
+// The spans are exported using the OTLP/HTTP JSON encoding rather than the OpenTelemetry SDK as the SDK and its OTLP
+// exporter require newer versions of Go, protobuf and gRPC than the Kubernetes and cloud provider clients jx depends
+// on allow. The API mirrors the SDK, passing the parent span via a context.Context, so that it can be replaced by the
+// SDK once those dependencies are upgraded.
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// EnvOTLPEndpoint the standard OpenTelemetry environment variable of the base URL of the OTLP endpoint
+	EnvOTLPEndpoint = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	// EnvOTLPTracesEndpoint the standard OpenTelemetry environment variable of the full URL traces are exported to
+	EnvOTLPTracesEndpoint = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+	// EnvOTLPHeaders the standard OpenTelemetry environment variable of the headers sent to the OTLP endpoint as
+	// comma separated key=value pairs
+	EnvOTLPHeaders = "OTEL_EXPORTER_OTLP_HEADERS"
+	// EnvServiceName the standard OpenTelemetry environment variable of the name of the traced service
+	EnvServiceName = "OTEL_SERVICE_NAME"
+
+	// DefaultServiceName the service name of the spans
+	DefaultServiceName = "jx"
+	// TracesPath the path of the OTLP/HTTP traces endpoint
+	TracesPath = "/v1/traces"
+
+	exportTimeout = 5 * time.Second
+
+	// OTLP span kind and status codes
+	spanKindInternal = 1
+	statusCodeOK     = 1
+	statusCodeError  = 2
+)
+
+// Config the configuration of the exporter of the spans
+type Config struct {
+	// Endpoint the full URL of the OTLP/HTTP traces endpoint
+	Endpoint string
+	// Headers the headers sent with the spans such as an API key
+	Headers map[string]string
+	// ServiceName the name of the service the spans are reported for
+	ServiceName string
+}
+
+// Attribute a key value pair describing a span
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// String creates a string attribute
+func String(key string, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int creates an integer attribute
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Span a timed operation such as a chart install or waiting for a deployment. A span is the child of the span in the
+// context it is started with or, if there is none, of the root span of the trace which is the span of the jx command.
+// A nil Span is returned when tracing is not configured and all its methods do nothing
+type Span struct {
+	tracer     *tracer
+	traceID    string
+	spanID     string
+	parentID   string
+	name       string
+	start      time.Time
+	end        time.Time
+	attributes []Attribute
+	err        error
+}
+
+type tracer struct {
+	lock     sync.Mutex
+	config   Config
+	traceID  string
+	root     *Span
+	finished []*Span
+}
+
+type spanContextKey struct{}
+
+var (
+	globalLock   sync.Mutex
+	globalTracer *tracer
+)
+
+// ConfigFromEnv returns the configuration from the standard OpenTelemetry environment variables. The given endpoint,
+// e.g. from a flag, takes precedence and is the base URL of the OTLP endpoint the traces path is added to
+func ConfigFromEnv(endpoint string) Config {
+	config := Config{
+		Headers:     parseHeaders(os.Getenv(EnvOTLPHeaders)),
+		ServiceName: os.Getenv(EnvServiceName),
+	}
+	switch {
+	case endpoint != "":
+		config.Endpoint = TracesURL(endpoint)
+	case os.Getenv(EnvOTLPTracesEndpoint) != "":
+		config.Endpoint = os.Getenv(EnvOTLPTracesEndpoint)
+	case os.Getenv(EnvOTLPEndpoint) != "":
+		config.Endpoint = TracesURL(os.Getenv(EnvOTLPEndpoint))
+	}
+	if config.ServiceName == "" {
+		config.ServiceName = DefaultServiceName
+	}
+	return config
+}
+
+// TracesURL returns the URL of the traces endpoint of the given base URL of an OTLP endpoint
+func TracesURL(endpoint string) string {
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	if strings.HasSuffix(endpoint, TracesPath) {
+		return endpoint
+	}
+	return endpoint + TracesPath
+}
+
+// Configure enables tracing if the configuration has an endpoint, otherwise tracing is disabled. Any spans which were
+// not exported yet are discarded
+func Configure(config Config) {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+	if config.Endpoint == "" {
+		globalTracer = nil
+		return
+	}
+	globalTracer = &tracer{
+		config:  config,
+		traceID: randomID(16),
+	}
+}
+
+// Enabled returns true if tracing is configured
+func Enabled() bool {
+	return currentTracer() != nil
+}
+
+func currentTracer() *tracer {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+	return globalTracer
+}
+
+// StartSpan starts a span which is a child of the span in the context, or of the root span if the context has no span.
+// The first span started without a parent becomes the root span. It returns the context containing the new span to
+// pass to the operations it runs, and a nil span if tracing is not configured
+func StartSpan(ctx context.Context, name string, attributes ...Attribute) (context.Context, *Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	t := currentTracer()
+	if t == nil {
+		return ctx, nil
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	span := &Span{
+		tracer:     t,
+		traceID:    t.traceID,
+		spanID:     randomID(8),
+		name:       name,
+		start:      time.Now(),
+		attributes: attributes,
+	}
+	parent := SpanFromContext(ctx)
+	if parent == nil || parent.tracer != t {
+		parent = t.root
+	}
+	if parent != nil {
+		span.parentID = parent.spanID
+	} else {
+		t.root = span
+	}
+	return ContextWithSpan(ctx, span), span
+}
+
+// ContextWithSpan returns a copy of the context containing the span so that spans started with it are its children
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	if span == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, spanContextKey{}, span)
+}
+
+// SpanFromContext returns the span in the context or nil if there is none
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanContextKey{}).(*Span)
+	return span
+}
+
+// SetAttributes adds attributes to the span
+func (s *Span) SetAttributes(attributes ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.tracer.lock.Lock()
+	defer s.tracer.lock.Unlock()
+	s.attributes = append(s.attributes, attributes...)
+}
+
+// End ends the span marking it as failed if the operation failed with the given error. Only the type of the error is
+// recorded as error messages may contain URLs, names or secrets
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	t := s.tracer
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if !s.end.IsZero() {
+		return
+	}
+	s.end = time.Now()
+	s.err = err
+	if t.root == s {
+		t.root = nil
+	}
+	t.finished = append(t.finished, s)
+}
+
+// Flush exports the finished spans to the OTLP endpoint
+func Flush() error {
+	t := currentTracer()
+	if t == nil {
+		return nil
+	}
+	t.lock.Lock()
+	spans := t.finished
+	t.finished = nil
+	t.lock.Unlock()
+	if len(spans) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(t.request(spans))
+	if err != nil {
+		return errors.Wrap(err, "marshalling the spans")
+	}
+	req, err := http.NewRequest(http.MethodPost, t.config.Endpoint, bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrapf(err, "creating the request to %s", t.config.Endpoint)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range t.config.Headers {
+		req.Header.Set(k, v)
+	}
+	client := &http.Client{Timeout: exportTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "exporting %d spans to %s", len(spans), t.config.Endpoint)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("exporting %d spans to %s returned status %s", len(spans), t.config.Endpoint, resp.Status)
+	}
+	return nil
+}
+
+// request returns the OTLP/HTTP JSON encoding of an ExportTraceServiceRequest of the spans
+func (t *tracer) request(spans []*Span) map[string]interface{} {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	otlpSpans := []map[string]interface{}{}
+	for _, s := range spans {
+		span := map[string]interface{}{
+			"traceId":           s.traceID,
+			"spanId":            s.spanID,
+			"name":              s.name,
+			"kind":              spanKindInternal,
+			"startTimeUnixNano": strconv.FormatInt(s.start.UnixNano(), 10),
+			"endTimeUnixNano":   strconv.FormatInt(s.end.UnixNano(), 10),
+			"attributes":        otlpAttributes(s.attributes),
+		}
+		if s.parentID != "" {
+			span["parentSpanId"] = s.parentID
+		}
+		if s.err != nil {
+			span["status"] = map[string]interface{}{"code": statusCodeError, "message": ErrorType(s.err)}
+		} else {
+			span["status"] = map[string]interface{}{"code": statusCodeOK}
+		}
+		otlpSpans = append(otlpSpans, span)
+	}
+	return map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": otlpAttributes([]Attribute{String("service.name", t.config.ServiceName)}),
+				},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{
+						"scope": map[string]interface{}{"name": DefaultServiceName},
+						"spans": otlpSpans,
+					},
+				},
+			},
+		},
+	}
+}
+
+// ErrorType returns the type of the cause of the error which is recorded in the status of a failed span instead of its
+// message
+func ErrorType(err error) string {
+	return fmt.Sprintf("%T", errors.Cause(err))
+}
+
+func otlpAttributes(attributes []Attribute) []interface{} {
+	answer := []interface{}{}
+	for _, a := range attributes {
+		var value map[string]interface{}
+		switch v := a.Value.(type) {
+		case int:
+			// 64 bit integers are encoded as strings in OTLP JSON
+			value = map[string]interface{}{"intValue": strconv.Itoa(v)}
+		case bool:
+			value = map[string]interface{}{"boolValue": v}
+		default:
+			value = map[string]interface{}{"stringValue": fmt.Sprintf("%v", v)}
+		}
+		answer = append(answer, map[string]interface{}{"key": a.Key, "value": value})
+	}
+	return answer
+}
+
+func parseHeaders(text string) map[string]string {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(text, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) == 2 && strings.TrimSpace(kv[0]) != "" {
+			headers[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+	}
+	return headers
+}
+
+func randomID(size int) string {
+	data := make([]byte, size)
+	_, err := rand.Read(data)
+	if err != nil {
+		// fall back to the time which is unique enough for the spans of a single command
+		return fmt.Sprintf("%0*x", size*2, time.Now().UnixNano())[:size*2]
+	}
+	return hex.EncodeToString(data)
+}
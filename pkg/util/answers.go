@@ -0,0 +1,188 @@
+package util
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"gopkg.in/AlecAivazis/survey.v1"
+	"gopkg.in/AlecAivazis/survey.v1/core"
+)
+
+// AnswersEnvVar the environment variable pointing at the answers file used for the survey prompts
+const AnswersEnvVar = "JX_ANSWERS"
+
+var (
+	answersLock sync.Mutex
+	answers     Answers
+
+	nonKeyCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// Answers maps the keys of survey prompts to the values used to answer them non-interactively
+type Answers map[string]interface{}
+
+// LoadAnswers loads the answers from the given YAML file
+func LoadAnswers(fileName string) (Answers, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the answers file %s", fileName)
+	}
+	loaded := Answers{}
+	err = yaml.Unmarshal(data, &loaded)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the answers file %s", fileName)
+	}
+	return loaded, nil
+}
+
+// SetAnswers configures the answers used by AskOne instead of prompting. Passing nil restores interactive prompts
+func SetAnswers(a Answers) {
+	answersLock.Lock()
+	defer answersLock.Unlock()
+	answers = a
+}
+
+func currentAnswers() Answers {
+	answersLock.Lock()
+	defer answersLock.Unlock()
+	return answers
+}
+
+// AnswerKey returns the key of a prompt message in the answers file: the message in lower case with every run of
+// other characters than letters and digits replaced by a dash, e.g. 'Pick the cluster name:' is pick-the-cluster-name
+func AnswerKey(message string) string {
+	return strings.Trim(nonKeyCharacters.ReplaceAllString(strings.ToLower(message), "-"), "-")
+}
+
+// AskOne asks the question of the prompt unless an answers file is configured in which case the answer is taken from
+// the file. A prompt without an answer in the file fails rather than blocking on input which never comes
+func AskOne(p survey.Prompt, response interface{}, v survey.Validator, opts ...survey.AskOpt) error {
+	a := currentAnswers()
+	if a == nil {
+		return survey.AskOne(p, response, v, opts...)
+	}
+	answer, err := a.answer(p, v)
+	if err != nil {
+		return err
+	}
+	return core.WriteAnswer(response, "", answer)
+}
+
+// Ask asks the questions unless an answers file is configured in which case the answers are taken from the file
+// like AskOne and written to the fields of the response named after the questions
+func Ask(qs []*survey.Question, response interface{}, opts ...survey.AskOpt) error {
+	a := currentAnswers()
+	if a == nil {
+		return survey.Ask(qs, response, opts...)
+	}
+	for _, q := range qs {
+		answer, err := a.answer(q.Prompt, q.Validate)
+		if err != nil {
+			return err
+		}
+		err = core.WriteAnswer(response, q.Name, answer)
+		if err != nil {
+			return errors.Wrapf(err, "writing the answer of %s", q.Name)
+		}
+	}
+	return nil
+}
+
+// answer returns the answer of the prompt from the answers
+func (a Answers) answer(p survey.Prompt, v survey.Validator) (interface{}, error) {
+	message := promptMessage(p)
+	key := AnswerKey(message)
+	value, ok := a[key]
+	if !ok {
+		// allow the exact message to be used as the key too
+		value, ok = a[message]
+	}
+	if !ok {
+		return nil, errors.Errorf("no answer for the prompt '%s' in the answers file, add an answer with the key: %s", message, key)
+	}
+	answer, err := promptAnswer(p, value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid answer for the key %s", key)
+	}
+	if v != nil {
+		err = v(answer)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid answer for the key %s", key)
+		}
+	}
+	return answer, nil
+}
+
+func promptMessage(p survey.Prompt) string {
+	switch prompt := p.(type) {
+	case *survey.Input:
+		return prompt.Message
+	case *survey.Password:
+		return prompt.Message
+	case *survey.Confirm:
+		return prompt.Message
+	case *survey.Select:
+		return prompt.Message
+	case *survey.MultiSelect:
+		return prompt.Message
+	case *survey.Editor:
+		return prompt.Message
+	case *survey.Multiline:
+		return prompt.Message
+	default:
+		return fmt.Sprintf("%T", p)
+	}
+}
+
+// promptAnswer converts the value from the answers file into the type of answer the prompt returns
+func promptAnswer(p survey.Prompt, value interface{}) (interface{}, error) {
+	switch prompt := p.(type) {
+	case *survey.Confirm:
+		switch b := value.(type) {
+		case bool:
+			return b, nil
+		case string:
+			return strconv.ParseBool(b)
+		default:
+			return nil, errors.Errorf("expected a boolean but got %v", value)
+		}
+	case *survey.Select:
+		answer := answerString(value)
+		if StringArrayIndex(prompt.Options, answer) < 0 {
+			return nil, errors.Errorf("%s is not one of the options %s", answer, strings.Join(prompt.Options, ", "))
+		}
+		return answer, nil
+	case *survey.MultiSelect:
+		var picked []string
+		switch values := value.(type) {
+		case []interface{}:
+			for _, v := range values {
+				picked = append(picked, answerString(v))
+			}
+		default:
+			picked = append(picked, answerString(value))
+		}
+		for _, answer := range picked {
+			if StringArrayIndex(prompt.Options, answer) < 0 {
+				return nil, errors.Errorf("%s is not one of the options %s", answer, strings.Join(prompt.Options, ", "))
+			}
+		}
+		return picked, nil
+	default:
+		return answerString(value), nil
+	}
+}
+
+func answerString(value interface{}) string {
+	if f, ok := value.(float64); ok {
+		// YAML numbers are parsed as floats so avoid answering 3 with 3.000000
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,95 @@
+// +build unit
+
+package util_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/AlecAivazis/survey.v1"
+)
+
+const testAnswers = `
+pick-the-cluster-name: mycluster
+do-you-want-to-install-the-ingress-controller: true
+"Pick the providers:":
+- gke
+- eks
+number-of-nodes: 3
+`
+
+func TestAskOneFromAnswersFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-answers")
+	require.NoError(t, err)
+	fileName := filepath.Join(dir, "answers.yaml")
+	err = ioutil.WriteFile(fileName, []byte(testAnswers), util.DefaultWritePermissions)
+	require.NoError(t, err)
+
+	answers, err := util.LoadAnswers(fileName)
+	require.NoError(t, err)
+	util.SetAnswers(answers)
+	defer util.SetAnswers(nil)
+
+	name, err := util.PickName([]string{"other", "mycluster"}, "Pick the cluster name:", "", util.IOFileHandles{})
+	require.NoError(t, err)
+	assert.Equal(t, "mycluster", name)
+
+	install := false
+	err = util.AskOne(&survey.Confirm{Message: "Do you want to install the ingress controller?"}, &install, nil)
+	require.NoError(t, err)
+	assert.True(t, install)
+
+	providers, err := util.PickNames([]string{"aks", "eks", "gke"}, "Pick the providers:", "", util.IOFileHandles{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gke", "eks"}, providers)
+
+	nodes, err := util.PickValue("Number of nodes", "1", true, "", util.IOFileHandles{})
+	require.NoError(t, err)
+	assert.Equal(t, "3", nodes)
+
+	_, err = util.PickValue("Git user name?", "", true, "", util.IOFileHandles{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "git-user-name")
+
+	_, err = util.PickName([]string{"a", "b"}, "Number of nodes", "", util.IOFileHandles{})
+	assert.Error(t, err, "an answer which is not one of the options should fail")
+}
+
+func TestAskFromAnswersFile(t *testing.T) {
+	util.SetAnswers(util.Answers{"change-namespace": "jx-staging", "group": "com.example"})
+	defer util.SetAnswers(nil)
+
+	qs := []*survey.Question{
+		{
+			Name:   "namespace",
+			Prompt: &survey.Select{Message: "Change namespace: ", Options: []string{"jx", "jx-staging"}},
+		},
+		{
+			Name:     "groupId",
+			Prompt:   &survey.Input{Message: "Group"},
+			Validate: survey.Required,
+		},
+	}
+	answers := struct {
+		Namespace string
+		GroupID   string `survey:"groupId"`
+	}{}
+	err := util.Ask(qs, &answers)
+	require.NoError(t, err)
+	assert.Equal(t, "jx-staging", answers.Namespace)
+	assert.Equal(t, "com.example", answers.GroupID)
+
+	qs = append(qs, &survey.Question{Name: "artifactId", Prompt: &survey.Input{Message: "Artifact"}})
+	err = util.Ask(qs, &answers)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "artifact")
+}
+
+func TestAnswerKey(t *testing.T) {
+	assert.Equal(t, "pick-the-cluster-name", util.AnswerKey("Pick the cluster name: "))
+	assert.Equal(t, "do-you-want-to-use-gke-s-default-domain", util.AnswerKey("Do you want to use GKE's default domain?"))
+}
@@ -1,13 +1,17 @@
 package util
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/jenkins-x/jx/v2/pkg/tracing"
 	"github.com/pkg/errors"
 
 	"github.com/cenkalti/backoff"
@@ -26,6 +30,8 @@ type Command struct {
 	Err                io.Writer
 	In                 io.Reader
 	Env                map[string]string
+	// Context the context containing the tracing span the span of the command is a child of
+	Context context.Context
 }
 
 // CommandError is the error object encapsulating an error from a Command
@@ -207,7 +213,30 @@ func (c *Command) String() string {
 	return builder.String()
 }
 
-func (c *Command) run() (string, error) {
+// subCommandArgPattern matches arguments which are sub commands like the install of helm install rather than values
+// which may be sensitive such as URLs with credentials
+var subCommandArgPattern = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// spanName returns the name of the tracing span of the command which only includes the sub command and never values
+func (c *Command) spanName() string {
+	name := filepath.Base(c.Name)
+	if len(c.Args) > 0 && subCommandArgPattern.MatchString(c.Args[0]) {
+		name += " " + c.Args[0]
+	}
+	return name
+}
+
+func (c *Command) run() (text string, err error) {
+	_, span := tracing.StartSpan(c.Context, c.spanName(), tracing.String("command.name", filepath.Base(c.Name)))
+	defer func() {
+		// only record the exit status as the error of a command includes its arguments which may contain secrets
+		if commandErr, ok := err.(CommandError); ok {
+			span.End(commandErr.cause)
+			return
+		}
+		span.End(err)
+	}()
+
 	e := exec.Command(c.Name, c.Args...) // #nosec
 	if c.Dir != "" {
 		e.Dir = c.Dir
@@ -243,9 +272,6 @@ func (c *Command) run() (string, error) {
 		e.Stdin = c.In
 	}
 
-	var text string
-	var err error
-
 	if c.Out != nil {
 		err := e.Run()
 		if err != nil {
@@ -270,7 +296,7 @@ func (c *Command) run() (string, error) {
 	return text, err
 }
 
-//PathWithBinary Returns the path to be used to execute a binary from, takes the form JX_HOME/bin:mvnBinDir:customPaths
+// PathWithBinary Returns the path to be used to execute a binary from, takes the form JX_HOME/bin:mvnBinDir:customPaths
 func PathWithBinary(customPaths ...string) string {
 	existingEnvironmentPath := os.Getenv("PATH")
 
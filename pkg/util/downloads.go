@@ -226,6 +226,32 @@ func GetTagsFromGithub(githubOwner, githubRepo string) ([]*github.RepositoryTag,
 	return tags, nil
 }
 
+// GetReleasesFromGitHub gets the most recent releases, including pre-releases, of a specific github repo
+func GetReleasesFromGitHub(githubOwner, githubRepo string) ([]*github.RepositoryRelease, error) {
+	client, _, _, _ := preamble()
+	releases, resp, err := client.Repositories.ListReleases(context.Background(), githubOwner, githubRepo, &github.ListOptions{PerPage: 100})
+	if resp != nil {
+		defer resp.Body.Close() //nolint:errcheck
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing releases for github.com/%s/%s", githubOwner, githubRepo)
+	}
+	return releases, nil
+}
+
+// GetReleaseByTagFromGitHub gets the release with the given tag of a specific github repo
+func GetReleaseByTagFromGitHub(githubOwner, githubRepo, tag string) (*github.RepositoryRelease, error) {
+	client, _, _, _ := preamble()
+	release, resp, err := client.Repositories.GetReleaseByTag(context.Background(), githubOwner, githubRepo, tag)
+	if resp != nil {
+		defer resp.Body.Close() //nolint:errcheck
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting release %s for github.com/%s/%s", tag, githubOwner, githubRepo)
+	}
+	return release, nil
+}
+
 func preamble() (*github.Client, *github.RepositoryRelease, *github.Response, error) {
 	if githubClient == nil {
 		token := os.Getenv("GH_TOKEN")
@@ -0,0 +1,85 @@
+package util
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// LogFieldCommand the log field of the jx command being run
+	LogFieldCommand = "command"
+	// LogFieldNamespace the log field of the namespace the command operates on
+	LogFieldNamespace = "namespace"
+	// LogFieldProvider the log field of the Kubernetes provider the command operates on
+	LogFieldProvider = "provider"
+	// LogFieldOperation the log field of the operation the command is currently performing such as a step of an
+	// install or boot or a chart install
+	LogFieldOperation = "operation"
+)
+
+var (
+	logFieldsOnce sync.Once
+	logFields     = &logFieldsHook{fields: logrus.Fields{}}
+)
+
+// logFieldsHook adds the fields describing the running command to each log entry. The text format ignores fields so
+// they only appear in structured formats such as JSON
+type logFieldsHook struct {
+	lock   sync.RWMutex
+	fields logrus.Fields
+}
+
+// Levels returns the levels the hook applies to
+func (h *logFieldsHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire adds the fields to the entry without overriding the fields of the entry itself
+func (h *logFieldsHook) Fire(entry *logrus.Entry) error {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	if len(h.fields) == 0 {
+		return nil
+	}
+	// the entry is a copy but its data is shared with the logger so lets not modify it
+	data := logrus.Fields{}
+	for k, v := range h.fields {
+		data[k] = v
+	}
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	entry.Data = data
+	return nil
+}
+
+// SetLogField attaches the field to all subsequent log entries. An empty value removes the field
+func SetLogField(key string, value string) {
+	setLogField(key, value)
+}
+
+// StartLogOperation attaches the operation to all subsequent log entries until the returned function is called, which
+// restores the operation it is nested in, if any
+func StartLogOperation(operation string) func() {
+	outer := setLogField(LogFieldOperation, operation)
+	return func() {
+		setLogField(LogFieldOperation, outer)
+	}
+}
+
+// setLogField sets the field returning its previous value
+func setLogField(key string, value string) string {
+	logFieldsOnce.Do(func() {
+		logrus.AddHook(logFields)
+	})
+	logFields.lock.Lock()
+	defer logFields.lock.Unlock()
+	previous, _ := logFields.fields[key].(string)
+	if value == "" {
+		delete(logFields.fields, key)
+	} else {
+		logFields.fields[key] = value
+	}
+	return previous
+}
@@ -22,7 +22,7 @@ func PickValue(message string, defaultValue string, required bool, help string,
 		validator = nil
 	}
 	surveyOpts := survey.WithStdio(handles.In, handles.Out, handles.Err)
-	err := survey.AskOne(prompt, &answer, validator, surveyOpts)
+	err := AskOne(prompt, &answer, validator, surveyOpts)
 	if err != nil {
 		return "", err
 	}
@@ -38,7 +38,7 @@ func PickPassword(message string, help string, handles IOFileHandles) (string, e
 	}
 	validator := survey.Required
 	surveyOpts := survey.WithStdio(handles.In, handles.Out, handles.Err)
-	err := survey.AskOne(prompt, &answer, validator, surveyOpts)
+	err := AskOne(prompt, &answer, validator, surveyOpts)
 	if err != nil {
 		return "", err
 	}
@@ -59,7 +59,7 @@ func PickNameWithDefault(names []string, message string, defaultValue string, he
 			Default: defaultValue,
 		}
 		surveyOpts := survey.WithStdio(handles.In, handles.Out, handles.Err)
-		err := survey.AskOne(prompt, &name, nil, surveyOpts)
+		err := AskOne(prompt, &name, nil, surveyOpts)
 		if err != nil {
 			return "", err
 		}
@@ -82,7 +82,7 @@ func PickRequiredNameWithDefault(names []string, message string, defaultValue st
 			Help:    help,
 		}
 		surveyOpts := survey.WithStdio(handles.In, handles.Out, handles.Err)
-		err := survey.AskOne(prompt, &name, survey.Required, surveyOpts)
+		err := AskOne(prompt, &name, survey.Required, surveyOpts)
 		if err != nil {
 			return "", err
 		}
@@ -115,7 +115,7 @@ func PickNamesWithDefaults(names []string, defaults []string, message string, he
 			Help:    help,
 		}
 		surveyOpts := survey.WithStdio(handles.In, handles.Out, handles.Err)
-		err := survey.AskOne(prompt, &picked, nil, surveyOpts)
+		err := AskOne(prompt, &picked, nil, surveyOpts)
 		if err != nil {
 			return picked, err
 		}
@@ -154,7 +154,7 @@ func SelectNames(names []string, message string, selectAll bool, help string, ha
 		prompt.Default = names
 	}
 	surveyOpts := survey.WithStdio(handles.In, handles.Out, handles.Err)
-	err := survey.AskOne(prompt, &answer, nil, surveyOpts)
+	err := AskOne(prompt, &answer, nil, surveyOpts)
 	return answer, err
 }
 
@@ -167,7 +167,7 @@ func Confirm(message string, defaultValue bool, help string, handles IOFileHandl
 		Help:    help,
 	}
 	surveyOpts := survey.WithStdio(handles.In, handles.Out, handles.Err)
-	err := survey.AskOne(prompt, &answer, nil, surveyOpts)
+	err := AskOne(prompt, &answer, nil, surveyOpts)
 	if err != nil {
 		return false, err
 	}
@@ -0,0 +1,28 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+// LatestSemverVersion returns the highest release version of the tags ignoring the tags which are not semantic
+// versions and the pre-release versions. The version is returned without any v prefix of the tag
+func LatestSemverVersion(tags []string) (string, error) {
+	var latest *semver.Version
+	for _, tag := range tags {
+		version, err := semver.Parse(strings.TrimPrefix(strings.TrimSpace(tag), "v"))
+		if err != nil || len(version.Pre) > 0 {
+			continue
+		}
+		if latest == nil || version.GT(*latest) {
+			v := version
+			latest = &v
+		}
+	}
+	if latest == nil {
+		return "", fmt.Errorf("none of the %d tags is a semantic version", len(tags))
+	}
+	return latest.String(), nil
+}
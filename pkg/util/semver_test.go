@@ -0,0 +1,22 @@
+// +build unit
+
+package util_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatestSemverVersion(t *testing.T) {
+	t.Parallel()
+
+	version, err := util.LatestSemverVersion([]string{"v1.2.0", "latest", "1.10.0", "v2.0.0-rc1", "1.9.3"})
+	require.NoError(t, err)
+	assert.Equal(t, "1.10.0", version)
+
+	_, err = util.LatestSemverVersion([]string{"latest", "master"})
+	assert.Error(t, err)
+}
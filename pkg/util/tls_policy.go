@@ -0,0 +1,215 @@
+package util
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// TLSMinVersionEnvVar the environment variable of the minimum TLS version of the HTTPS connections
+	TLSMinVersionEnvVar = "JX_TLS_MIN_VERSION"
+	// TLSCipherSuitesEnvVar the environment variable of the comma separated cipher suites of the HTTPS connections
+	TLSCipherSuitesEnvVar = "JX_TLS_CIPHER_SUITES"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// tlsCipherSuites the cipher suites which can be configured. The TLS 1.3 cipher suites are not configurable
+var tlsCipherSuites = map[string]uint16{
+	"TLS_RSA_WITH_AES_128_CBC_SHA":                  tls.TLS_RSA_WITH_AES_128_CBC_SHA,
+	"TLS_RSA_WITH_AES_256_CBC_SHA":                  tls.TLS_RSA_WITH_AES_256_CBC_SHA,
+	"TLS_RSA_WITH_AES_128_GCM_SHA256":               tls.TLS_RSA_WITH_AES_128_GCM_SHA256,
+	"TLS_RSA_WITH_AES_256_GCM_SHA384":               tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA":          tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA,
+	"TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA":          tls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA,
+	"TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA":            tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,
+	"TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA":            tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
+	"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256":         tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256":       tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384":         tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384":       tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256":   tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+	"TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256": tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+}
+
+// TLSPolicy the minimum TLS version and the cipher suites of the HTTPS connections of the shared HTTP clients of the
+// CLI and of the HTTPS it serves
+type TLSPolicy struct {
+	// MinVersion the minimum TLS version such as 1.2. Empty uses the default of Go
+	MinVersion string
+	// CipherSuites the names of the TLS 1.2 cipher suites such as TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256. Empty uses
+	// the defaults of Go
+	CipherSuites []string
+}
+
+// tlsPolicy the policy in use which defaults to the restricted policy of a tls_strict build
+var tlsPolicy = defaultTLSPolicy
+
+// TLSPolicyFromEnv returns the TLS policy of the JX_TLS_MIN_VERSION and JX_TLS_CIPHER_SUITES environment variables
+// falling back to the default policy of the build
+func TLSPolicyFromEnv() TLSPolicy {
+	policy := defaultTLSPolicy
+	if value := os.Getenv(TLSMinVersionEnvVar); value != "" {
+		policy.MinVersion = value
+	}
+	if value := os.Getenv(TLSCipherSuitesEnvVar); value != "" {
+		policy.CipherSuites = nil
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				policy.CipherSuites = append(policy.CipherSuites, name)
+			}
+		}
+	}
+	return policy
+}
+
+// Config returns the TLS configuration of the policy
+func (p TLSPolicy) Config() (*tls.Config, error) {
+	config := &tls.Config{}
+	if p.MinVersion != "" {
+		version, ok := tlsVersions[strings.TrimPrefix(strings.ToLower(p.MinVersion), "tls")]
+		if !ok {
+			return nil, fmt.Errorf("unknown TLS version %s, must be one of %s", p.MinVersion, strings.Join(sortedKeys(tlsVersions), ", "))
+		}
+		config.MinVersion = version
+	}
+	for _, name := range p.CipherSuites {
+		suite, ok := tlsCipherSuites[strings.ToUpper(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown TLS cipher suite %s, must be one of %s", name, strings.Join(sortedKeys(tlsCipherSuites), ", "))
+		}
+		config.CipherSuites = append(config.CipherSuites, suite)
+	}
+	if len(config.CipherSuites) > 0 && config.MinVersion == tls.VersionTLS13 {
+		return nil, fmt.Errorf("the cipher suites of TLS %s cannot be configured", p.MinVersion)
+	}
+	return config, nil
+}
+
+// SetTLSPolicy validates the policy and applies it to the HTTPS connections of the clients of GetClient,
+// GetClientWithTimeout and of any client using http.DefaultTransport. Clients with their own transports are only covered
+// if they pass them to ApplyTLSPolicy. The servers use the policy via ServerTLSConfig. A tls_strict build rejects
+// policies weaker than its default policy
+func SetTLSPolicy(policy TLSPolicy) error {
+	_, err := policy.Config()
+	if err != nil {
+		return errors.Wrap(err, "invalid TLS policy")
+	}
+	err = checkEnforcedTLSPolicy(policy, enforcedTLSPolicy)
+	if err != nil {
+		return errors.Wrap(err, "invalid TLS policy")
+	}
+	tlsPolicy = policy
+	for _, rt := range []http.RoundTripper{jxDefaultTransport, http.DefaultTransport} {
+		ApplyTLSPolicy(rt)
+	}
+	return nil
+}
+
+// checkEnforcedTLSPolicy returns an error if the policy allows an older TLS version or cipher suites which the enforced
+// policy, if any, does not
+func checkEnforcedTLSPolicy(policy TLSPolicy, enforced *TLSPolicy) error {
+	if enforced == nil {
+		return nil
+	}
+	config, err := policy.Config()
+	if err != nil {
+		return err
+	}
+	enforcedConfig, err := enforced.Config()
+	if err != nil {
+		return err
+	}
+	if config.MinVersion < enforcedConfig.MinVersion {
+		return fmt.Errorf("the minimum TLS version cannot be lower than %s in this build", enforced.MinVersion)
+	}
+	if len(enforcedConfig.CipherSuites) == 0 || config.MinVersion == tls.VersionTLS13 {
+		return nil
+	}
+	if len(config.CipherSuites) == 0 {
+		return fmt.Errorf("the TLS cipher suites must be a subset of %s in this build", strings.Join(enforced.CipherSuites, ", "))
+	}
+	for i, suite := range config.CipherSuites {
+		if !containsUint16(enforcedConfig.CipherSuites, suite) {
+			return fmt.Errorf("the TLS cipher suite %s is not allowed in this build, it must be one of %s", policy.CipherSuites[i], strings.Join(enforced.CipherSuites, ", "))
+		}
+	}
+	return nil
+}
+
+func containsUint16(values []uint16, value uint16) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyTLSPolicy applies the TLS policy in use to the transport if it is an http.Transport such as the transports of
+// clients which need custom certificates
+func ApplyTLSPolicy(rt http.RoundTripper) {
+	transport, ok := rt.(*http.Transport)
+	if !ok || transport == nil {
+		return
+	}
+	config, err := tlsPolicy.Config()
+	if err != nil {
+		return
+	}
+	// leave the TLS configuration unset without a policy so that the transport still negotiates HTTP/2
+	if transport.TLSClientConfig == nil && config.MinVersion == 0 && len(config.CipherSuites) == 0 {
+		return
+	}
+	transport.TLSClientConfig = applyTLSPolicy(transport.TLSClientConfig, config)
+}
+
+// ServerTLSConfig returns the TLS configuration of servers such as the webhook receivers of the controllers which
+// honours the TLS policy in use
+func ServerTLSConfig() *tls.Config {
+	config, err := tlsPolicy.Config()
+	if err != nil {
+		return &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return config
+}
+
+// ListenAndServe serves HTTPS with the TLS policy in use if a certificate and key are given otherwise it serves HTTP
+func ListenAndServe(server *http.Server, certFile string, keyFile string) error {
+	if certFile == "" && keyFile == "" {
+		return server.ListenAndServe()
+	}
+	server.TLSConfig = ServerTLSConfig()
+	return server.ListenAndServeTLS(certFile, keyFile)
+}
+
+func applyTLSPolicy(config *tls.Config, policy *tls.Config) *tls.Config {
+	if config == nil {
+		config = &tls.Config{}
+	} else {
+		config = config.Clone()
+	}
+	config.MinVersion = policy.MinVersion
+	config.CipherSuites = policy.CipherSuites
+	return config
+}
+
+func sortedKeys(m map[string]uint16) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,9 @@
+// +build !tls_strict
+
+package util
+
+// defaultTLSPolicy uses the TLS defaults of Go unless the policy is configured via flags or environment variables
+var defaultTLSPolicy = TLSPolicy{}
+
+// enforcedTLSPolicy any policy can be configured
+var enforcedTLSPolicy *TLSPolicy
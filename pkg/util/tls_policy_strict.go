@@ -0,0 +1,23 @@
+// +build tls_strict
+
+package util
+
+// defaultTLSPolicy restricts the HTTPS connections of a build with the tls_strict tag to TLS 1.2 or later and the
+// AES-GCM cipher suites with ECDHE key exchange.
+//
+// The restriction only applies to the TLS negotiated by the shared HTTP clients of jx, see SetTLSPolicy, and the
+// servers of the controllers. It does not make jx use FIPS 140 validated cryptography. The connections of clients with
+// their own transports, such as the Kubernetes client which uses the TLS configuration of the kubeconfig, and of the
+// git, helm and other binaries jx runs are not restricted
+var defaultTLSPolicy = TLSPolicy{
+	MinVersion: "1.2",
+	CipherSuites: []string{
+		"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+		"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+		"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+		"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+	},
+}
+
+// enforcedTLSPolicy rejects flags and environment variables which would weaken the restricted policy
+var enforcedTLSPolicy = &defaultTLSPolicy
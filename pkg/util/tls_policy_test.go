@@ -0,0 +1,69 @@
+// +build unit
+
+package util
+
+import (
+	"crypto/tls"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSPolicyConfig(t *testing.T) {
+	config, err := TLSPolicy{MinVersion: "1.2", CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}}.Config()
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}, config.CipherSuites)
+
+	config, err = TLSPolicy{MinVersion: "TLS1.3"}.Config()
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), config.MinVersion)
+
+	_, err = TLSPolicy{MinVersion: "1.4"}.Config()
+	assert.Error(t, err)
+	_, err = TLSPolicy{CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}}.Config()
+	assert.Error(t, err)
+	_, err = TLSPolicy{MinVersion: "1.3", CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}}.Config()
+	assert.Error(t, err)
+}
+
+func TestSetTLSPolicy(t *testing.T) {
+	previous := tlsPolicy
+	defer func() {
+		tlsPolicy = previous
+	}()
+
+	err := SetTLSPolicy(TLSPolicy{MinVersion: "1.5"})
+	assert.Error(t, err)
+
+	transport := &http.Transport{}
+	tlsPolicy = TLSPolicy{}
+	ApplyTLSPolicy(transport)
+	assert.Nil(t, transport.TLSClientConfig, "the transport should be unchanged without a policy")
+
+	tlsPolicy = TLSPolicy{MinVersion: "1.2", CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384"}}
+	transport.TLSClientConfig = &tls.Config{ServerName: "example.com"}
+	ApplyTLSPolicy(transport)
+	assert.Equal(t, "example.com", transport.TLSClientConfig.ServerName)
+	assert.Equal(t, uint16(tls.VersionTLS12), transport.TLSClientConfig.MinVersion)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384}, transport.TLSClientConfig.CipherSuites)
+
+	assert.Equal(t, uint16(tls.VersionTLS12), ServerTLSConfig().MinVersion)
+}
+
+func TestCheckEnforcedTLSPolicy(t *testing.T) {
+	enforced := &TLSPolicy{MinVersion: "1.2", CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"}}
+
+	assert.NoError(t, checkEnforcedTLSPolicy(TLSPolicy{}, nil), "any policy is allowed when none is enforced")
+	assert.NoError(t, checkEnforcedTLSPolicy(*enforced, enforced))
+	assert.NoError(t, checkEnforcedTLSPolicy(TLSPolicy{MinVersion: "1.2", CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"}}, enforced))
+	assert.NoError(t, checkEnforcedTLSPolicy(TLSPolicy{MinVersion: "1.3"}, enforced), "the cipher suites of TLS 1.3 are not configurable")
+
+	assert.EqualError(t, checkEnforcedTLSPolicy(TLSPolicy{MinVersion: "1.1", CipherSuites: enforced.CipherSuites}, enforced),
+		"the minimum TLS version cannot be lower than 1.2 in this build")
+	assert.Error(t, checkEnforcedTLSPolicy(TLSPolicy{MinVersion: "1.2"}, enforced), "the default cipher suites of Go are weaker")
+	assert.EqualError(t, checkEnforcedTLSPolicy(TLSPolicy{MinVersion: "1.2", CipherSuites: []string{"TLS_RSA_WITH_AES_128_CBC_SHA"}}, enforced),
+		"the TLS cipher suite TLS_RSA_WITH_AES_128_CBC_SHA is not allowed in this build, it must be one of TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384")
+}
@@ -0,0 +1,71 @@
+package util
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	yamlv2 "gopkg.in/yaml.v2"
+)
+
+const yamlDocumentSeparator = "---\n"
+
+// CanonicalYAML rewrites the given YAML so that map keys are sorted, indentation is consistent, line endings are
+// normalised and the content ends with a single newline. Multi document YAML is supported. Writing the same data
+// twice therefore always produces identical bytes so that diffs in GitOps repositories only show real changes
+func CanonicalYAML(data []byte) ([]byte, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if strings.TrimSpace(text) == "" {
+		return []byte{}, nil
+	}
+	decoder := yamlv2.NewDecoder(strings.NewReader(text))
+	var docs [][]byte
+	for {
+		var doc interface{}
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse YAML")
+		}
+		if doc == nil {
+			continue
+		}
+		out, err := yamlv2.Marshal(doc)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal YAML")
+		}
+		docs = append(docs, out)
+	}
+	var buffer bytes.Buffer
+	for i, doc := range docs {
+		if i > 0 {
+			buffer.WriteString(yamlDocumentSeparator)
+		}
+		buffer.Write(bytes.TrimRight(doc, "\n"))
+		buffer.WriteString("\n")
+	}
+	return buffer.Bytes(), nil
+}
+
+// WriteCanonicalYAMLFile writes the given YAML to the file in canonical form (see CanonicalYAML). The file is left
+// untouched if its content would not change
+func WriteCanonicalYAMLFile(fileName string, data []byte, perm os.FileMode) error {
+	canonical, err := CanonicalYAML(data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to canonicalise YAML for %s", fileName)
+	}
+	existing, err := ioutil.ReadFile(fileName)
+	if err == nil && bytes.Equal(existing, canonical) {
+		return nil
+	}
+	err = ioutil.WriteFile(fileName, canonical, perm)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write file %s", fileName)
+	}
+	return nil
+}
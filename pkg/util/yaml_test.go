@@ -0,0 +1,67 @@
+// +build unit
+
+package util_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalYAML(t *testing.T) {
+	t.Parallel()
+
+	input := "zebra: 1\r\napple:\r\n    version: \"1.0\"\r\n    enabled: true\r\n\r\n\r\n"
+	expected := "apple:\n  enabled: true\n  version: \"1.0\"\nzebra: 1\n"
+
+	actual, err := util.CanonicalYAML([]byte(input))
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(actual))
+
+	again, err := util.CanonicalYAML(actual)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(again), "canonical YAML should be stable")
+}
+
+func TestCanonicalYAMLMultipleDocuments(t *testing.T) {
+	t.Parallel()
+
+	input := "---\nkind: ConfigMap\napiVersion: v1\n---\n---\nkind: Secret\napiVersion: v1\n"
+	expected := "apiVersion: v1\nkind: ConfigMap\n---\napiVersion: v1\nkind: Secret\n"
+
+	actual, err := util.CanonicalYAML([]byte(input))
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(actual))
+}
+
+func TestWriteCanonicalYAMLFileSkipsUnchangedContent(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-canonical-yaml-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fileName := filepath.Join(dir, "values.yaml")
+	err = util.WriteCanonicalYAMLFile(fileName, []byte("b: 2\na: 1\n"), util.DefaultWritePermissions)
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(fileName)
+	require.NoError(t, err)
+	assert.Equal(t, "a: 1\nb: 2\n", string(data))
+
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(fileName, old, old))
+
+	err = util.WriteCanonicalYAMLFile(fileName, []byte("a: 1\nb: 2"), util.DefaultWritePermissions)
+	require.NoError(t, err)
+
+	info, err := os.Stat(fileName)
+	require.NoError(t, err)
+	assert.True(t, info.ModTime().Equal(old), "file should not be rewritten when the content is unchanged")
+}
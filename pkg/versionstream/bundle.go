@@ -0,0 +1,182 @@
+package versionstream
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// BundleMetadataFileName the name of the file inside a version stream bundle which describes where it came from
+const BundleMetadataFileName = "jx-versions-bundle.yml"
+
+// BundleMetadata describes the version stream git repository a bundle was created from
+type BundleMetadata struct {
+	// URL the git URL of the version stream
+	URL string `json:"url,omitempty"`
+	// Ref the git reference the bundle was created from
+	Ref string `json:"ref,omitempty"`
+	// Commit the git commit SHA the bundle was created from
+	Commit string `json:"commit,omitempty"`
+}
+
+// CreateBundle writes the version stream in versionsDir to a gzipped tarball so it can be used without any git access
+func CreateBundle(versionsDir string, fileName string, metadata *BundleMetadata) error {
+	f, err := os.Create(fileName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create file %s", fileName)
+	}
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	err = writeBundle(tw, versionsDir, metadata)
+	// the writers flush their remaining data when closed so they are closed in order and their errors returned
+	closeErr := tw.Close()
+	if closeErr == nil {
+		closeErr = gw.Close()
+	} else {
+		gw.Close() //nolint:errcheck
+	}
+	if closeErr == nil {
+		closeErr = f.Close()
+	} else {
+		f.Close() //nolint:errcheck
+	}
+	if err != nil {
+		return err
+	}
+	if closeErr != nil {
+		return errors.Wrapf(closeErr, "failed to write file %s", fileName)
+	}
+	return nil
+}
+
+// writeBundle writes the metadata and the files of the version stream to the bundle
+func writeBundle(tw *tar.Writer, versionsDir string, metadata *BundleMetadata) error {
+	data, err := yaml.Marshal(metadata)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the bundle metadata to YAML")
+	}
+	err = tw.WriteHeader(&tar.Header{
+		Name: BundleMetadataFileName,
+		Mode: 0644,
+		Size: int64(len(data)),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to write %s", BundleMetadataFileName)
+	}
+	_, err = tw.Write(data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write %s", BundleMetadataFileName)
+	}
+
+	return filepath.Walk(versionsDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(versionsDir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." || rel == BundleMetadataFileName {
+			return nil
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return errors.Wrapf(err, "failed to create tar header for %s", path)
+		}
+		header.Name = filepath.ToSlash(rel)
+		err = tw.WriteHeader(header)
+		if err != nil {
+			return errors.Wrapf(err, "failed to write tar header for %s", path)
+		}
+		src, err := os.Open(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to open %s", path)
+		}
+		defer src.Close()
+		_, err = io.Copy(tw, src)
+		if err != nil {
+			return errors.Wrapf(err, "failed to add %s to the bundle", path)
+		}
+		return nil
+	})
+}
+
+// ExtractBundle extracts the version stream bundle into the target dir replacing any previous contents and returns
+// the metadata describing the version stream
+func ExtractBundle(fileName string, targetDir string) (*BundleMetadata, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open version stream bundle %s", fileName)
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read version stream bundle %s", fileName)
+	}
+	defer gr.Close()
+
+	err = os.RemoveAll(targetDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to remove %s", targetDir)
+	}
+	err = os.MkdirAll(targetDir, util.DefaultWritePermissions)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create directory %s", targetDir)
+	}
+
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read version stream bundle %s", fileName)
+		}
+		path := filepath.Join(targetDir, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(path, filepath.Clean(targetDir)+string(os.PathSeparator)) {
+			return nil, fmt.Errorf("version stream bundle %s contains an invalid path %s", fileName, header.Name)
+		}
+		err = util.UnTarFile(header, path, tr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to extract %s", header.Name)
+		}
+	}
+
+	metadata := &BundleMetadata{}
+	metadataFile := filepath.Join(targetDir, BundleMetadataFileName)
+	exists, err := util.FileExists(metadataFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to check if file exists %s", metadataFile)
+	}
+	if !exists {
+		return metadata, nil
+	}
+	data, err := ioutil.ReadFile(metadataFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load file %s", metadataFile)
+	}
+	err = yaml.Unmarshal(data, metadata)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal YAML in file %s", metadataFile)
+	}
+	return metadata, nil
+}
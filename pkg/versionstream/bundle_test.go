@@ -0,0 +1,60 @@
+// +build unit
+
+package versionstream
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleAndLock(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "test-versions-bundle")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	metadata := &BundleMetadata{
+		URL:    "https://github.com/jenkins-x/jenkins-x-versions.git",
+		Ref:    "v1.0.1",
+		Commit: "0123456789abcdef",
+	}
+	bundleFile := filepath.Join(tmpDir, "bundle.tgz")
+	err = CreateBundle(dataDir, bundleFile, metadata)
+	require.NoError(t, err, "failed to create bundle from %s", dataDir)
+
+	extractDir := filepath.Join(tmpDir, "extracted")
+	actualMetadata, err := ExtractBundle(bundleFile, extractDir)
+	require.NoError(t, err, "failed to extract bundle %s", bundleFile)
+	assert.Equal(t, metadata, actualMetadata)
+
+	version, err := LoadStableVersionNumber(extractDir, KindPackage, "helm")
+	require.NoError(t, err)
+	assert.Equal(t, "2.12.2", version)
+
+	expected, err := CreateVersionsLock(dataDir)
+	require.NoError(t, err)
+	assert.Equal(t, "0.1.13", expected.Charts["jenkins-x/knative-build"])
+	assert.Equal(t, "2.12.2", expected.Packages["helm"])
+
+	lockFile := filepath.Join(tmpDir, VersionsLockFileName)
+	err = expected.SaveFile(lockFile)
+	require.NoError(t, err)
+	locked, err := LoadVersionsLock(lockFile)
+	require.NoError(t, err)
+
+	actual, err := CreateVersionsLock(extractDir)
+	require.NoError(t, err)
+	assert.NoError(t, locked.Verify(actual))
+
+	err = SaveStableVersion(extractDir, KindPackage, "helm", &StableVersion{Version: "2.16.1"})
+	require.NoError(t, err)
+	actual, err = CreateVersionsLock(extractDir)
+	require.NoError(t, err)
+	err = locked.Verify(actual)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "packages helm is locked to 2.12.2 but resolved to 2.16.1")
+}
@@ -0,0 +1,93 @@
+package versionstream
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/blang/semver"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// KubernetesVersionsFile the name of the file in the version stream which contains the supported Kubernetes versions
+const KubernetesVersionsFile = "kubernetes.yml"
+
+// KubernetesVersions the range of Kubernetes versions the version stream has been tested against along with the
+// APIs the charts in the version stream depend on
+type KubernetesVersions struct {
+	// MinVersion the oldest supported Kubernetes version
+	MinVersion string `json:"minVersion,omitempty"`
+	// UpperLimit the first Kubernetes version which is too new to be supported
+	UpperLimit string `json:"upperLimit,omitempty"`
+	// RequiredAPIs the APIs the charts in the version stream use which must be served by the cluster
+	RequiredAPIs []KubernetesAPI `json:"requiredApis,omitempty"`
+}
+
+// KubernetesAPI an API resource which must be available in the cluster
+type KubernetesAPI struct {
+	// GroupVersion the API group version such as 'extensions/v1beta1'
+	GroupVersion string `json:"groupVersion"`
+	// Resource the plural resource name such as 'ingresses'
+	Resource string `json:"resource"`
+}
+
+// String returns the text representation of the API
+func (a *KubernetesAPI) String() string {
+	return a.GroupVersion + " " + a.Resource
+}
+
+// GetKubernetesVersions loads the supported Kubernetes versions from the version stream. If the version stream
+// does not contain the file then an empty struct is returned which supports any version
+func GetKubernetesVersions(dir string) (*KubernetesVersions, error) {
+	answer := &KubernetesVersions{}
+	fileName := filepath.Join(dir, KubernetesVersionsFile)
+	exists, err := util.FileExists(fileName)
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to find file %s", fileName)
+	}
+	if !exists {
+		return answer, nil
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to load file %s", fileName)
+	}
+	err = yaml.Unmarshal(data, answer)
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to unmarshal YAML in file %s", fileName)
+	}
+	return answer, nil
+}
+
+// VerifyVersion returns an error if the given Kubernetes server version is outside of the supported range
+func (k *KubernetesVersions) VerifyVersion(serverVersion string) error {
+	current := convertToVersion(serverVersion)
+	if current == "" || (k.MinVersion == "" && k.UpperLimit == "") {
+		return nil
+	}
+	currentSem, err := semver.Make(current)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse Kubernetes server version %s", serverVersion)
+	}
+	if k.MinVersion != "" {
+		minSem, err := semver.Make(convertToVersion(k.MinVersion))
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse minimum Kubernetes version %s", k.MinVersion)
+		}
+		if currentSem.LT(minSem) {
+			return fmt.Errorf("Kubernetes version %s is too old. The version stream requires at least %s", current, k.MinVersion)
+		}
+	}
+	if k.UpperLimit != "" {
+		limitSem, err := semver.Make(convertToVersion(k.UpperLimit))
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse upper limit Kubernetes version %s", k.UpperLimit)
+		}
+		if currentSem.GE(limitSem) {
+			return fmt.Errorf("Kubernetes version %s is too new. The version stream has been tested with versions earlier than %s", current, k.UpperLimit)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+// +build unit
+
+package versionstream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKubernetesVersions(t *testing.T) {
+	versions, err := GetKubernetesVersions(dataDir)
+	require.NoError(t, err, "GetKubernetesVersions() failed on dir %s", dataDir)
+
+	assert.Equal(t, "1.13.0", versions.MinVersion)
+	assert.Equal(t, "1.18.0", versions.UpperLimit)
+	require.Len(t, versions.RequiredAPIs, 2)
+	assert.Equal(t, "extensions/v1beta1 ingresses", versions.RequiredAPIs[0].String())
+
+	assert.NoError(t, versions.VerifyVersion("v1.13.0"))
+	assert.NoError(t, versions.VerifyVersion("v1.15.11-gke.3"))
+	assert.NoError(t, versions.VerifyVersion(""))
+	assert.Error(t, versions.VerifyVersion("v1.12.10"))
+	assert.Error(t, versions.VerifyVersion("v1.18.2"))
+}
+
+func TestKubernetesVersionsMissingFile(t *testing.T) {
+	versions, err := GetKubernetesVersions("test_data/does-not-exist")
+	require.NoError(t, err)
+
+	assert.NoError(t, versions.VerifyVersion("v1.10.0"))
+	assert.Empty(t, versions.RequiredAPIs)
+}
@@ -0,0 +1,147 @@
+package versionstream
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// VersionsLockFileName the default name of the file which records the resolved version stream versions
+const VersionsLockFileName = "jx-versions-lock.yml"
+
+// VersionsLock records the version stream commit and the exact chart, package and docker image versions it resolves
+// so that subsequent installations are reproducible
+type VersionsLock struct {
+	// URL the git URL of the version stream
+	URL string `json:"url,omitempty"`
+	// Ref the git reference requested
+	Ref string `json:"ref,omitempty"`
+	// Commit the git commit SHA of the version stream
+	Commit string `json:"commit,omitempty"`
+	// Charts the chart versions
+	Charts map[string]string `json:"charts,omitempty"`
+	// Packages the package versions
+	Packages map[string]string `json:"packages,omitempty"`
+	// Docker the docker image versions
+	Docker map[string]string `json:"docker,omitempty"`
+}
+
+// CreateVersionsLock creates a lock of all the chart, package and docker image versions in the version stream dir
+func CreateVersionsLock(versionsDir string) (*VersionsLock, error) {
+	lock := &VersionsLock{}
+	var err error
+	lock.Charts, err = loadKindVersions(versionsDir, KindChart)
+	if err != nil {
+		return nil, err
+	}
+	lock.Packages, err = loadKindVersions(versionsDir, KindPackage)
+	if err != nil {
+		return nil, err
+	}
+	lock.Docker, err = loadKindVersions(versionsDir, KindDocker)
+	if err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+func loadKindVersions(versionsDir string, kind VersionKind) (map[string]string, error) {
+	answer := map[string]string{}
+	dir := filepath.Join(versionsDir, string(kind))
+	exists, err := util.DirExists(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to check if directory exists %s", dir)
+	}
+	if !exists {
+		return answer, nil
+	}
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".yml") {
+			return nil
+		}
+		name, err := NameFromPath(dir, path)
+		if err != nil {
+			return err
+		}
+		version, err := LoadStableVersionFile(path)
+		if err != nil {
+			// not every YAML file in the version stream is a version file
+			log.Logger().Debugf("ignoring %s: %s", path, err.Error())
+			return nil
+		}
+		if version.Version != "" {
+			answer[name] = version.Version
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load the %s versions from %s", string(kind), dir)
+	}
+	return answer, nil
+}
+
+// LoadVersionsLock loads the lock file
+func LoadVersionsLock(fileName string) (*VersionsLock, error) {
+	lock := &VersionsLock{}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load file %s", fileName)
+	}
+	err = yaml.Unmarshal(data, lock)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal YAML in file %s", fileName)
+	}
+	return lock, nil
+}
+
+// SaveFile saves the lock to the given file
+func (l *VersionsLock) SaveFile(fileName string) error {
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the versions lock to YAML")
+	}
+	err = ioutil.WriteFile(fileName, data, util.DefaultFileWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to save file %s", fileName)
+	}
+	return nil
+}
+
+// Verify returns an error listing any versions which differ from the actual versions
+func (l *VersionsLock) Verify(actual *VersionsLock) error {
+	var differences []string
+	differences = append(differences, diffVersions(KindChart, l.Charts, actual.Charts)...)
+	differences = append(differences, diffVersions(KindPackage, l.Packages, actual.Packages)...)
+	differences = append(differences, diffVersions(KindDocker, l.Docker, actual.Docker)...)
+	if len(differences) == 0 {
+		return nil
+	}
+	return fmt.Errorf("the version stream does not match the locked versions:\n  %s", strings.Join(differences, "\n  "))
+}
+
+func diffVersions(kind VersionKind, locked map[string]string, actual map[string]string) []string {
+	var answer []string
+	for _, name := range util.SortedMapKeys(locked) {
+		if actual[name] != locked[name] {
+			answer = append(answer, fmt.Sprintf("%s %s is locked to %s but resolved to %s", string(kind), name, locked[name], actual[name]))
+		}
+	}
+	for name := range actual {
+		if _, ok := locked[name]; !ok {
+			answer = append(answer, fmt.Sprintf("%s %s resolved to %s but is not locked", string(kind), name, actual[name]))
+		}
+	}
+	sort.Strings(answer)
+	return answer
+}
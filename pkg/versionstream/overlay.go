@@ -0,0 +1,63 @@
+package versionstream
+
+import (
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+)
+
+// UpstreamLayerName the name of the layer for the upstream version stream
+const UpstreamLayerName = "upstream"
+
+// VersionLayer a version stream directory layered on top of the upstream version stream. Any versions defined in
+// the layer override the versions in the layers below it
+type VersionLayer struct {
+	// Name describes where the layer came from, typically its git URL
+	Name string
+	// Dir the directory containing the version stream files
+	Dir string
+}
+
+// LoadLayeredStableVersion loads the stable version from the first of the layers which defines a version for the
+// given kind and name, returning the name of the layer which supplied it. If no layer defines a version an empty
+// version is returned from the last layer
+func LoadLayeredStableVersion(layers []VersionLayer, kind VersionKind, name string) (*StableVersion, string, error) {
+	answer := &StableVersion{}
+	layerName := ""
+	for _, layer := range layers {
+		version, err := LoadStableVersion(layer.Dir, kind, name)
+		if err != nil {
+			return nil, layer.Name, err
+		}
+		answer = version
+		layerName = layer.Name
+		if version.Version != "" {
+			break
+		}
+	}
+	return answer, layerName, nil
+}
+
+// layers returns the overlays followed by the upstream version stream
+func (v *VersionResolver) layers() []VersionLayer {
+	answer := append([]VersionLayer{}, v.Overlays...)
+	return append(answer, VersionLayer{Name: UpstreamLayerName, Dir: v.VersionsDir})
+}
+
+// StableVersionLayer returns the stable version of the given kind name along with the name of the layer which
+// supplied it
+func (v *VersionResolver) StableVersionLayer(kind VersionKind, name string) (*StableVersion, string, error) {
+	return LoadLayeredStableVersion(v.layers(), kind, name)
+}
+
+// overlayVersionsDir returns the overlay directory which defines a version for the given kind and name or the
+// upstream versions dir if no overlay does
+func (v *VersionResolver) overlayVersionsDir(kind VersionKind, name string) string {
+	for _, layer := range v.Overlays {
+		version, err := LoadStableVersion(layer.Dir, kind, name)
+		if err == nil && version.Version != "" {
+			log.Logger().Debugf("using %s %s version %s from the version stream overlay %s", string(kind), util.ColorInfo(name), util.ColorInfo(version.Version), util.ColorInfo(layer.Name))
+			return layer.Dir
+		}
+	}
+	return v.VersionsDir
+}
@@ -0,0 +1,55 @@
+// +build unit
+
+package versionstream
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionResolverOverlays(t *testing.T) {
+	overlayDir, err := ioutil.TempDir("", "test-versions-overlay")
+	require.NoError(t, err)
+	defer os.RemoveAll(overlayDir)
+
+	err = SaveStableVersion(overlayDir, KindPackage, "helm", &StableVersion{Version: "2.16.1"})
+	require.NoError(t, err)
+	err = SaveStableVersion(overlayDir, KindDocker, "gcr.io/jenkinsxio/builder-jx", &StableVersion{Version: "9.9.9"})
+	require.NoError(t, err)
+
+	overlayName := "https://github.com/myorg/versions-overlay.git"
+	resolver := &VersionResolver{
+		VersionsDir: dataDir,
+		Overlays: []VersionLayer{
+			{
+				Name: overlayName,
+				Dir:  overlayDir,
+			},
+		},
+	}
+
+	version, layer, err := resolver.StableVersionLayer(KindPackage, "helm")
+	require.NoError(t, err)
+	assert.Equal(t, "2.16.1", version.Version)
+	assert.Equal(t, overlayName, layer)
+
+	version, layer, err = resolver.StableVersionLayer(KindChart, "jenkins-x/knative-build")
+	require.NoError(t, err)
+	assert.Equal(t, "0.1.13", version.Version)
+	assert.Equal(t, UpstreamLayerName, layer)
+
+	number, err := resolver.StableVersionNumber(KindPackage, "helm")
+	require.NoError(t, err)
+	assert.Equal(t, "2.16.1", number)
+
+	image, err := resolver.ResolveDockerImage("gcr.io/jenkinsxio/builder-jx")
+	require.NoError(t, err)
+	assert.Equal(t, "gcr.io/jenkinsxio/builder-jx:9.9.9", image)
+
+	AssertPackageVersion(t, resolver, "helm", "2.16.1", true)
+	AssertPackageVersion(t, resolver, "helm", "2.12.2", false)
+}
@@ -11,6 +11,8 @@ import (
 // VersionResolver resolves versions of charts, packages or docker images
 type VersionResolver struct {
 	VersionsDir string
+	// Overlays version streams which override the versions in VersionsDir. The first overlay to define a version wins
+	Overlays []VersionLayer
 }
 
 // GetVersionsDir returns the versionsdir
@@ -20,17 +22,18 @@ func (v *VersionResolver) GetVersionsDir() string {
 
 // ResolveDockerImage ensures the given docker image has a valid version if there is one in the version stream
 func (v *VersionResolver) ResolveDockerImage(image string) (string, error) {
-	return ResolveDockerImage(v.VersionsDir, image)
+	return ResolveDockerImage(v.overlayVersionsDir(KindDocker, image), image)
 }
 
 // StableVersion returns the stable version of the given kind name
 func (v *VersionResolver) StableVersion(kind VersionKind, name string) (*StableVersion, error) {
-	return LoadStableVersion(v.VersionsDir, kind, name)
+	version, _, err := v.StableVersionLayer(kind, name)
+	return version, err
 }
 
 // StableVersionNumber returns the stable version number of the given kind name
 func (v *VersionResolver) StableVersionNumber(kind VersionKind, name string) (string, error) {
-	return LoadStableVersionNumber(v.VersionsDir, kind, name)
+	return LoadStableVersionNumber(v.overlayVersionsDir(kind, name), kind, name)
 }
 
 // ResolveGitVersion resolves the version to use for the given git repository using the version stream
@@ -67,11 +70,12 @@ func (v *VersionResolver) VerifyPackages(packages map[string]string) error {
 
 // VerifyPackage verifies the package is of a sufficient version
 func (v *VersionResolver) VerifyPackage(name string, currentVersion string) error {
-	data, err := LoadStableVersion(v.VersionsDir, KindPackage, name)
+	dir := v.overlayVersionsDir(KindPackage, name)
+	data, err := LoadStableVersion(dir, KindPackage, name)
 	if err != nil {
 		return err
 	}
-	return data.VerifyPackage(name, currentVersion, v.VersionsDir)
+	return data.VerifyPackage(name, currentVersion, dir)
 }
 
 // GetRepositoryPrefixes loads the repository prefixes for the version stream
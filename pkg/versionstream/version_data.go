@@ -5,6 +5,7 @@ import (
 	"sort"
 
 	"github.com/blang/semver"
+	jenkinsv1 "github.com/jenkins-x/jx-api/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx-logging/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/pkg/errors"
@@ -385,6 +386,56 @@ func SaveQuickStarts(dir string, qs *QuickStarts) error {
 	return nil
 }
 
+// GetPluginIndex loads the index of the jx binary plugins which can be installed from the version stream
+func GetPluginIndex(dir string) (*PluginIndex, error) {
+	answer := &PluginIndex{}
+	fileName := filepath.Join(dir, "plugins.yml")
+	exists, err := util.FileExists(fileName)
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to find file %s", fileName)
+	}
+	if !exists {
+		return answer, nil
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to load file %s", fileName)
+	}
+	err = yaml.Unmarshal(data, answer)
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to unmarshal YAML in file %s", fileName)
+	}
+	answer.DefaultMissingValues()
+	return answer, nil
+}
+
+// PluginIndex the jx binary plugins which can be installed from the version stream
+type PluginIndex struct {
+	Plugins []jenkinsv1.PluginSpec `json:"plugins"`
+}
+
+// DefaultMissingValues defaults the name of the plugin binaries from their sub command
+func (p *PluginIndex) DefaultMissingValues() {
+	for i := range p.Plugins {
+		plugin := &p.Plugins[i]
+		if plugin.Name == "" {
+			plugin.Name = "jx-" + strings.Join(strings.Fields(plugin.SubCommand), "-")
+		}
+	}
+}
+
+// Find returns the plugin with the given name, sub command or binary name or nil if there is no such plugin
+func (p *PluginIndex) Find(name string) *jenkinsv1.PluginSpec {
+	binaryName := "jx-" + strings.Join(strings.Fields(name), "-")
+	for i := range p.Plugins {
+		plugin := &p.Plugins[i]
+		if plugin.Name == name || plugin.Name == binaryName || plugin.SubCommand == name {
+			return plugin
+		}
+	}
+	return nil
+}
+
 // RepositoryPrefixes maps repository prefixes to URLs
 type RepositoryPrefixes struct {
 	Repositories []RepositoryURLs    `json:"repositories"`
@@ -60,6 +60,26 @@ func TestRepositories(t *testing.T) {
 	}
 }
 
+func TestPluginIndex(t *testing.T) {
+	index, err := GetPluginIndex(dataDir)
+	require.NoError(t, err, "GetPluginIndex() failed on dir %s", dataDir)
+	require.Len(t, index.Plugins, 2)
+
+	plugin := index.Find("hello")
+	require.NotNil(t, plugin, "failed to find the plugin by its sub command")
+	assert.Equal(t, "jx-hello", plugin.Name, "the plugin name should default from its sub command")
+	assert.Equal(t, "1.2.3", plugin.Version)
+	assert.Len(t, plugin.Binaries, 2)
+
+	assert.Equal(t, plugin, index.Find("jx-hello"), "failed to find the plugin by its binary name")
+	assert.Equal(t, "jx-admin", index.Find("admin").Name)
+	assert.Nil(t, index.Find("doesNotExist"))
+
+	index, err = GetPluginIndex("test_data")
+	require.NoError(t, err)
+	assert.Empty(t, index.Plugins, "version streams without a plugin index have no plugins")
+}
+
 // TestExactPackageVersionRange tests ranges of packages
 func TestExactPackageVersionRange(t *testing.T) {
 	resolver := &VersionResolver{
@@ -70,7 +70,7 @@ func cloneJXVersionsRepo(versionRepository string, versionRef string, settings *
 				Message: "A local Jenkins X versions repository already exists, pull the latest?",
 				Default: true,
 			}
-			err = survey.AskOne(confirm, &pullLatest, nil, surveyOpts)
+			err = util.AskOne(confirm, &pullLatest, nil, surveyOpts)
 			if err != nil {
 				log.Logger().Errorf("Error confirming if we should pull latest, skipping %s", wrkDir)
 			}
@@ -156,6 +156,12 @@ func deleteAndReClone(wrkDir string, versionRepository string, referenceName str
 }
 
 func clone(wrkDir string, versionRepository string, referenceName string, gitter gits.Gitter) (string, error) {
+	if !strings.Contains(referenceName, "/") && !strings.HasPrefix(referenceName, "PR-") {
+		cloned, err := cloneFromCache(wrkDir, versionRepository, referenceName, gitter)
+		if cloned || err != nil {
+			return "", err
+		}
+	}
 	if referenceName == "" || referenceName == "master" {
 		referenceName = "refs/heads/master"
 	} else if !strings.Contains(referenceName, "/") {
@@ -199,8 +205,14 @@ func clone(wrkDir string, versionRepository string, referenceName string, gitter
 	}
 	log.Logger().Infof("Cloning the Jenkins X versions repo %s with ref %s to %s", util.ColorInfo(versionRepository), util.ColorInfo(referenceName), util.ColorInfo(wrkDir))
 	// TODO: Change this to use gitter instead, but need to understand exactly what it's doing first.
-	_, err := git.PlainClone(wrkDir, false, &git.CloneOptions{
-		URL:           versionRepository,
+	sshOptions := gits.SSHOptionsFromEnv()
+	cloneURL, auth, err := sshOptions.GoGitRemote(versionRepository)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to configure the authentication to clone %s", versionRepository)
+	}
+	_, err = git.PlainClone(wrkDir, false, &git.CloneOptions{
+		URL:           cloneURL,
+		Auth:          auth,
 		ReferenceName: plumbing.ReferenceName(referenceName),
 		SingleBranch:  true,
 		Progress:      nil,
@@ -211,6 +223,33 @@ func clone(wrkDir string, versionRepository string, referenceName string, gitter
 	return "", err
 }
 
+// cloneFromCache clones the version stream via the git clone cache returning false if it could not be used. The
+// whole history is cloned as the ref is resolved to the nearest tag
+func cloneFromCache(wrkDir string, versionRepository string, referenceName string, gitter gits.Gitter) (bool, error) {
+	cache, err := gits.NewCloneCache(gitter)
+	if err != nil || cache == nil {
+		return false, err
+	}
+	if referenceName == "" {
+		referenceName = config.DefaultVersionsRef
+	}
+	log.Logger().Debugf("Cloning the Jenkins X versions repo %s with ref %s to %s via the git clone cache", util.ColorInfo(versionRepository), util.ColorInfo(referenceName), util.ColorInfo(wrkDir))
+	err = cache.Clone(versionRepository, referenceName, wrkDir, false)
+	if err == nil {
+		return true, nil
+	}
+	log.Logger().Warnf("failed to clone the Jenkins X versions repo via the git clone cache: %s", err.Error())
+	err = os.RemoveAll(wrkDir)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to delete dir %s", wrkDir)
+	}
+	err = os.MkdirAll(wrkDir, util.DefaultWritePermissions)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to ensure directory is created %s", wrkDir)
+	}
+	return false, nil
+}
+
 func shallowCloneGitRepositoryToDir(dir string, gitURL string, pullRequestNumber string, revision string, gitter gits.Gitter) error {
 	if pullRequestNumber != "" {
 		log.Logger().Infof("shallow cloning pull request %s of repository %s to temp dir %s", gitURL,
@@ -253,3 +292,26 @@ func resolveRefToTag(dir string, commitish string, gitter gits.Gitter) (string,
 	}
 	return resolved, nil
 }
+
+// CloneOverlayRepo clones a version stream overlay repository to a local working dir replacing any previous clone
+func CloneOverlayRepo(overlayRepository string, overlayRef string, gitter gits.Gitter) (string, error) {
+	configDir, err := util.ConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("error determining config dir %v", err)
+	}
+	wrkDir := filepath.Join(configDir, "jenkins-x-versions-overlay")
+	err = os.RemoveAll(wrkDir)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to delete dir %s", wrkDir)
+	}
+	err = os.MkdirAll(wrkDir, util.DefaultWritePermissions)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to ensure directory is created %s", wrkDir)
+	}
+	log.Logger().Debugf("Cloning the Jenkins X versions overlay repo %s with ref %s to %s", util.ColorInfo(overlayRepository), util.ColorInfo(overlayRef), util.ColorInfo(wrkDir))
+	err = gitter.ShallowClone(wrkDir, overlayRepository, overlayRef, "")
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to clone the versions overlay repository %s", overlayRepository)
+	}
+	return wrkDir, nil
+}